@@ -0,0 +1,52 @@
+package main
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	httpAdapter "github.com/gostratum/examples/bff-demo/internal/adapter/http"
+	"github.com/gostratum/examples/bff-demo/internal/adapter/memory"
+	"github.com/gostratum/examples/bff-demo/internal/auth"
+	"github.com/gostratum/examples/bff-demo/internal/session"
+	"github.com/gostratum/examples/bff-demo/internal/usecase"
+	"github.com/gostratum/examples/redact"
+	"github.com/gostratum/httpx"
+	"github.com/gostratum/metricsx"
+)
+
+func main() {
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// Login, refresh and CSRF metrics
+		metricsx.Module(),
+
+		// HTTP server for the frontend, the auth endpoints and the API
+		httpx.Module(),
+
+		// Provide dependencies
+		fx.Provide(
+			// Sessions in memory; one instance only
+			session.NewMemoryStore,
+
+			// OIDC login, session and CSRF middleware
+			auth.NewHandler,
+
+			// In-memory repository
+			memory.NewNotes,
+
+			// Usecase service
+			usecase.NewNoteService,
+
+			// HTTP handlers
+			httpAdapter.NewNoteHandler,
+			httpAdapter.NewProfileHandler,
+		),
+
+		// Invoke setup functions
+		fx.Invoke(httpAdapter.RegisterRoutes),
+	)
+
+	app.Run()
+}
@@ -0,0 +1,25 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gostratum/examples/bff-demo/internal/domain"
+)
+
+// AddNoteRequest represents the request payload for adding a note
+type AddNoteRequest struct {
+	Text string `json:"text" binding:"required"`
+}
+
+// NoteResponse represents a note in HTTP responses; the owner is always the
+// signed-in user, so it is left out
+type NoteResponse struct {
+	ID        string    `json:"id"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FromDomainNote converts a domain note to its HTTP DTO
+func FromDomainNote(n *domain.Note) NoteResponse {
+	return NoteResponse{ID: n.ID, Text: n.Text, CreatedAt: n.CreatedAt}
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/bff-demo/internal/auth"
+	"github.com/gostratum/examples/bff-demo/internal/usecase"
+)
+
+// NoteHandler serves the signed-in user's notes. Its routes sit behind the
+// auth middleware, so every request has a session and every change came with
+// the CSRF token.
+type NoteHandler struct {
+	service *usecase.NoteService
+	log     logx.Logger
+	// owner returns the subject of the signed-in user
+	owner func(c *gin.Context) string
+}
+
+// NewNoteHandler creates a new note handler
+func NewNoteHandler(service *usecase.NoteService, log logx.Logger) *NoteHandler {
+	return &NoteHandler{
+		service: service,
+		log:     log,
+		owner:   func(c *gin.Context) string { return auth.SessionFrom(c).Subject },
+	}
+}
+
+// List handles GET /api/notes
+func (h *NoteHandler) List(c *gin.Context) {
+	notes, err := h.service.List(c.Request.Context(), h.owner(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	res := make([]NoteResponse, 0, len(notes))
+	for _, n := range notes {
+		res = append(res, FromDomainNote(n))
+	}
+	responsex.OK(c, res, nil)
+}
+
+// Add handles POST /api/notes
+func (h *NoteHandler) Add(c *gin.Context) {
+	var req AddNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid request payload", nil)
+		return
+	}
+	n, err := h.service.Add(c.Request.Context(), h.owner(c), req.Text)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	responsex.Created(c, "", FromDomainNote(n))
+}
+
+// Delete handles DELETE /api/notes/:id
+func (h *NoteHandler) Delete(c *gin.Context) {
+	if err := h.service.Delete(c.Request.Context(), h.owner(c), c.Param("id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *NoteHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrNotFound):
+		responsex.Error(c, http.StatusNotFound, "NOTE_NOT_FOUND", "note not found", nil)
+	case errors.Is(err, usecase.ErrInvalid):
+		// The message says which rule the note broke
+		responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
+	case errors.Is(err, usecase.ErrUnavailable):
+		c.Header("Retry-After", "2")
+		responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "service temporarily unavailable", nil)
+	default:
+		h.log.Error("unexpected error", logx.Err(err))
+		responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+	}
+}
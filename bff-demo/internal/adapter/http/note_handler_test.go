@@ -0,0 +1,85 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/bff-demo/internal/adapter/memory"
+	"github.com/gostratum/examples/bff-demo/internal/usecase"
+)
+
+// setupRouter serves the note routes as the user whose subject is in the
+// X-Test-User header; the auth middleware is tested in its own package
+func setupRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewNoteHandler(usecase.NewNoteService(memory.NewNotes()), logx.NewNoopLogger())
+	handler.owner = func(c *gin.Context) string { return c.GetHeader("X-Test-User") }
+
+	e := gin.New()
+	e.GET("/api/notes", handler.List)
+	e.POST("/api/notes", handler.Add)
+	e.DELETE("/api/notes/:id", handler.Delete)
+	return e
+}
+
+func serve(e *gin.Engine, method, path, user, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-User", user)
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w
+}
+
+func TestNoteHandler(t *testing.T) {
+	e := setupRouter()
+
+	w := serve(e, http.MethodPost, "/api/notes", "ada", `{"text": "buy milk"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created struct {
+		Data NoteResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "buy milk", created.Data.Text)
+
+	w = serve(e, http.MethodGet, "/api/notes", "ada", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Data []NoteResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, []NoteResponse{created.Data}, list.Data)
+
+	w = serve(e, http.MethodGet, "/api/notes", "grace", "")
+	var empty struct {
+		Data json.RawMessage `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &empty))
+	assert.Equal(t, "[]", string(empty.Data), "an empty list, not null")
+
+	w = serve(e, http.MethodDelete, "/api/notes/"+created.Data.ID, "grace", "")
+	assert.Equal(t, http.StatusNotFound, w.Code, "another user's note")
+	w = serve(e, http.MethodDelete, "/api/notes/"+created.Data.ID, "ada", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestNoteHandlerInvalidInput(t *testing.T) {
+	e := setupRouter()
+
+	w := serve(e, http.MethodPost, "/api/notes", "ada", `{"text": 1}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_REQUEST")
+
+	w = serve(e, http.MethodPost, "/api/notes", "ada", `{"text": "`+strings.Repeat("a", 501)+`"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "at most 500 characters")
+}
@@ -0,0 +1,35 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/bff-demo/internal/auth"
+)
+
+// ProfileHandler calls the identity provider on behalf of the signed-in user
+type ProfileHandler struct {
+	auth *auth.Handler
+	log  logx.Logger
+}
+
+// NewProfileHandler creates a new profile handler
+func NewProfileHandler(a *auth.Handler, log logx.Logger) *ProfileHandler {
+	return &ProfileHandler{auth: a, log: log}
+}
+
+// Get handles GET /api/profile with the claims of the provider's userinfo
+// endpoint, fetched with the session's access token. The frontend never
+// holds that token; this is how it reaches APIs that need one.
+func (h *ProfileHandler) Get(c *gin.Context) {
+	claims, err := h.auth.UserInfo(c.Request.Context(), auth.SessionFrom(c))
+	if err != nil {
+		h.log.Warn("userinfo request failed", logx.Err(err))
+		responsex.Error(c, http.StatusBadGateway, "UPSTREAM_ERROR", "identity provider request failed", nil)
+		return
+	}
+	responsex.OK(c, claims, nil)
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core"
+	"github.com/gostratum/core/logx"
+
+	"github.com/gostratum/examples/bff-demo/internal/auth"
+	"github.com/gostratum/examples/bff-demo/web"
+)
+
+// contentSecurityPolicy lets the page load scripts, styles and data from
+// this origin only, so injected markup cannot run script or send what it
+// finds elsewhere, and keeps other sites from framing the page
+const contentSecurityPolicy = "default-src 'self'; frame-ancestors 'none'; base-uri 'none'; form-action 'self'"
+
+// RegisterRoutes registers all HTTP routes using the provided Gin engine
+// This function is designed to be used with fx.Invoke to work with httpx.Module
+func RegisterRoutes(e *gin.Engine, a *auth.Handler, notes *NoteHandler, profile *ProfileHandler, reg core.Registry, log logx.Logger) error {
+	e.Use(securityHeaders)
+
+	// Frontend
+	static := web.Static()
+	index, err := fs.ReadFile(static, "index.html")
+	if err != nil {
+		return fmt.Errorf("failed to read index.html: %w", err)
+	}
+	e.GET("/", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", index)
+	})
+	assets, err := fs.Glob(static, "assets/*")
+	if err != nil {
+		return fmt.Errorf("failed to list assets: %w", err)
+	}
+	for _, name := range assets {
+		e.StaticFileFS("/"+name, name, http.FS(static))
+	}
+
+	// Login, logout and the session the frontend asks about on load
+	authGroup := e.Group("/auth", noStore)
+	{
+		authGroup.GET("/login", a.Login)
+		authGroup.GET("/callback", a.Callback)
+		authGroup.GET("/session", a.Session)
+		authGroup.POST("/logout", a.RequireSession, a.RequireCSRF, a.Logout)
+	}
+
+	// API for the frontend: signed in, CSRF-checked, with a fresh access token
+	api := e.Group("/api", noStore, a.RequireSession, a.RequireCSRF, a.RefreshTokens)
+	{
+		api.GET("/profile", profile.Get)
+		api.GET("/notes", notes.List)
+		api.POST("/notes", notes.Add)
+		api.DELETE("/notes/:id", notes.Delete)
+	}
+
+	// Health endpoints - readiness and liveness checks
+	e.GET("/healthz", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		res := reg.Aggregate(ctx, core.Readiness)
+		if res.OK {
+			c.JSON(http.StatusOK, gin.H{"ok": true, "details": res.Details})
+		} else {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"ok": false, "details": res.Details})
+		}
+	})
+
+	e.GET("/livez", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		res := reg.Aggregate(ctx, core.Liveness)
+		if res.OK {
+			c.JSON(http.StatusOK, gin.H{"ok": true, "details": res.Details})
+		} else {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"ok": false, "details": res.Details})
+		}
+	})
+
+	log.Info("HTTP routes registered")
+	return nil
+}
+
+// securityHeaders sets the headers every browser-facing response should have
+func securityHeaders(c *gin.Context) {
+	h := c.Writer.Header()
+	h.Set("Content-Security-Policy", contentSecurityPolicy)
+	h.Set("X-Content-Type-Options", "nosniff")
+	h.Set("Referrer-Policy", "same-origin")
+	c.Next()
+}
+
+// noStore keeps responses that depend on the session out of every cache
+func noStore(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Next()
+}
@@ -0,0 +1,85 @@
+// Package memory keeps notes in memory. The example is about the browser-facing
+// side of a service, so there is no database behind it; restarting the server
+// drops the notes along with the sessions.
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/gostratum/examples/bff-demo/internal/domain"
+	"github.com/gostratum/examples/bff-demo/internal/usecase"
+)
+
+// Notes implements usecase.NoteRepository
+type Notes struct {
+	mu    sync.RWMutex
+	notes map[string]domain.Note
+	// byOwner holds the IDs of each owner's notes, sorted; version 7 UUIDs
+	// sort oldest first
+	byOwner map[string][]string
+}
+
+// NewNotes creates an empty note store
+func NewNotes() usecase.NoteRepository {
+	return &Notes{notes: make(map[string]domain.Note), byOwner: make(map[string][]string)}
+}
+
+// Save implements usecase.NoteRepository
+func (r *Notes) Save(ctx context.Context, n *domain.Note) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.notes[n.ID]; !ok {
+		ids := r.byOwner[n.Owner]
+		i, _ := slices.BinarySearch(ids, n.ID)
+		r.byOwner[n.Owner] = slices.Insert(ids, i, n.ID)
+	}
+	r.notes[n.ID] = *n
+	return nil
+}
+
+// FindByID implements usecase.NoteRepository
+func (r *Notes) FindByID(ctx context.Context, id string) (*domain.Note, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	n, ok := r.notes[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &n, nil
+}
+
+// ListByOwner implements usecase.NoteRepository
+func (r *Notes) ListByOwner(ctx context.Context, owner string, limit int) ([]*domain.Note, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := r.byOwner[owner]
+	notes := make([]*domain.Note, 0, min(limit, len(ids)))
+	for i := len(ids) - 1; i >= 0 && len(notes) < limit; i-- {
+		n := r.notes[ids[i]]
+		notes = append(notes, &n)
+	}
+	return notes, nil
+}
+
+// Delete implements usecase.NoteRepository
+func (r *Notes) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n, ok := r.notes[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.notes, id)
+	ids := r.byOwner[n.Owner]
+	if i, found := slices.BinarySearch(ids, id); found {
+		ids = slices.Delete(ids, i, i+1)
+	}
+	if len(ids) == 0 {
+		delete(r.byOwner, n.Owner)
+	} else {
+		r.byOwner[n.Owner] = ids
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/bff-demo/internal/domain"
+)
+
+func TestNotes(t *testing.T) {
+	repo := NewNotes()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	for _, n := range []domain.Note{
+		{ID: "n-1", Owner: "ada", Text: "first", CreatedAt: now},
+		{ID: "n-2", Owner: "grace", Text: "other", CreatedAt: now},
+		{ID: "n-3", Owner: "ada", Text: "second", CreatedAt: now},
+	} {
+		require.NoError(t, repo.Save(ctx, &n))
+	}
+
+	notes, err := repo.ListByOwner(ctx, "ada", 10)
+	require.NoError(t, err)
+	require.Len(t, notes, 2)
+	assert.Equal(t, "n-3", notes[0].ID, "newest first")
+
+	notes, err = repo.ListByOwner(ctx, "ada", 1)
+	require.NoError(t, err)
+	assert.Len(t, notes, 1)
+
+	notes[0].Text = "changed"
+	stored, err := repo.FindByID(ctx, "n-3")
+	require.NoError(t, err)
+	assert.Equal(t, "second", stored.Text, "callers get copies")
+
+	require.NoError(t, repo.Delete(ctx, "n-3"))
+	require.NoError(t, repo.Delete(ctx, "n-1"))
+	assert.ErrorIs(t, repo.Delete(ctx, "n-1"), domain.ErrNotFound)
+	notes, err = repo.ListByOwner(ctx, "ada", 10)
+	require.NoError(t, err)
+	assert.Empty(t, notes)
+}
@@ -0,0 +1,42 @@
+// Package auth signs browsers in with OpenID Connect and keeps them signed in.
+// The server is the OAuth client: it runs the authorization code flow with
+// PKCE, holds the tokens in a server-side session, refreshes them before they
+// expire, and guards every request that changes something against cross-site
+// request forgery. The browser never sees a token.
+package auth
+
+import "time"
+
+// Config holds the OpenID Connect client settings
+type Config struct {
+	// Issuer is the provider's issuer URL; its discovery document is read
+	// from <issuer>/.well-known/openid-configuration
+	Issuer       string `mapstructure:"issuer"`
+	ClientID     string `mapstructure:"client_id"`
+	ClientSecret string `mapstructure:"client_secret"`
+	// RedirectURL is this server's /auth/callback as the browser reaches
+	// it. Its origin is the only one allowed to send requests that change
+	// something.
+	RedirectURL string `mapstructure:"redirect_url"`
+	// PostLogoutRedirectURL is where the provider sends the browser after a
+	// logout; it defaults to the origin of RedirectURL
+	PostLogoutRedirectURL string `mapstructure:"post_logout_redirect_url"`
+	// Scopes default to openid, profile, email and offline_access; the last
+	// asks for a refresh token
+	Scopes []string `mapstructure:"scopes"`
+	// RefreshBefore refreshes an access token this long before it expires,
+	// so a request never reaches an API with a token about to lapse
+	RefreshBefore time.Duration `mapstructure:"refresh_before" default:"1m"`
+	// LoginTimeout is how long a user may take to sign in at the provider
+	LoginTimeout time.Duration `mapstructure:"login_timeout" default:"10m"`
+	// MaxPendingLogins bounds the logins started and not yet finished, so
+	// requests to /auth/login alone cannot fill the memory
+	MaxPendingLogins int `mapstructure:"max_pending_logins" default:"10000"`
+	// Timeout bounds each request to the provider
+	Timeout time.Duration `mapstructure:"timeout" default:"10s"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "oidc"
+}
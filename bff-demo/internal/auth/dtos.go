@@ -0,0 +1,26 @@
+package auth
+
+// SessionResponse is the HTTP DTO of GET /auth/session, which the frontend
+// calls on load to learn whether it is signed in
+type SessionResponse struct {
+	Authenticated bool  `json:"authenticated"`
+	User          *User `json:"user,omitempty"`
+	// CSRFToken must be sent back as the X-CSRF-Token header of every request
+	// that changes something. Only pages of this origin can read it.
+	CSRFToken string `json:"csrf_token,omitempty"`
+}
+
+// User is the signed-in user, from the claims of the ID token
+type User struct {
+	Subject string `json:"sub"`
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+}
+
+// LogoutResponse is the HTTP DTO of POST /auth/logout
+type LogoutResponse struct {
+	// RedirectURL is where the frontend should send the browser next: the
+	// provider's logout endpoint, which ends the session there too, or back
+	// to the app when the provider has none
+	RedirectURL string `json:"redirect_url"`
+}
@@ -0,0 +1,350 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"github.com/gostratum/metricsx"
+	"go.uber.org/fx"
+	"golang.org/x/oauth2"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/gostratum/examples/bff-demo/internal/session"
+)
+
+// defaultScopes are requested when oidc.scopes is empty
+var defaultScopes = []string{oidc.ScopeOpenID, "profile", "email", oidc.ScopeOfflineAccess}
+
+// Params holds the dependencies of the Handler
+type Params struct {
+	fx.In
+
+	Loader  configx.Loader
+	Metrics metricsx.Metrics
+	Store   session.Store
+	Log     logx.Logger
+}
+
+// Handler serves the /auth endpoints and the middleware that protects the API
+type Handler struct {
+	cfg     Config
+	cookies session.Config
+	store   session.Store
+	idp     *discovery
+	logins  *logins
+	// refreshes runs one refresh per session at a time, however many
+	// requests of the page find its access token expiring together
+	refreshes singleflight.Group
+	// origin is the scheme and host of RedirectURL
+	origin string
+	rec    *recorder
+	log    logx.Logger
+	now    func() time.Time
+}
+
+// NewHandler creates the Handler from the oidc and session config sections
+func NewHandler(p Params) (*Handler, error) {
+	var cfg Config
+	if err := p.Loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load oidc config: %w", err)
+	}
+	var cookies session.Config
+	if err := p.Loader.Bind(&cookies); err != nil {
+		return nil, fmt.Errorf("failed to load session config: %w", err)
+	}
+	return newHandler(cfg, cookies, p.Store, newRecorder(p.Metrics), p.Log)
+}
+
+func newHandler(cfg Config, cookies session.Config, store session.Store, rec *recorder, log logx.Logger) (*Handler, error) {
+	if cfg.Issuer == "" || cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return nil, fmt.Errorf("oidc.issuer, oidc.client_id and oidc.redirect_url are required")
+	}
+	redirect, err := url.Parse(cfg.RedirectURL)
+	if err != nil || !redirect.IsAbs() || redirect.Host == "" {
+		return nil, fmt.Errorf("oidc.redirect_url must be an absolute URL, got %q", cfg.RedirectURL)
+	}
+	if strings.HasPrefix(cookies.CookieName, "__Host-") && !cookies.Secure {
+		return nil, fmt.Errorf("session.cookie_name %q needs session.secure; browsers drop __Host- cookies that are not Secure", cookies.CookieName)
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = defaultScopes
+	}
+	origin := redirect.Scheme + "://" + redirect.Host
+	if cfg.PostLogoutRedirectURL == "" {
+		cfg.PostLogoutRedirectURL = origin + "/"
+	}
+
+	return &Handler{
+		cfg:     cfg,
+		cookies: cookies,
+		store:   store,
+		idp:     &discovery{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}},
+		logins:  newLogins(cfg.LoginTimeout, cfg.MaxPendingLogins),
+		origin:  origin,
+		rec:     rec,
+		log:     log,
+		now:     time.Now,
+	}, nil
+}
+
+// Login handles GET /auth/login, where the frontend sends the browser to sign
+// in. It redirects to the provider with a fresh state, nonce and PKCE
+// challenge; return_to is the path of the app to come back to.
+func (h *Handler) Login(c *gin.Context) {
+	p, err := h.idp.get(c.Request.Context())
+	if err != nil {
+		h.log.Error("identity provider unavailable", logx.Err(err))
+		h.loginError(c, "unavailable")
+		return
+	}
+
+	state, nonce, verifier := session.NewToken(), session.NewToken(), oauth2.GenerateVerifier()
+	if err := h.logins.add(state, login{nonce: nonce, verifier: verifier, returnTo: localPath(c.Query("return_to"))}); err != nil {
+		h.log.Warn("login refused", logx.Err(err))
+		h.loginError(c, "unavailable")
+		return
+	}
+	h.setCookie(c, h.loginCookie(), state, h.cfg.LoginTimeout)
+	c.Redirect(http.StatusFound, p.oauth2.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)))
+}
+
+// Callback handles GET /auth/callback, where the provider sends the browser
+// back. It exchanges the code for tokens, starts a session and redirects to
+// the path the login started from. Failures redirect to the app with a
+// login_error parameter the frontend shows.
+func (h *Handler) Callback(c *gin.Context) {
+	ctx := c.Request.Context()
+	state := c.Query("state")
+	cookie, _ := c.Cookie(h.loginCookie())
+	h.clearCookie(c, h.loginCookie())
+
+	lg, ok := h.logins.take(state)
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(cookie)) != 1 || !ok {
+		h.rec.logins.Inc(loginInvalidState)
+		h.loginError(c, "invalid_state")
+		return
+	}
+	if reason := c.Query("error"); reason != "" {
+		h.rec.logins.Inc(loginDenied)
+		h.log.Info("login denied", logx.String("error", reason), logx.String("description", c.Query("error_description")))
+		h.loginError(c, "denied")
+		return
+	}
+
+	s, err := h.signIn(ctx, c.Query("code"), lg)
+	if err != nil {
+		h.rec.logins.Inc(loginFailed)
+		h.log.Warn("login failed", logx.Err(err))
+		h.loginError(c, "failed")
+		return
+	}
+
+	// A login always gets a new session ID, never one the browser already
+	// had, so an ID planted in the browser beforehand is worth nothing
+	if old, err := c.Cookie(h.cookies.CookieName); err == nil {
+		_ = h.store.Delete(ctx, old)
+	}
+	if err := h.store.Save(ctx, s); err != nil {
+		h.rec.logins.Inc(loginFailed)
+		h.log.Error("failed to save session", logx.Err(err))
+		h.loginError(c, "failed")
+		return
+	}
+	// No Max-Age: the cookie goes when the browser closes, and the store
+	// ends the session on its timeouts before that
+	h.setCookie(c, h.cookies.CookieName, s.ID, 0)
+
+	h.rec.logins.Inc(loginOK)
+	h.log.Info("signed in", logx.String("subject", s.Subject))
+	c.Redirect(http.StatusFound, lg.returnTo)
+}
+
+// signIn exchanges the code and checks the ID token that comes with the tokens
+func (h *Handler) signIn(ctx context.Context, code string, lg login) (*session.Session, error) {
+	p, err := h.idp.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tok, err := p.oauth2.Exchange(h.idp.context(ctx), code, oauth2.VerifierOption(lg.verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	raw, ok := tok.Extra("id_token").(string)
+	if !ok || raw == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+	idToken, err := p.verifier.Verify(ctx, raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+	// The nonce ties the ID token to this login; a token replayed from
+	// another one does not carry it
+	if subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(lg.nonce)) != 1 {
+		return nil, errors.New("id_token nonce does not match the login")
+	}
+
+	var claims struct {
+		Name              string `json:"name"`
+		PreferredUsername string `json:"preferred_username"`
+		Email             string `json:"email"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to read id_token claims: %w", err)
+	}
+	if claims.Name == "" {
+		claims.Name = claims.PreferredUsername
+	}
+
+	now := h.now()
+	return &session.Session{
+		ID:           session.NewToken(),
+		Subject:      idToken.Subject,
+		Name:         claims.Name,
+		Email:        claims.Email,
+		IDToken:      raw,
+		AccessToken:  tok.AccessToken,
+		RefreshToken: tok.RefreshToken,
+		TokenExpiry:  tok.Expiry,
+		CSRFToken:    session.NewToken(),
+		CreatedAt:    now,
+		LastSeen:     now,
+	}, nil
+}
+
+// Session handles GET /auth/session. It answers 200 whether or not the
+// browser is signed in, so the frontend can tell a signed-out user from a
+// failure.
+func (h *Handler) Session(c *gin.Context) {
+	id, err := c.Cookie(h.cookies.CookieName)
+	if err != nil {
+		responsex.OK(c, SessionResponse{}, nil)
+		return
+	}
+	s, err := h.store.Get(c.Request.Context(), id)
+	if errors.Is(err, session.ErrNotFound) {
+		h.clearCookie(c, h.cookies.CookieName)
+		responsex.OK(c, SessionResponse{}, nil)
+		return
+	}
+	if err != nil {
+		h.unavailable(c, err)
+		return
+	}
+	responsex.OK(c, SessionResponse{
+		Authenticated: true,
+		User:          &User{Subject: s.Subject, Name: s.Name, Email: s.Email},
+		CSRFToken:     s.CSRFToken,
+	}, nil)
+}
+
+// Logout handles POST /auth/logout, behind RequireSession and RequireCSRF. It
+// ends the session here and answers with the provider's logout URL, which
+// ends it there too; without that, the next login would go through without a
+// password.
+func (h *Handler) Logout(c *gin.Context) {
+	s := SessionFrom(c)
+	if err := h.store.Delete(c.Request.Context(), s.ID); err != nil {
+		h.unavailable(c, err)
+		return
+	}
+	h.clearCookie(c, h.cookies.CookieName)
+	h.log.Info("signed out", logx.String("subject", s.Subject))
+
+	redirect := h.cfg.PostLogoutRedirectURL
+	if p, err := h.idp.get(c.Request.Context()); err == nil && p.endSession != "" {
+		q := url.Values{
+			"id_token_hint":            {s.IDToken},
+			"post_logout_redirect_uri": {h.cfg.PostLogoutRedirectURL},
+			"client_id":                {h.cfg.ClientID},
+		}
+		redirect = p.endSession + "?" + q.Encode()
+	}
+	responsex.OK(c, LogoutResponse{RedirectURL: redirect}, nil)
+}
+
+// UserInfo asks the provider's userinfo endpoint about the user of s, with
+// the session's access token. It is what an API call on behalf of the user
+// looks like: handlers behind RefreshTokens always have a valid token.
+func (h *Handler) UserInfo(ctx context.Context, s *session.Session) (map[string]any, error) {
+	p, err := h.idp.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	info, err := p.oidc.UserInfo(h.idp.context(ctx), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: s.AccessToken, TokenType: "Bearer"}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get userinfo: %w", err)
+	}
+	var claims map[string]any
+	if err := info.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to read userinfo claims: %w", err)
+	}
+	return claims, nil
+}
+
+// loginError sends the browser back to the app with the reason a login failed
+func (h *Handler) loginError(c *gin.Context, reason string) {
+	c.Redirect(http.StatusFound, "/?login_error="+url.QueryEscape(reason))
+}
+
+// loginCookie holds the state of a login in progress
+func (h *Handler) loginCookie() string {
+	return h.cookies.CookieName + "_login"
+}
+
+// setCookie sets a cookie scripts cannot read. SameSite=Lax keeps it off
+// cross-site subrequests and form posts, yet sends it on the top-level
+// navigation back from the provider.
+func (h *Handler) setCookie(c *gin.Context, name, value string, maxAge time.Duration) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   int(maxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   h.cookies.Secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (h *Handler) clearCookie(c *gin.Context, name string) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   h.cookies.Secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (h *Handler) unavailable(c *gin.Context, err error) {
+	h.log.Error("session store failed", logx.Err(err))
+	responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "service unavailable", nil)
+	c.Abort()
+}
+
+// localPath returns p if it is a path on this server, and "/" otherwise, so
+// a crafted return_to cannot turn the login into an open redirect
+func localPath(p string) string {
+	// "//host" and "/\host" are paths to browsers' eyes only until they
+	// resolve them against another host
+	if !strings.HasPrefix(p, "/") || strings.HasPrefix(p, "//") || strings.HasPrefix(p, "/\\") {
+		return "/"
+	}
+	u, err := url.Parse(p)
+	if err != nil || u.Scheme != "" || u.Host != "" {
+		return "/"
+	}
+	return p
+}
@@ -0,0 +1,428 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/bff-demo/internal/session"
+)
+
+const testCookie = "__Host-test_session"
+
+// fakeCounter counts increments per label values
+type fakeCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (f *fakeCounter) Inc(labels ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.counts[strings.Join(labels, ",")]++
+}
+
+func (f *fakeCounter) get(labels ...string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.counts[strings.Join(labels, ",")]
+}
+
+type testEnv struct {
+	*Handler
+	idp       *fakeIDP
+	store     *fakeStore
+	router    *gin.Engine
+	logins    *fakeCounter
+	refreshes *fakeCounter
+	rejected  *fakeCounter
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	env := &testEnv{
+		idp:       newFakeIDP(t),
+		store:     newFakeStore(),
+		logins:    &fakeCounter{counts: map[string]int{}},
+		refreshes: &fakeCounter{counts: map[string]int{}},
+		rejected:  &fakeCounter{counts: map[string]int{}},
+	}
+	cfg := Config{
+		Issuer:           env.idp.srv.URL,
+		ClientID:         testClientID,
+		ClientSecret:     testClientSecret,
+		RedirectURL:      testRedirectURL,
+		RefreshBefore:    time.Minute,
+		LoginTimeout:     10 * time.Minute,
+		MaxPendingLogins: 100,
+		Timeout:          5 * time.Second,
+	}
+	cookies := session.Config{CookieName: testCookie, Secure: true, IdleTimeout: time.Hour, AbsoluteTimeout: time.Hour}
+	rec := &recorder{logins: env.logins, refreshes: env.refreshes, rejected: env.rejected}
+
+	h, err := newHandler(cfg, cookies, env.store, rec, logx.NewNoopLogger())
+	require.NoError(t, err)
+	env.Handler = h
+
+	e := gin.New()
+	e.GET("/auth/login", h.Login)
+	e.GET("/auth/callback", h.Callback)
+	e.GET("/auth/session", h.Session)
+	e.POST("/auth/logout", h.RequireSession, h.RequireCSRF, h.Logout)
+	api := e.Group("/api", h.RequireSession, h.RequireCSRF, h.RefreshTokens)
+	token := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"access_token": SessionFrom(c).AccessToken})
+	}
+	api.GET("/token", token)
+	api.POST("/token", token)
+	env.router = e
+	return env
+}
+
+// do serves a request with the cookies and headers given
+func (env *testEnv) do(method, target string, header http.Header, cookies ...*http.Cookie) *http.Response {
+	req := httptest.NewRequest(method, target, nil)
+	for k, values := range header {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+	return w.Result()
+}
+
+// startLogin starts a login and returns the redirect to the provider and the
+// login cookie
+func (env *testEnv) startLogin(t *testing.T, returnTo string) (string, *http.Cookie) {
+	t.Helper()
+	res := env.do(http.MethodGet, "/auth/login?return_to="+returnTo, nil)
+	require.Equal(t, http.StatusFound, res.StatusCode)
+	login := cookie(res, testCookie+"_login")
+	require.NotNil(t, login)
+	return res.Header.Get("Location"), login
+}
+
+// signIn runs a whole login and returns the session cookie and CSRF token
+func (env *testEnv) signIn(t *testing.T) (*http.Cookie, string) {
+	t.Helper()
+	authURL, login := env.startLogin(t, "/")
+	code, state := env.idp.authorize(authURL)
+	res := env.do(http.MethodGet, "/auth/callback?code="+code+"&state="+state, nil, login)
+	require.Equal(t, http.StatusFound, res.StatusCode)
+	require.Equal(t, "/", res.Header.Get("Location"))
+	sess := cookie(res, testCookie)
+	require.NotNil(t, sess)
+	return sess, env.store.only(t).CSRFToken
+}
+
+// csrf returns the headers of a same-origin request that changes something
+func csrf(token string) http.Header {
+	return http.Header{"Origin": {testOrigin}, CSRFHeader: {token}}
+}
+
+func TestLogin(t *testing.T) {
+	env := newTestEnv(t)
+
+	authURL, login := env.startLogin(t, "/notes?tab=2")
+	assert.True(t, strings.HasPrefix(authURL, env.idp.srv.URL+"/authorize?"))
+	assert.Equal(t, testClientID, queryParam(t, authURL, "client_id"))
+	assert.Equal(t, testRedirectURL, queryParam(t, authURL, "redirect_uri"))
+	assert.Equal(t, "openid profile email offline_access", queryParam(t, authURL, "scope"))
+	assert.NotEmpty(t, queryParam(t, authURL, "nonce"))
+	assert.Equal(t, login.Value, queryParam(t, authURL, "state"))
+	assert.True(t, login.HttpOnly && login.Secure)
+	assert.Equal(t, http.SameSiteLaxMode, login.SameSite)
+
+	code, state := env.idp.authorize(authURL)
+	res := env.do(http.MethodGet, "/auth/callback?code="+code+"&state="+state, nil, login)
+	require.Equal(t, http.StatusFound, res.StatusCode)
+	assert.Equal(t, "/notes?tab=2", res.Header.Get("Location"))
+
+	sessCookie := cookie(res, testCookie)
+	require.NotNil(t, sessCookie)
+	assert.True(t, sessCookie.HttpOnly && sessCookie.Secure)
+	assert.Equal(t, "/", sessCookie.Path)
+	assert.Equal(t, http.SameSiteLaxMode, sessCookie.SameSite)
+	assert.Zero(t, sessCookie.MaxAge, "a browser-session cookie")
+	assert.Equal(t, -1, cookie(res, testCookie+"_login").MaxAge, "the login cookie is cleared")
+
+	s := env.store.only(t)
+	assert.Equal(t, sessCookie.Value, s.ID)
+	assert.Equal(t, "ada", s.Subject)
+	assert.Equal(t, "Ada Lovelace", s.Name)
+	assert.Equal(t, "at-1", s.AccessToken)
+	assert.Equal(t, "rt-1", s.RefreshToken)
+	assert.NotEmpty(t, s.IDToken)
+	assert.Equal(t, 1, env.logins.get(loginOK))
+
+	res = env.do(http.MethodGet, "/auth/session", nil, sessCookie)
+	var body struct {
+		Data SessionResponse `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+	assert.Equal(t, SessionResponse{
+		Authenticated: true,
+		User:          &User{Subject: "ada", Name: "Ada Lovelace", Email: "ada@example.com"},
+		CSRFToken:     s.CSRFToken,
+	}, body.Data)
+}
+
+func TestCallbackRejectsForeignState(t *testing.T) {
+	env := newTestEnv(t)
+	authURL, login := env.startLogin(t, "/")
+	code, state := env.idp.authorize(authURL)
+
+	// A callback without the login cookie was not started by this browser:
+	// an attacker's own login, forwarded to a victim
+	res := env.do(http.MethodGet, "/auth/callback?code="+code+"&state="+state, nil)
+	assert.Equal(t, "/?login_error=invalid_state", res.Header.Get("Location"))
+	assert.Nil(t, cookie(res, testCookie))
+
+	// The state is gone, even for the browser that started the login
+	res = env.do(http.MethodGet, "/auth/callback?code="+code+"&state="+state, nil, login)
+	assert.Equal(t, "/?login_error=invalid_state", res.Header.Get("Location"))
+	assert.Equal(t, 2, env.logins.get(loginInvalidState))
+	assert.Empty(t, env.store.sessions)
+}
+
+func TestCallbackRejectsWrongNonce(t *testing.T) {
+	env := newTestEnv(t)
+	env.idp.nonce = "replayed"
+	authURL, login := env.startLogin(t, "/")
+	code, state := env.idp.authorize(authURL)
+
+	res := env.do(http.MethodGet, "/auth/callback?code="+code+"&state="+state, nil, login)
+	assert.Equal(t, "/?login_error=failed", res.Header.Get("Location"))
+	assert.Equal(t, 1, env.logins.get(loginFailed))
+	assert.Empty(t, env.store.sessions)
+}
+
+func TestCallbackDenied(t *testing.T) {
+	env := newTestEnv(t)
+	authURL, login := env.startLogin(t, "/")
+	state := queryParam(t, authURL, "state")
+
+	res := env.do(http.MethodGet, "/auth/callback?error=access_denied&state="+state, nil, login)
+	assert.Equal(t, "/?login_error=denied", res.Header.Get("Location"))
+	assert.Equal(t, 1, env.logins.get(loginDenied))
+}
+
+func TestLoginReplacesSession(t *testing.T) {
+	env := newTestEnv(t)
+	first, _ := env.signIn(t)
+
+	authURL, login := env.startLogin(t, "/")
+	code, state := env.idp.authorize(authURL)
+	res := env.do(http.MethodGet, "/auth/callback?code="+code+"&state="+state, nil, login, first)
+	second := cookie(res, testCookie)
+
+	assert.NotEqual(t, first.Value, second.Value)
+	assert.Equal(t, second.Value, env.store.only(t).ID, "the old session is ended")
+}
+
+func TestLocalPath(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                     "/",
+		"/":                    "/",
+		"/notes?tab=2#top":     "/notes?tab=2#top",
+		"notes":                "/",
+		"https://evil.example": "/",
+		"//evil.example":       "/",
+		"/\\evil.example":      "/",
+		"javascript:alert(1)":  "/",
+	} {
+		assert.Equal(t, want, localPath(in), in)
+	}
+}
+
+func TestSessionSignedOut(t *testing.T) {
+	env := newTestEnv(t)
+
+	res := env.do(http.MethodGet, "/auth/session", nil, &http.Cookie{Name: testCookie, Value: "unknown"})
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	var body struct {
+		Data SessionResponse `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+	assert.False(t, body.Data.Authenticated)
+	assert.Equal(t, -1, cookie(res, testCookie).MaxAge, "the stale cookie is cleared")
+}
+
+func TestAPIRequiresSession(t *testing.T) {
+	env := newTestEnv(t)
+
+	res := env.do(http.MethodGet, "/api/token", nil)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	res = env.do(http.MethodGet, "/api/token", nil, &http.Cookie{Name: testCookie, Value: "unknown"})
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	assert.Equal(t, 2, env.rejected.get(rejectUnauthenticated))
+}
+
+func TestCSRF(t *testing.T) {
+	env := newTestEnv(t)
+	sess, token := env.signIn(t)
+
+	res := env.do(http.MethodGet, "/api/token", nil, sess)
+	assert.Equal(t, http.StatusOK, res.StatusCode, "reading needs no token")
+
+	for name, tc := range map[string]struct {
+		header http.Header
+		reason string
+	}{
+		"no token":         {http.Header{"Origin": {testOrigin}}, rejectCSRF},
+		"wrong token":      {csrf("guess"), rejectCSRF},
+		"other origin":     {http.Header{"Origin": {"https://evil.example"}, CSRFHeader: {token}}, rejectOrigin},
+		"cross-site fetch": {http.Header{"Sec-Fetch-Site": {"cross-site"}, CSRFHeader: {token}}, rejectOrigin},
+	} {
+		t.Run(name, func(t *testing.T) {
+			before := env.rejected.get(tc.reason)
+			res := env.do(http.MethodPost, "/api/token", tc.header, sess)
+			assert.Equal(t, http.StatusForbidden, res.StatusCode)
+			assert.Equal(t, before+1, env.rejected.get(tc.reason))
+		})
+	}
+
+	res = env.do(http.MethodPost, "/api/token", csrf(token), sess)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	res = env.do(http.MethodPost, "/api/token", http.Header{CSRFHeader: {token}}, sess)
+	assert.Equal(t, http.StatusOK, res.StatusCode, "clients that send no Origin rely on the token")
+}
+
+func TestRefresh(t *testing.T) {
+	env := newTestEnv(t)
+	env.idp.expiresIn = 30 * time.Second // Within RefreshBefore from the start
+	sess, _ := env.signIn(t)
+
+	const requests = 10
+	var wg sync.WaitGroup
+	tokens := make([]string, requests)
+	for i := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := env.do(http.MethodGet, "/api/token", nil, sess)
+			var body struct {
+				AccessToken string `json:"access_token"`
+			}
+			assert.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+			tokens[i] = body.AccessToken
+		}()
+	}
+	wg.Wait()
+
+	// Every request got a token issued after the first, and the requests
+	// that overlapped shared one refresh; a second refresh of the same
+	// refresh token would have been refused
+	for _, token := range tokens {
+		assert.NotEqual(t, "at-1", token)
+	}
+	s := env.store.only(t)
+	assert.Equal(t, "at-"+s.RefreshToken[len("rt-"):], s.AccessToken, "the rotated refresh token is kept")
+	assert.Equal(t, env.idp.refreshCount(), env.refreshes.get(refreshOK))
+	assert.Zero(t, env.refreshes.get(refreshRejected))
+}
+
+func TestRefreshSkippedForFreshTokens(t *testing.T) {
+	env := newTestEnv(t)
+	sess, _ := env.signIn(t)
+
+	res := env.do(http.MethodGet, "/api/token", nil, sess)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Zero(t, env.idp.refreshCount())
+}
+
+func TestRefreshRejectedEndsSession(t *testing.T) {
+	env := newTestEnv(t)
+	env.idp.expiresIn = 30 * time.Second
+	sess, _ := env.signIn(t)
+	env.idp.refuse = true
+
+	res := env.do(http.MethodGet, "/api/token", nil, sess)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	assert.Equal(t, -1, cookie(res, testCookie).MaxAge)
+	assert.Empty(t, env.store.sessions)
+	assert.Equal(t, 1, env.refreshes.get(refreshRejected))
+}
+
+func TestRefreshFailureKeepsSession(t *testing.T) {
+	env := newTestEnv(t)
+	env.idp.expiresIn = 30 * time.Second
+	sess, _ := env.signIn(t)
+	env.idp.srv.Close()
+
+	res := env.do(http.MethodGet, "/api/token", nil, sess)
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.Len(t, env.store.sessions, 1, "a provider that is down ends no session")
+	assert.Equal(t, 1, env.refreshes.get(refreshFailed))
+}
+
+func TestUserInfo(t *testing.T) {
+	env := newTestEnv(t)
+	env.signIn(t)
+	s := env.store.only(t)
+
+	claims, err := env.UserInfo(t.Context(), &s)
+	require.NoError(t, err)
+	assert.Equal(t, "en-GB", claims["locale"])
+}
+
+func TestLogout(t *testing.T) {
+	env := newTestEnv(t)
+	sess, token := env.signIn(t)
+	idToken := env.store.only(t).IDToken
+
+	res := env.do(http.MethodPost, "/auth/logout", http.Header{"Origin": {testOrigin}}, sess)
+	assert.Equal(t, http.StatusForbidden, res.StatusCode, "logout needs the CSRF token")
+
+	res = env.do(http.MethodPost, "/auth/logout", csrf(token), sess)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var body struct {
+		Data LogoutResponse `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+	redirect := body.Data.RedirectURL
+	assert.True(t, strings.HasPrefix(redirect, env.idp.srv.URL+"/logout?"))
+	assert.Equal(t, idToken, queryParam(t, redirect, "id_token_hint"))
+	assert.Equal(t, testOrigin+"/", queryParam(t, redirect, "post_logout_redirect_uri"))
+
+	assert.Equal(t, -1, cookie(res, testCookie).MaxAge)
+	assert.Empty(t, env.store.sessions)
+	res = env.do(http.MethodGet, "/api/token", nil, sess)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+}
+
+func TestNewHandlerValidatesConfig(t *testing.T) {
+	valid := Config{Issuer: "https://idp.example", ClientID: "c", RedirectURL: testRedirectURL}
+	cookies := session.Config{CookieName: "__Host-s", Secure: true}
+
+	_, err := newHandler(valid, cookies, newFakeStore(), nil, logx.NewNoopLogger())
+	assert.NoError(t, err)
+
+	noIssuer := valid
+	noIssuer.Issuer = ""
+	_, err = newHandler(noIssuer, cookies, newFakeStore(), nil, logx.NewNoopLogger())
+	assert.Error(t, err)
+
+	relative := valid
+	relative.RedirectURL = "/auth/callback"
+	_, err = newHandler(relative, cookies, newFakeStore(), nil, logx.NewNoopLogger())
+	assert.Error(t, err)
+
+	_, err = newHandler(valid, session.Config{CookieName: "__Host-s"}, newFakeStore(), nil, logx.NewNoopLogger())
+	assert.Error(t, err, "__Host- cookies must be Secure")
+}
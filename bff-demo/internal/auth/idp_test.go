@@ -0,0 +1,276 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/bff-demo/internal/session"
+)
+
+const (
+	testClientID     = "bff-demo"
+	testClientSecret = "secret"
+	testRedirectURL  = "https://app.example/auth/callback"
+	testOrigin       = "https://app.example"
+)
+
+// authorization is a code the fake provider handed out
+type authorization struct {
+	nonce     string
+	challenge string
+}
+
+// fakeIDP is an OpenID Connect provider with just enough of the protocol for
+// the handler: discovery, keys, the token endpoint for both grants, userinfo
+// and a logout endpoint. Its tokens are numbered in the order it issues them.
+type fakeIDP struct {
+	t      *testing.T
+	srv    *httptest.Server
+	key    *rsa.PrivateKey
+	signer jose.Signer
+
+	mu    sync.Mutex
+	codes map[string]authorization
+	// refreshTokens holds the refresh tokens that still work; each works once
+	refreshTokens map[string]bool
+	accessToken   string
+	issued        int
+	refreshes     int
+	// expiresIn is the lifetime of the access tokens issued from now on
+	expiresIn time.Duration
+	// refuse makes the token endpoint refuse refresh tokens
+	refuse bool
+	// nonce, when set, replaces the nonce of the ID tokens issued
+	nonce string
+}
+
+func newFakeIDP(t *testing.T) *fakeIDP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "test"))
+	require.NoError(t, err)
+
+	idp := &fakeIDP{
+		t:             t,
+		key:           key,
+		signer:        signer,
+		codes:         make(map[string]authorization),
+		refreshTokens: make(map[string]bool),
+		expiresIn:     time.Hour,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("GET /keys", idp.keys)
+	mux.HandleFunc("POST /token", idp.token)
+	mux.HandleFunc("GET /userinfo", idp.userinfo)
+	idp.srv = httptest.NewServer(mux)
+	t.Cleanup(idp.srv.Close)
+	return idp
+}
+
+// authorize hands out a code for the login the authorization URL started, as
+// the provider does once the user has signed in
+func (f *fakeIDP) authorize(authURL string) (code, state string) {
+	f.t.Helper()
+	req, err := http.NewRequest(http.MethodGet, authURL, nil)
+	require.NoError(f.t, err)
+	q := req.URL.Query()
+	require.Equal(f.t, "S256", q.Get("code_challenge_method"))
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	code = fmt.Sprintf("code-%d", len(f.codes)+1)
+	f.codes[code] = authorization{nonce: q.Get("nonce"), challenge: q.Get("code_challenge")}
+	return code, q.Get("state")
+}
+
+func (f *fakeIDP) discovery(w http.ResponseWriter, r *http.Request) {
+	url := f.srv.URL
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                url,
+		"authorization_endpoint":                url + "/authorize",
+		"token_endpoint":                        url + "/token",
+		"jwks_uri":                              url + "/keys",
+		"userinfo_endpoint":                     url + "/userinfo",
+		"end_session_endpoint":                  url + "/logout",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (f *fakeIDP) keys(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: &f.key.PublicKey, KeyID: "test", Algorithm: "RS256", Use: "sig"},
+	}})
+}
+
+func (f *fakeIDP) token(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		id, secret = r.FormValue("client_id"), r.FormValue("client_secret")
+	}
+	if id != testClientID || secret != testClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.FormValue("grant_type") {
+	case "authorization_code":
+		auth, ok := f.codes[r.FormValue("code")]
+		delete(f.codes, r.FormValue("code"))
+		sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+		if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != auth.challenge || r.FormValue("redirect_uri") != testRedirectURL {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		writeJSON(w, http.StatusOK, f.issue(auth.nonce))
+	case "refresh_token":
+		rt := r.FormValue("refresh_token")
+		if f.refuse || !f.refreshTokens[rt] {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		delete(f.refreshTokens, rt)
+		f.refreshes++
+		writeJSON(w, http.StatusOK, f.issue(""))
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+	}
+}
+
+// issue returns a token response with new tokens; f.mu is held
+func (f *fakeIDP) issue(nonce string) map[string]any {
+	f.issued++
+	if f.nonce != "" {
+		nonce = f.nonce
+	}
+	now := time.Now()
+	claims := map[string]any{
+		"iss":   f.srv.URL,
+		"sub":   "ada",
+		"aud":   testClientID,
+		"exp":   jwt.NewNumericDate(now.Add(time.Hour)),
+		"iat":   jwt.NewNumericDate(now),
+		"name":  "Ada Lovelace",
+		"email": "ada@example.com",
+	}
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+	idToken, err := jwt.Signed(f.signer).Claims(claims).Serialize()
+	require.NoError(f.t, err)
+
+	f.accessToken = fmt.Sprintf("at-%d", f.issued)
+	rt := fmt.Sprintf("rt-%d", f.issued)
+	f.refreshTokens[rt] = true
+	return map[string]any{
+		"access_token":  f.accessToken,
+		"token_type":    "Bearer",
+		"expires_in":    int(f.expiresIn.Seconds()),
+		"refresh_token": rt,
+		"id_token":      idToken,
+	}
+}
+
+func (f *fakeIDP) userinfo(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Header.Get("Authorization") != "Bearer "+f.accessToken {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"sub": "ada", "email": "ada@example.com", "locale": "en-GB"})
+}
+
+func (f *fakeIDP) refreshCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.refreshes
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// fakeStore implements session.Store in a map
+type fakeStore struct {
+	mu       sync.Mutex
+	sessions map[string]session.Session
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{sessions: make(map[string]session.Session)}
+}
+
+func (s *fakeStore) Get(ctx context.Context, id string) (*session.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return nil, session.ErrNotFound
+	}
+	return &sess, nil
+}
+
+func (s *fakeStore) Save(ctx context.Context, sess *session.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[sess.ID] = *sess
+	return nil
+}
+
+func (s *fakeStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+	return nil
+}
+
+// only returns the one session in the store
+func (s *fakeStore) only(t *testing.T) session.Session {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	require.Len(t, s.sessions, 1)
+	for _, sess := range s.sessions {
+		return sess
+	}
+	return session.Session{}
+}
+
+// cookie returns the cookie called name a response sets
+func cookie(res *http.Response, name string) *http.Cookie {
+	for _, c := range res.Cookies() {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// queryParam returns a parameter of a URL, failing the test on a bad URL
+func queryParam(t *testing.T, rawURL, name string) string {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	require.NoError(t, err)
+	return req.URL.Query().Get(name)
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// errTooManyLogins is returned when MaxPendingLogins logins are in progress
+var errTooManyLogins = errors.New("too many logins in progress")
+
+// login is a login in progress, between the redirect to the provider and
+// the callback
+type login struct {
+	nonce string
+	// verifier is the PKCE code verifier; the provider only saw its hash
+	verifier string
+	returnTo string
+	expires  time.Time
+}
+
+// logins holds the logins in progress by state. The state is also set in a
+// cookie of the browser that started the login, and the callback must come
+// with both; a callback from another browser is refused, so an attacker
+// cannot sign a victim in to the attacker's account.
+type logins struct {
+	ttl time.Duration
+	max int
+	now func() time.Time
+
+	mu      sync.Mutex
+	pending map[string]login
+}
+
+func newLogins(ttl time.Duration, max int) *logins {
+	return &logins{ttl: ttl, max: max, now: time.Now, pending: make(map[string]login)}
+}
+
+// add records a login under state
+func (l *logins) add(state string, lg login) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if len(l.pending) >= l.max {
+		for s, p := range l.pending {
+			if now.After(p.expires) {
+				delete(l.pending, s)
+			}
+		}
+		if len(l.pending) >= l.max {
+			return errTooManyLogins
+		}
+	}
+	lg.expires = now.Add(l.ttl)
+	l.pending[state] = lg
+	return nil
+}
+
+// take removes and returns the login under state; each state is good for
+// one callback
+func (l *logins) take(state string) (login, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lg, ok := l.pending[state]
+	if !ok {
+		return login{}, false
+	}
+	delete(l.pending, state)
+	if l.now().After(lg.expires) {
+		return login{}, false
+	}
+	return lg, true
+}
@@ -0,0 +1,59 @@
+package auth
+
+import "github.com/gostratum/metricsx"
+
+// Results of a login
+const (
+	loginOK = "ok"
+	// loginDenied: the user or the provider refused
+	loginDenied = "denied"
+	// loginInvalidState: the callback did not match a login this browser started
+	loginInvalidState = "invalid_state"
+	// loginFailed: the code exchange or the ID token failed
+	loginFailed = "failed"
+)
+
+// Results of a token refresh
+const (
+	refreshOK = "ok"
+	// refreshRejected: the provider refused the refresh token, ending the session
+	refreshRejected = "rejected"
+	// refreshFailed: the provider could not be reached
+	refreshFailed = "failed"
+)
+
+// Reasons a request is rejected
+const (
+	rejectUnauthenticated = "unauthenticated"
+	rejectOrigin          = "origin"
+	rejectCSRF            = "csrf"
+)
+
+// recorder records logins, refreshes and rejected requests
+type recorder struct {
+	logins    counter
+	refreshes counter
+	rejected  counter
+}
+
+func newRecorder(metrics metricsx.Metrics) *recorder {
+	return &recorder{
+		logins: metrics.Counter("auth_logins_total",
+			metricsx.WithHelp("Completed login callbacks, by result"),
+			metricsx.WithLabels("result"),
+		),
+		refreshes: metrics.Counter("auth_token_refreshes_total",
+			metricsx.WithHelp("Access token refreshes, by result"),
+			metricsx.WithLabels("result"),
+		),
+		rejected: metrics.Counter("auth_requests_rejected_total",
+			metricsx.WithHelp("Requests rejected before reaching a handler, by reason"),
+			metricsx.WithLabels("reason"),
+		),
+	}
+}
+
+// counter is the part of metricsx.Counter the handler uses
+type counter interface {
+	Inc(labels ...string)
+}
@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"golang.org/x/oauth2"
+
+	"github.com/gostratum/examples/bff-demo/internal/session"
+)
+
+// CSRFHeader carries the session's CSRF token on requests that change something
+const CSRFHeader = "X-CSRF-Token"
+
+// sessionKey is the gin context key of the request's session
+const sessionKey = "auth.session"
+
+// errSessionEnded means the session can no longer get valid tokens
+var errSessionEnded = errors.New("session ended")
+
+// SessionFrom returns the session of a request that passed RequireSession
+func SessionFrom(c *gin.Context) *session.Session {
+	return c.MustGet(sessionKey).(*session.Session)
+}
+
+// RequireSession answers 401 to requests without a live session, and makes
+// the session available to the handlers after it through SessionFrom
+func (h *Handler) RequireSession(c *gin.Context) {
+	id, err := c.Cookie(h.cookies.CookieName)
+	if err != nil {
+		h.reject(c, http.StatusUnauthorized, rejectUnauthenticated, "UNAUTHENTICATED", "sign in first")
+		return
+	}
+	s, err := h.store.Get(c.Request.Context(), id)
+	if errors.Is(err, session.ErrNotFound) {
+		h.clearCookie(c, h.cookies.CookieName)
+		h.reject(c, http.StatusUnauthorized, rejectUnauthenticated, "UNAUTHENTICATED", "session expired, sign in again")
+		return
+	}
+	if err != nil {
+		h.unavailable(c, err)
+		return
+	}
+	c.Set(sessionKey, s)
+	c.Next()
+}
+
+// RequireCSRF guards requests that change something, behind RequireSession.
+// A page on another site can make the browser send them with the session
+// cookie, but it cannot give them this origin's Origin header, nor read the
+// CSRF token to put in X-CSRF-Token. Either check alone would do in current
+// browsers; together they hold if one of them is ever bypassed.
+func (h *Handler) RequireCSRF(c *gin.Context) {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		c.Next()
+		return
+	}
+	if !h.sameOrigin(c.Request) {
+		h.reject(c, http.StatusForbidden, rejectOrigin, "FORBIDDEN", "cross-origin request")
+		return
+	}
+	token := c.GetHeader(CSRFHeader)
+	if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(SessionFrom(c).CSRFToken)) != 1 {
+		h.reject(c, http.StatusForbidden, rejectCSRF, "CSRF_TOKEN_INVALID", "missing or invalid "+CSRFHeader+" header")
+		return
+	}
+	c.Next()
+}
+
+// sameOrigin reports whether a request comes from a page of this origin, as
+// far as the browser says. Requests that say nothing, from clients that are
+// not browsers, are left to the CSRF token.
+func (h *Handler) sameOrigin(r *http.Request) bool {
+	if origin := r.Header.Get("Origin"); origin != "" {
+		return origin == h.origin
+	}
+	if site := r.Header.Get("Sec-Fetch-Site"); site != "" {
+		return site == "same-origin"
+	}
+	return true
+}
+
+// RefreshTokens refreshes the session's access token when it is about to
+// expire, behind RequireSession. When the provider refuses the refresh token,
+// because it was revoked or the user's session there ended, the session ends
+// here too and the request gets 401.
+func (h *Handler) RefreshTokens(c *gin.Context) {
+	s := SessionFrom(c)
+	if !h.expiring(s) {
+		c.Next()
+		return
+	}
+
+	// The refresh outlives a request that gives up, as other requests of the
+	// page may be waiting for it; the provider client's timeout bounds it
+	ctx := context.WithoutCancel(c.Request.Context())
+	v, err, _ := h.refreshes.Do(s.ID, func() (any, error) {
+		return h.refresh(ctx, s.ID)
+	})
+	switch {
+	case errors.Is(err, errSessionEnded):
+		h.clearCookie(c, h.cookies.CookieName)
+		h.reject(c, http.StatusUnauthorized, rejectUnauthenticated, "UNAUTHENTICATED", "session expired, sign in again")
+		return
+	case err != nil:
+		h.log.Error("token refresh failed", logx.Err(err))
+		responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "identity provider unavailable", nil)
+		c.Abort()
+		return
+	}
+	// Requests that shared the refresh share the session; handlers only read it
+	c.Set(sessionKey, v.(*session.Session))
+	c.Next()
+}
+
+// expiring reports whether the access token of s expires within RefreshBefore.
+// Tokens without an expiry never do.
+func (h *Handler) expiring(s *session.Session) bool {
+	return !s.TokenExpiry.IsZero() && h.now().Add(h.cfg.RefreshBefore).After(s.TokenExpiry)
+}
+
+// refresh gets new tokens for the session with id and saves them
+func (h *Handler) refresh(ctx context.Context, id string) (*session.Session, error) {
+	// Read the session again: another instance may have refreshed it since
+	s, err := h.store.Get(ctx, id)
+	if errors.Is(err, session.ErrNotFound) {
+		return nil, errSessionEnded
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !h.expiring(s) {
+		return s, nil
+	}
+	if s.RefreshToken == "" {
+		// Without a refresh token the session lasts as long as its access token
+		if h.now().Before(s.TokenExpiry) {
+			return s, nil
+		}
+		_ = h.store.Delete(ctx, id)
+		return nil, errSessionEnded
+	}
+
+	p, err := h.idp.get(ctx)
+	if err != nil {
+		h.rec.refreshes.Inc(refreshFailed)
+		return nil, err
+	}
+	tok, err := p.oauth2.TokenSource(h.idp.context(ctx), &oauth2.Token{RefreshToken: s.RefreshToken}).Token()
+	if err != nil {
+		var retrieve *oauth2.RetrieveError
+		if errors.As(err, &retrieve) && retrieve.Response != nil && retrieve.Response.StatusCode < http.StatusInternalServerError {
+			h.rec.refreshes.Inc(refreshRejected)
+			h.log.Info("refresh token rejected, ending session", logx.String("subject", s.Subject), logx.String("error", retrieve.ErrorCode))
+			_ = h.store.Delete(ctx, id)
+			return nil, errSessionEnded
+		}
+		h.rec.refreshes.Inc(refreshFailed)
+		return nil, fmt.Errorf("failed to refresh tokens: %w", err)
+	}
+
+	s.AccessToken = tok.AccessToken
+	s.TokenExpiry = tok.Expiry
+	// Providers that rotate refresh tokens send a new one with every
+	// refresh; the old one stops working, so it must be replaced
+	if tok.RefreshToken != "" {
+		s.RefreshToken = tok.RefreshToken
+	}
+	// Keep the newest ID token as the hint for logout
+	if raw, ok := tok.Extra("id_token").(string); ok && raw != "" {
+		if _, err := p.verifier.Verify(ctx, raw); err == nil {
+			s.IDToken = raw
+		}
+	}
+	if err := h.store.Save(ctx, s); err != nil {
+		return nil, err
+	}
+	h.rec.refreshes.Inc(refreshOK)
+	return s, nil
+}
+
+// reject answers a request the middleware turns away
+func (h *Handler) reject(c *gin.Context, status int, reason, code, message string) {
+	h.rec.rejected.Inc(reason)
+	responsex.Error(c, status, code, message, nil)
+	c.Abort()
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// provider is the identity provider as read from its discovery document
+type provider struct {
+	oidc     *oidc.Provider
+	oauth2   oauth2.Config
+	verifier *oidc.IDTokenVerifier
+	// endSession is the RP-initiated logout endpoint; empty when the
+	// provider has none
+	endSession string
+}
+
+// discovery reads the discovery document on first use rather than at
+// startup, so the server starts, and serves its health checks, while the
+// provider is still starting. A failed discovery is retried by the next login.
+type discovery struct {
+	cfg    Config
+	client *http.Client
+
+	mu       sync.Mutex
+	provider *provider
+}
+
+// get returns the provider, reading its discovery document if need be
+func (d *discovery) get(ctx context.Context) (*provider, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.provider != nil {
+		return d.provider, nil
+	}
+
+	p, err := oidc.NewProvider(d.context(ctx), d.cfg.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover %s: %w", d.cfg.Issuer, err)
+	}
+	var claims struct {
+		EndSession string `json:"end_session_endpoint"`
+	}
+	if err := p.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to read discovery document: %w", err)
+	}
+
+	d.provider = &provider{
+		oidc: p,
+		oauth2: oauth2.Config{
+			ClientID:     d.cfg.ClientID,
+			ClientSecret: d.cfg.ClientSecret,
+			Endpoint:     p.Endpoint(),
+			RedirectURL:  d.cfg.RedirectURL,
+			Scopes:       d.cfg.Scopes,
+		},
+		verifier:   p.Verifier(&oidc.Config{ClientID: d.cfg.ClientID}),
+		endSession: claims.EndSession,
+	}
+	return d.provider, nil
+}
+
+// context makes the oidc and oauth2 packages send their requests with the
+// client, which bounds them with the configured timeout
+func (d *discovery) context(ctx context.Context) context.Context {
+	ctx = oidc.ClientContext(ctx, d.client)
+	return context.WithValue(ctx, oauth2.HTTPClient, d.client)
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+
+func TestNewNote(t *testing.T) {
+	n, err := NewNote("n-1", "sub-1", "  buy milk\n", now)
+	require.NoError(t, err)
+	assert.Equal(t, &Note{ID: "n-1", Owner: "sub-1", Text: "buy milk", CreatedAt: now}, n)
+
+	_, err = NewNote("n-1", "sub-1", strings.Repeat("é", MaxNoteLength), now)
+	assert.NoError(t, err, "the limit is in characters, not bytes")
+
+	for name, tc := range map[string]struct{ owner, text string }{
+		"no owner": {"", "buy milk"},
+		"no text":  {"sub-1", " \t"},
+		"too long": {"sub-1", strings.Repeat("a", MaxNoteLength+1)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewNote("n-1", tc.owner, tc.text, now)
+			assert.ErrorIs(t, err, ErrInvalidInput)
+		})
+	}
+}
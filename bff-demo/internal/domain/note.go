@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxNoteLength is the longest note text, in characters
+const MaxNoteLength = 500
+
+// Note is a short text a signed-in user keeps for themselves
+// This is a pure domain model without infrastructure concerns
+type Note struct {
+	ID string
+	// Owner is the OIDC subject of the user the note belongs to
+	Owner     string
+	Text      string
+	CreatedAt time.Time
+}
+
+// NewNote creates a validated note; the text is trimmed
+func NewNote(id, owner, text string, now time.Time) (*Note, error) {
+	n := &Note{
+		ID:        id,
+		Owner:     owner,
+		Text:      strings.TrimSpace(text),
+		CreatedAt: now,
+	}
+	if err := n.Validate(); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// Validate performs basic validation on note fields
+func (n *Note) Validate() error {
+	if n.Owner == "" {
+		return fmt.Errorf("%w: owner is required", ErrInvalidInput)
+	}
+	if n.Text == "" {
+		return fmt.Errorf("%w: text is required", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(n.Text) > MaxNoteLength {
+		return fmt.Errorf("%w: text must be at most %d characters", ErrInvalidInput, MaxNoteLength)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+// Package session keeps the sessions of signed-in browsers on the server. The
+// browser holds only a random session ID in a cookie; the tokens from the
+// identity provider stay here, out of reach of any script on the page.
+package session
+
+import "time"
+
+// Config holds the session cookie and lifetime settings
+type Config struct {
+	// CookieName is the session cookie. The "__Host-" prefix makes browsers
+	// reject it unless it is Secure, has Path=/ and no Domain, so a sibling
+	// subdomain cannot set or overwrite it.
+	CookieName string `mapstructure:"cookie_name" default:"__Host-bff_session"`
+	// Secure marks cookies Secure. Browsers accept Secure cookies from
+	// http://localhost, so it only needs turning off to serve plain HTTP on
+	// another host, which a real deployment never does.
+	Secure bool `mapstructure:"secure" default:"true"`
+	// IdleTimeout ends a session that has not been used for this long
+	IdleTimeout time.Duration `mapstructure:"idle_timeout" default:"30m"`
+	// AbsoluteTimeout ends a session this long after sign-in, however active
+	AbsoluteTimeout time.Duration `mapstructure:"absolute_timeout" default:"12h"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "session"
+}
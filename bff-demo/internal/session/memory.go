@@ -0,0 +1,101 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gostratum/core/configx"
+)
+
+// sweepInterval is how often Save drops expired sessions
+const sweepInterval = time.Minute
+
+// MemoryStore keeps sessions in memory. Sessions are lost on restart, which
+// signs everyone out; that is fine for a single instance in development.
+type MemoryStore struct {
+	cfg Config
+	now func() time.Time
+
+	mu        sync.Mutex
+	sessions  map[string]Session
+	lastSweep time.Time
+}
+
+// NewMemoryStore creates an empty store from the session config section
+func NewMemoryStore(loader configx.Loader) (Store, error) {
+	var cfg Config
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load session config: %w", err)
+	}
+	if cfg.IdleTimeout <= 0 || cfg.AbsoluteTimeout <= 0 {
+		return nil, fmt.Errorf("session.idle_timeout and session.absolute_timeout must be positive")
+	}
+	return newMemoryStore(cfg), nil
+}
+
+func newMemoryStore(cfg Config) *MemoryStore {
+	return &MemoryStore{cfg: cfg, now: time.Now, sessions: make(map[string]Session)}
+}
+
+// Get implements Store
+func (m *MemoryStore) Get(ctx context.Context, id string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	now := m.now()
+	if m.expired(s, now) {
+		delete(m.sessions, id)
+		return nil, ErrNotFound
+	}
+	s.LastSeen = now
+	m.sessions[id] = s
+	return &s, nil
+}
+
+// Save implements Store
+func (m *MemoryStore) Save(ctx context.Context, s *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	if now.Sub(m.lastSweep) >= sweepInterval {
+		m.sweep(now)
+	}
+	m.sessions[s.ID] = *s
+	return nil
+}
+
+// Delete implements Store
+func (m *MemoryStore) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
+
+// Len returns the number of sessions held, expired ones included until the
+// next sweep
+func (m *MemoryStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sessions)
+}
+
+// sweep drops expired sessions, so browsers that never come back do not keep
+// theirs in memory
+func (m *MemoryStore) sweep(now time.Time) {
+	for id, s := range m.sessions {
+		if m.expired(s, now) {
+			delete(m.sessions, id)
+		}
+	}
+	m.lastSweep = now
+}
+
+func (m *MemoryStore) expired(s Session, now time.Time) bool {
+	return now.Sub(s.LastSeen) >= m.cfg.IdleTimeout || now.Sub(s.CreatedAt) >= m.cfg.AbsoluteTimeout
+}
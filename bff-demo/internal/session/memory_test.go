@@ -0,0 +1,95 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testClock struct{ t time.Time }
+
+func (c *testClock) now() time.Time          { return c.t }
+func (c *testClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestStore() (*MemoryStore, *testClock) {
+	clock := &testClock{t: time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)}
+	m := newMemoryStore(Config{IdleTimeout: 30 * time.Minute, AbsoluteTimeout: 2 * time.Hour})
+	m.now = clock.now
+	return m, clock
+}
+
+func TestMemoryStore(t *testing.T) {
+	m, clock := newTestStore()
+	ctx := context.Background()
+
+	_, err := m.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, m.Save(ctx, &Session{ID: "s1", Subject: "ada", CreatedAt: clock.t, LastSeen: clock.t}))
+	clock.advance(time.Minute)
+	s, err := m.Get(ctx, "s1")
+	require.NoError(t, err)
+	assert.Equal(t, "ada", s.Subject)
+	assert.Equal(t, clock.t, s.LastSeen, "Get counts as activity")
+
+	s.Subject = "changed"
+	s, err = m.Get(ctx, "s1")
+	require.NoError(t, err)
+	assert.Equal(t, "ada", s.Subject, "callers get copies")
+
+	require.NoError(t, m.Delete(ctx, "s1"))
+	require.NoError(t, m.Delete(ctx, "s1"))
+	_, err = m.Get(ctx, "s1")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryStoreIdleTimeout(t *testing.T) {
+	m, clock := newTestStore()
+	ctx := context.Background()
+	require.NoError(t, m.Save(ctx, &Session{ID: "s1", CreatedAt: clock.t, LastSeen: clock.t}))
+
+	// Used every 20 minutes, the session outlives its idle timeout...
+	for range 4 {
+		clock.advance(20 * time.Minute)
+		_, err := m.Get(ctx, "s1")
+		require.NoError(t, err)
+	}
+	// ...until it is left alone for 30
+	clock.advance(30 * time.Minute)
+	_, err := m.Get(ctx, "s1")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryStoreAbsoluteTimeout(t *testing.T) {
+	m, clock := newTestStore()
+	ctx := context.Background()
+	require.NoError(t, m.Save(ctx, &Session{ID: "s1", CreatedAt: clock.t, LastSeen: clock.t}))
+
+	for range 4 {
+		clock.advance(25 * time.Minute)
+		_, err := m.Get(ctx, "s1")
+		require.NoError(t, err)
+	}
+	clock.advance(20 * time.Minute)
+	_, err := m.Get(ctx, "s1")
+	assert.ErrorIs(t, err, ErrNotFound, "ends two hours after sign-in, however active")
+}
+
+func TestMemoryStoreSweepsExpiredSessions(t *testing.T) {
+	m, clock := newTestStore()
+	ctx := context.Background()
+	require.NoError(t, m.Save(ctx, &Session{ID: "abandoned", CreatedAt: clock.t, LastSeen: clock.t}))
+
+	clock.advance(time.Hour)
+	require.NoError(t, m.Save(ctx, &Session{ID: "new", CreatedAt: clock.t, LastSeen: clock.t}))
+	assert.Equal(t, 1, m.Len())
+}
+
+func TestNewToken(t *testing.T) {
+	a, b := NewToken(), NewToken()
+	assert.Len(t, a, 43)
+	assert.NotEqual(t, a, b)
+}
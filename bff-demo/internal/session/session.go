@@ -0,0 +1,57 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+)
+
+// ErrNotFound indicates there is no session with an ID, or it has expired
+var ErrNotFound = errors.New("session not found")
+
+// Session is a signed-in browser
+type Session struct {
+	// ID is the value of the session cookie
+	ID string
+	// Subject, Name and Email are claims of the ID token
+	Subject string
+	Name    string
+	Email   string
+
+	// IDToken is kept as the id_token_hint of a logout at the provider
+	IDToken      string
+	AccessToken  string
+	RefreshToken string
+	// TokenExpiry is when AccessToken expires
+	TokenExpiry time.Time
+
+	// CSRFToken must accompany every request that changes something
+	CSRFToken string
+
+	CreatedAt time.Time
+	LastSeen  time.Time
+}
+
+// Store keeps sessions. The in-memory store serves one instance; with several
+// replicas behind a load balancer, implement it over a shared store such as
+// Redis so that any of them can serve any browser.
+type Store interface {
+	// Get returns the session with id and counts as activity, extending its
+	// idle timeout. Unknown and expired sessions are ErrNotFound.
+	Get(ctx context.Context, id string) (*Session, error)
+	// Save creates or replaces a session
+	Save(ctx context.Context, s *Session) error
+	// Delete removes a session; deleting an unknown one is not an error
+	Delete(ctx context.Context, id string) error
+}
+
+// NewToken returns 32 random bytes, base64url-encoded. It is used for session
+// IDs, CSRF tokens and the state and nonce of a login.
+func NewToken() string {
+	b := make([]byte, 32)
+	// crypto/rand.Read never returns an error
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
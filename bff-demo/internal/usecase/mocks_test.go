@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gostratum/examples/bff-demo/internal/domain"
+)
+
+// MockNoteRepository implements NoteRepository for testing
+type MockNoteRepository struct {
+	notes map[string]*domain.Note
+	err   error
+	// calls counts the calls of each method
+	calls map[string]int
+}
+
+func newMockNotes() *MockNoteRepository {
+	return &MockNoteRepository{notes: make(map[string]*domain.Note), calls: make(map[string]int)}
+}
+
+func (m *MockNoteRepository) Save(ctx context.Context, n *domain.Note) error {
+	m.calls["Save"]++
+	if m.err != nil {
+		return m.err
+	}
+	m.notes[n.ID] = n
+	return nil
+}
+
+func (m *MockNoteRepository) FindByID(ctx context.Context, id string) (*domain.Note, error) {
+	m.calls["FindByID"]++
+	if m.err != nil {
+		return nil, m.err
+	}
+	n, ok := m.notes[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return n, nil
+}
+
+func (m *MockNoteRepository) ListByOwner(ctx context.Context, owner string, limit int) ([]*domain.Note, error) {
+	m.calls["ListByOwner"]++
+	if m.err != nil {
+		return nil, m.err
+	}
+	var notes []*domain.Note
+	for _, n := range m.notes {
+		if n.Owner == owner {
+			notes = append(notes, n)
+		}
+	}
+	slices.SortFunc(notes, func(a, b *domain.Note) int { return strings.Compare(b.ID, a.ID) })
+	return notes[:min(limit, len(notes))], nil
+}
+
+func (m *MockNoteRepository) Delete(ctx context.Context, id string) error {
+	m.calls["Delete"]++
+	if m.err != nil {
+		return m.err
+	}
+	delete(m.notes, id)
+	return nil
+}
+
+// sequentialIDs replaces the clock and ID generator of a service with a
+// fixed time and IDs that sort in creation order
+func sequentialIDs(prefix string) (func() time.Time, func() string) {
+	next := 0
+	return func() time.Time { return time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC) },
+		func() string {
+			next++
+			return fmt.Sprintf("%s-%03d", prefix, next)
+		}
+}
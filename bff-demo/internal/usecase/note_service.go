@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gostratum/examples/bff-demo/internal/domain"
+)
+
+// maxNotes is how many notes List returns
+const maxNotes = 100
+
+// NoteService handles note business logic. Every method takes the owner, the
+// subject of the signed-in user, and only ever touches that owner's notes.
+type NoteService struct {
+	repo  NoteRepository
+	now   func() time.Time
+	newID func() string
+}
+
+// NewNoteService creates a new note service with repository injection
+func NewNoteService(repo NoteRepository) *NoteService {
+	return &NoteService{repo: repo, now: time.Now, newID: newID}
+}
+
+// Add creates a note for owner
+func (s *NoteService) Add(ctx context.Context, owner, text string) (*domain.Note, error) {
+	n, err := domain.NewNote(s.newID(), owner, text, s.now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, n); err != nil {
+		return nil, translateError(err)
+	}
+	return n, nil
+}
+
+// List returns the newest notes of owner
+func (s *NoteService) List(ctx context.Context, owner string) ([]*domain.Note, error) {
+	notes, err := s.repo.ListByOwner(ctx, owner, maxNotes)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return notes, nil
+}
+
+// Delete deletes a note of owner. Notes of other owners are reported as not
+// found, so their IDs cannot be probed.
+func (s *NoteService) Delete(ctx context.Context, owner, id string) error {
+	n, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return translateError(err)
+	}
+	if n.Owner != owner {
+		return ErrNotFound
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// newID returns a version 7 UUID, which sorts by creation time
+func newID() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// translateError converts repository errors to usecase errors
+func translateError(err error) error {
+	for _, passthrough := range []error{domain.ErrNotFound, domain.ErrInvalidInput, domain.ErrConflict, context.DeadlineExceeded, context.Canceled} {
+		if errors.Is(err, passthrough) {
+			return err
+		}
+	}
+
+	// All other errors are infrastructure/availability issues
+	return fmt.Errorf("%w: %v", ErrUnavailable, err)
+}
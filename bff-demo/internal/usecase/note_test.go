@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestNoteService() (*NoteService, *MockNoteRepository) {
+	repo := newMockNotes()
+	svc := NewNoteService(repo)
+	svc.now, svc.newID = sequentialIDs("n")
+	return svc, repo
+}
+
+func TestNoteService_AddAndList(t *testing.T) {
+	svc, repo := newTestNoteService()
+	ctx := context.Background()
+
+	for _, text := range []string{"first", "second"} {
+		_, err := svc.Add(ctx, "ada", text)
+		require.NoError(t, err)
+	}
+	_, err := svc.Add(ctx, "grace", "not ada's")
+	require.NoError(t, err)
+
+	notes, err := svc.List(ctx, "ada")
+	require.NoError(t, err)
+	require.Len(t, notes, 2)
+	assert.Equal(t, "second", notes[0].Text, "newest first")
+	assert.Equal(t, "first", notes[1].Text)
+
+	_, err = svc.Add(ctx, "ada", " ")
+	assert.ErrorIs(t, err, ErrInvalid)
+	assert.Equal(t, 3, repo.calls["Save"], "invalid notes are not saved")
+}
+
+func TestNoteService_Delete(t *testing.T) {
+	svc, repo := newTestNoteService()
+	ctx := context.Background()
+	n, err := svc.Add(ctx, "ada", "buy milk")
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, svc.Delete(ctx, "grace", n.ID), ErrNotFound, "another owner's note is not found")
+	assert.Contains(t, repo.notes, n.ID)
+
+	require.NoError(t, svc.Delete(ctx, "ada", n.ID))
+	assert.NotContains(t, repo.notes, n.ID)
+	assert.ErrorIs(t, svc.Delete(ctx, "ada", n.ID), ErrNotFound)
+}
+
+func TestNoteService_Errors(t *testing.T) {
+	svc, repo := newTestNoteService()
+	ctx := context.Background()
+
+	repo.err = errors.New("disk on fire")
+	_, err := svc.Add(ctx, "ada", "buy milk")
+	assert.ErrorIs(t, err, ErrUnavailable)
+	_, err = svc.List(ctx, "ada")
+	assert.ErrorIs(t, err, ErrUnavailable)
+
+	repo.err = context.DeadlineExceeded
+	_, err = svc.List(ctx, "ada")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotErrorIs(t, err, ErrUnavailable)
+}
@@ -0,0 +1,17 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/gostratum/examples/bff-demo/internal/domain"
+)
+
+// NoteRepository defines the interface for note data operations
+// This interface is owned by the use case layer (dependency inversion principle)
+type NoteRepository interface {
+	Save(ctx context.Context, n *domain.Note) error
+	FindByID(ctx context.Context, id string) (*domain.Note, error)
+	// ListByOwner returns up to limit notes of owner, newest first
+	ListByOwner(ctx context.Context, owner string, limit int) ([]*domain.Note, error)
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,156 @@
+// The page holds no token. It learns who is signed in from /auth/session,
+// and the browser sends the session cookie with every API call on its own.
+// Calls that change something carry the CSRF token from /auth/session.
+"use strict";
+
+let csrfToken = "";
+
+const loginErrors = {
+  denied: "Sign-in was cancelled.",
+  invalid_state: "Sign-in took too long or was started in another tab. Try again.",
+  failed: "Sign-in failed. Try again.",
+  unavailable: "The identity provider is unavailable. Try again later.",
+};
+
+async function api(method, path, body) {
+  const headers = { "Accept": "application/json" };
+  if (method !== "GET") {
+    headers["X-CSRF-Token"] = csrfToken;
+  }
+  if (body !== undefined) {
+    headers["Content-Type"] = "application/json";
+  }
+  const res = await fetch(path, {
+    method,
+    headers,
+    body: body === undefined ? undefined : JSON.stringify(body),
+    credentials: "same-origin",
+  });
+  if (res.status === 401) {
+    // The session ended: expired, or the provider refused to refresh it
+    showSignedOut();
+    throw new Error("Your session has ended. Sign in again.");
+  }
+  if (res.status === 204) {
+    return null;
+  }
+  const envelope = await res.json();
+  if (!envelope.ok) {
+    throw new Error(envelope.error.message);
+  }
+  return envelope.data;
+}
+
+function showError(message) {
+  const el = document.getElementById("error");
+  el.textContent = message;
+  el.hidden = !message;
+}
+
+function showSignedOut() {
+  csrfToken = "";
+  document.getElementById("account").replaceChildren();
+  document.getElementById("signed-in").hidden = true;
+  document.getElementById("signed-out").hidden = false;
+}
+
+function showSignedIn(user) {
+  const logout = document.createElement("button");
+  logout.type = "button";
+  logout.textContent = "Sign out";
+  logout.addEventListener("click", signOut);
+
+  const name = document.createElement("span");
+  name.textContent = (user.name || user.email || user.sub) + " ";
+
+  document.getElementById("account").replaceChildren(name, logout);
+  document.getElementById("signed-out").hidden = true;
+  document.getElementById("signed-in").hidden = false;
+}
+
+async function signOut() {
+  try {
+    const { redirect_url } = await api("POST", "/auth/logout");
+    window.location.assign(redirect_url);
+  } catch (err) {
+    showError(err.message);
+  }
+}
+
+async function loadNotes() {
+  const notes = await api("GET", "/api/notes");
+  const items = notes.map((note) => {
+    const text = document.createElement("span");
+    text.textContent = note.text;
+
+    const remove = document.createElement("button");
+    remove.type = "button";
+    remove.textContent = "Delete";
+    remove.addEventListener("click", () => deleteNote(note.id));
+
+    const li = document.createElement("li");
+    li.append(text, remove);
+    return li;
+  });
+  document.getElementById("notes").replaceChildren(...items);
+}
+
+async function addNote(event) {
+  event.preventDefault();
+  const input = document.getElementById("note-text");
+  try {
+    await api("POST", "/api/notes", { text: input.value });
+    input.value = "";
+    showError("");
+    await loadNotes();
+  } catch (err) {
+    showError(err.message);
+  }
+}
+
+async function deleteNote(id) {
+  try {
+    await api("DELETE", "/api/notes/" + encodeURIComponent(id));
+    await loadNotes();
+  } catch (err) {
+    showError(err.message);
+  }
+}
+
+async function loadProfile() {
+  try {
+    const claims = await api("GET", "/api/profile");
+    document.getElementById("profile").textContent = JSON.stringify(claims, null, 2);
+  } catch (err) {
+    showError(err.message);
+  }
+}
+
+async function start() {
+  const params = new URLSearchParams(window.location.search);
+  const loginError = params.get("login_error");
+  if (loginError) {
+    showError(loginErrors[loginError] || loginErrors.failed);
+    history.replaceState(null, "", window.location.pathname);
+  }
+
+  document.getElementById("login").href =
+    "/auth/login?return_to=" + encodeURIComponent(window.location.pathname);
+  document.getElementById("add-note").addEventListener("submit", addNote);
+  document.getElementById("load-profile").addEventListener("click", loadProfile);
+
+  try {
+    const session = await api("GET", "/auth/session");
+    if (!session.authenticated) {
+      showSignedOut();
+      return;
+    }
+    csrfToken = session.csrf_token;
+    showSignedIn(session.user);
+    await loadNotes();
+  } catch (err) {
+    showError(err.message);
+  }
+}
+
+start();
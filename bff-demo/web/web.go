@@ -0,0 +1,23 @@
+// Package web holds the frontend: one page, its script and its styles,
+// embedded in the binary and served by the backend, so the page and the API
+// share an origin and the session cookie never has to cross sites.
+package web
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed static
+var files embed.FS
+
+// Static returns the frontend files; index.html is at the root and
+// everything it loads is under assets/
+func Static() fs.FS {
+	static, err := fs.Sub(files, "static")
+	if err != nil {
+		// The directory is embedded, so this cannot happen
+		panic(err)
+	}
+	return static
+}
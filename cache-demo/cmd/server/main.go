@@ -0,0 +1,54 @@
+package main
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/examples/cache-demo/internal/adapter/cached"
+	"github.com/gostratum/examples/cache-demo/internal/adapter/catalog"
+	httpAdapter "github.com/gostratum/examples/cache-demo/internal/adapter/http"
+	"github.com/gostratum/examples/cache-demo/internal/cache"
+	"github.com/gostratum/examples/cache-demo/internal/usecase"
+	"github.com/gostratum/examples/redact"
+	"github.com/gostratum/httpx"
+	"github.com/gostratum/metricsx"
+	"github.com/gostratum/tracingx"
+)
+
+func main() {
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// Observability: cache metrics on /metrics, spans for every cache operation
+		metricsx.Module(),
+		tracingx.Module(),
+
+		// HTTP server
+		httpx.Module(),
+
+		// Redis cache
+		cache.Module(),
+
+		// Provide dependencies
+		fx.Provide(
+			// The slow backend, and the cache in front of it
+			catalog.NewCatalog,
+			cached.NewProducts,
+
+			// Usecase services
+			usecase.NewProductService,
+
+			// HTTP handlers
+			httpAdapter.NewProductHandler,
+			httpAdapter.NewStatsHandler,
+		),
+
+		// Invoke setup functions
+		fx.Invoke(
+			httpAdapter.RegisterRoutes,
+		),
+	)
+
+	app.Run()
+}
@@ -0,0 +1,57 @@
+// Package cached puts the cache in front of the catalog: reads go through the
+// cache, and writes go to the catalog and drop the cached entry
+package cached
+
+import (
+	"context"
+
+	"github.com/gostratum/core/logx"
+
+	"github.com/gostratum/examples/cache-demo/internal/adapter/catalog"
+	"github.com/gostratum/examples/cache-demo/internal/cache"
+	"github.com/gostratum/examples/cache-demo/internal/domain"
+	"github.com/gostratum/examples/cache-demo/internal/usecase"
+)
+
+// Products is a usecase.ProductRepository that caches the products of another
+type Products struct {
+	next  usecase.ProductRepository
+	cache *cache.Cache
+	log   logx.Logger
+}
+
+// NewProducts puts the cache in front of the catalog
+func NewProducts(next *catalog.Catalog, c *cache.Cache, log logx.Logger) usecase.ProductRepository {
+	return newProducts(next, c, log)
+}
+
+func newProducts(next usecase.ProductRepository, c *cache.Cache, log logx.Logger) *Products {
+	return &Products{next: next, cache: c, log: log}
+}
+
+// Get implements usecase.ProductRepository. Products are kept for cache.ttl;
+// unknown IDs are not cached.
+func (p *Products) Get(ctx context.Context, id string) (domain.Product, error) {
+	return cache.Fetch(ctx, p.cache, productKey(id), 0, func(ctx context.Context) (domain.Product, error) {
+		return p.next.Get(ctx, id)
+	})
+}
+
+// UpdatePrice implements usecase.ProductRepository
+func (p *Products) UpdatePrice(ctx context.Context, id string, cents int64) (domain.Product, error) {
+	product, err := p.next.UpdatePrice(ctx, id, cents)
+	if err != nil {
+		return domain.Product{}, err
+	}
+
+	// Drop the old price, so the next read loads the new one
+	if err := p.cache.Delete(ctx, productKey(id)); err != nil {
+		// Readers see the old price until it expires
+		p.log.Warn("failed to drop repriced product from cache", logx.String("id", id), logx.Err(err))
+	}
+	return product, nil
+}
+
+func productKey(id string) string {
+	return "product:" + id
+}
@@ -0,0 +1,114 @@
+// Package catalog is the slow backend the cache sits in front of: an
+// in-memory product catalog that takes catalog.latency to answer, as a remote
+// service or a heavy query would
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gostratum/core/configx"
+
+	"github.com/gostratum/examples/cache-demo/internal/domain"
+)
+
+// Config sets how slow the catalog is
+type Config struct {
+	Latency time.Duration `mapstructure:"latency" default:"300ms"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "catalog"
+}
+
+// Catalog holds the products and counts the calls it answers
+type Catalog struct {
+	latency time.Duration
+	now     func() time.Time
+	calls   atomic.Int64
+
+	mu       sync.Mutex
+	products map[string]domain.Product
+}
+
+// NewCatalog creates the catalog from the catalog config section, seeded with
+// the demo products
+func NewCatalog(loader configx.Loader) (*Catalog, error) {
+	var cfg Config
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load catalog config: %w", err)
+	}
+	return newCatalog(cfg.Latency), nil
+}
+
+func newCatalog(latency time.Duration) *Catalog {
+	c := &Catalog{latency: latency, now: time.Now, products: map[string]domain.Product{}}
+	updated := c.now().UTC()
+	for _, p := range []domain.Product{
+		{ID: "p1", Name: "Espresso machine", PriceCents: 34900},
+		{ID: "p2", Name: "Burr grinder", PriceCents: 12900},
+		{ID: "p3", Name: "Milk frother", PriceCents: 4900},
+		{ID: "p4", Name: "Pour-over kettle", PriceCents: 5900},
+		{ID: "p5", Name: "Coffee beans, 1 kg", PriceCents: 2400},
+	} {
+		p.UpdatedAt = updated
+		c.products[p.ID] = p
+	}
+	return c
+}
+
+// Get returns the product with the given ID
+func (c *Catalog) Get(ctx context.Context, id string) (domain.Product, error) {
+	if err := c.wait(ctx); err != nil {
+		return domain.Product{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.products[id]
+	if !ok {
+		return domain.Product{}, fmt.Errorf("product %q: %w", id, domain.ErrNotFound)
+	}
+	return p, nil
+}
+
+// UpdatePrice sets the price of a product and returns the updated product
+func (c *Catalog) UpdatePrice(ctx context.Context, id string, cents int64) (domain.Product, error) {
+	if err := c.wait(ctx); err != nil {
+		return domain.Product{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.products[id]
+	if !ok {
+		return domain.Product{}, fmt.Errorf("product %q: %w", id, domain.ErrNotFound)
+	}
+	if err := p.SetPrice(cents, c.now().UTC()); err != nil {
+		return domain.Product{}, err
+	}
+	c.products[id] = p
+	return p, nil
+}
+
+// Calls returns how many calls the catalog has answered
+func (c *Catalog) Calls() int64 {
+	return c.calls.Load()
+}
+
+// wait counts a call and takes the catalog's latency
+func (c *Catalog) wait(ctx context.Context) error {
+	c.calls.Add(1)
+	timer := time.NewTimer(c.latency)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,42 @@
+package catalog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/cache-demo/internal/domain"
+)
+
+func TestUpdatePrice(t *testing.T) {
+	c := newCatalog(0)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	p, err := c.UpdatePrice(context.Background(), "p1", 29900)
+	require.NoError(t, err)
+	assert.Equal(t, int64(29900), p.PriceCents)
+	assert.Equal(t, now, p.UpdatedAt)
+
+	p, err = c.Get(context.Background(), "p1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(29900), p.PriceCents)
+	assert.Equal(t, int64(2), c.Calls())
+
+	_, err = c.UpdatePrice(context.Background(), "p1", -1)
+	assert.ErrorIs(t, err, domain.ErrInvalidPrice)
+	_, err = c.Get(context.Background(), "nope")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestLatencyFollowsContext(t *testing.T) {
+	c := newCatalog(time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := c.Get(ctx, "p1")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
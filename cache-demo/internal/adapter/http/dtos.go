@@ -0,0 +1,55 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gostratum/examples/cache-demo/internal/cache"
+	"github.com/gostratum/examples/cache-demo/internal/domain"
+)
+
+// UpdatePriceRequest represents the request payload for repricing a product
+type UpdatePriceRequest struct {
+	PriceCents *int64 `json:"price_cents" binding:"required"`
+}
+
+// ProductResponse is the HTTP DTO for product data
+type ProductResponse struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	PriceCents int64     `json:"price_cents"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// FromDomainProduct converts a domain.Product to ProductResponse DTO
+func FromDomainProduct(p domain.Product) ProductResponse {
+	return ProductResponse{
+		ID:         p.ID,
+		Name:       p.Name,
+		PriceCents: p.PriceCents,
+		UpdatedAt:  p.UpdatedAt,
+	}
+}
+
+// StatsResponse is the HTTP DTO for what the cache and the backend did
+type StatsResponse struct {
+	Hits         int64   `json:"hits"`
+	Misses       int64   `json:"misses"`
+	Errors       int64   `json:"errors"`
+	HitRatio     float64 `json:"hit_ratio"`
+	Loads        int64   `json:"loads"`
+	SharedLoads  int64   `json:"shared_loads"`
+	BackendCalls int64   `json:"backend_calls"`
+}
+
+// FromStats converts cache.Stats and the backend's call count to StatsResponse DTO
+func FromStats(s cache.Stats, backendCalls int64) StatsResponse {
+	return StatsResponse{
+		Hits:         s.Hits,
+		Misses:       s.Misses,
+		Errors:       s.Errors,
+		HitRatio:     s.HitRatio(),
+		Loads:        s.Loads,
+		SharedLoads:  s.SharedLoads,
+		BackendCalls: backendCalls,
+	}
+}
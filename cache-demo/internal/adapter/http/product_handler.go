@@ -0,0 +1,80 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/cache-demo/internal/domain"
+	"github.com/gostratum/examples/cache-demo/internal/usecase"
+)
+
+// Products reads and reprices products; implemented by usecase.ProductService
+type Products interface {
+	GetProduct(ctx context.Context, id string) (domain.Product, error)
+	UpdatePrice(ctx context.Context, id string, cents int64) (domain.Product, error)
+}
+
+// ProductHandler handles product-related HTTP requests
+type ProductHandler struct {
+	service Products
+	log     logx.Logger
+}
+
+// NewProductHandler creates a new product handler
+func NewProductHandler(service *usecase.ProductService, log logx.Logger) *ProductHandler {
+	return newProductHandler(service, log)
+}
+
+func newProductHandler(service Products, log logx.Logger) *ProductHandler {
+	return &ProductHandler{service: service, log: log}
+}
+
+// GetProduct handles GET /products/:id
+func (h *ProductHandler) GetProduct(c *gin.Context) {
+	p, err := h.service.GetProduct(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.OK(c, FromDomainProduct(p), nil)
+}
+
+// UpdatePrice handles PUT /products/:id/price
+func (h *ProductHandler) UpdatePrice(c *gin.Context) {
+	var req UpdatePriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid request payload", nil)
+		return
+	}
+
+	p, err := h.service.UpdatePrice(c.Request.Context(), c.Param("id"), *req.PriceCents)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.OK(c, FromDomainProduct(p), nil)
+}
+
+// handleError maps usecase errors to HTTP responses
+func (h *ProductHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrNotFound):
+		responsex.Error(c, http.StatusNotFound, "NOT_FOUND", "product not found", nil)
+	case errors.Is(err, usecase.ErrValidation):
+		responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
+	case errors.Is(err, usecase.ErrUnavailable):
+		h.log.Error("catalog unavailable", logx.Err(err))
+		c.Header("Retry-After", "2")
+		responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "service temporarily unavailable", nil)
+	default:
+		h.log.Error("unexpected error", logx.Err(err))
+		responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+	}
+}
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/cache-demo/internal/cache"
+	"github.com/gostratum/examples/cache-demo/internal/domain"
+	"github.com/gostratum/examples/cache-demo/internal/usecase"
+)
+
+// fakeProducts returns one product or an error
+type fakeProducts struct {
+	err error
+}
+
+func (f *fakeProducts) GetProduct(ctx context.Context, id string) (domain.Product, error) {
+	if f.err != nil {
+		return domain.Product{}, f.err
+	}
+	return domain.Product{ID: id, Name: "Burr grinder", PriceCents: 12900}, nil
+}
+
+func (f *fakeProducts) UpdatePrice(ctx context.Context, id string, cents int64) (domain.Product, error) {
+	p, err := f.GetProduct(ctx, id)
+	p.PriceCents = cents
+	return p, err
+}
+
+type fakeStats struct{}
+
+func (fakeStats) Stats() cache.Stats {
+	return cache.Stats{Hits: 3, Misses: 1, Loads: 1, SharedLoads: 2}
+}
+
+func (fakeStats) Calls() int64 {
+	return 1
+}
+
+func setupRouter(products Products) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	handler := newProductHandler(products, logx.NewNoopLogger())
+	stats := newStatsHandler(fakeStats{}, fakeStats{})
+
+	e := gin.New()
+	e.GET("/products/:id", handler.GetProduct)
+	e.PUT("/products/:id/price", handler.UpdatePrice)
+	e.GET("/cache/stats", stats.Stats)
+	return e
+}
+
+func do(e *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w
+}
+
+func TestGetProduct(t *testing.T) {
+	e := setupRouter(&fakeProducts{})
+
+	w := do(e, http.MethodGet, "/products/p2", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var env responsex.Envelope[ProductResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+	assert.Equal(t, "p2", env.Data.ID)
+	assert.Equal(t, int64(12900), env.Data.PriceCents)
+}
+
+func TestUpdatePrice(t *testing.T) {
+	e := setupRouter(&fakeProducts{})
+
+	w := do(e, http.MethodPut, "/products/p2/price", `{"price_cents":9900}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var env responsex.Envelope[ProductResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+	assert.Equal(t, int64(9900), env.Data.PriceCents)
+
+	// A price of 0 is a price; a missing one is not
+	assert.Equal(t, http.StatusOK, do(e, http.MethodPut, "/products/p2/price", `{"price_cents":0}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do(e, http.MethodPut, "/products/p2/price", `{}`).Code)
+}
+
+func TestProductErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "not found", err: usecase.ErrNotFound, wantStatus: http.StatusNotFound, wantCode: "NOT_FOUND"},
+		{name: "invalid", err: usecase.ErrValidation, wantStatus: http.StatusBadRequest, wantCode: "INVALID_INPUT"},
+		{name: "unavailable", err: usecase.ErrUnavailable, wantStatus: http.StatusServiceUnavailable, wantCode: "SERVICE_UNAVAILABLE"},
+		{name: "unexpected", err: errors.New("boom"), wantStatus: http.StatusInternalServerError, wantCode: "INTERNAL_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(setupRouter(&fakeProducts{err: tt.err}), http.MethodGet, "/products/p1", "")
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			var env responsex.Envelope[ProductResponse]
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+			require.NotNil(t, env.Error)
+			assert.Equal(t, tt.wantCode, env.Error.Code)
+		})
+	}
+}
+
+func TestStats(t *testing.T) {
+	w := do(setupRouter(&fakeProducts{}), http.MethodGet, "/cache/stats", "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var env responsex.Envelope[StatsResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+	assert.Equal(t, StatsResponse{Hits: 3, Misses: 1, HitRatio: 0.75, Loads: 1, SharedLoads: 2, BackendCalls: 1}, env.Data)
+}
@@ -0,0 +1,41 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/cache-demo/internal/adapter/catalog"
+	"github.com/gostratum/examples/cache-demo/internal/cache"
+)
+
+// CacheStats reports what the cache did; implemented by cache.Cache
+type CacheStats interface {
+	Stats() cache.Stats
+}
+
+// BackendCalls reports how often the backend was called; implemented by
+// catalog.Catalog
+type BackendCalls interface {
+	Calls() int64
+}
+
+// StatsHandler shows how well the cache works, next to the Prometheus metrics
+// that record the same
+type StatsHandler struct {
+	cache   CacheStats
+	backend BackendCalls
+}
+
+// NewStatsHandler creates a new stats handler
+func NewStatsHandler(c *cache.Cache, backend *catalog.Catalog) *StatsHandler {
+	return newStatsHandler(c, backend)
+}
+
+func newStatsHandler(c CacheStats, backend BackendCalls) *StatsHandler {
+	return &StatsHandler{cache: c, backend: backend}
+}
+
+// Stats handles GET /cache/stats
+func (h *StatsHandler) Stats(c *gin.Context) {
+	responsex.OK(c, FromStats(h.cache.Stats(), h.backend.Calls()), nil)
+}
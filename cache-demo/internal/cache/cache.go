@@ -0,0 +1,246 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/metricsx"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"golang.org/x/sync/singleflight"
+)
+
+// Loader loads a value from the backend after a miss
+type Loader func(ctx context.Context) ([]byte, error)
+
+// Params holds the dependencies of the Cache
+type Params struct {
+	fx.In
+
+	Loader  configx.Loader
+	Metrics metricsx.Metrics
+	Log     logx.Logger
+}
+
+// Cache keeps values in Redis. Lookups fail open: when Redis is down or slow,
+// GetOrLoad loads from the backend, so the cache makes the service faster but
+// never makes it fail.
+type Cache struct {
+	cfg    Config
+	client *redis.Client
+	group  singleflight.Group
+	rec    *recorder
+	log    logx.Logger
+
+	// tracer and jitter, which returns a number in [0, 1), are replaced in tests
+	tracer trace.Tracer
+	jitter func() float64
+}
+
+// NewCache creates a Cache from the cache config section
+func NewCache(p Params) (*Cache, error) {
+	var cfg Config
+	if err := p.Loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load cache config: %w", err)
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+	return newCache(cfg, client, newRecorder(p.Metrics), p.Log), nil
+}
+
+func newCache(cfg Config, client *redis.Client, rec *recorder, log logx.Logger) *Cache {
+	return &Cache{
+		cfg:    cfg,
+		client: client,
+		rec:    rec,
+		tracer: otel.Tracer("github.com/gostratum/examples/cache-demo/cache"),
+		log:    log,
+		jitter: rand.Float64,
+	}
+}
+
+// Register ties the Redis client to the application lifecycle.
+// This function is designed to be used with fx.Invoke.
+func Register(lc fx.Lifecycle, c *Cache) {
+	lc.Append(fx.Hook{OnStart: c.start, OnStop: c.stop})
+}
+
+func (c *Cache) start(ctx context.Context) error {
+	// A cache that is down is no reason not to start; every lookup is a miss
+	// until it is back
+	if err := c.client.Ping(ctx).Err(); err != nil {
+		c.log.Warn("redis is unreachable; serving from the backend until it is back",
+			logx.String("addr", c.cfg.Addr), logx.Err(err))
+	}
+	return nil
+}
+
+func (c *Cache) stop(context.Context) error {
+	return c.client.Close()
+}
+
+// Get returns the value cached under key; ok is false on a miss
+func (c *Cache) Get(ctx context.Context, key string) (value []byte, ok bool, err error) {
+	ctx, span := c.startSpan(ctx, "cache.get", key)
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	value, err = c.client.Get(ctx, c.key(key)).Bytes()
+	c.rec.operation("get", start)
+
+	switch {
+	case errors.Is(err, redis.Nil):
+		c.rec.lookup(resultMiss)
+		span.SetAttributes(attribute.Bool("cache.hit", false))
+		return nil, false, nil
+	case err != nil:
+		c.rec.lookup(resultError)
+		fail(span, err)
+		return nil, false, fmt.Errorf("failed to get %s from cache: %w", key, err)
+	}
+	c.rec.lookup(resultHit)
+	span.SetAttributes(attribute.Bool("cache.hit", true))
+	return value, true, nil
+}
+
+// Set caches value under key for ttl, or for the configured TTL when ttl is 0
+func (c *Cache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	ctx, span := c.startSpan(ctx, "cache.set", key)
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	ttl = c.ttl(ttl)
+	span.SetAttributes(attribute.Int64("cache.ttl_ms", ttl.Milliseconds()))
+
+	start := time.Now()
+	err := c.client.Set(ctx, c.key(key), value, ttl).Err()
+	c.rec.operation("set", start)
+	if err != nil {
+		fail(span, err)
+		return fmt.Errorf("failed to set %s in cache: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes key from the cache, so the next lookup loads it again
+func (c *Cache) Delete(ctx context.Context, key string) error {
+	ctx, span := c.startSpan(ctx, "cache.delete", key)
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.client.Del(ctx, c.key(key)).Err()
+	c.rec.operation("delete", start)
+	if err != nil {
+		fail(span, err)
+		return fmt.Errorf("failed to delete %s from cache: %w", key, err)
+	}
+	return nil
+}
+
+// GetOrLoad returns the value cached under key. On a miss it calls load and
+// caches the result for ttl; errors are not cached. Concurrent misses of a key
+// share one call to load, so a popular key expiring does not send a stampede
+// of requests to the backend.
+func (c *Cache) GetOrLoad(ctx context.Context, key string, ttl time.Duration, load Loader) ([]byte, error) {
+	value, ok, err := c.Get(ctx, key)
+	if err != nil {
+		// Recorded in the metrics and on the span; logging every failed lookup
+		// would flood the log while Redis is down
+		c.log.Debug("cache lookup failed; loading from the backend", logx.String("key", key), logx.Err(err))
+	} else if ok {
+		return value, nil
+	}
+
+	ch := c.group.DoChan(key, func() (any, error) {
+		// The load goes on when the caller that started it gives up, for the
+		// callers waiting on it, and keeps the caller's trace
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.cfg.LoadTimeout)
+		defer cancel()
+		return c.load(ctx, key, ttl, load)
+	})
+
+	select {
+	case res := <-ch:
+		if res.Shared {
+			c.rec.sharedLoad()
+		}
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.([]byte), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// load calls the backend and caches what it returns
+func (c *Cache) load(ctx context.Context, key string, ttl time.Duration, load Loader) ([]byte, error) {
+	ctx, span := c.tracer.Start(ctx, "cache.load", trace.WithAttributes(attribute.String("cache.key", key)))
+	defer span.End()
+
+	value, err := load(ctx)
+	c.rec.load(err)
+	if err != nil {
+		fail(span, err)
+		return nil, err
+	}
+
+	if err := c.Set(ctx, key, value, ttl); err != nil {
+		// The value is still good; the next lookup loads it again
+		c.log.Debug("failed to cache loaded value", logx.String("key", key), logx.Err(err))
+	}
+	return value, nil
+}
+
+// Stats returns what the cache did since the service started
+func (c *Cache) Stats() Stats {
+	return c.rec.stats()
+}
+
+func (c *Cache) key(key string) string {
+	return c.cfg.KeyPrefix + key
+}
+
+// ttl applies the default and the jitter to a TTL
+func (c *Cache) ttl(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		ttl = c.cfg.TTL
+	}
+	if c.cfg.TTLJitter > 0 {
+		ttl -= time.Duration(float64(ttl) * c.cfg.TTLJitter * c.jitter())
+	}
+	return ttl
+}
+
+func (c *Cache) startSpan(ctx context.Context, name, key string) (context.Context, trace.Span) {
+	return c.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "redis"),
+			attribute.String("cache.key", key),
+		),
+	)
+}
+
+// fail marks span as failed with err
+func fail(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,282 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gostratum/core/logx"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fakeCounter counts increments per label values
+type fakeCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (f *fakeCounter) Inc(labels ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.counts[strings.Join(labels, ",")]++
+}
+
+func (f *fakeCounter) get(labels ...string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.counts[strings.Join(labels, ",")]
+}
+
+// fakeHistogram counts observations per label values
+type fakeHistogram struct {
+	fakeCounter
+}
+
+func (f *fakeHistogram) Observe(_ float64, labels ...string) {
+	f.Inc(labels...)
+}
+
+type testCache struct {
+	*Cache
+	redis    *miniredis.Miniredis
+	requests *fakeCounter
+	loads    *fakeCounter
+	duration *fakeHistogram
+	spans    *tracetest.SpanRecorder
+}
+
+func newTestCache(t *testing.T) *testCache {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	// No retries, so a Redis that is down fails fast
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+
+	tc := &testCache{
+		redis:    mr,
+		requests: &fakeCounter{counts: map[string]int{}},
+		loads:    &fakeCounter{counts: map[string]int{}},
+		duration: &fakeHistogram{fakeCounter{counts: map[string]int{}}},
+		spans:    tracetest.NewSpanRecorder(),
+	}
+	rec := &recorder{requests: tc.requests, loads: tc.loads, shared: &fakeCounter{counts: map[string]int{}}, duration: tc.duration}
+	cfg := Config{KeyPrefix: "test:", TTL: time.Minute, TTLJitter: 0.1, Timeout: time.Second, LoadTimeout: time.Second}
+
+	tc.Cache = newCache(cfg, client, rec, logx.NewNoopLogger())
+	tc.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(tc.spans)).Tracer("test")
+	tc.jitter = func() float64 { return 0.5 }
+	return tc
+}
+
+// loadValue returns a Loader that returns value and counts its calls
+func loadValue(value string, calls *int) Loader {
+	return func(context.Context) ([]byte, error) {
+		*calls++
+		return []byte(value), nil
+	}
+}
+
+func TestGetSet(t *testing.T) {
+	c := newTestCache(t)
+	ctx := context.Background()
+
+	_, ok, err := c.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, c.Set(ctx, "k", []byte("v"), 0))
+	value, ok, err := c.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "v", string(value))
+
+	// The default TTL of a minute, shortened by half of the 10% jitter
+	assert.Equal(t, 57*time.Second, c.redis.TTL("test:k"))
+	require.NoError(t, c.Set(ctx, "other", []byte("v"), 10*time.Second))
+	assert.Equal(t, 9500*time.Millisecond, c.redis.TTL("test:other"))
+
+	require.NoError(t, c.Delete(ctx, "k"))
+	assert.False(t, c.redis.Exists("test:k"))
+
+	assert.Equal(t, 1, c.requests.get(resultHit))
+	assert.Equal(t, 1, c.requests.get(resultMiss))
+	assert.Equal(t, 2, c.duration.get("get"))
+	assert.Equal(t, 2, c.duration.get("set"))
+	assert.Equal(t, 1, c.duration.get("delete"))
+}
+
+func TestGetOrLoad(t *testing.T) {
+	c := newTestCache(t)
+	ctx := context.Background()
+	calls := 0
+
+	for range 3 {
+		value, err := c.GetOrLoad(ctx, "k", 0, loadValue("v", &calls))
+		require.NoError(t, err)
+		assert.Equal(t, "v", string(value))
+	}
+	assert.Equal(t, 1, calls, "loaded once, then served from the cache")
+
+	c.redis.FastForward(time.Minute)
+	_, err := c.GetOrLoad(ctx, "k", 0, loadValue("v", &calls))
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls, "loaded again after the TTL")
+
+	assert.Equal(t, Stats{Hits: 2, Misses: 2, Loads: 2}, c.Stats())
+	assert.InDelta(t, 0.5, c.Stats().HitRatio(), 0.001)
+	assert.Equal(t, 2, c.loads.get("ok"))
+}
+
+func TestGetOrLoadDoesNotCacheErrors(t *testing.T) {
+	c := newTestCache(t)
+	ctx := context.Background()
+	errBackend := errors.New("backend down")
+
+	_, err := c.GetOrLoad(ctx, "k", 0, func(context.Context) ([]byte, error) { return nil, errBackend })
+	assert.ErrorIs(t, err, errBackend)
+	assert.False(t, c.redis.Exists("test:k"))
+	assert.Equal(t, 1, c.loads.get("error"))
+
+	calls := 0
+	value, err := c.GetOrLoad(ctx, "k", 0, loadValue("v", &calls))
+	require.NoError(t, err)
+	assert.Equal(t, "v", string(value))
+	assert.Equal(t, 1, calls)
+}
+
+func TestGetOrLoadSharesConcurrentLoads(t *testing.T) {
+	c := newTestCache(t)
+	const callers = 20
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	calls := 0
+	load := func(context.Context) ([]byte, error) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		<-release
+		return []byte("v"), nil
+	}
+
+	var wg sync.WaitGroup
+	values := make([]string, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := c.GetOrLoad(context.Background(), "hot", 0, load)
+			assert.NoError(t, err)
+			values[i] = string(value)
+		}()
+	}
+
+	// Every caller has missed; give the last ones a moment to join the load
+	require.Eventually(t, func() bool { return c.Stats().Misses == callers }, 5*time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, 1, calls, "one load for all callers")
+	for _, v := range values {
+		assert.Equal(t, "v", v)
+	}
+	assert.Equal(t, int64(callers), c.Stats().SharedLoads)
+}
+
+func TestGetOrLoadOutlivesCallerThatGivesUp(t *testing.T) {
+	c := newTestCache(t)
+
+	release := make(chan struct{})
+	loadCtx := make(chan context.Context, 1)
+	load := func(ctx context.Context) ([]byte, error) {
+		loadCtx <- ctx
+		<-release
+		return []byte("v"), nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := c.GetOrLoad(ctx, "k", 0, load)
+		first <- err
+	}()
+	started := <-loadCtx
+
+	second := make(chan string, 1)
+	go func() {
+		value, err := c.GetOrLoad(context.Background(), "k", 0, load)
+		assert.NoError(t, err)
+		second <- string(value)
+	}()
+	require.Eventually(t, func() bool { return c.Stats().Misses == 2 }, 5*time.Second, time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-first, context.Canceled)
+	assert.NoError(t, started.Err(), "the load is not canceled with its first caller")
+
+	close(release)
+	assert.Equal(t, "v", <-second)
+	assert.True(t, c.redis.Exists("test:k"))
+}
+
+func TestGetOrLoadFailsOpen(t *testing.T) {
+	c := newTestCache(t)
+	c.redis.Close()
+	calls := 0
+
+	value, err := c.GetOrLoad(context.Background(), "k", 0, loadValue("v", &calls))
+	require.NoError(t, err, "a cache that is down is a miss")
+	assert.Equal(t, "v", string(value))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, Stats{Errors: 1, Loads: 1}, c.Stats())
+
+	var get sdktrace.ReadOnlySpan
+	for _, s := range c.spans.Ended() {
+		if s.Name() == "cache.get" {
+			get = s
+		}
+	}
+	require.NotNil(t, get)
+	assert.Equal(t, codes.Error, get.Status().Code)
+}
+
+func TestSpans(t *testing.T) {
+	c := newTestCache(t)
+	calls := 0
+
+	_, err := c.GetOrLoad(context.Background(), "k", 0, loadValue("v", &calls))
+	require.NoError(t, err)
+	_, _, err = c.Get(context.Background(), "k")
+	require.NoError(t, err)
+
+	var names []string
+	for _, s := range c.spans.Ended() {
+		names = append(names, s.Name())
+	}
+	assert.Equal(t, []string{"cache.get", "cache.set", "cache.load", "cache.get"}, names)
+
+	spans := c.spans.Ended()
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	assert.Contains(t, spans[0].Attributes(), attribute.Bool("cache.hit", false))
+	assert.Contains(t, spans[3].Attributes(), attribute.Bool("cache.hit", true))
+	assert.Contains(t, spans[3].Attributes(), attribute.String("cache.key", "k"))
+	assert.Equal(t, spans[2].SpanContext().SpanID(), spans[1].Parent().SpanID(), "the set is part of the load")
+}
+
+func TestStatsHitRatio(t *testing.T) {
+	assert.Zero(t, Stats{}.HitRatio())
+	assert.InDelta(t, 0.75, Stats{Hits: 3, Misses: 1}.HitRatio(), 0.001)
+	assert.InDelta(t, 0.5, Stats{Hits: 2, Misses: 1, Errors: 1}.HitRatio(), 0.001)
+}
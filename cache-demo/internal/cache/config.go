@@ -0,0 +1,31 @@
+// Package cache is a small fx module for a Redis cache in front of a slow
+// backend: get and set with a TTL, loads that concurrent misses of a key share,
+// hit and miss metrics, and a span for every cache operation
+package cache
+
+import "time"
+
+// Config says where Redis is and how long values are kept
+type Config struct {
+	Addr     string `mapstructure:"addr" default:"localhost:6379"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db"`
+	// KeyPrefix is put in front of every key, so services can share a Redis
+	KeyPrefix string `mapstructure:"key_prefix"`
+	// TTL is how long a value is kept when the caller passes no TTL
+	TTL time.Duration `mapstructure:"ttl" default:"5m"`
+	// TTLJitter shortens every TTL by a random fraction up to this much, so
+	// values cached together do not all expire together
+	TTLJitter float64 `mapstructure:"ttl_jitter" default:"0.1"`
+	// Timeout bounds every Redis command. A slow cache is treated like a miss,
+	// so keep it well below the backend's latency.
+	Timeout time.Duration `mapstructure:"timeout" default:"100ms"`
+	// LoadTimeout bounds a load from the backend, which runs on after the
+	// caller that started it gives up, for the callers waiting on it
+	LoadTimeout time.Duration `mapstructure:"load_timeout" default:"5s"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "cache"
+}
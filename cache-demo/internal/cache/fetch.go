@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gostratum/core/logx"
+)
+
+// Fetch is GetOrLoad for values stored as JSON. A cached value that no longer
+// decodes into T, e.g. after T changed, is dropped and loaded again.
+func Fetch[T any](ctx context.Context, c *Cache, key string, ttl time.Duration, load func(ctx context.Context) (T, error)) (T, error) {
+	var loaded *T
+	value, err := c.GetOrLoad(ctx, key, ttl, func(ctx context.Context) ([]byte, error) {
+		v, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		loaded = &v
+		return json.Marshal(v)
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	// This caller loaded the value itself; no need to decode it again
+	if loaded != nil {
+		return *loaded, nil
+	}
+
+	var v T
+	if err := json.Unmarshal(value, &v); err != nil {
+		if err := c.Delete(ctx, key); err != nil {
+			c.log.Debug("failed to drop undecodable cache entry", logx.String("key", key), logx.Err(err))
+		}
+		return load(ctx)
+	}
+	return v, nil
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type item struct {
+	ID    string
+	Price int64
+}
+
+func TestFetch(t *testing.T) {
+	c := newTestCache(t)
+	ctx := context.Background()
+	calls := 0
+	load := func(context.Context) (item, error) {
+		calls++
+		return item{ID: "a", Price: 100}, nil
+	}
+
+	for range 2 {
+		got, err := Fetch(ctx, c.Cache, "item:a", 0, load)
+		require.NoError(t, err)
+		assert.Equal(t, item{ID: "a", Price: 100}, got)
+	}
+	assert.Equal(t, 1, calls)
+	assert.JSONEq(t, `{"ID":"a","Price":100}`, mustGet(t, c, "test:item:a"))
+}
+
+func TestFetchDropsUndecodableEntries(t *testing.T) {
+	c := newTestCache(t)
+	require.NoError(t, c.redis.Set("test:item:a", `{"ID":`))
+	calls := 0
+
+	got, err := Fetch(context.Background(), c.Cache, "item:a", 0, func(context.Context) (item, error) {
+		calls++
+		return item{ID: "a"}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, item{ID: "a"}, got)
+	assert.Equal(t, 1, calls)
+	assert.False(t, c.redis.Exists("test:item:a"))
+}
+
+func mustGet(t *testing.T, c *testCache, key string) string {
+	t.Helper()
+	value, err := c.redis.Get(key)
+	require.NoError(t, err)
+	return value
+}
@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/gostratum/metricsx"
+)
+
+// Lookup results recorded in cache_requests_total
+const (
+	resultHit   = "hit"
+	resultMiss  = "miss"
+	resultError = "error"
+)
+
+// Stats counts what the cache did since the service started
+type Stats struct {
+	Hits   int64
+	Misses int64
+	// Errors are lookups that failed and were served by the backend
+	Errors int64
+	// Loads are calls to the backend
+	Loads int64
+	// SharedLoads are lookups served by a load that other lookups shared
+	SharedLoads int64
+}
+
+// HitRatio returns the share of lookups served from the cache
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses + s.Errors
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// counter and histogram are the parts of the metricsx instruments the
+// recorder uses
+type counter interface {
+	Inc(labels ...string)
+}
+
+type histogram interface {
+	Observe(v float64, labels ...string)
+}
+
+// recorder records the cache's Prometheus metrics and keeps the Stats
+type recorder struct {
+	requests counter
+	loads    counter
+	shared   counter
+	duration histogram
+
+	hits, misses, errors, loadCount, sharedCount atomic.Int64
+}
+
+func newRecorder(metrics metricsx.Metrics) *recorder {
+	return &recorder{
+		requests: metrics.Counter("cache_requests_total",
+			metricsx.WithHelp("Cache lookups by result: hit, miss or error"),
+			metricsx.WithLabels("result"),
+		),
+		loads: metrics.Counter("cache_loads_total",
+			metricsx.WithHelp("Loads from the backend after a miss, by result: ok or error"),
+			metricsx.WithLabels("result"),
+		),
+		shared: metrics.Counter("cache_loads_shared_total",
+			metricsx.WithHelp("Lookups served by a load that concurrent lookups of the same key shared"),
+		),
+		duration: metrics.Histogram("cache_operation_duration_seconds",
+			metricsx.WithHelp("Duration of Redis commands by operation: get, set or delete"),
+			metricsx.WithLabels("operation"),
+			metricsx.WithBuckets(0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1),
+		),
+	}
+}
+
+func (r *recorder) lookup(result string) {
+	r.requests.Inc(result)
+	switch result {
+	case resultHit:
+		r.hits.Add(1)
+	case resultMiss:
+		r.misses.Add(1)
+	default:
+		r.errors.Add(1)
+	}
+}
+
+func (r *recorder) load(err error) {
+	r.loadCount.Add(1)
+	if err != nil {
+		r.loads.Inc("error")
+		return
+	}
+	r.loads.Inc("ok")
+}
+
+func (r *recorder) sharedLoad() {
+	r.sharedCount.Add(1)
+	r.shared.Inc()
+}
+
+func (r *recorder) operation(name string, start time.Time) {
+	r.duration.Observe(time.Since(start).Seconds(), name)
+}
+
+func (r *recorder) stats() Stats {
+	return Stats{
+		Hits:        r.hits.Load(),
+		Misses:      r.misses.Load(),
+		Errors:      r.errors.Load(),
+		Loads:       r.loadCount.Load(),
+		SharedLoads: r.sharedCount.Load(),
+	}
+}
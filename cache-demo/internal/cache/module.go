@@ -0,0 +1,13 @@
+package cache
+
+import "go.uber.org/fx"
+
+// Module provides the Cache and ties its Redis client to the application
+// lifecycle. It needs metricsx.Module for the metrics; spans go to the tracer
+// provider tracingx sets up.
+func Module() fx.Option {
+	return fx.Module("cache",
+		fx.Provide(NewCache),
+		fx.Invoke(Register),
+	)
+}
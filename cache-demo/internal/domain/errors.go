@@ -0,0 +1,12 @@
+package domain
+
+import "errors"
+
+// Domain errors represent business rule violations
+var (
+	// ErrNotFound indicates the requested product does not exist
+	ErrNotFound = errors.New("not found")
+
+	// ErrInvalidPrice indicates a negative price
+	ErrInvalidPrice = errors.New("price must not be negative")
+)
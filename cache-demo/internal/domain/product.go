@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// Product is an item of the catalog and its price
+type Product struct {
+	ID         string
+	Name       string
+	PriceCents int64
+	UpdatedAt  time.Time
+}
+
+// SetPrice changes the price of the product
+func (p *Product) SetPrice(cents int64, now time.Time) error {
+	if cents < 0 {
+		return ErrInvalidPrice
+	}
+	p.PriceCents = cents
+	p.UpdatedAt = now
+	return nil
+}
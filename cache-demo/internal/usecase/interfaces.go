@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/gostratum/examples/cache-demo/internal/domain"
+)
+
+// Application-level errors for use case layer
+// These are used to communicate failures to the presentation layer
+var (
+	// ErrUnavailable indicates the catalog cannot be reached
+	ErrUnavailable = errors.New("service unavailable")
+
+	// ErrNotFound wraps domain.ErrNotFound for application layer
+	ErrNotFound = domain.ErrNotFound
+
+	// ErrValidation wraps domain validation errors for application layer
+	ErrValidation = errors.New("validation failed")
+)
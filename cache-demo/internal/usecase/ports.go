@@ -0,0 +1,14 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/gostratum/examples/cache-demo/internal/domain"
+)
+
+// ProductRepository reads and reprices products
+// This interface is owned by the use case layer (dependency inversion principle)
+type ProductRepository interface {
+	Get(ctx context.Context, id string) (domain.Product, error)
+	UpdatePrice(ctx context.Context, id string, cents int64) (domain.Product, error)
+}
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gostratum/examples/cache-demo/internal/domain"
+)
+
+// ProductService reads and reprices products. It knows nothing about the
+// cache; the repository it is given does the caching.
+type ProductService struct {
+	products ProductRepository
+}
+
+// NewProductService creates a new product service with repository injection
+func NewProductService(products ProductRepository) *ProductService {
+	return &ProductService{products: products}
+}
+
+// GetProduct returns the product with the given ID
+func (s *ProductService) GetProduct(ctx context.Context, id string) (domain.Product, error) {
+	p, err := s.products.Get(ctx, id)
+	if err != nil {
+		return domain.Product{}, translateError(err)
+	}
+	return p, nil
+}
+
+// UpdatePrice sets the price of a product and returns the updated product
+func (s *ProductService) UpdatePrice(ctx context.Context, id string, cents int64) (domain.Product, error) {
+	p, err := s.products.UpdatePrice(ctx, id, cents)
+	if err != nil {
+		return domain.Product{}, translateError(err)
+	}
+	return p, nil
+}
+
+// translateError maps repository errors to usecase errors
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return ErrNotFound
+	case errors.Is(err, domain.ErrInvalidPrice):
+		return fmt.Errorf("%w: %v", ErrValidation, err)
+	default:
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+}
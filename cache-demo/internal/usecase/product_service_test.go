@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/cache-demo/internal/domain"
+)
+
+// fakeProducts returns one product or an error
+type fakeProducts struct {
+	product domain.Product
+	err     error
+}
+
+func (f *fakeProducts) Get(ctx context.Context, id string) (domain.Product, error) {
+	return f.product, f.err
+}
+
+func (f *fakeProducts) UpdatePrice(ctx context.Context, id string, cents int64) (domain.Product, error) {
+	if f.err != nil {
+		return domain.Product{}, f.err
+	}
+	p := f.product
+	p.PriceCents = cents
+	return p, nil
+}
+
+func TestGetProduct(t *testing.T) {
+	svc := NewProductService(&fakeProducts{product: domain.Product{ID: "p1", PriceCents: 100}})
+
+	p, err := svc.GetProduct(context.Background(), "p1")
+	require.NoError(t, err)
+	assert.Equal(t, "p1", p.ID)
+
+	p, err = svc.UpdatePrice(context.Background(), "p1", 250)
+	require.NoError(t, err)
+	assert.Equal(t, int64(250), p.PriceCents)
+}
+
+func TestProductErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "not found", err: domain.ErrNotFound, want: ErrNotFound},
+		{name: "invalid price", err: domain.ErrInvalidPrice, want: ErrValidation},
+		{name: "backend down", err: errors.New("connection refused"), want: ErrUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewProductService(&fakeProducts{err: tt.err})
+
+			_, err := svc.GetProduct(context.Background(), "p1")
+			assert.ErrorIs(t, err, tt.want)
+			_, err = svc.UpdatePrice(context.Background(), "p1", 100)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	httpAdapter "github.com/gostratum/examples/chat-demo/internal/adapter/http"
+	"github.com/gostratum/examples/chat-demo/internal/hub"
+	"github.com/gostratum/examples/redact"
+	"github.com/gostratum/httpx"
+	"github.com/gostratum/metricsx"
+)
+
+func main() {
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// Prometheus metrics for connections and message traffic
+		metricsx.Module(),
+
+		// HTTP server; WebSocket connections start as HTTP requests
+		httpx.Module(),
+
+		// Rooms and connections; closes every connection on shutdown
+		hub.Module(),
+
+		// Provide dependencies
+		fx.Provide(
+			// HTTP handlers
+			httpAdapter.NewChatHandler,
+		),
+
+		// Invoke setup functions
+		fx.Invoke(
+			httpAdapter.RegisterRoutes,
+		),
+	)
+
+	app.Run()
+}
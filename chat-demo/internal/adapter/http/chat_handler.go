@@ -0,0 +1,137 @@
+package http
+
+import (
+	_ "embed"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/chat-demo/internal/domain"
+	"github.com/gostratum/examples/chat-demo/internal/hub"
+)
+
+// defaultRoom is joined when the request names none
+const defaultRoom = "lobby"
+
+//go:embed web/index.html
+var indexHTML []byte
+
+// Hub keeps the connections of every room; implemented by hub.Hub
+type Hub interface {
+	Admit() error
+	Serve(conn *websocket.Conn, room, name string) error
+	Broadcast(msg domain.Message) int
+	Rooms() []hub.RoomInfo
+}
+
+// ChatHandler upgrades chat connections and pushes notices to rooms
+type ChatHandler struct {
+	hub      Hub
+	upgrader websocket.Upgrader
+	log      logx.Logger
+}
+
+// NewChatHandler creates a new chat handler
+func NewChatHandler(h *hub.Hub, log logx.Logger) *ChatHandler {
+	return newChatHandler(h, log)
+}
+
+func newChatHandler(h Hub, log logx.Logger) *ChatHandler {
+	return &ChatHandler{
+		hub: h,
+		// The default origin check only accepts pages served by this host
+		upgrader: websocket.Upgrader{ReadBufferSize: 1024, WriteBufferSize: 1024},
+		log:      log,
+	}
+}
+
+// Index handles GET / with a minimal browser client
+func (h *ChatHandler) Index(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", indexHTML)
+}
+
+// Connect handles GET /ws?room=lobby&name=alice and upgrades it to a WebSocket
+func (h *ChatHandler) Connect(c *gin.Context) {
+	room := c.DefaultQuery("room", defaultRoom)
+	name := c.Query("name")
+	if err := domain.ValidateName("room", room); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
+		return
+	}
+	if err := domain.ValidateName("name", name); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
+		return
+	}
+	if !websocket.IsWebSocketUpgrade(c.Request) {
+		responsex.Error(c, http.StatusUpgradeRequired, "UPGRADE_REQUIRED", "connect with a WebSocket client", nil)
+		return
+	}
+
+	// Refuse with a plain HTTP error while that is still possible
+	if err := h.hub.Admit(); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already answered the request
+		h.log.Warn("websocket upgrade failed", logx.Err(err))
+		return
+	}
+	if err := h.hub.Serve(conn, room, name); err != nil {
+		h.log.Warn("websocket connection refused", logx.String("room", room), logx.Err(err))
+	}
+}
+
+// ListRooms handles GET /rooms
+func (h *ChatHandler) ListRooms(c *gin.Context) {
+	rooms := h.hub.Rooms()
+	resp := make([]RoomResponse, len(rooms))
+	for i, r := range rooms {
+		resp[i] = FromRoomInfo(r)
+	}
+	responsex.OK(c, resp, nil)
+}
+
+// PostNotice handles POST /rooms/:room/notices, for other services to notify
+// everyone in a room
+func (h *ChatHandler) PostNotice(c *gin.Context) {
+	room := c.Param("room")
+	if err := domain.ValidateName("room", room); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
+		return
+	}
+
+	var req NoticeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid request payload", nil)
+		return
+	}
+	notice, err := domain.NewNotice(room, req.Text)
+	if err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
+		return
+	}
+
+	delivered := h.hub.Broadcast(notice)
+	responsex.OK(c, NoticeResponse{ID: notice.ID, Room: room, Delivered: delivered}, nil)
+}
+
+// handleError maps hub errors to HTTP responses
+func (h *ChatHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, hub.ErrFull):
+		c.Header("Retry-After", "5")
+		responsex.Error(c, http.StatusServiceUnavailable, "TOO_MANY_CONNECTIONS", "too many connections, retry later", nil)
+	case errors.Is(err, hub.ErrClosed):
+		responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "shutting down", nil)
+	default:
+		h.log.Error("chat request failed", logx.Err(err))
+		responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+	}
+}
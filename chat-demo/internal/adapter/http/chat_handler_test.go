@@ -0,0 +1,153 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/chat-demo/internal/domain"
+	"github.com/gostratum/examples/chat-demo/internal/hub"
+)
+
+// fakeHub records what the handler asks of it
+type fakeHub struct {
+	admitErr  error
+	served    chan string
+	broadcast []domain.Message
+}
+
+func (h *fakeHub) Admit() error {
+	return h.admitErr
+}
+
+func (h *fakeHub) Serve(conn *websocket.Conn, room, name string) error {
+	conn.Close()
+	h.served <- room + "/" + name
+	return nil
+}
+
+func (h *fakeHub) Broadcast(msg domain.Message) int {
+	h.broadcast = append(h.broadcast, msg)
+	return 3
+}
+
+func (h *fakeHub) Rooms() []hub.RoomInfo {
+	return []hub.RoomInfo{{Name: "lobby", Members: []string{"alice", "bob"}}}
+}
+
+func setupRouter(h Hub) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	handler := newChatHandler(h, logx.NewNoopLogger())
+
+	e := gin.New()
+	e.GET("/", handler.Index)
+	e.GET("/ws", handler.Connect)
+	e.GET("/rooms", handler.ListRooms)
+	e.POST("/rooms/:room/notices", handler.PostNotice)
+	return e
+}
+
+func TestConnectUpgrades(t *testing.T) {
+	fake := &fakeHub{served: make(chan string, 1)}
+	srv := httptest.NewServer(setupRouter(fake))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws?name=alice", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, "lobby/alice", <-fake.served)
+}
+
+func TestConnectRefusals(t *testing.T) {
+	upgrade := http.Header{
+		"Connection":            {"Upgrade"},
+		"Upgrade":               {"websocket"},
+		"Sec-Websocket-Version": {"13"},
+		"Sec-Websocket-Key":     {"dGhlIHNhbXBsZSBub25jZQ=="},
+	}
+
+	tests := []struct {
+		name       string
+		url        string
+		header     http.Header
+		admitErr   error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "missing name", url: "/ws", header: upgrade, wantStatus: http.StatusBadRequest, wantCode: "INVALID_INPUT"},
+		{name: "invalid room", url: "/ws?room=a+b&name=alice", header: upgrade, wantStatus: http.StatusBadRequest, wantCode: "INVALID_INPUT"},
+		{name: "plain HTTP", url: "/ws?name=alice", wantStatus: http.StatusUpgradeRequired, wantCode: "UPGRADE_REQUIRED"},
+		{name: "hub full", url: "/ws?name=alice", header: upgrade, admitErr: hub.ErrFull, wantStatus: http.StatusServiceUnavailable, wantCode: "TOO_MANY_CONNECTIONS"},
+		{name: "shutting down", url: "/ws?name=alice", header: upgrade, admitErr: hub.ErrClosed, wantStatus: http.StatusServiceUnavailable, wantCode: "SERVICE_UNAVAILABLE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := setupRouter(&fakeHub{admitErr: tt.admitErr})
+
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantCode)
+		})
+	}
+}
+
+func TestPostNotice(t *testing.T) {
+	fake := &fakeHub{}
+	e := setupRouter(fake)
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/rooms/lobby/notices", strings.NewReader(`{"text": "deploy at 18:00"}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp responsex.Envelope[NoticeResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "lobby", resp.Data.Room)
+	assert.Equal(t, 3, resp.Data.Delivered)
+
+	require.Len(t, fake.broadcast, 1)
+	assert.Equal(t, domain.TypeNotice, fake.broadcast[0].Type)
+	assert.Equal(t, "deploy at 18:00", fake.broadcast[0].Text)
+	assert.Equal(t, resp.Data.ID, fake.broadcast[0].ID)
+
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/rooms/lobby/notices", strings.NewReader(`{"text": "  "}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Len(t, fake.broadcast, 1)
+}
+
+func TestListRooms(t *testing.T) {
+	e := setupRouter(&fakeHub{})
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/rooms", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp responsex.Envelope[[]RoomResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []RoomResponse{{Name: "lobby", Members: []string{"alice", "bob"}}}, resp.Data)
+}
+
+func TestIndex(t *testing.T) {
+	e := setupRouter(&fakeHub{})
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "new WebSocket(")
+}
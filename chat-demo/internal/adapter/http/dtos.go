@@ -0,0 +1,27 @@
+package http
+
+import "github.com/gostratum/examples/chat-demo/internal/hub"
+
+// NoticeRequest represents the request payload for pushing a notice to a room
+type NoticeRequest struct {
+	Text string `json:"text" binding:"required"`
+}
+
+// NoticeResponse reports a pushed notice
+type NoticeResponse struct {
+	ID   string `json:"id"`
+	Room string `json:"room"`
+	// Delivered is how many clients the notice was queued for
+	Delivered int `json:"delivered"`
+}
+
+// RoomResponse is the HTTP DTO for a room with clients in it
+type RoomResponse struct {
+	Name    string   `json:"name"`
+	Members []string `json:"members"`
+}
+
+// FromRoomInfo converts a hub.RoomInfo to RoomResponse DTO
+func FromRoomInfo(r hub.RoomInfo) RoomResponse {
+	return RoomResponse{Name: r.Name, Members: r.Members}
+}
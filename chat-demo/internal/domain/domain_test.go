@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "valid", value: "lobby", wantErr: false},
+		{name: "digits dashes and underscores", value: "team-42_ops", wantErr: false},
+		{name: "empty", value: "", wantErr: true},
+		{name: "space", value: "the lobby", wantErr: true},
+		{name: "too long", value: strings.Repeat("a", 33), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateName("room", tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateName(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("ValidateName(%q) error = %v, want ErrInvalidInput", tt.value, err)
+			}
+		})
+	}
+}
+
+func TestNewMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		wantText string
+		wantErr  bool
+	}{
+		{name: "valid", text: "hello", wantText: "hello"},
+		{name: "trimmed", text: "  hello \n", wantText: "hello"},
+		{name: "empty", text: "   ", wantErr: true},
+		{name: "longest allowed", text: strings.Repeat("é", MaxTextLength), wantText: strings.Repeat("é", MaxTextLength)},
+		{name: "too long", text: strings.Repeat("a", MaxTextLength+1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := NewMessage("lobby", "alice", tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if msg.Text != tt.wantText || msg.Type != TypeMessage || msg.From != "alice" || msg.Room != "lobby" {
+				t.Errorf("NewMessage() = %+v", msg)
+			}
+			if msg.ID == "" || msg.SentAt.IsZero() {
+				t.Errorf("NewMessage() did not set ID and SentAt: %+v", msg)
+			}
+		})
+	}
+}
+
+func TestNewNotice(t *testing.T) {
+	msg, err := NewNotice("lobby", "maintenance at 18:00")
+	if err != nil {
+		t.Fatalf("NewNotice() error = %v", err)
+	}
+	if msg.Type != TypeNotice || msg.From != "" {
+		t.Errorf("NewNotice() = %+v", msg)
+	}
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// MaxTextLength is the longest message text, in characters
+const MaxTextLength = 1000
+
+// MessageType tells clients how to show a message
+type MessageType string
+
+const (
+	// TypeMessage is a chat message from a user
+	TypeMessage MessageType = "message"
+	// TypeJoin announces a user joining the room
+	TypeJoin MessageType = "join"
+	// TypeLeave announces a user leaving the room
+	TypeLeave MessageType = "leave"
+	// TypeNotice is a notification pushed by the server to everyone in the room
+	TypeNotice MessageType = "notice"
+	// TypeError is sent to one client only, about a message it sent
+	TypeError MessageType = "error"
+)
+
+// Message is one event in a room
+// This is a pure domain model without infrastructure concerns
+type Message struct {
+	ID     string
+	Type   MessageType
+	Room   string
+	From   string
+	Text   string
+	SentAt time.Time
+}
+
+// namePattern is what room and user names may look like
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// ValidateName checks a room or user name; kind names it in the error
+func ValidateName(kind, name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("%w: %s must be 1-32 letters, digits, '-' or '_'", ErrInvalidInput, kind)
+	}
+	return nil
+}
+
+// NewMessage creates a chat message from a user
+func NewMessage(room, from, text string) (Message, error) {
+	return newMessage(TypeMessage, room, from, text)
+}
+
+// NewNotice creates a notification from the server
+func NewNotice(room, text string) (Message, error) {
+	return newMessage(TypeNotice, room, "", text)
+}
+
+// NewPresence creates the join or leave announcement of a user
+func NewPresence(t MessageType, room, user string) Message {
+	return Message{ID: uuid.NewString(), Type: t, Room: room, From: user, SentAt: time.Now()}
+}
+
+func newMessage(t MessageType, room, from, text string) (Message, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Message{}, fmt.Errorf("%w: text is required", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(text) > MaxTextLength {
+		return Message{}, fmt.Errorf("%w: text is longer than %d characters", ErrInvalidInput, MaxTextLength)
+	}
+	return Message{ID: uuid.NewString(), Type: t, Room: room, From: from, Text: text, SentAt: time.Now()}, nil
+}
@@ -0,0 +1,174 @@
+package hub
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/gostratum/core/logx"
+	"golang.org/x/time/rate"
+
+	"github.com/gostratum/examples/chat-demo/internal/domain"
+)
+
+// client is one connection. Its reader and writer are the only goroutines that
+// read from and write messages to conn.
+type client struct {
+	hub         *Hub
+	conn        *websocket.Conn
+	room        string
+	name        string
+	connectedAt time.Time
+
+	// send holds encoded events waiting for the writer
+	send    chan []byte
+	limiter *rate.Limiter
+
+	// closing is closed to make the writer send closeMsg and end the connection
+	closeOnce sync.Once
+	closing   chan struct{}
+	closeMsg  []byte
+}
+
+func newClient(h *Hub, conn *websocket.Conn, room, name string) *client {
+	return &client{
+		hub:         h,
+		conn:        conn,
+		room:        room,
+		name:        name,
+		connectedAt: time.Now(),
+		send:        make(chan []byte, h.cfg.SendBuffer),
+		limiter:     rate.NewLimiter(rate.Limit(h.cfg.RateLimit), h.cfg.RateBurst),
+		closing:     make(chan struct{}),
+	}
+}
+
+// enqueue hands an event to the writer without waiting; false means the send
+// buffer is full
+func (c *client) enqueue(data []byte) bool {
+	select {
+	case c.send <- data:
+		return true
+	default:
+		return false
+	}
+}
+
+// shutdown asks the writer to close the connection with code and text. Only
+// the first call counts.
+func (c *client) shutdown(code int, text string) {
+	c.closeOnce.Do(func() {
+		c.closeMsg = websocket.FormatCloseMessage(code, text)
+		close(c.closing)
+	})
+}
+
+// readPump reads messages until the connection fails or closes, then takes
+// the client out of the hub
+func (c *client) readPump() {
+	defer c.hub.pumps.Done()
+	defer func() {
+		c.hub.remove(c)
+		c.shutdown(websocket.CloseNormalClosure, "")
+	}()
+
+	cfg := c.hub.cfg
+	c.conn.SetReadLimit(cfg.MaxMessageBytes)
+	c.conn.SetReadDeadline(time.Now().Add(cfg.PongTimeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(cfg.PongTimeout))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) {
+				c.hub.log.Debug("connection ended", logx.String("name", c.name), logx.Err(err))
+			}
+			return
+		}
+		c.hub.metrics.received.Inc()
+		c.handle(data)
+	}
+}
+
+// handle broadcasts one message from the client, or tells the client why not
+func (c *client) handle(data []byte) {
+	if !c.limiter.Allow() {
+		c.hub.metrics.dropped.Inc("rate_limited")
+		c.reply("slow down: too many messages")
+		return
+	}
+
+	var in inbound
+	if err := json.Unmarshal(data, &in); err != nil {
+		c.hub.metrics.dropped.Inc("invalid")
+		c.reply(`messages must be JSON like {"text": "hello"}`)
+		return
+	}
+	msg, err := domain.NewMessage(c.room, c.name, in.Text)
+	if err != nil {
+		c.hub.metrics.dropped.Inc("invalid")
+		c.reply(err.Error())
+		return
+	}
+	c.hub.Broadcast(msg)
+}
+
+// reply sends an error event to this client only. It is dropped if the send
+// buffer is full; the next broadcast will deal with the slow client.
+func (c *client) reply(text string) {
+	data, err := json.Marshal(event{Type: string(domain.TypeError), Room: c.room, Text: text, SentAt: time.Now()})
+	if err != nil {
+		return
+	}
+	c.enqueue(data)
+}
+
+// writePump writes queued events and pings until the client is shut down or a
+// write fails, then closes the connection, which also ends readPump
+func (c *client) writePump() {
+	cfg := c.hub.cfg
+	ticker := time.NewTicker(cfg.pingInterval())
+	defer c.hub.pumps.Done()
+	defer c.conn.Close()
+	defer ticker.Stop()
+
+	for {
+		select {
+		case data := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(cfg.WriteTimeout))
+			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+			c.hub.metrics.delivered.Inc()
+
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(cfg.WriteTimeout)); err != nil {
+				return
+			}
+
+		case <-c.closing:
+			c.flush()
+			_ = c.conn.WriteControl(websocket.CloseMessage, c.closeMsg, time.Now().Add(cfg.WriteTimeout))
+			return
+		}
+	}
+}
+
+// flush writes the events still queued, all within one write timeout
+func (c *client) flush() {
+	c.conn.SetWriteDeadline(time.Now().Add(c.hub.cfg.WriteTimeout))
+	for {
+		select {
+		case data := <-c.send:
+			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+			c.hub.metrics.delivered.Inc()
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,37 @@
+// Package hub is a small fx module that keeps WebSocket connections in rooms
+// and broadcasts messages to them. Every connection has a reader and a writer
+// goroutine and a bounded send buffer; a client that cannot keep up is
+// disconnected instead of slowing down the rest of its room.
+package hub
+
+import "time"
+
+// Config limits connections and the traffic on each of them
+type Config struct {
+	// MaxConnections is how many clients may be connected at the same time
+	MaxConnections int `mapstructure:"max_connections" default:"1000"`
+	// SendBuffer is how many messages may wait for one client's writer;
+	// a client whose buffer is full is disconnected as too slow
+	SendBuffer int `mapstructure:"send_buffer" default:"32"`
+	// WriteTimeout bounds one write to a client
+	WriteTimeout time.Duration `mapstructure:"write_timeout" default:"10s"`
+	// PongTimeout is how long a client may stay silent, pongs included, before
+	// it is considered gone; pings are sent at 9/10 of it
+	PongTimeout time.Duration `mapstructure:"pong_timeout" default:"60s"`
+	// MaxMessageBytes is the largest message a client may send
+	MaxMessageBytes int64 `mapstructure:"max_message_bytes" default:"4096"`
+	// RateLimit is how many messages per second one client may send, with
+	// bursts of up to RateBurst
+	RateLimit float64 `mapstructure:"rate_limit" default:"5"`
+	RateBurst int     `mapstructure:"rate_burst" default:"10"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "hub"
+}
+
+// pingInterval leaves a client time to answer a ping before PongTimeout
+func (c Config) pingInterval() time.Duration {
+	return c.PongTimeout * 9 / 10
+}
@@ -0,0 +1,33 @@
+package hub
+
+import (
+	"time"
+
+	"github.com/gostratum/examples/chat-demo/internal/domain"
+)
+
+// event is a message as clients receive it, one JSON object per WebSocket message
+type event struct {
+	ID     string    `json:"id,omitempty"`
+	Type   string    `json:"type"`
+	Room   string    `json:"room"`
+	From   string    `json:"from,omitempty"`
+	Text   string    `json:"text,omitempty"`
+	SentAt time.Time `json:"sent_at"`
+}
+
+func toEvent(m domain.Message) event {
+	return event{
+		ID:     m.ID,
+		Type:   string(m.Type),
+		Room:   m.Room,
+		From:   m.From,
+		Text:   m.Text,
+		SentAt: m.SentAt,
+	}
+}
+
+// inbound is a message as clients send it
+type inbound struct {
+	Text string `json:"text"`
+}
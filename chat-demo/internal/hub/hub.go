@@ -0,0 +1,292 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/gostratum/core"
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"go.uber.org/fx"
+
+	"github.com/gostratum/examples/chat-demo/internal/domain"
+)
+
+var (
+	// ErrClosed indicates the hub is shutting down and accepts no more clients
+	ErrClosed = errors.New("hub is closed")
+
+	// ErrFull indicates max_connections clients are already connected
+	ErrFull = errors.New("too many connections")
+)
+
+// Params are the dependencies of NewHub
+type Params struct {
+	fx.In
+
+	Loader   configx.Loader
+	Registry core.Registry
+	Metrics  *Metrics
+	Log      logx.Logger
+}
+
+// Hub tracks the clients of every room and fans messages out to them
+type Hub struct {
+	cfg     Config
+	metrics *Metrics
+	log     logx.Logger
+
+	mu      sync.RWMutex
+	closed  bool
+	rooms   map[string]map[*client]struct{}
+	clients int
+
+	// pumps counts the reader and writer goroutines of every client
+	pumps sync.WaitGroup
+}
+
+// RoomInfo describes a room with clients in it
+type RoomInfo struct {
+	Name    string
+	Members []string
+}
+
+// NewHub creates the hub from the hub config section and registers its
+// readiness check
+func NewHub(p Params) (*Hub, error) {
+	var cfg Config
+	if err := p.Loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load hub config: %w", err)
+	}
+
+	h, err := newHub(cfg, p.Metrics, p.Log)
+	if err != nil {
+		return nil, err
+	}
+	p.Registry.Register(&admitCheck{hub: h})
+	return h, nil
+}
+
+func newHub(cfg Config, metrics *Metrics, log logx.Logger) (*Hub, error) {
+	if cfg.MaxConnections <= 0 || cfg.SendBuffer <= 0 || cfg.RateBurst <= 0 {
+		return nil, fmt.Errorf("hub.max_connections (%d), hub.send_buffer (%d) and hub.rate_burst (%d) must be positive",
+			cfg.MaxConnections, cfg.SendBuffer, cfg.RateBurst)
+	}
+	if cfg.WriteTimeout <= 0 || cfg.PongTimeout <= 0 {
+		return nil, errors.New("hub.write_timeout and hub.pong_timeout must be positive")
+	}
+	return &Hub{
+		cfg:     cfg,
+		metrics: metrics,
+		log:     log,
+		rooms:   make(map[string]map[*client]struct{}),
+	}, nil
+}
+
+// Register ties the hub to the application lifecycle.
+// This function is designed to be used with fx.Invoke.
+func Register(lc fx.Lifecycle, h *Hub) {
+	lc.Append(fx.Hook{
+		OnStop: h.stop,
+	})
+}
+
+// stop refuses new clients and closes every connection with "going away",
+// after flushing the messages already queued for it. Connections still open
+// when ctx expires are dropped.
+func (h *Hub) stop(ctx context.Context) error {
+	h.mu.Lock()
+	h.closed = true
+	var clients []*client
+	for _, members := range h.rooms {
+		for c := range members {
+			clients = append(clients, c)
+		}
+	}
+	h.mu.Unlock()
+
+	h.log.Info("closing websocket connections", logx.Int("connections", len(clients)))
+	for _, c := range clients {
+		c.shutdown(websocket.CloseGoingAway, "server shutting down")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.pumps.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		h.log.Info("websocket connections closed")
+		return nil
+	case <-ctx.Done():
+		for _, c := range clients {
+			c.conn.Close()
+		}
+		return fmt.Errorf("%d websocket connections did not close in time", len(clients))
+	}
+}
+
+// Admit reports whether a new client would be accepted, so a request can be
+// refused with a plain HTTP error before it is upgraded
+func (h *Hub) Admit() error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.admit()
+}
+
+func (h *Hub) admit() error {
+	switch {
+	case h.closed:
+		return ErrClosed
+	case h.clients >= h.cfg.MaxConnections:
+		return ErrFull
+	default:
+		return nil
+	}
+}
+
+// Serve adds an upgraded connection to a room and starts its reader and
+// writer. It returns once they are running; the hub owns the connection
+// from then on. A connection that cannot be accepted is closed.
+func (h *Hub) Serve(conn *websocket.Conn, room, name string) error {
+	c := newClient(h, conn, room, name)
+
+	h.mu.Lock()
+	if err := h.admit(); err != nil {
+		h.mu.Unlock()
+		reason, code := "full", websocket.CloseTryAgainLater
+		if errors.Is(err, ErrClosed) {
+			reason, code = "closed", websocket.CloseGoingAway
+		}
+		h.metrics.rejected.Inc(reason)
+		deadline := time.Now().Add(h.cfg.WriteTimeout)
+		_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, err.Error()), deadline)
+		conn.Close()
+		return err
+	}
+	members, ok := h.rooms[room]
+	if !ok {
+		members = make(map[*client]struct{})
+		h.rooms[room] = members
+	}
+	members[c] = struct{}{}
+	h.clients++
+	h.metrics.connections.Set(float64(h.clients))
+	h.metrics.rooms.Set(float64(len(h.rooms)))
+	h.pumps.Add(2)
+	h.mu.Unlock()
+
+	go c.readPump()
+	go c.writePump()
+
+	h.log.Info("client connected", logx.String("room", room), logx.String("name", name))
+	h.Broadcast(domain.NewPresence(domain.TypeJoin, room, name))
+	return nil
+}
+
+// remove takes a client out of its room and announces that it left. It is
+// safe to call more than once.
+func (h *Hub) remove(c *client) {
+	h.mu.Lock()
+	members := h.rooms[c.room]
+	if _, ok := members[c]; !ok {
+		h.mu.Unlock()
+		return
+	}
+	delete(members, c)
+	if len(members) == 0 {
+		delete(h.rooms, c.room)
+	}
+	h.clients--
+	closed := h.closed
+	h.metrics.connections.Set(float64(h.clients))
+	h.metrics.rooms.Set(float64(len(h.rooms)))
+	h.mu.Unlock()
+
+	h.metrics.duration.Observe(time.Since(c.connectedAt).Seconds())
+	h.log.Info("client disconnected", logx.String("room", c.room), logx.String("name", c.name))
+
+	// Everyone is leaving at shutdown; nobody needs to hear about it
+	if !closed {
+		h.Broadcast(domain.NewPresence(domain.TypeLeave, c.room, c.name))
+	}
+}
+
+// Broadcast queues a message for every client in its room and returns how
+// many clients it was queued for. It never blocks: clients whose send buffer
+// is full are disconnected instead.
+func (h *Hub) Broadcast(msg domain.Message) int {
+	data, err := json.Marshal(toEvent(msg))
+	if err != nil {
+		h.log.Error("failed to encode message", logx.Err(err))
+		return 0
+	}
+
+	var slow []*client
+	queued := 0
+	h.mu.RLock()
+	for c := range h.rooms[msg.Room] {
+		if c.enqueue(data) {
+			queued++
+		} else {
+			slow = append(slow, c)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, c := range slow {
+		h.log.Warn("client too slow, disconnecting",
+			logx.String("room", c.room),
+			logx.String("name", c.name),
+			logx.Int("send_buffer", h.cfg.SendBuffer),
+		)
+		h.metrics.slow.Inc()
+		c.shutdown(websocket.CloseTryAgainLater, "too slow")
+		h.remove(c)
+	}
+	return queued
+}
+
+// Rooms lists the rooms with clients in them, sorted by name
+func (h *Hub) Rooms() []RoomInfo {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	rooms := make([]RoomInfo, 0, len(h.rooms))
+	for name, members := range h.rooms {
+		info := RoomInfo{Name: name, Members: make([]string, 0, len(members))}
+		for c := range members {
+			info.Members = append(info.Members, c.name)
+		}
+		sort.Strings(info.Members)
+		rooms = append(rooms, info)
+	}
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].Name < rooms[j].Name })
+	return rooms
+}
+
+// admitCheck reports not ready while the hub accepts no new clients, so load
+// balancers send new connections to other replicas
+type admitCheck struct {
+	hub *Hub
+}
+
+func (c *admitCheck) Name() string {
+	return "hub"
+}
+
+func (c *admitCheck) Kind() core.Kind {
+	return core.Readiness
+}
+
+func (c *admitCheck) Check(ctx context.Context) error {
+	return c.hub.Admit()
+}
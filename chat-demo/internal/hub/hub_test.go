@@ -0,0 +1,269 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/chat-demo/internal/domain"
+)
+
+// recorder stores the last value set and the number of increments per metric and labels
+type recorder struct {
+	name string
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func (r *recorder) key(labels []string) string {
+	return r.name + "{" + strings.Join(labels, ",") + "}"
+}
+
+func (r *recorder) Inc(labels ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[r.key(labels)]++
+}
+
+func (r *recorder) Observe(v float64, labels ...string) {
+	r.Inc(labels...)
+}
+
+func (r *recorder) Set(v float64, labels ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[r.key(labels)] = v
+}
+
+func (r *recorder) get(labels ...string) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.values[r.key(labels)]
+}
+
+func newTestMetrics() *Metrics {
+	m := func(name string) *recorder { return &recorder{name: name, values: map[string]float64{}} }
+	return &Metrics{
+		connections: m("connections"),
+		rooms:       m("rooms"),
+		rejected:    m("rejected"),
+		received:    m("received"),
+		delivered:   m("delivered"),
+		dropped:     m("dropped"),
+		slow:        m("slow"),
+		duration:    m("duration"),
+	}
+}
+
+func testConfig() Config {
+	return Config{
+		MaxConnections:  10,
+		SendBuffer:      8,
+		WriteTimeout:    time.Second,
+		PongTimeout:     time.Minute,
+		MaxMessageBytes: 1024,
+		RateLimit:       100,
+		RateBurst:       100,
+	}
+}
+
+// newTestServer serves the hub at /ws?room=&name=
+func newTestServer(t *testing.T, cfg Config) (*Hub, *Metrics, string) {
+	t.Helper()
+
+	metrics := newTestMetrics()
+	h, err := newHub(cfg, metrics, logx.NewNoopLogger())
+	require.NoError(t, err)
+
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		_ = h.Serve(conn, r.URL.Query().Get("room"), r.URL.Query().Get("name"))
+	}))
+	t.Cleanup(func() {
+		_ = h.stop(context.Background())
+		srv.Close()
+	})
+	return h, metrics, "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func dial(t *testing.T, url, room, name string) *websocket.Conn {
+	t.Helper()
+
+	conn, _, err := websocket.DefaultDialer.Dial(url+"/ws?room="+room+"&name="+name, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func readEvent(t *testing.T, conn *websocket.Conn) event {
+	t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	var e event
+	require.NoError(t, json.Unmarshal(data, &e))
+	return e
+}
+
+func send(t *testing.T, conn *websocket.Conn, text string) {
+	t.Helper()
+	require.NoError(t, conn.WriteJSON(inbound{Text: text}))
+}
+
+func TestBroadcastStaysInRoom(t *testing.T) {
+	h, metrics, url := newTestServer(t, testConfig())
+
+	alice := dial(t, url, "lobby", "alice")
+	assert.Equal(t, event{Type: "join", Room: "lobby", From: "alice"}, strip(readEvent(t, alice)))
+	bob := dial(t, url, "lobby", "bob")
+	assert.Equal(t, "join", readEvent(t, alice).Type)
+	assert.Equal(t, "join", readEvent(t, bob).Type)
+	carol := dial(t, url, "ops", "carol")
+	assert.Equal(t, "join", readEvent(t, carol).Type)
+
+	send(t, alice, "hi bob")
+	want := event{Type: "message", Room: "lobby", From: "alice", Text: "hi bob"}
+	assert.Equal(t, want, strip(readEvent(t, alice)))
+	assert.Equal(t, want, strip(readEvent(t, bob)))
+
+	bob.Close()
+	assert.Equal(t, event{Type: "leave", Room: "lobby", From: "bob"}, strip(readEvent(t, alice)))
+
+	// carol saw nothing from the lobby: the next event she gets is her own
+	send(t, carol, "anyone?")
+	assert.Equal(t, "anyone?", readEvent(t, carol).Text)
+
+	assert.Equal(t, []RoomInfo{{Name: "lobby", Members: []string{"alice"}}, {Name: "ops", Members: []string{"carol"}}}, h.Rooms())
+	assert.Equal(t, float64(2), metrics.received.(*recorder).get())
+	assert.Equal(t, float64(2), metrics.connections.(*recorder).get())
+}
+
+// strip clears the fields that differ on every run
+func strip(e event) event {
+	e.ID = ""
+	e.SentAt = time.Time{}
+	return e
+}
+
+func TestInvalidMessagesGetErrorReply(t *testing.T) {
+	_, metrics, url := newTestServer(t, testConfig())
+	alice := dial(t, url, "lobby", "alice")
+	readEvent(t, alice)
+
+	require.NoError(t, alice.WriteMessage(websocket.TextMessage, []byte("not json")))
+	e := readEvent(t, alice)
+	assert.Equal(t, "error", e.Type)
+	assert.Contains(t, e.Text, "JSON")
+
+	send(t, alice, "   ")
+	e = readEvent(t, alice)
+	assert.Equal(t, "error", e.Type)
+	assert.Contains(t, e.Text, "text is required")
+
+	assert.Equal(t, float64(2), metrics.dropped.(*recorder).get("invalid"))
+}
+
+func TestRateLimit(t *testing.T) {
+	cfg := testConfig()
+	cfg.RateLimit = 0.001
+	cfg.RateBurst = 2
+	_, metrics, url := newTestServer(t, cfg)
+	alice := dial(t, url, "lobby", "alice")
+	readEvent(t, alice)
+
+	for _, text := range []string{"one", "two", "three"} {
+		send(t, alice, text)
+	}
+	assert.Equal(t, "one", readEvent(t, alice).Text)
+	assert.Equal(t, "two", readEvent(t, alice).Text)
+	e := readEvent(t, alice)
+	assert.Equal(t, "error", e.Type)
+	assert.Contains(t, e.Text, "slow down")
+	assert.Equal(t, float64(1), metrics.dropped.(*recorder).get("rate_limited"))
+}
+
+func TestSlowConsumerIsDisconnected(t *testing.T) {
+	metrics := newTestMetrics()
+	h, err := newHub(testConfig(), metrics, logx.NewNoopLogger())
+	require.NoError(t, err)
+
+	// A client without a writer never drains its send buffer
+	slow := newClient(h, nil, "lobby", "slow")
+	h.rooms["lobby"] = map[*client]struct{}{slow: {}}
+	h.clients = 1
+
+	notice, err := domain.NewNotice("lobby", "hello")
+	require.NoError(t, err)
+	for range h.cfg.SendBuffer {
+		assert.Equal(t, 1, h.Broadcast(notice))
+	}
+	assert.Equal(t, 0, h.Broadcast(notice))
+
+	assert.Empty(t, h.Rooms())
+	assert.Equal(t, float64(1), metrics.slow.(*recorder).get())
+	select {
+	case <-slow.closing:
+	default:
+		t.Fatal("slow client was not shut down")
+	}
+	code := int(slow.closeMsg[0])<<8 | int(slow.closeMsg[1])
+	assert.Equal(t, websocket.CloseTryAgainLater, code)
+}
+
+func TestMaxConnections(t *testing.T) {
+	cfg := testConfig()
+	cfg.MaxConnections = 1
+	h, metrics, url := newTestServer(t, cfg)
+
+	alice := dial(t, url, "lobby", "alice")
+	readEvent(t, alice)
+	assert.ErrorIs(t, h.Admit(), ErrFull)
+
+	bob := dial(t, url, "lobby", "bob")
+	bob.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := bob.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseTryAgainLater), "got %v", err)
+	assert.Equal(t, float64(1), metrics.rejected.(*recorder).get("full"))
+}
+
+func TestStopClosesConnections(t *testing.T) {
+	h, _, url := newTestServer(t, testConfig())
+	alice := dial(t, url, "lobby", "alice")
+	readEvent(t, alice)
+
+	// Queued before stop, so it is flushed before the close frame
+	notice, err := domain.NewNotice("lobby", "shutting down in 1s")
+	require.NoError(t, err)
+	h.Broadcast(notice)
+
+	require.NoError(t, h.stop(context.Background()))
+	assert.Equal(t, "shutting down in 1s", readEvent(t, alice).Text)
+	_, _, err = alice.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "got %v", err)
+
+	assert.ErrorIs(t, h.Admit(), ErrClosed)
+	assert.Empty(t, h.Rooms())
+}
+
+func TestNewHub(t *testing.T) {
+	cfg := testConfig()
+	cfg.SendBuffer = 0
+	_, err := newHub(cfg, newTestMetrics(), logx.NewNoopLogger())
+	assert.ErrorContains(t, err, "must be positive")
+}
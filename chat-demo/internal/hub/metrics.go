@@ -0,0 +1,63 @@
+package hub
+
+import "github.com/gostratum/metricsx"
+
+// Metrics records connections and message traffic
+type Metrics struct {
+	connections gauge
+	rooms       gauge
+	rejected    counter
+	received    counter
+	delivered   counter
+	dropped     counter
+	slow        counter
+	duration    histogram
+}
+
+// NewMetrics registers the hub metrics
+func NewMetrics(metrics metricsx.Metrics) *Metrics {
+	return &Metrics{
+		connections: metrics.Gauge("ws_connections",
+			metricsx.WithHelp("Open WebSocket connections"),
+		),
+		rooms: metrics.Gauge("ws_rooms",
+			metricsx.WithHelp("Rooms with at least one client"),
+		),
+		rejected: metrics.Counter("ws_connections_rejected_total",
+			metricsx.WithHelp("Connections refused, by reason (full, closed)"),
+			metricsx.WithLabels("reason"),
+		),
+		received: metrics.Counter("ws_messages_received_total",
+			metricsx.WithHelp("Messages read from clients"),
+		),
+		delivered: metrics.Counter("ws_messages_delivered_total",
+			metricsx.WithHelp("Messages written to clients"),
+		),
+		dropped: metrics.Counter("ws_messages_dropped_total",
+			metricsx.WithHelp("Messages from clients that were not broadcast, by reason (rate_limited, invalid)"),
+			metricsx.WithLabels("reason"),
+		),
+		slow: metrics.Counter("ws_slow_consumers_total",
+			metricsx.WithHelp("Clients disconnected because their send buffer was full"),
+		),
+		duration: metrics.Histogram("ws_connection_duration_seconds",
+			metricsx.WithHelp("How long connections stayed open"),
+			metricsx.WithBuckets(1, 10, 60, 300, 1800, 3600, 4*3600),
+		),
+	}
+}
+
+// counter is the part of metricsx.Counter the hub uses
+type counter interface {
+	Inc(labels ...string)
+}
+
+// histogram is the part of metricsx.Histogram the hub uses
+type histogram interface {
+	Observe(v float64, labels ...string)
+}
+
+// gauge is the part of metricsx.Gauge the hub uses
+type gauge interface {
+	Set(v float64, labels ...string)
+}
@@ -0,0 +1,14 @@
+package hub
+
+import "go.uber.org/fx"
+
+// Module provides the hub and its metrics, and closes every connection at stop
+func Module() fx.Option {
+	return fx.Module("hub",
+		fx.Provide(
+			NewMetrics,
+			NewHub,
+		),
+		fx.Invoke(Register),
+	)
+}
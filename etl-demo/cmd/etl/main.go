@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/dbx"
+	repoAdapter "github.com/gostratum/examples/etl-demo/internal/adapter/repo"
+	sourceAdapter "github.com/gostratum/examples/etl-demo/internal/adapter/source"
+	"github.com/gostratum/examples/etl-demo/internal/cli"
+	"github.com/gostratum/examples/etl-demo/internal/progress"
+	"github.com/gostratum/examples/etl-demo/internal/usecase"
+	"github.com/gostratum/examples/redact"
+	"github.com/gostratum/metricsx"
+	"github.com/gostratum/storagex"
+	s3Adapter "github.com/gostratum/storagex/adapters/s3"
+)
+
+func main() {
+	var cmd *cli.Command
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// Prometheus metrics of the run, served while it lasts; no httpx:
+		// the process ends when the load does
+		metricsx.Module(),
+
+		// Include dbx module; it migrates the database on startup
+		dbx.Module(),
+
+		// Include storagex module with S3 adapter; MinIO locally
+		storagex.Module(),
+		s3Adapter.Module(),
+
+		// Provide dependencies
+		fx.Provide(
+			// CSV files in the bucket, through storagex
+			sourceAdapter.NewSource,
+
+			// Sales and checkpoints in Postgres
+			repoAdapter.NewLoadRepo,
+
+			// Metrics and logs of the run
+			progress.NewMetrics,
+			progress.NewReporter,
+
+			usecase.NewPipelineService,
+			cli.NewCommand,
+		),
+
+		// Run the load once the application has started
+		fx.Invoke(cli.Register),
+		fx.Populate(&cmd),
+	)
+
+	// Run returns once the command has shut the application down, or after
+	// SIGINT/SIGTERM; it exits with status 1 by itself if startup fails
+	app.Run()
+	os.Exit(cmd.ExitCode())
+}
@@ -0,0 +1,90 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/gostratum/examples/etl-demo/internal/domain"
+)
+
+// LoadFileEntity represents the GORM model for load_files table
+type LoadFileEntity struct {
+	ID          int64  `gorm:"primaryKey"`
+	ObjectKey   string `gorm:"not null;uniqueIndex"`
+	ETag        string `gorm:"column:etag;not null"`
+	Status      string `gorm:"not null"`
+	Line        int    `gorm:"not null"`
+	Loaded      int    `gorm:"not null"`
+	Rejected    int    `gorm:"not null"`
+	Error       string `gorm:"not null"`
+	StartedAt   time.Time
+	UpdatedAt   time.Time
+	CompletedAt *time.Time
+}
+
+// TableName specifies the table name for LoadFileEntity
+func (LoadFileEntity) TableName() string {
+	return "load_files"
+}
+
+// ToDomain converts LoadFileEntity to domain.Checkpoint
+func (e *LoadFileEntity) ToDomain() domain.Checkpoint {
+	return domain.Checkpoint{
+		ID:          e.ID,
+		Key:         e.ObjectKey,
+		ETag:        e.ETag,
+		Status:      domain.FileStatus(e.Status),
+		Line:        e.Line,
+		Loaded:      e.Loaded,
+		Rejected:    e.Rejected,
+		Error:       e.Error,
+		StartedAt:   e.StartedAt,
+		UpdatedAt:   e.UpdatedAt,
+		CompletedAt: e.CompletedAt,
+	}
+}
+
+// SaleEntity represents the GORM model for sales table
+type SaleEntity struct {
+	FileID         int64     `gorm:"primaryKey"`
+	Line           int       `gorm:"primaryKey"`
+	OrderID        string    `gorm:"not null"`
+	SoldAt         time.Time `gorm:"not null"`
+	Store          string    `gorm:"not null"`
+	SKU            string    `gorm:"not null"`
+	Quantity       int       `gorm:"not null"`
+	UnitPriceCents int64     `gorm:"not null"`
+	Currency       string    `gorm:"not null"`
+	AmountCents    int64     `gorm:"not null"`
+}
+
+// TableName specifies the table name for SaleEntity
+func (SaleEntity) TableName() string {
+	return "sales"
+}
+
+// FromDomain creates SaleEntity from domain.Sale of the file fileID
+func (e *SaleEntity) FromDomain(fileID int64, s domain.Sale) {
+	e.FileID = fileID
+	e.Line = s.Line
+	e.OrderID = s.OrderID
+	e.SoldAt = s.SoldAt
+	e.Store = s.Store
+	e.SKU = s.SKU
+	e.Quantity = s.Quantity
+	e.UnitPriceCents = s.UnitPrice
+	e.Currency = s.Currency
+	e.AmountCents = s.Amount
+}
+
+// RejectedRowEntity represents the GORM model for rejected_rows table
+type RejectedRowEntity struct {
+	FileID int64  `gorm:"primaryKey"`
+	Line   int    `gorm:"primaryKey"`
+	Reason string `gorm:"not null"`
+	Record string `gorm:"not null"`
+}
+
+// TableName specifies the table name for RejectedRowEntity
+func (RejectedRowEntity) TableName() string {
+	return "rejected_rows"
+}
@@ -0,0 +1,152 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/gostratum/examples/etl-demo/internal/domain"
+	"github.com/gostratum/examples/etl-demo/internal/usecase"
+)
+
+// LoadRepo implements the LoadRepository interface using GORM
+type LoadRepo struct {
+	db *gorm.DB
+}
+
+// NewLoadRepo creates a new GORM-based load repository
+func NewLoadRepo(db *gorm.DB) usecase.LoadRepository {
+	return &LoadRepo{db: db}
+}
+
+// Start implements usecase.LoadRepository
+func (r *LoadRepo) Start(ctx context.Context, file domain.File) (domain.Checkpoint, error) {
+	var entity LoadFileEntity
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// Create the checkpoint of a new file; another run may get there first
+		fresh := LoadFileEntity{ObjectKey: file.Key, ETag: file.ETag, Status: string(domain.FileLoading), StartedAt: now, UpdatedAt: now}
+		err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "object_key"}}, DoNothing: true}).
+			Create(&fresh).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("object_key = ?", file.Key).
+			First(&entity).Error
+		if err != nil || entity.ETag == file.ETag {
+			return err
+		}
+
+		// The file was replaced: the rows of the earlier version go, and the
+		// load starts over
+		if err := tx.Where("file_id = ?", entity.ID).Delete(&SaleEntity{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("file_id = ?", entity.ID).Delete(&RejectedRowEntity{}).Error; err != nil {
+			return err
+		}
+		entity = LoadFileEntity{ID: entity.ID, ObjectKey: file.Key, ETag: file.ETag, Status: string(domain.FileLoading), StartedAt: now, UpdatedAt: now}
+		return tx.Save(&entity).Error
+	})
+	if err != nil {
+		return domain.Checkpoint{}, translateError(err)
+	}
+	return entity.ToDomain(), nil
+}
+
+// Commit implements usecase.LoadRepository
+func (r *LoadRepo) Commit(ctx context.Context, cp domain.Checkpoint, batch domain.Batch) (domain.Checkpoint, error) {
+	next := cp.Advance(batch, time.Now())
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Move the checkpoint first: the row lock keeps another run's commit
+		// to the file waiting until this one is done, and then finds it moved
+		err := r.move(tx, cp, map[string]any{
+			"status":       string(next.Status),
+			"line":         next.Line,
+			"loaded":       next.Loaded,
+			"rejected":     next.Rejected,
+			"updated_at":   next.UpdatedAt,
+			"completed_at": next.CompletedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(batch.Sales) > 0 {
+			sales := make([]SaleEntity, len(batch.Sales))
+			for i, s := range batch.Sales {
+				sales[i].FromDomain(cp.ID, s)
+			}
+			if err := tx.Create(&sales).Error; err != nil {
+				return err
+			}
+		}
+		if len(batch.Rejections) > 0 {
+			rejections := make([]RejectedRowEntity, len(batch.Rejections))
+			for i, rej := range batch.Rejections {
+				rejections[i] = RejectedRowEntity{FileID: cp.ID, Line: rej.Line, Reason: rej.Reason, Record: rej.Record}
+			}
+			if err := tx.Create(&rejections).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Checkpoint{}, translateError(err)
+	}
+	return next, nil
+}
+
+// Fail implements usecase.LoadRepository
+func (r *LoadRepo) Fail(ctx context.Context, cp domain.Checkpoint, reason string) (domain.Checkpoint, error) {
+	failed := cp.Fail(reason, time.Now())
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := r.move(tx, cp, map[string]any{
+			"status":     string(failed.Status),
+			"loaded":     failed.Loaded,
+			"error":      failed.Error,
+			"updated_at": failed.UpdatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		return tx.Where("file_id = ?", cp.ID).Delete(&SaleEntity{}).Error
+	})
+	if err != nil {
+		return domain.Checkpoint{}, translateError(err)
+	}
+	return failed, nil
+}
+
+// move updates the checkpoint cp to values, or fails with
+// usecase.ErrCheckpointMoved if it is no longer the stored one
+func (r *LoadRepo) move(tx *gorm.DB, cp domain.Checkpoint, values map[string]any) error {
+	result := tx.Model(&LoadFileEntity{}).
+		Where("id = ? AND etag = ? AND line = ? AND status = ?", cp.ID, cp.ETag, cp.Line, string(domain.FileLoading)).
+		Updates(values)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return usecase.ErrCheckpointMoved
+	}
+	return nil
+}
+
+// translateError marks database errors as usecase.ErrUnavailable; the
+// original error stays in the chain, so a cancelled context can still be
+// told apart
+func translateError(err error) error {
+	if errors.Is(err, usecase.ErrCheckpointMoved) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", usecase.ErrUnavailable, err)
+}
@@ -0,0 +1,226 @@
+package repo
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/gostratum/examples/etl-demo/internal/domain"
+	"github.com/gostratum/examples/etl-demo/internal/usecase"
+	"github.com/gostratum/examples/etl-demo/migrations"
+	"github.com/gostratum/examples/testkit/containers"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(containers.Run(m))
+}
+
+// setupTestDB creates a Postgres database with the service's migrations applied
+func setupTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	return containers.PostgresGorm(t, containers.WithMigrations(migrations.FS))
+}
+
+// batch returns a batch with sales on lines and a rejection on rejected
+func batch(rejected int, lines ...int) domain.Batch {
+	b := domain.Batch{Line: rejected}
+	for _, line := range lines {
+		b.Sales = append(b.Sales, domain.Sale{
+			Line:      line,
+			OrderID:   "ORD-1",
+			SoldAt:    time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC),
+			Store:     "AMS-01",
+			SKU:       "WIDGET-1",
+			Quantity:  2,
+			UnitPrice: 999,
+			Currency:  "EUR",
+			Amount:    1998,
+		})
+		b.Line = max(b.Line, line)
+	}
+	if rejected > 0 {
+		b.Rejections = []domain.Rejection{{Line: rejected, Reason: "invalid row: quantity must be 1 to 10000, got 0", Record: "ORD-1,..."}}
+	}
+	return b
+}
+
+// count returns the rows of table that came from the file fileID
+func count(t *testing.T, db *gorm.DB, table string, fileID int64) int64 {
+	t.Helper()
+	var n int64
+	require.NoError(t, db.Table(table).Where("file_id = ?", fileID).Count(&n).Error)
+	return n
+}
+
+func TestLoadRepoStartAndCommit(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewLoadRepo(db)
+	ctx := context.Background()
+	file := domain.File{Key: "incoming/a.csv", ETag: `"v1"`}
+
+	cp, err := repo.Start(ctx, file)
+	require.NoError(t, err)
+	assert.NotZero(t, cp.ID)
+	assert.Equal(t, domain.FileLoading, cp.Status)
+	assert.Zero(t, cp.Line)
+
+	cp, err = repo.Commit(ctx, cp, batch(4, 2, 3))
+	require.NoError(t, err)
+	assert.Equal(t, 4, cp.Line)
+
+	last := batch(0, 5)
+	last.Last = true
+	cp, err = repo.Commit(ctx, cp, last)
+	require.NoError(t, err)
+	assert.Equal(t, domain.FileCompleted, cp.Status)
+
+	stored, err := repo.Start(ctx, file)
+	require.NoError(t, err)
+	assert.Equal(t, cp.ID, stored.ID)
+	assert.Equal(t, domain.FileCompleted, stored.Status)
+	assert.Equal(t, 5, stored.Line)
+	assert.Equal(t, 3, stored.Loaded)
+	assert.Equal(t, 1, stored.Rejected)
+	require.NotNil(t, stored.CompletedAt)
+
+	assert.EqualValues(t, 3, count(t, db, "sales", cp.ID))
+	assert.EqualValues(t, 1, count(t, db, "rejected_rows", cp.ID))
+
+	var sale SaleEntity
+	require.NoError(t, db.Where("file_id = ? AND line = ?", cp.ID, 3).First(&sale).Error)
+	assert.Equal(t, int64(1998), sale.AmountCents)
+	assert.True(t, sale.SoldAt.Equal(time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)))
+}
+
+func TestLoadRepoCommitRefusesMovedCheckpoint(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewLoadRepo(db)
+	ctx := context.Background()
+
+	cp, err := repo.Start(ctx, domain.File{Key: "incoming/a.csv", ETag: `"v1"`})
+	require.NoError(t, err)
+
+	// Two runs read the same checkpoint; the second to commit loses
+	_, err = repo.Commit(ctx, cp, batch(0, 2, 3))
+	require.NoError(t, err)
+	_, err = repo.Commit(ctx, cp, batch(0, 2, 3))
+	assert.ErrorIs(t, err, usecase.ErrCheckpointMoved)
+
+	assert.EqualValues(t, 2, count(t, db, "sales", cp.ID), "the losing batch is not stored")
+}
+
+func TestLoadRepoConcurrentCommits(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewLoadRepo(db)
+	ctx := context.Background()
+
+	cp, err := repo.Start(ctx, domain.File{Key: "incoming/a.csv", ETag: `"v1"`})
+	require.NoError(t, err)
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		moved int
+	)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.Commit(ctx, cp, batch(0, 2, 3))
+			if err != nil {
+				assert.ErrorIs(t, err, usecase.ErrCheckpointMoved)
+				mu.Lock()
+				moved++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 9, moved, "exactly one commit wins")
+	assert.EqualValues(t, 2, count(t, db, "sales", cp.ID))
+}
+
+func TestLoadRepoStartsReplacedFileOver(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewLoadRepo(db)
+	ctx := context.Background()
+
+	cp, err := repo.Start(ctx, domain.File{Key: "incoming/a.csv", ETag: `"v1"`})
+	require.NoError(t, err)
+	_, err = repo.Commit(ctx, cp, batch(4, 2, 3))
+	require.NoError(t, err)
+
+	replaced, err := repo.Start(ctx, domain.File{Key: "incoming/a.csv", ETag: `"v2"`})
+	require.NoError(t, err)
+	assert.Equal(t, cp.ID, replaced.ID)
+	assert.Equal(t, `"v2"`, replaced.ETag)
+	assert.Equal(t, domain.FileLoading, replaced.Status)
+	assert.Zero(t, replaced.Line)
+	assert.Zero(t, replaced.Loaded)
+	assert.Zero(t, replaced.Rejected)
+
+	assert.Zero(t, count(t, db, "sales", cp.ID), "the rows of the earlier version are deleted")
+	assert.Zero(t, count(t, db, "rejected_rows", cp.ID))
+
+	_, err = repo.Commit(ctx, cp, batch(0, 5))
+	assert.ErrorIs(t, err, usecase.ErrCheckpointMoved, "a run still on the earlier version cannot commit")
+}
+
+func TestLoadRepoFail(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewLoadRepo(db)
+	ctx := context.Background()
+
+	cp, err := repo.Start(ctx, domain.File{Key: "incoming/a.csv", ETag: `"v1"`})
+	require.NoError(t, err)
+	cp, err = repo.Commit(ctx, cp, batch(4, 2, 3))
+	require.NoError(t, err)
+
+	failed, err := repo.Fail(ctx, cp, "too many rejected rows: more than 0")
+	require.NoError(t, err)
+	assert.Equal(t, domain.FileFailed, failed.Status)
+
+	stored, err := repo.Start(ctx, domain.File{Key: "incoming/a.csv", ETag: `"v1"`})
+	require.NoError(t, err)
+	assert.Equal(t, domain.FileFailed, stored.Status)
+	assert.Equal(t, "too many rejected rows: more than 0", stored.Error)
+	assert.Zero(t, stored.Loaded)
+	assert.Zero(t, count(t, db, "sales", cp.ID))
+	assert.EqualValues(t, 1, count(t, db, "rejected_rows", cp.ID), "rejected rows are kept for review")
+}
+
+func TestLoadRepoConcurrentStarts(t *testing.T) {
+	t.Parallel()
+	repo := NewLoadRepo(setupTestDB(t))
+	ctx := context.Background()
+	file := domain.File{Key: "incoming/a.csv", ETag: `"v1"`}
+
+	ids := make([]int64, 5)
+	var wg sync.WaitGroup
+	for i := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cp, err := repo.Start(ctx, file)
+			assert.NoError(t, err)
+			ids[i] = cp.ID
+		}()
+	}
+	wg.Wait()
+
+	for _, id := range ids {
+		assert.Equal(t, ids[0], id, "every run gets the same checkpoint")
+	}
+}
@@ -0,0 +1,114 @@
+package source
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gostratum/examples/etl-demo/internal/domain"
+)
+
+// Reader reads records from a CSV stream with a header line. It implements
+// usecase.RecordReader.
+type Reader struct {
+	body   io.Closer
+	csv    *csv.Reader
+	header []string
+}
+
+func newReader(body io.ReadCloser) *Reader {
+	c := csv.NewReader(body)
+	c.TrimLeadingSpace = true
+	// Rows with missing or extra fields are reported per row, not as a fatal error
+	c.FieldsPerRecord = -1
+	return &Reader{body: body, csv: c}
+}
+
+// readHeader reads the header line and checks it has every column of
+// domain.Columns. It is read with the first record, so opening a file
+// costs no more than the request.
+func (r *Reader) readHeader() error {
+	header, err := r.csv.Read()
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: file is empty", domain.ErrInvalidFile)
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return fmt.Errorf("%w: failed to read header: %v", domain.ErrInvalidFile, parseErr.Err)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Spreadsheet exports often start with a byte order mark
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+
+	seen := make(map[string]bool, len(header))
+	for i, name := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(name))
+		seen[header[i]] = true
+	}
+	for _, name := range domain.Columns {
+		if !seen[name] {
+			return fmt.Errorf("%w: header has no %q column", domain.ErrInvalidFile, name)
+		}
+	}
+	r.header = header
+	return nil
+}
+
+// Next implements usecase.RecordReader
+func (r *Reader) Next() (domain.Record, error) {
+	if r.header == nil {
+		if err := r.readHeader(); err != nil {
+			return domain.Record{}, err
+		}
+	}
+
+	fields, err := r.csv.Read()
+	if errors.Is(err, io.EOF) {
+		return domain.Record{}, io.EOF
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		// A malformed line, such as a stray quote; the reader skips past it
+		return domain.Record{}, &domain.RowError{Line: parseErr.StartLine, Err: fmt.Errorf("%w: %v", domain.ErrInvalidRow, parseErr.Err)}
+	}
+	if err != nil {
+		return domain.Record{}, err
+	}
+	line, _ := r.csv.FieldPos(0)
+	raw := encode(fields)
+
+	if len(fields) != len(r.header) {
+		return domain.Record{}, &domain.RowError{
+			Line:   line,
+			Err:    fmt.Errorf("%w: %d fields, the header has %d", domain.ErrInvalidRow, len(fields), len(r.header)),
+			Record: raw,
+		}
+	}
+
+	values := make(map[string]string, len(fields))
+	for i, name := range r.header {
+		values[name] = fields[i]
+	}
+	return domain.Record{Line: line, Fields: values, Raw: raw}, nil
+}
+
+// Close closes the stream
+func (r *Reader) Close() error {
+	return r.body.Close()
+}
+
+// encode writes fields back as a CSV line, to keep rejected rows as they
+// appeared in the file
+func encode(fields []string) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write(fields)
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
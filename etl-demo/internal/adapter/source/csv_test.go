@@ -0,0 +1,74 @@
+package source
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/etl-demo/internal/domain"
+)
+
+func reader(src string) *Reader {
+	return newReader(io.NopCloser(strings.NewReader(src)))
+}
+
+func TestReader(t *testing.T) {
+	src := "\ufeffOrder_ID, sold_at ,store,sku,quantity,unit_price,currency,note\n" +
+		"ORD-1,2025-03-01 09:30:00,AMS-01,WIDGET-1,2,9.99,EUR,\"first, of the day\"\n" +
+		"ORD-2,2025-03-01 09:31:00,AMS-01,WIDGET-1,1,9.99\n" +
+		"\n" +
+		"ORD-3,2025-03-01 09:32:00,AMS-01,\"GADGET\n-1\",1,4.50,EUR,\n" +
+		"ORD-4,2025-03-01 09:33:00,AMS-01,\"WIDGET-1,1,9.99,EUR,\n"
+	r := reader(src)
+
+	record, err := r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, 2, record.Line)
+	assert.Equal(t, "ORD-1", record.Fields["order_id"])
+	assert.Equal(t, "2025-03-01 09:30:00", record.Fields["sold_at"])
+	assert.Equal(t, "first, of the day", record.Fields["note"])
+	assert.Equal(t, `ORD-1,2025-03-01 09:30:00,AMS-01,WIDGET-1,2,9.99,EUR,"first, of the day"`, record.Raw)
+
+	_, err = r.Next()
+	var rowErr *domain.RowError
+	require.ErrorAs(t, err, &rowErr)
+	assert.Equal(t, 3, rowErr.Line)
+	assert.ErrorIs(t, err, domain.ErrInvalidRow)
+	assert.Contains(t, err.Error(), "6 fields, the header has 8")
+	assert.Equal(t, "ORD-2,2025-03-01 09:31:00,AMS-01,WIDGET-1,1,9.99", rowErr.Record)
+
+	record, err = r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, 5, record.Line, "a record is numbered by the line it starts on")
+	assert.Equal(t, "GADGET\n-1", record.Fields["sku"])
+
+	_, err = r.Next()
+	require.ErrorAs(t, err, &rowErr)
+	assert.Equal(t, 7, rowErr.Line)
+
+	_, err = r.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestReaderHeader(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{name: "empty", src: "", want: "file is empty"},
+		{name: "missing column", src: "order_id,sold_at,store,quantity,unit_price,currency\n", want: `header has no "sku" column`},
+		{name: "malformed", src: "order_id,\"sold_at\n", want: "failed to read header"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := reader(tt.src).Next()
+			assert.ErrorIs(t, err, domain.ErrInvalidFile)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
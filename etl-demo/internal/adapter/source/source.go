@@ -0,0 +1,66 @@
+// Package source implements usecase.Source: it lists the CSV files in the
+// bucket through storagex and reads their records
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gostratum/storagex"
+
+	"github.com/gostratum/examples/etl-demo/internal/domain"
+	"github.com/gostratum/examples/etl-demo/internal/usecase"
+)
+
+// listPageSize is how many keys one List request asks for
+const listPageSize = 1000
+
+// Source reads files through storagex, so the same code runs against S3,
+// MinIO or any other backend storagex has an adapter for
+type Source struct {
+	storage storagex.Storage
+}
+
+// NewSource creates the source
+func NewSource(storage storagex.Storage) usecase.Source {
+	return &Source{storage: storage}
+}
+
+// List implements usecase.Source. Only keys ending in .csv are listed.
+func (s *Source) List(ctx context.Context, prefix string) ([]domain.File, error) {
+	var files []domain.File
+	opts := storagex.ListOptions{Prefix: prefix, PageSize: listPageSize}
+	for {
+		page, err := s.storage.List(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to list %s: %v", usecase.ErrUnavailable, prefix, err)
+		}
+		for _, stat := range page.Keys {
+			if strings.HasSuffix(strings.ToLower(stat.Key), ".csv") {
+				files = append(files, domain.File{Key: stat.Key, ETag: stat.ETag, Size: stat.Size})
+			}
+		}
+		if !page.IsTruncated || page.NextToken == "" {
+			break
+		}
+		opts.ContinuationToken = page.NextToken
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Key < files[j].Key })
+	return files, nil
+}
+
+// Open implements usecase.Source
+func (s *Source) Open(ctx context.Context, key string) (usecase.RecordReader, domain.File, error) {
+	body, stat, err := s.storage.Get(ctx, key)
+	if errors.Is(err, storagex.ErrNotFound) {
+		return nil, domain.File{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, domain.File{}, fmt.Errorf("%w: failed to get %s: %v", usecase.ErrUnavailable, key, err)
+	}
+	return newReader(body), domain.File{Key: key, ETag: stat.ETag, Size: stat.Size}, nil
+}
@@ -0,0 +1,88 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gostratum/storagex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/etl-demo/internal/domain"
+	"github.com/gostratum/examples/etl-demo/internal/usecase"
+)
+
+// fakeStorage serves List from pages and Get from objects
+type fakeStorage struct {
+	storagex.Storage
+	pages   map[string]storagex.ListPage
+	objects map[string]string
+	err     error
+}
+
+func (f *fakeStorage) List(ctx context.Context, opts storagex.ListOptions) (storagex.ListPage, error) {
+	if f.err != nil {
+		return storagex.ListPage{}, f.err
+	}
+	return f.pages[opts.ContinuationToken], nil
+}
+
+func (f *fakeStorage) Get(ctx context.Context, key string) (io.ReadCloser, storagex.Stat, error) {
+	if f.err != nil {
+		return nil, storagex.Stat{}, f.err
+	}
+	content, ok := f.objects[key]
+	if !ok {
+		return nil, storagex.Stat{}, storagex.ErrNotFound
+	}
+	return io.NopCloser(strings.NewReader(content)), storagex.Stat{Key: key, ETag: `"v2"`, Size: int64(len(content))}, nil
+}
+
+func TestListFollowsPagesAndKeepsCSVFiles(t *testing.T) {
+	fake := &fakeStorage{pages: map[string]storagex.ListPage{
+		"": {Keys: []storagex.Stat{
+			{Key: "incoming/2025-03-02.csv", ETag: `"b"`},
+			{Key: "incoming/README.txt", ETag: `"r"`},
+		}, NextToken: "page-2", IsTruncated: true},
+		"page-2": {Keys: []storagex.Stat{{Key: "incoming/2025-03-01.CSV", ETag: `"a"`, Size: 42}}},
+	}}
+
+	files, err := NewSource(fake).List(context.Background(), "incoming/")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.File{
+		{Key: "incoming/2025-03-01.CSV", ETag: `"a"`, Size: 42},
+		{Key: "incoming/2025-03-02.csv", ETag: `"b"`},
+	}, files)
+}
+
+func TestOpen(t *testing.T) {
+	header := strings.Join(domain.Columns, ",")
+	fake := &fakeStorage{objects: map[string]string{
+		"incoming/a.csv": header + "\nORD-1,2025-03-01 09:30:00,AMS-01,WIDGET-1,2,9.99,EUR\n",
+	}}
+	source := NewSource(fake)
+
+	r, file, err := source.Open(context.Background(), "incoming/a.csv")
+	require.NoError(t, err)
+	defer r.Close()
+	assert.Equal(t, `"v2"`, file.ETag, "the version read, not the one listed")
+	record, err := r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "ORD-1", record.Fields["order_id"])
+
+	_, _, err = source.Open(context.Background(), "incoming/gone.csv")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestStorageErrorsAreUnavailable(t *testing.T) {
+	source := NewSource(&fakeStorage{err: errors.New("connection refused")})
+
+	_, err := source.List(context.Background(), "incoming/")
+	assert.ErrorIs(t, err, usecase.ErrUnavailable)
+
+	_, _, err = source.Open(context.Background(), "incoming/a.csv")
+	assert.ErrorIs(t, err, usecase.ErrUnavailable)
+}
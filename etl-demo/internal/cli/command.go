@@ -0,0 +1,185 @@
+// Package cli runs the load as a one-shot command inside the fx application:
+// it starts when the application has started, and stops the application with
+// an exit code when it is done
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"go.uber.org/fx"
+
+	"github.com/gostratum/examples/etl-demo/internal/usecase"
+)
+
+// Exit codes of the command
+const (
+	// ExitOK: every file was loaded, or had been before, without rejected rows
+	ExitOK = 0
+	// ExitFailure: the load could not run or stopped early
+	ExitFailure = 1
+	// ExitRejected: the load finished, but rows of the files it loaded were
+	// rejected, or files failed
+	ExitRejected = 3
+	// ExitInterrupted: stopped by SIGINT or SIGTERM (128 + SIGINT, as shells report it)
+	ExitInterrupted = 130
+)
+
+// Config tunes the load
+type Config struct {
+	// SourcePrefix is where in the bucket the files to load are
+	SourcePrefix string `mapstructure:"source_prefix" default:"incoming/"`
+	// BatchSize is how many rows are committed per transaction, together
+	// with the checkpoint; a stopped run loses at most one batch of work
+	BatchSize int `mapstructure:"batch_size" default:"500"`
+	// MaxRejected fails a file once more of its rows were rejected; -1 for no limit
+	MaxRejected int `mapstructure:"max_rejected" default:"100"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "etl"
+}
+
+// Pipeline loads the files; implemented by usecase.PipelineService
+type Pipeline interface {
+	Run(ctx context.Context, prefix string, batchSize, maxRejected int) (usecase.Report, error)
+}
+
+// Command loads every new file in the bucket
+type Command struct {
+	cfg        Config
+	pipeline   Pipeline
+	shutdowner fx.Shutdowner
+	log        logx.Logger
+
+	// cancel stops the load when the application is stopped from outside
+	cancel context.CancelFunc
+	// done is closed once the load has finished and code is set
+	done chan struct{}
+	code int
+}
+
+// NewCommand creates the command from the etl config section
+func NewCommand(loader configx.Loader, service *usecase.PipelineService, shutdowner fx.Shutdowner, log logx.Logger) (*Command, error) {
+	var cfg Config
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load etl config: %w", err)
+	}
+	return newCommand(cfg, service, shutdowner, log), nil
+}
+
+func newCommand(cfg Config, pipeline Pipeline, shutdowner fx.Shutdowner, log logx.Logger) *Command {
+	return &Command{
+		cfg:        cfg,
+		pipeline:   pipeline,
+		shutdowner: shutdowner,
+		log:        log,
+		done:       make(chan struct{}),
+	}
+}
+
+// Register ties the command to the application lifecycle.
+// This function is designed to be used with fx.Invoke.
+func Register(lc fx.Lifecycle, cmd *Command) {
+	lc.Append(fx.Hook{
+		OnStart: cmd.start,
+		OnStop:  cmd.stop,
+	})
+}
+
+// start runs the load in the background; start hooks must return quickly
+func (c *Command) start(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	go func() {
+		c.code = c.run(ctx)
+		close(c.done)
+
+		// Stop the application, which makes app.Run return. When stop
+		// cancelled the load, the application is already stopping.
+		if ctx.Err() != nil {
+			return
+		}
+		if err := c.shutdowner.Shutdown(); err != nil {
+			c.log.Error("failed to shut down", logx.Err(err))
+		}
+	}()
+	return nil
+}
+
+// stop interrupts a load that is still running, on a signal. The batch in
+// flight is rolled back; the next run resumes from the last committed one.
+func (c *Command) stop(ctx context.Context) error {
+	c.cancel()
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return errors.New("load did not stop in time")
+	}
+}
+
+// ExitCode is the code the process should exit with once the application has
+// stopped. It is ExitInterrupted if the load never finished.
+func (c *Command) ExitCode() int {
+	select {
+	case <-c.done:
+		return c.code
+	default:
+		return ExitInterrupted
+	}
+}
+
+// run loads the files and decides the exit code
+func (c *Command) run(ctx context.Context) int {
+	c.log.Info("load started",
+		logx.String("prefix", c.cfg.SourcePrefix),
+		logx.Int("batch_size", c.cfg.BatchSize),
+		logx.Int("max_rejected", c.cfg.MaxRejected),
+	)
+
+	start := time.Now()
+	report, err := c.pipeline.Run(ctx, c.cfg.SourcePrefix, c.cfg.BatchSize, c.cfg.MaxRejected)
+
+	fields := []logx.Field{
+		logx.Int("files", len(report.Files)),
+		logx.Int("loaded", report.Count(usecase.ResultLoaded)),
+		logx.Int("skipped", report.Count(usecase.ResultSkipped)),
+		logx.Int("failed", report.Count(usecase.ResultFailed)),
+		logx.Int("busy", report.Count(usecase.ResultBusy)),
+		logx.Int("rejected_rows", report.Rejected()),
+		logx.String("duration", time.Since(start).String()),
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		c.log.Warn("load interrupted; the next run resumes it", fields...)
+		return ExitInterrupted
+	case err != nil:
+		c.log.Error("load failed; the next run resumes it", append(fields, logx.Err(err))...)
+		return ExitFailure
+	case report.Count(usecase.ResultFailed) > 0 || rejectedInRun(report) > 0:
+		c.log.Warn("load finished with rejected rows or failed files", fields...)
+		return ExitRejected
+	default:
+		c.log.Info("load finished", fields...)
+		return ExitOK
+	}
+}
+
+// rejectedInRun counts the rows rejected in the files loaded in this run;
+// the files skipped were reported by the run that loaded them
+func rejectedInRun(report usecase.Report) int {
+	n := 0
+	for _, f := range report.Files {
+		if f.Result == usecase.ResultLoaded {
+			n += f.Rejected
+		}
+	}
+	return n
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+
+	"github.com/gostratum/examples/etl-demo/internal/usecase"
+)
+
+// fakeShutdowner counts shutdown requests
+type fakeShutdowner struct {
+	calls chan struct{}
+}
+
+func (s *fakeShutdowner) Shutdown(...fx.ShutdownOption) error {
+	s.calls <- struct{}{}
+	return nil
+}
+
+// pipelineFunc adapts a function to Pipeline
+type pipelineFunc func(ctx context.Context) (usecase.Report, error)
+
+func (f pipelineFunc) Run(ctx context.Context, prefix string, batchSize, maxRejected int) (usecase.Report, error) {
+	return f(ctx)
+}
+
+// returns is a pipeline that ends with files and err
+func returns(err error, files ...usecase.FileReport) Pipeline {
+	return pipelineFunc(func(context.Context) (usecase.Report, error) {
+		return usecase.Report{Files: files}, err
+	})
+}
+
+func TestCommandExitCodes(t *testing.T) {
+	loaded := usecase.FileReport{Key: "incoming/a.csv", Result: usecase.ResultLoaded, Loaded: 10}
+	skipped := usecase.FileReport{Key: "incoming/b.csv", Result: usecase.ResultSkipped, Loaded: 10, Rejected: 2}
+
+	tests := []struct {
+		name     string
+		pipeline Pipeline
+		want     int
+	}{
+		{name: "nothing to load", pipeline: returns(nil), want: ExitOK},
+		{name: "all loaded", pipeline: returns(nil, loaded), want: ExitOK},
+		{name: "rows rejected in an earlier run", pipeline: returns(nil, loaded, skipped), want: ExitOK},
+		{name: "rows rejected", pipeline: returns(nil, usecase.FileReport{Key: "incoming/a.csv", Result: usecase.ResultLoaded, Loaded: 9, Rejected: 1}), want: ExitRejected},
+		{name: "file failed", pipeline: returns(nil, loaded, usecase.FileReport{Key: "incoming/c.csv", Result: usecase.ResultFailed, Error: "invalid file: file is empty"}), want: ExitRejected},
+		{name: "database unavailable", pipeline: returns(fmt.Errorf("%w: connection refused", usecase.ErrUnavailable), loaded), want: ExitFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shutdowner := &fakeShutdowner{calls: make(chan struct{}, 1)}
+			cmd := newCommand(Config{SourcePrefix: "incoming/", BatchSize: 100, MaxRejected: -1},
+				tt.pipeline, shutdowner, logx.NewNoopLogger())
+
+			require.NoError(t, cmd.start(context.Background()))
+			select {
+			case <-shutdowner.calls:
+			case <-time.After(time.Second):
+				t.Fatal("command did not shut the application down")
+			}
+			require.NoError(t, cmd.stop(context.Background()))
+			assert.Equal(t, tt.want, cmd.ExitCode())
+		})
+	}
+}
+
+func TestCommandInterrupted(t *testing.T) {
+	started := make(chan struct{})
+	blocking := pipelineFunc(func(ctx context.Context) (usecase.Report, error) {
+		close(started)
+		<-ctx.Done()
+		return usecase.Report{}, ctx.Err()
+	})
+	shutdowner := &fakeShutdowner{calls: make(chan struct{}, 1)}
+	cmd := newCommand(Config{SourcePrefix: "incoming/", BatchSize: 100, MaxRejected: -1},
+		blocking, shutdowner, logx.NewNoopLogger())
+
+	require.NoError(t, cmd.start(context.Background()))
+	<-started
+	assert.Equal(t, ExitInterrupted, cmd.ExitCode())
+
+	// What a signal does: the application stops while the load is running
+	require.NoError(t, cmd.stop(context.Background()))
+	assert.Equal(t, ExitInterrupted, cmd.ExitCode())
+	assert.Empty(t, shutdowner.calls)
+}
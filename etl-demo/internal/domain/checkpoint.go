@@ -0,0 +1,89 @@
+package domain
+
+import "time"
+
+// File is a source file in the bucket. Its ETag changes whenever the file is
+// replaced.
+type File struct {
+	Key  string
+	ETag string
+	Size int64
+}
+
+// FileStatus is where the load of a file stands
+type FileStatus string
+
+const (
+	// FileLoading files have been started and not finished; the next run
+	// resumes them from their checkpoint
+	FileLoading FileStatus = "loading"
+	// FileCompleted files have been loaded to the end
+	FileCompleted FileStatus = "completed"
+	// FileFailed files were given up on, for too many rejected rows. They
+	// are loaded again only once they are replaced.
+	FileFailed FileStatus = "failed"
+)
+
+// Checkpoint is how far the load of one version of a file has got. Every row
+// up to and including Line has been loaded or rejected, in the same
+// transaction that moved the checkpoint there.
+type Checkpoint struct {
+	ID     int64
+	Key    string
+	ETag   string
+	Status FileStatus
+	// Line is the last line committed; 0 before the first batch
+	Line     int
+	Loaded   int
+	Rejected int
+	// Error says why a failed file was given up on
+	Error       string
+	StartedAt   time.Time
+	UpdatedAt   time.Time
+	CompletedAt *time.Time
+}
+
+// Done reports whether the file needs no more work in this version
+func (c Checkpoint) Done() bool {
+	return c.Status == FileCompleted || c.Status == FileFailed
+}
+
+// Advance returns the checkpoint once b is committed
+func (c Checkpoint) Advance(b Batch, now time.Time) Checkpoint {
+	next := c
+	next.Line = max(c.Line, b.Line)
+	next.Loaded += len(b.Sales)
+	next.Rejected += len(b.Rejections)
+	next.UpdatedAt = now
+	if b.Last {
+		next.Status = FileCompleted
+		next.CompletedAt = &now
+	}
+	return next
+}
+
+// Fail returns the checkpoint of a file given up on. Its sales are deleted
+// with it, so it counts none as loaded.
+func (c Checkpoint) Fail(reason string, now time.Time) Checkpoint {
+	next := c
+	next.Status = FileFailed
+	next.Loaded = 0
+	next.Error = reason
+	next.UpdatedAt = now
+	return next
+}
+
+// Batch is the rows of a file committed together with the checkpoint
+type Batch struct {
+	Sales      []Sale
+	Rejections []Rejection
+	// Line is the last line the batch covers
+	Line int
+	// Last is set on the batch that ends the file
+	Last bool
+}
+
+// Len is the number of rows in the batch
+func (b Batch) Len() int {
+	return len(b.Sales) + len(b.Rejections)
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpointAdvance(t *testing.T) {
+	now := time.Date(2025, 3, 2, 1, 0, 0, 0, time.UTC)
+	cp := Checkpoint{ID: 1, Status: FileLoading, Line: 10, Loaded: 8, Rejected: 1}
+
+	next := cp.Advance(Batch{Sales: make([]Sale, 3), Rejections: make([]Rejection, 1), Line: 14}, now)
+	assert.Equal(t, 14, next.Line)
+	assert.Equal(t, 11, next.Loaded)
+	assert.Equal(t, 2, next.Rejected)
+	assert.Equal(t, FileLoading, next.Status)
+	assert.False(t, next.Done())
+	assert.Equal(t, 10, cp.Line, "the checkpoint it started from is unchanged")
+
+	last := next.Advance(Batch{Last: true}, now)
+	assert.Equal(t, 14, last.Line, "an empty last batch keeps the line")
+	assert.Equal(t, FileCompleted, last.Status)
+	assert.Equal(t, &now, last.CompletedAt)
+	assert.True(t, last.Done())
+}
+
+func TestCheckpointFail(t *testing.T) {
+	now := time.Date(2025, 3, 2, 1, 0, 0, 0, time.UTC)
+	cp := Checkpoint{ID: 1, Status: FileLoading, Line: 10, Loaded: 8, Rejected: 3}
+
+	failed := cp.Fail("too many rejected rows", now)
+	assert.Equal(t, FileFailed, failed.Status)
+	assert.Zero(t, failed.Loaded)
+	assert.Equal(t, 3, failed.Rejected, "rejected rows are kept for review")
+	assert.Equal(t, "too many rejected rows", failed.Error)
+	assert.True(t, failed.Done())
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "errors"
+
+// Domain errors represent business rule violations
+var (
+	// ErrInvalidRow indicates a row violates the sales rules or cannot be parsed
+	ErrInvalidRow = errors.New("invalid row")
+
+	// ErrInvalidFile indicates a file that cannot be loaded at all, such as
+	// one without the required columns
+	ErrInvalidFile = errors.New("invalid file")
+
+	// ErrNotFound indicates the file was deleted after it was listed
+	ErrNotFound = errors.New("not found")
+)
@@ -0,0 +1,196 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Columns a sales export must have, in any order; other columns are ignored
+var Columns = []string{"order_id", "sold_at", "store", "sku", "quantity", "unit_price", "currency"}
+
+// Limits of the sales rules
+const (
+	maxOrderIDLength = 64
+	maxStoreLength   = 32
+	maxSKULength     = 64
+	maxQuantity      = 10000
+	// maxPrice bounds amounts in whole units, so no amount overflows
+	maxPrice = 1_000_000_000
+)
+
+// timeLayouts are the formats sold_at is accepted in; times without a zone
+// are UTC, as the point-of-sale exports write them
+var timeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05"}
+
+// Record is one line of a source file as read, before validation
+type Record struct {
+	// Line is the line of the file the record starts on
+	Line int
+	// Fields holds the values by lower-case column name
+	Fields map[string]string
+	// Raw is the record as it appeared in the file, kept with rejections
+	Raw string
+}
+
+// Sale is one line of an order, transformed for loading
+// This is a pure domain model without infrastructure concerns
+type Sale struct {
+	Line     int
+	OrderID  string
+	SoldAt   time.Time
+	Store    string
+	SKU      string
+	Quantity int
+	// UnitPrice and Amount are in cents of Currency
+	UnitPrice int64
+	Currency  string
+	Amount    int64
+}
+
+// ParseSale validates a record and transforms it into a sale: fields are
+// trimmed, codes upper-cased, times converted to UTC and prices to cents.
+// Errors wrap ErrInvalidRow and name the field at fault.
+func ParseSale(r Record) (Sale, error) {
+	s := Sale{
+		Line:     r.Line,
+		OrderID:  strings.TrimSpace(r.Fields["order_id"]),
+		Store:    strings.ToUpper(strings.TrimSpace(r.Fields["store"])),
+		SKU:      strings.ToUpper(strings.TrimSpace(r.Fields["sku"])),
+		Currency: strings.ToUpper(strings.TrimSpace(r.Fields["currency"])),
+	}
+
+	if err := checkText("order_id", s.OrderID, maxOrderIDLength); err != nil {
+		return Sale{}, err
+	}
+	if err := checkText("store", s.Store, maxStoreLength); err != nil {
+		return Sale{}, err
+	}
+	if err := checkText("sku", s.SKU, maxSKULength); err != nil {
+		return Sale{}, err
+	}
+
+	soldAt, err := parseTime(r.Fields["sold_at"])
+	if err != nil {
+		return Sale{}, err
+	}
+	s.SoldAt = soldAt
+
+	quantity, err := strconv.Atoi(strings.TrimSpace(r.Fields["quantity"]))
+	if err != nil {
+		return Sale{}, fmt.Errorf("%w: quantity %q is not a whole number", ErrInvalidRow, r.Fields["quantity"])
+	}
+	if quantity < 1 || quantity > maxQuantity {
+		return Sale{}, fmt.Errorf("%w: quantity must be 1 to %d, got %d", ErrInvalidRow, maxQuantity, quantity)
+	}
+	s.Quantity = quantity
+
+	price, err := ParseCents(r.Fields["unit_price"])
+	if err != nil {
+		return Sale{}, fmt.Errorf("%w: unit_price %v", ErrInvalidRow, err)
+	}
+	s.UnitPrice = price
+
+	if !isCurrency(s.Currency) {
+		return Sale{}, fmt.Errorf("%w: currency %q is not a three-letter code", ErrInvalidRow, r.Fields["currency"])
+	}
+
+	s.Amount = s.UnitPrice * int64(s.Quantity)
+	return s, nil
+}
+
+// checkText requires a value of at most max characters
+func checkText(field, value string, max int) error {
+	if value == "" {
+		return fmt.Errorf("%w: %s is required", ErrInvalidRow, field)
+	}
+	if utf8.RuneCountInString(value) > max {
+		return fmt.Errorf("%w: %s is longer than %d characters", ErrInvalidRow, field, max)
+	}
+	return nil
+}
+
+// parseTime reads sold_at in any of timeLayouts
+func parseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: sold_at %q is not a time like 2025-03-01 09:30:00", ErrInvalidRow, value)
+}
+
+// ParseCents reads a non-negative decimal amount with at most two decimals,
+// such as "12", "12.5" or "12.50", into cents. It works on the digits, so no
+// cent is lost to floating point. Amounts above a billion are refused.
+func ParseCents(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	whole, frac, hasFrac := strings.Cut(value, ".")
+	if whole == "" || !digits(whole) || (hasFrac && (frac == "" || len(frac) > 2 || !digits(frac))) {
+		return 0, fmt.Errorf("%q is not an amount like 12.50", value)
+	}
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || units > maxPrice {
+		return 0, fmt.Errorf("%q is too large", value)
+	}
+	frac = (frac + "00")[:2]
+	cents, _ := strconv.ParseInt(frac, 10, 64)
+	return units*100 + cents, nil
+}
+
+// digits reports whether s consists of ASCII digits only
+func digits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isCurrency reports whether code has the shape of an ISO 4217 code
+func isCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Rejection records a row that was not loaded and why
+type Rejection struct {
+	Line   int
+	Reason string
+	// Record is the row as it appeared in the file, if it could be read
+	Record string
+}
+
+// RowError is a row that could not be read. The load skips it and carries
+// on with the next row.
+type RowError struct {
+	Line int
+	Err  error
+	// Record is the row as it appeared in the file, if it could be read
+	Record string
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Rejection converts the error into the record kept for review
+func (e *RowError) Rejection() Rejection {
+	return Rejection{Line: e.Line, Reason: e.Err.Error(), Record: e.Record}
+}
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"errors"
+	"maps"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// record builds a valid record, with fields overriding its values
+func record(fields map[string]string) Record {
+	r := Record{Line: 7, Fields: map[string]string{
+		"order_id":   "ORD-1001",
+		"sold_at":    "2025-03-01 09:30:00",
+		"store":      "ams-01",
+		"sku":        "widget-1",
+		"quantity":   "3",
+		"unit_price": "12.5",
+		"currency":   "eur",
+	}}
+	maps.Copy(r.Fields, fields)
+	return r
+}
+
+func TestParseSale(t *testing.T) {
+	sale, err := ParseSale(record(map[string]string{"order_id": "  ORD-1001 "}))
+	require.NoError(t, err)
+
+	assert.Equal(t, Sale{
+		Line:      7,
+		OrderID:   "ORD-1001",
+		SoldAt:    time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC),
+		Store:     "AMS-01",
+		SKU:       "WIDGET-1",
+		Quantity:  3,
+		UnitPrice: 1250,
+		Currency:  "EUR",
+		Amount:    3750,
+	}, sale)
+}
+
+func TestParseSaleConvertsTimesToUTC(t *testing.T) {
+	sale, err := ParseSale(record(map[string]string{"sold_at": "2025-03-01T10:30:00+01:00"}))
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC), sale.SoldAt)
+}
+
+func TestParseSaleRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields map[string]string
+		want   string
+	}{
+		{name: "missing order id", fields: map[string]string{"order_id": " "}, want: "order_id is required"},
+		{name: "missing store", fields: map[string]string{"store": ""}, want: "store is required"},
+		{name: "long sku", fields: map[string]string{"sku": strings.Repeat("X", 65)}, want: "sku is longer than 64 characters"},
+		{name: "bad time", fields: map[string]string{"sold_at": "01/03/2025"}, want: `sold_at "01/03/2025" is not a time`},
+		{name: "quantity not a number", fields: map[string]string{"quantity": "two"}, want: `quantity "two" is not a whole number`},
+		{name: "zero quantity", fields: map[string]string{"quantity": "0"}, want: "quantity must be 1 to 10000"},
+		{name: "negative price", fields: map[string]string{"unit_price": "-1.00"}, want: "unit_price"},
+		{name: "bad currency", fields: map[string]string{"currency": "EURO"}, want: "not a three-letter code"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSale(record(tt.fields))
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrInvalidRow))
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestParseCents(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{value: "12", want: 1200},
+		{value: "12.5", want: 1250},
+		{value: " 12.05 ", want: 1205},
+		{value: "0.99", want: 99},
+		{value: "0", want: 0},
+		{value: "1000000000.00", want: 100000000000},
+		{value: "", wantErr: true},
+		{value: ".50", wantErr: true},
+		{value: "12.", wantErr: true},
+		{value: "12.505", wantErr: true},
+		{value: "1,200.00", wantErr: true},
+		{value: "-3", wantErr: true},
+		{value: "1e3", wantErr: true},
+		{value: "1000000001", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseCents(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,17 @@
+// Package progress reports how a run goes: it counts rows, batches and files
+// in metrics, and logs each file and, while one loads, its progress
+package progress
+
+import "time"
+
+// Config sets how often progress is logged
+type Config struct {
+	// LogInterval is the least time between two progress lines of a file;
+	// the metrics are updated with every batch
+	LogInterval time.Duration `mapstructure:"log_interval" default:"5s"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "progress"
+}
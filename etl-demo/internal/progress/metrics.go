@@ -0,0 +1,50 @@
+package progress
+
+import "github.com/gostratum/metricsx"
+
+// Metrics records the rows, batches and files of a run
+type Metrics struct {
+	rows     counter
+	files    counter
+	batches  histogram
+	line     gauge
+	inFlight gauge
+}
+
+// NewMetrics registers the ETL metrics
+func NewMetrics(metrics metricsx.Metrics) *Metrics {
+	return &Metrics{
+		rows: metrics.Counter("etl_rows_total",
+			metricsx.WithHelp("Rows committed, by result (loaded, rejected)"),
+			metricsx.WithLabels("result"),
+		),
+		files: metrics.Counter("etl_files_total",
+			metricsx.WithHelp("Files handled, by result (loaded, skipped, failed, busy, stopped)"),
+			metricsx.WithLabels("result"),
+		),
+		batches: metrics.Histogram("etl_batch_duration_seconds",
+			metricsx.WithHelp("Time to commit one batch with its checkpoint"),
+		),
+		line: metrics.Gauge("etl_checkpoint_line",
+			metricsx.WithHelp("Last line committed of the file being loaded"),
+		),
+		inFlight: metrics.Gauge("etl_file_loading",
+			metricsx.WithHelp("1 while a file is being loaded"),
+		),
+	}
+}
+
+// counter is the part of metricsx.Counter the reporter uses
+type counter interface {
+	Inc(labels ...string)
+}
+
+// histogram is the part of metricsx.Histogram the reporter uses
+type histogram interface {
+	Observe(v float64, labels ...string)
+}
+
+// gauge is the part of metricsx.Gauge the reporter uses
+type gauge interface {
+	Set(v float64, labels ...string)
+}
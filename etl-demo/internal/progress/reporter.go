@@ -0,0 +1,129 @@
+package progress
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+
+	"github.com/gostratum/examples/etl-demo/internal/domain"
+	"github.com/gostratum/examples/etl-demo/internal/usecase"
+)
+
+// Reporter implements usecase.Progress with metrics and structured logs. The
+// pipeline loads one file at a time, so it keeps the state of one file.
+type Reporter struct {
+	cfg     Config
+	metrics *Metrics
+	log     logx.Logger
+	now     func() time.Time
+
+	// started is when the current file started in this run, and rows how
+	// many of its rows were committed since
+	started time.Time
+	rows    int
+	// logged is when the last progress line of the current file was logged
+	logged time.Time
+}
+
+// NewReporter creates the reporter from the progress config section
+func NewReporter(loader configx.Loader, metrics *Metrics, log logx.Logger) (usecase.Progress, error) {
+	var cfg Config
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load progress config: %w", err)
+	}
+	return newReporter(cfg, metrics, log), nil
+}
+
+func newReporter(cfg Config, metrics *Metrics, log logx.Logger) *Reporter {
+	return &Reporter{cfg: cfg, metrics: metrics, log: log, now: time.Now}
+}
+
+// FileStarted implements usecase.Progress
+func (r *Reporter) FileStarted(cp domain.Checkpoint) {
+	r.started = r.now()
+	r.logged = r.started
+	r.rows = 0
+
+	r.metrics.inFlight.Set(1)
+	r.metrics.line.Set(float64(cp.Line))
+
+	if cp.Line > 0 {
+		r.log.Info("resuming file",
+			logx.String("key", cp.Key),
+			logx.Int("resume_from", cp.Line),
+			logx.Int("loaded", cp.Loaded),
+			logx.Int("rejected", cp.Rejected),
+		)
+		return
+	}
+	r.log.Info("file started", logx.String("key", cp.Key))
+}
+
+// BatchCommitted implements usecase.Progress
+func (r *Reporter) BatchCommitted(cp domain.Checkpoint, batch domain.Batch, took time.Duration) {
+	r.metrics.batches.Observe(took.Seconds())
+	for range batch.Sales {
+		r.metrics.rows.Inc("loaded")
+	}
+	for range batch.Rejections {
+		r.metrics.rows.Inc("rejected")
+	}
+	r.metrics.line.Set(float64(cp.Line))
+	r.rows += batch.Len()
+
+	// The last batch is reported with the file
+	now := r.now()
+	if batch.Last || now.Sub(r.logged) < r.cfg.LogInterval {
+		return
+	}
+	r.logged = now
+	r.log.Info("progress",
+		logx.String("key", cp.Key),
+		logx.Int("line", cp.Line),
+		logx.Int("loaded", cp.Loaded),
+		logx.Int("rejected", cp.Rejected),
+		logx.String("rows_per_second", fmt.Sprintf("%.0f", r.rate(now))),
+	)
+}
+
+// FileFinished implements usecase.Progress
+func (r *Reporter) FileFinished(report usecase.FileReport) {
+	r.metrics.files.Inc(string(report.Result))
+	r.metrics.inFlight.Set(0)
+
+	fields := []logx.Field{
+		logx.String("key", report.Key),
+		logx.Int("loaded", report.Loaded),
+		logx.Int("rejected", report.Rejected),
+	}
+	switch report.Result {
+	case usecase.ResultLoaded:
+		fields = append(fields,
+			logx.String("duration", report.Duration.String()),
+			logx.String("rows_per_second", fmt.Sprintf("%.0f", r.rate(r.now()))),
+		)
+		if report.ResumedAt > 0 {
+			fields = append(fields, logx.Int("resumed_from", report.ResumedAt))
+		}
+		r.log.Info("file loaded", fields...)
+	case usecase.ResultSkipped:
+		r.log.Info("file skipped", fields...)
+	case usecase.ResultFailed:
+		r.log.Warn("file failed; replace it to load it again", append(fields, logx.String("reason", report.Error))...)
+	case usecase.ResultBusy:
+		r.log.Warn("file left to another run that is loading it", fields...)
+	case usecase.ResultStopped:
+		r.log.Warn("file stopped part-way; the next run resumes it", fields...)
+	}
+}
+
+// rate is the rows per second committed of the current file in this run
+func (r *Reporter) rate(now time.Time) float64 {
+	elapsed := now.Sub(r.started).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(r.rows) / elapsed
+}
@@ -0,0 +1,127 @@
+package progress
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gostratum/examples/etl-demo/internal/domain"
+	"github.com/gostratum/examples/etl-demo/internal/usecase"
+)
+
+// fakeCounter counts increments per label value
+type fakeCounter struct {
+	counts map[string]int
+}
+
+func (f *fakeCounter) Inc(labels ...string) {
+	f.counts[labels[0]]++
+}
+
+// fakeHistogram keeps the observed values
+type fakeHistogram struct {
+	values []float64
+}
+
+func (f *fakeHistogram) Observe(v float64, labels ...string) {
+	f.values = append(f.values, v)
+}
+
+// fakeGauge keeps the last value set
+type fakeGauge struct {
+	value float64
+}
+
+func (f *fakeGauge) Set(v float64, labels ...string) {
+	f.value = v
+}
+
+// recordingLogger keeps the messages logged at info level
+type recordingLogger struct {
+	logx.Logger
+	infos []string
+}
+
+func (l *recordingLogger) Info(msg string, fields ...logx.Field) {
+	l.infos = append(l.infos, msg)
+}
+
+type testReporter struct {
+	*Reporter
+	rows     *fakeCounter
+	files    *fakeCounter
+	batches  *fakeHistogram
+	line     *fakeGauge
+	inFlight *fakeGauge
+	log      *recordingLogger
+	clock    time.Time
+}
+
+func newTestReporter() *testReporter {
+	tr := &testReporter{
+		rows:     &fakeCounter{counts: map[string]int{}},
+		files:    &fakeCounter{counts: map[string]int{}},
+		batches:  &fakeHistogram{},
+		line:     &fakeGauge{},
+		inFlight: &fakeGauge{},
+		log:      &recordingLogger{Logger: logx.NewNoopLogger()},
+		clock:    time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC),
+	}
+	metrics := &Metrics{rows: tr.rows, files: tr.files, batches: tr.batches, line: tr.line, inFlight: tr.inFlight}
+	tr.Reporter = newReporter(Config{LogInterval: 5 * time.Second}, metrics, tr.log)
+	tr.now = func() time.Time { return tr.clock }
+	return tr
+}
+
+// commit reports a batch of sales and rejections ending at line, a second
+// after the previous one
+func (tr *testReporter) commit(cp *domain.Checkpoint, sales, rejections, line int) {
+	tr.clock = tr.clock.Add(time.Second)
+	b := domain.Batch{Sales: make([]domain.Sale, sales), Rejections: make([]domain.Rejection, rejections), Line: line}
+	*cp = cp.Advance(b, tr.clock)
+	tr.BatchCommitted(*cp, b, 20*time.Millisecond)
+}
+
+func TestReporterMetrics(t *testing.T) {
+	tr := newTestReporter()
+	cp := domain.Checkpoint{Key: "incoming/a.csv", Status: domain.FileLoading}
+
+	tr.FileStarted(cp)
+	assert.Equal(t, 1.0, tr.inFlight.value)
+
+	tr.commit(&cp, 3, 1, 5)
+	tr.commit(&cp, 2, 0, 7)
+	assert.Equal(t, 5, tr.rows.counts["loaded"])
+	assert.Equal(t, 1, tr.rows.counts["rejected"])
+	assert.Len(t, tr.batches.values, 2)
+	assert.Equal(t, 7.0, tr.line.value)
+
+	tr.FileFinished(usecase.FileReport{Key: cp.Key, Result: usecase.ResultLoaded, Loaded: 5, Rejected: 1})
+	tr.FileFinished(usecase.FileReport{Key: "incoming/b.csv", Result: usecase.ResultSkipped})
+	assert.Equal(t, 1, tr.files.counts["loaded"])
+	assert.Equal(t, 1, tr.files.counts["skipped"])
+	assert.Zero(t, tr.inFlight.value)
+}
+
+func TestReporterThrottlesProgress(t *testing.T) {
+	tr := newTestReporter()
+	cp := domain.Checkpoint{Key: "incoming/a.csv", Status: domain.FileLoading}
+
+	tr.FileStarted(cp)
+	for i := range 12 {
+		tr.commit(&cp, 10, 0, (i+1)*10+1)
+	}
+
+	// A batch a second, a progress line every five seconds
+	assert.Equal(t, []string{"file started", "progress", "progress"}, tr.log.infos)
+}
+
+func TestReporterResumedFile(t *testing.T) {
+	tr := newTestReporter()
+
+	tr.FileStarted(domain.Checkpoint{Key: "incoming/a.csv", Status: domain.FileLoading, Line: 501, Loaded: 500})
+	assert.Equal(t, []string{"resuming file"}, tr.log.infos)
+	assert.Equal(t, 501.0, tr.line.value)
+}
@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/gostratum/examples/etl-demo/internal/domain"
+)
+
+// Application-level errors for use case layer
+// These are used to communicate failures to the presentation layer
+var (
+	// ErrUnavailable indicates the bucket or the database cannot be reached; the run stops
+	ErrUnavailable = errors.New("service unavailable")
+
+	// ErrCheckpointMoved indicates another run committed to the file since this
+	// run read its checkpoint; the file is left to that run
+	ErrCheckpointMoved = errors.New("checkpoint moved by another run")
+
+	// ErrTooManyRejected indicates more rows of a file were rejected than
+	// allowed, which usually means the wrong file or a broken export
+	ErrTooManyRejected = errors.New("too many rejected rows")
+
+	// ErrInvalid wraps domain.ErrInvalidRow for application layer
+	ErrInvalid = domain.ErrInvalidRow
+)
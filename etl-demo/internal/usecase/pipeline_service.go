@@ -0,0 +1,237 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gostratum/examples/etl-demo/internal/domain"
+)
+
+// FileResult is how the load of one file ended in a run
+type FileResult string
+
+const (
+	// ResultLoaded files were loaded to the end in this run
+	ResultLoaded FileResult = "loaded"
+	// ResultSkipped files had been loaded by an earlier run
+	ResultSkipped FileResult = "skipped"
+	// ResultFailed files were given up on, in this run or an earlier one
+	ResultFailed FileResult = "failed"
+	// ResultBusy files are being loaded by another run
+	ResultBusy FileResult = "busy"
+	// ResultStopped files were left part-way when the run stopped; the next
+	// run resumes them
+	ResultStopped FileResult = "stopped"
+)
+
+// FileReport summarizes the load of one file
+type FileReport struct {
+	Key    string
+	Result FileResult
+	// ResumedAt is the line an earlier run had committed up to; 0 when the
+	// load started from the beginning of the file
+	ResumedAt int
+	// Loaded and Rejected count every row of the file so far, in any run
+	Loaded   int
+	Rejected int
+	// Error says why a file failed
+	Error    string
+	Duration time.Duration
+}
+
+// Report summarizes a run
+type Report struct {
+	Files []FileReport
+}
+
+// Count returns the number of files that ended with result
+func (r Report) Count(result FileResult) int {
+	n := 0
+	for _, f := range r.Files {
+		if f.Result == result {
+			n++
+		}
+	}
+	return n
+}
+
+// Rejected returns the rows rejected in every file of the run
+func (r Report) Rejected() int {
+	n := 0
+	for _, f := range r.Files {
+		n += f.Rejected
+	}
+	return n
+}
+
+// PipelineService extracts sales from the files in the bucket, validates and
+// transforms them, and loads them into the database in batches
+type PipelineService struct {
+	source   Source
+	repo     LoadRepository
+	progress Progress
+}
+
+// NewPipelineService creates a new pipeline service with dependency injection
+func NewPipelineService(source Source, repo LoadRepository, progress Progress) *PipelineService {
+	return &PipelineService{source: source, repo: repo, progress: progress}
+}
+
+// Run loads every file under prefix that is not loaded yet, in key order,
+// batchSize rows per transaction. A file with more than maxRejected rejected
+// rows (no limit when negative) fails, and the run goes on with the next one.
+//
+// The run stops early when ctx is done or the bucket or database is
+// unavailable. Every batch is committed with its checkpoint, so the next run
+// resumes each file after its last committed batch. The report covers the
+// files handled so far and the error says why the run stopped.
+func (s *PipelineService) Run(ctx context.Context, prefix string, batchSize, maxRejected int) (Report, error) {
+	if batchSize <= 0 {
+		return Report{}, fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+
+	files, err := s.source.List(ctx, prefix)
+	if err != nil {
+		return Report{}, err
+	}
+
+	var report Report
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		start := time.Now()
+		fr, err := s.loadFile(ctx, file.Key, batchSize, maxRejected)
+		fr.Duration = time.Since(start)
+		if fr.Result != "" {
+			report.Files = append(report.Files, fr)
+			s.progress.FileFinished(fr)
+		}
+		if err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// loadFile loads one file from its checkpoint on. The report has no result
+// if the run stopped before the file's checkpoint was read.
+func (s *PipelineService) loadFile(ctx context.Context, key string, batchSize, maxRejected int) (FileReport, error) {
+	reader, file, err := s.source.Open(ctx, key)
+	if errors.Is(err, domain.ErrNotFound) {
+		// Deleted since it was listed; nothing to load
+		return FileReport{Key: key, Result: ResultSkipped}, nil
+	}
+	if err != nil {
+		return FileReport{}, err
+	}
+	defer reader.Close()
+
+	cp, err := s.repo.Start(ctx, file)
+	if err != nil {
+		return FileReport{}, err
+	}
+	report := func(result FileResult) FileReport {
+		return FileReport{Key: key, Result: result, Loaded: cp.Loaded, Rejected: cp.Rejected, Error: cp.Error}
+	}
+	switch cp.Status {
+	case domain.FileCompleted:
+		return report(ResultSkipped), nil
+	case domain.FileFailed:
+		return report(ResultFailed), nil
+	}
+	resumedAt := cp.Line
+	s.progress.FileStarted(cp)
+
+	var batch domain.Batch
+	commit := func() error {
+		start := time.Now()
+		next, err := s.repo.Commit(ctx, cp, batch)
+		if err != nil {
+			return err
+		}
+		s.progress.BatchCommitted(next, batch, time.Since(start))
+		cp = next
+		batch = domain.Batch{}
+		return nil
+	}
+	fail := func(reason string) error {
+		failed, err := s.repo.Fail(ctx, cp, reason)
+		if err != nil {
+			return err
+		}
+		cp = failed
+		return nil
+	}
+	finish := func(result FileResult, err error) (FileReport, error) {
+		fr := report(result)
+		fr.ResumedAt = resumedAt
+		switch {
+		case errors.Is(err, ErrCheckpointMoved):
+			fr.Result = ResultBusy
+			return fr, nil
+		case err != nil:
+			fr.Result = ResultStopped
+		}
+		return fr, err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return finish(ResultStopped, err)
+		}
+
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var rowErr *domain.RowError
+		switch {
+		case errors.As(err, &rowErr):
+			if rowErr.Line <= cp.Line {
+				continue
+			}
+			batch.Rejections = append(batch.Rejections, rowErr.Rejection())
+			batch.Line = rowErr.Line
+		case errors.Is(err, domain.ErrInvalidFile):
+			return finish(ResultFailed, fail(err.Error()))
+		case err != nil:
+			return finish(ResultStopped, fmt.Errorf("failed to read %s: %w", key, err))
+		case record.Line <= cp.Line:
+			// Committed by an earlier run
+			continue
+		default:
+			sale, err := domain.ParseSale(record)
+			if err != nil {
+				batch.Rejections = append(batch.Rejections, domain.Rejection{Line: record.Line, Reason: err.Error(), Record: record.Raw})
+			} else {
+				batch.Sales = append(batch.Sales, sale)
+			}
+			batch.Line = record.Line
+		}
+
+		if maxRejected >= 0 && cp.Rejected+len(batch.Rejections) > maxRejected {
+			// Commit the rejections, so every one of them can be reviewed
+			if err := commit(); err != nil {
+				return finish(ResultStopped, err)
+			}
+			reason := fmt.Errorf("%w: more than %d", ErrTooManyRejected, maxRejected)
+			return finish(ResultFailed, fail(reason.Error()))
+		}
+		if batch.Len() >= batchSize {
+			if err := commit(); err != nil {
+				return finish(ResultStopped, err)
+			}
+		}
+	}
+
+	batch.Last = true
+	if err := commit(); err != nil {
+		return finish(ResultStopped, err)
+	}
+	return finish(ResultLoaded, nil)
+}
@@ -0,0 +1,359 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/etl-demo/internal/domain"
+)
+
+// sale returns a valid record on line
+func sale(line int) domain.Record {
+	return domain.Record{Line: line, Raw: fmt.Sprintf("ORD-%d,...", line), Fields: map[string]string{
+		"order_id":   fmt.Sprintf("ORD-%d", line),
+		"sold_at":    "2025-03-01 09:30:00",
+		"store":      "AMS-01",
+		"sku":        "WIDGET-1",
+		"quantity":   "1",
+		"unit_price": "9.99",
+		"currency":   "EUR",
+	}}
+}
+
+// invalid returns a record on line with an unreadable quantity
+func invalid(line int) domain.Record {
+	r := sale(line)
+	r.Fields["quantity"] = "many"
+	return r
+}
+
+// sales returns valid records on lines from to to
+func sales(from, to int) []any {
+	var records []any
+	for line := from; line <= to; line++ {
+		records = append(records, sale(line))
+	}
+	return records
+}
+
+// fakeFile is a file of records and read errors
+type fakeFile struct {
+	etag    string
+	records []any
+}
+
+// fakeSource serves files from memory
+type fakeSource struct {
+	files map[string]fakeFile
+}
+
+func (s *fakeSource) List(ctx context.Context, prefix string) ([]domain.File, error) {
+	var files []domain.File
+	for key, f := range s.files {
+		files = append(files, domain.File{Key: key, ETag: f.etag})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Key < files[j].Key })
+	return files, nil
+}
+
+func (s *fakeSource) Open(ctx context.Context, key string) (RecordReader, domain.File, error) {
+	f, ok := s.files[key]
+	if !ok {
+		return nil, domain.File{}, domain.ErrNotFound
+	}
+	return &sliceReader{records: f.records}, domain.File{Key: key, ETag: f.etag}, nil
+}
+
+// sliceReader returns records and errors from a slice
+type sliceReader struct {
+	records []any
+}
+
+func (r *sliceReader) Next() (domain.Record, error) {
+	if len(r.records) == 0 {
+		return domain.Record{}, io.EOF
+	}
+	next := r.records[0]
+	r.records = r.records[1:]
+	if err, ok := next.(error); ok {
+		return domain.Record{}, err
+	}
+	return next.(domain.Record), nil
+}
+
+func (r *sliceReader) Close() error { return nil }
+
+// fakeRepo keeps checkpoints and rows in memory, with the guard of the real
+// one against a checkpoint that moved
+type fakeRepo struct {
+	mu          sync.Mutex
+	checkpoints map[string]domain.Checkpoint
+	sales       map[string][]domain.Sale
+	rejections  map[string][]domain.Rejection
+	commits     int
+	// commitErr fails the commit with this number, counting from 1
+	commitErr map[int]error
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		checkpoints: map[string]domain.Checkpoint{},
+		sales:       map[string][]domain.Sale{},
+		rejections:  map[string][]domain.Rejection{},
+		commitErr:   map[int]error{},
+	}
+}
+
+func (r *fakeRepo) Start(ctx context.Context, file domain.File) (domain.Checkpoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp, ok := r.checkpoints[file.Key]
+	if !ok || cp.ETag != file.ETag {
+		cp = domain.Checkpoint{ID: int64(len(r.checkpoints) + 1), Key: file.Key, ETag: file.ETag, Status: domain.FileLoading}
+		r.checkpoints[file.Key] = cp
+		delete(r.sales, file.Key)
+		delete(r.rejections, file.Key)
+	}
+	return cp, nil
+}
+
+func (r *fakeRepo) Commit(ctx context.Context, cp domain.Checkpoint, batch domain.Batch) (domain.Checkpoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commits++
+	if err := r.commitErr[r.commits]; err != nil {
+		return domain.Checkpoint{}, err
+	}
+	stored := r.checkpoints[cp.Key]
+	if stored.ETag != cp.ETag || stored.Line != cp.Line {
+		return domain.Checkpoint{}, ErrCheckpointMoved
+	}
+	r.sales[cp.Key] = append(r.sales[cp.Key], batch.Sales...)
+	r.rejections[cp.Key] = append(r.rejections[cp.Key], batch.Rejections...)
+	next := cp.Advance(batch, time.Now())
+	r.checkpoints[cp.Key] = next
+	return next, nil
+}
+
+func (r *fakeRepo) Fail(ctx context.Context, cp domain.Checkpoint, reason string) (domain.Checkpoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	failed := cp.Fail(reason, time.Now())
+	r.checkpoints[cp.Key] = failed
+	delete(r.sales, cp.Key)
+	return failed, nil
+}
+
+// lines returns the lines of the sales loaded from key
+func (r *fakeRepo) lines(key string) []int {
+	var lines []int
+	for _, s := range r.sales[key] {
+		lines = append(lines, s.Line)
+	}
+	return lines
+}
+
+// fakeProgress records what it is told
+type fakeProgress struct {
+	started  []string
+	batches  []domain.Batch
+	finished []FileReport
+}
+
+func (p *fakeProgress) FileStarted(cp domain.Checkpoint) { p.started = append(p.started, cp.Key) }
+
+func (p *fakeProgress) BatchCommitted(cp domain.Checkpoint, batch domain.Batch, took time.Duration) {
+	p.batches = append(p.batches, batch)
+}
+
+func (p *fakeProgress) FileFinished(report FileReport) { p.finished = append(p.finished, report) }
+
+func TestRunLoadsFilesInBatches(t *testing.T) {
+	source := &fakeSource{files: map[string]fakeFile{
+		"incoming/a.csv": {etag: "a1", records: append(sales(2, 6),
+			invalid(7),
+			&domain.RowError{Line: 8, Err: errors.New("bare quote")},
+		)},
+		"incoming/b.csv": {etag: "b1", records: sales(2, 3)},
+	}}
+	repo := newFakeRepo()
+	progress := &fakeProgress{}
+
+	report, err := NewPipelineService(source, repo, progress).Run(context.Background(), "incoming/", 2, -1)
+	require.NoError(t, err)
+
+	require.Len(t, report.Files, 2)
+	a := report.Files[0]
+	assert.Equal(t, "incoming/a.csv", a.Key)
+	assert.Equal(t, ResultLoaded, a.Result)
+	assert.Equal(t, 5, a.Loaded)
+	assert.Equal(t, 2, a.Rejected)
+	assert.Zero(t, a.ResumedAt)
+	assert.Equal(t, ResultLoaded, report.Files[1].Result)
+	assert.Equal(t, 2, report.Count(ResultLoaded))
+	assert.Equal(t, 2, report.Rejected())
+
+	assert.Equal(t, []int{2, 3, 4, 5, 6}, repo.lines("incoming/a.csv"))
+	rejected := repo.rejections["incoming/a.csv"]
+	require.Len(t, rejected, 2)
+	assert.Equal(t, 7, rejected[0].Line)
+	assert.Contains(t, rejected[0].Reason, `quantity "many" is not a whole number`)
+	assert.Equal(t, "ORD-7,...", rejected[0].Record)
+	assert.Equal(t, domain.Rejection{Line: 8, Reason: "bare quote"}, rejected[1])
+
+	cp := repo.checkpoints["incoming/a.csv"]
+	assert.Equal(t, domain.FileCompleted, cp.Status)
+	assert.Equal(t, 8, cp.Line)
+
+	// a: 7 rows in batches of 2, the last one ending the file; b: 2 rows and an empty last batch
+	assert.Equal(t, []string{"incoming/a.csv", "incoming/b.csv"}, progress.started)
+	require.Len(t, progress.batches, 6)
+	assert.True(t, progress.batches[3].Last)
+	assert.Equal(t, 1, progress.batches[3].Len())
+	assert.Len(t, progress.finished, 2)
+}
+
+func TestRunResumesFromCheckpoint(t *testing.T) {
+	source := &fakeSource{files: map[string]fakeFile{
+		"incoming/a.csv": {etag: "a1", records: append([]any{invalid(2)}, sales(3, 7)...)},
+	}}
+	repo := newFakeRepo()
+	// An earlier run committed lines 2 to 4, then stopped
+	repo.checkpoints["incoming/a.csv"] = domain.Checkpoint{ID: 1, Key: "incoming/a.csv", ETag: "a1", Status: domain.FileLoading, Line: 4, Loaded: 2, Rejected: 1}
+
+	report, err := NewPipelineService(source, repo, &fakeProgress{}).Run(context.Background(), "incoming/", 10, -1)
+	require.NoError(t, err)
+
+	require.Len(t, report.Files, 1)
+	assert.Equal(t, ResultLoaded, report.Files[0].Result)
+	assert.Equal(t, 4, report.Files[0].ResumedAt)
+	assert.Equal(t, 5, report.Files[0].Loaded)
+	assert.Equal(t, 1, report.Files[0].Rejected)
+	assert.Equal(t, []int{5, 6, 7}, repo.lines("incoming/a.csv"), "lines up to the checkpoint are not loaded again")
+	assert.Empty(t, repo.rejections["incoming/a.csv"])
+}
+
+func TestRunStartsReplacedFileOver(t *testing.T) {
+	source := &fakeSource{files: map[string]fakeFile{"incoming/a.csv": {etag: "a2", records: sales(2, 4)}}}
+	repo := newFakeRepo()
+	repo.checkpoints["incoming/a.csv"] = domain.Checkpoint{ID: 1, Key: "incoming/a.csv", ETag: "a1", Status: domain.FileFailed, Line: 9}
+
+	report, err := NewPipelineService(source, repo, &fakeProgress{}).Run(context.Background(), "incoming/", 10, -1)
+	require.NoError(t, err)
+
+	assert.Equal(t, ResultLoaded, report.Files[0].Result)
+	assert.Zero(t, report.Files[0].ResumedAt)
+	assert.Equal(t, []int{2, 3, 4}, repo.lines("incoming/a.csv"))
+}
+
+func TestRunSkipsDoneFiles(t *testing.T) {
+	source := &fakeSource{files: map[string]fakeFile{
+		"incoming/a.csv": {etag: "a1", records: sales(2, 3)},
+		"incoming/b.csv": {etag: "b1", records: sales(2, 3)},
+	}}
+	repo := newFakeRepo()
+	repo.checkpoints["incoming/a.csv"] = domain.Checkpoint{ID: 1, Key: "incoming/a.csv", ETag: "a1", Status: domain.FileCompleted, Line: 3, Loaded: 2}
+	repo.checkpoints["incoming/b.csv"] = domain.Checkpoint{ID: 2, Key: "incoming/b.csv", ETag: "b1", Status: domain.FileFailed, Error: "too many rejected rows: more than 0"}
+	progress := &fakeProgress{}
+
+	report, err := NewPipelineService(source, repo, progress).Run(context.Background(), "incoming/", 10, -1)
+	require.NoError(t, err)
+
+	require.Len(t, report.Files, 2)
+	assert.Equal(t, ResultSkipped, report.Files[0].Result)
+	assert.Equal(t, 2, report.Files[0].Loaded)
+	assert.Equal(t, ResultFailed, report.Files[1].Result)
+	assert.Equal(t, "too many rejected rows: more than 0", report.Files[1].Error)
+	assert.Zero(t, repo.commits)
+	assert.Empty(t, progress.started)
+}
+
+func TestRunFailsFileWithTooManyRejections(t *testing.T) {
+	source := &fakeSource{files: map[string]fakeFile{
+		"incoming/a.csv": {etag: "a1", records: []any{sale(2), invalid(3), invalid(4), invalid(5), sale(6)}},
+		"incoming/b.csv": {etag: "b1", records: sales(2, 3)},
+	}}
+	repo := newFakeRepo()
+
+	report, err := NewPipelineService(source, repo, &fakeProgress{}).Run(context.Background(), "incoming/", 1, 2)
+	require.NoError(t, err, "the run goes on with the next file")
+
+	require.Len(t, report.Files, 2)
+	a := report.Files[0]
+	assert.Equal(t, ResultFailed, a.Result)
+	assert.Contains(t, a.Error, "too many rejected rows: more than 2")
+	assert.Zero(t, a.Loaded)
+	assert.Equal(t, 3, a.Rejected)
+	assert.Empty(t, repo.sales["incoming/a.csv"], "the sales of a failed file are deleted")
+	assert.Len(t, repo.rejections["incoming/a.csv"], 3, "its rejections are kept")
+	assert.Equal(t, domain.FileFailed, repo.checkpoints["incoming/a.csv"].Status)
+
+	assert.Equal(t, ResultLoaded, report.Files[1].Result)
+}
+
+func TestRunFailsInvalidFile(t *testing.T) {
+	source := &fakeSource{files: map[string]fakeFile{
+		"incoming/a.csv": {etag: "a1", records: []any{fmt.Errorf("%w: header has no \"sku\" column", domain.ErrInvalidFile)}},
+	}}
+	repo := newFakeRepo()
+
+	report, err := NewPipelineService(source, repo, &fakeProgress{}).Run(context.Background(), "incoming/", 10, -1)
+	require.NoError(t, err)
+
+	assert.Equal(t, ResultFailed, report.Files[0].Result)
+	assert.Equal(t, `invalid file: header has no "sku" column`, report.Files[0].Error)
+	assert.Equal(t, domain.FileFailed, repo.checkpoints["incoming/a.csv"].Status)
+}
+
+func TestRunStopsWhenUnavailable(t *testing.T) {
+	source := &fakeSource{files: map[string]fakeFile{
+		"incoming/a.csv": {etag: "a1", records: sales(2, 7)},
+		"incoming/b.csv": {etag: "b1", records: sales(2, 3)},
+	}}
+	repo := newFakeRepo()
+	repo.commitErr[2] = fmt.Errorf("%w: connection refused", ErrUnavailable)
+
+	report, err := NewPipelineService(source, repo, &fakeProgress{}).Run(context.Background(), "incoming/", 2, -1)
+	assert.ErrorIs(t, err, ErrUnavailable)
+
+	require.Len(t, report.Files, 1, "the run stops at the first file")
+	assert.Equal(t, ResultStopped, report.Files[0].Result)
+	assert.Equal(t, 2, report.Files[0].Loaded)
+	assert.Equal(t, 3, repo.checkpoints["incoming/a.csv"].Line, "the next run resumes after the first batch")
+}
+
+func TestRunLeavesFileToAnotherRun(t *testing.T) {
+	source := &fakeSource{files: map[string]fakeFile{
+		"incoming/a.csv": {etag: "a1", records: sales(2, 3)},
+		"incoming/b.csv": {etag: "b1", records: sales(2, 3)},
+	}}
+	repo := newFakeRepo()
+	repo.commitErr[1] = ErrCheckpointMoved
+
+	report, err := NewPipelineService(source, repo, &fakeProgress{}).Run(context.Background(), "incoming/", 10, -1)
+	require.NoError(t, err)
+
+	require.Len(t, report.Files, 2)
+	assert.Equal(t, ResultBusy, report.Files[0].Result)
+	assert.Equal(t, ResultLoaded, report.Files[1].Result)
+}
+
+func TestRunStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	source := &fakeSource{files: map[string]fakeFile{"incoming/a.csv": {etag: "a1", records: sales(2, 3)}}}
+	repo := newFakeRepo()
+
+	_, err := NewPipelineService(source, repo, &fakeProgress{}).Run(ctx, "incoming/", 10, -1)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, repo.commits)
+}
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/gostratum/examples/etl-demo/internal/domain"
+)
+
+// Source lists and reads the files to load
+// This interface is owned by the use case layer (dependency inversion principle)
+type Source interface {
+	// List returns the CSV files under prefix, sorted by key
+	List(ctx context.Context, prefix string) ([]domain.File, error)
+	// Open starts reading the file at key, or fails with domain.ErrNotFound.
+	// The file returned is the version being read, which may be newer than
+	// the listed one. The caller closes the reader.
+	Open(ctx context.Context, key string) (RecordReader, domain.File, error)
+}
+
+// RecordReader reads the records of a file one at a time
+type RecordReader interface {
+	// Next returns the next record, or io.EOF after the last one. A record
+	// that cannot be read is returned as a *domain.RowError, and reading can
+	// go on; a file that cannot be read at all fails with domain.ErrInvalidFile.
+	Next() (domain.Record, error)
+	Close() error
+}
+
+// LoadRepository stores sales and the checkpoints of the files they come from
+type LoadRepository interface {
+	// Start returns the checkpoint of file, creating it for a new file. A
+	// file whose ETag changed was replaced: the rows loaded from the earlier
+	// version are deleted and the checkpoint starts over.
+	Start(ctx context.Context, file domain.File) (domain.Checkpoint, error)
+	// Commit stores the batch and advances the checkpoint past it, in one
+	// transaction. It fails with ErrCheckpointMoved if cp is no longer the
+	// stored checkpoint.
+	Commit(ctx context.Context, cp domain.Checkpoint, batch domain.Batch) (domain.Checkpoint, error)
+	// Fail gives up on the file: the sales loaded from it are deleted, its
+	// rejected rows kept for review, and it is marked failed with reason
+	Fail(ctx context.Context, cp domain.Checkpoint, reason string) (domain.Checkpoint, error)
+}
+
+// Progress is told how the run goes, for metrics and logs
+type Progress interface {
+	FileStarted(cp domain.Checkpoint)
+	BatchCommitted(cp domain.Checkpoint, batch domain.Batch, took time.Duration)
+	FileFinished(report FileReport)
+}
@@ -0,0 +1,4 @@
+-- Drop ETL tables
+DROP TABLE IF EXISTS rejected_rows CASCADE;
+DROP TABLE IF EXISTS sales CASCADE;
+DROP TABLE IF EXISTS load_files CASCADE;
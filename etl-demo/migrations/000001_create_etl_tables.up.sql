@@ -0,0 +1,40 @@
+-- Create load_files table; one row per source file, holding its checkpoint
+CREATE TABLE IF NOT EXISTS load_files (
+    id BIGSERIAL PRIMARY KEY,
+    object_key TEXT NOT NULL UNIQUE,
+    etag TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'loading' CHECK (status IN ('loading', 'completed', 'failed')),
+    line INTEGER NOT NULL DEFAULT 0 CHECK (line >= 0),
+    loaded INTEGER NOT NULL DEFAULT 0 CHECK (loaded >= 0),
+    rejected INTEGER NOT NULL DEFAULT 0 CHECK (rejected >= 0),
+    error TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+-- Create sales table; a row is identified by the file and line it came from
+CREATE TABLE IF NOT EXISTS sales (
+    file_id BIGINT NOT NULL REFERENCES load_files (id) ON DELETE CASCADE,
+    line INTEGER NOT NULL,
+    order_id VARCHAR(64) NOT NULL,
+    sold_at TIMESTAMPTZ NOT NULL,
+    store VARCHAR(32) NOT NULL,
+    sku VARCHAR(64) NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price_cents BIGINT NOT NULL CHECK (unit_price_cents >= 0),
+    currency CHAR(3) NOT NULL,
+    amount_cents BIGINT NOT NULL CHECK (amount_cents >= 0),
+    PRIMARY KEY (file_id, line)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sales_sold_at ON sales (sold_at);
+
+-- Create rejected_rows table; the rows that failed validation, for review
+CREATE TABLE IF NOT EXISTS rejected_rows (
+    file_id BIGINT NOT NULL REFERENCES load_files (id) ON DELETE CASCADE,
+    line INTEGER NOT NULL,
+    reason TEXT NOT NULL,
+    record TEXT NOT NULL,
+    PRIMARY KEY (file_id, line)
+);
@@ -0,0 +1,10 @@
+// Package migrations embeds the versioned SQL migration files, so tests can
+// create their databases with the same schema the service migrates to
+package migrations
+
+import "embed"
+
+// FS holds every *.sql migration in this directory
+//
+//go:embed *.sql
+var FS embed.FS
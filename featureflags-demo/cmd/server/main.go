@@ -0,0 +1,54 @@
+package main
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	httpAdapter "github.com/gostratum/examples/featureflags-demo/internal/adapter/http"
+	"github.com/gostratum/examples/featureflags-demo/internal/adapter/memory"
+	"github.com/gostratum/examples/featureflags-demo/internal/flags"
+	"github.com/gostratum/examples/featureflags-demo/internal/usecase"
+	"github.com/gostratum/examples/redact"
+	"github.com/gostratum/httpx"
+	"github.com/gostratum/metricsx"
+	"github.com/gostratum/tracingx"
+)
+
+func main() {
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// Prometheus metrics for HTTP requests
+		metricsx.Module(),
+
+		// Tracing installs the tracer provider; httpx starts a server span per
+		// request, and flag evaluations are recorded on it
+		tracingx.Module(),
+
+		// HTTP server
+		httpx.Module(),
+
+		// Flags from configs/flags.yaml, reloaded when the file changes
+		flags.Module(),
+
+		// Provide dependencies
+		fx.Provide(
+			// In-memory catalog
+			memory.NewCatalog,
+
+			// Usecase services
+			usecase.NewSearchService,
+
+			// HTTP handlers
+			httpAdapter.NewSearchHandler,
+		),
+
+		// Invoke setup functions
+		fx.Invoke(
+			httpAdapter.RegisterRoutes,
+		),
+	)
+
+	app.Run()
+}
@@ -0,0 +1,34 @@
+package http
+
+import "github.com/gostratum/examples/featureflags-demo/internal/domain"
+
+// SearchResponse is the HTTP DTO for a page of search results
+type SearchResponse struct {
+	Query string `json:"query"`
+	// Ranking and PageSize are the flag values the results were produced with
+	Ranking  string            `json:"ranking"`
+	PageSize int               `json:"page_size"`
+	Products []ProductResponse `json:"products"`
+}
+
+// ProductResponse is the HTTP DTO for a product
+type ProductResponse struct {
+	SKU   string `json:"sku"`
+	Name  string `json:"name"`
+	Price int64  `json:"price"`
+	// Set only while the show-discounts flag is on and the product is on sale
+	DiscountPercent int    `json:"discount_percent,omitempty"`
+	DiscountedPrice *int64 `json:"discounted_price,omitempty"`
+}
+
+// FromProduct converts a domain.Product to ProductResponse DTO, with its
+// discount when showDiscount is set
+func FromProduct(p domain.Product, showDiscount bool) ProductResponse {
+	resp := ProductResponse{SKU: p.SKU, Name: p.Name, Price: p.Price}
+	if showDiscount && p.DiscountPercent > 0 {
+		price := p.DiscountedPrice()
+		resp.DiscountPercent = p.DiscountPercent
+		resp.DiscountedPrice = &price
+	}
+	return resp
+}
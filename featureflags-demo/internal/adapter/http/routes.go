@@ -0,0 +1,49 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+)
+
+// RegisterRoutes registers all HTTP routes using the provided Gin engine
+// This function is designed to be used with fx.Invoke to work with httpx.Module
+func RegisterRoutes(e *gin.Engine, searchHandler *SearchHandler, reg core.Registry, log logx.Logger) {
+	// Add responsex middleware for request tracking and metadata
+	e.Use(responsex.MetaMiddleware("featureflags-demo/v1.0.0"))
+
+	// Search endpoint; ranking, page size and discounts are feature flags
+	e.GET("/products", searchHandler.Search)
+
+	// Health endpoints - readiness and liveness checks
+	e.GET("/healthz", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		res := reg.Aggregate(ctx, core.Readiness)
+		if res.OK {
+			c.JSON(http.StatusOK, gin.H{"ok": true, "details": res.Details})
+		} else {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"ok": false, "details": res.Details})
+		}
+	})
+
+	e.GET("/livez", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		res := reg.Aggregate(ctx, core.Liveness)
+		if res.OK {
+			c.JSON(http.StatusOK, gin.H{"ok": true, "details": res.Details})
+		} else {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"ok": false, "details": res.Details})
+		}
+	})
+
+	log.Info("HTTP routes registered")
+}
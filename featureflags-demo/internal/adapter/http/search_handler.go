@@ -0,0 +1,132 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/featureflags-demo/internal/domain"
+	"github.com/gostratum/examples/featureflags-demo/internal/flags"
+	"github.com/gostratum/examples/featureflags-demo/internal/usecase"
+)
+
+// Flags the search endpoint evaluates, with the values used when a flag is
+// missing, disabled or of the wrong type
+const (
+	flagRanking       = "search-ranking"
+	flagPageSize      = "search-page-size"
+	flagShowDiscounts = "show-discounts"
+
+	defaultRanking  = domain.RankingRelevance
+	defaultPageSize = 10
+	maxPageSize     = 50
+)
+
+// Headers the evaluation context is built from. A real service would take them
+// from the authenticated session.
+const (
+	headerUserID   = "X-User-ID"
+	headerUserPlan = "X-User-Plan"
+)
+
+// FlagClient evaluates feature flags; implemented by flags.Client
+type FlagClient interface {
+	Boolean(ctx context.Context, flag string, defaultValue bool, evalCtx flags.EvaluationContext) bool
+	String(ctx context.Context, flag string, defaultValue string, evalCtx flags.EvaluationContext) string
+	Int(ctx context.Context, flag string, defaultValue int64, evalCtx flags.EvaluationContext) int64
+}
+
+// Searcher searches the catalog; implemented by usecase.SearchService
+type Searcher interface {
+	Search(ctx context.Context, q usecase.SearchQuery) ([]domain.Product, error)
+}
+
+// SearchHandler serves catalog search. Which ranking it uses, how many results
+// it returns and whether it shows discounts are feature flags.
+type SearchHandler struct {
+	search Searcher
+	flags  FlagClient
+	log    logx.Logger
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(search *usecase.SearchService, client *flags.Client, log logx.Logger) *SearchHandler {
+	return newSearchHandler(search, client, log)
+}
+
+func newSearchHandler(search Searcher, client FlagClient, log logx.Logger) *SearchHandler {
+	return &SearchHandler{search: search, flags: client, log: log}
+}
+
+// Search handles GET /products?q=
+func (h *SearchHandler) Search(c *gin.Context) {
+	// The request context carries the server span, so every evaluation below
+	// is recorded on it
+	ctx := c.Request.Context()
+	evalCtx := evaluationContext(c)
+
+	ranking, err := domain.ParseRanking(h.flags.String(ctx, flagRanking, string(defaultRanking), evalCtx))
+	if err != nil {
+		h.log.Warn("search-ranking has an unknown variant; using the default", logx.Err(err))
+		ranking = defaultRanking
+	}
+
+	pageSize := h.flags.Int(ctx, flagPageSize, defaultPageSize, evalCtx)
+	if pageSize < 1 || pageSize > maxPageSize {
+		h.log.Warn("search-page-size is out of range; using the default", logx.Int("page_size", int(pageSize)))
+		pageSize = defaultPageSize
+	}
+
+	showDiscounts := h.flags.Boolean(ctx, flagShowDiscounts, false, evalCtx)
+
+	query := c.Query("q")
+	products, err := h.search.Search(ctx, usecase.SearchQuery{
+		Text:    query,
+		Ranking: ranking,
+		Limit:   int(pageSize),
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	resp := SearchResponse{
+		Query:    query,
+		Ranking:  string(ranking),
+		PageSize: int(pageSize),
+		Products: make([]ProductResponse, len(products)),
+	}
+	for i, p := range products {
+		resp.Products[i] = FromProduct(p, showDiscounts)
+	}
+	responsex.OK(c, resp, nil)
+}
+
+// evaluationContext identifies the caller for flag targeting. Without a user
+// ID the request is anonymous: targeting rules on the plan still apply, but
+// percentage rollouts fall back to the default variant.
+func evaluationContext(c *gin.Context) flags.EvaluationContext {
+	attrs := map[string]any{}
+	if plan := c.GetHeader(headerUserPlan); plan != "" {
+		attrs["plan"] = plan
+	}
+	return flags.NewEvaluationContext(c.GetHeader(headerUserID), attrs)
+}
+
+// handleError maps usecase errors to HTTP responses
+func (h *SearchHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalid):
+		responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
+	case errors.Is(err, usecase.ErrUnavailable):
+		h.log.Error("catalog unavailable", logx.Err(err))
+		responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "catalog is unavailable", nil)
+	default:
+		h.log.Error("search failed", logx.Err(err))
+		responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+	}
+}
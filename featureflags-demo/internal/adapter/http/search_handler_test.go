@@ -0,0 +1,185 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/featureflags-demo/internal/domain"
+	"github.com/gostratum/examples/featureflags-demo/internal/flags"
+	"github.com/gostratum/examples/featureflags-demo/internal/usecase"
+)
+
+// fakeFlags serves fixed flag values and records the evaluation contexts
+type fakeFlags struct {
+	values   map[string]any
+	contexts []flags.EvaluationContext
+}
+
+func (f *fakeFlags) Boolean(ctx context.Context, flag string, defaultValue bool, evalCtx flags.EvaluationContext) bool {
+	f.contexts = append(f.contexts, evalCtx)
+	if v, ok := f.values[flag].(bool); ok {
+		return v
+	}
+	return defaultValue
+}
+
+func (f *fakeFlags) String(ctx context.Context, flag string, defaultValue string, evalCtx flags.EvaluationContext) string {
+	f.contexts = append(f.contexts, evalCtx)
+	if v, ok := f.values[flag].(string); ok {
+		return v
+	}
+	return defaultValue
+}
+
+func (f *fakeFlags) Int(ctx context.Context, flag string, defaultValue int64, evalCtx flags.EvaluationContext) int64 {
+	f.contexts = append(f.contexts, evalCtx)
+	if v, ok := f.values[flag].(int64); ok {
+		return v
+	}
+	return defaultValue
+}
+
+// fakeSearcher records the last query
+type fakeSearcher struct {
+	query usecase.SearchQuery
+	err   error
+}
+
+func (s *fakeSearcher) Search(ctx context.Context, q usecase.SearchQuery) ([]domain.Product, error) {
+	s.query = q
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []domain.Product{
+		{SKU: "LAMP-01", Name: "Desk Lamp", Price: 4000, DiscountPercent: 25},
+		{SKU: "LAMP-02", Name: "Floor Lamp", Price: 9000},
+	}, nil
+}
+
+func setupRouter(search Searcher, client FlagClient) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	handler := newSearchHandler(search, client, logx.NewNoopLogger())
+
+	e := gin.New()
+	e.GET("/products", handler.Search)
+	return e
+}
+
+func search(t *testing.T, e *gin.Engine, headers map[string]string) (*httptest.ResponseRecorder, responsex.Envelope[SearchResponse]) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/products?q=lamp", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+
+	var env responsex.Envelope[SearchResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+	return w, env
+}
+
+func TestSearchFollowsFlags(t *testing.T) {
+	tests := []struct {
+		name          string
+		values        map[string]any
+		wantRanking   domain.Ranking
+		wantLimit     int
+		wantDiscounts bool
+	}{
+		{
+			name:        "defaults",
+			wantRanking: domain.RankingRelevance,
+			wantLimit:   defaultPageSize,
+		},
+		{
+			name:          "flags on",
+			values:        map[string]any{flagRanking: "popularity", flagPageSize: int64(25), flagShowDiscounts: true},
+			wantRanking:   domain.RankingPopularity,
+			wantLimit:     25,
+			wantDiscounts: true,
+		},
+		{
+			name:        "unusable values",
+			values:      map[string]any{flagRanking: "random", flagPageSize: int64(500)},
+			wantRanking: domain.RankingRelevance,
+			wantLimit:   defaultPageSize,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searcher := &fakeSearcher{}
+			w, env := search(t, setupRouter(searcher, &fakeFlags{values: tt.values}), nil)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			assert.Equal(t, "lamp", searcher.query.Text)
+			assert.Equal(t, tt.wantRanking, searcher.query.Ranking)
+			assert.Equal(t, tt.wantLimit, searcher.query.Limit)
+
+			assert.Equal(t, string(tt.wantRanking), env.Data.Ranking)
+			assert.Equal(t, tt.wantLimit, env.Data.PageSize)
+			require.Len(t, env.Data.Products, 2)
+			if tt.wantDiscounts {
+				require.NotNil(t, env.Data.Products[0].DiscountedPrice)
+				assert.Equal(t, int64(3000), *env.Data.Products[0].DiscountedPrice)
+				assert.Equal(t, 25, env.Data.Products[0].DiscountPercent)
+			} else {
+				assert.Nil(t, env.Data.Products[0].DiscountedPrice)
+			}
+			assert.Nil(t, env.Data.Products[1].DiscountedPrice, "products not on sale have no discount")
+		})
+	}
+}
+
+func TestSearchEvaluationContext(t *testing.T) {
+	client := &fakeFlags{}
+	search(t, setupRouter(&fakeSearcher{}, client), map[string]string{
+		headerUserID:   "alice",
+		headerUserPlan: "pro",
+	})
+
+	require.Len(t, client.contexts, 3)
+	for _, evalCtx := range client.contexts {
+		assert.Equal(t, "alice", evalCtx.TargetingKey)
+		assert.Equal(t, map[string]any{"plan": "pro"}, evalCtx.Attributes)
+	}
+
+	client = &fakeFlags{}
+	search(t, setupRouter(&fakeSearcher{}, client), nil)
+	assert.Empty(t, client.contexts[0].TargetingKey)
+	assert.Empty(t, client.contexts[0].Attributes)
+}
+
+func TestSearchErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "invalid", err: usecase.ErrInvalid, wantStatus: http.StatusBadRequest, wantCode: "INVALID_INPUT"},
+		{name: "unavailable", err: usecase.ErrUnavailable, wantStatus: http.StatusServiceUnavailable, wantCode: "SERVICE_UNAVAILABLE"},
+		{name: "unexpected", err: errors.New("boom"), wantStatus: http.StatusInternalServerError, wantCode: "INTERNAL_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, env := search(t, setupRouter(&fakeSearcher{err: tt.err}, &fakeFlags{}), nil)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			require.NotNil(t, env.Error)
+			assert.Equal(t, tt.wantCode, env.Error.Code)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/gostratum/examples/featureflags-demo/internal/domain"
+	"github.com/gostratum/examples/featureflags-demo/internal/usecase"
+)
+
+// Catalog is a fixed, read-only product list. The example is about feature
+// flags, so there is no database behind it.
+type Catalog struct {
+	products []domain.Product
+}
+
+// NewCatalog creates the catalog seeded with the demo products
+func NewCatalog() usecase.ProductRepository {
+	added := func(daysAgo int) time.Time {
+		return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -daysAgo)
+	}
+
+	return &Catalog{products: []domain.Product{
+		{SKU: "LAMP-01", Name: "Desk Lamp", Price: 3999, Sold: 820, AddedAt: added(300)},
+		{SKU: "LAMP-02", Name: "Lamp Shade", Price: 1499, DiscountPercent: 20, Sold: 140, AddedAt: added(12)},
+		{SKU: "LAMP-03", Name: "Floor Lamp", Price: 8999, Sold: 310, AddedAt: added(90)},
+		{SKU: "LAMP-04", Name: "Smart Lamp", Price: 5999, DiscountPercent: 15, Sold: 1270, AddedAt: added(40)},
+		{SKU: "CHAIR-01", Name: "Office Chair", Price: 19900, DiscountPercent: 10, Sold: 640, AddedAt: added(400)},
+		{SKU: "CHAIR-02", Name: "Chair Cushion", Price: 2499, Sold: 95, AddedAt: added(5)},
+		{SKU: "DESK-01", Name: "Standing Desk", Price: 49900, Sold: 210, AddedAt: added(180)},
+		{SKU: "DESK-02", Name: "Desk Organizer", Price: 1999, DiscountPercent: 25, Sold: 930, AddedAt: added(60)},
+		{SKU: "DESK-03", Name: "Desk Mat", Price: 2999, Sold: 1480, AddedAt: added(220)},
+		{SKU: "SHELF-01", Name: "Wall Shelf", Price: 4499, Sold: 380, AddedAt: added(30)},
+		{SKU: "SHELF-02", Name: "Bookshelf", Price: 12900, DiscountPercent: 30, Sold: 170, AddedAt: added(2)},
+		{SKU: "CABLE-01", Name: "Cable Tray", Price: 1299, Sold: 510, AddedAt: added(150)},
+	}}
+}
+
+// List implements usecase.ProductRepository
+func (c *Catalog) List(ctx context.Context) ([]domain.Product, error) {
+	// A copy, so callers can sort the result
+	return append([]domain.Product(nil), c.products...), nil
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func names(products []Product) []string {
+	out := make([]string, len(products))
+	for i, p := range products {
+		out[i] = p.Name
+	}
+	return out
+}
+
+func TestRank(t *testing.T) {
+	day := func(n int) time.Time { return time.Date(2025, 1, n, 0, 0, 0, 0, time.UTC) }
+	catalog := []Product{
+		{Name: "Floor Lamp", Sold: 10, AddedAt: day(3)},
+		{Name: "Lamp", Sold: 5, AddedAt: day(1)},
+		{Name: "Lamp Shade", Sold: 30, AddedAt: day(2)},
+		{Name: "Clamp", Sold: 30, AddedAt: day(4)},
+	}
+
+	tests := []struct {
+		ranking Ranking
+		query   string
+		want    []string
+	}{
+		// Exact match, then prefix, then a later word, then any other match
+		{ranking: RankingRelevance, query: "lamp", want: []string{"Lamp", "Lamp Shade", "Floor Lamp", "Clamp"}},
+		{ranking: RankingRelevance, query: "", want: []string{"Clamp", "Floor Lamp", "Lamp", "Lamp Shade"}},
+		{ranking: RankingPopularity, query: "lamp", want: []string{"Clamp", "Lamp Shade", "Floor Lamp", "Lamp"}},
+		{ranking: RankingNewest, query: "lamp", want: []string{"Clamp", "Floor Lamp", "Lamp Shade", "Lamp"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.ranking)+" "+tt.query, func(t *testing.T) {
+			products := append([]Product(nil), catalog...)
+			Rank(products, tt.query, tt.ranking)
+			assert.Equal(t, tt.want, names(products))
+		})
+	}
+}
+
+func TestProductMatches(t *testing.T) {
+	p := Product{SKU: "LAMP-01", Name: "Desk Lamp"}
+	assert.True(t, p.Matches(""))
+	assert.True(t, p.Matches("  DESK "))
+	assert.True(t, p.Matches("lamp-01"))
+	assert.False(t, p.Matches("chair"))
+}
+
+func TestDiscountedPrice(t *testing.T) {
+	assert.Equal(t, int64(1999), Product{Price: 1999}.DiscountedPrice())
+	assert.Equal(t, int64(1499), Product{Price: 1999, DiscountPercent: 25}.DiscountedPrice())
+}
+
+func TestParseRanking(t *testing.T) {
+	r, err := ParseRanking("popularity")
+	require.NoError(t, err)
+	assert.Equal(t, RankingPopularity, r)
+
+	_, err = ParseRanking("random")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
@@ -0,0 +1,9 @@
+package domain
+
+import "errors"
+
+// Domain errors represent business rule violations
+var (
+	// ErrInvalidInput indicates the provided input violates business rules
+	ErrInvalidInput = errors.New("invalid input")
+)
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Product is a catalog entry
+type Product struct {
+	SKU  string
+	Name string
+	// Price is in cents
+	Price int64
+	// DiscountPercent is the current markdown, 0 when the product is not on sale
+	DiscountPercent int
+	// Sold counts units sold; it is what popularity ranking sorts by
+	Sold    int
+	AddedAt time.Time
+}
+
+// DiscountedPrice returns the price after the discount, rounded down to the cent
+func (p Product) DiscountedPrice() int64 {
+	return p.Price * int64(100-p.DiscountPercent) / 100
+}
+
+// Matches reports whether the product's name or SKU contains the query,
+// ignoring case. Every product matches an empty query.
+func (p Product) Matches(query string) bool {
+	q := strings.ToLower(strings.TrimSpace(query))
+	return q == "" ||
+		strings.Contains(strings.ToLower(p.Name), q) ||
+		strings.Contains(strings.ToLower(p.SKU), q)
+}
+
+// Ranking is the order search results are returned in
+type Ranking string
+
+const (
+	// RankingRelevance puts the best name matches first
+	RankingRelevance Ranking = "relevance"
+	// RankingPopularity puts the best sellers first
+	RankingPopularity Ranking = "popularity"
+	// RankingNewest puts the most recently added products first
+	RankingNewest Ranking = "newest"
+)
+
+// ParseRanking validates a ranking name
+func ParseRanking(s string) (Ranking, error) {
+	switch r := Ranking(s); r {
+	case RankingRelevance, RankingPopularity, RankingNewest:
+		return r, nil
+	default:
+		return "", fmt.Errorf("%w: unknown ranking %q", ErrInvalidInput, s)
+	}
+}
+
+// Rank sorts products for a query. Ties are broken by name, so the order is
+// stable across requests.
+func Rank(products []Product, query string, ranking Ranking) {
+	q := strings.ToLower(strings.TrimSpace(query))
+
+	sort.SliceStable(products, func(i, j int) bool {
+		a, b := products[i], products[j]
+		switch ranking {
+		case RankingPopularity:
+			if a.Sold != b.Sold {
+				return a.Sold > b.Sold
+			}
+		case RankingNewest:
+			if !a.AddedAt.Equal(b.AddedAt) {
+				return a.AddedAt.After(b.AddedAt)
+			}
+		default:
+			if sa, sb := relevance(a, q), relevance(b, q); sa != sb {
+				return sa > sb
+			}
+		}
+		return a.Name < b.Name
+	})
+}
+
+// relevance scores how well a product's name matches a lowercased query:
+// an exact match beats a prefix, which beats the start of a later word
+func relevance(p Product, q string) int {
+	name := strings.ToLower(p.Name)
+	switch {
+	case q == "":
+		return 0
+	case name == q:
+		return 3
+	case strings.HasPrefix(name, q):
+		return 2
+	case strings.Contains(name, " "+q):
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,139 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gostratum/core/logx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Client evaluates flags through a provider and records every evaluation on
+// the span in the context, so a trace shows which variants shaped a request
+type Client struct {
+	provider FeatureProvider
+	log      logx.Logger
+}
+
+// NewClient creates a client for a provider
+func NewClient(provider FeatureProvider, log logx.Logger) *Client {
+	return &Client{provider: provider, log: log}
+}
+
+// Boolean returns the value of a boolean flag, or defaultValue if it cannot be
+// evaluated
+func (c *Client) Boolean(ctx context.Context, flag string, defaultValue bool, evalCtx EvaluationContext) bool {
+	return c.BooleanDetails(ctx, flag, defaultValue, evalCtx).Value
+}
+
+// BooleanDetails evaluates a boolean flag and reports how its value was chosen
+func (c *Client) BooleanDetails(ctx context.Context, flag string, defaultValue bool, evalCtx EvaluationContext) BoolResolutionDetail {
+	d := c.provider.BooleanEvaluation(ctx, flag, defaultValue, evalCtx.Flatten())
+	if d.ErrorCode != "" {
+		d.Value = defaultValue
+	}
+	c.record(ctx, flag, evalCtx, d.Value, d.ProviderResolutionDetail)
+	return d
+}
+
+// String returns the value of a string flag, or defaultValue if it cannot be
+// evaluated
+func (c *Client) String(ctx context.Context, flag string, defaultValue string, evalCtx EvaluationContext) string {
+	return c.StringDetails(ctx, flag, defaultValue, evalCtx).Value
+}
+
+// StringDetails evaluates a string flag and reports how its value was chosen
+func (c *Client) StringDetails(ctx context.Context, flag string, defaultValue string, evalCtx EvaluationContext) StringResolutionDetail {
+	d := c.provider.StringEvaluation(ctx, flag, defaultValue, evalCtx.Flatten())
+	if d.ErrorCode != "" {
+		d.Value = defaultValue
+	}
+	c.record(ctx, flag, evalCtx, d.Value, d.ProviderResolutionDetail)
+	return d
+}
+
+// Int returns the value of an integer flag, or defaultValue if it cannot be
+// evaluated
+func (c *Client) Int(ctx context.Context, flag string, defaultValue int64, evalCtx EvaluationContext) int64 {
+	return c.IntDetails(ctx, flag, defaultValue, evalCtx).Value
+}
+
+// IntDetails evaluates an integer flag and reports how its value was chosen
+func (c *Client) IntDetails(ctx context.Context, flag string, defaultValue int64, evalCtx EvaluationContext) IntResolutionDetail {
+	d := c.provider.IntEvaluation(ctx, flag, defaultValue, evalCtx.Flatten())
+	if d.ErrorCode != "" {
+		d.Value = defaultValue
+	}
+	c.record(ctx, flag, evalCtx, d.Value, d.ProviderResolutionDetail)
+	return d
+}
+
+// Float returns the value of a float flag, or defaultValue if it cannot be
+// evaluated
+func (c *Client) Float(ctx context.Context, flag string, defaultValue float64, evalCtx EvaluationContext) float64 {
+	return c.FloatDetails(ctx, flag, defaultValue, evalCtx).Value
+}
+
+// FloatDetails evaluates a float flag and reports how its value was chosen
+func (c *Client) FloatDetails(ctx context.Context, flag string, defaultValue float64, evalCtx EvaluationContext) FloatResolutionDetail {
+	d := c.provider.FloatEvaluation(ctx, flag, defaultValue, evalCtx.Flatten())
+	if d.ErrorCode != "" {
+		d.Value = defaultValue
+	}
+	c.record(ctx, flag, evalCtx, d.Value, d.ProviderResolutionDetail)
+	return d
+}
+
+// record adds an evaluation to the span in ctx, twice:
+//   - a "feature_flag.evaluation" event with the OpenTelemetry feature flag
+//     attributes, which keeps every evaluation in order with its reason
+//   - a flag.<key> span attribute holding the variant, because trace search in
+//     backends such as Jaeger matches span attributes but not event attributes
+func (c *Client) record(ctx context.Context, flag string, evalCtx EvaluationContext, value any, d ProviderResolutionDetail) {
+	result := d.Variant
+	if result == "" {
+		result = fmt.Sprint(value)
+	}
+
+	fields := []logx.Field{
+		logx.String("flag", flag),
+		logx.String("result", result),
+		logx.String("reason", string(d.Reason)),
+	}
+	if d.ErrorCode != "" {
+		c.log.Warn("flag evaluation failed; using the default", append(fields,
+			logx.String("error_code", string(d.ErrorCode)),
+			logx.String("error", d.ErrorMessage),
+		)...)
+	} else {
+		c.log.Debug("flag evaluated", fields...)
+	}
+
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("feature_flag.key", flag),
+		attribute.String("feature_flag.provider.name", c.provider.Metadata().Name),
+		attribute.String("feature_flag.result.reason", strings.ToLower(string(d.Reason))),
+		attribute.String("feature_flag.result.value", fmt.Sprint(value)),
+	}
+	if d.Variant != "" {
+		attrs = append(attrs, attribute.String("feature_flag.result.variant", d.Variant))
+	}
+	if evalCtx.TargetingKey != "" {
+		attrs = append(attrs, attribute.String("feature_flag.context.id", evalCtx.TargetingKey))
+	}
+	if d.ErrorCode != "" {
+		attrs = append(attrs,
+			attribute.String("error.type", strings.ToLower(string(d.ErrorCode))),
+			attribute.String("error.message", d.ErrorMessage),
+		)
+	}
+	span.AddEvent("feature_flag.evaluation", trace.WithAttributes(attrs...))
+	span.SetAttributes(attribute.String("flag."+flag, result))
+}
@@ -0,0 +1,104 @@
+package flags
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recordSpan runs fn inside a span and returns the span once it has ended
+func recordSpan(t *testing.T, fn func(ctx context.Context)) sdktrace.ReadOnlySpan {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "request")
+	fn(ctx)
+	span.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	return spans[0]
+}
+
+func attributes(kvs []attribute.KeyValue) map[string]string {
+	m := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		m[string(kv.Key)] = kv.Value.Emit()
+	}
+	return m
+}
+
+func TestClientRecordsEvaluationsOnSpan(t *testing.T) {
+	p, _ := newTestProvider(t, testFlags)
+	client := NewClient(p, logx.NewNoopLogger())
+	evalCtx := NewEvaluationContext("user-1", map[string]any{"plan": "pro"})
+
+	span := recordSpan(t, func(ctx context.Context) {
+		assert.True(t, client.Boolean(ctx, "dark-mode", false, evalCtx))
+		assert.Equal(t, int64(50), client.Int(ctx, "page-size", 10, evalCtx))
+		assert.Equal(t, "fallback", client.String(ctx, "missing", "fallback", evalCtx))
+	})
+
+	events := span.Events()
+	require.Len(t, events, 3)
+	for _, e := range events {
+		assert.Equal(t, "feature_flag.evaluation", e.Name)
+	}
+
+	assert.Equal(t, map[string]string{
+		"feature_flag.key":            "dark-mode",
+		"feature_flag.provider.name":  "file",
+		"feature_flag.result.reason":  "static",
+		"feature_flag.result.value":   "true",
+		"feature_flag.result.variant": "on",
+		"feature_flag.context.id":     "user-1",
+	}, attributes(events[0].Attributes))
+
+	failed := attributes(events[2].Attributes)
+	assert.Equal(t, "missing", failed["feature_flag.key"])
+	assert.Equal(t, "error", failed["feature_flag.result.reason"])
+	assert.Equal(t, "fallback", failed["feature_flag.result.value"])
+	assert.Equal(t, "flag_not_found", failed["error.type"])
+	assert.NotContains(t, failed, "feature_flag.result.variant")
+
+	// Variants, or the value when there is none, are searchable span attributes
+	spanAttrs := attributes(span.Attributes())
+	assert.Equal(t, "on", spanAttrs["flag.dark-mode"])
+	assert.Equal(t, "big", spanAttrs["flag.page-size"])
+	assert.Equal(t, "fallback", spanAttrs["flag.missing"])
+}
+
+func TestClientWithoutSpan(t *testing.T) {
+	p, _ := newTestProvider(t, testFlags)
+	client := NewClient(p, logx.NewNoopLogger())
+
+	d := client.FloatDetails(context.Background(), "sample-rate", 1, EvaluationContext{})
+	assert.Equal(t, 0.25, d.Value)
+	assert.Equal(t, "some", d.Variant)
+}
+
+// brokenProvider reports an error but returns a value other than the default
+type brokenProvider struct{ FeatureProvider }
+
+func (brokenProvider) BooleanEvaluation(ctx context.Context, flag string, defaultValue bool, evalCtx FlattenedContext) BoolResolutionDetail {
+	return BoolResolutionDetail{
+		Value:                    !defaultValue,
+		ProviderResolutionDetail: ProviderResolutionDetail{Reason: ErrorReason, ErrorCode: GeneralCode},
+	}
+}
+
+func (brokenProvider) Metadata() Metadata {
+	return Metadata{Name: "broken"}
+}
+
+func TestClientReturnsDefaultOnProviderError(t *testing.T) {
+	client := NewClient(brokenProvider{}, logx.NewNoopLogger())
+	assert.True(t, client.Boolean(context.Background(), "f", true, EvaluationContext{}))
+}
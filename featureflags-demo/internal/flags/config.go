@@ -0,0 +1,21 @@
+// Package flags is a small fx module for feature flags. Its FeatureProvider
+// interface and evaluation types follow the OpenFeature provider contract, the
+// flags themselves come from a YAML file, and Client records every evaluation
+// on the current trace span.
+package flags
+
+import "time"
+
+// Config locates the flag file
+type Config struct {
+	// Path is the YAML file holding the flag definitions
+	Path string `mapstructure:"path" default:"./configs/flags.yaml"`
+	// PollInterval is how often the file is checked for changes; 0 loads it
+	// once at startup
+	PollInterval time.Duration `mapstructure:"poll_interval" default:"5s"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "flags"
+}
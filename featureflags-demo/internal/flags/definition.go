@@ -0,0 +1,183 @@
+package flags
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"slices"
+	"sort"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Flag states
+const (
+	stateEnabled  = "ENABLED"
+	stateDisabled = "DISABLED"
+)
+
+// flagFile is the layout of the flag file
+type flagFile struct {
+	Flags map[string]*definition `yaml:"flags"`
+}
+
+// definition is one flag of the flag file. state, variants and defaultVariant
+// mean what they mean in flagd flag definitions; targeting is expressed as
+// rules and a rollout rather than JSONLogic.
+type definition struct {
+	State          string         `yaml:"state"`
+	Variants       map[string]any `yaml:"variants"`
+	DefaultVariant string         `yaml:"defaultVariant"`
+	// Rules are tried in order; the first match picks the variant
+	Rules []rule `yaml:"rules"`
+	// Rollout maps variants to percentages adding up to 100. It applies when
+	// no rule matched and the context has a targeting key.
+	Rollout map[string]int `yaml:"rollout"`
+
+	// rolloutOrder lists the rollout variants in a fixed order, so a
+	// targeting key lands on the same variant every time
+	rolloutOrder []string
+}
+
+// rule picks a variant when a context attribute has one of the listed values
+type rule struct {
+	Attribute string   `yaml:"attribute"`
+	In        []string `yaml:"in"`
+	Variant   string   `yaml:"variant"`
+}
+
+// parseFlags reads and validates a flag file. Unknown fields are errors, so a
+// misspelt key fails the load instead of being ignored.
+func parseFlags(data []byte) (map[string]*definition, error) {
+	var file flagFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	for key, def := range file.Flags {
+		if def == nil {
+			return nil, fmt.Errorf("flag %q: no definition", key)
+		}
+		if err := def.validate(); err != nil {
+			return nil, fmt.Errorf("flag %q: %w", key, err)
+		}
+	}
+	if file.Flags == nil {
+		file.Flags = map[string]*definition{}
+	}
+	return file.Flags, nil
+}
+
+func (d *definition) validate() error {
+	if d.State != stateEnabled && d.State != stateDisabled {
+		return fmt.Errorf("state must be %s or %s, got %q", stateEnabled, stateDisabled, d.State)
+	}
+	if len(d.Variants) == 0 {
+		return errors.New("no variants")
+	}
+
+	kind := ""
+	for name, value := range d.Variants {
+		k := kindOf(value)
+		if k == "" {
+			return fmt.Errorf("variant %q: unsupported value %v", name, value)
+		}
+		if kind != "" && k != kind {
+			return errors.New("variants mix value types")
+		}
+		kind = k
+	}
+
+	if _, ok := d.Variants[d.DefaultVariant]; !ok {
+		return fmt.Errorf("defaultVariant %q is not a variant", d.DefaultVariant)
+	}
+	for i, r := range d.Rules {
+		if r.Attribute == "" || len(r.In) == 0 {
+			return fmt.Errorf("rule %d: attribute and in are required", i+1)
+		}
+		if _, ok := d.Variants[r.Variant]; !ok {
+			return fmt.Errorf("rule %d: variant %q is not a variant", i+1, r.Variant)
+		}
+	}
+
+	total := 0
+	for name, pct := range d.Rollout {
+		if _, ok := d.Variants[name]; !ok {
+			return fmt.Errorf("rollout: %q is not a variant", name)
+		}
+		if pct < 0 {
+			return fmt.Errorf("rollout: %q has a negative percentage", name)
+		}
+		total += pct
+		d.rolloutOrder = append(d.rolloutOrder, name)
+	}
+	if len(d.Rollout) > 0 && total != 100 {
+		return fmt.Errorf("rollout percentages add up to %d, not 100", total)
+	}
+	sort.Strings(d.rolloutOrder)
+	return nil
+}
+
+// evaluate picks the variant for a context. The value is nil when the flag is
+// disabled, in which case the caller's default applies.
+func (d *definition) evaluate(key string, evalCtx FlattenedContext) (any, ProviderResolutionDetail) {
+	if d.State == stateDisabled {
+		return nil, ProviderResolutionDetail{Reason: DisabledReason}
+	}
+
+	for _, r := range d.Rules {
+		if v, ok := evalCtx[r.Attribute]; ok && slices.Contains(r.In, fmt.Sprint(v)) {
+			return d.Variants[r.Variant], ProviderResolutionDetail{Reason: TargetingMatchReason, Variant: r.Variant}
+		}
+	}
+
+	if len(d.Rollout) > 0 {
+		if tk, _ := evalCtx[TargetingKey].(string); tk != "" {
+			variant := d.bucket(key, tk)
+			return d.Variants[variant], ProviderResolutionDetail{Reason: SplitReason, Variant: variant}
+		}
+	}
+
+	reason := DefaultReason
+	if len(d.Rules) == 0 && len(d.Rollout) == 0 {
+		reason = StaticReason
+	}
+	return d.Variants[d.DefaultVariant], ProviderResolutionDetail{Reason: reason, Variant: d.DefaultVariant}
+}
+
+// bucket maps a targeting key to a rollout variant. The flag key is part of
+// the hash, so the same users do not end up in the first bucket of every flag.
+func (d *definition) bucket(key, targetingKey string) string {
+	h := fnv.New32a()
+	h.Write([]byte(key + "/" + targetingKey))
+	n := int(h.Sum32() % 100)
+
+	for _, variant := range d.rolloutOrder {
+		n -= d.Rollout[variant]
+		if n < 0 {
+			return variant
+		}
+	}
+	// Unreachable once validate has checked the percentages add up to 100
+	return d.DefaultVariant
+}
+
+// kindOf names the type of a variant value, or returns "" for types flags
+// cannot hold. Integers and floats are both numbers, so a float flag may have
+// whole-number variants.
+func kindOf(v any) string {
+	switch v.(type) {
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case int, int64, uint64, float64:
+		return "number"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,183 @@
+package flags
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			yaml: `
+flags:
+  ranking:
+    state: ENABLED
+    variants: {a: relevance, b: popularity}
+    defaultVariant: a
+    rules:
+      - {attribute: plan, in: [pro], variant: b}
+    rollout: {a: 90, b: 10}
+  ratio:
+    state: DISABLED
+    variants: {low: 1, high: 0.5}
+    defaultVariant: low`,
+		},
+		{name: "empty file", yaml: ""},
+		{
+			name:    "unknown field",
+			yaml:    "flags:\n  f:\n    state: ENABLED\n    variants: {on: true}\n    default: on",
+			wantErr: "field default not found",
+		},
+		{
+			name:    "invalid state",
+			yaml:    "flags:\n  f:\n    state: enabled\n    variants: {a: true}\n    defaultVariant: a",
+			wantErr: `flag "f": state must be ENABLED or DISABLED`,
+		},
+		{
+			name:    "no variants",
+			yaml:    "flags:\n  f:\n    state: ENABLED\n    defaultVariant: a",
+			wantErr: "no variants",
+		},
+		{
+			name:    "mixed types",
+			yaml:    "flags:\n  f:\n    state: ENABLED\n    variants: {a: true, b: 'yes'}\n    defaultVariant: a",
+			wantErr: "variants mix value types",
+		},
+		{
+			name:    "unsupported value",
+			yaml:    "flags:\n  f:\n    state: ENABLED\n    variants: {a: [1, 2]}\n    defaultVariant: a",
+			wantErr: `variant "a": unsupported value`,
+		},
+		{
+			name:    "unknown default variant",
+			yaml:    "flags:\n  f:\n    state: ENABLED\n    variants: {a: true}\n    defaultVariant: b",
+			wantErr: `defaultVariant "b" is not a variant`,
+		},
+		{
+			name:    "rule without values",
+			yaml:    "flags:\n  f:\n    state: ENABLED\n    variants: {a: true}\n    defaultVariant: a\n    rules:\n      - {attribute: plan, variant: a}",
+			wantErr: "rule 1: attribute and in are required",
+		},
+		{
+			name:    "rule with unknown variant",
+			yaml:    "flags:\n  f:\n    state: ENABLED\n    variants: {a: true}\n    defaultVariant: a\n    rules:\n      - {attribute: plan, in: [pro], variant: b}",
+			wantErr: `rule 1: variant "b" is not a variant`,
+		},
+		{
+			name:    "rollout not adding up",
+			yaml:    "flags:\n  f:\n    state: ENABLED\n    variants: {a: true, b: false}\n    defaultVariant: a\n    rollout: {a: 50, b: 40}",
+			wantErr: "rollout percentages add up to 90, not 100",
+		},
+		{
+			name:    "rollout with unknown variant",
+			yaml:    "flags:\n  f:\n    state: ENABLED\n    variants: {a: true}\n    defaultVariant: a\n    rollout: {a: 50, c: 50}",
+			wantErr: `rollout: "c" is not a variant`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags, err := parseFlags([]byte(tt.yaml))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				assert.NotNil(t, flags)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func mustParse(t *testing.T, yaml string) map[string]*definition {
+	t.Helper()
+	flags, err := parseFlags([]byte(yaml))
+	require.NoError(t, err)
+	return flags
+}
+
+func TestEvaluate(t *testing.T) {
+	flags := mustParse(t, `
+flags:
+  static:
+    state: ENABLED
+    variants: {a: 1}
+    defaultVariant: a
+  off:
+    state: DISABLED
+    variants: {a: 1}
+    defaultVariant: a
+  targeted:
+    state: ENABLED
+    variants: {a: 1, b: 2, c: 3}
+    defaultVariant: a
+    rules:
+      - {attribute: plan, in: [pro, enterprise], variant: b}
+      - {attribute: targetingKey, in: [alice], variant: c}
+      - {attribute: beta, in: ["true"], variant: c}`)
+
+	tests := []struct {
+		flag        string
+		ctx         FlattenedContext
+		wantValue   any
+		wantVariant string
+		wantReason  Reason
+	}{
+		{flag: "static", wantValue: 1, wantVariant: "a", wantReason: StaticReason},
+		{flag: "off", wantValue: nil, wantReason: DisabledReason},
+		{flag: "targeted", wantValue: 1, wantVariant: "a", wantReason: DefaultReason},
+		{flag: "targeted", ctx: FlattenedContext{"plan": "free"}, wantValue: 1, wantVariant: "a", wantReason: DefaultReason},
+		{flag: "targeted", ctx: FlattenedContext{"plan": "enterprise"}, wantValue: 2, wantVariant: "b", wantReason: TargetingMatchReason},
+		{flag: "targeted", ctx: FlattenedContext{TargetingKey: "alice"}, wantValue: 3, wantVariant: "c", wantReason: TargetingMatchReason},
+		// The first matching rule wins
+		{flag: "targeted", ctx: FlattenedContext{"plan": "pro", TargetingKey: "alice"}, wantValue: 2, wantVariant: "b", wantReason: TargetingMatchReason},
+		// Non-string attributes are compared by their text
+		{flag: "targeted", ctx: FlattenedContext{"beta": true}, wantValue: 3, wantVariant: "c", wantReason: TargetingMatchReason},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %v", tt.flag, tt.ctx), func(t *testing.T) {
+			value, detail := flags[tt.flag].evaluate(tt.flag, tt.ctx)
+			assert.Equal(t, tt.wantValue, value)
+			assert.Equal(t, tt.wantVariant, detail.Variant)
+			assert.Equal(t, tt.wantReason, detail.Reason)
+			assert.Empty(t, detail.ErrorCode)
+		})
+	}
+}
+
+func TestEvaluateRollout(t *testing.T) {
+	flags := mustParse(t, `
+flags:
+  split:
+    state: ENABLED
+    variants: {on: true, off: false}
+    defaultVariant: off
+    rollout: {on: 25, off: 75}`)
+	def := flags["split"]
+
+	// Anonymous callers cannot be bucketed
+	_, detail := def.evaluate("split", FlattenedContext{})
+	assert.Equal(t, DefaultReason, detail.Reason)
+	assert.Equal(t, "off", detail.Variant)
+
+	counts := map[string]int{}
+	for i := range 2000 {
+		key := fmt.Sprintf("user-%d", i)
+		_, first := def.evaluate("split", FlattenedContext{TargetingKey: key})
+		_, again := def.evaluate("split", FlattenedContext{TargetingKey: key})
+		require.Equal(t, first.Variant, again.Variant, "a user must keep their variant")
+		assert.Equal(t, SplitReason, first.Reason)
+		counts[first.Variant]++
+	}
+	assert.InDelta(t, 500, counts["on"], 100)
+	assert.InDelta(t, 1500, counts["off"], 100)
+}
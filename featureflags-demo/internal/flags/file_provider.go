@@ -0,0 +1,233 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"go.uber.org/fx"
+)
+
+// FileProvider serves the flags defined in a YAML file and picks up changes to
+// the file while the application runs
+type FileProvider struct {
+	cfg Config
+	log logx.Logger
+
+	mu    sync.RWMutex
+	flags map[string]*definition
+	// modTime and size identify the version of the file last read, valid or not
+	modTime time.Time
+	size    int64
+
+	stopPoll chan struct{}
+	polling  sync.WaitGroup
+}
+
+// NewFileProvider creates the provider from the flags config section. It fails
+// if the flag file is missing or invalid.
+func NewFileProvider(loader configx.Loader, log logx.Logger) (*FileProvider, error) {
+	var cfg Config
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load flags config: %w", err)
+	}
+	return newFileProvider(cfg, log)
+}
+
+func newFileProvider(cfg Config, log logx.Logger) (*FileProvider, error) {
+	p := &FileProvider{cfg: cfg, log: log, stopPoll: make(chan struct{})}
+	if _, err := p.reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Register ties polling of the flag file to the application lifecycle.
+// This function is designed to be used with fx.Invoke.
+func Register(lc fx.Lifecycle, p *FileProvider) {
+	lc.Append(fx.Hook{
+		OnStart: p.start,
+		OnStop:  p.stop,
+	})
+}
+
+// start begins watching the flag file
+func (p *FileProvider) start(ctx context.Context) error {
+	p.log.Info("flags loaded",
+		logx.String("path", p.cfg.Path),
+		logx.Int("flags", p.count()),
+		logx.String("poll_interval", p.cfg.PollInterval.String()),
+	)
+	if p.cfg.PollInterval <= 0 {
+		return nil
+	}
+
+	p.polling.Add(1)
+	go p.poll()
+	return nil
+}
+
+// stop stops watching the flag file
+func (p *FileProvider) stop(ctx context.Context) error {
+	close(p.stopPoll)
+	p.polling.Wait()
+	return nil
+}
+
+// poll reloads the flag file whenever it changes. An invalid file is logged
+// once and the flags loaded before stay in effect.
+func (p *FileProvider) poll() {
+	defer p.polling.Done()
+
+	ticker := time.NewTicker(p.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stopPoll:
+			return
+		case <-ticker.C:
+			changed, err := p.reload()
+			if err != nil {
+				p.log.Error("flag file not reloaded; keeping the previous flags", logx.Err(err))
+				continue
+			}
+			if changed {
+				p.log.Info("flags reloaded", logx.Int("flags", p.count()))
+			}
+		}
+	}
+}
+
+// reload reads the flag file if it changed since the last read, and reports
+// whether new flags were loaded
+func (p *FileProvider) reload() (bool, error) {
+	info, err := os.Stat(p.cfg.Path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read flag file: %w", err)
+	}
+
+	p.mu.RLock()
+	unchanged := p.flags != nil && info.ModTime().Equal(p.modTime) && info.Size() == p.size
+	p.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(p.cfg.Path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read flag file: %w", err)
+	}
+	flags, err := parseFlags(data)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// Remembered even when the file is invalid, so it is reported once, not on every poll
+	p.modTime, p.size = info.ModTime(), info.Size()
+	if err != nil {
+		return false, fmt.Errorf("invalid flag file %s: %w", p.cfg.Path, err)
+	}
+	p.flags = flags
+	return true, nil
+}
+
+func (p *FileProvider) count() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.flags)
+}
+
+// Metadata implements FeatureProvider
+func (p *FileProvider) Metadata() Metadata {
+	return Metadata{Name: "file"}
+}
+
+// BooleanEvaluation implements FeatureProvider
+func (p *FileProvider) BooleanEvaluation(ctx context.Context, flag string, defaultValue bool, evalCtx FlattenedContext) BoolResolutionDetail {
+	value, detail := resolve(p, flag, defaultValue, evalCtx, func(v any) (bool, bool) {
+		b, ok := v.(bool)
+		return b, ok
+	})
+	return BoolResolutionDetail{Value: value, ProviderResolutionDetail: detail}
+}
+
+// StringEvaluation implements FeatureProvider
+func (p *FileProvider) StringEvaluation(ctx context.Context, flag string, defaultValue string, evalCtx FlattenedContext) StringResolutionDetail {
+	value, detail := resolve(p, flag, defaultValue, evalCtx, func(v any) (string, bool) {
+		s, ok := v.(string)
+		return s, ok
+	})
+	return StringResolutionDetail{Value: value, ProviderResolutionDetail: detail}
+}
+
+// IntEvaluation implements FeatureProvider
+func (p *FileProvider) IntEvaluation(ctx context.Context, flag string, defaultValue int64, evalCtx FlattenedContext) IntResolutionDetail {
+	value, detail := resolve(p, flag, defaultValue, evalCtx, func(v any) (int64, bool) {
+		switch n := v.(type) {
+		case int:
+			return int64(n), true
+		case int64:
+			return n, true
+		case uint64:
+			return int64(n), n <= math.MaxInt64
+		default:
+			return 0, false
+		}
+	})
+	return IntResolutionDetail{Value: value, ProviderResolutionDetail: detail}
+}
+
+// FloatEvaluation implements FeatureProvider
+func (p *FileProvider) FloatEvaluation(ctx context.Context, flag string, defaultValue float64, evalCtx FlattenedContext) FloatResolutionDetail {
+	value, detail := resolve(p, flag, defaultValue, evalCtx, func(v any) (float64, bool) {
+		switch n := v.(type) {
+		case float64:
+			return n, true
+		case int:
+			return float64(n), true
+		case int64:
+			return float64(n), true
+		case uint64:
+			return float64(n), true
+		default:
+			return 0, false
+		}
+	})
+	return FloatResolutionDetail{Value: value, ProviderResolutionDetail: detail}
+}
+
+// resolve evaluates a flag and converts its value to the requested type. The
+// caller's default is returned when the flag is disabled, missing or of
+// another type.
+func resolve[T any](p *FileProvider, flag string, defaultValue T, evalCtx FlattenedContext, convert func(any) (T, bool)) (T, ProviderResolutionDetail) {
+	p.mu.RLock()
+	def, ok := p.flags[flag]
+	p.mu.RUnlock()
+	if !ok {
+		return defaultValue, ProviderResolutionDetail{
+			Reason:       ErrorReason,
+			ErrorCode:    FlagNotFoundCode,
+			ErrorMessage: fmt.Sprintf("flag %q not found", flag),
+		}
+	}
+
+	value, detail := def.evaluate(flag, evalCtx)
+	if detail.Reason == DisabledReason {
+		return defaultValue, detail
+	}
+
+	v, ok := convert(value)
+	if !ok {
+		return defaultValue, ProviderResolutionDetail{
+			Reason:       ErrorReason,
+			ErrorCode:    TypeMismatchCode,
+			ErrorMessage: fmt.Sprintf("flag %q has the value %v, not a %T", flag, value, defaultValue),
+		}
+	}
+	return v, detail
+}
@@ -0,0 +1,161 @@
+package flags
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFlags = `
+flags:
+  dark-mode:
+    state: ENABLED
+    variants: {on: true, off: false}
+    defaultVariant: on
+  theme:
+    state: ENABLED
+    variants: {light: light, dark: dark}
+    defaultVariant: dark
+  page-size:
+    state: ENABLED
+    variants: {small: 5, big: 50}
+    defaultVariant: big
+  sample-rate:
+    state: ENABLED
+    variants: {all: 1, some: 0.25}
+    defaultVariant: some
+  legacy:
+    state: DISABLED
+    variants: {on: true}
+    defaultVariant: on
+`
+
+// writeFlags writes a flag file with the given modification time
+func writeFlags(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func newTestProvider(t *testing.T, content string) (*FileProvider, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	writeFlags(t, path, content, time.Now().Add(-time.Hour))
+
+	p, err := newFileProvider(Config{Path: path}, logx.NewNoopLogger())
+	require.NoError(t, err)
+	return p, path
+}
+
+func TestFileProviderEvaluations(t *testing.T) {
+	p, _ := newTestProvider(t, testFlags)
+	ctx := context.Background()
+
+	b := p.BooleanEvaluation(ctx, "dark-mode", false, nil)
+	assert.True(t, b.Value)
+	assert.Equal(t, "on", b.Variant)
+	assert.Equal(t, StaticReason, b.Reason)
+
+	s := p.StringEvaluation(ctx, "theme", "light", nil)
+	assert.Equal(t, "dark", s.Value)
+
+	i := p.IntEvaluation(ctx, "page-size", 10, nil)
+	assert.Equal(t, int64(50), i.Value)
+
+	f := p.FloatEvaluation(ctx, "sample-rate", 0, nil)
+	assert.Equal(t, 0.25, f.Value)
+	f = p.FloatEvaluation(ctx, "page-size", 0, nil)
+	assert.Equal(t, float64(50), f.Value, "whole numbers are valid floats")
+}
+
+func TestFileProviderReturnsDefaultOnFailure(t *testing.T) {
+	p, _ := newTestProvider(t, testFlags)
+	ctx := context.Background()
+
+	missing := p.BooleanEvaluation(ctx, "nope", true, nil)
+	assert.True(t, missing.Value)
+	assert.Equal(t, ErrorReason, missing.Reason)
+	assert.Equal(t, FlagNotFoundCode, missing.ErrorCode)
+
+	mismatch := p.IntEvaluation(ctx, "theme", 7, nil)
+	assert.Equal(t, int64(7), mismatch.Value)
+	assert.Equal(t, ErrorReason, mismatch.Reason)
+	assert.Equal(t, TypeMismatchCode, mismatch.ErrorCode)
+	assert.Contains(t, mismatch.ErrorMessage, `flag "theme"`)
+
+	fractional := p.IntEvaluation(ctx, "sample-rate", 7, nil)
+	assert.Equal(t, int64(7), fractional.Value)
+	assert.Equal(t, TypeMismatchCode, fractional.ErrorCode)
+
+	disabled := p.BooleanEvaluation(ctx, "legacy", false, nil)
+	assert.False(t, disabled.Value)
+	assert.Equal(t, DisabledReason, disabled.Reason)
+	assert.Empty(t, disabled.ErrorCode)
+}
+
+func TestNewFileProviderFailsOnBadFile(t *testing.T) {
+	_, err := newFileProvider(Config{Path: filepath.Join(t.TempDir(), "missing.yaml")}, logx.NewNoopLogger())
+	assert.ErrorContains(t, err, "failed to read flag file")
+
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	writeFlags(t, path, "flags:\n  f:\n    state: maybe", time.Now())
+	_, err = newFileProvider(Config{Path: path}, logx.NewNoopLogger())
+	assert.ErrorContains(t, err, "invalid flag file")
+}
+
+func TestFileProviderReload(t *testing.T) {
+	p, path := newTestProvider(t, testFlags)
+	ctx := context.Background()
+
+	changed, err := p.reload()
+	require.NoError(t, err)
+	assert.False(t, changed, "an unchanged file is not read again")
+
+	writeFlags(t, path, `
+flags:
+  dark-mode:
+    state: DISABLED
+    variants: {on: true}
+    defaultVariant: on`, time.Now().Add(-time.Minute))
+	changed, err = p.reload()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, DisabledReason, p.BooleanEvaluation(ctx, "dark-mode", false, nil).Reason)
+	assert.Equal(t, FlagNotFoundCode, p.StringEvaluation(ctx, "theme", "", nil).ErrorCode)
+
+	// An invalid file is reported once and the flags loaded before stay in effect
+	writeFlags(t, path, "flags: [", time.Now())
+	_, err = p.reload()
+	assert.ErrorContains(t, err, "invalid flag file")
+	changed, err = p.reload()
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, DisabledReason, p.BooleanEvaluation(ctx, "dark-mode", false, nil).Reason)
+}
+
+func TestFileProviderPollsForChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	writeFlags(t, path, testFlags, time.Now().Add(-time.Hour))
+	p, err := newFileProvider(Config{Path: path, PollInterval: 10 * time.Millisecond}, logx.NewNoopLogger())
+	require.NoError(t, err)
+
+	require.NoError(t, p.start(context.Background()))
+	defer p.stop(context.Background())
+
+	writeFlags(t, path, `
+flags:
+  theme:
+    state: ENABLED
+    variants: {light: light}
+    defaultVariant: light`, time.Now())
+
+	assert.Eventually(t, func() bool {
+		return p.StringEvaluation(context.Background(), "theme", "", nil).Value == "light"
+	}, time.Second, 10*time.Millisecond)
+}
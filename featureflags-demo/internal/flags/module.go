@@ -0,0 +1,18 @@
+package flags
+
+import "go.uber.org/fx"
+
+// Module provides the file provider, as itself and as the FeatureProvider, and
+// a Client on top of it. The flag file is watched from application start to stop.
+//
+// To use another provider, leave out Module and provide a FeatureProvider and
+// NewClient instead.
+func Module() fx.Option {
+	return fx.Module("flags",
+		fx.Provide(
+			fx.Annotate(NewFileProvider, fx.As(fx.Self()), fx.As(new(FeatureProvider))),
+			NewClient,
+		),
+		fx.Invoke(Register),
+	)
+}
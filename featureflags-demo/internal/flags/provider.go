@@ -0,0 +1,120 @@
+package flags
+
+import "context"
+
+// Reason says why an evaluation produced its value. The values are the ones
+// defined by the OpenFeature specification.
+type Reason string
+
+const (
+	// StaticReason: the flag has a single value and no targeting
+	StaticReason Reason = "STATIC"
+	// DefaultReason: no rule matched, so the flag's default variant was used
+	DefaultReason Reason = "DEFAULT"
+	// TargetingMatchReason: a targeting rule matched the evaluation context
+	TargetingMatchReason Reason = "TARGETING_MATCH"
+	// SplitReason: the variant was picked by a percentage rollout
+	SplitReason Reason = "SPLIT"
+	// DisabledReason: the flag is switched off, so the caller's default was used
+	DisabledReason Reason = "DISABLED"
+	// ErrorReason: the evaluation failed, so the caller's default was used
+	ErrorReason Reason = "ERROR"
+)
+
+// ErrorCode says why an evaluation failed. The values are the ones defined by
+// the OpenFeature specification.
+type ErrorCode string
+
+const (
+	// FlagNotFoundCode: no flag has the requested key
+	FlagNotFoundCode ErrorCode = "FLAG_NOT_FOUND"
+	// TypeMismatchCode: the flag's value is not of the requested type
+	TypeMismatchCode ErrorCode = "TYPE_MISMATCH"
+	// GeneralCode: any other failure
+	GeneralCode ErrorCode = "GENERAL"
+)
+
+// TargetingKey is the key of the targeting key in a FlattenedContext
+const TargetingKey = "targetingKey"
+
+// FlattenedContext is the evaluation context as a provider receives it: the
+// attributes, plus the targeting key under TargetingKey
+type FlattenedContext map[string]any
+
+// EvaluationContext describes who a flag is evaluated for
+type EvaluationContext struct {
+	// TargetingKey identifies the subject, usually a user ID. Percentage
+	// rollouts bucket on it, so the same key always gets the same variant.
+	TargetingKey string
+	// Attributes are matched by targeting rules
+	Attributes map[string]any
+}
+
+// NewEvaluationContext creates an evaluation context
+func NewEvaluationContext(targetingKey string, attributes map[string]any) EvaluationContext {
+	return EvaluationContext{TargetingKey: targetingKey, Attributes: attributes}
+}
+
+// Flatten returns the context in the form providers receive it
+func (e EvaluationContext) Flatten() FlattenedContext {
+	flat := make(FlattenedContext, len(e.Attributes)+1)
+	for k, v := range e.Attributes {
+		flat[k] = v
+	}
+	if e.TargetingKey != "" {
+		flat[TargetingKey] = e.TargetingKey
+	}
+	return flat
+}
+
+// ProviderResolutionDetail is what a provider reports besides the value
+type ProviderResolutionDetail struct {
+	Reason  Reason
+	Variant string
+	// ErrorCode is empty unless the evaluation failed
+	ErrorCode    ErrorCode
+	ErrorMessage string
+}
+
+// BoolResolutionDetail is the result of a boolean evaluation
+type BoolResolutionDetail struct {
+	Value bool
+	ProviderResolutionDetail
+}
+
+// StringResolutionDetail is the result of a string evaluation
+type StringResolutionDetail struct {
+	Value string
+	ProviderResolutionDetail
+}
+
+// IntResolutionDetail is the result of an integer evaluation
+type IntResolutionDetail struct {
+	Value int64
+	ProviderResolutionDetail
+}
+
+// FloatResolutionDetail is the result of a float evaluation
+type FloatResolutionDetail struct {
+	Value float64
+	ProviderResolutionDetail
+}
+
+// Metadata describes a provider
+type Metadata struct {
+	Name string
+}
+
+// FeatureProvider resolves flag values. Its methods have the signatures of the
+// OpenFeature Go SDK's FeatureProvider, so a provider written against it can
+// back an OpenFeature client through a thin adapter, and the other way round.
+//
+// A provider never fails an evaluation with an error: it returns defaultValue
+// and sets ErrorCode, and Reason says why the value was chosen.
+type FeatureProvider interface {
+	Metadata() Metadata
+	BooleanEvaluation(ctx context.Context, flag string, defaultValue bool, evalCtx FlattenedContext) BoolResolutionDetail
+	StringEvaluation(ctx context.Context, flag string, defaultValue string, evalCtx FlattenedContext) StringResolutionDetail
+	IntEvaluation(ctx context.Context, flag string, defaultValue int64, evalCtx FlattenedContext) IntResolutionDetail
+	FloatEvaluation(ctx context.Context, flag string, defaultValue float64, evalCtx FlattenedContext) FloatResolutionDetail
+}
@@ -0,0 +1,17 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/gostratum/examples/featureflags-demo/internal/domain"
+)
+
+// Application-level errors for use case layer
+// These are used to communicate failures to the presentation layer
+var (
+	// ErrUnavailable indicates the catalog cannot be read
+	ErrUnavailable = errors.New("service unavailable")
+
+	// ErrInvalid wraps domain.ErrInvalidInput for application layer
+	ErrInvalid = domain.ErrInvalidInput
+)
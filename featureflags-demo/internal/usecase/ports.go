@@ -0,0 +1,13 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/gostratum/examples/featureflags-demo/internal/domain"
+)
+
+// ProductRepository lists the catalog
+// This interface is owned by the use case layer (dependency inversion principle)
+type ProductRepository interface {
+	List(ctx context.Context) ([]domain.Product, error)
+}
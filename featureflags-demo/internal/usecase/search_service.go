@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/gostratum/examples/featureflags-demo/internal/domain"
+)
+
+const (
+	// maxQueryLength limits the search text, in characters
+	maxQueryLength = 100
+	// maxLimit caps the page size, whatever the caller or a flag asks for
+	maxLimit = 50
+)
+
+// SearchQuery describes one search
+type SearchQuery struct {
+	Text    string
+	Ranking domain.Ranking
+	// Limit is the number of products to return, 1 to 50
+	Limit int
+}
+
+// SearchService searches the catalog. It knows nothing about feature flags;
+// the caller decides the ranking and page size.
+type SearchService struct {
+	products ProductRepository
+}
+
+// NewSearchService creates a new search service with repository injection
+func NewSearchService(products ProductRepository) *SearchService {
+	return &SearchService{products: products}
+}
+
+// Search returns the products matching the query text, ranked and limited
+func (s *SearchService) Search(ctx context.Context, q SearchQuery) ([]domain.Product, error) {
+	if utf8.RuneCountInString(q.Text) > maxQueryLength {
+		return nil, fmt.Errorf("%w: query must be at most %d characters", ErrInvalid, maxQueryLength)
+	}
+	if q.Limit < 1 || q.Limit > maxLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalid, maxLimit)
+	}
+	if _, err := domain.ParseRanking(string(q.Ranking)); err != nil {
+		return nil, err
+	}
+
+	all, err := s.products.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	matches := make([]domain.Product, 0, len(all))
+	for _, p := range all {
+		if p.Matches(q.Text) {
+			matches = append(matches, p)
+		}
+	}
+	domain.Rank(matches, q.Text, q.Ranking)
+
+	if len(matches) > q.Limit {
+		matches = matches[:q.Limit]
+	}
+	return matches, nil
+}
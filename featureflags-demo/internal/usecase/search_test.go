@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/featureflags-demo/internal/domain"
+)
+
+// fakeRepository returns a fixed product list
+type fakeRepository struct {
+	products []domain.Product
+	err      error
+}
+
+func (r *fakeRepository) List(ctx context.Context) ([]domain.Product, error) {
+	return append([]domain.Product(nil), r.products...), r.err
+}
+
+func newTestService() *SearchService {
+	return NewSearchService(&fakeRepository{products: []domain.Product{
+		{SKU: "A", Name: "Desk Lamp", Sold: 1},
+		{SKU: "B", Name: "Lamp Shade", Sold: 3},
+		{SKU: "C", Name: "Office Chair", Sold: 2},
+		{SKU: "D", Name: "Floor Lamp", Sold: 5},
+	}})
+}
+
+func TestSearch(t *testing.T) {
+	svc := newTestService()
+
+	products, err := svc.Search(context.Background(), SearchQuery{Text: "lamp", Ranking: domain.RankingPopularity, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, products, 2)
+	assert.Equal(t, "D", products[0].SKU)
+	assert.Equal(t, "B", products[1].SKU)
+
+	products, err = svc.Search(context.Background(), SearchQuery{Ranking: domain.RankingRelevance, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, products, 4)
+}
+
+func TestSearchValidation(t *testing.T) {
+	svc := newTestService()
+
+	tests := []struct {
+		name  string
+		query SearchQuery
+	}{
+		{name: "limit too small", query: SearchQuery{Ranking: domain.RankingRelevance, Limit: 0}},
+		{name: "limit too large", query: SearchQuery{Ranking: domain.RankingRelevance, Limit: 51}},
+		{name: "query too long", query: SearchQuery{Text: strings.Repeat("x", 101), Ranking: domain.RankingRelevance, Limit: 10}},
+		{name: "unknown ranking", query: SearchQuery{Ranking: "random", Limit: 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Search(context.Background(), tt.query)
+			assert.ErrorIs(t, err, ErrInvalid)
+		})
+	}
+}
+
+func TestSearchRepositoryFailure(t *testing.T) {
+	svc := NewSearchService(&fakeRepository{err: errors.New("connection refused")})
+
+	_, err := svc.Search(context.Background(), SearchQuery{Ranking: domain.RankingRelevance, Limit: 10})
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/examples/gateway-demo/internal/adapter/gateway"
+	grpcAdapter "github.com/gostratum/examples/gateway-demo/internal/adapter/grpc"
+	httpAdapter "github.com/gostratum/examples/gateway-demo/internal/adapter/http"
+	"github.com/gostratum/examples/gateway-demo/internal/adapter/memory"
+	"github.com/gostratum/examples/gateway-demo/internal/usecase"
+	"github.com/gostratum/examples/redact"
+	"github.com/gostratum/httpx"
+	"github.com/gostratum/metricsx"
+	"github.com/gostratum/tracingx"
+)
+
+func main() {
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// Observability: the gRPC interceptors record metrics and the stats
+		// handlers report spans for both transports
+		metricsx.Module(),
+		tracingx.Module(),
+
+		// HTTP server the REST gateway is mounted on
+		httpx.Module(),
+
+		// Provide dependencies
+		fx.Provide(
+			// In-memory products
+			memory.NewProducts,
+
+			// Usecase services
+			usecase.NewCatalogService,
+
+			// gRPC server and service handlers
+			grpcAdapter.NewServer,
+			grpcAdapter.NewCatalogServer,
+
+			// REST gateway, a client of the gRPC server
+			gateway.NewGateway,
+		),
+
+		// Invoke setup functions
+		fx.Invoke(
+			grpcAdapter.RegisterServices,
+			gateway.Register,
+			httpAdapter.RegisterRoutes,
+		),
+	)
+
+	app.Run()
+}
@@ -0,0 +1,127 @@
+// Package gateway serves CatalogService as a REST API. grpc-gateway transcodes
+// each request into a gRPC call to this process's own gRPC server, so REST
+// calls go through exactly the interceptors native gRPC calls do.
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/gostratum/core/configx"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	catalogv1 "github.com/gostratum/examples/gateway-demo/api/catalog/v1"
+	grpcAdapter "github.com/gostratum/examples/gateway-demo/internal/adapter/grpc"
+)
+
+// createdMethods answer 201 Created instead of 200 OK
+var createdMethods = map[string]bool{
+	catalogv1.CatalogService_CreateProduct_FullMethodName: true,
+}
+
+// Gateway is the http.Handler of the REST API
+type Gateway struct {
+	mux  *runtime.ServeMux
+	conn *grpc.ClientConn
+}
+
+// NewGateway creates the gateway, connected to the gRPC server at the address
+// in the grpc config section
+func NewGateway(loader configx.Loader) (*Gateway, error) {
+	var cfg grpcAdapter.ServerConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load grpc config: %w", err)
+	}
+	target, err := dialTarget(cfg.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// The connection is made on the first call, so the gRPC server does not
+	// have to be listening yet
+	conn, err := grpc.NewClient(target,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(grpcAdapter.ClientStatsHandler()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gateway connection to %s: %w", target, err)
+	}
+	return newGateway(conn)
+}
+
+func newGateway(conn *grpc.ClientConn) (*Gateway, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithMetadata(markGateway),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeader),
+		runtime.WithForwardResponseOption(createdStatus),
+		// snake_case JSON like the other examples' REST APIs, with zero values
+		// written out so clients see every field
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions:   protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true},
+			UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+		}),
+	)
+	if err := catalogv1.RegisterCatalogServiceHandler(context.Background(), mux, conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to register gateway handlers: %w", err)
+	}
+	return &Gateway{mux: mux, conn: conn}, nil
+}
+
+// Register closes the gateway's connection when the application stops.
+// This function is designed to be used with fx.Invoke.
+func Register(lc fx.Lifecycle, g *Gateway) {
+	lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			return g.conn.Close()
+		},
+	})
+}
+
+// ServeHTTP implements http.Handler
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mux.ServeHTTP(w, r)
+}
+
+// markGateway tags transcoded calls so the server can tell them from native ones
+func markGateway(context.Context, *http.Request) metadata.MD {
+	return metadata.Pairs(grpcAdapter.GatewayMetadataKey, "1")
+}
+
+// outgoingHeader returns the trace ID header under its own name and every
+// other response header the server sets with grpc-gateway's Grpc-Metadata- prefix
+func outgoingHeader(key string) (string, bool) {
+	if key == grpcAdapter.TraceIDHeader {
+		return http.CanonicalHeaderKey(key), true
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
+
+// createdStatus answers 201 Created for methods that create resources
+func createdStatus(ctx context.Context, w http.ResponseWriter, _ proto.Message) error {
+	if method, ok := runtime.RPCMethod(ctx); ok && createdMethods[method] {
+		w.WriteHeader(http.StatusCreated)
+	}
+	return nil
+}
+
+// dialTarget turns a listen address into an address to dial: ":50052" listens
+// on every interface, and the gateway reaches it on localhost
+func dialTarget(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid grpc.addr %q: %w", addr, err)
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return net.JoinHostPort(host, port), nil
+}
@@ -0,0 +1,139 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	catalogv1 "github.com/gostratum/examples/gateway-demo/api/catalog/v1"
+	grpcAdapter "github.com/gostratum/examples/gateway-demo/internal/adapter/grpc"
+)
+
+// fakeCatalog records the metadata of the last call and serves one product
+type fakeCatalog struct {
+	catalogv1.UnimplementedCatalogServiceServer
+	md      metadata.MD
+	created *catalogv1.Product
+}
+
+func (f *fakeCatalog) CreateProduct(ctx context.Context, req *catalogv1.CreateProductRequest) (*catalogv1.Product, error) {
+	f.md, _ = metadata.FromIncomingContext(ctx)
+	f.created = req.GetProduct()
+	_ = grpc.SetHeader(ctx, metadata.Pairs(grpcAdapter.TraceIDHeader, "4bf92f3577b34da6a3ce929d0e0e4736"))
+	return &catalogv1.Product{Id: "p-1", Name: req.GetProduct().GetName()}, nil
+}
+
+func (f *fakeCatalog) GetProduct(ctx context.Context, req *catalogv1.GetProductRequest) (*catalogv1.Product, error) {
+	f.md, _ = metadata.FromIncomingContext(ctx)
+	if req.GetId() != "p-1" {
+		return nil, status.Error(codes.NotFound, "resource not found")
+	}
+	return &catalogv1.Product{Id: "p-1", Name: "Widget"}, nil
+}
+
+func (f *fakeCatalog) DeleteProduct(ctx context.Context, req *catalogv1.DeleteProductRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unauthenticated, "missing API key")
+}
+
+// newTestGateway returns a gateway in front of a fake CatalogService
+func newTestGateway(t *testing.T) (*Gateway, *fakeCatalog) {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	fake := &fakeCatalog{}
+	srv := grpc.NewServer()
+	catalogv1.RegisterCatalogServiceServer(srv, fake)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	gw, err := newGateway(conn)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return gw, fake
+}
+
+func TestCreateProduct(t *testing.T) {
+	gw, fake := newTestGateway(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/products",
+		strings.NewReader(`{"name":"Widget","price":1999,"currency":"USD","unknown":true}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	gw.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, "Widget", fake.created.GetName())
+	assert.Equal(t, int64(1999), fake.created.GetPrice())
+	assert.Equal(t, []string{"Bearer secret"}, fake.md.Get("authorization"))
+	assert.Equal(t, []string{"1"}, fake.md.Get(grpcAdapter.GatewayMetadataKey))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", w.Header().Get("X-Trace-Id"))
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "p-1", body["id"])
+	// Zero values are written out, under their proto names
+	assert.Contains(t, body, "create_time")
+	assert.Equal(t, "0", body["price"])
+}
+
+func TestErrorStatuses(t *testing.T) {
+	gw, _ := newTestGateway(t)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{name: "found", method: http.MethodGet, path: "/v1/products/p-1", wantStatus: http.StatusOK},
+		{name: "not found", method: http.MethodGet, path: "/v1/products/nope", wantStatus: http.StatusNotFound},
+		{name: "unauthenticated", method: http.MethodDelete, path: "/v1/products/p-1", wantStatus: http.StatusUnauthorized},
+		{name: "not implemented", method: http.MethodGet, path: "/v1/products", wantStatus: http.StatusNotImplemented},
+		{name: "no route", method: http.MethodGet, path: "/v1/orders", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			gw.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestDialTarget(t *testing.T) {
+	tests := map[string]string{
+		":50052":          "localhost:50052",
+		"0.0.0.0:50052":   "localhost:50052",
+		"[::]:50052":      "localhost:50052",
+		"10.0.0.1:50052":  "10.0.0.1:50052",
+		"grpc.local:9000": "grpc.local:9000",
+	}
+	for addr, want := range tests {
+		got, err := dialTarget(addr)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, addr)
+	}
+
+	_, err := dialTarget("50052")
+	assert.Error(t, err)
+}
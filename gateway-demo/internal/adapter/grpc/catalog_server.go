@@ -0,0 +1,78 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	catalogv1 "github.com/gostratum/examples/gateway-demo/api/catalog/v1"
+	"github.com/gostratum/examples/gateway-demo/internal/domain"
+	"github.com/gostratum/examples/gateway-demo/internal/usecase"
+)
+
+// CatalogServer implements catalogv1.CatalogServiceServer on top of the catalog use case
+type CatalogServer struct {
+	catalogv1.UnimplementedCatalogServiceServer
+	svc *usecase.CatalogService
+}
+
+// NewCatalogServer creates the CatalogService handler
+func NewCatalogServer(svc *usecase.CatalogService) *CatalogServer {
+	return &CatalogServer{svc: svc}
+}
+
+// CreateProduct implements catalogv1.CatalogServiceServer
+func (s *CatalogServer) CreateProduct(ctx context.Context, req *catalogv1.CreateProductRequest) (*catalogv1.Product, error) {
+	in := req.GetProduct()
+	p, err := s.svc.Create(ctx, in.GetName(), in.GetPrice(), in.GetCurrency())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(p), nil
+}
+
+// GetProduct implements catalogv1.CatalogServiceServer
+func (s *CatalogServer) GetProduct(ctx context.Context, req *catalogv1.GetProductRequest) (*catalogv1.Product, error) {
+	p, err := s.svc.Get(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(p), nil
+}
+
+// ListProducts implements catalogv1.CatalogServiceServer
+func (s *CatalogServer) ListProducts(ctx context.Context, req *catalogv1.ListProductsRequest) (*catalogv1.ListProductsResponse, error) {
+	page, err := s.svc.List(ctx, int(req.GetPageSize()), req.GetPageToken())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &catalogv1.ListProductsResponse{
+		Products:      make([]*catalogv1.Product, len(page.Products)),
+		NextPageToken: page.NextToken,
+	}
+	for i, p := range page.Products {
+		resp.Products[i] = toProto(p)
+	}
+	return resp, nil
+}
+
+// DeleteProduct implements catalogv1.CatalogServiceServer
+func (s *CatalogServer) DeleteProduct(ctx context.Context, req *catalogv1.DeleteProductRequest) (*emptypb.Empty, error) {
+	if err := s.svc.Delete(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// toProto converts a domain.Product to its wire form
+func toProto(p *domain.Product) *catalogv1.Product {
+	return &catalogv1.Product{
+		Id:         p.ID,
+		Name:       p.Name,
+		Price:      p.Price,
+		Currency:   p.Currency,
+		CreateTime: timestamppb.New(p.CreatedAt),
+	}
+}
@@ -0,0 +1,30 @@
+// Package grpc serves CatalogService over gRPC. Authentication, metrics,
+// logging and tracing are gRPC interceptors and stats handlers, so they apply
+// the same way to native gRPC calls and to REST calls grpc-gateway transcodes.
+package grpc
+
+import "time"
+
+// ServerConfig holds the gRPC listener settings
+type ServerConfig struct {
+	Addr string `mapstructure:"addr" default:":50052"`
+	// Reflection lets grpcurl and similar tools discover the services
+	Reflection      bool          `mapstructure:"reflection" default:"true"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" default:"10s"`
+}
+
+// Prefix implements configx.Configurable
+func (ServerConfig) Prefix() string {
+	return "grpc"
+}
+
+// AuthConfig holds the API keys that may call methods that change the catalog
+type AuthConfig struct {
+	// Keys maps client names to their API keys
+	Keys map[string]string `mapstructure:"keys"`
+}
+
+// Prefix implements configx.Configurable
+func (AuthConfig) Prefix() string {
+	return "auth"
+}
@@ -0,0 +1,31 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gostratum/examples/gateway-demo/internal/usecase"
+)
+
+// toStatus maps usecase errors to gRPC status errors. grpc-gateway maps the
+// codes on to HTTP statuses: InvalidArgument to 400, NotFound to 404,
+// Unavailable to 503.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrInvalid):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "deadline exceeded")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "call canceled")
+	case errors.Is(err, usecase.ErrUnavailable):
+		return status.Error(codes.Unavailable, "service temporarily unavailable")
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
@@ -0,0 +1,179 @@
+package grpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	catalogv1 "github.com/gostratum/examples/gateway-demo/api/catalog/v1"
+)
+
+// TraceIDHeader is the response header carrying the trace ID of the call, so
+// clients can look the trace up without exporting spans themselves
+const TraceIDHeader = "x-trace-id"
+
+// GatewayMetadataKey marks calls made by the REST gateway, so logs and metrics
+// can tell the transports apart. It is not a security boundary: any gRPC
+// client could send it.
+const GatewayMetadataKey = "x-gateway"
+
+// Transports as they appear in logs and the transport metric label
+const (
+	transportGRPC = "grpc"
+	transportREST = "rest"
+)
+
+// publicMethods can be called without an API key; everything that changes the
+// catalog needs one
+var publicMethods = map[string]bool{
+	catalogv1.CatalogService_GetProduct_FullMethodName:   true,
+	catalogv1.CatalogService_ListProducts_FullMethodName: true,
+}
+
+// isInfrastructure reports whether method belongs to grpc.health.v1 or to
+// reflection, which are neither authenticated nor recorded
+func isInfrastructure(method string) bool {
+	return strings.HasPrefix(method, "/grpc.health.v1.") || strings.HasPrefix(method, "/grpc.reflection.")
+}
+
+// transport returns how a call reached the server
+func transport(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if len(md.Get(GatewayMetadataKey)) > 0 {
+		return transportREST
+	}
+	return transportGRPC
+}
+
+// clientKey is the context key of the authenticated client name
+type clientKey struct{}
+
+// ClientFromContext returns the name of the client whose API key authenticated
+// the call, or "" for anonymous calls to public methods
+func ClientFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(clientKey{}).(string)
+	return name
+}
+
+// authInterceptor requires "authorization: Bearer <key>" on every method but
+// the public ones. REST clients send it as the Authorization header, which
+// grpc-gateway passes on as the same metadata. A key sent to a public method
+// is still checked, so a bad key never goes unnoticed.
+func authInterceptor(cfg AuthConfig) grpc.UnaryServerInterceptor {
+	hashes := make(map[string][sha256.Size]byte, len(cfg.Keys))
+	for name, key := range cfg.Keys {
+		hashes[name] = sha256.Sum256([]byte(key))
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if isInfrastructure(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 {
+			if publicMethods[info.FullMethod] {
+				return handler(ctx, req)
+			}
+			return nil, status.Error(codes.Unauthenticated, "missing API key")
+		}
+
+		key, ok := strings.CutPrefix(values[0], "Bearer ")
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "authorization must be a Bearer API key")
+		}
+		name := match(hashes, key)
+		if name == "" {
+			return nil, status.Error(codes.Unauthenticated, "invalid API key")
+		}
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("enduser.id", name))
+		return handler(context.WithValue(ctx, clientKey{}, name), req)
+	}
+}
+
+// match returns the name of the client key belongs to. It compares against
+// every key, so the time taken does not tell which one matched.
+func match(hashes map[string][sha256.Size]byte, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	found := ""
+	for name, h := range hashes {
+		if subtle.ConstantTimeCompare(sum[:], h[:]) == 1 {
+			found = name
+		}
+	}
+	return found
+}
+
+// traceHeaderInterceptor returns the call's trace ID in the response headers
+func traceHeaderInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() && !isInfrastructure(info.FullMethod) {
+			_ = grpc.SetHeader(ctx, metadata.Pairs(TraceIDHeader, sc.TraceID().String()))
+		}
+		return handler(ctx, req)
+	}
+}
+
+// metricsInterceptor records every call except infrastructure ones, including
+// calls the auth interceptor turns away
+func metricsInterceptor(rec *recorder) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if isInfrastructure(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		rec.call(info.FullMethod, transport(ctx), status.Code(err), time.Since(start))
+		return resp, err
+	}
+}
+
+// loggingInterceptor logs every call except infrastructure ones with its
+// status code, transport, client and trace ID. It runs after the auth
+// interceptor, so calls turned away for their key show in the metrics only.
+func loggingInterceptor(log logx.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if isInfrastructure(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		code := status.Code(err)
+
+		fields := []logx.Field{
+			logx.String("method", info.FullMethod),
+			logx.String("code", code.String()),
+			logx.String("transport", transport(ctx)),
+			logx.String("duration", time.Since(start).String()),
+		}
+		if client := ClientFromContext(ctx); client != "" {
+			fields = append(fields, logx.String("client", client))
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+			fields = append(fields, logx.String("trace_id", sc.TraceID().String()))
+		}
+
+		switch code {
+		case codes.OK:
+			log.Info("gRPC call", fields...)
+		case codes.Internal, codes.Unknown, codes.Unavailable, codes.DataLoss:
+			log.Error("gRPC call failed", append(fields, logx.Err(err))...)
+		default:
+			log.Warn("gRPC call failed", append(fields, logx.Err(err))...)
+		}
+		return resp, err
+	}
+}
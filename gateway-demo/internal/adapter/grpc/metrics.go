@@ -0,0 +1,42 @@
+package grpc
+
+import (
+	"time"
+
+	"github.com/gostratum/metricsx"
+	"google.golang.org/grpc/codes"
+)
+
+// recorder records calls by method, transport and status code
+type recorder struct {
+	calls    counter
+	duration histogram
+}
+
+func newRecorder(metrics metricsx.Metrics) *recorder {
+	return &recorder{
+		calls: metrics.Counter("grpc_server_handled_total",
+			metricsx.WithHelp("Calls handled, by method, transport (grpc or rest) and status code"),
+			metricsx.WithLabels("method", "transport", "code"),
+		),
+		duration: metrics.Histogram("grpc_server_handling_seconds",
+			metricsx.WithHelp("Time to handle a call, by method and transport"),
+			metricsx.WithLabels("method", "transport"),
+		),
+	}
+}
+
+func (r *recorder) call(method, transport string, code codes.Code, d time.Duration) {
+	r.calls.Inc(method, transport, code.String())
+	r.duration.Observe(d.Seconds(), method, transport)
+}
+
+// counter is the part of metricsx.Counter the server uses
+type counter interface {
+	Inc(labels ...string)
+}
+
+// histogram is the part of metricsx.Histogram the server uses
+type histogram interface {
+	Observe(v float64, labels ...string)
+}
@@ -0,0 +1,143 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/metricsx"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	catalogv1 "github.com/gostratum/examples/gateway-demo/api/catalog/v1"
+)
+
+// Params holds the dependencies of the Server
+type Params struct {
+	fx.In
+
+	Loader  configx.Loader
+	Metrics metricsx.Metrics
+	Log     logx.Logger
+}
+
+// Server is the gRPC server together with its health service
+type Server struct {
+	cfg    ServerConfig
+	grpc   *grpc.Server
+	health *health.Server
+}
+
+// NewServer creates the gRPC server from the grpc and auth config sections.
+// Every call, native or transcoded, passes through the same chain: a tracing
+// stats handler, then metrics, trace header, authentication and logging
+// interceptors.
+func NewServer(p Params) (*Server, error) {
+	var cfg ServerConfig
+	if err := p.Loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load grpc config: %w", err)
+	}
+	var auth AuthConfig
+	if err := p.Loader.Bind(&auth); err != nil {
+		return nil, fmt.Errorf("failed to load auth config: %w", err)
+	}
+	for name, key := range auth.Keys {
+		if key == "" {
+			return nil, fmt.Errorf("auth.keys.%s is empty", name)
+		}
+	}
+	if len(auth.Keys) == 0 {
+		p.Log.Warn("no API keys configured; only public methods can be called")
+	}
+	return newServer(cfg, auth, newRecorder(p.Metrics), p.Log), nil
+}
+
+func newServer(cfg ServerConfig, auth AuthConfig, rec *recorder, log logx.Logger) *Server {
+	srv := grpc.NewServer(
+		grpc.StatsHandler(serverStatsHandler()),
+		grpc.ChainUnaryInterceptor(
+			metricsInterceptor(rec),
+			traceHeaderInterceptor(),
+			authInterceptor(auth),
+			loggingInterceptor(log),
+		),
+	)
+	return &Server{cfg: cfg, grpc: srv, health: health.NewServer()}
+}
+
+// RegisterServices registers the API, health and reflection services and ties
+// the server to the application lifecycle.
+// This function is designed to be used with fx.Invoke.
+func RegisterServices(lc fx.Lifecycle, srv *Server, catalog *CatalogServer, log logx.Logger) {
+	srv.register(catalog)
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			lis, err := net.Listen("tcp", srv.cfg.Addr)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s: %w", srv.cfg.Addr, err)
+			}
+
+			go func() {
+				if err := srv.grpc.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+					log.Error("gRPC server stopped", logx.Err(err))
+				}
+			}()
+			srv.setServing(healthpb.HealthCheckResponse_SERVING)
+
+			log.Info("gRPC server listening",
+				logx.String("addr", lis.Addr().String()),
+				logx.String("reflection", fmt.Sprint(srv.cfg.Reflection)))
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			// Tell load balancers to go elsewhere before draining calls in flight
+			srv.health.Shutdown()
+			return srv.stop(ctx)
+		},
+	})
+}
+
+// register adds the API, health and reflection services. Health reports
+// NOT_SERVING until the server listens.
+func (s *Server) register(catalog *CatalogServer) {
+	catalogv1.RegisterCatalogServiceServer(s.grpc, catalog)
+	healthpb.RegisterHealthServer(s.grpc, s.health)
+	if s.cfg.Reflection {
+		reflection.Register(s.grpc)
+	}
+	s.setServing(healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+// setServing publishes status for the server as a whole and for CatalogService
+func (s *Server) setServing(status healthpb.HealthCheckResponse_ServingStatus) {
+	for _, name := range []string{"", catalogv1.CatalogService_ServiceDesc.ServiceName} {
+		s.health.SetServingStatus(name, status)
+	}
+}
+
+// stop drains calls in flight, forcing the server down if ctx ends first
+func (s *Server) stop(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.ShutdownTimeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.grpc.Stop()
+		return fmt.Errorf("gRPC server did not drain in time: %w", ctx.Err())
+	}
+}
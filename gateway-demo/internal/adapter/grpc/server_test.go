@@ -0,0 +1,214 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	catalogv1 "github.com/gostratum/examples/gateway-demo/api/catalog/v1"
+	"github.com/gostratum/examples/gateway-demo/internal/adapter/memory"
+	"github.com/gostratum/examples/gateway-demo/internal/usecase"
+)
+
+// fakeCounter counts increments per label values
+type fakeCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (f *fakeCounter) Inc(labels ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.counts[strings.Join(labels, ",")]++
+}
+
+func (f *fakeCounter) get(labels ...string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.counts[strings.Join(labels, ",")]
+}
+
+type fakeHistogram struct{}
+
+func (fakeHistogram) Observe(float64, ...string) {}
+
+// startTestServer runs CatalogService on an in-memory listener with the
+// "admin" API key configured
+func startTestServer(t *testing.T) (*grpc.ClientConn, *fakeCounter) {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	calls := &fakeCounter{counts: map[string]int{}}
+	srv := newServer(ServerConfig{}, AuthConfig{Keys: map[string]string{"admin": "secret"}},
+		&recorder{calls: calls, duration: fakeHistogram{}}, logx.NewNoopLogger())
+	srv.register(NewCatalogServer(usecase.NewCatalogService(memory.NewProducts())))
+
+	go srv.grpc.Serve(lis)
+	t.Cleanup(srv.grpc.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(ClientStatsHandler()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn, calls
+}
+
+// withKey adds an authorization header to ctx
+func withKey(ctx context.Context, value string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", value)
+}
+
+func TestAuth(t *testing.T) {
+	conn, _ := startTestServer(t)
+	client := catalogv1.NewCatalogServiceClient(conn)
+	create := &catalogv1.CreateProductRequest{Product: &catalogv1.Product{Name: "Widget", Price: 1999, Currency: "USD"}}
+
+	tests := []struct {
+		name          string
+		authorization string
+		call          func(ctx context.Context) error
+		wantCode      codes.Code
+	}{
+		{name: "create without key", call: func(ctx context.Context) error {
+			_, err := client.CreateProduct(ctx, create)
+			return err
+		}, wantCode: codes.Unauthenticated},
+		{name: "create with wrong key", authorization: "Bearer nope", call: func(ctx context.Context) error {
+			_, err := client.CreateProduct(ctx, create)
+			return err
+		}, wantCode: codes.Unauthenticated},
+		{name: "create with key but no scheme", authorization: "secret", call: func(ctx context.Context) error {
+			_, err := client.CreateProduct(ctx, create)
+			return err
+		}, wantCode: codes.Unauthenticated},
+		{name: "create with key", authorization: "Bearer secret", call: func(ctx context.Context) error {
+			_, err := client.CreateProduct(ctx, create)
+			return err
+		}, wantCode: codes.OK},
+		{name: "delete without key", call: func(ctx context.Context) error {
+			_, err := client.DeleteProduct(ctx, &catalogv1.DeleteProductRequest{Id: "p-1"})
+			return err
+		}, wantCode: codes.Unauthenticated},
+		{name: "public get without key", call: func(ctx context.Context) error {
+			_, err := client.GetProduct(ctx, &catalogv1.GetProductRequest{Id: "p-1"})
+			return err
+		}, wantCode: codes.NotFound},
+		{name: "public list with wrong key", authorization: "Bearer nope", call: func(ctx context.Context) error {
+			_, err := client.ListProducts(ctx, &catalogv1.ListProductsRequest{})
+			return err
+		}, wantCode: codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.authorization != "" {
+				ctx = withKey(ctx, tt.authorization)
+			}
+			err := tt.call(ctx)
+			assert.Equal(t, tt.wantCode, status.Code(err), "error: %v", err)
+		})
+	}
+}
+
+func TestCatalogLifecycle(t *testing.T) {
+	conn, _ := startTestServer(t)
+	client := catalogv1.NewCatalogServiceClient(conn)
+	ctx := withKey(context.Background(), "Bearer secret")
+
+	created, err := client.CreateProduct(ctx, &catalogv1.CreateProductRequest{
+		Product: &catalogv1.Product{Name: " Widget ", Price: 1999, Currency: "usd"},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.GetId())
+	assert.Equal(t, "Widget", created.GetName())
+	assert.Equal(t, "USD", created.GetCurrency())
+	assert.NotNil(t, created.GetCreateTime())
+
+	got, err := client.GetProduct(context.Background(), &catalogv1.GetProductRequest{Id: created.GetId()})
+	require.NoError(t, err)
+	assert.Equal(t, created.GetName(), got.GetName())
+
+	list, err := client.ListProducts(context.Background(), &catalogv1.ListProductsRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.GetProducts(), 1)
+	assert.Empty(t, list.GetNextPageToken())
+
+	_, err = client.DeleteProduct(ctx, &catalogv1.DeleteProductRequest{Id: created.GetId()})
+	require.NoError(t, err)
+	_, err = client.GetProduct(context.Background(), &catalogv1.GetProductRequest{Id: created.GetId()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.CreateProduct(ctx, &catalogv1.CreateProductRequest{Product: &catalogv1.Product{Name: "Widget", Currency: "dollars"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestMetricsLabelTransport(t *testing.T) {
+	conn, calls := startTestServer(t)
+	client := catalogv1.NewCatalogServiceClient(conn)
+	method := catalogv1.CatalogService_ListProducts_FullMethodName
+
+	_, err := client.ListProducts(context.Background(), &catalogv1.ListProductsRequest{})
+	require.NoError(t, err)
+	gateway := metadata.AppendToOutgoingContext(context.Background(), GatewayMetadataKey, "1")
+	_, err = client.ListProducts(gateway, &catalogv1.ListProductsRequest{})
+	require.NoError(t, err)
+	_, err = client.CreateProduct(gateway, &catalogv1.CreateProductRequest{})
+	require.Error(t, err)
+
+	assert.Equal(t, 1, calls.get(method, transportGRPC, "OK"))
+	assert.Equal(t, 1, calls.get(method, transportREST, "OK"))
+	// Calls turned away by auth are recorded too
+	assert.Equal(t, 1, calls.get(catalogv1.CatalogService_CreateProduct_FullMethodName, transportREST, "Unauthenticated"))
+}
+
+func TestTraceIDHeader(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	conn, _ := startTestServer(t)
+	client := catalogv1.NewCatalogServiceClient(conn)
+
+	var header metadata.MD
+	_, err := client.ListProducts(context.Background(), &catalogv1.ListProductsRequest{}, grpc.Header(&header))
+	require.NoError(t, err)
+	require.NoError(t, provider.ForceFlush(context.Background()))
+
+	spans := recorder.Ended()
+	// Client and server span of the same call
+	require.Len(t, spans, 2)
+	assert.Equal(t, spans[0].SpanContext().TraceID(), spans[1].SpanContext().TraceID())
+	require.Len(t, header.Get(TraceIDHeader), 1)
+	assert.Equal(t, spans[0].SpanContext().TraceID().String(), header.Get(TraceIDHeader)[0])
+}
+
+func TestHealthNeedsNoKey(t *testing.T) {
+	conn, calls := startTestServer(t)
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.GetStatus())
+	assert.Empty(t, calls.counts, "health checks are not recorded")
+}
@@ -0,0 +1,32 @@
+package grpc
+
+import (
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc/stats"
+)
+
+// propagator carries the W3C trace context and baggage in gRPC metadata. It is set
+// explicitly so propagation does not depend on what was installed globally.
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// serverStatsHandler starts a server span per call, continuing the caller's trace.
+// Spans go to the global tracer provider, which tracingx.Module installs.
+func serverStatsHandler() stats.Handler {
+	return otelgrpc.NewServerHandler(
+		otelgrpc.WithPropagators(propagator),
+		otelgrpc.WithFilter(notInfrastructure),
+	)
+}
+
+// ClientStatsHandler starts a client span per call and injects its context into
+// the outgoing metadata. The gateway calls the server with it, so a REST request
+// is one trace: the HTTP span, the gateway's client span and the server span.
+func ClientStatsHandler() stats.Handler {
+	return otelgrpc.NewClientHandler(otelgrpc.WithPropagators(propagator))
+}
+
+// notInfrastructure keeps health probes and reflection out of the traces
+func notInfrastructure(info *stats.RPCTagInfo) bool {
+	return !isInfrastructure(info.FullMethodName)
+}
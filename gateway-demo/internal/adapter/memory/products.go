@@ -0,0 +1,77 @@
+// Package memory keeps products in memory. The example is about the gRPC and
+// REST plumbing, so there is no database behind it.
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/gostratum/examples/gateway-demo/internal/domain"
+	"github.com/gostratum/examples/gateway-demo/internal/usecase"
+)
+
+// Products implements usecase.ProductRepository
+type Products struct {
+	mu       sync.RWMutex
+	products map[string]domain.Product
+	// ids is kept sorted for ListAfter
+	ids []string
+}
+
+// NewProducts creates an empty product store
+func NewProducts() usecase.ProductRepository {
+	return &Products{products: make(map[string]domain.Product)}
+}
+
+// Save implements usecase.ProductRepository
+func (r *Products) Save(ctx context.Context, p *domain.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.products[p.ID]; !ok {
+		i, _ := slices.BinarySearch(r.ids, p.ID)
+		r.ids = slices.Insert(r.ids, i, p.ID)
+	}
+	r.products[p.ID] = *p
+	return nil
+}
+
+// FindByID implements usecase.ProductRepository
+func (r *Products) FindByID(ctx context.Context, id string) (*domain.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.products[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &p, nil
+}
+
+// ListAfter implements usecase.ProductRepository
+func (r *Products) ListAfter(ctx context.Context, afterID string, limit int) ([]*domain.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	i, found := slices.BinarySearch(r.ids, afterID)
+	if found {
+		i++
+	}
+	products := make([]*domain.Product, 0, min(limit, len(r.ids)-i))
+	for _, id := range r.ids[i:min(i+limit, len(r.ids))] {
+		p := r.products[id]
+		products = append(products, &p)
+	}
+	return products, nil
+}
+
+// Delete implements usecase.ProductRepository
+func (r *Products) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.products[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.products, id)
+	i, _ := slices.BinarySearch(r.ids, id)
+	r.ids = slices.Delete(r.ids, i, i+1)
+	return nil
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProduct(t *testing.T) {
+	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	p, err := NewProduct("p-1", "  Widget ", 1999, "usd", now)
+	require.NoError(t, err)
+	assert.Equal(t, &Product{ID: "p-1", Name: "Widget", Price: 1999, Currency: "USD", CreatedAt: now}, p)
+
+	tests := map[string]struct {
+		name     string
+		price    int64
+		currency string
+	}{
+		"no name":          {name: " ", price: 1, currency: "USD"},
+		"name too long":    {name: string(make([]rune, 201)), price: 1, currency: "USD"},
+		"negative price":   {name: "Widget", price: -1, currency: "USD"},
+		"no currency":      {name: "Widget", price: 1},
+		"invalid currency": {name: "Widget", price: 1, currency: "US1"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewProduct("p-1", tt.name, tt.price, tt.currency, now)
+			assert.ErrorIs(t, err, ErrInvalidInput)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxNameLength bounds product names
+const maxNameLength = 200
+
+// Product is an item for sale
+type Product struct {
+	ID   string
+	Name string
+	// Price is in minor units of Currency
+	Price     int64
+	Currency  string
+	CreatedAt time.Time
+}
+
+// NewProduct creates a validated product. Names are trimmed and currencies
+// upper-cased.
+func NewProduct(id, name string, price int64, currency string, now time.Time) (*Product, error) {
+	p := &Product{
+		ID:        id,
+		Name:      strings.TrimSpace(name),
+		Price:     price,
+		Currency:  strings.ToUpper(strings.TrimSpace(currency)),
+		CreatedAt: now,
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Validate checks the product against the business rules
+func (p *Product) Validate() error {
+	switch {
+	case p.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	case utf8.RuneCountInString(p.Name) > maxNameLength:
+		return fmt.Errorf("%w: name is longer than %d characters", ErrInvalidInput, maxNameLength)
+	case p.Price < 0:
+		return fmt.Errorf("%w: price must not be negative", ErrInvalidInput)
+	case !isCurrencyCode(p.Currency):
+		return fmt.Errorf("%w: currency %q is not an ISO 4217 code", ErrInvalidInput, p.Currency)
+	}
+	return nil
+}
+
+func isCurrencyCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
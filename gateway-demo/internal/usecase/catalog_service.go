@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gostratum/examples/gateway-demo/internal/domain"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// CatalogService manages products. It knows nothing of gRPC or REST; both
+// reach it through the same gRPC handlers.
+type CatalogService struct {
+	repo  ProductRepository
+	now   func() time.Time
+	newID func() string
+}
+
+// NewCatalogService creates a new catalog service with repository injection
+func NewCatalogService(repo ProductRepository) *CatalogService {
+	return &CatalogService{
+		repo: repo,
+		now:  time.Now,
+		// Version 7 UUIDs sort by creation time, so listing in ID order lists
+		// oldest first
+		newID: func() string { return uuid.Must(uuid.NewV7()).String() },
+	}
+}
+
+// Page is one page of a product listing
+type Page struct {
+	Products []*domain.Product
+	// NextToken fetches the next page; empty on the last page
+	NextToken string
+}
+
+// Create adds a product
+func (s *CatalogService) Create(ctx context.Context, name string, price int64, currency string) (*domain.Product, error) {
+	p, err := domain.NewProduct(s.newID(), name, price, currency, s.now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, p); err != nil {
+		return nil, translateError(err)
+	}
+	return p, nil
+}
+
+// Get returns one product
+func (s *CatalogService) Get(ctx context.Context, id string) (*domain.Product, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w: id is required", ErrInvalid)
+	}
+	p, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return p, nil
+}
+
+// List returns a page of products, oldest first. pageSize 0 means the default
+// size; token is the NextToken of the previous page.
+func (s *CatalogService) List(ctx context.Context, pageSize int, token string) (*Page, error) {
+	switch {
+	case pageSize < 0:
+		return nil, fmt.Errorf("%w: page size must not be negative", ErrInvalid)
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+	afterID, err := decodeToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	// One more than asked for tells whether there is a next page
+	products, err := s.repo.ListAfter(ctx, afterID, pageSize+1)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	page := &Page{Products: products}
+	if len(products) > pageSize {
+		page.Products = products[:pageSize]
+		page.NextToken = encodeToken(page.Products[pageSize-1].ID)
+	}
+	return page, nil
+}
+
+// Delete removes a product
+func (s *CatalogService) Delete(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("%w: id is required", ErrInvalid)
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// encodeToken makes a page token from the last ID of a page. Tokens are opaque
+// to clients, so the cursor can change without breaking them.
+func encodeToken(lastID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(lastID))
+}
+
+func decodeToken(token string) (string, error) {
+	if token == "" {
+		return "", nil
+	}
+	id, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(id) == 0 {
+		return "", fmt.Errorf("%w: invalid page token", ErrInvalid)
+	}
+	return string(id), nil
+}
+
+// translateError converts repository errors to usecase errors
+func translateError(err error) error {
+	for _, passthrough := range []error{domain.ErrNotFound, domain.ErrInvalidInput, context.DeadlineExceeded, context.Canceled} {
+		if errors.Is(err, passthrough) {
+			return err
+		}
+	}
+
+	// All other errors are infrastructure/availability issues
+	return fmt.Errorf("%w: %v", ErrUnavailable, err)
+}
@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/gateway-demo/internal/domain"
+)
+
+// MockProductRepository implements ProductRepository for testing
+type MockProductRepository struct {
+	products map[string]*domain.Product
+	err      error
+}
+
+func newMockRepo() *MockProductRepository {
+	return &MockProductRepository{products: make(map[string]*domain.Product)}
+}
+
+func (m *MockProductRepository) Save(ctx context.Context, p *domain.Product) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.products[p.ID] = p
+	return nil
+}
+
+func (m *MockProductRepository) FindByID(ctx context.Context, id string) (*domain.Product, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	p, ok := m.products[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return p, nil
+}
+
+func (m *MockProductRepository) ListAfter(ctx context.Context, afterID string, limit int) ([]*domain.Product, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var ids []string
+	for id := range m.products {
+		if id > afterID {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	products := make([]*domain.Product, 0, limit)
+	for _, id := range ids[:min(limit, len(ids))] {
+		products = append(products, m.products[id])
+	}
+	return products, nil
+}
+
+func (m *MockProductRepository) Delete(ctx context.Context, id string) error {
+	if m.err != nil {
+		return m.err
+	}
+	if _, ok := m.products[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(m.products, id)
+	return nil
+}
+
+// newTestCatalogService returns a service with a fixed clock and sequential IDs
+func newTestCatalogService(repo ProductRepository) *CatalogService {
+	svc := NewCatalogService(repo)
+	svc.now = func() time.Time { return time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC) }
+	next := 0
+	svc.newID = func() string {
+		next++
+		return fmt.Sprintf("p-%03d", next)
+	}
+	return svc
+}
+
+func TestCatalogService_Create(t *testing.T) {
+	repo := newMockRepo()
+	svc := newTestCatalogService(repo)
+
+	p, err := svc.Create(context.Background(), "Widget", 1999, "usd")
+	require.NoError(t, err)
+	assert.Equal(t, "p-001", p.ID)
+	assert.Equal(t, "USD", p.Currency)
+	assert.Equal(t, p, repo.products["p-001"])
+
+	_, err = svc.Create(context.Background(), "", 1999, "USD")
+	assert.ErrorIs(t, err, ErrInvalid)
+	assert.Len(t, repo.products, 1)
+}
+
+func TestCatalogService_Errors(t *testing.T) {
+	repo := newMockRepo()
+	svc := newTestCatalogService(repo)
+	ctx := context.Background()
+
+	_, err := svc.Get(ctx, "")
+	assert.ErrorIs(t, err, ErrInvalid)
+	_, err = svc.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, svc.Delete(ctx, "missing"), ErrNotFound)
+
+	repo.err = errors.New("connection refused")
+	_, err = svc.Get(ctx, "p-001")
+	assert.ErrorIs(t, err, ErrUnavailable)
+	_, err = svc.Create(ctx, "Widget", 1, "USD")
+	assert.ErrorIs(t, err, ErrUnavailable)
+
+	repo.err = context.DeadlineExceeded
+	_, err = svc.List(ctx, 0, "")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotErrorIs(t, err, ErrUnavailable)
+}
+
+func TestCatalogService_List(t *testing.T) {
+	svc := newTestCatalogService(newMockRepo())
+	ctx := context.Background()
+	for i := range 5 {
+		_, err := svc.Create(ctx, fmt.Sprintf("Widget %d", i), 100, "USD")
+		require.NoError(t, err)
+	}
+
+	var ids []string
+	token := ""
+	pages := 0
+	for {
+		page, err := svc.List(ctx, 2, token)
+		require.NoError(t, err)
+		pages++
+		for _, p := range page.Products {
+			ids = append(ids, p.ID)
+		}
+		if page.NextToken == "" {
+			break
+		}
+		token = page.NextToken
+	}
+	assert.Equal(t, []string{"p-001", "p-002", "p-003", "p-004", "p-005"}, ids)
+	assert.Equal(t, 3, pages)
+
+	// Exactly one full page leaves no next page to fetch
+	page, err := svc.List(ctx, 5, "")
+	require.NoError(t, err)
+	assert.Len(t, page.Products, 5)
+	assert.Empty(t, page.NextToken)
+
+	_, err = svc.List(ctx, -1, "")
+	assert.ErrorIs(t, err, ErrInvalid)
+	_, err = svc.List(ctx, 0, "not base64!")
+	assert.ErrorIs(t, err, ErrInvalid)
+}
@@ -0,0 +1,19 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/gostratum/examples/gateway-demo/internal/domain"
+)
+
+// ProductRepository defines the interface for product data operations
+// This interface is owned by the use case layer (dependency inversion principle)
+type ProductRepository interface {
+	Save(ctx context.Context, p *domain.Product) error
+	FindByID(ctx context.Context, id string) (*domain.Product, error)
+	// ListAfter returns up to limit products with IDs greater than afterID, in
+	// ID order; an empty afterID starts from the first product
+	ListAfter(ctx context.Context, afterID string, limit int) ([]*domain.Product, error)
+	// Delete returns domain.ErrNotFound when there is no product with id
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/examples/graphql-demo/internal/adapter/graphql"
+	httpAdapter "github.com/gostratum/examples/graphql-demo/internal/adapter/http"
+	"github.com/gostratum/examples/graphql-demo/internal/adapter/memory"
+	"github.com/gostratum/examples/graphql-demo/internal/usecase"
+	"github.com/gostratum/examples/redact"
+	"github.com/gostratum/httpx"
+	"github.com/gostratum/metricsx"
+	"github.com/gostratum/tracingx"
+)
+
+func main() {
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// Observability: resolver spans and dataloader metrics
+		metricsx.Module(),
+		tracingx.Module(),
+
+		// HTTP server the GraphQL endpoint is mounted on
+		httpx.Module(),
+
+		// Provide dependencies
+		fx.Provide(
+			// In-memory repositories
+			memory.NewUsers,
+			memory.NewOrders,
+
+			// Usecase services
+			usecase.NewUserService,
+			usecase.NewOrderService,
+
+			// GraphQL schema and resolvers
+			graphql.NewHandler,
+		),
+
+		// Invoke setup functions
+		fx.Invoke(
+			memory.Seed,
+			httpAdapter.RegisterRoutes,
+		),
+	)
+
+	app.Run()
+}
@@ -0,0 +1,30 @@
+// Package graphql serves the users and orders use cases as a GraphQL API with
+// github.com/graph-gophers/graphql-go. Resolvers call the use cases and load
+// related objects through per-request dataloaders; every resolver that does
+// more than read a field gets a span.
+package graphql
+
+import "time"
+
+// Config holds the GraphQL execution settings
+type Config struct {
+	// MaxDepth rejects queries nested deeper than this before they run
+	MaxDepth int `mapstructure:"max_depth" default:"8"`
+	// MaxParallelism bounds the resolvers running at once for a request. It
+	// also bounds how many items of a list are resolved together, so it
+	// should be at least MaxBatch for batches to fill up.
+	MaxParallelism int `mapstructure:"max_parallelism" default:"100"`
+	// Introspection lets GraphiQL and code generators read the schema
+	Introspection bool `mapstructure:"introspection" default:"true"`
+	// BatchWait is how long a dataloader waits for more keys before a fetch
+	BatchWait time.Duration `mapstructure:"batch_wait" default:"2ms"`
+	// MaxBatch fetches a batch as soon as it holds this many keys
+	MaxBatch int `mapstructure:"max_batch" default:"100"`
+	// MaxBodyBytes limits the size of a request
+	MaxBodyBytes int64 `mapstructure:"max_body_bytes" default:"1048576"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "graphql"
+}
@@ -0,0 +1,52 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gostratum/examples/graphql-demo/internal/usecase"
+)
+
+// Error codes clients find in the "code" extension of an error
+const (
+	codeInvalid     = "BAD_USER_INPUT"
+	codeNotFound    = "NOT_FOUND"
+	codeConflict    = "CONFLICT"
+	codeUnavailable = "UNAVAILABLE"
+	codeTimeout     = "TIMEOUT"
+	codeInternal    = "INTERNAL"
+)
+
+// resolverError is an error graphql-go returns with extensions
+type resolverError struct {
+	code    string
+	message string
+}
+
+func (e *resolverError) Error() string {
+	return e.message
+}
+
+// Extensions implements the interface graphql-go reads error extensions from
+func (e *resolverError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
+
+// toGraphQLError maps usecase errors to errors with a code. Messages of
+// invalid input are passed on; infrastructure errors are not.
+func toGraphQLError(err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrInvalid):
+		return &resolverError{code: codeInvalid, message: err.Error()}
+	case errors.Is(err, usecase.ErrNotFound):
+		return &resolverError{code: codeNotFound, message: err.Error()}
+	case errors.Is(err, usecase.ErrConflict):
+		return &resolverError{code: codeConflict, message: err.Error()}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &resolverError{code: codeTimeout, message: "deadline exceeded"}
+	case errors.Is(err, usecase.ErrUnavailable):
+		return &resolverError{code: codeUnavailable, message: "service temporarily unavailable"}
+	default:
+		return &resolverError{code: codeInternal, message: "internal server error"}
+	}
+}
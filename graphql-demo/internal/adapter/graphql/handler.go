@@ -0,0 +1,118 @@
+package graphql
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/metricsx"
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+
+	"github.com/gostratum/examples/graphql-demo/internal/usecase"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+// Params holds the dependencies of the Handler
+type Params struct {
+	fx.In
+
+	Loader  configx.Loader
+	Metrics metricsx.Metrics
+	Log     logx.Logger
+	Users   *usecase.UserService
+	Orders  *usecase.OrderService
+}
+
+// Handler executes GraphQL requests
+type Handler struct {
+	cfg    Config
+	schema *graphqlgo.Schema
+	users  *usecase.UserService
+	orders *usecase.OrderService
+	rec    *recorder
+	tracer trace.Tracer
+	log    logx.Logger
+}
+
+// NewHandler parses the schema and checks the resolvers against it, so a
+// resolver missing for a field fails startup rather than a request
+func NewHandler(p Params) (*Handler, error) {
+	var cfg Config
+	if err := p.Loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load graphql config: %w", err)
+	}
+	return newHandler(cfg, p.Users, p.Orders, newRecorder(p.Metrics), p.Log)
+}
+
+func newHandler(cfg Config, users *usecase.UserService, orders *usecase.OrderService, rec *recorder, log logx.Logger) (*Handler, error) {
+	// Spans go to the global tracer provider, which tracingx.Module installs
+	t := otel.Tracer("github.com/gostratum/examples/graphql-demo/graphql")
+
+	opts := []graphqlgo.SchemaOpt{
+		graphqlgo.MaxDepth(cfg.MaxDepth),
+		graphqlgo.MaxParallelism(cfg.MaxParallelism),
+		graphqlgo.Tracer(tracer{tracer: t}),
+	}
+	if !cfg.Introspection {
+		opts = append(opts, graphqlgo.DisableIntrospection())
+	}
+	schema, err := graphqlgo.ParseSchema(schemaSDL, &resolver{users: users, orders: orders}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse graphql schema: %w", err)
+	}
+
+	return &Handler{cfg: cfg, schema: schema, users: users, orders: orders, rec: rec, tracer: t, log: log}, nil
+}
+
+// request is the body of a GraphQL request over HTTP
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Serve handles POST /graphql. Like most GraphQL servers it answers 200 OK
+// whenever it could read the request; errors of the query are in the body.
+func (h *Handler) Serve(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.cfg.MaxBodyBytes)
+
+	var req request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(c, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+		writeError(c, http.StatusBadRequest, "request body must be a JSON object with a query")
+		return
+	}
+	if req.Query == "" {
+		writeError(c, http.StatusBadRequest, "query is required")
+		return
+	}
+
+	ctx := c.Request.Context()
+	ctx = withLoaders(ctx, h.newLoaders(ctx))
+
+	start := time.Now()
+	resp := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	h.rec.request(len(resp.Errors), start)
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// writeError answers a request that could not be executed, in the shape of a
+// GraphQL response so clients handle it the same way
+func writeError(c *gin.Context, status int, message string) {
+	c.JSON(status, gin.H{"errors": []gin.H{{"message": message}}})
+}
@@ -0,0 +1,288 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/gostratum/examples/graphql-demo/internal/adapter/memory"
+	"github.com/gostratum/examples/graphql-demo/internal/domain"
+	"github.com/gostratum/examples/graphql-demo/internal/usecase"
+)
+
+// fakeCounter counts increments per label values
+type fakeCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (f *fakeCounter) Inc(labels ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.counts[strings.Join(labels, ",")]++
+}
+
+// fakeHistogram keeps observations per label values
+type fakeHistogram struct {
+	mu     sync.Mutex
+	values map[string][]float64
+}
+
+func (f *fakeHistogram) Observe(v float64, labels ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := strings.Join(labels, ",")
+	f.values[key] = append(f.values[key], v)
+}
+
+func (f *fakeHistogram) get(labels ...string) []float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.values[strings.Join(labels, ",")]
+}
+
+type testHandler struct {
+	*Handler
+	router  *gin.Engine
+	batches *fakeHistogram
+	users   []*domain.User
+}
+
+// newTestHandler serves a handler over memory repositories holding three
+// users with two orders each
+func newTestHandler(t *testing.T) *testHandler {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	userRepo := memory.NewUsers()
+	users := usecase.NewUserService(userRepo)
+	orders := usecase.NewOrderService(memory.NewOrders(), userRepo)
+
+	th := &testHandler{batches: &fakeHistogram{values: map[string][]float64{}}}
+	ctx := context.Background()
+	for _, name := range []string{"ada", "grace", "alan"} {
+		u, err := users.Create(ctx, name, name+"@example.com")
+		require.NoError(t, err)
+		th.users = append(th.users, u)
+		for range 2 {
+			_, err := orders.Place(ctx, u.ID, []domain.Item{{SKU: "WIDGET-1", Quantity: 2, UnitPrice: 1999}}, "USD")
+			require.NoError(t, err)
+		}
+	}
+
+	rec := &recorder{
+		requests: &fakeCounter{counts: map[string]int{}},
+		duration: &fakeHistogram{values: map[string][]float64{}},
+		batches:  th.batches,
+	}
+	cfg := Config{MaxDepth: 8, MaxParallelism: 100, Introspection: true, BatchWait: 5 * time.Millisecond, MaxBatch: 100, MaxBodyBytes: 1 << 20}
+	h, err := newHandler(cfg, users, orders, rec, logx.NewNoopLogger())
+	require.NoError(t, err)
+	th.Handler = h
+
+	th.router = gin.New()
+	th.router.POST("/graphql", h.Serve)
+	return th
+}
+
+// response is a GraphQL response with the parts the tests look at
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+func (th *testHandler) do(t *testing.T, body string) (int, response) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	th.router.ServeHTTP(w, req)
+
+	var resp response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), w.Body.String())
+	return w.Code, resp
+}
+
+// query runs query with variables and requires it to succeed
+func (th *testHandler) query(t *testing.T, query string, variables map[string]any, out any) {
+	t.Helper()
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	require.NoError(t, err)
+	code, resp := th.do(t, string(body))
+	require.Equal(t, http.StatusOK, code)
+	require.Empty(t, resp.Errors)
+	require.NoError(t, json.Unmarshal(resp.Data, out))
+}
+
+func TestOrdersWithUsersBatchesUserLookups(t *testing.T) {
+	th := newTestHandler(t)
+
+	var data struct {
+		Orders []struct {
+			ID   string
+			User struct{ Name string }
+		}
+	}
+	th.query(t, `{ orders(limit: 10) { id user { name } } }`, nil, &data)
+
+	require.Len(t, data.Orders, 6)
+	for _, o := range data.Orders {
+		assert.NotEmpty(t, o.User.Name)
+	}
+	// One fetch for the users of all six orders, each user once
+	assert.Equal(t, []float64{3}, th.batches.get("users"))
+}
+
+func TestUsersWithOrdersBatchesOrderLookups(t *testing.T) {
+	th := newTestHandler(t)
+
+	var data struct {
+		Users []struct {
+			Name   string
+			Orders []struct {
+				Total    int
+				Currency string
+				Items    []struct {
+					SKU       string
+					Quantity  int
+					UnitPrice int
+				}
+			}
+		}
+	}
+	th.query(t, `{ users { name orders(limit: 1) { total currency items { sku quantity unitPrice } } } }`, nil, &data)
+
+	require.Len(t, data.Users, 3)
+	for _, u := range data.Users {
+		require.Len(t, u.Orders, 1, u.Name)
+		assert.Equal(t, 3998, u.Orders[0].Total)
+		assert.Equal(t, "WIDGET-1", u.Orders[0].Items[0].SKU)
+	}
+	assert.Equal(t, []float64{3}, th.batches.get("orders_by_user"))
+}
+
+func TestUserNotFoundIsNull(t *testing.T) {
+	th := newTestHandler(t)
+
+	var data struct{ User *struct{ ID string } }
+	th.query(t, `query($id: ID!) { user(id: $id) { id } }`, map[string]any{"id": "nope"}, &data)
+	assert.Nil(t, data.User)
+
+	th.query(t, `query($id: ID!) { user(id: $id) { id } }`, map[string]any{"id": th.users[0].ID}, &data)
+	require.NotNil(t, data.User)
+	assert.Equal(t, th.users[0].ID, data.User.ID)
+}
+
+func TestMutations(t *testing.T) {
+	th := newTestHandler(t)
+
+	var placed struct {
+		PlaceOrder struct {
+			ID     string
+			Status string
+			Total  int
+			User   struct{ Email string }
+		}
+	}
+	th.query(t, `mutation($input: PlaceOrderInput!) {
+		placeOrder(input: $input) { id status total user { email } }
+	}`, map[string]any{"input": map[string]any{
+		"userId":   th.users[0].ID,
+		"currency": "eur",
+		"items":    []any{map[string]any{"sku": "GADGET-1", "quantity": 3, "unitPrice": 500}},
+	}}, &placed)
+	assert.Equal(t, "PLACED", placed.PlaceOrder.Status)
+	assert.Equal(t, 1500, placed.PlaceOrder.Total)
+	assert.Equal(t, "ada@example.com", placed.PlaceOrder.User.Email)
+
+	var cancelled struct{ CancelOrder struct{ Status string } }
+	th.query(t, `mutation($id: ID!) { cancelOrder(id: $id) { status } }`, map[string]any{"id": placed.PlaceOrder.ID}, &cancelled)
+	assert.Equal(t, "CANCELLED", cancelled.CancelOrder.Status)
+
+	var filtered struct{ Orders []struct{ ID string } }
+	th.query(t, `{ orders(status: CANCELLED) { id } }`, nil, &filtered)
+	require.Len(t, filtered.Orders, 1)
+	assert.Equal(t, placed.PlaceOrder.ID, filtered.Orders[0].ID)
+}
+
+func TestErrorCodes(t *testing.T) {
+	th := newTestHandler(t)
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode string
+	}{
+		{name: "invalid input", query: `mutation { createUser(input: {name: "Bob", email: "bob"}) { id } }`, wantCode: codeInvalid},
+		{name: "duplicate email", query: `mutation { createUser(input: {name: "Ada", email: "ada@example.com"}) { id } }`, wantCode: codeConflict},
+		{name: "unknown user", query: `mutation { placeOrder(input: {userId: "nope", currency: "USD", items: [{sku: "A", quantity: 1, unitPrice: 1}]}) { id } }`, wantCode: codeInvalid},
+		{name: "unknown order", query: `mutation { cancelOrder(id: "nope") { id } }`, wantCode: codeNotFound},
+		{name: "negative limit", query: `{ users(limit: -1) { id } }`, wantCode: codeInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := json.Marshal(map[string]any{"query": tt.query})
+			require.NoError(t, err)
+			code, resp := th.do(t, string(body))
+			assert.Equal(t, http.StatusOK, code)
+			require.Len(t, resp.Errors, 1)
+			assert.Equal(t, tt.wantCode, resp.Errors[0].Extensions["code"])
+		})
+	}
+}
+
+func TestBadRequests(t *testing.T) {
+	th := newTestHandler(t)
+
+	code, resp := th.do(t, `{"query": ""}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Len(t, resp.Errors, 1)
+
+	code, _ = th.do(t, `not json`)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// Too deep: users → orders → user → orders → ... past MaxDepth
+	code, resp = th.do(t, `{"query": "{ users { orders { user { orders { user { orders { user { orders { id } } } } } } } } }"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotEmpty(t, resp.Errors)
+	assert.Nil(t, resp.Data)
+}
+
+func TestSpans(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	th := newTestHandler(t)
+	var data struct{ Orders []struct{ ID string } }
+	th.query(t, `query Recent { orders(limit: 2) { id user { name } } }`, nil, &data)
+
+	names := make(map[string]int)
+	for _, s := range spans.Ended() {
+		names[s.Name()]++
+	}
+	assert.Equal(t, 1, names["graphql Recent"])
+	assert.Equal(t, 1, names["Query.orders"])
+	assert.Equal(t, 2, names["Order.user"], "one span per resolver that does work")
+	assert.Equal(t, 1, names["dataloader.users"])
+	assert.Zero(t, names["Order.id"], "fields read off a struct get no span")
+	assert.Zero(t, names["User.name"])
+}
@@ -0,0 +1,102 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gostratum/examples/graphql-demo/internal/dataloader"
+	"github.com/gostratum/examples/graphql-demo/internal/domain"
+	"github.com/gostratum/examples/graphql-demo/internal/usecase"
+)
+
+// loaders are the dataloaders of one request
+type loaders struct {
+	users        *dataloader.Loader[string, *domain.User]
+	ordersByUser *dataloader.Loader[ordersKey, []*domain.Order]
+}
+
+// ordersKey asks for the newest limit orders of a user
+type ordersKey struct {
+	userID string
+	limit  int
+}
+
+// loadersKey is the context key of the request's loaders
+type loadersKey struct{}
+
+// newLoaders creates the loaders of the request of ctx
+func (h *Handler) newLoaders(ctx context.Context) *loaders {
+	cfg := dataloader.Config{Wait: h.cfg.BatchWait, MaxBatch: h.cfg.MaxBatch}
+	return &loaders{
+		users:        dataloader.New(ctx, cfg, traced(h, "users", h.fetchUsers)),
+		ordersByUser: dataloader.New(ctx, cfg, traced(h, "orders_by_user", h.fetchOrdersByUser)),
+	}
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// fetchUsers loads a batch of users with one call to the use case
+func (h *Handler) fetchUsers(ctx context.Context, ids []string) (map[string]*domain.User, error) {
+	return h.users.GetMany(ctx, ids)
+}
+
+// fetchOrdersByUser loads the orders of a batch of users with one call to the
+// use case per distinct limit; a query rarely asks for more than one
+func (h *Handler) fetchOrdersByUser(ctx context.Context, keys []ordersKey) (map[ordersKey][]*domain.Order, error) {
+	byLimit := make(map[int][]string)
+	for _, k := range keys {
+		byLimit[k.limit] = append(byLimit[k.limit], k.userID)
+	}
+
+	values := make(map[ordersKey][]*domain.Order, len(keys))
+	for limit, userIDs := range byLimit {
+		byUser, err := h.orders.ListByUsers(ctx, userIDs, limit)
+		if err != nil {
+			return nil, err
+		}
+		// Every key gets a value, so users without orders get an empty list
+		// rather than dataloader.ErrNotFound
+		for _, id := range userIDs {
+			values[ordersKey{userID: id, limit: limit}] = byUser[id]
+		}
+	}
+	return values, nil
+}
+
+// traced wraps a batch function in a span and records the batch size
+func traced[K comparable, V any](h *Handler, name string, fetch dataloader.BatchFunc[K, V]) dataloader.BatchFunc[K, V] {
+	return func(ctx context.Context, keys []K) (map[K]V, error) {
+		ctx, span := h.tracer.Start(ctx, "dataloader."+name, trace.WithAttributes(
+			attribute.String("dataloader.name", name),
+			attribute.Int("dataloader.batch_size", len(keys)),
+		))
+		defer span.End()
+		h.rec.batch(name, len(keys))
+
+		values, err := fetch(ctx, keys)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return values, err
+	}
+}
+
+// loadError maps the errors of a dataloader to GraphQL errors. A missing key
+// means the object was not found.
+func loadError(err error) error {
+	if errors.Is(err, dataloader.ErrNotFound) {
+		return toGraphQLError(usecase.ErrNotFound)
+	}
+	return toGraphQLError(err)
+}
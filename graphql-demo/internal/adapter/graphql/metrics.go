@@ -0,0 +1,55 @@
+package graphql
+
+import (
+	"time"
+
+	"github.com/gostratum/metricsx"
+)
+
+// recorder records requests and dataloader batches
+type recorder struct {
+	requests counter
+	duration histogram
+	batches  histogram
+}
+
+func newRecorder(metrics metricsx.Metrics) *recorder {
+	return &recorder{
+		requests: metrics.Counter("graphql_requests_total",
+			metricsx.WithHelp("GraphQL requests, by result (ok or error)"),
+			metricsx.WithLabels("result"),
+		),
+		duration: metrics.Histogram("graphql_request_duration_seconds",
+			metricsx.WithHelp("Time to execute a GraphQL request"),
+		),
+		batches: metrics.Histogram("graphql_dataloader_batch_size",
+			metricsx.WithHelp("Keys fetched per dataloader batch, by loader"),
+			metricsx.WithLabels("loader"),
+			metricsx.WithBuckets(1, 2, 5, 10, 20, 50, 100),
+		),
+	}
+}
+
+// request records a request that returned errs errors
+func (r *recorder) request(errs int, start time.Time) {
+	result := "ok"
+	if errs > 0 {
+		result = "error"
+	}
+	r.requests.Inc(result)
+	r.duration.Observe(time.Since(start).Seconds())
+}
+
+func (r *recorder) batch(loader string, size int) {
+	r.batches.Observe(float64(size), loader)
+}
+
+// counter is the part of metricsx.Counter the handler uses
+type counter interface {
+	Inc(labels ...string)
+}
+
+// histogram is the part of metricsx.Histogram the handler uses
+type histogram interface {
+	Observe(v float64, labels ...string)
+}
@@ -0,0 +1,86 @@
+package graphql
+
+import (
+	"context"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+
+	"github.com/gostratum/examples/graphql-demo/internal/domain"
+)
+
+// orderResolver resolves the fields of Order
+type orderResolver struct {
+	order *domain.Order
+}
+
+func orderResolvers(orders []*domain.Order) []*orderResolver {
+	resolvers := make([]*orderResolver, len(orders))
+	for i, o := range orders {
+		resolvers[i] = &orderResolver{order: o}
+	}
+	return resolvers
+}
+
+// ID resolves Order.id
+func (r *orderResolver) ID() graphqlgo.ID {
+	return graphqlgo.ID(r.order.ID)
+}
+
+// User resolves Order.user through the request's dataloader, so the users of
+// every order in a list are fetched together, each one once
+func (r *orderResolver) User(ctx context.Context) (*userResolver, error) {
+	u, err := loadersFrom(ctx).users.Load(ctx, r.order.UserID)
+	if err != nil {
+		return nil, loadError(err)
+	}
+	return &userResolver{user: u}, nil
+}
+
+// Items resolves Order.items
+func (r *orderResolver) Items() []*itemResolver {
+	items := make([]*itemResolver, len(r.order.Items))
+	for i := range r.order.Items {
+		items[i] = &itemResolver{item: r.order.Items[i]}
+	}
+	return items
+}
+
+// Total resolves Order.total; domain.MaxOrderTotal keeps it within an Int
+func (r *orderResolver) Total() int32 {
+	return int32(r.order.Total())
+}
+
+// Currency resolves Order.currency
+func (r *orderResolver) Currency() string {
+	return r.order.Currency
+}
+
+// Status resolves Order.status
+func (r *orderResolver) Status() string {
+	return string(r.order.Status)
+}
+
+// CreatedAt resolves Order.createdAt
+func (r *orderResolver) CreatedAt() graphqlgo.Time {
+	return graphqlgo.Time{Time: r.order.CreatedAt}
+}
+
+// itemResolver resolves the fields of OrderItem
+type itemResolver struct {
+	item domain.Item
+}
+
+// SKU resolves OrderItem.sku
+func (r *itemResolver) SKU() string {
+	return r.item.SKU
+}
+
+// Quantity resolves OrderItem.quantity
+func (r *itemResolver) Quantity() int32 {
+	return int32(r.item.Quantity)
+}
+
+// UnitPrice resolves OrderItem.unitPrice
+func (r *itemResolver) UnitPrice() int32 {
+	return int32(r.item.UnitPrice)
+}
@@ -0,0 +1,125 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+
+	"github.com/gostratum/examples/graphql-demo/internal/domain"
+	"github.com/gostratum/examples/graphql-demo/internal/usecase"
+)
+
+// resolver resolves the fields of Query and Mutation
+type resolver struct {
+	users  *usecase.UserService
+	orders *usecase.OrderService
+}
+
+// User resolves Query.user
+func (r *resolver) User(ctx context.Context, args struct{ ID graphqlgo.ID }) (*userResolver, error) {
+	u, err := r.users.Get(ctx, string(args.ID))
+	if errors.Is(err, usecase.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, toGraphQLError(err)
+	}
+	return &userResolver{user: u}, nil
+}
+
+// Users resolves Query.users
+func (r *resolver) Users(ctx context.Context, args struct{ Limit *int32 }) ([]*userResolver, error) {
+	users, err := r.users.List(ctx, intArg(args.Limit))
+	if err != nil {
+		return nil, toGraphQLError(err)
+	}
+	return userResolvers(users), nil
+}
+
+// Order resolves Query.order
+func (r *resolver) Order(ctx context.Context, args struct{ ID graphqlgo.ID }) (*orderResolver, error) {
+	o, err := r.orders.Get(ctx, string(args.ID))
+	if errors.Is(err, usecase.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, toGraphQLError(err)
+	}
+	return &orderResolver{order: o}, nil
+}
+
+// Orders resolves Query.orders
+func (r *resolver) Orders(ctx context.Context, args struct {
+	Status *string
+	Limit  *int32
+}) ([]*orderResolver, error) {
+	var status domain.OrderStatus
+	if args.Status != nil {
+		status = domain.OrderStatus(*args.Status)
+	}
+	orders, err := r.orders.List(ctx, status, intArg(args.Limit))
+	if err != nil {
+		return nil, toGraphQLError(err)
+	}
+	return orderResolvers(orders), nil
+}
+
+// createUserInput is the CreateUserInput input type
+type createUserInput struct {
+	Name  string
+	Email string
+}
+
+// CreateUser resolves Mutation.createUser
+func (r *resolver) CreateUser(ctx context.Context, args struct{ Input createUserInput }) (*userResolver, error) {
+	u, err := r.users.Create(ctx, args.Input.Name, args.Input.Email)
+	if err != nil {
+		return nil, toGraphQLError(err)
+	}
+	return &userResolver{user: u}, nil
+}
+
+// placeOrderInput is the PlaceOrderInput input type
+type placeOrderInput struct {
+	UserID   graphqlgo.ID
+	Items    []orderItemInput
+	Currency string
+}
+
+// orderItemInput is the OrderItemInput input type
+type orderItemInput struct {
+	SKU       string
+	Quantity  int32
+	UnitPrice int32
+}
+
+// PlaceOrder resolves Mutation.placeOrder
+func (r *resolver) PlaceOrder(ctx context.Context, args struct{ Input placeOrderInput }) (*orderResolver, error) {
+	items := make([]domain.Item, len(args.Input.Items))
+	for i, item := range args.Input.Items {
+		items[i] = domain.Item{SKU: item.SKU, Quantity: int(item.Quantity), UnitPrice: int64(item.UnitPrice)}
+	}
+	o, err := r.orders.Place(ctx, string(args.Input.UserID), items, args.Input.Currency)
+	if err != nil {
+		return nil, toGraphQLError(err)
+	}
+	return &orderResolver{order: o}, nil
+}
+
+// CancelOrder resolves Mutation.cancelOrder
+func (r *resolver) CancelOrder(ctx context.Context, args struct{ ID graphqlgo.ID }) (*orderResolver, error) {
+	o, err := r.orders.Cancel(ctx, string(args.ID))
+	if err != nil {
+		return nil, toGraphQLError(err)
+	}
+	return &orderResolver{order: o}, nil
+}
+
+// intArg returns an optional Int argument, 0 when it was not given
+func intArg(v *int32) int {
+	if v == nil {
+		return 0
+	}
+	return int(*v)
+}
@@ -0,0 +1,58 @@
+package graphql
+
+import (
+	"context"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+
+	"github.com/gostratum/examples/graphql-demo/internal/domain"
+)
+
+// userResolver resolves the fields of User
+type userResolver struct {
+	user *domain.User
+}
+
+func userResolvers(users []*domain.User) []*userResolver {
+	resolvers := make([]*userResolver, len(users))
+	for i, u := range users {
+		resolvers[i] = &userResolver{user: u}
+	}
+	return resolvers
+}
+
+// ID resolves User.id
+func (r *userResolver) ID() graphqlgo.ID {
+	return graphqlgo.ID(r.user.ID)
+}
+
+// Name resolves User.name
+func (r *userResolver) Name() string {
+	return r.user.Name
+}
+
+// Email resolves User.email
+func (r *userResolver) Email() string {
+	return r.user.Email
+}
+
+// CreatedAt resolves User.createdAt
+func (r *userResolver) CreatedAt() graphqlgo.Time {
+	return graphqlgo.Time{Time: r.user.CreatedAt}
+}
+
+// Orders resolves User.orders through the request's dataloader, so the orders
+// of every user in a list are fetched together
+func (r *userResolver) Orders(ctx context.Context, args struct{ Limit *int32 }) ([]*orderResolver, error) {
+	// Checked here rather than in the use case, so a bad limit does not fail
+	// the batch it would share with the orders of other users
+	n := intArg(args.Limit)
+	if n < 0 {
+		return nil, &resolverError{code: codeInvalid, message: "limit must not be negative"}
+	}
+	orders, err := loadersFrom(ctx).ordersByUser.Load(ctx, ordersKey{userID: r.user.ID, limit: n})
+	if err != nil {
+		return nil, loadError(err)
+	}
+	return orderResolvers(orders), nil
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/gostratum/examples/graphql-demo/internal/domain"
+	"github.com/gostratum/examples/graphql-demo/internal/usecase"
+)
+
+// Orders implements usecase.OrderRepository
+type Orders struct {
+	mu     sync.RWMutex
+	orders map[string]domain.Order
+	// ids and byUser are kept sorted, oldest first
+	ids    []string
+	byUser map[string][]string
+}
+
+// NewOrders creates an empty order store
+func NewOrders() usecase.OrderRepository {
+	return &Orders{orders: make(map[string]domain.Order), byUser: make(map[string][]string)}
+}
+
+// Save implements usecase.OrderRepository
+func (r *Orders) Save(ctx context.Context, o *domain.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.orders[o.ID]; !ok {
+		r.ids = insertSorted(r.ids, o.ID)
+		r.byUser[o.UserID] = insertSorted(r.byUser[o.UserID], o.ID)
+	}
+	r.orders[o.ID] = clone(o)
+	return nil
+}
+
+// FindByID implements usecase.OrderRepository
+func (r *Orders) FindByID(ctx context.Context, id string) (*domain.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	o, ok := r.orders[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	c := clone(&o)
+	return &c, nil
+}
+
+// List implements usecase.OrderRepository
+func (r *Orders) List(ctx context.Context, status domain.OrderStatus, limit int) ([]*domain.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.newest(r.ids, status, limit), nil
+}
+
+// ListByUsers implements usecase.OrderRepository
+func (r *Orders) ListByUsers(ctx context.Context, userIDs []string, limit int) ([]*domain.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var orders []*domain.Order
+	for _, userID := range userIDs {
+		orders = append(orders, r.newest(r.byUser[userID], "", limit)...)
+	}
+	return orders, nil
+}
+
+// newest returns up to limit orders among ids with status, newest first
+func (r *Orders) newest(ids []string, status domain.OrderStatus, limit int) []*domain.Order {
+	var orders []*domain.Order
+	for i := len(ids) - 1; i >= 0 && len(orders) < limit; i-- {
+		o := r.orders[ids[i]]
+		if status == "" || o.Status == status {
+			c := clone(&o)
+			orders = append(orders, &c)
+		}
+	}
+	return orders
+}
+
+func insertSorted(ids []string, id string) []string {
+	i, _ := slices.BinarySearch(ids, id)
+	return slices.Insert(ids, i, id)
+}
+
+// clone copies o with its own items, so callers never share them with the store
+func clone(o *domain.Order) domain.Order {
+	c := *o
+	c.Items = slices.Clone(o.Items)
+	return c
+}
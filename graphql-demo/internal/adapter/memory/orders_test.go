@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/graphql-demo/internal/domain"
+)
+
+func saveOrders(t *testing.T, r *Orders, orders ...domain.Order) {
+	t.Helper()
+	for _, o := range orders {
+		o.Items = []domain.Item{{SKU: "A", Quantity: 1, UnitPrice: 100}}
+		require.NoError(t, r.Save(context.Background(), &o))
+	}
+}
+
+func ids(orders []*domain.Order) []string {
+	var ids []string
+	for _, o := range orders {
+		ids = append(ids, o.ID)
+	}
+	return ids
+}
+
+func TestOrdersListByUsers(t *testing.T) {
+	r := NewOrders().(*Orders)
+	saveOrders(t, r,
+		domain.Order{ID: "o-1", UserID: "u-1", Status: domain.StatusPlaced},
+		domain.Order{ID: "o-2", UserID: "u-2", Status: domain.StatusPlaced},
+		domain.Order{ID: "o-3", UserID: "u-1", Status: domain.StatusCancelled},
+		domain.Order{ID: "o-4", UserID: "u-1", Status: domain.StatusPlaced},
+	)
+	ctx := context.Background()
+
+	orders, err := r.ListByUsers(ctx, []string{"u-1", "u-2", "u-3"}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"o-4", "o-3", "o-2"}, ids(orders), "newest first, up to 2 per user")
+
+	orders, err = r.List(ctx, domain.StatusPlaced, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"o-4", "o-2", "o-1"}, ids(orders))
+}
+
+func TestOrdersDoNotShareItems(t *testing.T) {
+	r := NewOrders().(*Orders)
+	saveOrders(t, r, domain.Order{ID: "o-1", UserID: "u-1"})
+	ctx := context.Background()
+
+	o, err := r.FindByID(ctx, "o-1")
+	require.NoError(t, err)
+	o.Items[0].Quantity = 99
+
+	again, err := r.FindByID(ctx, "o-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, again.Items[0].Quantity)
+}
+
+func TestUsersFindByIDs(t *testing.T) {
+	r := NewUsers()
+	ctx := context.Background()
+	for _, id := range []string{"u-1", "u-2"} {
+		require.NoError(t, r.Save(ctx, &domain.User{ID: id, Name: id, Email: id + "@example.com"}))
+	}
+	assert.ErrorIs(t, r.Save(ctx, &domain.User{ID: "u-3", Name: "u-3", Email: "u-1@example.com"}), domain.ErrConflict)
+
+	users, err := r.FindByIDs(ctx, []string{"u-2", "missing", "u-1"})
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, "u-2", users[0].ID)
+}
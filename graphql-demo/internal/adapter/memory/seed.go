@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gostratum/core/logx"
+	"go.uber.org/fx"
+
+	"github.com/gostratum/examples/graphql-demo/internal/domain"
+	"github.com/gostratum/examples/graphql-demo/internal/usecase"
+)
+
+// demoUsers and their orders are created on start, so there is something to query
+var demoUsers = []struct {
+	name, email string
+	orders      [][]domain.Item
+}{
+	{name: "Ada Lovelace", email: "ada@example.com", orders: [][]domain.Item{
+		{{SKU: "WIDGET-1", Quantity: 2, UnitPrice: 1999}},
+		{{SKU: "GADGET-1", Quantity: 1, UnitPrice: 4500}, {SKU: "WIDGET-1", Quantity: 1, UnitPrice: 1999}},
+	}},
+	{name: "Grace Hopper", email: "grace@example.com", orders: [][]domain.Item{
+		{{SKU: "GIZMO-1", Quantity: 10, UnitPrice: 250}},
+	}},
+	{name: "Alan Turing", email: "alan@example.com"},
+}
+
+// Seed creates the demo users and orders through the use cases when the
+// application starts.
+// This function is designed to be used with fx.Invoke.
+func Seed(lc fx.Lifecycle, users *usecase.UserService, orders *usecase.OrderService, log logx.Logger) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			count := 0
+			for _, du := range demoUsers {
+				u, err := users.Create(ctx, du.name, du.email)
+				if err != nil {
+					return fmt.Errorf("failed to seed user %s: %w", du.email, err)
+				}
+				for _, items := range du.orders {
+					if _, err := orders.Place(ctx, u.ID, items, "USD"); err != nil {
+						return fmt.Errorf("failed to seed order of %s: %w", du.email, err)
+					}
+					count++
+				}
+			}
+			log.Info("demo data created", logx.Int("users", len(demoUsers)), logx.Int("orders", count))
+			return nil
+		},
+	})
+}
@@ -0,0 +1,79 @@
+// Package memory keeps users and orders in memory. The example is about the
+// GraphQL layer, so there is no database behind it; the batch methods still
+// answer in one call, like the single query a database would run.
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/gostratum/examples/graphql-demo/internal/domain"
+	"github.com/gostratum/examples/graphql-demo/internal/usecase"
+)
+
+// Users implements usecase.UserRepository
+type Users struct {
+	mu    sync.RWMutex
+	users map[string]domain.User
+	// ids is kept sorted for List; version 7 UUIDs sort oldest first
+	ids []string
+}
+
+// NewUsers creates an empty user store
+func NewUsers() usecase.UserRepository {
+	return &Users{users: make(map[string]domain.User)}
+}
+
+// Save implements usecase.UserRepository
+func (r *Users) Save(ctx context.Context, u *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, existing := range r.users {
+		if existing.Email == u.Email && id != u.ID {
+			return domain.ErrConflict
+		}
+	}
+	if _, ok := r.users[u.ID]; !ok {
+		i, _ := slices.BinarySearch(r.ids, u.ID)
+		r.ids = slices.Insert(r.ids, i, u.ID)
+	}
+	r.users[u.ID] = *u
+	return nil
+}
+
+// FindByID implements usecase.UserRepository
+func (r *Users) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	u, ok := r.users[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &u, nil
+}
+
+// FindByIDs implements usecase.UserRepository
+func (r *Users) FindByIDs(ctx context.Context, ids []string) ([]*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	users := make([]*domain.User, 0, len(ids))
+	for _, id := range ids {
+		if u, ok := r.users[id]; ok {
+			users = append(users, &u)
+		}
+	}
+	return users, nil
+}
+
+// List implements usecase.UserRepository
+func (r *Users) List(ctx context.Context, limit int) ([]*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	users := make([]*domain.User, 0, min(limit, len(r.ids)))
+	for _, id := range r.ids[:min(limit, len(r.ids))] {
+		u := r.users[id]
+		users = append(users, &u)
+	}
+	return users, nil
+}
@@ -0,0 +1,139 @@
+// Package dataloader batches the lookups made while a GraphQL query executes.
+// Resolvers of a list run concurrently and each asks for one key; a Loader
+// gathers the keys asked for within a short window and fetches them with one
+// call, so a query over n orders and their users makes two calls instead of n+1.
+//
+// A Loader lives for one request. It remembers every key it loaded, errors
+// included, so a user referenced by ten orders is fetched once and a query
+// sees one consistent answer for it.
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned by Load for keys the BatchFunc returned no value for
+var ErrNotFound = errors.New("not found")
+
+// BatchFunc fetches the values of keys in one call. Keys without a value are
+// left out of the map.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Config controls how keys are batched
+type Config struct {
+	// Wait is how long the first key of a batch waits for others to join it
+	Wait time.Duration
+	// MaxBatch fetches a batch as soon as it holds this many keys
+	MaxBatch int
+}
+
+// Loader batches and remembers the lookups of one request
+type Loader[K comparable, V any] struct {
+	ctx   context.Context
+	cfg   Config
+	fetch BatchFunc[K, V]
+
+	mu      sync.Mutex
+	results map[K]*result[V]
+	// pending is the batch collecting keys; nil until the next Load
+	pending *batch[K]
+}
+
+type batch[K comparable] struct {
+	keys []K
+}
+
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	found bool
+	err   error
+}
+
+// New creates a Loader for the request of ctx. Batches are fetched with ctx,
+// not with the context of the Load that started them, so a caller giving up
+// does not fail the other keys of its batch.
+func New[K comparable, V any](ctx context.Context, cfg Config, fetch BatchFunc[K, V]) *Loader[K, V] {
+	if cfg.MaxBatch <= 0 {
+		cfg.MaxBatch = 100
+	}
+	return &Loader[K, V]{ctx: ctx, cfg: cfg, fetch: fetch, results: make(map[K]*result[V])}
+}
+
+// Load returns the value of key, fetching it in a batch with the other keys
+// asked for within Config.Wait. It returns ErrNotFound when the batch had no
+// value for key.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	r, ok := l.results[key]
+	if !ok {
+		r = &result[V]{done: make(chan struct{})}
+		l.results[key] = r
+		l.add(key)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+	switch {
+	case r.err != nil:
+		return r.value, r.err
+	case !r.found:
+		return r.value, ErrNotFound
+	}
+	return r.value, nil
+}
+
+// add puts key in the pending batch, starting a batch when there is none.
+// l.mu must be held.
+func (l *Loader[K, V]) add(key K) {
+	if l.pending == nil {
+		b := &batch[K]{}
+		l.pending = b
+		time.AfterFunc(l.cfg.Wait, func() { l.dispatch(b) })
+	}
+	l.pending.keys = append(l.pending.keys, key)
+
+	if len(l.pending.keys) >= l.cfg.MaxBatch {
+		b := l.pending
+		l.pending = nil
+		go l.run(b.keys)
+	}
+}
+
+// dispatch fetches b when its wait is over, unless it filled up and was
+// fetched already
+func (l *Loader[K, V]) dispatch(b *batch[K]) {
+	l.mu.Lock()
+	if l.pending != b {
+		l.mu.Unlock()
+		return
+	}
+	l.pending = nil
+	l.mu.Unlock()
+	l.run(b.keys)
+}
+
+// run fetches keys and hands each waiting Load its result
+func (l *Loader[K, V]) run(keys []K) {
+	values, err := l.fetch(l.ctx, keys)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		r := l.results[key]
+		if err != nil {
+			r.err = err
+		} else {
+			r.value, r.found = values[key]
+		}
+		close(r.done)
+	}
+}
@@ -0,0 +1,127 @@
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingFetch doubles keys below 100 and records the batches it was called with
+type recordingFetch struct {
+	mu      sync.Mutex
+	batches [][]int
+	err     error
+}
+
+func (f *recordingFetch) fetch(ctx context.Context, keys []int) (map[int]int, error) {
+	f.mu.Lock()
+	batch := slices.Clone(keys)
+	slices.Sort(batch)
+	f.batches = append(f.batches, batch)
+	f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	values := make(map[int]int, len(keys))
+	for _, k := range keys {
+		if k < 100 {
+			values[k] = k * 2
+		}
+	}
+	return values, nil
+}
+
+func (f *recordingFetch) calls() [][]int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.batches)
+}
+
+// loadAll loads keys concurrently, as resolvers of a list do
+func loadAll(l *Loader[int, int], keys ...int) ([]int, []error) {
+	values := make([]int, len(keys))
+	errs := make([]error, len(keys))
+	var wg sync.WaitGroup
+	for i, k := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values[i], errs[i] = l.Load(context.Background(), k)
+		}()
+	}
+	wg.Wait()
+	return values, errs
+}
+
+func TestLoadBatches(t *testing.T) {
+	f := &recordingFetch{}
+	l := New(context.Background(), Config{Wait: 20 * time.Millisecond}, f.fetch)
+
+	values, errs := loadAll(l, 1, 2, 3, 2, 1)
+	assert.Equal(t, []int{2, 4, 6, 4, 2}, values)
+	assert.Equal(t, make([]error, 5), errs)
+	assert.Equal(t, [][]int{{1, 2, 3}}, f.calls(), "one call, each key once")
+
+	// Loaded keys are remembered; new ones make a new batch
+	values, _ = loadAll(l, 1, 4)
+	assert.Equal(t, []int{2, 8}, values)
+	assert.Equal(t, [][]int{{1, 2, 3}, {4}}, f.calls())
+}
+
+func TestLoadMaxBatch(t *testing.T) {
+	f := &recordingFetch{}
+	// A wait long enough that only full batches are fetched before the test ends
+	l := New(context.Background(), Config{Wait: time.Hour, MaxBatch: 2}, f.fetch)
+
+	values, _ := loadAll(l, 1, 2, 3, 4)
+	assert.Equal(t, []int{2, 4, 6, 8}, values)
+	calls := f.calls()
+	require.Len(t, calls, 2)
+	for _, batch := range calls {
+		assert.Len(t, batch, 2)
+	}
+}
+
+func TestLoadNotFound(t *testing.T) {
+	l := New(context.Background(), Config{Wait: time.Millisecond}, (&recordingFetch{}).fetch)
+
+	_, err := l.Load(context.Background(), 100)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLoadError(t *testing.T) {
+	errDown := errors.New("database down")
+	f := &recordingFetch{err: errDown}
+	l := New(context.Background(), Config{Wait: time.Millisecond}, f.fetch)
+
+	_, errs := loadAll(l, 1, 2)
+	assert.ErrorIs(t, errs[0], errDown)
+	assert.ErrorIs(t, errs[1], errDown)
+
+	// The error is remembered for the rest of the request
+	_, err := l.Load(context.Background(), 1)
+	assert.ErrorIs(t, err, errDown)
+	assert.Len(t, f.calls(), 1)
+}
+
+func TestLoadCallerGivesUp(t *testing.T) {
+	f := &recordingFetch{}
+	l := New(context.Background(), Config{Wait: 50 * time.Millisecond}, f.fetch)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := l.Load(ctx, 1)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// The batch still ran for the others waiting on it
+	value, err := l.Load(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, value)
+	assert.Len(t, f.calls(), 1)
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+
+func TestNewUser(t *testing.T) {
+	u, err := NewUser("u-1", " Ada ", " Ada@Example.com", now)
+	require.NoError(t, err)
+	assert.Equal(t, &User{ID: "u-1", Name: "Ada", Email: "ada@example.com", CreatedAt: now}, u)
+
+	for name, email := range map[string]string{
+		"no email":       "",
+		"no at":          "ada.example.com",
+		"display name":   "Ada <ada@example.com>",
+		"trailing stuff": "ada@example.com, bob@example.com",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewUser("u-1", "Ada", email, now)
+			assert.ErrorIs(t, err, ErrInvalidInput)
+		})
+	}
+
+	_, err = NewUser("u-1", " ", "ada@example.com", now)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func TestNewOrder(t *testing.T) {
+	items := []Item{{SKU: "WIDGET-1", Quantity: 2, UnitPrice: 1999}, {SKU: "GADGET-1", Quantity: 1, UnitPrice: 500}}
+	o, err := NewOrder("o-1", "u-1", items, "usd", now)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPlaced, o.Status)
+	assert.Equal(t, "USD", o.Currency)
+	assert.Equal(t, int64(4498), o.Total())
+
+	tests := map[string]struct {
+		userID   string
+		items    []Item
+		currency string
+	}{
+		"no user":           {items: items, currency: "USD"},
+		"no items":          {userID: "u-1", currency: "USD"},
+		"bad currency":      {userID: "u-1", items: items, currency: "US"},
+		"no sku":            {userID: "u-1", items: []Item{{Quantity: 1}}, currency: "USD"},
+		"zero quantity":     {userID: "u-1", items: []Item{{SKU: "A"}}, currency: "USD"},
+		"negative price":    {userID: "u-1", items: []Item{{SKU: "A", Quantity: 1, UnitPrice: -1}}, currency: "USD"},
+		"total over int32":  {userID: "u-1", items: []Item{{SKU: "A", Quantity: 2, UnitPrice: MaxOrderTotal}}, currency: "USD"},
+		"total over across": {userID: "u-1", items: []Item{{SKU: "A", Quantity: 1, UnitPrice: MaxOrderTotal}, {SKU: "B", Quantity: 1, UnitPrice: 1}}, currency: "USD"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewOrder("o-1", tt.userID, tt.items, tt.currency, now)
+			assert.ErrorIs(t, err, ErrInvalidInput)
+		})
+	}
+}
+
+func TestOrderCancel(t *testing.T) {
+	o, err := NewOrder("o-1", "u-1", []Item{{SKU: "A", Quantity: 1, UnitPrice: 100}}, "USD", now)
+	require.NoError(t, err)
+
+	require.NoError(t, o.Cancel())
+	assert.Equal(t, StatusCancelled, o.Status)
+	assert.ErrorIs(t, o.Cancel(), ErrConflict)
+}
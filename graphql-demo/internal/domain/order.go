@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// OrderStatus is where an order is in its lifecycle
+type OrderStatus string
+
+// Order statuses
+const (
+	StatusPlaced    OrderStatus = "PLACED"
+	StatusCancelled OrderStatus = "CANCELLED"
+)
+
+// MaxOrderTotal caps the total of an order. GraphQL's Int is 32 bits, and
+// totals are exposed in minor units.
+const MaxOrderTotal = math.MaxInt32
+
+// Item is one line of an order
+type Item struct {
+	SKU       string
+	Quantity  int
+	UnitPrice int64
+}
+
+// Order represents an order in the system
+// This is a pure domain model without infrastructure concerns
+type Order struct {
+	ID        string
+	UserID    string
+	Items     []Item
+	Currency  string
+	Status    OrderStatus
+	CreatedAt time.Time
+}
+
+// NewOrder creates a validated order in the PLACED status
+func NewOrder(id, userID string, items []Item, currency string, now time.Time) (*Order, error) {
+	o := &Order{
+		ID:        id,
+		UserID:    userID,
+		Items:     items,
+		Currency:  strings.ToUpper(strings.TrimSpace(currency)),
+		Status:    StatusPlaced,
+		CreatedAt: now,
+	}
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Total returns the sum of the order lines in minor units
+func (o *Order) Total() int64 {
+	var total int64
+	for _, item := range o.Items {
+		total += item.UnitPrice * int64(item.Quantity)
+	}
+	return total
+}
+
+// Cancel moves a placed order to CANCELLED
+func (o *Order) Cancel() error {
+	if o.Status != StatusPlaced {
+		return fmt.Errorf("%w: order is %s", ErrConflict, o.Status)
+	}
+	o.Status = StatusCancelled
+	return nil
+}
+
+// Validate performs basic validation on order fields
+func (o *Order) Validate() error {
+	if o.UserID == "" {
+		return fmt.Errorf("%w: user id is required", ErrInvalidInput)
+	}
+	if len(o.Items) == 0 {
+		return fmt.Errorf("%w: order must have at least one item", ErrInvalidInput)
+	}
+	if len(o.Currency) != 3 || strings.Trim(o.Currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return fmt.Errorf("%w: currency must be a 3-letter ISO 4217 code", ErrInvalidInput)
+	}
+
+	var total int64
+	for _, item := range o.Items {
+		switch {
+		case item.SKU == "":
+			return fmt.Errorf("%w: item SKU is required", ErrInvalidInput)
+		case item.Quantity <= 0 || item.Quantity > 1000:
+			return fmt.Errorf("%w: item quantity must be between 1 and 1000", ErrInvalidInput)
+		case item.UnitPrice < 0 || item.UnitPrice > MaxOrderTotal:
+			return fmt.Errorf("%w: item price must be between 0 and %d", ErrInvalidInput, MaxOrderTotal)
+		}
+		// Checked line by line, so the sum cannot overflow on the way
+		total += item.UnitPrice * int64(item.Quantity)
+		if total > MaxOrderTotal {
+			return fmt.Errorf("%w: order total must not exceed %d", ErrInvalidInput, MaxOrderTotal)
+		}
+	}
+	return nil
+}
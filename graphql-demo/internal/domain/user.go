@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// User represents a user in the system
+// This is a pure domain model without infrastructure concerns
+type User struct {
+	ID        string
+	Name      string
+	Email     string
+	CreatedAt time.Time
+}
+
+// NewUser creates a validated user; the name is trimmed and the email lowercased
+func NewUser(id, name, email string, now time.Time) (*User, error) {
+	u := &User{
+		ID:        id,
+		Name:      strings.TrimSpace(name),
+		Email:     strings.ToLower(strings.TrimSpace(email)),
+		CreatedAt: now,
+	}
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Validate performs basic validation on user fields
+func (u *User) Validate() error {
+	if u.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	if addr, err := mail.ParseAddress(u.Email); err != nil || addr.Address != u.Email {
+		return fmt.Errorf("%w: email format is invalid", ErrInvalidInput)
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package usecase
+
+import "fmt"
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+// limit applies the default to 0 and caps n at maxLimit
+func limit(n int) (int, error) {
+	switch {
+	case n < 0:
+		return 0, fmt.Errorf("%w: limit must not be negative", ErrInvalid)
+	case n == 0:
+		return defaultLimit, nil
+	case n > maxLimit:
+		return maxLimit, nil
+	}
+	return n, nil
+}
@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gostratum/examples/graphql-demo/internal/domain"
+)
+
+// MockUserRepository implements UserRepository for testing
+type MockUserRepository struct {
+	users map[string]*domain.User
+	err   error
+	// calls counts the calls of each method
+	calls map[string]int
+}
+
+func newMockUsers() *MockUserRepository {
+	return &MockUserRepository{users: make(map[string]*domain.User), calls: make(map[string]int)}
+}
+
+func (m *MockUserRepository) Save(ctx context.Context, u *domain.User) error {
+	m.calls["Save"]++
+	if m.err != nil {
+		return m.err
+	}
+	m.users[u.ID] = u
+	return nil
+}
+
+func (m *MockUserRepository) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	m.calls["FindByID"]++
+	if m.err != nil {
+		return nil, m.err
+	}
+	u, ok := m.users[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return u, nil
+}
+
+func (m *MockUserRepository) FindByIDs(ctx context.Context, ids []string) ([]*domain.User, error) {
+	m.calls["FindByIDs"]++
+	if m.err != nil {
+		return nil, m.err
+	}
+	var users []*domain.User
+	for _, id := range ids {
+		if u, ok := m.users[id]; ok {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+func (m *MockUserRepository) List(ctx context.Context, limit int) ([]*domain.User, error) {
+	m.calls["List"]++
+	if m.err != nil {
+		return nil, m.err
+	}
+	var users []*domain.User
+	for _, u := range m.users {
+		users = append(users, u)
+	}
+	slices.SortFunc(users, func(a, b *domain.User) int { return strings.Compare(a.ID, b.ID) })
+	return users[:min(limit, len(users))], nil
+}
+
+// MockOrderRepository implements OrderRepository for testing
+type MockOrderRepository struct {
+	orders map[string]*domain.Order
+	err    error
+	calls  map[string]int
+	// limits records the limit of each List and ListByUsers call
+	limits []int
+}
+
+func newMockOrders() *MockOrderRepository {
+	return &MockOrderRepository{orders: make(map[string]*domain.Order), calls: make(map[string]int)}
+}
+
+func (m *MockOrderRepository) Save(ctx context.Context, o *domain.Order) error {
+	m.calls["Save"]++
+	if m.err != nil {
+		return m.err
+	}
+	m.orders[o.ID] = o
+	return nil
+}
+
+func (m *MockOrderRepository) FindByID(ctx context.Context, id string) (*domain.Order, error) {
+	m.calls["FindByID"]++
+	if m.err != nil {
+		return nil, m.err
+	}
+	o, ok := m.orders[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return o, nil
+}
+
+func (m *MockOrderRepository) List(ctx context.Context, status domain.OrderStatus, limit int) ([]*domain.Order, error) {
+	m.calls["List"]++
+	m.limits = append(m.limits, limit)
+	if m.err != nil {
+		return nil, m.err
+	}
+	var orders []*domain.Order
+	for _, o := range m.newest() {
+		if (status == "" || o.Status == status) && len(orders) < limit {
+			orders = append(orders, o)
+		}
+	}
+	return orders, nil
+}
+
+func (m *MockOrderRepository) ListByUsers(ctx context.Context, userIDs []string, limit int) ([]*domain.Order, error) {
+	m.calls["ListByUsers"]++
+	m.limits = append(m.limits, limit)
+	if m.err != nil {
+		return nil, m.err
+	}
+	perUser := make(map[string]int)
+	var orders []*domain.Order
+	for _, o := range m.newest() {
+		if slices.Contains(userIDs, o.UserID) && perUser[o.UserID] < limit {
+			perUser[o.UserID]++
+			orders = append(orders, o)
+		}
+	}
+	return orders, nil
+}
+
+func (m *MockOrderRepository) newest() []*domain.Order {
+	var orders []*domain.Order
+	for _, o := range m.orders {
+		orders = append(orders, o)
+	}
+	slices.SortFunc(orders, func(a, b *domain.Order) int { return strings.Compare(b.ID, a.ID) })
+	return orders
+}
+
+// sequentialIDs replaces the clock and ID generator of a service with a
+// fixed time and IDs that sort in creation order
+func sequentialIDs(prefix string) (func() time.Time, func() string) {
+	next := 0
+	return func() time.Time { return time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC) },
+		func() string {
+			next++
+			return fmt.Sprintf("%s-%03d", prefix, next)
+		}
+}
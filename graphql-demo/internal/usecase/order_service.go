@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gostratum/examples/graphql-demo/internal/domain"
+)
+
+// OrderService handles order business logic
+type OrderService struct {
+	orders OrderRepository
+	users  UserRepository
+	now    func() time.Time
+	newID  func() string
+}
+
+// NewOrderService creates a new order service with repository injection
+func NewOrderService(orders OrderRepository, users UserRepository) *OrderService {
+	return &OrderService{orders: orders, users: users, now: time.Now, newID: newID}
+}
+
+// Place places an order for an existing user
+func (s *OrderService) Place(ctx context.Context, userID string, items []domain.Item, currency string) (*domain.Order, error) {
+	o, err := domain.NewOrder(s.newID(), userID, items, currency, s.now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.users.FindByID(ctx, userID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("%w: user %s does not exist", ErrInvalid, userID)
+		}
+		return nil, translateError(err)
+	}
+	if err := s.orders.Save(ctx, o); err != nil {
+		return nil, translateError(err)
+	}
+	return o, nil
+}
+
+// Get retrieves an order by ID
+func (s *OrderService) Get(ctx context.Context, id string) (*domain.Order, error) {
+	o, err := s.orders.FindByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return o, nil
+}
+
+// List returns up to n orders with status, newest first. An empty status
+// matches every order; n 0 means the default of 20.
+func (s *OrderService) List(ctx context.Context, status domain.OrderStatus, n int) ([]*domain.Order, error) {
+	n, err := limit(n)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := s.orders.List(ctx, status, n)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return orders, nil
+}
+
+// ListByUsers returns up to n orders of each of userIDs in one repository
+// call, newest first, by user ID. Users without orders are missing from the map.
+func (s *OrderService) ListByUsers(ctx context.Context, userIDs []string, n int) (map[string][]*domain.Order, error) {
+	n, err := limit(n)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := s.orders.ListByUsers(ctx, userIDs, n)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	byUser := make(map[string][]*domain.Order, len(userIDs))
+	for _, o := range orders {
+		byUser[o.UserID] = append(byUser[o.UserID], o)
+	}
+	return byUser, nil
+}
+
+// Cancel cancels a placed order
+func (s *OrderService) Cancel(ctx context.Context, id string) (*domain.Order, error) {
+	o, err := s.orders.FindByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	if err := o.Cancel(); err != nil {
+		return nil, err
+	}
+	if err := s.orders.Save(ctx, o); err != nil {
+		return nil, translateError(err)
+	}
+	return o, nil
+}
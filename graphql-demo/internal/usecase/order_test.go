@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/graphql-demo/internal/domain"
+)
+
+var widget = []domain.Item{{SKU: "WIDGET-1", Quantity: 1, UnitPrice: 1999}}
+
+func newTestOrderService(t *testing.T, users ...string) (*OrderService, *MockOrderRepository) {
+	t.Helper()
+	userRepo := newMockUsers()
+	for _, id := range users {
+		userRepo.users[id] = &domain.User{ID: id, Name: id, Email: id + "@example.com"}
+	}
+	repo := newMockOrders()
+	svc := NewOrderService(repo, userRepo)
+	svc.now, svc.newID = sequentialIDs("o")
+	return svc, repo
+}
+
+func TestOrderService_Place(t *testing.T) {
+	svc, repo := newTestOrderService(t, "u-1")
+	ctx := context.Background()
+
+	o, err := svc.Place(ctx, "u-1", widget, "usd")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusPlaced, o.Status)
+	assert.Equal(t, o, repo.orders["o-001"])
+
+	_, err = svc.Place(ctx, "missing", widget, "USD")
+	assert.ErrorIs(t, err, ErrInvalid, "an unknown user is bad input, not a missing order")
+	_, err = svc.Place(ctx, "u-1", nil, "USD")
+	assert.ErrorIs(t, err, ErrInvalid)
+	assert.Len(t, repo.orders, 1)
+}
+
+func TestOrderService_Cancel(t *testing.T) {
+	svc, repo := newTestOrderService(t, "u-1")
+	ctx := context.Background()
+	o, err := svc.Place(ctx, "u-1", widget, "USD")
+	require.NoError(t, err)
+
+	cancelled, err := svc.Cancel(ctx, o.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusCancelled, cancelled.Status)
+	assert.Equal(t, domain.StatusCancelled, repo.orders[o.ID].Status)
+
+	_, err = svc.Cancel(ctx, o.ID)
+	assert.ErrorIs(t, err, ErrConflict)
+	_, err = svc.Cancel(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestOrderService_ListByUsers(t *testing.T) {
+	svc, repo := newTestOrderService(t, "u-1", "u-2", "u-3")
+	ctx := context.Background()
+	for _, user := range []string{"u-1", "u-2", "u-1", "u-1"} {
+		_, err := svc.Place(ctx, user, widget, "USD")
+		require.NoError(t, err)
+	}
+
+	byUser, err := svc.ListByUsers(ctx, []string{"u-1", "u-2", "u-3"}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo.calls["ListByUsers"], "one repository call for all users")
+
+	var ids []string
+	for _, o := range byUser["u-1"] {
+		ids = append(ids, o.ID)
+	}
+	assert.Equal(t, []string{"o-004", "o-003"}, ids, "newest first, up to the limit")
+	assert.Len(t, byUser["u-2"], 1)
+	assert.NotContains(t, byUser, "u-3")
+}
+
+func TestOrderService_ListLimits(t *testing.T) {
+	svc, repo := newTestOrderService(t)
+	ctx := context.Background()
+
+	for _, n := range []int{0, 5, 1000} {
+		_, err := svc.List(ctx, "", n)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []int{defaultLimit, 5, maxLimit}, repo.limits)
+
+	_, err := svc.ListByUsers(ctx, []string{"u-1"}, -1)
+	assert.ErrorIs(t, err, ErrInvalid)
+}
@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/gostratum/examples/graphql-demo/internal/domain"
+)
+
+// UserRepository defines the interface for user data operations
+// This interface is owned by the use case layer (dependency inversion principle)
+type UserRepository interface {
+	Save(ctx context.Context, u *domain.User) error
+	FindByID(ctx context.Context, id string) (*domain.User, error)
+	// FindByIDs returns the users among ids that exist, in no particular order.
+	// It is one query however many IDs there are.
+	FindByIDs(ctx context.Context, ids []string) ([]*domain.User, error)
+	// List returns up to limit users, oldest first
+	List(ctx context.Context, limit int) ([]*domain.User, error)
+}
+
+// OrderRepository defines the interface for order data operations
+// This interface is owned by the use case layer (dependency inversion principle)
+type OrderRepository interface {
+	Save(ctx context.Context, o *domain.Order) error
+	FindByID(ctx context.Context, id string) (*domain.Order, error)
+	// List returns up to limit orders, newest first; an empty status matches
+	// every order
+	List(ctx context.Context, status domain.OrderStatus, limit int) ([]*domain.Order, error)
+	// ListByUsers returns up to limit orders of each of userIDs, newest first.
+	// It is one query however many users there are.
+	ListByUsers(ctx context.Context, userIDs []string, limit int) ([]*domain.Order, error)
+}
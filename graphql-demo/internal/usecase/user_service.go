@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gostratum/examples/graphql-demo/internal/domain"
+)
+
+// UserService handles user business logic
+type UserService struct {
+	repo  UserRepository
+	now   func() time.Time
+	newID func() string
+}
+
+// NewUserService creates a new user service with repository injection
+func NewUserService(repo UserRepository) *UserService {
+	return &UserService{repo: repo, now: time.Now, newID: newID}
+}
+
+// Create creates a new user
+func (s *UserService) Create(ctx context.Context, name, email string) (*domain.User, error) {
+	u, err := domain.NewUser(s.newID(), name, email, s.now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, u); err != nil {
+		return nil, translateError(err)
+	}
+	return u, nil
+}
+
+// Get retrieves a user by ID
+func (s *UserService) Get(ctx context.Context, id string) (*domain.User, error) {
+	u, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return u, nil
+}
+
+// GetMany retrieves the users with ids in one repository call, by ID. IDs
+// without a user are missing from the map.
+func (s *UserService) GetMany(ctx context.Context, ids []string) (map[string]*domain.User, error) {
+	users, err := s.repo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	byID := make(map[string]*domain.User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+	return byID, nil
+}
+
+// List returns up to n users, oldest first; n 0 means the default of 20
+func (s *UserService) List(ctx context.Context, n int) ([]*domain.User, error) {
+	n, err := limit(n)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.repo.List(ctx, n)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return users, nil
+}
+
+// newID returns a version 7 UUID, which sorts by creation time
+func newID() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// translateError converts repository errors to usecase errors
+func translateError(err error) error {
+	for _, passthrough := range []error{domain.ErrNotFound, domain.ErrInvalidInput, domain.ErrConflict, context.DeadlineExceeded, context.Canceled} {
+		if errors.Is(err, passthrough) {
+			return err
+		}
+	}
+
+	// All other errors are infrastructure/availability issues
+	return fmt.Errorf("%w: %v", ErrUnavailable, err)
+}
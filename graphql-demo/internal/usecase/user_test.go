@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUserService() (*UserService, *MockUserRepository) {
+	repo := newMockUsers()
+	svc := NewUserService(repo)
+	svc.now, svc.newID = sequentialIDs("u")
+	return svc, repo
+}
+
+func TestUserService_Create(t *testing.T) {
+	svc, repo := newTestUserService()
+
+	u, err := svc.Create(context.Background(), "Ada", "Ada@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "u-001", u.ID)
+	assert.Equal(t, "ada@example.com", u.Email)
+	assert.Equal(t, u, repo.users["u-001"])
+
+	_, err = svc.Create(context.Background(), "Ada", "not an email")
+	assert.ErrorIs(t, err, ErrInvalid)
+	assert.Equal(t, 1, repo.calls["Save"], "invalid users are not saved")
+}
+
+func TestUserService_GetMany(t *testing.T) {
+	svc, repo := newTestUserService()
+	ctx := context.Background()
+	for _, name := range []string{"ada", "grace"} {
+		_, err := svc.Create(ctx, name, name+"@example.com")
+		require.NoError(t, err)
+	}
+
+	users, err := svc.GetMany(ctx, []string{"u-002", "u-001", "missing"})
+	require.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.Equal(t, "grace", users["u-002"].Name)
+	assert.NotContains(t, users, "missing")
+	assert.Equal(t, 1, repo.calls["FindByIDs"], "one repository call for all IDs")
+}
+
+func TestUserService_Errors(t *testing.T) {
+	svc, repo := newTestUserService()
+	ctx := context.Background()
+
+	_, err := svc.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.List(ctx, -1)
+	assert.ErrorIs(t, err, ErrInvalid)
+
+	repo.err = errors.New("connection refused")
+	_, err = svc.Get(ctx, "u-001")
+	assert.ErrorIs(t, err, ErrUnavailable)
+	_, err = svc.GetMany(ctx, []string{"u-001"})
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
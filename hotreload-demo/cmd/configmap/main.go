@@ -0,0 +1,45 @@
+// Command configmap copies config files into a directory the way the kubelet
+// updates a mounted ConfigMap, for trying out reload.mode: kubernetes without
+// a cluster. Run it once to create the volume, and again after an edit, as
+// kubectl apply would:
+//
+//	go run ./cmd/configmap -dir /tmp/hotreload-config configs/base.yaml
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/gostratum/examples/hotreload-demo/internal/configmap"
+)
+
+func main() {
+	dir := flag.String("dir", "/tmp/hotreload-config", "directory of the volume")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: configmap [-dir dir] file...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	files := make(map[string][]byte, flag.NArg())
+	for _, name := range flag.Args() {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			log.Fatalf("❌ Failed to read %s: %v", name, err)
+		}
+		files[filepath.Base(name)] = data
+	}
+
+	version, err := configmap.Write(*dir, files)
+	if err != nil {
+		log.Fatalf("❌ Failed to write %s: %v", *dir, err)
+	}
+	fmt.Printf("✅ %s is at version %s\n", *dir, version)
+}
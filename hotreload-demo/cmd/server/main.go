@@ -0,0 +1,50 @@
+package main
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	httpAdapter "github.com/gostratum/examples/hotreload-demo/internal/adapter/http"
+	"github.com/gostratum/examples/hotreload-demo/internal/adapter/memory"
+	"github.com/gostratum/examples/hotreload-demo/internal/live"
+	"github.com/gostratum/examples/hotreload-demo/internal/settings"
+	"github.com/gostratum/examples/hotreload-demo/internal/usecase"
+	"github.com/gostratum/examples/redact"
+	"github.com/gostratum/httpx"
+)
+
+func main() {
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// HTTP server
+		httpx.Module(),
+
+		// Watches the config files and reloads the live settings when they change
+		live.Module(),
+
+		// Log level, rate limiter and feature toggles from the live section
+		settings.Module(),
+
+		// Provide dependencies
+		fx.Provide(
+			// In-memory quotes
+			memory.NewQuotes,
+
+			// Usecase services
+			usecase.NewQuoteService,
+
+			// HTTP handlers
+			httpAdapter.NewQuoteHandler,
+			httpAdapter.NewAdminHandler,
+		),
+
+		// Invoke setup functions
+		fx.Invoke(
+			httpAdapter.RegisterRoutes,
+		),
+	)
+
+	app.Run()
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/hotreload-demo/internal/live"
+	"github.com/gostratum/examples/hotreload-demo/internal/settings"
+)
+
+// Reloader reloads the config files; implemented by live.Watcher
+type Reloader interface {
+	Reload() live.Result
+	Last() live.Result
+}
+
+// ConfigSource returns the config section a live value was built from;
+// implemented by live.Value
+type ConfigSource[C any] interface {
+	Config() C
+}
+
+// AdminHandler shows the live settings in effect and reloads them on demand.
+// In production it belongs behind authentication, or on an internal listener.
+type AdminHandler struct {
+	reloader  Reloader
+	logLevel  ConfigSource[settings.LogConfig]
+	rateLimit ConfigSource[settings.RateLimitConfig]
+	features  ConfigSource[settings.Features]
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(
+	w *live.Watcher,
+	logLevel *live.Value[settings.LogConfig, settings.Level],
+	rateLimit *live.Value[settings.RateLimitConfig, settings.RateLimiter],
+	features *live.Value[settings.Features, settings.Features],
+) *AdminHandler {
+	return newAdminHandler(w, logLevel, rateLimit, features)
+}
+
+func newAdminHandler(
+	reloader Reloader,
+	logLevel ConfigSource[settings.LogConfig],
+	rateLimit ConfigSource[settings.RateLimitConfig],
+	features ConfigSource[settings.Features],
+) *AdminHandler {
+	return &AdminHandler{reloader: reloader, logLevel: logLevel, rateLimit: rateLimit, features: features}
+}
+
+// Config handles GET /admin/config
+func (h *AdminHandler) Config(c *gin.Context) {
+	responsex.OK(c, h.snapshot(h.reloader.Last()), nil)
+}
+
+// Reload handles POST /admin/reload, for when file events do not arrive, as on
+// some network and container file systems. An invalid section keeps its
+// current values and is listed under last_reload.failed.
+func (h *AdminHandler) Reload(c *gin.Context) {
+	responsex.OK(c, h.snapshot(h.reloader.Reload()), nil)
+}
+
+func (h *AdminHandler) snapshot(last live.Result) ConfigResponse {
+	resp := FromSettings(h.logLevel.Config(), h.rateLimit.Config(), h.features.Config())
+	if !last.At.IsZero() {
+		resp.LastReload = &last
+	}
+	return resp
+}
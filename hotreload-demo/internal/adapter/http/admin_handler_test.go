@@ -0,0 +1,97 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/httpx/responsex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/hotreload-demo/internal/live"
+	"github.com/gostratum/examples/hotreload-demo/internal/settings"
+)
+
+// fakeReloader returns a fixed reload result and counts reloads
+type fakeReloader struct {
+	result  live.Result
+	reloads int
+}
+
+func (f *fakeReloader) Reload() live.Result {
+	f.reloads++
+	return f.result
+}
+
+func (f *fakeReloader) Last() live.Result {
+	if f.reloads == 0 {
+		return live.Result{}
+	}
+	return f.result
+}
+
+// section is a ConfigSource of a fixed section
+type section[C any] struct {
+	cfg C
+}
+
+func (s section[C]) Config() C {
+	return s.cfg
+}
+
+func setupAdminRouter(reloader Reloader) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	handler := newAdminHandler(
+		reloader,
+		section[settings.LogConfig]{cfg: settings.LogConfig{Level: "debug"}},
+		section[settings.RateLimitConfig]{cfg: settings.RateLimitConfig{Enabled: true, RequestsPerSecond: 5, Burst: 10}},
+		section[settings.Features]{cfg: settings.Features{RandomQuote: true}},
+	)
+
+	e := gin.New()
+	e.GET("/admin/config", handler.Config)
+	e.POST("/admin/reload", handler.Reload)
+	return e
+}
+
+func TestAdminConfig(t *testing.T) {
+	reloader := &fakeReloader{}
+	e := setupAdminRouter(reloader)
+
+	w := get(e, "/admin/config")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var env responsex.Envelope[ConfigResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+	assert.Equal(t, "debug", env.Data.Log.Level)
+	assert.Equal(t, 10, env.Data.RateLimit.Burst)
+	assert.True(t, env.Data.Features.RandomQuote)
+	assert.Nil(t, env.Data.LastReload, "nothing has been reloaded yet")
+	assert.Zero(t, reloader.reloads)
+}
+
+func TestAdminReload(t *testing.T) {
+	reloader := &fakeReloader{result: live.Result{
+		At:      time.Now(),
+		Trigger: "manual",
+		Changed: []string{"live.rate_limit"},
+		Failed:  map[string]string{"live.log": `invalid live.log config: unknown level "loud"`},
+	}}
+	e := setupAdminRouter(reloader)
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/reload", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, reloader.reloads)
+
+	var env responsex.Envelope[ConfigResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+	require.NotNil(t, env.Data.LastReload)
+	assert.Equal(t, []string{"live.rate_limit"}, env.Data.LastReload.Changed)
+	assert.Contains(t, env.Data.LastReload.Failed["live.log"], "unknown level")
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"github.com/gostratum/examples/hotreload-demo/internal/domain"
+	"github.com/gostratum/examples/hotreload-demo/internal/live"
+	"github.com/gostratum/examples/hotreload-demo/internal/settings"
+)
+
+// QuoteResponse is the HTTP DTO for a quote
+type QuoteResponse struct {
+	ID   int    `json:"id"`
+	Text string `json:"text"`
+	// Set only while the show_authors feature is on
+	Author string `json:"author,omitempty"`
+}
+
+// FromQuote converts a domain.Quote to QuoteResponse DTO, with its author when
+// showAuthor is set
+func FromQuote(q domain.Quote, showAuthor bool) QuoteResponse {
+	resp := QuoteResponse{ID: q.ID, Text: q.Text}
+	if showAuthor {
+		resp.Author = q.Author
+	}
+	return resp
+}
+
+// ConfigResponse is the HTTP DTO for the live settings in effect
+type ConfigResponse struct {
+	Log        LogResponse       `json:"log"`
+	RateLimit  RateLimitResponse `json:"rate_limit"`
+	Features   FeaturesResponse  `json:"features"`
+	LastReload *live.Result      `json:"last_reload,omitempty"`
+}
+
+// LogResponse is the HTTP DTO for the live.log section
+type LogResponse struct {
+	Level string `json:"level"`
+}
+
+// RateLimitResponse is the HTTP DTO for the live.rate_limit section
+type RateLimitResponse struct {
+	Enabled           bool    `json:"enabled"`
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	Burst             int     `json:"burst"`
+}
+
+// FeaturesResponse is the HTTP DTO for the live.features section
+type FeaturesResponse struct {
+	Maintenance        bool   `json:"maintenance"`
+	MaintenanceMessage string `json:"maintenance_message"`
+	RandomQuote        bool   `json:"random_quote"`
+	ShowAuthors        bool   `json:"show_authors"`
+}
+
+// FromSettings converts the live sections to ConfigResponse DTO
+func FromSettings(log settings.LogConfig, limit settings.RateLimitConfig, features settings.Features) ConfigResponse {
+	return ConfigResponse{
+		Log: LogResponse{Level: log.Level},
+		RateLimit: RateLimitResponse{
+			Enabled:           limit.Enabled,
+			RequestsPerSecond: limit.RequestsPerSecond,
+			Burst:             limit.Burst,
+		},
+		Features: FeaturesResponse{
+			Maintenance:        features.Maintenance,
+			MaintenanceMessage: features.MaintenanceMessage,
+			RandomQuote:        features.RandomQuote,
+			ShowAuthors:        features.ShowAuthors,
+		},
+	}
+}
@@ -0,0 +1,75 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/hotreload-demo/internal/settings"
+)
+
+// FeatureSource returns the feature toggles in effect; implemented by the
+// live.Value provided by settings.Module
+type FeatureSource interface {
+	Load() *settings.Features
+}
+
+// LimiterSource returns the rate limiter in effect; implemented by the
+// live.Value provided by settings.Module
+type LimiterSource interface {
+	Load() *settings.RateLimiter
+}
+
+// DebugLogger writes debug lines; implemented by settings.Logger
+type DebugLogger interface {
+	Debug(msg string, fields ...logx.Field)
+}
+
+// Maintenance answers every request with 503 while the maintenance feature is on
+func Maintenance(features FeatureSource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if f := features.Load(); f.Maintenance {
+			c.Header("Retry-After", "60")
+			responsex.Error(c, http.StatusServiceUnavailable, "MAINTENANCE", f.MaintenanceMessage, nil)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RateLimit answers 429 to clients over their limit. Each request loads the
+// limiter once, so a reload in the middle of it changes nothing for it.
+func RateLimit(limiters LimiterSource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limiter := limiters.Load()
+		if !limiter.Allow(c.ClientIP()) {
+			seconds := math.Ceil(limiter.RetryAfter().Seconds())
+			c.Header("Retry-After", strconv.Itoa(int(math.Max(seconds, 1))))
+			responsex.Error(c, http.StatusTooManyRequests, "RATE_LIMITED", "too many requests", nil)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequestLog writes a debug line per request, so switching live.log.level to
+// debug shows the traffic
+func RequestLog(log DebugLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		log.Debug("request served",
+			logx.String("method", c.Request.Method),
+			logx.String("path", c.Request.URL.Path),
+			logx.Int("status", c.Writer.Status()),
+			logx.String("duration", time.Since(start).String()),
+		)
+	}
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/hotreload-demo/internal/settings"
+)
+
+// fixed is a live source that returns the same object until it is swapped
+type fixed[T any] struct {
+	v *T
+}
+
+func (f *fixed[T]) Load() *T {
+	return f.v
+}
+
+// debugLines records debug lines
+type debugLines struct {
+	msgs []string
+}
+
+func (d *debugLines) Debug(msg string, fields ...logx.Field) {
+	d.msgs = append(d.msgs, msg)
+}
+
+func get(e *gin.Engine, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestMaintenance(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	features := &fixed[settings.Features]{v: &settings.Features{}}
+
+	e := gin.New()
+	e.GET("/quotes", Maintenance(features), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	assert.Equal(t, http.StatusNoContent, get(e, "/quotes").Code)
+
+	// Swapping the toggles applies to the next request
+	features.v = &settings.Features{Maintenance: true, MaintenanceMessage: "back at noon"}
+	w := get(e, "/quotes")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "MAINTENANCE")
+	assert.Contains(t, w.Body.String(), "back at noon")
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+}
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter, err := settings.NewRateLimiter(settings.RateLimitConfig{Enabled: true, RequestsPerSecond: 0.5, Burst: 2})
+	require.NoError(t, err)
+	limiters := &fixed[settings.RateLimiter]{v: limiter}
+
+	e := gin.New()
+	e.GET("/quotes", RateLimit(limiters), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	assert.Equal(t, http.StatusNoContent, get(e, "/quotes").Code)
+	assert.Equal(t, http.StatusNoContent, get(e, "/quotes").Code)
+	w := get(e, "/quotes")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), "RATE_LIMITED")
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+
+	// A new limiter from a reload starts with full buckets
+	limiters.v, err = settings.NewRateLimiter(settings.RateLimitConfig{Enabled: true, RequestsPerSecond: 0.5, Burst: 5})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, get(e, "/quotes").Code)
+}
+
+func TestRequestLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	lines := &debugLines{}
+
+	e := gin.New()
+	e.GET("/quotes", RequestLog(lines), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	get(e, "/quotes")
+
+	assert.Equal(t, []string{"request served"}, lines.msgs)
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/hotreload-demo/internal/domain"
+	"github.com/gostratum/examples/hotreload-demo/internal/live"
+	"github.com/gostratum/examples/hotreload-demo/internal/settings"
+	"github.com/gostratum/examples/hotreload-demo/internal/usecase"
+)
+
+// Quoter serves quotes; implemented by usecase.QuoteService
+type Quoter interface {
+	List(ctx context.Context) ([]domain.Quote, error)
+	Random(ctx context.Context) (domain.Quote, error)
+}
+
+// QuoteHandler serves the quote endpoints. Which of them are on and whether
+// authors are shown are live feature toggles.
+type QuoteHandler struct {
+	quotes   Quoter
+	features FeatureSource
+	log      logx.Logger
+}
+
+// NewQuoteHandler creates a new quote handler
+func NewQuoteHandler(quotes *usecase.QuoteService, features *live.Value[settings.Features, settings.Features], log logx.Logger) *QuoteHandler {
+	return newQuoteHandler(quotes, features, log)
+}
+
+func newQuoteHandler(quotes Quoter, features FeatureSource, log logx.Logger) *QuoteHandler {
+	return &QuoteHandler{quotes: quotes, features: features, log: log}
+}
+
+// List handles GET /quotes
+func (h *QuoteHandler) List(c *gin.Context) {
+	features := h.features.Load()
+
+	quotes, err := h.quotes.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	resp := make([]QuoteResponse, len(quotes))
+	for i, q := range quotes {
+		resp[i] = FromQuote(q, features.ShowAuthors)
+	}
+	responsex.OK(c, resp, nil)
+}
+
+// Random handles GET /quotes/random, which exists only while the random_quote
+// feature is on
+func (h *QuoteHandler) Random(c *gin.Context) {
+	features := h.features.Load()
+	if !features.RandomQuote {
+		responsex.Error(c, http.StatusNotFound, "NOT_FOUND", "not found", nil)
+		return
+	}
+
+	q, err := h.quotes.Random(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	responsex.OK(c, FromQuote(q, features.ShowAuthors), nil)
+}
+
+// handleError maps usecase errors to HTTP responses
+func (h *QuoteHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrNotFound):
+		responsex.Error(c, http.StatusNotFound, "NOT_FOUND", err.Error(), nil)
+	case errors.Is(err, usecase.ErrUnavailable):
+		h.log.Error("quotes unavailable", logx.Err(err))
+		responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "quotes are unavailable", nil)
+	default:
+		h.log.Error("quote request failed", logx.Err(err))
+		responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+	}
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/hotreload-demo/internal/domain"
+	"github.com/gostratum/examples/hotreload-demo/internal/settings"
+	"github.com/gostratum/examples/hotreload-demo/internal/usecase"
+)
+
+// fakeQuoter returns one quote or an error
+type fakeQuoter struct {
+	err error
+}
+
+func (f *fakeQuoter) List(ctx context.Context) ([]domain.Quote, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []domain.Quote{{ID: 3, Text: "Clear is better than clever.", Author: "Rob Pike"}}, nil
+}
+
+func (f *fakeQuoter) Random(ctx context.Context) (domain.Quote, error) {
+	quotes, err := f.List(ctx)
+	if err != nil {
+		return domain.Quote{}, err
+	}
+	return quotes[0], nil
+}
+
+func setupRouter(quotes Quoter, features FeatureSource) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	handler := newQuoteHandler(quotes, features, logx.NewNoopLogger())
+
+	e := gin.New()
+	e.GET("/quotes", handler.List)
+	e.GET("/quotes/random", handler.Random)
+	return e
+}
+
+func TestListFollowsShowAuthors(t *testing.T) {
+	features := &fixed[settings.Features]{v: &settings.Features{ShowAuthors: true}}
+	e := setupRouter(&fakeQuoter{}, features)
+
+	var env responsex.Envelope[[]QuoteResponse]
+	w := get(e, "/quotes")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+	assert.Equal(t, "Rob Pike", env.Data[0].Author)
+
+	features.v = &settings.Features{}
+	w = get(e, "/quotes")
+	assert.NotContains(t, w.Body.String(), "author")
+}
+
+func TestRandomFollowsToggle(t *testing.T) {
+	features := &fixed[settings.Features]{v: &settings.Features{}}
+	e := setupRouter(&fakeQuoter{}, features)
+
+	assert.Equal(t, http.StatusNotFound, get(e, "/quotes/random").Code)
+
+	features.v = &settings.Features{RandomQuote: true}
+	w := get(e, "/quotes/random")
+	require.Equal(t, http.StatusOK, w.Code)
+	var env responsex.Envelope[QuoteResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+	assert.Equal(t, 3, env.Data.ID)
+}
+
+func TestQuoteErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "not found", err: usecase.ErrNotFound, wantStatus: http.StatusNotFound, wantCode: "NOT_FOUND"},
+		{name: "unavailable", err: usecase.ErrUnavailable, wantStatus: http.StatusServiceUnavailable, wantCode: "SERVICE_UNAVAILABLE"},
+		{name: "unexpected", err: errors.New("boom"), wantStatus: http.StatusInternalServerError, wantCode: "INTERNAL_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := setupRouter(&fakeQuoter{err: tt.err}, &fixed[settings.Features]{v: &settings.Features{RandomQuote: true}})
+			w := get(e, "/quotes/random")
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			var env responsex.Envelope[QuoteResponse]
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+			require.NotNil(t, env.Error)
+			assert.Equal(t, tt.wantCode, env.Error.Code)
+		})
+	}
+}
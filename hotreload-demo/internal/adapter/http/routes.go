@@ -0,0 +1,67 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/hotreload-demo/internal/live"
+	"github.com/gostratum/examples/hotreload-demo/internal/settings"
+)
+
+// RegisterRoutes registers all HTTP routes using the provided Gin engine
+// This function is designed to be used with fx.Invoke to work with httpx.Module
+func RegisterRoutes(
+	e *gin.Engine,
+	quoteHandler *QuoteHandler,
+	adminHandler *AdminHandler,
+	logger *settings.Logger,
+	features *live.Value[settings.Features, settings.Features],
+	limiter *live.Value[settings.RateLimitConfig, settings.RateLimiter],
+	reg core.Registry,
+	log logx.Logger,
+) {
+	// Add responsex middleware for request tracking and metadata
+	e.Use(responsex.MetaMiddleware("hotreload-demo/v1.0.0"))
+
+	// Quote endpoints; every middleware and handler loads the live settings per request
+	quotes := e.Group("/quotes", RequestLog(logger), Maintenance(features), RateLimit(limiter))
+	quotes.GET("", quoteHandler.List)
+	quotes.GET("/random", quoteHandler.Random)
+
+	// Admin endpoints; not rate limited, so settings can always be checked
+	e.GET("/admin/config", adminHandler.Config)
+	e.POST("/admin/reload", adminHandler.Reload)
+
+	// Health endpoints - readiness and liveness checks
+	e.GET("/healthz", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		res := reg.Aggregate(ctx, core.Readiness)
+		if res.OK {
+			c.JSON(http.StatusOK, gin.H{"ok": true, "details": res.Details})
+		} else {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"ok": false, "details": res.Details})
+		}
+	})
+
+	e.GET("/livez", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		res := reg.Aggregate(ctx, core.Liveness)
+		if res.OK {
+			c.JSON(http.StatusOK, gin.H{"ok": true, "details": res.Details})
+		} else {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"ok": false, "details": res.Details})
+		}
+	})
+
+	log.Info("HTTP routes registered")
+}
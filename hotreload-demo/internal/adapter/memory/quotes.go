@@ -0,0 +1,31 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/gostratum/examples/hotreload-demo/internal/domain"
+	"github.com/gostratum/examples/hotreload-demo/internal/usecase"
+)
+
+// Quotes is a fixed, read-only quote list. The example is about live
+// configuration, so there is no database behind it.
+type Quotes struct {
+	quotes []domain.Quote
+}
+
+// NewQuotes creates the quote list seeded with the demo quotes
+func NewQuotes() usecase.QuoteRepository {
+	return &Quotes{quotes: []domain.Quote{
+		{ID: 1, Text: "Simplicity is prerequisite for reliability.", Author: "Edsger W. Dijkstra"},
+		{ID: 2, Text: "Premature optimization is the root of all evil.", Author: "Donald Knuth"},
+		{ID: 3, Text: "Clear is better than clever.", Author: "Rob Pike"},
+		{ID: 4, Text: "Make it work, make it right, make it fast.", Author: "Kent Beck"},
+		{ID: 5, Text: "Programs must be written for people to read.", Author: "Harold Abelson"},
+		{ID: 6, Text: "The cheapest, fastest and most reliable components are those that aren't there.", Author: "Gordon Bell"},
+	}}
+}
+
+// List implements usecase.QuoteRepository
+func (q *Quotes) List(ctx context.Context) ([]domain.Quote, error) {
+	return append([]domain.Quote(nil), q.quotes...), nil
+}
@@ -0,0 +1,82 @@
+// Package configmap writes a directory the way the kubelet writes a mounted
+// ConfigMap or Secret, so the kubernetes reload mode can be tried and tested
+// off a cluster. The files of each version are in a directory of their own,
+// named after the time it was written; the ..data link points to the current
+// one, and each file is a link through ..data. An update writes a new version
+// and swaps ..data in one rename, so a reader sees the files of either
+// version, never a mix.
+package configmap
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DataLink is the link to the current version
+const DataLink = "..data"
+
+// Write makes files, names to contents, the files of the volume at dir,
+// creating it if need be, and returns the new version: the directory ..data
+// now points to. Files of the old version that are not in files are removed.
+func Write(dir string, files map[string][]byte) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	old, err := os.Readlink(filepath.Join(dir, DataLink))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	path, err := os.MkdirTemp(dir, time.Now().UTC().Format("..2006_01_02_15_04_05."))
+	if err != nil {
+		return "", err
+	}
+	if err := os.Chmod(path, 0o755); err != nil {
+		return "", err
+	}
+	version := filepath.Base(path)
+	for name, data := range files {
+		if name != filepath.Base(name) || name[0] == '.' {
+			return "", fmt.Errorf("invalid file name %q", name)
+		}
+		if err := os.WriteFile(filepath.Join(path, name), data, 0o644); err != nil {
+			return "", err
+		}
+	}
+
+	// The swap: a new link renamed over the old one
+	tmp := filepath.Join(dir, DataLink+"_tmp")
+	if err := os.Symlink(version, tmp); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, DataLink)); err != nil {
+		return "", err
+	}
+
+	for name := range files {
+		link := filepath.Join(dir, name)
+		if _, err := os.Lstat(link); errors.Is(err, os.ErrNotExist) {
+			if err := os.Symlink(filepath.Join(DataLink, name), link); err != nil {
+				return "", err
+			}
+		}
+	}
+	if old == "" {
+		return version, nil
+	}
+	previous, err := os.ReadDir(filepath.Join(dir, old))
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range previous {
+		if _, ok := files[entry.Name()]; !ok {
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return "", err
+			}
+		}
+	}
+	return version, os.RemoveAll(filepath.Join(dir, old))
+}
@@ -0,0 +1,52 @@
+package configmap
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "config")
+
+	first, err := Write(dir, map[string][]byte{"base.yaml": []byte("v: 1"), "extra.yaml": []byte("x: 1")})
+	require.NoError(t, err)
+	assert.Regexp(t, `^\.\.\d{4}_\d{2}_\d{2}_\d{2}_\d{2}_\d{2}\.\d+$`, first)
+
+	data, err := os.ReadFile(filepath.Join(dir, "base.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "v: 1", string(data))
+	link, err := os.Readlink(filepath.Join(dir, "base.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(DataLink, "base.yaml"), link, "files are links through ..data")
+
+	second, err := Write(dir, map[string][]byte{"base.yaml": []byte("v: 2")})
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+
+	data, err = os.ReadFile(filepath.Join(dir, "base.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "v: 2", string(data))
+	target, err := os.Readlink(filepath.Join(dir, DataLink))
+	require.NoError(t, err)
+	assert.Equal(t, second, target)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.ElementsMatch(t, []string{DataLink, second, "base.yaml"}, names, "the old version and its extra file are gone")
+}
+
+func TestWriteRejectsPaths(t *testing.T) {
+	_, err := Write(t.TempDir(), map[string][]byte{"../base.yaml": nil})
+	assert.ErrorContains(t, err, "invalid file name")
+
+	_, err = Write(t.TempDir(), map[string][]byte{DataLink: nil})
+	assert.ErrorContains(t, err, "invalid file name")
+}
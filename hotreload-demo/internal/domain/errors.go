@@ -0,0 +1,9 @@
+package domain
+
+import "errors"
+
+// Domain errors represent business rule violations
+var (
+	// ErrNotFound indicates the requested quote does not exist
+	ErrNotFound = errors.New("not found")
+)
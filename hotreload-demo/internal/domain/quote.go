@@ -0,0 +1,8 @@
+package domain
+
+// Quote is a quotation and who said it
+type Quote struct {
+	ID     int
+	Text   string
+	Author string
+}
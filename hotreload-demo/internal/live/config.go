@@ -0,0 +1,43 @@
+// Package live is a small fx module for configuration that changes while the
+// service runs: it watches the config files, and when they change, binds the
+// registered config sections again and swaps in the objects built from them
+package live
+
+import (
+	"fmt"
+	"time"
+)
+
+// Modes of watching
+const (
+	// ModeFiles reloads when a YAML file in a config directory is written or
+	// replaced
+	ModeFiles = "files"
+	// ModeKubernetes reloads when a config directory, a mounted ConfigMap or
+	// Secret, is updated to a new version
+	ModeKubernetes = "kubernetes"
+)
+
+// Config controls the watching of the config files. It is read once at startup.
+type Config struct {
+	// Enabled turns watching off; SIGHUP and Watcher.Reload still reload
+	Enabled bool `mapstructure:"enabled" default:"true"`
+	// Debounce waits for writes to settle, since editors and deploy tools
+	// change a file in several steps
+	Debounce time.Duration `mapstructure:"debounce" default:"250ms"`
+	// Mode is files, or kubernetes when CONFIG_PATHS are mounted ConfigMaps
+	// or Secrets
+	Mode string `mapstructure:"mode" default:"files"`
+}
+
+func (c Config) validate() error {
+	if c.Mode != ModeFiles && c.Mode != ModeKubernetes {
+		return fmt.Errorf("unknown mode %q; use %s or %s", c.Mode, ModeFiles, ModeKubernetes)
+	}
+	return nil
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "reload"
+}
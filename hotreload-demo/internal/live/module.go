@@ -0,0 +1,12 @@
+package live
+
+import "go.uber.org/fx"
+
+// Module provides the Watcher and ties it to the application lifecycle.
+// Register values with Watch.
+func Module() fx.Option {
+	return fx.Module("live",
+		fx.Provide(NewWatcher),
+		fx.Invoke(Register),
+	)
+}
@@ -0,0 +1,99 @@
+package live
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gostratum/core/configx"
+)
+
+// binder binds config sections; configx.Loader is one
+type binder interface {
+	Bind(v any) error
+}
+
+// reloadable is a value the watcher updates
+type reloadable interface {
+	section() string
+	reload(loader binder) (changed bool, err error)
+}
+
+// Value holds an object of type T built from config section C. Load returns the
+// current object. A reload builds a new object from the new section and swaps it
+// in atomically: callers that loaded the old one keep using it until they are
+// done, and never see a half-updated object.
+type Value[C configx.Configurable, T any] struct {
+	build   func(C) (*T, error)
+	current atomic.Pointer[T]
+
+	// mu serializes reloads; cfg is the section the current object was built from
+	mu  sync.Mutex
+	cfg C
+}
+
+// Watch binds section C, builds the first object from it and registers the
+// value with the watcher. It fails if the section is invalid, so a service
+// never starts with a bad config; later reloads keep the current object instead.
+func Watch[C configx.Configurable, T any](w *Watcher, loader configx.Loader, build func(C) (*T, error)) (*Value[C, T], error) {
+	v, err := newValue(loader, build)
+	if err != nil {
+		return nil, err
+	}
+	w.register(v)
+	return v, nil
+}
+
+func newValue[C configx.Configurable, T any](loader binder, build func(C) (*T, error)) (*Value[C, T], error) {
+	v := &Value[C, T]{build: build}
+	if err := loader.Bind(&v.cfg); err != nil {
+		return nil, fmt.Errorf("failed to load %s config: %w", v.cfg.Prefix(), err)
+	}
+	t, err := build(v.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s config: %w", v.cfg.Prefix(), err)
+	}
+	v.current.Store(t)
+	return v, nil
+}
+
+// Load returns the current object. Load it once per unit of work, such as a
+// request, so that the work sees one consistent version.
+func (v *Value[C, T]) Load() *T {
+	return v.current.Load()
+}
+
+// Config returns the section the current object was built from
+func (v *Value[C, T]) Config() C {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.cfg
+}
+
+func (v *Value[C, T]) section() string {
+	return v.cfg.Prefix()
+}
+
+// reload binds the section again and swaps in a new object if it changed. An
+// unchanged section keeps the current object, and with it any state it holds.
+func (v *Value[C, T]) reload(loader binder) (bool, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	var cfg C
+	if err := loader.Bind(&cfg); err != nil {
+		return false, fmt.Errorf("failed to load %s config: %w", cfg.Prefix(), err)
+	}
+	if reflect.DeepEqual(cfg, v.cfg) {
+		return false, nil
+	}
+
+	t, err := v.build(cfg)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s config: %w", cfg.Prefix(), err)
+	}
+	v.current.Store(t)
+	v.cfg = cfg
+	return true, nil
+}
@@ -0,0 +1,143 @@
+package live
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// greetingConfig is a section for the tests
+type greetingConfig struct {
+	Text  string
+	Times int
+}
+
+func (greetingConfig) Prefix() string {
+	return "greeting"
+}
+
+// greeting is the object built from greetingConfig
+type greeting struct {
+	message string
+}
+
+func newGreeting(cfg greetingConfig) (*greeting, error) {
+	if cfg.Times < 1 {
+		return nil, errors.New("times must be at least 1")
+	}
+	return &greeting{message: fmt.Sprintf("%s x%d", cfg.Text, cfg.Times)}, nil
+}
+
+// fakeLoader binds sections from a map keyed by prefix
+type fakeLoader struct {
+	mu       sync.Mutex
+	sections map[string]any
+	err      error
+}
+
+func (l *fakeLoader) Bind(v any) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return l.err
+	}
+	prefix := v.(interface{ Prefix() string }).Prefix()
+	if s, ok := l.sections[prefix]; ok {
+		reflect.ValueOf(v).Elem().Set(reflect.ValueOf(s))
+	}
+	return nil
+}
+
+func (l *fakeLoader) set(section any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sections[section.(interface{ Prefix() string }).Prefix()] = section
+}
+
+func newFakeLoader(sections ...any) *fakeLoader {
+	l := &fakeLoader{sections: map[string]any{}}
+	for _, s := range sections {
+		l.set(s)
+	}
+	return l
+}
+
+func TestNewValueFailsOnInvalidSection(t *testing.T) {
+	_, err := newValue(newFakeLoader(greetingConfig{Text: "hi"}), newGreeting)
+	assert.ErrorContains(t, err, "invalid greeting config: times must be at least 1")
+
+	_, err = newValue(&fakeLoader{err: errors.New("yaml: line 3")}, newGreeting)
+	assert.ErrorContains(t, err, "failed to load greeting config")
+}
+
+func TestValueReload(t *testing.T) {
+	loader := newFakeLoader(greetingConfig{Text: "hi", Times: 1})
+	v, err := newValue(loader, newGreeting)
+	require.NoError(t, err)
+	first := v.Load()
+	assert.Equal(t, "hi x1", first.message)
+
+	// An unchanged section keeps the object
+	changed, err := v.reload(loader)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Same(t, first, v.Load())
+
+	loader.set(greetingConfig{Text: "hello", Times: 2})
+	changed, err = v.reload(loader)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "hello x2", v.Load().message)
+	assert.Equal(t, greetingConfig{Text: "hello", Times: 2}, v.Config())
+
+	// A caller holding the old object still has it, unchanged
+	assert.Equal(t, "hi x1", first.message)
+}
+
+func TestValueKeepsObjectOnInvalidReload(t *testing.T) {
+	loader := newFakeLoader(greetingConfig{Text: "hi", Times: 1})
+	v, err := newValue(loader, newGreeting)
+	require.NoError(t, err)
+	current := v.Load()
+
+	loader.set(greetingConfig{Text: "hi", Times: 0})
+	changed, err := v.reload(loader)
+	assert.ErrorContains(t, err, "invalid greeting config")
+	assert.False(t, changed)
+	assert.Same(t, current, v.Load())
+	assert.Equal(t, 1, v.Config().Times)
+
+	loader.err = errors.New("yaml: line 3")
+	_, err = v.reload(loader)
+	assert.ErrorContains(t, err, "failed to load greeting config")
+	assert.Same(t, current, v.Load())
+}
+
+func TestValueConcurrentLoads(t *testing.T) {
+	loader := newFakeLoader(greetingConfig{Text: "hi", Times: 1})
+	v, err := newValue(loader, newGreeting)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				assert.NotEmpty(t, v.Load().message)
+			}
+		}()
+	}
+	for i := 2; i < 50; i++ {
+		loader.set(greetingConfig{Text: "hi", Times: i})
+		_, err := v.reload(loader)
+		require.NoError(t, err)
+	}
+	wg.Wait()
+	assert.Equal(t, "hi x49", v.Load().message)
+}
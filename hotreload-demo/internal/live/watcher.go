@@ -0,0 +1,335 @@
+package live
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"go.uber.org/fx"
+)
+
+// Params are the dependencies of NewWatcher
+type Params struct {
+	fx.In
+
+	Loader configx.Loader
+	Log    logx.Logger
+}
+
+// dataLink is the link the kubelet swaps to update a mounted ConfigMap or
+// Secret; every file of the volume is a link through it
+const dataLink = "..data"
+
+// Result reports one reload
+type Result struct {
+	At      time.Time `json:"at"`
+	Trigger string    `json:"trigger"`
+	// Changed lists the sections whose objects were swapped
+	Changed []string `json:"changed"`
+	// Failed maps sections that kept their objects to the reason
+	Failed map[string]string `json:"failed,omitempty"`
+	// Versions maps each config directory to the version of the ConfigMap or
+	// Secret read, in kubernetes mode
+	Versions map[string]string `json:"versions,omitempty"`
+}
+
+// Watcher reloads the registered values when the config files change, when the
+// process gets SIGHUP, or when Reload is called
+type Watcher struct {
+	cfg       Config
+	paths     []string
+	newLoader func() binder
+	log       logx.Logger
+
+	// mu serializes reloads and guards values, last and versions
+	mu     sync.Mutex
+	values []reloadable
+	last   Result
+	// versions are those of the volumes the last reload read, or the service
+	// started with, in kubernetes mode
+	versions map[string]string
+
+	fsw     *fsnotify.Watcher
+	signals chan os.Signal
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewWatcher creates a watcher for the directories in CONFIG_PATHS, the same
+// ones the service loaded its config from
+func NewWatcher(p Params) (*Watcher, error) {
+	var cfg Config
+	if err := p.Loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load reload config: %w", err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid reload config: %w", err)
+	}
+
+	paths := configPaths()
+	return newWatcher(cfg, paths, func() binder {
+		return configx.New(configx.WithConfigPaths(paths...))
+	}, p.Log), nil
+}
+
+func newWatcher(cfg Config, paths []string, newLoader func() binder, log logx.Logger) *Watcher {
+	return &Watcher{
+		cfg:       cfg,
+		paths:     paths,
+		newLoader: newLoader,
+		log:       log,
+		signals:   make(chan os.Signal, 1),
+		done:      make(chan struct{}),
+	}
+}
+
+// configPaths returns the comma-separated directories in CONFIG_PATHS, or
+// ./configs when it is not set
+func configPaths() []string {
+	var paths []string
+	for _, p := range strings.Split(os.Getenv("CONFIG_PATHS"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	if len(paths) == 0 {
+		paths = []string{"./configs"}
+	}
+	return paths
+}
+
+// Register ties the watcher to the application lifecycle.
+// This function is designed to be used with fx.Invoke.
+func Register(lc fx.Lifecycle, w *Watcher) {
+	lc.Append(fx.Hook{
+		OnStart: w.start,
+		OnStop:  w.stop,
+	})
+}
+
+// start watches the config directories and SIGHUP. Directories are watched
+// rather than files, since editors and Kubernetes ConfigMaps replace a file
+// instead of writing to it.
+func (w *Watcher) start(ctx context.Context) error {
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	if w.cfg.Mode == ModeKubernetes {
+		versions, err := w.readVersions()
+		if err != nil {
+			return err
+		}
+		w.mu.Lock()
+		w.versions = versions
+		w.mu.Unlock()
+	}
+	if w.cfg.Enabled {
+		fsw, err := fsnotify.NewWatcher()
+		if err != nil {
+			return fmt.Errorf("failed to watch config files: %w", err)
+		}
+		for _, path := range w.paths {
+			if err := fsw.Add(path); err != nil {
+				fsw.Close()
+				return fmt.Errorf("failed to watch %s: %w", path, err)
+			}
+		}
+		w.fsw, events, errs = fsw, fsw.Events, fsw.Errors
+	}
+	signal.Notify(w.signals, syscall.SIGHUP)
+
+	w.wg.Add(1)
+	go w.run(events, errs)
+
+	switch {
+	case w.cfg.Enabled && w.cfg.Mode == ModeKubernetes:
+		w.log.Info("watching config volumes for updates", logx.String("paths", strings.Join(w.paths, ",")))
+	case w.cfg.Enabled:
+		w.log.Info("watching config files", logx.String("paths", strings.Join(w.paths, ",")))
+	default:
+		w.log.Info("config file watching disabled; reload with SIGHUP")
+	}
+	return nil
+}
+
+// stop stops watching and waits for a reload in progress
+func (w *Watcher) stop(ctx context.Context) error {
+	signal.Stop(w.signals)
+	close(w.done)
+	w.wg.Wait()
+	if w.fsw != nil {
+		return w.fsw.Close()
+	}
+	return nil
+}
+
+func (w *Watcher) run(events <-chan fsnotify.Event, errs <-chan error) {
+	defer w.wg.Done()
+
+	// debounce is armed by a change and fires once no change has followed
+	// for cfg.Debounce
+	var debounce *time.Timer
+	var fire <-chan time.Time
+	for {
+		select {
+		case ev := <-events:
+			if !w.relevant(ev) {
+				continue
+			}
+			if debounce == nil {
+				debounce = time.NewTimer(w.cfg.Debounce)
+			} else {
+				debounce.Reset(w.cfg.Debounce)
+			}
+			fire = debounce.C
+		case <-fire:
+			fire = nil
+			if w.cfg.Mode == ModeKubernetes {
+				w.reloadUpdatedVolumes()
+				continue
+			}
+			w.reload("file")
+		case err := <-errs:
+			w.log.Warn("config watcher error", logx.Err(err))
+		case <-w.signals:
+			w.reload("signal")
+		case <-w.done:
+			if debounce != nil {
+				debounce.Stop()
+			}
+			return
+		}
+	}
+}
+
+// relevant reports whether an event can change the config: a YAML file, or the
+// ..data link Kubernetes swaps when a mounted ConfigMap changes. In kubernetes
+// mode only the ..data link is: the other entries of a volume are the links
+// through it, which never change, and the versions it points to.
+func (w *Watcher) relevant(ev fsnotify.Event) bool {
+	if ev.Op == fsnotify.Chmod {
+		return false
+	}
+	name := filepath.Base(ev.Name)
+	if w.cfg.Mode == ModeKubernetes {
+		return name == dataLink
+	}
+	ext := filepath.Ext(name)
+	return ext == ".yaml" || ext == ".yml" || name == dataLink
+}
+
+// readVersions returns the version each config directory is at: the directory
+// its ..data link points to. A directory without the link is not a mounted
+// ConfigMap or Secret, or is mounted with subPath, which the kubelet never
+// updates.
+func (w *Watcher) readVersions() (map[string]string, error) {
+	versions := make(map[string]string, len(w.paths))
+	for _, path := range w.paths {
+		version, err := os.Readlink(filepath.Join(path, dataLink))
+		if err != nil {
+			return nil, fmt.Errorf("reload.mode is %s, but %s is not a mounted ConfigMap or Secret (mounted with subPath, it is never updated): %w", ModeKubernetes, path, err)
+		}
+		versions[path] = version
+	}
+	return versions, nil
+}
+
+// reloadUpdatedVolumes reloads when a volume is at another version than the
+// last reload read. The kubelet swaps ..data only to update a volume, but the
+// versions are compared all the same, so a link recreated in place, or the
+// events of one update spread over two debounces, reload once.
+func (w *Watcher) reloadUpdatedVolumes() {
+	versions, err := w.readVersions()
+	if err != nil {
+		w.log.Warn("config volume not readable; keeping the current values", logx.Err(err))
+		return
+	}
+	w.mu.Lock()
+	var updated []string
+	for _, path := range w.paths {
+		if versions[path] != w.versions[path] {
+			updated = append(updated, path)
+		}
+	}
+	w.mu.Unlock()
+	if len(updated) == 0 {
+		w.log.Debug("config volumes unchanged")
+		return
+	}
+	for _, path := range updated {
+		w.log.Info("config volume updated", logx.String("path", path), logx.String("version", versions[path]))
+	}
+	w.reload("volume")
+}
+
+// Reload loads the config files now and updates every registered value
+func (w *Watcher) Reload() Result {
+	return w.reload("manual")
+}
+
+// Last returns the result of the last reload; At is zero before the first
+func (w *Watcher) Last() Result {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.last
+}
+
+func (w *Watcher) register(v reloadable) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.values = append(w.values, v)
+}
+
+// reload updates each value on its own: an invalid section keeps its current
+// object and does not hold back the valid ones
+func (w *Watcher) reload(trigger string) Result {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	res := Result{At: time.Now(), Trigger: trigger, Changed: []string{}}
+	if w.cfg.Mode == ModeKubernetes {
+		// Read before the files, so a version that lands meanwhile reloads again
+		if versions, err := w.readVersions(); err == nil {
+			w.versions = versions
+			res.Versions = maps.Clone(versions)
+		}
+	}
+	loader := w.newLoader()
+	for _, v := range w.values {
+		changed, err := v.reload(loader)
+		switch {
+		case err != nil:
+			if res.Failed == nil {
+				res.Failed = map[string]string{}
+			}
+			res.Failed[v.section()] = err.Error()
+			w.log.Error("config section not reloaded; keeping the current values",
+				logx.String("section", v.section()),
+				logx.Err(err),
+			)
+		case changed:
+			res.Changed = append(res.Changed, v.section())
+		}
+	}
+
+	if len(res.Changed) > 0 {
+		w.log.Info("config reloaded",
+			logx.String("trigger", trigger),
+			logx.String("changed", strings.Join(res.Changed, ",")),
+		)
+	} else if len(res.Failed) == 0 {
+		w.log.Debug("config unchanged", logx.String("trigger", trigger))
+	}
+
+	w.last = res
+	return res
+}
@@ -0,0 +1,189 @@
+package live
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/hotreload-demo/internal/configmap"
+)
+
+// counterConfig is a second section, so reloads can update one and fail another
+type counterConfig struct {
+	Max int
+}
+
+func (counterConfig) Prefix() string {
+	return "counter"
+}
+
+func newCounter(cfg counterConfig) (*int, error) {
+	return &cfg.Max, nil
+}
+
+// newTestWatcher creates a watcher of a temporary directory whose reloads bind
+// from loader, and counts the reloads
+func newTestWatcher(t *testing.T, loader *fakeLoader) (*Watcher, string, *atomic.Int32) {
+	t.Helper()
+	dir := t.TempDir()
+	var reloads atomic.Int32
+	w := newWatcher(Config{Enabled: true, Debounce: 20 * time.Millisecond}, []string{dir}, func() binder {
+		reloads.Add(1)
+		return loader
+	}, logx.NewNoopLogger())
+	return w, dir, &reloads
+}
+
+// mustWatch is Watch with a binder in place of a configx.Loader
+func mustWatch[C configx.Configurable, T any](t *testing.T, w *Watcher, loader binder, build func(C) (*T, error)) *Value[C, T] {
+	t.Helper()
+	v, err := newValue(loader, build)
+	require.NoError(t, err)
+	w.register(v)
+	return v
+}
+
+func TestWatcherReload(t *testing.T) {
+	loader := newFakeLoader(greetingConfig{Text: "hi", Times: 1}, counterConfig{Max: 3})
+	w, _, _ := newTestWatcher(t, loader)
+
+	greet := mustWatch(t, w, loader, newGreeting)
+	counter := mustWatch(t, w, loader, newCounter)
+	assert.True(t, w.Last().At.IsZero())
+
+	loader.set(counterConfig{Max: 5})
+	res := w.Reload()
+	assert.Equal(t, "manual", res.Trigger)
+	assert.Equal(t, []string{"counter"}, res.Changed)
+	assert.Empty(t, res.Failed)
+	assert.Equal(t, 5, *counter.Load())
+	assert.Equal(t, res, w.Last())
+
+	// An invalid section does not hold back the others
+	loader.set(greetingConfig{Text: "hi", Times: 0})
+	loader.set(counterConfig{Max: 7})
+	res = w.Reload()
+	assert.Equal(t, []string{"counter"}, res.Changed)
+	assert.Contains(t, res.Failed["greeting"], "times must be at least 1")
+	assert.Equal(t, "hi x1", greet.Load().message)
+	assert.Equal(t, 7, *counter.Load())
+}
+
+func TestWatcherReloadsOnFileChange(t *testing.T) {
+	loader := newFakeLoader(greetingConfig{Text: "hi", Times: 1})
+	w, dir, reloads := newTestWatcher(t, loader)
+	greet := mustWatch(t, w, loader, newGreeting)
+
+	require.NoError(t, w.start(context.Background()))
+	defer w.stop(context.Background())
+
+	// Files that are not config do not trigger a reload
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0o644))
+
+	// Several writes in a row reload once
+	loader.set(greetingConfig{Text: "hello", Times: 3})
+	for i := 0; i < 3; i++ {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "base.yaml"), []byte("live: {}"), 0o644))
+	}
+
+	require.Eventually(t, func() bool {
+		return greet.Load().message == "hello x3"
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "file", w.Last().Trigger)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(1), reloads.Load())
+}
+
+func TestWatcherReloadsOnSIGHUP(t *testing.T) {
+	loader := newFakeLoader(greetingConfig{Text: "hi", Times: 1})
+	w, _, _ := newTestWatcher(t, loader)
+	w.cfg.Enabled = false
+	greet := mustWatch(t, w, loader, newGreeting)
+
+	require.NoError(t, w.start(context.Background()))
+	defer w.stop(context.Background())
+
+	loader.set(greetingConfig{Text: "hi", Times: 2})
+	w.signals <- syscall.SIGHUP
+
+	require.Eventually(t, func() bool {
+		return greet.Load().message == "hi x2"
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "signal", w.Last().Trigger)
+}
+
+func TestWatcherReloadsOnVolumeUpdate(t *testing.T) {
+	loader := newFakeLoader(greetingConfig{Text: "hi", Times: 1})
+	w, dir, reloads := newTestWatcher(t, loader)
+	w.cfg.Mode = ModeKubernetes
+	greet := mustWatch(t, w, loader, newGreeting)
+
+	first, err := configmap.Write(dir, map[string][]byte{"base.yaml": []byte("live: {}")})
+	require.NoError(t, err)
+	require.NoError(t, w.start(context.Background()))
+	defer w.stop(context.Background())
+
+	// The kubelet swaps ..data; nothing else in the volume counts
+	loader.set(greetingConfig{Text: "hello", Times: 2})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.yaml"), []byte("x: 1"), 0o644))
+	second, err := configmap.Write(dir, map[string][]byte{"base.yaml": []byte("live: {greeting: {times: 2}}")})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return greet.Load().message == "hello x2"
+	}, 2*time.Second, 10*time.Millisecond)
+	last := w.Last()
+	assert.Equal(t, "volume", last.Trigger)
+	assert.Equal(t, map[string]string{dir: second}, last.Versions)
+	assert.NotEqual(t, first, second)
+
+	// ..data recreated at the same version reloads nothing
+	link := filepath.Join(dir, configmap.DataLink)
+	require.NoError(t, os.Remove(link))
+	require.NoError(t, os.Symlink(second, link))
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(1), reloads.Load())
+
+	// A manual reload reads the volume as well
+	res := w.Reload()
+	assert.Equal(t, map[string]string{dir: second}, res.Versions)
+}
+
+func TestWatcherKubernetesModeNeedsAVolume(t *testing.T) {
+	loader := newFakeLoader()
+	w, dir, _ := newTestWatcher(t, loader)
+	w.cfg.Mode = ModeKubernetes
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "base.yaml"), []byte("live: {}"), 0o644))
+
+	err := w.start(context.Background())
+	assert.ErrorContains(t, err, "is not a mounted ConfigMap or Secret")
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, Config{Mode: ModeFiles}.validate())
+	assert.NoError(t, Config{Mode: ModeKubernetes}.validate())
+	assert.ErrorContains(t, Config{Mode: "k8s"}.validate(), `unknown mode "k8s"; use files or kubernetes`)
+}
+
+func TestWatcherFailsOnMissingDirectory(t *testing.T) {
+	w := newWatcher(Config{Enabled: true}, []string{filepath.Join(t.TempDir(), "missing")}, nil, logx.NewNoopLogger())
+	assert.ErrorContains(t, w.start(context.Background()), "failed to watch")
+}
+
+func TestConfigPaths(t *testing.T) {
+	t.Setenv("CONFIG_PATHS", "")
+	assert.Equal(t, []string{"./configs"}, configPaths())
+
+	t.Setenv("CONFIG_PATHS", "./configs, /etc/app ,")
+	assert.Equal(t, []string{"./configs", "/etc/app"}, configPaths())
+}
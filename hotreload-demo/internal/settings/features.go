@@ -0,0 +1,27 @@
+package settings
+
+import "errors"
+
+// Features are the feature toggles of the service
+type Features struct {
+	// Maintenance answers every quote request with 503 and MaintenanceMessage
+	Maintenance        bool   `mapstructure:"maintenance"`
+	MaintenanceMessage string `mapstructure:"maintenance_message" default:"down for maintenance"`
+	// RandomQuote turns on GET /quotes/random
+	RandomQuote bool `mapstructure:"random_quote"`
+	// ShowAuthors includes the author of each quote
+	ShowAuthors bool `mapstructure:"show_authors" default:"true"`
+}
+
+// Prefix implements configx.Configurable
+func (Features) Prefix() string {
+	return "live.features"
+}
+
+// NewFeatures checks the live.features section; the section is its own object
+func NewFeatures(cfg Features) (*Features, error) {
+	if cfg.Maintenance && cfg.MaintenanceMessage == "" {
+		return nil, errors.New("maintenance_message is required in maintenance")
+	}
+	return &cfg, nil
+}
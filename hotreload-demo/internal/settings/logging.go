@@ -0,0 +1,91 @@
+// Package settings holds the settings of this service that change without a
+// restart, each built into the object the service uses and kept in a live.Value
+package settings
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gostratum/core/logx"
+
+	"github.com/gostratum/examples/hotreload-demo/internal/live"
+)
+
+// LogConfig is the level the service logs at. core builds its logger once, at
+// log.level, and this level filters on top of it: it can be raised above
+// log.level and lowered back to it, but not below.
+type LogConfig struct {
+	Level string `mapstructure:"level" default:"info"`
+}
+
+// Prefix implements configx.Configurable
+func (LogConfig) Prefix() string {
+	return "live.log"
+}
+
+// Level is a log level, from the most to the least verbose
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[string]Level{
+	"debug": LevelDebug,
+	"info":  LevelInfo,
+	"warn":  LevelWarn,
+	"error": LevelError,
+}
+
+// NewLevel parses the configured level
+func NewLevel(cfg LogConfig) (*Level, error) {
+	level, ok := levelNames[strings.ToLower(strings.TrimSpace(cfg.Level))]
+	if !ok {
+		return nil, fmt.Errorf("unknown level %q; use debug, info, warn or error", cfg.Level)
+	}
+	return &level, nil
+}
+
+// Logger writes through core's logger and drops lines below the live level
+type Logger struct {
+	log   logx.Logger
+	level *live.Value[LogConfig, Level]
+}
+
+// NewLogger creates a logger that follows the live.log section
+func NewLogger(log logx.Logger, level *live.Value[LogConfig, Level]) *Logger {
+	return &Logger{log: log, level: level}
+}
+
+// Enabled reports whether lines of the level are written; check it before
+// building expensive fields
+func (l *Logger) Enabled(level Level) bool {
+	return level >= *l.level.Load()
+}
+
+func (l *Logger) Debug(msg string, fields ...logx.Field) {
+	if l.Enabled(LevelDebug) {
+		l.log.Debug(msg, fields...)
+	}
+}
+
+func (l *Logger) Info(msg string, fields ...logx.Field) {
+	if l.Enabled(LevelInfo) {
+		l.log.Info(msg, fields...)
+	}
+}
+
+func (l *Logger) Warn(msg string, fields ...logx.Field) {
+	if l.Enabled(LevelWarn) {
+		l.log.Warn(msg, fields...)
+	}
+}
+
+func (l *Logger) Error(msg string, fields ...logx.Field) {
+	if l.Enabled(LevelError) {
+		l.log.Error(msg, fields...)
+	}
+}
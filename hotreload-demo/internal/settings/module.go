@@ -0,0 +1,36 @@
+package settings
+
+import (
+	"github.com/gostratum/core/configx"
+	"go.uber.org/fx"
+
+	"github.com/gostratum/examples/hotreload-demo/internal/live"
+)
+
+// WatchLogLevel provides the live log level
+func WatchLogLevel(w *live.Watcher, loader configx.Loader) (*live.Value[LogConfig, Level], error) {
+	return live.Watch(w, loader, NewLevel)
+}
+
+// WatchRateLimiter provides the live rate limiter
+func WatchRateLimiter(w *live.Watcher, loader configx.Loader) (*live.Value[RateLimitConfig, RateLimiter], error) {
+	return live.Watch(w, loader, NewRateLimiter)
+}
+
+// WatchFeatures provides the live feature toggles
+func WatchFeatures(w *live.Watcher, loader configx.Loader) (*live.Value[Features, Features], error) {
+	return live.Watch(w, loader, NewFeatures)
+}
+
+// Module provides the live settings and the Logger that follows the live
+// level. It needs live.Module.
+func Module() fx.Option {
+	return fx.Module("settings",
+		fx.Provide(
+			WatchLogLevel,
+			WatchRateLimiter,
+			WatchFeatures,
+			NewLogger,
+		),
+	)
+}
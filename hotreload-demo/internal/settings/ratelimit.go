@@ -0,0 +1,99 @@
+package settings
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// idleClient is how long a client's bucket is kept after its last request; by
+// then the bucket is full again, so dropping it changes nothing
+const idleClient = time.Minute
+
+// RateLimitConfig limits the requests of each client with a token bucket
+type RateLimitConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// RequestsPerSecond is the rate the bucket refills at
+	RequestsPerSecond float64 `mapstructure:"requests_per_second" default:"5"`
+	// Burst is the bucket size: how many requests a client can make at once
+	Burst int `mapstructure:"burst" default:"10"`
+}
+
+// Prefix implements configx.Configurable
+func (RateLimitConfig) Prefix() string {
+	return "live.rate_limit"
+}
+
+// RateLimiter limits requests per client. A reload builds a new limiter, so
+// every client starts again with a full bucket of the new size.
+type RateLimiter struct {
+	cfg RateLimitConfig
+	now func() time.Time
+
+	mu        sync.Mutex
+	clients   map[string]*client
+	lastSweep time.Time
+}
+
+type client struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewRateLimiter creates a rate limiter from the live.rate_limit section
+func NewRateLimiter(cfg RateLimitConfig) (*RateLimiter, error) {
+	if cfg.Enabled {
+		if cfg.RequestsPerSecond <= 0 {
+			return nil, errors.New("requests_per_second must be positive")
+		}
+		if cfg.Burst < 1 {
+			return nil, errors.New("burst must be at least 1")
+		}
+	}
+	return &RateLimiter{cfg: cfg, now: time.Now, clients: make(map[string]*client)}, nil
+}
+
+// Allow reports whether the client may make a request now, and takes a token
+// if so
+func (r *RateLimiter) Allow(key string) bool {
+	if !r.cfg.Enabled {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	r.sweep(now)
+
+	c, ok := r.clients[key]
+	if !ok {
+		c = &client{limiter: rate.NewLimiter(rate.Limit(r.cfg.RequestsPerSecond), r.cfg.Burst)}
+		r.clients[key] = c
+	}
+	c.lastSeen = now
+	return c.limiter.AllowN(now, 1)
+}
+
+// RetryAfter is how long a limited client waits for its next token
+func (r *RateLimiter) RetryAfter() time.Duration {
+	if !r.cfg.Enabled {
+		return 0
+	}
+	return time.Duration(float64(time.Second) / r.cfg.RequestsPerSecond)
+}
+
+// sweep drops the buckets of idle clients, at most once per idleClient
+func (r *RateLimiter) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < idleClient {
+		return
+	}
+	r.lastSweep = now
+	for key, c := range r.clients {
+		if now.Sub(c.lastSeen) >= idleClient {
+			delete(r.clients, key)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package settings
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLevel(t *testing.T) {
+	for name, want := range map[string]Level{"debug": LevelDebug, "INFO": LevelInfo, " warn ": LevelWarn, "error": LevelError} {
+		level, err := NewLevel(LogConfig{Level: name})
+		require.NoError(t, err, name)
+		assert.Equal(t, want, *level)
+	}
+
+	_, err := NewLevel(LogConfig{Level: "verbose"})
+	assert.ErrorContains(t, err, `unknown level "verbose"`)
+}
+
+func TestNewFeatures(t *testing.T) {
+	f, err := NewFeatures(Features{RandomQuote: true})
+	require.NoError(t, err)
+	assert.True(t, f.RandomQuote)
+
+	_, err = NewFeatures(Features{Maintenance: true})
+	assert.ErrorContains(t, err, "maintenance_message is required")
+}
+
+func TestNewRateLimiterValidates(t *testing.T) {
+	_, err := NewRateLimiter(RateLimitConfig{Enabled: true, Burst: 1})
+	assert.ErrorContains(t, err, "requests_per_second must be positive")
+
+	_, err = NewRateLimiter(RateLimitConfig{Enabled: true, RequestsPerSecond: 1})
+	assert.ErrorContains(t, err, "burst must be at least 1")
+
+	_, err = NewRateLimiter(RateLimitConfig{})
+	assert.NoError(t, err, "a disabled limiter needs no limits")
+}
+
+func TestRateLimiter(t *testing.T) {
+	r, err := NewRateLimiter(RateLimitConfig{Enabled: true, RequestsPerSecond: 2, Burst: 3})
+	require.NoError(t, err)
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, r.Allow("10.0.0.1"), "request %d is within the burst", i)
+	}
+	assert.False(t, r.Allow("10.0.0.1"))
+	assert.True(t, r.Allow("10.0.0.2"), "clients have their own buckets")
+	assert.Equal(t, 500*time.Millisecond, r.RetryAfter())
+
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, r.Allow("10.0.0.1"), "the bucket refills")
+	assert.False(t, r.Allow("10.0.0.1"))
+}
+
+func TestRateLimiterDropsIdleClients(t *testing.T) {
+	r, err := NewRateLimiter(RateLimitConfig{Enabled: true, RequestsPerSecond: 1, Burst: 1})
+	require.NoError(t, err)
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	r.Allow("10.0.0.1")
+	now = now.Add(30 * time.Second)
+	r.Allow("10.0.0.2")
+	require.Len(t, r.clients, 2)
+
+	now = now.Add(idleClient)
+	r.Allow("10.0.0.3")
+	assert.Len(t, r.clients, 1)
+	assert.Contains(t, r.clients, "10.0.0.3")
+}
+
+func TestDisabledRateLimiter(t *testing.T) {
+	r, err := NewRateLimiter(RateLimitConfig{})
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		require.True(t, r.Allow("10.0.0.1"))
+	}
+	assert.Zero(t, r.RetryAfter())
+}
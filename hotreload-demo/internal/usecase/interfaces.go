@@ -0,0 +1,17 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/gostratum/examples/hotreload-demo/internal/domain"
+)
+
+// Application-level errors for use case layer
+// These are used to communicate failures to the presentation layer
+var (
+	// ErrUnavailable indicates the quotes cannot be read
+	ErrUnavailable = errors.New("service unavailable")
+
+	// ErrNotFound wraps domain.ErrNotFound for application layer
+	ErrNotFound = domain.ErrNotFound
+)
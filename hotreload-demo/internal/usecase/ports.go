@@ -0,0 +1,13 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/gostratum/examples/hotreload-demo/internal/domain"
+)
+
+// QuoteRepository lists the quotes
+// This interface is owned by the use case layer (dependency inversion principle)
+type QuoteRepository interface {
+	List(ctx context.Context) ([]domain.Quote, error)
+}
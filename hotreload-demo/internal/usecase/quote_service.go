@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/gostratum/examples/hotreload-demo/internal/domain"
+)
+
+// QuoteService serves quotes. It knows nothing about live settings; the HTTP
+// layer applies them.
+type QuoteService struct {
+	quotes QuoteRepository
+	// pick returns a number in [0, n); replaced in tests
+	pick func(n int) int
+}
+
+// NewQuoteService creates a new quote service with repository injection
+func NewQuoteService(quotes QuoteRepository) *QuoteService {
+	return &QuoteService{quotes: quotes, pick: rand.IntN}
+}
+
+// List returns every quote
+func (s *QuoteService) List(ctx context.Context) ([]domain.Quote, error) {
+	quotes, err := s.quotes.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return quotes, nil
+}
+
+// Random returns one quote picked at random
+func (s *QuoteService) Random(ctx context.Context) (domain.Quote, error) {
+	quotes, err := s.List(ctx)
+	if err != nil {
+		return domain.Quote{}, err
+	}
+	if len(quotes) == 0 {
+		return domain.Quote{}, fmt.Errorf("%w: there are no quotes", ErrNotFound)
+	}
+	return quotes[s.pick(len(quotes))], nil
+}
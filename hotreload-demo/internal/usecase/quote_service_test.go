@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/hotreload-demo/internal/domain"
+)
+
+// fakeQuotes returns fixed quotes or an error
+type fakeQuotes struct {
+	quotes []domain.Quote
+	err    error
+}
+
+func (f *fakeQuotes) List(ctx context.Context) ([]domain.Quote, error) {
+	return f.quotes, f.err
+}
+
+func TestRandom(t *testing.T) {
+	svc := NewQuoteService(&fakeQuotes{quotes: []domain.Quote{{ID: 1}, {ID: 2}, {ID: 3}}})
+	svc.pick = func(n int) int { return n - 1 }
+
+	q, err := svc.Random(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, q.ID)
+}
+
+func TestRandomWithoutQuotes(t *testing.T) {
+	svc := NewQuoteService(&fakeQuotes{})
+	_, err := svc.Random(context.Background())
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestListUnavailable(t *testing.T) {
+	svc := NewQuoteService(&fakeQuotes{err: errors.New("disk on fire")})
+
+	_, err := svc.List(context.Background())
+	assert.ErrorIs(t, err, ErrUnavailable)
+	_, err = svc.Random(context.Background())
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
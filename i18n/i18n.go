@@ -0,0 +1,161 @@
+// Package i18n translates the strings the examples show to people: API error
+// messages, validation messages and notifications. A Bundle holds a service's
+// message catalogs, one JSON file per language, and picks the language a
+// client asks for; Middleware puts it in the context of each request, so
+// handlers answer in it.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Bundle holds the message catalogs of a service. The catalog of the fallback
+// language has every key; the others may miss some, which are answered in the
+// fallback language.
+type Bundle struct {
+	fallback  string
+	languages []string
+	catalogs  map[string]map[string]string
+	matcher   language.Matcher
+}
+
+// placeholder is a value a message takes, such as {limit}
+var placeholder = regexp.MustCompile(`\{[a-z_]+\}`)
+
+// New loads the catalogs of dir in fsys: one JSON object of keys to messages
+// per language, named after it, such as en.json and es.json. fallback names
+// the language answered when no other fits. A catalog with a key the fallback
+// lacks, or a message whose placeholders differ from the fallback's, fails,
+// so a broken translation stops startup rather than a request.
+func New(fsys fs.FS, dir, fallback string) (*Bundle, error) {
+	names, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	b := &Bundle{fallback: fallback, catalogs: make(map[string]map[string]string, len(names))}
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		var catalog map[string]string
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return nil, fmt.Errorf("failed to parse catalog %s: %w", name, err)
+		}
+		lang := strings.TrimSuffix(path.Base(name), ".json")
+		if _, err := language.Parse(lang); err != nil {
+			return nil, fmt.Errorf("catalog %s is not named after a language: %w", name, err)
+		}
+		b.catalogs[lang] = catalog
+	}
+
+	base, ok := b.catalogs[fallback]
+	if !ok {
+		return nil, fmt.Errorf("no catalog for the fallback language %s in %s", fallback, dir)
+	}
+	// The fallback comes first, as the matcher answers the first language
+	// when none fits
+	b.languages = append(b.languages, fallback)
+	for lang, catalog := range b.catalogs {
+		if lang == fallback {
+			continue
+		}
+		for key, message := range catalog {
+			want, ok := base[key]
+			if !ok {
+				return nil, fmt.Errorf("catalog %s has %q, which %s does not", lang, key, fallback)
+			}
+			if !samePlaceholders(message, want) {
+				return nil, fmt.Errorf("catalog %s: %q must have the placeholders of %s, %v", lang, key, fallback, placeholders(want))
+			}
+		}
+		b.languages = append(b.languages, lang)
+	}
+	slices.Sort(b.languages[1:])
+
+	tags := make([]language.Tag, len(b.languages))
+	for i, lang := range b.languages {
+		tags[i] = language.MustParse(lang)
+	}
+	b.matcher = language.NewMatcher(tags)
+	return b, nil
+}
+
+// MustNew is New for catalogs embedded in the binary, which are checked by the
+// service's tests; it panics on error
+func MustNew(fsys fs.FS, dir, fallback string) *Bundle {
+	b, err := New(fsys, dir, fallback)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Languages returns the languages of the catalogs, the fallback first
+func (b *Bundle) Languages() []string {
+	return slices.Clone(b.languages)
+}
+
+// Fallback returns the language answered when no other fits
+func (b *Bundle) Fallback() string {
+	return b.fallback
+}
+
+// Message returns the message of key in lang, with its placeholders replaced
+// by args, pairs of names and values: Message("es", "file_too_large",
+// "limit", "10 MB"). It falls back to the fallback language for a language
+// or key the catalogs lack, and to the key itself for a key none has.
+func (b *Bundle) Message(lang, key string, args ...string) string {
+	message, ok := b.catalogs[lang][key]
+	if !ok {
+		message, ok = b.catalogs[b.fallback][key]
+	}
+	if !ok {
+		message = key
+	}
+	if len(args) == 0 {
+		return message
+	}
+	pairs := make([]string, 0, len(args))
+	for i := 0; i+1 < len(args); i += 2 {
+		pairs = append(pairs, "{"+args[i]+"}", args[i+1])
+	}
+	return strings.NewReplacer(pairs...).Replace(message)
+}
+
+// Has reports whether the fallback catalog has key
+func (b *Bundle) Has(key string) bool {
+	_, ok := b.catalogs[b.fallback][key]
+	return ok
+}
+
+// Missing returns the keys of the fallback catalog lang has no message for,
+// sorted, for tests to require complete translations
+func (b *Bundle) Missing(lang string) []string {
+	var missing []string
+	for key := range b.catalogs[b.fallback] {
+		if _, ok := b.catalogs[lang][key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	slices.Sort(missing)
+	return missing
+}
+
+func placeholders(message string) []string {
+	found := placeholder.FindAllString(message, -1)
+	slices.Sort(found)
+	return slices.Compact(found)
+}
+
+func samePlaceholders(a, b string) bool {
+	return slices.Equal(placeholders(a), placeholders(b))
+}
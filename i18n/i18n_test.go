@@ -0,0 +1,83 @@
+package i18n
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func catalogs(files map[string]string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for name, data := range files {
+		fsys["locales/"+name] = &fstest.MapFile{Data: []byte(data)}
+	}
+	return fsys
+}
+
+func testBundle(t *testing.T) *Bundle {
+	t.Helper()
+	b, err := New(catalogs(map[string]string{
+		"en.json": `{"not_found": "user not found", "too_large": "file size exceeds {limit} limit", "only_en": "English"}`,
+		"es.json": `{"not_found": "usuario no encontrado", "too_large": "el archivo supera el límite de {limit}"}`,
+		"de.json": `{"not_found": "Benutzer nicht gefunden", "too_large": "Die Datei überschreitet das Limit von {limit}", "only_en": "Englisch"}`,
+	}), "locales", "en")
+	require.NoError(t, err)
+	return b
+}
+
+func TestBundle_Message(t *testing.T) {
+	b := testBundle(t)
+	assert.Equal(t, []string{"en", "de", "es"}, b.Languages())
+
+	assert.Equal(t, "usuario no encontrado", b.Message("es", "not_found"))
+	assert.Equal(t, "Die Datei überschreitet das Limit von 10 MB", b.Message("de", "too_large", "limit", "10 MB"))
+	assert.Equal(t, "English", b.Message("es", "only_en"), "a key the language lacks")
+	assert.Equal(t, "user not found", b.Message("fr", "not_found"), "a language without a catalog")
+	assert.Equal(t, "user not found", b.Message("", "not_found"))
+	assert.Equal(t, "no_such_key", b.Message("es", "no_such_key"))
+
+	assert.Equal(t, []string{"only_en"}, b.Missing("es"))
+	assert.Empty(t, b.Missing("de"))
+}
+
+func TestNew_RejectsBrokenCatalogs(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		err   string
+	}{
+		{
+			name:  "no fallback",
+			files: map[string]string{"es.json": `{}`},
+			err:   "no catalog for the fallback language en in locales",
+		},
+		{
+			name:  "malformed",
+			files: map[string]string{"en.json": `{"a": 1}`},
+			err:   "failed to parse catalog locales/en.json",
+		},
+		{
+			name:  "not a language",
+			files: map[string]string{"en.json": `{}`, "english.json": `{}`},
+			err:   "catalog locales/english.json is not named after a language",
+		},
+		{
+			name:  "key the fallback lacks",
+			files: map[string]string{"en.json": `{"a": "A"}`, "es.json": `{"b": "B"}`},
+			err:   `catalog es has "b", which en does not`,
+		},
+		{
+			name:  "placeholders that differ",
+			files: map[string]string{"en.json": `{"a": "exceeds {limit}"}`, "de.json": `{"a": "überschreitet {grenze}"}`},
+			err:   `catalog de: "a" must have the placeholders of en, [{limit}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(catalogs(tt.files), "locales", "en")
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package i18n
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// Match returns the language of the catalogs that best fits acceptLanguage,
+// an Accept-Language header such as "de-CH, de;q=0.9, en;q=0.5": a regional
+// variant gets its language, de-CH gets de, and anything else, an empty or
+// malformed header included, gets the fallback.
+func (b *Bundle) Match(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return b.fallback
+	}
+	_, index, confidence := b.matcher.Match(tags...)
+	if confidence == language.No {
+		return b.fallback
+	}
+	return b.languages[index]
+}
+
+// contextKey keys the language in a context
+type contextKey struct{}
+
+// WithLocale returns ctx carrying lang, the language to answer in
+func WithLocale(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// Locale returns the language ctx carries, or "" for none, which Message
+// answers in the fallback language
+func Locale(ctx context.Context) string {
+	lang, _ := ctx.Value(contextKey{}).(string)
+	return lang
+}
+
+// T returns the message of key in the language of ctx; see Message
+func (b *Bundle) T(ctx context.Context, key string, args ...string) string {
+	return b.Message(Locale(ctx), key, args...)
+}
+
+// Middleware picks the language of each request from its Accept-Language
+// header and puts it in the request's context, for T. The response says it in
+// Content-Language, and varies on Accept-Language, so caches keep a response
+// per language.
+func Middleware(b *Bundle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lang := b.Match(c.GetHeader("Accept-Language"))
+		c.Request = c.Request.WithContext(WithLocale(c.Request.Context(), lang))
+		c.Header("Content-Language", lang)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
@@ -0,0 +1,56 @@
+package i18n
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBundle_Match(t *testing.T) {
+	b := testBundle(t)
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: "en"},
+		{header: "es", want: "es"},
+		{header: "es-MX", want: "es"},
+		{header: "de-CH, de;q=0.9, en;q=0.5", want: "de"},
+		{header: "fr-FR, es;q=0.8, en;q=0.5", want: "es"},
+		{header: "en-GB, de;q=0.9", want: "en"},
+		{header: "ja", want: "en"},
+		{header: "*", want: "en"},
+		{header: "es;q=nope", want: "en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.want, b.Match(tt.header))
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	b := testBundle(t)
+	gin.SetMode(gin.TestMode)
+	e := gin.New()
+	e.Use(Middleware(b))
+	e.GET("/users/:id", func(c *gin.Context) {
+		c.String(http.StatusNotFound, b.T(c.Request.Context(), "not_found"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("Accept-Language", "es-ES,es;q=0.9")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	assert.Equal(t, "usuario no encontrado", w.Body.String())
+	assert.Equal(t, "es", w.Header().Get("Content-Language"))
+	assert.Equal(t, "Accept-Language", w.Header().Get("Vary"))
+
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	assert.Equal(t, "user not found", w.Body.String())
+	assert.Equal(t, "en", w.Header().Get("Content-Language"))
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"log"
+
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/dbx"
+	"github.com/gostratum/examples/inventoryservice/configs"
+	httpAdapter "github.com/gostratum/examples/inventoryservice/internal/adapter/http"
+	repoAdapter "github.com/gostratum/examples/inventoryservice/internal/adapter/repo"
+	"github.com/gostratum/examples/inventoryservice/internal/usecase"
+	"github.com/gostratum/examples/redact"
+	"github.com/gostratum/httpx"
+)
+
+func main() {
+	// The overlay of APP_ENV's profile and local.yaml go over base.yaml
+	profile, err := configs.ApplyProfile(configs.Paths()...)
+	if err != nil {
+		log.Fatalf("Failed to apply configuration profile: %v", err)
+	}
+
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// Include dbx module; migrations run on startup when auto_migrate is enabled
+		dbx.Module(),
+
+		// Include httpx module
+		httpx.Module(),
+
+		// The profile, logged and reported by /version
+		fx.Supply(profile),
+
+		// Provide dependencies
+		fx.Provide(
+			// GORM repositories
+			repoAdapter.NewStockRepo,
+			repoAdapter.NewReservationRepo,
+
+			// Usecase services
+			usecase.NewStockService,
+			usecase.NewReservationService,
+
+			// HTTP handlers
+			httpAdapter.NewStockHandler,
+			httpAdapter.NewReservationHandler,
+		),
+
+		// Invoke setup functions
+		fx.Invoke(
+			httpAdapter.RegisterRoutes,
+			httpAdapter.RegisterVersionRoute,
+			configs.LogProfile,
+		),
+	)
+
+	app.Run()
+}
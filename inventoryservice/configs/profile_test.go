@@ -0,0 +1,114 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEnv is an environment applyProfile reads and sets
+type fakeEnv map[string]string
+
+func (e fakeEnv) lookup(name string) (string, bool) {
+	value, ok := e[name]
+	return value, ok
+}
+
+func (e fakeEnv) set(name, value string) error {
+	e[name] = value
+	return nil
+}
+
+// writeFiles writes files into a temporary directory and returns it
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestApplyProfile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"prod.yaml": "db:\n  databases:\n    primary:\n      max_open_conns: 50\n      log_level: error\nstoragex:\n  bucket: avatars-prod\n",
+		"dev.yaml":  "http:\n  addr: \":9999\"\n",
+		"local.yaml": "db:\n  databases:\n    primary:\n      log_level: debug\n" +
+			"uploads:\n  gc:\n    dry_run: true\n",
+	})
+	env := fakeEnv{"APP_ENV": "prod", "STRATUM_STORAGEX_BUCKET": "avatars-eu"}
+
+	profile, err := applyProfile([]string{dir}, env.lookup, env.set)
+	require.NoError(t, err)
+
+	assert.Equal(t, "prod", profile.Name)
+	assert.Equal(t, []string{filepath.Join(dir, "prod.yaml"), filepath.Join(dir, "local.yaml")}, profile.Files)
+	assert.Equal(t, fakeEnv{
+		"APP_ENV":         "prod",
+		"STRATUM_APP_ENV": "prod",
+		"STRATUM_DB_DATABASES_PRIMARY_MAX_OPEN_CONNS": "50",
+		"STRATUM_DB_DATABASES_PRIMARY_LOG_LEVEL":      "debug",
+		"STRATUM_STORAGEX_BUCKET":                     "avatars-eu",
+		"STRATUM_UPLOADS_GC_DRY_RUN":                  "true",
+	}, env, "local.yaml goes over the profile, and the environment over both; other profiles are not read")
+}
+
+func TestApplyProfile_Default(t *testing.T) {
+	env := fakeEnv{}
+
+	profile, err := applyProfile([]string{t.TempDir()}, env.lookup, env.set)
+	require.NoError(t, err)
+
+	assert.Equal(t, Profile{Name: "dev"}, profile, "without overlays, base.yaml is read alone")
+	assert.Equal(t, fakeEnv{"STRATUM_APP_ENV": "dev"}, env)
+}
+
+func TestApplyProfile_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     fakeEnv
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "unknown profile",
+			env:     fakeEnv{"APP_ENV": "production"},
+			wantErr: `APP_ENV must be one of dev, staging, prod, got "production"`,
+		},
+		{
+			name:    "malformed overlay",
+			files:   map[string]string{"dev.yaml": "http: [addr"},
+			wantErr: "invalid ",
+		},
+		{
+			name:    "list in an overlay",
+			files:   map[string]string{"local.yaml": "uploads:\n  avatar:\n    allowed_types: [image/png]\n"},
+			wantErr: "uploads.avatar.allowed_types is a list; lists are set in base.yaml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.env
+			if env == nil {
+				env = fakeEnv{}
+			}
+			_, err := applyProfile([]string{writeFiles(t, tt.files)}, env.lookup, env.set)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestOverlays(t *testing.T) {
+	for _, name := range envs {
+		t.Run(name, func(t *testing.T) {
+			env := fakeEnv{"APP_ENV": name}
+			profile, err := applyProfile([]string{"."}, env.lookup, env.set)
+			require.NoError(t, err)
+			assert.Contains(t, profile.Files, name+".yaml", "every profile has an overlay")
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gostratum/examples/inventoryservice/internal/domain"
+)
+
+// StockResponse is the HTTP DTO for stock data
+// This struct handles JSON serialization concerns for the HTTP layer
+type StockResponse struct {
+	SKU       string    `json:"sku"`
+	Name      string    `json:"name"`
+	Available int       `json:"available"`
+	Reserved  int       `json:"reserved"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FromDomainStock converts a domain.Stock to StockResponse DTO
+func FromDomainStock(stock *domain.Stock) *StockResponse {
+	if stock == nil {
+		return nil
+	}
+	return &StockResponse{
+		SKU:       stock.SKU,
+		Name:      stock.Name,
+		Available: stock.Available,
+		Reserved:  stock.Reserved,
+		UpdatedAt: stock.UpdatedAt,
+	}
+}
+
+// ReservationLineResponse is the HTTP DTO for a reservation line
+type ReservationLineResponse struct {
+	SKU string `json:"sku"`
+	Qty int    `json:"qty"`
+}
+
+// ReservationResponse is the HTTP DTO for reservation data
+type ReservationResponse struct {
+	ID        string                    `json:"id"`
+	OrderID   string                    `json:"order_id"`
+	Items     []ReservationLineResponse `json:"items"`
+	Status    string                    `json:"status"`
+	CreatedAt time.Time                 `json:"created_at"`
+}
+
+// FromDomainReservation converts a domain.Reservation to ReservationResponse DTO
+func FromDomainReservation(reservation *domain.Reservation) *ReservationResponse {
+	if reservation == nil {
+		return nil
+	}
+
+	items := make([]ReservationLineResponse, len(reservation.Lines))
+	for i, line := range reservation.Lines {
+		items[i] = ReservationLineResponse{SKU: line.SKU, Qty: line.Qty}
+	}
+
+	return &ReservationResponse{
+		ID:        reservation.ID,
+		OrderID:   reservation.OrderID,
+		Items:     items,
+		Status:    reservation.Status,
+		CreatedAt: reservation.CreatedAt,
+	}
+}
@@ -0,0 +1,183 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/inventoryservice/internal/domain"
+	"github.com/gostratum/examples/inventoryservice/internal/usecase"
+)
+
+// memoryStore implements both repositories in memory for handler tests
+type memoryStore struct {
+	stock        map[string]*domain.Stock
+	reservations map[string]*domain.Reservation
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		stock:        make(map[string]*domain.Stock),
+		reservations: make(map[string]*domain.Reservation),
+	}
+}
+
+func (m *memoryStore) Save(ctx context.Context, s *domain.Stock) error {
+	m.stock[s.SKU] = s
+	return nil
+}
+
+func (m *memoryStore) FindBySKU(ctx context.Context, sku string) (*domain.Stock, error) {
+	s, ok := m.stock[sku]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return s, nil
+}
+
+func (m *memoryStore) Create(ctx context.Context, r *domain.Reservation) error {
+	for _, line := range r.Lines {
+		s, ok := m.stock[line.SKU]
+		if !ok || s.Available < line.Qty {
+			return fmt.Errorf("%w: sku %s", domain.ErrInsufficientStock, line.SKU)
+		}
+	}
+	for _, line := range r.Lines {
+		_ = m.stock[line.SKU].Reserve(line.Qty)
+	}
+	m.reservations[r.ID] = r
+	return nil
+}
+
+func (m *memoryStore) FindByID(ctx context.Context, id string) (*domain.Reservation, error) {
+	r, ok := m.reservations[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return r, nil
+}
+
+func (m *memoryStore) FindByOrderID(ctx context.Context, orderID string) (*domain.Reservation, error) {
+	for _, r := range m.reservations {
+		if r.OrderID == orderID {
+			return r, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (m *memoryStore) Release(ctx context.Context, r *domain.Reservation) error {
+	for _, line := range r.Lines {
+		m.stock[line.SKU].Release(line.Qty)
+	}
+	return nil
+}
+
+func setupRouter(store *memoryStore) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	logger := logx.NewNoopLogger()
+
+	stockHandler := NewStockHandler(usecase.NewStockService(store), logger)
+	reservationHandler := NewReservationHandler(usecase.NewReservationService(store), logger)
+
+	router := gin.New()
+	router.GET("/stock/:sku", stockHandler.GetStock)
+	router.PUT("/stock/:sku", stockHandler.SetStock)
+	router.POST("/reservations", reservationHandler.CreateReservation)
+	router.DELETE("/reservations/:id", reservationHandler.ReleaseReservation)
+	return router
+}
+
+func doJSON(router *gin.Engine, method, path string, body any) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	req, _ := http.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestStockHandler(t *testing.T) {
+	router := setupRouter(newMemoryStore())
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           any
+		expectedStatus int
+	}{
+		{name: "create stock", method: http.MethodPut, path: "/stock/SKU1", body: map[string]any{"name": "Widget", "available": 5}, expectedStatus: http.StatusOK},
+		{name: "zero units is valid", method: http.MethodPut, path: "/stock/SKU2", body: map[string]any{"name": "Gadget", "available": 0}, expectedStatus: http.StatusOK},
+		{name: "missing available", method: http.MethodPut, path: "/stock/SKU1", body: map[string]any{"name": "Widget"}, expectedStatus: http.StatusBadRequest},
+		{name: "negative available", method: http.MethodPut, path: "/stock/SKU1", body: map[string]any{"name": "Widget", "available": -1}, expectedStatus: http.StatusBadRequest},
+		{name: "get stock", method: http.MethodGet, path: "/stock/SKU1", expectedStatus: http.StatusOK},
+		{name: "unknown sku", method: http.MethodGet, path: "/stock/SKU9", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doJSON(router, tt.method, tt.path, tt.body)
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestReservationHandler(t *testing.T) {
+	store := newMemoryStore()
+	store.stock["SKU1"] = domain.NewStock("SKU1", "Widget", 3)
+	router := setupRouter(store)
+
+	t.Run("reserve available stock", func(t *testing.T) {
+		w := doJSON(router, http.MethodPost, "/reservations", map[string]any{
+			"order_id": "order1",
+			"items":    []map[string]any{{"sku": "SKU1", "qty": 2}},
+		})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var envelope responsex.Envelope[ReservationResponse]
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+		assert.True(t, envelope.Ok)
+		assert.Equal(t, "order1", envelope.Data.OrderID)
+		assert.Equal(t, domain.ReservationReserved, envelope.Data.Status)
+
+		w = doJSON(router, http.MethodDelete, "/reservations/"+envelope.Data.ID, nil)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, 3, store.stock["SKU1"].Available)
+
+		w = doJSON(router, http.MethodDelete, "/reservations/"+envelope.Data.ID, nil)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("out of stock", func(t *testing.T) {
+		w := doJSON(router, http.MethodPost, "/reservations", map[string]any{
+			"order_id": "order2",
+			"items":    []map[string]any{{"sku": "SKU1", "qty": 4}},
+		})
+		require.Equal(t, http.StatusConflict, w.Code)
+
+		var envelope responsex.Envelope[any]
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+		require.NotNil(t, envelope.Error)
+		assert.Equal(t, "OUT_OF_STOCK", envelope.Error.Code)
+		assert.Contains(t, envelope.Error.Message, "SKU1")
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		w := doJSON(router, http.MethodPost, "/reservations", map[string]any{"order_id": "order3"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/inventoryservice/internal/domain"
+	"github.com/gostratum/examples/inventoryservice/internal/usecase"
+)
+
+// ReservationHandler handles reservation-related HTTP requests
+type ReservationHandler struct {
+	service *usecase.ReservationService
+	log     logx.Logger
+}
+
+// NewReservationHandler creates a new reservation handler
+func NewReservationHandler(service *usecase.ReservationService, log logx.Logger) *ReservationHandler {
+	return &ReservationHandler{service: service, log: log}
+}
+
+// ReservationLineRequest represents the request payload for a reserved SKU
+type ReservationLineRequest struct {
+	SKU string `json:"sku" binding:"required"`
+	Qty int    `json:"qty" binding:"required"`
+}
+
+// CreateReservationRequest represents the request payload for reserving stock for an order
+type CreateReservationRequest struct {
+	OrderID string                   `json:"order_id" binding:"required"`
+	Items   []ReservationLineRequest `json:"items" binding:"required"`
+}
+
+// CreateReservation handles POST /reservations
+func (h *ReservationHandler) CreateReservation(c *gin.Context) {
+	var req CreateReservationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid request payload", nil)
+		return
+	}
+
+	lines := make([]domain.ReservationLine, len(req.Items))
+	for i, item := range req.Items {
+		lines[i] = domain.ReservationLine{SKU: item.SKU, Qty: item.Qty}
+	}
+
+	reservation, err := h.service.Reserve(c.Request.Context(), req.OrderID, lines)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.Created(c, "", FromDomainReservation(reservation))
+}
+
+// GetReservation handles GET /reservations/:id
+func (h *ReservationHandler) GetReservation(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		responsex.Error(c, http.StatusBadRequest, "MISSING_PARAMETER", "reservation id is required", nil)
+		return
+	}
+
+	reservation, err := h.service.GetReservation(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.OK(c, FromDomainReservation(reservation), nil)
+}
+
+// ReleaseReservation handles DELETE /reservations/:id
+func (h *ReservationHandler) ReleaseReservation(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		responsex.Error(c, http.StatusBadRequest, "MISSING_PARAMETER", "reservation id is required", nil)
+		return
+	}
+
+	reservation, err := h.service.Release(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.OK(c, FromDomainReservation(reservation), nil)
+}
+
+// handleError maps usecase errors to HTTP responses
+func (h *ReservationHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrOutOfStock):
+		// The message names the SKU that could not be reserved
+		responsex.Error(c, http.StatusConflict, "OUT_OF_STOCK", err.Error(), nil)
+	case errors.Is(err, usecase.ErrNotFound):
+		responsex.Error(c, http.StatusNotFound, "NOT_FOUND", err.Error(), nil)
+	case errors.Is(err, usecase.ErrConflict):
+		responsex.Error(c, http.StatusConflict, "RESERVATION_RELEASED", "reservation was already released", nil)
+	case errors.Is(err, usecase.ErrInvalid):
+		responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", "invalid input", nil)
+	case errors.Is(err, usecase.ErrUnavailable):
+		c.Header("Retry-After", "2")
+		responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "service temporarily unavailable", nil)
+	default:
+		h.log.Error("unexpected error", logx.Err(err))
+		responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+	}
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/inventoryservice/internal/usecase"
+)
+
+// StockHandler handles stock-related HTTP requests
+type StockHandler struct {
+	service *usecase.StockService
+	log     logx.Logger
+}
+
+// NewStockHandler creates a new stock handler
+func NewStockHandler(service *usecase.StockService, log logx.Logger) *StockHandler {
+	return &StockHandler{service: service, log: log}
+}
+
+// SetStockRequest represents the request payload for creating or restocking a SKU
+type SetStockRequest struct {
+	Name      string `json:"name" binding:"required"`
+	Available *int   `json:"available" binding:"required"`
+}
+
+// SetStock handles PUT /stock/:sku
+func (h *StockHandler) SetStock(c *gin.Context) {
+	var req SetStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid request payload", nil)
+		return
+	}
+
+	stock, err := h.service.SetStock(c.Request.Context(), c.Param("sku"), req.Name, *req.Available)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.OK(c, FromDomainStock(stock), nil)
+}
+
+// GetStock handles GET /stock/:sku
+func (h *StockHandler) GetStock(c *gin.Context) {
+	sku := c.Param("sku")
+	if sku == "" {
+		responsex.Error(c, http.StatusBadRequest, "MISSING_PARAMETER", "sku is required", nil)
+		return
+	}
+
+	stock, err := h.service.GetStock(c.Request.Context(), sku)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.OK(c, FromDomainStock(stock), nil)
+}
+
+// handleError maps usecase errors to HTTP responses
+func (h *StockHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrNotFound):
+		responsex.Error(c, http.StatusNotFound, "STOCK_NOT_FOUND", "sku not found", nil)
+	case errors.Is(err, usecase.ErrInvalid):
+		responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", "invalid input", nil)
+	case errors.Is(err, usecase.ErrUnavailable):
+		c.Header("Retry-After", "2")
+		responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "service temporarily unavailable", nil)
+	default:
+		h.log.Error("unexpected error", logx.Err(err))
+		responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+	}
+}
@@ -0,0 +1,21 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gostratum/examples/inventoryservice/configs"
+)
+
+// serviceName names inventoryservice in /version
+const serviceName = "inventoryservice"
+
+// RegisterVersionRoute registers GET /version, which reports the service and
+// the configuration profile it runs with. This function is designed to be
+// used with fx.Invoke.
+func RegisterVersionRoute(e *gin.Engine, profile configs.Profile) {
+	e.GET("/version", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"service": serviceName, "profile": profile.Name})
+	})
+}
@@ -0,0 +1,24 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gostratum/examples/inventoryservice/configs"
+)
+
+func TestRegisterVersionRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	e := gin.New()
+	RegisterVersionRoute(e, configs.Profile{Name: "staging"})
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"service":"inventoryservice","profile":"staging"}`, w.Body.String())
+}
@@ -0,0 +1,98 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/gostratum/examples/inventoryservice/internal/domain"
+)
+
+// StockEntity represents the GORM model for stock_items table
+type StockEntity struct {
+	SKU       string    `gorm:"primaryKey"`
+	Name      string    `gorm:"not null"`
+	Available int       `gorm:"not null"`
+	Reserved  int       `gorm:"not null;default:0"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName specifies the table name for StockEntity
+func (StockEntity) TableName() string {
+	return "stock_items"
+}
+
+// ToDomain converts StockEntity to domain.Stock
+func (s *StockEntity) ToDomain() *domain.Stock {
+	return &domain.Stock{
+		SKU:       s.SKU,
+		Name:      s.Name,
+		Available: s.Available,
+		Reserved:  s.Reserved,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+// FromDomain creates StockEntity from domain.Stock
+func (s *StockEntity) FromDomain(stock *domain.Stock) {
+	s.SKU = stock.SKU
+	s.Name = stock.Name
+	s.Available = stock.Available
+	s.Reserved = stock.Reserved
+	s.UpdatedAt = stock.UpdatedAt
+}
+
+// ReservationLineEntity represents the GORM model for reservation_lines table
+type ReservationLineEntity struct {
+	ID            uint   `gorm:"primaryKey"`
+	ReservationID string `gorm:"type:uuid;not null;index"`
+	SKU           string `gorm:"not null"`
+	Qty           int    `gorm:"not null"`
+}
+
+// TableName specifies the table name for ReservationLineEntity
+func (ReservationLineEntity) TableName() string {
+	return "reservation_lines"
+}
+
+// ReservationEntity represents the GORM model for reservations table
+type ReservationEntity struct {
+	ID        string                  `gorm:"primaryKey;type:uuid"`
+	OrderID   string                  `gorm:"uniqueIndex;not null"`
+	Lines     []ReservationLineEntity `gorm:"foreignKey:ReservationID"`
+	Status    string                  `gorm:"not null"`
+	CreatedAt time.Time               `gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for ReservationEntity
+func (ReservationEntity) TableName() string {
+	return "reservations"
+}
+
+// ToDomain converts ReservationEntity to domain.Reservation
+func (r *ReservationEntity) ToDomain() *domain.Reservation {
+	lines := make([]domain.ReservationLine, len(r.Lines))
+	for i, line := range r.Lines {
+		lines[i] = domain.ReservationLine{SKU: line.SKU, Qty: line.Qty}
+	}
+
+	return &domain.Reservation{
+		ID:        r.ID,
+		OrderID:   r.OrderID,
+		Lines:     lines,
+		Status:    r.Status,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+// FromDomain creates ReservationEntity from domain.Reservation
+func (r *ReservationEntity) FromDomain(reservation *domain.Reservation) {
+	r.ID = reservation.ID
+	r.OrderID = reservation.OrderID
+	r.Status = reservation.Status
+	r.CreatedAt = reservation.CreatedAt
+
+	lines := make([]ReservationLineEntity, len(reservation.Lines))
+	for i, line := range reservation.Lines {
+		lines[i] = ReservationLineEntity{ReservationID: reservation.ID, SKU: line.SKU, Qty: line.Qty}
+	}
+	r.Lines = lines
+}
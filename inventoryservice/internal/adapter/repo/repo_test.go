@@ -0,0 +1,137 @@
+package repo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gostratum/examples/inventoryservice/internal/domain"
+	"github.com/gostratum/examples/inventoryservice/internal/usecase"
+	"github.com/gostratum/examples/inventoryservice/migrations"
+	"github.com/gostratum/examples/testkit/sqlitedb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// setupTestDB creates an in-memory SQLite database with the service's schema
+func setupTestDB(t *testing.T) *gorm.DB {
+	return sqlitedb.Open(t, migrations.FS)
+}
+
+func seedStock(t *testing.T, repo usecase.StockRepository, sku string, available int) {
+	t.Helper()
+	require.NoError(t, repo.Save(context.Background(), domain.NewStock(sku, "Item "+sku, available)))
+}
+
+func newReservation(t *testing.T, orderID string, lines ...domain.ReservationLine) *domain.Reservation {
+	t.Helper()
+	r := domain.NewReservation(orderID)
+	for _, line := range lines {
+		require.NoError(t, r.AddLine(line))
+	}
+	return r
+}
+
+// TestStockRepo_Save tests creating and restocking SKUs
+func TestStockRepo_Save(t *testing.T) {
+	db := setupTestDB(t)
+	stockRepo := NewStockRepo(db)
+	reservationRepo := NewReservationRepo(db)
+	ctx := context.Background()
+
+	seedStock(t, stockRepo, "SKU1", 10)
+	require.NoError(t, reservationRepo.Create(ctx, newReservation(t, "order1", domain.ReservationLine{SKU: "SKU1", Qty: 4})))
+
+	t.Run("restock keeps reserved units", func(t *testing.T) {
+		stock := domain.NewStock("SKU1", "Renamed", 20)
+		require.NoError(t, stockRepo.Save(ctx, stock))
+
+		assert.Equal(t, "Renamed", stock.Name)
+		assert.Equal(t, 20, stock.Available)
+		assert.Equal(t, 4, stock.Reserved)
+	})
+
+	t.Run("find non-existing sku", func(t *testing.T) {
+		found, err := stockRepo.FindBySKU(ctx, "missing")
+		assert.Equal(t, usecase.ErrNotFound, err)
+		assert.Nil(t, found)
+	})
+}
+
+// TestReservationRepo_Create tests that reservations are all or nothing
+func TestReservationRepo_Create(t *testing.T) {
+	db := setupTestDB(t)
+	stockRepo := NewStockRepo(db)
+	reservationRepo := NewReservationRepo(db)
+	ctx := context.Background()
+
+	seedStock(t, stockRepo, "SKU1", 5)
+	seedStock(t, stockRepo, "SKU2", 1)
+
+	t.Run("reserve available stock", func(t *testing.T) {
+		r := newReservation(t, "order1",
+			domain.ReservationLine{SKU: "SKU1", Qty: 2},
+			domain.ReservationLine{SKU: "SKU2", Qty: 1},
+		)
+		require.NoError(t, reservationRepo.Create(ctx, r))
+
+		stock, err := stockRepo.FindBySKU(ctx, "SKU1")
+		require.NoError(t, err)
+		assert.Equal(t, 3, stock.Available)
+		assert.Equal(t, 2, stock.Reserved)
+
+		found, err := reservationRepo.FindByOrderID(ctx, "order1")
+		require.NoError(t, err)
+		assert.Equal(t, r.ID, found.ID)
+		assert.Len(t, found.Lines, 2)
+	})
+
+	t.Run("insufficient stock reserves nothing", func(t *testing.T) {
+		r := newReservation(t, "order2",
+			domain.ReservationLine{SKU: "SKU1", Qty: 1},
+			domain.ReservationLine{SKU: "SKU2", Qty: 1},
+		)
+		err := reservationRepo.Create(ctx, r)
+		assert.ErrorIs(t, err, domain.ErrInsufficientStock)
+		assert.Contains(t, err.Error(), "SKU2")
+
+		stock, err := stockRepo.FindBySKU(ctx, "SKU1")
+		require.NoError(t, err)
+		assert.Equal(t, 3, stock.Available, "the SKU1 decrement must be rolled back")
+
+		_, err = reservationRepo.FindByOrderID(ctx, "order2")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("unknown sku", func(t *testing.T) {
+		err := reservationRepo.Create(ctx, newReservation(t, "order3", domain.ReservationLine{SKU: "SKU9", Qty: 1}))
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+// TestReservationRepo_Release tests returning reserved stock
+func TestReservationRepo_Release(t *testing.T) {
+	db := setupTestDB(t)
+	stockRepo := NewStockRepo(db)
+	reservationRepo := NewReservationRepo(db)
+	ctx := context.Background()
+
+	seedStock(t, stockRepo, "SKU1", 5)
+	r := newReservation(t, "order1", domain.ReservationLine{SKU: "SKU1", Qty: 5})
+	require.NoError(t, reservationRepo.Create(ctx, r))
+
+	require.NoError(t, r.Release())
+	require.NoError(t, reservationRepo.Release(ctx, r))
+
+	stock, err := stockRepo.FindBySKU(ctx, "SKU1")
+	require.NoError(t, err)
+	assert.Equal(t, 5, stock.Available)
+	assert.Equal(t, 0, stock.Reserved)
+
+	found, err := reservationRepo.FindByID(ctx, r.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReservationReleased, found.Status)
+
+	// A second release must not return the units again
+	assert.ErrorIs(t, reservationRepo.Release(ctx, r), domain.ErrConflict)
+}
@@ -0,0 +1,121 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gostratum/examples/inventoryservice/internal/domain"
+	"github.com/gostratum/examples/inventoryservice/internal/usecase"
+	"gorm.io/gorm"
+)
+
+// ReservationRepo implements the ReservationRepository interface using GORM
+type ReservationRepo struct {
+	db *gorm.DB
+}
+
+// NewReservationRepo creates a new GORM-based reservation repository
+func NewReservationRepo(db *gorm.DB) usecase.ReservationRepository {
+	return &ReservationRepo{db: db}
+}
+
+// Create decrements available stock for every line and stores the reservation in one
+// transaction. Each decrement is a conditional UPDATE, so concurrent reservations can
+// never oversell a SKU.
+func (r *ReservationRepo) Create(ctx context.Context, reservation *domain.Reservation) error {
+	var entity ReservationEntity
+	entity.FromDomain(reservation)
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lines are sorted by SKU, so concurrent transactions lock rows in the same order
+		for _, line := range reservation.Lines {
+			res := tx.Model(&StockEntity{}).
+				Where("sku = ? AND available >= ?", line.SKU, line.Qty).
+				Updates(map[string]any{
+					"available": gorm.Expr("available - ?", line.Qty),
+					"reserved":  gorm.Expr("reserved + ?", line.Qty),
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return r.missingStock(tx, line.SKU)
+			}
+		}
+
+		if err := tx.Create(&entity).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return domain.ErrConflict
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// missingStock explains why a conditional stock update matched no row
+func (r *ReservationRepo) missingStock(tx *gorm.DB, sku string) error {
+	var count int64
+	if err := tx.Model(&StockEntity{}).Where("sku = ?", sku).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: sku %s", domain.ErrNotFound, sku)
+	}
+	return fmt.Errorf("%w: sku %s", domain.ErrInsufficientStock, sku)
+}
+
+// FindByID retrieves a reservation by its ID, including all lines
+func (r *ReservationRepo) FindByID(ctx context.Context, id string) (*domain.Reservation, error) {
+	return r.findOne(ctx, "id = ?", id)
+}
+
+// FindByOrderID retrieves the reservation made for an order
+func (r *ReservationRepo) FindByOrderID(ctx context.Context, orderID string) (*domain.Reservation, error) {
+	return r.findOne(ctx, "order_id = ?", orderID)
+}
+
+func (r *ReservationRepo) findOne(ctx context.Context, query string, arg string) (*domain.Reservation, error) {
+	var entity ReservationEntity
+
+	err := r.db.WithContext(ctx).Preload("Lines").Where(query, arg).First(&entity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		// Return raw error - use case layer will translate to ErrUnavailable
+		return nil, err
+	}
+
+	return entity.ToDomain(), nil
+}
+
+// Release marks the reservation released and returns its units to stock. The status
+// update only matches a reservation that is still held, so stock is returned once.
+func (r *ReservationRepo) Release(ctx context.Context, reservation *domain.Reservation) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&ReservationEntity{}).
+			Where("id = ? AND status = ?", reservation.ID, domain.ReservationReserved).
+			Update("status", domain.ReservationReleased)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrConflict
+		}
+
+		for _, line := range reservation.Lines {
+			err := tx.Model(&StockEntity{}).
+				Where("sku = ?", line.SKU).
+				Updates(map[string]any{
+					"available": gorm.Expr("available + ?", line.Qty),
+					"reserved":  gorm.Expr("reserved - ?", line.Qty),
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
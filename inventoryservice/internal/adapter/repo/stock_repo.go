@@ -0,0 +1,65 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gostratum/examples/inventoryservice/internal/domain"
+	"github.com/gostratum/examples/inventoryservice/internal/usecase"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StockRepo implements the StockRepository interface using GORM
+type StockRepo struct {
+	db *gorm.DB
+}
+
+// NewStockRepo creates a new GORM-based stock repository
+func NewStockRepo(db *gorm.DB) usecase.StockRepository {
+	return &StockRepo{db: db}
+}
+
+// Save inserts the stock record or updates its name and available units.
+// Reserved units are owned by reservations and are never overwritten here.
+func (r *StockRepo) Save(ctx context.Context, stock *domain.Stock) error {
+	var entity StockEntity
+	entity.FromDomain(stock)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "sku"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "available", "updated_at"}),
+		}).Create(&entity).Error
+		if err != nil {
+			return err
+		}
+
+		// Reload to pick up the reserved units of an existing SKU
+		if err := tx.Where("sku = ?", entity.SKU).First(&entity).Error; err != nil {
+			return err
+		}
+
+		*stock = *entity.ToDomain()
+		return nil
+	})
+
+	// Return raw error - use case layer will translate to ErrUnavailable if needed
+	return err
+}
+
+// FindBySKU retrieves the stock record of a SKU
+func (r *StockRepo) FindBySKU(ctx context.Context, sku string) (*domain.Stock, error) {
+	var entity StockEntity
+
+	err := r.db.WithContext(ctx).Where("sku = ?", sku).First(&entity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		// Return raw error - use case layer will translate to ErrUnavailable
+		return nil, err
+	}
+
+	return entity.ToDomain(), nil
+}
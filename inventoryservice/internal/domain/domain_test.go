@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStockValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		stock   *Stock
+		wantErr bool
+	}{
+		{name: "valid stock", stock: NewStock("SKU1", "Widget", 10), wantErr: false},
+		{name: "zero available", stock: NewStock("SKU1", "Widget", 0), wantErr: false},
+		{name: "empty sku", stock: NewStock("  ", "Widget", 10), wantErr: true},
+		{name: "empty name", stock: NewStock("SKU1", "", 10), wantErr: true},
+		{name: "negative available", stock: NewStock("SKU1", "Widget", -1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.stock.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Stock.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStockReserveAndRelease(t *testing.T) {
+	stock := NewStock("SKU1", "Widget", 5)
+
+	if err := stock.Reserve(3); err != nil {
+		t.Fatalf("Reserve(3) error = %v", err)
+	}
+	if stock.Available != 2 || stock.Reserved != 3 {
+		t.Errorf("after Reserve(3) got available=%d reserved=%d, want 2 and 3", stock.Available, stock.Reserved)
+	}
+
+	if err := stock.Reserve(3); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("Reserve(3) error = %v, want ErrInsufficientStock", err)
+	}
+	if err := stock.Reserve(0); err == nil {
+		t.Error("Reserve(0) expected an error")
+	}
+
+	stock.Release(10)
+	if stock.Available != 5 || stock.Reserved != 0 {
+		t.Errorf("after Release got available=%d reserved=%d, want 5 and 0", stock.Available, stock.Reserved)
+	}
+}
+
+func TestReservationAddLine(t *testing.T) {
+	r := NewReservation("order123")
+
+	lines := []ReservationLine{
+		{SKU: "SKU2", Qty: 1},
+		{SKU: "SKU1", Qty: 2},
+		{SKU: "SKU2", Qty: 3},
+	}
+	for _, line := range lines {
+		if err := r.AddLine(line); err != nil {
+			t.Fatalf("AddLine(%+v) error = %v", line, err)
+		}
+	}
+
+	if len(r.Lines) != 2 {
+		t.Fatalf("expected 2 merged lines, got %d", len(r.Lines))
+	}
+	if r.Lines[0].SKU != "SKU1" || r.Lines[1].SKU != "SKU2" {
+		t.Errorf("expected lines sorted by SKU, got %+v", r.Lines)
+	}
+	if r.Lines[1].Qty != 4 {
+		t.Errorf("expected SKU2 quantity 4, got %d", r.Lines[1].Qty)
+	}
+
+	if err := r.AddLine(ReservationLine{SKU: "", Qty: 1}); err == nil {
+		t.Error("AddLine with empty SKU expected an error")
+	}
+	if err := r.AddLine(ReservationLine{SKU: "SKU1", Qty: 0}); err == nil {
+		t.Error("AddLine with zero quantity expected an error")
+	}
+}
+
+func TestReservationValidate(t *testing.T) {
+	withLine := NewReservation("order123")
+	_ = withLine.AddLine(ReservationLine{SKU: "SKU1", Qty: 1})
+
+	noOrder := NewReservation("")
+	_ = noOrder.AddLine(ReservationLine{SKU: "SKU1", Qty: 1})
+
+	tests := []struct {
+		name        string
+		reservation *Reservation
+		wantErr     bool
+	}{
+		{name: "valid reservation", reservation: withLine, wantErr: false},
+		{name: "empty order id", reservation: noOrder, wantErr: true},
+		{name: "no lines", reservation: NewReservation("order123"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.reservation.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Reservation.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReservationRelease(t *testing.T) {
+	r := NewReservation("order123")
+
+	if err := r.Release(); err != nil {
+		t.Fatalf("first Release() error = %v", err)
+	}
+	if r.Status != ReservationReleased {
+		t.Errorf("expected status %q, got %q", ReservationReleased, r.Status)
+	}
+	if err := r.Release(); !errors.Is(err, ErrConflict) {
+		t.Errorf("second Release() error = %v, want ErrConflict", err)
+	}
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reservation statuses
+const (
+	ReservationReserved = "reserved"
+	ReservationReleased = "released"
+)
+
+// ReservationLine is the quantity of one SKU held by a reservation
+type ReservationLine struct {
+	SKU string
+	Qty int
+}
+
+// Reservation holds stock for an order until the order is completed or cancelled
+// This is a pure domain model without infrastructure concerns
+type Reservation struct {
+	ID        string
+	OrderID   string
+	Lines     []ReservationLine
+	Status    string
+	CreatedAt time.Time
+}
+
+// NewReservation creates an empty reservation for an order with a generated ID
+func NewReservation(orderID string) *Reservation {
+	return &Reservation{
+		ID:        uuid.New().String(),
+		OrderID:   strings.TrimSpace(orderID),
+		Lines:     []ReservationLine{},
+		Status:    ReservationReserved,
+		CreatedAt: time.Now(),
+	}
+}
+
+// AddLine adds a SKU to the reservation, merging quantities of repeated SKUs
+func (r *Reservation) AddLine(line ReservationLine) error {
+	line.SKU = strings.TrimSpace(line.SKU)
+	if line.SKU == "" {
+		return errors.New("line SKU is required")
+	}
+	if line.Qty <= 0 {
+		return errors.New("line quantity must be positive")
+	}
+
+	for i := range r.Lines {
+		if r.Lines[i].SKU == line.SKU {
+			r.Lines[i].Qty += line.Qty
+			return nil
+		}
+	}
+
+	r.Lines = append(r.Lines, line)
+	// Keep lines sorted so stock rows are always locked in the same order
+	sort.Slice(r.Lines, func(i, j int) bool { return r.Lines[i].SKU < r.Lines[j].SKU })
+	return nil
+}
+
+// Release marks the reservation as released
+func (r *Reservation) Release() error {
+	if r.Status == ReservationReleased {
+		return ErrConflict
+	}
+	r.Status = ReservationReleased
+	return nil
+}
+
+// Validate performs basic validation on reservation fields
+// Line-level validation is already done in AddLine()
+func (r *Reservation) Validate() error {
+	if r.OrderID == "" {
+		return errors.New("order_id is required")
+	}
+	if len(r.Lines) == 0 {
+		return errors.New("reservation must have at least one line")
+	}
+	return nil
+}
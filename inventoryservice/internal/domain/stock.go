@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Stock represents the stock level of a single SKU
+// This is a pure domain model without infrastructure concerns
+type Stock struct {
+	SKU       string
+	Name      string
+	Available int
+	Reserved  int
+	UpdatedAt time.Time
+}
+
+// NewStock creates a stock record with the given available units
+func NewStock(sku, name string, available int) *Stock {
+	return &Stock{
+		SKU:       strings.TrimSpace(sku),
+		Name:      strings.TrimSpace(name),
+		Available: available,
+		UpdatedAt: time.Now(),
+	}
+}
+
+// Reserve moves qty units from available to reserved
+func (s *Stock) Reserve(qty int) error {
+	if qty <= 0 {
+		return errors.New("quantity must be positive")
+	}
+	if s.Available < qty {
+		return ErrInsufficientStock
+	}
+
+	s.Available -= qty
+	s.Reserved += qty
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// Release returns qty previously reserved units to available
+func (s *Stock) Release(qty int) {
+	if qty > s.Reserved {
+		qty = s.Reserved
+	}
+
+	s.Reserved -= qty
+	s.Available += qty
+	s.UpdatedAt = time.Now()
+}
+
+// Validate performs basic validation on stock fields
+func (s *Stock) Validate() error {
+	if s.SKU == "" {
+		return errors.New("sku is required")
+	}
+	if s.Name == "" {
+		return errors.New("name is required")
+	}
+	if s.Available < 0 {
+		return errors.New("available units cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/gostratum/examples/inventoryservice/internal/domain"
+)
+
+// StockRepository defines the interface for stock data operations
+// This interface is owned by the use case layer (dependency inversion principle)
+type StockRepository interface {
+	// Save creates the stock record or replaces its name and available units
+	Save(ctx context.Context, s *domain.Stock) error
+	FindBySKU(ctx context.Context, sku string) (*domain.Stock, error)
+}
+
+// ReservationRepository defines the interface for reservation data operations
+// This interface is owned by the use case layer (dependency inversion principle)
+type ReservationRepository interface {
+	// Create reserves stock for every line and stores the reservation atomically.
+	// It returns domain.ErrInsufficientStock (wrapped with the SKU) when any line
+	// cannot be reserved, in which case nothing is reserved.
+	Create(ctx context.Context, r *domain.Reservation) error
+	FindByID(ctx context.Context, id string) (*domain.Reservation, error)
+	FindByOrderID(ctx context.Context, orderID string) (*domain.Reservation, error)
+	// Release returns the reserved units to stock and marks the reservation released
+	Release(ctx context.Context, r *domain.Reservation) error
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gostratum/examples/inventoryservice/internal/domain"
+)
+
+// ReservationService handles stock reservations for orders
+type ReservationService struct {
+	repo ReservationRepository
+}
+
+// NewReservationService creates a new reservation service with repository injection
+func NewReservationService(repo ReservationRepository) *ReservationService {
+	return &ReservationService{
+		repo: repo,
+	}
+}
+
+// Reserve holds stock for every line of an order, all or nothing.
+// Reserving again for the same order returns the existing reservation, so callers
+// such as orderservice can safely retry.
+func (s *ReservationService) Reserve(ctx context.Context, orderID string, lines []domain.ReservationLine) (*domain.Reservation, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	reservation := domain.NewReservation(orderID)
+	for _, line := range lines {
+		if err := reservation.AddLine(line); err != nil {
+			return nil, ErrInvalid
+		}
+	}
+
+	if err := reservation.Validate(); err != nil {
+		return nil, ErrInvalid
+	}
+
+	existing, err := s.repo.FindByOrderID(ctx, reservation.OrderID)
+	switch {
+	case err == nil && existing.Status == domain.ReservationReserved:
+		return existing, nil
+	case err == nil:
+		// The order's stock was already released; it needs a new order ID
+		return nil, ErrConflict
+	case !errors.Is(err, domain.ErrNotFound):
+		return nil, translateError(err)
+	}
+
+	if err := s.repo.Create(ctx, reservation); err != nil {
+		return nil, translateError(err)
+	}
+
+	return reservation, nil
+}
+
+// GetReservation retrieves a reservation by ID
+func (s *ReservationService) GetReservation(ctx context.Context, id string) (*domain.Reservation, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	reservation, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return reservation, nil
+}
+
+// Release returns the stock held by a reservation, e.g. when its order failed or was cancelled
+func (s *ReservationService) Release(ctx context.Context, id string) (*domain.Reservation, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	reservation, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	if err := reservation.Release(); err != nil {
+		return nil, translateError(err)
+	}
+
+	if err := s.repo.Release(ctx, reservation); err != nil {
+		return nil, translateError(err)
+	}
+
+	return reservation, nil
+}
@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gostratum/examples/inventoryservice/internal/domain"
+)
+
+// MockReservationRepository implements ReservationRepository on top of MockStockRepository
+type MockReservationRepository struct {
+	stock        *MockStockRepository
+	reservations map[string]*domain.Reservation
+	createError  error
+}
+
+func NewMockReservationRepository(stock *MockStockRepository) *MockReservationRepository {
+	return &MockReservationRepository{
+		stock:        stock,
+		reservations: make(map[string]*domain.Reservation),
+	}
+}
+
+func (m *MockReservationRepository) Create(ctx context.Context, r *domain.Reservation) error {
+	if m.createError != nil {
+		return m.createError
+	}
+	for _, line := range r.Lines {
+		stock, ok := m.stock.stock[line.SKU]
+		if !ok {
+			return fmt.Errorf("%w: sku %s", domain.ErrNotFound, line.SKU)
+		}
+		if stock.Available < line.Qty {
+			return fmt.Errorf("%w: sku %s", domain.ErrInsufficientStock, line.SKU)
+		}
+	}
+	for _, line := range r.Lines {
+		_ = m.stock.stock[line.SKU].Reserve(line.Qty)
+	}
+	m.reservations[r.ID] = r
+	return nil
+}
+
+func (m *MockReservationRepository) FindByID(ctx context.Context, id string) (*domain.Reservation, error) {
+	r, ok := m.reservations[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return r, nil
+}
+
+func (m *MockReservationRepository) FindByOrderID(ctx context.Context, orderID string) (*domain.Reservation, error) {
+	for _, r := range m.reservations {
+		if r.OrderID == orderID {
+			return r, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (m *MockReservationRepository) Release(ctx context.Context, r *domain.Reservation) error {
+	for _, line := range r.Lines {
+		m.stock.stock[line.SKU].Release(line.Qty)
+	}
+	m.reservations[r.ID] = r
+	return nil
+}
+
+func newReservationFixture() (*ReservationService, *MockStockRepository, *MockReservationRepository) {
+	stock := NewMockStockRepository()
+	stock.stock["SKU1"] = domain.NewStock("SKU1", "Widget", 5)
+	stock.stock["SKU2"] = domain.NewStock("SKU2", "Gadget", 1)
+	reservations := NewMockReservationRepository(stock)
+	return NewReservationService(reservations), stock, reservations
+}
+
+func TestReserve(t *testing.T) {
+	tests := []struct {
+		name        string
+		orderID     string
+		lines       []domain.ReservationLine
+		createError error
+		wantErr     error
+	}{
+		{
+			name:    "available stock should be reserved",
+			orderID: "order1",
+			lines:   []domain.ReservationLine{{SKU: "SKU1", Qty: 2}, {SKU: "SKU2", Qty: 1}},
+		},
+		{
+			name:    "empty order id should return invalid error",
+			orderID: "",
+			lines:   []domain.ReservationLine{{SKU: "SKU1", Qty: 1}},
+			wantErr: ErrInvalid,
+		},
+		{
+			name:    "no lines should return invalid error",
+			orderID: "order1",
+			lines:   nil,
+			wantErr: ErrInvalid,
+		},
+		{
+			name:    "zero quantity should return invalid error",
+			orderID: "order1",
+			lines:   []domain.ReservationLine{{SKU: "SKU1", Qty: 0}},
+			wantErr: ErrInvalid,
+		},
+		{
+			name:    "insufficient stock should return out of stock error",
+			orderID: "order1",
+			lines:   []domain.ReservationLine{{SKU: "SKU1", Qty: 1}, {SKU: "SKU2", Qty: 2}},
+			wantErr: ErrOutOfStock,
+		},
+		{
+			name:    "unknown sku should return not found error",
+			orderID: "order1",
+			lines:   []domain.ReservationLine{{SKU: "SKU9", Qty: 1}},
+			wantErr: ErrNotFound,
+		},
+		{
+			name:        "repository error should return unavailable error",
+			orderID:     "order1",
+			lines:       []domain.ReservationLine{{SKU: "SKU1", Qty: 1}},
+			createError: errors.New("database connection failed"),
+			wantErr:     ErrUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, stock, reservations := newReservationFixture()
+			reservations.createError = tt.createError
+
+			reservation, err := service.Reserve(context.Background(), tt.orderID, tt.lines)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Reserve() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if reservation != nil {
+					t.Errorf("Reserve() should return nil reservation on error, got %v", reservation)
+				}
+				if stock.stock["SKU1"].Available != 5 {
+					t.Errorf("failed Reserve() must not change stock, SKU1 available = %d", stock.stock["SKU1"].Available)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Reserve() unexpected error = %v", err)
+			}
+			if reservation.Status != domain.ReservationReserved {
+				t.Errorf("Reserve() status = %s, want %s", reservation.Status, domain.ReservationReserved)
+			}
+			if stock.stock["SKU1"].Available != 3 || stock.stock["SKU1"].Reserved != 2 {
+				t.Errorf("Reserve() SKU1 = %+v, want 3 available and 2 reserved", stock.stock["SKU1"])
+			}
+		})
+	}
+}
+
+func TestReserveIsIdempotentPerOrder(t *testing.T) {
+	service, stock, _ := newReservationFixture()
+	ctx := context.Background()
+	lines := []domain.ReservationLine{{SKU: "SKU1", Qty: 2}}
+
+	first, err := service.Reserve(ctx, "order1", lines)
+	if err != nil {
+		t.Fatalf("first Reserve() error = %v", err)
+	}
+	second, err := service.Reserve(ctx, "order1", lines)
+	if err != nil {
+		t.Fatalf("second Reserve() error = %v", err)
+	}
+
+	if first.ID != second.ID {
+		t.Errorf("expected the existing reservation %s, got %s", first.ID, second.ID)
+	}
+	if stock.stock["SKU1"].Reserved != 2 {
+		t.Errorf("retried Reserve() must not reserve twice, reserved = %d", stock.stock["SKU1"].Reserved)
+	}
+
+	if _, err := service.Release(ctx, first.ID); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, err := service.Reserve(ctx, "order1", lines); !errors.Is(err, ErrConflict) {
+		t.Errorf("Reserve() after release error = %v, want ErrConflict", err)
+	}
+}
+
+func TestRelease(t *testing.T) {
+	service, stock, _ := newReservationFixture()
+	ctx := context.Background()
+
+	reservation, err := service.Reserve(ctx, "order1", []domain.ReservationLine{{SKU: "SKU1", Qty: 4}})
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+
+	released, err := service.Release(ctx, reservation.ID)
+	if err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if released.Status != domain.ReservationReleased {
+		t.Errorf("Release() status = %s, want %s", released.Status, domain.ReservationReleased)
+	}
+	if stock.stock["SKU1"].Available != 5 || stock.stock["SKU1"].Reserved != 0 {
+		t.Errorf("Release() SKU1 = %+v, want 5 available and 0 reserved", stock.stock["SKU1"])
+	}
+
+	if _, err := service.Release(ctx, reservation.ID); !errors.Is(err, ErrConflict) {
+		t.Errorf("second Release() error = %v, want ErrConflict", err)
+	}
+	if _, err := service.Release(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Release() of unknown reservation error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gostratum/examples/inventoryservice/internal/domain"
+)
+
+// StockService handles stock level business logic
+type StockService struct {
+	repo StockRepository
+}
+
+// NewStockService creates a new stock service with repository injection
+func NewStockService(repo StockRepository) *StockService {
+	return &StockService{
+		repo: repo,
+	}
+}
+
+// SetStock creates a SKU or replaces its name and available units (e.g. after a restock)
+func (s *StockService) SetStock(ctx context.Context, sku, name string, available int) (*domain.Stock, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	stock := domain.NewStock(sku, name, available)
+	if err := stock.Validate(); err != nil {
+		return nil, ErrInvalid
+	}
+
+	if err := s.repo.Save(ctx, stock); err != nil {
+		return nil, translateError(err)
+	}
+
+	return stock, nil
+}
+
+// GetStock retrieves the stock level of a SKU
+func (s *StockService) GetStock(ctx context.Context, sku string) (*domain.Stock, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	stock, err := s.repo.FindBySKU(ctx, sku)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return stock, nil
+}
+
+// translateError converts repository/domain errors to usecase errors
+func translateError(err error) error {
+	// Domain errors pass through unchanged, so messages keep the SKU they refer to
+	for _, domainErr := range []error{
+		domain.ErrInsufficientStock,
+		domain.ErrNotFound,
+		domain.ErrConflict,
+		domain.ErrInvalidInput,
+	} {
+		if errors.Is(err, domainErr) {
+			return err
+		}
+	}
+
+	// All other errors are infrastructure/availability issues
+	return ErrUnavailable
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gostratum/examples/inventoryservice/internal/domain"
+)
+
+// MockStockRepository implements StockRepository for testing
+type MockStockRepository struct {
+	stock     map[string]*domain.Stock
+	saveError error
+	findError error
+}
+
+func NewMockStockRepository() *MockStockRepository {
+	return &MockStockRepository{
+		stock: make(map[string]*domain.Stock),
+	}
+}
+
+func (m *MockStockRepository) Save(ctx context.Context, s *domain.Stock) error {
+	if m.saveError != nil {
+		return m.saveError
+	}
+	if existing, ok := m.stock[s.SKU]; ok {
+		s.Reserved = existing.Reserved
+	}
+	m.stock[s.SKU] = s
+	return nil
+}
+
+func (m *MockStockRepository) FindBySKU(ctx context.Context, sku string) (*domain.Stock, error) {
+	if m.findError != nil {
+		return nil, m.findError
+	}
+	stock, exists := m.stock[sku]
+	if !exists {
+		return nil, domain.ErrNotFound
+	}
+	return stock, nil
+}
+
+func TestSetStock(t *testing.T) {
+	tests := []struct {
+		name      string
+		sku       string
+		stockName string
+		available int
+		saveError error
+		wantErr   error
+	}{
+		{name: "valid stock", sku: "SKU1", stockName: "Widget", available: 10},
+		{name: "empty sku should return invalid error", sku: "", stockName: "Widget", available: 10, wantErr: ErrInvalid},
+		{name: "negative units should return invalid error", sku: "SKU1", stockName: "Widget", available: -5, wantErr: ErrInvalid},
+		{
+			name:      "repository error should return unavailable error",
+			sku:       "SKU1",
+			stockName: "Widget",
+			available: 10,
+			saveError: errors.New("database connection failed"),
+			wantErr:   ErrUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockStockRepository()
+			repo.saveError = tt.saveError
+
+			service := NewStockService(repo)
+			stock, err := service.SetStock(context.Background(), tt.sku, tt.stockName, tt.available)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("SetStock() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if stock != nil {
+					t.Errorf("SetStock() should return nil stock on error, got %v", stock)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("SetStock() unexpected error = %v", err)
+			}
+			if stock.SKU != tt.sku || stock.Available != tt.available {
+				t.Errorf("SetStock() = %+v, want sku %s with %d available", stock, tt.sku, tt.available)
+			}
+		})
+	}
+}
+
+func TestGetStock(t *testing.T) {
+	tests := []struct {
+		name      string
+		sku       string
+		findError error
+		wantErr   error
+	}{
+		{name: "existing stock should be returned", sku: "SKU1"},
+		{name: "unknown sku should return not found error", sku: "SKU2", wantErr: ErrNotFound},
+		{
+			name:      "repository error should return unavailable error",
+			sku:       "SKU1",
+			findError: errors.New("database connection failed"),
+			wantErr:   ErrUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockStockRepository()
+			repo.stock["SKU1"] = domain.NewStock("SKU1", "Widget", 3)
+			repo.findError = tt.findError
+
+			service := NewStockService(repo)
+			stock, err := service.GetStock(context.Background(), tt.sku)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("GetStock() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("GetStock() unexpected error = %v", err)
+			}
+			if stock.Available != 3 {
+				t.Errorf("GetStock() available = %d, want 3", stock.Available)
+			}
+		})
+	}
+}
@@ -0,0 +1,2 @@
+-- Drop stock_items table
+DROP TABLE IF EXISTS stock_items CASCADE;
@@ -0,0 +1,8 @@
+-- Create stock_items table
+CREATE TABLE IF NOT EXISTS stock_items (
+    sku VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    available INTEGER NOT NULL CHECK (available >= 0),
+    reserved INTEGER NOT NULL DEFAULT 0 CHECK (reserved >= 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+-- Drop reservations and their lines
+DROP TABLE IF EXISTS reservation_lines;
+DROP TABLE IF EXISTS reservations;
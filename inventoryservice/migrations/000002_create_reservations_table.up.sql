@@ -0,0 +1,16 @@
+-- Create reservations and their lines
+CREATE TABLE IF NOT EXISTS reservations (
+    id UUID PRIMARY KEY,
+    order_id VARCHAR(64) NOT NULL UNIQUE,
+    status VARCHAR(20) NOT NULL DEFAULT 'reserved',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS reservation_lines (
+    id SERIAL PRIMARY KEY,
+    reservation_id UUID NOT NULL REFERENCES reservations(id) ON DELETE CASCADE,
+    sku VARCHAR(64) NOT NULL REFERENCES stock_items(sku),
+    qty INTEGER NOT NULL CHECK (qty > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reservation_lines_reservation_id ON reservation_lines(reservation_id);
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/dbx"
+	httpAdapter "github.com/gostratum/examples/ledger-demo/internal/adapter/http"
+	repoAdapter "github.com/gostratum/examples/ledger-demo/internal/adapter/repo"
+	"github.com/gostratum/examples/ledger-demo/internal/projection"
+	"github.com/gostratum/examples/ledger-demo/internal/usecase"
+	"github.com/gostratum/examples/redact"
+	"github.com/gostratum/httpx"
+)
+
+func main() {
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// Include dbx module; it migrates the database on startup
+		dbx.Module(),
+
+		// Include httpx module
+		httpx.Module(),
+
+		// Applies new events to every projection below, from start to stop
+		projection.Module(),
+
+		// Provide dependencies
+		fx.Provide(
+			// Event store and read models
+			repoAdapter.NewEventStore,
+			repoAdapter.NewReadModels,
+
+			// Projections join the projection.projections group
+			projection.AsProjection(repoAdapter.NewAccountsProjection),
+			projection.AsProjection(repoAdapter.NewDailyTotalsProjection),
+
+			// Usecase services: the command and the query side
+			usecase.NewAccountCommands,
+			usecase.NewAccountQueries,
+
+			// HTTP handlers
+			httpAdapter.NewAccountHandler,
+			httpAdapter.NewProjectionHandler,
+		),
+
+		// Invoke setup functions
+		fx.Invoke(
+			httpAdapter.RegisterRoutes,
+		),
+	)
+
+	app.Run()
+}
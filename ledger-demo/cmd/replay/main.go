@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/dbx"
+	repoAdapter "github.com/gostratum/examples/ledger-demo/internal/adapter/repo"
+	"github.com/gostratum/examples/ledger-demo/internal/projection"
+	"github.com/gostratum/examples/ledger-demo/internal/replay"
+	"github.com/gostratum/examples/redact"
+)
+
+func main() {
+	var args replay.Args
+	flag.StringVar(&args.Projection, "projection", "", "projection to rebuild, or \"all\"")
+	flag.BoolVar(&args.Status, "status", false, "print how far each projection has come and exit")
+	flag.Parse()
+
+	if (args.Projection == "") == !args.Status || flag.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "usage: replay -projection <name|all> | -status")
+		flag.PrintDefaults()
+		os.Exit(replay.ExitUsage)
+	}
+
+	var cmd *replay.Command
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// The same database as the service; no httpx: nothing listens, the
+		// process ends when the replay does
+		dbx.Module(),
+		fx.Supply(args),
+
+		// Provide dependencies; the projector is not registered, so it only
+		// runs when the command calls it
+		fx.Provide(
+			repoAdapter.NewEventStore,
+			projection.AsProjection(repoAdapter.NewAccountsProjection),
+			projection.AsProjection(repoAdapter.NewDailyTotalsProjection),
+			projection.NewProjector,
+			replay.NewCommand,
+		),
+
+		// Run the replay once the application has started
+		fx.Invoke(replay.Register),
+		fx.Populate(&cmd),
+	)
+
+	// Run returns once the command has shut the application down, or after
+	// SIGINT/SIGTERM; it exits with status 1 by itself if startup fails
+	app.Run()
+	os.Exit(cmd.ExitCode())
+}
@@ -0,0 +1,200 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/ledger-demo/internal/domain"
+	"github.com/gostratum/examples/ledger-demo/internal/usecase"
+)
+
+// defaultReportDays is how many days GET /reports/daily covers by default
+const defaultReportDays = 7
+
+// Commands changes accounts; implemented by usecase.AccountCommands
+type Commands interface {
+	Open(ctx context.Context, owner string) (*domain.Account, error)
+	Deposit(ctx context.Context, id string, amount int64) (*domain.Account, error)
+	Withdraw(ctx context.Context, id string, amount int64) (*domain.Account, error)
+	Close(ctx context.Context, id string) (*domain.Account, error)
+}
+
+// Queries reads accounts; implemented by usecase.AccountQueries
+type Queries interface {
+	Account(ctx context.Context, id string) (*usecase.AccountView, error)
+	Accounts(ctx context.Context) ([]usecase.AccountView, error)
+	History(ctx context.Context, id string) ([]domain.RecordedEvent, error)
+	DailyTotals(ctx context.Context, days int) ([]usecase.DailyTotal, error)
+}
+
+// AccountHandler handles account-related HTTP requests. POST requests are
+// commands and answer with the account as the command left it; GET
+// requests are queries on the read models, which may not show the latest
+// command yet.
+type AccountHandler struct {
+	commands Commands
+	queries  Queries
+	log      logx.Logger
+}
+
+// NewAccountHandler creates a new account handler
+func NewAccountHandler(commands *usecase.AccountCommands, queries *usecase.AccountQueries, log logx.Logger) *AccountHandler {
+	return newAccountHandler(commands, queries, log)
+}
+
+func newAccountHandler(commands Commands, queries Queries, log logx.Logger) *AccountHandler {
+	return &AccountHandler{commands: commands, queries: queries, log: log}
+}
+
+// Open handles POST /accounts
+func (h *AccountHandler) Open(c *gin.Context) {
+	var req OpenAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid request payload", nil)
+		return
+	}
+
+	account, err := h.commands.Open(c.Request.Context(), req.Owner)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.Created(c, "/accounts/"+account.ID, FromDomainAccount(account))
+}
+
+// Deposit handles POST /accounts/:id/deposits
+func (h *AccountHandler) Deposit(c *gin.Context) {
+	h.move(c, h.commands.Deposit)
+}
+
+// Withdraw handles POST /accounts/:id/withdrawals
+func (h *AccountHandler) Withdraw(c *gin.Context) {
+	h.move(c, h.commands.Withdraw)
+}
+
+// move runs a deposit or withdrawal command
+func (h *AccountHandler) move(c *gin.Context, command func(ctx context.Context, id string, amount int64) (*domain.Account, error)) {
+	var req AmountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid request payload", nil)
+		return
+	}
+
+	account, err := command(c.Request.Context(), c.Param("id"), req.Amount)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.OK(c, FromDomainAccount(account), nil)
+}
+
+// Close handles POST /accounts/:id/close
+func (h *AccountHandler) Close(c *gin.Context) {
+	account, err := h.commands.Close(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.OK(c, FromDomainAccount(account), nil)
+}
+
+// GetAccount handles GET /accounts/:id
+func (h *AccountHandler) GetAccount(c *gin.Context) {
+	view, err := h.queries.Account(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.OK(c, FromAccountView(view), nil)
+}
+
+// ListAccounts handles GET /accounts
+func (h *AccountHandler) ListAccounts(c *gin.Context) {
+	views, err := h.queries.Accounts(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	resp := make([]*AccountResponse, len(views))
+	for i := range views {
+		resp[i] = FromAccountView(&views[i])
+	}
+	responsex.OK(c, resp, nil)
+}
+
+// History handles GET /accounts/:id/events
+func (h *AccountHandler) History(c *gin.Context) {
+	events, err := h.queries.History(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	resp := make([]EventResponse, len(events))
+	for i, e := range events {
+		resp[i] = FromRecordedEvent(e)
+	}
+	responsex.OK(c, resp, nil)
+}
+
+// DailyTotals handles GET /reports/daily. The optional days query parameter
+// sets how many days, today included, the report covers.
+func (h *AccountHandler) DailyTotals(c *gin.Context) {
+	days := defaultReportDays
+	if s := c.Query("days"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", "days must be a number", nil)
+			return
+		}
+		days = n
+	}
+
+	totals, err := h.queries.DailyTotals(c.Request.Context(), days)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	resp := make([]DailyTotalResponse, len(totals))
+	for i, t := range totals {
+		resp[i] = FromDailyTotal(t)
+	}
+	responsex.OK(c, resp, nil)
+}
+
+// handleError maps usecase errors to HTTP responses
+func (h *AccountHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrNotFound):
+		responsex.Error(c, http.StatusNotFound, "NOT_FOUND", "account not found", nil)
+	case errors.Is(err, usecase.ErrInvalid):
+		responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
+	case errors.Is(err, usecase.ErrInsufficientFunds):
+		responsex.Error(c, http.StatusUnprocessableEntity, "INSUFFICIENT_FUNDS", err.Error(), nil)
+	case errors.Is(err, usecase.ErrAccountClosed):
+		responsex.Error(c, http.StatusConflict, "ACCOUNT_CLOSED", "account is closed", nil)
+	case errors.Is(err, usecase.ErrBalanceNotZero):
+		responsex.Error(c, http.StatusConflict, "BALANCE_NOT_ZERO", err.Error(), nil)
+	case errors.Is(err, usecase.ErrConflict):
+		responsex.Error(c, http.StatusConflict, "CONCURRENT_UPDATE", "account is being changed by other requests; try again", nil)
+	case errors.Is(err, usecase.ErrUnavailable):
+		h.log.Error("database unavailable", logx.Err(err))
+		c.Header("Retry-After", "2")
+		responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "service temporarily unavailable", nil)
+	default:
+		h.log.Error("unexpected error", logx.Err(err))
+		responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+	}
+}
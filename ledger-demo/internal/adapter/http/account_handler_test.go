@@ -0,0 +1,215 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/ledger-demo/internal/domain"
+	"github.com/gostratum/examples/ledger-demo/internal/usecase"
+)
+
+const testAccountID = "0b8f9f4e-5c43-4d3e-9b8e-3f0f7c1d2a6b"
+
+// fakeLedger answers every command and query with account or err
+type fakeLedger struct {
+	account *domain.Account
+	events  []domain.RecordedEvent
+	days    int
+	err     error
+}
+
+func (f *fakeLedger) Open(ctx context.Context, owner string) (*domain.Account, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return domain.OpenAccount(testAccountID, owner)
+}
+
+func (f *fakeLedger) Deposit(ctx context.Context, id string, amount int64) (*domain.Account, error) {
+	return f.change(func(a *domain.Account) error { return a.Deposit(amount) })
+}
+
+func (f *fakeLedger) Withdraw(ctx context.Context, id string, amount int64) (*domain.Account, error) {
+	return f.change(func(a *domain.Account) error { return a.Withdraw(amount) })
+}
+
+func (f *fakeLedger) Close(ctx context.Context, id string) (*domain.Account, error) {
+	return f.change(func(a *domain.Account) error { return a.Close() })
+}
+
+func (f *fakeLedger) change(command func(*domain.Account) error) (*domain.Account, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if err := command(f.account); err != nil {
+		return nil, err
+	}
+	return f.account, nil
+}
+
+func (f *fakeLedger) Account(ctx context.Context, id string) (*usecase.AccountView, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	opened := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	return &usecase.AccountView{ID: id, Owner: "Jane Doe", Balance: 250, Version: 2, OpenedAt: opened, UpdatedAt: opened}, nil
+}
+
+func (f *fakeLedger) Accounts(ctx context.Context) ([]usecase.AccountView, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []usecase.AccountView{{ID: testAccountID, Owner: "Jane Doe"}}, nil
+}
+
+func (f *fakeLedger) History(ctx context.Context, id string) ([]domain.RecordedEvent, error) {
+	return f.events, f.err
+}
+
+func (f *fakeLedger) DailyTotals(ctx context.Context, days int) ([]usecase.DailyTotal, error) {
+	f.days = days
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []usecase.DailyTotal{{Day: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), Opened: 1, Deposits: 500}}, nil
+}
+
+func setupRouter(ledger *fakeLedger) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := newAccountHandler(ledger, ledger, logx.NewNoopLogger())
+
+	e := gin.New()
+	e.POST("/accounts", handler.Open)
+	e.POST("/accounts/:id/deposits", handler.Deposit)
+	e.POST("/accounts/:id/withdrawals", handler.Withdraw)
+	e.POST("/accounts/:id/close", handler.Close)
+	e.GET("/accounts", handler.ListAccounts)
+	e.GET("/accounts/:id", handler.GetAccount)
+	e.GET("/accounts/:id/events", handler.History)
+	e.GET("/reports/daily", handler.DailyTotals)
+	return e
+}
+
+func do(e *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w
+}
+
+func TestOpenAccount(t *testing.T) {
+	e := setupRouter(&fakeLedger{})
+
+	w := do(e, http.MethodPost, "/accounts", `{"owner":"Jane Doe"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, "/accounts/"+testAccountID, w.Header().Get("Location"))
+
+	var resp responsex.Envelope[AccountResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "open", resp.Data.Status)
+	assert.Equal(t, 1, resp.Data.Version)
+	assert.Nil(t, resp.Data.OpenedAt, "a command knows no read model times")
+
+	w = do(e, http.MethodPost, "/accounts", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_REQUEST")
+}
+
+func TestCommandErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		history  []domain.Event
+		err      error
+		path     string
+		body     string
+		wantCode int
+		wantErr  string
+	}{
+		{name: "deposit", path: "deposits", body: `{"amount":100}`, wantCode: http.StatusOK},
+		{name: "no amount", path: "deposits", body: `{}`, wantCode: http.StatusBadRequest, wantErr: "INVALID_REQUEST"},
+		{name: "negative amount", path: "deposits", body: `{"amount":-100}`, wantCode: http.StatusBadRequest, wantErr: "INVALID_INPUT"},
+		{name: "insufficient funds", path: "withdrawals", body: `{"amount":100}`, wantCode: http.StatusUnprocessableEntity, wantErr: "INSUFFICIENT_FUNDS"},
+		{name: "closed", history: []domain.Event{domain.AccountClosed{}}, path: "deposits", body: `{"amount":100}`, wantCode: http.StatusConflict, wantErr: "ACCOUNT_CLOSED"},
+		{name: "close with money", history: []domain.Event{domain.MoneyDeposited{Amount: 1}}, path: "close", wantCode: http.StatusConflict, wantErr: "BALANCE_NOT_ZERO"},
+		{name: "not found", err: usecase.ErrNotFound, path: "close", wantCode: http.StatusNotFound, wantErr: "NOT_FOUND"},
+		{name: "concurrent update", err: fmt.Errorf("%w: changed 3 times", usecase.ErrConflict), path: "close", wantCode: http.StatusConflict, wantErr: "CONCURRENT_UPDATE"},
+		{name: "unavailable", err: fmt.Errorf("%w: connection refused", usecase.ErrUnavailable), path: "close", wantCode: http.StatusServiceUnavailable, wantErr: "SERVICE_UNAVAILABLE"},
+		{name: "unexpected", err: errors.New("boom"), path: "close", wantCode: http.StatusInternalServerError, wantErr: "INTERNAL_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account, err := domain.LoadAccount(testAccountID, append([]domain.Event{domain.AccountOpened{Owner: "Jane Doe"}}, tt.history...))
+			require.NoError(t, err)
+			e := setupRouter(&fakeLedger{account: account, err: tt.err})
+
+			w := do(e, http.MethodPost, "/accounts/"+testAccountID+"/"+tt.path, tt.body)
+			assert.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			if tt.wantErr != "" {
+				assert.Contains(t, w.Body.String(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetAccountFromReadModel(t *testing.T) {
+	e := setupRouter(&fakeLedger{})
+
+	w := do(e, http.MethodGet, "/accounts/"+testAccountID, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp responsex.Envelope[AccountResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(250), resp.Data.Balance)
+	require.NotNil(t, resp.Data.OpenedAt)
+	assert.Equal(t, 2025, resp.Data.OpenedAt.Year())
+}
+
+func TestHistory(t *testing.T) {
+	recorded := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	e := setupRouter(&fakeLedger{events: []domain.RecordedEvent{
+		{Position: 7, StreamID: testAccountID, Version: 1, Event: domain.AccountOpened{Owner: "Jane Doe"}, RecordedAt: recorded},
+		{Position: 9, StreamID: testAccountID, Version: 2, Event: domain.MoneyDeposited{Amount: 500}, RecordedAt: recorded},
+	}})
+
+	w := do(e, http.MethodGet, "/accounts/"+testAccountID+"/events", "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp responsex.Envelope[[]map[string]any]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data, 2)
+	assert.Equal(t, "account.deposited", resp.Data[1]["type"])
+	assert.Equal(t, map[string]any{"amount": float64(500)}, resp.Data[1]["data"])
+	assert.Equal(t, float64(9), resp.Data[1]["position"])
+}
+
+func TestDailyTotals(t *testing.T) {
+	ledger := &fakeLedger{}
+	e := setupRouter(ledger)
+
+	w := do(e, http.MethodGet, "/reports/daily", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, defaultReportDays, ledger.days)
+	assert.Contains(t, w.Body.String(), `"day":"2025-01-02"`)
+
+	w = do(e, http.MethodGet, "/reports/daily?days=30", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 30, ledger.days)
+
+	w = do(e, http.MethodGet, "/reports/daily?days=week", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_INPUT")
+}
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gostratum/examples/ledger-demo/internal/domain"
+	"github.com/gostratum/examples/ledger-demo/internal/projection"
+	"github.com/gostratum/examples/ledger-demo/internal/usecase"
+)
+
+// OpenAccountRequest represents the request payload for opening an account
+type OpenAccountRequest struct {
+	Owner string `json:"owner" binding:"required"`
+}
+
+// AmountRequest represents the request payload for a deposit or withdrawal
+type AmountRequest struct {
+	// Amount in cents
+	Amount int64 `json:"amount" binding:"required"`
+}
+
+// AccountResponse is the HTTP DTO for account data. The times are only known
+// to the read model, so command responses leave them out.
+type AccountResponse struct {
+	ID        string     `json:"id"`
+	Owner     string     `json:"owner"`
+	Balance   int64      `json:"balance"`
+	Status    string     `json:"status"`
+	Version   int        `json:"version"`
+	OpenedAt  *time.Time `json:"opened_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// FromDomainAccount converts a domain.Account, as a command left it, to
+// AccountResponse DTO
+func FromDomainAccount(a *domain.Account) *AccountResponse {
+	if a == nil {
+		return nil
+	}
+	return &AccountResponse{
+		ID:      a.ID,
+		Owner:   a.Owner,
+		Balance: a.Balance,
+		Status:  accountStatus(a.Closed),
+		Version: a.Version(),
+	}
+}
+
+// FromAccountView converts a usecase.AccountView to AccountResponse DTO
+func FromAccountView(v *usecase.AccountView) *AccountResponse {
+	if v == nil {
+		return nil
+	}
+	return &AccountResponse{
+		ID:        v.ID,
+		Owner:     v.Owner,
+		Balance:   v.Balance,
+		Status:    accountStatus(v.Closed),
+		Version:   v.Version,
+		OpenedAt:  &v.OpenedAt,
+		UpdatedAt: &v.UpdatedAt,
+	}
+}
+
+func accountStatus(closed bool) string {
+	if closed {
+		return "closed"
+	}
+	return "open"
+}
+
+// EventResponse is the HTTP DTO for a recorded event
+type EventResponse struct {
+	Position   int64        `json:"position"`
+	Version    int          `json:"version"`
+	Type       string       `json:"type"`
+	Data       domain.Event `json:"data"`
+	RecordedAt time.Time    `json:"recorded_at"`
+}
+
+// FromRecordedEvent converts a domain.RecordedEvent to EventResponse DTO
+func FromRecordedEvent(e domain.RecordedEvent) EventResponse {
+	return EventResponse{
+		Position:   e.Position,
+		Version:    e.Version,
+		Type:       e.Event.EventType(),
+		Data:       e.Event,
+		RecordedAt: e.RecordedAt,
+	}
+}
+
+// DailyTotalResponse is the HTTP DTO for one day's activity
+type DailyTotalResponse struct {
+	Day         string `json:"day"`
+	Opened      int    `json:"opened"`
+	Closed      int    `json:"closed"`
+	Deposits    int64  `json:"deposits"`
+	Withdrawals int64  `json:"withdrawals"`
+}
+
+// FromDailyTotal converts a usecase.DailyTotal to DailyTotalResponse DTO
+func FromDailyTotal(d usecase.DailyTotal) DailyTotalResponse {
+	return DailyTotalResponse{
+		Day:         d.Day.Format(time.DateOnly),
+		Opened:      d.Opened,
+		Closed:      d.Closed,
+		Deposits:    d.Deposits,
+		Withdrawals: d.Withdrawals,
+	}
+}
+
+// ProjectionResponse is the HTTP DTO for a projection's status
+type ProjectionResponse struct {
+	Name      string `json:"name"`
+	Position  int64  `json:"position"`
+	Head      int64  `json:"head"`
+	Lag       int64  `json:"lag"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// FromProjectionStatus converts a projection.Status to ProjectionResponse DTO
+func FromProjectionStatus(s projection.Status) ProjectionResponse {
+	return ProjectionResponse{
+		Name:      s.Name,
+		Position:  s.Position,
+		Head:      s.Head,
+		Lag:       s.Lag(),
+		LastError: s.LastError,
+	}
+}
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/ledger-demo/internal/projection"
+)
+
+// Projections reports how far the projections have come; implemented by
+// projection.Projector
+type Projections interface {
+	Status(ctx context.Context) ([]projection.Status, error)
+}
+
+// ProjectionHandler handles projection-related HTTP requests
+type ProjectionHandler struct {
+	projections Projections
+	log         logx.Logger
+}
+
+// NewProjectionHandler creates a new projection handler
+func NewProjectionHandler(projector *projection.Projector, log logx.Logger) *ProjectionHandler {
+	return &ProjectionHandler{projections: projector, log: log}
+}
+
+// Status handles GET /projections
+func (h *ProjectionHandler) Status(c *gin.Context) {
+	statuses, err := h.projections.Status(c.Request.Context())
+	if err != nil {
+		h.log.Error("failed to read projection status", logx.Err(err))
+		c.Header("Retry-After", "2")
+		responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "service temporarily unavailable", nil)
+		return
+	}
+
+	resp := make([]ProjectionResponse, len(statuses))
+	for i, s := range statuses {
+		resp[i] = FromProjectionStatus(s)
+	}
+	responsex.OK(c, resp, nil)
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+)
+
+// RegisterRoutes registers all HTTP routes using the provided Gin engine
+// This function is designed to be used with fx.Invoke to work with httpx.Module
+func RegisterRoutes(e *gin.Engine, accountHandler *AccountHandler, projectionHandler *ProjectionHandler, reg core.Registry, log logx.Logger) {
+	// Add responsex middleware for request tracking and metadata
+	e.Use(responsex.MetaMiddleware("ledger-demo/v1.0.0"))
+
+	// Commands; each appends events to the event store
+	e.POST("/accounts", accountHandler.Open)
+	e.POST("/accounts/:id/deposits", accountHandler.Deposit)
+	e.POST("/accounts/:id/withdrawals", accountHandler.Withdraw)
+	e.POST("/accounts/:id/close", accountHandler.Close)
+
+	// Queries on the read models, and an account's events
+	e.GET("/accounts", accountHandler.ListAccounts)
+	e.GET("/accounts/:id", accountHandler.GetAccount)
+	e.GET("/accounts/:id/events", accountHandler.History)
+	e.GET("/reports/daily", accountHandler.DailyTotals)
+
+	// How far each projection has come
+	e.GET("/projections", projectionHandler.Status)
+
+	// Health endpoints - readiness and liveness checks
+	e.GET("/healthz", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		res := reg.Aggregate(ctx, core.Readiness)
+		if res.OK {
+			c.JSON(http.StatusOK, gin.H{"ok": true, "details": res.Details})
+		} else {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"ok": false, "details": res.Details})
+		}
+	})
+
+	e.GET("/livez", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		res := reg.Aggregate(ctx, core.Liveness)
+		if res.OK {
+			c.JSON(http.StatusOK, gin.H{"ok": true, "details": res.Details})
+		} else {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"ok": false, "details": res.Details})
+		}
+	})
+
+	log.Info("HTTP routes registered")
+}
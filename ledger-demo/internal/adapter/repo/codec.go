@@ -0,0 +1,50 @@
+package repo
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gostratum/examples/ledger-demo/internal/domain"
+)
+
+// decoders decode the stored data of each event type. An event type missing
+// here cannot be loaded, so one is added with every new event.
+var decoders = map[string]func(data []byte) (domain.Event, error){
+	domain.AccountOpened{}.EventType():  decode[domain.AccountOpened],
+	domain.MoneyDeposited{}.EventType(): decode[domain.MoneyDeposited],
+	domain.MoneyWithdrawn{}.EventType(): decode[domain.MoneyWithdrawn],
+	domain.AccountClosed{}.EventType():  decode[domain.AccountClosed],
+}
+
+func decode[E domain.Event](data []byte) (domain.Event, error) {
+	var e E
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// encodeEvent returns the type and JSON data an event is stored as
+func encodeEvent(e domain.Event) (string, []byte, error) {
+	if _, ok := decoders[e.EventType()]; !ok {
+		return "", nil, fmt.Errorf("event type %q has no decoder", e.EventType())
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode %s event: %w", e.EventType(), err)
+	}
+	return e.EventType(), data, nil
+}
+
+// decodeEvent returns the event stored as typ and data
+func decodeEvent(typ string, data []byte) (domain.Event, error) {
+	decode, ok := decoders[typ]
+	if !ok {
+		return nil, fmt.Errorf("unknown event type %q", typ)
+	}
+	e, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s event: %w", typ, err)
+	}
+	return e, nil
+}
@@ -0,0 +1,44 @@
+package repo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/ledger-demo/internal/domain"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	events := []domain.Event{
+		domain.AccountOpened{Owner: "Jane Doe"},
+		domain.MoneyDeposited{Amount: 500},
+		domain.MoneyWithdrawn{Amount: 200},
+		domain.AccountClosed{},
+	}
+	for _, e := range events {
+		typ, data, err := encodeEvent(e)
+		require.NoError(t, err)
+		assert.Equal(t, e.EventType(), typ)
+
+		decoded, err := decodeEvent(typ, data)
+		require.NoError(t, err)
+		assert.Equal(t, e, decoded)
+	}
+}
+
+func TestCodecStoredFormat(t *testing.T) {
+	// Stored events must stay readable: these are the formats written so far
+	decoded, err := decodeEvent("account.deposited", []byte(`{"amount":500}`))
+	require.NoError(t, err)
+	assert.Equal(t, domain.MoneyDeposited{Amount: 500}, decoded)
+
+	decoded, err = decodeEvent("account.opened", []byte(`{"owner":"Jane Doe"}`))
+	require.NoError(t, err)
+	assert.Equal(t, domain.AccountOpened{Owner: "Jane Doe"}, decoded)
+
+	_, err = decodeEvent("account.frozen", []byte(`{}`))
+	assert.ErrorContains(t, err, `unknown event type "account.frozen"`)
+	_, err = decodeEvent("account.deposited", []byte(`{"amount":"500"}`))
+	assert.Error(t, err)
+}
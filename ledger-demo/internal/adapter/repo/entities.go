@@ -0,0 +1,95 @@
+package repo
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gostratum/examples/ledger-demo/internal/domain"
+	"github.com/gostratum/examples/ledger-demo/internal/usecase"
+)
+
+// EventEntity represents the GORM model for events table
+type EventEntity struct {
+	Position   int64     `gorm:"primaryKey;autoIncrement"`
+	StreamID   string    `gorm:"not null"`
+	Version    int       `gorm:"not null"`
+	Type       string    `gorm:"not null"`
+	Data       []byte    `gorm:"type:jsonb;not null"`
+	RecordedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for EventEntity
+func (EventEntity) TableName() string {
+	return "events"
+}
+
+// ToDomain converts EventEntity to domain.RecordedEvent
+func (e *EventEntity) ToDomain() (domain.RecordedEvent, error) {
+	event, err := decodeEvent(e.Type, e.Data)
+	if err != nil {
+		return domain.RecordedEvent{}, fmt.Errorf("event %d: %w", e.Position, err)
+	}
+	return domain.RecordedEvent{
+		Position:   e.Position,
+		StreamID:   e.StreamID,
+		Version:    e.Version,
+		Event:      event,
+		RecordedAt: e.RecordedAt,
+	}, nil
+}
+
+// AccountViewEntity represents the GORM model for account_views table,
+// which the accounts projection fills
+type AccountViewEntity struct {
+	ID        string    `gorm:"primaryKey"`
+	Owner     string    `gorm:"not null"`
+	Balance   int64     `gorm:"not null"`
+	Closed    bool      `gorm:"not null"`
+	Version   int       `gorm:"not null"`
+	OpenedAt  time.Time `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"not null;autoUpdateTime:false"`
+}
+
+// TableName specifies the table name for AccountViewEntity
+func (AccountViewEntity) TableName() string {
+	return "account_views"
+}
+
+// ToUsecase converts AccountViewEntity to usecase.AccountView
+func (a *AccountViewEntity) ToUsecase() usecase.AccountView {
+	return usecase.AccountView{
+		ID:        a.ID,
+		Owner:     a.Owner,
+		Balance:   a.Balance,
+		Closed:    a.Closed,
+		Version:   a.Version,
+		OpenedAt:  a.OpenedAt,
+		UpdatedAt: a.UpdatedAt,
+	}
+}
+
+// DailyTotalEntity represents the GORM model for daily_totals table, which
+// the daily totals projection fills
+type DailyTotalEntity struct {
+	Day         time.Time `gorm:"primaryKey;type:date"`
+	Opened      int       `gorm:"not null"`
+	Closed      int       `gorm:"not null"`
+	Deposits    int64     `gorm:"not null"`
+	Withdrawals int64     `gorm:"not null"`
+}
+
+// TableName specifies the table name for DailyTotalEntity
+func (DailyTotalEntity) TableName() string {
+	return "daily_totals"
+}
+
+// ToUsecase converts DailyTotalEntity to usecase.DailyTotal
+func (d *DailyTotalEntity) ToUsecase() usecase.DailyTotal {
+	return usecase.DailyTotal{
+		Day:         d.Day,
+		Opened:      d.Opened,
+		Closed:      d.Closed,
+		Deposits:    d.Deposits,
+		Withdrawals: d.Withdrawals,
+	}
+}
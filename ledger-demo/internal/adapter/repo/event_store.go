@@ -0,0 +1,121 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+
+	"github.com/gostratum/examples/ledger-demo/internal/domain"
+	"github.com/gostratum/examples/ledger-demo/internal/usecase"
+)
+
+// appendLockKey is the key of the Postgres advisory lock appends take turns
+// on. Any constant works, as long as nothing else locks it.
+const appendLockKey = 4_470_001
+
+// EventStore implements the EventStore interface on a Postgres table, using
+// GORM
+type EventStore struct {
+	db *gorm.DB
+}
+
+// NewEventStore creates a new GORM-based event store
+func NewEventStore(db *gorm.DB) usecase.EventStore {
+	return &EventStore{db: db}
+}
+
+// Append implements usecase.EventStore
+func (s *EventStore) Append(ctx context.Context, streamID string, expectedVersion int, events []domain.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	entities := make([]EventEntity, len(events))
+	for i, e := range events {
+		typ, data, err := encodeEvent(e)
+		if err != nil {
+			return err
+		}
+		entities[i] = EventEntity{
+			StreamID:   streamID,
+			Version:    expectedVersion + i + 1,
+			Type:       typ,
+			Data:       data,
+			RecordedAt: now,
+		}
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Positions come from a sequence, and are drawn when rows are
+		// inserted, not when they are committed. Were appends to run side by
+		// side, a reader could see position 8 before the transaction holding
+		// position 7 commits, and never read 7. Appends take turns instead,
+		// so events are committed in position order.
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", appendLockKey).Error; err != nil {
+			return err
+		}
+
+		// (stream_id, version) is unique: when another command appended to
+		// the stream since it was loaded, its events hold these versions
+		if err := tx.Create(&entities).Error; err != nil {
+			if isUniqueViolation(err) {
+				return domain.ErrConflict
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// Load implements usecase.EventStore
+func (s *EventStore) Load(ctx context.Context, streamID string) ([]domain.RecordedEvent, error) {
+	var entities []EventEntity
+	if err := s.db.WithContext(ctx).Where("stream_id = ?", streamID).Order("version").Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return toRecorded(entities)
+}
+
+// ReadAll implements usecase.EventStore
+func (s *EventStore) ReadAll(ctx context.Context, after int64, limit int) ([]domain.RecordedEvent, error) {
+	var entities []EventEntity
+	err := s.db.WithContext(ctx).
+		Where("position > ?", after).
+		Order("position").
+		Limit(limit).
+		Find(&entities).Error
+	if err != nil {
+		return nil, err
+	}
+	return toRecorded(entities)
+}
+
+// Head implements usecase.EventStore
+func (s *EventStore) Head(ctx context.Context) (int64, error) {
+	var head int64
+	err := s.db.WithContext(ctx).Model(&EventEntity{}).Select("COALESCE(MAX(position), 0)").Scan(&head).Error
+	return head, err
+}
+
+func toRecorded(entities []EventEntity) ([]domain.RecordedEvent, error) {
+	events := make([]domain.RecordedEvent, len(entities))
+	for i := range entities {
+		e, err := entities[i].ToDomain()
+		if err != nil {
+			return nil, err
+		}
+		events[i] = e
+	}
+	return events, nil
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation,
+// whether or not GORM's TranslateError turned it into gorm.ErrDuplicatedKey
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.Is(err, gorm.ErrDuplicatedKey) || (errors.As(err, &pgErr) && pgErr.Code == "23505")
+}
@@ -0,0 +1,153 @@
+package repo
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/gostratum/examples/ledger-demo/internal/domain"
+	"github.com/gostratum/examples/ledger-demo/migrations"
+	"github.com/gostratum/examples/testkit/containers"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(containers.Run(m))
+}
+
+// setupTestDB creates a Postgres database with the service's migrations applied
+func setupTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	return containers.PostgresGorm(t, containers.WithMigrations(migrations.FS))
+}
+
+func TestEventStoreAppendAndLoad(t *testing.T) {
+	t.Parallel()
+	store := NewEventStore(setupTestDB(t))
+	ctx := context.Background()
+	a, b := domain.NewAccountID(), domain.NewAccountID()
+
+	require.NoError(t, store.Append(ctx, a, 0, []domain.Event{domain.AccountOpened{Owner: "Jane Doe"}, domain.MoneyDeposited{Amount: 500}}))
+	require.NoError(t, store.Append(ctx, b, 0, []domain.Event{domain.AccountOpened{Owner: "John Doe"}}))
+	require.NoError(t, store.Append(ctx, a, 2, []domain.Event{domain.MoneyWithdrawn{Amount: 200}}))
+
+	events, err := store.Load(ctx, a)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	for i, e := range events {
+		assert.Equal(t, a, e.StreamID)
+		assert.Equal(t, i+1, e.Version)
+		assert.False(t, e.RecordedAt.IsZero())
+	}
+	assert.Equal(t, domain.AccountOpened{Owner: "Jane Doe"}, events[0].Event)
+	assert.Equal(t, domain.MoneyDeposited{Amount: 500}, events[1].Event)
+	assert.Equal(t, domain.MoneyWithdrawn{Amount: 200}, events[2].Event)
+
+	events, err = store.Load(ctx, domain.NewAccountID())
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestEventStoreRejectsStaleVersion(t *testing.T) {
+	t.Parallel()
+	store := NewEventStore(setupTestDB(t))
+	ctx := context.Background()
+	id := domain.NewAccountID()
+
+	require.NoError(t, store.Append(ctx, id, 0, []domain.Event{domain.AccountOpened{Owner: "Jane Doe"}}))
+	require.NoError(t, store.Append(ctx, id, 1, []domain.Event{domain.MoneyDeposited{Amount: 500}}))
+
+	// Appended on version 1, which a deposit already followed
+	err := store.Append(ctx, id, 1, []domain.Event{domain.MoneyWithdrawn{Amount: 100}, domain.MoneyWithdrawn{Amount: 100}})
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	err = store.Append(ctx, id, 0, []domain.Event{domain.AccountOpened{Owner: "John Doe"}})
+	assert.ErrorIs(t, err, domain.ErrConflict)
+
+	events, err := store.Load(ctx, id)
+	require.NoError(t, err)
+	assert.Len(t, events, 2, "a rejected append must add none of its events")
+}
+
+func TestEventStoreConcurrentAppends(t *testing.T) {
+	t.Parallel()
+	store := NewEventStore(setupTestDB(t))
+	ctx := context.Background()
+	id := domain.NewAccountID()
+	require.NoError(t, store.Append(ctx, id, 0, []domain.Event{domain.AccountOpened{Owner: "Jane Doe"}}))
+
+	// Every writer loaded version 1; one of them may append
+	const writers = 8
+	errs := make([]error, writers)
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = store.Append(ctx, id, 1, []domain.Event{domain.MoneyDeposited{Amount: int64(i + 1)}})
+		}()
+	}
+	wg.Wait()
+
+	var appended int
+	for _, err := range errs {
+		if err == nil {
+			appended++
+		} else {
+			assert.ErrorIs(t, err, domain.ErrConflict)
+		}
+	}
+	assert.Equal(t, 1, appended)
+
+	events, err := store.Load(ctx, id)
+	require.NoError(t, err)
+	assert.Len(t, events, 2)
+}
+
+func TestEventStoreReadAll(t *testing.T) {
+	t.Parallel()
+	store := NewEventStore(setupTestDB(t))
+	ctx := context.Background()
+
+	head, err := store.Head(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, head)
+
+	// Appends to many streams at once still come out in position order
+	const streams = 10
+	var wg sync.WaitGroup
+	for range streams {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id := domain.NewAccountID()
+			assert.NoError(t, store.Append(ctx, id, 0, []domain.Event{domain.AccountOpened{Owner: "Jane Doe"}}))
+			assert.NoError(t, store.Append(ctx, id, 1, []domain.Event{domain.MoneyDeposited{Amount: 100}}))
+		}()
+	}
+	wg.Wait()
+
+	var all []domain.RecordedEvent
+	var after int64
+	for {
+		batch, err := store.ReadAll(ctx, after, 3)
+		require.NoError(t, err)
+		if len(batch) == 0 {
+			break
+		}
+		assert.LessOrEqual(t, len(batch), 3)
+		all = append(all, batch...)
+		after = batch[len(batch)-1].Position
+	}
+	require.Len(t, all, 2*streams)
+	for i := 1; i < len(all); i++ {
+		assert.Greater(t, all[i].Position, all[i-1].Position)
+	}
+
+	head, err = store.Head(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, all[len(all)-1].Position, head)
+}
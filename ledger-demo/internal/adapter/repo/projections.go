@@ -0,0 +1,103 @@
+package repo
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/gostratum/examples/ledger-demo/internal/domain"
+)
+
+// AccountsProjection keeps account_views: every account with its balance
+type AccountsProjection struct{}
+
+// NewAccountsProjection creates the accounts projection
+func NewAccountsProjection() *AccountsProjection {
+	return &AccountsProjection{}
+}
+
+// Name implements projection.Projection
+func (*AccountsProjection) Name() string {
+	return "accounts"
+}
+
+// Apply implements projection.Projection
+func (p *AccountsProjection) Apply(tx *gorm.DB, e domain.RecordedEvent) error {
+	switch ev := e.Event.(type) {
+	case domain.AccountOpened:
+		return tx.Create(&AccountViewEntity{
+			ID:        e.StreamID,
+			Owner:     ev.Owner,
+			Version:   e.Version,
+			OpenedAt:  e.RecordedAt,
+			UpdatedAt: e.RecordedAt,
+		}).Error
+	case domain.MoneyDeposited:
+		return p.update(tx, e, map[string]any{"balance": gorm.Expr("balance + ?", ev.Amount)})
+	case domain.MoneyWithdrawn:
+		return p.update(tx, e, map[string]any{"balance": gorm.Expr("balance - ?", ev.Amount)})
+	case domain.AccountClosed:
+		return p.update(tx, e, map[string]any{"closed": true})
+	}
+	return nil
+}
+
+// Reset implements projection.Projection
+func (*AccountsProjection) Reset(tx *gorm.DB) error {
+	return tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&AccountViewEntity{}).Error
+}
+
+func (*AccountsProjection) update(tx *gorm.DB, e domain.RecordedEvent, updates map[string]any) error {
+	updates["version"] = e.Version
+	updates["updated_at"] = e.RecordedAt
+	return tx.Model(&AccountViewEntity{}).Where("id = ?", e.StreamID).Updates(updates).Error
+}
+
+// DailyTotalsProjection keeps daily_totals: the accounts opened and closed
+// and the money moved on each day, in UTC
+type DailyTotalsProjection struct{}
+
+// NewDailyTotalsProjection creates the daily totals projection
+func NewDailyTotalsProjection() *DailyTotalsProjection {
+	return &DailyTotalsProjection{}
+}
+
+// Name implements projection.Projection
+func (*DailyTotalsProjection) Name() string {
+	return "daily_totals"
+}
+
+// Apply implements projection.Projection
+func (*DailyTotalsProjection) Apply(tx *gorm.DB, e domain.RecordedEvent) error {
+	total := DailyTotalEntity{Day: e.RecordedAt.UTC().Truncate(24 * time.Hour)}
+	switch ev := e.Event.(type) {
+	case domain.AccountOpened:
+		total.Opened = 1
+	case domain.AccountClosed:
+		total.Closed = 1
+	case domain.MoneyDeposited:
+		total.Deposits = ev.Amount
+	case domain.MoneyWithdrawn:
+		total.Withdrawals = ev.Amount
+	default:
+		return nil
+	}
+
+	// Add the event to its day's row, creating the row with the first event
+	// of the day
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"opened":      gorm.Expr("daily_totals.opened + excluded.opened"),
+			"closed":      gorm.Expr("daily_totals.closed + excluded.closed"),
+			"deposits":    gorm.Expr("daily_totals.deposits + excluded.deposits"),
+			"withdrawals": gorm.Expr("daily_totals.withdrawals + excluded.withdrawals"),
+		}),
+	}).Create(&total).Error
+}
+
+// Reset implements projection.Projection
+func (*DailyTotalsProjection) Reset(tx *gorm.DB) error {
+	return tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&DailyTotalEntity{}).Error
+}
@@ -0,0 +1,65 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gostratum/examples/ledger-demo/internal/domain"
+	"github.com/gostratum/examples/ledger-demo/internal/usecase"
+)
+
+// ReadModels implements the ReadModels interface on the tables the
+// projections fill, using GORM
+type ReadModels struct {
+	db *gorm.DB
+}
+
+// NewReadModels creates new GORM-based read models
+func NewReadModels(db *gorm.DB) usecase.ReadModels {
+	return &ReadModels{db: db}
+}
+
+// FindAccount implements usecase.ReadModels
+func (r *ReadModels) FindAccount(ctx context.Context, id string) (*usecase.AccountView, error) {
+	var entity AccountViewEntity
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&entity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	view := entity.ToUsecase()
+	return &view, nil
+}
+
+// ListAccounts implements usecase.ReadModels
+func (r *ReadModels) ListAccounts(ctx context.Context, limit int) ([]usecase.AccountView, error) {
+	var entities []AccountViewEntity
+	err := r.db.WithContext(ctx).Order("opened_at DESC, id").Limit(limit).Find(&entities).Error
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]usecase.AccountView, len(entities))
+	for i := range entities {
+		views[i] = entities[i].ToUsecase()
+	}
+	return views, nil
+}
+
+// DailyTotals implements usecase.ReadModels
+func (r *ReadModels) DailyTotals(ctx context.Context, since time.Time) ([]usecase.DailyTotal, error) {
+	var entities []DailyTotalEntity
+	if err := r.db.WithContext(ctx).Where("day >= ?", since).Order("day").Find(&entities).Error; err != nil {
+		return nil, err
+	}
+
+	totals := make([]usecase.DailyTotal, len(entities))
+	for i := range entities {
+		totals[i] = entities[i].ToUsecase()
+	}
+	return totals, nil
+}
@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// MaxAmount is the largest amount one deposit or withdrawal may move, in
+// cents. It keeps balances far from overflowing int64.
+const MaxAmount = 1_000_000_00
+
+// maxOwnerLength limits the owner's name, in bytes
+const maxOwnerLength = 200
+
+// Account is a bank account, the aggregate of the ledger. It is never stored
+// as such: LoadAccount rebuilds it from its events, and its commands check
+// the business rules and record new events, which the caller appends to the
+// event store.
+type Account struct {
+	ID      string
+	Owner   string
+	Balance int64
+	Closed  bool
+
+	// version is the number of events applied, new ones included
+	version int
+	// changes are the events recorded since the account was loaded
+	changes []Event
+}
+
+// NewAccountID returns a new random account ID
+func NewAccountID() string {
+	return uuid.NewString()
+}
+
+// ValidAccountID reports whether id can be an account ID
+func ValidAccountID(id string) bool {
+	_, err := uuid.Parse(id)
+	return err == nil && len(id) == 36
+}
+
+// OpenAccount opens an account for owner under id
+func OpenAccount(id, owner string) (*Account, error) {
+	owner = strings.TrimSpace(owner)
+	if owner == "" || len(owner) > maxOwnerLength {
+		return nil, fmt.Errorf("%w: owner must be 1 to %d bytes", ErrInvalidInput, maxOwnerLength)
+	}
+
+	a := &Account{ID: id}
+	a.record(AccountOpened{Owner: owner})
+	return a, nil
+}
+
+// LoadAccount rebuilds the account id from its events, oldest first
+func LoadAccount(id string, history []Event) (*Account, error) {
+	if len(history) == 0 {
+		return nil, ErrNotFound
+	}
+	if _, ok := history[0].(AccountOpened); !ok {
+		return nil, fmt.Errorf("account %s starts with %s, not %s", id, history[0].EventType(), AccountOpened{}.EventType())
+	}
+
+	a := &Account{ID: id}
+	for _, e := range history {
+		a.apply(e)
+	}
+	return a, nil
+}
+
+// Deposit adds amount to the balance
+func (a *Account) Deposit(amount int64) error {
+	if err := a.checkChange(amount); err != nil {
+		return err
+	}
+	a.record(MoneyDeposited{Amount: amount})
+	return nil
+}
+
+// Withdraw takes amount from the balance, which must cover it
+func (a *Account) Withdraw(amount int64) error {
+	if err := a.checkChange(amount); err != nil {
+		return err
+	}
+	if amount > a.Balance {
+		return fmt.Errorf("%w: balance is %d", ErrInsufficientFunds, a.Balance)
+	}
+	a.record(MoneyWithdrawn{Amount: amount})
+	return nil
+}
+
+// Close closes the account, which must be empty. Closing a closed account
+// changes nothing.
+func (a *Account) Close() error {
+	if a.Closed {
+		return nil
+	}
+	if a.Balance != 0 {
+		return fmt.Errorf("%w: balance is %d", ErrBalanceNotZero, a.Balance)
+	}
+	a.record(AccountClosed{})
+	return nil
+}
+
+// Version is the version of the account's latest event, new ones included
+func (a *Account) Version() int {
+	return a.version
+}
+
+// LoadedVersion is the version the account had when it was loaded; new
+// events are appended after it
+func (a *Account) LoadedVersion() int {
+	return a.version - len(a.changes)
+}
+
+// Changes returns the events recorded since the account was loaded
+func (a *Account) Changes() []Event {
+	return a.changes
+}
+
+func (a *Account) checkChange(amount int64) error {
+	if a.Closed {
+		return ErrAccountClosed
+	}
+	if amount <= 0 || amount > MaxAmount {
+		return fmt.Errorf("%w: amount must be 1 to %d cents", ErrInvalidInput, int64(MaxAmount))
+	}
+	return nil
+}
+
+// record applies a new event and keeps it to be appended
+func (a *Account) record(e Event) {
+	a.apply(e)
+	a.changes = append(a.changes, e)
+}
+
+// apply changes the state by one event. It checks nothing: the event has
+// happened, and the rules were checked when it was recorded.
+func (a *Account) apply(e Event) {
+	switch e := e.(type) {
+	case AccountOpened:
+		a.Owner = e.Owner
+	case MoneyDeposited:
+		a.Balance += e.Amount
+	case MoneyWithdrawn:
+		a.Balance -= e.Amount
+	case AccountClosed:
+		a.Closed = true
+	}
+	a.version++
+}
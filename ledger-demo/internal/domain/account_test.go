@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAccount(t *testing.T) {
+	a, err := OpenAccount("id", "  Jane Doe ")
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Doe", a.Owner)
+	assert.Equal(t, 1, a.Version())
+	assert.Zero(t, a.LoadedVersion())
+	assert.Equal(t, []Event{AccountOpened{Owner: "Jane Doe"}}, a.Changes())
+
+	_, err = OpenAccount("id", " ")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	_, err = OpenAccount("id", strings.Repeat("a", maxOwnerLength+1))
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func TestLoadAccount(t *testing.T) {
+	a, err := LoadAccount("id", []Event{
+		AccountOpened{Owner: "Jane Doe"},
+		MoneyDeposited{Amount: 500},
+		MoneyWithdrawn{Amount: 200},
+		MoneyDeposited{Amount: 50},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Doe", a.Owner)
+	assert.Equal(t, int64(350), a.Balance)
+	assert.False(t, a.Closed)
+	assert.Equal(t, 4, a.Version())
+	assert.Equal(t, 4, a.LoadedVersion())
+	assert.Empty(t, a.Changes())
+
+	_, err = LoadAccount("id", nil)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = LoadAccount("id", []Event{MoneyDeposited{Amount: 500}})
+	assert.Error(t, err)
+}
+
+func TestAccountCommands(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []Event
+		command func(a *Account) error
+		want    []Event
+		wantErr error
+	}{
+		{
+			name:    "deposit",
+			command: func(a *Account) error { return a.Deposit(100) },
+			want:    []Event{MoneyDeposited{Amount: 100}},
+		},
+		{
+			name:    "largest deposit",
+			command: func(a *Account) error { return a.Deposit(MaxAmount) },
+			want:    []Event{MoneyDeposited{Amount: MaxAmount}},
+		},
+		{
+			name:    "deposit too large",
+			command: func(a *Account) error { return a.Deposit(MaxAmount + 1) },
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:    "deposit nothing",
+			command: func(a *Account) error { return a.Deposit(0) },
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:    "withdraw the balance",
+			history: []Event{MoneyDeposited{Amount: 100}},
+			command: func(a *Account) error { return a.Withdraw(100) },
+			want:    []Event{MoneyWithdrawn{Amount: 100}},
+		},
+		{
+			name:    "withdraw more than the balance",
+			history: []Event{MoneyDeposited{Amount: 100}},
+			command: func(a *Account) error { return a.Withdraw(101) },
+			wantErr: ErrInsufficientFunds,
+		},
+		{
+			name:    "withdraw a negative amount",
+			command: func(a *Account) error { return a.Withdraw(-100) },
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:    "close",
+			history: []Event{MoneyDeposited{Amount: 100}, MoneyWithdrawn{Amount: 100}},
+			command: func(a *Account) error { return a.Close() },
+			want:    []Event{AccountClosed{}},
+		},
+		{
+			name:    "close with money in it",
+			history: []Event{MoneyDeposited{Amount: 100}},
+			command: func(a *Account) error { return a.Close() },
+			wantErr: ErrBalanceNotZero,
+		},
+		{
+			name:    "close twice",
+			history: []Event{AccountClosed{}},
+			command: func(a *Account) error { return a.Close() },
+		},
+		{
+			name:    "deposit into a closed account",
+			history: []Event{AccountClosed{}},
+			command: func(a *Account) error { return a.Deposit(100) },
+			wantErr: ErrAccountClosed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := LoadAccount("id", append([]Event{AccountOpened{Owner: "Jane Doe"}}, tt.history...))
+			require.NoError(t, err)
+
+			err = tt.command(a)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, a.Changes(), "a rejected command must record nothing")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, a.Changes())
+			assert.Equal(t, 1+len(tt.history), a.LoadedVersion())
+			assert.Equal(t, 1+len(tt.history)+len(tt.want), a.Version())
+		})
+	}
+}
+
+func TestValidAccountID(t *testing.T) {
+	assert.True(t, ValidAccountID(NewAccountID()))
+	assert.True(t, ValidAccountID("0b8f9f4e-5c43-4d3e-9b8e-3f0f7c1d2a6b"))
+	assert.False(t, ValidAccountID(""))
+	assert.False(t, ValidAccountID("0b8f9f4e5c434d3e9b8e3f0f7c1d2a6b"))
+	assert.False(t, ValidAccountID("not-an-id"))
+}
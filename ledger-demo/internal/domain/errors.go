@@ -0,0 +1,24 @@
+package domain
+
+import "errors"
+
+// Domain errors represent business rule violations
+var (
+	// ErrNotFound indicates the requested entity was not found
+	ErrNotFound = errors.New("not found")
+
+	// ErrInvalidInput indicates the provided input violates business rules
+	ErrInvalidInput = errors.New("invalid input")
+
+	// ErrConflict indicates the entity was changed since it was read
+	ErrConflict = errors.New("conflict")
+
+	// ErrInsufficientFunds indicates a withdrawal larger than the balance
+	ErrInsufficientFunds = errors.New("insufficient funds")
+
+	// ErrAccountClosed indicates a change to an account that was closed
+	ErrAccountClosed = errors.New("account closed")
+
+	// ErrBalanceNotZero indicates closing an account that still holds money
+	ErrBalanceNotZero = errors.New("balance not zero")
+)
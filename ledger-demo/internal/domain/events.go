@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+// Event is something that happened to an account. Events are the source of
+// truth: an account's state is whatever its events add up to.
+//
+// Event types and their JSON fields are stored with every event, so they
+// cannot be renamed once events of them have been written. A change that
+// needs a different shape is a new event type.
+type Event interface {
+	// EventType is the name the event is stored under
+	EventType() string
+}
+
+// AccountOpened is the first event of every account
+type AccountOpened struct {
+	Owner string `json:"owner"`
+}
+
+// EventType implements Event
+func (AccountOpened) EventType() string { return "account.opened" }
+
+// MoneyDeposited adds Amount to the balance
+type MoneyDeposited struct {
+	Amount int64 `json:"amount"`
+}
+
+// EventType implements Event
+func (MoneyDeposited) EventType() string { return "account.deposited" }
+
+// MoneyWithdrawn takes Amount from the balance
+type MoneyWithdrawn struct {
+	Amount int64 `json:"amount"`
+}
+
+// EventType implements Event
+func (MoneyWithdrawn) EventType() string { return "account.withdrawn" }
+
+// AccountClosed is the last event of an account
+type AccountClosed struct{}
+
+// EventType implements Event
+func (AccountClosed) EventType() string { return "account.closed" }
+
+// RecordedEvent is an event as the event store keeps it. Version numbers the
+// events of one stream from 1; Position orders the events of all streams.
+type RecordedEvent struct {
+	Position   int64
+	StreamID   string
+	Version    int
+	Event      Event
+	RecordedAt time.Time
+}
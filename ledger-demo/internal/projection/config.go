@@ -0,0 +1,24 @@
+// Package projection keeps read models up to date with the event store. Each
+// Projection in the "projection.projections" group reads the events in
+// position order from its own checkpoint, and a batch of events is applied
+// in the same transaction that moves the checkpoint, so every event is
+// applied exactly once, across restarts and replicas.
+package projection
+
+import "time"
+
+// Config sets how projections follow the event store
+type Config struct {
+	// PollInterval is how long a caught-up projection waits before it looks
+	// for new events; read models lag behind the event store by about this
+	PollInterval time.Duration `mapstructure:"poll_interval" default:"200ms"`
+	// BatchSize is how many events one transaction applies
+	BatchSize int `mapstructure:"batch_size" default:"500"`
+	// Timeout bounds one batch
+	Timeout time.Duration `mapstructure:"timeout" default:"30s"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "projections"
+}
@@ -0,0 +1,12 @@
+package projection
+
+import "go.uber.org/fx"
+
+// Module provides the Projector and runs every projection provided with
+// AsProjection from application start until stop
+func Module() fx.Option {
+	return fx.Module("projection",
+		fx.Provide(NewProjector),
+		fx.Invoke(Register),
+	)
+}
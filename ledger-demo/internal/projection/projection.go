@@ -0,0 +1,31 @@
+package projection
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+
+	"github.com/gostratum/examples/ledger-demo/internal/domain"
+)
+
+// projectionsGroup is the fx value group the projector runs
+const projectionsGroup = `group:"projection.projections"`
+
+// Projection builds a read model from events
+type Projection interface {
+	// Name identifies the projection in its checkpoint, logs and the replay
+	// command; renaming a projection replays it
+	Name() string
+	// Apply updates the read model for one event, in tx. Events of types the
+	// projection does not use are passed over.
+	Apply(tx *gorm.DB, e domain.RecordedEvent) error
+	// Reset empties the read model, in tx, before it is rebuilt
+	Reset(tx *gorm.DB) error
+}
+
+// AsProjection annotates a constructor so its result joins the projection
+// group the projector runs:
+//
+//	fx.Provide(projection.AsProjection(repo.NewAccountsProjection))
+func AsProjection(constructor any) any {
+	return fx.Annotate(constructor, fx.As(new(Projection)), fx.ResultTags(projectionsGroup))
+}
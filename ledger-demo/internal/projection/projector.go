@@ -0,0 +1,332 @@
+package projection
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/gostratum/examples/ledger-demo/internal/usecase"
+)
+
+// CheckpointEntity represents the GORM model for projection_checkpoints
+// table: the position of the last event a projection applied
+type CheckpointEntity struct {
+	Name     string `gorm:"primaryKey"`
+	Position int64  `gorm:"not null"`
+}
+
+// TableName specifies the table name for CheckpointEntity
+func (CheckpointEntity) TableName() string {
+	return "projection_checkpoints"
+}
+
+// Status is how far a projection has come
+type Status struct {
+	Name string
+	// Position of the last event applied
+	Position int64
+	// Head is the position of the latest event in the store
+	Head int64
+	// LastError is why the last batch failed; empty once a batch succeeds.
+	// Only this process's batches are known.
+	LastError string
+}
+
+// Lag is how many positions the projection is behind the event store
+func (s Status) Lag() int64 {
+	return s.Head - s.Position
+}
+
+// Params holds the dependencies of the Projector
+type Params struct {
+	fx.In
+
+	Loader      configx.Loader
+	DB          *gorm.DB
+	Store       usecase.EventStore
+	Log         logx.Logger
+	Projections []Projection `group:"projection.projections"`
+}
+
+// Projector applies new events to every projection
+type Projector struct {
+	cfg         Config
+	db          *gorm.DB
+	store       usecase.EventStore
+	log         logx.Logger
+	projections []Projection
+
+	mu       sync.Mutex
+	failures map[string]string
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewProjector creates the projector from the projections config section and
+// every projection in the "projection.projections" group
+func NewProjector(p Params) (*Projector, error) {
+	var cfg Config
+	if err := p.Loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load projections config: %w", err)
+	}
+	if cfg.PollInterval <= 0 || cfg.BatchSize <= 0 || cfg.Timeout <= 0 {
+		return nil, fmt.Errorf("projections.poll_interval, batch_size and timeout must be positive")
+	}
+	return newProjector(cfg, p.DB, p.Store, p.Log, p.Projections...)
+}
+
+func newProjector(cfg Config, db *gorm.DB, store usecase.EventStore, log logx.Logger, projections ...Projection) (*Projector, error) {
+	seen := map[string]bool{}
+	for _, p := range projections {
+		if seen[p.Name()] {
+			return nil, fmt.Errorf("projection %q is registered twice", p.Name())
+		}
+		seen[p.Name()] = true
+	}
+	sorted := append([]Projection(nil), projections...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name() < sorted[j].Name() })
+
+	return &Projector{
+		cfg:         cfg,
+		db:          db,
+		store:       store,
+		log:         log,
+		projections: sorted,
+		failures:    map[string]string{},
+		done:        make(chan struct{}),
+	}, nil
+}
+
+// Register ties the projection loop to the application lifecycle.
+// This function is designed to be used with fx.Invoke.
+func Register(lc fx.Lifecycle, p *Projector) {
+	lc.Append(fx.Hook{
+		OnStart: p.start,
+		OnStop:  p.stop,
+	})
+}
+
+func (p *Projector) start(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	go p.loop(ctx)
+	return nil
+}
+
+// stop ends the loop. A batch still running is rolled back and applied
+// again on the next start.
+func (p *Projector) stop(ctx context.Context) error {
+	p.cancel()
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Projector) loop(ctx context.Context) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		for _, proj := range p.projections {
+			if _, err := p.catchUp(ctx, proj); err != nil && ctx.Err() == nil {
+				p.log.Error("projection failed; retrying next interval",
+					logx.String("projection", proj.Name()), logx.Err(err))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Names returns the names of the projections, sorted
+func (p *Projector) Names() []string {
+	names := make([]string, len(p.projections))
+	for i, proj := range p.projections {
+		names[i] = proj.Name()
+	}
+	return names
+}
+
+// CatchUp applies every new event to every projection and returns how many
+// events it applied to each
+func (p *Projector) CatchUp(ctx context.Context) (map[string]int, error) {
+	applied := map[string]int{}
+	for _, proj := range p.projections {
+		n, err := p.catchUp(ctx, proj)
+		applied[proj.Name()] = n
+		if err != nil {
+			return applied, err
+		}
+	}
+	return applied, nil
+}
+
+// Rebuild empties the read model of the projection name and applies every
+// event to it again, returning how many events it applied. The read model
+// is incomplete until it has caught up; a projector running in another
+// process helps it catch up.
+func (p *Projector) Rebuild(ctx context.Context, name string) (int, error) {
+	proj := p.projection(name)
+	if proj == nil {
+		return 0, fmt.Errorf("unknown projection %q", name)
+	}
+
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockCheckpoint(tx, name); err != nil {
+			return err
+		}
+		if err := proj.Reset(tx); err != nil {
+			return fmt.Errorf("failed to reset projection %s: %w", name, err)
+		}
+		return saveCheckpoint(tx, name, 0)
+	})
+	if err != nil {
+		return 0, err
+	}
+	p.log.Info("projection reset; replaying events", logx.String("projection", name))
+
+	return p.catchUp(ctx, proj)
+}
+
+// Status returns the status of every projection
+func (p *Projector) Status(ctx context.Context) ([]Status, error) {
+	head, err := p.store.Head(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var checkpoints []CheckpointEntity
+	if err := p.db.WithContext(ctx).Where("name IN ?", p.Names()).Find(&checkpoints).Error; err != nil {
+		return nil, err
+	}
+	positions := map[string]int64{}
+	for _, c := range checkpoints {
+		positions[c.Name] = c.Position
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	statuses := make([]Status, len(p.projections))
+	for i, proj := range p.projections {
+		statuses[i] = Status{
+			Name:      proj.Name(),
+			Position:  positions[proj.Name()],
+			Head:      head,
+			LastError: p.failures[proj.Name()],
+		}
+	}
+	return statuses, nil
+}
+
+func (p *Projector) projection(name string) Projection {
+	for _, proj := range p.projections {
+		if proj.Name() == name {
+			return proj
+		}
+	}
+	return nil
+}
+
+// catchUp applies batches to proj until a batch comes back short
+func (p *Projector) catchUp(ctx context.Context, proj Projection) (int, error) {
+	total := 0
+	for {
+		n, err := p.applyBatch(ctx, proj)
+		total += n
+		p.recordFailure(proj.Name(), err)
+		if err != nil || n < p.cfg.BatchSize {
+			return total, err
+		}
+	}
+}
+
+// applyBatch applies the events after the checkpoint of proj, and moves the
+// checkpoint past them, in one transaction. The checkpoint row stays locked
+// until the transaction ends, so projectors in several processes take turns
+// rather than applying the same events twice.
+func (p *Projector) applyBatch(ctx context.Context, proj Projection) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+
+	applied := 0
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		position, err := lockCheckpoint(tx, proj.Name())
+		if err != nil {
+			return err
+		}
+
+		events, err := p.store.ReadAll(ctx, position, p.cfg.BatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to read events after %d: %w", position, err)
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		for _, e := range events {
+			if err := proj.Apply(tx, e); err != nil {
+				return fmt.Errorf("failed to apply event %d (%s): %w", e.Position, e.Event.EventType(), err)
+			}
+		}
+		if err := saveCheckpoint(tx, proj.Name(), events[len(events)-1].Position); err != nil {
+			return err
+		}
+		applied = len(events)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return applied, nil
+}
+
+func (p *Projector) recordFailure(name string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		p.failures[name] = err.Error()
+	} else {
+		delete(p.failures, name)
+	}
+}
+
+// lockCheckpoint returns the checkpoint of the projection name, creating it
+// at 0, and locks it until tx ends
+func lockCheckpoint(tx *gorm.DB, name string) (int64, error) {
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&CheckpointEntity{Name: name}).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to create checkpoint of %s: %w", name, err)
+	}
+
+	var checkpoint CheckpointEntity
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("name = ?", name).First(&checkpoint).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock checkpoint of %s: %w", name, err)
+	}
+	return checkpoint.Position, nil
+}
+
+func saveCheckpoint(tx *gorm.DB, name string, position int64) error {
+	err := tx.Model(&CheckpointEntity{}).Where("name = ?", name).Update("position", position).Error
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint of %s: %w", name, err)
+	}
+	return nil
+}
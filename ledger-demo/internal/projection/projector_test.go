@@ -0,0 +1,284 @@
+package projection
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	repoAdapter "github.com/gostratum/examples/ledger-demo/internal/adapter/repo"
+	"github.com/gostratum/examples/ledger-demo/internal/domain"
+	"github.com/gostratum/examples/ledger-demo/internal/usecase"
+	"github.com/gostratum/examples/ledger-demo/migrations"
+	"github.com/gostratum/examples/testkit/containers"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(containers.Run(m))
+}
+
+// fixture is an event store and its read models on a database of the
+// test's own
+type fixture struct {
+	db     *gorm.DB
+	store  usecase.EventStore
+	models usecase.ReadModels
+}
+
+func setup(t *testing.T) *fixture {
+	t.Helper()
+	db := containers.PostgresGorm(t, containers.WithMigrations(migrations.FS))
+
+	return &fixture{db: db, store: repoAdapter.NewEventStore(db), models: repoAdapter.NewReadModels(db)}
+}
+
+// projector returns a projector of the accounts and daily totals projections
+// plus extra
+func (f *fixture) projector(t *testing.T, batchSize int, extra ...Projection) *Projector {
+	t.Helper()
+	projections := append([]Projection{repoAdapter.NewAccountsProjection(), repoAdapter.NewDailyTotalsProjection()}, extra...)
+	p, err := newProjector(Config{PollInterval: 10 * time.Millisecond, BatchSize: batchSize, Timeout: 10 * time.Second},
+		f.db, f.store, logx.NewNoopLogger(), projections...)
+	require.NoError(t, err)
+	return p
+}
+
+// append appends events to a stream at version
+func (f *fixture) append(t *testing.T, id string, version int, events ...domain.Event) {
+	t.Helper()
+	require.NoError(t, f.store.Append(context.Background(), id, version, events))
+}
+
+// accountWithHistory appends an account with three deposits and a withdrawal
+func (f *fixture) accountWithHistory(t *testing.T) string {
+	t.Helper()
+	id := domain.NewAccountID()
+	f.append(t, id, 0,
+		domain.AccountOpened{Owner: "Jane Doe"},
+		domain.MoneyDeposited{Amount: 100},
+		domain.MoneyDeposited{Amount: 200},
+		domain.MoneyWithdrawn{Amount: 50},
+		domain.MoneyDeposited{Amount: 300},
+	)
+	return id
+}
+
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+func TestCatchUpBuildsReadModels(t *testing.T) {
+	t.Parallel()
+	f := setup(t)
+	ctx := context.Background()
+	p := f.projector(t, 500)
+
+	a := f.accountWithHistory(t)
+	b := domain.NewAccountID()
+	f.append(t, b, 0, domain.AccountOpened{Owner: "John Doe"})
+	f.append(t, b, 1, domain.AccountClosed{})
+
+	applied, err := p.CatchUp(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"accounts": 7, "daily_totals": 7}, applied)
+
+	view, err := f.models.FindAccount(ctx, a)
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Doe", view.Owner)
+	assert.Equal(t, int64(550), view.Balance)
+	assert.Equal(t, 5, view.Version)
+	assert.False(t, view.Closed)
+	assert.False(t, view.UpdatedAt.Before(view.OpenedAt))
+
+	view, err = f.models.FindAccount(ctx, b)
+	require.NoError(t, err)
+	assert.True(t, view.Closed)
+
+	_, err = f.models.FindAccount(ctx, domain.NewAccountID())
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	views, err := f.models.ListAccounts(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, views, 2)
+
+	totals, err := f.models.DailyTotals(ctx, today().AddDate(0, 0, -1))
+	require.NoError(t, err)
+	require.Len(t, totals, 1)
+	assert.True(t, totals[0].Day.Equal(today()), "day %s", totals[0].Day)
+	assert.Equal(t, usecase.DailyTotal{Day: totals[0].Day, Opened: 2, Closed: 1, Deposits: 600, Withdrawals: 50}, totals[0])
+
+	// The checkpoint is where the last catch-up stopped
+	applied, err = p.CatchUp(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"accounts": 0, "daily_totals": 0}, applied)
+
+	f.append(t, a, 5, domain.MoneyWithdrawn{Amount: 550})
+	applied, err = p.CatchUp(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"accounts": 1, "daily_totals": 1}, applied)
+
+	view, err = f.models.FindAccount(ctx, a)
+	require.NoError(t, err)
+	assert.Zero(t, view.Balance)
+}
+
+func TestCatchUpInBatches(t *testing.T) {
+	t.Parallel()
+	f := setup(t)
+	ctx := context.Background()
+	p := f.projector(t, 2)
+
+	id := f.accountWithHistory(t)
+	applied, err := p.CatchUp(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 5, applied["accounts"])
+
+	view, err := f.models.FindAccount(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, int64(550), view.Balance)
+
+	statuses, err := p.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	for _, s := range statuses {
+		assert.Equal(t, int64(5), s.Head, s.Name)
+		assert.Zero(t, s.Lag(), s.Name)
+		assert.Empty(t, s.LastError, s.Name)
+	}
+}
+
+func TestRebuildGivesTheSameReadModel(t *testing.T) {
+	t.Parallel()
+	f := setup(t)
+	ctx := context.Background()
+	p := f.projector(t, 500)
+
+	id := f.accountWithHistory(t)
+	_, err := p.CatchUp(ctx)
+	require.NoError(t, err)
+	before, err := f.models.FindAccount(ctx, id)
+	require.NoError(t, err)
+	totalsBefore, err := f.models.DailyTotals(ctx, today())
+	require.NoError(t, err)
+
+	// A read model gone wrong is rebuilt from the events
+	require.NoError(t, f.db.Exec("UPDATE account_views SET balance = 0").Error)
+
+	applied, err := p.Rebuild(ctx, "accounts")
+	require.NoError(t, err)
+	assert.Equal(t, 5, applied)
+	after, err := f.models.FindAccount(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+
+	applied, err = p.Rebuild(ctx, "daily_totals")
+	require.NoError(t, err)
+	assert.Equal(t, 5, applied)
+	totalsAfter, err := f.models.DailyTotals(ctx, today())
+	require.NoError(t, err)
+	assert.Equal(t, totalsBefore, totalsAfter, "a rebuild must not count events twice")
+
+	_, err = p.Rebuild(ctx, "balances")
+	assert.ErrorContains(t, err, `unknown projection "balances"`)
+}
+
+// failingProjection fails on deposits of failAmount, and applies nothing
+type failingProjection struct {
+	failAmount int64
+}
+
+func (failingProjection) Name() string { return "failing" }
+
+func (p failingProjection) Apply(tx *gorm.DB, e domain.RecordedEvent) error {
+	if d, ok := e.Event.(domain.MoneyDeposited); ok && d.Amount == p.failAmount {
+		return errors.New("cannot apply")
+	}
+	return nil
+}
+
+func (failingProjection) Reset(tx *gorm.DB) error { return nil }
+
+func TestFailedBatchIsRolledBack(t *testing.T) {
+	t.Parallel()
+	f := setup(t)
+	ctx := context.Background()
+	// The daily totals projection shares no transaction with the failing one
+	p := f.projector(t, 500, failingProjection{failAmount: 200})
+
+	f.accountWithHistory(t)
+	_, err := p.CatchUp(ctx)
+	require.ErrorContains(t, err, "failed to apply event 3 (account.deposited)")
+
+	statuses, err := p.Status(ctx)
+	require.NoError(t, err)
+	byName := map[string]Status{}
+	for _, s := range statuses {
+		byName[s.Name] = s
+	}
+	assert.Equal(t, int64(5), byName["accounts"].Position)
+	assert.Equal(t, int64(5), byName["daily_totals"].Position)
+	assert.Zero(t, byName["failing"].Position, "the failed batch must not move the checkpoint")
+	assert.Equal(t, int64(5), byName["failing"].Lag())
+	assert.Contains(t, byName["failing"].LastError, "cannot apply")
+}
+
+func TestProjectorsInSeveralProcesses(t *testing.T) {
+	t.Parallel()
+	f := setup(t)
+	ctx := context.Background()
+
+	const accounts = 20
+	for range accounts {
+		f.accountWithHistory(t)
+	}
+
+	// Replicas of the service each run a projector; together they apply
+	// every event exactly once
+	var wg sync.WaitGroup
+	for range 4 {
+		p := f.projector(t, 3)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := p.CatchUp(ctx)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	totals, err := f.models.DailyTotals(ctx, today())
+	require.NoError(t, err)
+	require.Len(t, totals, 1)
+	assert.Equal(t, accounts, totals[0].Opened)
+	assert.Equal(t, int64(accounts*600), totals[0].Deposits)
+	assert.Equal(t, int64(accounts*50), totals[0].Withdrawals)
+}
+
+func TestProjectorRunsWithTheApplication(t *testing.T) {
+	t.Parallel()
+	f := setup(t)
+	ctx := context.Background()
+	p := f.projector(t, 500)
+
+	require.NoError(t, p.start(ctx))
+	id := f.accountWithHistory(t)
+
+	assert.Eventually(t, func() bool {
+		view, err := f.models.FindAccount(ctx, id)
+		return err == nil && view.Balance == 550
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, p.stop(ctx))
+}
+
+func TestNewProjectorRejectsDuplicateNames(t *testing.T) {
+	_, err := newProjector(Config{}, nil, nil, logx.NewNoopLogger(),
+		repoAdapter.NewAccountsProjection(), repoAdapter.NewAccountsProjection())
+	assert.ErrorContains(t, err, `projection "accounts" is registered twice`)
+}
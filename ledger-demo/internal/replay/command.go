@@ -0,0 +1,197 @@
+// Package replay rebuilds read models from the event store as a one-shot
+// command inside the fx application: it starts when the application has
+// started, and stops the application with an exit code when it is done
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"go.uber.org/fx"
+
+	"github.com/gostratum/examples/ledger-demo/internal/projection"
+)
+
+// Exit codes of the command
+const (
+	// ExitOK: every projection asked for was rebuilt
+	ExitOK = 0
+	// ExitFailure: a projection could not be rebuilt
+	ExitFailure = 1
+	// ExitUsage: invalid command-line arguments
+	ExitUsage = 2
+	// ExitInterrupted: stopped by SIGINT or SIGTERM (128 + SIGINT, as shells report it)
+	ExitInterrupted = 130
+)
+
+// All rebuilds every projection
+const All = "all"
+
+// Args are the command-line arguments
+type Args struct {
+	// Projection to rebuild, or All
+	Projection string
+	// Status only prints how far each projection has come
+	Status bool
+}
+
+// Projections rebuilds projections; implemented by projection.Projector
+type Projections interface {
+	Names() []string
+	Rebuild(ctx context.Context, name string) (int, error)
+	Status(ctx context.Context) ([]projection.Status, error)
+}
+
+// Command rebuilds projections
+type Command struct {
+	args        Args
+	projections Projections
+	shutdowner  fx.Shutdowner
+	log         logx.Logger
+	out         io.Writer
+
+	// cancel stops the replay when the application is stopped from outside
+	cancel context.CancelFunc
+	// done is closed once the replay has finished and code is set
+	done chan struct{}
+	code int
+}
+
+// NewCommand creates the command
+func NewCommand(args Args, projector *projection.Projector, shutdowner fx.Shutdowner, log logx.Logger) *Command {
+	return newCommand(args, projector, shutdowner, log, os.Stdout)
+}
+
+func newCommand(args Args, projections Projections, shutdowner fx.Shutdowner, log logx.Logger, out io.Writer) *Command {
+	return &Command{
+		args:        args,
+		projections: projections,
+		shutdowner:  shutdowner,
+		log:         log,
+		out:         out,
+		done:        make(chan struct{}),
+	}
+}
+
+// Register ties the command to the application lifecycle.
+// This function is designed to be used with fx.Invoke.
+func Register(lc fx.Lifecycle, cmd *Command) {
+	lc.Append(fx.Hook{
+		OnStart: cmd.start,
+		OnStop:  cmd.stop,
+	})
+}
+
+// start runs the replay in the background; start hooks must return quickly
+func (c *Command) start(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	go func() {
+		c.code = c.run(ctx)
+		close(c.done)
+
+		// Stop the application, which makes app.Run return. When stop
+		// cancelled the replay, the application is already stopping.
+		if ctx.Err() != nil {
+			return
+		}
+		if err := c.shutdowner.Shutdown(); err != nil {
+			c.log.Error("failed to shut down", logx.Err(err))
+		}
+	}()
+	return nil
+}
+
+// stop interrupts a replay that is still running, on a signal. The batch in
+// flight is rolled back; the projection is left part way and catches up once
+// the service runs, or when the command runs again.
+func (c *Command) stop(ctx context.Context) error {
+	c.cancel()
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return errors.New("replay did not stop in time")
+	}
+}
+
+// ExitCode is the code the process should exit with once the application has
+// stopped. It is ExitInterrupted if the replay never finished.
+func (c *Command) ExitCode() int {
+	select {
+	case <-c.done:
+		return c.code
+	default:
+		return ExitInterrupted
+	}
+}
+
+// run rebuilds the projections asked for and decides the exit code
+func (c *Command) run(ctx context.Context) int {
+	if c.args.Status {
+		return c.printStatus(ctx)
+	}
+
+	names := []string{c.args.Projection}
+	if c.args.Projection == All {
+		names = c.projections.Names()
+	}
+	for _, name := range names {
+		if !slices.Contains(c.projections.Names(), name) {
+			c.log.Error("unknown projection",
+				logx.String("projection", name),
+				logx.String("known", strings.Join(c.projections.Names(), ", ")),
+			)
+			return ExitUsage
+		}
+	}
+
+	for _, name := range names {
+		start := time.Now()
+		applied, err := c.projections.Rebuild(ctx, name)
+		fields := []logx.Field{
+			logx.String("projection", name),
+			logx.Int("events", applied),
+			logx.String("duration", time.Since(start).String()),
+		}
+		switch {
+		case errors.Is(err, context.Canceled):
+			c.log.Warn("replay interrupted; it is safe to run again", fields...)
+			return ExitInterrupted
+		case err != nil:
+			c.log.Error("replay failed", append(fields, logx.Err(err))...)
+			return ExitFailure
+		}
+		c.log.Info("projection rebuilt", fields...)
+	}
+	return ExitOK
+}
+
+// printStatus writes a table of the projections' positions
+func (c *Command) printStatus(ctx context.Context) int {
+	statuses, err := c.projections.Status(ctx)
+	if err != nil {
+		c.log.Error("failed to read projection status", logx.Err(err))
+		return ExitFailure
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECTION\tPOSITION\tHEAD\tLAG")
+	for _, s := range statuses {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", s.Name, s.Position, s.Head, s.Lag())
+	}
+	if err := w.Flush(); err != nil {
+		c.log.Error("failed to print projection status", logx.Err(err))
+		return ExitFailure
+	}
+	return ExitOK
+}
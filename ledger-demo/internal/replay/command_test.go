@@ -0,0 +1,116 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+
+	"github.com/gostratum/examples/ledger-demo/internal/projection"
+)
+
+// fakeShutdowner counts shutdown requests
+type fakeShutdowner struct {
+	calls chan struct{}
+}
+
+func (s *fakeShutdowner) Shutdown(...fx.ShutdownOption) error {
+	s.calls <- struct{}{}
+	return nil
+}
+
+// fakeProjections records the projections rebuilt, failing those in fail
+type fakeProjections struct {
+	rebuilt []string
+	fail    map[string]error
+}
+
+func (f *fakeProjections) Names() []string {
+	return []string{"accounts", "daily_totals"}
+}
+
+func (f *fakeProjections) Rebuild(ctx context.Context, name string) (int, error) {
+	if err := f.fail[name]; err != nil {
+		return 0, err
+	}
+	f.rebuilt = append(f.rebuilt, name)
+	return 42, nil
+}
+
+func (f *fakeProjections) Status(ctx context.Context) ([]projection.Status, error) {
+	return []projection.Status{
+		{Name: "accounts", Position: 40, Head: 42},
+		{Name: "daily_totals", Position: 42, Head: 42},
+	}, nil
+}
+
+// runCommand starts the command and waits for it to shut the application down
+func runCommand(t *testing.T, cmd *Command, shutdowner *fakeShutdowner) int {
+	t.Helper()
+	require.NoError(t, cmd.start(context.Background()))
+	select {
+	case <-shutdowner.calls:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the command did not shut the application down")
+	}
+	require.NoError(t, cmd.stop(context.Background()))
+	return cmd.ExitCode()
+}
+
+func TestReplay(t *testing.T) {
+	tests := []struct {
+		name        string
+		projection  string
+		fail        map[string]error
+		want        int
+		wantRebuilt []string
+	}{
+		{name: "one", projection: "daily_totals", want: ExitOK, wantRebuilt: []string{"daily_totals"}},
+		{name: "all", projection: All, want: ExitOK, wantRebuilt: []string{"accounts", "daily_totals"}},
+		{name: "unknown", projection: "balances", want: ExitUsage},
+		{
+			name:        "failure stops the replay",
+			projection:  All,
+			fail:        map[string]error{"accounts": errors.New("connection refused")},
+			want:        ExitFailure,
+			wantRebuilt: nil,
+		},
+		{
+			name:        "interrupted",
+			projection:  All,
+			fail:        map[string]error{"daily_totals": context.Canceled},
+			want:        ExitInterrupted,
+			wantRebuilt: []string{"accounts"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projections := &fakeProjections{fail: tt.fail}
+			shutdowner := &fakeShutdowner{calls: make(chan struct{}, 1)}
+			cmd := newCommand(Args{Projection: tt.projection}, projections, shutdowner, logx.NewNoopLogger(), &bytes.Buffer{})
+
+			assert.Equal(t, tt.want, runCommand(t, cmd, shutdowner))
+			assert.Equal(t, tt.wantRebuilt, projections.rebuilt)
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	projections := &fakeProjections{}
+	shutdowner := &fakeShutdowner{calls: make(chan struct{}, 1)}
+	var out bytes.Buffer
+	cmd := newCommand(Args{Status: true}, projections, shutdowner, logx.NewNoopLogger(), &out)
+
+	assert.Equal(t, ExitOK, runCommand(t, cmd, shutdowner))
+	assert.Empty(t, projections.rebuilt)
+	assert.Equal(t, "PROJECTION    POSITION  HEAD  LAG\n"+
+		"accounts      40        42    2\n"+
+		"daily_totals  42        42    0\n", out.String())
+}
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gostratum/core/logx"
+
+	"github.com/gostratum/examples/ledger-demo/internal/domain"
+)
+
+// commandAttempts is how many times a command runs when other commands keep
+// changing the same account first
+const commandAttempts = 3
+
+// AccountCommands handles the commands that change accounts. Each command
+// loads the account from its events, lets the account decide, and appends
+// the new events; it never reads the read models, which may lag behind.
+type AccountCommands struct {
+	store EventStore
+	log   logx.Logger
+
+	// newID is replaced in tests
+	newID func() string
+}
+
+// NewAccountCommands creates the command side with event store injection
+func NewAccountCommands(store EventStore, log logx.Logger) *AccountCommands {
+	return &AccountCommands{
+		store: store,
+		log:   log,
+		newID: domain.NewAccountID,
+	}
+}
+
+// Open opens an account for owner
+func (s *AccountCommands) Open(ctx context.Context, owner string) (*domain.Account, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	account, err := domain.OpenAccount(s.newID(), owner)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Append(ctx, account.ID, account.LoadedVersion(), account.Changes()); err != nil {
+		return nil, translateError(err)
+	}
+
+	s.log.Info("account opened", logx.String("account_id", account.ID))
+	return account, nil
+}
+
+// Deposit adds amount cents to the account id
+func (s *AccountCommands) Deposit(ctx context.Context, id string, amount int64) (*domain.Account, error) {
+	return s.execute(ctx, id, func(a *domain.Account) error {
+		return a.Deposit(amount)
+	})
+}
+
+// Withdraw takes amount cents from the account id
+func (s *AccountCommands) Withdraw(ctx context.Context, id string, amount int64) (*domain.Account, error) {
+	return s.execute(ctx, id, func(a *domain.Account) error {
+		return a.Withdraw(amount)
+	})
+}
+
+// Close closes the account id
+func (s *AccountCommands) Close(ctx context.Context, id string) (*domain.Account, error) {
+	return s.execute(ctx, id, func(a *domain.Account) error {
+		return a.Close()
+	})
+}
+
+// execute runs command on the account id and appends the events it records.
+// When another command appended to the account in between, the account is
+// loaded again and the command decides again on the new state: a withdrawal
+// that raced a withdrawal is checked against the balance left by both.
+func (s *AccountCommands) execute(ctx context.Context, id string, command func(*domain.Account) error) (*domain.Account, error) {
+	if !domain.ValidAccountID(id) {
+		return nil, ErrNotFound
+	}
+
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	for range commandAttempts {
+		account, err := s.load(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := command(account); err != nil {
+			return nil, err
+		}
+		if len(account.Changes()) == 0 {
+			return account, nil
+		}
+
+		err = s.store.Append(ctx, id, account.LoadedVersion(), account.Changes())
+		if errors.Is(err, domain.ErrConflict) {
+			continue
+		}
+		if err != nil {
+			return nil, translateError(err)
+		}
+		return account, nil
+	}
+	return nil, fmt.Errorf("%w: account changed concurrently %d times", ErrConflict, commandAttempts)
+}
+
+// load rebuilds the account id from the event store
+func (s *AccountCommands) load(ctx context.Context, id string) (*domain.Account, error) {
+	recorded, err := s.store.Load(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	history := make([]domain.Event, len(recorded))
+	for i, r := range recorded {
+		history[i] = r.Event
+	}
+	account, err := domain.LoadAccount(id, history)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return account, nil
+}
+
+// translateError converts repository/domain errors to usecase errors
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return ErrNotFound
+	case errors.Is(err, domain.ErrConflict):
+		return ErrConflict
+	default:
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+}
@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/ledger-demo/internal/domain"
+)
+
+// openAccount opens an account holding balance cents
+func openAccount(t *testing.T, s *AccountCommands, balance int64) string {
+	t.Helper()
+	account, err := s.Open(context.Background(), "Jane Doe")
+	require.NoError(t, err)
+	if balance > 0 {
+		_, err = s.Deposit(context.Background(), account.ID, balance)
+		require.NoError(t, err)
+	}
+	return account.ID
+}
+
+func TestOpenDepositWithdraw(t *testing.T) {
+	store := &MockEventStore{}
+	s := NewAccountCommands(store, logx.NewNoopLogger())
+	ctx := context.Background()
+
+	account, err := s.Open(ctx, "Jane Doe")
+	require.NoError(t, err)
+	assert.True(t, domain.ValidAccountID(account.ID))
+	assert.Equal(t, 1, account.Version())
+
+	account, err = s.Deposit(ctx, account.ID, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), account.Balance)
+
+	account, err = s.Withdraw(ctx, account.ID, 400)
+	require.NoError(t, err)
+	assert.Equal(t, int64(600), account.Balance)
+	assert.Equal(t, 3, account.Version())
+
+	events, err := store.Load(ctx, account.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, domain.AccountOpened{Owner: "Jane Doe"}, events[0].Event)
+	assert.Equal(t, domain.MoneyDeposited{Amount: 1000}, events[1].Event)
+	assert.Equal(t, domain.MoneyWithdrawn{Amount: 400}, events[2].Event)
+}
+
+func TestCommandErrors(t *testing.T) {
+	store := &MockEventStore{}
+	s := NewAccountCommands(store, logx.NewNoopLogger())
+	ctx := context.Background()
+	id := openAccount(t, s, 100)
+
+	_, err := s.Open(ctx, "")
+	assert.ErrorIs(t, err, ErrInvalid)
+
+	_, err = s.Deposit(ctx, "not-an-id", 100)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Deposit(ctx, domain.NewAccountID(), 100)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = s.Withdraw(ctx, id, 101)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+	_, err = s.Close(ctx, id)
+	assert.ErrorIs(t, err, ErrBalanceNotZero)
+
+	store.err = errors.New("connection refused")
+	_, err = s.Deposit(ctx, id, 100)
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
+
+func TestCommandWithoutChangesAppendsNothing(t *testing.T) {
+	store := &MockEventStore{}
+	s := NewAccountCommands(store, logx.NewNoopLogger())
+	id := openAccount(t, s, 0)
+
+	_, err := s.Close(context.Background(), id)
+	require.NoError(t, err)
+	appends := store.appends
+
+	account, err := s.Close(context.Background(), id)
+	require.NoError(t, err)
+	assert.True(t, account.Closed)
+	assert.Equal(t, appends, store.appends, "closing a closed account must append nothing")
+}
+
+func TestCommandDecidesAgainAfterConflict(t *testing.T) {
+	store := &MockEventStore{}
+	s := NewAccountCommands(store, logx.NewNoopLogger())
+	ctx := context.Background()
+	id := openAccount(t, s, 100)
+
+	// Another withdrawal lands between loading the account and appending
+	store.beforeAppend = func() {
+		store.beforeAppend = nil
+		_, err := s.Withdraw(ctx, id, 70)
+		require.NoError(t, err)
+	}
+
+	_, err := s.Withdraw(ctx, id, 50)
+	assert.ErrorIs(t, err, ErrInsufficientFunds, "the retry must see the other withdrawal")
+
+	account, err := s.Deposit(ctx, id, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), account.Balance)
+}
+
+func TestCommandRetriesOnConflict(t *testing.T) {
+	store := &MockEventStore{}
+	s := NewAccountCommands(store, logx.NewNoopLogger())
+	ctx := context.Background()
+	id := openAccount(t, s, 100)
+
+	conflicts := 0
+	store.beforeAppend = func() {
+		if conflicts == commandAttempts-1 {
+			store.beforeAppend = nil
+			return
+		}
+		conflicts++
+		hook := store.beforeAppend
+		store.beforeAppend = nil
+		require.NoError(t, store.Append(ctx, id, conflicts+1, []domain.Event{domain.MoneyDeposited{Amount: 1}}))
+		store.beforeAppend = hook
+	}
+
+	account, err := s.Deposit(ctx, id, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100+commandAttempts-1+100), account.Balance)
+}
+
+func TestCommandGivesUpAfterConflicts(t *testing.T) {
+	store := &MockEventStore{}
+	s := NewAccountCommands(store, logx.NewNoopLogger())
+	ctx := context.Background()
+	id := openAccount(t, s, 100)
+
+	version := 2
+	store.beforeAppend = func() {
+		hook := store.beforeAppend
+		store.beforeAppend = nil
+		require.NoError(t, store.Append(ctx, id, version, []domain.Event{domain.MoneyDeposited{Amount: 1}}))
+		version++
+		store.beforeAppend = hook
+	}
+
+	_, err := s.Deposit(ctx, id, 100)
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.ErrorContains(t, err, "changed concurrently 3 times")
+}
@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gostratum/examples/ledger-demo/internal/domain"
+)
+
+// maxListedAccounts caps the accounts ListAccounts returns
+const maxListedAccounts = 100
+
+// AccountQueries answers questions about accounts from the read models, which
+// are built for them; only an account's history is read from the event store
+type AccountQueries struct {
+	views  ReadModels
+	events EventStore
+}
+
+// NewAccountQueries creates the query side with read model injection
+func NewAccountQueries(views ReadModels, events EventStore) *AccountQueries {
+	return &AccountQueries{views: views, events: events}
+}
+
+// Account returns the account id as of the latest projected event
+func (s *AccountQueries) Account(ctx context.Context, id string) (*AccountView, error) {
+	if !domain.ValidAccountID(id) {
+		return nil, ErrNotFound
+	}
+
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	view, err := s.views.FindAccount(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return view, nil
+}
+
+// Accounts returns the most recently opened accounts
+func (s *AccountQueries) Accounts(ctx context.Context) ([]AccountView, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	views, err := s.views.ListAccounts(ctx, maxListedAccounts)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return views, nil
+}
+
+// History returns every event of the account id, oldest first. It reads the
+// event store, so it includes events the projections have not caught up with.
+func (s *AccountQueries) History(ctx context.Context, id string) ([]domain.RecordedEvent, error) {
+	if !domain.ValidAccountID(id) {
+		return nil, ErrNotFound
+	}
+
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	events, err := s.events.Load(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	if len(events) == 0 {
+		return nil, ErrNotFound
+	}
+	return events, nil
+}
+
+// DailyTotals returns the activity of the last days days, today included
+func (s *AccountQueries) DailyTotals(ctx context.Context, days int) ([]DailyTotal, error) {
+	if days < 1 || days > 366 {
+		return nil, fmt.Errorf("%w: days must be 1 to 366", ErrInvalid)
+	}
+
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	totals, err := s.views.DailyTotals(ctx, today.AddDate(0, 0, 1-days))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return totals, nil
+}
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/ledger-demo/internal/domain"
+)
+
+func TestAccountFromReadModel(t *testing.T) {
+	id := domain.NewAccountID()
+	views := &MockReadModels{accounts: map[string]*AccountView{id: {ID: id, Owner: "Jane Doe", Balance: 100}}}
+	s := NewAccountQueries(views, &MockEventStore{})
+	ctx := context.Background()
+
+	view, err := s.Account(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), view.Balance)
+
+	_, err = s.Account(ctx, domain.NewAccountID())
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Account(ctx, "not-an-id")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	views.err = errors.New("connection refused")
+	_, err = s.Account(ctx, id)
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
+
+func TestHistoryFromEventStore(t *testing.T) {
+	store := &MockEventStore{}
+	commands := NewAccountCommands(store, logx.NewNoopLogger())
+	s := NewAccountQueries(&MockReadModels{}, store)
+	ctx := context.Background()
+	id := openAccount(t, commands, 100)
+
+	// The read model knows nothing of the account yet; the history does
+	events, err := s.History(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, 1, events[0].Version)
+	assert.Equal(t, domain.MoneyDeposited{Amount: 100}, events[1].Event)
+
+	_, err = s.History(ctx, domain.NewAccountID())
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestDailyTotalsDays(t *testing.T) {
+	views := &MockReadModels{}
+	s := NewAccountQueries(views, &MockEventStore{})
+	ctx := context.Background()
+
+	_, err := s.DailyTotals(ctx, 7)
+	require.NoError(t, err)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	assert.Equal(t, today.AddDate(0, 0, -6), views.since)
+
+	_, err = s.DailyTotals(ctx, 0)
+	assert.ErrorIs(t, err, ErrInvalid)
+	_, err = s.DailyTotals(ctx, 367)
+	assert.ErrorIs(t, err, ErrInvalid)
+}
@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/gostratum/examples/ledger-demo/internal/domain"
+)
+
+// Application-level errors for use case layer
+// These are used to communicate failures to the presentation layer
+var (
+	// ErrUnavailable indicates the database cannot be reached
+	ErrUnavailable = errors.New("service unavailable")
+
+	// ErrNotFound wraps domain.ErrNotFound for application layer
+	ErrNotFound = domain.ErrNotFound
+
+	// ErrInvalid wraps domain.ErrInvalidInput for application layer
+	ErrInvalid = domain.ErrInvalidInput
+
+	// ErrConflict wraps domain.ErrConflict for application layer
+	ErrConflict = domain.ErrConflict
+
+	// ErrInsufficientFunds wraps domain.ErrInsufficientFunds for application layer
+	ErrInsufficientFunds = domain.ErrInsufficientFunds
+
+	// ErrAccountClosed wraps domain.ErrAccountClosed for application layer
+	ErrAccountClosed = domain.ErrAccountClosed
+
+	// ErrBalanceNotZero wraps domain.ErrBalanceNotZero for application layer
+	ErrBalanceNotZero = domain.ErrBalanceNotZero
+)
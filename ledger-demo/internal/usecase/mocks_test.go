@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gostratum/examples/ledger-demo/internal/domain"
+)
+
+// MockEventStore implements EventStore in memory, with the version checks of
+// the real one
+type MockEventStore struct {
+	mu     sync.Mutex
+	events []domain.RecordedEvent
+	err    error
+	// beforeAppend runs at the start of every Append, outside the lock; tests
+	// append from it to play a concurrent command
+	beforeAppend func()
+	// appends counts the calls of Append
+	appends int
+}
+
+func (m *MockEventStore) Append(ctx context.Context, streamID string, expectedVersion int, events []domain.Event) error {
+	if m.beforeAppend != nil {
+		m.beforeAppend()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.appends++
+	if m.err != nil {
+		return m.err
+	}
+	if m.version(streamID) != expectedVersion {
+		return domain.ErrConflict
+	}
+	for i, e := range events {
+		m.events = append(m.events, domain.RecordedEvent{
+			Position:   int64(len(m.events) + 1),
+			StreamID:   streamID,
+			Version:    expectedVersion + i + 1,
+			Event:      e,
+			RecordedAt: time.Now().UTC(),
+		})
+	}
+	return nil
+}
+
+func (m *MockEventStore) Load(ctx context.Context, streamID string) ([]domain.RecordedEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	var events []domain.RecordedEvent
+	for _, e := range m.events {
+		if e.StreamID == streamID {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (m *MockEventStore) ReadAll(ctx context.Context, after int64, limit int) ([]domain.RecordedEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	events := m.events[min(int(after), len(m.events)):]
+	return events[:min(limit, len(events))], nil
+}
+
+func (m *MockEventStore) Head(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return int64(len(m.events)), m.err
+}
+
+// version returns the version of a stream; the caller holds the lock
+func (m *MockEventStore) version(streamID string) int {
+	version := 0
+	for _, e := range m.events {
+		if e.StreamID == streamID {
+			version = e.Version
+		}
+	}
+	return version
+}
+
+// MockReadModels implements ReadModels for testing
+type MockReadModels struct {
+	accounts map[string]*AccountView
+	since    time.Time
+	err      error
+}
+
+func (m *MockReadModels) FindAccount(ctx context.Context, id string) (*AccountView, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	view, ok := m.accounts[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return view, nil
+}
+
+func (m *MockReadModels) ListAccounts(ctx context.Context, limit int) ([]AccountView, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var views []AccountView
+	for _, v := range m.accounts {
+		views = append(views, *v)
+	}
+	return views[:min(limit, len(views))], nil
+}
+
+func (m *MockReadModels) DailyTotals(ctx context.Context, since time.Time) ([]DailyTotal, error) {
+	m.since = since
+	return nil, m.err
+}
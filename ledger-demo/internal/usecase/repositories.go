@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/gostratum/examples/ledger-demo/internal/domain"
+)
+
+// EventStore is the write side: an append-only log of events, kept in
+// streams of one aggregate each
+// This interface is owned by the use case layer (dependency inversion principle)
+type EventStore interface {
+	// Append adds events to the end of a stream that is at expectedVersion,
+	// all or none of them. It returns domain.ErrConflict when the stream has
+	// moved past expectedVersion since it was loaded.
+	Append(ctx context.Context, streamID string, expectedVersion int, events []domain.Event) error
+	// Load returns the events of a stream, oldest first; none when the
+	// stream does not exist
+	Load(ctx context.Context, streamID string) ([]domain.RecordedEvent, error)
+	// ReadAll returns up to limit events of all streams after position, in
+	// position order. An event is never read after events with a higher
+	// position, so a reader can resume from the last position it read.
+	ReadAll(ctx context.Context, after int64, limit int) ([]domain.RecordedEvent, error)
+	// Head returns the position of the latest event, 0 when there is none
+	Head(ctx context.Context) (int64, error)
+}
+
+// AccountView is an account as the accounts projection keeps it
+type AccountView struct {
+	ID        string
+	Owner     string
+	Balance   int64
+	Closed    bool
+	Version   int
+	OpenedAt  time.Time
+	UpdatedAt time.Time
+}
+
+// DailyTotal is one day's activity as the daily totals projection keeps it
+type DailyTotal struct {
+	Day         time.Time
+	Opened      int
+	Closed      int
+	Deposits    int64
+	Withdrawals int64
+}
+
+// ReadModels is the read side: the tables the projections build from the
+// events. They lag behind the event store by up to a projection interval.
+// This interface is owned by the use case layer (dependency inversion principle)
+type ReadModels interface {
+	FindAccount(ctx context.Context, id string) (*AccountView, error)
+	// ListAccounts returns up to limit accounts, most recently opened first
+	ListAccounts(ctx context.Context, limit int) ([]AccountView, error)
+	// DailyTotals returns the days from since on, oldest first
+	DailyTotals(ctx context.Context, since time.Time) ([]DailyTotal, error)
+}
@@ -0,0 +1,13 @@
+-- The event store: an append-only log of every event of every account.
+-- Rows are never updated or deleted.
+CREATE TABLE IF NOT EXISTS events (
+    position BIGSERIAL PRIMARY KEY,
+    stream_id VARCHAR(64) NOT NULL,
+    version INTEGER NOT NULL CHECK (version > 0),
+    type VARCHAR(100) NOT NULL,
+    data JSONB NOT NULL,
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- Two commands that loaded the same version of a stream cannot both
+    -- append to it
+    CONSTRAINT events_stream_version_key UNIQUE (stream_id, version)
+);
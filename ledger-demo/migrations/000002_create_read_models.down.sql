@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS daily_totals;
+DROP TABLE IF EXISTS account_views;
+DROP TABLE IF EXISTS projection_checkpoints;
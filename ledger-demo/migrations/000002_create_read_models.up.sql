@@ -0,0 +1,26 @@
+-- Read models, built from the events by the projections. They hold nothing
+-- the events do not: the replay command rebuilds them from scratch.
+CREATE TABLE IF NOT EXISTS projection_checkpoints (
+    name VARCHAR(100) PRIMARY KEY,
+    position BIGINT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS account_views (
+    id VARCHAR(64) PRIMARY KEY,
+    owner VARCHAR(200) NOT NULL,
+    balance BIGINT NOT NULL,
+    closed BOOLEAN NOT NULL,
+    version INTEGER NOT NULL,
+    opened_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_views_opened_at ON account_views(opened_at DESC, id);
+
+CREATE TABLE IF NOT EXISTS daily_totals (
+    day DATE PRIMARY KEY,
+    opened INTEGER NOT NULL,
+    closed INTEGER NOT NULL,
+    deposits BIGINT NOT NULL,
+    withdrawals BIGINT NOT NULL
+);
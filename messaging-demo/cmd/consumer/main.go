@@ -0,0 +1,42 @@
+package main
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	httpAdapter "github.com/gostratum/examples/messaging-demo/internal/adapter/http"
+	"github.com/gostratum/examples/messaging-demo/internal/handlers"
+	"github.com/gostratum/examples/messaging-demo/internal/messaging"
+	"github.com/gostratum/examples/redact"
+	"github.com/gostratum/httpx"
+	"github.com/gostratum/metricsx"
+)
+
+func main() {
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// Prometheus metrics for message outcomes, processing time and lag
+		metricsx.Module(),
+
+		// HTTP server for health probes only
+		httpx.Module(),
+
+		// NATS connection, stream, and one durable consumer per handler below
+		messaging.Module(),
+
+		// Handlers join the messaging.handlers group; each gets its own consumer
+		fx.Provide(
+			messaging.AsHandler(handlers.NewWelcomeHandler),
+			messaging.AsHandler(handlers.NewPlanStatsHandler),
+		),
+
+		// Invoke setup functions
+		fx.Invoke(
+			httpAdapter.RegisterHealthRoutes,
+		),
+	)
+
+	app.Run()
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	httpAdapter "github.com/gostratum/examples/messaging-demo/internal/adapter/http"
+	"github.com/gostratum/examples/messaging-demo/internal/messaging"
+	"github.com/gostratum/examples/redact"
+	"github.com/gostratum/httpx"
+	"github.com/gostratum/metricsx"
+)
+
+func main() {
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// Prometheus metrics for publish results and latency
+		metricsx.Module(),
+
+		// HTTP API that publishes signup events
+		httpx.Module(),
+
+		// NATS connection, stream and publisher; no handlers, so no consumers
+		messaging.Module(),
+
+		// Provide dependencies
+		fx.Provide(
+			httpAdapter.NewSignupHandler,
+		),
+
+		// Invoke setup functions
+		fx.Invoke(
+			httpAdapter.RegisterRoutes,
+		),
+	)
+
+	app.Run()
+}
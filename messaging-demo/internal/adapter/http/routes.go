@@ -0,0 +1,58 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+)
+
+// RegisterRoutes registers the publisher API and the health endpoints
+// This function is designed to be used with fx.Invoke to work with httpx.Module
+func RegisterRoutes(e *gin.Engine, signupHandler *SignupHandler, reg core.Registry, log logx.Logger) {
+	// Add responsex middleware for request tracking and metadata
+	e.Use(responsex.MetaMiddleware("messaging-demo/v1.0.0"))
+
+	e.POST("/signups", signupHandler.CreateSignup)
+
+	registerHealth(e, reg)
+	log.Info("HTTP routes registered")
+}
+
+// RegisterHealthRoutes registers only the health endpoints, for the consumer
+// This function is designed to be used with fx.Invoke to work with httpx.Module
+func RegisterHealthRoutes(e *gin.Engine, reg core.Registry, log logx.Logger) {
+	registerHealth(e, reg)
+	log.Info("HTTP routes registered")
+}
+
+// registerHealth adds readiness and liveness checks; readiness includes the NATS connection
+func registerHealth(e *gin.Engine, reg core.Registry) {
+	e.GET("/healthz", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		res := reg.Aggregate(ctx, core.Readiness)
+		if res.OK {
+			c.JSON(http.StatusOK, gin.H{"ok": true, "details": res.Details})
+		} else {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"ok": false, "details": res.Details})
+		}
+	})
+
+	e.GET("/livez", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		res := reg.Aggregate(ctx, core.Liveness)
+		if res.OK {
+			c.JSON(http.StatusOK, gin.H{"ok": true, "details": res.Details})
+		} else {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"ok": false, "details": res.Details})
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/messaging-demo/internal/event"
+	"github.com/gostratum/examples/messaging-demo/internal/messaging"
+)
+
+// IdempotencyKeyHeader lets clients retry a signup without publishing it twice.
+// The key becomes the event ID, which JetStream deduplicates.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// EventPublisher publishes events; implemented by messaging.Publisher
+type EventPublisher interface {
+	Publish(ctx context.Context, subject, id string, v any) (*messaging.Receipt, error)
+}
+
+// SignupHandler turns signup requests into UserSignedUp events
+type SignupHandler struct {
+	publisher EventPublisher
+	log       logx.Logger
+	now       func() time.Time
+}
+
+// NewSignupHandler creates a new signup handler
+func NewSignupHandler(publisher *messaging.Publisher, log logx.Logger) *SignupHandler {
+	return newSignupHandler(publisher, log)
+}
+
+func newSignupHandler(publisher EventPublisher, log logx.Logger) *SignupHandler {
+	return &SignupHandler{publisher: publisher, log: log, now: time.Now}
+}
+
+// SignupRequest represents the request payload for a signup
+type SignupRequest struct {
+	Email string `json:"email" binding:"required"`
+	Plan  string `json:"plan" binding:"required"`
+}
+
+// SignupResponse reports the published event
+type SignupResponse struct {
+	EventID   string `json:"event_id"`
+	UserID    string `json:"user_id"`
+	Stream    string `json:"stream"`
+	Sequence  uint64 `json:"sequence"`
+	Duplicate bool   `json:"duplicate"`
+}
+
+// CreateSignup handles POST /signups
+func (h *SignupHandler) CreateSignup(c *gin.Context) {
+	var req SignupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid request payload", nil)
+		return
+	}
+
+	id := strings.TrimSpace(c.GetHeader(IdempotencyKeyHeader))
+	if id == "" {
+		id = uuid.NewString()
+	}
+
+	e := event.UserSignedUp{
+		ID:         id,
+		UserID:     uuid.NewSHA1(uuid.NameSpaceOID, []byte(id)).String(),
+		Email:      strings.TrimSpace(req.Email),
+		Plan:       strings.ToLower(strings.TrimSpace(req.Plan)),
+		OccurredAt: h.now().UTC(),
+	}
+	if err := e.Validate(); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
+		return
+	}
+
+	receipt, err := h.publisher.Publish(c.Request.Context(), event.SubjectUserSignedUp, e.ID, e)
+	if err != nil {
+		h.log.Error("failed to publish signup", logx.String("event_id", e.ID), logx.Err(err))
+		if errors.Is(err, messaging.ErrNotConnected) {
+			responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "messaging is not connected", nil)
+			return
+		}
+		responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "failed to publish event", nil)
+		return
+	}
+
+	resp := SignupResponse{
+		EventID:   e.ID,
+		UserID:    e.UserID,
+		Stream:    receipt.Stream,
+		Sequence:  receipt.Sequence,
+		Duplicate: receipt.Duplicate,
+	}
+	if receipt.Duplicate {
+		responsex.OK(c, resp, nil)
+		return
+	}
+	responsex.Created(c, "", resp)
+}
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/messaging-demo/internal/event"
+	"github.com/gostratum/examples/messaging-demo/internal/messaging"
+)
+
+// memoryPublisher stores published events and deduplicates by ID like JetStream
+type memoryPublisher struct {
+	events []event.UserSignedUp
+	ids    map[string]uint64
+	err    error
+}
+
+func (p *memoryPublisher) Publish(ctx context.Context, subject, id string, v any) (*messaging.Receipt, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	if seq, ok := p.ids[id]; ok {
+		return &messaging.Receipt{Stream: "USERS", Sequence: seq, Duplicate: true}, nil
+	}
+	p.events = append(p.events, v.(event.UserSignedUp))
+	p.ids[id] = uint64(len(p.events))
+	return &messaging.Receipt{Stream: "USERS", Sequence: uint64(len(p.events))}, nil
+}
+
+func setupRouter(publisher *memoryPublisher) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	handler := newSignupHandler(publisher, logx.NewNoopLogger())
+	e := gin.New()
+	e.POST("/signups", handler.CreateSignup)
+	return e
+}
+
+func signupRequest(t *testing.T, body SignupRequest, idempotencyKey string) *http.Request {
+	t.Helper()
+
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/signups", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	if idempotencyKey != "" {
+		req.Header.Set(IdempotencyKeyHeader, idempotencyKey)
+	}
+	return req
+}
+
+func TestCreateSignup(t *testing.T) {
+	publisher := &memoryPublisher{ids: make(map[string]uint64)}
+	e := setupRouter(publisher)
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, signupRequest(t, SignupRequest{Email: "ada@example.com", Plan: "Pro"}, ""))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var resp responsex.Envelope[SignupResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.NotEmpty(t, resp.Data.EventID)
+	assert.Equal(t, uint64(1), resp.Data.Sequence)
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, "pro", publisher.events[0].Plan)
+}
+
+func TestCreateSignupIdempotencyKey(t *testing.T) {
+	publisher := &memoryPublisher{ids: make(map[string]uint64)}
+	e := setupRouter(publisher)
+	body := SignupRequest{Email: "ada@example.com", Plan: "free"}
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, signupRequest(t, body, "signup-42"))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var first responsex.Envelope[SignupResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &first))
+	assert.Equal(t, "signup-42", first.Data.EventID)
+
+	// A retry with the same key reports the original event and publishes nothing new
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, signupRequest(t, body, "signup-42"))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var retry responsex.Envelope[SignupResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &retry))
+	assert.True(t, retry.Data.Duplicate)
+	assert.Equal(t, first.Data.UserID, retry.Data.UserID)
+	assert.Len(t, publisher.events, 1)
+}
+
+func TestCreateSignupErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       SignupRequest
+		publishErr error
+		wantStatus int
+	}{
+		{name: "missing plan", body: SignupRequest{Email: "ada@example.com"}, wantStatus: http.StatusBadRequest},
+		{name: "invalid email", body: SignupRequest{Email: "ada", Plan: "pro"}, wantStatus: http.StatusBadRequest},
+		{name: "unknown plan", body: SignupRequest{Email: "ada@example.com", Plan: "gold"}, wantStatus: http.StatusBadRequest},
+		{name: "publish fails", body: SignupRequest{Email: "ada@example.com", Plan: "pro"}, publishErr: errors.New("timeout"), wantStatus: http.StatusServiceUnavailable},
+		{name: "not connected", body: SignupRequest{Email: "ada@example.com", Plan: "pro"}, publishErr: messaging.ErrNotConnected, wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := setupRouter(&memoryPublisher{ids: make(map[string]uint64), err: tt.publishErr})
+
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, signupRequest(t, tt.body, ""))
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+		})
+	}
+}
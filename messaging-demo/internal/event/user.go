@@ -0,0 +1,52 @@
+// Package event defines the messages exchanged between the publisher and the consumer
+package event
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"time"
+)
+
+// SubjectUserSignedUp is the subject UserSignedUp events are published on
+const SubjectUserSignedUp = "users.signed_up"
+
+// Plans a user can sign up for
+var Plans = []string{"free", "pro", "team"}
+
+// ErrInvalid indicates an event that does not satisfy the contract
+var ErrInvalid = errors.New("invalid event")
+
+// UserSignedUp is published when a user creates an account
+type UserSignedUp struct {
+	// ID is unique per event and used as the JetStream message ID
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	Email      string    `json:"email"`
+	Plan       string    `json:"plan"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Validate checks the event against the contract
+func (e UserSignedUp) Validate() error {
+	if e.ID == "" || e.UserID == "" {
+		return fmt.Errorf("%w: id and user_id are required", ErrInvalid)
+	}
+	if _, err := mail.ParseAddress(e.Email); err != nil {
+		return fmt.Errorf("%w: email %q is not valid", ErrInvalid, e.Email)
+	}
+	if !ValidPlan(e.Plan) {
+		return fmt.Errorf("%w: unknown plan %q", ErrInvalid, e.Plan)
+	}
+	return nil
+}
+
+// ValidPlan reports whether plan is one of Plans
+func ValidPlan(plan string) bool {
+	for _, p := range Plans {
+		if p == plan {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package event
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestUserSignedUpValidate(t *testing.T) {
+	valid := UserSignedUp{ID: "e1", UserID: "u1", Email: "ada@example.com", Plan: "pro", OccurredAt: time.Now()}
+
+	tests := []struct {
+		name    string
+		modify  func(e *UserSignedUp)
+		wantErr bool
+	}{
+		{name: "valid event", modify: func(e *UserSignedUp) {}, wantErr: false},
+		{name: "missing id", modify: func(e *UserSignedUp) { e.ID = "" }, wantErr: true},
+		{name: "missing user id", modify: func(e *UserSignedUp) { e.UserID = "" }, wantErr: true},
+		{name: "invalid email", modify: func(e *UserSignedUp) { e.Email = "not-an-email" }, wantErr: true},
+		{name: "unknown plan", modify: func(e *UserSignedUp) { e.Plan = "enterprise" }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := valid
+			tt.modify(&e)
+			err := e.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UserSignedUp.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalid) {
+				t.Errorf("UserSignedUp.Validate() error = %v, want ErrInvalid", err)
+			}
+		})
+	}
+}
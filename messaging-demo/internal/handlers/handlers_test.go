@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/messaging-demo/internal/event"
+	"github.com/gostratum/examples/messaging-demo/internal/messaging"
+)
+
+func signupMessage(t *testing.T, e event.UserSignedUp, attempt int) messaging.Message {
+	t.Helper()
+	data, err := json.Marshal(e)
+	require.NoError(t, err)
+	return messaging.Message{Subject: event.SubjectUserSignedUp, Data: data, Attempt: attempt}
+}
+
+func validSignup() event.UserSignedUp {
+	return event.UserSignedUp{ID: "e1", UserID: "u1", Email: "ada@example.com", Plan: "pro", OccurredAt: time.Now()}
+}
+
+func TestWelcomeHandler(t *testing.T) {
+	h := NewWelcomeHandler(logx.NewNoopLogger())
+
+	require.NoError(t, h.Handle(context.Background(), signupMessage(t, validSignup(), 1)))
+	// A redelivery is acknowledged without sending again
+	require.NoError(t, h.Handle(context.Background(), signupMessage(t, validSignup(), 2)))
+	assert.Len(t, h.sent, 1)
+
+	invalid := validSignup()
+	invalid.Email = ""
+	err := h.Handle(context.Background(), signupMessage(t, invalid, 1))
+	assert.ErrorIs(t, err, messaging.ErrPermanent)
+
+	err = h.Handle(context.Background(), messaging.Message{Data: []byte("{")})
+	assert.ErrorIs(t, err, messaging.ErrPermanent)
+}
+
+// recordingCounter records the labels of every Inc call
+type recordingCounter struct {
+	incs [][]string
+}
+
+func (c *recordingCounter) Inc(labels ...string) {
+	c.incs = append(c.incs, labels)
+}
+
+func TestPlanStatsHandler(t *testing.T) {
+	enterprise := validSignup()
+	enterprise.Plan = "enterprise"
+
+	tests := []struct {
+		name      string
+		event     event.UserSignedUp
+		random    float64
+		wantErr   error
+		wantCount int
+	}{
+		{name: "counted", event: validSignup(), random: 0.9, wantCount: 1},
+		{name: "simulated failure", event: validSignup(), random: 0.1, wantErr: ErrSimulatedFailure},
+		{name: "unknown plan", event: enterprise, random: 0.9, wantErr: messaging.ErrPermanent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signups := &recordingCounter{}
+			h := newPlanStatsHandler(signups, 0.5, func() float64 { return tt.random })
+
+			err := h.Handle(context.Background(), signupMessage(t, tt.event, 1))
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Len(t, signups.incs, tt.wantCount)
+		})
+	}
+}
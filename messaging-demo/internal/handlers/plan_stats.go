@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/metricsx"
+
+	"github.com/gostratum/examples/messaging-demo/internal/event"
+	"github.com/gostratum/examples/messaging-demo/internal/messaging"
+)
+
+// ErrSimulatedFailure is returned for the simulated transient failures
+var ErrSimulatedFailure = errors.New("simulated transient failure")
+
+// PlanStatsConfig tunes the plan statistics handler
+type PlanStatsConfig struct {
+	// FailureRate is the fraction of deliveries (0-1) that fail as if a downstream
+	// store were unavailable, to show naks and redelivery
+	FailureRate float64 `mapstructure:"failure_rate" default:"0.2"`
+}
+
+// Prefix implements configx.Configurable
+func (PlanStatsConfig) Prefix() string {
+	return "plan_stats"
+}
+
+// PlanStatsHandler counts signups per plan. It is a second durable consumer on
+// the same subject, so every event reaches both handlers independently. A lost
+// ack can count an event twice, which is acceptable for statistics.
+type PlanStatsHandler struct {
+	signups     counter
+	failureRate float64
+	// random returns a float in [0, 1); replaced in tests for deterministic failures
+	random func() float64
+}
+
+// NewPlanStatsHandler creates the handler from the plan_stats config section
+func NewPlanStatsHandler(loader configx.Loader, metrics metricsx.Metrics) (*PlanStatsHandler, error) {
+	var cfg PlanStatsConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load plan_stats config: %w", err)
+	}
+	if cfg.FailureRate < 0 || cfg.FailureRate > 1 {
+		return nil, fmt.Errorf("plan_stats.failure_rate must be between 0 and 1, got %v", cfg.FailureRate)
+	}
+	signups := metrics.Counter("signups_total",
+		metricsx.WithHelp("User signups counted by the plan-stats consumer, by plan"),
+		metricsx.WithLabels("plan"),
+	)
+	return newPlanStatsHandler(signups, cfg.FailureRate, rand.Float64), nil
+}
+
+// counter is the part of metricsx.Counter the handler uses
+type counter interface {
+	Inc(labels ...string)
+}
+
+func newPlanStatsHandler(signups counter, failureRate float64, random func() float64) *PlanStatsHandler {
+	return &PlanStatsHandler{signups: signups, failureRate: failureRate, random: random}
+}
+
+// Name implements messaging.Handler
+func (h *PlanStatsHandler) Name() string {
+	return "plan-stats"
+}
+
+// Subject implements messaging.Handler
+func (h *PlanStatsHandler) Subject() string {
+	return event.SubjectUserSignedUp
+}
+
+// Handle implements messaging.Handler
+func (h *PlanStatsHandler) Handle(ctx context.Context, msg messaging.Message) error {
+	var e event.UserSignedUp
+	if err := msg.Decode(&e); err != nil {
+		return err
+	}
+	if err := e.Validate(); err != nil {
+		return messaging.Permanent(err)
+	}
+
+	if h.random() < h.failureRate {
+		return ErrSimulatedFailure
+	}
+
+	h.signups.Inc(e.Plan)
+	return nil
+}
@@ -0,0 +1,65 @@
+// Package handlers holds the consumer's message handlers
+package handlers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gostratum/core/logx"
+
+	"github.com/gostratum/examples/messaging-demo/internal/event"
+	"github.com/gostratum/examples/messaging-demo/internal/messaging"
+)
+
+// WelcomeHandler greets new users. It stands in for a side effect that must
+// not repeat, so it remembers which events it has already handled.
+type WelcomeHandler struct {
+	log logx.Logger
+
+	mu   sync.Mutex
+	sent map[string]bool
+}
+
+// NewWelcomeHandler creates the welcome handler
+func NewWelcomeHandler(log logx.Logger) *WelcomeHandler {
+	return &WelcomeHandler{log: log, sent: make(map[string]bool)}
+}
+
+// Name implements messaging.Handler
+func (h *WelcomeHandler) Name() string {
+	return "welcome-email"
+}
+
+// Subject implements messaging.Handler
+func (h *WelcomeHandler) Subject() string {
+	return event.SubjectUserSignedUp
+}
+
+// Handle implements messaging.Handler
+func (h *WelcomeHandler) Handle(ctx context.Context, msg messaging.Message) error {
+	var e event.UserSignedUp
+	if err := msg.Decode(&e); err != nil {
+		return err
+	}
+	if err := e.Validate(); err != nil {
+		return messaging.Permanent(err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// Redeliveries of an event that was handled but not acked are skipped
+	if h.sent[e.ID] {
+		h.log.Info("welcome email already sent", logx.String("event_id", e.ID))
+		return nil
+	}
+
+	h.log.Info("sending welcome email",
+		logx.String("event_id", e.ID),
+		logx.String("user_id", e.UserID),
+		logx.String("email", e.Email),
+		logx.Int("attempt", msg.Attempt),
+	)
+	h.sent[e.ID] = true
+	return nil
+}
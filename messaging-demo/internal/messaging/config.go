@@ -0,0 +1,49 @@
+// Package messaging is a small fx module around NATS JetStream: one shared
+// connection, a JSON publisher, and durable consumers for every Handler in the
+// "messaging.handlers" group
+package messaging
+
+import "time"
+
+// Config holds the NATS connection, the stream and the consumer defaults
+type Config struct {
+	URL string `mapstructure:"url" default:"nats://localhost:4222"`
+	// Name identifies the connection in NATS monitoring
+	Name string `mapstructure:"name" default:"messaging-demo"`
+
+	// Stream stores every message published on Subjects. Publisher and consumer
+	// both declare it, so either can start first.
+	Stream   string        `mapstructure:"stream" default:"USERS"`
+	Subjects string        `mapstructure:"subjects" default:"users.>"`
+	MaxAge   time.Duration `mapstructure:"max_age" default:"24h"`
+	// DuplicateWindow is how long message IDs are remembered to drop duplicate publishes
+	DuplicateWindow time.Duration `mapstructure:"duplicate_window" default:"2m"`
+
+	PublishTimeout time.Duration `mapstructure:"publish_timeout" default:"5s"`
+
+	Consumer ConsumerConfig `mapstructure:"consumer"`
+}
+
+// ConsumerConfig applies to every durable consumer the module creates
+type ConsumerConfig struct {
+	// AckWait is how long JetStream waits for an ack before redelivering. Handlers
+	// that run longer are kept alive with in-progress acks.
+	AckWait time.Duration `mapstructure:"ack_wait" default:"30s"`
+	// MaxDeliver is how often a message is delivered before it is given up on
+	MaxDeliver    int `mapstructure:"max_deliver" default:"5"`
+	MaxAckPending int `mapstructure:"max_ack_pending" default:"32"`
+
+	// A failed message is redelivered after BackoffBase × 2^(attempt-1), capped at BackoffMax
+	BackoffBase time.Duration `mapstructure:"backoff_base" default:"500ms"`
+	BackoffMax  time.Duration `mapstructure:"backoff_max" default:"30s"`
+
+	// HandleTimeout bounds one call of a handler
+	HandleTimeout time.Duration `mapstructure:"handle_timeout" default:"1m"`
+	// LagInterval is how often consumer lag is sampled for metrics
+	LagInterval time.Duration `mapstructure:"lag_interval" default:"10s"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "messaging"
+}
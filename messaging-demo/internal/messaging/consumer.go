@@ -0,0 +1,254 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.uber.org/fx"
+)
+
+// outcome is how a delivery was settled
+type outcome string
+
+const (
+	// outcomeAck: handled; the message is done
+	outcomeAck outcome = "ack"
+	// outcomeNak: failed; JetStream redelivers it after a delay
+	outcomeNak outcome = "nak"
+	// outcomeTerm: failed permanently; never redelivered
+	outcomeTerm outcome = "term"
+	// outcomeExhausted: failed on the last allowed attempt; terminated
+	outcomeExhausted outcome = "exhausted"
+)
+
+// settlement is what to do with a delivery after the handler returned
+type settlement struct {
+	Outcome outcome
+	Delay   time.Duration
+}
+
+// settle decides the outcome of a delivery from the handler error and the attempt
+func settle(err error, attempt int, cfg ConsumerConfig) settlement {
+	switch {
+	case err == nil:
+		return settlement{Outcome: outcomeAck}
+	case errors.Is(err, ErrPermanent):
+		return settlement{Outcome: outcomeTerm}
+	case attempt >= cfg.MaxDeliver:
+		return settlement{Outcome: outcomeExhausted}
+	}
+
+	delay := cfg.BackoffBase
+	for i := 1; i < attempt && delay < cfg.BackoffMax; i++ {
+		delay *= 2
+	}
+	return settlement{Outcome: outcomeNak, Delay: min(delay, cfg.BackoffMax)}
+}
+
+// ConsumerParams are the dependencies of RegisterConsumers
+type ConsumerParams struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Broker    *Broker
+	Metrics   *Metrics
+	Log       logx.Logger
+	Handlers  []Handler `group:"messaging.handlers"`
+}
+
+// consumer drives one handler from its durable JetStream consumer
+type consumer struct {
+	handler Handler
+	cfg     ConsumerConfig
+	metrics *consumeMetrics
+	log     logx.Logger
+
+	js         jetstream.Consumer
+	consumeCtx jetstream.ConsumeContext
+}
+
+// RegisterConsumers creates a durable consumer for every handler in the group and
+// ties them to the application lifecycle. Consumers start after the broker has
+// connected and stop, finishing the messages in flight, before it disconnects.
+// This function is designed to be used with fx.Invoke.
+func RegisterConsumers(p ConsumerParams) error {
+	seen := make(map[string]bool)
+	for _, h := range p.Handlers {
+		if seen[h.Name()] {
+			return fmt.Errorf("two handlers share the consumer name %q", h.Name())
+		}
+		seen[h.Name()] = true
+
+		c := &consumer{
+			handler: h,
+			cfg:     p.Broker.cfg.Consumer,
+			metrics: p.Metrics.consume,
+			log:     p.Log,
+		}
+
+		stopLag := func() {}
+		lagDone := make(chan struct{})
+		p.Lifecycle.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				if err := c.start(ctx, p.Broker); err != nil {
+					return err
+				}
+				var lagCtx context.Context
+				lagCtx, stopLag = context.WithCancel(context.Background())
+				go c.reportLag(lagCtx, lagDone)
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				stopLag()
+				<-lagDone
+				return c.stop(ctx)
+			},
+		})
+	}
+
+	return nil
+}
+
+// start creates or updates the durable consumer and begins consuming
+func (c *consumer) start(ctx context.Context, b *Broker) error {
+	js, err := b.JetStream().CreateOrUpdateConsumer(ctx, b.cfg.Stream, jetstream.ConsumerConfig{
+		Durable:       c.handler.Name(),
+		FilterSubject: c.handler.Subject(),
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       c.cfg.AckWait,
+		MaxDeliver:    c.cfg.MaxDeliver,
+		MaxAckPending: c.cfg.MaxAckPending,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create consumer %s: %w", c.handler.Name(), err)
+	}
+	c.js = js
+
+	c.consumeCtx, err = js.Consume(c.handle, jetstream.ConsumeErrHandler(func(_ jetstream.ConsumeContext, err error) {
+		c.log.Warn("JetStream consume error", logx.String("consumer", c.handler.Name()), logx.Err(err))
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to start consumer %s: %w", c.handler.Name(), err)
+	}
+
+	c.log.Info("consuming messages",
+		logx.String("consumer", c.handler.Name()),
+		logx.String("subject", c.handler.Subject()),
+	)
+	return nil
+}
+
+// stop stops pulling messages and waits for the one in flight
+func (c *consumer) stop(ctx context.Context) error {
+	c.consumeCtx.Drain()
+	select {
+	case <-c.consumeCtx.Closed():
+	case <-ctx.Done():
+		c.log.Warn("stopped before the message in flight was handled; it will be redelivered",
+			logx.String("consumer", c.handler.Name()))
+	}
+	return nil
+}
+
+// handle runs the handler for one delivery and acks, naks or terminates it
+func (c *consumer) handle(msg jetstream.Msg) {
+	start := time.Now()
+	name := c.handler.Name()
+
+	attempt := 1
+	if meta, err := msg.Metadata(); err == nil {
+		attempt = int(meta.NumDelivered)
+	}
+	if attempt > 1 {
+		c.metrics.redelivered.Inc(name)
+	}
+
+	err := c.run(msg, attempt)
+	s := settle(err, attempt, c.cfg)
+
+	fields := []logx.Field{
+		logx.String("consumer", name),
+		logx.String("subject", msg.Subject()),
+		logx.Int("attempt", attempt),
+	}
+
+	var settleErr error
+	switch s.Outcome {
+	case outcomeAck:
+		// Wait for the server to confirm the ack, so a lost ack is logged
+		// instead of surprising us as a redelivery
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		settleErr = msg.DoubleAck(ctx)
+		cancel()
+	case outcomeNak:
+		c.log.Warn("message failed, retrying", append(fields, logx.Err(err), logx.String("delay", s.Delay.String()))...)
+		settleErr = msg.NakWithDelay(s.Delay)
+	case outcomeTerm:
+		c.log.Error("message failed permanently", append(fields, logx.Err(err))...)
+		settleErr = msg.TermWithReason(err.Error())
+	case outcomeExhausted:
+		c.log.Error("message failed on its last attempt", append(fields, logx.Err(err))...)
+		settleErr = msg.TermWithReason("max deliveries reached: " + err.Error())
+	}
+	if settleErr != nil {
+		c.log.Error("failed to settle message", append(fields, logx.String("outcome", string(s.Outcome)), logx.Err(settleErr))...)
+	}
+
+	c.metrics.processed.Inc(name, string(s.Outcome))
+	c.metrics.duration.Observe(time.Since(start).Seconds(), name)
+}
+
+// run calls the handler, sending in-progress acks while it works so slow
+// handlers are not redelivered while they are still running
+func (c *consumer) run(msg jetstream.Msg, attempt int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.cfg.HandleTimeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(c.cfg.AckWait / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := msg.InProgress(); err != nil {
+					c.log.Warn("failed to extend ack deadline", logx.String("consumer", c.handler.Name()), logx.Err(err))
+				}
+			}
+		}
+	}()
+
+	return c.handler.Handle(ctx, Message{Subject: msg.Subject(), Data: msg.Data(), Attempt: attempt})
+}
+
+// reportLag samples the consumer state every LagInterval until ctx is cancelled
+func (c *consumer) reportLag(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(c.cfg.LagInterval)
+	defer ticker.Stop()
+
+	name := c.handler.Name()
+	for {
+		info, err := c.js.Info(ctx)
+		if err == nil {
+			c.metrics.pending.Set(float64(info.NumPending), name)
+			c.metrics.ackPending.Set(float64(info.NumAckPending), name)
+		} else if ctx.Err() == nil {
+			c.log.Warn("failed to read consumer info", logx.String("consumer", name), logx.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
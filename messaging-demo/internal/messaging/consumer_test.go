@@ -0,0 +1,48 @@
+package messaging
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSettle(t *testing.T) {
+	cfg := ConsumerConfig{MaxDeliver: 5, BackoffBase: 500 * time.Millisecond, BackoffMax: 3 * time.Second}
+	transient := errors.New("store unavailable")
+
+	tests := []struct {
+		name    string
+		err     error
+		attempt int
+		want    settlement
+	}{
+		{name: "success", err: nil, attempt: 1, want: settlement{Outcome: outcomeAck}},
+		{name: "success on redelivery", err: nil, attempt: 5, want: settlement{Outcome: outcomeAck}},
+		{name: "permanent", err: Permanent(transient), attempt: 1, want: settlement{Outcome: outcomeTerm}},
+		{name: "first failure", err: transient, attempt: 1, want: settlement{Outcome: outcomeNak, Delay: 500 * time.Millisecond}},
+		{name: "backoff doubles", err: transient, attempt: 3, want: settlement{Outcome: outcomeNak, Delay: 2 * time.Second}},
+		{name: "backoff capped", err: transient, attempt: 4, want: settlement{Outcome: outcomeNak, Delay: 3 * time.Second}},
+		{name: "last attempt", err: transient, attempt: 5, want: settlement{Outcome: outcomeExhausted}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, settle(tt.err, tt.attempt, cfg))
+		})
+	}
+}
+
+func TestMessageDecode(t *testing.T) {
+	var v struct {
+		ID string `json:"id"`
+	}
+
+	err := Message{Subject: "users.signed_up", Data: []byte(`{"id":"e1"}`)}.Decode(&v)
+	assert.NoError(t, err)
+	assert.Equal(t, "e1", v.ID)
+
+	err = Message{Subject: "users.signed_up", Data: []byte(`{not json`)}.Decode(&v)
+	assert.ErrorIs(t, err, ErrPermanent)
+}
@@ -0,0 +1,66 @@
+package messaging
+
+import (
+	"github.com/gostratum/metricsx"
+)
+
+// Metrics holds the publish and consume metrics shared by the module
+type Metrics struct {
+	publish *publishMetrics
+	consume *consumeMetrics
+}
+
+// publishMetrics covers publish results and latency
+type publishMetrics struct {
+	published metricsx.Counter
+	duration  metricsx.Histogram
+}
+
+// consumeMetrics covers message outcomes, processing time and consumer lag
+type consumeMetrics struct {
+	processed   metricsx.Counter
+	duration    metricsx.Histogram
+	redelivered metricsx.Counter
+	pending     metricsx.Gauge
+	ackPending  metricsx.Gauge
+}
+
+// NewMetrics registers the messaging metrics
+func NewMetrics(metrics metricsx.Metrics) *Metrics {
+	return &Metrics{
+		publish: &publishMetrics{
+			published: metrics.Counter("messaging_messages_published_total",
+				metricsx.WithHelp("Messages published, by subject and result (stored, duplicate, error)"),
+				metricsx.WithLabels("subject", "result"),
+			),
+			duration: metrics.Histogram("messaging_publish_duration_seconds",
+				metricsx.WithHelp("Time until JetStream acknowledged a publish"),
+				metricsx.WithLabels("subject"),
+				metricsx.WithBuckets(0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 5),
+			),
+		},
+		consume: &consumeMetrics{
+			processed: metrics.Counter("messaging_messages_processed_total",
+				metricsx.WithHelp("Deliveries handled, by consumer and outcome (ack, nak, term, exhausted)"),
+				metricsx.WithLabels("consumer", "outcome"),
+			),
+			duration: metrics.Histogram("messaging_message_processing_seconds",
+				metricsx.WithHelp("Time a handler took for one delivery"),
+				metricsx.WithLabels("consumer"),
+				metricsx.WithBuckets(0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10, 60),
+			),
+			redelivered: metrics.Counter("messaging_messages_redelivered_total",
+				metricsx.WithHelp("Deliveries that were not the first attempt"),
+				metricsx.WithLabels("consumer"),
+			),
+			pending: metrics.Gauge("messaging_consumer_pending_messages",
+				metricsx.WithHelp("Messages in the stream not yet delivered to the consumer (consumer lag)"),
+				metricsx.WithLabels("consumer"),
+			),
+			ackPending: metrics.Gauge("messaging_consumer_ack_pending_messages",
+				metricsx.WithHelp("Messages delivered but not yet acknowledged"),
+				metricsx.WithLabels("consumer"),
+			),
+		},
+	}
+}
@@ -0,0 +1,16 @@
+package messaging
+
+import "go.uber.org/fx"
+
+// Module provides the broker, the publisher and the metrics, and creates a
+// durable consumer for every handler provided with AsHandler
+func Module() fx.Option {
+	return fx.Module("messaging",
+		fx.Provide(
+			NewBroker,
+			NewMetrics,
+			NewPublisher,
+		),
+		fx.Invoke(RegisterConsumers),
+	)
+}
@@ -0,0 +1,73 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// ErrNotConnected is returned when publishing before the broker has started
+var ErrNotConnected = errors.New("not connected to NATS")
+
+// Publisher publishes JSON messages to the stream
+type Publisher struct {
+	broker  *Broker
+	metrics *publishMetrics
+}
+
+// NewPublisher creates a publisher on the shared broker connection
+func NewPublisher(broker *Broker, metrics *Metrics) *Publisher {
+	return &Publisher{broker: broker, metrics: metrics.publish}
+}
+
+// Receipt reports where a published message was stored
+type Receipt struct {
+	Stream   string
+	Sequence uint64
+	// Duplicate is true when the stream already had a message with this ID
+	Duplicate bool
+}
+
+// Publish encodes v as JSON and publishes it on subject. The id becomes the
+// Nats-Msg-Id header, so publishing the same id again within the stream's
+// duplicate window is acknowledged without storing a second copy; callers can
+// retry a publish that timed out without creating duplicates.
+func (p *Publisher) Publish(ctx context.Context, subject, id string, v any) (*Receipt, error) {
+	js := p.broker.JetStream()
+	if js == nil {
+		return nil, ErrNotConnected
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode message for %s: %w", subject, err)
+	}
+
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+	msg.Header.Set(HeaderContentType, "application/json")
+
+	ctx, cancel := context.WithTimeout(ctx, p.broker.cfg.PublishTimeout)
+	defer cancel()
+
+	start := time.Now()
+	ack, err := js.PublishMsg(ctx, msg, jetstream.WithMsgID(id))
+	p.metrics.duration.Observe(time.Since(start).Seconds(), subject)
+	if err != nil {
+		p.metrics.published.Inc(subject, "error")
+		return nil, fmt.Errorf("failed to publish to %s: %w", subject, err)
+	}
+
+	result := "stored"
+	if ack.Duplicate {
+		result = "duplicate"
+	}
+	p.metrics.published.Inc(subject, result)
+
+	return &Receipt{Stream: ack.Stream, Sequence: ack.Sequence, Duplicate: ack.Duplicate}, nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/dbx"
+	httpAdapter "github.com/gostratum/examples/multitenant-demo/internal/adapter/http"
+	repoAdapter "github.com/gostratum/examples/multitenant-demo/internal/adapter/repo"
+	"github.com/gostratum/examples/multitenant-demo/internal/tenancy"
+	"github.com/gostratum/examples/multitenant-demo/internal/usecase"
+	"github.com/gostratum/examples/redact"
+	"github.com/gostratum/httpx"
+)
+
+func main() {
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// Include dbx module; it opens one connection per tenant database.
+		// auto_migrate is off: the tenancy module migrates each tenant itself.
+		dbx.Module(),
+
+		// Include httpx module
+		httpx.Module(),
+
+		// Tenant registry: resolves tenants and migrates their databases
+		tenancy.Module(),
+
+		// Provide dependencies
+		fx.Provide(
+			// GORM repositories, routed to the tenant's database per call
+			repoAdapter.NewProjectRepo,
+
+			// Usecase services
+			usecase.NewProjectService,
+
+			// HTTP handlers
+			httpAdapter.NewProjectHandler,
+			httpAdapter.NewTenantHandler,
+		),
+
+		// Invoke setup functions
+		fx.Invoke(
+			httpAdapter.RegisterRoutes,
+		),
+	)
+
+	app.Run()
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gostratum/examples/multitenant-demo/internal/domain"
+	"github.com/gostratum/examples/multitenant-demo/internal/tenancy"
+)
+
+// CreateProjectRequest represents the request payload for creating a project
+type CreateProjectRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+// ProjectResponse is the HTTP DTO for project data
+type ProjectResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// FromDomainProject converts a domain.Project to ProjectResponse DTO
+func FromDomainProject(project *domain.Project) *ProjectResponse {
+	if project == nil {
+		return nil
+	}
+	return &ProjectResponse{
+		ID:          project.ID,
+		Name:        project.Name,
+		Description: project.Description,
+		CreatedAt:   project.CreatedAt,
+	}
+}
+
+// TenantResponse is the HTTP DTO for a tenant and its migration state
+type TenantResponse struct {
+	ID         string     `json:"id"`
+	State      string     `json:"state"`
+	Version    uint       `json:"version"`
+	Error      string     `json:"error,omitempty"`
+	MigratedAt *time.Time `json:"migrated_at,omitempty"`
+}
+
+// FromTenantStatus converts a tenancy.Status to TenantResponse DTO
+func FromTenantStatus(s tenancy.Status) TenantResponse {
+	resp := TenantResponse{ID: s.ID, State: string(s.State), Version: s.Version, Error: s.Error}
+	if !s.MigratedAt.IsZero() {
+		resp.MigratedAt = &s.MigratedAt
+	}
+	return resp
+}
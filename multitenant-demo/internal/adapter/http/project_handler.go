@@ -0,0 +1,100 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/multitenant-demo/internal/domain"
+	"github.com/gostratum/examples/multitenant-demo/internal/usecase"
+)
+
+// Projects manages the projects of the tenant in ctx; implemented by
+// usecase.ProjectService
+type Projects interface {
+	CreateProject(ctx context.Context, name, description string) (*domain.Project, error)
+	GetProject(ctx context.Context, id string) (*domain.Project, error)
+	ListProjects(ctx context.Context) ([]*domain.Project, error)
+}
+
+// ProjectHandler handles project-related HTTP requests. It runs behind
+// TenantMiddleware and never deals with tenants itself.
+type ProjectHandler struct {
+	service Projects
+	log     logx.Logger
+}
+
+// NewProjectHandler creates a new project handler
+func NewProjectHandler(service *usecase.ProjectService, log logx.Logger) *ProjectHandler {
+	return newProjectHandler(service, log)
+}
+
+func newProjectHandler(service Projects, log logx.Logger) *ProjectHandler {
+	return &ProjectHandler{service: service, log: log}
+}
+
+// CreateProject handles POST /projects
+func (h *ProjectHandler) CreateProject(c *gin.Context) {
+	var req CreateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid request payload", nil)
+		return
+	}
+
+	project, err := h.service.CreateProject(c.Request.Context(), req.Name, req.Description)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.Created(c, "/projects/"+project.ID, FromDomainProject(project))
+}
+
+// GetProject handles GET /projects/:id
+func (h *ProjectHandler) GetProject(c *gin.Context) {
+	project, err := h.service.GetProject(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.OK(c, FromDomainProject(project), nil)
+}
+
+// ListProjects handles GET /projects
+func (h *ProjectHandler) ListProjects(c *gin.Context) {
+	projects, err := h.service.ListProjects(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	resp := make([]*ProjectResponse, len(projects))
+	for i, p := range projects {
+		resp[i] = FromDomainProject(p)
+	}
+	responsex.OK(c, resp, nil)
+}
+
+// handleError maps usecase errors to HTTP responses
+func (h *ProjectHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrNotFound):
+		responsex.Error(c, http.StatusNotFound, "NOT_FOUND", "project not found", nil)
+	case errors.Is(err, usecase.ErrConflict):
+		responsex.Error(c, http.StatusConflict, "PROJECT_EXISTS", err.Error(), nil)
+	case errors.Is(err, usecase.ErrInvalid):
+		responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
+	case errors.Is(err, usecase.ErrUnavailable):
+		h.log.Error("tenant database unavailable", logx.Err(err))
+		c.Header("Retry-After", "2")
+		responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "service temporarily unavailable", nil)
+	default:
+		h.log.Error("unexpected error", logx.Err(err))
+		responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+	}
+}
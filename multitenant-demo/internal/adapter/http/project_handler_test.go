@@ -0,0 +1,142 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/multitenant-demo/internal/domain"
+	"github.com/gostratum/examples/multitenant-demo/internal/tenancy"
+	"github.com/gostratum/examples/multitenant-demo/internal/usecase"
+)
+
+// fakeProjects keeps one list of projects per tenant, taken from ctx
+type fakeProjects struct {
+	projects map[string][]*domain.Project
+	err      error
+}
+
+func (f *fakeProjects) CreateProject(ctx context.Context, name, description string) (*domain.Project, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	id, _ := tenancy.FromContext(ctx)
+	p := &domain.Project{ID: fmt.Sprintf("%s-%d", id, len(f.projects[id])+1), Name: name, Description: description, CreatedAt: time.Now()}
+	f.projects[id] = append(f.projects[id], p)
+	return p, nil
+}
+
+func (f *fakeProjects) GetProject(ctx context.Context, projectID string) (*domain.Project, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	id, _ := tenancy.FromContext(ctx)
+	for _, p := range f.projects[id] {
+		if p.ID == projectID {
+			return p, nil
+		}
+	}
+	return nil, usecase.ErrNotFound
+}
+
+func (f *fakeProjects) ListProjects(ctx context.Context) ([]*domain.Project, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	id, _ := tenancy.FromContext(ctx)
+	return f.projects[id], nil
+}
+
+func setupRouter(projects Projects) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	handler := newProjectHandler(projects, logx.NewNoopLogger())
+	tenants := &fakeResolver{states: map[string]error{"acme": nil, "globex": nil}}
+
+	e := gin.New()
+	g := e.Group("/projects", TenantMiddleware(tenants))
+	g.POST("", handler.CreateProject)
+	g.GET("", handler.ListProjects)
+	g.GET("/:id", handler.GetProject)
+	return e
+}
+
+func do(e *gin.Engine, method, path, tenant, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tenant-ID", tenant)
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w
+}
+
+func TestCreateAndGetProject(t *testing.T) {
+	e := setupRouter(&fakeProjects{projects: map[string][]*domain.Project{}})
+
+	w := do(e, http.MethodPost, "/projects", "acme", `{"name":"Website","description":"Relaunch"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "/projects/acme-1", w.Header().Get("Location"))
+
+	var created responsex.Envelope[ProjectResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "Website", created.Data.Name)
+
+	w = do(e, http.MethodGet, "/projects/acme-1", "acme", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = do(e, http.MethodGet, "/projects/acme-1", "globex", "")
+	assert.Equal(t, http.StatusNotFound, w.Code, "projects are looked up in the caller's tenant")
+
+	w = do(e, http.MethodGet, "/projects", "globex", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list responsex.Envelope[[]ProjectResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Empty(t, list.Data)
+}
+
+func TestCreateProjectInvalidPayload(t *testing.T) {
+	e := setupRouter(&fakeProjects{projects: map[string][]*domain.Project{}})
+
+	w := do(e, http.MethodPost, "/projects", "acme", `{"description":"no name"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_REQUEST")
+}
+
+func TestProjectHandlerErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "conflict", err: fmt.Errorf("%w: a project named \"Website\" already exists", usecase.ErrConflict), wantStatus: http.StatusConflict, wantCode: "PROJECT_EXISTS"},
+		{name: "invalid", err: usecase.ErrInvalid, wantStatus: http.StatusBadRequest, wantCode: "INVALID_INPUT"},
+		{name: "unavailable", err: usecase.ErrUnavailable, wantStatus: http.StatusServiceUnavailable, wantCode: "SERVICE_UNAVAILABLE"},
+		{name: "unexpected", err: errors.New("boom"), wantStatus: http.StatusInternalServerError, wantCode: "INTERNAL_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := setupRouter(&fakeProjects{err: tt.err})
+			w := do(e, http.MethodPost, "/projects", "acme", `{"name":"Website"}`)
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			var env responsex.Envelope[ProjectResponse]
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+			require.NotNil(t, env.Error)
+			assert.Equal(t, tt.wantCode, env.Error.Code)
+		})
+	}
+}
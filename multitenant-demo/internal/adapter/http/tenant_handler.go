@@ -0,0 +1,61 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/multitenant-demo/internal/tenancy"
+)
+
+// TenantAdmin reports and retries tenant migrations; implemented by
+// tenancy.Registry
+type TenantAdmin interface {
+	Tenants() []tenancy.Status
+	Migrate(id string) (tenancy.Status, error)
+}
+
+// TenantHandler serves the operator endpoints for tenants. In production they
+// belong behind authentication, or on an internal listener.
+type TenantHandler struct {
+	tenants TenantAdmin
+	log     logx.Logger
+}
+
+// NewTenantHandler creates a new tenant handler
+func NewTenantHandler(registry *tenancy.Registry, log logx.Logger) *TenantHandler {
+	return newTenantHandler(registry, log)
+}
+
+func newTenantHandler(tenants TenantAdmin, log logx.Logger) *TenantHandler {
+	return &TenantHandler{tenants: tenants, log: log}
+}
+
+// ListTenants handles GET /admin/tenants
+func (h *TenantHandler) ListTenants(c *gin.Context) {
+	statuses := h.tenants.Tenants()
+	resp := make([]TenantResponse, len(statuses))
+	for i, s := range statuses {
+		resp[i] = FromTenantStatus(s)
+	}
+	responsex.OK(c, resp, nil)
+}
+
+// MigrateTenant handles POST /admin/tenants/:id/migrate
+func (h *TenantHandler) MigrateTenant(c *gin.Context) {
+	status, err := h.tenants.Migrate(c.Param("id"))
+	switch {
+	case err == nil:
+		responsex.OK(c, FromTenantStatus(status), nil)
+	case errors.Is(err, tenancy.ErrUnknownTenant):
+		responsex.Error(c, http.StatusNotFound, "TENANT_NOT_FOUND", "tenant not found", nil)
+	case errors.Is(err, tenancy.ErrMigrationRunning):
+		responsex.Error(c, http.StatusConflict, "MIGRATION_RUNNING", "the tenant is already being migrated", nil)
+	default:
+		// The status carries the migration error for the operator
+		responsex.Error(c, http.StatusBadGateway, "MIGRATION_FAILED", err.Error(), FromTenantStatus(status))
+	}
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/multitenant-demo/internal/tenancy"
+)
+
+// fakeTenants returns fixed statuses and migration results
+type fakeTenants struct {
+	statuses []tenancy.Status
+	err      error
+}
+
+func (f *fakeTenants) Tenants() []tenancy.Status {
+	return f.statuses
+}
+
+func (f *fakeTenants) Migrate(id string) (tenancy.Status, error) {
+	if id != "acme" {
+		return tenancy.Status{}, tenancy.ErrUnknownTenant
+	}
+	return f.statuses[0], f.err
+}
+
+func setupAdminRouter(tenants TenantAdmin) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	handler := newTenantHandler(tenants, logx.NewNoopLogger())
+
+	e := gin.New()
+	e.GET("/admin/tenants", handler.ListTenants)
+	e.POST("/admin/tenants/:id/migrate", handler.MigrateTenant)
+	return e
+}
+
+func TestListTenants(t *testing.T) {
+	e := setupAdminRouter(&fakeTenants{statuses: []tenancy.Status{
+		{ID: "acme", State: tenancy.StateReady, Version: 1, MigratedAt: time.Now()},
+		{ID: "globex", State: tenancy.StateFailed, Error: "connection refused"},
+	}})
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/tenants", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var env responsex.Envelope[[]TenantResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+	require.Len(t, env.Data, 2)
+	assert.Equal(t, "ready", env.Data[0].State)
+	assert.NotNil(t, env.Data[0].MigratedAt)
+	assert.Equal(t, "connection refused", env.Data[1].Error)
+	assert.Nil(t, env.Data[1].MigratedAt)
+}
+
+func TestMigrateTenant(t *testing.T) {
+	tests := []struct {
+		name       string
+		tenant     string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "migrated", tenant: "acme", wantStatus: http.StatusOK},
+		{name: "unknown", tenant: "umbrella", wantStatus: http.StatusNotFound, wantCode: "TENANT_NOT_FOUND"},
+		{name: "running", tenant: "acme", err: tenancy.ErrMigrationRunning, wantStatus: http.StatusConflict, wantCode: "MIGRATION_RUNNING"},
+		{name: "failed", tenant: "acme", err: errors.New("failed to migrate tenant acme: connection refused"), wantStatus: http.StatusBadGateway, wantCode: "MIGRATION_FAILED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := setupAdminRouter(&fakeTenants{statuses: []tenancy.Status{{ID: "acme", State: tenancy.StateReady}}, err: tt.err})
+
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/tenants/"+tt.tenant+"/migrate", nil))
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantCode != "" {
+				assert.Contains(t, w.Body.String(), tt.wantCode)
+			}
+		})
+	}
+}
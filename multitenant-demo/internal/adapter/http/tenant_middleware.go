@@ -0,0 +1,51 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/multitenant-demo/internal/tenancy"
+)
+
+// TenantResolver finds and checks the tenant of a request; implemented by
+// tenancy.Registry
+type TenantResolver interface {
+	Resolve(req *http.Request) (string, error)
+	Check(id string) error
+}
+
+// TenantMiddleware resolves the tenant of every request and puts it in the
+// request context, where repositories pick it up. Requests for a missing,
+// unknown or unavailable tenant stop here.
+func TenantMiddleware(tenants TenantResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := tenants.Resolve(c.Request)
+		if err == nil {
+			err = tenants.Check(id)
+		}
+
+		switch {
+		case err == nil:
+		case errors.Is(err, tenancy.ErrNoTenant):
+			responsex.Error(c, http.StatusBadRequest, "TENANT_REQUIRED", "the request does not name a tenant", nil)
+		case errors.Is(err, tenancy.ErrUnknownTenant):
+			// Same answer whether or not the ID is well formed, so tenant IDs cannot be probed
+			responsex.Error(c, http.StatusNotFound, "TENANT_NOT_FOUND", "tenant not found", nil)
+		case errors.Is(err, tenancy.ErrTenantUnavailable):
+			c.Header("Retry-After", "5")
+			responsex.Error(c, http.StatusServiceUnavailable, "TENANT_UNAVAILABLE", "tenant is not available yet", nil)
+		default:
+			responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+		}
+		if err != nil {
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(tenancy.WithTenant(c.Request.Context(), id))
+		c.Next()
+	}
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gostratum/examples/multitenant-demo/internal/tenancy"
+)
+
+// fakeResolver reads the tenant from X-Tenant-ID and checks it against states
+type fakeResolver struct {
+	states map[string]error
+}
+
+func (f *fakeResolver) Resolve(req *http.Request) (string, error) {
+	if id := req.Header.Get("X-Tenant-ID"); id != "" {
+		return id, nil
+	}
+	return "", tenancy.ErrNoTenant
+}
+
+func (f *fakeResolver) Check(id string) error {
+	err, ok := f.states[id]
+	if !ok {
+		return tenancy.ErrUnknownTenant
+	}
+	return err
+}
+
+func TestTenantMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	resolver := &fakeResolver{states: map[string]error{
+		"acme":    nil,
+		"globex":  tenancy.ErrTenantUnavailable,
+		"initech": errors.New("boom"),
+	}}
+
+	var seen string
+	e := gin.New()
+	e.GET("/projects", TenantMiddleware(resolver), func(c *gin.Context) {
+		seen, _ = tenancy.FromContext(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name       string
+		tenant     string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "ready", tenant: "acme", wantStatus: http.StatusNoContent},
+		{name: "missing", wantStatus: http.StatusBadRequest, wantCode: "TENANT_REQUIRED"},
+		{name: "unknown", tenant: "umbrella", wantStatus: http.StatusNotFound, wantCode: "TENANT_NOT_FOUND"},
+		{name: "unavailable", tenant: "globex", wantStatus: http.StatusServiceUnavailable, wantCode: "TENANT_UNAVAILABLE"},
+		{name: "unexpected", tenant: "initech", wantStatus: http.StatusInternalServerError, wantCode: "INTERNAL_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen = ""
+			req := httptest.NewRequest(http.MethodGet, "/projects", nil)
+			if tt.tenant != "" {
+				req.Header.Set("X-Tenant-ID", tt.tenant)
+			}
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantCode == "" {
+				assert.Equal(t, tt.tenant, seen, "the handler sees the tenant in the request context")
+				return
+			}
+			assert.Empty(t, seen, "the handler does not run")
+			assert.Contains(t, w.Body.String(), tt.wantCode)
+		})
+	}
+}
+
+func TestTenantMiddlewareRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	e := gin.New()
+	e.GET("/projects", TenantMiddleware(&fakeResolver{states: map[string]error{"globex": tenancy.ErrTenantUnavailable}}))
+
+	req := httptest.NewRequest(http.MethodGet, "/projects", nil)
+	req.Header.Set("X-Tenant-ID", "globex")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+
+	assert.Equal(t, "5", w.Header().Get("Retry-After"))
+}
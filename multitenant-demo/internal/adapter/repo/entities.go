@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/gostratum/examples/multitenant-demo/internal/domain"
+)
+
+// ProjectEntity represents the GORM model for projects table
+type ProjectEntity struct {
+	ID          string    `gorm:"primaryKey"`
+	Name        string    `gorm:"not null;uniqueIndex"`
+	Description string    `gorm:"not null;default:''"`
+	CreatedAt   time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for ProjectEntity
+func (ProjectEntity) TableName() string {
+	return "projects"
+}
+
+// ToDomain converts ProjectEntity to domain.Project
+func (p *ProjectEntity) ToDomain() *domain.Project {
+	return &domain.Project{
+		ID:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		CreatedAt:   p.CreatedAt,
+	}
+}
+
+// FromDomain creates ProjectEntity from domain.Project
+func (p *ProjectEntity) FromDomain(project *domain.Project) {
+	p.ID = project.ID
+	p.Name = project.Name
+	p.Description = project.Description
+	p.CreatedAt = project.CreatedAt
+}
@@ -0,0 +1,93 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/gostratum/examples/multitenant-demo/internal/domain"
+	"github.com/gostratum/examples/multitenant-demo/internal/tenancy"
+	"github.com/gostratum/examples/multitenant-demo/internal/usecase"
+)
+
+// TenantDB returns the database of the tenant in ctx; implemented by
+// tenancy.Registry
+type TenantDB interface {
+	DB(ctx context.Context) (*gorm.DB, error)
+}
+
+// ProjectRepo implements the ProjectRepository interface using GORM. It holds
+// no connection of its own: every call looks up the tenant's database, so a
+// query can never reach another tenant's data.
+type ProjectRepo struct {
+	dbs TenantDB
+}
+
+// NewProjectRepo creates a new GORM-based project repository
+func NewProjectRepo(registry *tenancy.Registry) usecase.ProjectRepository {
+	return newProjectRepo(registry)
+}
+
+func newProjectRepo(dbs TenantDB) *ProjectRepo {
+	return &ProjectRepo{dbs: dbs}
+}
+
+// Create implements usecase.ProjectRepository
+func (r *ProjectRepo) Create(ctx context.Context, project *domain.Project) error {
+	db, err := r.dbs.DB(ctx)
+	if err != nil {
+		return err
+	}
+
+	var entity ProjectEntity
+	entity.FromDomain(project)
+
+	// Insert unless the name is taken; the unique index decides, so two
+	// concurrent requests cannot both create the same name
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&entity)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrConflict
+	}
+	return nil
+}
+
+// FindByID implements usecase.ProjectRepository
+func (r *ProjectRepo) FindByID(ctx context.Context, id string) (*domain.Project, error) {
+	db, err := r.dbs.DB(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var entity ProjectEntity
+	if err := db.Where("id = ?", id).First(&entity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return entity.ToDomain(), nil
+}
+
+// List implements usecase.ProjectRepository
+func (r *ProjectRepo) List(ctx context.Context) ([]*domain.Project, error) {
+	db, err := r.dbs.DB(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var entities []ProjectEntity
+	if err := db.Order("created_at DESC").Find(&entities).Error; err != nil {
+		return nil, err
+	}
+
+	projects := make([]*domain.Project, len(entities))
+	for i := range entities {
+		projects[i] = entities[i].ToDomain()
+	}
+	return projects, nil
+}
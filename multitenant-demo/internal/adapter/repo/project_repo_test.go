@@ -0,0 +1,101 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/gostratum/examples/multitenant-demo/internal/domain"
+	"github.com/gostratum/examples/multitenant-demo/internal/tenancy"
+	"github.com/gostratum/examples/multitenant-demo/migrations"
+	"github.com/gostratum/examples/testkit/sqlitedb"
+)
+
+// tenantDBs is a TenantDB with a SQLite database per tenant
+type tenantDBs map[string]*gorm.DB
+
+func (d tenantDBs) DB(ctx context.Context) (*gorm.DB, error) {
+	id, ok := tenancy.FromContext(ctx)
+	if !ok {
+		return nil, tenancy.ErrNoTenant
+	}
+	db, ok := d[id]
+	if !ok {
+		return nil, tenancy.ErrUnknownTenant
+	}
+	return db.WithContext(ctx), nil
+}
+
+// setupTenantDBs creates a migrated SQLite database for each tenant
+func setupTenantDBs(t *testing.T, tenants ...string) tenantDBs {
+	dbs := tenantDBs{}
+	for _, id := range tenants {
+		dbs[id] = sqlitedb.Open(t, migrations.FS)
+	}
+	return dbs
+}
+
+func tenantCtx(id string) context.Context {
+	return tenancy.WithTenant(context.Background(), id)
+}
+
+func TestProjectRepoCreateAndFind(t *testing.T) {
+	repo := newProjectRepo(setupTenantDBs(t, "acme"))
+	ctx := tenantCtx("acme")
+
+	project := domain.NewProject("Website", "Relaunch")
+	require.NoError(t, repo.Create(ctx, project))
+
+	got, err := repo.FindByID(ctx, project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, project.Name, got.Name)
+	assert.Equal(t, project.Description, got.Description)
+	assert.WithinDuration(t, project.CreatedAt, got.CreatedAt, time.Second)
+
+	_, err = repo.FindByID(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	err = repo.Create(ctx, domain.NewProject("Website", ""))
+	assert.ErrorIs(t, err, domain.ErrConflict)
+}
+
+func TestProjectRepoList(t *testing.T) {
+	repo := newProjectRepo(setupTenantDBs(t, "acme"))
+	ctx := tenantCtx("acme")
+
+	older := domain.NewProject("Older", "")
+	older.CreatedAt = time.Now().Add(-time.Hour).UTC()
+	require.NoError(t, repo.Create(ctx, older))
+	require.NoError(t, repo.Create(ctx, domain.NewProject("Newer", "")))
+
+	projects, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, projects, 2)
+	assert.Equal(t, "Newer", projects[0].Name)
+	assert.Equal(t, "Older", projects[1].Name)
+}
+
+func TestProjectRepoIsolatesTenants(t *testing.T) {
+	repo := newProjectRepo(setupTenantDBs(t, "acme", "globex"))
+
+	acmeProject := domain.NewProject("Website", "")
+	require.NoError(t, repo.Create(tenantCtx("acme"), acmeProject))
+
+	// Names are unique per tenant, not across tenants
+	require.NoError(t, repo.Create(tenantCtx("globex"), domain.NewProject("Website", "")))
+
+	_, err := repo.FindByID(tenantCtx("globex"), acmeProject.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound, "a tenant cannot read another tenant's project")
+
+	projects, err := repo.List(tenantCtx("acme"))
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+	assert.Equal(t, acmeProject.ID, projects[0].ID)
+
+	_, err = repo.List(context.Background())
+	assert.ErrorIs(t, err, tenancy.ErrNoTenant)
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "errors"
+
+// Domain errors represent business rule violations
+var (
+	// ErrNotFound indicates the requested entity was not found
+	ErrNotFound = errors.New("not found")
+
+	// ErrInvalidInput indicates the provided input violates business rules
+	ErrInvalidInput = errors.New("invalid input")
+
+	// ErrConflict indicates the entity clashes with an existing one
+	ErrConflict = errors.New("conflict")
+)
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// maxNameLength limits project names, in characters
+const maxNameLength = 100
+
+// Project is a tenant's project. Names are unique within a tenant; two tenants
+// can have projects of the same name, since each has its own database.
+type Project struct {
+	ID          string
+	Name        string
+	Description string
+	CreatedAt   time.Time
+}
+
+// NewProject creates a project with a new ID
+func NewProject(name, description string) *Project {
+	return &Project{
+		ID:          uuid.NewString(),
+		Name:        strings.TrimSpace(name),
+		Description: strings.TrimSpace(description),
+		CreatedAt:   time.Now().UTC(),
+	}
+}
+
+// Validate checks the project against the business rules
+func (p *Project) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(p.Name) > maxNameLength {
+		return fmt.Errorf("%w: name must be at most %d characters", ErrInvalidInput, maxNameLength)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProject(t *testing.T) {
+	p := NewProject("  Website  ", " Relaunch ")
+	assert.NotEmpty(t, p.ID)
+	assert.Equal(t, "Website", p.Name)
+	assert.Equal(t, "Relaunch", p.Description)
+	assert.False(t, p.CreatedAt.IsZero())
+
+	assert.NotEqual(t, p.ID, NewProject("Website", "").ID)
+}
+
+func TestProjectValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		project string
+		wantErr bool
+	}{
+		{name: "valid", project: "Website"},
+		{name: "empty", project: "", wantErr: true},
+		{name: "blank", project: "   ", wantErr: true},
+		{name: "longest", project: strings.Repeat("é", maxNameLength)},
+		{name: "too long", project: strings.Repeat("a", maxNameLength+1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewProject(tt.project, "").Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidInput)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
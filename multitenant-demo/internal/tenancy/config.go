@@ -0,0 +1,50 @@
+// Package tenancy is a small fx module for database-per-tenant services: it
+// maps tenant IDs to dbx connections, migrates every tenant database in the
+// background, and resolves the tenant of an HTTP request
+package tenancy
+
+import "time"
+
+// Config lists the tenants and how requests name them
+type Config struct {
+	// Tenants are the tenant IDs. Each is also the name of its dbx connection
+	// in db.databases, so adding a tenant is a config change.
+	Tenants []string `mapstructure:"tenants"`
+	// Header carries the tenant ID of a request
+	Header string `mapstructure:"header" default:"X-Tenant-ID"`
+	// BaseDomain resolves requests to <tenant>.<base_domain> when they have no
+	// tenant header; empty turns subdomain resolution off
+	BaseDomain string `mapstructure:"base_domain"`
+
+	// SkipMigrations marks every tenant ready at start without migrating, for
+	// deployments that migrate as a separate step
+	SkipMigrations bool `mapstructure:"skip_migrations"`
+	// MigrationConcurrency is how many tenant databases migrate at once
+	MigrationConcurrency int `mapstructure:"migration_concurrency" default:"4"`
+	// MigrationTimeout bounds the migration of one tenant database
+	MigrationTimeout time.Duration `mapstructure:"migration_timeout" default:"2m"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "tenancy"
+}
+
+// databasesConfig is the part of dbx's db section the registry reads: where
+// each connection points and where its migrations are
+type databasesConfig struct {
+	Databases map[string]databaseConfig `mapstructure:"databases"`
+}
+
+// databaseConfig holds the dbx settings of one connection that migrations need
+type databaseConfig struct {
+	DSN                  string        `mapstructure:"dsn"`
+	MigrationSource      string        `mapstructure:"migration_source"`
+	MigrationTable       string        `mapstructure:"migration_table"`
+	MigrationLockTimeout time.Duration `mapstructure:"migration_lock_timeout"`
+}
+
+// Prefix implements configx.Configurable
+func (databasesConfig) Prefix() string {
+	return "db"
+}
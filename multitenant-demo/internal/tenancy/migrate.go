@@ -0,0 +1,45 @@
+package tenancy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gostratum/dbx/migrate"
+)
+
+// migrator brings one tenant database to the latest schema version and
+// returns that version
+type migrator interface {
+	migrate(ctx context.Context, db databaseConfig) (uint, error)
+}
+
+// dbxMigrator runs the migrations of a connection with dbx/migrate, using the
+// migration settings of its db.databases entry. dbx/migrate takes a lock in the
+// database, so instances starting together do not migrate a tenant twice.
+type dbxMigrator struct{}
+
+func (dbxMigrator) migrate(ctx context.Context, db databaseConfig) (uint, error) {
+	var opts []migrate.Option
+	if db.MigrationSource != "" {
+		opts = append(opts, migrate.WithDir(db.MigrationSource))
+	}
+	if db.MigrationTable != "" {
+		opts = append(opts, migrate.WithTable(db.MigrationTable))
+	}
+	if db.MigrationLockTimeout > 0 {
+		opts = append(opts, migrate.WithLockTimeout(db.MigrationLockTimeout))
+	}
+
+	if err := migrate.Up(ctx, db.DSN, opts...); err != nil {
+		return 0, err
+	}
+
+	status, err := migrate.GetStatus(ctx, db.DSN, opts...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migration status: %w", err)
+	}
+	if status.Dirty {
+		return 0, fmt.Errorf("schema version %d is dirty; fix it and force the version", status.Current)
+	}
+	return status.Current, nil
+}
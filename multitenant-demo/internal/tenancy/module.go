@@ -0,0 +1,12 @@
+package tenancy
+
+import "go.uber.org/fx"
+
+// Module provides the tenant registry. It needs dbx.Module for the
+// connections, and migrates the tenant databases from application start.
+func Module() fx.Option {
+	return fx.Module("tenancy",
+		fx.Provide(NewRegistry),
+		fx.Invoke(Register),
+	)
+}
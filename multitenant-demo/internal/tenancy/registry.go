@@ -0,0 +1,371 @@
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/dbx"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+// State is where a tenant database is in its migration
+type State string
+
+const (
+	// StatePending: waiting for a migration slot
+	StatePending State = "pending"
+	// StateMigrating: migrations are running
+	StateMigrating State = "migrating"
+	// StateReady: the schema is current and requests are served
+	StateReady State = "ready"
+	// StateFailed: the last migration failed; requests get ErrTenantUnavailable
+	StateFailed State = "failed"
+)
+
+// Status is a snapshot of one tenant
+type Status struct {
+	ID    string
+	State State
+	// Version is the schema version after the last successful migration
+	Version    uint
+	Error      string
+	MigratedAt time.Time
+}
+
+// Params are the dependencies of NewRegistry
+type Params struct {
+	fx.In
+
+	Loader      configx.Loader
+	Connections dbx.Connections
+	Registry    core.Registry
+	Log         logx.Logger
+}
+
+// Registry maps tenant IDs to their dbx connections and tracks whether each
+// tenant database is migrated
+type Registry struct {
+	cfg      Config
+	tenants  map[string]*tenant
+	ids      []string
+	migrator migrator
+	log      logx.Logger
+
+	// runCtx is cancelled at stop, abandoning migrations still running
+	runCtx     context.Context
+	cancelRuns context.CancelFunc
+	migrations sync.WaitGroup
+}
+
+// tenant is one tenant's connection and migration state
+type tenant struct {
+	id string
+	db *gorm.DB
+	// database is where migrations connect; the same database as db
+	database databaseConfig
+
+	mu     sync.Mutex
+	status Status
+	// running is set while a migration of this tenant is in progress
+	running bool
+}
+
+// NewRegistry creates the registry from the tenancy config section and the dbx
+// connections, and registers its readiness check. It fails if a tenant has no
+// connection.
+func NewRegistry(p Params) (*Registry, error) {
+	var cfg Config
+	if err := p.Loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load tenancy config: %w", err)
+	}
+	var dbs databasesConfig
+	if err := p.Loader.Bind(&dbs); err != nil {
+		return nil, fmt.Errorf("failed to load db config: %w", err)
+	}
+
+	r, err := newRegistry(cfg, dbs, p.Connections, dbxMigrator{}, p.Log)
+	if err != nil {
+		return nil, err
+	}
+	p.Registry.Register(&readinessCheck{registry: r})
+	return r, nil
+}
+
+func newRegistry(cfg Config, dbs databasesConfig, conns dbx.Connections, m migrator, log logx.Logger) (*Registry, error) {
+	if len(cfg.Tenants) == 0 {
+		return nil, errors.New("tenancy.tenants is empty")
+	}
+	if cfg.MigrationConcurrency <= 0 {
+		return nil, fmt.Errorf("tenancy.migration_concurrency must be positive, got %d", cfg.MigrationConcurrency)
+	}
+
+	r := &Registry{
+		cfg:      cfg,
+		tenants:  make(map[string]*tenant, len(cfg.Tenants)),
+		migrator: m,
+		log:      log,
+	}
+	for _, id := range cfg.Tenants {
+		if !ValidID(id) {
+			return nil, fmt.Errorf("tenancy.tenants: %q is not a valid tenant ID", id)
+		}
+		if _, ok := r.tenants[id]; ok {
+			return nil, fmt.Errorf("tenancy.tenants: %q is listed twice", id)
+		}
+		db, ok := conns[id]
+		if !ok {
+			return nil, fmt.Errorf("tenancy.tenants: %q has no connection in db.databases", id)
+		}
+		database := dbs.Databases[id]
+		if database.DSN == "" && !cfg.SkipMigrations {
+			return nil, fmt.Errorf("db.databases.%s.dsn is not set", id)
+		}
+
+		r.tenants[id] = &tenant{id: id, db: db, database: database, status: Status{ID: id, State: StatePending}}
+		r.ids = append(r.ids, id)
+	}
+	sort.Strings(r.ids)
+
+	r.runCtx, r.cancelRuns = context.WithCancel(context.Background())
+	return r, nil
+}
+
+// Register ties tenant migrations to the application lifecycle.
+// This function is designed to be used with fx.Invoke.
+func Register(lc fx.Lifecycle, r *Registry) {
+	lc.Append(fx.Hook{
+		OnStart: r.start,
+		OnStop:  r.stop,
+	})
+}
+
+// start migrates the tenant databases in the background, so one slow or
+// broken tenant holds up neither startup nor the other tenants
+func (r *Registry) start(ctx context.Context) error {
+	if r.cfg.SkipMigrations {
+		for _, t := range r.tenants {
+			t.set(func(s *Status) { s.State = StateReady })
+		}
+		r.log.Info("tenants ready without migrations", logx.Int("tenants", len(r.ids)))
+		return nil
+	}
+
+	r.log.Info("migrating tenant databases",
+		logx.Int("tenants", len(r.ids)),
+		logx.Int("concurrency", r.cfg.MigrationConcurrency),
+	)
+
+	slots := make(chan struct{}, r.cfg.MigrationConcurrency)
+	for _, id := range r.ids {
+		r.migrations.Add(1)
+		go func(t *tenant) {
+			defer r.migrations.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-r.runCtx.Done():
+				return
+			}
+			// A slot freed by a cancelled migration does not start another
+			if r.runCtx.Err() == nil {
+				_ = r.migrate(t)
+			}
+		}(r.tenants[id])
+	}
+	return nil
+}
+
+// stop cancels migrations still running and waits for them to return
+func (r *Registry) stop(ctx context.Context) error {
+	r.cancelRuns()
+
+	done := make(chan struct{})
+	go func() {
+		r.migrations.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.New("tenant migrations still running at shutdown")
+	}
+}
+
+// Migrate migrates one tenant now, for example to retry after a failure, and
+// returns its status once done
+func (r *Registry) Migrate(id string) (Status, error) {
+	t, ok := r.tenants[id]
+	if !ok {
+		return Status{}, ErrUnknownTenant
+	}
+	r.migrations.Add(1)
+	defer r.migrations.Done()
+
+	err := r.migrate(t)
+	return t.snapshot(), err
+}
+
+// migrate brings a tenant database to the latest schema version. The tenant
+// keeps serving requests if it was ready before.
+func (r *Registry) migrate(t *tenant) error {
+	wasReady, busy := false, false
+	t.set(func(s *Status) {
+		if busy = t.running; busy {
+			return
+		}
+		t.running = true
+		wasReady = s.State == StateReady
+		if !wasReady {
+			s.State = StateMigrating
+		}
+	})
+	if busy {
+		return ErrMigrationRunning
+	}
+	defer t.set(func(s *Status) { t.running = false })
+
+	ctx, cancel := context.WithTimeout(r.runCtx, r.cfg.MigrationTimeout)
+	defer cancel()
+
+	start := time.Now()
+	version, err := r.migrator.migrate(ctx, t.database)
+	fields := []logx.Field{
+		logx.String("tenant", t.id),
+		logx.String("duration", time.Since(start).String()),
+	}
+	if err != nil {
+		t.set(func(s *Status) {
+			s.Error = err.Error()
+			if !wasReady {
+				s.State = StateFailed
+			}
+		})
+		r.log.Error("tenant migration failed", append(fields, logx.Err(err))...)
+		return fmt.Errorf("failed to migrate tenant %s: %w", t.id, err)
+	}
+
+	t.set(func(s *Status) {
+		s.State = StateReady
+		s.Version = version
+		s.Error = ""
+		s.MigratedAt = time.Now()
+	})
+	r.log.Info("tenant migrated", append(fields, logx.Int("version", int(version)))...)
+	return nil
+}
+
+// Check reports whether a tenant exists and is ready to serve requests
+func (r *Registry) Check(id string) error {
+	t, ok := r.tenants[id]
+	if !ok {
+		return ErrUnknownTenant
+	}
+	if t.snapshot().State != StateReady {
+		return ErrTenantUnavailable
+	}
+	return nil
+}
+
+// DB returns the connection of the tenant carried by ctx, bound to ctx. There
+// is no fallback connection: a context without a tenant fails with ErrNoTenant.
+func (r *Registry) DB(ctx context.Context) (*gorm.DB, error) {
+	id, ok := FromContext(ctx)
+	if !ok {
+		return nil, ErrNoTenant
+	}
+	if err := r.Check(id); err != nil {
+		return nil, fmt.Errorf("tenant %s: %w", id, err)
+	}
+	return r.tenants[id].db.WithContext(ctx), nil
+}
+
+// Tenants returns the status of every tenant, sorted by ID
+func (r *Registry) Tenants() []Status {
+	statuses := make([]Status, 0, len(r.ids))
+	for _, id := range r.ids {
+		statuses = append(statuses, r.tenants[id].snapshot())
+	}
+	return statuses
+}
+
+// Resolve returns the tenant ID a request names: the tenant header, or else
+// the subdomain of tenancy.base_domain. It does not check that the tenant exists.
+//
+// Both come from the client. Behind authentication, take the tenant from the
+// verified token instead, or check that it matches.
+func (r *Registry) Resolve(req *http.Request) (string, error) {
+	if id := strings.TrimSpace(req.Header.Get(r.cfg.Header)); id != "" {
+		return strings.ToLower(id), nil
+	}
+
+	if r.cfg.BaseDomain != "" {
+		host := req.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.ToLower(host)
+		if sub, ok := strings.CutSuffix(host, "."+strings.ToLower(r.cfg.BaseDomain)); ok && !strings.Contains(sub, ".") {
+			return sub, nil
+		}
+	}
+	return "", ErrNoTenant
+}
+
+func (t *tenant) set(update func(s *Status)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	update(&t.status)
+}
+
+func (t *tenant) snapshot() Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.status
+}
+
+// readinessCheck fails while tenant databases are still migrating, so a new
+// instance gets no traffic before its tenants can be served, and when no
+// tenant is ready at all
+type readinessCheck struct {
+	registry *Registry
+}
+
+func (c *readinessCheck) Name() string {
+	return "tenancy"
+}
+
+func (c *readinessCheck) Kind() core.Kind {
+	return core.Readiness
+}
+
+func (c *readinessCheck) Check(ctx context.Context) error {
+	ready, busy := 0, 0
+	for _, s := range c.registry.Tenants() {
+		switch s.State {
+		case StateReady:
+			ready++
+		case StatePending, StateMigrating:
+			busy++
+		}
+	}
+	if busy > 0 {
+		return fmt.Errorf("%d tenants still migrating", busy)
+	}
+	if ready == 0 {
+		return errors.New("no tenant is ready")
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/dbx"
+	"github.com/gostratum/examples/testkit/sqlitedb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeMigrator fails the DSNs in errs and blocks on gate when it is set
+type fakeMigrator struct {
+	mu    sync.Mutex
+	errs  map[string]error
+	calls map[string]int
+	gate  chan struct{}
+}
+
+func (m *fakeMigrator) migrate(ctx context.Context, db databaseConfig) (uint, error) {
+	m.mu.Lock()
+	if m.calls == nil {
+		m.calls = map[string]int{}
+	}
+	m.calls[db.DSN]++
+	err := m.errs[db.DSN]
+	gate := m.gate
+	m.mu.Unlock()
+
+	if gate != nil {
+		select {
+		case <-gate:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func (m *fakeMigrator) setErr(dsn string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errs[dsn] = err
+}
+
+// newTestRegistry creates a registry with an empty SQLite database per tenant
+func newTestRegistry(t *testing.T, cfg Config, m migrator) *Registry {
+	t.Helper()
+	conns := dbx.Connections{}
+	dbs := databasesConfig{Databases: map[string]databaseConfig{}}
+	for _, id := range cfg.Tenants {
+		conns[id] = sqlitedb.Open(t, nil)
+		dbs.Databases[id] = databaseConfig{DSN: "dsn-" + id}
+	}
+
+	if cfg.Header == "" {
+		cfg.Header = "X-Tenant-ID"
+	}
+	if cfg.MigrationConcurrency == 0 {
+		cfg.MigrationConcurrency = 2
+	}
+	if cfg.MigrationTimeout == 0 {
+		cfg.MigrationTimeout = time.Second
+	}
+
+	r, err := newRegistry(cfg, dbs, conns, m, logx.NewNoopLogger())
+	require.NoError(t, err)
+	return r
+}
+
+// startAndWait starts the registry and waits for the start-up migrations
+func startAndWait(t *testing.T, r *Registry) {
+	t.Helper()
+	require.NoError(t, r.start(context.Background()))
+	r.migrations.Wait()
+}
+
+func TestNewRegistryValidatesConfig(t *testing.T) {
+	conns := dbx.Connections{"acme": &gorm.DB{}}
+	dbs := databasesConfig{Databases: map[string]databaseConfig{"acme": {DSN: "dsn"}}}
+	log := logx.NewNoopLogger()
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		dbs     databasesConfig
+		wantErr string
+	}{
+		{name: "no tenants", cfg: Config{MigrationConcurrency: 1}, dbs: dbs, wantErr: "tenancy.tenants is empty"},
+		{name: "bad concurrency", cfg: Config{Tenants: []string{"acme"}}, dbs: dbs, wantErr: "migration_concurrency"},
+		{name: "invalid ID", cfg: Config{Tenants: []string{"Acme Corp"}, MigrationConcurrency: 1}, dbs: dbs, wantErr: "not a valid tenant ID"},
+		{name: "duplicate", cfg: Config{Tenants: []string{"acme", "acme"}, MigrationConcurrency: 1}, dbs: dbs, wantErr: "listed twice"},
+		{name: "no connection", cfg: Config{Tenants: []string{"globex"}, MigrationConcurrency: 1}, dbs: dbs, wantErr: "has no connection"},
+		{name: "no dsn", cfg: Config{Tenants: []string{"acme"}, MigrationConcurrency: 1}, wantErr: "db.databases.acme.dsn is not set"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newRegistry(tt.cfg, tt.dbs, conns, &fakeMigrator{}, log)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	_, err := newRegistry(Config{Tenants: []string{"acme"}, MigrationConcurrency: 1, SkipMigrations: true}, databasesConfig{}, conns, &fakeMigrator{}, log)
+	assert.NoError(t, err, "a DSN is only needed to migrate")
+}
+
+func TestRegistryMigratesTenantsIndependently(t *testing.T) {
+	m := &fakeMigrator{errs: map[string]error{"dsn-globex": errors.New("syntax error at line 3")}}
+	r := newTestRegistry(t, Config{Tenants: []string{"initech", "acme", "globex"}}, m)
+
+	for _, s := range r.Tenants() {
+		assert.Equal(t, StatePending, s.State)
+	}
+	assert.ErrorIs(t, r.Check("acme"), ErrTenantUnavailable, "tenants are unavailable until migrated")
+
+	startAndWait(t, r)
+
+	statuses := r.Tenants()
+	require.Len(t, statuses, 3)
+	assert.Equal(t, []string{"acme", "globex", "initech"}, []string{statuses[0].ID, statuses[1].ID, statuses[2].ID})
+
+	assert.Equal(t, StateReady, statuses[0].State)
+	assert.Equal(t, uint(1), statuses[0].Version)
+	assert.False(t, statuses[0].MigratedAt.IsZero())
+
+	assert.Equal(t, StateFailed, statuses[1].State)
+	assert.Equal(t, "syntax error at line 3", statuses[1].Error)
+	assert.ErrorIs(t, r.Check("globex"), ErrTenantUnavailable)
+
+	// One broken tenant does not hold up the others
+	assert.NoError(t, r.Check("acme"))
+	assert.NoError(t, r.Check("initech"))
+	assert.ErrorIs(t, r.Check("umbrella"), ErrUnknownTenant)
+}
+
+func TestRegistryMigrateRetries(t *testing.T) {
+	m := &fakeMigrator{errs: map[string]error{"dsn-acme": errors.New("connection refused")}}
+	r := newTestRegistry(t, Config{Tenants: []string{"acme"}}, m)
+	startAndWait(t, r)
+	require.ErrorIs(t, r.Check("acme"), ErrTenantUnavailable)
+
+	m.setErr("dsn-acme", nil)
+	status, err := r.Migrate("acme")
+	require.NoError(t, err)
+	assert.Equal(t, StateReady, status.State)
+	assert.Empty(t, status.Error)
+	assert.NoError(t, r.Check("acme"))
+
+	// A failed re-migration is reported, but a ready tenant keeps serving
+	m.setErr("dsn-acme", errors.New("lock timeout"))
+	status, err = r.Migrate("acme")
+	assert.ErrorContains(t, err, "failed to migrate tenant acme: lock timeout")
+	assert.Equal(t, StateReady, status.State)
+	assert.Equal(t, "lock timeout", status.Error)
+	assert.NoError(t, r.Check("acme"))
+
+	_, err = r.Migrate("umbrella")
+	assert.ErrorIs(t, err, ErrUnknownTenant)
+}
+
+func TestRegistryMigrateWhileRunning(t *testing.T) {
+	m := &fakeMigrator{gate: make(chan struct{})}
+	r := newTestRegistry(t, Config{Tenants: []string{"acme"}}, m)
+	require.NoError(t, r.start(context.Background()))
+
+	require.Eventually(t, func() bool {
+		return r.Tenants()[0].State == StateMigrating
+	}, time.Second, time.Millisecond)
+
+	_, err := r.Migrate("acme")
+	assert.ErrorIs(t, err, ErrMigrationRunning)
+
+	close(m.gate)
+	r.migrations.Wait()
+	assert.NoError(t, r.Check("acme"))
+	assert.Equal(t, 1, m.calls["dsn-acme"])
+}
+
+func TestRegistryStopCancelsMigrations(t *testing.T) {
+	m := &fakeMigrator{gate: make(chan struct{})}
+	r := newTestRegistry(t, Config{Tenants: []string{"acme", "globex", "initech"}, MigrationConcurrency: 1}, m)
+	require.NoError(t, r.start(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, r.stop(ctx))
+
+	failed := 0
+	for _, s := range r.Tenants() {
+		assert.NotEqual(t, StateReady, s.State)
+		if s.State == StateFailed {
+			failed++
+		}
+	}
+	assert.LessOrEqual(t, failed, 1, "tenants waiting for a slot are not migrated at all")
+}
+
+func TestRegistrySkipMigrations(t *testing.T) {
+	m := &fakeMigrator{}
+	r := newTestRegistry(t, Config{Tenants: []string{"acme", "globex"}, SkipMigrations: true}, m)
+	startAndWait(t, r)
+
+	assert.NoError(t, r.Check("acme"))
+	assert.NoError(t, r.Check("globex"))
+	assert.Empty(t, m.calls)
+}
+
+func TestRegistryDBIsolatesTenants(t *testing.T) {
+	r := newTestRegistry(t, Config{Tenants: []string{"acme", "globex"}}, &fakeMigrator{})
+	startAndWait(t, r)
+
+	acme, err := r.DB(WithTenant(context.Background(), "acme"))
+	require.NoError(t, err)
+	require.NoError(t, acme.Exec("CREATE TABLE notes (body TEXT)").Error)
+	require.NoError(t, acme.Exec("INSERT INTO notes VALUES ('acme only')").Error)
+
+	globex, err := r.DB(WithTenant(context.Background(), "globex"))
+	require.NoError(t, err)
+	assert.True(t, acme.Migrator().HasTable("notes"))
+	assert.False(t, globex.Migrator().HasTable("notes"), "acme's table is not in globex's database")
+
+	_, err = r.DB(context.Background())
+	assert.ErrorIs(t, err, ErrNoTenant, "there is no default tenant")
+
+	_, err = r.DB(WithTenant(context.Background(), "umbrella"))
+	assert.ErrorIs(t, err, ErrUnknownTenant)
+}
+
+func TestRegistryResolve(t *testing.T) {
+	r := newTestRegistry(t, Config{Tenants: []string{"acme"}, BaseDomain: "example.com"}, &fakeMigrator{})
+
+	tests := []struct {
+		name    string
+		host    string
+		header  string
+		want    string
+		wantErr error
+	}{
+		{name: "header", host: "api.example.com", header: "acme", want: "acme"},
+		{name: "header is lowercased", host: "localhost", header: " ACME ", want: "acme"},
+		{name: "subdomain", host: "acme.example.com", want: "acme"},
+		{name: "subdomain with port", host: "Acme.Example.com:8090", want: "acme"},
+		{name: "header wins", host: "globex.example.com", header: "acme", want: "acme"},
+		{name: "nested subdomain", host: "a.acme.example.com", wantErr: ErrNoTenant},
+		{name: "other domain", host: "acme.example.org", wantErr: ErrNoTenant},
+		{name: "base domain", host: "example.com", wantErr: ErrNoTenant},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/projects", nil)
+			req.Host = tt.host
+			if tt.header != "" {
+				req.Header.Set("X-Tenant-ID", tt.header)
+			}
+
+			id, err := r.Resolve(req)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, id)
+		})
+	}
+
+	r.cfg.BaseDomain = ""
+	req := httptest.NewRequest("GET", "/projects", nil)
+	req.Host = "acme.example.com"
+	_, err := r.Resolve(req)
+	assert.ErrorIs(t, err, ErrNoTenant, "subdomains are ignored without a base domain")
+}
+
+func TestReadinessCheck(t *testing.T) {
+	m := &fakeMigrator{errs: map[string]error{}, gate: make(chan struct{})}
+	r := newTestRegistry(t, Config{Tenants: []string{"acme", "globex"}}, m)
+	check := &readinessCheck{registry: r}
+
+	assert.ErrorContains(t, check.Check(context.Background()), "2 tenants still migrating")
+
+	m.setErr("dsn-globex", errors.New("boom"))
+	require.NoError(t, r.start(context.Background()))
+	close(m.gate)
+	r.migrations.Wait()
+	assert.NoError(t, check.Check(context.Background()), "one failed tenant does not take the instance out")
+
+	r.tenants["acme"].set(func(s *Status) { s.State = StateFailed })
+	assert.ErrorContains(t, check.Check(context.Background()), "no tenant is ready")
+}
+
+func TestValidID(t *testing.T) {
+	for _, id := range []string{"acme", "a", "acme-2", "123"} {
+		assert.True(t, ValidID(id), id)
+	}
+	for _, id := range []string{"", "Acme", "-acme", "acme-", "acme.corp", "acme_corp", string(make([]byte, 64))} {
+		assert.False(t, ValidID(id), id)
+	}
+}
@@ -0,0 +1,44 @@
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"regexp"
+)
+
+var (
+	// ErrNoTenant indicates the request or context does not name a tenant
+	ErrNoTenant = errors.New("no tenant")
+
+	// ErrUnknownTenant indicates the tenant ID is not configured
+	ErrUnknownTenant = errors.New("unknown tenant")
+
+	// ErrTenantUnavailable indicates the tenant's database is not migrated yet,
+	// or its migration failed
+	ErrTenantUnavailable = errors.New("tenant unavailable")
+
+	// ErrMigrationRunning indicates the tenant is already being migrated
+	ErrMigrationRunning = errors.New("migration already running")
+)
+
+// idPattern keeps tenant IDs usable as subdomains and as config keys, which
+// are lowercased
+var idPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// ValidID reports whether id is a well-formed tenant ID
+func ValidID(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+type contextKey struct{}
+
+// WithTenant returns a context carrying the tenant ID
+func WithTenant(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant ID carried by ctx
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(contextKey{}).(string)
+	return id, ok && id != ""
+}
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gostratum/examples/multitenant-demo/internal/domain"
+)
+
+// ProjectService handles project business logic. It is unaware of tenants;
+// isolation happens in the repository, from the tenant in ctx.
+type ProjectService struct {
+	repo ProjectRepository
+}
+
+// NewProjectService creates a new project service with repository injection
+func NewProjectService(repo ProjectRepository) *ProjectService {
+	return &ProjectService{
+		repo: repo,
+	}
+}
+
+// CreateProject creates a project for the tenant in ctx
+func (s *ProjectService) CreateProject(ctx context.Context, name, description string) (*domain.Project, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	project := domain.NewProject(name, description)
+	if err := project.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, project); err != nil {
+		if errors.Is(err, domain.ErrConflict) {
+			return nil, fmt.Errorf("%w: a project named %q already exists", ErrConflict, project.Name)
+		}
+		return nil, translateError(err)
+	}
+	return project, nil
+}
+
+// GetProject retrieves a project of the tenant in ctx
+func (s *ProjectService) GetProject(ctx context.Context, id string) (*domain.Project, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	project, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return project, nil
+}
+
+// ListProjects returns the projects of the tenant in ctx, newest first
+func (s *ProjectService) ListProjects(ctx context.Context) ([]*domain.Project, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	projects, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return projects, nil
+}
+
+// translateError converts repository/domain errors to usecase errors
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return ErrNotFound
+	case errors.Is(err, domain.ErrConflict):
+		return ErrConflict
+	default:
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+}
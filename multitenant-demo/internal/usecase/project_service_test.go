@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/multitenant-demo/internal/domain"
+)
+
+// fakeRepo keeps projects in memory, keyed by ID
+type fakeRepo struct {
+	projects map[string]*domain.Project
+	err      error
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{projects: map[string]*domain.Project{}}
+}
+
+func (r *fakeRepo) Create(ctx context.Context, project *domain.Project) error {
+	if r.err != nil {
+		return r.err
+	}
+	for _, p := range r.projects {
+		if p.Name == project.Name {
+			return domain.ErrConflict
+		}
+	}
+	r.projects[project.ID] = project
+	return nil
+}
+
+func (r *fakeRepo) FindByID(ctx context.Context, id string) (*domain.Project, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	p, ok := r.projects[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return p, nil
+}
+
+func (r *fakeRepo) List(ctx context.Context) ([]*domain.Project, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	projects := make([]*domain.Project, 0, len(r.projects))
+	for _, p := range r.projects {
+		projects = append(projects, p)
+	}
+	return projects, nil
+}
+
+func TestCreateProject(t *testing.T) {
+	repo := newFakeRepo()
+	svc := NewProjectService(repo)
+	ctx := context.Background()
+
+	project, err := svc.CreateProject(ctx, "Website", "Relaunch")
+	require.NoError(t, err)
+	assert.Equal(t, "Website", project.Name)
+	assert.Contains(t, repo.projects, project.ID)
+
+	got, err := svc.GetProject(ctx, project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, project, got)
+
+	_, err = svc.CreateProject(ctx, "Website", "")
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.ErrorContains(t, err, `a project named "Website" already exists`)
+
+	_, err = svc.CreateProject(ctx, " ", "")
+	assert.ErrorIs(t, err, ErrInvalid)
+	assert.Len(t, repo.projects, 1)
+}
+
+func TestProjectServiceTranslatesErrors(t *testing.T) {
+	repo := newFakeRepo()
+	svc := NewProjectService(repo)
+	ctx := context.Background()
+
+	_, err := svc.GetProject(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	repo.err = errors.New("tenant acme: tenant unavailable")
+	_, err = svc.ListProjects(ctx)
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.ErrorContains(t, err, "tenant acme")
+
+	_, err = svc.CreateProject(ctx, "Website", "")
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
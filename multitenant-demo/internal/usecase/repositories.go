@@ -0,0 +1,17 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/gostratum/examples/multitenant-demo/internal/domain"
+)
+
+// ProjectRepository stores projects. The tenant is not a parameter: it comes
+// with ctx, and the repository uses that tenant's database.
+// This interface is owned by the use case layer (dependency inversion principle)
+type ProjectRepository interface {
+	// Create returns domain.ErrConflict when the tenant has a project of the same name
+	Create(ctx context.Context, project *domain.Project) error
+	FindByID(ctx context.Context, id string) (*domain.Project, error)
+	List(ctx context.Context) ([]*domain.Project, error)
+}
@@ -0,0 +1,2 @@
+-- Drop projects table
+DROP TABLE IF EXISTS projects CASCADE;
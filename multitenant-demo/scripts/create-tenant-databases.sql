@@ -0,0 +1,4 @@
+-- Create one database per tenant; runs on the first start of the container
+CREATE DATABASE tenant_acme;
+CREATE DATABASE tenant_globex;
+CREATE DATABASE tenant_initech;
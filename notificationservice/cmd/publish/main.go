@@ -0,0 +1,75 @@
+// Command publish sends a sample order event to JetStream, for trying out the worker
+// before orderservice publishes events itself.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/gostratum/examples/notificationservice/internal/adapter/broker"
+	"github.com/gostratum/examples/notificationservice/internal/domain"
+)
+
+func main() {
+	url := flag.String("url", nats.DefaultURL, "NATS server URL")
+	eventType := flag.String("type", domain.EventOrderCreated, "event type")
+	orderID := flag.String("order", "", "order ID (random when empty)")
+	name := flag.String("name", "Ada Lovelace", "customer name")
+	email := flag.String("email", "ada@example.com", "customer email (empty to skip email)")
+	phone := flag.String("phone", "+15550100", "customer phone (empty to skip SMS)")
+	locale := flag.String("locale", "", "customer language, e.g. es or de (empty for English)")
+	total := flag.Float64("total", 42.50, "order total")
+	items := flag.Int("items", 2, "number of items")
+	count := flag.Int("count", 1, "number of events to publish")
+	flag.Parse()
+
+	nc, err := nats.Connect(*url)
+	if err != nil {
+		log.Fatalf("❌ Failed to connect to NATS: %v", err)
+	}
+	defer nc.Close()
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		log.Fatalf("❌ Failed to create JetStream context: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for i := 0; i < *count; i++ {
+		event := broker.OrderEventMessage{
+			ID:         uuid.New().String(),
+			Type:       *eventType,
+			OrderID:    *orderID,
+			Customer:   broker.CustomerMessage{Name: *name, Email: *email, Phone: *phone, Locale: *locale},
+			Total:      *total,
+			Currency:   "USD",
+			ItemCount:  *items,
+			OccurredAt: time.Now().UTC(),
+		}
+		if event.OrderID == "" {
+			event.OrderID = uuid.New().String()
+		}
+
+		data, err := json.Marshal(event)
+		if err != nil {
+			log.Fatalf("❌ Failed to encode event: %v", err)
+		}
+
+		// The event ID doubles as JetStream message ID, so a retried publish is deduplicated
+		ack, err := js.Publish(ctx, broker.EventSubject(event.Type), data, jetstream.WithMsgID(event.ID))
+		if err != nil {
+			log.Fatalf("❌ Failed to publish event: %v (is the worker running? it creates the stream)", err)
+		}
+		fmt.Printf("✅ Published %s for order %s (stream %s, seq %d)\n", event.Type, event.OrderID, ack.Stream, ack.Sequence)
+	}
+}
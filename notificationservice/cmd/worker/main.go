@@ -0,0 +1,48 @@
+package main
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/examples/notificationservice/internal/adapter/broker"
+	httpAdapter "github.com/gostratum/examples/notificationservice/internal/adapter/http"
+	"github.com/gostratum/examples/notificationservice/internal/adapter/memory"
+	"github.com/gostratum/examples/notificationservice/internal/adapter/sender"
+	"github.com/gostratum/examples/notificationservice/internal/adapter/templates"
+	"github.com/gostratum/examples/notificationservice/internal/usecase"
+	"github.com/gostratum/examples/redact"
+	"github.com/gostratum/httpx"
+	"github.com/gostratum/metricsx"
+)
+
+func main() {
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// Prometheus metrics for sends, event outcomes and consumer lag
+		metricsx.Module(),
+
+		// HTTP server for health probes only
+		httpx.Module(),
+
+		// Provide dependencies
+		fx.Provide(
+			// Templates, senders and the delivery log
+			templates.NewRenderer,
+			sender.NewSenders,
+			memory.NewDeliveryLog,
+
+			// Usecase services
+			usecase.NewNotificationService,
+		),
+
+		// Invoke setup functions
+		fx.Invoke(
+			broker.RegisterConsumer,
+			httpAdapter.RegisterRoutes,
+		),
+	)
+
+	app.Run()
+}
@@ -0,0 +1,42 @@
+// Package broker consumes order events from NATS JetStream and drives retries,
+// dead-lettering and consumer lag metrics
+package broker
+
+import (
+	"time"
+)
+
+// Config holds the JetStream connection, stream and retry settings
+type Config struct {
+	URL string `mapstructure:"url" default:"nats://localhost:4222"`
+
+	// Stream holds the order events; Subject filters the events this worker consumes
+	Stream  string `mapstructure:"stream" default:"ORDERS"`
+	Subject string `mapstructure:"subject" default:"orders.events.>"`
+	// Consumer is the durable consumer name; workers sharing it split the events
+	Consumer string `mapstructure:"consumer" default:"notificationservice"`
+
+	// DeadLetterStream stores events that failed permanently or ran out of attempts.
+	// They are published to <DeadLetterSubject>.<original subject>.
+	DeadLetterStream  string `mapstructure:"dead_letter_stream" default:"NOTIFICATIONS_DLQ"`
+	DeadLetterSubject string `mapstructure:"dead_letter_subject" default:"notifications.dlq"`
+
+	// MaxAttempts is how often an event is delivered before it is dead-lettered
+	MaxAttempts int           `mapstructure:"max_attempts" default:"5"`
+	BackoffBase time.Duration `mapstructure:"backoff_base" default:"1s"`
+	BackoffMax  time.Duration `mapstructure:"backoff_max" default:"1m"`
+
+	// HandleTimeout bounds one delivery; AckWait must be longer or JetStream
+	// redelivers events that are still being handled
+	HandleTimeout time.Duration `mapstructure:"handle_timeout" default:"15s"`
+	AckWait       time.Duration `mapstructure:"ack_wait" default:"30s"`
+	MaxAckPending int           `mapstructure:"max_ack_pending" default:"64"`
+
+	// LagInterval is how often consumer lag is sampled for metrics
+	LagInterval time.Duration `mapstructure:"lag_interval" default:"10s"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "broker"
+}
@@ -0,0 +1,288 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/metricsx"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.uber.org/fx"
+
+	"github.com/gostratum/examples/notificationservice/internal/usecase"
+)
+
+// Headers added to dead-lettered events
+const (
+	HeaderError           = "Notification-Error"
+	HeaderAttempts        = "Notification-Attempts"
+	HeaderOriginalSubject = "Notification-Original-Subject"
+)
+
+// Consumer feeds order events from a durable JetStream consumer into the
+// NotificationService and acks, retries or dead-letters each one
+type Consumer struct {
+	cfg     Config
+	service *usecase.NotificationService
+	policy  usecase.RetryPolicy
+	metrics *consumerMetrics
+	log     logx.Logger
+
+	conn       atomic.Pointer[nats.Conn]
+	js         jetstream.JetStream
+	consumer   jetstream.Consumer
+	consumeCtx jetstream.ConsumeContext
+	stopLag    context.CancelFunc
+	lagDone    chan struct{}
+}
+
+// RegisterConsumer creates the consumer and ties it to the application lifecycle.
+// This function is designed to be used with fx.Invoke.
+func RegisterConsumer(
+	lc fx.Lifecycle,
+	loader configx.Loader,
+	service *usecase.NotificationService,
+	metrics metricsx.Metrics,
+	reg core.Registry,
+	log logx.Logger,
+) error {
+	var cfg Config
+	if err := loader.Bind(&cfg); err != nil {
+		return fmt.Errorf("failed to load broker config: %w", err)
+	}
+	if cfg.AckWait <= cfg.HandleTimeout {
+		return fmt.Errorf("broker.ack_wait (%s) must be longer than broker.handle_timeout (%s)", cfg.AckWait, cfg.HandleTimeout)
+	}
+
+	c := &Consumer{
+		cfg:     cfg,
+		service: service,
+		policy: usecase.RetryPolicy{
+			MaxAttempts: cfg.MaxAttempts,
+			BaseDelay:   cfg.BackoffBase,
+			MaxDelay:    cfg.BackoffMax,
+		},
+		metrics: newConsumerMetrics(metrics),
+		log:     log,
+	}
+
+	reg.Register(&connectionCheck{consumer: c})
+	lc.Append(fx.Hook{
+		OnStart: c.start,
+		OnStop:  c.stop,
+	})
+	return nil
+}
+
+// start connects to NATS, makes sure the streams and the durable consumer exist
+// and begins consuming
+func (c *Consumer) start(ctx context.Context) error {
+	nc, err := nats.Connect(c.cfg.URL,
+		nats.Name(c.cfg.Consumer),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			c.log.Warn("disconnected from NATS", logx.Err(err))
+		}),
+		nats.ReconnectHandler(func(nc *nats.Conn) {
+			c.log.Info("reconnected to NATS", logx.String("url", nc.ConnectedUrl()))
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NATS at %s: %w", c.cfg.URL, err)
+	}
+	c.conn.Store(nc)
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		nc.Close()
+		return fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+	c.js = js
+
+	// The streams are declared here so the example runs against an empty NATS server.
+	// In production they are usually owned by the platform or the publishing service.
+	streams := []jetstream.StreamConfig{
+		{Name: c.cfg.Stream, Subjects: []string{c.cfg.Subject}},
+		{Name: c.cfg.DeadLetterStream, Subjects: []string{c.cfg.DeadLetterSubject + ".>"}},
+	}
+	for _, stream := range streams {
+		if _, err := js.CreateOrUpdateStream(ctx, stream); err != nil {
+			nc.Close()
+			return fmt.Errorf("failed to create stream %s: %w", stream.Name, err)
+		}
+	}
+
+	consumer, err := js.CreateOrUpdateConsumer(ctx, c.cfg.Stream, jetstream.ConsumerConfig{
+		Durable:       c.cfg.Consumer,
+		FilterSubject: c.cfg.Subject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       c.cfg.AckWait,
+		MaxAckPending: c.cfg.MaxAckPending,
+		// Attempts are counted by the retry policy, which dead-letters the last one.
+		// An unlimited MaxDeliver keeps an event if publishing it to the DLQ fails.
+		MaxDeliver: -1,
+	})
+	if err != nil {
+		nc.Close()
+		return fmt.Errorf("failed to create consumer %s: %w", c.cfg.Consumer, err)
+	}
+	c.consumer = consumer
+
+	c.consumeCtx, err = consumer.Consume(c.handle, jetstream.ConsumeErrHandler(func(_ jetstream.ConsumeContext, err error) {
+		c.log.Warn("JetStream consume error", logx.Err(err))
+	}))
+	if err != nil {
+		nc.Close()
+		return fmt.Errorf("failed to start consuming: %w", err)
+	}
+
+	lagCtx, cancel := context.WithCancel(context.Background())
+	c.stopLag = cancel
+	c.lagDone = make(chan struct{})
+	go c.reportLag(lagCtx)
+
+	c.log.Info("consuming order events",
+		logx.String("stream", c.cfg.Stream),
+		logx.String("subject", c.cfg.Subject),
+		logx.String("consumer", c.cfg.Consumer),
+	)
+	return nil
+}
+
+// stop finishes the event in flight, then closes the connection
+func (c *Consumer) stop(ctx context.Context) error {
+	c.stopLag()
+	<-c.lagDone
+
+	c.consumeCtx.Drain()
+	select {
+	case <-c.consumeCtx.Closed():
+	case <-ctx.Done():
+		c.log.Warn("stopped before the event in flight was handled; it will be redelivered")
+	}
+
+	return c.conn.Load().Drain()
+}
+
+// handle processes one delivery and settles it according to the retry policy
+func (c *Consumer) handle(msg jetstream.Msg) {
+	start := time.Now()
+
+	attempt := 1
+	meta, metaErr := msg.Metadata()
+	if metaErr == nil {
+		attempt = int(meta.NumDelivered)
+	}
+
+	eventType := "unknown"
+	event, err := decodeEvent(msg.Data())
+	if err == nil {
+		eventType = event.Type
+
+		ctx, cancel := context.WithTimeout(context.Background(), c.cfg.HandleTimeout)
+		err = c.service.Handle(ctx, event)
+		cancel()
+	}
+
+	decision := c.policy.Decide(err, attempt)
+	fields := []logx.Field{
+		logx.String("subject", msg.Subject()),
+		logx.String("type", eventType),
+		logx.Int("attempt", attempt),
+	}
+
+	var settleErr error
+	switch decision.Action {
+	case usecase.Ack:
+		settleErr = msg.Ack()
+	case usecase.Retry:
+		c.log.Warn("event failed, retrying", append(fields, logx.Err(err), logx.String("delay", decision.Delay.String()))...)
+		settleErr = msg.NakWithDelay(decision.Delay)
+	case usecase.DeadLetter:
+		c.log.Error("event dead-lettered", append(fields, logx.Err(err))...)
+		settleErr = c.deadLetter(msg, meta, err, attempt)
+	}
+	if settleErr != nil {
+		c.log.Error("failed to settle event", append(fields, logx.Err(settleErr))...)
+	}
+
+	c.metrics.events.Inc(eventType, decision.Action.String())
+	c.metrics.duration.Observe(time.Since(start).Seconds(), eventType)
+}
+
+// deadLetter copies the event to the dead letter stream and terminates it. If the
+// copy fails, the event is retried later so it is never lost.
+func (c *Consumer) deadLetter(msg jetstream.Msg, meta *jetstream.MsgMetadata, cause error, attempt int) error {
+	dlq := nats.NewMsg(c.cfg.DeadLetterSubject + "." + msg.Subject())
+	dlq.Data = msg.Data()
+	dlq.Header.Set(HeaderError, cause.Error())
+	dlq.Header.Set(HeaderAttempts, strconv.Itoa(attempt))
+	dlq.Header.Set(HeaderOriginalSubject, msg.Subject())
+	if meta != nil {
+		// Deduplicates the copy if terminating the original fails after publishing
+		dlq.Header.Set(nats.MsgIdHdr, fmt.Sprintf("%s-%d", meta.Stream, meta.Sequence.Stream))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.js.PublishMsg(ctx, dlq); err != nil {
+		return errors.Join(fmt.Errorf("failed to publish to dead letter queue: %w", err), msg.NakWithDelay(c.policy.MaxDelay))
+	}
+	return msg.Term()
+}
+
+// reportLag samples the consumer state every LagInterval
+func (c *Consumer) reportLag(ctx context.Context) {
+	defer close(c.lagDone)
+
+	ticker := time.NewTicker(c.cfg.LagInterval)
+	defer ticker.Stop()
+
+	for {
+		info, err := c.consumer.Info(ctx)
+		if err == nil {
+			c.metrics.pending.Set(float64(info.NumPending), c.cfg.Consumer)
+			c.metrics.ackPending.Set(float64(info.NumAckPending), c.cfg.Consumer)
+			c.metrics.redelivered.Set(float64(info.NumRedelivered), c.cfg.Consumer)
+		} else if ctx.Err() == nil {
+			c.log.Warn("failed to read consumer info", logx.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// connectionCheck reports not ready while the NATS connection is down
+type connectionCheck struct {
+	consumer *Consumer
+}
+
+func (c *connectionCheck) Name() string {
+	return "nats"
+}
+
+func (c *connectionCheck) Kind() core.Kind {
+	return core.Readiness
+}
+
+func (c *connectionCheck) Check(ctx context.Context) error {
+	nc := c.consumer.conn.Load()
+	if nc == nil {
+		return errors.New("not connected yet")
+	}
+	if status := nc.Status(); status != nats.CONNECTED {
+		return fmt.Errorf("connection is %s", status)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package broker
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gostratum/examples/notificationservice/internal/domain"
+	"github.com/gostratum/examples/notificationservice/internal/usecase"
+)
+
+// EventSubjectPrefix is prepended to the event type to form the subject an event is
+// published on, e.g. orders.events.order.created
+const EventSubjectPrefix = "orders.events"
+
+// EventSubject returns the subject an event of eventType is published on
+func EventSubject(eventType string) string {
+	return EventSubjectPrefix + "." + eventType
+}
+
+// OrderEventMessage is the JSON contract of order events on the broker.
+// It is exported so publishers (cmd/publish, orderservice) share one definition.
+type OrderEventMessage struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	OrderID    string          `json:"order_id"`
+	Customer   CustomerMessage `json:"customer"`
+	Total      float64         `json:"total"`
+	Currency   string          `json:"currency"`
+	ItemCount  int             `json:"item_count"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// CustomerMessage is the customer part of OrderEventMessage
+type CustomerMessage struct {
+	Name   string `json:"name,omitempty"`
+	Email  string `json:"email,omitempty"`
+	Phone  string `json:"phone,omitempty"`
+	Locale string `json:"locale,omitempty"`
+}
+
+// ToDomain converts the message to a domain.OrderEvent
+func (m *OrderEventMessage) ToDomain() *domain.OrderEvent {
+	return &domain.OrderEvent{
+		ID:      m.ID,
+		Type:    m.Type,
+		OrderID: m.OrderID,
+		Customer: domain.Customer{
+			Name:   m.Customer.Name,
+			Email:  m.Customer.Email,
+			Phone:  m.Customer.Phone,
+			Locale: m.Customer.Locale,
+		},
+		Total:      m.Total,
+		Currency:   m.Currency,
+		ItemCount:  m.ItemCount,
+		OccurredAt: m.OccurredAt,
+	}
+}
+
+// decodeEvent parses a message body; malformed JSON can never succeed and is
+// reported as usecase.ErrInvalid so it goes straight to the dead letter queue
+func decodeEvent(data []byte) (*domain.OrderEvent, error) {
+	var m OrderEventMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%w: malformed event: %v", usecase.ErrInvalid, err)
+	}
+	return m.ToDomain(), nil
+}
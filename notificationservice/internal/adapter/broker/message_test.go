@@ -0,0 +1,47 @@
+package broker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/notificationservice/internal/domain"
+	"github.com/gostratum/examples/notificationservice/internal/usecase"
+)
+
+func TestDecodeEvent(t *testing.T) {
+	event, err := decodeEvent([]byte(`{
+		"id": "evt1",
+		"type": "order.created",
+		"order_id": "order1",
+		"customer": {"name": "Ada", "email": "ada@example.com", "locale": "es-MX"},
+		"total": 42.5,
+		"currency": "USD",
+		"item_count": 3,
+		"occurred_at": "2025-01-02T15:04:00Z"
+	}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, &domain.OrderEvent{
+		ID:         "evt1",
+		Type:       domain.EventOrderCreated,
+		OrderID:    "order1",
+		Customer:   domain.Customer{Name: "Ada", Email: "ada@example.com", Locale: "es-MX"},
+		Total:      42.5,
+		Currency:   "USD",
+		ItemCount:  3,
+		OccurredAt: time.Date(2025, 1, 2, 15, 4, 0, 0, time.UTC),
+	}, event)
+}
+
+func TestDecodeEventMalformed(t *testing.T) {
+	_, err := decodeEvent([]byte(`{"id": `))
+	assert.ErrorIs(t, err, usecase.ErrInvalid)
+	assert.True(t, usecase.IsPermanent(err))
+}
+
+func TestEventSubject(t *testing.T) {
+	assert.Equal(t, "orders.events.order.created", EventSubject(domain.EventOrderCreated))
+}
@@ -0,0 +1,40 @@
+package broker
+
+import (
+	"github.com/gostratum/metricsx"
+)
+
+// consumerMetrics covers event outcomes and how far the consumer is behind the stream
+type consumerMetrics struct {
+	events      metricsx.Counter
+	duration    metricsx.Histogram
+	pending     metricsx.Gauge
+	ackPending  metricsx.Gauge
+	redelivered metricsx.Gauge
+}
+
+func newConsumerMetrics(metrics metricsx.Metrics) *consumerMetrics {
+	return &consumerMetrics{
+		events: metrics.Counter("notification_events_total",
+			metricsx.WithHelp("Order events handled, by type and outcome (ack, retry, dead_letter)"),
+			metricsx.WithLabels("type", "outcome"),
+		),
+		duration: metrics.Histogram("notification_event_duration_seconds",
+			metricsx.WithHelp("Time taken to handle one delivery of an order event"),
+			metricsx.WithLabels("type"),
+			metricsx.WithBuckets(0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 15),
+		),
+		pending: metrics.Gauge("notification_consumer_pending_messages",
+			metricsx.WithHelp("Events in the stream not yet delivered to the consumer (consumer lag)"),
+			metricsx.WithLabels("consumer"),
+		),
+		ackPending: metrics.Gauge("notification_consumer_ack_pending_messages",
+			metricsx.WithHelp("Events delivered but not yet acknowledged, including those waiting for a retry"),
+			metricsx.WithLabels("consumer"),
+		),
+		redelivered: metrics.Gauge("notification_consumer_redelivered_messages",
+			metricsx.WithHelp("Events currently being redelivered"),
+			metricsx.WithLabels("consumer"),
+		),
+	}
+}
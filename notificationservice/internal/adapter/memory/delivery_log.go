@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gostratum/examples/notificationservice/internal/domain"
+	"github.com/gostratum/examples/notificationservice/internal/usecase"
+)
+
+// deliveryRetention is how long a delivery is remembered. It only has to outlast
+// the redeliveries of an event, which end after the retry policy's last attempt.
+const deliveryRetention = 24 * time.Hour
+
+// DeliveryLog remembers sent notifications in memory. It deduplicates redeliveries
+// within one process; a restarted worker may send a notification again, which is
+// the usual at-least-once trade-off for notifications.
+type DeliveryLog struct {
+	mu         sync.Mutex
+	delivered  map[string]time.Time
+	lastPruned time.Time
+	now        func() time.Time
+}
+
+// NewDeliveryLog creates an empty in-memory delivery log
+func NewDeliveryLog() usecase.DeliveryLog {
+	return &DeliveryLog{delivered: make(map[string]time.Time), now: time.Now}
+}
+
+// Delivered implements usecase.DeliveryLog
+func (l *DeliveryLog) Delivered(ctx context.Context, eventID string, channel domain.Channel) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	at, ok := l.delivered[key(eventID, channel)]
+	return ok && l.now().Sub(at) < deliveryRetention, nil
+}
+
+// MarkDelivered implements usecase.DeliveryLog. Expired entries are dropped at most
+// once a minute so the log stays bounded without scanning it on every send.
+func (l *DeliveryLog) MarkDelivered(ctx context.Context, eventID string, channel domain.Channel) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastPruned) >= time.Minute {
+		for k, at := range l.delivered {
+			if now.Sub(at) >= deliveryRetention {
+				delete(l.delivered, k)
+			}
+		}
+		l.lastPruned = now
+	}
+	l.delivered[key(eventID, channel)] = now
+	return nil
+}
+
+func key(eventID string, channel domain.Channel) string {
+	return eventID + "/" + string(channel)
+}
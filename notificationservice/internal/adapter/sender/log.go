@@ -0,0 +1,37 @@
+package sender
+
+import (
+	"context"
+
+	"github.com/gostratum/core/logx"
+
+	"github.com/gostratum/examples/notificationservice/internal/domain"
+)
+
+// LogSender "sends" messages by logging them. It is the default for SMS so the
+// example runs without a provider account.
+type LogSender struct {
+	channel domain.Channel
+	log     logx.Logger
+}
+
+// NewLogSender creates a sender that logs messages for channel
+func NewLogSender(channel domain.Channel, log logx.Logger) *LogSender {
+	return &LogSender{channel: channel, log: log}
+}
+
+// Channel implements usecase.Sender
+func (s *LogSender) Channel() domain.Channel {
+	return s.channel
+}
+
+// Send implements usecase.Sender
+func (s *LogSender) Send(ctx context.Context, msg *domain.Message) error {
+	s.log.Info("notification sent",
+		logx.String("channel", string(msg.Channel)),
+		logx.String("to", msg.To),
+		logx.String("subject", msg.Subject),
+		logx.String("body", msg.Body),
+	)
+	return nil
+}
@@ -0,0 +1,60 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gostratum/metricsx"
+
+	"github.com/gostratum/examples/notificationservice/internal/domain"
+	"github.com/gostratum/examples/notificationservice/internal/usecase"
+)
+
+// senderMetrics records the outcome and latency of every send
+type senderMetrics struct {
+	sent     metricsx.Counter
+	duration metricsx.Histogram
+}
+
+func newSenderMetrics(metrics metricsx.Metrics) *senderMetrics {
+	return &senderMetrics{
+		sent: metrics.Counter("notifications_sent_total",
+			metricsx.WithHelp("Notifications handed to a sender, by channel and result (sent, failed, undeliverable)"),
+			metricsx.WithLabels("channel", "result"),
+		),
+		duration: metrics.Histogram("notification_send_duration_seconds",
+			metricsx.WithHelp("Time taken by a sender to deliver a notification"),
+			metricsx.WithLabels("channel"),
+			metricsx.WithBuckets(0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10),
+		),
+	}
+}
+
+// instrumentedSender wraps a Sender with senderMetrics
+type instrumentedSender struct {
+	usecase.Sender
+	metrics *senderMetrics
+}
+
+func instrument(s usecase.Sender, m *senderMetrics) usecase.Sender {
+	return &instrumentedSender{Sender: s, metrics: m}
+}
+
+// Send implements usecase.Sender
+func (s *instrumentedSender) Send(ctx context.Context, msg *domain.Message) error {
+	channel := string(s.Channel())
+	start := time.Now()
+	err := s.Sender.Send(ctx, msg)
+	s.metrics.duration.Observe(time.Since(start).Seconds(), channel)
+
+	switch {
+	case err == nil:
+		s.metrics.sent.Inc(channel, "sent")
+	case errors.Is(err, domain.ErrUndeliverable):
+		s.metrics.sent.Inc(channel, "undeliverable")
+	default:
+		s.metrics.sent.Inc(channel, "failed")
+	}
+	return err
+}
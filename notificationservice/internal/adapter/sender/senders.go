@@ -0,0 +1,101 @@
+// Package sender implements usecase.Sender for email and SMS. Each channel picks its
+// provider from config, so a deployment can swap SMTP or the SMS webhook for the log
+// sender (or switch a channel off) without code changes.
+package sender
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/metricsx"
+
+	"github.com/gostratum/examples/notificationservice/internal/domain"
+	"github.com/gostratum/examples/notificationservice/internal/usecase"
+)
+
+// Providers accepted by the email.provider and sms.provider settings
+const (
+	ProviderSMTP     = "smtp"
+	ProviderWebhook  = "webhook"
+	ProviderLog      = "log"
+	ProviderDisabled = "disabled"
+)
+
+// EmailConfig selects and configures the email sender
+type EmailConfig struct {
+	// Provider is smtp, log or disabled
+	Provider string `mapstructure:"provider" default:"smtp"`
+	Host     string `mapstructure:"host" default:"localhost"`
+	Port     int    `mapstructure:"port" default:"1025"`
+	From     string `mapstructure:"from" default:"orders@example.com"`
+	// Username and Password enable SMTP AUTH PLAIN when set
+	Username string        `mapstructure:"username"`
+	Password string        `mapstructure:"password"`
+	Timeout  time.Duration `mapstructure:"timeout" default:"10s"`
+}
+
+// Prefix implements configx.Configurable
+func (EmailConfig) Prefix() string {
+	return "email"
+}
+
+// SMSConfig selects and configures the SMS sender
+type SMSConfig struct {
+	// Provider is webhook, log or disabled
+	Provider string `mapstructure:"provider" default:"log"`
+	// WebhookURL receives {"to": "...", "body": "..."} as JSON
+	WebhookURL string `mapstructure:"webhook_url"`
+	// Token is sent as a bearer token to the webhook when set
+	Token   string        `mapstructure:"token"`
+	Timeout time.Duration `mapstructure:"timeout" default:"5s"`
+}
+
+// Prefix implements configx.Configurable
+func (SMSConfig) Prefix() string {
+	return "sms"
+}
+
+// NewSenders builds the configured senders, each instrumented with delivery metrics
+func NewSenders(loader configx.Loader, log logx.Logger, metrics metricsx.Metrics) ([]usecase.Sender, error) {
+	var emailCfg EmailConfig
+	if err := loader.Bind(&emailCfg); err != nil {
+		return nil, fmt.Errorf("failed to load email config: %w", err)
+	}
+	var smsCfg SMSConfig
+	if err := loader.Bind(&smsCfg); err != nil {
+		return nil, fmt.Errorf("failed to load sms config: %w", err)
+	}
+
+	var senders []usecase.Sender
+
+	switch emailCfg.Provider {
+	case ProviderSMTP:
+		senders = append(senders, NewSMTPSender(emailCfg))
+	case ProviderLog:
+		senders = append(senders, NewLogSender(domain.ChannelEmail, log))
+	case ProviderDisabled:
+	default:
+		return nil, fmt.Errorf("unknown email.provider %q", emailCfg.Provider)
+	}
+
+	switch smsCfg.Provider {
+	case ProviderWebhook:
+		if smsCfg.WebhookURL == "" {
+			return nil, fmt.Errorf("sms.webhook_url is required for the webhook provider")
+		}
+		senders = append(senders, NewWebhookSender(smsCfg))
+	case ProviderLog:
+		senders = append(senders, NewLogSender(domain.ChannelSMS, log))
+	case ProviderDisabled:
+	default:
+		return nil, fmt.Errorf("unknown sms.provider %q", smsCfg.Provider)
+	}
+
+	m := newSenderMetrics(metrics)
+	for i, s := range senders {
+		senders[i] = instrument(s, m)
+	}
+	return senders, nil
+}
@@ -0,0 +1,112 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gostratum/examples/notificationservice/internal/domain"
+)
+
+// SMTPSender delivers email through an SMTP server (Mailpit in development)
+type SMTPSender struct {
+	cfg EmailConfig
+}
+
+// NewSMTPSender creates an SMTP email sender
+func NewSMTPSender(cfg EmailConfig) *SMTPSender {
+	return &SMTPSender{cfg: cfg}
+}
+
+// Channel implements usecase.Sender
+func (s *SMTPSender) Channel() domain.Channel {
+	return domain.ChannelEmail
+}
+
+// Send implements usecase.Sender. Permanent SMTP replies (5xx), such as an unknown
+// mailbox, are reported as domain.ErrUndeliverable.
+func (s *SMTPSender) Send(ctx context.Context, msg *domain.Message) error {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return classify(err)
+	}
+	defer client.Close()
+
+	if err := s.deliver(client, msg); err != nil {
+		return classify(err)
+	}
+	return client.Quit()
+}
+
+// deliver runs the SMTP transaction for one message
+func (s *SMTPSender) deliver(client *smtp.Client, msg *domain.Message) error {
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(nil); err != nil {
+			return err
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(s.cfg.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(buildMessage(s.cfg.From, msg)); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// buildMessage renders a plain text RFC 5322 message
+func buildMessage(from string, msg *domain.Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
+
+// classify marks permanent SMTP replies as undeliverable
+func classify(err error) error {
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return fmt.Errorf("%w: %v", domain.ErrUndeliverable, err)
+	}
+	return err
+}
@@ -0,0 +1,30 @@
+package sender
+
+import (
+	"errors"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gostratum/examples/notificationservice/internal/domain"
+)
+
+func TestClassify(t *testing.T) {
+	assert.ErrorIs(t, classify(&textproto.Error{Code: 550, Msg: "mailbox unavailable"}), domain.ErrUndeliverable)
+	assert.NotErrorIs(t, classify(&textproto.Error{Code: 451, Msg: "try again later"}), domain.ErrUndeliverable)
+	assert.NotErrorIs(t, classify(errors.New("connection reset")), domain.ErrUndeliverable)
+}
+
+func TestBuildMessage(t *testing.T) {
+	raw := string(buildMessage("orders@example.com", &domain.Message{
+		To:      "ada@example.com",
+		Subject: "Your order is confirmed ✓",
+		Body:    "line one\nline two",
+	}))
+
+	assert.Contains(t, raw, "To: ada@example.com\r\n")
+	assert.Contains(t, raw, "Subject: =?utf-8?q?")
+	assert.True(t, strings.HasSuffix(raw, "\r\n\r\nline one\r\nline two\r\n"))
+}
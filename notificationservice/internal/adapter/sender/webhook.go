@@ -0,0 +1,67 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gostratum/examples/notificationservice/internal/domain"
+)
+
+// WebhookSender delivers SMS by posting them to an HTTP endpoint, which is how most
+// SMS providers (or a small relay in front of them) accept messages
+type WebhookSender struct {
+	url   string
+	token string
+	http  *http.Client
+}
+
+// NewWebhookSender creates an SMS sender posting to cfg.WebhookURL
+func NewWebhookSender(cfg SMSConfig) *WebhookSender {
+	return &WebhookSender{url: cfg.WebhookURL, token: cfg.Token, http: &http.Client{Timeout: cfg.Timeout}}
+}
+
+type webhookMessage struct {
+	To   string `json:"to"`
+	Body string `json:"body"`
+}
+
+// Channel implements usecase.Sender
+func (s *WebhookSender) Channel() domain.Channel {
+	return domain.ChannelSMS
+}
+
+// Send implements usecase.Sender. A 4xx answer other than 408 and 429 means the
+// provider rejected the message and is reported as domain.ErrUndeliverable.
+func (s *WebhookSender) Send(ctx context.Context, msg *domain.Message) error {
+	payload, err := json.Marshal(webhookMessage{To: msg.To, Body: msg.Body})
+	if err != nil {
+		return fmt.Errorf("failed to encode sms: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create sms request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return fmt.Errorf("sms webhook returned status %d", resp.StatusCode)
+	default:
+		return fmt.Errorf("%w: sms webhook returned status %d", domain.ErrUndeliverable, resp.StatusCode)
+	}
+}
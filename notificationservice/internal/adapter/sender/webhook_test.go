@@ -0,0 +1,59 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/notificationservice/internal/domain"
+)
+
+func TestWebhookSender(t *testing.T) {
+	msg := &domain.Message{Channel: domain.ChannelSMS, To: "+15550100", Body: "Order order1 confirmed"}
+
+	t.Run("posts the message with the token", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+			var body webhookMessage
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, webhookMessage{To: "+15550100", Body: "Order order1 confirmed"}, body)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer srv.Close()
+
+		sender := NewWebhookSender(SMSConfig{WebhookURL: srv.URL, Token: "secret", Timeout: time.Second})
+		assert.NoError(t, sender.Send(context.Background(), msg))
+	})
+
+	tests := []struct {
+		name            string
+		status          int
+		wantUndelivered bool
+	}{
+		{name: "rejected number is undeliverable", status: http.StatusBadRequest, wantUndelivered: true},
+		{name: "rate limit is transient", status: http.StatusTooManyRequests},
+		{name: "server error is transient", status: http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			sender := NewWebhookSender(SMSConfig{WebhookURL: srv.URL, Timeout: time.Second})
+			err := sender.Send(context.Background(), msg)
+			require.Error(t, err)
+			assert.Equal(t, tt.wantUndelivered, errors.Is(err, domain.ErrUndeliverable))
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Package templates renders notifications from text templates embedded in the
+// binary, in the language of the customer
+package templates
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/gostratum/examples/i18n"
+	"github.com/gostratum/examples/notificationservice/internal/domain"
+	"github.com/gostratum/examples/notificationservice/internal/usecase"
+)
+
+// files holds one template per event type and channel, named <event type>.<channel>.tmpl.
+// Each defines a "body" template; email templates also define "subject".
+// Templates hold no text of their own: they render a view, whose T looks it up
+// in Messages.
+//
+//go:embed files/*.tmpl
+var files embed.FS
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// Messages holds the text of the templates, keyed <event type>.<part>, in
+// each language notifications are sent in. English is the fallback, and has
+// every key.
+var Messages = i18n.MustNew(locales, "locales", "en")
+
+// view is what templates render: the event, with the language of its
+// customer for T, Money and Date
+type view struct {
+	*domain.OrderEvent
+	lang string
+}
+
+// T returns the message of key, with args, pairs of placeholder names and
+// values, in place of its placeholders:
+// {{.T "order.created.subject" "order" .OrderID}}
+func (v view) T(key string, args ...any) string {
+	values := make([]string, len(args))
+	for i, arg := range args {
+		values[i] = fmt.Sprint(arg)
+	}
+	return Messages.Message(v.lang, key, values...)
+}
+
+// Money formats an amount with its currency in the customer's notation, e.g.
+// "1,042.50 USD" in English and "1.042,50 USD" in German
+func (v view) Money(amount float64, currency string) string {
+	p := message.NewPrinter(language.Make(v.lang))
+	return strings.TrimSpace(p.Sprintf("%.2f %s", amount, currency))
+}
+
+// Date formats t with the layout of the customer's language, date_format
+func (v view) Date(t time.Time) string {
+	return t.Format(Messages.Message(v.lang, "date_format"))
+}
+
+// Renderer implements usecase.Renderer with the embedded templates
+type Renderer struct {
+	templates map[string]*template.Template
+}
+
+// NewRenderer parses all embedded templates. A broken template fails startup
+// instead of the first matching event.
+func NewRenderer() (usecase.Renderer, error) {
+	return newRenderer(files, "files")
+}
+
+func newRenderer(fsys fs.FS, dir string) (*Renderer, error) {
+	names, err := fs.Glob(fsys, path.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Renderer{templates: make(map[string]*template.Template, len(names))}
+	for _, name := range names {
+		tmpl, err := template.New(path.Base(name)).Option("missingkey=error").ParseFS(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+		}
+		if tmpl.Lookup("body") == nil {
+			return nil, fmt.Errorf("template %s does not define \"body\"", name)
+		}
+		r.templates[strings.TrimSuffix(path.Base(name), ".tmpl")] = tmpl
+	}
+	return r, nil
+}
+
+// Render implements usecase.Renderer. The notification is in the language of
+// the catalogs closest to event.Customer.Locale, or English.
+func (r *Renderer) Render(channel domain.Channel, event *domain.OrderEvent) (*domain.Message, error) {
+	tmpl, ok := r.templates[event.Type+"."+string(channel)]
+	if !ok {
+		return nil, fmt.Errorf("%w for %s on %s", domain.ErrNoTemplate, event.Type, channel)
+	}
+
+	msg := &domain.Message{Channel: channel, To: event.Recipient(channel)}
+	data := view{OrderEvent: event, lang: Messages.Match(event.Customer.Locale)}
+
+	var err error
+	if msg.Body, err = execute(tmpl, "body", data); err != nil {
+		return nil, err
+	}
+	if tmpl.Lookup("subject") != nil {
+		if msg.Subject, err = execute(tmpl, "subject", data); err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
+}
+
+func execute(tmpl *template.Template, name string, data view) (string, error) {
+	var b strings.Builder
+	if err := tmpl.ExecuteTemplate(&b, name, data); err != nil {
+		return "", fmt.Errorf("failed to render %s/%s: %w", tmpl.Name(), name, err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
@@ -0,0 +1,101 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/notificationservice/internal/domain"
+)
+
+func orderCreated() *domain.OrderEvent {
+	return &domain.OrderEvent{
+		ID:         "evt1",
+		Type:       domain.EventOrderCreated,
+		OrderID:    "order1",
+		Customer:   domain.Customer{Name: "Ada", Email: "ada@example.com", Phone: "+15550100"},
+		Total:      42.5,
+		Currency:   "USD",
+		ItemCount:  3,
+		OccurredAt: time.Date(2025, 1, 2, 15, 4, 0, 0, time.UTC),
+	}
+}
+
+func TestRenderEmbeddedTemplates(t *testing.T) {
+	renderer, err := NewRenderer()
+	require.NoError(t, err)
+
+	email, err := renderer.Render(domain.ChannelEmail, orderCreated())
+	require.NoError(t, err)
+	assert.Equal(t, "ada@example.com", email.To)
+	assert.Equal(t, "Your order order1 is confirmed", email.Subject)
+	assert.Contains(t, email.Body, "Hi Ada,")
+	assert.Contains(t, email.Body, "3 item(s) totalling 42.50 USD")
+
+	sms, err := renderer.Render(domain.ChannelSMS, orderCreated())
+	require.NoError(t, err)
+	assert.Equal(t, "+15550100", sms.To)
+	assert.Empty(t, sms.Subject)
+	assert.Equal(t, "Order order1 confirmed: 3 item(s), 42.50 USD. Thanks for shopping with us!", sms.Body)
+}
+
+func TestRenderInTheCustomersLanguage(t *testing.T) {
+	renderer, err := NewRenderer()
+	require.NoError(t, err)
+
+	event := orderCreated()
+	event.Customer.Locale = "es-MX"
+	event.Total = 1042.5
+	email, err := renderer.Render(domain.ChannelEmail, event)
+	require.NoError(t, err)
+	assert.Equal(t, "Tu pedido order1 está confirmado", email.Subject)
+	assert.Contains(t, email.Body, "Hola, Ada:")
+	assert.Contains(t, email.Body, "3 artículo(s) por un total de 1.042,50 USD")
+	assert.Contains(t, email.Body, "Realizado: 02/01/2025 15:04 UTC")
+
+	event.Customer.Locale = "de"
+	event.Customer.Name = ""
+	email, err = renderer.Render(domain.ChannelEmail, event)
+	require.NoError(t, err)
+	assert.Equal(t, "Deine Bestellung order1 ist bestätigt", email.Subject)
+	assert.True(t, strings.HasPrefix(email.Body, "Hallo,\n"), email.Body)
+	assert.Contains(t, email.Body, "Aufgegeben: 02.01.2025 15:04 UTC")
+
+	sms, err := renderer.Render(domain.ChannelSMS, event)
+	require.NoError(t, err)
+	assert.Equal(t, "Bestellung order1 bestätigt: 3 Artikel, 1.042,50 USD. Danke für deinen Einkauf!", sms.Body)
+
+	event.Customer.Locale = "ja"
+	sms, err = renderer.Render(domain.ChannelSMS, event)
+	require.NoError(t, err)
+	assert.Equal(t, "Order order1 confirmed: 3 item(s), 1,042.50 USD. Thanks for shopping with us!", sms.Body, "English when not translated")
+}
+
+func TestMessages(t *testing.T) {
+	assert.Equal(t, []string{"en", "de", "es"}, Messages.Languages())
+	for _, lang := range Messages.Languages() {
+		assert.Empty(t, Messages.Missing(lang), "every message is translated into %s", lang)
+	}
+}
+
+func TestRenderUnknownEventType(t *testing.T) {
+	renderer, err := NewRenderer()
+	require.NoError(t, err)
+
+	event := orderCreated()
+	event.Type = "order.viewed"
+	_, err = renderer.Render(domain.ChannelEmail, event)
+	assert.ErrorIs(t, err, domain.ErrNoTemplate)
+}
+
+func TestNewRendererRejectsTemplateWithoutBody(t *testing.T) {
+	fsys := fstest.MapFS{
+		"files/order.created.email.tmpl": {Data: []byte(`{{define "subject"}}hi{{end}}`)},
+	}
+	_, err := newRenderer(fsys, "files")
+	assert.Error(t, err)
+}
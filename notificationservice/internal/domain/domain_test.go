@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"testing"
+)
+
+func validEvent() OrderEvent {
+	return OrderEvent{
+		ID:       "evt1",
+		Type:     EventOrderCreated,
+		OrderID:  "order1",
+		Customer: Customer{Name: "Ada", Email: " ada@example.com ", Phone: ""},
+		Total:    25.5,
+		Currency: "USD",
+	}
+}
+
+func TestOrderEventValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(e *OrderEvent)
+		wantErr bool
+	}{
+		{name: "valid event", modify: func(e *OrderEvent) {}, wantErr: false},
+		{name: "missing id", modify: func(e *OrderEvent) { e.ID = "" }, wantErr: true},
+		{name: "missing type", modify: func(e *OrderEvent) { e.Type = " " }, wantErr: true},
+		{name: "missing order id", modify: func(e *OrderEvent) { e.OrderID = "" }, wantErr: true},
+		{name: "negative total", modify: func(e *OrderEvent) { e.Total = -1 }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := validEvent()
+			tt.modify(&event)
+
+			err := event.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("OrderEvent.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOrderEventRecipient(t *testing.T) {
+	event := validEvent()
+
+	if got := event.Recipient(ChannelEmail); got != "ada@example.com" {
+		t.Errorf("Recipient(email) = %q, want trimmed address", got)
+	}
+	if got := event.Recipient(ChannelSMS); got != "" {
+		t.Errorf("Recipient(sms) = %q, want empty", got)
+	}
+	if got := event.Recipient(Channel("pager")); got != "" {
+		t.Errorf("Recipient(pager) = %q, want empty", got)
+	}
+}
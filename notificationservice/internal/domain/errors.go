@@ -0,0 +1,16 @@
+package domain
+
+import "errors"
+
+// Domain errors represent business rule violations
+var (
+	// ErrInvalidInput indicates an event violates business rules and can never be processed
+	ErrInvalidInput = errors.New("invalid input")
+
+	// ErrNoTemplate indicates there is no template for an event type and channel
+	ErrNoTemplate = errors.New("no template")
+
+	// ErrUndeliverable indicates a sender permanently rejected a message
+	// (e.g. an invalid address); retrying would not help
+	ErrUndeliverable = errors.New("undeliverable")
+)
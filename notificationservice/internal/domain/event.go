@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Order event types published by orderservice
+const (
+	EventOrderCreated = "order.created"
+)
+
+// Customer holds the contact details notifications are sent to
+type Customer struct {
+	Name  string
+	Email string
+	Phone string
+	// Locale is the language the customer reads, such as "es" or "de-CH";
+	// notifications fall back to English when it is empty or not translated
+	Locale string
+}
+
+// OrderEvent is an order lifecycle event consumed from the broker.
+// This is a pure domain model without infrastructure concerns
+type OrderEvent struct {
+	// ID is unique per event and used to avoid sending a notification twice
+	ID         string
+	Type       string
+	OrderID    string
+	Customer   Customer
+	Total      float64
+	Currency   string
+	ItemCount  int
+	OccurredAt time.Time
+}
+
+// Validate performs basic validation on event fields
+func (e *OrderEvent) Validate() error {
+	if strings.TrimSpace(e.ID) == "" {
+		return errors.New("event id is required")
+	}
+	if strings.TrimSpace(e.Type) == "" {
+		return errors.New("event type is required")
+	}
+	if strings.TrimSpace(e.OrderID) == "" {
+		return errors.New("order_id is required")
+	}
+	if e.Total < 0 {
+		return errors.New("total cannot be negative")
+	}
+	return nil
+}
+
+// Recipient returns the customer's address for a channel, or "" when the
+// customer cannot be reached on it
+func (e *OrderEvent) Recipient(channel Channel) string {
+	switch channel {
+	case ChannelEmail:
+		return strings.TrimSpace(e.Customer.Email)
+	case ChannelSMS:
+		return strings.TrimSpace(e.Customer.Phone)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,22 @@
+package domain
+
+// Channel is a way of reaching a customer
+type Channel string
+
+// Supported notification channels
+const (
+	ChannelEmail Channel = "email"
+	ChannelSMS   Channel = "sms"
+)
+
+// Channels lists every channel in the order notifications are sent
+var Channels = []Channel{ChannelEmail, ChannelSMS}
+
+// Message is a rendered notification ready to be handed to a sender
+type Message struct {
+	Channel Channel
+	To      string
+	// Subject is only used by channels that have one (email)
+	Subject string
+	Body    string
+}
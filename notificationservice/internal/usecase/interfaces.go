@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/gostratum/examples/notificationservice/internal/domain"
+)
+
+// Application-level errors for use case layer
+// These decide whether the consumer retries an event or dead-letters it
+var (
+	// ErrUnavailable indicates a sender or store is temporarily unavailable; the event is retried
+	ErrUnavailable = errors.New("service unavailable")
+
+	// ErrInvalid wraps domain.ErrInvalidInput for application layer
+	ErrInvalid = domain.ErrInvalidInput
+
+	// ErrNoTemplate wraps domain.ErrNoTemplate for application layer
+	ErrNoTemplate = domain.ErrNoTemplate
+
+	// ErrUndeliverable wraps domain.ErrUndeliverable for application layer
+	ErrUndeliverable = domain.ErrUndeliverable
+)
+
+// IsPermanent reports whether err can never succeed on retry. An error joining a
+// permanent and a transient failure is not permanent: the transient channel is
+// still worth retrying.
+func IsPermanent(err error) bool {
+	if errors.Is(err, ErrUnavailable) {
+		return false
+	}
+	return errors.Is(err, ErrInvalid) || errors.Is(err, ErrUndeliverable)
+}
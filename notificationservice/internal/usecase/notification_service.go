@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gostratum/examples/notificationservice/internal/domain"
+)
+
+// NotificationService turns order events into customer notifications
+type NotificationService struct {
+	renderer   Renderer
+	senders    map[domain.Channel]Sender
+	deliveries DeliveryLog
+}
+
+// NewNotificationService creates a new notification service. Channels without a
+// sender are skipped.
+func NewNotificationService(renderer Renderer, senders []Sender, deliveries DeliveryLog) *NotificationService {
+	byChannel := make(map[domain.Channel]Sender, len(senders))
+	for _, s := range senders {
+		byChannel[s.Channel()] = s
+	}
+	return &NotificationService{
+		renderer:   renderer,
+		senders:    byChannel,
+		deliveries: deliveries,
+	}
+}
+
+// Handle sends the notifications for an event on every channel the customer can be
+// reached on. Channels are independent: a failing channel does not stop the others,
+// and channels that already succeeded are skipped when the event is retried.
+// A nil error means there is nothing left to send.
+func (s *NotificationService) Handle(ctx context.Context, event *domain.OrderEvent) error {
+	if err := event.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	var errs []error
+	for _, channel := range domain.Channels {
+		if err := s.notify(ctx, event, channel); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// notify sends the event's notification on a single channel
+func (s *NotificationService) notify(ctx context.Context, event *domain.OrderEvent, channel domain.Channel) error {
+	sender, ok := s.senders[channel]
+	if !ok || event.Recipient(channel) == "" {
+		return nil
+	}
+
+	delivered, err := s.deliveries.Delivered(ctx, event.ID, channel)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	if delivered {
+		return nil
+	}
+
+	msg, err := s.renderer.Render(channel, event)
+	if errors.Is(err, ErrNoTemplate) {
+		// Event types without a template on this channel are not meant to notify
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to render %s: %w", event.Type, err)
+	}
+
+	if err := sender.Send(ctx, msg); err != nil {
+		if errors.Is(err, ErrUndeliverable) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	// The message is out; failing to record it only risks a duplicate on retry
+	_ = s.deliveries.MarkDelivered(ctx, event.ID, channel)
+	return nil
+}
@@ -0,0 +1,159 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gostratum/examples/notificationservice/internal/domain"
+)
+
+// MockSender implements Sender for testing
+type MockSender struct {
+	channel   domain.Channel
+	sent      []*domain.Message
+	sendError error
+}
+
+func (m *MockSender) Channel() domain.Channel {
+	return m.channel
+}
+
+func (m *MockSender) Send(ctx context.Context, msg *domain.Message) error {
+	if m.sendError != nil {
+		return m.sendError
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// MockRenderer implements Renderer for testing; it only knows order.created
+type MockRenderer struct{}
+
+func (MockRenderer) Render(channel domain.Channel, event *domain.OrderEvent) (*domain.Message, error) {
+	if event.Type != domain.EventOrderCreated {
+		return nil, domain.ErrNoTemplate
+	}
+	return &domain.Message{Channel: channel, To: event.Recipient(channel), Body: "order " + event.OrderID}, nil
+}
+
+// MockDeliveryLog implements DeliveryLog for testing
+type MockDeliveryLog struct {
+	delivered map[string]bool
+}
+
+func NewMockDeliveryLog() *MockDeliveryLog {
+	return &MockDeliveryLog{delivered: make(map[string]bool)}
+}
+
+func (m *MockDeliveryLog) Delivered(ctx context.Context, eventID string, channel domain.Channel) (bool, error) {
+	return m.delivered[eventID+"/"+string(channel)], nil
+}
+
+func (m *MockDeliveryLog) MarkDelivered(ctx context.Context, eventID string, channel domain.Channel) error {
+	m.delivered[eventID+"/"+string(channel)] = true
+	return nil
+}
+
+func testEvent() *domain.OrderEvent {
+	return &domain.OrderEvent{
+		ID:       "evt1",
+		Type:     domain.EventOrderCreated,
+		OrderID:  "order1",
+		Customer: domain.Customer{Name: "Ada", Email: "ada@example.com", Phone: "+15550100"},
+		Total:    42,
+		Currency: "USD",
+	}
+}
+
+func TestHandle(t *testing.T) {
+	tests := []struct {
+		name          string
+		modify        func(e *domain.OrderEvent)
+		emailError    error
+		smsError      error
+		wantErr       error
+		wantPermanent bool
+		wantEmails    int
+		wantSMS       int
+	}{
+		{name: "sends email and sms", wantEmails: 1, wantSMS: 1},
+		{name: "skips channels without recipient", modify: func(e *domain.OrderEvent) { e.Customer.Phone = "" }, wantEmails: 1},
+		{name: "skips event types without template", modify: func(e *domain.OrderEvent) { e.Type = "order.viewed" }},
+		{
+			name:          "invalid event is permanent",
+			modify:        func(e *domain.OrderEvent) { e.OrderID = "" },
+			wantErr:       ErrInvalid,
+			wantPermanent: true,
+		},
+		{
+			name:       "transient sender failure is unavailable",
+			smsError:   errors.New("connection refused"),
+			wantErr:    ErrUnavailable,
+			wantEmails: 1,
+		},
+		{
+			name:          "rejected recipient is permanent",
+			emailError:    fmt.Errorf("%w: mailbox does not exist", domain.ErrUndeliverable),
+			wantErr:       ErrUndeliverable,
+			wantPermanent: true,
+			wantSMS:       1,
+		},
+		{
+			name:       "transient failure beats permanent failure",
+			emailError: fmt.Errorf("%w: mailbox does not exist", domain.ErrUndeliverable),
+			smsError:   errors.New("timeout"),
+			wantErr:    ErrUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			email := &MockSender{channel: domain.ChannelEmail, sendError: tt.emailError}
+			sms := &MockSender{channel: domain.ChannelSMS, sendError: tt.smsError}
+			service := NewNotificationService(MockRenderer{}, []Sender{email, sms}, NewMockDeliveryLog())
+
+			event := testEvent()
+			if tt.modify != nil {
+				tt.modify(event)
+			}
+			err := service.Handle(context.Background(), event)
+
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Handle() unexpected error = %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Handle() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && IsPermanent(err) != tt.wantPermanent {
+				t.Errorf("IsPermanent(%v) = %v, want %v", err, IsPermanent(err), tt.wantPermanent)
+			}
+			if len(email.sent) != tt.wantEmails || len(sms.sent) != tt.wantSMS {
+				t.Errorf("sent %d emails and %d sms, want %d and %d", len(email.sent), len(sms.sent), tt.wantEmails, tt.wantSMS)
+			}
+		})
+	}
+}
+
+func TestHandleRetryDoesNotResend(t *testing.T) {
+	email := &MockSender{channel: domain.ChannelEmail}
+	sms := &MockSender{channel: domain.ChannelSMS, sendError: errors.New("timeout")}
+	service := NewNotificationService(MockRenderer{}, []Sender{email, sms}, NewMockDeliveryLog())
+
+	if err := service.Handle(context.Background(), testEvent()); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("first Handle() error = %v, want ErrUnavailable", err)
+	}
+
+	sms.sendError = nil
+	if err := service.Handle(context.Background(), testEvent()); err != nil {
+		t.Fatalf("retried Handle() error = %v", err)
+	}
+
+	if len(email.sent) != 1 {
+		t.Errorf("email sent %d times, want 1", len(email.sent))
+	}
+	if len(sms.sent) != 1 {
+		t.Errorf("sms sent %d times, want 1", len(sms.sent))
+	}
+}
@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/gostratum/examples/notificationservice/internal/domain"
+)
+
+// Sender delivers messages on one channel
+// This interface is owned by the use case layer (dependency inversion principle)
+type Sender interface {
+	Channel() domain.Channel
+	// Send delivers msg. It returns an error wrapping domain.ErrUndeliverable when
+	// the message can never be delivered and any other error for transient failures.
+	Send(ctx context.Context, msg *domain.Message) error
+}
+
+// Renderer turns an event into the message for a channel
+type Renderer interface {
+	// Render returns domain.ErrNoTemplate when the event type has no template for channel
+	Render(channel domain.Channel, event *domain.OrderEvent) (*domain.Message, error)
+}
+
+// DeliveryLog remembers which notifications were sent, so a redelivered event
+// does not notify the customer twice on channels that already succeeded
+type DeliveryLog interface {
+	Delivered(ctx context.Context, eventID string, channel domain.Channel) (bool, error)
+	MarkDelivered(ctx context.Context, eventID string, channel domain.Channel) error
+}
@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"time"
+)
+
+// Action is what the consumer does with an event after handling it
+type Action int
+
+const (
+	// Ack marks the event as done
+	Ack Action = iota
+	// Retry redelivers the event after Decision.Delay
+	Retry
+	// DeadLetter moves the event to the dead letter queue
+	DeadLetter
+)
+
+func (a Action) String() string {
+	switch a {
+	case Ack:
+		return "ack"
+	case Retry:
+		return "retry"
+	case DeadLetter:
+		return "dead_letter"
+	default:
+		return "unknown"
+	}
+}
+
+// Decision is the outcome of RetryPolicy.Decide
+type Decision struct {
+	Action Action
+	Delay  time.Duration
+}
+
+// RetryPolicy retries transient failures with exponential backoff and dead-letters
+// permanent failures and events that ran out of attempts
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Decide returns what to do with an event whose attempt-th delivery (starting at 1)
+// ended with err
+func (p RetryPolicy) Decide(err error, attempt int) Decision {
+	switch {
+	case err == nil:
+		return Decision{Action: Ack}
+	case IsPermanent(err), attempt >= p.MaxAttempts:
+		return Decision{Action: DeadLetter}
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return Decision{Action: Retry, Delay: delay}
+}
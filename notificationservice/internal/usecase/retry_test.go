@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDecide(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	transient := fmt.Errorf("%w: timeout", ErrUnavailable)
+
+	tests := []struct {
+		name    string
+		err     error
+		attempt int
+		want    Decision
+	}{
+		{name: "success is acked", err: nil, attempt: 1, want: Decision{Action: Ack}},
+		{name: "first failure waits base delay", err: transient, attempt: 1, want: Decision{Action: Retry, Delay: time.Second}},
+		{name: "backoff doubles", err: transient, attempt: 3, want: Decision{Action: Retry, Delay: 4 * time.Second}},
+		{name: "backoff is capped", err: transient, attempt: 4, want: Decision{Action: Retry, Delay: 5 * time.Second}},
+		{name: "last attempt is dead-lettered", err: transient, attempt: 5, want: Decision{Action: DeadLetter}},
+		{name: "permanent failure is dead-lettered", err: fmt.Errorf("%w: bad event", ErrInvalid), attempt: 1, want: Decision{Action: DeadLetter}},
+		{name: "unknown error is retried", err: errors.New("template failed"), attempt: 1, want: Decision{Action: Retry, Delay: time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := policy.Decide(tt.err, tt.attempt)
+			if got != tt.want {
+				t.Errorf("Decide() = %+v (%s), want %+v (%s)", got, got.Action, tt.want, tt.want.Action)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gostratum/examples/observability-demo/internal/app"
+)
+
+// The types below cover the subset of the Grafana dashboard JSON model the generator uses
+
+type dashboard struct {
+	UID           string     `json:"uid"`
+	Title         string     `json:"title"`
+	Tags          []string   `json:"tags"`
+	Timezone      string     `json:"timezone"`
+	SchemaVersion int        `json:"schemaVersion"`
+	Refresh       string     `json:"refresh"`
+	Time          timeRange  `json:"time"`
+	Templating    templating `json:"templating"`
+	Panels        []panel    `json:"panels"`
+}
+
+type timeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type templating struct {
+	List []variable `json:"list"`
+}
+
+type variable struct {
+	Name       string         `json:"name"`
+	Label      string         `json:"label"`
+	Type       string         `json:"type"`
+	Query      string         `json:"query"`
+	Datasource *datasourceRef `json:"datasource,omitempty"`
+	Refresh    int            `json:"refresh,omitempty"`
+	Multi      bool           `json:"multi,omitempty"`
+	IncludeAll bool           `json:"includeAll,omitempty"`
+}
+
+type datasourceRef struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+type panel struct {
+	ID          int            `json:"id"`
+	Type        string         `json:"type"`
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	GridPos     gridPos        `json:"gridPos"`
+	Datasource  *datasourceRef `json:"datasource,omitempty"`
+	Repeat      string         `json:"repeat,omitempty"`
+	FieldConfig *fieldConfig   `json:"fieldConfig,omitempty"`
+	Targets     []target       `json:"targets,omitempty"`
+}
+
+type gridPos struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type fieldConfig struct {
+	Defaults fieldDefaults `json:"defaults"`
+}
+
+type fieldDefaults struct {
+	Unit string `json:"unit"`
+}
+
+type target struct {
+	RefID        string `json:"refId"`
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat"`
+}
+
+const (
+	// panelsPerRow graph panels fit next to each other on Grafana's 24 column grid
+	panelsPerRow = 3
+	panelWidth   = 24 / panelsPerRow
+	panelHeight  = 8
+	rowHeight    = 1
+)
+
+// rateInterval lets Grafana pick a rate window that fits the scrape interval and zoom level
+const rateInterval = "$__rate_interval"
+
+// prometheus is the datasource of every panel, chosen through the datasource variable
+var prometheus = &datasourceRef{Type: "prometheus", UID: "${datasource}"}
+
+// builder lays panels out row by row and numbers them
+type builder struct {
+	panels []panel
+	nextID int
+	y      int
+	column int
+}
+
+// row starts a new dashboard row; repeat names a variable to repeat the row for
+func (b *builder) row(title, repeat string) {
+	if b.column > 0 {
+		b.y += panelHeight
+		b.column = 0
+	}
+	b.nextID++
+	b.panels = append(b.panels, panel{
+		ID:      b.nextID,
+		Type:    "row",
+		Title:   title,
+		GridPos: gridPos{X: 0, Y: b.y, W: 24, H: rowHeight},
+		Repeat:  repeat,
+	})
+	b.y += rowHeight
+}
+
+// graph adds a time series panel in the next free slot of the current row
+func (b *builder) graph(title, description, unit string, targets ...target) {
+	if b.column == panelsPerRow {
+		b.y += panelHeight
+		b.column = 0
+	}
+	for i := range targets {
+		targets[i].RefID = string(rune('A' + i))
+	}
+	b.nextID++
+	b.panels = append(b.panels, panel{
+		ID:          b.nextID,
+		Type:        "timeseries",
+		Title:       title,
+		Description: description,
+		GridPos:     gridPos{X: b.column * panelWidth, Y: b.y, W: panelWidth, H: panelHeight},
+		Datasource:  prometheus,
+		FieldConfig: &fieldConfig{Defaults: fieldDefaults{Unit: unit}},
+		Targets:     targets,
+	})
+	b.column++
+}
+
+// generateDashboard builds the demo dashboard: RED panels repeated per route, then one
+// panel per application, database and runtime metric in the catalog
+func generateDashboard(catalog, collected []app.MetricDefinition, uid, title string) (dashboard, error) {
+	red := []app.MetricDefinition{app.HTTPRouteRequests, app.HTTPRouteErrors, app.HTTPRouteDuration}
+	for _, m := range red {
+		if !inCatalog(catalog, m.Name) {
+			return dashboard{}, fmt.Errorf("RED metric %s is not in the catalog", m.Name)
+		}
+		if !m.HasLabel("route") {
+			return dashboard{}, fmt.Errorf("RED metric %s has no route label", m.Name)
+		}
+	}
+
+	var b builder
+
+	b.row("RED: $route", "route")
+	requests, errors, duration := app.HTTPRouteRequests.Name, app.HTTPRouteErrors.Name, app.HTTPRouteDuration.Name
+	b.graph("Rate", requests, "reqps", target{
+		Expr:         fmt.Sprintf(`sum by (method) (rate(%s{route=~"$route"}[%s]))`, requests, rateInterval),
+		LegendFormat: "{{method}}",
+	})
+	b.graph("Errors", errors+" / "+requests, "percentunit", target{
+		Expr: fmt.Sprintf(`sum by (method) (rate(%s{route=~"$route"}[%s])) / sum by (method) (rate(%s{route=~"$route"}[%s]))`,
+			errors, rateInterval, requests, rateInterval),
+		LegendFormat: "{{method}}",
+	})
+	var latency []target
+	for _, q := range []string{"0.5", "0.95", "0.99"} {
+		latency = append(latency, target{
+			Expr: fmt.Sprintf(`histogram_quantile(%s, sum by (le) (rate(%s_bucket{route=~"$route"}[%s])))`,
+				q, duration, rateInterval),
+			LegendFormat: "p" + strings.TrimPrefix(q, "0."),
+		})
+	}
+	b.graph("Duration", duration, "s", latency...)
+
+	var application, database, runtime []app.MetricDefinition
+	for _, m := range catalog {
+		if !isRED(red, m) {
+			application = append(application, m)
+		}
+	}
+	for _, m := range collected {
+		if strings.HasPrefix(m.Name, "db_") {
+			database = append(database, m)
+		} else {
+			runtime = append(runtime, m)
+		}
+	}
+
+	for _, section := range []struct {
+		title   string
+		metrics []app.MetricDefinition
+	}{
+		{"Application", application},
+		{"Database", database},
+		{"Runtime", runtime},
+	} {
+		if len(section.metrics) == 0 {
+			continue
+		}
+		b.row(section.title, "")
+		for _, m := range section.metrics {
+			b.graph(m.Help, m.Name, metricUnit(m), metricTarget(m))
+		}
+	}
+
+	return dashboard{
+		UID:           uid,
+		Title:         title,
+		Tags:          []string{"gostratum", "generated"},
+		Timezone:      "browser",
+		SchemaVersion: 39,
+		Refresh:       "30s",
+		Time:          timeRange{From: "now-1h", To: "now"},
+		Templating: templating{List: []variable{
+			{Name: "datasource", Label: "Data source", Type: "datasource", Query: "prometheus"},
+			{
+				Name:       "route",
+				Label:      "Route",
+				Type:       "query",
+				Query:      fmt.Sprintf("label_values(%s, route)", requests),
+				Datasource: prometheus,
+				Refresh:    2,
+				Multi:      true,
+				IncludeAll: true,
+			},
+		}},
+		Panels: b.panels,
+	}, nil
+}
+
+// metricTarget is the default query for a metric: the rate of a counter, the p95 of a
+// histogram or the value of a gauge, split by the metric's labels
+func metricTarget(m app.MetricDefinition) target {
+	if len(m.Labels) == 0 {
+		switch m.Type {
+		case app.CounterMetric:
+			return target{Expr: fmt.Sprintf("sum(rate(%s[%s]))", m.Name, rateInterval), LegendFormat: "rate"}
+		case app.HistogramMetric:
+			return target{
+				Expr:         fmt.Sprintf("histogram_quantile(0.95, sum by (le) (rate(%s_bucket[%s])))", m.Name, rateInterval),
+				LegendFormat: "p95",
+			}
+		default:
+			return target{Expr: m.Name, LegendFormat: "{{instance}}"}
+		}
+	}
+
+	by := strings.Join(m.Labels, ", ")
+	parts := make([]string, len(m.Labels))
+	for i, l := range m.Labels {
+		parts[i] = "{{" + l + "}}"
+	}
+	legend := strings.Join(parts, " ")
+
+	switch m.Type {
+	case app.CounterMetric:
+		return target{Expr: fmt.Sprintf("sum by (%s) (rate(%s[%s]))", by, m.Name, rateInterval), LegendFormat: legend}
+	case app.HistogramMetric:
+		return target{
+			Expr:         fmt.Sprintf("histogram_quantile(0.95, sum by (%s, le) (rate(%s_bucket[%s])))", by, m.Name, rateInterval),
+			LegendFormat: "p95 " + legend,
+		}
+	default:
+		return target{Expr: fmt.Sprintf("sum by (%s) (%s)", by, m.Name), LegendFormat: legend}
+	}
+}
+
+// metricUnit derives the Grafana unit from the metric name suffix
+func metricUnit(m app.MetricDefinition) string {
+	name := strings.TrimSuffix(m.Name, "_total")
+	switch {
+	case strings.HasSuffix(name, "_seconds") && m.Type == app.CounterMetric:
+		return "percentunit"
+	case strings.HasSuffix(name, "_seconds"):
+		return "s"
+	case strings.HasSuffix(name, "_bytes"):
+		return "bytes"
+	case m.Type == app.CounterMetric:
+		return "ops"
+	default:
+		return "short"
+	}
+}
+
+func inCatalog(catalog []app.MetricDefinition, name string) bool {
+	for _, m := range catalog {
+		if m.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isRED(red []app.MetricDefinition, m app.MetricDefinition) bool {
+	return inCatalog(red, m.Name)
+}
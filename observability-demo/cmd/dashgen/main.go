@@ -0,0 +1,43 @@
+// Command dashgen writes a Grafana dashboard for observability-demo generated from
+// app.MetricCatalog and app.CollectedMetrics, so panels never drift from instrumentation.
+//
+//	go run ./cmd/dashgen -out grafana/dashboards/observability-demo.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/gostratum/examples/observability-demo/internal/app"
+)
+
+func main() {
+	var uid, title, out string
+	flag.StringVar(&uid, "uid", "observability-demo", "Dashboard UID")
+	flag.StringVar(&title, "title", "Observability Demo", "Dashboard title")
+	flag.StringVar(&out, "out", "", "File to write the dashboard to (default: stdout)")
+	flag.Parse()
+
+	dash, err := generateDashboard(app.MetricCatalog(), app.CollectedMetrics(), uid, title)
+	if err != nil {
+		log.Fatalf("Failed to generate dashboard: %v", err)
+	}
+
+	data, err := json.MarshalIndent(dash, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode dashboard: %v", err)
+	}
+	data = append(data, '\n')
+
+	if out == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(out, data, 0o644); err != nil {
+		log.Fatalf("Failed to write %s: %v", out, err)
+	}
+	fmt.Printf("✅ Wrote %d panels to %s\n", len(dash.Panels), out)
+}
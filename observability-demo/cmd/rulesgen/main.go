@@ -0,0 +1,47 @@
+// Command rulesgen writes Prometheus recording rules and SLO burn-rate alerts for the
+// metrics observability-demo registers, generated from app.MetricCatalog.
+//
+//	go run ./cmd/rulesgen -out rules/observability-demo.yml
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/gostratum/examples/observability-demo/internal/app"
+)
+
+func main() {
+	var opts sloOptions
+	var out string
+	flag.StringVar(&opts.Service, "service", "observability-demo", "Service name used for rule group names and alert labels")
+	flag.Float64Var(&opts.Availability, "availability", 0.999, "Availability objective: share of requests that must not return 5xx")
+	flag.Float64Var(&opts.LatencyObjective, "latency-objective", 0.99, "Latency objective: share of requests that must finish within -latency-threshold")
+	flag.Float64Var(&opts.LatencyThreshold, "latency-threshold", 0.5, "Latency threshold in seconds; must be a bucket of http_route_request_duration_seconds")
+	flag.StringVar(&out, "out", "", "File to write the rules to (default: stdout)")
+	flag.Parse()
+
+	rules, err := generateRules(app.MetricCatalog(), opts)
+	if err != nil {
+		log.Fatalf("Failed to generate rules: %v", err)
+	}
+
+	data, err := yaml.Marshal(rules)
+	if err != nil {
+		log.Fatalf("Failed to encode rules: %v", err)
+	}
+	data = append([]byte("# Code generated by cmd/rulesgen; DO NOT EDIT.\n"), data...)
+
+	if out == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(out, data, 0o644); err != nil {
+		log.Fatalf("Failed to write %s: %v", out, err)
+	}
+	fmt.Printf("✅ Wrote %d rule groups to %s\n", len(rules.Groups), out)
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gostratum/examples/observability-demo/internal/app"
+)
+
+// ruleFile is the Prometheus rule file format
+type ruleFile struct {
+	Groups []ruleGroup `yaml:"groups"`
+}
+
+type ruleGroup struct {
+	Name  string `yaml:"name"`
+	Rules []rule `yaml:"rules"`
+}
+
+type rule struct {
+	Record      string            `yaml:"record,omitempty"`
+	Alert       string            `yaml:"alert,omitempty"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// sloOptions are the objectives the burn-rate alerts are derived from
+type sloOptions struct {
+	Service          string
+	Availability     float64
+	LatencyObjective float64
+	LatencyThreshold float64
+}
+
+// rateWindow is the window of the per-metric recording rules
+const rateWindow = "5m"
+
+// quantiles are recorded for every histogram in the catalog
+var quantiles = []float64{0.5, 0.95, 0.99}
+
+// burnRateWindows are the error-ratio windows the alerts below need
+var burnRateWindows = []string{"5m", "30m", "1h", "2h", "6h", "1d", "3d"}
+
+// burnRateAlert is one multiwindow, multi-burn-rate condition as described in the
+// Google SRE workbook: the long window detects the burn, the short one makes the
+// alert reset quickly once the burn stops.
+type burnRateAlert struct {
+	Severity string
+	Long     string
+	Short    string
+	Factor   float64
+}
+
+// burnRateAlerts page on budget burns that exhaust a 30 day budget in hours and open
+// a ticket for slower burns
+var burnRateAlerts = [][]burnRateAlert{
+	{
+		{Severity: "page", Long: "1h", Short: "5m", Factor: 14.4},
+		{Severity: "page", Long: "6h", Short: "30m", Factor: 6},
+	},
+	{
+		{Severity: "ticket", Long: "1d", Short: "2h", Factor: 3},
+		{Severity: "ticket", Long: "3d", Short: "6h", Factor: 1},
+	},
+}
+
+// generateRules builds recording rules for every metric in catalog plus availability
+// and latency SLO burn-rate alerts on the RED metrics
+func generateRules(catalog []app.MetricDefinition, opts sloOptions) (ruleFile, error) {
+	if err := validateObjective("availability", opts.Availability); err != nil {
+		return ruleFile{}, err
+	}
+	if err := validateObjective("latency objective", opts.LatencyObjective); err != nil {
+		return ruleFile{}, err
+	}
+
+	requests, err := lookupMetric(catalog, app.HTTPRouteRequests.Name)
+	if err != nil {
+		return ruleFile{}, err
+	}
+	errorsMetric, err := lookupMetric(catalog, app.HTTPRouteErrors.Name)
+	if err != nil {
+		return ruleFile{}, err
+	}
+	duration, err := lookupMetric(catalog, app.HTTPRouteDuration.Name)
+	if err != nil {
+		return ruleFile{}, err
+	}
+	le, err := bucketBound(duration, opts.LatencyThreshold)
+	if err != nil {
+		return ruleFile{}, err
+	}
+
+	availability := sloRules{
+		Service:   opts.Service,
+		Name:      "availability",
+		Record:    "http_route_errors",
+		Objective: opts.Availability,
+		Ratio: func(w string) string {
+			return fmt.Sprintf("sum(rate(%s[%s])) / sum(rate(%s[%s]))", errorsMetric.Name, w, requests.Name, w)
+		},
+		Summary: "HTTP requests are failing with 5xx faster than the error budget allows",
+	}
+	latency := sloRules{
+		Service:   opts.Service,
+		Name:      "latency",
+		Record:    "http_route_slow_requests",
+		Objective: opts.LatencyObjective,
+		Ratio: func(w string) string {
+			return fmt.Sprintf("1 - (sum(rate(%s_bucket{le=%q}[%s])) / sum(rate(%s_count[%s])))",
+				duration.Name, le, w, duration.Name, w)
+		},
+		Summary: fmt.Sprintf("HTTP requests slower than %ss are eating the latency budget", le),
+	}
+
+	return ruleFile{Groups: []ruleGroup{
+		{Name: opts.Service + ".recording", Rules: recordingRules(catalog)},
+		{Name: opts.Service + ".slo", Rules: append(availability.records(), latency.records()...)},
+		{Name: opts.Service + ".alerts", Rules: append(availability.alerts(), latency.alerts()...)},
+	}}, nil
+}
+
+// recordingRules pre-aggregates every counter and histogram by its labels
+func recordingRules(catalog []app.MetricDefinition) []rule {
+	var rules []rule
+	for _, m := range catalog {
+		level := recordLevel(m.Labels)
+		by := strings.Join(m.Labels, ", ")
+
+		switch m.Type {
+		case app.CounterMetric:
+			rules = append(rules, rule{
+				Record: fmt.Sprintf("%s:%s:rate%s", level, strings.TrimSuffix(m.Name, "_total"), rateWindow),
+				Expr:   fmt.Sprintf("sum by (%s) (rate(%s[%s]))", by, m.Name, rateWindow),
+			})
+		case app.HistogramMetric:
+			bucketBy := "le"
+			if by != "" {
+				bucketBy = by + ", le"
+			}
+			for _, q := range quantiles {
+				rules = append(rules, rule{
+					Record: fmt.Sprintf("%s:%s:p%s_%s", level, m.Name, strconv.FormatFloat(q*100, 'f', -1, 64), rateWindow),
+					Expr: fmt.Sprintf("histogram_quantile(%s, sum by (%s) (rate(%s_bucket[%s])))",
+						strconv.FormatFloat(q, 'f', -1, 64), bucketBy, m.Name, rateWindow),
+				})
+			}
+		}
+	}
+	return rules
+}
+
+// recordLevel is the "level" part of a level:metric:operations rule name
+func recordLevel(labels []string) string {
+	if len(labels) == 0 {
+		return "job"
+	}
+	return strings.Join(labels, "_")
+}
+
+// sloRules generates the ratio recording rules and burn-rate alerts of one SLO
+type sloRules struct {
+	Service   string
+	Name      string
+	Record    string
+	Objective float64
+	Ratio     func(window string) string
+	Summary   string
+}
+
+func (s sloRules) recordName(window string) string {
+	return fmt.Sprintf("slo:%s:ratio_rate%s", s.Record, window)
+}
+
+func (s sloRules) records() []rule {
+	rules := make([]rule, 0, len(burnRateWindows))
+	for _, w := range burnRateWindows {
+		rules = append(rules, rule{Record: s.recordName(w), Expr: s.Ratio(w)})
+	}
+	return rules
+}
+
+func (s sloRules) alerts() []rule {
+	budget := 1 - s.Objective
+	alertName := "HTTP" + strings.ToUpper(s.Name[:1]) + s.Name[1:] + "BudgetBurn"
+
+	rules := make([]rule, 0, len(burnRateAlerts))
+	for _, conditions := range burnRateAlerts {
+		var expr []string
+		for _, c := range conditions {
+			threshold := strconv.FormatFloat(c.Factor*budget, 'g', 6, 64)
+			expr = append(expr, fmt.Sprintf("(%s > %s and %s > %s)",
+				s.recordName(c.Long), threshold, s.recordName(c.Short), threshold))
+		}
+
+		severity := conditions[0].Severity
+		forDuration := "2m"
+		if severity == "ticket" {
+			forDuration = "15m"
+		}
+		rules = append(rules, rule{
+			Alert: alertName,
+			Expr:  strings.Join(expr, "\nor\n"),
+			For:   forDuration,
+			Labels: map[string]string{
+				"severity": severity,
+				"service":  s.Service,
+				"slo":      s.Name,
+			},
+			Annotations: map[string]string{
+				"summary": s.Summary,
+				"description": fmt.Sprintf("The %s SLO of %s%% is burning its error budget (%s severity).",
+					s.Name, strconv.FormatFloat(s.Objective*100, 'f', -1, 64), severity),
+			},
+		})
+	}
+	return rules
+}
+
+// lookupMetric finds name in the catalog
+func lookupMetric(catalog []app.MetricDefinition, name string) (app.MetricDefinition, error) {
+	for _, m := range catalog {
+		if m.Name == name {
+			return m, nil
+		}
+	}
+	return app.MetricDefinition{}, fmt.Errorf("metric %s is not in the catalog", name)
+}
+
+// bucketBound returns the le label value for threshold, which must be one of the
+// histogram's bucket bounds for the latency SLO to be computable
+func bucketBound(m app.MetricDefinition, threshold float64) (string, error) {
+	for _, b := range m.Buckets {
+		if b == threshold {
+			return strconv.FormatFloat(b, 'f', -1, 64), nil
+		}
+	}
+	return "", fmt.Errorf("latency threshold %gs is not a bucket of %s (buckets: %v)", threshold, m.Name, m.Buckets)
+}
+
+func validateObjective(name string, objective float64) error {
+	if objective <= 0 || objective >= 1 {
+		return fmt.Errorf("%s objective must be between 0 and 1 (exclusive), got %g", name, objective)
+	}
+	return nil
+}
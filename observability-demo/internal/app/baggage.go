@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// Baggage keys carrying the caller's identity across service and span boundaries
+const (
+	baggageUserID   = "user_id"
+	baggageTenantID = "tenant_id"
+)
+
+// Request headers the demo reads the identity from. A real service would take it
+// from the authenticated principal instead.
+const (
+	headerUserID   = "X-User-ID"
+	headerTenantID = "X-Tenant-ID"
+)
+
+// identityKeys lists the baggage members copied onto spans and log lines
+var identityKeys = []string{baggageUserID, baggageTenantID}
+
+// WithIdentity returns a context whose baggage carries userID and tenantID.
+// Empty values are left out; existing baggage members are preserved.
+func WithIdentity(ctx context.Context, userID, tenantID string) context.Context {
+	bag := baggage.FromContext(ctx)
+	for key, value := range map[string]string{baggageUserID: userID, baggageTenantID: tenantID} {
+		if value == "" {
+			continue
+		}
+		member, err := baggage.NewMemberRaw(key, value)
+		if err != nil {
+			continue
+		}
+		if next, err := bag.SetMember(member); err == nil {
+			bag = next
+		}
+	}
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// IdentityFromContext returns the user and tenant IDs from the context baggage
+func IdentityFromContext(ctx context.Context) (userID, tenantID string) {
+	bag := baggage.FromContext(ctx)
+	return bag.Member(baggageUserID).Value(), bag.Member(baggageTenantID).Value()
+}
+
+// IdentityAttributes returns the identity baggage as span attributes
+func IdentityAttributes(ctx context.Context) []attribute.KeyValue {
+	bag := baggage.FromContext(ctx)
+	var attrs []attribute.KeyValue
+	for _, key := range identityKeys {
+		if value := bag.Member(key).Value(); value != "" {
+			attrs = append(attrs, attribute.String(key, value))
+		}
+	}
+	return attrs
+}
+
+// IdentityFields returns the identity baggage as log fields
+func IdentityFields(ctx context.Context) []logx.Field {
+	bag := baggage.FromContext(ctx)
+	var fields []logx.Field
+	for _, key := range identityKeys {
+		if value := bag.Member(key).Value(); value != "" {
+			fields = append(fields, logx.String(key, value))
+		}
+	}
+	return fields
+}
+
+// BaggageMiddleware copies the caller identity headers into OpenTelemetry baggage so
+// downstream spans, log lines and outgoing requests carry it
+func BaggageMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := WithIdentity(c.Request.Context(), c.GetHeader(headerUserID), c.GetHeader(headerTenantID))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// RegisterBaggage installs the baggage middleware ahead of the route groups
+func RegisterBaggage(engine *gin.Engine) {
+	engine.Use(BaggageMiddleware())
+}
@@ -0,0 +1,177 @@
+package app
+
+// MetricType is the Prometheus type of a metric in the catalog
+type MetricType string
+
+const (
+	CounterMetric   MetricType = "counter"
+	HistogramMetric MetricType = "histogram"
+	GaugeMetric     MetricType = "gauge"
+)
+
+// MetricDefinition describes a metric the demo registers with metricsx.
+// The constructors register metrics from these definitions, and the generators under
+// cmd/ build alerting rules and dashboards from them, so neither drifts from the code.
+type MetricDefinition struct {
+	Name    string
+	Type    MetricType
+	Help    string
+	Labels  []string
+	Buckets []float64
+}
+
+// HasLabel reports whether the metric carries label
+func (d MetricDefinition) HasLabel(label string) bool {
+	for _, l := range d.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// RED metrics recorded per route template by REDMetrics
+var (
+	HTTPRouteRequests = MetricDefinition{
+		Name:   "http_route_requests_total",
+		Type:   CounterMetric,
+		Help:   "Requests per route template",
+		Labels: []string{"method", "route", "status"},
+	}
+	HTTPRouteErrors = MetricDefinition{
+		Name:   "http_route_errors_total",
+		Type:   CounterMetric,
+		Help:   "Requests per route template that returned a 5xx status",
+		Labels: []string{"method", "route"},
+	}
+	HTTPRouteDuration = MetricDefinition{
+		Name:    "http_route_request_duration_seconds",
+		Type:    HistogramMetric,
+		Help:    "Request duration per route template",
+		Labels:  []string{"method", "route"},
+		Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+	}
+)
+
+// Business metrics recorded by UserMetrics
+var (
+	UsersCreated = MetricDefinition{
+		Name:   "users_created_total",
+		Type:   CounterMetric,
+		Help:   "Total number of user creation attempts",
+		Labels: []string{"result"},
+	}
+	UserLookupDuration = MetricDefinition{
+		Name:    "user_lookup_duration_seconds",
+		Type:    HistogramMetric,
+		Help:    "Duration of single user lookups",
+		Labels:  []string{"result"},
+		Buckets: []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+	}
+	UserListSize = MetricDefinition{
+		Name:    "user_list_size",
+		Type:    HistogramMetric,
+		Help:    "Number of users returned by list requests",
+		Buckets: []float64{0, 1, 5, 10, 25, 50, 100, 250, 500, 1000},
+	}
+)
+
+// Database metrics recorded by dbx for every connection
+var (
+	DBQueries = MetricDefinition{
+		Name: "db_queries_total",
+		Type: CounterMetric,
+		Help: "Total queries",
+	}
+	DBQueryErrors = MetricDefinition{
+		Name: "db_query_errors_total",
+		Type: CounterMetric,
+		Help: "Query errors",
+	}
+	DBQueryDuration = MetricDefinition{
+		Name: "db_query_duration_seconds",
+		Type: HistogramMetric,
+		Help: "Query duration",
+	}
+	DBQueriesInFlight = MetricDefinition{
+		Name: "db_queries_in_flight",
+		Type: GaugeMetric,
+		Help: "Active queries",
+	}
+)
+
+// Runtime metrics exported by the Prometheus Go and process collectors
+var (
+	GoGoroutines = MetricDefinition{
+		Name: "go_goroutines",
+		Type: GaugeMetric,
+		Help: "Number of goroutines that currently exist",
+	}
+	GoHeapAlloc = MetricDefinition{
+		Name: "go_memstats_heap_alloc_bytes",
+		Type: GaugeMetric,
+		Help: "Heap bytes allocated and still in use",
+	}
+	ProcessCPU = MetricDefinition{
+		Name: "process_cpu_seconds_total",
+		Type: CounterMetric,
+		Help: "Total user and system CPU time spent in seconds",
+	}
+	ProcessResidentMemory = MetricDefinition{
+		Name: "process_resident_memory_bytes",
+		Type: GaugeMetric,
+		Help: "Resident memory size in bytes",
+	}
+)
+
+// Lifecycle metrics recorded by LifecycleMetrics
+var (
+	AppStartDuration = MetricDefinition{
+		Name: "app_start_duration_seconds",
+		Type: GaugeMetric,
+		Help: "Time from process start until all OnStart hooks completed",
+	}
+	AppStartHookDuration = MetricDefinition{
+		Name:   "app_start_hook_duration_seconds",
+		Type:   GaugeMetric,
+		Help:   "Runtime of each fx OnStart hook during the last start",
+		Labels: []string{"hook"},
+	}
+	BuildInfo = MetricDefinition{
+		Name:   "build_info",
+		Type:   GaugeMetric,
+		Help:   "Build metadata of the running binary; always 1",
+		Labels: []string{"version", "commit", "go_version"},
+	}
+)
+
+// MetricCatalog returns every metric the demo registers itself. Metrics recorded by
+// dbx and the Prometheus collectors are listed by CollectedMetrics instead.
+func MetricCatalog() []MetricDefinition {
+	return []MetricDefinition{
+		HTTPRouteRequests,
+		HTTPRouteErrors,
+		HTTPRouteDuration,
+		UsersCreated,
+		UserLookupDuration,
+		UserListSize,
+		AppStartDuration,
+		AppStartHookDuration,
+		BuildInfo,
+	}
+}
+
+// CollectedMetrics returns the metrics recorded for the demo by dbx and the Prometheus
+// runtime collectors, which dashboards and alerts may rely on as well
+func CollectedMetrics() []MetricDefinition {
+	return []MetricDefinition{
+		DBQueries,
+		DBQueryErrors,
+		DBQueryDuration,
+		DBQueriesInFlight,
+		GoGoroutines,
+		GoHeapAlloc,
+		ProcessCPU,
+		ProcessResidentMemory,
+	}
+}
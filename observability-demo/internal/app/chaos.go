@@ -0,0 +1,228 @@
+package app
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// chaosAdminPath is exempt from fault injection so chaos can always be switched off
+const chaosAdminPath = "/admin/chaos"
+
+// chaosRetryAfter is the Retry-After, in seconds, of injected 503s, which stand in
+// for an overloaded service that tells clients when to come back
+const chaosRetryAfter = "1"
+
+// ChaosConfig controls fault injection. Rates are fractions of requests between 0 and 1.
+type ChaosConfig struct {
+	Enabled     bool          `mapstructure:"enabled" json:"enabled"`
+	LatencyRate float64       `mapstructure:"latency_rate" json:"latency_rate"`
+	Latency     time.Duration `mapstructure:"latency" json:"-" default:"500ms"`
+	ErrorRate   float64       `mapstructure:"error_rate" json:"error_rate"`
+	ErrorStatus int           `mapstructure:"error_status" json:"error_status" default:"503"`
+	ResetRate   float64       `mapstructure:"reset_rate" json:"reset_rate"`
+}
+
+// Prefix implements configx.Configurable
+func (ChaosConfig) Prefix() string {
+	return "chaos"
+}
+
+// Validate checks that rates are fractions and the error status is a server error
+func (c ChaosConfig) Validate() error {
+	for name, rate := range map[string]float64{
+		"latency_rate": c.LatencyRate,
+		"error_rate":   c.ErrorRate,
+		"reset_rate":   c.ResetRate,
+	} {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("%s must be between 0 and 1, got %v", name, rate)
+		}
+	}
+	if c.ErrorStatus < 500 || c.ErrorStatus > 599 {
+		return fmt.Errorf("error_status must be a 5xx status, got %d", c.ErrorStatus)
+	}
+	if c.Latency < 0 {
+		return fmt.Errorf("latency must not be negative")
+	}
+	return nil
+}
+
+// chaosState is the admin endpoint representation of ChaosConfig with a readable latency
+type chaosState struct {
+	ChaosConfig
+	Latency string `json:"latency"`
+}
+
+// Chaos injects latency, errors and connection resets into a share of requests
+type Chaos struct {
+	mu     sync.RWMutex
+	config ChaosConfig
+	logger *TraceLogger
+}
+
+// NewChaos loads the chaos section of the configuration
+func NewChaos(loader configx.Loader, logger *TraceLogger) (*Chaos, error) {
+	var cfg ChaosConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load chaos config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid chaos config: %w", err)
+	}
+	return &Chaos{config: cfg, logger: logger}, nil
+}
+
+// Config returns the active configuration
+func (ch *Chaos) Config() ChaosConfig {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+	return ch.config
+}
+
+// SetConfig replaces the active configuration
+func (ch *Chaos) SetConfig(cfg ChaosConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	ch.mu.Lock()
+	ch.config = cfg
+	ch.mu.Unlock()
+	return nil
+}
+
+// Middleware injects the configured faults. Each fault is rolled independently,
+// so a request can be both delayed and then failed.
+func (ch *Chaos) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := ch.Config()
+		if !cfg.Enabled || strings.HasPrefix(c.Request.URL.Path, chaosAdminPath) {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		span := trace.SpanFromContext(ctx)
+
+		if roll(cfg.LatencyRate) {
+			span.AddEvent("chaos.latency", trace.WithAttributes(attribute.String("chaos.latency", cfg.Latency.String())))
+			ch.logger.Warn(ctx, "chaos: injecting latency", logx.String("latency", cfg.Latency.String()))
+			select {
+			case <-time.After(cfg.Latency):
+			case <-ctx.Done():
+			}
+		}
+
+		if roll(cfg.ResetRate) {
+			span.AddEvent("chaos.reset")
+			ch.logger.Warn(ctx, "chaos: resetting connection")
+			if resetConnection(c) {
+				c.Abort()
+				return
+			}
+		}
+
+		if roll(cfg.ErrorRate) {
+			span.AddEvent("chaos.error", trace.WithAttributes(attribute.Int("chaos.status", cfg.ErrorStatus)))
+			ch.logger.Warn(ctx, "chaos: injecting error", logx.Int("status", cfg.ErrorStatus))
+			if cfg.ErrorStatus == http.StatusServiceUnavailable {
+				c.Header("Retry-After", chaosRetryAfter)
+			}
+			responsex.Error(c, cfg.ErrorStatus, "INJECTED_FAULT", "injected fault", nil)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// roll reports true for the given fraction of calls
+func roll(rate float64) bool {
+	return rate > 0 && rand.Float64() < rate
+}
+
+// resetConnection hijacks the connection and closes it with SO_LINGER 0 so the client
+// sees a TCP reset instead of a response. It returns false if hijacking is unsupported.
+func resetConnection(c *gin.Context) (reset bool) {
+	hijacker, ok := c.Writer.(http.Hijacker)
+	if !ok {
+		return false
+	}
+	// gin's writer is always a Hijacker, and panics when the writer it wraps is not,
+	// as with HTTP/2 or a test recorder
+	defer func() {
+		if recover() != nil {
+			reset = false
+		}
+	}()
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		return false
+	}
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		_ = tcp.SetLinger(0)
+	}
+	_ = conn.Close()
+	return true
+}
+
+// GetConfig returns the active chaos configuration
+func (ch *Chaos) GetConfig(c *gin.Context) {
+	cfg := ch.Config()
+	responsex.OK(c, chaosState{ChaosConfig: cfg, Latency: cfg.Latency.String()}, nil)
+}
+
+// UpdateConfig replaces the chaos configuration, e.g. to toggle it on or off at runtime
+func (ch *Chaos) UpdateConfig(c *gin.Context) {
+	current := ch.Config()
+	state := chaosState{ChaosConfig: current, Latency: current.Latency.String()}
+	if err := c.ShouldBindJSON(&state); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid request payload", nil)
+		return
+	}
+
+	cfg := state.ChaosConfig
+	latency, err := time.ParseDuration(state.Latency)
+	if err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", fmt.Sprintf("invalid latency: %v", err), nil)
+		return
+	}
+	cfg.Latency = latency
+
+	if err := ch.SetConfig(cfg); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
+		return
+	}
+
+	ch.logger.Info(c.Request.Context(), "chaos config updated",
+		logx.String("enabled", fmt.Sprint(cfg.Enabled)),
+		logx.String("latency_rate", fmt.Sprint(cfg.LatencyRate)),
+		logx.String("error_rate", fmt.Sprint(cfg.ErrorRate)),
+		logx.String("reset_rate", fmt.Sprint(cfg.ResetRate)),
+	)
+	responsex.OK(c, chaosState{ChaosConfig: cfg, Latency: cfg.Latency.String()}, nil)
+}
+
+// RegisterChaos installs the fault injection middleware and its admin endpoint.
+// It is invoked after RegisterREDMetrics so injected faults show up in the RED metrics.
+func RegisterChaos(engine *gin.Engine, chaos *Chaos) {
+	engine.Use(chaos.Middleware())
+
+	admin := engine.Group(chaosAdminPath)
+	{
+		admin.GET("", chaos.GetConfig)
+		admin.PUT("", chaos.UpdateConfig)
+	}
+}
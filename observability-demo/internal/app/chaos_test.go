@@ -0,0 +1,157 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chaosServer is an engine with the chaos middleware in front of GET /ping,
+// which counts the requests that reach it
+type chaosServer struct {
+	engine  *gin.Engine
+	chaos   *Chaos
+	reached atomic.Int64
+}
+
+func newChaosServer(t *testing.T, cfg ChaosConfig) *chaosServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	if cfg.ErrorStatus == 0 {
+		cfg.ErrorStatus = http.StatusServiceUnavailable
+	}
+
+	s := &chaosServer{
+		engine: gin.New(),
+		chaos:  &Chaos{logger: NewTraceLogger(logx.NewNoopLogger(), &LogSampler{}, nil)},
+	}
+	require.NoError(t, s.chaos.SetConfig(cfg))
+	RegisterChaos(s.engine, s.chaos)
+	s.engine.GET("/ping", func(c *gin.Context) {
+		s.reached.Add(1)
+		c.String(http.StatusOK, "pong")
+	})
+	return s
+}
+
+func (s *chaosServer) do(method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	s.engine.ServeHTTP(w, req)
+	return w
+}
+
+// errorEnvelope is the body of a responsex.Error response
+type errorEnvelope struct {
+	OK    bool `json:"ok"`
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func TestChaos_Disabled(t *testing.T) {
+	s := newChaosServer(t, ChaosConfig{ErrorRate: 1, ResetRate: 1, LatencyRate: 1, Latency: time.Hour})
+
+	for range 10 {
+		assert.Equal(t, http.StatusOK, s.do(http.MethodGet, "/ping", "").Code)
+	}
+	assert.EqualValues(t, 10, s.reached.Load())
+}
+
+func TestChaos_InjectedErrors(t *testing.T) {
+	tests := []struct {
+		status     int
+		retryAfter string
+	}{
+		{http.StatusServiceUnavailable, chaosRetryAfter},
+		{http.StatusInternalServerError, ""},
+		{http.StatusBadGateway, ""},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			s := newChaosServer(t, ChaosConfig{Enabled: true, ErrorRate: 1, ErrorStatus: tt.status})
+
+			w := s.do(http.MethodGet, "/ping", "")
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.retryAfter, w.Header().Get("Retry-After"))
+
+			var body errorEnvelope
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body), w.Body.String())
+			assert.False(t, body.OK)
+			assert.Equal(t, "INJECTED_FAULT", body.Error.Code)
+			assert.Equal(t, "injected fault", body.Error.Message)
+			assert.Zero(t, s.reached.Load(), "a failed request does not reach the handler")
+		})
+	}
+}
+
+func TestChaos_PartialFailure(t *testing.T) {
+	s := newChaosServer(t, ChaosConfig{Enabled: true, ErrorRate: 0.3})
+
+	const requests = 1000
+	failed := 0
+	for range requests {
+		w := s.do(http.MethodGet, "/ping", "")
+		switch w.Code {
+		case http.StatusOK:
+			assert.Equal(t, "pong", w.Body.String())
+		case http.StatusServiceUnavailable:
+			failed++
+			var body errorEnvelope
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body), w.Body.String())
+			assert.Equal(t, "INJECTED_FAULT", body.Error.Code)
+			assert.Equal(t, chaosRetryAfter, w.Header().Get("Retry-After"))
+		default:
+			t.Fatalf("unexpected status %d", w.Code)
+		}
+	}
+
+	// 300 expected; the bounds are seven standard deviations away
+	assert.InDelta(t, 300, failed, 100, "failed requests")
+	assert.EqualValues(t, requests-failed, s.reached.Load(), "every request that did not fail was served")
+}
+
+func TestChaos_ConnectionReset(t *testing.T) {
+	s := newChaosServer(t, ChaosConfig{Enabled: true, ResetRate: 1})
+	srv := httptest.NewServer(s.engine)
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL + "/ping")
+	if err == nil {
+		resp.Body.Close()
+	}
+	assert.Error(t, err, "the client sees the connection closed instead of a response")
+	assert.Zero(t, s.reached.Load())
+}
+
+func TestChaos_Latency(t *testing.T) {
+	s := newChaosServer(t, ChaosConfig{Enabled: true, LatencyRate: 1, Latency: 50 * time.Millisecond})
+
+	start := time.Now()
+	w := s.do(http.MethodGet, "/ping", "")
+	assert.Equal(t, http.StatusOK, w.Code, "a delayed request still succeeds")
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestChaos_AdminIsExempt(t *testing.T) {
+	s := newChaosServer(t, ChaosConfig{Enabled: true, ErrorRate: 1})
+	require.Equal(t, http.StatusServiceUnavailable, s.do(http.MethodGet, "/ping", "").Code)
+
+	assert.Equal(t, http.StatusOK, s.do(http.MethodGet, chaosAdminPath, "").Code)
+	w := s.do(http.MethodPut, chaosAdminPath, `{"enabled": false}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	assert.Equal(t, http.StatusOK, s.do(http.MethodGet, "/ping", "").Code, "chaos can always be switched off")
+	assert.Equal(t, 1.0, s.chaos.Config().ErrorRate, "omitted fields keep their value")
+}
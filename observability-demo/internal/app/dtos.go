@@ -0,0 +1,58 @@
+package app
+
+import "time"
+
+// UserResponse is the HTTP DTO for user data, decoupling the API contract from the GORM model
+type UserResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FromUser converts a User to UserResponse DTO
+func FromUser(user *User) *UserResponse {
+	if user == nil {
+		return nil
+	}
+	return &UserResponse{
+		ID:        user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
+
+// FromUsers converts a slice of User to UserResponse DTOs
+func FromUsers(users []User) []UserResponse {
+	out := make([]UserResponse, len(users))
+	for i := range users {
+		out[i] = *FromUser(&users[i])
+	}
+	return out
+}
+
+// CreateUserRequest represents the request payload for creating a user
+type CreateUserRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Email string `json:"email" binding:"required,email"`
+}
+
+// UpdateUserRequest represents the request payload for updating a user
+type UpdateUserRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Email string `json:"email" binding:"required,email"`
+}
+
+// userURI binds the :id path parameter
+type userURI struct {
+	ID uint `uri:"id" binding:"required"`
+}
+
+// listUsersQuery binds the pagination query parameters
+type listUsersQuery struct {
+	Page int `form:"page" binding:"omitempty,min=1"`
+	Size int `form:"size" binding:"omitempty,min=1"`
+}
@@ -0,0 +1,308 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+)
+
+// ErrorReportingConfig configures shipping of errors and panics to a Sentry-compatible
+// endpoint. Reporting is disabled while DSN is empty.
+type ErrorReportingConfig struct {
+	DSN         string        `mapstructure:"dsn"`
+	Environment string        `mapstructure:"environment" default:"development"`
+	Release     string        `mapstructure:"release"`
+	Timeout     time.Duration `mapstructure:"timeout" default:"5s"`
+	QueueSize   int           `mapstructure:"queue_size" default:"100"`
+}
+
+// Prefix implements configx.Configurable
+func (ErrorReportingConfig) Prefix() string {
+	return "error_reporting"
+}
+
+// sensitiveHeaders are never sent with an error report
+var sensitiveHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+	"X-Api-Key":     true,
+}
+
+// sentryFrame, sentryException and sentryEvent are the subset of the Sentry event
+// payload the reporter fills in
+type sentryFrame struct {
+	Filename string `json:"filename"`
+	Function string `json:"function"`
+	Lineno   int    `json:"lineno"`
+}
+
+type sentryException struct {
+	Type       string `json:"type"`
+	Value      string `json:"value"`
+	Stacktrace struct {
+		Frames []sentryFrame `json:"frames"`
+	} `json:"stacktrace"`
+}
+
+type sentryRequest struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+type sentryEvent struct {
+	EventID     string `json:"event_id"`
+	Timestamp   string `json:"timestamp"`
+	Level       string `json:"level"`
+	Platform    string `json:"platform"`
+	ServerName  string `json:"server_name,omitempty"`
+	Environment string `json:"environment,omitempty"`
+	Release     string `json:"release,omitempty"`
+	Exception   struct {
+		Values []sentryException `json:"values"`
+	} `json:"exception"`
+	Request *sentryRequest    `json:"request,omitempty"`
+	Tags    map[string]string `json:"tags,omitempty"`
+}
+
+// ErrorReporter sends error events to a Sentry-compatible store endpoint from a
+// background worker so request handling never waits on the network
+type ErrorReporter struct {
+	config   ErrorReportingConfig
+	endpoint string
+	auth     string
+	client   *http.Client
+	logger   logx.Logger
+	events   chan sentryEvent
+	done     chan struct{}
+}
+
+// NewErrorReporter loads the configuration and starts the delivery worker with the app
+func NewErrorReporter(lc fx.Lifecycle, loader configx.Loader, logger logx.Logger) (*ErrorReporter, error) {
+	var cfg ErrorReportingConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load error reporting config: %w", err)
+	}
+
+	r := &ErrorReporter{
+		config: cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		logger: logger,
+	}
+	if cfg.DSN == "" {
+		return r, nil
+	}
+
+	endpoint, auth, err := parseSentryDSN(cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 100
+	}
+	r.endpoint, r.auth = endpoint, auth
+	r.events = make(chan sentryEvent, cfg.QueueSize)
+	r.done = make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go r.run()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(r.events)
+			select {
+			case <-r.done:
+			case <-ctx.Done():
+			}
+			return nil
+		},
+	})
+	return r, nil
+}
+
+// parseSentryDSN turns https://<key>@<host>/<project> into the store endpoint and auth header
+func parseSentryDSN(dsn string) (endpoint, auth string, err error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid error reporting dsn: %w", err)
+	}
+	key := u.User.Username()
+	project := strings.Trim(u.Path, "/")
+	if key == "" || project == "" {
+		return "", "", fmt.Errorf("invalid error reporting dsn: expected scheme://key@host/project")
+	}
+
+	endpoint = fmt.Sprintf("%s://%s/api/%s/store/", u.Scheme, u.Host, project)
+	auth = fmt.Sprintf("Sentry sentry_version=7, sentry_client=observability-demo/1.0, sentry_key=%s", key)
+	return endpoint, auth, nil
+}
+
+// Enabled reports whether a DSN is configured
+func (r *ErrorReporter) Enabled() bool {
+	return r.events != nil
+}
+
+// Capture queues an error with the stack frames of its caller and the request context.
+// Events are dropped rather than blocking when the queue is full.
+func (r *ErrorReporter) Capture(ctx context.Context, err error, req *http.Request) {
+	r.capture(ctx, "error", fmt.Sprintf("%T", err), err.Error(), stackFrames(3), req)
+}
+
+// capturePanic queues a recovered panic with the frames of the panicking goroutine
+func (r *ErrorReporter) capturePanic(ctx context.Context, recovered any, req *http.Request) {
+	// Skip the deferred recover closure and runtime.gopanic to start at the panic site
+	r.capture(ctx, "fatal", "panic", fmt.Sprint(recovered), stackFrames(5), req)
+}
+
+func (r *ErrorReporter) capture(ctx context.Context, level, typ, value string, frames []sentryFrame, req *http.Request) {
+	if !r.Enabled() {
+		return
+	}
+
+	event := sentryEvent{
+		EventID:     newEventID(),
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		Level:       level,
+		Platform:    "go",
+		Environment: r.config.Environment,
+		Release:     r.config.Release,
+		Tags:        map[string]string{},
+	}
+	event.ServerName, _ = os.Hostname()
+
+	exception := sentryException{Type: typ, Value: value}
+	exception.Stacktrace.Frames = frames
+	event.Exception.Values = []sentryException{exception}
+
+	if req != nil {
+		event.Request = &sentryRequest{URL: req.URL.String(), Method: req.Method, Headers: map[string]string{}}
+		for name := range req.Header {
+			if !sensitiveHeaders[name] {
+				event.Request.Headers[name] = req.Header.Get(name)
+			}
+		}
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		event.Tags["trace_id"] = sc.TraceID().String()
+	}
+	userID, tenantID := IdentityFromContext(ctx)
+	if userID != "" {
+		event.Tags["user_id"] = userID
+	}
+	if tenantID != "" {
+		event.Tags["tenant_id"] = tenantID
+	}
+
+	select {
+	case r.events <- event:
+	default:
+		r.logger.Warn("error report dropped, queue full", logx.String("event_id", event.EventID))
+	}
+}
+
+// run delivers queued events until the queue is closed
+func (r *ErrorReporter) run() {
+	defer close(r.done)
+	for event := range r.events {
+		if err := r.send(event); err != nil {
+			r.logger.Warn("failed to send error report", logx.Err(err), logx.String("event_id", event.EventID))
+		}
+	}
+}
+
+func (r *ErrorReporter) send(event sentryEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", r.auth)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("error reporting endpoint returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// stackFrames returns the caller's stack, outermost frame first as Sentry expects
+func stackFrames(skip int) []sentryFrame {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var out []sentryFrame
+	for {
+		frame, more := frames.Next()
+		out = append(out, sentryFrame{Filename: frame.File, Function: frame.Function, Lineno: frame.Line})
+		if !more {
+			break
+		}
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+// newEventID returns a random 32-character hex event ID
+func newEventID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Middleware recovers panics and reports them, and reports errors handlers attached
+// with c.Error when the response is a server error
+func (r *ErrorReporter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				r.capturePanic(c.Request.Context(), recovered, c.Request)
+				responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+				c.Abort()
+			}
+		}()
+
+		c.Next()
+
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			for _, err := range c.Errors {
+				r.Capture(c.Request.Context(), err.Err, c.Request)
+			}
+		}
+	}
+}
+
+// RegisterErrorReporting installs the reporting middleware ahead of the route groups
+func RegisterErrorReporting(engine *gin.Engine, reporter *ErrorReporter) {
+	engine.Use(reporter.Middleware())
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gostratum/dbx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// gormSpanKey stores the in-flight span on the GORM statement between callbacks
+const gormSpanKey = "otel:span"
+
+// GORMTracingPlugin creates a child span for every GORM query so database time
+// shows up inside the HTTP request trace. The recorded SQL keeps its placeholders;
+// bound values are never attached to the span.
+type GORMTracingPlugin struct {
+	tracer   trace.Tracer
+	database string
+}
+
+// NewGORMTracingPlugin returns a plugin for the named dbx connection using the global
+// tracer provider set up by tracingx
+func NewGORMTracingPlugin(database string) *GORMTracingPlugin {
+	return &GORMTracingPlugin{
+		tracer:   otel.Tracer("github.com/gostratum/examples/observability-demo/gorm"),
+		database: database,
+	}
+}
+
+func (p *GORMTracingPlugin) Name() string {
+	return "otel-tracing"
+}
+
+// Initialize registers before/after callbacks around every GORM operation
+func (p *GORMTracingPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	hooks := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+
+	for _, h := range hooks {
+		if err := h.before("otel:before_"+h.operation, p.start(h.operation)); err != nil {
+			return err
+		}
+		if err := h.after("otel:after_"+h.operation, p.end); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// start opens a span as a child of the span in the statement's context
+func (p *GORMTracingPlugin) start(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		ctx, span := p.tracer.Start(tx.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.operation", operation),
+				attribute.String("db.name", p.database),
+			),
+			trace.WithAttributes(IdentityAttributes(tx.Statement.Context)...),
+		)
+		tx.Statement.Context = ctx
+		tx.InstanceSet(gormSpanKey, span)
+	}
+}
+
+// end records the statement, table, rows affected and error status, then closes the span
+func (p *GORMTracingPlugin) end(tx *gorm.DB) {
+	value, ok := tx.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.system", tx.Dialector.Name()),
+		attribute.String("db.statement", tx.Statement.SQL.String()),
+		attribute.String("db.sql.table", tx.Statement.Table),
+		attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+	)
+
+	if err := tx.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// RegisterGORMTracing installs the tracing plugin on every dbx connection
+func RegisterGORMTracing(conns dbx.Connections) error {
+	for name, db := range conns {
+		if err := db.Use(NewGORMTracingPlugin(name)); err != nil {
+			return fmt.Errorf("failed to register tracing on %s connection: %w", name, err)
+		}
+	}
+	return nil
+}
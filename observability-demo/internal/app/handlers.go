@@ -0,0 +1,170 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+// UserHandler handles HTTP requests
+type UserHandler struct {
+	service *UserService
+	logger  *TraceLogger
+}
+
+func NewUserHandler(service *UserService, logger *TraceLogger) *UserHandler {
+	return &UserHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Create handles POST /api/v1/users
+func (h *UserHandler) Create(c *gin.Context) {
+	var req CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid request payload", nil)
+		return
+	}
+
+	user, err := h.service.CreateUser(c.Request.Context(), req.Name, req.Email)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.Created(c, "", FromUser(user))
+}
+
+// Get handles GET /api/v1/users/:id
+func (h *UserHandler) Get(c *gin.Context) {
+	var uri userURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_PARAMETER", "user id must be a positive integer", nil)
+		return
+	}
+
+	user, err := h.service.GetUser(c.Request.Context(), uri.ID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.OK(c, FromUser(user), nil)
+}
+
+// List handles GET /api/v1/users?page=&size=
+func (h *UserHandler) List(c *gin.Context) {
+	var query listUsersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_PARAMETER", "page and size must be positive integers", nil)
+		return
+	}
+
+	page := Page{Page: query.Page, Size: query.Size}.normalize()
+	users, total, err := h.service.ListUsers(c.Request.Context(), page)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	offset := (page.Page - 1) * page.Size
+	responsex.OK(c, FromUsers(users), &responsex.Pagination{
+		Total:  &total,
+		Limit:  &page.Size,
+		Offset: &offset,
+	})
+}
+
+// Update handles PUT /api/v1/users/:id
+func (h *UserHandler) Update(c *gin.Context) {
+	var uri userURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_PARAMETER", "user id must be a positive integer", nil)
+		return
+	}
+
+	var req UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid request payload", nil)
+		return
+	}
+
+	user, err := h.service.UpdateUser(c.Request.Context(), uri.ID, req.Name, req.Email)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.OK(c, FromUser(user), nil)
+}
+
+// Delete handles DELETE /api/v1/users/:id
+func (h *UserHandler) Delete(c *gin.Context) {
+	var uri userURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_PARAMETER", "user id must be a positive integer", nil)
+		return
+	}
+
+	if err := h.service.DeleteUser(c.Request.Context(), uri.ID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// handleError maps service errors to HTTP responses
+func (h *UserHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		responsex.Error(c, http.StatusNotFound, "USER_NOT_FOUND", "user not found", nil)
+	case errors.Is(err, ErrInvalidUser):
+		responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
+	default:
+		h.logger.Error(c.Request.Context(), "unexpected error", logx.Err(err))
+		_ = c.Error(err)
+		responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+	}
+}
+
+// RegisterRoutes registers HTTP routes
+func RegisterRoutes(engine *gin.Engine, handler *UserHandler, reports *ReportHandler) {
+	v1 := engine.Group("/api/v1", responsex.MetaMiddleware("observability-demo/v1.0.0"))
+	{
+		users := v1.Group("/users")
+		{
+			users.POST("", handler.Create)
+			users.GET("", handler.List)
+			users.GET("/:id", handler.Get)
+			users.PUT("/:id", handler.Update)
+			users.DELETE("/:id", handler.Delete)
+		}
+
+		// Reporting queries run on the analytics connection
+		v1.GET("/reports/signups", reports.Signups)
+	}
+}
+
+// SetupDatabase initializes the database schema
+func SetupDatabase(lc fx.Lifecycle, db *gorm.DB, logger logx.Logger) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			// Auto-migrate the User model
+			if err := db.AutoMigrate(&User{}); err != nil {
+				logger.Error("failed to migrate database", logx.Err(err))
+				return err
+			}
+
+			logger.Info("database migration completed")
+			return nil
+		},
+	})
+}
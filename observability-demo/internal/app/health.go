@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core"
+	"github.com/gostratum/core/configx"
+	"gorm.io/gorm"
+)
+
+// HealthConfig configures the demo's custom readiness checks
+type HealthConfig struct {
+	Timeout       time.Duration `mapstructure:"timeout" default:"2s"`
+	DiskPath      string        `mapstructure:"disk_path" default:"."`
+	MinFreeBytes  uint64        `mapstructure:"min_free_bytes" default:"104857600"`
+	DependencyURL string        `mapstructure:"dependency_url"`
+}
+
+// Prefix implements configx.Configurable
+func (HealthConfig) Prefix() string {
+	return "health"
+}
+
+// dbPingCheck pings the database with a timeout
+type dbPingCheck struct {
+	db      *gorm.DB
+	timeout time.Duration
+}
+
+func (c *dbPingCheck) Name() string    { return "db-ping" }
+func (c *dbPingCheck) Kind() core.Kind { return core.Readiness }
+
+func (c *dbPingCheck) Check(ctx context.Context) error {
+	sqlDB, err := c.db.DB()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return sqlDB.PingContext(ctx)
+}
+
+// diskSpaceCheck fails when the filesystem holding path runs low on space
+type diskSpaceCheck struct {
+	path     string
+	minBytes uint64
+}
+
+func (c *diskSpaceCheck) Name() string    { return "disk-space" }
+func (c *diskSpaceCheck) Kind() core.Kind { return core.Readiness }
+
+func (c *diskSpaceCheck) Check(ctx context.Context) error {
+	free, err := freeDiskBytes(c.path)
+	if err != nil {
+		return fmt.Errorf("failed to read free space of %s: %w", c.path, err)
+	}
+	if free < c.minBytes {
+		return fmt.Errorf("only %d bytes free on %s, need %d", free, c.path, c.minBytes)
+	}
+	return nil
+}
+
+// dependencyCheck requires an upstream URL to answer without a server error
+type dependencyCheck struct {
+	url    string
+	client *http.Client
+}
+
+func (c *dependencyCheck) Name() string    { return "dependency" }
+func (c *dependencyCheck) Kind() core.Kind { return core.Readiness }
+
+func (c *dependencyCheck) Check(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("%s returned %d", c.url, resp.StatusCode)
+	}
+	return nil
+}
+
+// RegisterHealthChecks adds the custom readiness checks to the registry, next to the
+// checks dbx registers automatically
+func RegisterHealthChecks(reg core.Registry, loader configx.Loader, db *gorm.DB) error {
+	var cfg HealthConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return fmt.Errorf("failed to load health config: %w", err)
+	}
+
+	reg.Register(&dbPingCheck{db: db, timeout: cfg.Timeout})
+	reg.Register(&diskSpaceCheck{path: cfg.DiskPath, minBytes: cfg.MinFreeBytes})
+	if cfg.DependencyURL != "" {
+		reg.Register(&dependencyCheck{url: cfg.DependencyURL, client: &http.Client{Timeout: cfg.Timeout}})
+	}
+	return nil
+}
+
+// RegisterHealthRoutes serves /healthz (readiness) and /livez (liveness) like orderservice
+func RegisterHealthRoutes(engine *gin.Engine, reg core.Registry) {
+	engine.GET("/healthz", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		res := reg.Aggregate(ctx, core.Readiness)
+		if res.OK {
+			c.JSON(http.StatusOK, gin.H{"ok": true, "details": res.Details})
+		} else {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"ok": false, "details": res.Details})
+		}
+	})
+
+	engine.GET("/livez", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		res := reg.Aggregate(ctx, core.Liveness)
+		if res.OK {
+			c.JSON(http.StatusOK, gin.H{"ok": true, "details": res.Details})
+		} else {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"ok": false, "details": res.Details})
+		}
+	})
+}
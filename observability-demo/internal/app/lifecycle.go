@@ -0,0 +1,99 @@
+package app
+
+import (
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/metricsx"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+)
+
+// processStart approximates process start for the cold-start duration
+var processStart = time.Now()
+
+// Build metadata, set at build time:
+//
+//	pkg=github.com/gostratum/examples/observability-demo/internal/app
+//	go build -ldflags "-X $pkg.version=1.2.3 -X $pkg.commit=$(git rev-parse --short HEAD)"
+var (
+	version = "dev"
+	commit  = ""
+)
+
+// buildCommit returns the ldflags commit or the VCS revision stamped by the Go toolchain
+func buildCommit() string {
+	if commit != "" {
+		return commit
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" && len(setting.Value) >= 7 {
+				return setting.Value[:7]
+			}
+		}
+	}
+	return "unknown"
+}
+
+// lifecycleMetrics is an fxevent.Logger that turns fx lifecycle events into metrics:
+// total start duration, the runtime of every OnStart hook, and a build_info gauge
+type lifecycleMetrics struct {
+	logger        logx.Logger
+	startDuration metricsx.Gauge
+	hookDuration  metricsx.Gauge
+	buildInfo     metricsx.Gauge
+}
+
+// newLifecycleMetrics registers the lifecycle metrics
+func newLifecycleMetrics(metrics metricsx.Metrics, logger logx.Logger) fxevent.Logger {
+	l := &lifecycleMetrics{
+		logger: logger,
+		startDuration: metrics.Gauge(AppStartDuration.Name,
+			metricsx.WithHelp(AppStartDuration.Help),
+		),
+		hookDuration: metrics.Gauge(AppStartHookDuration.Name,
+			metricsx.WithHelp(AppStartHookDuration.Help),
+			metricsx.WithLabels(AppStartHookDuration.Labels...),
+		),
+		buildInfo: metrics.Gauge(BuildInfo.Name,
+			metricsx.WithHelp(BuildInfo.Help),
+			metricsx.WithLabels(BuildInfo.Labels...),
+		),
+	}
+	l.buildInfo.Set(1, version, buildCommit(), runtime.Version())
+	return l
+}
+
+// LogEvent implements fxevent.Logger
+func (l *lifecycleMetrics) LogEvent(event fxevent.Event) {
+	switch e := event.(type) {
+	case *fxevent.OnStartExecuted:
+		if e.Err != nil {
+			l.logger.Error("OnStart hook failed", logx.String("hook", e.FunctionName), logx.Err(e.Err))
+			return
+		}
+		l.hookDuration.Set(e.Runtime.Seconds(), e.FunctionName)
+		l.logger.Debug("OnStart hook executed",
+			logx.String("hook", e.FunctionName),
+			logx.String("runtime", e.Runtime.String()),
+		)
+	case *fxevent.Started:
+		if e.Err != nil {
+			return
+		}
+		elapsed := time.Since(processStart)
+		l.startDuration.Set(elapsed.Seconds())
+		l.logger.Info("application started",
+			logx.String("start_duration", elapsed.String()),
+			logx.String("version", version),
+		)
+	}
+}
+
+// LifecycleMetrics routes fx events through lifecycleMetrics
+func LifecycleMetrics() fx.Option {
+	return fx.WithLogger(newLifecycleMetrics)
+}
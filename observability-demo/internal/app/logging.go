@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+
+	"github.com/gostratum/core/logx"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceLogger wraps logx.Logger and appends the trace_id and span_id of the active
+// span, plus the identity baggage, to every log line, so logs can be joined with
+// traces in Jaeger/Grafana. Lines pass through the LogSampler first; lines that are
+// kept also go to the OTLP LogExporter when it is enabled.
+type TraceLogger struct {
+	logger   logx.Logger
+	sampler  *LogSampler
+	exporter *LogExporter
+}
+
+// NewTraceLogger returns a context-aware logger backed by logger
+func NewTraceLogger(logger logx.Logger, sampler *LogSampler, exporter *LogExporter) *TraceLogger {
+	return &TraceLogger{logger: logger, sampler: sampler, exporter: exporter}
+}
+
+// TraceFields returns the trace_id/span_id fields for the span in ctx,
+// or nothing when ctx carries no sampled or unsampled span
+func TraceFields(ctx context.Context) []logx.Field {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return []logx.Field{
+		logx.String("trace_id", sc.TraceID().String()),
+		logx.String("span_id", sc.SpanID().String()),
+	}
+}
+
+// contextFields appends the trace and identity fields for ctx to fields
+func contextFields(ctx context.Context, fields []logx.Field) []logx.Field {
+	fields = append(fields, TraceFields(ctx)...)
+	return append(fields, IdentityFields(ctx)...)
+}
+
+// sample consults the sampler and reports lines dropped in the previous tick
+func (l *TraceLogger) sample(level, msg string) bool {
+	allowed, dropped := l.sampler.Allow(level, msg)
+	if dropped > 0 {
+		l.logger.Warn("log lines dropped by sampling", logx.Int("dropped", dropped))
+	}
+	return allowed
+}
+
+func (l *TraceLogger) Debug(ctx context.Context, msg string, fields ...logx.Field) {
+	if l.sample("debug", msg) {
+		l.logger.Debug(msg, contextFields(ctx, fields)...)
+		l.exporter.Emit(ctx, otellog.SeverityDebug, msg, append(fields, IdentityFields(ctx)...))
+	}
+}
+
+func (l *TraceLogger) Info(ctx context.Context, msg string, fields ...logx.Field) {
+	if l.sample("info", msg) {
+		l.logger.Info(msg, contextFields(ctx, fields)...)
+		l.exporter.Emit(ctx, otellog.SeverityInfo, msg, append(fields, IdentityFields(ctx)...))
+	}
+}
+
+func (l *TraceLogger) Warn(ctx context.Context, msg string, fields ...logx.Field) {
+	if l.sample("warn", msg) {
+		l.logger.Warn(msg, contextFields(ctx, fields)...)
+		l.exporter.Emit(ctx, otellog.SeverityWarn, msg, append(fields, IdentityFields(ctx)...))
+	}
+}
+
+func (l *TraceLogger) Error(ctx context.Context, msg string, fields ...logx.Field) {
+	if l.sample("error", msg) {
+		l.logger.Error(msg, contextFields(ctx, fields)...)
+		l.exporter.Emit(ctx, otellog.SeverityError, msg, append(fields, IdentityFields(ctx)...))
+	}
+}
@@ -0,0 +1,168 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/httpx/responsex"
+)
+
+// LogSamplingConfig limits repetitive logging. Within each tick the first Initial
+// identical lines (same level and message) are written, then every Thereafter-th one.
+// ErrorBurst caps the total number of error lines per tick to survive error storms.
+type LogSamplingConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	Tick       time.Duration `mapstructure:"tick" default:"1s"`
+	Initial    int           `mapstructure:"initial" default:"5"`
+	Thereafter int           `mapstructure:"thereafter" default:"100"`
+	ErrorBurst int           `mapstructure:"error_burst" default:"20"`
+}
+
+// Prefix implements configx.Configurable
+func (LogSamplingConfig) Prefix() string {
+	return "log.sampling"
+}
+
+// LogSamplingStats counts the lines the sampler let through and dropped since startup
+type LogSamplingStats struct {
+	Emitted int64 `json:"emitted"`
+	Dropped int64 `json:"dropped"`
+}
+
+// LogSampler decides which log lines are written
+type LogSampler struct {
+	config LogSamplingConfig
+	now    func() time.Time
+
+	mu          sync.Mutex
+	windowStart time.Time
+	counts      map[string]int
+	errors      int
+	dropped     int
+	stats       LogSamplingStats
+}
+
+// NewLogSampler loads the log.sampling section of the configuration
+func NewLogSampler(loader configx.Loader) (*LogSampler, error) {
+	var cfg LogSamplingConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load log sampling config: %w", err)
+	}
+	if cfg.Enabled && cfg.Tick <= 0 {
+		return nil, fmt.Errorf("log.sampling.tick must be positive")
+	}
+	return &LogSampler{config: cfg, now: time.Now, counts: make(map[string]int)}, nil
+}
+
+// Allow reports whether a line should be written. When a new tick starts it also
+// returns how many lines were dropped during the previous one so callers can report it.
+func (s *LogSampler) Allow(level, msg string) (allowed bool, droppedLastTick int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.config.Enabled {
+		s.stats.Emitted++
+		return true, 0
+	}
+
+	if now := s.now(); now.Sub(s.windowStart) >= s.config.Tick {
+		droppedLastTick = s.dropped
+		s.windowStart = now
+		s.counts = make(map[string]int)
+		s.errors = 0
+		s.dropped = 0
+	}
+
+	key := level + "|" + msg
+	s.counts[key]++
+	n := s.counts[key]
+
+	allowed = n <= s.config.Initial ||
+		(s.config.Thereafter > 0 && (n-s.config.Initial)%s.config.Thereafter == 0)
+
+	if allowed && level == "error" && s.config.ErrorBurst > 0 {
+		s.errors++
+		allowed = s.errors <= s.config.ErrorBurst
+	}
+
+	if allowed {
+		s.stats.Emitted++
+	} else {
+		s.dropped++
+		s.stats.Dropped++
+	}
+	return allowed, droppedLastTick
+}
+
+// Stats returns the running totals
+func (s *LogSampler) Stats() LogSamplingStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// logGeneratorLimit caps the lines a single generator request may produce
+const logGeneratorLimit = 100000
+
+// LogGeneratorResponse reports what the sampler did with a generated burst
+type LogGeneratorResponse struct {
+	Requested int              `json:"requested"`
+	Emitted   int64            `json:"emitted"`
+	Dropped   int64            `json:"dropped"`
+	Totals    LogSamplingStats `json:"totals"`
+}
+
+// LogGenerator is a demo endpoint that floods the logger with identical lines so
+// the effect of sampling can be observed
+type LogGenerator struct {
+	logger  *TraceLogger
+	sampler *LogSampler
+}
+
+func NewLogGenerator(logger *TraceLogger, sampler *LogSampler) *LogGenerator {
+	return &LogGenerator{logger: logger, sampler: sampler}
+}
+
+// Generate writes ?count= identical lines at ?level= (debug, info, warn, error)
+// and reports how many of them the sampler let through
+func (g *LogGenerator) Generate(c *gin.Context) {
+	count, err := strconv.Atoi(c.DefaultQuery("count", "1000"))
+	if err != nil || count < 1 || count > logGeneratorLimit {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_PARAMETER", fmt.Sprintf("count must be between 1 and %d", logGeneratorLimit), nil)
+		return
+	}
+
+	log := map[string]func(){
+		"debug": func() { g.logger.Debug(c.Request.Context(), "generated debug line") },
+		"info":  func() { g.logger.Info(c.Request.Context(), "generated info line") },
+		"warn":  func() { g.logger.Warn(c.Request.Context(), "generated warn line") },
+		"error": func() { g.logger.Error(c.Request.Context(), "generated error line") },
+	}[c.DefaultQuery("level", "debug")]
+	if log == nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_PARAMETER", "level must be one of debug, info, warn, error", nil)
+		return
+	}
+
+	before := g.sampler.Stats()
+	for i := 0; i < count; i++ {
+		log()
+	}
+	after := g.sampler.Stats()
+
+	responsex.OK(c, LogGeneratorResponse{
+		Requested: count,
+		Emitted:   after.Emitted - before.Emitted,
+		Dropped:   after.Dropped - before.Dropped,
+		Totals:    after,
+	}, nil)
+}
+
+// RegisterLogGenerator exposes the generator under /debug/logs
+func RegisterLogGenerator(engine *gin.Engine, generator *LogGenerator) {
+	engine.POST("/debug/logs", generator.Generate)
+}
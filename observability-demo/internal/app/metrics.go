@@ -0,0 +1,32 @@
+package app
+
+import (
+	"github.com/gostratum/metricsx"
+)
+
+// UserMetrics holds the business metrics emitted by UserService.
+// They complement the HTTP and database metrics recorded automatically by httpx and dbx.
+type UserMetrics struct {
+	created        metricsx.Counter
+	lookupDuration metricsx.Histogram
+	listSize       metricsx.Histogram
+}
+
+// NewUserMetrics registers the user metrics with the metricsx provider
+func NewUserMetrics(metrics metricsx.Metrics) *UserMetrics {
+	return &UserMetrics{
+		created: metrics.Counter(UsersCreated.Name,
+			metricsx.WithHelp(UsersCreated.Help),
+			metricsx.WithLabels(UsersCreated.Labels...),
+		),
+		lookupDuration: metrics.Histogram(UserLookupDuration.Name,
+			metricsx.WithHelp(UserLookupDuration.Help),
+			metricsx.WithLabels(UserLookupDuration.Labels...),
+			metricsx.WithBuckets(UserLookupDuration.Buckets...),
+		),
+		listSize: metrics.Histogram(UserListSize.Name,
+			metricsx.WithHelp(UserListSize.Help),
+			metricsx.WithBuckets(UserListSize.Buckets...),
+		),
+	}
+}
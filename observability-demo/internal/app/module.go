@@ -0,0 +1,46 @@
+// Package app holds the observability demo's services, handlers and instrumentation so
+// the root, modular and monolithic mains all compose the same working code.
+package app
+
+import "go.uber.org/fx"
+
+// Providers lists the constructors of the demo's components
+var Providers = []any{
+	NewLogSampler,
+	NewLogExporter,
+	NewTraceLogger,
+	NewUserMetrics,
+	NewREDMetrics,
+	NewChaos,
+	NewErrorReporter,
+	NewLogGenerator,
+	NewUserService,
+	NewUserHandler,
+	NewReportService,
+	NewReportHandler,
+}
+
+// Invokes lists the setup functions in the order they must run: middleware is
+// installed on the engine before any route group is created
+var Invokes = []any{
+	InstallRouteSampler,
+	RegisterREDMetrics,
+	RegisterBaggage,
+	RegisterErrorReporting,
+	RegisterChaos,
+	RegisterGORMTracing,
+	RegisterRoutes,
+	RegisterLogGenerator,
+	RegisterHealthChecks,
+	RegisterHealthRoutes,
+	SetupDatabase,
+}
+
+// Module wires the demo application. Infrastructure (metricsx, tracingx, httpx, dbx)
+// and LifecycleMetrics are left to the caller.
+func Module() fx.Option {
+	return fx.Options(
+		fx.Provide(Providers...),
+		fx.Invoke(Invokes...),
+	)
+}
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.uber.org/fx"
+	"go.uber.org/zap/zapcore"
+)
+
+// LogExportConfig enables exporting log lines over OTLP in addition to stdout
+type LogExportConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Endpoint string `mapstructure:"endpoint" default:"localhost:4317"`
+	Insecure bool   `mapstructure:"insecure"`
+}
+
+// Prefix implements configx.Configurable
+func (LogExportConfig) Prefix() string {
+	return "log.otlp"
+}
+
+// serviceResource describes this service identically for every telemetry signal, so
+// logs, traces and metrics from one instance can be joined in the backend
+func serviceResource(serviceName string) *resource.Resource {
+	return resource.NewSchemaless(attribute.String("service.name", serviceName))
+}
+
+// LogExporter emits TraceLogger lines as OpenTelemetry log records. Records carry the
+// span context of the ctx they are emitted with, linking each line to its trace.
+type LogExporter struct {
+	logger otellog.Logger
+}
+
+// NewLogExporter creates the OTLP log pipeline when log.otlp.enabled is set
+func NewLogExporter(lc fx.Lifecycle, loader configx.Loader) (*LogExporter, error) {
+	var cfg LogExportConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load log export config: %w", err)
+	}
+	if !cfg.Enabled {
+		return &LogExporter{}, nil
+	}
+
+	var tracing SamplingConfig
+	if err := loader.Bind(&tracing); err != nil {
+		return nil, fmt.Errorf("failed to load tracing config: %w", err)
+	}
+
+	opts := []otlploggrpc.Option{otlploggrpc.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlploggrpc.WithInsecure())
+	}
+	exporter, err := otlploggrpc.New(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP log exporter: %w", err)
+	}
+
+	provider := sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
+		sdklog.WithResource(serviceResource(tracing.ServiceName)),
+	)
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return provider.Shutdown(ctx)
+		},
+	})
+
+	return &LogExporter{logger: provider.Logger("github.com/gostratum/examples/observability-demo")}, nil
+}
+
+// Emit exports one log line; it is a no-op when the pipeline is disabled
+func (e *LogExporter) Emit(ctx context.Context, severity otellog.Severity, msg string, fields []logx.Field) {
+	if e == nil || e.logger == nil {
+		return
+	}
+
+	var record otellog.Record
+	record.SetTimestamp(time.Now())
+	record.SetSeverity(severity)
+	record.SetSeverityText(severity.String())
+	record.SetBody(otellog.StringValue(msg))
+	record.AddAttributes(logAttributes(fields)...)
+
+	e.logger.Emit(ctx, record)
+}
+
+// logAttributes converts logx fields, which are zap fields, to log record attributes
+func logAttributes(fields []logx.Field) []otellog.KeyValue {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	attrs := make([]otellog.KeyValue, 0, len(enc.Fields))
+	for key, value := range enc.Fields {
+		switch v := value.(type) {
+		case string:
+			attrs = append(attrs, otellog.String(key, v))
+		case bool:
+			attrs = append(attrs, otellog.Bool(key, v))
+		case int:
+			attrs = append(attrs, otellog.Int(key, v))
+		case int64:
+			attrs = append(attrs, otellog.Int64(key, v))
+		case float64:
+			attrs = append(attrs, otellog.Float64(key, v))
+		default:
+			attrs = append(attrs, otellog.String(key, fmt.Sprint(v)))
+		}
+	}
+	return attrs
+}
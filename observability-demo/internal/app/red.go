@@ -0,0 +1,67 @@
+package app
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/metricsx"
+)
+
+// unmatchedRoute is the route label for requests that matched no registered route.
+// Using it instead of the raw URL keeps label cardinality bounded.
+const unmatchedRoute = "unmatched"
+
+// REDMetrics records Rate, Errors and Duration per route
+type REDMetrics struct {
+	requests metricsx.Counter
+	errors   metricsx.Counter
+	duration metricsx.Histogram
+}
+
+// NewREDMetrics registers the RED metrics with the metricsx provider
+func NewREDMetrics(metrics metricsx.Metrics) *REDMetrics {
+	return &REDMetrics{
+		requests: metrics.Counter(HTTPRouteRequests.Name,
+			metricsx.WithHelp(HTTPRouteRequests.Help),
+			metricsx.WithLabels(HTTPRouteRequests.Labels...),
+		),
+		errors: metrics.Counter(HTTPRouteErrors.Name,
+			metricsx.WithHelp(HTTPRouteErrors.Help),
+			metricsx.WithLabels(HTTPRouteErrors.Labels...),
+		),
+		duration: metrics.Histogram(HTTPRouteDuration.Name,
+			metricsx.WithHelp(HTTPRouteDuration.Help),
+			metricsx.WithLabels(HTTPRouteDuration.Labels...),
+			metricsx.WithBuckets(HTTPRouteDuration.Buckets...),
+		),
+	}
+}
+
+// Middleware records RED metrics labelled with the route template
+// (e.g. /api/v1/users/:id) rather than the raw request path
+func (m *REDMetrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := c.Request.Method
+		status := c.Writer.Status()
+
+		m.requests.Inc(method, route, strconv.Itoa(status))
+		if status >= 500 {
+			m.errors.Inc(method, route)
+		}
+		m.duration.Observe(time.Since(start).Seconds(), method, route)
+	}
+}
+
+// RegisterREDMetrics installs the RED middleware on the engine. It must be invoked
+// before any route group is created so every group inherits it.
+func RegisterREDMetrics(engine *gin.Engine, red *REDMetrics) {
+	engine.Use(red.Middleware())
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/dbx"
+	"github.com/gostratum/httpx/responsex"
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+)
+
+// analyticsConnection is the dbx connection reporting queries are routed to, keeping
+// heavy aggregations away from the primary that serves the API
+const analyticsConnection = "analytics"
+
+// SignupCount is the number of users created on one day
+type SignupCount struct {
+	Day   string `json:"day"`
+	Users int64  `json:"users"`
+}
+
+// ReportService runs reporting queries against the analytics connection
+type ReportService struct {
+	db     *gorm.DB
+	logger *TraceLogger
+}
+
+// NewReportService picks the analytics database out of the dbx connections
+func NewReportService(conns dbx.Connections, logger *TraceLogger) (*ReportService, error) {
+	db, ok := conns[analyticsConnection]
+	if !ok {
+		return nil, fmt.Errorf("%s database connection not found", analyticsConnection)
+	}
+	return &ReportService{db: db, logger: logger}, nil
+}
+
+// SignupsPerDay counts user signups per calendar day
+func (s *ReportService) SignupsPerDay(ctx context.Context) ([]SignupCount, error) {
+	ctx, span := tracer.Start(ctx, "ReportService.SignupsPerDay")
+	defer span.End()
+
+	var counts []SignupCount
+	err := s.db.WithContext(ctx).
+		Model(&User{}).
+		Select("DATE(created_at) AS day, COUNT(*) AS users").
+		Group("DATE(created_at)").
+		Order("day").
+		Scan(&counts).Error
+	if err != nil {
+		recordSpanError(span, err)
+		s.logger.Error(ctx, "failed to build signup report", logx.Err(err))
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("report.rows", len(counts)))
+	return counts, nil
+}
+
+// ReportHandler serves reporting endpoints
+type ReportHandler struct {
+	service *ReportService
+}
+
+func NewReportHandler(service *ReportService) *ReportHandler {
+	return &ReportHandler{service: service}
+}
+
+// Signups handles GET /api/v1/reports/signups
+func (h *ReportHandler) Signups(c *gin.Context) {
+	counts, err := h.service.SignupsPerDay(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+		return
+	}
+	responsex.OK(c, counts, nil)
+}
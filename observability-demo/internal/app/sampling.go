@@ -0,0 +1,138 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/fx"
+)
+
+// RouteSampling overrides the sampling ratio for one route
+type RouteSampling struct {
+	Ratio float64 `mapstructure:"ratio"`
+}
+
+// SamplingConfig reads the per-route sampling settings from the tracing section, e.g.
+//
+//	tracing:
+//	  sample_rate: 0.1
+//	  routes:
+//	    /health/live: {ratio: 0}
+//	    /api/v1/users/:id: {ratio: 1}
+type SamplingConfig struct {
+	Enabled     bool                     `mapstructure:"enabled"`
+	ServiceName string                   `mapstructure:"service_name"`
+	SampleRate  float64                  `mapstructure:"sample_rate" default:"1.0"`
+	Routes      map[string]RouteSampling `mapstructure:"routes"`
+	OTLP        struct {
+		Endpoint string `mapstructure:"endpoint"`
+		Insecure bool   `mapstructure:"insecure"`
+	} `mapstructure:"otlp"`
+}
+
+// Prefix implements configx.Configurable
+func (SamplingConfig) Prefix() string {
+	return "tracing"
+}
+
+// routeAttributes are the span attributes checked, in order, for the request route
+var routeAttributes = []attribute.Key{"http.route", "url.path", "http.target"}
+
+// RouteSampler samples root spans with a ratio chosen by route, falling back to a
+// default ratio. Child spans follow their parent's decision.
+type RouteSampler struct {
+	routes   map[string]sdktrace.Sampler
+	fallback sdktrace.Sampler
+	desc     string
+}
+
+// NewRouteSampler builds a sampler from the configured ratios
+func NewRouteSampler(defaultRatio float64, routes map[string]RouteSampling) sdktrace.Sampler {
+	rs := &RouteSampler{
+		routes:   make(map[string]sdktrace.Sampler, len(routes)),
+		fallback: sdktrace.TraceIDRatioBased(defaultRatio),
+	}
+
+	parts := make([]string, 0, len(routes))
+	for route, cfg := range routes {
+		rs.routes[route] = sdktrace.TraceIDRatioBased(cfg.Ratio)
+		parts = append(parts, fmt.Sprintf("%s=%g", route, cfg.Ratio))
+	}
+	sort.Strings(parts)
+	rs.desc = fmt.Sprintf("RouteSampler{default=%g,%s}", defaultRatio, strings.Join(parts, ","))
+
+	return sdktrace.ParentBased(rs)
+}
+
+// ShouldSample picks the sampler for the span's route
+func (s *RouteSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if sampler, ok := s.routes[spanRoute(p)]; ok {
+		return sampler.ShouldSample(p)
+	}
+	return s.fallback.ShouldSample(p)
+}
+
+func (s *RouteSampler) Description() string {
+	return s.desc
+}
+
+// spanRoute returns the route of a server span from its attributes, or from a span
+// name of the form "/route" or "GET /route"
+func spanRoute(p sdktrace.SamplingParameters) string {
+	for _, key := range routeAttributes {
+		for _, attr := range p.Attributes {
+			if attr.Key == key {
+				return attr.Value.AsString()
+			}
+		}
+	}
+	if _, route, ok := strings.Cut(p.Name, " "); ok {
+		return route
+	}
+	return p.Name
+}
+
+// InstallRouteSampler replaces the global tracer provider with one using RouteSampler
+// when per-route ratios are configured. Without routes the tracingx provider is kept.
+func InstallRouteSampler(lc fx.Lifecycle, loader configx.Loader, logger logx.Logger) error {
+	var cfg SamplingConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return fmt.Errorf("failed to load sampling config: %w", err)
+	}
+	if !cfg.Enabled || len(cfg.Routes) == 0 {
+		return nil
+	}
+
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.OTLP.Endpoint)}
+	if cfg.OTLP.Insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(context.Background(), opts...)
+	if err != nil {
+		return fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	sampler := NewRouteSampler(cfg.SampleRate, cfg.Routes)
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sampler),
+		sdktrace.WithResource(serviceResource(cfg.ServiceName)),
+	)
+	otel.SetTracerProvider(provider)
+	logger.Info("per-route trace sampling enabled", logx.String("sampler", sampler.Description()))
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return provider.Shutdown(ctx)
+		},
+	})
+	return nil
+}
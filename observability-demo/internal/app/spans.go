@@ -0,0 +1,113 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// tracer creates the domain spans of UserService. Automatic HTTP and GORM spans only
+// show that work happened; these add what the work meant.
+var tracer = otel.Tracer("github.com/gostratum/examples/observability-demo")
+
+// Retry settings for transient database errors
+const (
+	dbRetryAttempts = 3
+	dbRetryBackoff  = 50 * time.Millisecond
+)
+
+// transientErrorMarkers identify database errors worth retrying
+var transientErrorMarkers = []string{
+	"database is locked",
+	"deadlock detected",
+	"connection reset",
+	"bad connection",
+}
+
+// isTransient reports whether err is a database error that may succeed on retry
+func isTransient(err error) bool {
+	if err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, marker := range transientErrorMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// withDBRetry runs fn, retrying transient failures with a linear backoff.
+// Each retry is recorded as a db_retry event on the span in ctx.
+func withDBRetry(ctx context.Context, operation string, fn func() error) error {
+	span := trace.SpanFromContext(ctx)
+
+	var err error
+	for attempt := 1; attempt <= dbRetryAttempts; attempt++ {
+		if err = fn(); !isTransient(err) || attempt == dbRetryAttempts {
+			return err
+		}
+
+		span.AddEvent("db_retry", trace.WithAttributes(
+			attribute.String("db.operation", operation),
+			attribute.Int("retry.attempt", attempt),
+			attribute.String("error", err.Error()),
+		))
+
+		select {
+		case <-time.After(time.Duration(attempt) * dbRetryBackoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
+
+// recordSpanError marks the span as failed unless err is an expected not-found result
+func recordSpanError(span trace.Span, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		span.SetAttributes(attribute.Bool("user.found", false))
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// userCache is a small read-through cache for GetUser, there mainly to demonstrate
+// cache_hit/cache_miss span events
+type userCache struct {
+	mu    sync.RWMutex
+	users map[uint]User
+}
+
+func newUserCache() *userCache {
+	return &userCache{users: make(map[uint]User)}
+}
+
+func (c *userCache) Get(id uint) (User, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	user, ok := c.users[id]
+	return user, ok
+}
+
+func (c *userCache) Set(user User) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.users[user.ID] = user
+}
+
+func (c *userCache) Delete(id uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.users, id)
+}
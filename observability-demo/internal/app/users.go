@@ -0,0 +1,228 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// User model for demonstration
+type User struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email" gorm:"uniqueIndex"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ErrInvalidUser is returned when user input fails domain validation
+var ErrInvalidUser = errors.New("invalid user")
+
+// maxUserNameLength bounds the stored user name
+const maxUserNameLength = 100
+
+// UserService handles user operations
+type UserService struct {
+	db      *gorm.DB
+	logger  *TraceLogger
+	metrics *UserMetrics
+	cache   *userCache
+}
+
+func NewUserService(db *gorm.DB, logger *TraceLogger, metrics *UserMetrics) (*UserService, error) {
+	return &UserService{db: db, logger: logger, metrics: metrics, cache: newUserCache()}, nil
+}
+
+// validateUser normalizes name and email and records a validation_failed event on the
+// span when they are unusable
+func validateUser(span trace.Span, name, email string) (string, string, error) {
+	name = strings.TrimSpace(name)
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	var reason string
+	switch {
+	case name == "":
+		reason = "name is blank"
+	case len(name) > maxUserNameLength:
+		reason = "name is too long"
+	case !strings.Contains(email, "@"):
+		reason = "email is malformed"
+	}
+
+	if reason != "" {
+		span.AddEvent("validation_failed", trace.WithAttributes(attribute.String("validation.reason", reason)))
+		return "", "", fmt.Errorf("%w: %s", ErrInvalidUser, reason)
+	}
+	return name, email, nil
+}
+
+func (s *UserService) CreateUser(ctx context.Context, name, email string) (*User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.CreateUser")
+	defer span.End()
+
+	name, email, err := validateUser(span, name, email)
+	if err != nil {
+		s.metrics.created.Inc("invalid")
+		s.logger.Warn(ctx, "rejected user", logx.Err(err))
+		return nil, err
+	}
+
+	user := &User{
+		Name:  name,
+		Email: email,
+	}
+
+	if err := withDBRetry(ctx, "create", func() error {
+		return s.db.WithContext(ctx).Create(user).Error
+	}); err != nil {
+		recordSpanError(span, err)
+		s.metrics.created.Inc("error")
+		s.logger.Error(ctx, "failed to create user", logx.Err(err))
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("user.id", int(user.ID)))
+	s.metrics.created.Inc("success")
+	s.logger.Info(ctx, "user created", logx.Int("id", int(user.ID)), logx.String("email", email))
+	return user, nil
+}
+
+func (s *UserService) GetUser(ctx context.Context, id uint) (*User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.GetUser", trace.WithAttributes(attribute.Int("user.id", int(id))))
+	defer span.End()
+
+	start := time.Now()
+
+	if cached, ok := s.cache.Get(id); ok {
+		span.AddEvent("cache_hit")
+		s.metrics.lookupDuration.Observe(time.Since(start).Seconds(), "found")
+		return &cached, nil
+	}
+	span.AddEvent("cache_miss")
+
+	var user User
+	if err := withDBRetry(ctx, "query", func() error {
+		return s.db.WithContext(ctx).First(&user, id).Error
+	}); err != nil {
+		recordSpanError(span, err)
+		result := "error"
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			result = "not_found"
+		}
+		s.metrics.lookupDuration.Observe(time.Since(start).Seconds(), result)
+		s.logger.Error(ctx, "failed to get user", logx.Err(err), logx.Int("id", int(id)))
+		return nil, err
+	}
+	s.cache.Set(user)
+	s.metrics.lookupDuration.Observe(time.Since(start).Seconds(), "found")
+	return &user, nil
+}
+
+// Page size limits for ListUsers
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// Page selects a window of a listing. Page is 1-based.
+type Page struct {
+	Page int
+	Size int
+}
+
+// normalize applies the default size and caps it so a client cannot load the whole table
+func (p Page) normalize() Page {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.Size < 1 {
+		p.Size = defaultPageSize
+	}
+	if p.Size > maxPageSize {
+		p.Size = maxPageSize
+	}
+	return p
+}
+
+// ListUsers returns one page of users ordered by ID together with the total count
+func (s *UserService) ListUsers(ctx context.Context, page Page) ([]User, int64, error) {
+	page = page.normalize()
+	ctx, span := tracer.Start(ctx, "UserService.ListUsers", trace.WithAttributes(
+		attribute.Int("page.number", page.Page),
+		attribute.Int("page.size", page.Size),
+	))
+	defer span.End()
+
+	var total int64
+	var users []User
+	if err := withDBRetry(ctx, "query", func() error {
+		if err := s.db.WithContext(ctx).Model(&User{}).Count(&total).Error; err != nil {
+			return err
+		}
+		return s.db.WithContext(ctx).
+			Order("id").
+			Limit(page.Size).
+			Offset((page.Page - 1) * page.Size).
+			Find(&users).Error
+	}); err != nil {
+		recordSpanError(span, err)
+		s.logger.Error(ctx, "failed to list users", logx.Err(err))
+		return nil, 0, err
+	}
+	span.SetAttributes(attribute.Int("users.count", len(users)), attribute.Int64("users.total", total))
+	s.metrics.listSize.Observe(float64(len(users)))
+	return users, total, nil
+}
+
+func (s *UserService) UpdateUser(ctx context.Context, id uint, name, email string) (*User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.UpdateUser", trace.WithAttributes(attribute.Int("user.id", int(id))))
+	defer span.End()
+
+	name, email, err := validateUser(span, name, email)
+	if err != nil {
+		s.logger.Warn(ctx, "rejected user update", logx.Err(err), logx.Int("id", int(id)))
+		return nil, err
+	}
+
+	var user User
+	if err := s.db.WithContext(ctx).First(&user, id).Error; err != nil {
+		recordSpanError(span, err)
+		return nil, err
+	}
+
+	user.Name = name
+	user.Email = email
+
+	if err := withDBRetry(ctx, "update", func() error {
+		return s.db.WithContext(ctx).Save(&user).Error
+	}); err != nil {
+		recordSpanError(span, err)
+		s.logger.Error(ctx, "failed to update user", logx.Err(err), logx.Int("id", int(id)))
+		return nil, err
+	}
+	s.cache.Delete(id)
+	s.logger.Info(ctx, "user updated", logx.Int("id", int(id)))
+	return &user, nil
+}
+
+func (s *UserService) DeleteUser(ctx context.Context, id uint) error {
+	ctx, span := tracer.Start(ctx, "UserService.DeleteUser", trace.WithAttributes(attribute.Int("user.id", int(id))))
+	defer span.End()
+
+	if err := withDBRetry(ctx, "delete", func() error {
+		return s.db.WithContext(ctx).Delete(&User{}, id).Error
+	}); err != nil {
+		recordSpanError(span, err)
+		s.logger.Error(ctx, "failed to delete user", logx.Err(err), logx.Int("id", int(id)))
+		return err
+	}
+	s.cache.Delete(id)
+	s.logger.Info(ctx, "user deleted", logx.Int("id", int(id)))
+	return nil
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// breakerState is the state of a circuit breaker
+type breakerState int
+
+const (
+	// closed lets every request through
+	closed breakerState = iota
+	// open refuses every request until the open timeout has passed
+	open
+	// halfOpen lets one trial request through, whose outcome closes or opens
+	// the breaker again
+	halfOpen
+)
+
+// breaker stops requests to an API that keeps failing, so the client fails fast
+// instead of piling retries on a service that cannot answer them
+type breaker struct {
+	threshold int
+	timeout   time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+func newBreaker(threshold int, timeout time.Duration, now func() time.Time) *breaker {
+	return &breaker{threshold: threshold, timeout: timeout, now: now}
+}
+
+// allow reports whether a request may be sent. Once the open timeout has passed,
+// it lets a single trial through until its outcome is recorded.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case open:
+		if b.now().Sub(b.openedAt) < b.timeout {
+			return false
+		}
+		b.state, b.trial = halfOpen, true
+		return true
+	case halfOpen:
+		if b.trial {
+			return false
+		}
+		b.trial = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record counts the outcome of a request allow let through
+func (b *breaker) record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if success {
+		b.state, b.failures, b.trial = closed, 0, false
+		return
+	}
+	b.failures++
+	if b.state == halfOpen || b.failures >= b.threshold {
+		b.state, b.openedAt, b.trial = open, b.now(), false
+	}
+}
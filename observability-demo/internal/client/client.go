@@ -0,0 +1,206 @@
+// Package client calls the demo's API the way a client SDK should when the API
+// misbehaves: it retries failed requests with exponential backoff, waits as long
+// as a Retry-After asks, and stops calling through a circuit breaker once the API
+// keeps failing. The tests run it against the chaos middleware.
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the API while the breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Config tunes retries and the breaker. Zero fields take the defaults of DefaultConfig.
+type Config struct {
+	// MaxAttempts is how many times a request is sent at most, the first included
+	MaxAttempts int
+	// BaseBackoff is the wait before the first retry; it doubles with each one
+	BaseBackoff time.Duration
+	// MaxBackoff caps the doubled wait. A longer Retry-After is still honoured.
+	MaxBackoff time.Duration
+	// FailureThreshold is how many failed attempts in a row open the breaker
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before it lets one trial
+	// request through
+	OpenTimeout time.Duration
+}
+
+// DefaultConfig returns the settings a Config without them gets
+func DefaultConfig() Config {
+	return Config{
+		MaxAttempts:      3,
+		BaseBackoff:      100 * time.Millisecond,
+		MaxBackoff:       2 * time.Second,
+		FailureThreshold: 5,
+		OpenTimeout:      10 * time.Second,
+	}
+}
+
+// Response is an answer of the API, read in full
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Client sends requests to one base URL
+type Client struct {
+	baseURL string
+	http    *http.Client
+	cfg     Config
+	breaker *breaker
+
+	// sleep waits between attempts; the tests replace it to record the waits
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// New creates a Client for baseURL that sends its requests with httpClient
+func New(baseURL string, httpClient *http.Client, cfg Config) *Client {
+	def := DefaultConfig()
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = def.MaxAttempts
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = def.BaseBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = def.MaxBackoff
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = def.FailureThreshold
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = def.OpenTimeout
+	}
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    httpClient,
+		cfg:     cfg,
+		breaker: newBreaker(cfg.FailureThreshold, cfg.OpenTimeout, time.Now),
+		sleep:   sleep,
+	}
+}
+
+// Get sends a GET request for path
+func (c *Client) Get(ctx context.Context, path string) (*Response, error) {
+	return c.Do(ctx, http.MethodGet, path, nil)
+}
+
+// Do sends a request with body, which may be nil. A request that fails with a
+// connection error or a retryable status is sent again, when its method is
+// idempotent, until it succeeds or MaxAttempts is reached; Do then returns the
+// last response, or the last error. It returns ErrCircuitOpen without sending
+// the request while the breaker is open, including when it opens between
+// attempts.
+func (c *Client) Do(ctx context.Context, method, path string, body []byte) (*Response, error) {
+	attempts := 1
+	if idempotent(method) {
+		attempts = c.cfg.MaxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		if !c.breaker.allow() {
+			return nil, ErrCircuitOpen
+		}
+		resp, err := c.send(ctx, method, path, body)
+		failed := err != nil || retryableStatus(resp.StatusCode)
+		c.breaker.record(!failed)
+		if !failed || attempt == attempts || ctx.Err() != nil {
+			return resp, err
+		}
+
+		wait := c.backoff(attempt)
+		if resp != nil {
+			wait = max(wait, retryAfter(resp.Header))
+		}
+		if err := c.sleep(ctx, wait); err != nil {
+			return resp, err
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, path string, body []byte) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return &Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: data}, nil
+}
+
+// backoff returns the wait after attempt: BaseBackoff doubled for each attempt
+// before it, capped at MaxBackoff, of which a random half is taken away so
+// clients failed at once do not retry at once
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.cfg.MaxBackoff
+	if attempt < 32 {
+		d = min(c.cfg.BaseBackoff<<(attempt-1), c.cfg.MaxBackoff)
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// idempotent reports whether a request with method can be sent twice without
+// doing twice what it does
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryableStatus reports whether a response with status may succeed when the
+// request is sent again: the server failed, or asked to be called later
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryAfter returns the wait a Retry-After header in seconds asks for, or 0
+func retryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,233 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/examples/observability-demo/internal/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chaosLoader binds the chaos section of a test to a fixed configuration
+type chaosLoader struct {
+	configx.Loader
+	cfg app.ChaosConfig
+}
+
+func (l chaosLoader) Bind(v any) error {
+	*v.(*app.ChaosConfig) = l.cfg
+	return nil
+}
+
+// chaosAPI is a server with the chaos middleware in front of GET /ping. It counts
+// the requests sent to it, those the chaos middleware failed included.
+type chaosAPI struct {
+	url   string
+	chaos *app.Chaos
+	sent  atomic.Int64
+}
+
+func newChaosAPI(t *testing.T, cfg app.ChaosConfig) *chaosAPI {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg.Enabled = true
+	cfg.ErrorStatus = http.StatusServiceUnavailable
+
+	chaos, err := app.NewChaos(chaosLoader{cfg: cfg}, app.NewTraceLogger(logx.NewNoopLogger(), &app.LogSampler{}, nil))
+	require.NoError(t, err)
+
+	api := &chaosAPI{chaos: chaos}
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		api.sent.Add(1)
+		c.Next()
+	})
+	app.RegisterChaos(engine, chaos)
+	engine.GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, "pong")
+	})
+
+	srv := httptest.NewServer(engine)
+	t.Cleanup(srv.Close)
+	api.url = srv.URL
+	return api
+}
+
+// setChaos replaces the faults the API injects
+func (a *chaosAPI) setChaos(t *testing.T, cfg app.ChaosConfig) {
+	t.Helper()
+	cfg.ErrorStatus = http.StatusServiceUnavailable
+	require.NoError(t, a.chaos.SetConfig(cfg))
+}
+
+// fakeClock is the breaker's clock, moved by the test
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newTestClient creates a Client for api that records its waits instead of
+// sleeping, with its breaker on clock
+func newTestClient(api *chaosAPI, cfg Config, clock *fakeClock) (*Client, *[]time.Duration) {
+	c := New(api.url, &http.Client{Timeout: 5 * time.Second}, cfg)
+	c.breaker.now = clock.Now
+	waits := &[]time.Duration{}
+	c.sleep = func(_ context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return nil
+	}
+	return c, waits
+}
+
+func TestClient_RetriesThroughErrorsAndResets(t *testing.T) {
+	api := newChaosAPI(t, app.ChaosConfig{ErrorRate: 0.3, ResetRate: 0.3})
+	// Enough attempts, and a breaker lenient enough, that a run of bad luck does
+	// not fail a request: one fails 0.51 of the time, so 30 in a row almost never
+	c, _ := newTestClient(api, Config{MaxAttempts: 30, FailureThreshold: 1000}, &fakeClock{now: time.Now()})
+
+	const requests = 50
+	for i := 0; i < requests; i++ {
+		resp, err := c.Get(context.Background(), "/ping")
+		require.NoError(t, err, "request %d", i)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "pong", string(resp.Body))
+	}
+
+	// Half the requests fail, so the retries have sent many more than asked for
+	assert.Greater(t, api.sent.Load(), int64(requests))
+}
+
+func TestClient_GivesUpAfterMaxAttempts(t *testing.T) {
+	api := newChaosAPI(t, app.ChaosConfig{ErrorRate: 1})
+	c, _ := newTestClient(api, Config{MaxAttempts: 4, FailureThreshold: 1000}, &fakeClock{now: time.Now()})
+
+	resp, err := c.Get(context.Background(), "/ping")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, "the last response is returned")
+	assert.Equal(t, int64(4), api.sent.Load())
+}
+
+func TestClient_DoesNotRetryPost(t *testing.T) {
+	api := newChaosAPI(t, app.ChaosConfig{ErrorRate: 1})
+	c, waits := newTestClient(api, Config{MaxAttempts: 4, FailureThreshold: 1000}, &fakeClock{now: time.Now()})
+
+	resp, err := c.Do(context.Background(), http.MethodPost, "/ping", []byte(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int64(1), api.sent.Load(), "a POST may have been applied, so it is sent once")
+	assert.Empty(t, *waits)
+}
+
+func TestClient_BacksOffExponentiallyAndHonoursRetryAfter(t *testing.T) {
+	api := newChaosAPI(t, app.ChaosConfig{ResetRate: 1})
+	cfg := Config{MaxAttempts: 5, BaseBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond, FailureThreshold: 1000}
+	c, waits := newTestClient(api, cfg, &fakeClock{now: time.Now()})
+
+	_, err := c.Get(context.Background(), "/ping")
+	require.Error(t, err, "every connection is reset")
+
+	// Each wait is between half and all of 100ms, 200ms, then the 300ms cap
+	require.Len(t, *waits, 4)
+	for i, ceiling := range []time.Duration{100, 200, 300, 300} {
+		ceiling *= time.Millisecond
+		assert.GreaterOrEqual(t, (*waits)[i], ceiling/2, "wait %d", i)
+		assert.LessOrEqual(t, (*waits)[i], ceiling, "wait %d", i)
+	}
+
+	// The 503 of the chaos middleware asks for a second, longer than any backoff
+	api.setChaos(t, app.ChaosConfig{Enabled: true, ErrorRate: 1})
+	*waits = nil
+	_, err = c.Get(context.Background(), "/ping")
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{time.Second, time.Second, time.Second, time.Second}, *waits)
+}
+
+func TestClient_BreakerOpensAndFailsFast(t *testing.T) {
+	api := newChaosAPI(t, app.ChaosConfig{ErrorRate: 1})
+	c, _ := newTestClient(api, Config{MaxAttempts: 2, FailureThreshold: 3, OpenTimeout: 10 * time.Second}, &fakeClock{now: time.Now()})
+
+	// The first request fails twice, the second once more and opens the breaker
+	// before its retry
+	_, err := c.Get(context.Background(), "/ping")
+	require.NoError(t, err)
+	_, err = c.Get(context.Background(), "/ping")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int64(3), api.sent.Load())
+
+	for i := 0; i < 10; i++ {
+		_, err := c.Get(context.Background(), "/ping")
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+	}
+	assert.Equal(t, int64(3), api.sent.Load(), "no request is sent while the breaker is open")
+}
+
+func TestClient_BreakerRecoversThroughHalfOpen(t *testing.T) {
+	api := newChaosAPI(t, app.ChaosConfig{ErrorRate: 1})
+	clock := &fakeClock{now: time.Now()}
+	c, _ := newTestClient(api, Config{MaxAttempts: 1, FailureThreshold: 2, OpenTimeout: 10 * time.Second}, clock)
+
+	for i := 0; i < 2; i++ {
+		_, err := c.Get(context.Background(), "/ping")
+		require.NoError(t, err)
+	}
+	_, err := c.Get(context.Background(), "/ping")
+	require.ErrorIs(t, err, ErrCircuitOpen)
+
+	// A trial that fails opens the breaker for another timeout
+	clock.Advance(10 * time.Second)
+	resp, err := c.Get(context.Background(), "/ping")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	_, err = c.Get(context.Background(), "/ping")
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int64(3), api.sent.Load())
+
+	// Once the API has recovered, the next trial closes it
+	api.setChaos(t, app.ChaosConfig{})
+	clock.Advance(5 * time.Second)
+	_, err = c.Get(context.Background(), "/ping")
+	require.ErrorIs(t, err, ErrCircuitOpen, "the timeout restarted with the failed trial")
+
+	clock.Advance(5 * time.Second)
+	for i := 0; i < 5; i++ {
+		resp, err := c.Get(context.Background(), "/ping")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	assert.Equal(t, int64(8), api.sent.Load())
+}
+
+func TestClient_StopsWaitingWhenContextIsDone(t *testing.T) {
+	api := newChaosAPI(t, app.ChaosConfig{ErrorRate: 1})
+	c := New(api.url, http.DefaultClient, Config{MaxAttempts: 5, FailureThreshold: 1000})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := c.Get(ctx, "/ping")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "got %v", err)
+	assert.Less(t, time.Since(start), time.Second, "the Retry-After wait is cut short")
+	assert.Equal(t, int64(1), api.sent.Load())
+}
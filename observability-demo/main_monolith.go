@@ -4,32 +4,38 @@
 package main
 
 import (
-	"github.com/gostratum/core/logx"
+	"github.com/gostratum/core"
 	"github.com/gostratum/dbx"
 	"github.com/gostratum/httpx"
 	"github.com/gostratum/metricsx"
 	"github.com/gostratum/tracingx"
 	"go.uber.org/fx"
+
+	"github.com/gostratum/examples/observability-demo/internal/app"
+	"github.com/gostratum/examples/redact"
 )
 
 // Monolithic main selected with build tag `monolith`.
 // Run: `go run -tags=monolith .`
 func main() {
-	// Monolithic composition: keep wiring in one place (same as original but presented as a single unit)
-	app := fx.New(
-		logx.Module(),
+	// Monolithic composition: the same components as app.Module(), listed in one place
+	application := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// Observability modules (opt-in)
 		metricsx.Module(),
 		tracingx.Module(),
-		dbx.Module(),
+		app.LifecycleMetrics(),
+
+		// Infrastructure modules
 		httpx.Module(),
+		dbx.Module(),
 
-		fx.Provide(
-			NewUserService,
-			NewUserHandler,
-		),
-		fx.Invoke(RegisterRoutes),
-		fx.Invoke(SetupDatabase),
+		// Application wiring, spelled out instead of using app.Module()
+		fx.Provide(app.Providers...),
+		fx.Invoke(app.Invokes...),
 	)
 
-	app.Run()
+	application.Run()
 }
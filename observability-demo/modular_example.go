@@ -1,3 +0,0 @@
-package main
-
-// stub: left intentionally empty so the package builds without redeclaring symbols.
@@ -0,0 +1,66 @@
+package orderservice_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/gostratum/examples/orderservice/internal/testutil"
+)
+
+// BenchmarkEndToEnd_CreateOrder measures POST /orders over a real connection
+// to the whole application: middleware, handler, service and PostgreSQL
+func BenchmarkEndToEnd_CreateOrder(b *testing.B) {
+	ta := testutil.NewTestApp(b)
+
+	user, err := json.Marshal(map[string]any{"name": "Bench User", "email": "bench@example.com"})
+	if err != nil {
+		b.Fatal(err)
+	}
+	userID := postID(b, ta.BaseURL+"/users", user)
+
+	for _, n := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("items=%d", n), func(b *testing.B) {
+			items := make([]map[string]any, n)
+			for i := range items {
+				items[i] = map[string]any{"sku": fmt.Sprintf("SKU-%04d", i), "qty": 1 + i%5, "price": 9.99}
+			}
+			body, err := json.Marshal(map[string]any{"user_id": userID, "items": items})
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ReportAllocs()
+			for b.Loop() {
+				postID(b, ta.BaseURL+"/orders", body)
+			}
+		})
+	}
+}
+
+// postID posts body and returns the id of what it created
+func postID(b *testing.B, url string, body []byte) string {
+	b.Helper()
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		data, _ := io.ReadAll(resp.Body)
+		b.Fatalf("status %d: %s", resp.StatusCode, data)
+	}
+
+	var envelope struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		b.Fatal(err)
+	}
+	return envelope.Data.ID
+}
@@ -0,0 +1,42 @@
+//go:build debug
+// +build debug
+
+package main
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/examples/orderservice/configs"
+	"github.com/gostratum/examples/orderservice/internal/adapter/clamav"
+	"github.com/gostratum/examples/orderservice/internal/adapter/dbsecret"
+	"github.com/gostratum/examples/orderservice/internal/adapter/health"
+	"github.com/gostratum/examples/orderservice/internal/adapter/inventory"
+	"github.com/gostratum/examples/orderservice/internal/adapter/payment"
+	"github.com/gostratum/examples/orderservice/internal/adapter/repo"
+	"github.com/gostratum/examples/orderservice/internal/audit"
+	"github.com/gostratum/examples/orderservice/internal/buckets"
+	"github.com/gostratum/examples/orderservice/internal/debug"
+	"github.com/gostratum/examples/orderservice/internal/uploads"
+	"github.com/gostratum/examples/workkit/workqueue"
+)
+
+// debugModule serves the debug endpoints, dumping every config section the
+// service reads. Built with `-tags debug` only: `make run-debug`.
+func debugModule() fx.Option {
+	return debug.Module(
+		configs.App{},
+		configs.HTTP{},
+		configs.Database{},
+		dbsecret.Config{},
+		repo.FailoverConfig{},
+		configs.Storage{},
+		buckets.Config{},
+		health.StorageConfig{},
+		uploads.Config{},
+		clamav.Config{},
+		workqueue.Config{},
+		inventory.Config{},
+		payment.Config{},
+		audit.Config{},
+	)
+}
@@ -1,50 +1,109 @@
 package main
 
 import (
-	"go.uber.org/fx"
+	"context"
+	"log"
+	"time"
 
 	"github.com/gostratum/core"
+	"github.com/gostratum/core/configx"
 	"github.com/gostratum/dbx"
-	httpAdapter "github.com/gostratum/examples/orderservice/internal/adapter/http"
-	repoAdapter "github.com/gostratum/examples/orderservice/internal/adapter/repo"
-	"github.com/gostratum/examples/orderservice/internal/usecase"
+	"github.com/gostratum/examples/orderservice/configs"
+	"github.com/gostratum/examples/orderservice/internal/adapter/configkey"
+	dbsecretAdapter "github.com/gostratum/examples/orderservice/internal/adapter/dbsecret"
+	"github.com/gostratum/examples/orderservice/internal/adapter/localstorage"
+	"github.com/gostratum/examples/orderservice/internal/app"
+	"github.com/gostratum/examples/orderservice/internal/buildinfo"
+	"github.com/gostratum/examples/redact"
 	"github.com/gostratum/httpx"
-	"github.com/gostratum/storagex"
-	s3Adapter "github.com/gostratum/storagex/adapters/s3"
+	"github.com/gostratum/metricsx"
+	"go.uber.org/fx"
 )
 
 func main() {
-	app := core.New(
+	// Traces and metrics exported through OpenTelemetry carry the build
+	if err := buildinfo.SetResourceAttributes(); err != nil {
+		log.Fatalf("Failed to set resource attributes: %v", err)
+	}
+
+	// The overlay of APP_ENV's profile and local.yaml go over base.yaml
+	profile, err := configs.ApplyProfile("./configs")
+	if err != nil {
+		log.Fatalf("Failed to apply configuration profile: %v", err)
+	}
+
+	// Decrypt the enc: values, such as staging's DSN password, with the key of
+	// CONFIG_KEY or CONFIG_KEY_KMS
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	key, err := configkey.Load(ctx)
+	if err != nil {
+		log.Fatalf("Failed to load configuration key: %v", err)
+	}
+	if err := configs.DecryptValues(key, "./configs"); err != nil {
+		log.Fatalf("Failed to decrypt configuration: %v", err)
+	}
+	cancel()
+	loader := configx.New(configx.WithConfigPaths("./configs"))
+
+	// Resolve the DSN from AWS, when a secret is configured, before dbx binds its config.
+	// Without one, the DSN of the configuration is read again on SIGHUP.
+	ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
+	secret, err := dbsecretAdapter.New(ctx, loader)
+	if err != nil {
+		log.Fatalf("Failed to configure database secret: %v", err)
+	}
+	if secret != nil {
+		if err := secret.SetEnv(ctx); err != nil {
+			log.Fatalf("Failed to resolve database secret: %v", err)
+		}
+	} else {
+		secret, err = dbsecretAdapter.FromConfig(loader, func() configx.Loader {
+			return configx.New(configx.WithConfigPaths("./configs"))
+		})
+		if err != nil {
+			log.Fatalf("Failed to configure database DSN: %v", err)
+		}
+	}
+	cancel()
+
+	// Stop here, naming every key to fix, rather than fail requests later
+	cfg, err := configs.Load(loader)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	application := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// Move the connections to new credentials when the database secret, or
+		// the DSN of the configuration, changes; read at once on SIGHUP
+		dbsecretAdapter.Module(secret),
+
 		// Include dbx module without auto-migration (run migrations separately)
 		dbx.Module(),
 
 		// Include httpx module
 		httpx.Module(),
 
-		// Include storagex module with S3 adapter
-		storagex.Module(),
-		s3Adapter.Module(),
-
-		// Provide dependencies
-		fx.Provide(
-			// GORM repositories
-			repoAdapter.NewUserRepo,
-			repoAdapter.NewOrderRepo,
-
-			// Usecase services
-			usecase.NewUserService,
-			usecase.NewOrderService,
-
-			// HTTP handlers
-			httpAdapter.NewUserHandler,
-			httpAdapter.NewOrderHandler,
-		),
-
-		// Invoke setup functions
-		fx.Invoke(
-			httpAdapter.RegisterRoutes,
-		),
+		// Prometheus metrics for the worker pool that makes avatar thumbnails
+		metricsx.Module(),
+
+		// Include storagex module with S3 adapter, or the local disk when
+		// storagex.provider is "local"
+		localstorage.Module(cfg.Storage.Local()),
+
+		// Repositories, clients, services, routes and the worker pool
+		app.Module(),
+
+		// Log the profile and the build, which /version reports too
+		fx.Supply(profile),
+		fx.Invoke(configs.LogProfile, buildinfo.Log),
+
+		// Config dump, goroutines and the dependency graph under /debug, in
+		// builds with the debug tag alone
+		debugModule(),
 	)
 
-	app.Run()
+	application.Run()
 }
@@ -11,14 +11,20 @@ import (
 	"go.uber.org/fx"
 
 	httpAdapter "github.com/gostratum/examples/orderservice/internal/adapter/http"
+	inventoryAdapter "github.com/gostratum/examples/orderservice/internal/adapter/inventory"
+	paymentAdapter "github.com/gostratum/examples/orderservice/internal/adapter/payment"
 	repoAdapter "github.com/gostratum/examples/orderservice/internal/adapter/repo"
 	"github.com/gostratum/examples/orderservice/internal/usecase"
+	"github.com/gostratum/examples/redact"
 )
 
 // Monolithic main selected with build tag `monolith`.
 // Run: `go run -tags=monolith .` from the cmd/api directory
 func main() {
 	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
 		// Include dbx module without auto-migration (run migrations separately)
 		dbx.Module(
 			dbx.WithDefault("primary"),
@@ -37,6 +43,10 @@ func main() {
 			repoAdapter.NewUserRepo,
 			repoAdapter.NewOrderRepo,
 
+			// inventoryservice and paymentservice clients
+			inventoryAdapter.NewClient,
+			paymentAdapter.NewClient,
+
 			// Usecase services
 			usecase.NewUserService,
 			usecase.NewOrderService,
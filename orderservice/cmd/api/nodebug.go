@@ -0,0 +1,12 @@
+//go:build !debug
+// +build !debug
+
+package main
+
+import "go.uber.org/fx"
+
+// debugModule adds nothing: the debug endpoints are left out of builds
+// without the debug tag
+func debugModule() fx.Option {
+	return fx.Options()
+}
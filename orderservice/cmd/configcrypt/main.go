@@ -0,0 +1,63 @@
+// Command configcrypt makes the key that encrypts values of the
+// configuration, and encrypts or decrypts a value with the key in CONFIG_KEY
+// or CONFIG_KEY_KMS. The value is read from standard input, so it stays out of
+// the shell's history:
+//
+//	configcrypt -genkey
+//	printf '%s' 's3cr3t' | CONFIG_KEY=... configcrypt
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gostratum/examples/orderservice/configs"
+	"github.com/gostratum/examples/orderservice/internal/adapter/configkey"
+)
+
+func main() {
+	genKey := flag.Bool("genkey", false, "Print a new random key, for CONFIG_KEY")
+	decrypt := flag.Bool("decrypt", false, "Decrypt the value instead of encrypting it")
+	flag.Parse()
+
+	if *genKey {
+		key, err := configs.GenerateKey()
+		if err != nil {
+			log.Fatalf("Failed to generate a key: %v", err)
+		}
+		fmt.Println(key)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	c, err := configkey.Load(ctx)
+	if err != nil {
+		log.Fatalf("Failed to load the key: %v", err)
+	}
+	if c == nil {
+		log.Fatalf("Set %s or %s; configcrypt -genkey makes a key", configs.KeyEnv, configs.KeyKMSEnv)
+	}
+
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		log.Fatalf("Failed to read the value: %v", err)
+	}
+	value := strings.TrimRight(string(input), "\r\n")
+
+	if *decrypt {
+		value, err = c.Decrypt(value)
+	} else {
+		value, err = c.Encrypt(value)
+	}
+	if err != nil {
+		log.Fatalf("Failed: %v", err)
+	}
+	fmt.Println(value)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// statusError is a response with a status other than the one expected
+type statusError struct {
+	got, want int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("status %d, want %d", e.got, e.want)
+}
+
+// client calls orderservice's HTTP API
+type client struct {
+	baseURL string
+	http    *http.Client
+}
+
+// envelope is the part of the responsex envelope the scenarios read
+type envelope struct {
+	Data struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
+
+// do sends body as JSON, unless nil, and returns the id in the response data.
+// A status other than want is a *statusError.
+func (c *client) do(ctx context.Context, method, path string, body any, want int) (string, error) {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return "", err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return "", err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != want {
+		// Drain the body so the connection is reused
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return "", &statusError{got: resp.StatusCode, want: want}
+	}
+
+	var env envelope
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	return env.Data.ID, nil
+}
@@ -0,0 +1,92 @@
+// Command loadtest sends a mix of requests to a running orderservice from
+// concurrent workers, and reports the latency percentiles and error rate of
+// each scenario, so the effect of a change to the handlers or repositories
+// can be measured.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// exitThresholds is the exit code when the load ran but breached -max-error-rate or -max-p99
+const exitThresholds = 3
+
+func main() {
+	var opts options
+	var mixText string
+	var jsonOut bool
+	var maxErrorRate float64
+	var maxP99 time.Duration
+	flag.StringVar(&opts.BaseURL, "url", "http://localhost:8080", "orderservice base URL")
+	flag.IntVar(&opts.Concurrency, "concurrency", 10, "Number of workers sending requests")
+	flag.DurationVar(&opts.Duration, "duration", 30*time.Second, "How long to send requests (0 = until -requests or Ctrl+C)")
+	flag.IntVar(&opts.Requests, "requests", 0, "Stop after this many requests (0 = no limit)")
+	flag.IntVar(&opts.Rate, "rate", 0, "Requests per second across all workers (0 = as fast as the workers go)")
+	flag.StringVar(&mixText, "mix", defaultMix, "Scenarios and their weights: "+strings.Join(scenarioNames(), ", "))
+	flag.DurationVar(&opts.Timeout, "timeout", 5*time.Second, "Timeout of one request")
+	flag.IntVar(&opts.SeedUsers, "seed-users", 20, "Users to create before the load starts")
+	flag.IntVar(&opts.SeedOrders, "seed-orders", 20, "Orders to create before the load starts, when the mix reads orders")
+	flag.BoolVar(&jsonOut, "json", false, "Print the report as JSON")
+	flag.Float64Var(&maxErrorRate, "max-error-rate", -1, "Exit with 3 when the error rate is above this, e.g. 0.01 (-1 = not checked)")
+	flag.DurationVar(&maxP99, "max-p99", 0, "Exit with 3 when the p99 latency of all requests is above this (0 = not checked)")
+	flag.Parse()
+
+	m, err := parseMix(mixText)
+	if err != nil {
+		log.Fatalf("Invalid -mix: %v", err)
+	}
+	opts.Mix = m
+	if opts.Concurrency < 1 {
+		log.Fatalf("Invalid -concurrency: must be at least 1")
+	}
+	if opts.Duration == 0 && opts.Requests == 0 {
+		log.Printf("No -duration or -requests given; sending requests until Ctrl+C")
+	}
+
+	// Ctrl+C ends the load early; the report covers what ran until then
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	lt := newLoadTest(opts)
+	if err := lt.seed(ctx); err != nil {
+		log.Fatalf("Failed to seed %s: %v", opts.BaseURL, err)
+	}
+	log.Printf("Seeded %d users and %d orders; sending requests to %s", lt.users.len(), lt.orders.len(), opts.BaseURL)
+
+	elapsed := lt.start(ctx)
+	r := lt.report(elapsed, mixText)
+	if jsonOut {
+		err = r.writeJSON(os.Stdout)
+	} else {
+		err = r.writeText(os.Stdout)
+	}
+	if err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+
+	if err := checkThresholds(r.Total, maxErrorRate, maxP99); err != nil {
+		log.Printf("%v", err)
+		os.Exit(exitThresholds)
+	}
+}
+
+// checkThresholds returns an error naming each threshold total breached
+func checkThresholds(total summary, maxErrorRate float64, maxP99 time.Duration) error {
+	var errs []error
+	if maxErrorRate >= 0 && total.ErrorRate > maxErrorRate {
+		errs = append(errs, fmt.Errorf("error rate %.2f%% is above %.2f%%", 100*total.ErrorRate, 100*maxErrorRate))
+	}
+	if maxP99 > 0 && total.P99 > maxP99 {
+		errs = append(errs, fmt.Errorf("p99 latency %s is above %s", ms(total.P99), ms(maxP99)))
+	}
+	return errors.Join(errs...)
+}
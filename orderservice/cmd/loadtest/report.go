@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// report is the outcome of a load test
+type report struct {
+	Elapsed     time.Duration `json:"elapsed_ns"`
+	Concurrency int           `json:"concurrency"`
+	Mix         string        `json:"mix"`
+	Scenarios   []summary     `json:"scenarios"`
+	Total       summary       `json:"total"`
+}
+
+func (lt *loadTest) report(elapsed time.Duration, mixText string) report {
+	scenarios, total := lt.recorder.summaries(elapsed)
+	return report{
+		Elapsed:     elapsed,
+		Concurrency: lt.opts.Concurrency,
+		Mix:         mixText,
+		Scenarios:   scenarios,
+		Total:       total,
+	}
+}
+
+// writeJSON writes the report as JSON, for comparing runs with a script
+func (r report) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// writeText writes the report as a table, one row per scenario, followed by
+// the errors by kind
+func (r report) writeText(w io.Writer) error {
+	fmt.Fprintf(w, "Ran %s with %d workers, mix %s\n\n", r.Elapsed.Round(time.Millisecond), r.Concurrency, r.Mix)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "scenario\trequests\terrors\terror %\tskipped\treq/s\tmean\tp50\tp90\tp95\tp99\tmax\t")
+	for _, s := range append(r.Scenarios, r.Total) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.2f\t%d\t%.1f\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			s.Scenario, s.Requests, s.Errors, 100*s.ErrorRate, s.Skipped, s.RPS,
+			ms(s.Mean), ms(s.P50), ms(s.P90), ms(s.P95), ms(s.P99), ms(s.Max))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if r.Total.Errors == 0 {
+		return nil
+	}
+	fmt.Fprintln(w, "\nErrors:")
+	for _, s := range r.Scenarios {
+		kinds := make([]string, 0, len(s.ByKind))
+		for kind := range s.ByKind {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+		for _, kind := range kinds {
+			fmt.Fprintf(w, "  %-14s %-12s %d\n", s.Scenario, kind, s.ByKind[kind])
+		}
+	}
+	return nil
+}
+
+// ms formats d in milliseconds, e.g. 12.4ms
+func ms(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
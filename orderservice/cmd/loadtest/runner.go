@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// options configure a load test
+type options struct {
+	BaseURL     string
+	Concurrency int
+	// Duration ends the load after that long; 0 for no limit
+	Duration time.Duration
+	// Requests ends the load after that many requests; 0 for no limit
+	Requests int
+	// Rate caps the requests per second across all workers; 0 for no cap
+	Rate    int
+	Mix     mix
+	Timeout time.Duration
+	// SeedUsers and SeedOrders are created before the load starts, so the
+	// read scenarios have something to read from the first request
+	SeedUsers  int
+	SeedOrders int
+}
+
+// loadTest sends the requests and records how they went
+type loadTest struct {
+	opts     options
+	client   *client
+	users    *pool
+	orders   *pool
+	run      string
+	seq      atomic.Int64
+	recorder *recorder
+}
+
+func newLoadTest(opts options) *loadTest {
+	return &loadTest{
+		opts: opts,
+		client: &client{
+			baseURL: opts.BaseURL,
+			http: &http.Client{
+				Timeout: opts.Timeout,
+				// One idle connection per worker, so connections are reused
+				// rather than opened for every request
+				Transport: &http.Transport{
+					Proxy:               http.ProxyFromEnvironment,
+					MaxIdleConns:        opts.Concurrency,
+					MaxIdleConnsPerHost: opts.Concurrency,
+					IdleConnTimeout:     90 * time.Second,
+				},
+			},
+		},
+		users:    &pool{},
+		orders:   &pool{},
+		run:      strconv.FormatInt(time.Now().UnixNano(), 36),
+		recorder: newRecorder(),
+	}
+}
+
+func (lt *loadTest) newWorker(seed uint64) *worker {
+	return &worker{
+		client: lt.client,
+		users:  lt.users,
+		orders: lt.orders,
+		rand:   rand.New(rand.NewPCG(seed, uint64(time.Now().UnixNano()))),
+		run:    lt.run,
+		seq:    &lt.seq,
+	}
+}
+
+// seed creates the users, and the orders when the mix reads orders, that
+// the load starts from. Its requests are not part of the report.
+func (lt *loadTest) seed(ctx context.Context) error {
+	w := lt.newWorker(0)
+	for range lt.opts.SeedUsers {
+		if err := createUser(ctx, w); err != nil {
+			return fmt.Errorf("failed to create a user: %w", err)
+		}
+	}
+	if !lt.opts.Mix.has("get_order") {
+		return nil
+	}
+	for range lt.opts.SeedOrders {
+		if err := createOrder(ctx, w); err != nil {
+			if errors.Is(err, errNoData) {
+				return errors.New("failed to create an order: no users; seed some with -seed-users")
+			}
+			return fmt.Errorf("failed to create an order: %w", err)
+		}
+	}
+	return nil
+}
+
+// start runs the load until the duration is up, the requests are sent or ctx
+// is cancelled, and returns how long it ran
+func (lt *loadTest) start(ctx context.Context) time.Duration {
+	if lt.opts.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, lt.opts.Duration)
+		defer cancel()
+	}
+
+	var tokens <-chan time.Time
+	if lt.opts.Rate > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(lt.opts.Rate))
+		defer ticker.Stop()
+		tokens = ticker.C
+	}
+
+	var sent atomic.Int64
+	var wg sync.WaitGroup
+	started := time.Now()
+	for i := range lt.opts.Concurrency {
+		w := lt.newWorker(uint64(i) + 1)
+		wg.Go(func() {
+			for {
+				if lt.opts.Requests > 0 && sent.Add(1) > int64(lt.opts.Requests) {
+					return
+				}
+				if tokens != nil {
+					select {
+					case <-ctx.Done():
+						return
+					case <-tokens:
+					}
+				}
+				if ctx.Err() != nil {
+					return
+				}
+
+				name := lt.opts.Mix.pick(w.rand)
+				begin := time.Now()
+				err := scenarios[name](ctx, w)
+				took := time.Since(begin)
+
+				// A request cut off by the end of the load says nothing
+				// about the service
+				if err != nil && ctx.Err() != nil {
+					return
+				}
+				lt.recorder.record(name, took, err)
+			}
+		})
+	}
+	wg.Wait()
+	return time.Since(started)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMix(t *testing.T) {
+	m, err := parseMix("get_user=3, create_order , get_order=0")
+	require.NoError(t, err)
+	assert.Equal(t, mix{{name: "get_user", weight: 3}, {name: "create_order", weight: 1}}, m)
+	assert.False(t, m.has("get_order"))
+
+	for _, bad := range []string{"", "get_order=0", "list_users=1", "get_user=x", "get_user=-1", "get_user,get_user"} {
+		_, err := parseMix(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestMixPick(t *testing.T) {
+	m := mix{{name: "get_user", weight: 3}, {name: "create_user", weight: 1}}
+	r := rand.New(rand.NewPCG(1, 2))
+
+	counts := map[string]int{}
+	for range 4000 {
+		counts[m.pick(r)]++
+	}
+	assert.InDelta(t, 3000, counts["get_user"], 150)
+	assert.InDelta(t, 1000, counts["create_user"], 150)
+}
+
+// fakeOrderService answers like orderservice, keeping users and orders in
+// memory, and fails every failEvery-th order
+type fakeOrderService struct {
+	mu        sync.Mutex
+	users     map[string]bool
+	orders    map[string]bool
+	seq       int
+	calls     atomic.Int64
+	failEvery int
+}
+
+func newFakeOrderService() *fakeOrderService {
+	return &fakeOrderService{users: map[string]bool{}, orders: map[string]bool{}}
+}
+
+func (f *fakeOrderService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.calls.Add(1)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	reply := func(status int, id string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": status < 400, "data": map[string]any{"id": id}})
+	}
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/users":
+		f.seq++
+		id := fmt.Sprintf("u-%d", f.seq)
+		f.users[id] = true
+		reply(http.StatusCreated, id)
+	case r.Method == http.MethodPost && r.URL.Path == "/orders":
+		f.seq++
+		if f.failEvery > 0 && f.seq%f.failEvery == 0 {
+			reply(http.StatusServiceUnavailable, "")
+			return
+		}
+		id := fmt.Sprintf("o-%d", f.seq)
+		f.orders[id] = true
+		reply(http.StatusCreated, id)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/users/"):
+		id := strings.TrimPrefix(r.URL.Path, "/users/")
+		if !f.users[id] {
+			reply(http.StatusNotFound, "")
+			return
+		}
+		reply(http.StatusOK, id)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/orders/"):
+		id := strings.TrimPrefix(r.URL.Path, "/orders/")
+		if !f.orders[id] {
+			reply(http.StatusNotFound, "")
+			return
+		}
+		reply(http.StatusOK, id)
+	default:
+		reply(http.StatusNotFound, "")
+	}
+}
+
+func TestLoadTest(t *testing.T) {
+	fake := newFakeOrderService()
+	fake.failEvery = 5
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	m, err := parseMix(defaultMix)
+	require.NoError(t, err)
+	lt := newLoadTest(options{
+		BaseURL:     srv.URL,
+		Concurrency: 4,
+		Requests:    200,
+		Mix:         m,
+		Timeout:     time.Second,
+		SeedUsers:   5,
+		SeedOrders:  3,
+	})
+
+	require.NoError(t, lt.seed(context.Background()))
+	assert.Equal(t, 5, lt.users.len())
+	assert.Equal(t, 3, lt.orders.len())
+	seeded := fake.calls.Load()
+
+	elapsed := lt.start(context.Background())
+	r := lt.report(elapsed, defaultMix)
+
+	// Seeding is not part of the report
+	assert.Equal(t, int64(200), fake.calls.Load()-seeded)
+	assert.Equal(t, 200, r.Total.Requests)
+	require.Len(t, r.Scenarios, 4)
+
+	// Only the orders fail, with the status they failed with
+	for _, s := range r.Scenarios {
+		if s.Scenario == "create_order" {
+			assert.Positive(t, s.Errors)
+			assert.Equal(t, map[string]int{"status 503": s.Errors}, s.ByKind)
+		} else {
+			assert.Zero(t, s.Errors, s.Scenario)
+		}
+	}
+
+	var text bytes.Buffer
+	require.NoError(t, r.writeText(&text))
+	assert.Contains(t, text.String(), "create_order")
+	assert.Contains(t, text.String(), "status 503")
+
+	var decoded report
+	var out bytes.Buffer
+	require.NoError(t, r.writeJSON(&out))
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, r.Total.Requests, decoded.Total.Requests)
+}
+
+func TestLoadTestStopsWithContext(t *testing.T) {
+	srv := httptest.NewServer(newFakeOrderService())
+	defer srv.Close()
+
+	lt := newLoadTest(options{
+		BaseURL:     srv.URL,
+		Concurrency: 2,
+		Duration:    50 * time.Millisecond,
+		Rate:        100,
+		Mix:         mix{{name: "create_user", weight: 1}},
+		Timeout:     time.Second,
+	})
+
+	elapsed := lt.start(context.Background())
+	assert.Less(t, elapsed, time.Second)
+
+	// About five requests in 50ms at 100 a second
+	r := lt.report(elapsed, "create_user")
+	assert.Positive(t, r.Total.Requests)
+	assert.LessOrEqual(t, r.Total.Requests, 10)
+}
+
+func TestSeedNeedsUsersForOrders(t *testing.T) {
+	srv := httptest.NewServer(newFakeOrderService())
+	defer srv.Close()
+
+	lt := newLoadTest(options{
+		BaseURL:     srv.URL,
+		Concurrency: 1,
+		Mix:         mix{{name: "get_order", weight: 1}},
+		Timeout:     time.Second,
+		SeedOrders:  1,
+	})
+	assert.ErrorContains(t, lt.seed(context.Background()), "no users")
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// errNoData means a scenario had nothing to read yet, e.g. get_order before
+// any order was created; it is counted as skipped, not as an error
+var errNoData = errors.New("no data to read yet")
+
+// scenarios are the requests the load test can send, by name
+var scenarios = map[string]func(ctx context.Context, w *worker) error{
+	"create_user":  createUser,
+	"get_user":     getUser,
+	"create_order": createOrder,
+	"get_order":    getOrder,
+}
+
+// defaultMix reads three times as much as it writes
+const defaultMix = "get_user=3,get_order=3,create_user=1,create_order=1"
+
+// weighted is a scenario and how often it runs relative to the others
+type weighted struct {
+	name   string
+	weight int
+}
+
+// mix picks scenarios at random, in proportion to their weights
+type mix []weighted
+
+// parseMix reads a mix such as "get_user=3,create_order=1". A scenario
+// without a weight has weight 1.
+func parseMix(s string) (mix, error) {
+	var m mix
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, weightText, hasWeight := strings.Cut(part, "=")
+		name = strings.TrimSpace(name)
+		if _, ok := scenarios[name]; !ok {
+			return nil, fmt.Errorf("unknown scenario %q (known: %s)", name, strings.Join(scenarioNames(), ", "))
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("scenario %q given twice", name)
+		}
+		seen[name] = true
+
+		weight := 1
+		if hasWeight {
+			w, err := strconv.Atoi(strings.TrimSpace(weightText))
+			if err != nil || w < 0 {
+				return nil, fmt.Errorf("invalid weight %q for scenario %q", weightText, name)
+			}
+			weight = w
+		}
+		if weight > 0 {
+			m = append(m, weighted{name: name, weight: weight})
+		}
+	}
+	if len(m) == 0 {
+		return nil, errors.New("the mix has no scenario with a weight above 0")
+	}
+	return m, nil
+}
+
+// pick returns a scenario name, drawn with r in proportion to the weights
+func (m mix) pick(r *rand.Rand) string {
+	total := 0
+	for _, w := range m {
+		total += w.weight
+	}
+	n := r.IntN(total)
+	for _, w := range m {
+		if n < w.weight {
+			return w.name
+		}
+		n -= w.weight
+	}
+	return m[len(m)-1].name
+}
+
+// has reports whether the mix runs the scenario
+func (m mix) has(name string) bool {
+	for _, w := range m {
+		if w.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func scenarioNames() []string {
+	names := make([]string, 0, len(scenarios))
+	for name := range scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pool holds the ids of the users or orders created so far, for the read
+// scenarios and for new orders
+type pool struct {
+	mu  sync.RWMutex
+	ids []string
+}
+
+func (p *pool) add(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.ids = append(p.ids, id)
+}
+
+// random returns an id drawn with r, or false while the pool is empty
+func (p *pool) random(r *rand.Rand) (string, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if len(p.ids) == 0 {
+		return "", false
+	}
+	return p.ids[r.IntN(len(p.ids))], true
+}
+
+func (p *pool) len() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.ids)
+}
+
+// worker sends requests one after the other
+type worker struct {
+	client *client
+	users  *pool
+	orders *pool
+	rand   *rand.Rand
+	// run tells the emails of this load test apart from earlier ones
+	run string
+	// seq numbers the users created, across workers
+	seq *atomic.Int64
+}
+
+func createUser(ctx context.Context, w *worker) error {
+	n := w.seq.Add(1)
+	id, err := w.client.do(ctx, http.MethodPost, "/users", map[string]any{
+		"name":  fmt.Sprintf("Load Test %d", n),
+		"email": fmt.Sprintf("loadtest-%s-%d@example.com", w.run, n),
+	}, http.StatusCreated)
+	if err != nil {
+		return err
+	}
+	w.users.add(id)
+	return nil
+}
+
+func getUser(ctx context.Context, w *worker) error {
+	id, ok := w.users.random(w.rand)
+	if !ok {
+		return errNoData
+	}
+	_, err := w.client.do(ctx, http.MethodGet, "/users/"+id, nil, http.StatusOK)
+	return err
+}
+
+// skus are the products orders are made of
+var skus = []string{"LAPTOP", "MOUSE", "KEYBOARD", "MONITOR", "DOCK"}
+
+func createOrder(ctx context.Context, w *worker) error {
+	userID, ok := w.users.random(w.rand)
+	if !ok {
+		return errNoData
+	}
+	items := make([]map[string]any, 1+w.rand.IntN(3))
+	for i := range items {
+		items[i] = map[string]any{
+			"sku":   skus[w.rand.IntN(len(skus))],
+			"qty":   1 + w.rand.IntN(5),
+			"price": float64(100+w.rand.IntN(100000)) / 100,
+		}
+	}
+	id, err := w.client.do(ctx, http.MethodPost, "/orders", map[string]any{
+		"user_id": userID,
+		"items":   items,
+	}, http.StatusCreated)
+	if err != nil {
+		return err
+	}
+	w.orders.add(id)
+	return nil
+}
+
+func getOrder(ctx context.Context, w *worker) error {
+	id, ok := w.orders.random(w.rand)
+	if !ok {
+		return errNoData
+	}
+	_, err := w.client.do(ctx, http.MethodGet, "/orders/"+id, nil, http.StatusOK)
+	return err
+}
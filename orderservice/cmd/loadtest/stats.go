@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+// recorder collects the outcome of every request, by scenario. It is safe
+// for concurrent use.
+type recorder struct {
+	mu        sync.Mutex
+	scenarios map[string]*samples
+}
+
+// samples are the outcomes of one scenario's requests
+type samples struct {
+	latencies []time.Duration
+	errors    map[string]int
+	skipped   int
+}
+
+func newRecorder() *recorder {
+	return &recorder{scenarios: map[string]*samples{}}
+}
+
+// record adds a request of scenario that took d and ended with err
+func (r *recorder) record(scenario string, d time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.scenarios[scenario]
+	if !ok {
+		s = &samples{errors: map[string]int{}}
+		r.scenarios[scenario] = s
+	}
+	if errors.Is(err, errNoData) {
+		s.skipped++
+		return
+	}
+	s.latencies = append(s.latencies, d)
+	if err != nil {
+		s.errors[errorKind(err)]++
+	}
+}
+
+// errorKind groups errors for the report: "status 503", "timeout" or "transport"
+func errorKind(err error) string {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return fmt.Sprintf("status %d", statusErr.got)
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return "timeout"
+	}
+	return "transport"
+}
+
+// summary is what the report shows for a scenario, or for all of them
+type summary struct {
+	Scenario  string         `json:"scenario"`
+	Requests  int            `json:"requests"`
+	Errors    int            `json:"errors"`
+	ErrorRate float64        `json:"error_rate"`
+	Skipped   int            `json:"skipped"`
+	RPS       float64        `json:"rps"`
+	Mean      time.Duration  `json:"mean_ns"`
+	P50       time.Duration  `json:"p50_ns"`
+	P90       time.Duration  `json:"p90_ns"`
+	P95       time.Duration  `json:"p95_ns"`
+	P99       time.Duration  `json:"p99_ns"`
+	Max       time.Duration  `json:"max_ns"`
+	ByKind    map[string]int `json:"errors_by_kind,omitempty"`
+}
+
+// summaries returns a summary per scenario, sorted by name, and one for all
+// requests together; elapsed is how long the load ran
+func (r *recorder) summaries(elapsed time.Duration) ([]summary, summary) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.scenarios))
+	for name := range r.scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	all := &samples{errors: map[string]int{}}
+	result := make([]summary, 0, len(names))
+	for _, name := range names {
+		s := r.scenarios[name]
+		result = append(result, summarize(name, s, elapsed))
+
+		all.latencies = append(all.latencies, s.latencies...)
+		all.skipped += s.skipped
+		for kind, n := range s.errors {
+			all.errors[kind] += n
+		}
+	}
+	return result, summarize("total", all, elapsed)
+}
+
+func summarize(name string, s *samples, elapsed time.Duration) summary {
+	sum := summary{Scenario: name, Requests: len(s.latencies), Skipped: s.skipped}
+	if len(s.errors) > 0 {
+		sum.ByKind = make(map[string]int, len(s.errors))
+	}
+	for kind, n := range s.errors {
+		sum.Errors += n
+		sum.ByKind[kind] = n
+	}
+	if sum.Requests == 0 {
+		return sum
+	}
+
+	sum.ErrorRate = float64(sum.Errors) / float64(sum.Requests)
+	if elapsed > 0 {
+		sum.RPS = float64(sum.Requests) / elapsed.Seconds()
+	}
+
+	sorted := append([]time.Duration(nil), s.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	sum.Mean = total / time.Duration(len(sorted))
+	sum.P50 = percentile(sorted, 50)
+	sum.P90 = percentile(sorted, 90)
+	sum.P95 = percentile(sorted, 95)
+	sum.P99 = percentile(sorted, 99)
+	sum.Max = sorted[len(sorted)-1]
+	return sum
+}
+
+// percentile returns the p-th percentile of sorted by the nearest-rank
+// method: the smallest latency at least p percent of the requests took
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPercentile(t *testing.T) {
+	sorted := make([]time.Duration, 100)
+	for i := range sorted {
+		sorted[i] = time.Duration(i+1) * time.Millisecond
+	}
+
+	assert.Equal(t, 50*time.Millisecond, percentile(sorted, 50))
+	assert.Equal(t, 99*time.Millisecond, percentile(sorted, 99))
+	assert.Equal(t, 100*time.Millisecond, percentile(sorted, 100))
+	assert.Equal(t, time.Millisecond, percentile(sorted, 0))
+	assert.Equal(t, 7*time.Millisecond, percentile([]time.Duration{7 * time.Millisecond}, 99))
+	assert.Zero(t, percentile(nil, 50))
+}
+
+func TestRecorderSummaries(t *testing.T) {
+	r := newRecorder()
+	for i := 1; i <= 10; i++ {
+		r.record("get_user", time.Duration(i)*time.Millisecond, nil)
+	}
+	r.record("create_order", 30*time.Millisecond, &statusError{got: 503, want: 201})
+	r.record("create_order", 20*time.Millisecond, nil)
+	r.record("get_order", 0, errNoData)
+	r.record("get_order", time.Second, fmt.Errorf("request failed: %w", context.DeadlineExceeded))
+
+	scenarios, total := r.summaries(2 * time.Second)
+	require.Len(t, scenarios, 3)
+
+	order := scenarios[0]
+	assert.Equal(t, "create_order", order.Scenario)
+	assert.Equal(t, 2, order.Requests)
+	assert.Equal(t, 1, order.Errors)
+	assert.Equal(t, 0.5, order.ErrorRate)
+	assert.Equal(t, map[string]int{"status 503": 1}, order.ByKind)
+
+	assert.Equal(t, "get_order", scenarios[1].Scenario)
+	assert.Equal(t, 1, scenarios[1].Skipped)
+	assert.Equal(t, map[string]int{"timeout": 1}, scenarios[1].ByKind)
+
+	user := scenarios[2]
+	assert.Equal(t, 10, user.Requests)
+	assert.Equal(t, 5.0, user.RPS)
+	assert.Equal(t, 5500*time.Microsecond, user.Mean)
+	assert.Equal(t, 5*time.Millisecond, user.P50)
+	assert.Equal(t, 10*time.Millisecond, user.P99)
+
+	assert.Equal(t, 13, total.Requests)
+	assert.Equal(t, 2, total.Errors)
+	assert.Equal(t, 1, total.Skipped)
+	assert.Equal(t, time.Second, total.Max)
+}
+
+func TestErrorKind(t *testing.T) {
+	assert.Equal(t, "status 500", errorKind(&statusError{got: 500, want: 200}))
+	assert.Equal(t, "timeout", errorKind(context.DeadlineExceeded))
+	assert.Equal(t, "transport", errorKind(errors.New("connection refused")))
+}
+
+func TestCheckThresholds(t *testing.T) {
+	total := summary{ErrorRate: 0.02, P99: 80 * time.Millisecond}
+
+	assert.NoError(t, checkThresholds(total, -1, 0))
+	assert.NoError(t, checkThresholds(total, 0.05, 100*time.Millisecond))
+	assert.ErrorContains(t, checkThresholds(total, 0.01, 0), "error rate 2.00% is above 1.00%")
+	assert.ErrorContains(t, checkThresholds(total, -1, 50*time.Millisecond), "p99 latency 80.0ms is above 50.0ms")
+}
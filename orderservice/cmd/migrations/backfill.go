@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gostratum/dbx/migrate"
+)
+
+// backfillTable stores the progress checkpoint of each backfill
+const backfillTable = "schema_backfills"
+
+// defaultBackfillBatchSize is used when neither the backfill nor -batch-size sets one
+const defaultBackfillBatchSize = 500
+
+// batchResult is what a backfill batch reports back to the runner
+type batchResult struct {
+	// Cursor is the checkpoint to resume from (typically the last processed key)
+	Cursor string
+	// Rows is the number of rows the batch changed
+	Rows int
+	// Done is true once there is nothing left to process
+	Done bool
+}
+
+// batchFunc processes one batch after cursor inside tx. The runner commits tx together
+// with the new checkpoint, so a crash never loses or repeats a committed batch.
+type batchFunc func(ctx context.Context, tx *sql.Tx, cursor string, limit int) (batchResult, error)
+
+// backfill is a long-running data migration tied to the schema version it requires.
+// Backfills run outside the migrate lock so large data fixes never block DDL.
+type backfill struct {
+	Version     uint
+	Name        string
+	Description string
+	BatchSize   int
+	Batch       batchFunc
+}
+
+// backfillCheckpoint is the persisted progress of one backfill
+type backfillCheckpoint struct {
+	Cursor      string
+	Rows        int64
+	CompletedAt sql.NullTime
+}
+
+// backfillStore persists backfill checkpoints in the target database
+type backfillStore struct {
+	db *sql.DB
+}
+
+// openBackfillStore connects to the database and ensures the checkpoint table exists
+func openBackfillStore(ctx context.Context, dbURL string) (*backfillStore, error) {
+	db, err := openDB(ctx, dbURL)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+backfillTable+` (
+		name TEXT PRIMARY KEY,
+		version BIGINT NOT NULL,
+		last_cursor TEXT NOT NULL DEFAULT '',
+		rows_processed BIGINT NOT NULL DEFAULT 0,
+		started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+		updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+		completed_at TIMESTAMP
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create %s table: %w", backfillTable, err)
+	}
+
+	return &backfillStore{db: db}, nil
+}
+
+// Close releases the database connection
+func (s *backfillStore) Close() error {
+	return s.db.Close()
+}
+
+// Checkpoint returns the saved progress of a backfill, creating it on first run
+func (s *backfillStore) Checkpoint(ctx context.Context, b backfill) (backfillCheckpoint, error) {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO `+backfillTable+` (name, version) VALUES ($1, $2) ON CONFLICT (name) DO NOTHING`,
+		b.Name, int64(b.Version))
+	if err != nil {
+		return backfillCheckpoint{}, fmt.Errorf("failed to create checkpoint for %s: %w", b.Name, err)
+	}
+
+	var cp backfillCheckpoint
+	err = s.db.QueryRowContext(ctx,
+		`SELECT last_cursor, rows_processed, completed_at FROM `+backfillTable+` WHERE name = $1`, b.Name,
+	).Scan(&cp.Cursor, &cp.Rows, &cp.CompletedAt)
+	if err != nil {
+		return backfillCheckpoint{}, fmt.Errorf("failed to load checkpoint for %s: %w", b.Name, err)
+	}
+
+	return cp, nil
+}
+
+// RunBatch executes one batch and advances the checkpoint in the same transaction
+func (s *backfillStore) RunBatch(ctx context.Context, b backfill, cursor string, limit int) (batchResult, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return batchResult{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := b.Batch(ctx, tx, cursor, limit)
+	if err != nil {
+		return batchResult{}, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE `+backfillTable+`
+		SET last_cursor = $2, rows_processed = rows_processed + $3, updated_at = NOW(),
+			completed_at = CASE WHEN $4 THEN NOW() ELSE NULL END
+		WHERE name = $1`,
+		b.Name, res.Cursor, int64(res.Rows), res.Done)
+	if err != nil {
+		return batchResult{}, fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return batchResult{}, fmt.Errorf("failed to commit batch: %w", err)
+	}
+	return res, nil
+}
+
+// validateBackfills checks that backfill names are unique and every backfill is runnable
+func validateBackfills(backfills []backfill) error {
+	seen := make(map[string]bool)
+	for _, b := range backfills {
+		switch {
+		case b.Name == "":
+			return fmt.Errorf("backfill for version %d has no name", b.Version)
+		case seen[b.Name]:
+			return fmt.Errorf("duplicate backfill name: %s", b.Name)
+		case b.Batch == nil:
+			return fmt.Errorf("backfill %s has no batch function", b.Name)
+		}
+		seen[b.Name] = true
+	}
+	return nil
+}
+
+// runBackfills executes registered backfills whose schema version has been applied,
+// resuming each from its last checkpoint
+func runBackfills(ctx context.Context, dbURL string, run runOptions, opts []migrate.Option) error {
+	if err := validateBackfills(registeredBackfills); err != nil {
+		return err
+	}
+
+	selected := make([]backfill, 0, len(registeredBackfills))
+	for _, b := range registeredBackfills {
+		if run.Backfill == "" || run.Backfill == b.Name {
+			selected = append(selected, b)
+		}
+	}
+	if len(selected) == 0 {
+		if run.Backfill != "" {
+			return fmt.Errorf("unknown backfill: %s", run.Backfill)
+		}
+		fmt.Println("✅ No backfills registered")
+		return nil
+	}
+	sort.SliceStable(selected, func(i, j int) bool { return selected[i].Version < selected[j].Version })
+
+	status, err := migrate.GetStatus(ctx, dbURL, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to get migration status: %w", err)
+	}
+
+	store, err := openBackfillStore(ctx, dbURL)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	for _, b := range selected {
+		if b.Version > uint(status.Current) {
+			fmt.Printf("⏭️  %s: requires schema version %d (current %d), skipping\n", b.Name, b.Version, status.Current)
+			continue
+		}
+
+		if err := runBackfill(ctx, store, b, run.BatchSize); err != nil {
+			return fmt.Errorf("backfill %s failed: %w", b.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// runBackfill drives a single backfill batch by batch until it reports done
+func runBackfill(ctx context.Context, store *backfillStore, b backfill, batchSize int) error {
+	cp, err := store.Checkpoint(ctx, b)
+	if err != nil {
+		return err
+	}
+	if cp.CompletedAt.Valid {
+		fmt.Printf("✅ %s: already completed at %s (%d rows)\n", b.Name, cp.CompletedAt.Time.Format(time.RFC3339), cp.Rows)
+		return nil
+	}
+
+	limit := batchSize
+	if limit <= 0 {
+		limit = b.BatchSize
+	}
+	if limit <= 0 {
+		limit = defaultBackfillBatchSize
+	}
+
+	if cp.Cursor != "" {
+		fmt.Printf("🔁 %s: resuming after %q (%d rows so far)\n", b.Name, cp.Cursor, cp.Rows)
+	} else {
+		fmt.Printf("🚚 %s: %s\n", b.Name, b.Description)
+	}
+
+	cursor, total := cp.Cursor, cp.Rows
+	started := time.Now()
+	for batch := 1; ; batch++ {
+		res, err := store.RunBatch(ctx, b, cursor, limit)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				fmt.Printf("⏸️  %s: interrupted, progress saved at %q\n", b.Name, cursor)
+			}
+			return err
+		}
+
+		cursor, total = res.Cursor, total+int64(res.Rows)
+		fmt.Printf("  batch %d: %d rows (total %d, cursor %q, %s elapsed)\n",
+			batch, res.Rows, total, cursor, time.Since(started).Round(time.Second))
+
+		if res.Done {
+			break
+		}
+	}
+
+	fmt.Printf("✅ %s: completed (%d rows)\n", b.Name, total)
+	return nil
+}
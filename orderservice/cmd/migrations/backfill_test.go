@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisteredBackfills(t *testing.T) {
+	require.NoError(t, validateBackfills(registeredBackfills))
+
+	files, err := readMigrationFiles(os.DirFS("../../migrations"))
+	require.NoError(t, err)
+	migrations, err := groupMigrations(files)
+	require.NoError(t, err)
+
+	for _, b := range registeredBackfills {
+		assert.True(t, hasVersion(migrations, b.Version), "backfill %s requires unknown version %d", b.Name, b.Version)
+	}
+}
+
+func TestValidateBackfills(t *testing.T) {
+	noop := func(ctx context.Context, tx *sql.Tx, cursor string, limit int) (batchResult, error) {
+		return batchResult{Done: true}, nil
+	}
+
+	tests := []struct {
+		name      string
+		backfills []backfill
+		wantErr   bool
+	}{
+		{name: "valid", backfills: []backfill{{Version: 1, Name: "a", Batch: noop}, {Version: 2, Name: "b", Batch: noop}}},
+		{name: "missing name", backfills: []backfill{{Version: 1, Batch: noop}}, wantErr: true},
+		{name: "duplicate name", backfills: []backfill{{Version: 1, Name: "a", Batch: noop}, {Version: 2, Name: "a", Batch: noop}}, wantErr: true},
+		{name: "missing batch", backfills: []backfill{{Version: 1, Name: "a"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBackfills(tt.backfills)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+)
+
+// registeredBackfills lists the data backfills run by -action=backfill.
+// Each one names the schema version it depends on; it only runs once that
+// version has been applied.
+var registeredBackfills = []backfill{
+	{
+		Version:     4,
+		Name:        "users_avatar_url_defaults",
+		Description: "replace NULL users.avatar_url with an empty string so the column can become NOT NULL",
+		BatchSize:   1000,
+		Batch:       backfillAvatarURLDefaults,
+	},
+}
+
+// backfillAvatarURLDefaults walks users in primary key order and fills missing avatar URLs
+func backfillAvatarURLDefaults(ctx context.Context, tx *sql.Tx, cursor string, limit int) (batchResult, error) {
+	// Find the upper bound of the next key range
+	var last sql.NullString
+	err := tx.QueryRowContext(ctx,
+		`SELECT MAX(id) FROM (SELECT id FROM users WHERE id > $1 ORDER BY id LIMIT $2) batch`,
+		cursor, limit,
+	).Scan(&last)
+	if err != nil {
+		return batchResult{}, err
+	}
+	if !last.Valid {
+		return batchResult{Cursor: cursor, Done: true}, nil
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE users SET avatar_url = '' WHERE id > $1 AND id <= $2 AND avatar_url IS NULL`,
+		cursor, last.String,
+	)
+	if err != nil {
+		return batchResult{}, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return batchResult{}, err
+	}
+
+	return batchResult{Cursor: last.String, Rows: int(rows)}, nil
+}
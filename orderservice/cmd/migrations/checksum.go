@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/gostratum/dbx/migrate"
+)
+
+// checksumTable stores the hash of each applied migration alongside the migrate tracking table
+const checksumTable = "schema_migration_checksums"
+
+// migrationChecksum returns the hex SHA-256 of a migration's up SQL
+func migrationChecksum(m migration) string {
+	sum := sha256.Sum256([]byte(m.UpSQL))
+	return hex.EncodeToString(sum[:])
+}
+
+// checksumStore persists migration checksums in the target database
+type checksumStore struct {
+	db *sql.DB
+}
+
+// openChecksumStore connects to the database and ensures the checksum table exists
+func openChecksumStore(ctx context.Context, dbURL string) (*checksumStore, error) {
+	db, err := openDB(ctx, dbURL)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+checksumTable+` (
+		version BIGINT PRIMARY KEY,
+		name TEXT NOT NULL,
+		checksum TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create %s table: %w", checksumTable, err)
+	}
+
+	return &checksumStore{db: db}, nil
+}
+
+// Close releases the database connection
+func (s *checksumStore) Close() error {
+	return s.db.Close()
+}
+
+// Load returns the recorded checksums keyed by version
+func (s *checksumStore) Load(ctx context.Context) (map[uint]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT version, checksum FROM `+checksumTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load checksums: %w", err)
+	}
+	defer rows.Close()
+
+	recorded := make(map[uint]string)
+	for rows.Next() {
+		var version int64
+		var checksum string
+		if err := rows.Scan(&version, &checksum); err != nil {
+			return nil, fmt.Errorf("failed to scan checksum: %w", err)
+		}
+		recorded[uint(version)] = checksum
+	}
+	return recorded, rows.Err()
+}
+
+// Sync records checksums for applied migrations that have none yet and removes
+// checksums of migrations that were rolled back past current
+func (s *checksumStore) Sync(ctx context.Context, migrations []migration, current uint) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM `+checksumTable+` WHERE version > $1`, int64(current)); err != nil {
+		return fmt.Errorf("failed to remove rolled back checksums: %w", err)
+	}
+
+	for _, m := range migrations {
+		if m.Version > current {
+			break
+		}
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO `+checksumTable+` (version, name, checksum) VALUES ($1, $2, $3) ON CONFLICT (version) DO NOTHING`,
+			int64(m.Version), m.Name, migrationChecksum(m))
+		if err != nil {
+			return fmt.Errorf("failed to record checksum for version %d: %w", m.Version, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// compareChecksums reports applied migrations whose files changed, disappeared,
+// or were never recorded
+func compareChecksums(migrations []migration, recorded map[uint]string, current uint) []validationIssue {
+	var issues []validationIssue
+	known := make(map[uint]bool)
+
+	for _, m := range migrations {
+		known[m.Version] = true
+		if m.Version > current {
+			continue
+		}
+
+		checksum, ok := recorded[m.Version]
+		switch {
+		case !ok:
+			issues = append(issues, validationIssue{File: m.UpFile, Message: "applied but no checksum recorded"})
+		case checksum != migrationChecksum(m):
+			issues = append(issues, validationIssue{File: m.UpFile, Message: "edited after it was applied (checksum mismatch)"})
+		}
+	}
+
+	for version := range recorded {
+		if !known[version] {
+			issues = append(issues, validationIssue{Message: fmt.Sprintf("version %d was applied but its file is missing", version)})
+		}
+	}
+
+	return issues
+}
+
+// recordChecksums brings the checksum table in line with the current schema version
+func recordChecksums(ctx context.Context, dbURL string, cfg *migrate.Config, opts []migrate.Option) error {
+	migrations, err := loadMigrations(cfg)
+	if err != nil {
+		return err
+	}
+
+	status, err := migrate.GetStatus(ctx, dbURL, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to get migration status: %w", err)
+	}
+
+	store, err := openChecksumStore(ctx, dbURL)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := store.Sync(ctx, migrations, uint(status.Current)); err != nil {
+		return fmt.Errorf("migrations applied but checksums were not recorded: %w", err)
+	}
+	return nil
+}
+
+// runVerify fails when any applied migration differs from the file on disk
+func runVerify(ctx context.Context, dbURL string, cfg *migrate.Config, opts []migrate.Option) error {
+	migrations, err := loadMigrations(cfg)
+	if err != nil {
+		return err
+	}
+
+	status, err := migrate.GetStatus(ctx, dbURL, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to get migration status: %w", err)
+	}
+
+	store, err := openChecksumStore(ctx, dbURL)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	recorded, err := store.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🔐 Verifying %d applied migration(s) against %s...\n", len(recorded), checksumTable)
+	issues := compareChecksums(migrations, recorded, uint(status.Current))
+	if len(issues) > 0 {
+		for _, issue := range issues {
+			fmt.Printf("  ❌ %s\n", issue)
+		}
+		return fmt.Errorf("checksum verification found %d problem(s)", len(issues))
+	}
+
+	fmt.Println("✅ Applied migrations match their files")
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareChecksums(t *testing.T) {
+	files, err := readMigrationFiles(testMigrationFS())
+	require.NoError(t, err)
+	migrations, err := groupMigrations(files)
+	require.NoError(t, err)
+
+	recorded := map[uint]string{
+		1: migrationChecksum(migrations[0]),
+		2: migrationChecksum(migrations[1]),
+	}
+
+	t.Run("matching checksums", func(t *testing.T) {
+		assert.Empty(t, compareChecksums(migrations, recorded, 2))
+	})
+
+	t.Run("edited after apply", func(t *testing.T) {
+		edited := append([]migration(nil), migrations...)
+		edited[1].UpSQL = "CREATE TABLE orders (id TEXT, total REAL);"
+
+		issues := compareChecksums(edited, recorded, 2)
+		require.Len(t, issues, 1)
+		assert.Contains(t, issues[0].String(), "checksum mismatch")
+	})
+
+	t.Run("applied without checksum", func(t *testing.T) {
+		issues := compareChecksums(migrations, recorded, 3)
+		require.Len(t, issues, 1)
+		assert.Contains(t, issues[0].String(), "no checksum recorded")
+	})
+
+	t.Run("applied file removed", func(t *testing.T) {
+		issues := compareChecksums(migrations[:1], recorded, 2)
+		require.Len(t, issues, 1)
+		assert.True(t, strings.Contains(issues[0].String(), "version 2 was applied but its file is missing"))
+	})
+}
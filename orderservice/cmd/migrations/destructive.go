@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gostratum/dbx/migrate"
+)
+
+// destructivePatterns match statements that permanently remove data
+var destructivePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)^DROP\s+(TABLE|SCHEMA|DATABASE)\b`),
+	regexp.MustCompile(`(?i)^TRUNCATE\b`),
+	regexp.MustCompile(`(?i)^DELETE\s+FROM\b`),
+}
+
+var (
+	alterTablePattern = regexp.MustCompile(`(?i)^ALTER\s+TABLE\b`)
+	alterDropPattern  = regexp.MustCompile(`(?i)\bDROP\s+(\w+)`)
+)
+
+// nonColumnDrops are ALTER TABLE ... DROP targets that do not remove column data
+var nonColumnDrops = map[string]bool{
+	"CONSTRAINT": true,
+	"DEFAULT":    true,
+	"NOT":        true,
+	"IDENTITY":   true,
+	"EXPRESSION": true,
+}
+
+// destructiveStatements returns the statements in sql that remove tables, columns or rows
+func destructiveStatements(sql string) []string {
+	var found []string
+	for _, stmt := range sqlStatements(sql) {
+		if isDestructive(stmt) {
+			found = append(found, stmt)
+		}
+	}
+	return found
+}
+
+// isDestructive reports whether a single statement removes a table, column or rows
+func isDestructive(stmt string) bool {
+	for _, pattern := range destructivePatterns {
+		if pattern.MatchString(stmt) {
+			return true
+		}
+	}
+
+	if alterTablePattern.MatchString(stmt) {
+		// Both "DROP COLUMN x" and the shorthand "DROP x" remove a column
+		for _, match := range alterDropPattern.FindAllStringSubmatch(stmt, -1) {
+			if !nonColumnDrops[strings.ToUpper(match[1])] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// checkDestructive refuses a down plan containing destructive SQL unless allowed
+func checkDestructive(plan migrationPlan, allow bool) error {
+	var count int
+	for _, m := range plan.Migrations {
+		for _, stmt := range destructiveStatements(m.DownSQL) {
+			if count == 0 {
+				fmt.Println("⚠️  The rollback contains destructive statements:")
+			}
+			fmt.Printf("  %s: %s\n", m.DownFile, stmt)
+			count++
+		}
+	}
+
+	if count == 0 {
+		return nil
+	}
+	if allow {
+		fmt.Printf("⚠️  Proceeding with %d destructive statement(s) (-allow-destructive)\n", count)
+		return nil
+	}
+	return fmt.Errorf("rollback would run %d destructive statement(s); re-run with -allow-destructive to proceed", count)
+}
+
+// guardDown plans a down migration of steps (0 = all) and checks it for destructive SQL
+func guardDown(ctx context.Context, dbURL string, run runOptions, cfg *migrate.Config, opts []migrate.Option) (migrationPlan, error) {
+	plan, err := loadPlan(ctx, dbURL, cfg, opts, "down", run.Steps)
+	if err != nil {
+		return migrationPlan{}, err
+	}
+
+	if err := checkDestructive(plan, run.AllowDestructive); err != nil {
+		return migrationPlan{}, err
+	}
+	return plan, nil
+}
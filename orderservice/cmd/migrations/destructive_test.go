@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDestructiveStatements(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want int
+	}{
+		{name: "drop table", sql: "DROP TABLE IF EXISTS users CASCADE;", want: 1},
+		{name: "drop column", sql: "ALTER TABLE users DROP COLUMN IF EXISTS avatar_url;", want: 1},
+		{name: "drop column shorthand", sql: "ALTER TABLE users DROP avatar_url;", want: 1},
+		{name: "drop constraint", sql: "ALTER TABLE users DROP CONSTRAINT users_email_key;", want: 0},
+		{name: "truncate", sql: "TRUNCATE orders;", want: 1},
+		{name: "drop index", sql: "DROP INDEX IF EXISTS idx_orders_status;", want: 0},
+		{name: "add column", sql: "ALTER TABLE users ADD COLUMN avatar_url TEXT;", want: 0},
+		{name: "drop not null", sql: "ALTER TABLE users ALTER COLUMN name DROP NOT NULL;", want: 0},
+		{name: "drop shorthand starting with c", sql: "ALTER TABLE users DROP created_at;", want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Len(t, destructiveStatements(tt.sql), tt.want)
+		})
+	}
+}
+
+func TestCheckDestructive(t *testing.T) {
+	plan := migrationPlan{
+		Direction: "down",
+		Migrations: []migration{
+			{Version: 2, DownFile: "000002_add_index.down.sql", DownSQL: "DROP INDEX IF EXISTS idx;"},
+			{Version: 1, DownFile: "000001_create_users.down.sql", DownSQL: "DROP TABLE IF EXISTS users;"},
+		},
+	}
+
+	assert.Error(t, checkDestructive(plan, false))
+	assert.NoError(t, checkDestructive(plan, true))
+	assert.NoError(t, checkDestructive(migrationPlan{Migrations: plan.Migrations[:1]}, false))
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// migrationPlan describes the migrations an action would execute and the resulting version change
+type migrationPlan struct {
+	Direction  string
+	From       uint
+	To         uint
+	Migrations []migration
+}
+
+// planUp returns the pending migrations above current, limited to steps when steps > 0
+func planUp(migrations []migration, current uint, steps int) migrationPlan {
+	plan := migrationPlan{Direction: "up", From: current, To: current}
+
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		if steps > 0 && len(plan.Migrations) == steps {
+			break
+		}
+		plan.Migrations = append(plan.Migrations, m)
+		plan.To = m.Version
+	}
+
+	return plan
+}
+
+// planDown returns the applied migrations to roll back from current (newest first),
+// limited to steps when steps > 0
+func planDown(migrations []migration, current uint, steps int) migrationPlan {
+	plan := migrationPlan{Direction: "down", From: current, To: current}
+
+	var applied []migration
+	for _, m := range migrations {
+		if m.Version <= current {
+			applied = append(applied, m)
+		}
+	}
+
+	for i := len(applied) - 1; i >= 0; i-- {
+		if steps > 0 && len(plan.Migrations) == steps {
+			break
+		}
+		plan.Migrations = append(plan.Migrations, applied[i])
+		if i > 0 {
+			plan.To = applied[i-1].Version
+		} else {
+			plan.To = 0
+		}
+	}
+
+	return plan
+}
+
+// printPlan writes the plan and the SQL each migration would execute to stdout
+func printPlan(plan migrationPlan) {
+	fmt.Printf("🔍 Dry run: migrate %s from version %d to version %d (%d migration(s))\n",
+		plan.Direction, plan.From, plan.To, len(plan.Migrations))
+
+	if len(plan.Migrations) == 0 {
+		fmt.Println("  Nothing to do")
+		return
+	}
+
+	for _, m := range plan.Migrations {
+		file, sql := m.UpFile, m.UpSQL
+		if plan.Direction == "down" {
+			file, sql = m.DownFile, m.DownSQL
+		}
+
+		fmt.Printf("\n-- ========== %06d %s (%s) ==========\n", m.Version, m.Name, file)
+		if file == "" {
+			fmt.Printf("-- ⚠️  no %s file for this version\n", plan.Direction)
+			continue
+		}
+		fmt.Println(strings.TrimSpace(sql))
+	}
+	fmt.Println()
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMigrationFS() fstest.MapFS {
+	return fstest.MapFS{
+		"000001_create_users.up.sql":    {Data: []byte("CREATE TABLE users (id TEXT);")},
+		"000001_create_users.down.sql":  {Data: []byte("DROP TABLE IF EXISTS users;")},
+		"000002_create_orders.up.sql":   {Data: []byte("CREATE TABLE orders (id TEXT);")},
+		"000002_create_orders.down.sql": {Data: []byte("DROP TABLE IF EXISTS orders;")},
+		"000003_add_index.up.sql":       {Data: []byte("CREATE INDEX idx ON orders(id);")},
+		"000003_add_index.down.sql":     {Data: []byte("DROP INDEX idx;")},
+		"README.md":                     {Data: []byte("not a migration")},
+	}
+}
+
+func TestReadMigrationFiles(t *testing.T) {
+	files, err := readMigrationFiles(testMigrationFS())
+	require.NoError(t, err)
+	require.Len(t, files, 6)
+
+	assert.Equal(t, uint(1), files[0].Version)
+	assert.Equal(t, "up", files[0].Direction)
+	assert.Equal(t, "down", files[1].Direction)
+	assert.Equal(t, "create_orders", files[2].Name)
+
+	migrations, err := groupMigrations(files)
+	require.NoError(t, err)
+	require.Len(t, migrations, 3)
+	assert.Equal(t, "000003_add_index.down.sql", migrations[2].DownFile)
+}
+
+func versions(plan migrationPlan) []uint {
+	var out []uint
+	for _, m := range plan.Migrations {
+		out = append(out, m.Version)
+	}
+	return out
+}
+
+func TestPlanMigrations(t *testing.T) {
+	files, err := readMigrationFiles(testMigrationFS())
+	require.NoError(t, err)
+	migrations, err := groupMigrations(files)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		direction string
+		current   uint
+		steps     int
+		want      []uint
+		wantTo    uint
+	}{
+		{name: "up all from empty", direction: "up", current: 0, want: []uint{1, 2, 3}, wantTo: 3},
+		{name: "up one step", direction: "up", current: 1, steps: 1, want: []uint{2}, wantTo: 2},
+		{name: "up when current", direction: "up", current: 3, want: nil, wantTo: 3},
+		{name: "down all", direction: "down", current: 3, want: []uint{3, 2, 1}, wantTo: 0},
+		{name: "down two steps", direction: "down", current: 3, steps: 2, want: []uint{3, 2}, wantTo: 1},
+		{name: "down from empty", direction: "down", current: 0, want: nil, wantTo: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var plan migrationPlan
+			if tt.direction == "up" {
+				plan = planUp(migrations, tt.current, tt.steps)
+			} else {
+				plan = planDown(migrations, tt.current, tt.steps)
+			}
+
+			assert.Equal(t, tt.want, versions(plan))
+			assert.Equal(t, tt.current, plan.From)
+			assert.Equal(t, tt.wantTo, plan.To)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/gostratum/dbx/migrate"
+
+	"github.com/gostratum/examples/orderservice/migrations"
+)
+
+// useEmbeddedMigrations points the config at the migrations compiled into the binary.
+// dbx/migrate cannot be handed migrations.FS: its WithEmbed option takes no fs.FS, and
+// WithDir, the only other source, takes a path. So the embedded files are extracted to a
+// temporary directory that lives for the duration of the command, and UseEmbed is cleared
+// so WithEmbed is not passed along with it. The checksums, hooks, phases and dry runs of
+// this command read the same directory. The returned cleanup function removes it.
+func useEmbeddedMigrations(cfg *migrate.Config) (func(), error) {
+	dir, err := os.MkdirTemp("", "orderservice-migrations-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir for embedded migrations: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	if err := extractFS(migrations.FS, dir); err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	cfg.Dir = dir
+	cfg.UseEmbed = false
+	return cleanup, nil
+}
+
+// extractFS copies the top-level SQL files of fsys into dir
+func extractFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return fmt.Errorf("failed to read embedded migration %s: %w", entry.Name(), err)
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, entry.Name()), content, 0o600); err != nil {
+			return fmt.Errorf("failed to extract migration %s: %w", entry.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// dirExists reports whether path exists and is a directory
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
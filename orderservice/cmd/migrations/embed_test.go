@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gostratum/dbx/migrate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseEmbeddedMigrations(t *testing.T) {
+	cfg := &migrate.Config{UseEmbed: true, Table: "schema_migrations"}
+
+	cleanup, err := useEmbeddedMigrations(cfg)
+	require.NoError(t, err)
+
+	assert.False(t, cfg.UseEmbed)
+	assert.True(t, dirExists(cfg.Dir))
+
+	migrations, err := loadMigrations(cfg)
+	require.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+	for _, m := range migrations {
+		assert.NotEmpty(t, m.UpFile, "version %d has no up file", m.Version)
+	}
+
+	dir := cfg.Dir
+	cleanup()
+	_, err = os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+}
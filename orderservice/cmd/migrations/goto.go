@@ -0,0 +1,44 @@
+package main
+
+import "fmt"
+
+// planGoto returns the plan that moves the schema from current to exactly target.
+// target must be 0 or the version of a known migration.
+func planGoto(migrations []migration, current, target uint) (migrationPlan, error) {
+	if target != 0 && !hasVersion(migrations, target) {
+		return migrationPlan{}, fmt.Errorf("version %d does not match any migration", target)
+	}
+
+	switch {
+	case target > current:
+		var steps int
+		for _, m := range migrations {
+			if m.Version > current && m.Version <= target {
+				steps++
+			}
+		}
+		return planUp(migrations, current, steps), nil
+
+	case target < current:
+		var steps int
+		for _, m := range migrations {
+			if m.Version > target && m.Version <= current {
+				steps++
+			}
+		}
+		return planDown(migrations, current, steps), nil
+
+	default:
+		return migrationPlan{Direction: "up", From: current, To: current}, nil
+	}
+}
+
+// hasVersion reports whether a migration with the given version exists
+func hasVersion(migrations []migration, version uint) bool {
+	for _, m := range migrations {
+		if m.Version == version {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanGoto(t *testing.T) {
+	files, err := readMigrationFiles(testMigrationFS())
+	require.NoError(t, err)
+	migrations, err := groupMigrations(files)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		current       uint
+		target        uint
+		wantDirection string
+		want          []uint
+		wantErr       bool
+	}{
+		{name: "up to latest", current: 0, target: 3, wantDirection: "up", want: []uint{1, 2, 3}},
+		{name: "up one version", current: 1, target: 2, wantDirection: "up", want: []uint{2}},
+		{name: "down to version", current: 3, target: 1, wantDirection: "down", want: []uint{3, 2}},
+		{name: "down to empty", current: 2, target: 0, wantDirection: "down", want: []uint{2, 1}},
+		{name: "already there", current: 2, target: 2, wantDirection: "up", want: nil},
+		{name: "unknown version", current: 1, target: 7, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := planGoto(migrations, tt.current, tt.target)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDirection, plan.Direction)
+			assert.Equal(t, tt.want, versions(plan))
+			assert.Equal(t, tt.target, plan.To)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/gostratum/dbx/migrate"
+)
+
+// hookFunc runs around a migration with a plain database handle
+type hookFunc func(ctx context.Context, db *sql.DB) error
+
+// migrationHook runs Go code before and/or after a specific migration version.
+// Direction is "up", "down", or empty to run in both directions.
+type migrationHook struct {
+	Version   uint
+	Direction string
+	Name      string
+	Before    hookFunc
+	After     hookFunc
+}
+
+// sqlHook returns a hook that executes the given statements in order
+func sqlHook(statements ...string) hookFunc {
+	return func(ctx context.Context, db *sql.DB) error {
+		for _, stmt := range statements {
+			if _, err := db.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("%q: %w", stmt, err)
+			}
+		}
+		return nil
+	}
+}
+
+// notifyHook returns a hook that publishes payload on a Postgres NOTIFY channel so
+// running application instances can react (e.g. reload caches) to schema changes
+func notifyHook(channel, payload string) hookFunc {
+	return func(ctx context.Context, db *sql.DB) error {
+		_, err := db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, channel, payload)
+		return err
+	}
+}
+
+// hooksFor returns the registered hooks for a version and direction
+func hooksFor(hooks []migrationHook, version uint, direction string) []migrationHook {
+	var matched []migrationHook
+	for _, h := range hooks {
+		if h.Version == version && (h.Direction == "" || h.Direction == direction) {
+			matched = append(matched, h)
+		}
+	}
+	return matched
+}
+
+// planHasHooks reports whether any migration in the plan has hooks registered
+func planHasHooks(hooks []migrationHook, plan migrationPlan) bool {
+	for _, m := range plan.Migrations {
+		if len(hooksFor(hooks, m.Version, plan.Direction)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// runHooks invokes the before or after function of each hook
+func runHooks(ctx context.Context, db *sql.DB, hooks []migrationHook, version uint, stage string) error {
+	for _, h := range hooks {
+		fn := h.Before
+		if stage == "after" {
+			fn = h.After
+		}
+		if fn == nil {
+			continue
+		}
+
+		fmt.Printf("🪝 Running %s hook %s for version %d...\n", stage, h.Name, version)
+		if err := fn(ctx, db); err != nil {
+			return fmt.Errorf("%s hook %s for version %d failed: %w", stage, h.Name, version, err)
+		}
+	}
+	return nil
+}
+
+// loadPlan builds the up or down plan for steps (0 = all) from the current schema version
+func loadPlan(ctx context.Context, dbURL string, cfg *migrate.Config, opts []migrate.Option, direction string, steps int) (migrationPlan, error) {
+	migrations, err := loadMigrations(cfg)
+	if err != nil {
+		return migrationPlan{}, err
+	}
+
+	status, err := migrate.GetStatus(ctx, dbURL, opts...)
+	if err != nil {
+		return migrationPlan{}, fmt.Errorf("failed to get migration status: %w", err)
+	}
+
+	if direction == "down" {
+		return planDown(migrations, uint(status.Current), steps), nil
+	}
+	return planUp(migrations, uint(status.Current), steps), nil
+}
+
+// applyPlan executes plan. When no hooks apply, bulk performs the whole plan in one call;
+// otherwise the migrations are stepped one at a time so each version's hooks run
+// immediately before and after it.
+func applyPlan(ctx context.Context, dbURL string, run runOptions, plan migrationPlan, opts []migrate.Option, bulk func() error) error {
+	if !planHasHooks(registeredHooks, plan) {
+		return withLockWait(ctx, run.LockWait, bulk)
+	}
+
+	db, err := openDB(ctx, dbURL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	step := 1
+	if plan.Direction == "down" {
+		step = -1
+	}
+
+	for _, m := range plan.Migrations {
+		hooks := hooksFor(registeredHooks, m.Version, plan.Direction)
+
+		if err := runHooks(ctx, db, hooks, m.Version, "before"); err != nil {
+			return err
+		}
+
+		if err := withLockWait(ctx, run.LockWait, func() error {
+			return migrate.Steps(ctx, dbURL, step, opts...)
+		}); err != nil {
+			return fmt.Errorf("version %d: %w", m.Version, err)
+		}
+
+		if err := runHooks(ctx, db, hooks, m.Version, "after"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package main
+
+// registeredHooks lists the Go hooks run around specific migration versions.
+// Hooks run outside the migration's own transaction: a failing before hook stops the run
+// before the migration is applied, a failing after hook stops it right after.
+var registeredHooks = []migrationHook{
+	{
+		Version:   3,
+		Direction: "up",
+		Name:      "analyze_indexed_tables",
+		// Refresh planner statistics so the new indexes are used immediately
+		After: sqlHook("ANALYZE users", "ANALYZE orders"),
+	},
+	{
+		Version:   4,
+		Direction: "up",
+		Name:      "notify_avatar_column_added",
+		After:     notifyHook("orderservice_schema", "4"),
+	},
+}
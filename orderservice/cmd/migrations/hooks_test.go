@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooksFor(t *testing.T) {
+	hooks := []migrationHook{
+		{Version: 2, Direction: "up", Name: "up_only"},
+		{Version: 2, Name: "both"},
+		{Version: 3, Direction: "down", Name: "down_only"},
+	}
+
+	names := func(hs []migrationHook) []string {
+		var out []string
+		for _, h := range hs {
+			out = append(out, h.Name)
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"up_only", "both"}, names(hooksFor(hooks, 2, "up")))
+	assert.Equal(t, []string{"both"}, names(hooksFor(hooks, 2, "down")))
+	assert.Equal(t, []string{"down_only"}, names(hooksFor(hooks, 3, "down")))
+	assert.Empty(t, hooksFor(hooks, 1, "up"))
+}
+
+func TestPlanHasHooks(t *testing.T) {
+	files, err := readMigrationFiles(testMigrationFS())
+	require.NoError(t, err)
+	migrations, err := groupMigrations(files)
+	require.NoError(t, err)
+
+	hooks := []migrationHook{{Version: 3, Direction: "up", Name: "after_index"}}
+
+	assert.True(t, planHasHooks(hooks, planUp(migrations, 0, 0)))
+	assert.False(t, planHasHooks(hooks, planUp(migrations, 0, 2)))
+	assert.False(t, planHasHooks(hooks, planDown(migrations, 3, 0)))
+}
+
+func TestRegisteredHooks(t *testing.T) {
+	files, err := readMigrationFiles(os.DirFS("../../migrations"))
+	require.NoError(t, err)
+	migrations, err := groupMigrations(files)
+	require.NoError(t, err)
+
+	seen := make(map[string]bool)
+	for _, h := range registeredHooks {
+		assert.True(t, hasVersion(migrations, h.Version), "hook %s references unknown version %d", h.Name, h.Version)
+		assert.Contains(t, []string{"", "up", "down"}, h.Direction, "hook %s", h.Name)
+		assert.False(t, seen[h.Name], "duplicate hook name %s", h.Name)
+		assert.True(t, h.Before != nil || h.After != nil, "hook %s has no function", h.Name)
+		seen[h.Name] = true
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Backoff bounds for lock retries (variables so tests can shorten them)
+var (
+	lockRetryInitial = 500 * time.Millisecond
+	lockRetryMax     = 10 * time.Second
+)
+
+// lockErrorMarkers are substrings of the errors golang-migrate returns when another
+// instance holds the advisory migration lock
+var lockErrorMarkers = []string{
+	"can't acquire lock",
+	"can't acquire database lock",
+	"database is locked",
+}
+
+// isLockContention reports whether err means the migration lock is held elsewhere
+func isLockContention(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, marker := range lockErrorMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// withLockWait runs fn, retrying with exponential backoff while the migration lock is
+// held by another instance, for up to wait. A zero wait disables retrying.
+func withLockWait(ctx context.Context, wait time.Duration, fn func() error) error {
+	err := fn()
+	if wait <= 0 || !isLockContention(err) {
+		return err
+	}
+
+	deadline := time.Now().Add(wait)
+	backoff := lockRetryInitial
+	for attempt := 2; isLockContention(err); attempt++ {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("migration lock still held after waiting %s: %w", wait, err)
+		}
+		if backoff > remaining {
+			backoff = remaining
+		}
+
+		fmt.Printf("🔒 Migration lock is held by another instance, retrying in %s (attempt %d)...\n", backoff.Round(time.Millisecond), attempt)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("gave up waiting for migration lock: %w", ctx.Err())
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > lockRetryMax {
+			backoff = lockRetryMax
+		}
+		err = fn()
+	}
+
+	return err
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithLockWait(t *testing.T) {
+	lockRetryInitial, lockRetryMax = time.Millisecond, 5*time.Millisecond
+	t.Cleanup(func() {
+		lockRetryInitial, lockRetryMax = 500*time.Millisecond, 10*time.Second
+	})
+
+	errLocked := errors.New("failed to migrate: can't acquire lock")
+
+	t.Run("retries until the lock is released", func(t *testing.T) {
+		calls := 0
+		err := withLockWait(context.Background(), time.Second, func() error {
+			calls++
+			if calls < 3 {
+				return errLocked
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("gives up after the wait", func(t *testing.T) {
+		err := withLockWait(context.Background(), 20*time.Millisecond, func() error {
+			return errLocked
+		})
+		assert.ErrorIs(t, err, errLocked)
+		assert.Contains(t, err.Error(), "still held")
+	})
+
+	t.Run("no retry without wait", func(t *testing.T) {
+		calls := 0
+		err := withLockWait(context.Background(), 0, func() error {
+			calls++
+			return errLocked
+		})
+		assert.ErrorIs(t, err, errLocked)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("other errors are not retried", func(t *testing.T) {
+		calls := 0
+		err := withLockWait(context.Background(), time.Second, func() error {
+			calls++
+			return errors.New("syntax error at or near DROP")
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Migration phases for zero-downtime (expand/contract) schema changes.
+// Expand migrations are backwards compatible and run before the new code is rolled out;
+// contract migrations remove what old code still depends on and run after the rollout.
+const (
+	phaseExpand   = "expand"
+	phaseContract = "contract"
+)
+
+// phaseAnnotation matches a "-- migrate:phase expand|contract" comment in an up file
+var phaseAnnotation = regexp.MustCompile(`(?im)^\s*--\s*migrate:phase\s+(expand|contract)\b`)
+
+// migrationPhase classifies a migration by annotation, then by a _expand/_contract
+// name suffix, and otherwise by whether its up SQL is destructive
+func migrationPhase(m migration) string {
+	if match := phaseAnnotation.FindStringSubmatch(m.UpSQL); match != nil {
+		return strings.ToLower(match[1])
+	}
+
+	switch {
+	case strings.HasSuffix(m.Name, "_"+phaseExpand):
+		return phaseExpand
+	case strings.HasSuffix(m.Name, "_"+phaseContract):
+		return phaseContract
+	}
+
+	if len(destructiveStatements(m.UpSQL)) > 0 {
+		return phaseContract
+	}
+	return phaseExpand
+}
+
+// planPhase returns the pending migrations of the requested phase that can be applied now.
+// Migrations apply in version order, so the plan stops at the first pending migration
+// of the other phase; everything from there on is returned as held back.
+func planPhase(migrations []migration, current uint, phase string) (migrationPlan, []migration, error) {
+	if phase != phaseExpand && phase != phaseContract {
+		return migrationPlan{}, nil, fmt.Errorf("unknown phase: %s. Use expand or contract", phase)
+	}
+
+	pending := planUp(migrations, current, 0).Migrations
+
+	var steps int
+	for _, m := range pending {
+		if migrationPhase(m) != phase {
+			break
+		}
+		steps++
+	}
+
+	if steps == 0 {
+		// planUp treats 0 steps as "all", so an empty phase needs its own plan
+		return migrationPlan{Direction: "up", From: current, To: current}, pending, nil
+	}
+	return planUp(migrations, current, steps), pending[steps:], nil
+}
+
+// printHeldBack lists pending migrations that were not part of the requested phase
+func printHeldBack(phase string, held []migration) {
+	if len(held) == 0 {
+		return
+	}
+
+	fmt.Printf("⏸️  %d pending migration(s) held back (not in %s phase or ordered after one that is not):\n", len(held), phase)
+	for _, m := range held {
+		fmt.Printf("  %06d %s [%s]\n", m.Version, m.Name, migrationPhase(m))
+	}
+}
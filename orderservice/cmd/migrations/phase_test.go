@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrationPhase(t *testing.T) {
+	tests := []struct {
+		name string
+		m    migration
+		want string
+	}{
+		{name: "additive sql", m: migration{Name: "add_status", UpSQL: "ALTER TABLE users ADD COLUMN status TEXT;"}, want: phaseExpand},
+		{name: "destructive sql", m: migration{Name: "remove_legacy", UpSQL: "ALTER TABLE users DROP COLUMN legacy;"}, want: phaseContract},
+		{name: "name suffix", m: migration{Name: "backfill_status_contract", UpSQL: "UPDATE users SET status = 'active';"}, want: phaseContract},
+		{name: "annotation wins", m: migration{Name: "drop_tmp_contract", UpSQL: "-- migrate:phase expand\nDROP TABLE IF EXISTS tmp;"}, want: phaseExpand},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, migrationPhase(tt.m))
+		})
+	}
+}
+
+func TestPlanPhase(t *testing.T) {
+	migrations := []migration{
+		{Version: 1, Name: "create_users", UpSQL: "CREATE TABLE users (id TEXT);"},
+		{Version: 2, Name: "add_email", UpSQL: "ALTER TABLE users ADD COLUMN email TEXT;"},
+		{Version: 3, Name: "drop_username", UpSQL: "ALTER TABLE users DROP COLUMN username;"},
+		{Version: 4, Name: "add_index", UpSQL: "CREATE INDEX idx ON users(email);"},
+	}
+
+	t.Run("expand stops at first contract", func(t *testing.T) {
+		plan, held, err := planPhase(migrations, 0, phaseExpand)
+		require.NoError(t, err)
+		assert.Equal(t, []uint{1, 2}, versions(plan))
+		assert.Len(t, held, 2)
+	})
+
+	t.Run("contract after expand applied", func(t *testing.T) {
+		plan, held, err := planPhase(migrations, 2, phaseContract)
+		require.NoError(t, err)
+		assert.Equal(t, []uint{3}, versions(plan))
+		assert.Len(t, held, 1)
+	})
+
+	t.Run("contract blocked by pending expand", func(t *testing.T) {
+		plan, held, err := planPhase(migrations, 0, phaseContract)
+		require.NoError(t, err)
+		assert.Empty(t, plan.Migrations)
+		assert.Len(t, held, 4)
+	})
+
+	t.Run("unknown phase", func(t *testing.T) {
+		_, _, err := planPhase(migrations, 0, "migrate")
+		assert.Error(t, err)
+	})
+}
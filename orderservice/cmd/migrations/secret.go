@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gostratum/core/configx"
+
+	"github.com/gostratum/examples/orderservice/configs"
+	"github.com/gostratum/examples/orderservice/internal/adapter/configkey"
+	"github.com/gostratum/examples/orderservice/internal/adapter/dbsecret"
+)
+
+// secretTimeout bounds reading the database secret and trying it on the database
+const secretTimeout = 30 * time.Second
+
+// secretSource is the part of dbsecret.Resolver this command uses
+type secretSource interface {
+	DSN(ctx context.Context) (string, error)
+	Refresh(ctx context.Context) (string, bool, error)
+}
+
+// decryptConfig decrypts the enc: values of the configuration, such as an
+// encrypted DSN password, with the key of CONFIG_KEY or CONFIG_KEY_KMS
+func decryptConfig() error {
+	ctx, cancel := context.WithTimeout(context.Background(), secretTimeout)
+	defer cancel()
+	key, err := configkey.Load(ctx)
+	if err != nil {
+		return err
+	}
+	return configs.DecryptValues(key, "./configs")
+}
+
+// resolveSecretURL returns the database URL from the AWS secret named by db_secret.arn,
+// or "" when none is configured or the action does not touch the database
+func resolveSecretURL(loader configx.Loader, action string) (string, error) {
+	if action == "validate" {
+		return "", nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), secretTimeout)
+	defer cancel()
+
+	resolver, err := dbsecret.New(ctx, loader)
+	if err != nil || resolver == nil {
+		return "", err
+	}
+	for _, name := range configs.DSNEnv {
+		if strings.TrimSpace(os.Getenv(name)) != "" {
+			log.Printf("Ignoring %s because db_secret.arn is set", name)
+		}
+	}
+
+	fmt.Println("🔑 Reading the database URL from AWS...")
+	return checkSecretURL(ctx, resolver, pingDB)
+}
+
+// checkSecretURL tries the URL from the secret on the database. When the database
+// rejects the credentials, the secret may have been rotated after it was read, so it is
+// read once more. Any other connection error is left for the action to report.
+func checkSecretURL(ctx context.Context, secret secretSource, ping func(ctx context.Context, dbURL string) error) (string, error) {
+	dbURL, err := secret.DSN(ctx)
+	if err != nil {
+		return "", err
+	}
+	if !dbsecret.IsAuthError(ping(ctx, dbURL)) {
+		return dbURL, nil
+	}
+
+	dbURL, rotated, err := secret.Refresh(ctx)
+	if err != nil {
+		return "", err
+	}
+	if rotated {
+		fmt.Println("🔄 Database secret was rotated, using the new version")
+	}
+	return dbURL, nil
+}
+
+// pingDB connects to the database and disconnects again
+func pingDB(ctx context.Context, dbURL string) error {
+	db, err := openDB(ctx, dbURL)
+	if err != nil {
+		return err
+	}
+	return db.Close()
+}
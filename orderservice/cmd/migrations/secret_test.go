@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSecretSource returns dsn until it is refreshed, then rotated (when set)
+type fakeSecretSource struct {
+	dsn       string
+	rotated   string
+	refreshes int
+}
+
+func (f *fakeSecretSource) DSN(context.Context) (string, error) {
+	return f.dsn, nil
+}
+
+func (f *fakeSecretSource) Refresh(context.Context) (string, bool, error) {
+	f.refreshes++
+	if f.rotated == "" {
+		return f.dsn, false, nil
+	}
+	f.dsn, f.rotated = f.rotated, ""
+	return f.dsn, true, nil
+}
+
+// acceptOnly is a ping that accepts dbURL and rejects any other credentials
+func acceptOnly(dbURL string) func(context.Context, string) error {
+	return func(_ context.Context, got string) error {
+		if got != dbURL {
+			return &pgconn.PgError{Code: "28P01", Message: "password authentication failed"}
+		}
+		return nil
+	}
+}
+
+func TestCheckSecretURL(t *testing.T) {
+	t.Run("uses the secret when the database accepts it", func(t *testing.T) {
+		secret := &fakeSecretSource{dsn: "postgres://app:one@db/orders"}
+
+		dbURL, err := checkSecretURL(context.Background(), secret, acceptOnly("postgres://app:one@db/orders"))
+		require.NoError(t, err)
+		assert.Equal(t, "postgres://app:one@db/orders", dbURL)
+		assert.Zero(t, secret.refreshes)
+	})
+
+	t.Run("reads a rotated secret again", func(t *testing.T) {
+		secret := &fakeSecretSource{dsn: "postgres://app:one@db/orders", rotated: "postgres://app:two@db/orders"}
+
+		dbURL, err := checkSecretURL(context.Background(), secret, acceptOnly("postgres://app:two@db/orders"))
+		require.NoError(t, err)
+		assert.Equal(t, "postgres://app:two@db/orders", dbURL)
+		assert.Equal(t, 1, secret.refreshes)
+	})
+
+	t.Run("leaves other connection errors to the action", func(t *testing.T) {
+		secret := &fakeSecretSource{dsn: "postgres://app:one@db/orders"}
+
+		dbURL, err := checkSecretURL(context.Background(), secret, func(context.Context, string) error {
+			return errors.New("connection refused")
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "postgres://app:one@db/orders", dbURL)
+		assert.Zero(t, secret.refreshes)
+	})
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gostratum/dbx/migrate"
+)
+
+// defaultMigrationsDir is used when the migration config does not specify a directory
+const defaultMigrationsDir = "./migrations"
+
+// migrationFilePattern matches golang-migrate style file names: {version}_{name}.{up|down}.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// migrationFile is a single SQL file found in the migration source
+type migrationFile struct {
+	Version   uint
+	Name      string
+	Direction string
+	Filename  string
+	SQL       string
+}
+
+// migration pairs the up and down files sharing a version
+type migration struct {
+	Version  uint
+	Name     string
+	UpFile   string
+	DownFile string
+	UpSQL    string
+	DownSQL  string
+}
+
+// migrationsDir resolves the on-disk migration directory from config
+func migrationsDir(cfg *migrate.Config) string {
+	dir := strings.TrimPrefix(cfg.Dir, "file://")
+	if dir == "" {
+		return defaultMigrationsDir
+	}
+	return dir
+}
+
+// readMigrationFiles reads every migration file from the source, sorted by version then direction.
+// Files that do not follow the naming convention are ignored.
+func readMigrationFiles(fsys fs.FS) ([]migrationFile, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration source: %w", err)
+	}
+
+	var files []migrationFile
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+
+		files = append(files, migrationFile{
+			Version:   uint(version),
+			Name:      match[2],
+			Direction: match[3],
+			Filename:  entry.Name(),
+			SQL:       string(content),
+		})
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].Version != files[j].Version {
+			return files[i].Version < files[j].Version
+		}
+		return files[i].Direction > files[j].Direction // "up" before "down"
+	})
+
+	return files, nil
+}
+
+// groupMigrations pairs up/down files by version, returning them sorted ascending
+func groupMigrations(files []migrationFile) ([]migration, error) {
+	byVersion := make(map[uint]*migration)
+	for _, f := range files {
+		m, ok := byVersion[f.Version]
+		if !ok {
+			m = &migration{Version: f.Version, Name: f.Name}
+			byVersion[f.Version] = m
+		}
+
+		switch f.Direction {
+		case "up":
+			if m.UpFile != "" {
+				return nil, fmt.Errorf("duplicate up migration for version %d: %s and %s", f.Version, m.UpFile, f.Filename)
+			}
+			m.UpFile, m.UpSQL = f.Filename, f.SQL
+		case "down":
+			if m.DownFile != "" {
+				return nil, fmt.Errorf("duplicate down migration for version %d: %s and %s", f.Version, m.DownFile, f.Filename)
+			}
+			m.DownFile, m.DownSQL = f.Filename, f.SQL
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// loadMigrations reads and groups the migrations from the configured directory
+func loadMigrations(cfg *migrate.Config) ([]migration, error) {
+	files, err := readMigrationFiles(os.DirFS(migrationsDir(cfg)))
+	if err != nil {
+		return nil, err
+	}
+	return groupMigrations(files)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// validationIssue is a single problem found while validating migration files
+type validationIssue struct {
+	File    string
+	Message string
+}
+
+func (i validationIssue) String() string {
+	if i.File == "" {
+		return i.Message
+	}
+	return fmt.Sprintf("%s: %s", i.File, i.Message)
+}
+
+// dangerousStatement describes SQL that should not appear in a migration unguarded
+type dangerousStatement struct {
+	pattern *regexp.Regexp
+	guard   *regexp.Regexp
+	message string
+}
+
+// dangerousStatements lists the statements the validator rejects.
+// A statement matching guard is considered safe.
+var dangerousStatements = []dangerousStatement{
+	{
+		pattern: regexp.MustCompile(`(?i)^DROP\s+TABLE\b`),
+		guard:   regexp.MustCompile(`(?i)^DROP\s+TABLE\s+IF\s+EXISTS\b`),
+		message: "DROP TABLE without IF EXISTS",
+	},
+	{
+		pattern: regexp.MustCompile(`(?i)^DROP\s+(DATABASE|SCHEMA)\b`),
+		message: "drops a whole database or schema",
+	},
+	{
+		pattern: regexp.MustCompile(`(?i)^TRUNCATE\b`),
+		message: "TRUNCATE removes all rows",
+	},
+	{
+		pattern: regexp.MustCompile(`(?i)^DELETE\s+FROM\b`),
+		guard:   regexp.MustCompile(`(?i)\bWHERE\b`),
+		message: "DELETE without WHERE removes all rows",
+	},
+}
+
+// sqlStatements splits a migration into individual statements with comments and
+// surrounding whitespace removed. It is a lightweight splitter meant for linting,
+// not a full SQL parser.
+func sqlStatements(sql string) []string {
+	var b strings.Builder
+	for _, line := range strings.Split(sql, "\n") {
+		if idx := strings.Index(line, "--"); idx >= 0 {
+			line = line[:idx]
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	var statements []string
+	for _, stmt := range strings.Split(b.String(), ";") {
+		stmt = strings.Join(strings.Fields(stmt), " ")
+		if stmt != "" {
+			statements = append(statements, stmt)
+		}
+	}
+	return statements
+}
+
+// lintStatements returns a message for each dangerous statement in sql
+func lintStatements(sql string) []string {
+	var problems []string
+	for _, stmt := range sqlStatements(sql) {
+		for _, d := range dangerousStatements {
+			if d.pattern.MatchString(stmt) && (d.guard == nil || !d.guard.MatchString(stmt)) {
+				problems = append(problems, fmt.Sprintf("%s: %q", d.message, stmt))
+			}
+		}
+	}
+	return problems
+}
+
+// validateMigrations checks naming, ordering, pairing and dangerous statements
+// for every migration in the source
+func validateMigrations(fsys fs.FS) ([]validationIssue, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration source: %w", err)
+	}
+
+	var issues []validationIssue
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".sql" && !migrationFilePattern.MatchString(entry.Name()) {
+			issues = append(issues, validationIssue{File: entry.Name(), Message: "name does not match {version}_{name}.{up|down}.sql"})
+		}
+	}
+
+	files, err := readMigrationFiles(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	type versionFiles struct {
+		names map[string]bool
+		up    []string
+		down  []string
+	}
+	byVersion := make(map[uint]*versionFiles)
+	var versions []uint
+	widths := make(map[int]bool)
+
+	for _, f := range files {
+		vf, ok := byVersion[f.Version]
+		if !ok {
+			vf = &versionFiles{names: make(map[string]bool)}
+			byVersion[f.Version] = vf
+			versions = append(versions, f.Version)
+		}
+		vf.names[f.Name] = true
+		if f.Direction == "up" {
+			vf.up = append(vf.up, f.Filename)
+		} else {
+			vf.down = append(vf.down, f.Filename)
+		}
+		widths[strings.Index(f.Filename, "_")] = true
+
+		if strings.TrimSpace(f.SQL) == "" {
+			issues = append(issues, validationIssue{File: f.Filename, Message: "file is empty"})
+		}
+		for _, problem := range lintStatements(f.SQL) {
+			issues = append(issues, validationIssue{File: f.Filename, Message: problem})
+		}
+	}
+
+	if len(widths) > 1 {
+		issues = append(issues, validationIssue{Message: "version numbers use inconsistent zero-padding"})
+	}
+
+	for i, v := range versions {
+		vf := byVersion[v]
+		switch {
+		case len(vf.up) == 0:
+			issues = append(issues, validationIssue{Message: fmt.Sprintf("version %d has no up migration", v)})
+		case len(vf.up) > 1:
+			issues = append(issues, validationIssue{Message: fmt.Sprintf("duplicate version %d: %s", v, strings.Join(vf.up, ", "))})
+		}
+		switch {
+		case len(vf.down) == 0:
+			issues = append(issues, validationIssue{File: strings.Join(vf.up, ", "), Message: "missing down migration"})
+		case len(vf.down) > 1:
+			issues = append(issues, validationIssue{Message: fmt.Sprintf("duplicate version %d: %s", v, strings.Join(vf.down, ", "))})
+		}
+		if len(vf.names) > 1 {
+			issues = append(issues, validationIssue{Message: fmt.Sprintf("version %d has up/down files with different names", v)})
+		}
+		if i > 0 && v != versions[i-1]+1 {
+			issues = append(issues, validationIssue{Message: fmt.Sprintf("version gap between %d and %d", versions[i-1], v)})
+		}
+	}
+
+	return issues, nil
+}
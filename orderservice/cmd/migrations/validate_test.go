@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintStatements(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		problems int
+	}{
+		{name: "guarded drop", sql: "DROP TABLE IF EXISTS users CASCADE;", problems: 0},
+		{name: "unguarded drop", sql: "drop table users;", problems: 1},
+		{name: "truncate", sql: "TRUNCATE orders;", problems: 1},
+		{name: "delete with where", sql: "DELETE FROM orders WHERE status = 'stale';", problems: 0},
+		{name: "delete without where", sql: "DELETE FROM orders;", problems: 1},
+		{name: "commented out", sql: "-- DROP TABLE users;\nCREATE TABLE x (id int);", problems: 0},
+		{name: "multiple statements", sql: "DROP TABLE a;\nDROP TABLE b;", problems: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Len(t, lintStatements(tt.sql), tt.problems)
+		})
+	}
+}
+
+func TestValidateMigrations(t *testing.T) {
+	t.Run("valid migrations", func(t *testing.T) {
+		issues, err := validateMigrations(testMigrationFS())
+		require.NoError(t, err)
+		assert.Empty(t, issues)
+	})
+
+	t.Run("problems are reported", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"000001_create_users.up.sql":  {Data: []byte("CREATE TABLE users (id TEXT);")},
+			"000002_create_orders.up.sql": {Data: []byte("CREATE TABLE orders (id TEXT);")},
+			"000002_other.up.sql":         {Data: []byte("CREATE TABLE other (id TEXT);")},
+			"000004_drop.up.sql":          {Data: []byte("DROP TABLE other;")},
+			"000004_drop.down.sql":        {Data: []byte("CREATE TABLE other (id TEXT);")},
+			"bad_name.sql":                {Data: []byte("SELECT 1;")},
+		}
+
+		issues, err := validateMigrations(fsys)
+		require.NoError(t, err)
+
+		var messages []string
+		for _, issue := range issues {
+			messages = append(messages, issue.String())
+		}
+		joined := strings.Join(messages, "\n")
+
+		assert.Contains(t, joined, "bad_name.sql: name does not match")
+		assert.Contains(t, joined, "missing down migration")
+		assert.Contains(t, joined, "duplicate version 2")
+		assert.Contains(t, joined, "version gap between 2 and 4")
+		assert.Contains(t, joined, "DROP TABLE without IF EXISTS")
+	})
+}
+
+func TestValidateRepoMigrations(t *testing.T) {
+	issues, err := validateMigrations(os.DirFS("../../migrations"))
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
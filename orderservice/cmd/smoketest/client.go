@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// client calls orderservice's HTTP API
+type client struct {
+	baseURL string
+	http    *http.Client
+}
+
+// response is the responsex envelope, and the body of the health endpoints,
+// which report details instead of data
+type response struct {
+	OK      bool            `json:"ok"`
+	Data    json.RawMessage `json:"data"`
+	Error   *apiError       `json:"error"`
+	Details map[string]any  `json:"details"`
+}
+
+// apiError is the error of a responsex envelope
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// statusError is a response with a status other than the one expected, with
+// what its body said about why
+type statusError struct {
+	got, want int
+	reason    string
+}
+
+func (e *statusError) Error() string {
+	msg := fmt.Sprintf("status %d, want %d", e.got, e.want)
+	if e.reason != "" {
+		msg += ": " + e.reason
+	}
+	return msg
+}
+
+// do sends body as JSON, unless nil, and decodes the response. A status other
+// than want is a *statusError.
+func (c *client) do(ctx context.Context, method, path string, body any, want int) (*response, error) {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var r response
+	decodeErr := json.NewDecoder(resp.Body).Decode(&r)
+	if resp.StatusCode != want {
+		return nil, &statusError{got: resp.StatusCode, want: want, reason: r.reason()}
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", decodeErr)
+	}
+	return &r, nil
+}
+
+// data decodes the data of the envelope into v
+func (r *response) data(v any) error {
+	if len(r.Data) == 0 {
+		return fmt.Errorf("response has no data")
+	}
+	if err := json.Unmarshal(r.Data, v); err != nil {
+		return fmt.Errorf("failed to decode response data: %w", err)
+	}
+	return nil
+}
+
+// reason is the error code and message, or the health details, of a failed
+// response; empty when its body had neither
+func (r *response) reason() string {
+	if r.Error != nil {
+		return r.Error.Code + ": " + r.Error.Message
+	}
+	if len(r.Details) == 0 {
+		return ""
+	}
+	details := make([]string, 0, len(r.Details))
+	for name, detail := range r.Details {
+		details = append(details, fmt.Sprintf("%s=%v", name, detail))
+	}
+	sort.Strings(details)
+	return strings.Join(details, ", ")
+}
@@ -0,0 +1,115 @@
+// Command smoketest runs a short flow against a deployed orderservice: it
+// creates a user, creates an order for them, reads the order back and checks
+// the health endpoints. It prints how each step went and exits with 1 when
+// one failed, so a deploy pipeline can run it as a gate after rolling out.
+//
+// The flow writes a real user and order, which reserve stock and charge a
+// payment, so point it at an environment where that is harmless.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+// exitFailed is the exit code when a step failed, or the service did not
+// become ready within -wait
+const exitFailed = 1
+
+func main() {
+	var opts options
+	var skus string
+	var wait time.Duration
+	flag.StringVar(&opts.BaseURL, "url", "http://localhost:8080", "orderservice base URL")
+	flag.DurationVar(&opts.Timeout, "timeout", 10*time.Second, "Timeout of one request")
+	flag.StringVar(&skus, "skus", "LAPTOP,MOUSE", "Comma-separated SKUs of the order; they must be in stock")
+	flag.DurationVar(&wait, "wait", 0, "How long to wait for /healthz to report ready before the flow (0 = do not wait)")
+	flag.Parse()
+
+	var err error
+	if opts.SKUs, err = parseSKUs(skus); err != nil {
+		log.Fatalf("Invalid -skus: %v", err)
+	}
+	opts.BaseURL = strings.TrimRight(opts.BaseURL, "/")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	st := newSmokeTest(opts)
+	if wait > 0 {
+		waitCtx, cancel := context.WithTimeout(ctx, wait)
+		err := st.waitReady(waitCtx, time.Second)
+		cancel()
+		if err != nil {
+			log.Printf("%s did not become ready within %s: %v", opts.BaseURL, wait, err)
+			os.Exit(exitFailed)
+		}
+	}
+
+	started := time.Now()
+	results := st.runSteps(ctx)
+	if err := writeResults(os.Stdout, results); err != nil {
+		log.Fatalf("Failed to write results: %v", err)
+	}
+	if failed(results) {
+		log.Printf("Smoke test of %s failed", opts.BaseURL)
+		os.Exit(exitFailed)
+	}
+	log.Printf("Smoke test of %s passed in %s", opts.BaseURL, time.Since(started).Round(time.Millisecond))
+}
+
+// parseSKUs reads a list such as "LAPTOP,MOUSE"; each SKU may appear once
+func parseSKUs(s string) ([]string, error) {
+	var skus []string
+	seen := map[string]bool{}
+	for _, sku := range strings.Split(s, ",") {
+		sku = strings.TrimSpace(sku)
+		if sku == "" {
+			continue
+		}
+		if seen[sku] {
+			return nil, fmt.Errorf("SKU %q given twice", sku)
+		}
+		seen[sku] = true
+		skus = append(skus, sku)
+	}
+	if len(skus) == 0 {
+		return nil, fmt.Errorf("no SKU given")
+	}
+	return skus, nil
+}
+
+// writeResults writes a line per step: PASS, FAIL with the error, or SKIP
+func writeResults(w io.Writer, results []result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, r := range results {
+		switch {
+		case r.Skipped:
+			fmt.Fprintf(tw, "SKIP\t%s\t\t\n", r.Step)
+		case r.Err != nil:
+			fmt.Fprintf(tw, "FAIL\t%s\t%s\t%v\n", r.Step, r.Duration.Round(time.Millisecond), r.Err)
+		default:
+			fmt.Fprintf(tw, "PASS\t%s\t%s\t\n", r.Step, r.Duration.Round(time.Millisecond))
+		}
+	}
+	return tw.Flush()
+}
+
+// failed reports whether a step failed
+func failed(results []result) bool {
+	for _, r := range results {
+		if r.Err != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// options configure a smoke test
+type options struct {
+	BaseURL string
+	// Timeout is the timeout of one request
+	Timeout time.Duration
+	// SKUs are the products of the order; they must be in stock
+	SKUs []string
+}
+
+// step is one request of the flow. It checks the response, and keeps what
+// later steps need in the smokeTest.
+type step struct {
+	name string
+	run  func(st *smokeTest, ctx context.Context) error
+}
+
+// steps are the flow, in order. Each step needs the ones before it to have
+// passed, so the flow stops at the first that fails.
+var steps = []step{
+	{name: "create_user", run: (*smokeTest).createUser},
+	{name: "create_order", run: (*smokeTest).createOrder},
+	{name: "get_order", run: (*smokeTest).getOrder},
+	{name: "health", run: (*smokeTest).health},
+}
+
+// result is how a step went. A step after one that failed is skipped.
+type result struct {
+	Step     string
+	Duration time.Duration
+	Err      error
+	Skipped  bool
+}
+
+// user and order are the fields of the responses the steps check
+type user struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type order struct {
+	ID     string  `json:"id"`
+	UserID string  `json:"user_id"`
+	Status string  `json:"status"`
+	Total  float64 `json:"total"`
+	Items  []item  `json:"items"`
+}
+
+type item struct {
+	SKU   string  `json:"sku"`
+	Qty   int     `json:"qty"`
+	Price float64 `json:"price"`
+}
+
+// smokeTest runs the flow once against a service
+type smokeTest struct {
+	opts   options
+	client *client
+	// run tells the email of this smoke test apart from earlier ones
+	run string
+
+	user  *user
+	order *order
+}
+
+func newSmokeTest(opts options) *smokeTest {
+	return &smokeTest{
+		opts:   opts,
+		client: &client{baseURL: opts.BaseURL, http: &http.Client{Timeout: opts.Timeout}},
+		run:    strconv.FormatInt(time.Now().UnixNano(), 36),
+	}
+}
+
+// runSteps runs the steps in order, and returns a result for each
+func (st *smokeTest) runSteps(ctx context.Context) []result {
+	results := make([]result, 0, len(steps))
+	failed := false
+	for _, s := range steps {
+		if failed {
+			results = append(results, result{Step: s.name, Skipped: true})
+			continue
+		}
+		begin := time.Now()
+		err := s.run(st, ctx)
+		results = append(results, result{Step: s.name, Duration: time.Since(begin), Err: err})
+		failed = err != nil
+	}
+	return results
+}
+
+// waitReady polls /healthz every interval until the service is ready, for
+// a service that is still starting after a deploy. It returns the last
+// reason the service was not ready once ctx is done.
+func (st *smokeTest) waitReady(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var last error
+	for {
+		_, err := st.client.do(ctx, http.MethodGet, "/healthz", nil, http.StatusOK)
+		if err == nil {
+			return nil
+		}
+		// A poll cut off by ctx says nothing about the service
+		if ctx.Err() == nil || last == nil {
+			last = err
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("not ready: %w", last)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (st *smokeTest) createUser(ctx context.Context) error {
+	want := user{Name: "Smoke Test", Email: fmt.Sprintf("smoketest-%s@example.com", st.run)}
+	resp, err := st.client.do(ctx, http.MethodPost, "/users", map[string]any{
+		"name":  want.Name,
+		"email": want.Email,
+	}, http.StatusCreated)
+	if err != nil {
+		return err
+	}
+
+	var got user
+	if err := resp.data(&got); err != nil {
+		return err
+	}
+	switch {
+	case got.ID == "":
+		return errors.New("user has no id")
+	case got.Name != want.Name || got.Email != want.Email:
+		return fmt.Errorf("user is %q <%s>, want %q <%s>", got.Name, got.Email, want.Name, want.Email)
+	}
+	st.user = &got
+	return nil
+}
+
+func (st *smokeTest) createOrder(ctx context.Context) error {
+	want := order{UserID: st.user.ID, Status: "pending"}
+	for i, sku := range st.opts.SKUs {
+		it := item{SKU: sku, Qty: i + 1, Price: 9.99}
+		want.Items = append(want.Items, it)
+		want.Total += it.Price * float64(it.Qty)
+	}
+	resp, err := st.client.do(ctx, http.MethodPost, "/orders", map[string]any{
+		"user_id": want.UserID,
+		"items":   want.Items,
+	}, http.StatusCreated)
+	if err != nil {
+		return err
+	}
+
+	var got order
+	if err := resp.data(&got); err != nil {
+		return err
+	}
+	if got.ID == "" {
+		return errors.New("order has no id")
+	}
+	want.ID = got.ID
+	if err := checkOrder(got, want); err != nil {
+		return err
+	}
+	st.order = &got
+	return nil
+}
+
+func (st *smokeTest) getOrder(ctx context.Context) error {
+	resp, err := st.client.do(ctx, http.MethodGet, "/orders/"+st.order.ID, nil, http.StatusOK)
+	if err != nil {
+		return err
+	}
+
+	var got order
+	if err := resp.data(&got); err != nil {
+		return err
+	}
+	return checkOrder(got, *st.order)
+}
+
+// health checks the service is both live and ready
+func (st *smokeTest) health(ctx context.Context) error {
+	for _, path := range []string{"/livez", "/healthz"} {
+		resp, err := st.client.do(ctx, http.MethodGet, path, nil, http.StatusOK)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if !resp.OK {
+			return fmt.Errorf("%s: not ok: %s", path, resp.reason())
+		}
+	}
+	return nil
+}
+
+// checkOrder returns an error naming the first field of got that differs
+// from want. Items are compared by SKU, quantity and price, in any order.
+func checkOrder(got, want order) error {
+	switch {
+	case got.ID != want.ID:
+		return fmt.Errorf("order id is %q, want %q", got.ID, want.ID)
+	case got.UserID != want.UserID:
+		return fmt.Errorf("order user_id is %q, want %q", got.UserID, want.UserID)
+	case got.Status != want.Status:
+		return fmt.Errorf("order status is %q, want %q", got.Status, want.Status)
+	case math.Abs(got.Total-want.Total) > 0.005:
+		return fmt.Errorf("order total is %.2f, want %.2f", got.Total, want.Total)
+	case len(got.Items) != len(want.Items):
+		return fmt.Errorf("order has %d items, want %d", len(got.Items), len(want.Items))
+	}
+	bySKU := make(map[string]item, len(got.Items))
+	for _, it := range got.Items {
+		bySKU[it.SKU] = it
+	}
+	for _, it := range want.Items {
+		if bySKU[it.SKU] != it {
+			return fmt.Errorf("order item %s is %+v, want %+v", it.SKU, bySKU[it.SKU], it)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOrderService answers like orderservice, keeping users and orders in
+// memory. Its fields break it in the ways the smoke test must catch.
+type fakeOrderService struct {
+	mu     sync.Mutex
+	users  map[string]map[string]any
+	orders map[string]map[string]any
+	seq    int
+
+	// outOfStock fails every order with 409
+	outOfStock bool
+	// totalDrift is added to the total of the orders it reads back
+	totalDrift float64
+	// notReadyFor is how many /healthz calls report the database down
+	notReadyFor int
+}
+
+func newFakeOrderService() *fakeOrderService {
+	return &fakeOrderService{users: map[string]map[string]any{}, orders: map[string]map[string]any{}}
+}
+
+func (f *fakeOrderService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	reply := func(status int, body map[string]any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(body)
+	}
+	data := func(status int, v map[string]any) { reply(status, map[string]any{"ok": true, "data": v}) }
+	fail := func(status int, code, message string) {
+		reply(status, map[string]any{"ok": false, "error": map[string]any{"code": code, "message": message}})
+	}
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/users":
+		var user map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&user)
+		f.seq++
+		user["id"] = fmt.Sprintf("u-%d", f.seq)
+		f.users[user["id"].(string)] = user
+		data(http.StatusCreated, user)
+	case r.Method == http.MethodPost && r.URL.Path == "/orders":
+		if f.outOfStock {
+			fail(http.StatusConflict, "OUT_OF_STOCK", "out of stock: LAPTOP")
+			return
+		}
+		var order map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&order)
+		if f.users[order["user_id"].(string)] == nil {
+			fail(http.StatusNotFound, "NOT_FOUND", "user not found")
+			return
+		}
+		total := 0.0
+		for _, it := range order["items"].([]any) {
+			it := it.(map[string]any)
+			total += it["price"].(float64) * it["qty"].(float64)
+		}
+		f.seq++
+		order["id"] = fmt.Sprintf("o-%d", f.seq)
+		order["status"] = "pending"
+		order["total"] = total
+		f.orders[order["id"].(string)] = order
+		data(http.StatusCreated, order)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/orders/"):
+		order := f.orders[strings.TrimPrefix(r.URL.Path, "/orders/")]
+		if order == nil {
+			fail(http.StatusNotFound, "NOT_FOUND", "order not found")
+			return
+		}
+		read := map[string]any{}
+		for k, v := range order {
+			read[k] = v
+		}
+		read["total"] = order["total"].(float64) + f.totalDrift
+		data(http.StatusOK, read)
+	case r.Method == http.MethodGet && r.URL.Path == "/livez":
+		reply(http.StatusOK, map[string]any{"ok": true, "details": map[string]any{"database": "ok"}})
+	case r.Method == http.MethodGet && r.URL.Path == "/healthz":
+		if f.notReadyFor > 0 {
+			f.notReadyFor--
+			reply(http.StatusServiceUnavailable, map[string]any{"ok": false, "details": map[string]any{"database": "connection refused"}})
+			return
+		}
+		reply(http.StatusOK, map[string]any{"ok": true, "details": map[string]any{"database": "ok"}})
+	default:
+		fail(http.StatusNotFound, "NOT_FOUND", "no route")
+	}
+}
+
+func newTestSmokeTest(t *testing.T, fake *fakeOrderService) *smokeTest {
+	t.Helper()
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	return newSmokeTest(options{BaseURL: srv.URL, Timeout: time.Second, SKUs: []string{"LAPTOP", "MOUSE"}})
+}
+
+// outcomes is each result as PASS, FAIL or SKIP followed by the step
+func outcomes(results []result) []string {
+	var out []string
+	for _, r := range results {
+		switch {
+		case r.Skipped:
+			out = append(out, "SKIP "+r.Step)
+		case r.Err != nil:
+			out = append(out, "FAIL "+r.Step)
+		default:
+			out = append(out, "PASS "+r.Step)
+		}
+	}
+	return out
+}
+
+func TestSmokeTest(t *testing.T) {
+	tests := []struct {
+		name    string
+		arrange func(f *fakeOrderService)
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "passes against a working service",
+			want: []string{"PASS create_user", "PASS create_order", "PASS get_order", "PASS health"},
+		},
+		{
+			name:    "stops at a failed order",
+			arrange: func(f *fakeOrderService) { f.outOfStock = true },
+			want:    []string{"PASS create_user", "FAIL create_order", "SKIP get_order", "SKIP health"},
+			wantErr: "status 409, want 201: OUT_OF_STOCK: out of stock: LAPTOP",
+		},
+		{
+			name:    "fails when the order reads back different",
+			arrange: func(f *fakeOrderService) { f.totalDrift = 1 },
+			want:    []string{"PASS create_user", "PASS create_order", "FAIL get_order", "SKIP health"},
+			wantErr: "order total is 30.97, want 29.97",
+		},
+		{
+			name:    "fails when the service is not ready",
+			arrange: func(f *fakeOrderService) { f.notReadyFor = 1 },
+			want:    []string{"PASS create_user", "PASS create_order", "PASS get_order", "FAIL health"},
+			wantErr: "/healthz: status 503, want 200: database=connection refused",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeOrderService()
+			if tt.arrange != nil {
+				tt.arrange(fake)
+			}
+			results := newTestSmokeTest(t, fake).runSteps(context.Background())
+
+			assert.Equal(t, tt.want, outcomes(results))
+			assert.Equal(t, tt.wantErr != "", failed(results))
+			for _, r := range results {
+				if r.Err != nil {
+					assert.EqualError(t, r.Err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestSmokeTest_WaitReady(t *testing.T) {
+	t.Run("until ready", func(t *testing.T) {
+		fake := newFakeOrderService()
+		fake.notReadyFor = 3
+		st := newTestSmokeTest(t, fake)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		require.NoError(t, st.waitReady(ctx, 10*time.Millisecond))
+		assert.Zero(t, fake.notReadyFor)
+	})
+
+	t.Run("gives up with the last reason", func(t *testing.T) {
+		fake := newFakeOrderService()
+		fake.notReadyFor = 1 << 30
+		st := newTestSmokeTest(t, fake)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := st.waitReady(ctx, 10*time.Millisecond)
+		assert.EqualError(t, err, "not ready: status 503, want 200: database=connection refused")
+	})
+}
+
+func TestParseSKUs(t *testing.T) {
+	skus, err := parseSKUs(" LAPTOP, MOUSE ,,")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"LAPTOP", "MOUSE"}, skus)
+
+	for _, bad := range []string{"", " , ", "LAPTOP,LAPTOP"} {
+		_, err := parseSKUs(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestWriteResults(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, writeResults(&out, []result{
+		{Step: "create_user", Duration: 12 * time.Millisecond},
+		{Step: "create_order", Duration: 30 * time.Millisecond, Err: fmt.Errorf("status 409, want 201")},
+		{Step: "get_order", Skipped: true},
+	}))
+	assert.Equal(t, ""+
+		"PASS  create_user   12ms  \n"+
+		"FAIL  create_order  30ms  status 409, want 201\n"+
+		"SKIP  get_order           \n", out.String())
+}
@@ -0,0 +1,190 @@
+package configs
+
+import (
+	"cmp"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// EncryptedPrefix starts an encrypted value, enc:<base64>. It may stand for a
+// whole value or for part of one, such as the password of a DSN.
+const EncryptedPrefix = "enc:"
+
+// KeyEnv holds the key that decrypts encrypted values, 32 bytes in base64,
+// and KeyKMSEnv the same key encrypted with AWS KMS, which KMS decrypts at
+// startup. configcrypt -genkey makes a key.
+const (
+	KeyEnv    = "CONFIG_KEY"
+	KeyKMSEnv = "CONFIG_KEY_KMS"
+)
+
+// KeySize is the size of a key: AES-256
+const KeySize = 32
+
+// encrypted is an encrypted value: a nonce and a GCM tag at least, 28 bytes,
+// which take 38 characters of base64
+var encrypted = regexp.MustCompile(`\b` + EncryptedPrefix + `[A-Za-z0-9_-]{38,}`)
+
+// Cipher encrypts and decrypts the values of the configuration with AES-GCM,
+// which also detects a value encrypted with another key, or altered
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher returns a Cipher for key, of KeySize bytes
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("the key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// GenerateKey returns a random key, in base64 as KeyEnv takes it
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate a key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// Encrypt returns plaintext encrypted, enc:<base64>. The nonce is random, so
+// the same plaintext is encrypted differently each time.
+func (c *Cipher) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate a nonce: %w", err)
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return EncryptedPrefix + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns value with each encrypted value in it decrypted. The error
+// does not echo value, which the plaintext around the encrypted part may make
+// a secret.
+func (c *Cipher) Decrypt(value string) (string, error) {
+	var err error
+	decrypted := encrypted.ReplaceAllStringFunc(value, func(token string) string {
+		plaintext, tokenErr := c.decrypt(strings.TrimPrefix(token, EncryptedPrefix))
+		if tokenErr != nil {
+			err = cmp.Or(err, tokenErr)
+			return token
+		}
+		return plaintext
+	})
+	if err != nil {
+		return "", err
+	}
+	return decrypted, nil
+}
+
+func (c *Cipher) decrypt(encoded string) (string, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.New("failed to decrypt: the value was encrypted with another key, or altered")
+	}
+	return string(plaintext), nil
+}
+
+// Encrypted reports whether value holds an encrypted value
+func Encrypted(value string) bool {
+	return encrypted.MatchString(value)
+}
+
+// DecryptValues decrypts the encrypted values of the configuration with c:
+// those of base.yaml in each of dirs, and those of the environment, where
+// ApplyProfile put those of the overlays. Each is decrypted into the
+// environment variable of its key, which configx reads over base.yaml, so
+// every module, dbx's included, binds the plaintext. It runs after
+// ApplyProfile and before configx.New, and fails naming the key when a value
+// is encrypted and c is nil, or does not decrypt.
+func DecryptValues(c *Cipher, dirs ...string) error {
+	return decryptValues(c, dirs, os.Environ, os.LookupEnv, os.Setenv)
+}
+
+func decryptValues(c *Cipher, dirs []string, environ func() []string, lookupEnv func(string) (string, bool), setenv func(string, string) error) error {
+	// Environment variables, under their own names
+	values, sources := map[string]string{}, map[string]string{}
+	for _, kv := range environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if (strings.HasPrefix(name, envPrefix) || slices.Contains(DSNEnv, name)) && Encrypted(value) {
+			values[name], sources[name] = value, name
+		}
+	}
+	// base.yaml, under the variables of its keys when the environment does not
+	// set them already
+	for _, dir := range dirs {
+		path := filepath.Join(dir, "base.yaml")
+		base, err := readYAML(path)
+		if err != nil {
+			return err
+		}
+		fromFile := map[string]string{}
+		encryptedValues("", base, fromFile)
+		for key, value := range fromFile {
+			if _, ok := lookupEnv(envName(key)); !ok {
+				values[envName(key)], sources[envName(key)] = value, key+" in "+path
+			}
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if c == nil {
+			return fmt.Errorf("%s is encrypted; set %s or %s", sources[name], KeyEnv, KeyKMSEnv)
+		}
+		plaintext, err := c.Decrypt(values[name])
+		if err != nil {
+			return fmt.Errorf("%s: %w", sources[name], err)
+		}
+		if err := setenv(name, plaintext); err != nil {
+			return fmt.Errorf("failed to set %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// encryptedValues adds the string values of section that hold an encrypted
+// value to values, under their dotted keys. Lists are skipped.
+func encryptedValues(prefix string, section map[string]any, values map[string]string) {
+	for key, value := range section {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch value := value.(type) {
+		case map[string]any:
+			encryptedValues(key, value, values)
+		case string:
+			if Encrypted(value) {
+				values[key] = value
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package configs
+
+import (
+	"encoding/base64"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCipher returns a Cipher for a new random key
+func newCipher(t *testing.T) *Cipher {
+	t.Helper()
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	raw, err := base64.StdEncoding.DecodeString(key)
+	require.NoError(t, err)
+	c, err := NewCipher(raw)
+	require.NoError(t, err)
+	return c
+}
+
+// encrypt encrypts plaintext with c
+func encrypt(t *testing.T, c *Cipher, plaintext string) string {
+	t.Helper()
+	value, err := c.Encrypt(plaintext)
+	require.NoError(t, err)
+	return value
+}
+
+func TestCipher(t *testing.T) {
+	c := newCipher(t)
+
+	value := encrypt(t, c, "s3cr3t")
+	assert.True(t, strings.HasPrefix(value, EncryptedPrefix))
+	assert.True(t, Encrypted(value))
+	assert.NotEqual(t, value, encrypt(t, c, "s3cr3t"), "each encryption has a nonce of its own")
+
+	plaintext, err := c.Decrypt(value)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", plaintext)
+
+	dsn, err := c.Decrypt("postgres://orders:" + encrypt(t, c, "p@ss") + "@db:5432/orders?sslmode=require")
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://orders:p@ss@db:5432/orders?sslmode=require", dsn, "a value may hold an encrypted part")
+
+	plain, err := c.Decrypt("enc:short and plain")
+	require.NoError(t, err)
+	assert.Equal(t, "enc:short and plain", plain, "only values that look encrypted are decrypted")
+}
+
+func TestCipher_Rejects(t *testing.T) {
+	c := newCipher(t)
+	value := encrypt(t, c, "s3cr3t")
+
+	_, err := newCipher(t).Decrypt(value)
+	assert.EqualError(t, err, "failed to decrypt: the value was encrypted with another key, or altered")
+
+	// A character of the nonce, every bit of which counts
+	i := len(EncryptedPrefix) + 4
+	flipped := "A"
+	if value[i] == 'A' {
+		flipped = "B"
+	}
+	tampered := value[:i] + flipped + value[i+1:]
+	_, err = c.Decrypt("postgres://orders:" + tampered + "@db/orders")
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "orders", "the value is not echoed")
+
+	_, err = NewCipher([]byte("too short"))
+	assert.EqualError(t, err, "the key must be 32 bytes, got 9")
+}
+
+func TestDecryptValues(t *testing.T) {
+	c := newCipher(t)
+	dir := writeFiles(t, map[string]string{
+		"base.yaml": "db:\n  databases:\n    primary:\n      dsn: \"postgres://orders:" + encrypt(t, c, "db-pass") + "@db/orders\"\n" +
+			"payment:\n  secret: \"" + encrypt(t, c, "from-file") + "\"\n" +
+			"uploads:\n  avatar:\n    allowed_types: [image/png]\n" +
+			"http:\n  addr: \":8080\"\n",
+	})
+	env := fakeEnv{
+		"STRATUM_PAYMENT_SECRET": encrypt(t, c, "from-overlay"),
+		"DATABASE_URL":           "postgres://app:" + encrypt(t, c, "url-pass") + "@db/orders",
+		"HOME":                   "/home/" + encrypt(t, c, "not config"),
+	}
+	home := env["HOME"]
+	environ := func() []string {
+		var kvs []string
+		for name, value := range env {
+			kvs = append(kvs, name+"="+value)
+		}
+		return kvs
+	}
+
+	require.NoError(t, decryptValues(c, []string{dir}, environ, env.lookup, env.set))
+
+	assert.Equal(t, fakeEnv{
+		"STRATUM_DB_DATABASES_PRIMARY_DSN": "postgres://orders:db-pass@db/orders",
+		"STRATUM_PAYMENT_SECRET":           "from-overlay",
+		"DATABASE_URL":                     "postgres://app:url-pass@db/orders",
+		"HOME":                             home,
+	}, env, "the environment goes over base.yaml, and only configuration is decrypted")
+}
+
+func TestDecryptValues_Invalid(t *testing.T) {
+	c := newCipher(t)
+	dir := writeFiles(t, map[string]string{"base.yaml": "payment:\n  secret: \"" + encrypt(t, c, "s3cr3t") + "\"\n"})
+	env := fakeEnv{}
+	environ := func() []string { return nil }
+
+	err := decryptValues(nil, []string{dir}, environ, env.lookup, env.set)
+	assert.EqualError(t, err, "payment.secret in "+filepath.Join(dir, "base.yaml")+" is encrypted; set CONFIG_KEY or CONFIG_KEY_KMS")
+
+	err = decryptValues(newCipher(t), []string{dir}, environ, env.lookup, env.set)
+	assert.ErrorContains(t, err, "payment.secret in ")
+	assert.ErrorContains(t, err, "encrypted with another key")
+	assert.Empty(t, env, "nothing is set when a value does not decrypt")
+}
+
+func TestOverlays_Encrypted(t *testing.T) {
+	env := fakeEnv{"APP_ENV": "staging"}
+	_, err := applyProfile([]string{"."}, env.lookup, env.set)
+	require.NoError(t, err)
+
+	assert.True(t, Encrypted(env["STRATUM_DB_DATABASES_PRIMARY_DSN"]), "staging's DSN password is encrypted")
+	assert.True(t, Encrypted(env["STRATUM_PAYMENT_SECRET"]), "staging's signing secret is encrypted")
+}
@@ -0,0 +1,137 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gostratum/core/logx"
+	"go.yaml.in/yaml/v3"
+)
+
+// ProfileEnv names the profile: dev, staging or prod, dev when unset
+const ProfileEnv = "APP_ENV"
+
+// LocalFile holds a developer's own overrides, over those of the profile. It
+// is not committed.
+const LocalFile = "local.yaml"
+
+// envPrefix starts the environment variable that overrides a key
+const envPrefix = "STRATUM_"
+
+// Profile is the configuration profile the service runs with
+type Profile struct {
+	// Name is the profile, which app.env takes
+	Name string
+	// Files are the overlays read over base.yaml, in order
+	Files []string
+}
+
+// ApplyProfile reads the overlay of the profile, <profile>.yaml, then
+// local.yaml from each of dirs, and sets the environment variable of every key
+// they hold, STRATUM_HTTP_ADDR for http.addr, which configx reads over
+// base.yaml. A variable already set is left alone, so the environment still
+// comes first. Overlays set values, not lists, which stay in base.yaml.
+func ApplyProfile(dirs ...string) (Profile, error) {
+	return applyProfile(dirs, os.LookupEnv, os.Setenv)
+}
+
+func applyProfile(dirs []string, lookupEnv func(string) (string, bool), setenv func(string, string) error) (Profile, error) {
+	name, _ := lookupEnv(ProfileEnv)
+	if name = strings.TrimSpace(name); name == "" {
+		name = "dev"
+	}
+	if !slices.Contains(envs, name) {
+		return Profile{}, fmt.Errorf("%s must be one of %s, got %q", ProfileEnv, strings.Join(envs, ", "), name)
+	}
+
+	profile := Profile{Name: name}
+	values := map[string]string{"app.env": name}
+	for _, dir := range dirs {
+		for _, file := range []string{name + ".yaml", LocalFile} {
+			path := filepath.Join(dir, file)
+			overlay, err := readYAML(path)
+			if err != nil {
+				return Profile{}, err
+			}
+			if overlay == nil {
+				continue
+			}
+			if err := flatten("", overlay, values); err != nil {
+				return Profile{}, fmt.Errorf("invalid %s: %w", path, err)
+			}
+			profile.Files = append(profile.Files, path)
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env := envName(key)
+		if _, ok := lookupEnv(env); ok {
+			continue
+		}
+		if err := setenv(env, values[key]); err != nil {
+			return Profile{}, fmt.Errorf("failed to set %s: %w", env, err)
+		}
+	}
+	return profile, nil
+}
+
+// readYAML reads a YAML file, or returns nil when there is none
+func readYAML(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	section := map[string]any{}
+	if err := yaml.Unmarshal(data, &section); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return section, nil
+}
+
+// envName returns the environment variable that overrides key
+func envName(key string) string {
+	return envPrefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+// flatten adds the values of section to values under their dotted keys
+func flatten(prefix string, section map[string]any, values map[string]string) error {
+	for key, value := range section {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch value := value.(type) {
+		case map[string]any:
+			if err := flatten(key, value, values); err != nil {
+				return err
+			}
+		case []any:
+			return fmt.Errorf("%s is a list; lists are set in base.yaml", key)
+		case nil:
+		default:
+			values[key] = fmt.Sprint(value)
+		}
+	}
+	return nil
+}
+
+// LogProfile logs the profile the service runs with, and the overlays it
+// read. It is meant for fx.Invoke.
+func LogProfile(profile Profile, log logx.Logger) {
+	log.Info("configuration profile",
+		logx.String("profile", profile.Name),
+		logx.String("overlays", strings.Join(profile.Files, ", ")))
+}
@@ -0,0 +1,407 @@
+package contracts_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core"
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/require"
+
+	httpAdapter "github.com/gostratum/examples/orderservice/internal/adapter/http"
+	"github.com/gostratum/examples/orderservice/internal/domain"
+	"github.com/gostratum/examples/orderservice/internal/uploads"
+	"github.com/gostratum/examples/orderservice/internal/usecase"
+)
+
+// contract is what one consumer expects of orderservice
+type contract struct {
+	Consumer     string        `json:"consumer"`
+	Provider     string        `json:"provider"`
+	Interactions []interaction `json:"interactions"`
+}
+
+// interaction is one request and the response the consumer relies on
+type interaction struct {
+	Description string          `json:"description"`
+	Given       []providerState `json:"given"`
+	Request     struct {
+		Method string          `json:"method"`
+		Path   string          `json:"path"`
+		Body   json.RawMessage `json:"body"`
+	} `json:"request"`
+	Response struct {
+		Status  int               `json:"status"`
+		Headers map[string]string `json:"headers"`
+		// Body is matched by type: every key must be there with a value of
+		// the same JSON type, and every array element must match the first
+		// element of the expected array. Keys the consumer does not list
+		// may come and go.
+		Body any `json:"body"`
+		// Exact are the values the consumer depends on, by dotted path
+		Exact map[string]any `json:"exact"`
+	} `json:"response"`
+}
+
+// providerState is a precondition the provider sets up before the request
+type providerState struct {
+	State  string            `json:"state"`
+	Params map[string]string `json:"params"`
+}
+
+// states sets up each provider state the contracts name
+var states = map[string]func(p *provider, params map[string]string){
+	"a user exists": func(p *provider, params map[string]string) {
+		u := domain.NewUser("Jane Smith", "jane@example.com")
+		u.ID = params["id"]
+		p.users.users[u.ID] = u
+	},
+	"the email is in use": func(p *provider, params map[string]string) {
+		u := domain.NewUser("Jane Smith", params["email"])
+		p.users.users[u.ID] = u
+	},
+	"an order exists": func(p *provider, params map[string]string) {
+		o := domain.NewOrder("5b0a7a4e-2f41-4c55-8a1c-4d5e6f708192")
+		o.ID = params["id"]
+		_ = o.AddItem(domain.Item{ID: 1, OrderID: o.ID, SKU: "LAPTOP", Qty: 1, Price: 1200})
+		p.orders.orders[o.ID] = o
+	},
+	"an item is out of stock": func(p *provider, params map[string]string) {
+		p.inventory.outOfStock = params["sku"]
+	},
+	"payments are declined": func(p *provider, params map[string]string) {
+		p.payments.declined = true
+	},
+	"the database is unavailable": func(p *provider, params map[string]string) {
+		err := errors.New("dial tcp 127.0.0.1:5432: connect: connection refused")
+		p.users.err = err
+		p.orders.err = err
+		p.health.err = err
+	},
+}
+
+// TestProviderHonoursContracts replays every interaction of every contract in
+// this directory against orderservice's routes, and checks the responses the
+// consumers rely on
+func TestProviderHonoursContracts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	files, err := filepath.Glob("*.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, files, "no contracts found")
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		var c contract
+		require.NoError(t, json.Unmarshal(data, &c), file)
+		require.Equal(t, "orderservice", c.Provider, file)
+
+		for _, it := range c.Interactions {
+			t.Run(c.Consumer+"/"+it.Description, func(t *testing.T) {
+				verify(t, it)
+			})
+		}
+	}
+}
+
+// verify sets up the interaction's states on a fresh provider, sends its
+// request and checks the response
+func verify(t *testing.T, it interaction) {
+	p := newProvider()
+	for _, s := range it.Given {
+		setUp, ok := states[s.State]
+		require.True(t, ok, "no set-up for provider state %q", s.State)
+		setUp(p, s.Params)
+	}
+
+	req := httptest.NewRequest(it.Request.Method, it.Request.Path, bytes.NewReader(it.Request.Body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	p.engine.ServeHTTP(w, req)
+
+	require.Equal(t, it.Response.Status, w.Code, "status; body: %s", w.Body.String())
+	for name, want := range it.Response.Headers {
+		require.Equal(t, want, w.Header().Get(name), "header %s", name)
+	}
+
+	var actual any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual), "body is not JSON: %s", w.Body.String())
+	if mismatches := matchType("body", it.Response.Body, actual); len(mismatches) > 0 {
+		t.Fatalf("response does not match the contract:\n  %s\nbody: %s", strings.Join(mismatches, "\n  "), w.Body.String())
+	}
+	for path, want := range it.Response.Exact {
+		got, ok := lookup(actual, path)
+		require.True(t, ok, "body has no %s: %s", path, w.Body.String())
+		require.Equal(t, want, got, "body.%s", path)
+	}
+}
+
+// matchType reports where actual differs in shape from expected
+func matchType(path string, expected, actual any) []string {
+	switch want := expected.(type) {
+	case map[string]any:
+		got, ok := actual.(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: want an object, got %s", path, jsonType(actual))}
+		}
+		keys := make([]string, 0, len(want))
+		for k := range want {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var mismatches []string
+		for _, k := range keys {
+			v, ok := got[k]
+			if !ok {
+				mismatches = append(mismatches, fmt.Sprintf("%s.%s: missing", path, k))
+				continue
+			}
+			mismatches = append(mismatches, matchType(path+"."+k, want[k], v)...)
+		}
+		return mismatches
+	case []any:
+		got, ok := actual.([]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: want an array, got %s", path, jsonType(actual))}
+		}
+		if len(want) == 0 {
+			return nil
+		}
+		if len(got) == 0 {
+			return []string{fmt.Sprintf("%s: want at least one element", path)}
+		}
+		var mismatches []string
+		for i, v := range got {
+			mismatches = append(mismatches, matchType(fmt.Sprintf("%s[%d]", path, i), want[0], v)...)
+		}
+		return mismatches
+	default:
+		if jsonType(expected) != jsonType(actual) {
+			return []string{fmt.Sprintf("%s: want a %s, got %s", path, jsonType(expected), jsonType(actual))}
+		}
+		return nil
+	}
+}
+
+// jsonType names the JSON type of a decoded value
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return reflect.TypeOf(v).String()
+	}
+}
+
+// lookup returns the value at a dotted path, e.g. error.code
+func lookup(v any, path string) (any, bool) {
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = obj[key]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// provider is orderservice's HTTP API over in-memory repositories and stub
+// clients, which the provider states arrange
+type provider struct {
+	engine    *gin.Engine
+	users     *memUserRepo
+	orders    *memOrderRepo
+	inventory *stubInventory
+	payments  *stubPayments
+	health    *stubRegistry
+}
+
+func newProvider() *provider {
+	p := &provider{
+		engine:    gin.New(),
+		users:     &memUserRepo{users: map[string]*domain.User{}},
+		orders:    &memOrderRepo{orders: map[string]*domain.Order{}},
+		inventory: &stubInventory{},
+		payments:  &stubPayments{},
+		health:    &stubRegistry{},
+	}
+	httpAdapter.RegisterRoutes(
+		p.engine,
+		usecase.NewUserService(p.users),
+		usecase.NewOrderService(p.orders, p.inventory, p.payments),
+		nil, // avatar uploads are multipart, and not part of the contracts
+		uploads.NopScanner{},
+		nil,
+		uploads.Config{},
+		p.health,
+		logx.NewNoopLogger(),
+	)
+	return p
+}
+
+// memUserRepo keeps users in memory, with unique emails like the users table
+type memUserRepo struct {
+	mu    sync.Mutex
+	users map[string]*domain.User
+	err   error
+}
+
+func (r *memUserRepo) Save(ctx context.Context, u *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	for _, existing := range r.users {
+		if existing.Email == u.Email {
+			return domain.ErrConflict
+		}
+	}
+	r.users[u.ID] = u
+	return nil
+}
+
+func (r *memUserRepo) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+	u, ok := r.users[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return u, nil
+}
+
+func (r *memUserRepo) Update(ctx context.Context, u *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	if _, ok := r.users[u.ID]; !ok {
+		return domain.ErrNotFound
+	}
+	r.users[u.ID] = u
+	return nil
+}
+
+// AvatarHashesInUse finds none, as the contracts upload no avatars
+func (r *memUserRepo) AvatarHashesInUse(ctx context.Context, hashes []string) ([]string, error) {
+	return nil, nil
+}
+
+// memOrderRepo keeps orders in memory
+type memOrderRepo struct {
+	mu     sync.Mutex
+	orders map[string]*domain.Order
+	err    error
+}
+
+func (r *memOrderRepo) Save(ctx context.Context, o *domain.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	r.orders[o.ID] = o
+	return nil
+}
+
+func (r *memOrderRepo) FindByID(ctx context.Context, id string) (*domain.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+	o, ok := r.orders[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return o, nil
+}
+
+// ExistingOrderIDs finds none, as the contracts upload no attachments
+func (r *memOrderRepo) ExistingOrderIDs(ctx context.Context, ids []string) ([]string, error) {
+	return nil, nil
+}
+
+// stubInventory reserves everything but the SKU out of stock
+type stubInventory struct {
+	outOfStock string
+}
+
+func (s *stubInventory) Reserve(ctx context.Context, orderID string, items []domain.Item) (string, error) {
+	for _, item := range items {
+		if item.SKU == s.outOfStock {
+			return "", fmt.Errorf("%w: %s", usecase.ErrOutOfStock, item.SKU)
+		}
+	}
+	return "res-" + orderID, nil
+}
+
+func (s *stubInventory) Release(ctx context.Context, reservationID string) error {
+	return nil
+}
+
+// stubPayments charges every order, or declines every one
+type stubPayments struct {
+	declined bool
+}
+
+func (s *stubPayments) Charge(ctx context.Context, orderID string, amount float64) (string, error) {
+	if s.declined {
+		return "", fmt.Errorf("%w: card declined", usecase.ErrPaymentDeclined)
+	}
+	return "ch-" + orderID, nil
+}
+
+func (s *stubPayments) FindCharge(ctx context.Context, orderID string) (string, error) {
+	if s.declined {
+		return "", nil
+	}
+	return "ch-" + orderID, nil
+}
+
+func (s *stubPayments) Refund(ctx context.Context, chargeID string) error {
+	return nil
+}
+
+// stubRegistry reports the service ready, or not ready with err
+type stubRegistry struct {
+	core.Registry
+	err error
+}
+
+func (s *stubRegistry) Aggregate(ctx context.Context, kind core.Kind) core.Result {
+	if s.err != nil && kind == core.Readiness {
+		return core.Result{OK: false, Details: map[string]any{"db": s.err.Error()}}
+	}
+	return core.Result{OK: true, Details: map[string]any{}}
+}
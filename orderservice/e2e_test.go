@@ -2,249 +2,293 @@ package orderservice_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"mime/multipart"
 	"net/http"
-	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"slices"
 	"testing"
+	"time"
 
-	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
 
-	"github.com/gostratum/core/logx"
-	httpAdapter "github.com/gostratum/examples/orderservice/internal/adapter/http"
-	"github.com/gostratum/examples/orderservice/internal/adapter/repo"
-	"github.com/gostratum/examples/orderservice/internal/usecase"
+	"github.com/gostratum/examples/orderservice/internal/domain"
+	"github.com/gostratum/examples/orderservice/internal/testutil"
+	"github.com/gostratum/examples/orderservice/internal/testutil/factories"
+	"github.com/gostratum/examples/testkit/containers"
 )
 
-func setupTestServer(t *testing.T) *gin.Engine {
-	// Set Gin to test mode
-	gin.SetMode(gin.TestMode)
+func TestMain(m *testing.M) {
+	os.Exit(containers.Run(m))
+}
 
-	// Create in-memory SQLite database
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+// call sends a request with body encoded as JSON, unless nil, and returns the
+// status code and the decoded response envelope
+func call(t *testing.T, method, url string, body any) (int, map[string]any) {
+	t.Helper()
+	var reqBody bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&reqBody).Encode(body))
+	}
+	req, err := http.NewRequest(method, url, &reqBody)
 	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
 
-	// Create tables manually for SQLite compatibility (same as repo tests)
-	err = db.Exec(`
-		CREATE TABLE users (
-			id TEXT PRIMARY KEY,
-			name TEXT NOT NULL,
-			email TEXT NOT NULL UNIQUE,
-			avatar_url TEXT,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		);
-		CREATE TABLE orders (
-			id TEXT PRIMARY KEY,
-			user_id TEXT NOT NULL,
-			status TEXT NOT NULL DEFAULT 'pending',
-			total REAL NOT NULL,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (user_id) REFERENCES users(id)
-		);
-		CREATE TABLE items (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			order_id TEXT NOT NULL,
-			sku TEXT NOT NULL,
-			qty INTEGER NOT NULL,
-			price REAL NOT NULL,
-			FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
-		);
-	`).Error
+	resp, err := http.DefaultClient.Do(req)
 	require.NoError(t, err)
+	defer resp.Body.Close()
 
-	// Create repositories
-	userRepo := repo.NewUserRepo(db)
-	orderRepo := repo.NewOrderRepo(db)
-
-	// Create services
-	userService := usecase.NewUserService(userRepo)
-	orderService := usecase.NewOrderService(orderRepo)
-
-	// Create logger
-	logger := logx.NewNoopLogger()
+	var envelope map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&envelope))
+	return resp.StatusCode, envelope
+}
 
-	// Create handlers
-	userHandler := httpAdapter.NewUserHandler(userService, nil, logger)
-	orderHandler := httpAdapter.NewOrderHandler(orderService, logger)
+// createUser creates a user with the name and email of user and returns its id
+func createUser(t *testing.T, baseURL string, user *domain.User) string {
+	t.Helper()
+	status, envelope := call(t, http.MethodPost, baseURL+"/users", userRequest(user))
+	require.Equal(t, http.StatusCreated, status)
+	return envelope["data"].(map[string]any)["id"].(string)
+}
 
-	// Create router
-	router := gin.New()
-	router.Use(gin.Logger(), gin.Recovery())
+// putPart sends part n of upload session id and returns the status code
+func putPart(t *testing.T, baseURL, id string, n int, data []byte) int {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/upload-sessions/%s/parts/%d", baseURL, id, n), bytes.NewReader(data))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp.StatusCode
+}
 
-	// Setup routes
-	api := router.Group("/api/v1")
-	{
-		users := api.Group("/users")
-		{
-			users.POST("", userHandler.CreateUser)
-			users.GET("/:id", userHandler.GetUser)
-		}
+// userRequest is the body of POST /users that creates user
+func userRequest(user *domain.User) map[string]any {
+	return map[string]any{"name": user.Name, "email": user.Email}
+}
 
-		orders := api.Group("/orders")
-		{
-			orders.POST("", orderHandler.CreateOrder)
-			orders.GET("/:id", orderHandler.GetOrder)
-		}
+// orderRequest is the body of POST /orders that creates an order of items
+// for userID
+func orderRequest(userID string, items []domain.Item) map[string]any {
+	body := make([]map[string]any, len(items))
+	for i, item := range items {
+		body[i] = map[string]any{"sku": item.SKU, "qty": item.Qty, "price": item.Price}
 	}
-
-	return router
+	return map[string]any{"user_id": userID, "items": body}
 }
 
 func TestEndToEnd_UserLifecycle(t *testing.T) {
-	router := setupTestServer(t)
-
-	t.Run("create and retrieve user", func(t *testing.T) {
-		// Create user request
-		userReq := map[string]any{
-			"name":  "John Doe",
-			"email": "john.doe@example.com",
-		}
-		reqBody, _ := json.Marshal(userReq)
-
-		// Create user
-		req, _ := http.NewRequest("POST", "/api/v1/users", bytes.NewBuffer(reqBody))
-		req.Header.Set("Content-Type", "application/json")
-		w := httptest.NewRecorder()
-		router.ServeHTTP(w, req)
+	t.Parallel()
+	ta := testutil.NewTestApp(t)
+	baseURL := ta.BaseURL
 
-		assert.Equal(t, http.StatusCreated, w.Code)
+	user := factories.User()
 
-		var createEnvelope map[string]any
-		err := json.Unmarshal(w.Body.Bytes(), &createEnvelope)
-		require.NoError(t, err)
+	t.Run("create and retrieve user", func(t *testing.T) {
+		status, createEnvelope := call(t, http.MethodPost, baseURL+"/users", userRequest(user))
+		assert.Equal(t, http.StatusCreated, status)
 
 		// Extract data from envelope
 		assert.True(t, createEnvelope["ok"].(bool))
 		createResp := createEnvelope["data"].(map[string]any)
 
 		userID := createResp["id"].(string)
-		assert.Equal(t, "John Doe", createResp["name"])
-		assert.Equal(t, "john.doe@example.com", createResp["email"])
+		assert.Equal(t, user.Name, createResp["name"])
+		assert.Equal(t, user.Email, createResp["email"])
 
 		// Retrieve user
-		req, _ = http.NewRequest("GET", "/api/v1/users/"+userID, nil)
-		w = httptest.NewRecorder()
-		router.ServeHTTP(w, req)
+		status, getEnvelope := call(t, http.MethodGet, baseURL+"/users/"+userID, nil)
+		assert.Equal(t, http.StatusOK, status)
 
-		assert.Equal(t, http.StatusOK, w.Code)
-
-		var getEnvelope map[string]any
-		err = json.Unmarshal(w.Body.Bytes(), &getEnvelope)
-		require.NoError(t, err)
-
-		// Extract data from envelope
 		assert.True(t, getEnvelope["ok"].(bool))
 		getResp := getEnvelope["data"].(map[string]any)
 
 		assert.Equal(t, userID, getResp["id"])
-		assert.Equal(t, "John Doe", getResp["name"])
-		assert.Equal(t, "john.doe@example.com", getResp["email"])
+		assert.Equal(t, user.Name, getResp["name"])
+		assert.Equal(t, user.Email, getResp["email"])
 	})
-}
 
-func TestEndToEnd_OrderLifecycle(t *testing.T) {
-	router := setupTestServer(t)
+	t.Run("create user with an email in use", func(t *testing.T) {
+		other := factories.User(func(u *domain.User) { u.Email = user.Email })
+		status, envelope := call(t, http.MethodPost, baseURL+"/users", userRequest(other))
+		assert.Equal(t, http.StatusConflict, status)
+		assert.False(t, envelope["ok"].(bool))
 
-	t.Run("create and retrieve order", func(t *testing.T) {
-		// First create a user
-		userReq := map[string]any{
-			"name":  "Jane Smith",
-			"email": "jane.smith@example.com",
-		}
-		reqBody, _ := json.Marshal(userReq)
-
-		req, _ := http.NewRequest("POST", "/api/v1/users", bytes.NewBuffer(reqBody))
-		req.Header.Set("Content-Type", "application/json")
-		w := httptest.NewRecorder()
-		router.ServeHTTP(w, req)
-		require.Equal(t, http.StatusCreated, w.Code)
-
-		// Create order request using the proper HTTP request format
-		orderReq := map[string]any{
-			"user_id": "1",
-			"items": []map[string]any{
-				{"sku": "Laptop", "qty": 1, "price": 1200.00},
-				{"sku": "Mouse", "qty": 2, "price": 25.00},
-			},
-		}
-		reqBody, _ = json.Marshal(orderReq)
+		// A conflict is the client's mistake, not the service's
+		assert.Empty(t, ta.Logs.Level("error"))
+	})
 
-		// Create order
-		req, _ = http.NewRequest("POST", "/api/v1/orders", bytes.NewBuffer(reqBody))
-		req.Header.Set("Content-Type", "application/json")
-		w = httptest.NewRecorder()
-		router.ServeHTTP(w, req)
+	t.Run("upload avatar", func(t *testing.T) {
+		userID := createUser(t, baseURL, factories.User())
 
-		assert.Equal(t, http.StatusCreated, w.Code)
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, err := form.CreatePart(textproto.MIMEHeader{
+			"Content-Disposition": {`form-data; name="avatar"; filename="me.png"`},
+			"Content-Type":        {"image/png"},
+		})
+		require.NoError(t, err)
+		require.NoError(t, png.Encode(part, image.NewRGBA(image.Rect(0, 0, 600, 300))))
+		require.NoError(t, form.Close())
 
-		var createEnvelope map[string]any
-		err := json.Unmarshal(w.Body.Bytes(), &createEnvelope)
+		resp, err := http.Post(baseURL+"/users/"+userID+"/avatar", form.FormDataContentType(), &body)
 		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// Stored under the hash of its content, the key the user now points at
+		var envelope map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&envelope))
+		data := envelope["data"].(map[string]any)
+		avatarURL := data["avatar_url"].(string)
+		assert.Regexp(t, `^avatars/[0-9a-f]{64}\.png$`, avatarURL)
+		assert.Contains(t, ta.Storage.Keys(), avatarURL)
+
+		// and the thumbnails follow, at the URLs the response announced
+		urls := data["avatar_urls"].(map[string]any)
+		assert.Equal(t, avatarURL, urls["original"])
+		for _, variant := range domain.AvatarVariants {
+			key := urls[variant.Name].(string)
+			assert.Eventually(t, func() bool { return slices.Contains(ta.Storage.Keys(), key) },
+				5*time.Second, 20*time.Millisecond, "thumbnail %s", key)
+
+			thumb, _, err := ta.Storage.Get(context.Background(), key)
+			require.NoError(t, err)
+			cfg, err := png.DecodeConfig(thumb)
+			thumb.Close()
+			require.NoError(t, err)
+			assert.Equal(t, variant.Size, cfg.Width, "width of %s", key)
+		}
+	})
+}
+
+func TestEndToEnd_OrderLifecycle(t *testing.T) {
+	t.Parallel()
+	ta := testutil.NewTestApp(t)
+	baseURL := ta.BaseURL
+
+	t.Run("create and retrieve order", func(t *testing.T) {
+		userID := createUser(t, baseURL, factories.User())
+		order := factories.Order(factories.WithItems(2))
+
+		status, createEnvelope := call(t, http.MethodPost, baseURL+"/orders", orderRequest(userID, order.Items))
+		assert.Equal(t, http.StatusCreated, status)
 
-		// Extract data from envelope
 		assert.True(t, createEnvelope["ok"].(bool))
 		createResp := createEnvelope["data"].(map[string]any)
 
 		orderID := createResp["id"].(string)
-		assert.Equal(t, "1", createResp["user_id"])
-		assert.Equal(t, 1250.00, createResp["total"].(float64))
-
-		// Retrieve order
-		req, _ = http.NewRequest("GET", "/api/v1/orders/"+orderID, nil)
-		w = httptest.NewRecorder()
-		router.ServeHTTP(w, req)
+		assert.Equal(t, userID, createResp["user_id"])
+		assert.Equal(t, order.Total, createResp["total"].(float64))
 
-		assert.Equal(t, http.StatusOK, w.Code)
-
-		var getEnvelope map[string]any
-		err = json.Unmarshal(w.Body.Bytes(), &getEnvelope)
-		require.NoError(t, err)
+		// Retrieve order; the items come back from their own table
+		status, getEnvelope := call(t, http.MethodGet, baseURL+"/orders/"+orderID, nil)
+		assert.Equal(t, http.StatusOK, status)
 
-		// Extract data from envelope
 		assert.True(t, getEnvelope["ok"].(bool))
 		getResp := getEnvelope["data"].(map[string]any)
 
 		assert.Equal(t, orderID, getResp["id"])
-		assert.Equal(t, "1", getResp["user_id"])
-		assert.Equal(t, 1250.00, getResp["total"].(float64))
+		assert.Equal(t, userID, getResp["user_id"])
+		assert.Equal(t, order.Total, getResp["total"].(float64))
+		assert.Len(t, getResp["items"], 2)
+
+		// One row per item, in their own table
+		var items int64
+		require.NoError(t, ta.DB.Table("items").Where("order_id = ?", orderID).Count(&items).Error)
+		assert.Equal(t, int64(2), items)
+	})
+
+	t.Run("upload an attachment in parts", func(t *testing.T) {
+		userID := createUser(t, baseURL, factories.User())
+		status, envelope := call(t, http.MethodPost, baseURL+"/orders", orderRequest(userID, factories.Items(1)))
+		require.Equal(t, http.StatusCreated, status)
+		orderID := envelope["data"].(map[string]any)["id"].(string)
+
+		// A little over one part of the configured 5MB
+		file := bytes.Repeat([]byte("0123456789abcdef"), 5<<16+1)
+		status, envelope = call(t, http.MethodPost, baseURL+"/upload-sessions", map[string]any{
+			"kind": "attachment", "owner_id": orderID, "filename": "scan.bin", "size": len(file),
+		})
+		require.Equal(t, http.StatusCreated, status)
+		session := envelope["data"].(map[string]any)
+		id := session["id"].(string)
+		partSize := int(session["part_size"].(float64))
+		require.Equal(t, 2.0, session["parts"])
+
+		// The last part first; order does not matter
+		assert.Equal(t, http.StatusOK, putPart(t, baseURL, id, 2, file[partSize:]))
+		assert.Equal(t, http.StatusOK, putPart(t, baseURL, id, 1, file[:partSize]))
+
+		status, envelope = call(t, http.MethodPost, baseURL+"/upload-sessions/"+id+"/complete", nil)
+		require.Equal(t, http.StatusOK, status)
+		key := envelope["data"].(map[string]any)["key"].(string)
+		assert.Equal(t, float64(len(file)), envelope["data"].(map[string]any)["size"])
+
+		body, _, err := ta.Storage.Get(context.Background(), key)
+		require.NoError(t, err)
+		defer body.Close()
+		stored, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(file, stored), "the parts are joined in order")
+		assert.Equal(t, []string{key}, ta.Storage.Keys(), "parts are deleted")
+
+		status, envelope = call(t, http.MethodGet, baseURL+"/orders/"+orderID+"/attachments", nil)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Len(t, envelope["data"], 1)
+
+		// The attachment is recorded, and downloads as uploaded
+		attachmentID := envelope["data"].([]any)[0].(map[string]any)["id"].(string)
+		resp, err := http.Get(baseURL + "/orders/" + orderID + "/attachments/" + attachmentID)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		downloaded, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `attachment; filename=scan.bin`, resp.Header.Get("Content-Disposition"))
+		assert.True(t, bytes.Equal(file, downloaded))
+
+		var rows int64
+		require.NoError(t, ta.DB.Table("order_attachments").Where("order_id = ?", orderID).Count(&rows).Error)
+		assert.Equal(t, int64(1), rows)
 	})
 }
 
 func TestEndToEnd_ErrorHandling(t *testing.T) {
-	router := setupTestServer(t)
+	t.Parallel()
+	baseURL := testutil.NewTestApp(t).BaseURL
 
 	t.Run("create user with invalid data", func(t *testing.T) {
-		// Invalid email
-		userReq := map[string]any{
-			"name":  "John Doe",
-			"email": "invalid-email",
-		}
-		reqBody, _ := json.Marshal(userReq)
-
-		req, _ := http.NewRequest("POST", "/api/v1/users", bytes.NewBuffer(reqBody))
-		req.Header.Set("Content-Type", "application/json")
-		w := httptest.NewRecorder()
-		router.ServeHTTP(w, req)
-
-		assert.Equal(t, http.StatusBadRequest, w.Code)
+		invalid := factories.User(func(u *domain.User) { u.Email = "invalid-email" })
+		status, _ := call(t, http.MethodPost, baseURL+"/users", userRequest(invalid))
+		assert.Equal(t, http.StatusBadRequest, status)
 	})
 
 	t.Run("get non-existent user", func(t *testing.T) {
-		req, _ := http.NewRequest("GET", "/api/v1/users/999", nil)
-		w := httptest.NewRecorder()
-		router.ServeHTTP(w, req)
-
-		assert.Equal(t, http.StatusNotFound, w.Code)
+		status, _ := call(t, http.MethodGet, baseURL+"/users/999", nil)
+		assert.Equal(t, http.StatusNotFound, status)
 	})
 
 	t.Run("get non-existent order", func(t *testing.T) {
-		req, _ := http.NewRequest("GET", "/api/v1/orders/999", nil)
-		w := httptest.NewRecorder()
-		router.ServeHTTP(w, req)
-
-		assert.Equal(t, http.StatusNotFound, w.Code)
+		status, _ := call(t, http.MethodGet, baseURL+"/orders/999", nil)
+		assert.Equal(t, http.StatusNotFound, status)
 	})
 }
+
+func TestEndToEnd_Health(t *testing.T) {
+	t.Parallel()
+	baseURL := testutil.NewTestApp(t).BaseURL
+
+	// Ready once the database is at the schema version the binary expects
+	status, body := call(t, http.MethodGet, baseURL+"/healthz", nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.True(t, body["ok"].(bool))
+}
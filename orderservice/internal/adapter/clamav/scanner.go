@@ -0,0 +1,133 @@
+// Package clamav implements uploads.Scanner against clamd, ClamAV's daemon,
+// streaming each file to it with the INSTREAM command
+package clamav
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/gostratum/core/configx"
+
+	"github.com/gostratum/examples/orderservice/internal/uploads"
+)
+
+// chunkSize is how much of a file is sent to clamd at once
+const chunkSize = 64 << 10
+
+// Config locates clamd
+type Config struct {
+	// Address of clamd, as host:port; uploads are not scanned when empty
+	Address string `mapstructure:"address"`
+	// Timeout bounds the scan of one file, sending it included
+	Timeout time.Duration `mapstructure:"timeout" default:"30s"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "clamav"
+}
+
+// NewScanner creates the scanner from the clamav config section. Without an
+// address it returns uploads.NopScanner, so orderservice also runs without
+// clamd.
+func NewScanner(loader configx.Loader) (uploads.Scanner, error) {
+	var cfg Config
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load clamav config: %w", err)
+	}
+	if cfg.Address == "" {
+		return uploads.NopScanner{}, nil
+	}
+	return newScanner(cfg.Address, cfg.Timeout), nil
+}
+
+// Scanner scans files with clamd, one connection per file. clamd refuses
+// files over its StreamMaxLength, 25 MiB by default, which then fail to scan;
+// raise it to the largest upload.
+type Scanner struct {
+	address string
+	timeout time.Duration
+	dialer  net.Dialer
+}
+
+func newScanner(address string, timeout time.Duration) *Scanner {
+	return &Scanner{address: address, timeout: timeout}
+}
+
+// Scan implements uploads.Scanner
+func (s *Scanner) Scan(ctx context.Context, r io.Reader) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	conn, err := s.dialer.DialContext(ctx, "tcp", s.address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+	// Ending ctx interrupts the read or write in progress
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if _, err := io.WriteString(conn, "zINSTREAM\x00"); err != nil {
+		return fmt.Errorf("failed to send to clamd: %w", err)
+	}
+
+	// Each chunk is preceded by its length, and one of length zero ends the
+	// file
+	buf := make([]byte, 4+chunkSize)
+	for {
+		n, err := io.ReadFull(r, buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf, uint32(n))
+			if _, werr := conn.Write(buf[:4+n]); werr != nil {
+				// clamd answers, and closes the connection, when it refuses
+				// the file
+				return s.reply(conn, fmt.Errorf("failed to send to clamd: %w", werr))
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+	}
+	if _, err := conn.Write(make([]byte, 4)); err != nil {
+		return s.reply(conn, fmt.Errorf("failed to send to clamd: %w", err))
+	}
+	return s.reply(conn, nil)
+}
+
+// reply reads clamd's answer and returns what it means, or sendErr, when
+// sending the file failed, if there is no answer
+func (s *Scanner) reply(conn net.Conn, sendErr error) error {
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil {
+		if sendErr != nil {
+			return sendErr
+		}
+		return fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+	return verdict(strings.TrimSuffix(reply, "\x00"))
+}
+
+// verdict maps a reply to INSTREAM, such as "stream: OK" or
+// "stream: Eicar-Signature FOUND", to the error Scan returns
+func verdict(reply string) error {
+	result := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case result == "OK":
+		return nil
+	case strings.HasSuffix(result, " FOUND"):
+		return fmt.Errorf("%w: %s", uploads.ErrInfected, strings.TrimSuffix(result, " FOUND"))
+	default:
+		return fmt.Errorf("clamd: %s", reply)
+	}
+}
@@ -0,0 +1,128 @@
+package clamav
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/orderservice/internal/uploads"
+)
+
+// eicar is the EICAR test file, which every scanner flags
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// fakeClamd answers INSTREAM like clamd, flagging the files that contain the
+// EICAR test file. It returns the address it listens on.
+func fakeClamd(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveInstream(t, conn)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func serveInstream(t *testing.T, conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	command, err := r.ReadString(0)
+	if err != nil || command != "zINSTREAM\x00" {
+		io.WriteString(conn, "UNKNOWN COMMAND\x00")
+		return
+	}
+
+	var file bytes.Buffer
+	for {
+		var size uint32
+		if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+			return
+		}
+		if size == 0 {
+			break
+		}
+		if size > chunkSize {
+			t.Errorf("chunk of %d bytes", size)
+		}
+		if _, err := io.CopyN(&file, r, int64(size)); err != nil {
+			return
+		}
+	}
+
+	switch {
+	case strings.Contains(file.String(), eicar):
+		io.WriteString(conn, "stream: Eicar-Signature FOUND\x00")
+	case file.Len() > 2*chunkSize:
+		io.WriteString(conn, "INSTREAM size limit exceeded. ERROR\x00")
+	default:
+		io.WriteString(conn, "stream: OK\x00")
+	}
+}
+
+func TestScanner_Scan(t *testing.T) {
+	scanner := newScanner(fakeClamd(t), 5*time.Second)
+	ctx := context.Background()
+
+	t.Run("clean", func(t *testing.T) {
+		assert.NoError(t, scanner.Scan(ctx, strings.NewReader("invoice")))
+	})
+
+	t.Run("flagged", func(t *testing.T) {
+		// The test file straddles two chunks
+		file := strings.Repeat("a", chunkSize-10) + eicar
+		err := scanner.Scan(ctx, strings.NewReader(file))
+		assert.ErrorIs(t, err, uploads.ErrInfected)
+		assert.EqualError(t, err, "file is infected: Eicar-Signature")
+	})
+
+	t.Run("refused", func(t *testing.T) {
+		err := scanner.Scan(ctx, strings.NewReader(strings.Repeat("a", 3*chunkSize)))
+		assert.EqualError(t, err, "clamd: INSTREAM size limit exceeded. ERROR")
+		assert.NotErrorIs(t, err, uploads.ErrInfected)
+	})
+}
+
+func TestScanner_Unreachable(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	l.Close()
+
+	err = newScanner(addr, time.Second).Scan(context.Background(), strings.NewReader("invoice"))
+	assert.ErrorContains(t, err, "failed to connect to clamd")
+}
+
+func TestScanner_Timeout(t *testing.T) {
+	// clamd takes the file but never answers
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.Copy(io.Discard, conn)
+	}()
+
+	err = newScanner(l.Addr().String(), 100*time.Millisecond).Scan(context.Background(), strings.NewReader("invoice"))
+	assert.ErrorContains(t, err, "failed to read clamd reply")
+}
@@ -0,0 +1,59 @@
+// Package configkey loads the key that decrypts the encrypted values of the
+// configuration, from CONFIG_KEY itself, or from CONFIG_KEY_KMS, the key
+// encrypted with AWS KMS, so that only a role allowed to decrypt with the KMS
+// key can read the configuration
+package configkey
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gostratum/examples/orderservice/configs"
+)
+
+// decryptFunc decrypts a ciphertext blob with KMS
+type decryptFunc func(ctx context.Context, blob []byte) ([]byte, error)
+
+// Load returns the cipher of the key in CONFIG_KEY or CONFIG_KEY_KMS, or nil
+// when neither is set, which is fine as long as no value is encrypted
+func Load(ctx context.Context) (*configs.Cipher, error) {
+	return load(ctx, os.Getenv, kmsDecrypt)
+}
+
+func load(ctx context.Context, getenv func(string) string, decrypt decryptFunc) (*configs.Cipher, error) {
+	key := strings.TrimSpace(getenv(configs.KeyEnv))
+	kmsKey := strings.TrimSpace(getenv(configs.KeyKMSEnv))
+	switch {
+	case key != "" && kmsKey != "":
+		return nil, fmt.Errorf("set %s or %s, not both", configs.KeyEnv, configs.KeyKMSEnv)
+	case key != "":
+		raw, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not base64: %w", configs.KeyEnv, err)
+		}
+		return newCipher(configs.KeyEnv, raw)
+	case kmsKey != "":
+		blob, err := base64.StdEncoding.DecodeString(kmsKey)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not base64: %w", configs.KeyKMSEnv, err)
+		}
+		raw, err := decrypt(ctx, blob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s with KMS: %w", configs.KeyKMSEnv, err)
+		}
+		return newCipher(configs.KeyKMSEnv, raw)
+	default:
+		return nil, nil
+	}
+}
+
+func newCipher(env string, key []byte) (*configs.Cipher, error) {
+	c, err := configs.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", env, err)
+	}
+	return c, nil
+}
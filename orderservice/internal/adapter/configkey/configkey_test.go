@@ -0,0 +1,91 @@
+package configkey
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/orderservice/configs"
+)
+
+// fakeKMS decrypts the blobs it knows
+type fakeKMS map[string][]byte
+
+func (f fakeKMS) Decrypt(_ context.Context, in *kms.DecryptInput, _ ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	plaintext, ok := f[string(in.CiphertextBlob)]
+	if !ok {
+		return nil, errors.New("InvalidCiphertextException")
+	}
+	return &kms.DecryptOutput{Plaintext: plaintext}, nil
+}
+
+func (f fakeKMS) decrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	return decryptWith(ctx, f, blob)
+}
+
+// env returns a getenv over vars
+func env(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+func TestLoad(t *testing.T) {
+	key, err := configs.GenerateKey()
+	require.NoError(t, err)
+	raw, _ := base64.StdEncoding.DecodeString(key)
+	want, err := configs.NewCipher(raw)
+	require.NoError(t, err)
+	value, err := want.Encrypt("s3cr3t")
+	require.NoError(t, err)
+	kmsClient := fakeKMS{"blob": raw}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{"key", map[string]string{"CONFIG_KEY": key}},
+		{"key encrypted with KMS", map[string]string{"CONFIG_KEY_KMS": base64.StdEncoding.EncodeToString([]byte("blob"))}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := load(context.Background(), env(tt.env), kmsClient.decrypt)
+			require.NoError(t, err)
+			plaintext, err := c.Decrypt(value)
+			require.NoError(t, err)
+			assert.Equal(t, "s3cr3t", plaintext)
+		})
+	}
+
+	c, err := load(context.Background(), env(nil), kmsClient.decrypt)
+	require.NoError(t, err)
+	assert.Nil(t, c, "without a key, the configuration must not be encrypted")
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	key, err := configs.GenerateKey()
+	require.NoError(t, err)
+	blob := base64.StdEncoding.EncodeToString([]byte("unknown"))
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{"both", map[string]string{"CONFIG_KEY": key, "CONFIG_KEY_KMS": blob}, "set CONFIG_KEY or CONFIG_KEY_KMS, not both"},
+		{"not base64", map[string]string{"CONFIG_KEY": "not base64!"}, "CONFIG_KEY is not base64"},
+		{"short key", map[string]string{"CONFIG_KEY": base64.StdEncoding.EncodeToString([]byte("short"))}, "invalid CONFIG_KEY: the key must be 32 bytes, got 5"},
+		{"KMS refuses", map[string]string{"CONFIG_KEY_KMS": blob}, "failed to decrypt CONFIG_KEY_KMS with KMS: InvalidCiphertextException"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := load(context.Background(), env(tt.env), fakeKMS{}.decrypt)
+			assert.ErrorContains(t, err, tt.wantErr)
+			assert.False(t, strings.Contains(err.Error(), key), "the key is not echoed")
+		})
+	}
+}
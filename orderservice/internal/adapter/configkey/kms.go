@@ -0,0 +1,33 @@
+package configkey
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// kmsAPI is the part of the KMS client the key needs
+type kmsAPI interface {
+	Decrypt(ctx context.Context, in *kms.DecryptInput, opts ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+// kmsDecrypt decrypts blob with KMS. The blob names the KMS key it was
+// encrypted with. The region and credentials come from the default chain, and
+// AWS_ENDPOINT_URL_KMS points the client at LocalStack.
+func kmsDecrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	awsCfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return decryptWith(ctx, kms.NewFromConfig(awsCfg), blob)
+}
+
+func decryptWith(ctx context.Context, client kmsAPI, blob []byte) ([]byte, error) {
+	out, err := client.Decrypt(ctx, &kms.DecryptInput{CiphertextBlob: blob})
+	if err != nil {
+		return nil, err
+	}
+	return out.Plaintext, nil
+}
@@ -0,0 +1,108 @@
+package dbsecret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+// secretsManagerAPI is the part of the Secrets Manager client the resolver uses
+type secretsManagerAPI interface {
+	GetSecretValue(ctx context.Context, in *secretsmanager.GetSecretValueInput, opts ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// ssmAPI is the part of the SSM client the resolver uses
+type ssmAPI interface {
+	GetParameter(ctx context.Context, in *ssm.GetParameterInput, opts ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+}
+
+// newFetcher creates a client for the service the ARN belongs to, in the ARN's
+// region. Credentials come from the default chain: environment, shared config, or
+// the role of the task or instance.
+func newFetcher(ctx context.Context, cfg Config) (fetchFunc, error) {
+	a, err := arn.Parse(cfg.ARN)
+	if err != nil {
+		return nil, fmt.Errorf("db_secret.arn is not an ARN: %w", err)
+	}
+
+	var name string
+	switch a.Service {
+	case "secretsmanager":
+	case "ssm":
+		var ok bool
+		if name, ok = parameterName(a.Resource); !ok {
+			return nil, fmt.Errorf("db_secret.arn must name an SSM parameter, got %q", a.Resource)
+		}
+	default:
+		return nil, fmt.Errorf("db_secret.arn must name a Secrets Manager secret or an SSM parameter, got service %q", a.Service)
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(a.Region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	var endpoint *string
+	if cfg.Endpoint != "" {
+		endpoint = aws.String(cfg.Endpoint)
+	}
+
+	if a.Service == "ssm" {
+		client := ssm.NewFromConfig(awsCfg, func(o *ssm.Options) { o.BaseEndpoint = endpoint })
+		return ssmFetcher(client, name), nil
+	}
+	client := secretsmanager.NewFromConfig(awsCfg, func(o *secretsmanager.Options) { o.BaseEndpoint = endpoint })
+	return secretsManagerFetcher(client, cfg.ARN), nil
+}
+
+// parameterName returns the name of the parameter an SSM ARN resource names.
+// Hierarchical names start with a slash, which the ARN leaves out.
+func parameterName(resource string) (string, bool) {
+	name, ok := strings.CutPrefix(resource, "parameter/")
+	if !ok || name == "" {
+		return "", false
+	}
+	if strings.Contains(name, "/") {
+		name = "/" + name
+	}
+	return name, true
+}
+
+// secretsManagerFetcher reads the AWSCURRENT version of a secret, which rotation
+// moves to the new credentials once the database accepts them
+func secretsManagerFetcher(client secretsManagerAPI, id string) fetchFunc {
+	return func(ctx context.Context) (string, string, error) {
+		out, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(id)})
+		if err != nil {
+			return "", "", err
+		}
+		if out.SecretString == nil {
+			return "", "", errors.New("secret has no string value")
+		}
+		return *out.SecretString, aws.ToString(out.VersionId), nil
+	}
+}
+
+// ssmFetcher reads a parameter, decrypting SecureString parameters
+func ssmFetcher(client ssmAPI, name string) fetchFunc {
+	return func(ctx context.Context) (string, string, error) {
+		out, err := client.GetParameter(ctx, &ssm.GetParameterInput{
+			Name:           aws.String(name),
+			WithDecryption: aws.Bool(true),
+		})
+		if err != nil {
+			return "", "", err
+		}
+		if out.Parameter == nil {
+			return "", "", errors.New("parameter has no value")
+		}
+		return aws.ToString(out.Parameter.Value), strconv.FormatInt(out.Parameter.Version, 10), nil
+	}
+}
@@ -0,0 +1,48 @@
+package dbsecret
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/gostratum/core/configx"
+
+	"github.com/gostratum/examples/orderservice/configs"
+)
+
+// configSource names the DSN of a Resolver from FromConfig in its messages
+const configSource = "db.databases.primary.dsn"
+
+// FromConfig creates a Resolver for the DSN of the configuration, for when
+// db_secret names no secret. Each read binds db.databases.primary with a loader
+// from newLoader, so an edit of the config files is read by the next check or
+// SIGHUP. A DSN from an environment variable keeps the value the API started
+// with, as the environment of a running process does not change.
+func FromConfig(loader binder, newLoader func() configx.Loader) (*Resolver, error) {
+	var cfg Config
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load db_secret config: %w", err)
+	}
+	return newConfigResolver(cfg, configFetcher(newLoader)), nil
+}
+
+func newConfigResolver(cfg Config, fetch fetchFunc) *Resolver {
+	r := newResolver(cfg, fetch)
+	r.source = configSource
+	r.fromConfig = true
+	return r
+}
+
+// configFetcher reads the DSN from a new loader. The hash of the DSN is its
+// version, so a new DSN is a rotation.
+func configFetcher(newLoader func() configx.Loader) fetchFunc {
+	return func(context.Context) (string, string, error) {
+		db, err := configs.LoadDatabase(newLoader())
+		if err != nil {
+			return "", "", err
+		}
+		sum := sha256.Sum256([]byte(db.DSN))
+		return db.DSN, hex.EncodeToString(sum[:]), nil
+	}
+}
@@ -0,0 +1,217 @@
+// Package dbsecret resolves the database URL from AWS Secrets Manager, SSM Parameter
+// Store, a mounted file or the configuration, caches it, notices when it has been
+// rotated, and moves the connection pool to the new credentials while the API runs
+package dbsecret
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Config names the secret holding the database URL
+type Config struct {
+	// ARN of a Secrets Manager secret or an SSM parameter. The database URL comes from
+	// the db section and DATABASE_URL when empty.
+	ARN string `mapstructure:"arn"`
+	// File holds the database URL instead, as a Kubernetes secret volume or Vault Agent
+	// writes it; it is read again at every check, and rotated when its content changes
+	File string `mapstructure:"file"`
+	// Endpoint overrides the AWS endpoint, e.g. http://localhost:4566 for LocalStack
+	Endpoint string `mapstructure:"endpoint"`
+	// RefreshInterval is how long a value read from AWS is reused, and how often the
+	// API checks whether the secret has been rotated
+	RefreshInterval time.Duration `mapstructure:"refresh_interval" default:"5m"`
+	// SSLMode is added to URLs built from JSON secrets, which carry no sslmode
+	SSLMode string `mapstructure:"sslmode" default:"require"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "db_secret"
+}
+
+// binder is the part of configx.Loader that New needs
+type binder interface {
+	Bind(v any) error
+}
+
+// fetchFunc reads the current value of the secret and the version it belongs to
+type fetchFunc func(ctx context.Context) (value, version string, err error)
+
+// Resolver reads the database URL from its source and caches it for the refresh
+// interval
+type Resolver struct {
+	source   string
+	fetch    fetchFunc
+	sslMode  string
+	interval time.Duration
+	now      func() time.Time
+	// fromConfig is set for the DSN of the configuration, which the rotated DSN
+	// must not be written over: the next read would see the override instead
+	fromConfig bool
+
+	mu      sync.Mutex
+	dsn     string
+	version string
+	fetched time.Time
+}
+
+// New creates a Resolver for the db_secret section, or returns nil when neither an
+// ARN nor a file is configured
+func New(ctx context.Context, loader binder) (*Resolver, error) {
+	var cfg Config
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load db_secret config: %w", err)
+	}
+
+	var fetch fetchFunc
+	var err error
+	switch {
+	case cfg.ARN != "" && cfg.File != "":
+		return nil, errors.New("set db_secret.arn or db_secret.file, not both")
+	case cfg.ARN != "":
+		fetch, err = newFetcher(ctx, cfg)
+	case cfg.File != "":
+		fetch = fileFetcher(cfg.File)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newResolver(cfg, fetch), nil
+}
+
+func newResolver(cfg Config, fetch fetchFunc) *Resolver {
+	return &Resolver{
+		source:   cmp.Or(cfg.ARN, cfg.File),
+		fetch:    fetch,
+		sslMode:  cfg.SSLMode,
+		interval: cfg.RefreshInterval,
+		now:      time.Now,
+	}
+}
+
+// DSN returns the database URL, reading the secret again once the cached value is
+// older than the refresh interval
+func (r *Resolver) DSN(ctx context.Context) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.dsn != "" && r.now().Sub(r.fetched) < r.interval {
+		return r.dsn, nil
+	}
+	if _, err := r.refresh(ctx); err != nil {
+		return "", err
+	}
+	return r.dsn, nil
+}
+
+// Refresh reads the secret regardless of the cache and reports whether its version
+// changed since the previous read, i.e. whether it has been rotated
+func (r *Resolver) Refresh(ctx context.Context) (string, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rotated, err := r.refresh(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	return r.dsn, rotated, nil
+}
+
+func (r *Resolver) refresh(ctx context.Context) (bool, error) {
+	value, version, err := r.fetch(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to read database secret %s: %w", r.source, err)
+	}
+	dsn, err := parseDSN(value, r.sslMode)
+	if err != nil {
+		return false, fmt.Errorf("invalid database secret %s: %w", r.source, err)
+	}
+
+	rotated := r.version != "" && version != r.version
+	r.dsn, r.version, r.fetched = dsn, version, r.now()
+	return rotated, nil
+}
+
+// rdsSecret is the JSON layout of database secrets created by RDS and managed by the
+// Secrets Manager rotation functions
+type rdsSecret struct {
+	Engine   string      `json:"engine"`
+	Host     string      `json:"host"`
+	Port     json.Number `json:"port"`
+	Username string      `json:"username"`
+	Password string      `json:"password"`
+	DBName   string      `json:"dbname"`
+}
+
+// parseDSN returns the secret as is when it holds a URL, or builds a PostgreSQL URL
+// from a JSON secret in the RDS layout
+func parseDSN(value, sslMode string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", errors.New("secret is empty")
+	}
+	if !strings.HasPrefix(value, "{") {
+		return value, nil
+	}
+
+	var s rdsSecret
+	if err := json.Unmarshal([]byte(value), &s); err != nil {
+		return "", fmt.Errorf("secret is not valid JSON: %w", err)
+	}
+	switch s.Engine {
+	case "", "postgres", "aurora-postgresql":
+	default:
+		return "", fmt.Errorf("engine %q is not PostgreSQL", s.Engine)
+	}
+	if s.Host == "" || s.Username == "" || s.Password == "" {
+		return "", errors.New("JSON secret needs a host, username and password")
+	}
+
+	u := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(s.Username, s.Password),
+		Host:   s.Host,
+	}
+	if s.Port != "" {
+		u.Host = net.JoinHostPort(s.Host, s.Port.String())
+	}
+	if s.DBName != "" {
+		u.Path = "/" + s.DBName
+	}
+	if sslMode != "" {
+		u.RawQuery = url.Values{"sslmode": {sslMode}}.Encode()
+	}
+	return u.String(), nil
+}
+
+// authErrorCodes are the SQLSTATEs PostgreSQL answers rejected credentials with
+var authErrorCodes = []string{"28P01", "28000"}
+
+// IsAuthError reports whether err means PostgreSQL rejected the credentials, as it
+// does when the secret was rotated after it was read
+func IsAuthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return slices.Contains(authErrorCodes, pgErr.Code)
+	}
+	// golang-migrate passes on only the message of driver errors
+	msg := err.Error()
+	return strings.Contains(msg, "password authentication failed") || strings.Contains(msg, "SQLSTATE 28P01")
+}
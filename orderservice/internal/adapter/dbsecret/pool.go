@@ -0,0 +1,164 @@
+package dbsecret
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+
+	"github.com/gostratum/examples/orderservice/configs"
+)
+
+// defaultMaxIdleConns is database/sql's limit of idle connections, when
+// max_idle_conns is not set
+const defaultMaxIdleConns = 2
+
+// Pool is the connection pool of the primary database. It opens each connection
+// with the credentials of the latest DSN, so Rotate moves it to new credentials
+// without closing a connection that is in use: a request or transaction running
+// on an old connection finishes on it, and the connection is closed when it
+// comes back to the pool instead of being used again.
+type Pool struct {
+	db      *sql.DB
+	maxIdle int
+
+	mu         sync.RWMutex
+	dsn        string
+	generation uint64
+	connector  driver.Connector
+}
+
+// NewPool opens a pool with the DSN of r and the pool settings of
+// db.databases.primary
+func NewPool(r *Resolver, cfg *configs.Config) (*Pool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+	dsn, err := r.DSN(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newPool(dsn, cfg.Database)
+}
+
+func newPool(dsn string, cfg configs.Database) (*Pool, error) {
+	p := &Pool{maxIdle: cmp.Or(cfg.MaxIdleConns, defaultMaxIdleConns)}
+	connector, err := p.newConnector(dsn, 0)
+	if err != nil {
+		return nil, err
+	}
+	p.dsn, p.connector = dsn, connector
+
+	p.db = sql.OpenDB(p)
+	p.db.SetMaxOpenConns(cfg.MaxOpenConns)
+	p.db.SetMaxIdleConns(p.maxIdle)
+	p.db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	p.db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	return p, nil
+}
+
+// newConnector returns a connector for dsn whose connections are refused for reuse
+// once the pool has moved past generation
+func (p *Pool) newConnector(dsn string, generation uint64) (driver.Connector, error) {
+	cfg, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DSN: %w", err)
+	}
+	return stdlib.GetConnector(*cfg, stdlib.OptionResetSession(func(context.Context, *pgx.Conn) error {
+		if p.current() != generation {
+			return driver.ErrBadConn
+		}
+		return nil
+	})), nil
+}
+
+func (p *Pool) current() uint64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.generation
+}
+
+// Connect implements driver.Connector with the connector of the latest DSN
+func (p *Pool) Connect(ctx context.Context) (driver.Conn, error) {
+	p.mu.RLock()
+	connector := p.connector
+	p.mu.RUnlock()
+	return connector.Connect(ctx)
+}
+
+// Driver implements driver.Connector
+func (p *Pool) Driver() driver.Driver {
+	return stdlib.GetDefaultDriver()
+}
+
+// DB returns the pool
+func (p *Pool) DB() *sql.DB {
+	return p.db
+}
+
+// DSN returns the DSN new connections are opened with
+func (p *Pool) DSN() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.dsn
+}
+
+// Rotate moves the pool to dsn. It first connects with it, and keeps the current
+// credentials when that fails, so a secret rotated before the database accepts the
+// new password does not take the API down. Idle connections are closed at once;
+// those in use are closed when they are released.
+func (p *Pool) Rotate(ctx context.Context, dsn string) error {
+	p.mu.RLock()
+	next := p.generation + 1
+	p.mu.RUnlock()
+
+	connector, err := p.newConnector(dsn, next)
+	if err != nil {
+		return err
+	}
+	conn, err := connector.Connect(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect with the new credentials: %w", err)
+	}
+	_ = conn.Close()
+
+	p.mu.Lock()
+	p.dsn, p.generation, p.connector = dsn, next, connector
+	p.mu.Unlock()
+
+	// Dropping the idle limit closes the idle connections, all of them old ones
+	p.db.SetMaxIdleConns(0)
+	p.db.SetMaxIdleConns(p.maxIdle)
+	return nil
+}
+
+// Attach makes db, the primary connection of dbx, run on the pool. dbx opens its
+// connections with a DSN fixed at startup; the handle it provides, which the
+// repositories and the health checks use, is kept, and its connection pool is
+// replaced and closed. Call it before the application starts.
+func (p *Pool) Attach(db *gorm.DB) error {
+	old, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get the connection pool of dbx: %w", err)
+	}
+	if prepared, ok := db.ConnPool.(*gorm.PreparedStmtDB); ok {
+		// Statements prepared on the old pool are dropped with it
+		prepared.Close()
+		prepared.Stmts = map[string]*gorm.Stmt{}
+		prepared.ConnPool = p.db
+	} else {
+		db.ConnPool = p.db
+	}
+	db.Statement.ConnPool = db.ConnPool
+	return old.Close()
+}
+
+// Close closes the pool
+func (p *Pool) Close() error {
+	return p.db.Close()
+}
@@ -0,0 +1,185 @@
+package dbsecret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+// checkTimeout bounds one read of the secret by the watcher
+const checkTimeout = 10 * time.Second
+
+// envOverrides are the variables SetEnv sets: dbx reads the override of
+// db.databases.primary.dsn, the migration readiness check DATABASE_URL
+var envOverrides = []string{"STRATUM_DB_DATABASES_PRIMARY_DSN", "DATABASE_URL"}
+
+// SetEnv reads the database URL and sets it as the environment overrides the API's
+// modules read. Call it before core.New, so they bind their config with it in place.
+func (r *Resolver) SetEnv(ctx context.Context) error {
+	dsn, err := r.DSN(ctx)
+	if err != nil {
+		return err
+	}
+	return setEnv(dsn)
+}
+
+// setEnv sets dsn as the environment overrides
+func setEnv(dsn string) error {
+	for _, name := range envOverrides {
+		if err := os.Setenv(name, dsn); err != nil {
+			return fmt.Errorf("failed to set %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// rotator is the part of Pool the Watcher uses
+type rotator interface {
+	DSN() string
+	Rotate(ctx context.Context, dsn string) error
+}
+
+// Params holds the dependencies of the Watcher
+type Params struct {
+	fx.In
+
+	Resolver *Resolver
+	Pool     *Pool
+	Log      logx.Logger
+}
+
+// Watcher checks the secret, or the DSN of the configuration, for rotation while
+// the API runs, every refresh interval and when the process receives SIGHUP, and
+// moves the pool to the new credentials. Requests keep being served meanwhile.
+type Watcher struct {
+	resolver *Resolver
+	pool     rotator
+	log      logx.Logger
+	hangups  chan os.Signal
+
+	stopping chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewWatcher creates a Watcher that rotates the credentials of p.Pool
+func NewWatcher(p Params) *Watcher {
+	return newWatcher(p.Resolver, p.Pool, p.Log)
+}
+
+func newWatcher(resolver *Resolver, pool rotator, log logx.Logger) *Watcher {
+	return &Watcher{
+		resolver: resolver,
+		pool:     pool,
+		log:      log,
+		hangups:  make(chan os.Signal, 1),
+		stopping: make(chan struct{}),
+	}
+}
+
+// Register ties the Watcher and the pool to the application lifecycle, and moves
+// db, dbx's primary connection, to the pool.
+// This function is designed to be used with fx.Invoke.
+func Register(lc fx.Lifecycle, w *Watcher, pool *Pool, db *gorm.DB) error {
+	if err := pool.Attach(db); err != nil {
+		return err
+	}
+	lc.Append(fx.Hook{
+		OnStart: w.start,
+		OnStop: func(ctx context.Context) error {
+			return errors.Join(w.stop(ctx), pool.Close())
+		},
+	})
+	return nil
+}
+
+// Module opens the pool with the DSN of r, moves dbx's primary connection to it, and
+// rotates its credentials when r is. r comes from New when a secret is configured,
+// and from FromConfig otherwise.
+func Module(r *Resolver) fx.Option {
+	return fx.Module("dbsecret",
+		fx.Supply(r),
+		fx.Provide(NewPool, NewWatcher),
+		fx.Invoke(Register),
+	)
+}
+
+func (w *Watcher) start(context.Context) error {
+	signal.Notify(w.hangups, syscall.SIGHUP)
+	w.wg.Add(1)
+	go w.watch()
+	return nil
+}
+
+func (w *Watcher) stop(ctx context.Context) error {
+	signal.Stop(w.hangups)
+	close(w.stopping)
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *Watcher) watch() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.resolver.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stopping:
+			return
+		case <-ticker.C:
+		case <-w.hangups:
+			w.log.Info("SIGHUP received; reading the database URL", logx.String("secret", w.resolver.source))
+		}
+		w.check()
+	}
+}
+
+// check reads the secret and moves the pool to its DSN when it differs from the
+// pool's. A rotation the database does not accept yet is tried again at the next
+// check, the pool keeping the credentials it has.
+func (w *Watcher) check() {
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+
+	dsn, _, err := w.resolver.Refresh(ctx)
+	if err != nil {
+		// The open connections keep working; try again at the next interval
+		w.log.Warn("failed to check database secret for rotation", logx.Err(err))
+		return
+	}
+	if dsn == w.pool.DSN() {
+		return
+	}
+
+	if err := w.pool.Rotate(ctx, dsn); err != nil {
+		w.log.Error("database secret was rotated, but its credentials do not connect; keeping the current ones",
+			logx.String("secret", w.resolver.source), logx.Err(err))
+		return
+	}
+	if !w.resolver.fromConfig {
+		if err := setEnv(dsn); err != nil {
+			w.log.Warn("failed to update the DSN in the environment", logx.Err(err))
+		}
+	}
+	w.log.Info("database secret was rotated; new connections use the new credentials",
+		logx.String("secret", w.resolver.source))
+}
@@ -0,0 +1,213 @@
+package dbsecret
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/orderservice/configs"
+)
+
+// syncSecret is a fakeSecret that the watcher goroutine can read while the test rotates it
+type syncSecret struct {
+	mu sync.Mutex
+	fakeSecret
+}
+
+func (s *syncSecret) fetch(ctx context.Context) (string, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fakeSecret.fetch(ctx)
+}
+
+func (s *syncSecret) set(value, version string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.value, s.version, s.err = value, version, err
+}
+
+// fakePool records the DSNs it is moved to, and refuses those of refuse
+type fakePool struct {
+	mu      sync.Mutex
+	dsn     string
+	refuse  string
+	rotated chan string
+}
+
+func (p *fakePool) DSN() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.dsn
+}
+
+func (p *fakePool) Rotate(_ context.Context, dsn string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if dsn == p.refuse {
+		return errors.New("password authentication failed")
+	}
+	p.dsn = dsn
+	p.rotated <- dsn
+	return nil
+}
+
+func startWatcher(t *testing.T, secret *syncSecret, interval time.Duration) (*Watcher, *fakePool) {
+	t.Helper()
+	for _, name := range envOverrides {
+		t.Setenv(name, "")
+	}
+	r := newResolver(Config{ARN: testARN, RefreshInterval: interval}, secret.fetch)
+	dsn, err := r.DSN(context.Background())
+	require.NoError(t, err)
+
+	pool := &fakePool{dsn: dsn, rotated: make(chan string, 2)}
+	w := newWatcher(r, pool, logx.NewNoopLogger())
+	require.NoError(t, w.start(context.Background()))
+	return w, pool
+}
+
+// waitRotation returns the DSN the pool is moved to next
+func waitRotation(t *testing.T, pool *fakePool) string {
+	t.Helper()
+	select {
+	case dsn := <-pool.rotated:
+		return dsn
+	case <-time.After(5 * time.Second):
+		t.Fatal("watcher did not rotate the pool")
+		return ""
+	}
+}
+
+func TestWatcher_RotatesPool(t *testing.T) {
+	secret := &syncSecret{fakeSecret: fakeSecret{value: "postgres://app:one@db/orders", version: "v1"}}
+	w, pool := startWatcher(t, secret, 10*time.Millisecond)
+
+	// A failed check is not a rotation
+	secret.set("", "", errors.New("ThrottlingException"))
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, pool.rotated)
+
+	secret.set("postgres://app:two@db/orders", "v2", nil)
+	assert.Equal(t, "postgres://app:two@db/orders", waitRotation(t, pool))
+	assert.Equal(t, "postgres://app:two@db/orders", os.Getenv("DATABASE_URL"), "the migrations tool and check follow")
+
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, w.stop(context.Background()))
+	assert.Empty(t, pool.rotated, "rotates once per version")
+}
+
+func TestWatcher_RetriesRefusedCredentials(t *testing.T) {
+	secret := &syncSecret{fakeSecret: fakeSecret{value: "postgres://app:one@db/orders", version: "v1"}}
+	w, pool := startWatcher(t, secret, 10*time.Millisecond)
+	defer w.stop(context.Background())
+
+	// The secret is rotated before the database takes the new password
+	pool.mu.Lock()
+	pool.refuse = "postgres://app:two@db/orders"
+	pool.mu.Unlock()
+	secret.set("postgres://app:two@db/orders", "v2", nil)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, "postgres://app:one@db/orders", pool.DSN(), "the current credentials are kept")
+
+	pool.mu.Lock()
+	pool.refuse = ""
+	pool.mu.Unlock()
+	assert.Equal(t, "postgres://app:two@db/orders", waitRotation(t, pool), "and the new ones tried again")
+}
+
+func TestWatcher_SIGHUP(t *testing.T) {
+	secret := &syncSecret{fakeSecret: fakeSecret{value: "postgres://app:one@db/orders", version: "v1"}}
+	w, pool := startWatcher(t, secret, time.Hour)
+	defer w.stop(context.Background())
+
+	secret.set("postgres://app:two@db/orders", "v2", nil)
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	assert.Equal(t, "postgres://app:two@db/orders", waitRotation(t, pool), "SIGHUP reads the secret at once")
+}
+
+// configFiles stands in for the config files: each loader binds the DSN they hold
+// when it is created, as configx.New reads the files then
+type configFiles struct {
+	mu  sync.Mutex
+	dsn string
+}
+
+func (f *configFiles) set(dsn string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.dsn = dsn
+}
+
+func (f *configFiles) newLoader() configx.Loader {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return configLoader{dsn: f.dsn}
+}
+
+// configLoader binds the database section with dsn and leaves the others as they are
+type configLoader struct {
+	configx.Loader
+	dsn string
+}
+
+func (l configLoader) Bind(v any) error {
+	if db, ok := v.(*configs.Database); ok {
+		db.Driver, db.DSN = "postgres", l.dsn
+	}
+	return nil
+}
+
+func TestWatcher_SIGHUPRereadsConfig(t *testing.T) {
+	for _, name := range append(envOverrides, configs.DSNEnv...) {
+		t.Setenv(name, "")
+	}
+	files := &configFiles{dsn: "postgres://app:one@db/orders"}
+	r, err := FromConfig(configLoader{}, files.newLoader)
+	require.NoError(t, err)
+	r.interval = time.Hour
+	dsn, err := r.DSN(context.Background())
+	require.NoError(t, err)
+
+	pool := &fakePool{dsn: dsn, rotated: make(chan string, 2)}
+	w := newWatcher(r, pool, logx.NewNoopLogger())
+	require.NoError(t, w.start(context.Background()))
+	defer w.stop(context.Background())
+
+	files.set("postgres://app:two@db/orders")
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	assert.Equal(t, "postgres://app:two@db/orders", waitRotation(t, pool), "SIGHUP reads the config files again")
+	assert.Empty(t, os.Getenv("STRATUM_DB_DATABASES_PRIMARY_DSN"), "no override hides the next edit")
+
+	files.set("postgres://app:three@db/orders")
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	assert.Equal(t, "postgres://app:three@db/orders", waitRotation(t, pool))
+}
+
+func TestWatcher_StopsWithoutRotation(t *testing.T) {
+	secret := &syncSecret{fakeSecret: fakeSecret{value: "postgres://app:one@db/orders", version: "v1"}}
+	w, pool := startWatcher(t, secret, 10*time.Millisecond)
+
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, w.stop(context.Background()))
+	assert.Empty(t, pool.rotated)
+}
+
+func TestResolver_SetEnv(t *testing.T) {
+	for _, name := range envOverrides {
+		t.Setenv(name, "")
+	}
+	r, _ := newTestResolver(&fakeSecret{value: "postgres://app:secret@db/orders", version: "v1"})
+
+	require.NoError(t, r.SetEnv(context.Background()))
+	assert.Equal(t, "postgres://app:secret@db/orders", os.Getenv("STRATUM_DB_DATABASES_PRIMARY_DSN"))
+	assert.Equal(t, "postgres://app:secret@db/orders", os.Getenv("DATABASE_URL"))
+}
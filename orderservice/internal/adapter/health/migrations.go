@@ -0,0 +1,98 @@
+// Package health provides application-specific health checks registered with core.Registry
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/dbx/migrate"
+	"go.uber.org/fx"
+
+	"github.com/gostratum/examples/orderservice/configs"
+	"github.com/gostratum/examples/orderservice/internal/adapter/dbsecret"
+	"github.com/gostratum/examples/orderservice/migrations"
+)
+
+// StatusFunc returns the migration status of the database
+type StatusFunc func(ctx context.Context) (*migrate.Status, error)
+
+// MigrationCheck reports not ready until the database schema has reached the version
+// this binary was built with. A newer schema is accepted so that old instances stay
+// ready while an expand migration for the next release rolls out.
+type MigrationCheck struct {
+	expected uint
+	status   StatusFunc
+}
+
+// NewMigrationCheck creates a readiness check expecting at least schema version expected
+func NewMigrationCheck(expected uint, status StatusFunc) *MigrationCheck {
+	return &MigrationCheck{expected: expected, status: status}
+}
+
+func (c *MigrationCheck) Name() string {
+	return "db-migrations"
+}
+
+func (c *MigrationCheck) Kind() core.Kind {
+	return core.Readiness
+}
+
+func (c *MigrationCheck) Check(ctx context.Context) error {
+	status, err := c.status(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get migration status: %w", err)
+	}
+
+	switch {
+	case status.Dirty:
+		return fmt.Errorf("schema version %d is dirty; fix it with the migrations tool", status.Current)
+	case uint(status.Current) < c.expected:
+		return fmt.Errorf("schema version %d is behind expected version %d; run migrations", status.Current, c.expected)
+	}
+	return nil
+}
+
+// MigrationCheckParams holds the dependencies of the migration check
+type MigrationCheckParams struct {
+	fx.In
+
+	Registry core.Registry
+	Config   *configs.Config
+	Loader   configx.Loader
+	// Pool has the DSN of the latest credentials; test apps run without it
+	Pool *dbsecret.Pool `optional:"true"`
+}
+
+// RegisterMigrationCheck registers a MigrationCheck for the primary database that
+// expects the latest embedded migration version. The DSN has the same overrides as
+// cmd/migrations, so both always look at the same database, and follows the
+// rotations of the database secret.
+func RegisterMigrationCheck(p MigrationCheckParams) error {
+	expected, err := migrations.LatestVersion()
+	if err != nil {
+		return err
+	}
+	dsn := func() string { return p.Config.Database.DSN }
+	if p.Pool != nil {
+		dsn = p.Pool.DSN
+	}
+
+	cfg, err := migrate.NewConfig(p.Loader)
+	if err != nil {
+		return fmt.Errorf("failed to load migration config: %w", err)
+	}
+	var opts []migrate.Option
+	if cfg.Dir != "" {
+		opts = append(opts, migrate.WithDir(cfg.Dir))
+	}
+	if cfg.Table != "" {
+		opts = append(opts, migrate.WithTable(cfg.Table))
+	}
+
+	p.Registry.Register(NewMigrationCheck(expected, func(ctx context.Context) (*migrate.Status, error) {
+		return migrate.GetStatus(ctx, dsn(), opts...)
+	}))
+	return nil
+}
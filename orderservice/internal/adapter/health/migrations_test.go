@@ -0,0 +1,42 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/dbx/migrate"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrationCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  *migrate.Status
+		err     error
+		wantErr string
+	}{
+		{name: "up to date", status: &migrate.Status{Current: 4}},
+		{name: "ahead of binary", status: &migrate.Status{Current: 5}},
+		{name: "behind", status: &migrate.Status{Current: 3}, wantErr: "behind expected version 4"},
+		{name: "dirty", status: &migrate.Status{Current: 4, Dirty: true}, wantErr: "dirty"},
+		{name: "status error", err: errors.New("connection refused"), wantErr: "connection refused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := NewMigrationCheck(4, func(ctx context.Context) (*migrate.Status, error) {
+				return tt.status, tt.err
+			})
+
+			assert.Equal(t, core.Readiness, check.Kind())
+			err := check.Check(context.Background())
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
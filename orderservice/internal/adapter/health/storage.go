@@ -0,0 +1,81 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/storagex"
+
+	"github.com/gostratum/examples/orderservice/internal/buckets"
+)
+
+// StorageConfig sets how the storage check probes the bucket
+type StorageConfig struct {
+	// ProbeKey is listed as a prefix; it need not exist, so the probe reads
+	// at most one key and writes nothing
+	ProbeKey string `mapstructure:"probe_key" default:"healthz/probe"`
+	// Timeout bounds the probe, so a hung S3 or MinIO fails the check rather
+	// than holding /healthz
+	Timeout time.Duration `mapstructure:"timeout" default:"2s"`
+}
+
+// Prefix implements configx.Configurable
+func (StorageConfig) Prefix() string {
+	return "health.storage"
+}
+
+// StorageCheck reports not ready while object storage cannot be reached.
+// Avatars and attachments are stored there, so an outage fails uploads and
+// downloads even with the database healthy. Every storage client is probed,
+// as each may be in a bucket of its own. The probe is a listing rather than
+// a read of one key, as S3 answers a missing bucket with 404 to a HEAD
+// request, the same as a missing key.
+type StorageCheck struct {
+	clients buckets.Clients
+	cfg     StorageConfig
+}
+
+// NewStorageCheck creates a readiness check probing clients as cfg sets
+func NewStorageCheck(clients buckets.Clients, cfg StorageConfig) *StorageCheck {
+	return &StorageCheck{clients: clients, cfg: cfg}
+}
+
+func (c *StorageCheck) Name() string {
+	return "storage"
+}
+
+func (c *StorageCheck) Kind() core.Kind {
+	return core.Readiness
+}
+
+func (c *StorageCheck) Check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	for _, name := range slices.Sorted(maps.Keys(c.clients)) {
+		if _, err := c.clients[name].List(ctx, storagex.ListOptions{Prefix: c.cfg.ProbeKey, PageSize: 1}); err != nil {
+			return fmt.Errorf("%s storage is unreachable: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// RegisterStorageCheck registers a StorageCheck configured by the
+// health.storage section
+func RegisterStorageCheck(reg core.Registry, loader configx.Loader, clients buckets.Clients) error {
+	var cfg StorageConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return fmt.Errorf("failed to load storage health config: %w", err)
+	}
+	if cfg.Timeout <= 0 {
+		return fmt.Errorf("health.storage.timeout must be positive")
+	}
+
+	reg.Register(NewStorageCheck(clients, cfg))
+	return nil
+}
@@ -0,0 +1,66 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/storagex"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gostratum/examples/orderservice/internal/buckets"
+)
+
+// probedStorage answers listings with err, or blocks until ctx ends when
+// hang is set, and records the options it was listed with
+type probedStorage struct {
+	storagex.Storage
+	err    error
+	hang   bool
+	listed storagex.ListOptions
+}
+
+func (s *probedStorage) List(ctx context.Context, opts storagex.ListOptions) (storagex.ListPage, error) {
+	s.listed = opts
+	if s.hang {
+		<-ctx.Done()
+		return storagex.ListPage{}, ctx.Err()
+	}
+	return storagex.ListPage{}, s.err
+}
+
+func TestStorageCheck(t *testing.T) {
+	cfg := StorageConfig{ProbeKey: "healthz/probe", Timeout: 50 * time.Millisecond}
+
+	tests := []struct {
+		name    string
+		storage *probedStorage
+		wantErr string
+	}{
+		{name: "reachable", storage: &probedStorage{}},
+		{name: "unreachable", storage: &probedStorage{err: errors.New("dial tcp: connection refused")}, wantErr: "invoices storage is unreachable: dial tcp: connection refused"},
+		{name: "hung", storage: &probedStorage{hang: true}, wantErr: "deadline exceeded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Every client is probed, whichever bucket it is in
+			avatars := &probedStorage{}
+			check := NewStorageCheck(buckets.Clients{buckets.Avatars: avatars, buckets.Invoices: tt.storage}, cfg)
+
+			assert.Equal(t, core.Readiness, check.Kind())
+			start := time.Now()
+			err := check.Check(context.Background())
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+			assert.Less(t, time.Since(start), time.Second, "the probe is bounded by the timeout")
+			assert.Equal(t, storagex.ListOptions{Prefix: "healthz/probe", PageSize: 1}, avatars.listed)
+			assert.Equal(t, storagex.ListOptions{Prefix: "healthz/probe", PageSize: 1}, tt.storage.listed)
+		})
+	}
+}
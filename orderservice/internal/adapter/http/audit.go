@@ -0,0 +1,183 @@
+package http
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/orderservice/configs"
+	"github.com/gostratum/examples/orderservice/internal/apierror"
+	"github.com/gostratum/examples/orderservice/internal/audit"
+	"github.com/gostratum/examples/orderservice/internal/requestid"
+)
+
+// AdminTokenHeader carries the admin token
+const AdminTokenHeader = "X-Admin-Token"
+
+// Limits of GET /admin/audit
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// maxActorLength bounds the actor kept from the actor header
+const maxActorLength = 256
+
+// AuditScheduler writes the audit entries of a request after it is answered;
+// implemented by tasks.AuditScheduler
+type AuditScheduler interface {
+	Schedule(ctx context.Context, entries []audit.Entry) error
+}
+
+// AuditHandler records the requests that change something, and serves the
+// audit log to admins
+type AuditHandler struct {
+	cfg       audit.Config
+	store     audit.Store
+	scheduler AuditScheduler
+	log       logx.Logger
+	// tokenHash is the SHA-256 hash of the admin token. Comparing hashes
+	// takes the same time whatever the length of the token sent.
+	tokenHash [sha256.Size]byte
+}
+
+// NewAuditHandler creates the audit handler from the audit section
+func NewAuditHandler(cfg *configs.Config, store audit.Store, scheduler AuditScheduler, log logx.Logger) *AuditHandler {
+	return newAuditHandler(cfg.Audit, store, scheduler, log)
+}
+
+func newAuditHandler(cfg audit.Config, store audit.Store, scheduler AuditScheduler, log logx.Logger) *AuditHandler {
+	cfg.AdminToken = strings.TrimSpace(cfg.AdminToken)
+	return &AuditHandler{
+		cfg:       cfg,
+		store:     store,
+		scheduler: scheduler,
+		log:       log,
+		tokenHash: sha256.Sum256([]byte(cfg.AdminToken)),
+	}
+}
+
+// RegisterAuditRoutes adds the audit middleware, and GET /admin/audit when an
+// admin token is set. It runs before RegisterRoutes, so the middleware sees
+// every route. This function is designed to be used with fx.Invoke.
+func RegisterAuditRoutes(e *gin.Engine, h *AuditHandler) {
+	e.Use(h.Middleware())
+	if h.cfg.AdminToken == "" {
+		h.log.Info("GET /admin/audit is not served without audit.admin_token")
+		return
+	}
+	e.GET("/admin/audit", h.adminAuth, h.List)
+}
+
+// Middleware records each request that changes something, i.e. that is not a
+// GET, HEAD or OPTIONS, once it is answered: an entry for each entity the
+// usecases Record, or one for the request alone. Requests to unknown routes
+// are not recorded.
+func (h *AuditHandler) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !h.cfg.Enabled || !mutating(c.Request.Method) || c.FullPath() == "" {
+			c.Next()
+			return
+		}
+
+		ctx, trail := audit.WithTrail(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		entries := trail.Entries(audit.Entry{
+			CreatedAt: time.Now().UTC(),
+			Actor:     h.actor(c),
+			RequestID: requestid.FromContext(c),
+			Method:    c.Request.Method,
+			Route:     c.FullPath(),
+			Status:    c.Writer.Status(),
+		})
+		for i := range entries {
+			entries[i].ID = uuid.NewString()
+		}
+		// Written whether or not the client is still there
+		if err := h.scheduler.Schedule(context.WithoutCancel(c.Request.Context()), entries); err != nil {
+			h.log.Error("failed to record audit entries", logx.Any("entries", entries), logx.Err(err), requestid.Field(c))
+		}
+	}
+}
+
+// mutating reports whether requests of method may change something
+func mutating(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// actor returns who made the request, from the actor header
+func (h *AuditHandler) actor(c *gin.Context) string {
+	actor := strings.TrimSpace(c.GetHeader(h.cfg.ActorHeader))
+	if len(actor) > maxActorLength {
+		actor = actor[:maxActorLength]
+	}
+	return cmp.Or(actor, audit.Anonymous)
+}
+
+// adminAuth rejects requests without the admin token
+func (h *AuditHandler) adminAuth(c *gin.Context) {
+	sum := sha256.Sum256([]byte(c.GetHeader(AdminTokenHeader)))
+	if subtle.ConstantTimeCompare(sum[:], h.tokenHash[:]) != 1 {
+		apierror.Respond(c, apierror.InvalidAdminToken)
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+// List handles GET /admin/audit: the entries, newest first, filtered by the
+// actor, entity_type, entity_id, since and until query parameters, at most
+// limit of them. Times are RFC 3339; a page ends at the created_at of its
+// last entry, which is the until of the next.
+func (h *AuditHandler) List(c *gin.Context) {
+	filter := audit.Filter{
+		Actor:      c.Query("actor"),
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+		Limit:      defaultAuditLimit,
+	}
+	for name, bound := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			apierror.RespondMessage(c, apierror.InvalidInput, "audit_time_invalid", "name", name)
+			return
+		}
+		*bound = t
+	}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxAuditLimit {
+			apierror.RespondMessage(c, apierror.InvalidInput, "audit_limit_invalid", "max", strconv.Itoa(maxAuditLimit))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := h.store.List(c.Request.Context(), filter)
+	if err != nil {
+		h.log.Error("failed to list audit entries", logx.Err(err), requestid.Field(c))
+		apierror.Respond(c, apierror.ServiceUnavailable)
+		return
+	}
+	responsex.OK(c, entries, responsex.Pagination{Limit: &filter.Limit})
+}
@@ -0,0 +1,190 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/orderservice/internal/audit"
+	"github.com/gostratum/examples/orderservice/internal/requestid"
+)
+
+// auditStore keeps the filter of the last List
+type auditStore struct {
+	filter  audit.Filter
+	entries []audit.Entry
+	err     error
+}
+
+func (s *auditStore) Insert(ctx context.Context, entries []audit.Entry) error {
+	return errors.New("not used: entries are scheduled")
+}
+
+func (s *auditStore) List(ctx context.Context, filter audit.Filter) ([]audit.Entry, error) {
+	s.filter = filter
+	return s.entries, s.err
+}
+
+// auditSchedule keeps the entries scheduled
+type auditSchedule struct {
+	entries []audit.Entry
+}
+
+func (s *auditSchedule) Schedule(ctx context.Context, entries []audit.Entry) error {
+	s.entries = append(s.entries, entries...)
+	return nil
+}
+
+func newAuditEngine(t *testing.T, cfg audit.Config) (*gin.Engine, *auditStore, *auditSchedule) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	store, scheduler := &auditStore{}, &auditSchedule{}
+	e := gin.New()
+	e.Use(requestid.Middleware())
+	RegisterAuditRoutes(e, newAuditHandler(cfg, store, scheduler, logx.NewNoopLogger()))
+
+	e.POST("/users", func(c *gin.Context) {
+		audit.Record(c.Request.Context(), "user", "u-1", nil, &struct{ Email string }{Email: "alice@example.com"})
+		c.Status(http.StatusCreated)
+	})
+	e.POST("/users/:id/avatar", func(c *gin.Context) {
+		c.Status(http.StatusBadRequest)
+	})
+	e.GET("/users/:id", func(c *gin.Context) {
+		audit.Record(c.Request.Context(), "user", "u-1", nil, &struct{ Email string }{})
+		c.Status(http.StatusOK)
+	})
+	return e, store, scheduler
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	e, _, scheduler := newAuditEngine(t, audit.Config{Enabled: true, ActorHeader: "X-Actor"})
+
+	req := httptest.NewRequest(http.MethodPost, "/users", nil)
+	req.Header.Set("X-Actor", "alice")
+	req.Header.Set(requestid.Header, "req-1")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Len(t, scheduler.entries, 1)
+	entry := scheduler.entries[0]
+	assert.NotEmpty(t, entry.ID)
+	assert.WithinDuration(t, time.Now(), entry.CreatedAt, time.Minute)
+	assert.Equal(t, "alice", entry.Actor)
+	assert.Equal(t, "req-1", entry.RequestID)
+	assert.Equal(t, http.MethodPost, entry.Method)
+	assert.Equal(t, "/users", entry.Route)
+	assert.Equal(t, http.StatusCreated, entry.Status)
+	assert.Equal(t, "user", entry.EntityType)
+	assert.Equal(t, "u-1", entry.EntityID)
+	assert.Equal(t, audit.ActionCreate, entry.Action)
+	assert.Equal(t, map[string]audit.Change{"email": {After: "alice@example.com"}}, entry.Changes)
+
+	// A failed request is recorded too, by its route, without an entity
+	scheduler.entries = nil
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/users/u-1/avatar", nil))
+	require.Len(t, scheduler.entries, 1)
+	assert.Equal(t, audit.Anonymous, scheduler.entries[0].Actor)
+	assert.Equal(t, "/users/:id/avatar", scheduler.entries[0].Route)
+	assert.Equal(t, http.StatusBadRequest, scheduler.entries[0].Status)
+	assert.Empty(t, scheduler.entries[0].EntityType)
+
+	// Reads and unknown routes are not recorded
+	scheduler.entries = nil
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/u-1", nil))
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/unknown", nil))
+	assert.Empty(t, scheduler.entries)
+}
+
+func TestAuditMiddleware_Disabled(t *testing.T) {
+	e, _, scheduler := newAuditEngine(t, audit.Config{Enabled: false, ActorHeader: "X-Actor"})
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users", nil))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, scheduler.entries)
+}
+
+func TestAuditHandler_List(t *testing.T) {
+	e, store, _ := newAuditEngine(t, audit.Config{Enabled: true, ActorHeader: "X-Actor", AdminToken: " secret "})
+	store.entries = []audit.Entry{{ID: "e-1", Actor: "alice", EntityType: "user", EntityID: "u-1", Action: audit.ActionCreate}}
+
+	get := func(target, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if token != "" {
+			req.Header.Set(AdminTokenHeader, token)
+		}
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("lists the entries of the filter", func(t *testing.T) {
+		w := get("/admin/audit?actor=alice&entity_type=user&entity_id=u-1&since=2024-05-01T00:00:00Z&until=2024-06-01T00:00:00Z&limit=10", "secret")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, audit.Filter{
+			Actor:      "alice",
+			EntityType: "user",
+			EntityID:   "u-1",
+			Since:      time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			Until:      time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			Limit:      10,
+		}, store.filter)
+		var body struct {
+			Data []audit.Entry `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, store.entries, body.Data)
+	})
+
+	t.Run("limits to 50 by default", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, get("/admin/audit", "secret").Code)
+		assert.Equal(t, audit.Filter{Limit: defaultAuditLimit}, store.filter)
+	})
+
+	t.Run("rejects a missing or wrong token", func(t *testing.T) {
+		for _, token := range []string{"", "wrong"} {
+			w := get("/admin/audit", token)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Contains(t, w.Body.String(), `"INVALID_ADMIN_TOKEN"`)
+		}
+	})
+
+	t.Run("rejects invalid parameters", func(t *testing.T) {
+		for _, query := range []string{"since=yesterday", "until=2024-05-01", "limit=0", "limit=501", "limit=ten"} {
+			w := get("/admin/audit?"+query, "secret")
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+			assert.Contains(t, w.Body.String(), `"INVALID_INPUT"`, query)
+		}
+	})
+
+	t.Run("answers a store failure as unavailable", func(t *testing.T) {
+		store.err = errors.New("connection refused")
+		defer func() { store.err = nil }()
+
+		w := get("/admin/audit", "secret")
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.NotContains(t, w.Body.String(), "connection refused")
+	})
+}
+
+func TestAuditHandler_ListNotServedWithoutToken(t *testing.T) {
+	e, _, _ := newAuditEngine(t, audit.Config{Enabled: true, ActorHeader: "X-Actor"})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/admin/audit", nil)
+	req.Header.Set(AdminTokenHeader, "")
+	e.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,162 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/storagex"
+
+	"github.com/gostratum/examples/orderservice/internal/apierror"
+	"github.com/gostratum/examples/orderservice/internal/domain"
+	"github.com/gostratum/examples/orderservice/internal/requestid"
+	"github.com/gostratum/examples/orderservice/internal/uploads"
+	"github.com/gostratum/examples/workkit/imaging"
+)
+
+// storeAvatar stores an avatar under the hash of its content, without its
+// metadata when cfg strips it, and returns the key and the hash. It fails
+// with imaging.ErrMalformed for an image whose metadata cannot be found.
+func storeAvatar(ctx context.Context, storage storagex.Storage, cfg uploads.AvatarConfig, data []byte, contentType string) (key, hash string, err error) {
+	if cfg.StripMetadata {
+		if data, _, err = imaging.StripMetadata(data); err != nil {
+			return "", "", err
+		}
+	}
+	key, hash = uploads.ContentKey(cfg.KeyPrefix, data, contentType)
+
+	// The file may be there already, uploaded by this user or another. It is
+	// stored again all the same, which renews its modification time, so the
+	// collector leaves it alone until the user refers to it.
+	_, err = storage.Put(ctx, key, bytes.NewReader(data), &storagex.PutOptions{
+		ContentType: contentType,
+		Overwrite:   true,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to store avatar: %w", err)
+	}
+	return key, hash, nil
+}
+
+// avatarStagingKey returns a new key among the parts, where an avatar waits
+// until it is stored under the hash of its content
+func avatarStagingKey(cfg uploads.Config) string {
+	return cfg.PartPrefix + "avatars/" + uuid.NewString()
+}
+
+// storeStagedAvatar stores the avatar staged at staged under the hash of its
+// content, as storeAvatar does, and leaves the staged file to the caller. An
+// avatar is held whole to strip its metadata; one that keeps it is hashed and
+// copied a read at a time.
+func storeStagedAvatar(ctx context.Context, storage storagex.Storage, cfg uploads.AvatarConfig, staged, contentType string) (key, hash string, err error) {
+	if cfg.StripMetadata {
+		body, _, err := storage.Get(ctx, staged)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read avatar: %w", err)
+		}
+		data, err := io.ReadAll(io.LimitReader(body, cfg.MaxSize))
+		body.Close()
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read avatar: %w", err)
+		}
+		return storeAvatar(ctx, storage, cfg, data, contentType)
+	}
+
+	body, _, err := storage.Get(ctx, staged)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read avatar: %w", err)
+	}
+	hash, err = uploads.HashContent(body)
+	body.Close()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read avatar: %w", err)
+	}
+	key = uploads.HashKey(cfg.KeyPrefix, hash, contentType)
+
+	body, _, err = storage.Get(ctx, staged)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read avatar: %w", err)
+	}
+	defer body.Close()
+	// Stored again when it is there already, as storeAvatar does
+	if _, err := storage.Put(ctx, key, body, &storagex.PutOptions{ContentType: contentType, Overwrite: true}); err != nil {
+		return "", "", fmt.Errorf("failed to store avatar: %w", err)
+	}
+	return key, hash, nil
+}
+
+// formFile returns the file in the multipart field name of r, read from the
+// body as it arrives rather than spooled to memory or disk first. Fields
+// before it are skipped.
+func formFile(r *http.Request, name string) (*multipart.Part, error) {
+	form, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := form.NextPart()
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == name && part.FileName() != "" {
+			return part, nil
+		}
+		part.Close()
+	}
+}
+
+// respondScanError answers an upload the malware scanner flagged with 422,
+// without saying what was found, and one it could not scan with 503, as
+// files are stored only once scanned
+func respondScanError(c *gin.Context, log logx.Logger, err error) {
+	if errors.Is(err, uploads.ErrInfected) {
+		apierror.Respond(c, apierror.FileInfected)
+		return
+	}
+	log.Error("failed to scan upload", logx.Err(err), requestid.Field(c))
+	apierror.Respond(c, apierror.ScanUnavailable)
+}
+
+// immutableCacheControl lets clients and CDNs keep a file for a year without
+// asking again
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// immutableAvatars marks the avatars and thumbnails served under /uploads at
+// content keys as immutable, as what is at such a key never changes. Files
+// that are not found, such as thumbnails not made yet, are not.
+func immutableAvatars(avatarPrefix string) gin.HandlerFunc {
+	prefixes := []string{avatarPrefix}
+	for _, variant := range domain.AvatarVariants {
+		prefixes = append(prefixes, domain.AvatarVariantPrefix(variant))
+	}
+	return func(c *gin.Context) {
+		key := strings.TrimPrefix(c.Param("filepath"), "/")
+		for _, prefix := range prefixes {
+			if _, ok := uploads.ContentHash(prefix, key); ok {
+				c.Writer = immutableWriter{c.Writer}
+				break
+			}
+		}
+		c.Next()
+	}
+}
+
+// immutableWriter adds the immutable Cache-Control header to a 200 response
+type immutableWriter struct {
+	gin.ResponseWriter
+}
+
+func (w immutableWriter) WriteHeader(code int) {
+	if code == http.StatusOK {
+		w.Header().Set("Cache-Control", immutableCacheControl)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
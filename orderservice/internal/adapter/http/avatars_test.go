@@ -0,0 +1,47 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gostratum/examples/orderservice/internal/uploads"
+)
+
+func TestImmutableAvatars(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	key, hash := uploads.ContentKey("avatars/", []byte("avatar"), "image/png")
+
+	// The engine serves every file but the small thumbnail
+	e := gin.New()
+	e.GET("/uploads/*filepath", immutableAvatars("avatars/"), func(c *gin.Context) {
+		if c.Param("filepath") == "/thumbnails/small/"+hash+".jpg" {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.String(http.StatusOK, "file")
+	})
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/uploads/" + key, want: immutableCacheControl},
+		{path: "/uploads/thumbnails/medium/" + hash + ".png", want: immutableCacheControl},
+		// A thumbnail not made yet may be there later
+		{path: "/uploads/thumbnails/small/" + hash + ".jpg"},
+		// Avatars stored before content keys may be replaced
+		{path: "/uploads/avatars/u1_1700000000.png"},
+		{path: "/uploads/attachments/o1/" + hash + ".png"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.want, w.Header().Get("Cache-Control"))
+		})
+	}
+}
@@ -8,12 +8,15 @@ import (
 
 // UserResponse is the HTTP DTO for user data
 // This struct handles JSON serialization concerns for the HTTP layer
+// AvatarURLs holds the avatar and its thumbnails by variant (original, small
+// and medium); the thumbnails appear shortly after the upload.
 type UserResponse struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	AvatarURL string    `json:"avatar_url"`
-	CreatedAt time.Time `json:"created_at"`
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Email      string            `json:"email"`
+	AvatarURL  string            `json:"avatar_url"`
+	AvatarURLs map[string]string `json:"avatar_urls,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
 }
 
 // FromDomainUser converts a domain.User to UserResponse DTO
@@ -22,11 +25,12 @@ func FromDomainUser(user *domain.User) *UserResponse {
 		return nil
 	}
 	return &UserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		AvatarURL: user.AvatarURL,
-		CreatedAt: user.CreatedAt,
+		ID:         user.ID,
+		Name:       user.Name,
+		Email:      user.Email,
+		AvatarURL:  user.AvatarURL,
+		AvatarURLs: user.AvatarURLs(),
+		CreatedAt:  user.CreatedAt,
 	}
 }
 
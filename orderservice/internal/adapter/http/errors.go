@@ -0,0 +1,51 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gostratum/examples/i18n"
+	"github.com/gostratum/examples/orderservice/internal/apierror"
+	"github.com/gostratum/examples/orderservice/internal/domain"
+)
+
+// RegisterLocaleMiddleware answers every route in the language the request's
+// Accept-Language asks for, among those of apierror.Messages. This function
+// is designed to be used with fx.Invoke.
+func RegisterLocaleMiddleware(e *gin.Engine) {
+	e.Use(i18n.Middleware(apierror.Messages))
+}
+
+// RegisterErrorsRoute registers GET /errors, which lists the error catalog:
+// each code with its status and default message, in the language of the
+// request, for client tooling. This function is designed to be used with
+// fx.Invoke.
+func RegisterErrorsRoute(e *gin.Engine) {
+	e.GET("/errors", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"errors": apierror.CatalogIn(i18n.Locale(c.Request.Context()))})
+	})
+}
+
+// respondInvalid answers INVALID_INPUT, with the rule a value breaks when err
+// is a *domain.ValidationError
+func respondInvalid(c *gin.Context, err error) {
+	var invalid *domain.ValidationError
+	if errors.As(err, &invalid) {
+		apierror.RespondMessage(c, apierror.InvalidInput, "validation."+invalid.Key)
+		return
+	}
+	apierror.Respond(c, apierror.InvalidInput)
+}
+
+// respondDetail answers code with the message of key and what err adds to
+// sentinel, such as the SKU inventoryservice could not reserve, or with the
+// code's message when err adds nothing
+func respondDetail(c *gin.Context, code apierror.Code, key string, err, sentinel error) {
+	if detail, ok := apierror.Detail(err, sentinel); ok {
+		apierror.RespondMessage(c, code, key, "detail", detail)
+		return
+	}
+	apierror.Respond(c, code)
+}
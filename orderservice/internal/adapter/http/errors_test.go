@@ -0,0 +1,108 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/orderservice/internal/apierror"
+	"github.com/gostratum/examples/orderservice/internal/domain"
+)
+
+func TestRegisterErrorsRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	e := gin.New()
+	RegisterErrorsRoute(e)
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/errors", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Errors []apierror.Entry `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, apierror.Catalog(), body.Errors)
+}
+
+func TestRegisterErrorsRoute_InTheClientsLanguage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	e := gin.New()
+	RegisterLocaleMiddleware(e)
+	RegisterErrorsRoute(e)
+
+	req := httptest.NewRequest(http.MethodGet, "/errors", nil)
+	req.Header.Set("Accept-Language", "es-MX,es;q=0.9,en;q=0.5")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+
+	assert.Equal(t, "es", w.Header().Get("Content-Language"))
+	var body struct {
+		Errors []apierror.Entry `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, apierror.CatalogIn("es"), body.Errors)
+}
+
+func TestRespondInvalid(t *testing.T) {
+	order := domain.NewOrder("u1")
+	// Every rule of the domain, so each has a message
+	rules := []error{
+		domain.NewUser("", "ada@example.com").Validate(),
+		domain.NewUser("Ada", "").Validate(),
+		domain.NewUser("Ada", "ada").Validate(),
+		order.AddItem(domain.Item{Qty: 1}),
+		order.AddItem(domain.Item{SKU: "A"}),
+		order.AddItem(domain.Item{SKU: "A", Qty: 1, Price: -1}),
+		domain.NewOrder("").Validate(),
+		order.Validate(),
+		domain.NewAttachment("", "k", "a.pdf", "application/pdf", 1).Validate(),
+		domain.NewAttachment("o1", "", "a.pdf", "application/pdf", 1).Validate(),
+		domain.NewAttachment("o1", "k", "", "application/pdf", 1).Validate(),
+		domain.NewAttachment("o1", "k", "a.pdf", "application/pdf", -1).Validate(),
+	}
+	for _, err := range rules {
+		var invalid *domain.ValidationError
+		require.ErrorAs(t, err, &invalid)
+		for _, lang := range apierror.Messages.Languages() {
+			assert.NotEqual(t, "validation."+invalid.Key, apierror.Messages.Message(lang, "validation."+invalid.Key), "%s has a message in %s", invalid.Key, lang)
+		}
+	}
+
+	respond := func(lang string, err error) (code, message string) {
+		gin.SetMode(gin.TestMode)
+		e := gin.New()
+		RegisterLocaleMiddleware(e)
+		e.GET("/", func(c *gin.Context) { respondInvalid(c, err) })
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Language", lang)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var body struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return body.Error.Code, body.Error.Message
+	}
+
+	err := fmt.Errorf("create user: %w", domain.NewUser("Ada", "").Validate())
+	code, message := respond("de", err)
+	assert.Equal(t, "INVALID_INPUT", code)
+	assert.Equal(t, apierror.Messages.Message("de", "validation.email_required"), message)
+	_, message = respond("en", err)
+	assert.Equal(t, "email is required", message)
+
+	_, message = respond("es", errors.New("invalid input"))
+	assert.Equal(t, apierror.Messages.Message("es", "INVALID_INPUT"), message, "not a rule of the domain")
+}
@@ -0,0 +1,326 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core"
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/gostratum/examples/orderservice/internal/buckets"
+	"github.com/gostratum/examples/orderservice/internal/domain"
+	"github.com/gostratum/examples/orderservice/internal/mocks"
+	"github.com/gostratum/examples/orderservice/internal/uploads"
+	"github.com/gostratum/examples/orderservice/internal/usecase"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+const (
+	goldenUserID  = "5b0a7a4e-2f41-4c55-8a1c-4d5e6f708192"
+	goldenOrderID = "c3d4e5f6-0718-4293-a4b5-c6d7e8f90a1b"
+)
+
+// goldenAPI is the API over mocks of the ports, which each case arranges
+// before its request. Calls a case did not arrange fall back to the users and
+// orders stored by withUser and withOrder, and to reservations and charges
+// that succeed.
+type goldenAPI struct {
+	engine    *gin.Engine
+	users     *mocks.MockUserRepository
+	orders    *mocks.MockOrderRepository
+	inventory *mocks.MockInventoryClient
+	payments  *mocks.MockPaymentGateway
+	health    *stubRegistry
+
+	storedUsers  map[string]*domain.User
+	storedOrders map[string]*domain.Order
+}
+
+func newGoldenAPI(t *testing.T) *goldenAPI {
+	ctrl := gomock.NewController(t)
+	api := &goldenAPI{
+		engine:       gin.New(),
+		users:        mocks.NewMockUserRepository(ctrl),
+		orders:       mocks.NewMockOrderRepository(ctrl),
+		inventory:    mocks.NewMockInventoryClient(ctrl),
+		payments:     mocks.NewMockPaymentGateway(ctrl),
+		health:       &stubRegistry{},
+		storedUsers:  map[string]*domain.User{},
+		storedOrders: map[string]*domain.Order{},
+	}
+	RegisterRoutes(
+		api.engine,
+		usecase.NewUserService(api.users),
+		usecase.NewOrderService(api.orders, api.inventory, api.payments),
+		buckets.Clients{buckets.Avatars: &objectStorage{objects: map[string][]byte{}}},
+		uploads.NopScanner{},
+		scheduleNothing{},
+		testUploadConfig,
+		api.health,
+		logx.NewNoopLogger(),
+	)
+	return api
+}
+
+// fallBack sets the expectations for calls the case did not arrange. gomock
+// matches expectations in the order they were set, so this comes after the
+// case's own.
+func (api *goldenAPI) fallBack() {
+	api.users.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	api.users.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	api.users.EXPECT().FindByID(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, id string) (*domain.User, error) {
+			if user, ok := api.storedUsers[id]; ok {
+				return user, nil
+			}
+			return nil, usecase.ErrNotFound
+		}).AnyTimes()
+
+	api.orders.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	api.orders.EXPECT().FindByID(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, id string) (*domain.Order, error) {
+			if order, ok := api.storedOrders[id]; ok {
+				return order, nil
+			}
+			return nil, usecase.ErrNotFound
+		}).AnyTimes()
+
+	api.inventory.EXPECT().Reserve(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, orderID string, _ []domain.Item) (string, error) {
+			return "res-" + orderID, nil
+		}).AnyTimes()
+	api.inventory.EXPECT().Release(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	api.payments.EXPECT().Charge(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, orderID string, _ float64) (string, error) {
+			return "ch-" + orderID, nil
+		}).AnyTimes()
+	api.payments.EXPECT().Refund(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+}
+
+// withUser stores the user the cases read
+func (api *goldenAPI) withUser() {
+	api.storedUsers[goldenUserID] = &domain.User{
+		ID: goldenUserID, Name: "Jane Smith", Email: "jane@example.com", CreatedAt: time.Now(),
+	}
+}
+
+// withOrder stores the order the cases read
+func (api *goldenAPI) withOrder() {
+	order := domain.NewOrder(goldenUserID)
+	order.ID = goldenOrderID
+	_ = order.AddItem(domain.Item{ID: 1, OrderID: goldenOrderID, SKU: "LAPTOP", Qty: 1, Price: 1200})
+	_ = order.AddItem(domain.Item{ID: 2, OrderID: goldenOrderID, SKU: "MOUSE", Qty: 2, Price: 25})
+	api.storedOrders[goldenOrderID] = order
+}
+
+var goldenCases = []struct {
+	name    string
+	arrange func(api *goldenAPI)
+	request func() *http.Request
+}{
+	// Users
+	{name: "create_user_created", request: postJSON("/users", `{"name":"Jane Smith","email":"jane@example.com"}`)},
+	{name: "create_user_invalid_request", request: postJSON("/users", `{"name":"Jane Smith"}`)},
+	{name: "create_user_invalid_input", request: postJSON("/users", `{"name":"Jane Smith","email":"not-an-email"}`)},
+	{
+		name: "create_user_email_taken",
+		arrange: func(api *goldenAPI) {
+			api.users.EXPECT().Save(gomock.Any(), gomock.Any()).Return(domain.ErrConflict)
+		},
+		request: postJSON("/users", `{"name":"Jane Smith","email":"jane@example.com"}`),
+	},
+	{name: "get_user_ok", arrange: (*goldenAPI).withUser, request: get("/users/" + goldenUserID)},
+	{name: "get_user_not_found", request: get("/users/" + goldenUserID)},
+	{
+		name: "get_user_unavailable",
+		arrange: func(api *goldenAPI) {
+			api.users.EXPECT().FindByID(gomock.Any(), goldenUserID).Return(nil, errors.New("connection refused"))
+		},
+		request: get("/users/" + goldenUserID),
+	},
+	{name: "upload_avatar_ok", arrange: (*goldenAPI).withUser, request: postAvatar("/users/"+goldenUserID+"/avatar", "image/png")},
+	{name: "upload_avatar_missing_file", arrange: (*goldenAPI).withUser, request: postJSON("/users/"+goldenUserID+"/avatar", `{}`)},
+	{name: "upload_avatar_invalid_file_type", arrange: (*goldenAPI).withUser, request: postAvatar("/users/"+goldenUserID+"/avatar", "text/plain")},
+	{name: "upload_avatar_user_not_found", request: postAvatar("/users/"+goldenUserID+"/avatar", "image/png")},
+
+	// Orders
+	{
+		name:    "create_order_created",
+		arrange: (*goldenAPI).withUser,
+		request: postJSON("/orders", `{"user_id":"`+goldenUserID+`","items":[{"sku":"LAPTOP","qty":1,"price":1200},{"sku":"MOUSE","qty":2,"price":25}]}`),
+	},
+	{name: "create_order_invalid_request", request: postJSON("/orders", `{"user_id":"`+goldenUserID+`"}`)},
+	{name: "create_order_invalid_input", request: postJSON("/orders", `{"user_id":"`+goldenUserID+`","items":[{"sku":"LAPTOP","qty":1,"price":-1200}]}`)},
+	{
+		name: "create_order_out_of_stock",
+		arrange: func(api *goldenAPI) {
+			api.inventory.EXPECT().Reserve(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return("", fmt.Errorf("%w: LAPTOP", usecase.ErrOutOfStock))
+		},
+		request: postJSON("/orders", `{"user_id":"`+goldenUserID+`","items":[{"sku":"LAPTOP","qty":1,"price":1200}]}`),
+	},
+	{
+		name: "create_order_payment_declined",
+		arrange: func(api *goldenAPI) {
+			api.payments.EXPECT().Charge(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return("", fmt.Errorf("%w: insufficient funds", usecase.ErrPaymentDeclined))
+		},
+		request: postJSON("/orders", `{"user_id":"`+goldenUserID+`","items":[{"sku":"LAPTOP","qty":1,"price":1200}]}`),
+	},
+	{name: "get_order_ok", arrange: (*goldenAPI).withOrder, request: get("/orders/" + goldenOrderID)},
+	{name: "get_order_not_found", request: get("/orders/" + goldenOrderID)},
+
+	// Health
+	{name: "healthz_ready", request: get("/healthz")},
+	{
+		name:    "healthz_not_ready",
+		arrange: func(api *goldenAPI) { api.health.err = errors.New("connection refused") },
+		request: get("/healthz"),
+	},
+	{name: "livez_live", request: get("/livez")},
+}
+
+// TestGoldenResponses compares each endpoint's status and body with
+// testdata/golden/<case>.json. After an intended change to a response, run
+//
+//	go test ./internal/adapter/http -run TestGoldenResponses -update
+//
+// and review the diff of the golden files with the change.
+func TestGoldenResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, tc := range goldenCases {
+		t.Run(tc.name, func(t *testing.T) {
+			api := newGoldenAPI(t)
+			if tc.arrange != nil {
+				tc.arrange(api)
+			}
+			api.fallBack()
+			w := httptest.NewRecorder()
+			api.engine.ServeHTTP(w, tc.request())
+
+			got, err := normalizeResponse(w.Code, w.Body.Bytes())
+			require.NoError(t, err, "body: %s", w.Body.String())
+
+			path := filepath.Join("testdata", "golden", tc.name+".json")
+			if *update {
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+				require.NoError(t, os.WriteFile(path, got, 0o644))
+				return
+			}
+			want, err := os.ReadFile(path)
+			require.NoError(t, err, "no golden file; run with -update to create it")
+			require.Equal(t, string(want), string(got), "response differs from %s; run with -update if the change is intended", path)
+		})
+	}
+}
+
+var uuidPattern = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+
+// normalizeResponse renders the status and body of a response with the values
+// that change on every run replaced: ids by <uuid> and times by <timestamp>.
+// Avatar keys are the hash of the file, the same on every run. The meta object is left out; httpx's
+// middleware fills it with a request id and timings. So are null fields, such
+// as the data of an error: whether responsex writes them as null or omits
+// them is not part of the API.
+func normalizeResponse(status int, body []byte) ([]byte, error) {
+	var decoded map[string]any
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return nil, err
+	}
+	delete(decoded, "meta")
+
+	// Maps are encoded with sorted keys, so field order does not matter
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(map[string]any{"status": status, "body": normalize(decoded)}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func normalize(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, value := range v {
+			if value == nil {
+				delete(v, k)
+				continue
+			}
+			v[k] = normalize(value)
+		}
+		return v
+	case []any:
+		for i, value := range v {
+			v[i] = normalize(value)
+		}
+		return v
+	case string:
+		if _, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return "<timestamp>"
+		}
+		return uuidPattern.ReplaceAllString(v, "<uuid>")
+	default:
+		return v
+	}
+}
+
+func get(path string) func() *http.Request {
+	return func() *http.Request {
+		return httptest.NewRequest(http.MethodGet, path, nil)
+	}
+}
+
+func postJSON(path, body string) func() *http.Request {
+	return func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+}
+
+// postAvatar uploads a small file of contentType as the avatar form field
+func postAvatar(path, contentType string) func() *http.Request {
+	return func() *http.Request {
+		body, formType := avatarForm(contentType)()
+		req := httptest.NewRequest(http.MethodPost, path, body)
+		req.Header.Set("Content-Type", formType)
+		return req
+	}
+}
+
+// stubRegistry reports the service live, and ready unless err is set
+type stubRegistry struct {
+	core.Registry
+	err error
+}
+
+func (s *stubRegistry) Aggregate(ctx context.Context, kind core.Kind) core.Result {
+	if s.err != nil && kind == core.Readiness {
+		return core.Result{OK: false, Details: map[string]any{"database": s.err.Error()}}
+	}
+	return core.Result{OK: true, Details: map[string]any{"database": "ok"}}
+}
+
+// scheduleNothing accepts every avatar and makes no thumbnails
+type scheduleNothing struct{}
+
+func (scheduleNothing) Schedule(key string) error { return nil }
@@ -0,0 +1,166 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/metricsx"
+	"go.uber.org/fx"
+)
+
+// unmatchedRoute is the route of requests no route matched. Labelling them
+// with it rather than their path keeps a scan of random URLs from making a
+// series per URL.
+const unmatchedRoute = "unmatched"
+
+// otherMethod is the method of requests with a method the API serves on no
+// route, for the same reason
+const otherMethod = "OTHER"
+
+// HTTPMetrics records the requests of each route. The requests, errors and
+// duration are the observability demo's RED metrics, under its names and
+// labels, so its dashboards and alerting rules chart orderservice as well.
+type HTTPMetrics struct {
+	requests counter
+	errors   counter
+	duration histogram
+	size     histogram
+	inFlight gauge
+}
+
+// MetricsParams are the dependencies of NewHTTPMetrics
+type MetricsParams struct {
+	fx.In
+
+	// Metrics is missing when the application runs without metricsx.Module,
+	// as the test apps do
+	Metrics metricsx.Metrics `optional:"true"`
+}
+
+// NewHTTPMetrics registers the request metrics, or records none without
+// metricsx
+func NewHTTPMetrics(p MetricsParams) *HTTPMetrics {
+	if p.Metrics == nil {
+		return &HTTPMetrics{requests: discard{}, errors: discard{}, duration: discard{}, size: discard{}, inFlight: discard{}}
+	}
+	metrics := p.Metrics
+	return &HTTPMetrics{
+		requests: metrics.Counter("http_route_requests_total",
+			metricsx.WithHelp("Requests per route template"),
+			metricsx.WithLabels("method", "route", "status"),
+		),
+		errors: metrics.Counter("http_route_errors_total",
+			metricsx.WithHelp("Requests per route template that returned a 5xx status"),
+			metricsx.WithLabels("method", "route"),
+		),
+		duration: metrics.Histogram("http_route_request_duration_seconds",
+			metricsx.WithHelp("Request duration per route template"),
+			metricsx.WithLabels("method", "route"),
+			metricsx.WithBuckets(0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5),
+		),
+		size: metrics.Histogram("http_route_request_size_bytes",
+			metricsx.WithHelp("Size of the request body, by method and route template"),
+			metricsx.WithLabels("method", "route"),
+			metricsx.WithBuckets(0, 256, 1<<10, 16<<10, 256<<10, 1<<20, 5<<20, 20<<20, 100<<20),
+		),
+		inFlight: metrics.Gauge("http_route_requests_in_flight",
+			metricsx.WithHelp("Requests being answered, by method and route template"),
+			metricsx.WithLabels("method", "route"),
+		),
+	}
+}
+
+// Middleware records each request under its route template, such as
+// /api/v1/users/:id, rather than its path, which would make a series per
+// user
+func (m *HTTPMetrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		method := methodLabel(c.Request.Method)
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		// A chunked body has no Content-Length; its size is what the
+		// handler reads of it
+		body := &countingReader{ReadCloser: c.Request.Body}
+		if c.Request.ContentLength < 0 && c.Request.Body != nil {
+			c.Request.Body = body
+		}
+
+		m.inFlight.Inc(method, route)
+		defer m.inFlight.Dec(method, route)
+		c.Next()
+
+		size := c.Request.ContentLength
+		if size < 0 {
+			size = body.n
+		}
+		status := c.Writer.Status()
+		m.requests.Inc(method, route, strconv.Itoa(status))
+		if status >= 500 {
+			m.errors.Inc(method, route)
+		}
+		m.duration.Observe(time.Since(start).Seconds(), method, route)
+		m.size.Observe(float64(size), method, route)
+	}
+}
+
+// RegisterMetricsMiddleware records the requests of every route. It runs
+// before the other middleware and the routes, so it sees them all, and the
+// time spent in the middleware counts. This function is designed to be used
+// with fx.Invoke.
+func RegisterMetricsMiddleware(e *gin.Engine, m *HTTPMetrics) {
+	e.Use(m.Middleware())
+}
+
+// methodLabel returns method, or OTHER for a method the API serves on no
+// route
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return method
+	default:
+		return otherMethod
+	}
+}
+
+// countingReader counts the bytes read from a request body
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// counter is the part of metricsx.Counter the middleware uses
+type counter interface {
+	Inc(labels ...string)
+}
+
+// histogram is the part of metricsx.Histogram the middleware uses
+type histogram interface {
+	Observe(v float64, labels ...string)
+}
+
+// gauge is the part of metricsx.Gauge the middleware uses
+type gauge interface {
+	Inc(labels ...string)
+	Dec(labels ...string)
+}
+
+// discard is a counter, histogram and gauge that records nothing
+type discard struct{}
+
+func (discard) Inc(labels ...string)                {}
+func (discard) Dec(labels ...string)                {}
+func (discard) Observe(v float64, labels ...string) {}
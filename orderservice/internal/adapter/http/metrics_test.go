@@ -0,0 +1,105 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder adds up what is recorded per labels: increments, decrements and
+// observed values
+type recorder struct {
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func newRecorder() *recorder { return &recorder{values: map[string]float64{}} }
+
+func (r *recorder) add(v float64, labels []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[strings.Join(labels, ",")] += v
+}
+
+func (r *recorder) Inc(labels ...string)                { r.add(1, labels) }
+func (r *recorder) Dec(labels ...string)                { r.add(-1, labels) }
+func (r *recorder) Observe(v float64, labels ...string) { r.add(v, labels) }
+
+func (r *recorder) get(labels ...string) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.values[strings.Join(labels, ",")]
+}
+
+func TestHTTPMetrics(t *testing.T) {
+	metrics := &HTTPMetrics{requests: newRecorder(), errors: newRecorder(), duration: newRecorder(), size: newRecorder(), inFlight: newRecorder()}
+	inFlight := metrics.inFlight.(*recorder)
+
+	gin.SetMode(gin.TestMode)
+	e := gin.New()
+	RegisterMetricsMiddleware(e, metrics)
+	e.GET("/api/v1/users/:id", func(c *gin.Context) {
+		assert.Equal(t, float64(1), inFlight.get("GET", "/api/v1/users/:id"), "in flight while answered")
+		c.Status(http.StatusOK)
+	})
+	e.POST("/api/v1/orders", func(c *gin.Context) {
+		_, _ = io.Copy(io.Discard, c.Request.Body)
+		c.Status(http.StatusBadRequest)
+	})
+	e.PUT("/api/v1/uploads/:id/parts/:n", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+
+	serve := func(req *http.Request) {
+		e.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	serve(httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil))
+	serve(httptest.NewRequest(http.MethodGet, "/api/v1/users/2", nil))
+	serve(httptest.NewRequest(http.MethodPost, "/api/v1/orders", strings.NewReader(`{"user_id": "1"}`)))
+	chunked := httptest.NewRequest(http.MethodPost, "/api/v1/orders", io.NopCloser(strings.NewReader("0123456789")))
+	chunked.ContentLength = -1
+	serve(chunked)
+	serve(httptest.NewRequest(http.MethodPut, "/api/v1/uploads/u1/parts/1", strings.NewReader("part")))
+	serve(httptest.NewRequest(http.MethodGet, "/wp-login.php", nil))
+	serve(httptest.NewRequest("PROPFIND", "/", nil))
+
+	requests := metrics.requests.(*recorder)
+	assert.Equal(t, float64(2), requests.get("GET", "/api/v1/users/:id", "200"), "route templates, not paths")
+	assert.Equal(t, float64(2), requests.get("POST", "/api/v1/orders", "400"))
+	assert.Equal(t, float64(1), requests.get("PUT", "/api/v1/uploads/:id/parts/:n", "500"))
+	assert.Equal(t, float64(1), requests.get("GET", unmatchedRoute, "404"))
+	assert.Equal(t, float64(1), requests.get(otherMethod, unmatchedRoute, "404"))
+	assert.Len(t, requests.values, 5)
+
+	failed := metrics.errors.(*recorder)
+	assert.Equal(t, float64(1), failed.get("PUT", "/api/v1/uploads/:id/parts/:n"), "5xx only")
+	assert.Len(t, failed.values, 1)
+
+	size := metrics.size.(*recorder)
+	assert.Equal(t, float64(len(`{"user_id": "1"}`)+10), size.get("POST", "/api/v1/orders"), "chunked bodies by what is read")
+	assert.Equal(t, float64(4), size.get("PUT", "/api/v1/uploads/:id/parts/:n"), "unread bodies by Content-Length")
+	assert.Equal(t, float64(0), size.get("GET", "/api/v1/users/:id"))
+
+	assert.Len(t, metrics.duration.(*recorder).values, 5)
+	for labels, v := range inFlight.values {
+		assert.Zero(t, v, "%s in flight after the response", labels)
+	}
+}
+
+func TestNewHTTPMetrics_WithoutMetricsx(t *testing.T) {
+	metrics := NewHTTPMetrics(MetricsParams{})
+	gin.SetMode(gin.TestMode)
+	e := gin.New()
+	RegisterMetricsMiddleware(e, metrics)
+	e.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"go.uber.org/mock/gomock"
+
+	"github.com/gostratum/examples/orderservice/internal/adapter/inventory"
+	"github.com/gostratum/examples/orderservice/internal/adapter/payment"
+	"github.com/gostratum/examples/orderservice/internal/domain"
+	"github.com/gostratum/examples/orderservice/internal/mocks"
+	"github.com/gostratum/examples/orderservice/internal/usecase"
+)
+
+// largeOrder returns an order with n items, as the repository returns it
+func largeOrder(n int) *domain.Order {
+	order := domain.NewOrder("5b0a7a4e-2f41-4c55-8a1c-4d5e6f708192")
+	for i := range n {
+		_ = order.AddItem(domain.Item{ID: uint(i + 1), OrderID: order.ID, SKU: fmt.Sprintf("SKU-%04d", i), Qty: 1 + i%5, Price: 9.99})
+	}
+	return order
+}
+
+// BenchmarkCreateOrderHandler measures POST /orders through gin: binding the
+// request, the order service over a mock repository, and writing the
+// response
+func BenchmarkCreateOrderHandler(b *testing.B) {
+	gin.SetMode(gin.TestMode)
+
+	for _, n := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("items=%d", n), func(b *testing.B) {
+			repo := mocks.NewMockOrderRepository(gomock.NewController(b))
+			repo.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			service := usecase.NewOrderService(repo, inventory.Disabled{}, payment.Disabled{})
+			handler := NewOrderHandler(service, logx.NewNoopLogger())
+			router := gin.New()
+			router.POST("/orders", handler.CreateOrder)
+
+			items := make([]ItemRequest, n)
+			for i := range items {
+				items[i] = ItemRequest{SKU: fmt.Sprintf("SKU-%04d", i), Qty: 1 + i%5, Price: 9.99}
+			}
+			body, err := json.Marshal(CreateOrderRequest{UserID: "5b0a7a4e-2f41-4c55-8a1c-4d5e6f708192", Items: items})
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ReportAllocs()
+			b.SetBytes(int64(len(body)))
+			for b.Loop() {
+				req := httptest.NewRequest(http.MethodPost, "/orders", bytes.NewReader(body))
+				req.Header.Set("Content-Type", "application/json")
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				if w.Code != http.StatusCreated {
+					b.Fatalf("status %d: %s", w.Code, w.Body.String())
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkOrderResponseJSON measures turning an order into the JSON body of
+// GET /orders/:id: the DTO conversion and the envelope's encoding
+func BenchmarkOrderResponseJSON(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("items=%d", n), func(b *testing.B) {
+			order := largeOrder(n)
+
+			var size int
+			b.ReportAllocs()
+			for b.Loop() {
+				data, err := json.Marshal(responsex.Envelope[any]{Ok: true, Data: FromDomainOrder(order)})
+				if err != nil {
+					b.Fatal(err)
+				}
+				size = len(data)
+			}
+			b.SetBytes(int64(size))
+		})
+	}
+}
@@ -2,13 +2,14 @@ package http
 
 import (
 	"errors"
-	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gostratum/core/logx"
 	"github.com/gostratum/httpx/responsex"
 
+	"github.com/gostratum/examples/orderservice/internal/apierror"
 	"github.com/gostratum/examples/orderservice/internal/domain"
+	"github.com/gostratum/examples/orderservice/internal/requestid"
 	"github.com/gostratum/examples/orderservice/internal/usecase"
 )
 
@@ -49,7 +50,7 @@ type CreateOrderRequest struct {
 func (h *OrderHandler) CreateOrder(c *gin.Context) {
 	var req CreateOrderRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid request payload", nil)
+		apierror.Respond(c, apierror.InvalidRequest)
 		return
 	}
 
@@ -74,7 +75,7 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 func (h *OrderHandler) GetOrder(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		responsex.Error(c, http.StatusBadRequest, "MISSING_PARAMETER", "order id is required", nil)
+		apierror.RespondMessage(c, apierror.MissingParameter, "order_id_required")
 		return
 	}
 
@@ -93,14 +94,19 @@ func (h *OrderHandler) GetOrder(c *gin.Context) {
 func (h *OrderHandler) handleError(c *gin.Context, err error) {
 	switch {
 	case errors.Is(err, usecase.ErrNotFound):
-		responsex.Error(c, http.StatusNotFound, "ORDER_NOT_FOUND", "order not found", nil)
+		apierror.Respond(c, apierror.OrderNotFound)
 	case errors.Is(err, usecase.ErrInvalid):
-		responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", "invalid input", nil)
+		respondInvalid(c, err)
+	case errors.Is(err, usecase.ErrOutOfStock):
+		// The message names the SKU inventoryservice could not reserve
+		respondDetail(c, apierror.OutOfStock, "out_of_stock_detail", err, usecase.ErrOutOfStock)
+	case errors.Is(err, usecase.ErrPaymentDeclined):
+		// The message carries paymentservice's decline reason
+		respondDetail(c, apierror.PaymentDeclined, "payment_declined_detail", err, usecase.ErrPaymentDeclined)
 	case errors.Is(err, usecase.ErrUnavailable):
-		c.Header("Retry-After", "2")
-		responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "service temporarily unavailable", nil)
+		apierror.Respond(c, apierror.ServiceUnavailable)
 	default:
-		h.log.Error("unexpected error", logx.Err(err))
-		responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+		h.log.Error("unexpected error", logx.Err(err), requestid.Field(c))
+		apierror.Respond(c, apierror.InternalError)
 	}
 }
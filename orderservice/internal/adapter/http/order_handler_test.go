@@ -0,0 +1,191 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"go.uber.org/mock/gomock"
+
+	"github.com/gostratum/examples/orderservice/internal/domain"
+	"github.com/gostratum/examples/orderservice/internal/mocks"
+	"github.com/gostratum/examples/orderservice/internal/testutil/handlertest"
+	"github.com/gostratum/examples/orderservice/internal/usecase"
+)
+
+// orderPorts are the mocks behind an OrderHandler
+type orderPorts struct {
+	repo      *mocks.MockOrderRepository
+	inventory *mocks.MockInventoryClient
+	payments  *mocks.MockPaymentGateway
+}
+
+func newOrderHandler(t *testing.T) (*OrderHandler, orderPorts) {
+	ctrl := gomock.NewController(t)
+	ports := orderPorts{
+		repo:      mocks.NewMockOrderRepository(ctrl),
+		inventory: mocks.NewMockInventoryClient(ctrl),
+		payments:  mocks.NewMockPaymentGateway(ctrl),
+	}
+	service := usecase.NewOrderService(ports.repo, ports.inventory, ports.payments)
+	return NewOrderHandler(service, logx.NewNoopLogger()), ports
+}
+
+func TestOrderHandler_CreateOrder(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	order := map[string]any{
+		"user_id": "user123",
+		"items": []map[string]any{
+			{"sku": "LAPTOP", "qty": 1, "price": 1200},
+			{"sku": "MOUSE", "qty": 2, "price": 25},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		requestBody any
+		arrange     func(p orderPorts)
+		want        handlertest.Expect
+	}{
+		{
+			name:        "valid order creation",
+			requestBody: order,
+			arrange: func(p orderPorts) {
+				p.inventory.EXPECT().Reserve(gomock.Any(), gomock.Any(), gomock.Any()).Return("res-1", nil)
+				p.payments.EXPECT().Charge(gomock.Any(), gomock.Any(), 1250.0).Return("ch-1", nil)
+				p.repo.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			want: handlertest.Expect{
+				Status: http.StatusCreated,
+				Data: handlertest.Fields{
+					"id":      handlertest.NotEmpty,
+					"user_id": "user123",
+					"status":  "pending",
+					"total":   1250,
+					"items":   handlertest.NotEmpty,
+				},
+			},
+		},
+		{
+			name:        "no items",
+			requestBody: map[string]any{"user_id": "user123"},
+			want:        handlertest.Expect{Status: http.StatusBadRequest, Code: "INVALID_REQUEST", Message: "invalid request payload"},
+		},
+		{
+			name: "negative price",
+			requestBody: map[string]any{
+				"user_id": "user123",
+				"items":   []map[string]any{{"sku": "LAPTOP", "qty": 1, "price": -1200}},
+			},
+			want: handlertest.Expect{Status: http.StatusBadRequest, Code: "INVALID_INPUT", Message: "item price cannot be negative"},
+		},
+		{
+			name:        "out of stock",
+			requestBody: order,
+			arrange: func(p orderPorts) {
+				p.inventory.EXPECT().Reserve(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return("", fmt.Errorf("%w: LAPTOP", usecase.ErrOutOfStock))
+			},
+			want: handlertest.Expect{Status: http.StatusConflict, Code: "OUT_OF_STOCK", Message: "out of stock: LAPTOP"},
+		},
+		{
+			name:        "payment declined",
+			requestBody: order,
+			arrange: func(p orderPorts) {
+				p.inventory.EXPECT().Reserve(gomock.Any(), gomock.Any(), gomock.Any()).Return("res-1", nil)
+				p.payments.EXPECT().Charge(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return("", fmt.Errorf("%w: insufficient funds", usecase.ErrPaymentDeclined))
+				p.inventory.EXPECT().Release(gomock.Any(), "res-1").Return(nil)
+			},
+			want: handlertest.Expect{Status: http.StatusPaymentRequired, Code: "PAYMENT_DECLINED", Message: "payment declined: insufficient funds"},
+		},
+		{
+			name:        "repository unavailable",
+			requestBody: order,
+			arrange: func(p orderPorts) {
+				p.inventory.EXPECT().Reserve(gomock.Any(), gomock.Any(), gomock.Any()).Return("res-1", nil)
+				p.payments.EXPECT().Charge(gomock.Any(), gomock.Any(), gomock.Any()).Return("ch-1", nil)
+				p.repo.EXPECT().Save(gomock.Any(), gomock.Any()).Return(errors.New("database connection failed"))
+				p.payments.EXPECT().Refund(gomock.Any(), "ch-1").Return(nil)
+				p.inventory.EXPECT().Release(gomock.Any(), "res-1").Return(nil)
+			},
+			want: handlertest.Expect{Status: http.StatusServiceUnavailable, Code: "SERVICE_UNAVAILABLE", Message: "service temporarily unavailable"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, ports := newOrderHandler(t)
+			if tt.arrange != nil {
+				tt.arrange(ports)
+			}
+
+			handlertest.Call(t, handler.CreateOrder, handlertest.Request{
+				Method: http.MethodPost,
+				Path:   "/orders",
+				JSON:   tt.requestBody,
+			}).Assert(t, tt.want)
+		})
+	}
+}
+
+func TestOrderHandler_GetOrder(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testOrder := domain.NewOrder("user123")
+	_ = testOrder.AddItem(domain.Item{SKU: "LAPTOP", Qty: 1, Price: 1200})
+
+	tests := []struct {
+		name           string
+		orderID        string
+		setupOrder     *domain.Order
+		setupRepoError error
+		want           handlertest.Expect
+	}{
+		{
+			name:       "existing order",
+			orderID:    testOrder.ID,
+			setupOrder: testOrder,
+			want: handlertest.Expect{
+				Status: http.StatusOK,
+				Data:   handlertest.Fields{"id": testOrder.ID, "user_id": "user123", "total": 1200},
+			},
+		},
+		{
+			name:    "empty order ID",
+			orderID: "",
+			want:    handlertest.Expect{Status: http.StatusBadRequest, Code: "MISSING_PARAMETER", Message: "order id is required"},
+		},
+		{
+			name:           "non-existing order",
+			orderID:        "non-existing",
+			setupRepoError: usecase.ErrNotFound,
+			want:           handlertest.Expect{Status: http.StatusNotFound, Code: "ORDER_NOT_FOUND", Message: "order not found"},
+		},
+		{
+			name:           "repository unavailable",
+			orderID:        testOrder.ID,
+			setupRepoError: usecase.ErrUnavailable,
+			want:           handlertest.Expect{Status: http.StatusServiceUnavailable, Code: "SERVICE_UNAVAILABLE", Message: "service temporarily unavailable"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, ports := newOrderHandler(t)
+			if tt.orderID != "" {
+				ports.repo.EXPECT().FindByID(gomock.Any(), tt.orderID).Return(tt.setupOrder, tt.setupRepoError)
+			}
+
+			handlertest.Call(t, handler.GetOrder, handlertest.Request{
+				Method: http.MethodGet,
+				Path:   "/orders/" + tt.orderID,
+				Params: gin.Params{{Key: "id", Value: tt.orderID}},
+			}).Assert(t, tt.want)
+		})
+	}
+}
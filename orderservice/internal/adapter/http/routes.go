@@ -2,15 +2,20 @@ package http
 
 import (
 	"context"
+	"maps"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gostratum/core/logx"
 	"github.com/gostratum/httpx/responsex"
-	"github.com/gostratum/storagex"
 
 	"github.com/gostratum/core"
+	"github.com/gostratum/examples/orderservice/internal/adapter/localstorage"
+	"github.com/gostratum/examples/orderservice/internal/buckets"
+	"github.com/gostratum/examples/orderservice/internal/buildinfo"
+	"github.com/gostratum/examples/orderservice/internal/requestid"
+	"github.com/gostratum/examples/orderservice/internal/uploads"
 	"github.com/gostratum/examples/orderservice/internal/usecase"
 )
 
@@ -20,18 +25,23 @@ func RegisterRoutes(
 	e *gin.Engine,
 	userService *usecase.UserService,
 	orderService *usecase.OrderService,
-	storageClient storagex.Storage,
+	clients buckets.Clients,
+	scanner uploads.Scanner,
+	thumbnails ThumbnailScheduler,
+	uploadConfig uploads.Config,
 	reg core.Registry,
 	log logx.Logger,
 ) {
-	// Add responsex middleware for request tracking and metadata
-	e.Use(responsex.MetaMiddleware("orderservice/v1.0.0"))
+	// Give each request an id, then add responsex middleware for request
+	// tracking and metadata
+	e.Use(requestid.Middleware(), responsex.MetaMiddleware("orderservice/v1.0.0"))
 
-	// Serve static files for uploaded content
-	e.Static("/uploads", "./uploads")
+	// Serve static files for uploaded content, which the local storage
+	// provider keeps there. Avatars and thumbnails are cached for good.
+	e.Group("/uploads", immutableAvatars(uploadConfig.Avatar.KeyPrefix)).Static("/", localstorage.Dir)
 
 	// User handlers
-	userHandler := NewUserHandler(userService, storageClient, log)
+	userHandler := NewUserHandler(userService, clients, scanner, thumbnails, uploadConfig, log)
 	e.POST("/users", userHandler.CreateUser)
 	e.GET("/users/:id", userHandler.GetUser)
 	e.POST("/users/:id/avatar", userHandler.UploadAvatar)
@@ -41,16 +51,19 @@ func RegisterRoutes(
 	e.POST("/orders", orderHandler.CreateOrder)
 	e.GET("/orders/:id", orderHandler.GetOrder)
 
-	// Health endpoints - readiness and liveness checks
+	// Health endpoints - readiness and liveness checks. Readiness reports
+	// the build too, so a rollout shows which instances run the new one.
 	e.GET("/healthz", func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 		defer cancel()
 
 		res := reg.Aggregate(ctx, core.Readiness)
+		details := map[string]any{"build": buildinfo.Get()}
+		maps.Copy(details, res.Details)
 		if res.OK {
-			c.JSON(http.StatusOK, gin.H{"ok": true, "details": res.Details})
+			c.JSON(http.StatusOK, gin.H{"ok": true, "details": details})
 		} else {
-			c.JSON(http.StatusServiceUnavailable, gin.H{"ok": false, "details": res.Details})
+			c.JSON(http.StatusServiceUnavailable, gin.H{"ok": false, "details": details})
 		}
 	})
 
@@ -0,0 +1,496 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"github.com/gostratum/storagex"
+
+	"github.com/gostratum/examples/orderservice/internal/apierror"
+	"github.com/gostratum/examples/orderservice/internal/buckets"
+	"github.com/gostratum/examples/orderservice/internal/domain"
+	"github.com/gostratum/examples/orderservice/internal/requestid"
+	"github.com/gostratum/examples/orderservice/internal/uploads"
+	"github.com/gostratum/examples/orderservice/internal/usecase"
+	"github.com/gostratum/examples/workkit/imaging"
+)
+
+// Kinds of file an upload session takes
+const (
+	UploadAvatar     = "avatar"
+	UploadAttachment = "attachment"
+)
+
+// UploadHandler handles chunked uploads of avatars and order attachments,
+// and order attachments sent whole. Avatars are stored in the avatars
+// client, and attachments in the invoices client.
+type UploadHandler struct {
+	uploads     *uploads.Service
+	cfg         uploads.Config
+	users       *usecase.UserService
+	orders      *usecase.OrderService
+	attachments *usecase.AttachmentService
+	avatars     storagex.Storage
+	invoices    storagex.Storage
+	scanner     uploads.Scanner
+	thumbnails  ThumbnailScheduler
+	log         logx.Logger
+}
+
+// NewUploadHandler creates a new upload handler
+func NewUploadHandler(
+	uploadService *uploads.Service,
+	cfg uploads.Config,
+	users *usecase.UserService,
+	orders *usecase.OrderService,
+	attachments *usecase.AttachmentService,
+	clients buckets.Clients,
+	scanner uploads.Scanner,
+	thumbnails ThumbnailScheduler,
+	log logx.Logger,
+) *UploadHandler {
+	return &UploadHandler{
+		uploads:     uploadService,
+		cfg:         cfg,
+		users:       users,
+		orders:      orders,
+		attachments: attachments,
+		avatars:     clients[buckets.Avatars],
+		invoices:    clients[buckets.Invoices],
+		scanner:     scanner,
+		thumbnails:  thumbnails,
+		log:         log,
+	}
+}
+
+// RegisterUploadRoutes registers the upload session routes and the order
+// attachment routes. This function is designed to be used with fx.Invoke.
+func RegisterUploadRoutes(e *gin.Engine, h *UploadHandler) {
+	e.POST("/upload-sessions", h.CreateSession)
+	e.GET("/upload-sessions/:id", h.GetSession)
+	e.PUT("/upload-sessions/:id/parts/:n", h.PutPart)
+	e.POST("/upload-sessions/:id/complete", h.Complete)
+	e.DELETE("/upload-sessions/:id", h.Abort)
+	e.POST("/orders/:id/attachments", h.UploadAttachment)
+	e.GET("/orders/:id/attachments", h.ListAttachments)
+	e.GET("/orders/:id/attachments/:attachment_id", h.DownloadAttachment)
+}
+
+// CreateUploadRequest represents the request payload for starting an upload.
+// OwnerID is the user id for an avatar and the order id for an attachment.
+type CreateUploadRequest struct {
+	Kind        string `json:"kind" binding:"required,oneof=avatar attachment"`
+	OwnerID     string `json:"owner_id" binding:"required"`
+	Filename    string `json:"filename" binding:"required"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size" binding:"required,gt=0"`
+}
+
+// UploadSessionResponse is the HTTP DTO for an upload session. Parts are
+// numbered from 1, and every part but the last is part_size bytes long.
+type UploadSessionResponse struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	OwnerID   string    `json:"owner_id"`
+	Size      int64     `json:"size"`
+	PartSize  int64     `json:"part_size"`
+	Parts     int       `json:"parts"`
+	Received  []int     `json:"received"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// FromUploadSession converts an uploads.Session to UploadSessionResponse DTO
+func FromUploadSession(s uploads.Session) *UploadSessionResponse {
+	return &UploadSessionResponse{
+		ID:        s.ID,
+		Kind:      s.Target.Kind,
+		OwnerID:   s.Target.Owner,
+		Size:      s.Size,
+		PartSize:  s.PartSize,
+		Parts:     s.Parts,
+		Received:  s.Received,
+		ExpiresAt: s.ExpiresAt,
+	}
+}
+
+// AttachmentResponse is the HTTP DTO for an order attachment
+type AttachmentResponse struct {
+	ID          string    `json:"id"`
+	OrderID     string    `json:"order_id"`
+	Filename    string    `json:"filename"`
+	Key         string    `json:"key"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type,omitempty"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+}
+
+// FromDomainAttachment converts a domain.Attachment to AttachmentResponse DTO
+func FromDomainAttachment(a *domain.Attachment) AttachmentResponse {
+	return AttachmentResponse{
+		ID:          a.ID,
+		OrderID:     a.OrderID,
+		Filename:    a.Filename,
+		Key:         a.Key,
+		Size:        a.Size,
+		ContentType: a.ContentType,
+		UploadedAt:  a.CreatedAt,
+	}
+}
+
+// CreateSession handles POST /upload-sessions
+func (h *UploadHandler) CreateSession(c *gin.Context) {
+	var req CreateUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.InvalidRequest)
+		return
+	}
+
+	var target uploads.Target
+	switch req.Kind {
+	case UploadAvatar:
+		if !h.cfg.Avatar.Allows(req.ContentType) {
+			apierror.RespondMessage(c, apierror.InvalidFileType, "images_only")
+			return
+		}
+		if req.Size > h.cfg.Avatar.MaxSize {
+			apierror.RespondMessage(c, apierror.FileTooLarge, "file_too_large", "limit", sizeLimit(h.cfg.Avatar.MaxSize))
+			return
+		}
+		if _, err := h.users.GetUser(c.Request.Context(), req.OwnerID); err != nil {
+			h.handleError(c, err, apierror.UserNotFound)
+			return
+		}
+		// Joined among the parts, and stored under the hash of its content
+		// once complete
+		target = uploads.Target{
+			Key:     avatarStagingKey(h.cfg),
+			Storage: h.avatars,
+		}
+	case UploadAttachment:
+		if !h.cfg.Attachment.Allows(req.ContentType) {
+			apierror.Respond(c, apierror.InvalidFileType)
+			return
+		}
+		if req.Size > h.cfg.Attachment.MaxSize {
+			apierror.RespondMessage(c, apierror.FileTooLarge, "file_too_large", "limit", sizeLimit(h.cfg.Attachment.MaxSize))
+			return
+		}
+		if _, err := h.orders.GetOrder(c.Request.Context(), req.OwnerID); err != nil {
+			h.handleError(c, err, apierror.OrderNotFound)
+			return
+		}
+		target = uploads.Target{
+			Key:      h.attachmentKey(req.OwnerID, req.Filename),
+			Filename: baseFilename(req.Filename),
+			Storage:  h.invoices,
+		}
+	}
+	target.Kind = req.Kind
+	target.Owner = req.OwnerID
+	target.ContentType = req.ContentType
+
+	session, err := h.uploads.Create(target, req.Size)
+	if err != nil {
+		h.handleError(c, err, "")
+		return
+	}
+	responsex.Created(c, "", FromUploadSession(session))
+}
+
+// GetSession handles GET /upload-sessions/:id. A client resuming an upload
+// sends the parts missing from received.
+func (h *UploadHandler) GetSession(c *gin.Context) {
+	session, err := h.uploads.Get(c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, "")
+		return
+	}
+	responsex.OK(c, FromUploadSession(session), nil)
+}
+
+// PutPart handles PUT /upload-sessions/:id/parts/:n, with the part as the body
+func (h *UploadHandler) PutPart(c *gin.Context) {
+	n, err := strconv.Atoi(c.Param("n"))
+	if err != nil {
+		apierror.RespondMessage(c, apierror.InvalidPart, "part_number_invalid")
+		return
+	}
+
+	session, err := h.uploads.PutPart(c.Request.Context(), c.Param("id"), n, c.Request.Body)
+	if err != nil {
+		h.handleError(c, err, "")
+		return
+	}
+	responsex.OK(c, FromUploadSession(session), nil)
+}
+
+// Complete handles POST /upload-sessions/:id/complete. The file is scanned
+// for malware first. An avatar becomes the user's avatar, and the user is
+// returned; an attachment is recorded against its order, and returned.
+func (h *UploadHandler) Complete(c *gin.Context) {
+	session, stat, err := h.uploads.Complete(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, "")
+		return
+	}
+
+	if session.Target.Kind != UploadAvatar {
+		target := session.Target
+		attachment, ok := h.recordAttachment(c, target.Owner, stat.Key, target.Filename, target.ContentType, stat.Size)
+		if !ok {
+			return
+		}
+		responsex.OK(c, FromDomainAttachment(attachment), nil)
+		return
+	}
+
+	key, hash, ok := h.storeAvatar(c, session.Target)
+	if !ok {
+		return
+	}
+
+	user, err := h.users.UpdateAvatar(c.Request.Context(), session.Target.Owner, key, hash)
+	if err != nil {
+		h.handleError(c, err, apierror.UserNotFound)
+		return
+	}
+	if err := h.thumbnails.Schedule(key); err != nil {
+		h.log.Warn("failed to schedule avatar thumbnails", logx.String("key", key), logx.Err(err), requestid.Field(c))
+	}
+	responsex.OK(c, FromDomainUser(user), nil)
+}
+
+// storeAvatar moves a joined avatar to the hash of its content, without its
+// metadata when configured, and returns the key and the hash. The joined file
+// is deleted whatever happens. It reports whether the avatar was stored,
+// having answered c otherwise.
+func (h *UploadHandler) storeAvatar(c *gin.Context, target uploads.Target) (key, hash string, ok bool) {
+	ctx := c.Request.Context()
+	defer func() {
+		if err := h.avatars.Delete(ctx, target.Key); err != nil && !errors.Is(err, storagex.ErrNotFound) {
+			h.log.Warn("failed to delete joined avatar", logx.String("key", target.Key), logx.Err(err), requestid.Field(c))
+		}
+	}()
+
+	key, hash, err := storeStagedAvatar(ctx, h.avatars, h.cfg.Avatar, target.Key, target.ContentType)
+	if errors.Is(err, imaging.ErrMalformed) {
+		apierror.RespondMessage(c, apierror.InvalidFile, "avatar_invalid")
+		return "", "", false
+	}
+	if err != nil {
+		h.log.Error("failed to upload avatar", logx.String("key", target.Key), logx.Err(err), requestid.Field(c))
+		apierror.RespondMessage(c, apierror.UploadFailed, "avatar_upload_failed")
+		return "", "", false
+	}
+	return key, hash, true
+}
+
+// Abort handles DELETE /upload-sessions/:id
+func (h *UploadHandler) Abort(c *gin.Context) {
+	if err := h.uploads.Abort(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleError(c, err, "")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// UploadAttachment handles POST /orders/:id/attachments, with the file in the
+// multipart field "file". Files larger than attachment.max_form_size are sent
+// in parts through /upload-sessions.
+func (h *UploadHandler) UploadAttachment(c *gin.Context) {
+	ctx := c.Request.Context()
+	orderID := c.Param("id")
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		apierror.RespondMessage(c, apierror.InvalidFile, "file_required")
+		return
+	}
+	defer file.Close()
+
+	// Validate file type and size against uploads.attachment
+	contentType := header.Header.Get("Content-Type")
+	if !h.cfg.Attachment.Allows(contentType) {
+		apierror.Respond(c, apierror.InvalidFileType)
+		return
+	}
+	if header.Size > h.cfg.Attachment.MaxFormSize {
+		apierror.RespondMessage(c, apierror.FileTooLarge, "file_too_large", "limit", sizeLimit(h.cfg.Attachment.MaxFormSize))
+		return
+	}
+
+	if _, err := h.orders.GetOrder(ctx, orderID); err != nil {
+		h.handleError(c, err, apierror.OrderNotFound)
+		return
+	}
+
+	// Nothing the malware scanner flags, or could not scan, is stored
+	if err := uploads.Scan(ctx, h.scanner, file); err != nil {
+		if errors.Is(err, uploads.ErrInfected) {
+			h.log.Warn("attachment flagged by the malware scanner", logx.String("order_id", orderID), logx.Err(err), requestid.Field(c))
+		}
+		h.handleError(c, err, "")
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		h.log.Error("failed to read attachment", logx.Err(err), requestid.Field(c))
+		apierror.RespondMessage(c, apierror.UploadFailed, "attachment_upload_failed")
+		return
+	}
+
+	key := h.attachmentKey(orderID, header.Filename)
+	stat, err := h.invoices.Put(ctx, key, io.LimitReader(file, h.cfg.Attachment.MaxFormSize), &storagex.PutOptions{ContentType: contentType})
+	if err != nil {
+		h.log.Error("failed to upload attachment", logx.String("key", key), logx.Err(err), requestid.Field(c))
+		apierror.RespondMessage(c, apierror.UploadFailed, "attachment_upload_failed")
+		return
+	}
+
+	attachment, ok := h.recordAttachment(c, orderID, key, baseFilename(header.Filename), contentType, stat.Size)
+	if !ok {
+		return
+	}
+	responsex.Created(c, "", FromDomainAttachment(attachment))
+}
+
+// recordAttachment records a stored file as attached to an order. A file
+// that could not be recorded is deleted, as nothing would list or serve it.
+// It reports whether the file was recorded, having answered c otherwise.
+func (h *UploadHandler) recordAttachment(c *gin.Context, orderID, key, filename, contentType string, size int64) (*domain.Attachment, bool) {
+	ctx := c.Request.Context()
+	attachment, err := h.attachments.AddAttachment(ctx, orderID, key, filename, contentType, size)
+	if err != nil {
+		if err := h.invoices.Delete(ctx, key); err != nil && !errors.Is(err, storagex.ErrNotFound) {
+			h.log.Warn("failed to delete unrecorded attachment", logx.String("key", key), logx.Err(err), requestid.Field(c))
+		}
+		h.handleError(c, err, apierror.OrderNotFound)
+		return nil, false
+	}
+	return attachment, true
+}
+
+// ListAttachments handles GET /orders/:id/attachments
+func (h *UploadHandler) ListAttachments(c *gin.Context) {
+	attachments, err := h.attachments.ListAttachments(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, apierror.OrderNotFound)
+		return
+	}
+
+	responses := make([]AttachmentResponse, 0, len(attachments))
+	for _, attachment := range attachments {
+		responses = append(responses, FromDomainAttachment(attachment))
+	}
+	responsex.OK(c, responses, nil)
+}
+
+// DownloadAttachment handles GET /orders/:id/attachments/:attachment_id. The
+// file is sent as a download under the name the client uploaded it with, so
+// a browser does not render it in the service's origin.
+func (h *UploadHandler) DownloadAttachment(c *gin.Context) {
+	ctx := c.Request.Context()
+	attachment, err := h.attachments.GetAttachment(ctx, c.Param("id"), c.Param("attachment_id"))
+	if err != nil {
+		h.handleError(c, err, apierror.AttachmentNotFound)
+		return
+	}
+
+	body, _, err := h.invoices.Get(ctx, attachment.Key)
+	if err != nil {
+		if errors.Is(err, storagex.ErrNotFound) {
+			h.log.Warn("attachment file is missing", logx.String("id", attachment.ID), logx.String("key", attachment.Key), requestid.Field(c))
+			apierror.Respond(c, apierror.AttachmentNotFound)
+			return
+		}
+		h.log.Error("failed to read attachment", logx.String("key", attachment.Key), logx.Err(err), requestid.Field(c))
+		apierror.Respond(c, apierror.InternalError)
+		return
+	}
+	defer body.Close()
+
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.DataFromReader(http.StatusOK, attachment.Size, contentType, body, map[string]string{
+		"Content-Disposition":    contentDisposition(attachment.Filename),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// attachmentKey returns the key a new attachment of an order is stored at:
+// under the order's id, unique, and ending in the client's filename
+func (h *UploadHandler) attachmentKey(orderID, filename string) string {
+	return h.cfg.Attachment.KeyPrefix + orderID + "/" + uuid.NewString() + "_" + safeFilename(filename)
+}
+
+// contentDisposition returns the Content-Disposition of a download of a file
+// named filename, encoded as RFC 2231 when it is not plain ASCII
+func contentDisposition(filename string) string {
+	if v := mime.FormatMediaType("attachment", map[string]string{"filename": filename}); v != "" {
+		return v
+	}
+	return "attachment"
+}
+
+// baseFilename keeps the base of a client's filename, without the
+// directories some clients send, or "file" when nothing is left
+func baseFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	if name == "." || name == "/" {
+		return "file"
+	}
+	return name
+}
+
+// safeFilename keeps the base of a client's filename, with anything but
+// letters, digits, dots, dashes and underscores replaced, so it is safe in a
+// key and a URL
+func safeFilename(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, baseFilename(name))
+	if strings.Trim(name, ".") == "" {
+		return "file"
+	}
+	return name
+}
+
+// handleError maps upload and usecase errors to HTTP responses; notFound is
+// the code of the user or order a usecase.ErrNotFound is for
+func (h *UploadHandler) handleError(c *gin.Context, err error, notFound apierror.Code) {
+	switch {
+	case errors.Is(err, uploads.ErrNotFound):
+		apierror.Respond(c, apierror.UploadNotFound)
+	case errors.Is(err, uploads.ErrInvalidPart):
+		// The message says which part and what size it must have
+		respondDetail(c, apierror.InvalidPart, "invalid_part_detail", err, uploads.ErrInvalidPart)
+	case errors.Is(err, uploads.ErrIncomplete):
+		respondDetail(c, apierror.UploadIncomplete, "upload_incomplete_detail", err, uploads.ErrIncomplete)
+	case errors.Is(err, uploads.ErrInvalidSize):
+		apierror.Respond(c, apierror.InvalidInput)
+	case errors.Is(err, uploads.ErrInfected), errors.Is(err, uploads.ErrScanFailed):
+		respondScanError(c, h.log, err)
+	case errors.Is(err, usecase.ErrNotFound) && notFound != "":
+		apierror.Respond(c, notFound)
+	case errors.Is(err, usecase.ErrUnavailable):
+		apierror.Respond(c, apierror.ServiceUnavailable)
+	default:
+		h.log.Error("unexpected error", logx.Err(err), requestid.Field(c))
+		apierror.Respond(c, apierror.InternalError)
+	}
+}
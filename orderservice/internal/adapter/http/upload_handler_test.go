@@ -0,0 +1,614 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/storagex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/gostratum/examples/orderservice/internal/buckets"
+	"github.com/gostratum/examples/orderservice/internal/domain"
+	"github.com/gostratum/examples/orderservice/internal/mocks"
+	"github.com/gostratum/examples/orderservice/internal/testutil/factories"
+	"github.com/gostratum/examples/orderservice/internal/testutil/handlertest"
+	"github.com/gostratum/examples/orderservice/internal/uploads"
+	"github.com/gostratum/examples/orderservice/internal/usecase"
+)
+
+// uploadPorts are the mocks and fakes behind an UploadHandler
+type uploadPorts struct {
+	users       *mocks.MockUserRepository
+	orders      *mocks.MockOrderRepository
+	attachments *attachmentRows
+	storage     *objectStorage
+	scanner     *verdictScanner
+	thumbnails  *recordingScheduler
+}
+
+// testUploadConfig is the uploads section of configs/base.yaml, with parts of
+// 4 bytes
+var testUploadConfig = uploads.Config{
+	PartSize:        4,
+	SessionTTL:      time.Hour,
+	CleanupInterval: time.Minute,
+	PartPrefix:      "parts/",
+	Avatar: uploads.AvatarConfig{
+		MaxSize:       10 << 20,
+		MaxFormSize:   5 << 20,
+		AllowedTypes:  []string{"image/jpeg", "image/png", "image/gif", "image/webp"},
+		KeyPrefix:     "avatars/",
+		StripMetadata: true,
+	},
+	Attachment: uploads.AttachmentConfig{
+		MaxSize:     100 << 20,
+		MaxFormSize: 10 << 20,
+		KeyPrefix:   "attachments/",
+	},
+	GC: uploads.GCConfig{Interval: time.Hour, GracePeriod: 24 * time.Hour},
+}
+
+// newUploadEngine returns an engine with the upload routes, configured by
+// testUploadConfig and then changes
+func newUploadEngine(t *testing.T, changes ...func(c *uploads.Config)) (*gin.Engine, uploadPorts) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	ports := uploadPorts{
+		users:       mocks.NewMockUserRepository(ctrl),
+		orders:      mocks.NewMockOrderRepository(ctrl),
+		attachments: &attachmentRows{},
+		storage:     &objectStorage{objects: map[string][]byte{}},
+		scanner:     &verdictScanner{},
+		thumbnails:  &recordingScheduler{},
+	}
+	cfg := testUploadConfig
+	for _, change := range changes {
+		change(&cfg)
+	}
+	service, err := uploads.New(cfg, ports.storage, ports.scanner, logx.NewNoopLogger())
+	require.NoError(t, err)
+
+	orderService := usecase.NewOrderService(ports.orders, mocks.NewMockInventoryClient(ctrl), mocks.NewMockPaymentGateway(ctrl))
+	attachmentService := usecase.NewAttachmentService(ports.orders, ports.attachments)
+	handler := NewUploadHandler(service, cfg, usecase.NewUserService(ports.users), orderService, attachmentService, buckets.Clients{buckets.Avatars: ports.storage, buckets.Invoices: ports.storage}, ports.scanner, ports.thumbnails, logx.NewNoopLogger())
+	e := gin.New()
+	RegisterUploadRoutes(e, handler)
+	return e, ports
+}
+
+// createSession starts an upload and returns its id
+func createSession(t *testing.T, e *gin.Engine, req map[string]any) string {
+	t.Helper()
+	resp := handlertest.Serve(t, e, handlertest.Request{Method: http.MethodPost, Path: "/upload-sessions", JSON: req})
+	require.Equal(t, http.StatusCreated, resp.Status, "body: %s", resp.Body)
+	return handlertest.Data[UploadSessionResponse](t, resp).ID
+}
+
+func putPart(t *testing.T, e *gin.Engine, id, n, data string) *handlertest.Response {
+	t.Helper()
+	return handlertest.Serve(t, e, handlertest.Request{
+		Method:      http.MethodPut,
+		Path:        "/upload-sessions/" + id + "/parts/" + n,
+		Body:        strings.NewReader(data),
+		ContentType: "application/octet-stream",
+	})
+}
+
+func TestUploadHandler_Attachment(t *testing.T) {
+	e, ports := newUploadEngine(t)
+	order := factories.Order(func(o *domain.Order) { o.ID = "order-1" })
+	ports.orders.EXPECT().FindByID(gomock.Any(), "order-1").Return(order, nil).Times(2)
+
+	resp := handlertest.Serve(t, e, handlertest.Request{Method: http.MethodPost, Path: "/upload-sessions", JSON: map[string]any{
+		"kind": "attachment", "owner_id": "order-1", "filename": `C:\scans\invoice 2024.pdf`, "content_type": "application/pdf", "size": 10,
+	}})
+	resp.Assert(t, handlertest.Expect{
+		Status: http.StatusCreated,
+		Data: handlertest.Fields{
+			"id":         handlertest.NotEmpty,
+			"kind":       "attachment",
+			"owner_id":   "order-1",
+			"size":       10,
+			"part_size":  4,
+			"parts":      3,
+			"received":   []int{},
+			"expires_at": handlertest.NotEmpty,
+		},
+	})
+	id := handlertest.Data[UploadSessionResponse](t, resp).ID
+
+	// Parts arrive in any order, and a part that failed is sent again
+	putPart(t, e, id, "2", "4567").Assert(t, handlertest.Expect{Status: http.StatusOK, Data: handlertest.Fields{"received": []int{2}}})
+	putPart(t, e, id, "3", "8").Assert(t, handlertest.Expect{Status: http.StatusBadRequest, Code: "INVALID_PART"})
+	putPart(t, e, id, "3", "89").Assert(t, handlertest.Expect{Status: http.StatusOK})
+
+	complete := handlertest.Request{Method: http.MethodPost, Path: "/upload-sessions/" + id + "/complete"}
+	handlertest.Serve(t, e, complete).Assert(t, handlertest.Expect{Status: http.StatusConflict, Code: "UPLOAD_INCOMPLETE"})
+	handlertest.Serve(t, e, handlertest.Request{Method: http.MethodGet, Path: "/upload-sessions/" + id}).
+		Assert(t, handlertest.Expect{Status: http.StatusOK, Data: handlertest.Fields{"received": []int{2, 3}}})
+
+	putPart(t, e, id, "1", "0123").Assert(t, handlertest.Expect{Status: http.StatusOK})
+	resp = handlertest.Serve(t, e, complete)
+	resp.Assert(t, handlertest.Expect{
+		Status: http.StatusOK,
+		Data: handlertest.Fields{
+			"id":           handlertest.NotEmpty,
+			"order_id":     "order-1",
+			"filename":     "invoice 2024.pdf",
+			"size":         10,
+			"content_type": "application/pdf",
+		},
+	})
+	key := handlertest.Data[AttachmentResponse](t, resp).Key
+	assert.Regexp(t, `^attachments/order-1/[0-9a-f-]{36}_invoice_2024\.pdf$`, key)
+	assert.Equal(t, "0123456789", string(ports.storage.objects[key]))
+	assert.Equal(t, []string{key}, ports.storage.keys(), "parts are deleted")
+	handlertest.Serve(t, e, complete).Assert(t, handlertest.Expect{Status: http.StatusNotFound, Code: "UPLOAD_NOT_FOUND"})
+
+	resp = handlertest.Serve(t, e, handlertest.Request{Method: http.MethodGet, Path: "/orders/order-1/attachments"})
+	require.Equal(t, http.StatusOK, resp.Status, "body: %s", resp.Body)
+	attachments := handlertest.Data[[]AttachmentResponse](t, resp)
+	if assert.Len(t, attachments, 1) {
+		assert.Equal(t, key, attachments[0].Key)
+		assert.Equal(t, "invoice 2024.pdf", attachments[0].Filename)
+		assert.EqualValues(t, 10, attachments[0].Size)
+	}
+	assert.Empty(t, ports.thumbnails.keys)
+}
+
+func TestUploadHandler_Avatar(t *testing.T) {
+	e, ports := newUploadEngine(t)
+	user := factories.User(func(u *domain.User) { u.ID = "user-1" })
+	ports.users.EXPECT().FindByID(gomock.Any(), "user-1").Return(user, nil).Times(2)
+	ports.users.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+	id := createSession(t, e, map[string]any{
+		"kind": "avatar", "owner_id": "user-1", "filename": "me.png", "content_type": "image/png", "size": 6,
+	})
+	putPart(t, e, id, "1", "\x89PNG").Assert(t, handlertest.Expect{Status: http.StatusOK})
+	putPart(t, e, id, "2", "\r\n").Assert(t, handlertest.Expect{Status: http.StatusOK})
+
+	resp := handlertest.Serve(t, e, handlertest.Request{Method: http.MethodPost, Path: "/upload-sessions/" + id + "/complete"})
+	resp.Assert(t, handlertest.Expect{Status: http.StatusOK, Data: handlertest.Fields{"id": "user-1", "avatar_url": handlertest.NotEmpty}})
+
+	// The avatar is stored under the hash of its content and becomes the
+	// user's, and its thumbnails are queued. The joined file is deleted.
+	key := handlertest.Data[UserResponse](t, resp).AvatarURL
+	wantKey, _ := uploads.ContentKey("avatars/", []byte("\x89PNG\r\n"), "image/png")
+	assert.Equal(t, wantKey, key)
+	assert.Equal(t, []string{key}, ports.storage.keys())
+	assert.Equal(t, []string{key}, ports.thumbnails.keys)
+}
+
+func TestUploadHandler_AvatarStripMetadata(t *testing.T) {
+	// sendAvatar uploads file as user-1's avatar, in parts, and completes it
+	sendAvatar := func(t *testing.T, e *gin.Engine, file string) *handlertest.Response {
+		id := createSession(t, e, map[string]any{
+			"kind": "avatar", "owner_id": "user-1", "filename": "me.jpg", "content_type": "image/jpeg", "size": len(file),
+		})
+		for n := 0; n*4 < len(file); n++ {
+			part := file[n*4 : min(n*4+4, len(file))]
+			putPart(t, e, id, strconv.Itoa(n+1), part).Assert(t, handlertest.Expect{Status: http.StatusOK})
+		}
+		return handlertest.Serve(t, e, handlertest.Request{Method: http.MethodPost, Path: "/upload-sessions/" + id + "/complete"})
+	}
+
+	t.Run("EXIF is removed", func(t *testing.T) {
+		e, ports := newUploadEngine(t)
+		user := factories.User(func(u *domain.User) { u.ID = "user-1" })
+		ports.users.EXPECT().FindByID(gomock.Any(), "user-1").Return(user, nil).Times(2)
+		ports.users.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+		resp := sendAvatar(t, e, exifJPEG)
+		resp.Assert(t, handlertest.Expect{Status: http.StatusOK})
+		key := handlertest.Data[UserResponse](t, resp).AvatarURL
+		assert.Equal(t, strippedJPEG, string(ports.storage.objects[key]))
+	})
+
+	t.Run("malformed image is deleted", func(t *testing.T) {
+		e, ports := newUploadEngine(t)
+		ports.users.EXPECT().FindByID(gomock.Any(), "user-1").Return(factories.User(), nil)
+
+		sendAvatar(t, e, malformedJPEG).Assert(t, handlertest.Expect{
+			Status: http.StatusBadRequest, Code: "INVALID_FILE", Message: "avatar is not a valid image",
+		})
+		assert.Empty(t, ports.storage.keys())
+		assert.Empty(t, ports.thumbnails.keys)
+	})
+}
+
+func TestUploadHandler_Scan(t *testing.T) {
+	e, ports := newUploadEngine(t)
+	order := factories.Order(func(o *domain.Order) { o.ID = "order-1" })
+	ports.orders.EXPECT().FindByID(gomock.Any(), "order-1").Return(order, nil)
+
+	id := createSession(t, e, map[string]any{
+		"kind": "attachment", "owner_id": "order-1", "filename": "invoice.pdf", "content_type": "application/pdf", "size": 4,
+	})
+	putPart(t, e, id, "1", "0123").Assert(t, handlertest.Expect{Status: http.StatusOK})
+	complete := func() *handlertest.Response {
+		return handlertest.Serve(t, e, handlertest.Request{Method: http.MethodPost, Path: "/upload-sessions/" + id + "/complete"})
+	}
+
+	// A file the scanner cannot vouch for is not stored, and may be
+	// completed again
+	ports.scanner.err = errors.New("clamd: connection refused")
+	resp := complete()
+	resp.Assert(t, handlertest.Expect{Status: http.StatusServiceUnavailable, Code: "SCAN_UNAVAILABLE"})
+	assert.Equal(t, "2", resp.Header.Get("Retry-After"))
+	assert.Equal(t, []string{"parts/" + id + "/00001"}, ports.storage.keys())
+
+	// A flagged file is not stored, and its session ends
+	ports.scanner.err = fmt.Errorf("%w: Eicar-Signature", uploads.ErrInfected)
+	complete().Assert(t, handlertest.Expect{
+		Status: http.StatusUnprocessableEntity, Code: "FILE_INFECTED", Message: "file was flagged by the malware scanner",
+	})
+	assert.Empty(t, ports.storage.keys())
+	complete().Assert(t, handlertest.Expect{Status: http.StatusNotFound, Code: "UPLOAD_NOT_FOUND"})
+}
+
+func TestUploadHandler_CreateSession(t *testing.T) {
+	avatar := func(overrides map[string]any) map[string]any {
+		req := map[string]any{"kind": "avatar", "owner_id": "user-1", "filename": "me.png", "content_type": "image/png", "size": 1024}
+		for k, v := range overrides {
+			req[k] = v
+		}
+		return req
+	}
+
+	tests := []struct {
+		name    string
+		request map[string]any
+		arrange func(p uploadPorts)
+		want    handlertest.Expect
+	}{
+		{
+			name:    "unknown kind",
+			request: avatar(map[string]any{"kind": "video"}),
+			want:    handlertest.Expect{Status: http.StatusBadRequest, Code: "INVALID_REQUEST"},
+		},
+		{
+			name:    "empty file",
+			request: avatar(map[string]any{"size": 0}),
+			want:    handlertest.Expect{Status: http.StatusBadRequest, Code: "INVALID_REQUEST"},
+		},
+		{
+			name:    "avatar that is not an image",
+			request: avatar(map[string]any{"content_type": "application/pdf"}),
+			want:    handlertest.Expect{Status: http.StatusBadRequest, Code: "INVALID_FILE_TYPE"},
+		},
+		{
+			name:    "avatar too large for thumbnails",
+			request: avatar(map[string]any{"size": 10<<20 + 1}),
+			want:    handlertest.Expect{Status: http.StatusBadRequest, Code: "FILE_TOO_LARGE", Message: "file size exceeds 10MB limit"},
+		},
+		{
+			name:    "non-existing user",
+			request: avatar(nil),
+			arrange: func(p uploadPorts) {
+				p.users.EXPECT().FindByID(gomock.Any(), "user-1").Return(nil, domain.ErrNotFound)
+			},
+			want: handlertest.Expect{Status: http.StatusNotFound, Code: "USER_NOT_FOUND"},
+		},
+		{
+			name:    "attachment too large",
+			request: avatar(map[string]any{"kind": "attachment", "owner_id": "order-1", "size": 100<<20 + 1}),
+			want:    handlertest.Expect{Status: http.StatusBadRequest, Code: "FILE_TOO_LARGE", Message: "file size exceeds 100MB limit"},
+		},
+		{
+			name:    "attachment type not allowed",
+			request: avatar(map[string]any{"kind": "attachment", "owner_id": "order-1", "content_type": "application/x-msdownload"}),
+			want:    handlertest.Expect{Status: http.StatusBadRequest, Code: "INVALID_FILE_TYPE", Message: "file type is not allowed"},
+		},
+		{
+			name:    "non-existing order",
+			request: avatar(map[string]any{"kind": "attachment", "owner_id": "order-1"}),
+			arrange: func(p uploadPorts) {
+				p.orders.EXPECT().FindByID(gomock.Any(), "order-1").Return(nil, domain.ErrNotFound)
+			},
+			want: handlertest.Expect{Status: http.StatusNotFound, Code: "ORDER_NOT_FOUND"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, ports := newUploadEngine(t, func(c *uploads.Config) {
+				c.Attachment.AllowedTypes = []string{"application/pdf", "image/png"}
+			})
+			if tt.arrange != nil {
+				tt.arrange(ports)
+			}
+			handlertest.Serve(t, e, handlertest.Request{Method: http.MethodPost, Path: "/upload-sessions", JSON: tt.request}).Assert(t, tt.want)
+		})
+	}
+}
+
+func TestUploadHandler_Abort(t *testing.T) {
+	e, ports := newUploadEngine(t)
+	ports.orders.EXPECT().FindByID(gomock.Any(), "order-1").Return(factories.Order(), nil)
+
+	id := createSession(t, e, map[string]any{
+		"kind": "attachment", "owner_id": "order-1", "filename": "notes.txt", "size": 8,
+	})
+	putPart(t, e, id, "1", "0123").Assert(t, handlertest.Expect{Status: http.StatusOK})
+	putPart(t, e, id, "x", "0123").Assert(t, handlertest.Expect{Status: http.StatusBadRequest, Code: "INVALID_PART"})
+
+	abort := handlertest.Request{Method: http.MethodDelete, Path: "/upload-sessions/" + id}
+	assert.Equal(t, http.StatusNoContent, handlertest.Serve(t, e, abort).Status)
+	assert.Empty(t, ports.storage.keys(), "parts are deleted")
+
+	handlertest.Serve(t, e, abort).Assert(t, handlertest.Expect{Status: http.StatusNotFound, Code: "UPLOAD_NOT_FOUND"})
+	putPart(t, e, id, "2", "4567").Assert(t, handlertest.Expect{Status: http.StatusNotFound, Code: "UPLOAD_NOT_FOUND"})
+}
+
+// attachmentFile returns a multipart form with data in the field "file", as
+// a browser sends it
+func attachmentFile(filename, contentType, data string) (io.Reader, string) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreatePart(textproto.MIMEHeader{
+		"Content-Disposition": {fmt.Sprintf(`form-data; name="file"; filename=%q`, filename)},
+		"Content-Type":        {contentType},
+	})
+	_, _ = part.Write([]byte(data))
+	_ = form.Close()
+	return &body, form.FormDataContentType()
+}
+
+func TestUploadHandler_UploadAttachment(t *testing.T) {
+	order := factories.Order(func(o *domain.Order) { o.ID = "order-1" })
+
+	tests := []struct {
+		name     string
+		filename string
+		data     string
+		arrange  func(p uploadPorts)
+		want     handlertest.Expect
+		stored   int
+	}{
+		{
+			name:     "document is stored and recorded",
+			filename: `C:\scans\PO 1234.pdf`,
+			data:     "%PDF-1.7",
+			arrange: func(p uploadPorts) {
+				p.orders.EXPECT().FindByID(gomock.Any(), "order-1").Return(order, nil)
+			},
+			want: handlertest.Expect{
+				Status: http.StatusCreated,
+				Data: handlertest.Fields{
+					"id":           handlertest.NotEmpty,
+					"order_id":     "order-1",
+					"filename":     "PO 1234.pdf",
+					"size":         8,
+					"content_type": "application/pdf",
+				},
+			},
+			stored: 1,
+		},
+		{
+			name:     "type not allowed",
+			filename: "setup.exe",
+			data:     "MZ",
+			want:     handlertest.Expect{Status: http.StatusBadRequest, Code: "INVALID_FILE_TYPE", Message: "file type is not allowed"},
+		},
+		{
+			name:     "too large to send whole",
+			filename: "scan.pdf",
+			data:     "%PDF-1.7 and more",
+			want:     handlertest.Expect{Status: http.StatusBadRequest, Code: "FILE_TOO_LARGE", Message: "file size exceeds 16 bytes limit"},
+		},
+		{
+			name:     "non-existing order",
+			filename: "scan.pdf",
+			data:     "%PDF-1.7",
+			arrange: func(p uploadPorts) {
+				p.orders.EXPECT().FindByID(gomock.Any(), "order-1").Return(nil, domain.ErrNotFound)
+			},
+			want: handlertest.Expect{Status: http.StatusNotFound, Code: "ORDER_NOT_FOUND"},
+		},
+		{
+			name:     "flagged by the malware scanner",
+			filename: "scan.pdf",
+			data:     "%PDF-1.7",
+			arrange: func(p uploadPorts) {
+				p.orders.EXPECT().FindByID(gomock.Any(), "order-1").Return(order, nil)
+				p.scanner.err = fmt.Errorf("%w: Eicar-Signature", uploads.ErrInfected)
+			},
+			want: handlertest.Expect{Status: http.StatusUnprocessableEntity, Code: "FILE_INFECTED"},
+		},
+		{
+			name:     "not recorded, as the order was deleted meanwhile",
+			filename: "scan.pdf",
+			data:     "%PDF-1.7",
+			arrange: func(p uploadPorts) {
+				p.orders.EXPECT().FindByID(gomock.Any(), "order-1").Return(order, nil)
+				p.attachments.err = domain.ErrNotFound
+			},
+			want: handlertest.Expect{Status: http.StatusNotFound, Code: "ORDER_NOT_FOUND"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, ports := newUploadEngine(t, func(c *uploads.Config) {
+				c.Attachment.AllowedTypes = []string{"application/pdf"}
+				c.Attachment.MaxFormSize = 16
+			})
+			if tt.arrange != nil {
+				tt.arrange(ports)
+			}
+
+			contentType := "application/pdf"
+			if strings.HasSuffix(tt.filename, ".exe") {
+				contentType = "application/x-msdownload"
+			}
+			body, formType := attachmentFile(tt.filename, contentType, tt.data)
+			resp := handlertest.Serve(t, e, handlertest.Request{
+				Method:      http.MethodPost,
+				Path:        "/orders/order-1/attachments",
+				Body:        body,
+				ContentType: formType,
+			})
+			resp.Assert(t, tt.want)
+
+			// Nothing is left in storage that is not recorded
+			assert.Len(t, ports.storage.keys(), tt.stored)
+			assert.Len(t, ports.attachments.rows, tt.stored)
+			if tt.stored > 0 {
+				attachment := handlertest.Data[AttachmentResponse](t, resp)
+				assert.Regexp(t, `^attachments/order-1/[0-9a-f-]{36}_PO_1234\.pdf$`, attachment.Key)
+				assert.Equal(t, tt.data, string(ports.storage.objects[attachment.Key]))
+			}
+		})
+	}
+
+	t.Run("file is required", func(t *testing.T) {
+		e, _ := newUploadEngine(t)
+		handlertest.Serve(t, e, handlertest.Request{Method: http.MethodPost, Path: "/orders/order-1/attachments", JSON: map[string]any{}}).
+			Assert(t, handlertest.Expect{Status: http.StatusBadRequest, Code: "INVALID_FILE"})
+	})
+}
+
+func TestUploadHandler_DownloadAttachment(t *testing.T) {
+	e, ports := newUploadEngine(t)
+	ports.storage.objects["attachments/order-1/a_receipt.pdf"] = []byte("%PDF-1.7")
+	ports.attachments.rows = []*domain.Attachment{
+		{ID: "a1", OrderID: "order-1", Key: "attachments/order-1/a_receipt.pdf", Filename: "Quittung März.pdf", ContentType: "application/pdf", Size: 8},
+		{ID: "a2", OrderID: "order-1", Key: "attachments/order-1/b_gone.pdf", Filename: "gone.pdf", Size: 4},
+	}
+	download := func(path string) *handlertest.Response {
+		return handlertest.Serve(t, e, handlertest.Request{Method: http.MethodGet, Path: path})
+	}
+
+	resp := download("/orders/order-1/attachments/a1")
+	require.Equal(t, http.StatusOK, resp.Status, "body: %s", resp.Body)
+	assert.Equal(t, "%PDF-1.7", string(resp.Body))
+	assert.Equal(t, "application/pdf", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "nosniff", resp.Header.Get("X-Content-Type-Options"))
+	assert.Equal(t, "attachment; filename*=utf-8''Quittung%20M%C3%A4rz.pdf", resp.Header.Get("Content-Disposition"))
+
+	// An attachment is only found under its own order, and one whose file is
+	// missing is not found either
+	download("/orders/order-2/attachments/a1").Assert(t, handlertest.Expect{Status: http.StatusNotFound, Code: "ATTACHMENT_NOT_FOUND"})
+	download("/orders/order-1/attachments/a3").Assert(t, handlertest.Expect{Status: http.StatusNotFound, Code: "ATTACHMENT_NOT_FOUND"})
+	download("/orders/order-1/attachments/a2").Assert(t, handlertest.Expect{Status: http.StatusNotFound, Code: "ATTACHMENT_NOT_FOUND"})
+}
+
+// attachmentRows keeps attachment records in memory, and fails to save with
+// err
+type attachmentRows struct {
+	mu   sync.Mutex
+	rows []*domain.Attachment
+	err  error
+}
+
+func (r *attachmentRows) Save(ctx context.Context, a *domain.Attachment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	stored := *a
+	r.rows = append(r.rows, &stored)
+	return nil
+}
+
+func (r *attachmentRows) FindByID(ctx context.Context, id string) (*domain.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, a := range r.rows {
+		if a.ID == id {
+			return a, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *attachmentRows) ListByOrder(ctx context.Context, orderID string) ([]*domain.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found []*domain.Attachment
+	for _, a := range r.rows {
+		if a.OrderID == orderID {
+			found = append(found, a)
+		}
+	}
+	return found, nil
+}
+
+// objectStorage keeps objects in memory, with the methods upload sessions use
+type objectStorage struct {
+	storagex.Storage
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *objectStorage) Put(ctx context.Context, key string, r io.Reader, opts *storagex.PutOptions) (storagex.Stat, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return storagex.Stat{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+	return storagex.Stat{Key: key, Size: int64(len(data)), ContentType: opts.ContentType}, nil
+}
+
+func (s *objectStorage) Get(ctx context.Context, key string) (io.ReadCloser, storagex.Stat, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, storagex.Stat{}, storagex.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), storagex.Stat{Key: key, Size: int64(len(data))}, nil
+}
+
+func (s *objectStorage) List(ctx context.Context, opts storagex.ListOptions) (storagex.ListPage, error) {
+	var page storagex.ListPage
+	for _, key := range s.keys() {
+		if strings.HasPrefix(key, opts.Prefix) {
+			s.mu.Lock()
+			page.Keys = append(page.Keys, storagex.Stat{Key: key, Size: int64(len(s.objects[key]))})
+			s.mu.Unlock()
+		}
+	}
+	return page, nil
+}
+
+func (s *objectStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.objects[key]; !ok {
+		return storagex.ErrNotFound
+	}
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *objectStorage) keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
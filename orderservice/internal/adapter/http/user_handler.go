@@ -1,34 +1,50 @@
 package http
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"mime/multipart"
 	"net/http"
-	"path/filepath"
-	"strings"
-	"time"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gostratum/core/logx"
 	"github.com/gostratum/httpx/responsex"
 	"github.com/gostratum/storagex"
 
+	"github.com/gostratum/examples/orderservice/internal/apierror"
+	"github.com/gostratum/examples/orderservice/internal/buckets"
+	"github.com/gostratum/examples/orderservice/internal/requestid"
+	"github.com/gostratum/examples/orderservice/internal/uploads"
 	"github.com/gostratum/examples/orderservice/internal/usecase"
+	"github.com/gostratum/examples/workkit/imaging"
 )
 
+// ThumbnailScheduler queues the thumbnails of an uploaded avatar, to be made
+// after the upload is answered; implemented by tasks.AvatarThumbnailScheduler
+type ThumbnailScheduler interface {
+	Schedule(key string) error
+}
+
 // UserHandler handles user-related HTTP requests
 type UserHandler struct {
 	service       *usecase.UserService
 	storageClient storagex.Storage
+	scanner       uploads.Scanner
+	thumbnails    ThumbnailScheduler
+	cfg           uploads.Config
 	log           logx.Logger
 }
 
-// NewUserHandler creates a new user handler
-func NewUserHandler(service *usecase.UserService, storageClient storagex.Storage, log logx.Logger) *UserHandler {
+// NewUserHandler creates a new user handler, storing avatars in the avatars
+// client
+func NewUserHandler(service *usecase.UserService, clients buckets.Clients, scanner uploads.Scanner, thumbnails ThumbnailScheduler, cfg uploads.Config, log logx.Logger) *UserHandler {
 	return &UserHandler{
 		service:       service,
-		storageClient: storageClient,
+		storageClient: clients[buckets.Avatars],
+		scanner:       scanner,
+		thumbnails:    thumbnails,
+		cfg:           cfg,
 		log:           log,
 	}
 }
@@ -43,7 +59,7 @@ type CreateUserRequest struct {
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var req CreateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid request payload", nil)
+		apierror.Respond(c, apierror.InvalidRequest)
 		return
 	}
 
@@ -62,7 +78,7 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 func (h *UserHandler) GetUser(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		responsex.Error(c, http.StatusBadRequest, "MISSING_PARAMETER", "user id is required", nil)
+		apierror.RespondMessage(c, apierror.MissingParameter, "user_id_required")
 		return
 	}
 
@@ -81,90 +97,141 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 func (h *UserHandler) UploadAvatar(c *gin.Context) {
 	userID := c.Param("id")
 	if userID == "" {
-		responsex.Error(c, http.StatusBadRequest, "MISSING_PARAMETER", "user id is required", nil)
+		apierror.RespondMessage(c, apierror.MissingParameter, "user_id_required")
 		return
 	}
 
-	// Get the uploaded file
-	file, header, err := c.Request.FormFile("avatar")
+	ctx := c.Request.Context()
+
+	// The file is read from the body as it arrives, and bounded by
+	// uploads.avatar.max_form_size as it is streamed to storage, so it is
+	// neither spooled to disk nor held whole in memory. The other fields
+	// are bounded by the body's limit.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.cfg.Avatar.MaxFormSize+maxFormFields)
+	file, err := formFile(c.Request, "avatar")
 	if err != nil {
-		responsex.Error(c, http.StatusBadRequest, "INVALID_FILE", "avatar file is required", nil)
+		apierror.RespondMessage(c, apierror.InvalidFile, "avatar_required")
 		return
 	}
 	defer file.Close()
 
-	// Validate file type
-	if !h.isValidImageType(header) {
-		responsex.Error(c, http.StatusBadRequest, "INVALID_FILE_TYPE", "only image files are allowed", nil)
+	// Validate file type against uploads.avatar
+	contentType := file.Header.Get("Content-Type")
+	if !h.cfg.Avatar.Allows(contentType) {
+		apierror.RespondMessage(c, apierror.InvalidFileType, "images_only")
 		return
 	}
 
-	// Validate file size (5MB max)
-	if header.Size > 5*1024*1024 {
-		responsex.Error(c, http.StatusBadRequest, "FILE_TOO_LARGE", "file size exceeds 5MB limit", nil)
+	// Staged among the parts until it is scanned, then stored under the
+	// hash of its content
+	staged := avatarStagingKey(h.cfg)
+	counted := &uploads.CountingReader{R: file, Limit: h.cfg.Avatar.MaxFormSize, Step: h.cfg.ProgressStep, Progress: func(n int64) {
+		h.log.Info("receiving avatar",
+			logx.String("user_id", userID),
+			logx.Int("bytes", int(n)),
+			logx.Int("content_length", int(c.Request.ContentLength)),
+			requestid.Field(c),
+		)
+	}}
+	if _, err := h.storageClient.Put(ctx, staged, counted, &storagex.PutOptions{ContentType: contentType, Overwrite: true}); err != nil {
+		var maxBytes *http.MaxBytesError
+		switch {
+		case errors.Is(counted.Err(), uploads.ErrTooLarge), errors.As(counted.Err(), &maxBytes):
+			apierror.RespondMessage(c, apierror.FileTooLarge, "file_too_large", "limit", sizeLimit(h.cfg.Avatar.MaxFormSize))
+		case counted.Err() != nil:
+			apierror.RespondMessage(c, apierror.InvalidFile, "avatar_unreadable")
+		default:
+			h.log.Error("failed to upload avatar", logx.String("key", staged), logx.Err(err), requestid.Field(c))
+			apierror.RespondMessage(c, apierror.UploadFailed, "avatar_upload_failed")
+		}
 		return
 	}
+	defer func() {
+		if err := h.storageClient.Delete(ctx, staged); err != nil && !errors.Is(err, storagex.ErrNotFound) {
+			h.log.Warn("failed to delete staged avatar", logx.String("key", staged), logx.Err(err), requestid.Field(c))
+		}
+	}()
 
-	// Generate unique filename
-	ext := filepath.Ext(header.Filename)
-	filename := fmt.Sprintf("avatars/%s_%d%s", userID, time.Now().Unix(), ext)
+	// Nothing the malware scanner flags, or could not scan, is stored
+	if err := h.scan(ctx, staged); err != nil {
+		if errors.Is(err, uploads.ErrInfected) {
+			h.log.Warn("avatar flagged by the malware scanner", logx.String("user_id", userID), logx.Err(err), requestid.Field(c))
+		}
+		h.handleError(c, err)
+		return
+	}
 
-	// Upload to storage
-	_, err = h.storageClient.Put(c.Request.Context(), filename, file, &storagex.PutOptions{
-		ContentType: header.Header.Get("Content-Type"),
-		Overwrite:   true,
-	})
+	// Upload to storage under the hash of the content, without EXIF such as
+	// where the photo was taken
+	key, hash, err := storeStagedAvatar(ctx, h.storageClient, h.cfg.Avatar, staged, contentType)
+	if errors.Is(err, imaging.ErrMalformed) {
+		apierror.RespondMessage(c, apierror.InvalidFile, "avatar_invalid")
+		return
+	}
 	if err != nil {
-		h.log.Error("failed to upload avatar", logx.Err(err))
-		responsex.Error(c, http.StatusInternalServerError, "UPLOAD_FAILED", "failed to upload avatar", nil)
+		h.log.Error("failed to upload avatar", logx.Err(err), requestid.Field(c))
+		apierror.RespondMessage(c, apierror.UploadFailed, "avatar_upload_failed")
 		return
 	}
 
 	// For cloud storage, you might want to use a presigned URL or construct the full S3 URL
 	// For now, we'll use the key as the URL - this should be customized based on your deployment
-	url := filename
+	url := key
 
 	// Update user avatar in database
-	user, err := h.service.UpdateAvatar(c.Request.Context(), userID, url)
+	user, err := h.service.UpdateAvatar(ctx, userID, url, hash)
 	if err != nil {
 		h.handleError(c, err)
 		return
 	}
 
+	// The thumbnails are made in the background. If the queue is full the
+	// avatar goes without them, and the upload still succeeds.
+	if err := h.thumbnails.Schedule(key); err != nil {
+		h.log.Warn("failed to schedule avatar thumbnails", logx.String("key", key), logx.Err(err), requestid.Field(c))
+	}
+
 	userResponse := FromDomainUser(user)
 	responsex.OK(c, userResponse, nil)
 }
 
-// isValidImageType checks if the uploaded file is a valid image type
-func (h *UserHandler) isValidImageType(header *multipart.FileHeader) bool {
-	contentType := header.Header.Get("Content-Type")
-	validTypes := []string{
-		"image/jpeg",
-		"image/png",
-		"image/gif",
-		"image/webp",
+// maxFormFields bounds what a form sends besides its file: the boundaries,
+// the part headers and any other fields
+const maxFormFields = 64 << 10
+
+// scan scans the staged avatar at key
+func (h *UserHandler) scan(ctx context.Context, key string) error {
+	body, _, err := h.storageClient.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to read avatar: %w", err)
 	}
+	defer body.Close()
+	return uploads.Scan(ctx, h.scanner, body)
+}
 
-	for _, validType := range validTypes {
-		if strings.EqualFold(contentType, validType) {
-			return true
-		}
+// sizeLimit formats a size limit for error messages, e.g. 5MB
+func sizeLimit(n int64) string {
+	if n%(1<<20) == 0 {
+		return strconv.FormatInt(n>>20, 10) + "MB"
 	}
-	return false
+	return strconv.FormatInt(n, 10) + " bytes"
 }
 
-// handleError maps usecase errors to HTTP responses
+// handleError maps usecase and scan errors to HTTP responses
 func (h *UserHandler) handleError(c *gin.Context, err error) {
 	switch {
+	case errors.Is(err, uploads.ErrInfected), errors.Is(err, uploads.ErrScanFailed):
+		respondScanError(c, h.log, err)
 	case errors.Is(err, usecase.ErrNotFound):
-		responsex.Error(c, http.StatusNotFound, "USER_NOT_FOUND", "user not found", nil)
+		apierror.Respond(c, apierror.UserNotFound)
 	case errors.Is(err, usecase.ErrInvalid):
-		responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", "invalid input", nil)
+		respondInvalid(c, err)
+	case errors.Is(err, usecase.ErrConflict):
+		apierror.Respond(c, apierror.EmailTaken)
 	case errors.Is(err, usecase.ErrUnavailable):
-		c.Header("Retry-After", "2")
-		responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "service temporarily unavailable", nil)
+		apierror.Respond(c, apierror.ServiceUnavailable)
 	default:
-		h.log.Error("unexpected error", logx.Err(err))
-		responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+		h.log.Error("unexpected error", logx.Err(err), requestid.Field(c))
+		apierror.Respond(c, apierror.InternalError)
 	}
 }
@@ -1,76 +1,402 @@
 package http
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
-	"net/http/httptest"
+	"net/textproto"
 	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/gostratum/examples/orderservice/internal/buckets"
+	"github.com/gostratum/examples/orderservice/internal/domain"
+	"github.com/gostratum/examples/orderservice/internal/mocks"
+	"github.com/gostratum/examples/orderservice/internal/testutil/factories"
+	"github.com/gostratum/examples/orderservice/internal/testutil/handlertest"
+	"github.com/gostratum/examples/orderservice/internal/uploads"
+	"github.com/gostratum/examples/orderservice/internal/usecase"
 )
 
-func TestUploadAvatarEndpoint(t *testing.T) {
+func TestUserHandler_UploadAvatar(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	t.Run("missing avatar file returns bad request", func(t *testing.T) {
-		// Create a simple test that doesn't require complex mocking
-		// Just test that the endpoint exists and handles missing files correctly
+	testUser := factories.User(func(u *domain.User) { u.ID = "test-user-id" })
+
+	// avatarKey matches the key the avatar is stored under, named by the
+	// hash of the file avatarForm sends
+	want, _ := uploads.ContentKey("avatars/", []byte("\x89PNG\r\n\x1a\n"), "image/png")
+	var avatarKey handlertest.Matcher = func(got any) error {
+		if got != want {
+			return fmt.Errorf("is %q, want %s", got, want)
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name         string
+		userID       string
+		body         func() (io.Reader, string)
+		findUser     *domain.User
+		findError    error
+		expectUpdate bool
+		scheduleErr  error
+		want         handlertest.Expect
+	}{
+		{
+			name:         "uploads the avatar",
+			userID:       "test-user-id",
+			body:         avatarForm("image/png"),
+			findUser:     testUser,
+			expectUpdate: true,
+			want: handlertest.Expect{
+				Status: http.StatusOK,
+				Data:   handlertest.Fields{"id": testUser.ID, "avatar_url": avatarKey},
+			},
+		},
+		{
+			name:         "succeeds when the thumbnails cannot be queued",
+			userID:       "test-user-id",
+			body:         avatarForm("image/png"),
+			findUser:     testUser,
+			expectUpdate: true,
+			scheduleErr:  errors.New("queue is full"),
+			want: handlertest.Expect{
+				Status: http.StatusOK,
+				Data:   handlertest.Fields{"id": testUser.ID, "avatar_url": avatarKey},
+			},
+		},
+		{
+			name:   "missing user id",
+			userID: "",
+			body:   avatarForm("image/png"),
+			want:   handlertest.Expect{Status: http.StatusBadRequest, Code: "MISSING_PARAMETER", Message: "user id is required"},
+		},
+		{
+			name:   "missing avatar file",
+			userID: "test-user-id",
+			body:   func() (io.Reader, string) { return strings.NewReader(""), "application/x-www-form-urlencoded" },
+			want:   handlertest.Expect{Status: http.StatusBadRequest, Code: "INVALID_FILE", Message: "avatar file is required"},
+		},
+		{
+			name:   "not an image",
+			userID: "test-user-id",
+			body:   avatarForm("text/plain"),
+			want:   handlertest.Expect{Status: http.StatusBadRequest, Code: "INVALID_FILE_TYPE", Message: "only image files are allowed"},
+		},
+		{
+			name:      "non-existing user",
+			userID:    "test-user-id",
+			body:      avatarForm("image/png"),
+			findError: usecase.ErrNotFound,
+			want:      handlertest.Expect{Status: http.StatusNotFound, Code: "USER_NOT_FOUND", Message: "user not found"},
+		},
+	}
+
+	logger := logx.NewNoopLogger()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewMockUserRepository(gomock.NewController(t))
+			if tt.findUser != nil || tt.findError != nil {
+				repo.EXPECT().FindByID(gomock.Any(), tt.userID).Return(tt.findUser, tt.findError)
+			}
+			if tt.expectUpdate {
+				repo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+			}
+			thumbnails := &recordingScheduler{err: tt.scheduleErr}
+			handler := NewUserHandler(usecase.NewUserService(repo), buckets.Clients{buckets.Avatars: &objectStorage{objects: map[string][]byte{}}}, uploads.NopScanner{}, thumbnails, testUploadConfig, logger)
 
-		router := gin.New()
+			body, contentType := tt.body()
+			handlertest.Call(t, handler.UploadAvatar, handlertest.Request{
+				Method:      http.MethodPost,
+				Path:        "/users/" + tt.userID + "/avatar",
+				Params:      gin.Params{{Key: "id", Value: tt.userID}},
+				Body:        body,
+				ContentType: contentType,
+			}).Assert(t, tt.want)
 
-		// Add a simple handler for testing
-		router.POST("/users/:id/avatar", func(c *gin.Context) {
-			_, _, err := c.Request.FormFile("avatar")
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{
-					"error":   "INVALID_FILE",
-					"message": "avatar file is required",
-				})
+			// The thumbnails of a stored avatar are queued, and of nothing else
+			if !tt.expectUpdate {
+				assert.Empty(t, thumbnails.keys)
 				return
 			}
-			c.JSON(http.StatusOK, gin.H{"status": "ok"})
+			if assert.Len(t, thumbnails.keys, 1) {
+				assert.NoError(t, avatarKey(thumbnails.keys[0]))
+			}
 		})
+	}
+}
+
+func TestUserHandler_UploadAvatar_Config(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	avatars := uploads.AvatarConfig{
+		MaxSize:      1 << 20,
+		MaxFormSize:  1 << 20,
+		AllowedTypes: []string{"image/jpeg", "image/png"},
+		KeyPrefix:    "people/",
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		maxFormSize int64
+		want        handlertest.Expect
+		wantKey     string
+	}{
+		{
+			name:        "stored under the prefix, with the extension of the type",
+			contentType: "image/jpeg",
+			want:        handlertest.Expect{Status: http.StatusOK},
+			wantKey:     `^people/[0-9a-f]{64}\.jpg$`,
+		},
+		{
+			name:        "type not allowed",
+			contentType: "image/gif",
+			want:        handlertest.Expect{Status: http.StatusBadRequest, Code: "INVALID_FILE_TYPE"},
+		},
+		{
+			name:        "larger than the limit",
+			contentType: "image/png",
+			maxFormSize: 4,
+			want:        handlertest.Expect{Status: http.StatusBadRequest, Code: "FILE_TOO_LARGE", Message: "file size exceeds 4 bytes limit"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewMockUserRepository(gomock.NewController(t))
+			if tt.wantKey != "" {
+				repo.EXPECT().FindByID(gomock.Any(), "test-user-id").Return(factories.User(), nil)
+				repo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+			}
+			cfg := testUploadConfig
+			cfg.Avatar = avatars
+			if tt.maxFormSize != 0 {
+				cfg.Avatar.MaxFormSize = tt.maxFormSize
+			}
+			thumbnails := &recordingScheduler{}
+			storage := &objectStorage{objects: map[string][]byte{}}
+			handler := NewUserHandler(usecase.NewUserService(repo), buckets.Clients{buckets.Avatars: storage}, uploads.NopScanner{}, thumbnails, cfg, logx.NewNoopLogger())
 
-		// Create request without file
-		req := httptest.NewRequest("POST", "/users/test-user-id/avatar", strings.NewReader(""))
-		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			body, contentType := avatarForm(tt.contentType)()
+			handlertest.Call(t, handler.UploadAvatar, handlertest.Request{
+				Method:      http.MethodPost,
+				Path:        "/users/test-user-id/avatar",
+				Params:      gin.Params{{Key: "id", Value: "test-user-id"}},
+				Body:        body,
+				ContentType: contentType,
+			}).Assert(t, tt.want)
 
-		// Create recorder
-		w := httptest.NewRecorder()
+			// The file was streamed to storage among the parts, and only
+			// the avatar stored under its hash is left
+			if tt.wantKey == "" {
+				assert.Empty(t, storage.keys())
+				return
+			}
+			if assert.Len(t, thumbnails.keys, 1) {
+				assert.Regexp(t, tt.wantKey, thumbnails.keys[0])
+				assert.Equal(t, thumbnails.keys, storage.keys())
+			}
+		})
+	}
+}
 
-		// Perform request
-		router.ServeHTTP(w, req)
+func TestUserHandler_UploadAvatar_StripMetadata(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name          string
+		stripMetadata bool
+		file          string
+		want          handlertest.Expect
+		wantStored    string
+	}{
+		{
+			name:          "EXIF is removed",
+			stripMetadata: true,
+			file:          exifJPEG,
+			want:          handlertest.Expect{Status: http.StatusOK},
+			wantStored:    strippedJPEG,
+		},
+		{
+			name:       "stored as sent when turned off",
+			file:       exifJPEG,
+			want:       handlertest.Expect{Status: http.StatusOK},
+			wantStored: exifJPEG,
+		},
+		{
+			name:          "malformed image",
+			stripMetadata: true,
+			file:          malformedJPEG,
+			want:          handlertest.Expect{Status: http.StatusBadRequest, Code: "INVALID_FILE", Message: "avatar is not a valid image"},
+		},
+	}
 
-		// Assert response
-		assert.Equal(t, http.StatusBadRequest, w.Code)
-		assert.Contains(t, w.Body.String(), "INVALID_FILE")
-	})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewMockUserRepository(gomock.NewController(t))
+			if tt.wantStored != "" {
+				repo.EXPECT().FindByID(gomock.Any(), "test-user-id").Return(factories.User(), nil)
+				repo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+			}
+			cfg := testUploadConfig
+			cfg.Avatar.StripMetadata = tt.stripMetadata
+			storage := &objectStorage{objects: map[string][]byte{}}
+			handler := NewUserHandler(usecase.NewUserService(repo), buckets.Clients{buckets.Avatars: storage}, uploads.NopScanner{}, &recordingScheduler{}, cfg, logx.NewNoopLogger())
 
-	t.Run("missing user id returns bad request", func(t *testing.T) {
-		router := gin.New()
+			body, contentType := avatarFile("image/jpeg", tt.file)()
+			handlertest.Call(t, handler.UploadAvatar, handlertest.Request{
+				Method:      http.MethodPost,
+				Path:        "/users/test-user-id/avatar",
+				Params:      gin.Params{{Key: "id", Value: "test-user-id"}},
+				Body:        body,
+				ContentType: contentType,
+			}).Assert(t, tt.want)
 
-		// Add a handler that checks for user ID
-		router.POST("/users/:id/avatar", func(c *gin.Context) {
-			userID := c.Param("id")
-			if userID == "" {
-				c.JSON(http.StatusBadRequest, gin.H{
-					"error":   "MISSING_PARAMETER",
-					"message": "user id is required",
-				})
+			if tt.wantStored == "" {
+				assert.Empty(t, storage.keys())
 				return
 			}
-			c.JSON(http.StatusOK, gin.H{"user_id": userID})
+			if keys := storage.keys(); assert.Len(t, keys, 1) {
+				assert.Equal(t, tt.wantStored, string(storage.objects[keys[0]]))
+			}
 		})
+	}
+}
 
-		// Create request
-		req := httptest.NewRequest("POST", "/users//avatar", strings.NewReader(""))
-		w := httptest.NewRecorder()
+// verdictScanner returns err for every file, after reading it
+type verdictScanner struct {
+	err error
+}
 
-		// Perform request
-		router.ServeHTTP(w, req)
+func (s *verdictScanner) Scan(ctx context.Context, r io.Reader) error {
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return err
+	}
+	return s.err
+}
+
+func TestUserHandler_UploadAvatar_Scan(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name string
+		err  error
+		want handlertest.Expect
+	}{
+		{
+			name: "flagged",
+			err:  fmt.Errorf("%w: Eicar-Signature", uploads.ErrInfected),
+			want: handlertest.Expect{Status: http.StatusUnprocessableEntity, Code: "FILE_INFECTED", Message: "file was flagged by the malware scanner"},
+		},
+		{
+			name: "scanner unavailable",
+			err:  errors.New("clamd: connection refused"),
+			want: handlertest.Expect{Status: http.StatusServiceUnavailable, Code: "SCAN_UNAVAILABLE"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewMockUserRepository(gomock.NewController(t))
+			storage := &objectStorage{objects: map[string][]byte{}}
+			thumbnails := &recordingScheduler{}
+			handler := NewUserHandler(usecase.NewUserService(repo), buckets.Clients{buckets.Avatars: storage}, &verdictScanner{err: tt.err}, thumbnails, testUploadConfig, logx.NewNoopLogger())
+
+			body, contentType := avatarFile("image/jpeg", strippedJPEG)()
+			handlertest.Call(t, handler.UploadAvatar, handlertest.Request{
+				Method:      http.MethodPost,
+				Path:        "/users/test-user-id/avatar",
+				Params:      gin.Params{{Key: "id", Value: "test-user-id"}},
+				Body:        body,
+				ContentType: contentType,
+			}).Assert(t, tt.want)
+
+			assert.Empty(t, storage.keys())
+			assert.Empty(t, thumbnails.keys)
+		})
+	}
+}
+
+func TestUserHandler_UploadAvatar_SameFile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := mocks.NewMockUserRepository(gomock.NewController(t))
+	storage := &objectStorage{objects: map[string][]byte{}}
+	handler := NewUserHandler(usecase.NewUserService(repo), buckets.Clients{buckets.Avatars: storage}, uploads.NopScanner{}, &recordingScheduler{}, testUploadConfig, logx.NewNoopLogger())
+
+	// Two users upload the same photo
+	var keys, hashes []string
+	for _, id := range []string{"ada", "grace"} {
+		repo.EXPECT().FindByID(gomock.Any(), id).Return(factories.User(func(u *domain.User) { u.ID = id }), nil)
+		repo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, u *domain.User) error {
+			keys, hashes = append(keys, u.AvatarURL), append(hashes, u.AvatarHash)
+			return nil
+		})
 
-		// Assert response: handler should report missing parameter
-		assert.Equal(t, http.StatusBadRequest, w.Code)
-	})
+		body, contentType := avatarFile("image/jpeg", strippedJPEG)()
+		handlertest.Call(t, handler.UploadAvatar, handlertest.Request{
+			Method:      http.MethodPost,
+			Path:        "/users/" + id + "/avatar",
+			Params:      gin.Params{{Key: "id", Value: id}},
+			Body:        body,
+			ContentType: contentType,
+		}).Assert(t, handlertest.Expect{Status: http.StatusOK})
+	}
+
+	// They share one object, whose key and hash the users record
+	key, hash := uploads.ContentKey("avatars/", []byte(strippedJPEG), "image/jpeg")
+	assert.Equal(t, []string{key, key}, keys)
+	assert.Equal(t, []string{hash, hash}, hashes)
+	assert.Equal(t, []string{key}, storage.keys())
+}
+
+// A JPEG with an EXIF block holding a position, the same JPEG once stripped,
+// and one whose EXIF block runs past the end of the file
+const (
+	exifJPEG      = "\xFF\xD8" + "\xFF\xE1\x00\x11Exif\x00\x00GPS 51.5N" + "\xFF\xDA\x00\x02x\xFF\xD9"
+	strippedJPEG  = "\xFF\xD8" + "\xFF\xDA\x00\x02x\xFF\xD9"
+	malformedJPEG = "\xFF\xD8" + "\xFF\xE1\x00\x40Exif"
+)
+
+// recordingScheduler records the avatars it is asked to make thumbnails of,
+// and fails with err
+type recordingScheduler struct {
+	keys []string
+	err  error
+}
+
+func (s *recordingScheduler) Schedule(key string) error {
+	s.keys = append(s.keys, key)
+	return s.err
+}
+
+// avatarForm returns a multipart form with a small file of contentType as the
+// avatar field
+func avatarForm(contentType string) func() (io.Reader, string) {
+	return avatarFile(contentType, "\x89PNG\r\n\x1a\n")
+}
+
+// avatarFile returns a multipart form with data, of contentType, as the
+// avatar field
+func avatarFile(contentType, data string) func() (io.Reader, string) {
+	return func() (io.Reader, string) {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, _ := form.CreatePart(textproto.MIMEHeader{
+			"Content-Disposition": {`form-data; name="avatar"; filename="me.png"`},
+			"Content-Type":        {contentType},
+		})
+		_, _ = part.Write([]byte(data))
+		_ = form.Close()
+		return &body, form.FormDataContentType()
+	}
 }
@@ -0,0 +1,29 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gostratum/examples/orderservice/configs"
+	"github.com/gostratum/examples/orderservice/internal/buildinfo"
+)
+
+// serviceName names orderservice in /version
+const serviceName = "orderservice"
+
+// RegisterVersionRoute registers GET /version, which reports the service,
+// the configuration profile it runs with and its build. This function is
+// designed to be used with fx.Invoke.
+func RegisterVersionRoute(e *gin.Engine, cfg *configs.Config) {
+	build := buildinfo.Get()
+	e.GET("/version", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"service": serviceName,
+			"profile": cfg.App.Env,
+			"version": build.Version,
+			"commit":  build.Commit,
+			"date":    build.Date,
+		})
+	})
+}
@@ -0,0 +1,25 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gostratum/examples/orderservice/configs"
+)
+
+func TestRegisterVersionRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	e := gin.New()
+	RegisterVersionRoute(e, &configs.Config{App: configs.App{Env: "staging"}})
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	// Test binaries are built without ldflags or VCS information
+	assert.JSONEq(t, `{"service":"orderservice","profile":"staging","version":"dev","commit":"unknown","date":"unknown"}`, w.Body.String())
+}
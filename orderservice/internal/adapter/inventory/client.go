@@ -0,0 +1,162 @@
+// Package inventory implements usecase.InventoryClient against inventoryservice's HTTP API
+package inventory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/orderservice/internal/domain"
+	"github.com/gostratum/examples/orderservice/internal/requestid"
+	"github.com/gostratum/examples/orderservice/internal/usecase"
+)
+
+// Config locates inventoryservice
+type Config struct {
+	// BaseURL of inventoryservice; reservations are skipped when empty
+	BaseURL string        `mapstructure:"base_url"`
+	Timeout time.Duration `mapstructure:"timeout" default:"2s"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "inventory"
+}
+
+// NewClient creates the inventory client from the inventory config section.
+// Without a base_url it returns Disabled, so orderservice also runs on its own.
+// Its calls send the X-Request-ID of the request they are made for.
+func NewClient(loader configx.Loader) (usecase.InventoryClient, error) {
+	var cfg Config
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load inventory config: %w", err)
+	}
+	if cfg.BaseURL == "" {
+		return Disabled{}, nil
+	}
+	return newHTTPClient(cfg.BaseURL, &http.Client{Timeout: cfg.Timeout, Transport: requestid.Transport(nil)}), nil
+}
+
+// Disabled is an InventoryClient that reserves nothing
+type Disabled struct{}
+
+// Reserve implements usecase.InventoryClient
+func (Disabled) Reserve(ctx context.Context, orderID string, items []domain.Item) (string, error) {
+	return "", nil
+}
+
+// Release implements usecase.InventoryClient
+func (Disabled) Release(ctx context.Context, reservationID string) error {
+	return nil
+}
+
+// HTTPClient calls inventoryservice's reservation endpoints
+type HTTPClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+func newHTTPClient(baseURL string, client *http.Client) *HTTPClient {
+	return &HTTPClient{baseURL: strings.TrimSuffix(baseURL, "/"), http: client}
+}
+
+type reservationItem struct {
+	SKU string `json:"sku"`
+	Qty int    `json:"qty"`
+}
+
+type reservationRequest struct {
+	OrderID string            `json:"order_id"`
+	Items   []reservationItem `json:"items"`
+}
+
+type reservationResponse struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// Reserve implements usecase.InventoryClient via POST /reservations
+func (c *HTTPClient) Reserve(ctx context.Context, orderID string, items []domain.Item) (string, error) {
+	req := reservationRequest{OrderID: orderID, Items: make([]reservationItem, len(items))}
+	for i, item := range items {
+		req.Items[i] = reservationItem{SKU: item.SKU, Qty: item.Qty}
+	}
+
+	var envelope responsex.Envelope[reservationResponse]
+	status, err := c.do(ctx, http.MethodPost, "/reservations", req, &envelope)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case status == http.StatusCreated || status == http.StatusOK:
+		return envelope.Data.ID, nil
+	case status == http.StatusConflict:
+		return "", fmt.Errorf("%w: %s", usecase.ErrOutOfStock, errorMessage(envelope))
+	case status == http.StatusNotFound || status == http.StatusBadRequest:
+		// Unknown SKUs and malformed items are problems with the order itself
+		return "", fmt.Errorf("%w: %s", usecase.ErrInvalid, errorMessage(envelope))
+	default:
+		return "", fmt.Errorf("%w: inventory returned status %d", usecase.ErrUnavailable, status)
+	}
+}
+
+// Release implements usecase.InventoryClient via DELETE /reservations/:id.
+// Releasing a reservation that is already released or gone is not an error.
+func (c *HTTPClient) Release(ctx context.Context, reservationID string) error {
+	var envelope responsex.Envelope[reservationResponse]
+	status, err := c.do(ctx, http.MethodDelete, "/reservations/"+url.PathEscape(reservationID), nil, &envelope)
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case http.StatusOK, http.StatusConflict, http.StatusNotFound:
+		return nil
+	default:
+		return fmt.Errorf("%w: inventory returned status %d", usecase.ErrUnavailable, status)
+	}
+}
+
+// do sends a JSON request and decodes the responsex envelope into out
+func (c *HTTPClient) do(ctx context.Context, method, path string, body, out any) (int, error) {
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			return 0, fmt.Errorf("failed to encode inventory request: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, &payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create inventory request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", usecase.ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	// Gateways in front of inventoryservice may answer 5xx without an envelope
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && resp.StatusCode < 500 {
+		return 0, fmt.Errorf("%w: invalid inventory response: %v", usecase.ErrUnavailable, err)
+	}
+	return resp.StatusCode, nil
+}
+
+func errorMessage(envelope responsex.Envelope[reservationResponse]) string {
+	if envelope.Error == nil {
+		return "inventory rejected the reservation"
+	}
+	return envelope.Error.Message
+}
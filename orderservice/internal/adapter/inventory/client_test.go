@@ -0,0 +1,99 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/orderservice/internal/domain"
+	"github.com/gostratum/examples/orderservice/internal/usecase"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *HTTPClient {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return newHTTPClient(srv.URL+"/", srv.Client())
+}
+
+func writeEnvelope(w http.ResponseWriter, status int, envelope map[string]any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(envelope)
+}
+
+func TestHTTPClient_Reserve(t *testing.T) {
+	items := []domain.Item{{SKU: "SKU1", Qty: 2, Price: 10}}
+
+	t.Run("sends the order and returns the reservation id", func(t *testing.T) {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/reservations", r.URL.Path)
+
+			var req reservationRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "order1", req.OrderID)
+			assert.Equal(t, []reservationItem{{SKU: "SKU1", Qty: 2}}, req.Items)
+
+			writeEnvelope(w, http.StatusCreated, map[string]any{
+				"ok":   true,
+				"data": map[string]any{"id": "res1", "status": "reserved"},
+			})
+		})
+
+		id, err := client.Reserve(context.Background(), "order1", items)
+		require.NoError(t, err)
+		assert.Equal(t, "res1", id)
+	})
+
+	tests := []struct {
+		name    string
+		status  int
+		wantErr error
+	}{
+		{name: "conflict is out of stock", status: http.StatusConflict, wantErr: usecase.ErrOutOfStock},
+		{name: "unknown sku is invalid", status: http.StatusNotFound, wantErr: usecase.ErrInvalid},
+		{name: "server error is unavailable", status: http.StatusServiceUnavailable, wantErr: usecase.ErrUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				writeEnvelope(w, tt.status, map[string]any{
+					"ok":    false,
+					"error": map[string]any{"code": "OUT_OF_STOCK", "message": "insufficient stock: sku SKU1"},
+				})
+			})
+
+			_, err := client.Reserve(context.Background(), "order1", items)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("unreachable inventory is unavailable", func(t *testing.T) {
+		client := newHTTPClient("http://127.0.0.1:1", http.DefaultClient)
+		_, err := client.Reserve(context.Background(), "order1", items)
+		assert.ErrorIs(t, err, usecase.ErrUnavailable)
+	})
+}
+
+func TestHTTPClient_Release(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusConflict, http.StatusNotFound} {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodDelete, r.Method)
+			assert.Equal(t, "/reservations/res1", r.URL.Path)
+			writeEnvelope(w, status, map[string]any{"ok": status == http.StatusOK})
+		})
+		assert.NoError(t, client.Release(context.Background(), "res1"), "status %d", status)
+	}
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	assert.ErrorIs(t, client.Release(context.Background(), "res1"), usecase.ErrUnavailable)
+}
@@ -0,0 +1,256 @@
+// Package localstorage keeps objects in a directory on the local disk, for
+// running the service without S3 or MinIO credentials. The API serves the
+// directory at /uploads, so an avatar stored at avatars/<sha256>.png is at
+// /uploads/avatars/<sha256>.png.
+package localstorage
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/gostratum/storagex"
+	s3Adapter "github.com/gostratum/storagex/adapters/s3"
+	"go.uber.org/fx"
+
+	"github.com/gostratum/examples/orderservice/internal/buckets"
+)
+
+// Dir is the directory objects are kept in, relative to the working directory
+const Dir = "./uploads"
+
+// BucketsDir holds a directory for each bucket other than storagex.bucket
+// that a storage client names. Only Dir is served at /uploads.
+const BucketsDir = "./buckets"
+
+// tmpSuffix marks files still being written, which List leaves out
+const tmpSuffix = ".tmp-"
+
+// Module provides storagex.Storage: the local disk when local is true, and
+// otherwise storagex with the S3 adapter. The local disk also provides a
+// buckets.Opener, so storage clients may name buckets of their own.
+func Module(local bool) fx.Option {
+	if !local {
+		return fx.Options(
+			storagex.Module(),
+			s3Adapter.Module(),
+		)
+	}
+	return fx.Module("localstorage",
+		fx.Provide(func(lc fx.Lifecycle) (storagex.Storage, error) {
+			s, err := New(Dir)
+			if err != nil {
+				return nil, err
+			}
+			lc.Append(fx.StopHook(s.Close))
+			return s, nil
+		}),
+		fx.Provide(func(lc fx.Lifecycle) buckets.Opener {
+			return func(bucket string) (storagex.Storage, error) {
+				s, err := OpenBucket(BucketsDir, bucket)
+				if err != nil {
+					return nil, err
+				}
+				lc.Append(fx.StopHook(s.Close))
+				return s, nil
+			}
+		}),
+	)
+}
+
+// OpenBucket returns the storage over the directory of bucket in dir
+func OpenBucket(dir, bucket string) (*Storage, error) {
+	if bucket == "" || bucket == "." || !fs.ValidPath(bucket) || strings.Contains(bucket, "/") {
+		return nil, fmt.Errorf("invalid bucket name %q", bucket)
+	}
+	return New(path.Join(dir, bucket))
+}
+
+// Storage is a storagex.Storage over a directory, with keys as paths below
+// it. Content types follow the extension, ETags come with Put only, and
+// metadata is not kept. Only the methods orderservice calls are implemented;
+// the others, such as presigning, are left to S3 and panic here.
+type Storage struct {
+	storagex.Storage
+	root *os.Root
+
+	// mu makes checking for an existing file and renaming over it one step
+	mu sync.Mutex
+}
+
+// New creates the directory if needed and returns the storage over it
+func New(dir string) (*Storage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage directory: %w", err)
+	}
+	return &Storage{root: root}, nil
+}
+
+// checkKey refuses keys that are not a slash-separated path below the
+// directory. os.Root would refuse to leave it anyway.
+func checkKey(key string) error {
+	if !fs.ValidPath(key) || key == "." {
+		return fmt.Errorf("invalid key %q", key)
+	}
+	return nil
+}
+
+// Put implements storagex.Storage. The content goes to a temporary file that
+// is renamed over the key, so readers see the old file or the new one whole.
+func (s *Storage) Put(ctx context.Context, key string, r io.Reader, opts *storagex.PutOptions) (storagex.Stat, error) {
+	if err := checkKey(key); err != nil {
+		return storagex.Stat{}, err
+	}
+	if opts == nil {
+		opts = &storagex.PutOptions{}
+	}
+	if err := s.root.MkdirAll(path.Dir(key), 0o755); err != nil {
+		return storagex.Stat{}, err
+	}
+
+	tmp := key + tmpSuffix + uuid.NewString()
+	f, err := s.root.Create(tmp)
+	if err != nil {
+		return storagex.Stat{}, err
+	}
+	sum := md5.New()
+	_, err = io.Copy(io.MultiWriter(f, sum), r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		s.root.Remove(tmp)
+		return storagex.Stat{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !opts.Overwrite {
+		if _, err := s.root.Stat(key); err == nil {
+			s.root.Remove(tmp)
+			return storagex.Stat{}, storagex.ErrAlreadyExists
+		}
+	}
+	if err := s.root.Rename(tmp, key); err != nil {
+		s.root.Remove(tmp)
+		return storagex.Stat{}, err
+	}
+
+	stat, err := s.stat(key)
+	if err != nil {
+		return storagex.Stat{}, err
+	}
+	stat.ETag = `"` + hex.EncodeToString(sum.Sum(nil)) + `"`
+	if opts.ContentType != "" {
+		stat.ContentType = opts.ContentType
+	}
+	return stat, nil
+}
+
+// Get implements storagex.Storage
+func (s *Storage) Get(ctx context.Context, key string) (io.ReadCloser, storagex.Stat, error) {
+	if err := checkKey(key); err != nil {
+		return nil, storagex.Stat{}, err
+	}
+	f, err := s.root.Open(key)
+	if err != nil {
+		return nil, storagex.Stat{}, notFound(err)
+	}
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		f.Close()
+		return nil, storagex.Stat{}, storagex.ErrNotFound
+	}
+	return f, statOf(key, info), nil
+}
+
+// Head implements storagex.Storage
+func (s *Storage) Head(ctx context.Context, key string) (storagex.Stat, error) {
+	if err := checkKey(key); err != nil {
+		return storagex.Stat{}, err
+	}
+	return s.stat(key)
+}
+
+// List implements storagex.Storage; every key under the prefix comes in one
+// page, sorted, and the delimiter is ignored
+func (s *Storage) List(ctx context.Context, opts storagex.ListOptions) (storagex.ListPage, error) {
+	var page storagex.ListPage
+	err := fs.WalkDir(s.root.FS(), ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.Contains(path.Base(p), tmpSuffix) || !strings.HasPrefix(p, opts.Prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		page.Keys = append(page.Keys, statOf(p, info))
+		return nil
+	})
+	if err != nil {
+		return storagex.ListPage{}, err
+	}
+	slices.SortFunc(page.Keys, func(a, b storagex.Stat) int { return strings.Compare(a.Key, b.Key) })
+	return page, nil
+}
+
+// Delete implements storagex.Storage
+func (s *Storage) Delete(ctx context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return notFound(s.root.Remove(key))
+}
+
+// Close releases the directory
+func (s *Storage) Close() error {
+	return s.root.Close()
+}
+
+func (s *Storage) stat(key string) (storagex.Stat, error) {
+	info, err := s.root.Stat(key)
+	if err != nil {
+		return storagex.Stat{}, notFound(err)
+	}
+	if info.IsDir() {
+		return storagex.Stat{}, storagex.ErrNotFound
+	}
+	return statOf(key, info), nil
+}
+
+func statOf(key string, info fs.FileInfo) storagex.Stat {
+	return storagex.Stat{
+		Key:          key,
+		Size:         info.Size(),
+		ContentType:  mime.TypeByExtension(path.Ext(key)),
+		LastModified: info.ModTime(),
+	}
+}
+
+// notFound turns a missing file into storagex.ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return storagex.ErrNotFound
+	}
+	return err
+}
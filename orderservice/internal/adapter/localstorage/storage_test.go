@@ -0,0 +1,151 @@
+package localstorage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gostratum/storagex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStorage(t *testing.T) (*Storage, string) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "uploads")
+	s, err := New(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	return s, dir
+}
+
+func put(t *testing.T, s *Storage, key, content string, overwrite bool) (storagex.Stat, error) {
+	t.Helper()
+	return s.Put(context.Background(), key, strings.NewReader(content), &storagex.PutOptions{
+		ContentType: "image/png",
+		Overwrite:   overwrite,
+	})
+}
+
+func TestStorage_PutGetHead(t *testing.T) {
+	s, dir := newTestStorage(t)
+	ctx := context.Background()
+
+	stat, err := put(t, s, "avatars/u1_1700000000.png", "first", true)
+	require.NoError(t, err)
+	assert.Equal(t, "avatars/u1_1700000000.png", stat.Key)
+	assert.EqualValues(t, 5, stat.Size)
+	assert.Equal(t, "image/png", stat.ContentType)
+	assert.NotEmpty(t, stat.ETag)
+
+	// The file is where /uploads serves it from
+	data, err := os.ReadFile(filepath.Join(dir, "avatars", "u1_1700000000.png"))
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(data))
+
+	_, err = put(t, s, "avatars/u1_1700000000.png", "second", true)
+	require.NoError(t, err)
+	body, stat, err := s.Get(ctx, "avatars/u1_1700000000.png")
+	require.NoError(t, err)
+	data, err = io.ReadAll(body)
+	body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+	assert.EqualValues(t, 6, stat.Size)
+
+	head, err := s.Head(ctx, "avatars/u1_1700000000.png")
+	require.NoError(t, err)
+	assert.EqualValues(t, 6, head.Size)
+	assert.Equal(t, "image/png", head.ContentType, "content type from the extension")
+}
+
+func TestStorage_NoOverwrite(t *testing.T) {
+	s, _ := newTestStorage(t)
+
+	_, err := put(t, s, "avatars/a.png", "first", false)
+	require.NoError(t, err)
+	_, err = put(t, s, "avatars/a.png", "second", false)
+	assert.ErrorIs(t, err, storagex.ErrAlreadyExists)
+
+	body, _, err := s.Get(context.Background(), "avatars/a.png")
+	require.NoError(t, err)
+	defer body.Close()
+	data, _ := io.ReadAll(body)
+	assert.Equal(t, "first", string(data))
+}
+
+func TestStorage_NotFound(t *testing.T) {
+	s, _ := newTestStorage(t)
+	ctx := context.Background()
+	_, err := put(t, s, "avatars/a.png", "a", true)
+	require.NoError(t, err)
+
+	_, _, err = s.Get(ctx, "avatars/missing.png")
+	assert.ErrorIs(t, err, storagex.ErrNotFound)
+	_, err = s.Head(ctx, "avatars/missing.png")
+	assert.ErrorIs(t, err, storagex.ErrNotFound)
+	assert.ErrorIs(t, s.Delete(ctx, "avatars/missing.png"), storagex.ErrNotFound)
+
+	// A directory is not an object
+	_, _, err = s.Get(ctx, "avatars")
+	assert.ErrorIs(t, err, storagex.ErrNotFound)
+	_, err = s.Head(ctx, "avatars")
+	assert.ErrorIs(t, err, storagex.ErrNotFound)
+}
+
+func TestStorage_ListAndDelete(t *testing.T) {
+	s, _ := newTestStorage(t)
+	ctx := context.Background()
+	for _, key := range []string{"avatars/b.png", "avatars/a.png", "thumbnails/small/a.png"} {
+		_, err := put(t, s, key, key, true)
+		require.NoError(t, err)
+	}
+
+	page, err := s.List(ctx, storagex.ListOptions{Prefix: "avatars/"})
+	require.NoError(t, err)
+	keys := []string{}
+	for _, stat := range page.Keys {
+		keys = append(keys, stat.Key)
+	}
+	assert.Equal(t, []string{"avatars/a.png", "avatars/b.png"}, keys)
+
+	require.NoError(t, s.Delete(ctx, "avatars/a.png"))
+	page, err = s.List(ctx, storagex.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, page.Keys, 2)
+}
+
+func TestStorage_KeysStayInTheDirectory(t *testing.T) {
+	s, dir := newTestStorage(t)
+	ctx := context.Background()
+
+	for _, key := range []string{"../escape.png", "avatars/../../escape.png", "/etc/passwd", "", "."} {
+		_, err := put(t, s, key, "x", true)
+		assert.Error(t, err, "put %q", key)
+		_, _, err = s.Get(ctx, key)
+		assert.Error(t, err, "get %q", key)
+		assert.Error(t, s.Delete(ctx, key), "delete %q", key)
+	}
+	_, err := os.Stat(filepath.Join(filepath.Dir(dir), "escape.png"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestOpenBucket(t *testing.T) {
+	dir := t.TempDir()
+	s, err := OpenBucket(dir, "exports")
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+
+	_, err = put(t, s, "report.csv", "x", true)
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "exports", "report.csv"))
+	assert.NoError(t, err, "each bucket is a directory of its own")
+
+	for _, bucket := range []string{"", ".", "..", "a/b", "/etc"} {
+		_, err := OpenBucket(dir, bucket)
+		assert.ErrorContains(t, err, "invalid bucket name", "bucket %q", bucket)
+	}
+}
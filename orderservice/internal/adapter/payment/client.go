@@ -0,0 +1,215 @@
+// Package payment implements usecase.PaymentGateway against paymentservice's HTTP API
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/orderservice/internal/requestid"
+	"github.com/gostratum/examples/orderservice/internal/usecase"
+)
+
+// signatureHeader carries "t=<unix seconds>,v1=<hex hmac>" as paymentservice expects
+const signatureHeader = "X-Signature"
+
+// Config locates paymentservice
+type Config struct {
+	// BaseURL of paymentservice; orders are not charged when empty
+	BaseURL string `mapstructure:"base_url"`
+	// Secret signs requests; it must match paymentservice's signing.secret
+	Secret   string        `mapstructure:"secret"`
+	Timeout  time.Duration `mapstructure:"timeout" default:"2s"`
+	Currency string        `mapstructure:"currency" default:"USD"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "payment"
+}
+
+// NewClient creates the payment client from the payment config section.
+// Without a base_url it returns Disabled, so orderservice also runs on its own.
+// Its calls send the X-Request-ID of the request they are made for.
+func NewClient(loader configx.Loader) (usecase.PaymentGateway, error) {
+	var cfg Config
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load payment config: %w", err)
+	}
+	if cfg.BaseURL == "" {
+		return Disabled{}, nil
+	}
+	return newHTTPClient(cfg.BaseURL, cfg.Secret, cfg.Currency, &http.Client{Timeout: cfg.Timeout, Transport: requestid.Transport(nil)}), nil
+}
+
+// Disabled is a PaymentGateway that charges nothing
+type Disabled struct{}
+
+// Charge implements usecase.PaymentGateway
+func (Disabled) Charge(ctx context.Context, orderID string, amount float64) (string, error) {
+	return "", nil
+}
+
+// FindCharge implements usecase.PaymentGateway
+func (Disabled) FindCharge(ctx context.Context, orderID string) (string, error) {
+	return "", nil
+}
+
+// Refund implements usecase.PaymentGateway
+func (Disabled) Refund(ctx context.Context, chargeID string) error {
+	return nil
+}
+
+// HTTPClient calls paymentservice's charge endpoints with signed requests
+type HTTPClient struct {
+	baseURL  string
+	secret   []byte
+	currency string
+	http     *http.Client
+	now      func() time.Time
+}
+
+func newHTTPClient(baseURL, secret, currency string, client *http.Client) *HTTPClient {
+	return &HTTPClient{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		secret:   []byte(secret),
+		currency: currency,
+		http:     client,
+		now:      time.Now,
+	}
+}
+
+type chargeRequest struct {
+	OrderID  string `json:"order_id"`
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+type chargeResponse struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// Charge implements usecase.PaymentGateway via POST /charges.
+// The order total is sent in minor units.
+func (c *HTTPClient) Charge(ctx context.Context, orderID string, amount float64) (string, error) {
+	req := chargeRequest{
+		OrderID:  orderID,
+		Amount:   int64(math.Round(amount * 100)),
+		Currency: c.currency,
+	}
+
+	var envelope responsex.Envelope[chargeResponse]
+	status, err := c.do(ctx, http.MethodPost, "/charges", req, &envelope)
+	if err != nil {
+		return "", err
+	}
+
+	switch status {
+	case http.StatusCreated, http.StatusOK:
+		return envelope.Data.ID, nil
+	case http.StatusPaymentRequired:
+		return "", fmt.Errorf("%w: %s", usecase.ErrPaymentDeclined, errorMessage(envelope))
+	case http.StatusBadRequest:
+		return "", fmt.Errorf("%w: %s", usecase.ErrInvalid, errorMessage(envelope))
+	case http.StatusUnauthorized:
+		// A signature mismatch is a deployment problem, not something the caller can fix
+		return "", fmt.Errorf("%w: payment request signature rejected: %s", usecase.ErrUnavailable, errorMessage(envelope))
+	default:
+		return "", fmt.Errorf("%w: payment returned status %d", usecase.ErrUnavailable, status)
+	}
+}
+
+// FindCharge implements usecase.PaymentGateway via GET /charges?order_id=
+func (c *HTTPClient) FindCharge(ctx context.Context, orderID string) (string, error) {
+	var envelope responsex.Envelope[chargeResponse]
+	status, err := c.do(ctx, http.MethodGet, "/charges?order_id="+url.QueryEscape(orderID), nil, &envelope)
+	if err != nil {
+		return "", err
+	}
+
+	switch status {
+	case http.StatusOK:
+		return envelope.Data.ID, nil
+	case http.StatusNotFound:
+		return "", nil
+	default:
+		return "", fmt.Errorf("%w: payment returned status %d", usecase.ErrUnavailable, status)
+	}
+}
+
+// Refund implements usecase.PaymentGateway via POST /charges/:id/refunds.
+// Refunding a charge that is already fully refunded or unknown is not an error.
+func (c *HTTPClient) Refund(ctx context.Context, chargeID string) error {
+	var envelope responsex.Envelope[chargeResponse]
+	status, err := c.do(ctx, http.MethodPost, "/charges/"+url.PathEscape(chargeID)+"/refunds", nil, &envelope)
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case http.StatusCreated, http.StatusOK, http.StatusConflict, http.StatusNotFound:
+		return nil
+	default:
+		return fmt.Errorf("%w: payment returned status %d", usecase.ErrUnavailable, status)
+	}
+}
+
+// do sends a signed JSON request and decodes the responsex envelope into out
+func (c *HTTPClient) do(ctx context.Context, method, path string, body, out any) (int, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return 0, fmt.Errorf("failed to encode payment request: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create payment request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(signatureHeader, c.sign(payload))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", usecase.ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	// Gateways in front of paymentservice may answer 5xx without an envelope
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && resp.StatusCode < 500 {
+		return 0, fmt.Errorf("%w: invalid payment response: %v", usecase.ErrUnavailable, err)
+	}
+	return resp.StatusCode, nil
+}
+
+// sign returns the X-Signature value: an HMAC-SHA256 over "<timestamp>.<body>"
+func (c *HTTPClient) sign(body []byte) string {
+	ts := strconv.FormatInt(c.now().Unix(), 10)
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func errorMessage(envelope responsex.Envelope[chargeResponse]) string {
+	if envelope.Error == nil {
+		return "payment rejected the request"
+	}
+	return envelope.Error.Message
+}
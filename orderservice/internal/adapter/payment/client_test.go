@@ -0,0 +1,171 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/orderservice/internal/usecase"
+)
+
+const testSecret = "test-secret"
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *HTTPClient {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	client := newHTTPClient(srv.URL+"/", testSecret, "USD", srv.Client())
+	client.now = func() time.Time { return time.Unix(1700000000, 0) }
+	return client
+}
+
+func writeEnvelope(w http.ResponseWriter, status int, envelope map[string]any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(envelope)
+}
+
+// verifySignature recomputes the signature the way paymentservice does
+func verifySignature(t *testing.T, r *http.Request) []byte {
+	t.Helper()
+	body, err := io.ReadAll(r.Body)
+	require.NoError(t, err)
+
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte("1700000000."))
+	mac.Write(body)
+	assert.Equal(t, "t=1700000000,v1="+hex.EncodeToString(mac.Sum(nil)), r.Header.Get(signatureHeader))
+	return body
+}
+
+func TestHTTPClient_Charge(t *testing.T) {
+	t.Run("sends a signed charge in minor units", func(t *testing.T) {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/charges", r.URL.Path)
+
+			var req chargeRequest
+			require.NoError(t, json.Unmarshal(verifySignature(t, r), &req))
+			assert.Equal(t, chargeRequest{OrderID: "order1", Amount: 3510, Currency: "USD"}, req)
+
+			writeEnvelope(w, http.StatusCreated, map[string]any{
+				"ok":   true,
+				"data": map[string]any{"id": "ch1", "status": "succeeded"},
+			})
+		})
+
+		// 35.1 is not exactly representable; rounding keeps it at 3510 cents
+		id, err := client.Charge(context.Background(), "order1", 35.1)
+		require.NoError(t, err)
+		assert.Equal(t, "ch1", id)
+	})
+
+	tests := []struct {
+		name    string
+		status  int
+		wantErr error
+	}{
+		{name: "payment required is declined", status: http.StatusPaymentRequired, wantErr: usecase.ErrPaymentDeclined},
+		{name: "bad request is invalid", status: http.StatusBadRequest, wantErr: usecase.ErrInvalid},
+		{name: "bad signature is unavailable", status: http.StatusUnauthorized, wantErr: usecase.ErrUnavailable},
+		{name: "server error is unavailable", status: http.StatusServiceUnavailable, wantErr: usecase.ErrUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				writeEnvelope(w, tt.status, map[string]any{
+					"ok":    false,
+					"error": map[string]any{"code": "X", "message": "amount exceeds the card limit"},
+				})
+			})
+
+			_, err := client.Charge(context.Background(), "order1", 10)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("unreachable service is unavailable", func(t *testing.T) {
+		client := newHTTPClient("http://127.0.0.1:1", testSecret, "USD", &http.Client{Timeout: time.Second})
+		_, err := client.Charge(context.Background(), "order1", 10)
+		assert.ErrorIs(t, err, usecase.ErrUnavailable)
+	})
+}
+
+func TestHTTPClient_FindCharge(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantID  string
+		wantErr error
+	}{
+		{name: "charged order", status: http.StatusOK, wantID: "ch1"},
+		{name: "order that was not charged", status: http.StatusNotFound},
+		{name: "server error", status: http.StatusServiceUnavailable, wantErr: usecase.ErrUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodGet, r.Method)
+				assert.Equal(t, "/charges", r.URL.Path)
+				assert.Equal(t, "order1", r.URL.Query().Get("order_id"))
+				assert.Empty(t, verifySignature(t, r))
+				writeEnvelope(w, tt.status, map[string]any{
+					"ok":   tt.status == http.StatusOK,
+					"data": map[string]any{"id": tt.wantID, "status": "succeeded"},
+				})
+			})
+
+			id, err := client.FindCharge(context.Background(), "order1")
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantID, id)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHTTPClient_Refund(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr error
+	}{
+		{name: "created", status: http.StatusCreated},
+		{name: "already refunded", status: http.StatusConflict},
+		{name: "unknown charge", status: http.StatusNotFound},
+		{name: "server error", status: http.StatusInternalServerError, wantErr: usecase.ErrUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.True(t, strings.HasSuffix(r.URL.Path, "/charges/ch1/refunds"))
+				assert.Empty(t, verifySignature(t, r))
+				writeEnvelope(w, tt.status, map[string]any{"ok": tt.wantErr == nil})
+			})
+
+			err := client.Refund(context.Background(), "ch1")
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gostratum/examples/orderservice/internal/domain"
+	"github.com/gostratum/examples/orderservice/internal/usecase"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// AttachmentRepo implements the AttachmentRepository interface using GORM
+type AttachmentRepo struct {
+	db *gorm.DB
+}
+
+// NewAttachmentRepo creates a new GORM-based attachment repository
+func NewAttachmentRepo(db *gorm.DB) usecase.AttachmentRepository {
+	return &AttachmentRepo{db: db}
+}
+
+// Save stores an attachment in the database
+func (r *AttachmentRepo) Save(ctx context.Context, attachment *domain.Attachment) error {
+	var entity AttachmentEntity
+	entity.FromDomain(attachment)
+
+	if err := r.db.WithContext(ctx).Create(&entity).Error; err != nil {
+		// The order was deleted since it was looked up
+		if isForeignKeyViolation(err) {
+			return domain.ErrNotFound
+		}
+		// A file is stored at most once
+		if isUniqueViolation(err) {
+			return domain.ErrConflict
+		}
+		// Return raw error - use case layer will translate to ErrUnavailable
+		return err
+	}
+
+	// Update domain model with generated values
+	*attachment = *entity.ToDomain()
+	return nil
+}
+
+// FindByID retrieves an attachment by its ID
+func (r *AttachmentRepo) FindByID(ctx context.Context, id string) (*domain.Attachment, error) {
+	var entity AttachmentEntity
+
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&entity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		// Return raw error - use case layer will translate to ErrUnavailable
+		return nil, err
+	}
+
+	return entity.ToDomain(), nil
+}
+
+// ListByOrder retrieves the attachments of an order, oldest first
+func (r *AttachmentRepo) ListByOrder(ctx context.Context, orderID string) ([]*domain.Attachment, error) {
+	var entities []AttachmentEntity
+
+	err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Order("created_at, id").Find(&entities).Error
+	if err != nil {
+		// Return raw error - use case layer will translate to ErrUnavailable
+		return nil, err
+	}
+
+	attachments := make([]*domain.Attachment, len(entities))
+	for i := range entities {
+		attachments[i] = entities[i].ToDomain()
+	}
+	return attachments, nil
+}
+
+// isForeignKeyViolation reports whether err is a foreign key violation,
+// whether or not GORM's TranslateError turned it into gorm.ErrForeignKeyViolated
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.Is(err, gorm.ErrForeignKeyViolated) || (errors.As(err, &pgErr) && pgErr.Code == "23503")
+}
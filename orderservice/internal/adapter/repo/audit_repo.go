@@ -0,0 +1,77 @@
+package repo
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/gostratum/examples/orderservice/internal/audit"
+)
+
+// maxAuditEntries bounds the entries a List returns
+const maxAuditEntries = 500
+
+// AuditRepo implements audit.Store using GORM
+type AuditRepo struct {
+	db *gorm.DB
+}
+
+// NewAuditRepo creates a new GORM-based audit log
+func NewAuditRepo(db *gorm.DB) audit.Store {
+	return &AuditRepo{db: db}
+}
+
+// Insert adds entries to the audit log, all or none
+func (r *AuditRepo) Insert(ctx context.Context, entries []audit.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	entities := make([]AuditEntryEntity, len(entries))
+	for i, entry := range entries {
+		if err := entities[i].FromAudit(entry); err != nil {
+			return err
+		}
+	}
+	return r.db.WithContext(ctx).Create(&entities).Error
+}
+
+// List returns the entries of filter, newest first, at most
+// maxAuditEntries
+func (r *AuditRepo) List(ctx context.Context, filter audit.Filter) ([]audit.Entry, error) {
+	query := r.db.WithContext(ctx).Order("created_at DESC, id DESC")
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at < ?", filter.Until)
+	}
+	limit := filter.Limit
+	if limit <= 0 || limit > maxAuditEntries {
+		limit = maxAuditEntries
+	}
+
+	var entities []AuditEntryEntity
+	if err := query.Limit(limit).Find(&entities).Error; err != nil {
+		// Return raw error - the handler answers it as unavailable
+		return nil, err
+	}
+
+	entries := make([]audit.Entry, len(entities))
+	for i := range entities {
+		entry, err := entities[i].ToAudit()
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = entry
+	}
+	return entries, nil
+}
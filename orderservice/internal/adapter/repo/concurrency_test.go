@@ -0,0 +1,204 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/gostratum/examples/orderservice/internal/adapter/inventory"
+	"github.com/gostratum/examples/orderservice/internal/adapter/payment"
+	"github.com/gostratum/examples/orderservice/internal/domain"
+	"github.com/gostratum/examples/orderservice/internal/testutil/dbtest"
+	"github.com/gostratum/examples/orderservice/internal/testutil/factories"
+	"github.com/gostratum/examples/orderservice/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// These tests call the repositories from many goroutines at once, each on a
+// connection of its own, so they run on the shared database rather than in a
+// test transaction, which is a single connection. What they write is
+// committed; the factories make it unique to the test. Run them with -race
+// (make test-race) to have the race detector check the adapters too.
+
+// goroutines is how many calls each test makes at once
+const goroutines = 20
+
+// concurrently calls f(0) to f(n-1) from n goroutines released together,
+// and returns their errors by i
+func concurrently(n int, f func(i int) error) []error {
+	errs := make([]error, n)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() {
+			<-start
+			errs[i] = f(i)
+		})
+	}
+	close(start)
+	wg.Wait()
+	return errs
+}
+
+// succeeded counts the nil errors
+func succeeded(errs []error) int {
+	n := 0
+	for _, err := range errs {
+		if err == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// count returns the number of rows of model where query holds
+func count(t *testing.T, db *gorm.DB, model any, query string, args ...any) int64 {
+	t.Helper()
+	var n int64
+	require.NoError(t, db.Model(model).Where(query, args...).Count(&n).Error)
+	return n
+}
+
+func TestCreateOrder_Concurrent(t *testing.T) {
+	t.Parallel()
+	db := dbtest.Open(t)
+	ctx := context.Background()
+
+	user := factories.User(unsavedUser)
+	require.NoError(t, NewUserRepo(db).Save(ctx, user))
+	orderRepo := NewOrderRepo(db)
+	service := usecase.NewOrderService(orderRepo, inventory.Disabled{}, payment.Disabled{})
+
+	// Each goroutine orders different items, so every order can be checked
+	// against what it was created with
+	items := make([][]domain.Item, goroutines)
+	for i := range items {
+		items[i] = factories.Items(1 + i%3)
+	}
+	orders := make([]*domain.Order, goroutines)
+	errs := concurrently(goroutines, func(i int) error {
+		order, err := service.CreateOrder(ctx, user.ID, items[i])
+		orders[i] = order
+		return err
+	})
+
+	ids := map[string]bool{}
+	wantItems := 0
+	for i, err := range errs {
+		require.NoError(t, err, "order %d", i)
+		assert.False(t, ids[orders[i].ID], "order id %s given twice", orders[i].ID)
+		ids[orders[i].ID] = true
+		wantItems += len(items[i])
+
+		found, err := orderRepo.FindByID(ctx, orders[i].ID)
+		require.NoError(t, err)
+		assert.Len(t, found.Items, len(items[i]), "items of order %d", i)
+		assert.InDelta(t, orders[i].Total, found.Total, 1e-9, "total of order %d", i)
+	}
+
+	// Nothing was stored twice, or lost
+	assert.EqualValues(t, goroutines, count(t, db, &OrderEntity{}, "user_id = ?", user.ID))
+	assert.EqualValues(t, wantItems, count(t, db, &ItemEntity{},
+		"order_id IN (?)", db.Model(&OrderEntity{}).Select("id").Where("user_id = ?", user.ID)))
+}
+
+func TestOrderRepo_ConcurrentSavesOfOneOrder(t *testing.T) {
+	t.Parallel()
+	db := dbtest.Open(t)
+	ctx := context.Background()
+
+	user := factories.User(unsavedUser)
+	require.NoError(t, NewUserRepo(db).Save(ctx, user))
+	repo := NewOrderRepo(db)
+
+	// The same order, retried by several callers at once: one insert wins,
+	// and the transactions of the others roll back with their items
+	order := factories.Order(factories.ForUser(user), factories.WithItems(3))
+	errs := concurrently(goroutines, func(int) error {
+		retry := *order
+		retry.Items = append([]domain.Item(nil), order.Items...)
+		return repo.Save(ctx, &retry)
+	})
+
+	assert.Equal(t, 1, succeeded(errs), "saves that succeeded: %v", errs)
+	assert.EqualValues(t, 1, count(t, db, &OrderEntity{}, "id = ?", order.ID))
+	assert.EqualValues(t, 3, count(t, db, &ItemEntity{}, "order_id = ?", order.ID))
+}
+
+func TestUserRepo_ConcurrentSavesOfOneEmail(t *testing.T) {
+	t.Parallel()
+	db := dbtest.Open(t)
+	repo := NewUserRepo(db)
+
+	email := factories.User().Email
+	errs := concurrently(goroutines, func(int) error {
+		return repo.Save(context.Background(), factories.User(unsavedUser, func(u *domain.User) { u.Email = email }))
+	})
+
+	// The unique index admits one; the others are told the email is taken
+	assert.Equal(t, 1, succeeded(errs), "saves that succeeded: %v", errs)
+	for _, err := range errs {
+		if err != nil {
+			assert.ErrorIs(t, err, domain.ErrConflict)
+		}
+	}
+	assert.EqualValues(t, 1, count(t, db, &UserEntity{}, "email = ?", email))
+}
+
+func TestUpdateAvatar_Concurrent(t *testing.T) {
+	t.Parallel()
+	db := dbtest.Open(t)
+	ctx := context.Background()
+	repo := NewUserRepo(db)
+	service := usecase.NewUserService(repo)
+
+	t.Run("one user", func(t *testing.T) {
+		user := factories.User(unsavedUser)
+		require.NoError(t, repo.Save(ctx, user))
+
+		avatars := map[string]bool{}
+		for i := range goroutines {
+			avatars[fmt.Sprintf("avatars/%064x.png", i)] = true
+		}
+		errs := concurrently(goroutines, func(i int) error {
+			hash := fmt.Sprintf("%064x", i)
+			_, err := service.UpdateAvatar(ctx, user.ID, "avatars/"+hash+".png", hash)
+			return err
+		})
+		for _, err := range errs {
+			require.NoError(t, err)
+		}
+
+		// The last write wins, whole: one of the avatars, and the fields
+		// no update changed as they were
+		found, err := repo.FindByID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.True(t, avatars[found.AvatarURL], "avatar %q is none of those written", found.AvatarURL)
+		assert.Equal(t, "avatars/"+found.AvatarHash+".png", found.AvatarURL, "the hash is of another avatar")
+		assert.Equal(t, user.Name, found.Name)
+		assert.Equal(t, user.Email, found.Email)
+	})
+
+	t.Run("many users", func(t *testing.T) {
+		users := make([]*domain.User, goroutines)
+		for i := range users {
+			users[i] = factories.User(unsavedUser)
+			require.NoError(t, repo.Save(ctx, users[i]))
+		}
+		errs := concurrently(goroutines, func(i int) error {
+			_, err := service.UpdateAvatar(ctx, users[i].ID, "avatars/"+users[i].ID+".png", users[i].ID)
+			return err
+		})
+
+		// No update lands on another user's row
+		for i, user := range users {
+			require.NoError(t, errs[i])
+			found, err := repo.FindByID(ctx, user.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "avatars/"+user.ID+".png", found.AvatarURL)
+		}
+	})
+}
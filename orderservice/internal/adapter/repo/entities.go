@@ -1,20 +1,24 @@
 package repo
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/gostratum/examples/orderservice/internal/audit"
 	"github.com/gostratum/examples/orderservice/internal/domain"
 	"gorm.io/gorm"
 )
 
 // UserEntity represents the GORM model for user table
 type UserEntity struct {
-	ID        string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	Name      string    `gorm:"not null"`
-	Email     string    `gorm:"uniqueIndex;not null"`
-	AvatarURL string    `gorm:"type:text"`
-	CreatedAt time.Time `gorm:"autoCreateTime"`
+	ID         string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Name       string    `gorm:"not null"`
+	Email      string    `gorm:"uniqueIndex;not null"`
+	AvatarURL  string    `gorm:"type:text"`
+	AvatarHash string    `gorm:"type:varchar(64);index"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
 }
 
 // TableName specifies the table name for UserEntity
@@ -33,11 +37,12 @@ func (u *UserEntity) BeforeCreate(tx *gorm.DB) error {
 // ToDomain converts UserEntity to domain.User
 func (u *UserEntity) ToDomain() *domain.User {
 	return &domain.User{
-		ID:        u.ID,
-		Name:      u.Name,
-		Email:     u.Email,
-		AvatarURL: u.AvatarURL,
-		CreatedAt: u.CreatedAt,
+		ID:         u.ID,
+		Name:       u.Name,
+		Email:      u.Email,
+		AvatarURL:  u.AvatarURL,
+		AvatarHash: u.AvatarHash,
+		CreatedAt:  u.CreatedAt,
 	}
 }
 
@@ -47,6 +52,7 @@ func (u *UserEntity) FromDomain(user *domain.User) {
 	u.Name = user.Name
 	u.Email = user.Email
 	u.AvatarURL = user.AvatarURL
+	u.AvatarHash = user.AvatarHash
 	u.CreatedAt = user.CreatedAt
 }
 
@@ -138,3 +144,117 @@ func (o *OrderEntity) FromDomain(order *domain.Order) {
 	}
 	o.Items = items
 }
+
+// AttachmentEntity represents the GORM model for order_attachments table
+type AttachmentEntity struct {
+	ID          string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	OrderID     string    `gorm:"type:uuid;not null;index"`
+	StorageKey  string    `gorm:"type:text;uniqueIndex;not null"`
+	Filename    string    `gorm:"type:text;not null"`
+	ContentType string    `gorm:"not null"`
+	Size        int64     `gorm:"not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for AttachmentEntity
+func (AttachmentEntity) TableName() string {
+	return "order_attachments"
+}
+
+// BeforeCreate generates UUID for new attachments
+func (a *AttachmentEntity) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// ToDomain converts AttachmentEntity to domain.Attachment
+func (a *AttachmentEntity) ToDomain() *domain.Attachment {
+	return &domain.Attachment{
+		ID:          a.ID,
+		OrderID:     a.OrderID,
+		Key:         a.StorageKey,
+		Filename:    a.Filename,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		CreatedAt:   a.CreatedAt,
+	}
+}
+
+// FromDomain creates AttachmentEntity from domain.Attachment
+func (a *AttachmentEntity) FromDomain(attachment *domain.Attachment) {
+	a.ID = attachment.ID
+	a.OrderID = attachment.OrderID
+	a.StorageKey = attachment.Key
+	a.Filename = attachment.Filename
+	a.ContentType = attachment.ContentType
+	a.Size = attachment.Size
+	a.CreatedAt = attachment.CreatedAt
+}
+
+// AuditEntryEntity represents the GORM model for audit_log table
+type AuditEntryEntity struct {
+	ID         string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	CreatedAt  time.Time `gorm:"not null;index"`
+	Actor      string    `gorm:"type:text;not null"`
+	RequestID  string    `gorm:"type:text;not null"`
+	Method     string    `gorm:"not null"`
+	Route      string    `gorm:"type:text;not null"`
+	Status     int       `gorm:"not null"`
+	EntityType string    `gorm:"not null"`
+	EntityID   string    `gorm:"not null"`
+	Action     string    `gorm:"not null"`
+	// Changes is audit.Entry's, in JSON
+	Changes []byte `gorm:"type:jsonb"`
+}
+
+// TableName specifies the table name for AuditEntryEntity
+func (AuditEntryEntity) TableName() string {
+	return "audit_log"
+}
+
+// ToAudit converts AuditEntryEntity to audit.Entry
+func (a *AuditEntryEntity) ToAudit() (audit.Entry, error) {
+	entry := audit.Entry{
+		ID:         a.ID,
+		CreatedAt:  a.CreatedAt,
+		Actor:      a.Actor,
+		RequestID:  a.RequestID,
+		Method:     a.Method,
+		Route:      a.Route,
+		Status:     a.Status,
+		EntityType: a.EntityType,
+		EntityID:   a.EntityID,
+		Action:     a.Action,
+	}
+	if len(a.Changes) > 0 {
+		if err := json.Unmarshal(a.Changes, &entry.Changes); err != nil {
+			return audit.Entry{}, fmt.Errorf("invalid changes of audit entry %s: %w", a.ID, err)
+		}
+	}
+	return entry, nil
+}
+
+// FromAudit creates AuditEntryEntity from audit.Entry
+func (a *AuditEntryEntity) FromAudit(entry audit.Entry) error {
+	a.ID = entry.ID
+	a.CreatedAt = entry.CreatedAt
+	a.Actor = entry.Actor
+	a.RequestID = entry.RequestID
+	a.Method = entry.Method
+	a.Route = entry.Route
+	a.Status = entry.Status
+	a.EntityType = entry.EntityType
+	a.EntityID = entry.EntityID
+	a.Action = entry.Action
+	a.Changes = nil
+	if len(entry.Changes) > 0 {
+		changes, err := json.Marshal(entry.Changes)
+		if err != nil {
+			return fmt.Errorf("failed to encode the changes of %s %s: %w", entry.EntityType, entry.EntityID, err)
+		}
+		a.Changes = changes
+	}
+	return nil
+}
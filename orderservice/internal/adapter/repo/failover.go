@@ -0,0 +1,373 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/fx"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// replayTimeout bounds one queued write replayed on the primary
+const replayTimeout = 5 * time.Second
+
+var (
+	// ErrWriteQueueFull is returned for writes while the primary is down and
+	// failover.max_queued writes wait already
+	ErrWriteQueueFull = errors.New("primary database is down and the write queue is full")
+	// ErrFailedOver is returned by the queries that must see every row, such
+	// as those the orphan collector deletes files by, while the repositories
+	// run on the standby
+	ErrFailedOver = errors.New("primary database is down; the standby may miss rows")
+)
+
+// FailoverConfig is the failover section: the standby database the
+// repositories read from while the primary is down
+type FailoverConfig struct {
+	// Enabled wraps the repositories; without it they use the primary alone
+	Enabled bool `mapstructure:"enabled" default:"false"`
+	// StandbyDSN connects to the standby, a replica of the primary. Set it
+	// through STRATUM_FAILOVER_STANDBY_DSN; it is never logged.
+	StandbyDSN string `mapstructure:"standby_dsn"`
+	// ProbeInterval is how often the primary is pinged, to fail over before
+	// a request finds it down, and to fail back once it answers again
+	ProbeInterval time.Duration `mapstructure:"probe_interval" default:"1s"`
+	// MaxQueued bounds the writes kept while the primary is down; writes
+	// beyond it fail
+	MaxQueued int `mapstructure:"max_queued" default:"1000"`
+}
+
+// Prefix implements configx.Configurable
+func (FailoverConfig) Prefix() string {
+	return "failover"
+}
+
+// Validate checks an enabled failover has a standby
+func (c FailoverConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if strings.TrimSpace(c.StandbyDSN) == "" {
+		return errors.New("failover.standby_dsn must be set when failover.enabled is")
+	}
+	if c.ProbeInterval <= 0 || c.MaxQueued <= 0 {
+		return fmt.Errorf("failover.probe_interval (%s) and failover.max_queued (%d) must be positive", c.ProbeInterval, c.MaxQueued)
+	}
+	return nil
+}
+
+// Failover runs the repositories on the primary database while it answers.
+// When it does not, reads go to the standby, and writes are kept in memory and
+// reported done; once the primary answers again, the writes are replayed on it
+// in the order they were made, and then everything goes to the primary again.
+//
+// A write kept in memory is lost if the service stops before the primary is
+// back, and one the primary refuses on replay, such as a duplicate email, is
+// dropped and logged: the client was told it succeeded. Rows written while
+// failed over are not read back until they are replayed, as the standby does
+// not have them.
+type Failover struct {
+	cfg     FailoverConfig
+	standby *gorm.DB
+	log     logx.Logger
+	// ping asks the primary whether it answers
+	ping func(ctx context.Context) error
+
+	mu         sync.Mutex
+	failedOver bool
+	queue      []queuedWrite
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// queuedWrite is a write kept while the primary is down
+type queuedWrite struct {
+	// name tells the write apart in logs, e.g. "save user <id>"
+	name string
+	run  func(ctx context.Context) error
+}
+
+// NewFailover opens the standby of the failover section, or returns nil when
+// failover is disabled
+func NewFailover(loader configx.Loader, primary *gorm.DB, log logx.Logger) (*Failover, error) {
+	var cfg FailoverConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load failover config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	return newFailover(cfg, primary, log)
+}
+
+func newFailover(cfg FailoverConfig, primary *gorm.DB, log logx.Logger) (*Failover, error) {
+	standby, err := gorm.Open(postgres.Open(cfg.StandbyDSN), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		// The DSN is left out, as it holds the password
+		return nil, errors.New("failed to open the standby database of failover.standby_dsn")
+	}
+	return &Failover{
+		cfg:     cfg,
+		standby: standby,
+		log:     log,
+		ping: func(ctx context.Context) error {
+			db, err := primary.DB()
+			if err != nil {
+				return err
+			}
+			return db.PingContext(ctx)
+		},
+	}, nil
+}
+
+// RegisterFailover pings the primary while the application runs, when
+// failover is enabled. This function is designed to be used with fx.Invoke.
+func RegisterFailover(lc fx.Lifecycle, f *Failover) {
+	if f == nil {
+		return
+	}
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			f.start()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			f.shutdown()
+			return nil
+		},
+	})
+}
+
+// FailedOver reports whether the repositories run on the standby
+func (f *Failover) FailedOver() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.failedOver
+}
+
+// Queued returns the number of writes waiting for the primary
+func (f *Failover) Queued() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.queue)
+}
+
+func (f *Failover) start() {
+	f.stop, f.done = make(chan struct{}), make(chan struct{})
+	go f.probe()
+}
+
+// shutdown stops the probe and closes the standby. Writes still queued are
+// lost, and logged so they can be recovered by hand.
+func (f *Failover) shutdown() {
+	close(f.stop)
+	<-f.done
+
+	f.mu.Lock()
+	lost := make([]string, len(f.queue))
+	for i, w := range f.queue {
+		lost[i] = w.name
+	}
+	f.mu.Unlock()
+	if len(lost) > 0 {
+		f.log.Error("writes queued while the primary database was down are lost",
+			logx.Int("count", len(lost)), logx.Any("writes", lost))
+	}
+
+	if f.standby != nil {
+		if db, err := f.standby.DB(); err == nil {
+			_ = db.Close()
+		}
+	}
+}
+
+// probe pings the primary every probe interval: it fails over when a healthy
+// primary stops answering, and fails back when a failed one answers again
+func (f *Failover) probe() {
+	defer close(f.done)
+	ticker := time.NewTicker(f.cfg.ProbeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), f.cfg.ProbeInterval)
+			err := f.ping(ctx)
+			cancel()
+			switch {
+			case err == nil && f.FailedOver():
+				f.failBack()
+			case err != nil && !f.FailedOver():
+				f.failOver(err)
+			}
+		}
+	}
+}
+
+// failOver sends the repositories to the standby, as the primary failed
+// with err
+func (f *Failover) failOver(err error) {
+	f.mu.Lock()
+	already := f.failedOver
+	f.failedOver = true
+	f.mu.Unlock()
+	if !already {
+		f.warnFailedOver(err)
+	}
+}
+
+func (f *Failover) warnFailedOver(err error) {
+	f.log.Warn("primary database is down; reads go to the standby and writes are queued", logx.Err(err))
+}
+
+// failBack replays the queued writes on the primary, oldest first, and sends
+// the repositories back to it once none is left. Writes made meanwhile are
+// queued behind, so none overtakes another. When the primary fails again, the
+// rest wait for the next probe.
+func (f *Failover) failBack() {
+	replayed, dropped := 0, 0
+	for {
+		f.mu.Lock()
+		if len(f.queue) == 0 {
+			f.failedOver = false
+			f.mu.Unlock()
+			break
+		}
+		w := f.queue[0]
+		f.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), replayTimeout)
+		err := w.run(ctx)
+		cancel()
+		if err != nil && primaryDown(err) {
+			f.log.Warn("primary database failed while queued writes were replayed",
+				logx.Int("replayed", replayed), logx.Err(err))
+			return
+		}
+
+		f.mu.Lock()
+		f.queue = f.queue[1:]
+		f.mu.Unlock()
+		if err != nil {
+			dropped++
+			f.log.Error("queued write refused by the primary database, and dropped",
+				logx.String("write", w.name), logx.Err(err))
+			continue
+		}
+		replayed++
+	}
+	f.log.Info("primary database is back; reads and writes go to it again",
+		logx.Int("replayed", replayed), logx.Int("dropped", dropped))
+}
+
+// read runs call with the primary repository, or with the standby's while
+// failed over. A primary that fails it fails over, and the standby answers.
+func read[R, T any](f *Failover, primary, standby R, call func(R) (T, error)) (T, error) {
+	if !f.FailedOver() {
+		result, err := call(primary)
+		if !primaryDown(err) {
+			return result, err
+		}
+		f.failOver(err)
+	}
+	return call(standby)
+}
+
+// write runs now on the primary. While failed over, it queues later instead
+// and returns nil. A primary that fails now fails over, and later is queued
+// when the write never reached the primary; when it may have, the error is
+// returned, as running it again could run it twice. later must not share
+// what the caller may change after write returns.
+//
+// Whether to queue is decided under the lock the write is queued under, so
+// failBack cannot empty the queue and fail back in between, leaving the write
+// queued with nothing to replay it.
+func (f *Failover) write(ctx context.Context, name string, now, later func(context.Context) error) error {
+	f.mu.Lock()
+	if f.failedOver {
+		defer f.mu.Unlock()
+		return f.enqueue(name, later)
+	}
+	f.mu.Unlock()
+
+	err := now(ctx)
+	if !primaryDown(err) {
+		return err
+	}
+	if !notSent(err) {
+		f.failOver(err)
+		return err
+	}
+
+	f.mu.Lock()
+	already := f.failedOver
+	f.failedOver = true
+	queueErr := f.enqueue(name, later)
+	f.mu.Unlock()
+	if !already {
+		f.warnFailedOver(err)
+	}
+	return queueErr
+}
+
+// enqueue queues later behind the other writes. f.mu must be held.
+func (f *Failover) enqueue(name string, later func(context.Context) error) error {
+	if len(f.queue) >= f.cfg.MaxQueued {
+		return ErrWriteQueueFull
+	}
+	f.queue = append(f.queue, queuedWrite{name: name, run: later})
+	return nil
+}
+
+// primaryDown reports whether err tells the database cannot be reached, rather
+// than that it refused a statement. A query running out of time is not taken
+// for a failure, as a slow query does too; the probe finds a hung primary.
+func primaryDown(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if notSent(err) {
+		return true
+	}
+	var netErr net.Error
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.As(err, &netErr):
+		return true
+	case errors.As(err, &pgErr):
+		// Class 08 is connection exceptions; 57P01 to 57P03 a server shutting
+		// down or starting up
+		return strings.HasPrefix(pgErr.Code, "08") || slices.Contains([]string{"57P01", "57P02", "57P03"}, pgErr.Code)
+	}
+	return false
+}
+
+// notSent reports whether err stopped a statement before it reached the
+// database, so running it again cannot run it twice
+func notSent(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var connectErr *pgconn.ConnectError
+	return errors.As(err, &connectErr) || pgconn.SafeToRetry(err)
+}
@@ -0,0 +1,189 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/gostratum/examples/orderservice/internal/audit"
+	"github.com/gostratum/examples/orderservice/internal/domain"
+	"github.com/gostratum/examples/orderservice/internal/uploads"
+	"github.com/gostratum/examples/orderservice/internal/usecase"
+)
+
+// The With* functions wrap a repository of the primary database with f, and
+// return it as it is when failover is disabled. They are designed to be used
+// with fx.Decorate.
+
+// WithFailoverUsers wraps the user repository with f
+func WithFailoverUsers(f *Failover, users usecase.UserRepository) usecase.UserRepository {
+	if f == nil {
+		return users
+	}
+	return &failoverUserRepo{failover: f, primary: users, standby: NewUserRepo(f.standby)}
+}
+
+// WithFailoverOrders wraps the order repository with f
+func WithFailoverOrders(f *Failover, orders usecase.OrderRepository) usecase.OrderRepository {
+	if f == nil {
+		return orders
+	}
+	return &failoverOrderRepo{failover: f, primary: orders, standby: NewOrderRepo(f.standby)}
+}
+
+// WithFailoverAttachments wraps the attachment repository with f
+func WithFailoverAttachments(f *Failover, attachments usecase.AttachmentRepository) usecase.AttachmentRepository {
+	if f == nil {
+		return attachments
+	}
+	return &failoverAttachmentRepo{failover: f, primary: attachments, standby: NewAttachmentRepo(f.standby)}
+}
+
+// WithFailoverAudit wraps the audit log with f
+func WithFailoverAudit(f *Failover, store audit.Store) audit.Store {
+	if f == nil {
+		return store
+	}
+	return &failoverAuditRepo{failover: f, primary: store, standby: NewAuditRepo(f.standby)}
+}
+
+// WithFailoverAvatarReferences makes the collector's avatar references fail
+// while failed over
+func WithFailoverAvatarReferences(f *Failover, refs uploads.AvatarReferences) uploads.AvatarReferences {
+	if f == nil {
+		return refs
+	}
+	return failoverAvatarReferences{failover: f, refs: refs}
+}
+
+// WithFailoverAttachmentReferences makes the collector's attachment
+// references fail while failed over
+func WithFailoverAttachmentReferences(f *Failover, refs uploads.AttachmentReferences) uploads.AttachmentReferences {
+	if f == nil {
+		return refs
+	}
+	return failoverAttachmentReferences{failover: f, refs: refs}
+}
+
+// failoverAvatarReferences asks the primary alone, and fails while failed
+// over: an avatar the standby does not know to be in use yet, or whose user
+// is in the write queue, would be deleted
+type failoverAvatarReferences struct {
+	failover *Failover
+	refs     uploads.AvatarReferences
+}
+
+func (r failoverAvatarReferences) AvatarHashesInUse(ctx context.Context, hashes []string) ([]string, error) {
+	if r.failover.FailedOver() {
+		return nil, ErrFailedOver
+	}
+	return r.refs.AvatarHashesInUse(ctx, hashes)
+}
+
+// failoverAttachmentReferences asks the primary alone, and fails while
+// failed over, for the same reason
+type failoverAttachmentReferences struct {
+	failover *Failover
+	refs     uploads.AttachmentReferences
+}
+
+func (r failoverAttachmentReferences) ExistingOrderIDs(ctx context.Context, ids []string) ([]string, error) {
+	if r.failover.FailedOver() {
+		return nil, ErrFailedOver
+	}
+	return r.refs.ExistingOrderIDs(ctx, ids)
+}
+
+type failoverUserRepo struct {
+	failover         *Failover
+	primary, standby usecase.UserRepository
+}
+
+func (r *failoverUserRepo) Save(ctx context.Context, user *domain.User) error {
+	queued := *user
+	return r.failover.write(ctx, "save user "+user.ID,
+		func(ctx context.Context) error { return r.primary.Save(ctx, user) },
+		func(ctx context.Context) error { return r.primary.Save(ctx, &queued) })
+}
+
+func (r *failoverUserRepo) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	return read(r.failover, r.primary, r.standby, func(users usecase.UserRepository) (*domain.User, error) {
+		return users.FindByID(ctx, id)
+	})
+}
+
+func (r *failoverUserRepo) Update(ctx context.Context, user *domain.User) error {
+	queued := *user
+	return r.failover.write(ctx, "update user "+user.ID,
+		func(ctx context.Context) error { return r.primary.Update(ctx, user) },
+		func(ctx context.Context) error { return r.primary.Update(ctx, &queued) })
+}
+
+func (r *failoverUserRepo) AvatarHashesInUse(ctx context.Context, hashes []string) ([]string, error) {
+	return failoverAvatarReferences{failover: r.failover, refs: r.primary}.AvatarHashesInUse(ctx, hashes)
+}
+
+type failoverOrderRepo struct {
+	failover         *Failover
+	primary, standby usecase.OrderRepository
+}
+
+func (r *failoverOrderRepo) Save(ctx context.Context, order *domain.Order) error {
+	queued := *order
+	queued.Items = slices.Clone(order.Items)
+	return r.failover.write(ctx, "save order "+order.ID,
+		func(ctx context.Context) error { return r.primary.Save(ctx, order) },
+		func(ctx context.Context) error { return r.primary.Save(ctx, &queued) })
+}
+
+func (r *failoverOrderRepo) FindByID(ctx context.Context, id string) (*domain.Order, error) {
+	return read(r.failover, r.primary, r.standby, func(orders usecase.OrderRepository) (*domain.Order, error) {
+		return orders.FindByID(ctx, id)
+	})
+}
+
+func (r *failoverOrderRepo) ExistingOrderIDs(ctx context.Context, ids []string) ([]string, error) {
+	return failoverAttachmentReferences{failover: r.failover, refs: r.primary}.ExistingOrderIDs(ctx, ids)
+}
+
+type failoverAttachmentRepo struct {
+	failover         *Failover
+	primary, standby usecase.AttachmentRepository
+}
+
+func (r *failoverAttachmentRepo) Save(ctx context.Context, attachment *domain.Attachment) error {
+	queued := *attachment
+	return r.failover.write(ctx, "save attachment "+attachment.ID,
+		func(ctx context.Context) error { return r.primary.Save(ctx, attachment) },
+		func(ctx context.Context) error { return r.primary.Save(ctx, &queued) })
+}
+
+func (r *failoverAttachmentRepo) FindByID(ctx context.Context, id string) (*domain.Attachment, error) {
+	return read(r.failover, r.primary, r.standby, func(attachments usecase.AttachmentRepository) (*domain.Attachment, error) {
+		return attachments.FindByID(ctx, id)
+	})
+}
+
+func (r *failoverAttachmentRepo) ListByOrder(ctx context.Context, orderID string) ([]*domain.Attachment, error) {
+	return read(r.failover, r.primary, r.standby, func(attachments usecase.AttachmentRepository) ([]*domain.Attachment, error) {
+		return attachments.ListByOrder(ctx, orderID)
+	})
+}
+
+type failoverAuditRepo struct {
+	failover         *Failover
+	primary, standby audit.Store
+}
+
+func (r *failoverAuditRepo) Insert(ctx context.Context, entries []audit.Entry) error {
+	queued := slices.Clone(entries)
+	return r.failover.write(ctx, fmt.Sprintf("insert %d audit entries", len(entries)),
+		func(ctx context.Context) error { return r.primary.Insert(ctx, entries) },
+		func(ctx context.Context) error { return r.primary.Insert(ctx, queued) })
+}
+
+func (r *failoverAuditRepo) List(ctx context.Context, filter audit.Filter) ([]audit.Entry, error) {
+	return read(r.failover, r.primary, r.standby, func(store audit.Store) ([]audit.Entry, error) {
+		return store.List(ctx, filter)
+	})
+}
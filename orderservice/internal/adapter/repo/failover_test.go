@@ -0,0 +1,499 @@
+package repo
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/gostratum/examples/orderservice/internal/domain"
+	"github.com/gostratum/examples/orderservice/internal/testutil/factories"
+	"github.com/gostratum/examples/orderservice/migrations"
+	"github.com/gostratum/examples/testkit/containers"
+)
+
+// refusedError is a connection refused before a statement was sent, as pgx
+// reports it
+type refusedError struct{}
+
+func (refusedError) Error() string     { return "dial tcp 10.0.0.5:5432: connect: connection refused" }
+func (refusedError) SafeToRetry() bool { return true }
+
+// memUsers is a user repository in memory, failing every call with err when
+// it is set
+type memUsers struct {
+	mu    sync.Mutex
+	users map[string]domain.User
+	err   error
+}
+
+func newMemUsers(users ...*domain.User) *memUsers {
+	m := &memUsers{users: map[string]domain.User{}}
+	for _, u := range users {
+		m.users[u.ID] = *u
+	}
+	return m
+}
+
+func (m *memUsers) fail(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.err = err
+}
+
+func (m *memUsers) get(id string) (domain.User, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.users[id]
+	return u, ok
+}
+
+func (m *memUsers) Save(ctx context.Context, user *domain.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	for _, u := range m.users {
+		if u.Email == user.Email {
+			return domain.ErrConflict
+		}
+	}
+	m.users[user.ID] = *user
+	return nil
+}
+
+func (m *memUsers) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	u, ok := m.users[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &u, nil
+}
+
+func (m *memUsers) Update(ctx context.Context, user *domain.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	if _, ok := m.users[user.ID]; !ok {
+		return domain.ErrNotFound
+	}
+	m.users[user.ID] = *user
+	return nil
+}
+
+func (m *memUsers) AvatarHashesInUse(ctx context.Context, hashes []string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return nil, m.err
+}
+
+// newMemFailover wraps primary and standby with a failover whose probe finds
+// the primary as it answers
+func newMemFailover(primary, standby *memUsers, maxQueued int) (*Failover, *failoverUserRepo) {
+	f := &Failover{
+		cfg: FailoverConfig{Enabled: true, ProbeInterval: 10 * time.Millisecond, MaxQueued: maxQueued},
+		log: logx.NewNoopLogger(),
+		ping: func(ctx context.Context) error {
+			_, err := primary.FindByID(ctx, "ping")
+			if errors.Is(err, domain.ErrNotFound) {
+				return nil
+			}
+			return err
+		},
+	}
+	return f, &failoverUserRepo{failover: f, primary: primary, standby: standby}
+}
+
+func TestFailover_QueuesWritesWhilePrimaryIsDown(t *testing.T) {
+	ctx := context.Background()
+	alice := factories.User(func(u *domain.User) { u.Email = "alice@example.com" })
+	primary, standby := newMemUsers(alice), newMemUsers(alice)
+	f, users := newMemFailover(primary, standby, 2)
+
+	primary.fail(refusedError{})
+
+	// Reads fail over to the standby
+	found, err := users.FindByID(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, alice.Email, found.Email)
+	assert.True(t, f.FailedOver())
+
+	// Writes are queued, in order, up to max_queued
+	bob := factories.User(func(u *domain.User) { u.Email = "bob@example.com" })
+	require.NoError(t, users.Save(ctx, bob))
+	updated := *alice
+	updated.UpdateAvatar("avatars/ab.png", "ab")
+	require.NoError(t, users.Update(ctx, &updated))
+	updated.AvatarURL = "changed by the caller after Update returned"
+	assert.Equal(t, ErrWriteQueueFull, users.Save(ctx, factories.User()))
+	assert.Equal(t, 2, f.Queued())
+	_, ok := standby.get(bob.ID)
+	assert.False(t, ok, "queued writes go nowhere until the primary is back")
+
+	// The collector deletes nothing meanwhile
+	_, err = users.AvatarHashesInUse(ctx, []string{"ab"})
+	assert.Equal(t, ErrFailedOver, err)
+
+	// The primary is back: the writes are replayed, and reads go to it again
+	primary.fail(nil)
+	f.failBack()
+	assert.False(t, f.FailedOver())
+	assert.Zero(t, f.Queued())
+	saved, ok := primary.get(bob.ID)
+	assert.True(t, ok)
+	assert.Equal(t, bob.Email, saved.Email)
+	saved, _ = primary.get(alice.ID)
+	assert.Equal(t, "avatars/ab.png", saved.AvatarURL, "the user as it was when Update was called")
+}
+
+func TestFailover_WriteThatMayHaveReachedThePrimary(t *testing.T) {
+	primary, standby := newMemUsers(), newMemUsers()
+	f, users := newMemFailover(primary, standby, 10)
+
+	// The connection broke while the statement ran: it may have committed,
+	// so it is not run again
+	primary.fail(io.ErrUnexpectedEOF)
+	assert.ErrorIs(t, users.Save(context.Background(), factories.User()), io.ErrUnexpectedEOF)
+	assert.True(t, f.FailedOver())
+	assert.Zero(t, f.Queued())
+}
+
+func TestFailover_RefusedStatementsDoNotFailOver(t *testing.T) {
+	alice := factories.User(func(u *domain.User) { u.Email = "alice@example.com" })
+	primary, standby := newMemUsers(alice), newMemUsers()
+	f, users := newMemFailover(primary, standby, 10)
+
+	copycat := factories.User(func(u *domain.User) { u.Email = alice.Email })
+	assert.Equal(t, domain.ErrConflict, users.Save(context.Background(), copycat))
+	_, err := users.FindByID(context.Background(), "missing")
+	assert.Equal(t, domain.ErrNotFound, err)
+	assert.False(t, f.FailedOver())
+}
+
+func TestFailover_Replay(t *testing.T) {
+	ctx := context.Background()
+	alice := factories.User(func(u *domain.User) { u.Email = "alice@example.com" })
+	primary, standby := newMemUsers(alice), newMemUsers(alice)
+	f, users := newMemFailover(primary, standby, 10)
+
+	primary.fail(refusedError{})
+	copycat := factories.User(func(u *domain.User) { u.Email = alice.Email })
+	bob := factories.User(func(u *domain.User) { u.Email = "bob@example.com" })
+	require.NoError(t, users.Save(ctx, copycat))
+	require.NoError(t, users.Save(ctx, bob))
+
+	t.Run("stops when the primary fails again", func(t *testing.T) {
+		f.failBack()
+		assert.True(t, f.FailedOver())
+		assert.Equal(t, 2, f.Queued())
+	})
+
+	t.Run("drops the writes the primary refuses", func(t *testing.T) {
+		primary.fail(nil)
+		f.failBack()
+		assert.False(t, f.FailedOver())
+		assert.Zero(t, f.Queued())
+		_, ok := primary.get(copycat.ID)
+		assert.False(t, ok, "a duplicate email is dropped")
+		_, ok = primary.get(bob.ID)
+		assert.True(t, ok, "the writes after it are replayed")
+	})
+}
+
+func TestFailover_Probe(t *testing.T) {
+	primary, standby := newMemUsers(), newMemUsers()
+	f, users := newMemFailover(primary, standby, 10)
+	f.start()
+	defer f.shutdown()
+
+	// Failed over before any request finds the primary down
+	primary.fail(refusedError{})
+	assert.Eventually(t, f.FailedOver, time.Second, 5*time.Millisecond)
+	require.NoError(t, users.Save(context.Background(), factories.User()))
+
+	primary.fail(nil)
+	assert.Eventually(t, func() bool { return !f.FailedOver() }, time.Second, 5*time.Millisecond)
+	assert.Zero(t, f.Queued())
+}
+
+func TestFailover_WriteWhileFailingBack(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("is queued behind the replay", func(t *testing.T) {
+		f, _ := newMemFailover(newMemUsers(), newMemUsers(), 10)
+		f.failOver(refusedError{})
+
+		replaying, release := make(chan struct{}), make(chan struct{})
+		var replayed []string
+		require.NoError(t, f.write(ctx, "first", nil, func(context.Context) error {
+			close(replaying)
+			<-release
+			replayed = append(replayed, "first")
+			return nil
+		}))
+		failedBack := make(chan struct{})
+		go func() {
+			f.failBack()
+			close(failedBack)
+		}()
+
+		<-replaying
+		now := func(context.Context) error {
+			t.Error("a write made during the replay overtook the queued ones")
+			return nil
+		}
+		require.NoError(t, f.write(ctx, "second", now, func(context.Context) error {
+			replayed = append(replayed, "second")
+			return nil
+		}))
+		close(release)
+		<-failedBack
+
+		assert.Equal(t, []string{"first", "second"}, replayed)
+		assert.False(t, f.FailedOver())
+		assert.Zero(t, f.Queued())
+	})
+
+	t.Run("that fails over is queued before the probe can fail back", func(t *testing.T) {
+		f, _ := newMemFailover(newMemUsers(), newMemUsers(), 10)
+		// The probe finds the primary back while the write fails over
+		f.log = warnHook{Logger: f.log, warn: f.failBack}
+
+		var runs int
+		refused := func(context.Context) error { return refusedError{} }
+		require.NoError(t, f.write(ctx, "save", refused, func(context.Context) error {
+			runs++
+			return nil
+		}))
+
+		assert.Equal(t, 1, runs, "replayed by the fail back")
+		assert.False(t, f.FailedOver())
+		assert.Zero(t, f.Queued(), "left queued after the primary was back")
+	})
+}
+
+// warnHook calls warn on every warning
+type warnHook struct {
+	logx.Logger
+	warn func()
+}
+
+func (h warnHook) Warn(msg string, fields ...logx.Field) {
+	h.Logger.Warn(msg, fields...)
+	h.warn()
+}
+
+func TestPrimaryDown(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		down    bool
+		notSent bool
+	}{
+		{name: "no error"},
+		{name: "connection refused", err: refusedError{}, down: true, notSent: true},
+		{name: "bad connection", err: driver.ErrBadConn, down: true},
+		{name: "connection lost mid-statement", err: io.ErrUnexpectedEOF, down: true},
+		{name: "network error", err: &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}, down: true},
+		{name: "connection failure", err: &pgconn.PgError{Code: "08006"}, down: true},
+		{name: "server shutting down", err: &pgconn.PgError{Code: "57P01"}, down: true},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}},
+		{name: "not found", err: domain.ErrNotFound},
+		{name: "query out of time", err: context.DeadlineExceeded},
+		{name: "request cancelled", err: context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.down, primaryDown(tt.err))
+			if tt.err != nil {
+				assert.Equal(t, tt.notSent, notSent(tt.err))
+			}
+		})
+	}
+}
+
+func TestFailoverConfig_Validate(t *testing.T) {
+	assert.NoError(t, FailoverConfig{}.Validate(), "nothing is needed when disabled")
+	assert.NoError(t, FailoverConfig{Enabled: true, StandbyDSN: "postgres://standby/orders", ProbeInterval: time.Second, MaxQueued: 1}.Validate())
+	assert.EqualError(t, FailoverConfig{Enabled: true, ProbeInterval: time.Second, MaxQueued: 1}.Validate(),
+		"failover.standby_dsn must be set when failover.enabled is")
+	assert.EqualError(t, FailoverConfig{Enabled: true, StandbyDSN: "postgres://standby/orders"}.Validate(),
+		"failover.probe_interval (0s) and failover.max_queued (0) must be positive")
+}
+
+// proxy forwards connections to a database until it is cut, as a primary
+// that went down would: open connections break, and new ones are refused
+type proxy struct {
+	listener net.Listener
+	target   string
+	down     atomic.Bool
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+func newProxy(t *testing.T, target string) *proxy {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	p := &proxy{listener: listener, target: target, conns: map[net.Conn]struct{}{}}
+	t.Cleanup(func() {
+		listener.Close()
+		p.cut()
+	})
+	go p.serve()
+	return p
+}
+
+func (p *proxy) serve() {
+	for {
+		client, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		if p.down.Load() {
+			client.Close()
+			continue
+		}
+		server, err := net.Dial("tcp", p.target)
+		if err != nil {
+			client.Close()
+			continue
+		}
+		p.track(client, server)
+		go func() { _, _ = io.Copy(server, client); server.Close() }()
+		go func() { _, _ = io.Copy(client, server); client.Close() }()
+	}
+}
+
+func (p *proxy) track(conns ...net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, c := range conns {
+		p.conns[c] = struct{}{}
+	}
+}
+
+// cut breaks the open connections and refuses new ones
+func (p *proxy) cut() {
+	p.down.Store(true)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for c := range p.conns {
+		c.Close()
+		delete(p.conns, c)
+	}
+}
+
+// restore accepts connections again
+func (p *proxy) restore() {
+	p.down.Store(false)
+}
+
+// TestFailover_Scenario runs a primary outage end to end: the primary goes
+// down with the service reading and writing, the standby answers reads, the
+// writes wait, and once the primary is back they are replayed on it. The
+// primary sits behind a proxy the test cuts. The standby is a database of its
+// own, which the test keeps in step with the primary where a replica would
+// follow it by streaming replication.
+func TestFailover_Scenario(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	primaryDatabase := containers.Postgres(t, containers.WithMigrations(migrations.FS))
+	standbyDatabase := containers.Postgres(t, containers.WithMigrations(migrations.FS))
+
+	dsn, err := url.Parse(primaryDatabase.DSN)
+	require.NoError(t, err)
+	primaryProxy := newProxy(t, dsn.Host)
+	dsn.Host = primaryProxy.listener.Addr().String()
+	primaryDB, err := gorm.Open(postgres.Open(dsn.String()), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+
+	f, err := newFailover(FailoverConfig{
+		Enabled:       true,
+		StandbyDSN:    standbyDatabase.DSN,
+		ProbeInterval: 50 * time.Millisecond,
+		MaxQueued:     100,
+	}, primaryDB, logx.NewNoopLogger())
+	require.NoError(t, err)
+	f.start()
+	t.Cleanup(f.shutdown)
+
+	users := WithFailoverUsers(f, NewUserRepo(primaryDB))
+	orders := WithFailoverOrders(f, NewOrderRepo(primaryDB))
+	avatars := WithFailoverAvatarReferences(f, NewUserRepo(primaryDB))
+	replicate := func(u *domain.User) {
+		t.Helper()
+		replica := *u
+		require.NoError(t, NewUserRepo(f.standby).Save(ctx, &replica))
+	}
+
+	// 1. The primary is up: everything goes to it
+	alice := factories.User()
+	require.NoError(t, users.Save(ctx, alice))
+	replicate(alice)
+	assert.False(t, f.FailedOver())
+
+	// 2. The primary goes down: reads are answered by the standby
+	primaryProxy.cut()
+	found, err := users.FindByID(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, alice.Email, found.Email)
+	assert.True(t, f.FailedOver())
+
+	// 3. Writes are queued and reported done
+	bob := factories.User()
+	require.NoError(t, users.Save(ctx, bob))
+	order := factories.Order(factories.ForUser(bob), factories.WithItems(2))
+	require.NoError(t, orders.Save(ctx, order))
+	updated := *alice
+	updated.UpdateAvatar("avatars/ab.png", "ab")
+	require.NoError(t, users.Update(ctx, &updated))
+	assert.Equal(t, 3, f.Queued())
+
+	_, err = users.FindByID(ctx, bob.ID)
+	assert.Equal(t, domain.ErrNotFound, err, "the standby has no queued write")
+	_, err = avatars.AvatarHashesInUse(ctx, []string{"ab"})
+	assert.Equal(t, ErrFailedOver, err, "the collector deletes nothing while failed over")
+
+	// 4. The primary is back: the probe replays the writes, in order, then
+	// sends everything to the primary again
+	primaryProxy.restore()
+	require.Eventually(t, func() bool { return !f.FailedOver() }, 10*time.Second, 50*time.Millisecond)
+	assert.Zero(t, f.Queued())
+
+	found, err = users.FindByID(ctx, bob.ID)
+	require.NoError(t, err)
+	assert.Equal(t, bob.Email, found.Email)
+	savedOrder, err := orders.FindByID(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Len(t, savedOrder.Items, 2, "the order was saved after its user")
+	found, err = users.FindByID(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "avatars/ab.png", found.AvatarURL)
+	inUse, err := avatars.AvatarHashesInUse(ctx, []string{"ab"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ab"}, inUse)
+}
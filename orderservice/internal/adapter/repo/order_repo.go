@@ -59,3 +59,19 @@ func (r *OrderRepo) FindByID(ctx context.Context, id string) (*domain.Order, err
 
 	return entity.ToDomain(), nil
 }
+
+// ExistingOrderIDs returns those of ids an order has
+func (r *OrderRepo) ExistingOrderIDs(ctx context.Context, ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var existing []string
+	err := r.db.WithContext(ctx).Model(&OrderEntity{}).
+		Where("id IN ?", ids).
+		Pluck("id", &existing).Error
+	if err != nil {
+		// Return raw error - use case layer will translate to ErrUnavailable
+		return nil, err
+	}
+	return existing, nil
+}
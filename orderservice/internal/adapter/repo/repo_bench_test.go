@@ -0,0 +1,123 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/gostratum/examples/orderservice/internal/adapter/inventory"
+	"github.com/gostratum/examples/orderservice/internal/adapter/payment"
+	"github.com/gostratum/examples/orderservice/internal/domain"
+	"github.com/gostratum/examples/orderservice/internal/testutil/factories"
+	"github.com/gostratum/examples/orderservice/internal/usecase"
+	"github.com/gostratum/examples/orderservice/migrations"
+	"github.com/gostratum/examples/testkit/containers"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupTestDB creates a Postgres database with the service's migrations
+// applied for a benchmark. Unlike the tests' transactions from dbtest, it
+// commits every write, as the service does, so commits are measured too.
+func setupTestDB(b *testing.B) *gorm.DB {
+	b.Helper()
+	database := containers.Postgres(b, containers.WithMigrations(migrations.FS))
+
+	db, err := gorm.Open(postgres.Open(database.DSN), &gorm.Config{Logger: logger.Discard})
+	require.NoError(b, err)
+	sqlDB, err := db.DB()
+	require.NoError(b, err)
+	b.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// itemCounts are the order sizes the benchmarks run with
+var itemCounts = []int{1, 10, 100}
+
+// benchUser saves a user for the benchmark's orders to belong to
+func benchUser(b *testing.B, repo usecase.UserRepository) *domain.User {
+	b.Helper()
+	user := factories.User()
+	if err := repo.Save(context.Background(), user); err != nil {
+		b.Fatal(err)
+	}
+	return user
+}
+
+// benchOrder returns a new order of user with n items
+func benchOrder(user *domain.User, n int) *domain.Order {
+	return factories.Order(factories.ForUser(user), factories.WithItems(n))
+}
+
+// BenchmarkOrderRepo_Save measures inserting an order and its items in one
+// transaction
+func BenchmarkOrderRepo_Save(b *testing.B) {
+	db := setupTestDB(b)
+	orderRepo := NewOrderRepo(db)
+	user := benchUser(b, NewUserRepo(db))
+	ctx := context.Background()
+
+	for _, n := range itemCounts {
+		b.Run(fmt.Sprintf("items=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if err := orderRepo.Save(ctx, benchOrder(user, n)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkOrderRepo_FindByID measures reading an order with its items
+// preloaded, which takes a second query
+func BenchmarkOrderRepo_FindByID(b *testing.B) {
+	db := setupTestDB(b)
+	orderRepo := NewOrderRepo(db)
+	user := benchUser(b, NewUserRepo(db))
+	ctx := context.Background()
+
+	for _, n := range itemCounts {
+		b.Run(fmt.Sprintf("items=%d", n), func(b *testing.B) {
+			order := benchOrder(user, n)
+			if err := orderRepo.Save(ctx, order); err != nil {
+				b.Fatal(err)
+			}
+
+			b.ReportAllocs()
+			for b.Loop() {
+				found, err := orderRepo.FindByID(ctx, order.ID)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(found.Items) != n {
+					b.Fatalf("got %d items, want %d", len(found.Items), n)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkCreateOrder_Postgres measures the order service over the real
+// repository, without inventoryservice and paymentservice
+func BenchmarkCreateOrder_Postgres(b *testing.B) {
+	db := setupTestDB(b)
+	service := usecase.NewOrderService(NewOrderRepo(db), inventory.Disabled{}, payment.Disabled{})
+	user := benchUser(b, NewUserRepo(db))
+	ctx := context.Background()
+
+	for _, n := range itemCounts {
+		b.Run(fmt.Sprintf("items=%d", n), func(b *testing.B) {
+			items := benchOrder(user, n).Items
+
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := service.CreateOrder(ctx, user.ID, items); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -3,71 +3,44 @@ package repo
 import (
 	"context"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gostratum/examples/orderservice/internal/audit"
 	"github.com/gostratum/examples/orderservice/internal/domain"
+	"github.com/gostratum/examples/orderservice/internal/testutil/dbtest"
+	"github.com/gostratum/examples/orderservice/internal/testutil/factories"
 	"github.com/gostratum/examples/orderservice/internal/usecase"
+	"github.com/gostratum/examples/testkit/containers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
 )
 
-// setupTestDB creates an in-memory SQLite database for testing
-func setupTestDB(t *testing.T) *gorm.DB {
-	// Use a unique database name for each test to avoid conflicts
-	dbName := t.Name() + ".db"
-	db, err := gorm.Open(sqlite.Open(dbName), &gorm.Config{})
-	require.NoError(t, err)
-
-	// Clean up the database file after test
-	t.Cleanup(func() {
-		os.Remove(dbName)
-	})
+func TestMain(m *testing.M) {
+	os.Exit(containers.Run(m))
+}
 
-	// Create tables manually for SQLite compatibility
-	err = db.Exec(`
-		CREATE TABLE users (
-			id TEXT PRIMARY KEY,
-			name TEXT NOT NULL,
-			email TEXT NOT NULL UNIQUE,
-			avatar_url TEXT,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		);
-		CREATE TABLE orders (
-			id TEXT PRIMARY KEY,
-			user_id TEXT NOT NULL,
-			status TEXT NOT NULL DEFAULT 'pending',
-			total REAL NOT NULL,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (user_id) REFERENCES users(id)
-		);
-		CREATE TABLE items (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			order_id TEXT NOT NULL,
-			sku TEXT NOT NULL,
-			qty INTEGER NOT NULL,
-			price REAL NOT NULL,
-			FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
-		);
-	`).Error
-	require.NoError(t, err)
+// unsavedUser clears what the repository fills in when it saves a user
+func unsavedUser(u *domain.User) {
+	u.ID, u.CreatedAt = "", time.Time{}
+}
 
-	return db
+// unsavedOrder clears what the repository fills in when it saves an order
+func unsavedOrder(o *domain.Order) {
+	o.ID, o.Status, o.CreatedAt = "", "", time.Time{}
 }
 
 // TestUserRepo_Save tests user repository save operations
 func TestUserRepo_Save(t *testing.T) {
-	db := setupTestDB(t)
+	t.Parallel()
+	db := dbtest.Tx(t)
 	repo := NewUserRepo(db)
 
 	ctx := context.Background()
 
 	t.Run("save valid user", func(t *testing.T) {
-		user := &domain.User{
-			Name:  "John Doe",
-			Email: "john@example.com",
-		}
+		user := factories.User(unsavedUser)
 
 		err := repo.Save(ctx, user)
 		assert.NoError(t, err)
@@ -76,10 +49,8 @@ func TestUserRepo_Save(t *testing.T) {
 	})
 
 	t.Run("save user with invalid data", func(t *testing.T) {
-		user := &domain.User{
-			Name:  "", // Empty name - validation should happen in use case, not repo
-			Email: "invalid@example.com",
-		}
+		// Empty name - validation should happen in use case, not repo
+		user := factories.User(func(u *domain.User) { u.Name = "" })
 
 		// Repository layer doesn't validate, just persists
 		// This should succeed at repo level; validation happens in use case
@@ -88,38 +59,112 @@ func TestUserRepo_Save(t *testing.T) {
 	})
 
 	t.Run("save user with duplicate email", func(t *testing.T) {
+		// The violation aborts the transaction; the savepoint restores it
+		dbtest.Savepoint(t, db)
+
 		// First user
-		user1 := &domain.User{
-			Name:  "Jane Doe",
-			Email: "jane@example.com",
-		}
+		user1 := factories.User()
 		err := repo.Save(ctx, user1)
 		require.NoError(t, err)
 
 		// Second user with same email
-		user2 := &domain.User{
-			Name:  "Jane Smith",
-			Email: "jane@example.com", // Duplicate email
-		}
+		user2 := factories.User(func(u *domain.User) { u.Email = user1.Email })
 		err = repo.Save(ctx, user2)
-		// SQLite uses different error type, so we'll check for any error for now
-		assert.Error(t, err)
-		// TODO: Check specific error type once we determine SQLite's duplicate key error
+		// Postgres reports the unique violation as SQLSTATE 23505
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	})
+}
+
+// TestUserRepo_Update tests user repository update operations
+func TestUserRepo_Update(t *testing.T) {
+	t.Parallel()
+	db := dbtest.Tx(t)
+	repo := NewUserRepo(db)
+
+	ctx := context.Background()
+
+	ada := factories.User()
+	require.NoError(t, repo.Save(ctx, ada))
+	grace := factories.User()
+	require.NoError(t, repo.Save(ctx, grace))
+
+	t.Run("update existing user", func(t *testing.T) {
+		ada.AvatarURL = "https://cdn.example.com/avatars/ada.png"
+		require.NoError(t, repo.Update(ctx, ada))
+
+		found, err := repo.FindByID(ctx, ada.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "https://cdn.example.com/avatars/ada.png", found.AvatarURL)
+	})
+
+	t.Run("update to an email in use", func(t *testing.T) {
+		dbtest.Savepoint(t, db)
+
+		taken := *grace
+		taken.Email = ada.Email
+		assert.ErrorIs(t, repo.Update(ctx, &taken), domain.ErrConflict)
 	})
+
+	t.Run("update non-existing user", func(t *testing.T) {
+		missing := factories.User()
+		assert.ErrorIs(t, repo.Update(ctx, missing), domain.ErrNotFound)
+	})
+}
+
+// TestUserRepo_DatabaseDefaultID tests that rows written outside the
+// repository, such as by a backfill, get an id from the database
+func TestUserRepo_DatabaseDefaultID(t *testing.T) {
+	t.Parallel()
+	db := dbtest.Tx(t)
+	repo := NewUserRepo(db)
+
+	ctx := context.Background()
+	user := factories.User()
+
+	var id string
+	require.NoError(t, db.Raw("INSERT INTO users (name, email) VALUES (?, ?) RETURNING id", user.Name, user.Email).Scan(&id).Error)
+	assert.Len(t, id, 36)
+
+	found, err := repo.FindByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, user.Email, found.Email)
+}
+
+// TestUserRepo_AvatarHashesInUse tests the lookup the avatar collector keeps
+// the avatars users refer to by
+func TestUserRepo_AvatarHashesInUse(t *testing.T) {
+	t.Parallel()
+	db := dbtest.Tx(t)
+	repo := NewUserRepo(db)
+
+	ctx := context.Background()
+	shared, own, unused := strings.Repeat("a", 64), strings.Repeat("b", 64), strings.Repeat("c", 64)
+	for _, hash := range []string{shared, shared, own} {
+		user := factories.User(unsavedUser, func(u *domain.User) { u.UpdateAvatar("avatars/"+hash+".png", hash) })
+		require.NoError(t, repo.Save(ctx, user))
+	}
+	// Avatars stored before content keys have no hash
+	require.NoError(t, repo.Save(ctx, factories.User(unsavedUser)))
+
+	inUse, err := repo.AvatarHashesInUse(ctx, []string{shared, own, unused})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{shared, own}, inUse)
+
+	inUse, err = repo.AvatarHashesInUse(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, inUse)
 }
 
 // TestUserRepo_FindByID tests user repository find operations
 func TestUserRepo_FindByID(t *testing.T) {
-	db := setupTestDB(t)
+	t.Parallel()
+	db := dbtest.Tx(t)
 	repo := NewUserRepo(db)
 
 	ctx := context.Background()
 
 	// Create a test user
-	user := &domain.User{
-		Name:  "Test User",
-		Email: "test@example.com",
-	}
+	user := factories.User()
 	err := repo.Save(ctx, user)
 	require.NoError(t, err)
 	userID := user.ID
@@ -129,11 +174,13 @@ func TestUserRepo_FindByID(t *testing.T) {
 		assert.NoError(t, err)
 		assert.NotNil(t, found)
 		assert.Equal(t, userID, found.ID)
-		assert.Equal(t, "Test User", found.Name)
-		assert.Equal(t, "test@example.com", found.Email)
+		assert.Equal(t, user.Name, found.Name)
+		assert.Equal(t, user.Email, found.Email)
 	})
 
 	t.Run("find non-existing user", func(t *testing.T) {
+		dbtest.Savepoint(t, db)
+
 		found, err := repo.FindByID(ctx, "non-existing-id")
 		assert.Equal(t, usecase.ErrNotFound, err)
 		assert.Nil(t, found)
@@ -142,28 +189,20 @@ func TestUserRepo_FindByID(t *testing.T) {
 
 // TestOrderRepo_Save tests order repository save operations
 func TestOrderRepo_Save(t *testing.T) {
-	db := setupTestDB(t)
+	t.Parallel()
+	db := dbtest.Tx(t)
 	userRepo := NewUserRepo(db)
 	orderRepo := NewOrderRepo(db)
 
 	ctx := context.Background()
 
 	// Create a test user first
-	user := &domain.User{
-		Name:  "Order User",
-		Email: "order@example.com",
-	}
+	user := factories.User()
 	err := userRepo.Save(ctx, user)
 	require.NoError(t, err)
 
 	t.Run("save valid order", func(t *testing.T) {
-		order := &domain.Order{
-			UserID: user.ID,
-			Items: []domain.Item{
-				{SKU: "ITEM001", Qty: 2, Price: 10.99},
-				{SKU: "ITEM002", Qty: 1, Price: 25.50},
-			},
-		}
+		order := factories.Order(factories.ForUser(user), factories.WithItems(2), unsavedOrder)
 
 		err := orderRepo.Save(ctx, order)
 		assert.NoError(t, err)
@@ -173,25 +212,22 @@ func TestOrderRepo_Save(t *testing.T) {
 		assert.Len(t, order.Items, 2)
 	})
 
-	t.Run("save order with invalid data", func(t *testing.T) {
-		order := &domain.Order{
-			UserID: "", // Empty user ID - validation should happen in use case
-			Items:  []domain.Item{},
-		}
+	t.Run("save order for unknown user", func(t *testing.T) {
+		dbtest.Savepoint(t, db)
 
-		// Repository layer doesn't validate, just persists
-		// This should succeed at repo level; validation happens in use case
+		// The order belongs to a user id that was never saved
+		order := factories.Order()
+
+		// The repository does not validate, but the foreign key refuses the
+		// order; SQLite did not enforce it
 		err := orderRepo.Save(ctx, order)
-		assert.NoError(t, err) // Repository accepts any data
+		assert.Error(t, err)
 	})
 
 	t.Run("save order with invalid items", func(t *testing.T) {
-		order := &domain.Order{
-			UserID: user.ID,
-			Items: []domain.Item{
-				{SKU: "", Qty: 1, Price: 10.00}, // Empty SKU - validation in use case
-			},
-		}
+		order := factories.Order(factories.ForUser(user), func(o *domain.Order) {
+			o.Items[0].SKU = "" // Empty SKU - validation in use case
+		})
 
 		// Repository layer doesn't validate, just persists
 		err := orderRepo.Save(ctx, order)
@@ -201,27 +237,21 @@ func TestOrderRepo_Save(t *testing.T) {
 
 // TestOrderRepo_FindByID tests order repository find operations
 func TestOrderRepo_FindByID(t *testing.T) {
-	db := setupTestDB(t)
+	t.Parallel()
+	db := dbtest.Tx(t)
 	userRepo := NewUserRepo(db)
 	orderRepo := NewOrderRepo(db)
 
 	ctx := context.Background()
 
 	// Create a test user
-	user := &domain.User{
-		Name:  "Order Test User",
-		Email: "ordertest@example.com",
-	}
+	user := factories.User()
 	err := userRepo.Save(ctx, user)
 	require.NoError(t, err)
 
 	// Create a test order
-	order := &domain.Order{
-		UserID: user.ID,
-		Items: []domain.Item{
-			{SKU: "TEST001", Qty: 3, Price: 15.99},
-		},
-	}
+	order := factories.Order(factories.ForUser(user), factories.WithItems(1))
+	item := order.Items[0]
 	err = orderRepo.Save(ctx, order)
 	require.NoError(t, err)
 	orderID := order.ID
@@ -234,44 +264,188 @@ func TestOrderRepo_FindByID(t *testing.T) {
 		assert.Equal(t, user.ID, found.UserID)
 		assert.Equal(t, "pending", found.Status)
 		assert.Len(t, found.Items, 1)
-		assert.Equal(t, "TEST001", found.Items[0].SKU)
-		assert.Equal(t, 3, found.Items[0].Qty)
-		assert.Equal(t, 15.99, found.Items[0].Price)
+		assert.Equal(t, item.SKU, found.Items[0].SKU)
+		assert.Equal(t, item.Qty, found.Items[0].Qty)
+		assert.Equal(t, item.Price, found.Items[0].Price)
 	})
 
 	t.Run("find non-existing order", func(t *testing.T) {
+		dbtest.Savepoint(t, db)
+
 		found, err := orderRepo.FindByID(ctx, "non-existing-id")
 		assert.Equal(t, usecase.ErrNotFound, err)
 		assert.Nil(t, found)
 	})
 }
 
+// TestOrderRepo_ExistingOrderIDs tests the lookup the collector keeps the
+// attachments of existing orders by
+func TestOrderRepo_ExistingOrderIDs(t *testing.T) {
+	t.Parallel()
+	db := dbtest.Tx(t)
+	userRepo := NewUserRepo(db)
+	orderRepo := NewOrderRepo(db)
+
+	ctx := context.Background()
+	user := factories.User()
+	require.NoError(t, userRepo.Save(ctx, user))
+	order := factories.Order(factories.ForUser(user), factories.WithItems(1))
+	require.NoError(t, orderRepo.Save(ctx, order))
+
+	existing, err := orderRepo.ExistingOrderIDs(ctx, []string{order.ID, "deleted-order-id"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{order.ID}, existing)
+
+	existing, err = orderRepo.ExistingOrderIDs(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, existing)
+}
+
+// TestAttachmentRepo tests attachment repository operations
+func TestAttachmentRepo(t *testing.T) {
+	t.Parallel()
+	db := dbtest.Tx(t)
+	userRepo := NewUserRepo(db)
+	orderRepo := NewOrderRepo(db)
+	attachmentRepo := NewAttachmentRepo(db)
+
+	ctx := context.Background()
+	user := factories.User()
+	require.NoError(t, userRepo.Save(ctx, user))
+	order := factories.Order(factories.ForUser(user), factories.WithItems(1))
+	require.NoError(t, orderRepo.Save(ctx, order))
+
+	invoice := domain.NewAttachment(order.ID, "attachments/"+order.ID+"/a_invoice.pdf", "invoice.pdf", "application/pdf", 1024)
+	receipt := domain.NewAttachment(order.ID, "attachments/"+order.ID+"/b_receipt.pdf", "receipt.pdf", "", 2048)
+	receipt.CreatedAt = invoice.CreatedAt.Add(time.Second)
+	require.NoError(t, attachmentRepo.Save(ctx, invoice))
+	require.NoError(t, attachmentRepo.Save(ctx, receipt))
+
+	t.Run("find existing attachment", func(t *testing.T) {
+		found, err := attachmentRepo.FindByID(ctx, invoice.ID)
+		require.NoError(t, err)
+		assert.Equal(t, order.ID, found.OrderID)
+		assert.Equal(t, invoice.Key, found.Key)
+		assert.Equal(t, "invoice.pdf", found.Filename)
+		assert.Equal(t, "application/pdf", found.ContentType)
+		assert.EqualValues(t, 1024, found.Size)
+	})
+
+	t.Run("list the attachments of an order, oldest first", func(t *testing.T) {
+		attachments, err := attachmentRepo.ListByOrder(ctx, order.ID)
+		require.NoError(t, err)
+		if assert.Len(t, attachments, 2) {
+			assert.Equal(t, invoice.ID, attachments[0].ID)
+			assert.Equal(t, receipt.ID, attachments[1].ID)
+		}
+
+		attachments, err = attachmentRepo.ListByOrder(ctx, "other-order-id")
+		require.NoError(t, err)
+		assert.Empty(t, attachments)
+	})
+
+	t.Run("find non-existing attachment", func(t *testing.T) {
+		dbtest.Savepoint(t, db)
+
+		found, err := attachmentRepo.FindByID(ctx, "00000000-0000-0000-0000-000000000000")
+		assert.Equal(t, usecase.ErrNotFound, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("save for a non-existing order", func(t *testing.T) {
+		dbtest.Savepoint(t, db)
+
+		orphan := domain.NewAttachment("00000000-0000-0000-0000-000000000000", "attachments/gone/c.pdf", "c.pdf", "", 1)
+		assert.Equal(t, usecase.ErrNotFound, attachmentRepo.Save(ctx, orphan))
+	})
+
+	t.Run("save the same file twice", func(t *testing.T) {
+		dbtest.Savepoint(t, db)
+
+		again := domain.NewAttachment(order.ID, invoice.Key, "invoice.pdf", "application/pdf", 1024)
+		assert.Equal(t, usecase.ErrConflict, attachmentRepo.Save(ctx, again))
+	})
+}
+
+// TestAuditRepo tests audit log operations
+func TestAuditRepo(t *testing.T) {
+	t.Parallel()
+	db := dbtest.Tx(t)
+	auditRepo := NewAuditRepo(db)
+
+	ctx := context.Background()
+	start := time.Now().UTC().Truncate(time.Microsecond)
+	entry := func(id, actor, entityID string, at time.Duration) audit.Entry {
+		return audit.Entry{
+			ID:         id,
+			CreatedAt:  start.Add(at),
+			Actor:      actor,
+			RequestID:  "req-" + id[len(id)-1:],
+			Method:     "POST",
+			Route:      "/users",
+			Status:     201,
+			EntityType: "user",
+			EntityID:   entityID,
+			Action:     audit.ActionCreate,
+			Changes:    map[string]audit.Change{"email": {After: actor + "@example.com"}},
+		}
+	}
+	first := entry("00000000-0000-0000-0000-000000000001", "alice", "u-1", 0)
+	second := entry("00000000-0000-0000-0000-000000000002", "bob", "u-2", time.Second)
+	third := entry("00000000-0000-0000-0000-000000000003", "alice", "u-3", 2*time.Second)
+	require.NoError(t, auditRepo.Insert(ctx, []audit.Entry{first, second}))
+	require.NoError(t, auditRepo.Insert(ctx, []audit.Entry{third}))
+	require.NoError(t, auditRepo.Insert(ctx, nil))
+
+	list := func(filter audit.Filter) []string {
+		t.Helper()
+		entries, err := auditRepo.List(ctx, filter)
+		require.NoError(t, err)
+		ids := make([]string, len(entries))
+		for i, e := range entries {
+			ids[i] = e.ID
+		}
+		return ids
+	}
+
+	t.Run("list newest first", func(t *testing.T) {
+		entries, err := auditRepo.List(ctx, audit.Filter{})
+		require.NoError(t, err)
+		if assert.Len(t, entries, 3) {
+			assert.Equal(t, third.ID, entries[0].ID)
+			assert.True(t, third.CreatedAt.Equal(entries[0].CreatedAt))
+			assert.Equal(t, "req-3", entries[0].RequestID)
+			assert.Equal(t, map[string]audit.Change{"email": {After: "alice@example.com"}}, entries[0].Changes)
+			assert.Equal(t, first.ID, entries[2].ID)
+		}
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		assert.Equal(t, []string{third.ID, first.ID}, list(audit.Filter{Actor: "alice"}))
+		assert.Equal(t, []string{second.ID}, list(audit.Filter{EntityType: "user", EntityID: "u-2"}))
+		assert.Empty(t, list(audit.Filter{EntityType: "order"}))
+		assert.Equal(t, []string{third.ID, second.ID}, list(audit.Filter{Since: second.CreatedAt}))
+		assert.Equal(t, []string{first.ID}, list(audit.Filter{Until: second.CreatedAt}))
+		assert.Equal(t, []string{third.ID, second.ID}, list(audit.Filter{Limit: 2}))
+	})
+}
+
 // TestRepositoryIntegration tests the complete flow between repositories
 func TestRepositoryIntegration(t *testing.T) {
-	db := setupTestDB(t)
+	t.Parallel()
+	db := dbtest.Tx(t)
 	userRepo := NewUserRepo(db)
 	orderRepo := NewOrderRepo(db)
 
 	ctx := context.Background()
 
 	// Create user
-	user := &domain.User{
-		Name:  "Integration User",
-		Email: "integration@example.com",
-	}
+	user := factories.User()
 	err := userRepo.Save(ctx, user)
 	require.NoError(t, err)
 
-	// Create order for that user
-	order := &domain.Order{
-		UserID: user.ID,
-		Items: []domain.Item{
-			{SKU: "INT001", Qty: 1, Price: 99.99},
-			{SKU: "INT002", Qty: 2, Price: 49.50},
-		},
-	}
-	// Calculate total manually for the test
-	order.Total = 99.99 + (2 * 49.50) // 198.99
+	// Create order for that user; the factory calculates its total
+	order := factories.Order(factories.ForUser(user), factories.WithItems(2))
 
 	err = orderRepo.Save(ctx, order)
 	require.NoError(t, err)
@@ -281,9 +455,5 @@ func TestRepositoryIntegration(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, user.ID, foundOrder.UserID)
 	assert.Len(t, foundOrder.Items, 2)
-
-	// Calculate expected total: (1 * 99.99) + (2 * 49.50) = 99.99 + 99.00 = 198.99
-	expectedTotal := 99.99 + (2 * 49.50)
-	actualTotal := foundOrder.Total
-	assert.Equal(t, expectedTotal, actualTotal)
+	assert.Equal(t, order.Total, foundOrder.Total)
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gostratum/examples/orderservice/internal/domain"
 	"github.com/gostratum/examples/orderservice/internal/usecase"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 )
 
@@ -30,7 +31,7 @@ func (r *UserRepo) Save(ctx context.Context, user *domain.User) error {
 
 	if err := r.db.WithContext(ctx).Create(&entity).Error; err != nil {
 		// Check for unique constraint violation (duplicate email)
-		if errors.Is(err, gorm.ErrDuplicatedKey) {
+		if isUniqueViolation(err) {
 			return domain.ErrConflict
 		}
 		// Return raw error - use case layer will translate to ErrUnavailable
@@ -67,7 +68,7 @@ func (r *UserRepo) Update(ctx context.Context, user *domain.User) error {
 	result := r.db.WithContext(ctx).Where("id = ?", user.ID).Updates(&entity)
 	if result.Error != nil {
 		// Check for unique constraint violation (duplicate email)
-		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+		if isUniqueViolation(result.Error) {
 			return domain.ErrConflict
 		}
 		// Return raw error - use case layer will translate to ErrUnavailable
@@ -81,3 +82,27 @@ func (r *UserRepo) Update(ctx context.Context, user *domain.User) error {
 
 	return nil
 }
+
+// AvatarHashesInUse returns those of hashes some user's avatar has
+func (r *UserRepo) AvatarHashesInUse(ctx context.Context, hashes []string) ([]string, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+	var inUse []string
+	err := r.db.WithContext(ctx).Model(&UserEntity{}).
+		Distinct("avatar_hash").
+		Where("avatar_hash IN ?", hashes).
+		Pluck("avatar_hash", &inUse).Error
+	if err != nil {
+		// Return raw error - use case layer will translate to ErrUnavailable
+		return nil, err
+	}
+	return inUse, nil
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation,
+// whether or not GORM's TranslateError turned it into gorm.ErrDuplicatedKey
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.Is(err, gorm.ErrDuplicatedKey) || (errors.As(err, &pgErr) && pgErr.Code == "23505")
+}
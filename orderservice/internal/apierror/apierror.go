@@ -0,0 +1,177 @@
+// Package apierror is the catalog of the errors the API answers with: each
+// machine-readable code, the HTTP status it comes with and its default
+// message. Handlers answer errors through Respond, so a code always has the
+// same status, and GET /errors lists the catalog for client tooling. Clients
+// branch on the code, which is stable, never on the message, which may
+// change or carry details.
+//
+// Messages are answered in the language of the request, which i18n.Middleware
+// picks from Accept-Language: they are keys of the catalogs in locales, one
+// per language, English being the fallback. A code's message is under the
+// code.
+package apierror
+
+import (
+	"embed"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/i18n"
+)
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// Messages are the catalogs of the API's messages: en, es and de
+var Messages = i18n.MustNew(locales, "locales", "en")
+
+// Code is a machine-readable error code
+type Code string
+
+// The codes of the catalog
+const (
+	InvalidRequest     Code = "INVALID_REQUEST"
+	InvalidInput       Code = "INVALID_INPUT"
+	MissingParameter   Code = "MISSING_PARAMETER"
+	UserNotFound       Code = "USER_NOT_FOUND"
+	EmailTaken         Code = "EMAIL_TAKEN"
+	OrderNotFound      Code = "ORDER_NOT_FOUND"
+	OutOfStock         Code = "OUT_OF_STOCK"
+	PaymentDeclined    Code = "PAYMENT_DECLINED"
+	InvalidFile        Code = "INVALID_FILE"
+	InvalidFileType    Code = "INVALID_FILE_TYPE"
+	FileTooLarge       Code = "FILE_TOO_LARGE"
+	FileInfected       Code = "FILE_INFECTED"
+	UploadFailed       Code = "UPLOAD_FAILED"
+	UploadNotFound     Code = "UPLOAD_NOT_FOUND"
+	InvalidPart        Code = "INVALID_PART"
+	UploadIncomplete   Code = "UPLOAD_INCOMPLETE"
+	AttachmentNotFound Code = "ATTACHMENT_NOT_FOUND"
+	ScanUnavailable    Code = "SCAN_UNAVAILABLE"
+	InvalidAdminToken  Code = "INVALID_ADMIN_TOKEN"
+	ServiceUnavailable Code = "SERVICE_UNAVAILABLE"
+	InternalError      Code = "INTERNAL_ERROR"
+)
+
+// Entry describes a code of the catalog
+type Entry struct {
+	Code Code `json:"code"`
+	// Status is the HTTP status the code is answered with
+	Status int `json:"status"`
+	// Message is the message answered unless the handler gives one with
+	// details, such as the limit a file exceeds; in English, unless the
+	// entry comes from CatalogIn
+	Message string `json:"message"`
+	// Description says when the code is answered, for client developers
+	Description string `json:"description"`
+	// RetryAfter, in seconds, is sent as Retry-After when the request may
+	// succeed if retried
+	RetryAfter int `json:"retry_after,omitempty"`
+}
+
+// catalog lists every code, grouped by resource. The messages are the
+// English catalog's.
+var catalog = withMessages(Messages.Fallback(), []Entry{
+	{Code: InvalidRequest, Status: 400, Description: "The body is not JSON, or lacks a required field."},
+	{Code: InvalidInput, Status: 400, Description: "The request is well formed, but a value is invalid, such as an empty name or a negative quantity; the message says which."},
+	{Code: MissingParameter, Status: 400, Description: "A path parameter, such as the id, is empty."},
+
+	{Code: UserNotFound, Status: 404, Description: "No user has the id."},
+	{Code: EmailTaken, Status: 409, Description: "Another user has the email."},
+
+	{Code: OrderNotFound, Status: 404, Description: "No order has the id."},
+	{Code: OutOfStock, Status: 409, Description: "inventoryservice could not reserve an item; the message names the SKU."},
+	{Code: PaymentDeclined, Status: 402, Description: "paymentservice declined the charge; the message gives the reason."},
+
+	{Code: InvalidFile, Status: 400, Description: "The form has no file, or the file cannot be read or is not a valid image."},
+	{Code: InvalidFileType, Status: 400, Description: "The content type is not among the allowed types of the upload."},
+	{Code: FileTooLarge, Status: 400, Description: "The file exceeds the size limit, which the message gives."},
+	{Code: FileInfected, Status: 422, Description: "The malware scanner flagged the file, which was not stored."},
+	{Code: UploadFailed, Status: 500, Description: "Storing the file failed; retrying may succeed."},
+	{Code: UploadNotFound, Status: 404, Description: "No upload session has the id, or it expired."},
+	{Code: InvalidPart, Status: 400, Description: "The part number is not an integer, or the part has the wrong size; the message says which."},
+	{Code: UploadIncomplete, Status: 409, Description: "The session is completed before all its parts were uploaded; the message says which are missing."},
+	{Code: AttachmentNotFound, Status: 404, Description: "No attachment of the order has the id."},
+
+	{Code: InvalidAdminToken, Status: 401, Description: "An admin endpoint, such as GET /admin/audit, was called without audit.admin_token."},
+
+	{Code: ScanUnavailable, Status: 503, Description: "The malware scanner did not answer; files are stored only once scanned.", RetryAfter: 2},
+	{Code: ServiceUnavailable, Status: 503, Description: "The database or a service the request needs is unavailable.", RetryAfter: 2},
+	{Code: InternalError, Status: 500, Description: "An unexpected error, logged by the service."},
+})
+
+// withMessages sets the message of each entry in lang
+func withMessages(lang string, entries []Entry) []Entry {
+	for i, e := range entries {
+		entries[i].Message = Messages.Message(lang, string(e.Code))
+	}
+	return entries
+}
+
+// byCode indexes the catalog
+var byCode = func() map[Code]Entry {
+	m := make(map[Code]Entry, len(catalog))
+	for _, e := range catalog {
+		m[e.Code] = e
+	}
+	return m
+}()
+
+// Catalog returns every entry of the catalog
+func Catalog() []Entry {
+	return append([]Entry(nil), catalog...)
+}
+
+// CatalogIn returns every entry of the catalog, with its message in lang
+func CatalogIn(lang string) []Entry {
+	return withMessages(lang, Catalog())
+}
+
+// Lookup returns the entry of code
+func Lookup(code Code) (Entry, bool) {
+	e, ok := byCode[code]
+	return e, ok
+}
+
+// Respond answers code with its status and default message
+func Respond(c *gin.Context, code Code) {
+	RespondMessage(c, code, "")
+}
+
+// RespondMessage answers code with its status and the message of key, with
+// its placeholders replaced by args, pairs of names and values; see
+// i18n.Bundle.Message. An empty key answers the default message. A code
+// missing from the catalog is answered as INTERNAL_ERROR.
+func RespondMessage(c *gin.Context, code Code, key string, args ...string) {
+	e, ok := Lookup(code)
+	if !ok {
+		e = byCode[InternalError]
+	}
+	if key == "" {
+		key = string(e.Code)
+	}
+	message := Messages.Message(locale(c), key, args...)
+	if e.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(e.RetryAfter))
+	}
+	responsex.Error(c, e.Status, string(e.Code), message, nil)
+}
+
+// Detail returns what err adds to sentinel, such as the SKU of "out of stock:
+// LAPTOP", for a message of the catalogs that takes it, or false when err
+// adds nothing. It comes from the error, so it is not translated.
+func Detail(err, sentinel error) (string, bool) {
+	_, detail, ok := strings.Cut(err.Error(), sentinel.Error()+": ")
+	return detail, ok && detail != ""
+}
+
+// locale returns the language of the request, "" for the fallback
+func locale(c *gin.Context) string {
+	if c.Request == nil {
+		return ""
+	}
+	return i18n.Locale(c.Request.Context())
+}
@@ -0,0 +1,132 @@
+package apierror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/i18n"
+)
+
+func TestCatalog(t *testing.T) {
+	codes := []Code{
+		InvalidRequest, InvalidInput, MissingParameter,
+		UserNotFound, EmailTaken,
+		OrderNotFound, OutOfStock, PaymentDeclined,
+		InvalidFile, InvalidFileType, FileTooLarge, FileInfected, UploadFailed,
+		UploadNotFound, InvalidPart, UploadIncomplete, AttachmentNotFound,
+		InvalidAdminToken,
+		ScanUnavailable, ServiceUnavailable, InternalError,
+	}
+	assert.Len(t, Catalog(), len(codes), "every code is in the catalog once")
+	assert.Len(t, byCode, len(codes), "codes are unique")
+
+	for _, code := range codes {
+		e, ok := Lookup(code)
+		if assert.True(t, ok, code) {
+			assert.GreaterOrEqual(t, e.Status, 400, code)
+			assert.NotEmpty(t, e.Message, code)
+			assert.NotEmpty(t, e.Description, code)
+		}
+	}
+}
+
+// respond answers with f and returns the response
+func respond(f func(c *gin.Context)) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	f(c)
+	return w
+}
+
+// errorOf returns the code and message of the response
+func errorOf(t *testing.T, w *httptest.ResponseRecorder) (string, string) {
+	t.Helper()
+	var body struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return body.Error.Code, body.Error.Message
+}
+
+func TestRespond(t *testing.T) {
+	w := respond(func(c *gin.Context) { Respond(c, UserNotFound) })
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, w.Header().Get("Retry-After"))
+	code, message := errorOf(t, w)
+	assert.Equal(t, "USER_NOT_FOUND", code)
+	assert.Equal(t, "user not found", message)
+
+	w = respond(func(c *gin.Context) { Respond(c, ServiceUnavailable) })
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+
+	w = respond(func(c *gin.Context) { RespondMessage(c, FileTooLarge, "file_too_large", "limit", "5 MB") })
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	_, message = errorOf(t, w)
+	assert.Equal(t, "file size exceeds 5 MB limit", message)
+
+	w = respond(func(c *gin.Context) { Respond(c, "NOT_IN_CATALOG") })
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	code, _ = errorOf(t, w)
+	assert.Equal(t, "INTERNAL_ERROR", code, "an unknown code is answered as an internal error")
+}
+
+func TestMessages(t *testing.T) {
+	assert.Equal(t, []string{"en", "de", "es"}, Messages.Languages())
+	for _, lang := range Messages.Languages() {
+		assert.Empty(t, Messages.Missing(lang), "every message is translated into %s", lang)
+	}
+	for _, e := range Catalog() {
+		assert.True(t, Messages.Has(string(e.Code)), "%s has a message", e.Code)
+	}
+}
+
+func TestRespond_InTheLanguageOfTheRequest(t *testing.T) {
+	inLocale := func(lang string, f func(c *gin.Context)) func(c *gin.Context) {
+		return func(c *gin.Context) {
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil).WithContext(i18n.WithLocale(context.Background(), lang))
+			f(c)
+		}
+	}
+
+	w := respond(inLocale("es", func(c *gin.Context) { Respond(c, UserNotFound) }))
+	code, message := errorOf(t, w)
+	assert.Equal(t, "USER_NOT_FOUND", code, "codes are never translated")
+	assert.Equal(t, "usuario no encontrado", message)
+
+	w = respond(inLocale("de", func(c *gin.Context) { RespondMessage(c, FileTooLarge, "file_too_large", "limit", "5 MB") }))
+	_, message = errorOf(t, w)
+	assert.Equal(t, "Dateigröße überschreitet das Limit von 5 MB", message)
+
+	de := CatalogIn("de")
+	assert.Equal(t, "Bestellung nicht gefunden", de[5].Message)
+	assert.Equal(t, "order not found", Catalog()[5].Message, "the catalog itself stays in English")
+}
+
+func TestDetail(t *testing.T) {
+	outOfStock := errors.New("out of stock")
+
+	detail, ok := Detail(fmt.Errorf("%w: LAPTOP", outOfStock), outOfStock)
+	assert.True(t, ok)
+	assert.Equal(t, "LAPTOP", detail)
+
+	detail, ok = Detail(fmt.Errorf("reserve: %w: LAPTOP", outOfStock), outOfStock)
+	assert.True(t, ok)
+	assert.Equal(t, "LAPTOP", detail, "wrapped")
+
+	_, ok = Detail(outOfStock, outOfStock)
+	assert.False(t, ok, "nothing added")
+}
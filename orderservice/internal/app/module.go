@@ -0,0 +1,112 @@
+// Package app wires orderservice's repositories, clients, services and routes, so
+// cmd/api and the end-to-end tests compose the same graph.
+package app
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/examples/orderservice/configs"
+	clamavAdapter "github.com/gostratum/examples/orderservice/internal/adapter/clamav"
+	healthAdapter "github.com/gostratum/examples/orderservice/internal/adapter/health"
+	httpAdapter "github.com/gostratum/examples/orderservice/internal/adapter/http"
+	inventoryAdapter "github.com/gostratum/examples/orderservice/internal/adapter/inventory"
+	paymentAdapter "github.com/gostratum/examples/orderservice/internal/adapter/payment"
+	repoAdapter "github.com/gostratum/examples/orderservice/internal/adapter/repo"
+	"github.com/gostratum/examples/orderservice/internal/buckets"
+	"github.com/gostratum/examples/orderservice/internal/buildinfo"
+	"github.com/gostratum/examples/orderservice/internal/requestid"
+	"github.com/gostratum/examples/orderservice/internal/tasks"
+	"github.com/gostratum/examples/orderservice/internal/uploads"
+	"github.com/gostratum/examples/orderservice/internal/usecase"
+	"github.com/gostratum/examples/workkit/workqueue"
+)
+
+// Providers lists the constructors of the service's components
+var Providers = []any{
+	// Typed configuration, checked before anything is built with it
+	configs.Load,
+
+	// GORM repositories. They also tell the collector which avatars are in
+	// use and which orders attachments are stored for still exist.
+	fx.Annotate(repoAdapter.NewUserRepo, fx.As(fx.Self()), fx.As(new(uploads.AvatarReferences))),
+	fx.Annotate(repoAdapter.NewOrderRepo, fx.As(fx.Self()), fx.As(new(uploads.AttachmentReferences))),
+	repoAdapter.NewAttachmentRepo,
+	repoAdapter.NewAuditRepo,
+	// The standby they fail over to, when failover is enabled
+	repoAdapter.NewFailover,
+
+	// inventoryservice and paymentservice clients
+	inventoryAdapter.NewClient,
+	paymentAdapter.NewClient,
+
+	// Storage clients for avatars, invoices and exports
+	buckets.NewClients,
+
+	// Malware scanner for uploads; none without clamav.address
+	clamavAdapter.NewScanner,
+
+	// Usecase services
+	usecase.NewUserService,
+	usecase.NewOrderService,
+	usecase.NewAttachmentService,
+
+	// HTTP handlers
+	httpAdapter.NewUserHandler,
+	httpAdapter.NewOrderHandler,
+	httpAdapter.NewUploadHandler,
+	httpAdapter.NewAuditHandler,
+	// Request metrics of every route
+	httpAdapter.NewHTTPMetrics,
+
+	// Avatar thumbnails, made on the worker pool after the upload
+	fx.Annotate(tasks.NewAvatarThumbnailScheduler, fx.As(new(httpAdapter.ThumbnailScheduler))),
+	workqueue.AsHandler(tasks.NewAvatarThumbnailHandler),
+
+	// The audit log, written on the worker pool after the request
+	fx.Annotate(tasks.NewAuditScheduler, fx.As(new(httpAdapter.AuditScheduler))),
+	workqueue.AsHandler(tasks.NewAuditEntriesHandler),
+}
+
+// Decorators wrap the repositories with the failover, when it is enabled
+var Decorators = []any{
+	repoAdapter.WithFailoverUsers,
+	repoAdapter.WithFailoverOrders,
+	repoAdapter.WithFailoverAttachments,
+	repoAdapter.WithFailoverAudit,
+	repoAdapter.WithFailoverAvatarReferences,
+	repoAdapter.WithFailoverAttachmentReferences,
+}
+
+// Invokes lists the setup functions
+var Invokes = []any{
+	healthAdapter.RegisterMigrationCheck,
+	healthAdapter.RegisterStorageCheck,
+	// First, so the time spent in the other middleware is measured too
+	httpAdapter.RegisterMetricsMiddleware,
+	// Before the routes, so their errors are answered in the client's language
+	httpAdapter.RegisterLocaleMiddleware,
+	// Before the routes, so its middleware sees them all
+	httpAdapter.RegisterAuditRoutes,
+	httpAdapter.RegisterRoutes,
+	httpAdapter.RegisterUploadRoutes,
+	httpAdapter.RegisterVersionRoute,
+	httpAdapter.RegisterErrorsRoute,
+	requestid.RegisterSQLComments,
+	buildinfo.RegisterMetrics,
+	repoAdapter.RegisterFailover,
+}
+
+// Module wires the service, its worker pool, its upload sessions and the
+// collection of unused avatars and attachments.
+// Infrastructure (dbx, httpx, storagex, metricsx and the database secret) is
+// left to the caller; without metricsx neither the pool nor the routes record
+// metrics, and the build is not exported as build_info.
+func Module() fx.Option {
+	return fx.Options(
+		workqueue.Module(),
+		uploads.Module(),
+		fx.Provide(Providers...),
+		fx.Decorate(Decorators...),
+		fx.Invoke(Invokes...),
+	)
+}
@@ -0,0 +1,134 @@
+// Package audit records who did what through the API: each request that
+// changes something, with the actor who made it, its route and outcome, and
+// for each entity it changed, the fields before and after. The HTTP
+// middleware starts a trail for the request, the usecases Record the
+// entities they change on it, and the entries are written to the audit_log
+// table after the request is answered.
+package audit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Actions of an entry
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Anonymous is the actor of requests that name none
+const Anonymous = "anonymous"
+
+// Entry is a line of the audit log
+type Entry struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	// Actor is who made the request, as the gateway in front of the service
+	// names them, or Anonymous
+	Actor     string `json:"actor"`
+	RequestID string `json:"request_id,omitempty"`
+	Method    string `json:"method"`
+	// Route is the route's pattern, such as /users/:id/avatar
+	Route  string `json:"route"`
+	Status int    `json:"status"`
+	// EntityType, EntityID and Action name what the request changed, and
+	// are empty when it changed nothing, such as a rejected request
+	EntityType string `json:"entity_type,omitempty"`
+	EntityID   string `json:"entity_id,omitempty"`
+	Action     string `json:"action,omitempty"`
+	// Changes holds the fields that changed, by name
+	Changes map[string]Change `json:"changes,omitempty"`
+}
+
+// Change is a field's value before and after a request; Before is nil for an
+// entity created, and After for one deleted
+type Change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// Filter selects entries, newest first
+type Filter struct {
+	Actor      string
+	EntityType string
+	EntityID   string
+	// Since and Until bound CreatedAt, Until excluded; zero for no bound
+	Since time.Time
+	Until time.Time
+	Limit int
+}
+
+// Store keeps the entries
+type Store interface {
+	Insert(ctx context.Context, entries []Entry) error
+	List(ctx context.Context, filter Filter) ([]Entry, error)
+}
+
+// change is an entity a request changed
+type change struct {
+	entityType string
+	entityID   string
+	action     string
+	changes    map[string]Change
+}
+
+// Trail collects the changes of a request
+type Trail struct {
+	mu      sync.Mutex
+	changes []change
+}
+
+type contextKey struct{}
+
+// WithTrail returns ctx with a new trail, which Record adds to
+func WithTrail(ctx context.Context) (context.Context, *Trail) {
+	t := &Trail{}
+	return context.WithValue(ctx, contextKey{}, t), t
+}
+
+// Record adds an entity changed to the trail of ctx: created when before is
+// nil, deleted when after is nil, updated otherwise. before and after are
+// values or pointers to structs, such as a *domain.User; only the fields that
+// differ are kept. It does nothing outside a request, such as in a
+// background task.
+func Record(ctx context.Context, entityType, entityID string, before, after any) {
+	t, ok := ctx.Value(contextKey{}).(*Trail)
+	if !ok {
+		return
+	}
+	action := ActionUpdate
+	switch {
+	case isNil(before):
+		action = ActionCreate
+	case isNil(after):
+		action = ActionDelete
+	}
+	changes := Diff(before, after)
+	if action == ActionUpdate && len(changes) == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.changes = append(t.changes, change{entityType: entityType, entityID: entityID, action: action, changes: changes})
+}
+
+// Entries returns an entry for each change of the trail, made from request,
+// or request alone when nothing changed
+func (t *Trail) Entries(request Entry) []Entry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.changes) == 0 {
+		return []Entry{request}
+	}
+	entries := make([]Entry, len(t.changes))
+	for i, c := range t.changes {
+		entry := request
+		entry.EntityType, entry.EntityID, entry.Action, entry.Changes = c.entityType, c.entityID, c.action, c.changes
+		entries[i] = entry
+	}
+	return entries
+}
@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type item struct {
+	SKU string
+	Qty int
+}
+
+type order struct {
+	ID        string
+	UserID    string
+	AvatarURL string
+	Items     []item
+	CreatedAt time.Time
+	internal  string
+}
+
+func TestDiff(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	before := &order{ID: "o-1", UserID: "u-1", Items: []item{{SKU: "A", Qty: 1}}, CreatedAt: created, internal: "x"}
+	after := *before
+	after.AvatarURL = "avatars/ab.png"
+	after.internal = "y"
+
+	assert.Equal(t, map[string]Change{
+		"avatar_url": {Before: "", After: "avatars/ab.png"},
+	}, Diff(before, &after), "only the exported fields that changed")
+
+	assert.Equal(t, map[string]Change{
+		"id":         {After: "o-1"},
+		"user_id":    {After: "u-1"},
+		"avatar_url": {After: ""},
+		"items":      {After: []any{map[string]any{"sku": "A", "qty": 1}}},
+		"created_at": {After: "2024-05-01T12:00:00Z"},
+	}, Diff(nil, before), "every field of an entity created")
+
+	assert.Equal(t, "o-1", Diff(before, (*order)(nil))["id"].Before, "every field of an entity deleted")
+	assert.Empty(t, Diff(before, before))
+}
+
+func TestSnakeCase(t *testing.T) {
+	for name, want := range map[string]string{
+		"ID":          "id",
+		"UserID":      "user_id",
+		"AvatarURL":   "avatar_url",
+		"ContentType": "content_type",
+		"HTTPStatus":  "http_status",
+		"SHA256Hash":  "sha256_hash",
+	} {
+		assert.Equal(t, want, snakeCase(name), name)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	request := Entry{Actor: "alice", Method: "POST", Route: "/users", Status: 201}
+
+	// Outside a request, nothing is recorded, and nothing fails
+	Record(context.Background(), "user", "u-1", nil, &order{ID: "u-1"})
+
+	ctx, trail := WithTrail(context.Background())
+	assert.Equal(t, []Entry{request}, trail.Entries(request), "a request that changed nothing is recorded alone")
+
+	Record(ctx, "user", "u-1", nil, &order{ID: "u-1"})
+	Record(ctx, "user", "u-1", &order{ID: "u-1"}, &order{ID: "u-1"})
+	Record(ctx, "user", "u-1", &order{ID: "u-1"}, &order{ID: "u-1", AvatarURL: "a.png"})
+	Record(ctx, "order", "o-1", &order{ID: "o-1"}, nil)
+
+	entries := trail.Entries(request)
+	if assert.Len(t, entries, 3, "an update that changed nothing is not recorded") {
+		assert.Equal(t, ActionCreate, entries[0].Action)
+		assert.Equal(t, ActionUpdate, entries[1].Action)
+		assert.Equal(t, map[string]Change{"avatar_url": {Before: "", After: "a.png"}}, entries[1].Changes)
+		assert.Equal(t, ActionDelete, entries[2].Action)
+		assert.Equal(t, "order", entries[2].EntityType)
+		assert.Equal(t, "o-1", entries[2].EntityID)
+		for _, entry := range entries {
+			assert.Equal(t, "alice", entry.Actor)
+			assert.Equal(t, "/users", entry.Route)
+		}
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, Config{Enabled: true, ActorHeader: "X-Actor"}.Validate())
+	assert.NoError(t, Config{}.Validate(), "the header is not needed when disabled")
+	assert.EqualError(t, Config{Enabled: true, ActorHeader: "X-Actor: alice"}.Validate(), `audit.actor_header must be a header name, got "X-Actor: alice"`)
+}
@@ -0,0 +1,37 @@
+package audit
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Config is the audit section
+type Config struct {
+	// Enabled records the requests that change something; GET requests are
+	// never recorded
+	Enabled bool `mapstructure:"enabled" default:"true"`
+	// ActorHeader carries who makes a request. The gateway in front of the
+	// service sets it once it has authenticated the caller; the service does
+	// not authenticate callers itself.
+	ActorHeader string `mapstructure:"actor_header" default:"X-Actor"`
+	// AdminToken authenticates GET /admin/audit, sent in X-Admin-Token. The
+	// endpoint is not served without one. Set it through the environment in
+	// anything but development.
+	AdminToken string `mapstructure:"admin_token"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "audit"
+}
+
+// headerName matches the header names ActorHeader takes
+var headerName = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// Validate checks the actor header is a header name
+func (c Config) Validate() error {
+	if c.Enabled && !headerName.MatchString(c.ActorHeader) {
+		return fmt.Errorf("audit.actor_header must be a header name, got %q", c.ActorHeader)
+	}
+	return nil
+}
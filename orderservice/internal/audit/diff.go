@@ -0,0 +1,109 @@
+package audit
+
+import (
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Diff returns the fields of before and after that differ, named in
+// snake_case as the API names them: AvatarURL is avatar_url. Either may be
+// nil; the other's fields are then all returned.
+func Diff(before, after any) map[string]Change {
+	from, to := fields(before), fields(after)
+	changes := map[string]Change{}
+	for name, value := range from {
+		if other, ok := to[name]; !ok || !reflect.DeepEqual(value, other) {
+			changes[name] = Change{Before: value, After: other}
+		}
+	}
+	for name, value := range to {
+		if _, ok := from[name]; !ok {
+			changes[name] = Change{After: value}
+		}
+	}
+	return changes
+}
+
+// fields returns the exported fields of the struct v points to, or is
+func fields(v any) map[string]any {
+	if isNil(v) {
+		return nil
+	}
+	value, ok := plain(reflect.ValueOf(v)).(map[string]any)
+	if !ok {
+		return nil
+	}
+	return value
+}
+
+// plain returns v as JSON would encode it, with structs as maps of their
+// exported fields in snake_case, so entries compare and store alike
+func plain(v reflect.Value) any {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		m := make(map[string]any, v.NumField())
+		for i := range v.NumField() {
+			if field := v.Type().Field(i); field.IsExported() {
+				m[snakeCase(field.Name)] = plain(v.Field(i))
+			}
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		s := make([]any, v.Len())
+		for i := range s {
+			s[i] = plain(v.Index(i))
+		}
+		return s
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		m := make(map[string]any, v.Len())
+		for _, key := range v.MapKeys() {
+			m[key.String()] = plain(v.MapIndex(key))
+		}
+		return m
+	}
+	return v.Interface()
+}
+
+// snakeCase turns a Go name into snake_case, keeping initialisms together:
+// UserID is user_id and AvatarURL avatar_url
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			previous := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// isNil reports whether v is nil, or a nil pointer
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	value := reflect.ValueOf(v)
+	return value.Kind() == reflect.Pointer && value.IsNil()
+}
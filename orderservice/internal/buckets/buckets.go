@@ -0,0 +1,133 @@
+// Package buckets gives each kind of stored file a storage client of its
+// own, so avatars, invoices and exports can live in buckets or under
+// prefixes with their own lifecycle rules and access policies: avatars are
+// public and kept for good, invoices private and kept for the retention
+// period, exports private and short-lived. Clients are named, as dbx names
+// its connections, and configured under storagex.clients:
+//
+//	storagex:
+//	  bucket: "orderservice-avatars"
+//	  clients:
+//	    avatars: {}
+//	    invoices:
+//	      prefix: "private/invoices/"
+//	    exports:
+//	      bucket: "orderservice-exports"
+//
+// A client without a bucket uses storagex.bucket, the one storagex opens; a
+// client with a prefix sees the keys under it only, and stores its keys
+// under it. Without any configuration, every client is storagex.bucket as it
+// is, so the keys are those the service always used.
+package buckets
+
+import (
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/storagex"
+	"go.uber.org/fx"
+)
+
+// Names of the clients the service uses
+const (
+	// Avatars holds avatars and their thumbnails
+	Avatars = "avatars"
+	// Invoices holds order attachments, such as purchase orders and receipts
+	Invoices = "invoices"
+	// Exports holds files the service generates for download
+	Exports = "exports"
+)
+
+// names are the clients NewClients always provides
+var names = []string{Avatars, Invoices, Exports}
+
+// Config is the part of the storagex section that names the clients
+type Config struct {
+	// Bucket is the bucket storagex opens
+	Bucket  string                  `mapstructure:"bucket"`
+	Clients map[string]ClientConfig `mapstructure:"clients"`
+}
+
+// ClientConfig places the files of one client
+type ClientConfig struct {
+	// Bucket holds the client's files; empty for storagex.bucket
+	Bucket string `mapstructure:"bucket"`
+	// Prefix the client's keys are stored under within the bucket, ending in
+	// a slash; empty for the whole bucket
+	Prefix string `mapstructure:"prefix"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "storagex"
+}
+
+// Clients maps client names to their storage. It always has Avatars,
+// Invoices and Exports.
+type Clients map[string]storagex.Storage
+
+// Opener opens the storage of a bucket other than storagex.bucket. The
+// local provider opens a directory per bucket; storagex opens one bucket
+// only, so with S3 every client is in storagex.bucket.
+type Opener func(bucket string) (storagex.Storage, error)
+
+// Params are the dependencies of NewClients
+type Params struct {
+	fx.In
+
+	Loader configx.Loader
+	// Storage is storagex.bucket
+	Storage storagex.Storage
+	// Open is missing when the provider opens one bucket only
+	Open Opener `optional:"true"`
+}
+
+// NewClients loads storagex.clients and opens every client, and fails when
+// one is not valid, so a mistake stops the service at startup rather than
+// storing files where their lifecycle rules do not apply
+func NewClients(p Params) (Clients, error) {
+	var cfg Config
+	if err := p.Loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load storagex config: %w", err)
+	}
+	return Open(cfg, p.Storage, p.Open)
+}
+
+// Open opens the clients cfg configures over storage, storagex.bucket, and
+// the other buckets open opens, which may be nil
+func Open(cfg Config, storage storagex.Storage, open Opener) (Clients, error) {
+	for name := range cfg.Clients {
+		if !slices.Contains(names, name) {
+			return nil, fmt.Errorf("storagex.clients.%s is not a client; the clients are %s", name, strings.Join(names, ", "))
+		}
+	}
+
+	opened := map[string]storagex.Storage{}
+	clients := Clients{}
+	for _, name := range names {
+		client := cfg.Clients[name]
+		if client.Prefix != "" && (!strings.HasSuffix(client.Prefix, "/") || !fs.ValidPath(strings.TrimSuffix(client.Prefix, "/"))) {
+			return nil, fmt.Errorf("storagex.clients.%s.prefix must be a relative directory ending in a slash, got %q", name, client.Prefix)
+		}
+
+		bucket := storage
+		if client.Bucket != "" && client.Bucket != cfg.Bucket {
+			if open == nil {
+				return nil, fmt.Errorf("storagex.clients.%s.bucket: the storage provider opens storagex.bucket only; give the client a prefix in it instead", name)
+			}
+			if bucket = opened[client.Bucket]; bucket == nil {
+				var err error
+				if bucket, err = open(client.Bucket); err != nil {
+					return nil, fmt.Errorf("failed to open bucket %q of storagex.clients.%s: %w", client.Bucket, name, err)
+				}
+				opened[client.Bucket] = bucket
+			}
+		}
+
+		clients[name] = WithPrefix(bucket, client.Prefix)
+	}
+	return clients, nil
+}
@@ -0,0 +1,148 @@
+package buckets_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gostratum/storagex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/orderservice/internal/buckets"
+	"github.com/gostratum/examples/orderservice/internal/testutil"
+)
+
+func TestOpen_Default(t *testing.T) {
+	storage := testutil.NewMemoryStorage()
+
+	clients, err := buckets.Open(buckets.Config{Bucket: "orders"}, storage, nil)
+	require.NoError(t, err)
+
+	assert.Len(t, clients, 3)
+	for _, name := range []string{buckets.Avatars, buckets.Invoices, buckets.Exports} {
+		assert.Same(t, storage, clients[name], "%s keeps the keys of storagex.bucket", name)
+	}
+}
+
+func TestOpen_Buckets(t *testing.T) {
+	storage := testutil.NewMemoryStorage()
+	opened := map[string]*testutil.MemoryStorage{}
+	open := func(bucket string) (storagex.Storage, error) {
+		if bucket == "broken" {
+			return nil, errors.New("no such bucket")
+		}
+		opened[bucket] = testutil.NewMemoryStorage()
+		return opened[bucket], nil
+	}
+
+	clients, err := buckets.Open(buckets.Config{
+		Bucket: "orders",
+		Clients: map[string]buckets.ClientConfig{
+			buckets.Avatars:  {Bucket: "orders"},
+			buckets.Invoices: {Bucket: "private", Prefix: "invoices/"},
+			buckets.Exports:  {Bucket: "private", Prefix: "exports/"},
+		},
+	}, storage, open)
+	require.NoError(t, err)
+
+	assert.Same(t, storage, clients[buckets.Avatars], "storagex.bucket is not opened again")
+	require.Len(t, opened, 1, "a bucket is opened once")
+	ctx := context.Background()
+	for name, client := range clients {
+		_, err := client.Put(ctx, "a.pdf", strings.NewReader(name), nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"a.pdf"}, storage.Keys())
+	assert.Equal(t, []string{"exports/a.pdf", "invoices/a.pdf"}, opened["private"].Keys())
+
+	_, err = buckets.Open(buckets.Config{Clients: map[string]buckets.ClientConfig{
+		buckets.Exports: {Bucket: "broken"},
+	}}, storage, open)
+	assert.ErrorContains(t, err, `failed to open bucket "broken" of storagex.clients.exports: no such bucket`)
+}
+
+func TestOpen_Invalid(t *testing.T) {
+	open := func(string) (storagex.Storage, error) { return testutil.NewMemoryStorage(), nil }
+
+	tests := []struct {
+		name    string
+		clients map[string]buckets.ClientConfig
+		open    buckets.Opener
+		wantErr string
+	}{
+		{
+			name:    "unknown client",
+			clients: map[string]buckets.ClientConfig{"reports": {}},
+			open:    open,
+			wantErr: "storagex.clients.reports is not a client; the clients are avatars, invoices, exports",
+		},
+		{
+			name:    "prefix without a slash",
+			clients: map[string]buckets.ClientConfig{buckets.Invoices: {Prefix: "invoices"}},
+			open:    open,
+			wantErr: "storagex.clients.invoices.prefix must be a relative directory ending in a slash",
+		},
+		{
+			name:    "prefix out of the bucket",
+			clients: map[string]buckets.ClientConfig{buckets.Exports: {Prefix: "../exports/"}},
+			open:    open,
+			wantErr: "storagex.clients.exports.prefix must be a relative directory ending in a slash",
+		},
+		{
+			name:    "bucket without an opener",
+			clients: map[string]buckets.ClientConfig{buckets.Exports: {Bucket: "exports"}},
+			wantErr: "storagex.clients.exports.bucket: the storage provider opens storagex.bucket only",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buckets.Open(buckets.Config{Bucket: "orders", Clients: tt.clients}, testutil.NewMemoryStorage(), tt.open)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestWithPrefix(t *testing.T) {
+	ctx := context.Background()
+	storage := testutil.NewMemoryStorage()
+	_, err := storage.Put(ctx, "outside.pdf", strings.NewReader("outside"), nil)
+	require.NoError(t, err)
+	client := buckets.WithPrefix(storage, "invoices/")
+
+	stat, err := client.Put(ctx, "o1/a.pdf", strings.NewReader("invoice"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "o1/a.pdf", stat.Key)
+	_, err = client.Put(ctx, "o2/b.pdf", strings.NewReader("receipt"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"invoices/o1/a.pdf", "invoices/o2/b.pdf", "outside.pdf"}, storage.Keys())
+
+	body, stat, err := client.Get(ctx, "o1/a.pdf")
+	require.NoError(t, err)
+	data, _ := io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, "invoice", string(data))
+	assert.Equal(t, "o1/a.pdf", stat.Key)
+
+	stat, err = client.Head(ctx, "o2/b.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, "o2/b.pdf", stat.Key)
+
+	page, err := client.List(ctx, storagex.ListOptions{})
+	require.NoError(t, err)
+	var keys []string
+	for _, stat := range page.Keys {
+		keys = append(keys, stat.Key)
+	}
+	assert.Equal(t, []string{"o1/a.pdf", "o2/b.pdf"}, keys, "keys outside the prefix are not listed")
+
+	require.NoError(t, client.Delete(ctx, "o1/a.pdf"))
+	_, err = client.Head(ctx, "outside.pdf")
+	assert.ErrorIs(t, err, storagex.ErrNotFound)
+	assert.Equal(t, []string{"invoices/o2/b.pdf", "outside.pdf"}, storage.Keys())
+
+	assert.Same(t, storage, buckets.WithPrefix(storage, ""))
+}
@@ -0,0 +1,67 @@
+package buckets
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/gostratum/storagex"
+)
+
+// prefixed is a storagex.Storage over the keys under a prefix of another.
+// Keys go in without the prefix and come out without it. Only the methods
+// orderservice calls are narrowed; the others reach the whole bucket.
+type prefixed struct {
+	storagex.Storage
+	prefix string
+}
+
+// WithPrefix returns storage narrowed to the keys under prefix, or storage
+// itself for an empty prefix
+func WithPrefix(storage storagex.Storage, prefix string) storagex.Storage {
+	if prefix == "" {
+		return storage
+	}
+	return &prefixed{Storage: storage, prefix: prefix}
+}
+
+// Put implements storagex.Storage
+func (s *prefixed) Put(ctx context.Context, key string, r io.Reader, opts *storagex.PutOptions) (storagex.Stat, error) {
+	stat, err := s.Storage.Put(ctx, s.prefix+key, r, opts)
+	return s.trim(stat), err
+}
+
+// Get implements storagex.Storage
+func (s *prefixed) Get(ctx context.Context, key string) (io.ReadCloser, storagex.Stat, error) {
+	body, stat, err := s.Storage.Get(ctx, s.prefix+key)
+	return body, s.trim(stat), err
+}
+
+// Head implements storagex.Storage
+func (s *prefixed) Head(ctx context.Context, key string) (storagex.Stat, error) {
+	stat, err := s.Storage.Head(ctx, s.prefix+key)
+	return s.trim(stat), err
+}
+
+// List implements storagex.Storage
+func (s *prefixed) List(ctx context.Context, opts storagex.ListOptions) (storagex.ListPage, error) {
+	opts.Prefix = s.prefix + opts.Prefix
+	page, err := s.Storage.List(ctx, opts)
+	for i := range page.Keys {
+		page.Keys[i] = s.trim(page.Keys[i])
+	}
+	for i, p := range page.CommonPrefixes {
+		page.CommonPrefixes[i] = strings.TrimPrefix(p, s.prefix)
+	}
+	return page, err
+}
+
+// Delete implements storagex.Storage
+func (s *prefixed) Delete(ctx context.Context, key string) error {
+	return s.Storage.Delete(ctx, s.prefix+key)
+}
+
+func (s *prefixed) trim(stat storagex.Stat) storagex.Stat {
+	stat.Key = strings.TrimPrefix(stat.Key, s.prefix)
+	return stat
+}
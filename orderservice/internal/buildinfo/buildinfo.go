@@ -0,0 +1,137 @@
+// Package buildinfo tells which build of the service runs: its version,
+// commit and build date, set at link time with
+//
+//	go build -ldflags "-X github.com/gostratum/examples/orderservice/internal/buildinfo.Version=v1.4.0 \
+//	  -X github.com/gostratum/examples/orderservice/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/gostratum/examples/orderservice/internal/buildinfo.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// as make build does. Without them, the commit and date come from the VCS
+// information go build stamps into binaries built in a git checkout.
+package buildinfo
+
+import (
+	"cmp"
+	"os"
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	"github.com/gostratum/core/logx"
+)
+
+// Set with -ldflags "-X ..."; see the package documentation
+var (
+	Version string
+	Commit  string
+	Date    string
+)
+
+// Unknown stands for a value neither the linker nor the VCS information set
+const Unknown = "unknown"
+
+// DevVersion is the version of builds without one, such as go run
+const DevVersion = "dev"
+
+// Info describes the build of the service
+type Info struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+	Date    string `json:"date"`
+}
+
+var (
+	once sync.Once
+	info Info
+)
+
+// Get returns the build of the running binary
+func Get() Info {
+	once.Do(func() {
+		info = read(Version, Commit, Date, debug.ReadBuildInfo)
+	})
+	return info
+}
+
+// read fills what the linker left unset from the VCS information, then with
+// DevVersion and Unknown
+func read(version, commit, date string, buildInfo func() (*debug.BuildInfo, bool)) Info {
+	var revision, revisionTime string
+	modified := false
+	if bi, ok := buildInfo(); ok {
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				revision = setting.Value
+			case "vcs.time":
+				revisionTime = setting.Value
+			case "vcs.modified":
+				modified = setting.Value == "true"
+			}
+		}
+	}
+	if revision != "" && modified {
+		revision += "-dirty"
+	}
+	return Info{
+		Version: cmp.Or(version, DevVersion),
+		Commit:  cmp.Or(commit, revision, Unknown),
+		Date:    cmp.Or(date, revisionTime, Unknown),
+	}
+}
+
+// Fields returns the build as log fields
+func (i Info) Fields() []logx.Field {
+	return []logx.Field{
+		logx.String("version", i.Version),
+		logx.String("commit", i.Commit),
+		logx.String("date", i.Date),
+	}
+}
+
+// Log logs the build the service starts with. It is meant for fx.Invoke.
+func Log(log logx.Logger) {
+	log.Info("build", Get().Fields()...)
+}
+
+// resourceAttributes is the variable the OpenTelemetry SDKs read resource
+// attributes from, as key=value pairs separated by commas
+const resourceAttributes = "OTEL_RESOURCE_ATTRIBUTES"
+
+// SetResourceAttributes adds the build to OTEL_RESOURCE_ATTRIBUTES, so the
+// traces and metrics an OpenTelemetry SDK exports carry it: service.version,
+// vcs.ref.head.revision and build.date. Attributes the environment already
+// sets are kept. It runs before the application is built, as the SDKs read
+// the variable when their provider is created.
+func SetResourceAttributes() error {
+	return os.Setenv(resourceAttributes, withBuild(os.Getenv(resourceAttributes), Get()))
+}
+
+// withBuild adds the attributes of build to attributes, those set winning
+func withBuild(attributes string, build Info) string {
+	set := map[string]bool{}
+	pairs := []string{}
+	for _, pair := range strings.Split(attributes, ",") {
+		key, _, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		set[strings.TrimSpace(key)] = true
+		pairs = append(pairs, pair)
+	}
+	for _, attribute := range [][2]string{
+		{"service.version", build.Version},
+		{"vcs.ref.head.revision", build.Commit},
+		{"build.date", build.Date},
+	} {
+		if !set[attribute[0]] {
+			pairs = append(pairs, attribute[0]+"="+escape(attribute[1]))
+		}
+	}
+	return strings.Join(pairs, ",")
+}
+
+// escape percent-encodes the characters that would end a value, as the
+// OpenTelemetry specification asks
+func escape(value string) string {
+	return strings.NewReplacer("%", "%25", ",", "%2C", "=", "%3D", " ", "%20").Replace(value)
+}
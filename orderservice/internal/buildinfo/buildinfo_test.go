@@ -0,0 +1,76 @@
+package buildinfo
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stamped is the VCS information of a binary built in a git checkout
+func stamped(modified string) func() (*debug.BuildInfo, bool) {
+	return func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{Settings: []debug.BuildSetting{
+			{Key: "vcs", Value: "git"},
+			{Key: "vcs.revision", Value: "4f2c9e1"},
+			{Key: "vcs.time", Value: "2024-05-01T12:00:00Z"},
+			{Key: "vcs.modified", Value: modified},
+		}}, true
+	}
+}
+
+func unstamped() (*debug.BuildInfo, bool) {
+	return nil, false
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name            string
+		version, commit string
+		date            string
+		buildInfo       func() (*debug.BuildInfo, bool)
+		want            Info
+	}{
+		{
+			name:      "set by the linker",
+			version:   "v1.4.0",
+			commit:    "9b8a7c6",
+			date:      "2024-06-01T08:30:00Z",
+			buildInfo: stamped("false"),
+			want:      Info{Version: "v1.4.0", Commit: "9b8a7c6", Date: "2024-06-01T08:30:00Z"},
+		},
+		{
+			name:      "from the VCS information",
+			buildInfo: stamped("false"),
+			want:      Info{Version: DevVersion, Commit: "4f2c9e1", Date: "2024-05-01T12:00:00Z"},
+		},
+		{
+			name:      "from a checkout with changes",
+			buildInfo: stamped("true"),
+			want:      Info{Version: DevVersion, Commit: "4f2c9e1-dirty", Date: "2024-05-01T12:00:00Z"},
+		},
+		{
+			name:      "without either",
+			buildInfo: unstamped,
+			want:      Info{Version: DevVersion, Commit: Unknown, Date: Unknown},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, read(tt.version, tt.commit, tt.date, tt.buildInfo))
+		})
+	}
+}
+
+func TestWithBuild(t *testing.T) {
+	build := Info{Version: "v1.4.0", Commit: "9b8a7c6", Date: "2024-06-01T08:30:00Z"}
+
+	assert.Equal(t, "service.version=v1.4.0,vcs.ref.head.revision=9b8a7c6,build.date=2024-06-01T08:30:00Z",
+		withBuild("", build))
+	assert.Equal(t, "deployment.environment=staging, service.version=v1.4.0-rc.1,vcs.ref.head.revision=9b8a7c6,build.date=2024-06-01T08:30:00Z",
+		withBuild("deployment.environment=staging, service.version=v1.4.0-rc.1", build),
+		"attributes the environment sets are kept")
+	assert.Equal(t, "service.version=v1%2C%20a%3Db%25,vcs.ref.head.revision=9b8a7c6,build.date=2024-06-01T08:30:00Z",
+		withBuild("", Info{Version: "v1, a=b%", Commit: build.Commit, Date: build.Date}),
+		"separators in values are escaped")
+}
@@ -0,0 +1,30 @@
+package buildinfo
+
+import (
+	"github.com/gostratum/metricsx"
+	"go.uber.org/fx"
+)
+
+// MetricsParams are the dependencies of RegisterMetrics
+type MetricsParams struct {
+	fx.In
+
+	// Metrics is missing when the application runs without metricsx.Module,
+	// as the test apps do
+	Metrics metricsx.Metrics `optional:"true"`
+}
+
+// RegisterMetrics exports the build as the build_info gauge, always 1, with
+// the build in its labels; a query joins it on the instance to see which
+// build served a request. This function is designed to be used with
+// fx.Invoke.
+func RegisterMetrics(p MetricsParams) {
+	if p.Metrics == nil {
+		return
+	}
+	build := Get()
+	p.Metrics.Gauge("build_info",
+		metricsx.WithHelp("The build of the service: its version, commit and build date"),
+		metricsx.WithLabels("version", "commit", "date"),
+	).Set(1, build.Version, build.Commit, build.Date)
+}
@@ -0,0 +1,103 @@
+// Package debug serves introspection endpoints for developers under /debug:
+//
+//   - GET /debug/config: the configuration as the loader sees it, with
+//     secrets redacted
+//   - GET /debug/goroutines: the stack of every goroutine
+//   - GET /debug/fx: the fx dependency graph, in DOT or as a page rendering it
+//
+// They tell more about the service than any client should know, so only
+// cmd/api built with the debug tag includes them:
+//
+//	go run -tags debug ./cmd/api
+package debug
+
+import (
+	"maps"
+	"net/http"
+	"reflect"
+	"runtime/pprof"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"go.uber.org/fx"
+)
+
+// Module serves the debug endpoints, the config one dumping sections, such as
+// configs.App{}. Each request binds them anew, so the dump shows what the
+// loader reads now, environment overrides included.
+func Module(sections ...configx.Configurable) fx.Option {
+	return fx.Module("debug",
+		fx.Supply(Sections(sections)),
+		fx.Invoke(Register),
+	)
+}
+
+// Sections are the config sections /debug/config dumps
+type Sections []configx.Configurable
+
+// Register serves the debug endpoints on e. This function is designed to be
+// used with fx.Invoke.
+func Register(e *gin.Engine, loader configx.Loader, sections Sections, graph fx.DotGraph, log logx.Logger) {
+	g := e.Group("/debug")
+	g.GET("/config", configHandler(loader, sections))
+	g.GET("/goroutines", goroutinesHandler)
+	g.GET("/fx", graphHandler(graph))
+	log.Warn("debug endpoints are served under /debug; this build is not for production")
+}
+
+// binder is the part of configx.Loader that the config dump needs
+type binder interface {
+	Bind(v any) error
+}
+
+// configHandler answers the sections bound by loader, by prefix. Sections of
+// one prefix, such as the storagex of configs.Storage and buckets.Config, are
+// merged.
+func configHandler(loader binder, sections Sections) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		dump := make(map[string]map[string]any, len(sections))
+		for _, section := range sections {
+			// A pointer to a zero value of the section's type, for Bind to fill
+			v := reflect.New(reflect.Indirect(reflect.ValueOf(section)).Type())
+			if err := loader.Bind(v.Interface()); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load " + section.Prefix() + " config: " + err.Error()})
+				return
+			}
+			fields, _ := Redacted(v.Elem().Interface()).(map[string]any)
+			if dump[section.Prefix()] == nil {
+				dump[section.Prefix()] = map[string]any{}
+			}
+			maps.Copy(dump[section.Prefix()], fields)
+		}
+		c.IndentedJSON(http.StatusOK, dump)
+	}
+}
+
+// goroutinesHandler writes the stack of every goroutine, as a panic does;
+// ?debug=1 groups goroutines with the same stack instead
+func goroutinesHandler(c *gin.Context) {
+	level := 2
+	if n, err := strconv.Atoi(c.Query("debug")); err == nil && n >= 0 {
+		level = n
+	}
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Status(http.StatusOK)
+	_ = pprof.Lookup("goroutine").WriteTo(c.Writer, level)
+}
+
+// graphHandler answers the dependency graph in DOT, for
+//
+//	curl -s localhost:8080/debug/fx | dot -Tsvg > graph.svg
+//
+// or ?format=html, a page that renders it in the browser
+func graphHandler(graph fx.DotGraph) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Query("format") == "html" {
+			c.Data(http.StatusOK, "text/html; charset=utf-8", graphPage(graph))
+			return
+		}
+		c.Data(http.StatusOK, "text/vnd.graphviz; charset=utf-8", []byte(graph))
+	}
+}
@@ -0,0 +1,38 @@
+package debug
+
+import (
+	"bytes"
+	"html/template"
+
+	"go.uber.org/fx"
+)
+
+// graphTemplate renders the DOT of the graph with Graphviz compiled to
+// WebAssembly, loaded from a CDN. The template escapes the graph as a
+// JavaScript string.
+var graphTemplate = template.Must(template.New("graph").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>orderservice: fx dependency graph</title>
+<style>body { font-family: sans-serif; margin: 1rem; } svg { max-width: 100%; height: auto; }</style>
+</head>
+<body>
+<p>Constructors and the types they provide. Decorators, such as the failover of the repositories, are not shown.</p>
+<script src="https://cdn.jsdelivr.net/npm/@viz-js/viz@3.7.0/lib/viz-standalone.js"></script>
+<script>
+Viz.instance().then(function (viz) {
+  document.body.appendChild(viz.renderSVGElement({{.}}));
+});
+</script>
+</body>
+</html>
+`))
+
+// graphPage returns the page rendering graph
+func graphPage(graph fx.DotGraph) []byte {
+	var b bytes.Buffer
+	// Executing with a string cannot fail
+	_ = graphTemplate.Execute(&b, string(graph))
+	return b.Bytes()
+}
@@ -0,0 +1,86 @@
+package debug
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gostratum/examples/redact"
+)
+
+// Redacted returns section as a map of its mapstructure keys, as it would be
+// written in YAML, with the secrets masked: whole values under keys naming a
+// secret, such as admin_token, and the passwords of DSNs, tokens and emails
+// in the others, as the logs mask them. Durations are written as in YAML, 5m0s.
+func Redacted(section any) any {
+	return redacted("", reflect.ValueOf(section))
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func redacted(key string, v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return redacted(key, v.Elem())
+	case reflect.String:
+		return redact.Value(key, v.String())
+	case reflect.Struct:
+		fields := map[string]any{}
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			name, ok := fieldKey(field)
+			if !ok {
+				continue
+			}
+			fields[name] = redacted(name, v.Field(i))
+		}
+		return fields
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return v.Interface()
+		}
+		entries := make(map[string]any, v.Len())
+		for it := v.MapRange(); it.Next(); {
+			name := it.Key().String()
+			entries[name] = redacted(name, it.Value())
+		}
+		return entries
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return []any{}
+		}
+		items := make([]any, v.Len())
+		for i := range items {
+			// Items take the key of the slice, so a list of tokens is masked
+			items[i] = redacted(key, v.Index(i))
+		}
+		return items
+	}
+	return v.Interface()
+}
+
+// fieldKey returns the key of a field as configx binds it: its mapstructure
+// tag, or its name in lower case. Unexported fields and those tagged "-",
+// which configx does not bind, are left out.
+func fieldKey(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return strings.ToLower(field.Name), true
+	}
+	return name, true
+}
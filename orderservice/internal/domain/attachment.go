@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Attachment is a document attached to an order, such as a purchase order or
+// a receipt. The file is in object storage at Key.
+type Attachment struct {
+	ID      string
+	OrderID string
+	// Key the file is stored at
+	Key string
+	// Filename is the name the client gave the file, without its directory
+	Filename    string
+	ContentType string
+	// Size of the file in bytes
+	Size      int64
+	CreatedAt time.Time
+}
+
+// NewAttachment creates a new attachment with a generated ID
+func NewAttachment(orderID, key, filename, contentType string, size int64) *Attachment {
+	return &Attachment{
+		ID:          uuid.New().String(),
+		OrderID:     orderID,
+		Key:         key,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// Validate performs basic validation on attachment fields
+func (a *Attachment) Validate() error {
+	if a.OrderID == "" {
+		return invalid("order_id_required", "order_id is required")
+	}
+	if a.Key == "" {
+		return invalid("key_required", "key is required")
+	}
+	if strings.TrimSpace(a.Filename) == "" {
+		return invalid("filename_required", "filename is required")
+	}
+	if a.Size < 0 {
+		return invalid("size_negative", "size cannot be negative")
+	}
+	return nil
+}
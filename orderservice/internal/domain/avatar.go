@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"path"
+	"strings"
+)
+
+// AvatarOriginal names the uploaded avatar among its variants
+const AvatarOriginal = "original"
+
+// AvatarVariant is a thumbnail made of every avatar
+type AvatarVariant struct {
+	// Name is the variant's key in the avatar URLs and part of its storage key
+	Name string
+	// Size is the longest edge in pixels
+	Size int
+}
+
+// AvatarVariants are the thumbnails of an avatar, smallest first
+var AvatarVariants = []AvatarVariant{
+	{Name: "small", Size: 64},
+	{Name: "medium", Size: 256},
+}
+
+// avatarThumbnailExts maps the extensions of avatars that get thumbnails to
+// the extension of the thumbnails. JPEGs stay JPEGs; PNGs and GIFs become PNGs,
+// which keep their transparency. Other formats, such as WebP, get none.
+var avatarThumbnailExts = map[string]string{
+	".jpg":  ".jpg",
+	".jpeg": ".jpg",
+	".png":  ".png",
+	".gif":  ".png",
+}
+
+// HasAvatarThumbnails reports whether the avatar at key gets thumbnails
+func HasAvatarThumbnails(key string) bool {
+	_, ok := avatarThumbnailExts[strings.ToLower(path.Ext(key))]
+	return ok
+}
+
+// AvatarVariantKey returns the key of a thumbnail of the avatar at key, in
+// thumbnails/<variant>/ under the avatar's file name, e.g.
+// thumbnails/small/u1_1700000000.png for avatars/u1_1700000000.gif. The key
+// follows from the avatar's alone, so it is known before the thumbnail exists.
+func AvatarVariantKey(key string, variant AvatarVariant) string {
+	ext := path.Ext(key)
+	name := strings.TrimSuffix(path.Base(key), ext)
+	return AvatarVariantPrefix(variant) + name + avatarThumbnailExts[strings.ToLower(ext)]
+}
+
+// AvatarVariantPrefix returns the prefix the thumbnails of a variant are
+// stored under
+func AvatarVariantPrefix(variant AvatarVariant) string {
+	return "thumbnails/" + variant.Name + "/"
+}
+
+// AvatarURLs returns the URL of the avatar and of each thumbnail, by variant
+// name, or nil when the user has no avatar. The thumbnails are made after the
+// upload, so for a moment their URLs lead nowhere.
+func (u *User) AvatarURLs() map[string]string {
+	if u.AvatarURL == "" {
+		return nil
+	}
+	urls := map[string]string{AvatarOriginal: u.AvatarURL}
+	if !HasAvatarThumbnails(u.AvatarURL) {
+		return urls
+	}
+	for _, v := range AvatarVariants {
+		urls[v.Name] = AvatarVariantKey(u.AvatarURL, v)
+	}
+	return urls
+}
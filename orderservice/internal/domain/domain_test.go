@@ -1,6 +1,9 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -189,3 +192,96 @@ func TestOrderTotal(t *testing.T) {
 		t.Errorf("Order.Total = %v, want %v", actual, expected)
 	}
 }
+
+func TestUserAvatarURLs(t *testing.T) {
+	tests := []struct {
+		name   string
+		avatar string
+		want   map[string]string
+	}{
+		{
+			name:   "no avatar",
+			avatar: "",
+			want:   nil,
+		},
+		{
+			name:   "JPEG",
+			avatar: "avatars/u1_1700000000.jpeg",
+			want: map[string]string{
+				"original": "avatars/u1_1700000000.jpeg",
+				"small":    "thumbnails/small/u1_1700000000.jpg",
+				"medium":   "thumbnails/medium/u1_1700000000.jpg",
+			},
+		},
+		{
+			name:   "GIF gets PNG thumbnails",
+			avatar: "avatars/u1_1700000000.GIF",
+			want: map[string]string{
+				"original": "avatars/u1_1700000000.GIF",
+				"small":    "thumbnails/small/u1_1700000000.png",
+				"medium":   "thumbnails/medium/u1_1700000000.png",
+			},
+		},
+		{
+			name:   "WebP gets no thumbnails",
+			avatar: "avatars/u1_1700000000.webp",
+			want:   map[string]string{"original": "avatars/u1_1700000000.webp"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := User{ID: "u1", AvatarURL: tt.avatar}
+			if got := user.AvatarURLs(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("User.AvatarURLs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttachmentValidate(t *testing.T) {
+	valid := func() Attachment {
+		return *NewAttachment("order123", "attachments/order123/a_invoice.pdf", "invoice.pdf", "application/pdf", 1024)
+	}
+	tests := []struct {
+		name    string
+		change  func(a *Attachment)
+		wantErr bool
+	}{
+		{name: "valid attachment", change: func(a *Attachment) {}},
+		{name: "empty file", change: func(a *Attachment) { a.Size = 0 }},
+		{name: "empty order id", change: func(a *Attachment) { a.OrderID = "" }, wantErr: true},
+		{name: "empty key", change: func(a *Attachment) { a.Key = "" }, wantErr: true},
+		{name: "blank filename", change: func(a *Attachment) { a.Filename = "  " }, wantErr: true},
+		{name: "negative size", change: func(a *Attachment) { a.Size = -1 }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := valid()
+			tt.change(&a)
+			err := a.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Attachment.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	err := NewUser("Ada", "ada").Validate()
+
+	var invalid *ValidationError
+	if !errors.As(fmt.Errorf("create user: %w", err), &invalid) {
+		t.Fatalf("Validate() error = %T, want *ValidationError", err)
+	}
+	if invalid.Key != "email_invalid" || err.Error() != "email format is invalid" {
+		t.Errorf("Validate() error = %q (%s), want email_invalid", err, invalid.Key)
+	}
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Error("a ValidationError is ErrInvalidInput")
+	}
+	if errors.Is(err, ErrConflict) {
+		t.Error("a ValidationError is only ErrInvalidInput")
+	}
+}
@@ -13,3 +13,25 @@ var (
 	// ErrConflict indicates a conflict with existing data (e.g., duplicate email)
 	ErrConflict = errors.New("resource conflict")
 )
+
+// ValidationError is a value that breaks a rule of the domain, such as an
+// empty name. It is ErrInvalidInput; Key names the rule, so the API can
+// answer it in the client's language.
+type ValidationError struct {
+	Key     string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// Is makes errors.Is(err, ErrInvalidInput) hold
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidInput
+}
+
+// invalid returns the ValidationError of key
+func invalid(key, message string) error {
+	return &ValidationError{Key: key, Message: message}
+}
@@ -1,7 +1,6 @@
 package domain
 
 import (
-	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -42,13 +41,13 @@ func NewOrder(userID string) *Order {
 // AddItem adds an item to the order with validation
 func (o *Order) AddItem(item Item) error {
 	if item.SKU == "" {
-		return errors.New("item SKU is required")
+		return invalid("sku_required", "item SKU is required")
 	}
 	if item.Qty <= 0 {
-		return errors.New("item quantity must be positive")
+		return invalid("quantity_not_positive", "item quantity must be positive")
 	}
 	if item.Price < 0 {
-		return errors.New("item price cannot be negative")
+		return invalid("price_negative", "item price cannot be negative")
 	}
 
 	o.Items = append(o.Items, item)
@@ -64,11 +63,11 @@ func (o *Order) AddItem(item Item) error {
 // Item-level validation is already done in AddItem(), so this only validates order-level rules
 func (o *Order) Validate() error {
 	if o.UserID == "" {
-		return errors.New("user_id is required")
+		return invalid("user_id_required", "user_id is required")
 	}
 
 	if len(o.Items) == 0 {
-		return errors.New("order must have at least one item")
+		return invalid("items_required", "order must have at least one item")
 	}
 
 	// Note: Individual item validation (SKU, Qty, Price) happens in AddItem()
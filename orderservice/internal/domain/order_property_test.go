@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+// Property tests for Order.Total. rapid generates the orders, and when a
+// property fails it shrinks the input to the smallest order that still
+// breaks it. Run more cases with -rapid.checks=10000.
+
+// genItem generates valid items. Prices are whole cents, as they are entered
+// in the API, up to 10,000.00.
+var genItem = rapid.Custom(func(t *rapid.T) Item {
+	return Item{
+		SKU:   rapid.StringMatching(`[A-Z]{3}-[0-9]{4}`).Draw(t, "sku"),
+		Qty:   rapid.IntRange(1, 1000).Draw(t, "qty"),
+		Price: float64(rapid.IntRange(0, 1_000_000).Draw(t, "cents")) / 100,
+	}
+})
+
+// genInvalidItem generates items that AddItem rejects
+var genInvalidItem = rapid.Custom(func(t *rapid.T) Item {
+	item := genItem.Draw(t, "item")
+	switch rapid.IntRange(0, 2).Draw(t, "defect") {
+	case 0:
+		item.SKU = ""
+	case 1:
+		item.Qty = rapid.IntRange(-1000, 0).Draw(t, "qty")
+	default:
+		item.Price = -float64(rapid.IntRange(1, 1_000_000).Draw(t, "cents")) / 100
+	}
+	return item
+})
+
+// sum is Σ(price×qty) of items, in their order
+func sum(items []Item) float64 {
+	var total float64
+	for _, item := range items {
+		total += item.Price * float64(item.Qty)
+	}
+	return total
+}
+
+// addAll adds items to a new order, failing t on the first error
+func addAll(t *rapid.T, items []Item) *Order {
+	order := NewOrder("user123")
+	for _, item := range items {
+		if err := order.AddItem(item); err != nil {
+			t.Fatalf("AddItem(%+v) = %v", item, err)
+		}
+	}
+	return order
+}
+
+// approxEqual reports whether a and b are equal up to float64 rounding,
+// which depends on the order of the additions
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+
+func TestOrderTotal_IsSumOfItems(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		items := rapid.SliceOf(genItem).Draw(t, "items")
+
+		order := addAll(t, items)
+
+		if order.Total != sum(items) {
+			t.Fatalf("Total = %v, want Σ(price×qty) = %v", order.Total, sum(items))
+		}
+		if len(order.Items) != len(items) {
+			t.Fatalf("got %d items, want %d", len(order.Items), len(items))
+		}
+	})
+}
+
+func TestOrderTotal_IsNonNegative(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		order := addAll(t, rapid.SliceOf(genItem).Draw(t, "items"))
+
+		if order.Total < 0 || math.IsNaN(order.Total) || math.IsInf(order.Total, 0) {
+			t.Fatalf("Total = %v, want a finite amount ≥ 0", order.Total)
+		}
+	})
+}
+
+func TestOrderTotal_IndependentOfItemOrder(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		items := rapid.SliceOf(genItem).Draw(t, "items")
+		shuffled := rapid.Permutation(items).Draw(t, "shuffled")
+
+		a, b := addAll(t, items), addAll(t, shuffled)
+
+		if !approxEqual(a.Total, b.Total) {
+			t.Fatalf("Total = %v in one order and %v in another", a.Total, b.Total)
+		}
+	})
+}
+
+func TestOrderTotal_RecalculationIsIdempotent(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		items := rapid.SliceOf(genItem).Draw(t, "items")
+		order := addAll(t, items)
+
+		// Rebuilding the order from its own items gives the same total, and
+		// so does every prefix: each AddItem recalculates from all the items
+		rebuilt := addAll(t, order.Items)
+		if rebuilt.Total != order.Total {
+			t.Fatalf("rebuilt Total = %v, want %v", rebuilt.Total, order.Total)
+		}
+		for n := range len(items) + 1 {
+			if prefix := addAll(t, items[:n]); prefix.Total != sum(items[:n]) {
+				t.Fatalf("Total after %d items = %v, want %v", n, prefix.Total, sum(items[:n]))
+			}
+		}
+	})
+}
+
+func TestOrderTotal_RejectedItemChangesNothing(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		order := addAll(t, rapid.SliceOf(genItem).Draw(t, "items"))
+		total, count := order.Total, len(order.Items)
+
+		invalid := genInvalidItem.Draw(t, "invalid")
+		if err := order.AddItem(invalid); err == nil {
+			t.Fatalf("AddItem(%+v) accepted an invalid item", invalid)
+		}
+
+		if order.Total != total || len(order.Items) != count {
+			t.Fatalf("after a rejected item: Total = %v with %d items, want %v with %d",
+				order.Total, len(order.Items), total, count)
+		}
+	})
+}
@@ -1,7 +1,6 @@
 package domain
 
 import (
-	"errors"
 	"strings"
 	"time"
 
@@ -15,7 +14,10 @@ type User struct {
 	Name      string
 	Email     string
 	AvatarURL string
-	CreatedAt time.Time
+	// AvatarHash is the hex SHA-256 of the avatar, empty for avatars stored
+	// before keys were derived from it
+	AvatarHash string
+	CreatedAt  time.Time
 }
 
 // NewUser creates a new user with a generated ID
@@ -29,24 +31,26 @@ func NewUser(name, email string) *User {
 	}
 }
 
-// UpdateAvatar updates the user's avatar URL
-func (u *User) UpdateAvatar(avatarURL string) {
+// UpdateAvatar updates the user's avatar URL, and the hash of the avatar's
+// content
+func (u *User) UpdateAvatar(avatarURL, hash string) {
 	u.AvatarURL = avatarURL
+	u.AvatarHash = hash
 }
 
 // Validate performs basic validation on user fields
 func (u *User) Validate() error {
 	if strings.TrimSpace(u.Name) == "" {
-		return errors.New("name is required")
+		return invalid("name_required", "name is required")
 	}
 
 	if strings.TrimSpace(u.Email) == "" {
-		return errors.New("email is required")
+		return invalid("email_required", "email is required")
 	}
 
 	// Basic email validation
 	if !strings.Contains(u.Email, "@") || !strings.Contains(u.Email, ".") {
-		return errors.New("email format is invalid")
+		return invalid("email_invalid", "email format is invalid")
 	}
 
 	return nil
@@ -0,0 +1,13 @@
+// Package mocks holds gomock mocks of the usecase ports, generated by mockgen
+// from internal/usecase. Regenerate them after changing a port:
+//
+//	go generate ./internal/mocks
+//
+// Tests that need a port to behave like the real thing, such as remembering
+// what was saved, give a mock DoAndReturn, or use the fakes in
+// internal/testutil.
+package mocks
+
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -source=../usecase/repositories.go -destination=repositories.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -source=../usecase/inventory.go -destination=inventory.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -source=../usecase/payment.go -destination=payment.go -package=mocks
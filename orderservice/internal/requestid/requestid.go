@@ -0,0 +1,96 @@
+// Package requestid gives each request an id, taken from its X-Request-ID
+// header or generated, and carries it where the request goes: into the
+// response, the logs of its handler, the calls to inventoryservice and
+// paymentservice, and, as a comment, the SQL it runs. A request can then be
+// followed from a client's report to the database's logs.
+package requestid
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gostratum/core/logx"
+)
+
+// Header carries the request id, in requests and responses
+const Header = "X-Request-ID"
+
+// Key is the key of the request id among the values of a gin context, and its
+// field in logs
+const Key = "request_id"
+
+// valid matches the ids accepted from clients. Anything else, which could
+// forge log lines or end the SQL comment, is replaced by a new id.
+var valid = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+type contextKey struct{}
+
+// WithID returns ctx carrying id
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request id ctx carries, or "" outside a request. A
+// gin context carries the id of its request.
+func FromContext(ctx context.Context) string {
+	if c, ok := ctx.(*gin.Context); ok {
+		if c.Request == nil {
+			return ""
+		}
+		ctx = c.Request.Context()
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Middleware takes the request id from the X-Request-ID header, or generates
+// one, and puts it in the request's context and the response's header. Use it
+// before responsex.MetaMiddleware, which takes the id from the header for the
+// meta of the response.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(Header)
+		if !valid.MatchString(id) {
+			id = uuid.NewString()
+			c.Request.Header.Set(Header, id)
+		}
+		c.Request = c.Request.WithContext(WithID(c.Request.Context(), id))
+		c.Set(Key, id)
+		c.Header(Header, id)
+		c.Next()
+	}
+}
+
+// Field returns the log field of the request id ctx carries
+func Field(ctx context.Context) logx.Field {
+	return logx.String(Key, FromContext(ctx))
+}
+
+// Transport returns an http.RoundTripper that sends the request id of each
+// request's context in its X-Request-ID header, over base, or
+// http.DefaultTransport when base is nil
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return transport{base: base}
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	id := FromContext(req.Context())
+	if id == "" || req.Header.Get(Header) != "" {
+		return t.base.RoundTrip(req)
+	}
+	// A RoundTripper must not modify the request it is given
+	req = req.Clone(req.Context())
+	req.Header.Set(Header, id)
+	return t.base.RoundTrip(req)
+}
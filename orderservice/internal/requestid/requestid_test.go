@@ -0,0 +1,86 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serve sends a request with the X-Request-ID header id, if any, through
+// Middleware, and returns the response and the id the handler saw
+func serve(t *testing.T, id string) (*httptest.ResponseRecorder, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	e := gin.New()
+	var seen string
+	e.Use(Middleware())
+	e.GET("/", func(c *gin.Context) {
+		seen = FromContext(c)
+		assert.Equal(t, seen, FromContext(c.Request.Context()))
+		assert.Equal(t, seen, c.GetString(Key))
+		assert.Equal(t, seen, c.GetHeader(Header), "the middlewares after see the id in the header")
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if id != "" {
+		req.Header.Set(Header, id)
+	}
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	return w, seen
+}
+
+func TestMiddleware(t *testing.T) {
+	w, seen := serve(t, "edge-7f3a:1")
+	assert.Equal(t, "edge-7f3a:1", seen, "the client's id is kept")
+	assert.Equal(t, "edge-7f3a:1", w.Header().Get(Header))
+
+	w, seen = serve(t, "")
+	assert.NoError(t, uuid.Validate(seen), "an id is generated")
+	assert.Equal(t, seen, w.Header().Get(Header))
+
+	for _, id := range []string{"a*/ DROP TABLE users; --", "id'", "line\nbreak", string(make([]byte, 129))} {
+		_, seen = serve(t, id)
+		assert.NoError(t, uuid.Validate(seen), "%q is replaced", id)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Empty(t, FromContext(context.Background()))
+	assert.Empty(t, FromContext(&gin.Context{}))
+	assert.Equal(t, "abc", FromContext(WithID(context.Background(), "abc")))
+}
+
+func TestTransport(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get(Header))
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: Transport(nil)}
+
+	send := func(ctx context.Context, header string) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		if header != "" {
+			req.Header.Set(Header, header)
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, header, req.Header.Get(Header), "the caller's request is not modified")
+	}
+	send(WithID(context.Background(), "abc"), "")
+	send(context.Background(), "")
+	send(WithID(context.Background(), "abc"), "set-by-caller")
+
+	assert.Equal(t, []string{"abc", "", "set-by-caller"}, got)
+}
@@ -0,0 +1,103 @@
+package requestid
+
+import (
+	"context"
+	"database/sql"
+
+	"gorm.io/gorm"
+
+	"github.com/gostratum/examples/orderservice/configs"
+)
+
+// RegisterSQLComments appends the request id of each query's context to the
+// query as a comment, /*request_id='…'*/, when db.databases.primary.sql_comments
+// is set. PostgreSQL logs the comment with the statement and shows it in
+// pg_stat_activity. Queries outside a request are left as they are.
+//
+// A query with a comment is a statement of its own, prepared anew, which is
+// why the setting needs prepare_stmt off. It runs after the database secret,
+// if any, has attached its pool. This function is designed to be used with
+// fx.Invoke.
+func RegisterSQLComments(db *gorm.DB, cfg *configs.Config) {
+	if !cfg.Database.SQLComments {
+		return
+	}
+	db.ConnPool = &commentPool{ConnPool: db.ConnPool}
+	db.Statement.ConnPool = db.ConnPool
+}
+
+// withComment returns query with the comment of the request id ctx carries
+func withComment(ctx context.Context, query string) string {
+	id := FromContext(ctx)
+	if id == "" {
+		return query
+	}
+	// Middleware accepts no id a quote or */ could end the comment with
+	return query + " /*" + Key + "='" + id + "'*/"
+}
+
+// commentPool is a gorm.ConnPool that comments the queries of requests
+type commentPool struct {
+	gorm.ConnPool
+}
+
+// ExecContext implements gorm.ConnPool
+func (p *commentPool) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return p.ConnPool.ExecContext(ctx, withComment(ctx, query), args...)
+}
+
+// QueryContext implements gorm.ConnPool
+func (p *commentPool) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return p.ConnPool.QueryContext(ctx, withComment(ctx, query), args...)
+}
+
+// QueryRowContext implements gorm.ConnPool
+func (p *commentPool) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return p.ConnPool.QueryRowContext(ctx, withComment(ctx, query), args...)
+}
+
+// BeginTx implements gorm.ConnPoolBeginner, so the queries of transactions are
+// commented too
+func (p *commentPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	beginner, ok := p.ConnPool.(gorm.TxBeginner)
+	if !ok {
+		return nil, gorm.ErrInvalidTransaction
+	}
+	tx, err := beginner.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &commentTx{Tx: tx}, nil
+}
+
+// GetDBConn implements gorm.GetDBConnector, so db.DB() still returns the pool
+// of database/sql, for the health checks
+func (p *commentPool) GetDBConn() (*sql.DB, error) {
+	switch pool := p.ConnPool.(type) {
+	case *sql.DB:
+		return pool, nil
+	case gorm.GetDBConnector:
+		return pool.GetDBConn()
+	}
+	return nil, gorm.ErrInvalidDB
+}
+
+// commentTx is a transaction that comments the queries of requests
+type commentTx struct {
+	*sql.Tx
+}
+
+// ExecContext implements gorm.ConnPool
+func (tx *commentTx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return tx.Tx.ExecContext(ctx, withComment(ctx, query), args...)
+}
+
+// QueryContext implements gorm.ConnPool
+func (tx *commentTx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return tx.Tx.QueryContext(ctx, withComment(ctx, query), args...)
+}
+
+// QueryRowContext implements gorm.ConnPool
+func (tx *commentTx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return tx.Tx.QueryRowContext(ctx, withComment(ctx, query), args...)
+}
@@ -0,0 +1,107 @@
+package requestid
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/gostratum/examples/orderservice/configs"
+)
+
+// recorder is a database/sql driver that records the queries it is sent, and
+// answers them with no rows
+type recorder struct {
+	mu      sync.Mutex
+	queries []string
+}
+
+func (r *recorder) Open(string) (driver.Conn, error) { return recorderConn{r}, nil }
+
+func (r *recorder) record(query string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queries = append(r.queries, query)
+}
+
+type recorderConn struct{ r *recorder }
+
+func (c recorderConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c recorderConn) Close() error                        { return nil }
+func (c recorderConn) Begin() (driver.Tx, error)           { c.r.record("BEGIN"); return c, nil }
+func (c recorderConn) Commit() error                       { c.r.record("COMMIT"); return nil }
+func (c recorderConn) Rollback() error                     { c.r.record("ROLLBACK"); return nil }
+
+func (c recorderConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.r.record(query)
+	return driver.RowsAffected(0), nil
+}
+
+func (c recorderConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.r.record(query)
+	return noRows{}, nil
+}
+
+type noRows struct{}
+
+func (noRows) Columns() []string         { return []string{"n"} }
+func (noRows) Close() error              { return nil }
+func (noRows) Next([]driver.Value) error { return io.EOF }
+
+// openRecorded returns a gorm.DB over a recorder, with RegisterSQLComments
+// applied for cfg
+func openRecorded(t *testing.T, cfg configs.Database) (*gorm.DB, *recorder) {
+	t.Helper()
+	r := &recorder{}
+	sqlDB := sql.OpenDB(connector{r})
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	RegisterSQLComments(db, &configs.Config{Database: cfg})
+	return db, r
+}
+
+type connector struct{ r *recorder }
+
+func (c connector) Connect(context.Context) (driver.Conn, error) { return c.r.Open("") }
+func (c connector) Driver() driver.Driver                        { return c.r }
+
+func TestRegisterSQLComments(t *testing.T) {
+	db, r := openRecorded(t, configs.Database{SQLComments: true})
+	ctx := WithID(context.Background(), "req-1")
+
+	require.NoError(t, db.WithContext(ctx).Exec("UPDATE orders SET status = ?", "paid").Error)
+	var n int
+	require.NoError(t, db.WithContext(ctx).Raw("SELECT 1").Scan(&n).Error)
+	require.NoError(t, db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Exec("DELETE FROM orders").Error
+	}))
+	require.NoError(t, db.WithContext(context.Background()).Exec("VACUUM").Error)
+
+	assert.Equal(t, []string{
+		"UPDATE orders SET status = $1 /*request_id='req-1'*/",
+		"SELECT 1 /*request_id='req-1'*/",
+		"BEGIN",
+		"DELETE FROM orders /*request_id='req-1'*/",
+		"COMMIT",
+		"VACUUM",
+	}, r.queries, "queries outside a request are left as they are")
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	assert.NoError(t, sqlDB.Ping(), "db.DB() still returns the pool")
+}
+
+func TestRegisterSQLComments_Off(t *testing.T) {
+	db, r := openRecorded(t, configs.Database{})
+	require.NoError(t, db.WithContext(WithID(context.Background(), "req-1")).Exec("VACUUM").Error)
+	assert.Equal(t, []string{"VACUUM"}, r.queries)
+}
@@ -0,0 +1,86 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gostratum/core/logx"
+
+	"github.com/gostratum/examples/orderservice/internal/audit"
+	"github.com/gostratum/examples/workkit/workqueue"
+)
+
+// KindAuditEntries is the task kind of writing the audit entries of a request
+const KindAuditEntries = "audit_entries"
+
+// auditWriteTimeout bounds writing the entries of a request at once, when the
+// queue takes no more
+const auditWriteTimeout = 5 * time.Second
+
+// AuditEntriesTask is the payload of an audit entries task
+type AuditEntriesTask struct {
+	Entries []audit.Entry
+}
+
+// AuditScheduler queues the audit entries of requests on the worker pool, so
+// requests are answered without waiting for the audit log
+type AuditScheduler struct {
+	pool  *workqueue.Pool
+	store audit.Store
+}
+
+// NewAuditScheduler creates a scheduler on the pool, which writes to store
+// when the pool takes no more tasks
+func NewAuditScheduler(pool *workqueue.Pool, store audit.Store) *AuditScheduler {
+	return &AuditScheduler{pool: pool, store: store}
+}
+
+// Schedule queues entries to be written. When the queue is full, or draining
+// for shutdown, it writes them at once instead, so the audit log misses no
+// request under load.
+func (s *AuditScheduler) Schedule(ctx context.Context, entries []audit.Entry) error {
+	_, err := s.pool.Enqueue(KindAuditEntries, AuditEntriesTask{Entries: entries})
+	if errors.Is(err, workqueue.ErrQueueFull) || errors.Is(err, workqueue.ErrClosed) {
+		ctx, cancel := context.WithTimeout(ctx, auditWriteTimeout)
+		defer cancel()
+		return s.store.Insert(ctx, entries)
+	}
+	return err
+}
+
+// AuditEntriesHandler writes audit entries to the store
+type AuditEntriesHandler struct {
+	store audit.Store
+	log   logx.Logger
+}
+
+// NewAuditEntriesHandler creates the handler of the entries of store
+func NewAuditEntriesHandler(store audit.Store, log logx.Logger) *AuditEntriesHandler {
+	return &AuditEntriesHandler{store: store, log: log}
+}
+
+// Kind implements workqueue.Handler
+func (h *AuditEntriesHandler) Kind() string {
+	return KindAuditEntries
+}
+
+// Handle implements workqueue.Handler. A failed task is not retried; the
+// entries are logged instead, so they can be recovered from the logs.
+func (h *AuditEntriesHandler) Handle(ctx context.Context, task workqueue.Task) error {
+	payload, ok := task.Payload.(AuditEntriesTask)
+	if !ok {
+		return fmt.Errorf("unexpected payload %T", task.Payload)
+	}
+
+	if err := h.store.Insert(ctx, payload.Entries); err != nil {
+		h.log.Error("failed to write audit entries",
+			logx.String("task_id", task.ID),
+			logx.Any("entries", payload.Entries),
+			logx.Err(err),
+		)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package tasks_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gostratum/examples/orderservice/internal/audit"
+	"github.com/gostratum/examples/orderservice/internal/tasks"
+	"github.com/gostratum/examples/workkit/workqueue"
+)
+
+// auditStore keeps the entries inserted, or fails with err
+type auditStore struct {
+	entries []audit.Entry
+	err     error
+}
+
+func (s *auditStore) Insert(ctx context.Context, entries []audit.Entry) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.entries = append(s.entries, entries...)
+	return nil
+}
+
+func (s *auditStore) List(ctx context.Context, filter audit.Filter) ([]audit.Entry, error) {
+	return s.entries, nil
+}
+
+func TestAuditEntriesHandler(t *testing.T) {
+	entries := []audit.Entry{{ID: "e-1", Route: "/users"}, {ID: "e-2", Route: "/users"}}
+	handle := func(h *tasks.AuditEntriesHandler, payload any) error {
+		return h.Handle(context.Background(), workqueue.Task{ID: "task-1", Kind: tasks.KindAuditEntries, Payload: payload})
+	}
+
+	store := &auditStore{}
+	h := tasks.NewAuditEntriesHandler(store, logx.NewNoopLogger())
+	assert.Equal(t, tasks.KindAuditEntries, h.Kind())
+	assert.NoError(t, handle(h, tasks.AuditEntriesTask{Entries: entries}))
+	assert.Equal(t, entries, store.entries)
+
+	store = &auditStore{err: errors.New("connection refused")}
+	h = tasks.NewAuditEntriesHandler(store, logx.NewNoopLogger())
+	assert.EqualError(t, handle(h, tasks.AuditEntriesTask{Entries: entries}), "connection refused")
+	assert.ErrorContains(t, handle(h, entries), "unexpected payload []audit.Entry")
+}
@@ -0,0 +1,178 @@
+// Package tasks holds the workqueue handlers that run after a request has
+// been answered, and the schedulers the handlers queue them with
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // Register GIF decoding
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/storagex"
+
+	"github.com/gostratum/examples/orderservice/internal/buckets"
+	"github.com/gostratum/examples/orderservice/internal/domain"
+	"github.com/gostratum/examples/workkit/imaging"
+	"github.com/gostratum/examples/workkit/workqueue"
+)
+
+// KindAvatarThumbnails is the task kind of making an avatar's thumbnails
+const KindAvatarThumbnails = "avatar_thumbnails"
+
+// Limits on the avatars decoded. Uploads are capped by uploads.avatar.max_size,
+// 10 MB by default; larger avatars get no thumbnails. The pixel limit keeps a
+// small file that decodes to a huge image from exhausting memory.
+const (
+	maxAvatarBytes  = 10 << 20
+	maxAvatarPixels = 40_000_000
+	jpegQuality     = 85
+)
+
+// AvatarThumbnailsTask is the payload of an avatar thumbnails task
+type AvatarThumbnailsTask struct {
+	// Key of the uploaded avatar
+	Key string
+}
+
+// AvatarThumbnailScheduler queues the thumbnails of uploaded avatars on the
+// worker pool, so the upload is answered without waiting for them
+type AvatarThumbnailScheduler struct {
+	pool *workqueue.Pool
+}
+
+// NewAvatarThumbnailScheduler creates a scheduler on the pool
+func NewAvatarThumbnailScheduler(pool *workqueue.Pool) *AvatarThumbnailScheduler {
+	return &AvatarThumbnailScheduler{pool: pool}
+}
+
+// Schedule queues the thumbnails of the avatar at key. Avatars in formats
+// that get no thumbnails are not queued. It fails with workqueue.ErrQueueFull
+// or workqueue.ErrClosed when the pool takes no more tasks.
+func (s *AvatarThumbnailScheduler) Schedule(key string) error {
+	if !domain.HasAvatarThumbnails(key) {
+		return nil
+	}
+	_, err := s.pool.Enqueue(KindAvatarThumbnails, AvatarThumbnailsTask{Key: key})
+	return err
+}
+
+// AvatarThumbnailHandler makes the thumbnails of an avatar, one for each of
+// domain.AvatarVariants, and stores them at domain.AvatarVariantKey
+type AvatarThumbnailHandler struct {
+	storage storagex.Storage
+	log     logx.Logger
+}
+
+// NewAvatarThumbnailHandler creates the handler of the avatars in the avatars
+// client
+func NewAvatarThumbnailHandler(clients buckets.Clients, log logx.Logger) *AvatarThumbnailHandler {
+	return &AvatarThumbnailHandler{storage: clients[buckets.Avatars], log: log}
+}
+
+// Kind implements workqueue.Handler
+func (h *AvatarThumbnailHandler) Kind() string {
+	return KindAvatarThumbnails
+}
+
+// Handle implements workqueue.Handler. A failed task is not retried; the
+// avatar keeps its original, and the next upload brings new thumbnails.
+func (h *AvatarThumbnailHandler) Handle(ctx context.Context, task workqueue.Task) error {
+	payload, ok := task.Payload.(AvatarThumbnailsTask)
+	if !ok {
+		return fmt.Errorf("unexpected payload %T", task.Payload)
+	}
+
+	img, err := h.load(ctx, payload.Key)
+	if errors.Is(err, storagex.ErrNotFound) {
+		// Replaced or deleted since the upload
+		h.log.Info("avatar gone before its thumbnails were made",
+			logx.String("task_id", task.ID),
+			logx.String("key", payload.Key),
+		)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, variant := range domain.AvatarVariants {
+		if err := h.write(ctx, img, domain.AvatarVariantKey(payload.Key, variant), variant.Size); err != nil {
+			return err
+		}
+	}
+	h.log.Info("avatar thumbnails created",
+		logx.String("task_id", task.ID),
+		logx.String("key", payload.Key),
+	)
+	return nil
+}
+
+// load downloads and decodes the avatar at key
+func (h *AvatarThumbnailHandler) load(ctx context.Context, key string) (image.Image, error) {
+	body, _, err := h.storage.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(body, maxAvatarBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read avatar %s: %w", key, err)
+	}
+	if len(data) > maxAvatarBytes {
+		return nil, fmt.Errorf("avatar %s is over the limit of %d bytes", key, maxAvatarBytes)
+	}
+
+	// Check the dimensions before decoding
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("avatar %s is not a supported image: %w", key, err)
+	}
+	if cfg.Width*cfg.Height > maxAvatarPixels {
+		return nil, fmt.Errorf("avatar %s is %dx%d, over the limit of %d pixels", key, cfg.Width, cfg.Height, maxAvatarPixels)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("avatar %s is not a supported image: %w", key, err)
+	}
+	return img, nil
+}
+
+// write scales img down to size and stores it at key, re-encoded in the
+// format the key's extension names
+func (h *AvatarThumbnailHandler) write(ctx context.Context, img image.Image, key string, size int) error {
+	// Scaling cannot be interrupted, so give up before it if time is already out
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	thumb := imaging.Thumbnail(img, size)
+
+	var buf bytes.Buffer
+	contentType := "image/png"
+	var err error
+	if strings.EqualFold(path.Ext(key), ".jpg") {
+		contentType = "image/jpeg"
+		err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		err = png.Encode(&buf, thumb)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode thumbnail %s: %w", key, err)
+	}
+
+	if _, err := h.storage.Put(ctx, key, &buf, &storagex.PutOptions{
+		ContentType: contentType,
+		Overwrite:   true,
+	}); err != nil {
+		return fmt.Errorf("failed to store thumbnail %s: %w", key, err)
+	}
+	return nil
+}
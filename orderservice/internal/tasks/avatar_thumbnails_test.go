@@ -0,0 +1,105 @@
+package tasks_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/storagex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/orderservice/internal/buckets"
+	"github.com/gostratum/examples/orderservice/internal/domain"
+	"github.com/gostratum/examples/orderservice/internal/tasks"
+	"github.com/gostratum/examples/orderservice/internal/testutil"
+	"github.com/gostratum/examples/workkit/workqueue"
+)
+
+// upload stores an avatar of w×h pixels, encoded as its key's extension says
+func upload(t *testing.T, storage *testutil.MemoryStorage, key string, w, h int) {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if strings.HasSuffix(key, ".jpg") {
+		require.NoError(t, jpeg.Encode(&buf, img, nil))
+	} else {
+		require.NoError(t, png.Encode(&buf, img))
+	}
+	_, err := storage.Put(context.Background(), key, &buf, &storagex.PutOptions{})
+	require.NoError(t, err)
+}
+
+func handle(h *tasks.AvatarThumbnailHandler, payload any) error {
+	return h.Handle(context.Background(), workqueue.Task{ID: "task-1", Kind: tasks.KindAvatarThumbnails, Payload: payload})
+}
+
+func TestAvatarThumbnailHandler_MakesEveryVariant(t *testing.T) {
+	tests := []struct {
+		key         string
+		contentType string
+		format      string
+	}{
+		{key: "avatars/u1_1700000000.png", contentType: "image/png", format: "png"},
+		{key: "avatars/u1_1700000000.jpg", contentType: "image/jpeg", format: "jpeg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			storage := testutil.NewMemoryStorage()
+			upload(t, storage, tt.key, 600, 300)
+			h := tasks.NewAvatarThumbnailHandler(buckets.Clients{buckets.Avatars: storage}, logx.NewNoopLogger())
+
+			require.NoError(t, handle(h, tasks.AvatarThumbnailsTask{Key: tt.key}))
+
+			for _, variant := range domain.AvatarVariants {
+				key := domain.AvatarVariantKey(tt.key, variant)
+				body, stat, err := storage.Get(context.Background(), key)
+				require.NoError(t, err, "thumbnail %s", key)
+				data, err := io.ReadAll(body)
+				require.NoError(t, err)
+
+				// Scaled to fit, keeping the aspect ratio, and re-encoded
+				cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+				require.NoError(t, err)
+				assert.Equal(t, tt.format, format)
+				assert.Equal(t, tt.contentType, stat.ContentType)
+				assert.Equal(t, variant.Size, cfg.Width)
+				assert.Equal(t, variant.Size/2, cfg.Height)
+			}
+		})
+	}
+}
+
+func TestAvatarThumbnailHandler_AvatarGone(t *testing.T) {
+	storage := testutil.NewMemoryStorage()
+	h := tasks.NewAvatarThumbnailHandler(buckets.Clients{buckets.Avatars: storage}, logx.NewNoopLogger())
+
+	// Replaced by a newer upload before the task ran: nothing to do
+	assert.NoError(t, handle(h, tasks.AvatarThumbnailsTask{Key: "avatars/u1_1700000000.png"}))
+	assert.Empty(t, storage.Keys())
+}
+
+func TestAvatarThumbnailHandler_Failures(t *testing.T) {
+	storage := testutil.NewMemoryStorage()
+	_, err := storage.Put(context.Background(), "avatars/u1_1700000000.png", bytes.NewReader([]byte("\x89PNG\r\n\x1a\n")), &storagex.PutOptions{})
+	require.NoError(t, err)
+	h := tasks.NewAvatarThumbnailHandler(buckets.Clients{buckets.Avatars: storage}, logx.NewNoopLogger())
+
+	err = handle(h, tasks.AvatarThumbnailsTask{Key: "avatars/u1_1700000000.png"})
+	assert.ErrorContains(t, err, "is not a supported image")
+	assert.Equal(t, []string{"avatars/u1_1700000000.png"}, storage.Keys(), "no thumbnails are written")
+
+	assert.ErrorContains(t, handle(h, "avatars/u1_1700000000.png"), "unexpected payload string")
+}
@@ -0,0 +1,208 @@
+// Package testutil boots orderservice for tests: the fx graph of cmd/api, with
+// a migrated PostgreSQL database from testkit and test doubles for everything
+// else the service talks to.
+package testutil
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/dbx"
+	"github.com/gostratum/httpx"
+	"github.com/gostratum/storagex"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+
+	"github.com/gostratum/examples/orderservice/internal/adapter/inventory"
+	"github.com/gostratum/examples/orderservice/internal/adapter/payment"
+	"github.com/gostratum/examples/orderservice/internal/app"
+	"github.com/gostratum/examples/orderservice/internal/usecase"
+	"github.com/gostratum/examples/orderservice/migrations"
+	"github.com/gostratum/examples/testkit/containers"
+)
+
+// startTimeout bounds starting and stopping the application
+const startTimeout = 30 * time.Second
+
+// TestApp is a running orderservice
+type TestApp struct {
+	// BaseURL is where the HTTP server listens, e.g. http://127.0.0.1:41233
+	BaseURL string
+	// DB is the application's database handle, for arranging and checking
+	// rows directly
+	DB *gorm.DB
+	// Logs holds every line the application logged
+	Logs *LogCapture
+	// Storage holds the avatars uploaded
+	Storage *MemoryStorage
+}
+
+// Option changes what NewTestApp wires in
+type Option func(*options)
+
+type options struct {
+	inventory usecase.InventoryClient
+	payments  usecase.PaymentGateway
+	extra     []fx.Option
+}
+
+// WithInventory replaces the inventory client, which reserves nothing by default
+func WithInventory(client usecase.InventoryClient) Option {
+	return func(o *options) {
+		o.inventory = client
+	}
+}
+
+// WithPayments replaces the payment gateway, which charges nothing by default
+func WithPayments(gateway usecase.PaymentGateway) Option {
+	return func(o *options) {
+		o.payments = gateway
+	}
+}
+
+// WithFxOptions adds options to the graph, e.g. fx.Decorate to replace another
+// component or fx.Populate to get hold of one
+func WithFxOptions(opts ...fx.Option) Option {
+	return func(o *options) {
+		o.extra = append(o.extra, opts...)
+	}
+}
+
+// NewTestApp starts orderservice for t, as cmd/api composes it, and stops it
+// when t ends. It gets a database of its own created from migrations/, and
+// serves on a port the kernel picks, so tests that call it can run in
+// parallel.
+//
+// The database comes from testkit, so the test binary's TestMain must call
+// containers.Run, and t is skipped without Docker.
+func NewTestApp(t testing.TB, opts ...Option) *TestApp {
+	t.Helper()
+	o := options{inventory: inventory.Disabled{}, payments: payment.Disabled{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	database := containers.Postgres(t, containers.WithMigrations(migrations.FS))
+
+	// The test serves httpx's engine from a listener of its own on port 0,
+	// which no other test can take, and reads the port back from it. httpx's
+	// server listens on another port 0 and is not used.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	ta := &TestApp{
+		BaseURL: "http://" + listener.Addr().String(),
+		Logs:    &LogCapture{},
+		Storage: NewMemoryStorage(),
+	}
+	var engine *gin.Engine
+	graph := append([]fx.Option{
+		dbx.Module(),
+		httpx.Module(),
+		app.Module(),
+
+		fx.Provide(func() storagex.Storage { return ta.Storage }),
+		fx.Decorate(
+			func(logx.Logger) logx.Logger { return ta.Logs },
+			func(usecase.InventoryClient) usecase.InventoryClient { return o.inventory },
+			func(usecase.PaymentGateway) usecase.PaymentGateway { return o.payments },
+		),
+		fx.Populate(&ta.DB, &engine),
+	}, o.extra...)
+
+	// Configuration comes from configs/base.yaml, with the environment
+	// overrides a deployment would use
+	env := map[string]string{
+		"CONFIG_PATHS":                     configDir(),
+		"STRATUM_HTTP_ADDR":                "127.0.0.1:0",
+		"STRATUM_DB_DATABASES_PRIMARY_DSN": database.DSN,
+	}
+	var application interface {
+		Start(context.Context) error
+		Stop(context.Context) error
+	}
+	withEnv(env, func() { application = core.New(graph...) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
+	defer cancel()
+	if err := application.Start(ctx); err != nil {
+		listener.Close()
+		t.Fatalf("failed to start orderservice: %v", err)
+	}
+
+	server := &http.Server{Handler: engine, ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = server.Serve(listener) }()
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			t.Errorf("failed to stop the test server: %v", err)
+		}
+		if err := application.Stop(ctx); err != nil {
+			t.Errorf("failed to stop orderservice: %v", err)
+		}
+	})
+
+	ta.waitLive(t)
+	return ta
+}
+
+// envMu serializes the builds of test apps, which withEnv configures through
+// the environment that the whole process shares
+var envMu sync.Mutex
+
+// withEnv runs build with env set, and restores the environment after. The
+// constructors read their configuration while core.New builds the graph, so
+// an application started later keeps its own.
+func withEnv(env map[string]string, build func()) {
+	envMu.Lock()
+	defer envMu.Unlock()
+
+	for key, value := range env {
+		previous, ok := os.LookupEnv(key)
+		os.Setenv(key, value)
+		if ok {
+			defer os.Setenv(key, previous)
+		} else {
+			defer os.Unsetenv(key)
+		}
+	}
+	build()
+}
+
+// waitLive waits until the server answers /livez
+func (ta *TestApp) waitLive(t testing.TB) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := http.Get(ta.BaseURL + "/livez")
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("orderservice did not come up on %s", ta.BaseURL)
+}
+
+// configDir returns the service's configs directory, whichever package the
+// test runs from
+func configDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "configs")
+}
@@ -0,0 +1,93 @@
+// Package dbtest runs each database test in a transaction that is rolled back
+// when the test ends, on a migrated PostgreSQL database that the tests of the
+// binary share. The repositories take the transaction as their *gorm.DB:
+//
+//	func TestUserRepo_Save(t *testing.T) {
+//		repo := NewUserRepo(dbtest.Tx(t))
+//		...
+//	}
+//
+// Nothing a test writes is committed, so tests need neither a database of
+// their own nor a cleanup, and they can run in parallel. The test binary's
+// TestMain must call containers.Run.
+package dbtest
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/gostratum/examples/orderservice/migrations"
+	"github.com/gostratum/examples/testkit/containers"
+)
+
+var (
+	mu  sync.Mutex
+	dbs = map[string]*gorm.DB{}
+
+	// savepoints numbers the savepoints, whose names must differ within a
+	// transaction
+	savepoints atomic.Int64
+)
+
+// Open returns the shared database, with the service's migrations applied.
+// Writes through it are committed and seen by every later test, so arrange
+// rows through Tx instead, unless the test needs several connections at once,
+// as concurrency tests do; then make the rows unique to the test.
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+	database := containers.SharedPostgres(t, containers.WithMigrations(migrations.FS))
+
+	mu.Lock()
+	defer mu.Unlock()
+	if db, ok := dbs[database.DSN]; ok {
+		return db
+	}
+	db, err := gorm.Open(postgres.Open(database.DSN), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to open shared test database: %v", err)
+	}
+	dbs[database.DSN] = db
+	return db
+}
+
+// Tx begins a transaction on the shared database and rolls it back when t
+// ends. Repositories that run their own transactions on it get savepoints
+// within it. A transaction is one connection, so t's subtests must not use it
+// in parallel.
+func Tx(t testing.TB) *gorm.DB {
+	t.Helper()
+	tx := Open(t).Begin()
+	if tx.Error != nil {
+		t.Fatalf("failed to begin test transaction: %v", tx.Error)
+	}
+	t.Cleanup(func() {
+		if err := tx.Rollback().Error; err != nil {
+			t.Errorf("failed to roll back test transaction: %v", err)
+		}
+	})
+	return tx
+}
+
+// Savepoint sets a savepoint in tx, a transaction from Tx, and rolls back to
+// it when t ends. Give it to a subtest whose statement should fail: an error
+// aborts a PostgreSQL transaction, and rolling back to the savepoint makes
+// tx usable again for the subtests after it.
+func Savepoint(t testing.TB, tx *gorm.DB) *gorm.DB {
+	t.Helper()
+	name := fmt.Sprintf("dbtest_%d", savepoints.Add(1))
+	if err := tx.SavePoint(name).Error; err != nil {
+		t.Fatalf("failed to set savepoint: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := tx.RollbackTo(name).Error; err != nil {
+			t.Errorf("failed to roll back to savepoint: %v", err)
+		}
+	})
+	return tx
+}
@@ -0,0 +1,111 @@
+// Package factories builds valid domain values filled with realistic fake
+// data, so a test spells out only the fields it is about:
+//
+//	user := factories.User(func(u *domain.User) { u.Email = "taken@example.com" })
+//	order := factories.Order(factories.ForUser(user), factories.WithItems(3))
+//
+// Every call draws new data, and emails are unique within the test binary, so
+// users from different tests can share a database.
+package factories
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/brianvoe/gofakeit/v6"
+
+	"github.com/gostratum/examples/orderservice/internal/domain"
+)
+
+// faker is safe for concurrent use, so parallel tests can share it
+var faker = gofakeit.New(0)
+
+// emailSeq makes every email unique, whatever names faker draws
+var emailSeq atomic.Int64
+
+// User returns a new user with a generated ID, a fake name and a unique email
+// at example.com, changed by overrides in order
+func User(overrides ...func(*domain.User)) *domain.User {
+	first, last := faker.FirstName(), faker.LastName()
+	user := domain.NewUser(first+" "+last, Email(first, last))
+	for _, override := range overrides {
+		override(user)
+	}
+	return user
+}
+
+// Email returns a unique address at example.com for a person named first
+// last, e.g. ada.lovelace.7@example.com
+func Email(first, last string) string {
+	return fmt.Sprintf("%s.%s.%d@example.com", localPart(first), localPart(last), emailSeq.Add(1))
+}
+
+// localPart keeps the ASCII letters of name, lowercased, so names such as
+// O'Keefe make a valid address
+func localPart(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z':
+			return r
+		case 'A' <= r && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return -1
+	}, name)
+	if name == "" {
+		return "user"
+	}
+	return name
+}
+
+// Item returns a valid item with a SKU such as QXA-4821, a quantity from 1 to
+// 5 and a price in whole cents from 0.50 to 500.00, changed by overrides in
+// order
+func Item(overrides ...func(*domain.Item)) domain.Item {
+	item := domain.Item{
+		SKU:   strings.ToUpper(faker.Lexify("???")) + "-" + faker.Numerify("####"),
+		Qty:   faker.Number(1, 5),
+		Price: faker.Price(0.50, 500),
+	}
+	for _, override := range overrides {
+		override(&item)
+	}
+	return item
+}
+
+// Items returns n valid items with distinct SKUs
+func Items(n int) []domain.Item {
+	items := make([]domain.Item, n)
+	for i := range items {
+		items[i] = Item(func(item *domain.Item) { item.SKU = fmt.Sprintf("%s-%02d", item.SKU, i) })
+	}
+	return items
+}
+
+// Order returns a new pending order of a fake user with one to three items,
+// changed by overrides in order. Total is then recalculated from the items,
+// so set it after Order returns to test an order whose total is wrong.
+func Order(overrides ...func(*domain.Order)) *domain.Order {
+	order := domain.NewOrder(faker.UUID())
+	order.Items = Items(faker.Number(1, 3))
+	for _, override := range overrides {
+		override(order)
+	}
+
+	order.Total = 0
+	for _, item := range order.Items {
+		order.Total += item.Price * float64(item.Qty)
+	}
+	return order
+}
+
+// ForUser makes the order belong to user
+func ForUser(user *domain.User) func(*domain.Order) {
+	return func(o *domain.Order) { o.UserID = user.ID }
+}
+
+// WithItems gives the order n new items
+func WithItems(n int) func(*domain.Order) {
+	return func(o *domain.Order) { o.Items = Items(n) }
+}
@@ -0,0 +1,75 @@
+package factories
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/orderservice/internal/domain"
+)
+
+func TestUser(t *testing.T) {
+	t.Run("valid with unique emails", func(t *testing.T) {
+		seen := map[string]bool{}
+		for range 1000 {
+			user := User()
+			require.NoError(t, user.Validate(), "%+v", user)
+			assert.NotEmpty(t, user.ID)
+			assert.False(t, seen[user.Email], "duplicate email %s", user.Email)
+			seen[user.Email] = true
+		}
+	})
+
+	t.Run("overrides apply in order", func(t *testing.T) {
+		user := User(
+			func(u *domain.User) { u.Name = "Ada Lovelace" },
+			func(u *domain.User) { u.Email = "ada@example.com" },
+		)
+		assert.Equal(t, "Ada Lovelace", user.Name)
+		assert.Equal(t, "ada@example.com", user.Email)
+	})
+
+	t.Run("names without letters still make an address", func(t *testing.T) {
+		assert.Regexp(t, `^user\.okeefe\.\d+@example\.com$`, Email("'", "O'Keefe"))
+	})
+}
+
+func TestItems(t *testing.T) {
+	items := Items(50)
+	require.Len(t, items, 50)
+
+	skus := map[string]bool{}
+	for _, item := range items {
+		assert.NoError(t, domain.NewOrder("user123").AddItem(item), "%+v", item)
+		assert.False(t, skus[item.SKU], "duplicate SKU %s", item.SKU)
+		skus[item.SKU] = true
+	}
+}
+
+func TestOrder(t *testing.T) {
+	t.Run("valid with a consistent total", func(t *testing.T) {
+		order := Order()
+		require.NoError(t, order.Validate())
+		assert.Equal(t, "pending", order.Status)
+		assertTotal(t, order)
+	})
+
+	t.Run("total follows overridden items", func(t *testing.T) {
+		user := User()
+		order := Order(ForUser(user), WithItems(4), func(o *domain.Order) { o.Items[0].Qty = 100 })
+		assert.Equal(t, user.ID, order.UserID)
+		assert.Len(t, order.Items, 4)
+		assertTotal(t, order)
+	})
+}
+
+// assertTotal checks the order's total is Σ(price×qty) of its items
+func assertTotal(t *testing.T, order *domain.Order) {
+	t.Helper()
+	var want float64
+	for _, item := range order.Items {
+		want += item.Price * float64(item.Qty)
+	}
+	assert.Equal(t, want, order.Total)
+}
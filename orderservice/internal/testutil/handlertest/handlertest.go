@@ -0,0 +1,211 @@
+// Package handlertest calls gin handlers from table-driven tests and checks
+// the responsex envelope they write. A case states its request and the
+// response it expects, and the checks name what differs:
+//
+//	handlertest.Call(t, handler.GetUser, handlertest.Request{
+//		Method: http.MethodGet,
+//		Path:   "/users/" + id,
+//		Params: gin.Params{{Key: "id", Value: id}},
+//	}).Assert(t, handlertest.Expect{
+//		Status: http.StatusOK,
+//		Data:   handlertest.Fields{"id": id, "created_at": handlertest.NotEmpty},
+//	})
+package handlertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Request is a request to a handler
+type Request struct {
+	Method string
+	Path   string
+	// Params are the route parameters, which Call sets as the router would
+	// after matching the route
+	Params gin.Params
+	// JSON, unless nil, is encoded as the body and sent as application/json
+	JSON any
+	// Body and ContentType send a body JSON cannot, such as a multipart
+	// form or malformed JSON. Body is ignored when JSON is set.
+	Body        io.Reader
+	ContentType string
+}
+
+func (r Request) build(t testing.TB) *http.Request {
+	t.Helper()
+	body, contentType := r.Body, r.ContentType
+	if r.JSON != nil {
+		data, err := json.Marshal(r.JSON)
+		require.NoError(t, err, "failed to encode request body")
+		body, contentType = bytes.NewReader(data), "application/json"
+	}
+	req := httptest.NewRequest(r.Method, r.Path, body)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return req
+}
+
+// Call calls handler with a gin test context for req, and returns what it
+// wrote. No middleware runs; use Serve to go through an engine.
+func Call(t testing.TB, handler gin.HandlerFunc, req Request) *Response {
+	t.Helper()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req.build(t)
+	c.Params = req.Params
+	handler(c)
+	return newResponse(w)
+}
+
+// Serve sends req through h, usually an engine with its routes registered,
+// and returns the response. Params are ignored; the router sets them.
+func Serve(t testing.TB, h http.Handler, req Request) *Response {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req.build(t))
+	return newResponse(w)
+}
+
+// Response is what a handler wrote
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+func newResponse(w *httptest.ResponseRecorder) *Response {
+	return &Response{Status: w.Code, Header: w.Header(), Body: w.Body.Bytes()}
+}
+
+// Envelope is the responsex envelope, with data left encoded
+type Envelope struct {
+	OK    bool            `json:"ok"`
+	Data  json.RawMessage `json:"data"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Envelope decodes the body, failing t when it is not an envelope
+func (r *Response) Envelope(t testing.TB) Envelope {
+	t.Helper()
+	var env Envelope
+	require.NoError(t, json.Unmarshal(r.Body, &env), "body is not a JSON envelope: %s", r.Body)
+	return env
+}
+
+// Data decodes the data of the response's envelope into a T, for checks
+// Fields cannot express
+func Data[T any](t testing.TB, r *Response) T {
+	t.Helper()
+	var data T
+	env := r.Envelope(t)
+	require.NoError(t, json.Unmarshal(env.Data, &data), "failed to decode data: %s", env.Data)
+	return data
+}
+
+// Expect is the response a case expects. A Status below 400 expects an
+// envelope that is ok, and one of 400 or above an envelope with an error.
+type Expect struct {
+	Status int
+	// Code and Message are the error's, each checked unless empty
+	Code    string
+	Message string
+	// Data, unless nil, holds fields the data must have
+	Data Fields
+}
+
+// Fields are fields of the data by JSON name. A value is either a Matcher,
+// or what the field must equal once both are encoded as JSON, so 2 matches
+// 2.0 and a time.Time matches its RFC 3339 string. Fields not listed are not
+// checked.
+type Fields map[string]any
+
+// Matcher checks a field decoded from JSON, and returns why it does not match
+type Matcher func(got any) error
+
+// NotEmpty matches a field that is present and not null, "", 0, false, [] or {}
+var NotEmpty Matcher = func(got any) error {
+	if got == nil || reflect.ValueOf(got).IsZero() {
+		return fmt.Errorf("is %#v, want a value", got)
+	}
+	if v := reflect.ValueOf(got); (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0 {
+		return fmt.Errorf("is empty, want a value")
+	}
+	return nil
+}
+
+// Assert checks the response is want, reporting every difference
+func (r *Response) Assert(t testing.TB, want Expect) {
+	t.Helper()
+	if !assert.Equal(t, want.Status, r.Status, "status; body: %s", r.Body) {
+		return
+	}
+	env := r.Envelope(t)
+
+	if want.Status >= http.StatusBadRequest {
+		assert.False(t, env.OK, "ok of an error response")
+		if !assert.NotNil(t, env.Error, "error of an error response") {
+			return
+		}
+		if want.Code != "" {
+			assert.Equal(t, want.Code, env.Error.Code, "error code")
+		}
+		if want.Message != "" {
+			assert.Equal(t, want.Message, env.Error.Message, "error message")
+		}
+	} else {
+		assert.True(t, env.OK, "ok of a successful response")
+		assert.Nil(t, env.Error, "error of a successful response")
+	}
+
+	if want.Data != nil {
+		var data map[string]any
+		if !assert.NoError(t, json.Unmarshal(env.Data, &data), "data is not an object: %s", env.Data) {
+			return
+		}
+		want.Data.assert(t, data)
+	}
+}
+
+func (f Fields) assert(t testing.TB, data map[string]any) {
+	t.Helper()
+	for name, want := range f {
+		got, ok := data[name]
+		if match, isMatcher := want.(Matcher); isMatcher {
+			if !ok {
+				t.Errorf("data.%s is missing", name)
+			} else if err := match(got); err != nil {
+				t.Errorf("data.%s %v", name, err)
+			}
+			continue
+		}
+		if !assert.True(t, ok, "data.%s is missing", name) {
+			continue
+		}
+		assert.Equal(t, asJSON(t, want), got, "data.%s", name)
+	}
+}
+
+// asJSON is v as it decodes from JSON into an any
+func asJSON(t testing.TB, v any) any {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err, "failed to encode %#v", v)
+	var decoded any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	return decoded
+}
@@ -0,0 +1,123 @@
+package handlertest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/httpx/responsex"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder is a testing.TB that records the failures of a check instead of
+// failing the test
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) FailNow() {
+	r.errors = append(r.errors, "FailNow")
+	// Like testing.T, stop the check
+	panic(r)
+}
+
+// failures runs check with a recorder and returns what it reported
+func failures(t *testing.T, check func(t testing.TB)) string {
+	r := &recorder{TB: t}
+	func() {
+		defer func() {
+			if p := recover(); p != nil && p != r {
+				panic(p)
+			}
+		}()
+		check(r)
+	}()
+	return strings.Join(r.errors, "\n")
+}
+
+func getUser(c *gin.Context) {
+	if c.Param("id") != "u-1" {
+		responsex.Error(c, http.StatusNotFound, "USER_NOT_FOUND", "user not found", nil)
+		return
+	}
+	responsex.OK(c, map[string]any{
+		"id":         "u-1",
+		"name":       "Ada",
+		"age":        36,
+		"tags":       []string{},
+		"created_at": time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, nil)
+}
+
+func TestCall(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	found := Call(t, getUser, Request{Method: http.MethodGet, Path: "/users/u-1", Params: gin.Params{{Key: "id", Value: "u-1"}}})
+	missing := Call(t, getUser, Request{Method: http.MethodGet, Path: "/users/u-2", Params: gin.Params{{Key: "id", Value: "u-2"}}})
+
+	t.Run("matching responses pass", func(t *testing.T) {
+		assert.Empty(t, failures(t, func(t testing.TB) {
+			found.Assert(t, Expect{Status: http.StatusOK, Data: Fields{
+				"id":         "u-1",
+				"age":        36,
+				"created_at": time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+				"name":       NotEmpty,
+			}})
+			missing.Assert(t, Expect{Status: http.StatusNotFound, Code: "USER_NOT_FOUND", Message: "user not found"})
+		}))
+	})
+
+	t.Run("a wrong status stops the checks", func(t *testing.T) {
+		got := failures(t, func(t testing.TB) {
+			missing.Assert(t, Expect{Status: http.StatusOK, Data: Fields{"id": "u-1"}})
+		})
+		assert.Contains(t, got, "status")
+		assert.NotContains(t, got, "data.id")
+	})
+
+	t.Run("a wrong error is reported", func(t *testing.T) {
+		got := failures(t, func(t testing.TB) {
+			missing.Assert(t, Expect{Status: http.StatusNotFound, Code: "NOT_FOUND", Message: "no such user"})
+		})
+		assert.Contains(t, got, "error code")
+		assert.Contains(t, got, "error message")
+	})
+
+	t.Run("each wrong field is reported", func(t *testing.T) {
+		got := failures(t, func(t testing.TB) {
+			found.Assert(t, Expect{Status: http.StatusOK, Data: Fields{
+				"name":  "Grace",
+				"email": "ada@example.com",
+				"tags":  NotEmpty,
+				"role":  NotEmpty,
+			}})
+		})
+		assert.Contains(t, got, "data.name")
+		assert.Contains(t, got, "data.email is missing")
+		assert.Contains(t, got, "data.tags is empty")
+		assert.Contains(t, got, "data.role is missing")
+	})
+}
+
+func TestServe(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/users/:id", getUser)
+
+	resp := Serve(t, engine, Request{Method: http.MethodGet, Path: "/users/u-1"})
+	resp.Assert(t, Expect{Status: http.StatusOK, Data: Fields{"id": "u-1"}})
+
+	type user struct {
+		Name string `json:"name"`
+	}
+	assert.Equal(t, "Ada", Data[user](t, resp).Name)
+}
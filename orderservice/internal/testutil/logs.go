@@ -0,0 +1,68 @@
+package testutil
+
+import (
+	"sync"
+
+	"github.com/gostratum/core/logx"
+	"go.uber.org/zap/zapcore"
+)
+
+// Entry is one logged line
+type Entry struct {
+	Level   string
+	Message string
+	Fields  map[string]any
+}
+
+// LogCapture is a logx.Logger that keeps every line, for tests to check what
+// was logged. It is safe for concurrent use.
+type LogCapture struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func (l *LogCapture) Debug(msg string, fields ...logx.Field) { l.add("debug", msg, fields) }
+func (l *LogCapture) Info(msg string, fields ...logx.Field)  { l.add("info", msg, fields) }
+func (l *LogCapture) Warn(msg string, fields ...logx.Field)  { l.add("warn", msg, fields) }
+func (l *LogCapture) Error(msg string, fields ...logx.Field) { l.add("error", msg, fields) }
+
+func (l *LogCapture) add(level, msg string, fields []logx.Field) {
+	// logx fields are zap fields, whose value is kept by type
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	e := Entry{Level: level, Message: msg, Fields: enc.Fields}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, e)
+}
+
+// Entries returns the lines logged so far, oldest first
+func (l *LogCapture) Entries() []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Entry(nil), l.entries...)
+}
+
+// Level returns the lines logged so far at level: debug, info, warn or error
+func (l *LogCapture) Level(level string) []Entry {
+	var entries []Entry
+	for _, e := range l.Entries() {
+		if e.Level == level {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// Find returns the first line logged with msg
+func (l *LogCapture) Find(msg string) (Entry, bool) {
+	for _, e := range l.Entries() {
+		if e.Message == msg {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
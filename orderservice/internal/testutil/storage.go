@@ -0,0 +1,120 @@
+package testutil
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gostratum/storagex"
+)
+
+// MemoryStorage is a storagex.Storage that keeps objects in memory. It is
+// safe for concurrent use.
+type MemoryStorage struct {
+	storagex.Storage
+	mu      sync.Mutex
+	objects map[string]memoryObject
+}
+
+type memoryObject struct {
+	data []byte
+	stat storagex.Stat
+}
+
+// NewMemoryStorage returns an empty MemoryStorage
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{objects: map[string]memoryObject{}}
+}
+
+// Put implements storagex.Storage
+func (s *MemoryStorage) Put(ctx context.Context, key string, r io.Reader, opts *storagex.PutOptions) (storagex.Stat, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return storagex.Stat{}, err
+	}
+	if opts == nil {
+		opts = &storagex.PutOptions{}
+	}
+	sum := md5.Sum(data)
+	stat := storagex.Stat{
+		Key:          key,
+		Size:         int64(len(data)),
+		ETag:         `"` + hex.EncodeToString(sum[:]) + `"`,
+		ContentType:  opts.ContentType,
+		LastModified: time.Now(),
+		Metadata:     opts.Metadata,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.objects[key]; ok && !opts.Overwrite {
+		return storagex.Stat{}, storagex.ErrAlreadyExists
+	}
+	s.objects[key] = memoryObject{data: data, stat: stat}
+	return stat, nil
+}
+
+// Get implements storagex.Storage
+func (s *MemoryStorage) Get(ctx context.Context, key string) (io.ReadCloser, storagex.Stat, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.objects[key]
+	if !ok {
+		return nil, storagex.Stat{}, storagex.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(obj.data)), obj.stat, nil
+}
+
+// Head implements storagex.Storage
+func (s *MemoryStorage) Head(ctx context.Context, key string) (storagex.Stat, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.objects[key]
+	if !ok {
+		return storagex.Stat{}, storagex.ErrNotFound
+	}
+	return obj.stat, nil
+}
+
+// List implements storagex.Storage; every key under the prefix comes in one page
+func (s *MemoryStorage) List(ctx context.Context, opts storagex.ListOptions) (storagex.ListPage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var page storagex.ListPage
+	for key, obj := range s.objects {
+		if strings.HasPrefix(key, opts.Prefix) {
+			page.Keys = append(page.Keys, obj.stat)
+		}
+	}
+	sort.Slice(page.Keys, func(i, j int) bool { return page.Keys[i].Key < page.Keys[j].Key })
+	return page, nil
+}
+
+// Delete implements storagex.Storage
+func (s *MemoryStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.objects[key]; !ok {
+		return storagex.ErrNotFound
+	}
+	delete(s.objects, key)
+	return nil
+}
+
+// Keys returns the keys stored, sorted
+func (s *MemoryStorage) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,101 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/storagex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/testkit/containers"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(containers.Run(m))
+}
+
+func TestNewTestApp_Parallel(t *testing.T) {
+	apps := make([]*TestApp, 3)
+	t.Run("start", func(t *testing.T) {
+		for i := range apps {
+			t.Run("app", func(t *testing.T) {
+				t.Parallel()
+				apps[i] = NewTestApp(t)
+
+				resp, err := http.Get(apps[i].BaseURL + "/livez")
+				require.NoError(t, err)
+				resp.Body.Close()
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+				// Each app has a database of its own
+				require.NoError(t, apps[i].DB.Exec("INSERT INTO users (name, email) VALUES ('Only Me', 'only.me@example.com')").Error)
+				var users int64
+				require.NoError(t, apps[i].DB.Table("users").Count(&users).Error)
+				assert.Equal(t, int64(1), users)
+			})
+		}
+	})
+
+	addrs := map[string]bool{}
+	for _, ta := range apps {
+		if ta == nil {
+			t.Skip("the apps were skipped")
+		}
+		assert.False(t, addrs[ta.BaseURL], "two apps on %s", ta.BaseURL)
+		addrs[ta.BaseURL] = true
+	}
+}
+
+func TestLogCapture(t *testing.T) {
+	var logs LogCapture
+	var log logx.Logger = &logs
+
+	log.Info("user created", logx.String("user_id", "u-1"))
+	log.Error("unexpected error", logx.Err(errors.New("boom")))
+
+	assert.Len(t, logs.Entries(), 2)
+	assert.Len(t, logs.Level("error"), 1)
+	assert.Empty(t, logs.Level("warn"))
+
+	e, ok := logs.Find("user created")
+	require.True(t, ok)
+	assert.Equal(t, "info", e.Level)
+	assert.Equal(t, "u-1", e.Fields["user_id"])
+}
+
+func TestMemoryStorage(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStorage()
+
+	stat, err := s.Put(ctx, "avatars/u-1.png", strings.NewReader("png"), &storagex.PutOptions{ContentType: "image/png"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stat.Size)
+	assert.NotEmpty(t, stat.ETag)
+
+	_, err = s.Put(ctx, "avatars/u-1.png", strings.NewReader("png"), nil)
+	assert.ErrorIs(t, err, storagex.ErrAlreadyExists)
+	_, err = s.Put(ctx, "avatars/u-1.png", strings.NewReader("jpeg"), &storagex.PutOptions{Overwrite: true})
+	require.NoError(t, err)
+
+	r, _, err := s.Get(ctx, "avatars/u-1.png")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", string(data))
+
+	page, err := s.List(ctx, storagex.ListOptions{Prefix: "avatars/"})
+	require.NoError(t, err)
+	assert.Len(t, page.Keys, 1)
+
+	require.NoError(t, s.Delete(ctx, "avatars/u-1.png"))
+	_, err = s.Head(ctx, "avatars/u-1.png")
+	assert.ErrorIs(t, err, storagex.ErrNotFound)
+	assert.Empty(t, s.Keys())
+}
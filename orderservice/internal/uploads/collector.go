@@ -0,0 +1,260 @@
+package uploads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/storagex"
+
+	"github.com/gostratum/examples/orderservice/internal/buckets"
+	"github.com/gostratum/examples/orderservice/internal/domain"
+)
+
+// AvatarReferences tells which stored avatars users refer to; implemented by
+// the user repository
+type AvatarReferences interface {
+	// AvatarHashesInUse returns those of hashes some user's avatar has
+	AvatarHashesInUse(ctx context.Context, hashes []string) ([]string, error)
+}
+
+// AttachmentReferences tells which orders attachments are stored for still
+// exist; implemented by the order repository
+type AttachmentReferences interface {
+	// ExistingOrderIDs returns those of ids an order has
+	ExistingOrderIDs(ctx context.Context, ids []string) ([]string, error)
+}
+
+// Tally counts the files of one kind a collection found nothing refers to
+type Tally struct {
+	// Orphans are the files stored before the grace period
+	Orphans int
+	// Bytes the orphans hold
+	Bytes int64
+	// Deleted counts the orphans deleted; none in a dry run
+	Deleted int
+}
+
+// Report is what a collection found, by kind of file: avatar or attachment
+type Report map[string]Tally
+
+// collection is a kind of file the collector looks after
+type collection struct {
+	kind    string
+	storage storagex.Storage
+	prefix  string
+	// owner returns what the file at key belongs to, or false for a file
+	// that is not the collector's
+	owner func(key string) (string, bool)
+	// existing returns those of owners the database still has
+	existing func(ctx context.Context, owners []string) ([]string, error)
+	// dependents returns the files deleted with the one at key, before it
+	dependents func(key string) []string
+}
+
+// Collector deletes the files nothing refers to. Avatars are stored under
+// the hash of their content, so one is shared by every user who uploaded the
+// same file, and lingers once the last of them replaces it; it is deleted
+// with its thumbnails. Attachments are stored under the id of their order,
+// and linger once it is deleted. Only keys ContentKey returns are collected
+// among avatars, and only keys one directory deep among attachments.
+type Collector struct {
+	cfg         GCConfig
+	collections []collection
+	metrics     *Metrics
+	log         logx.Logger
+	now         func() time.Time
+
+	// stopping ends the collection loop
+	stopping chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewCollector creates the collector of the files cfg stores: avatars in the
+// avatars client, and attachments in the invoices client
+func NewCollector(cfg Config, clients buckets.Clients, avatars AvatarReferences, attachments AttachmentReferences, metrics *Metrics, log logx.Logger) *Collector {
+	return &Collector{
+		cfg: cfg.GC,
+		collections: []collection{
+			{
+				kind:    "avatar",
+				storage: clients[buckets.Avatars],
+				prefix:  cfg.Avatar.KeyPrefix,
+				owner: func(key string) (string, bool) {
+					return ContentHash(cfg.Avatar.KeyPrefix, key)
+				},
+				existing:   avatars.AvatarHashesInUse,
+				dependents: avatarThumbnails,
+			},
+			{
+				kind:    "attachment",
+				storage: clients[buckets.Invoices],
+				prefix:  cfg.Attachment.KeyPrefix,
+				owner: func(key string) (string, bool) {
+					return attachmentOrder(cfg.Attachment.KeyPrefix, key)
+				},
+				existing:   attachments.ExistingOrderIDs,
+				dependents: func(string) []string { return nil },
+			},
+		},
+		metrics:  metrics,
+		log:      log,
+		now:      time.Now,
+		stopping: make(chan struct{}),
+	}
+}
+
+// Collect deletes the files stored before the grace period that nothing
+// refers to, or only reports them in a dry run. An upload refers to the file
+// it stored within its request, and an avatar upload stores the file again
+// when it is there already, so a recent file may be on its way to a record
+// and is left alone. A kind that fails does not stop the others.
+func (c *Collector) Collect(ctx context.Context) (Report, error) {
+	start := time.Now()
+	report := Report{}
+	var errs []error
+	for _, coll := range c.collections {
+		tally, err := c.collectAll(ctx, coll)
+		report[coll.kind] = tally
+		c.metrics.orphans.Set(float64(tally.Orphans), coll.kind)
+		c.metrics.bytes.Set(float64(tally.Bytes), coll.kind)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	err := errors.Join(errs...)
+	result := "succeeded"
+	if err != nil {
+		result = "failed"
+	}
+	c.metrics.runs.Inc(result)
+	c.metrics.duration.Observe(time.Since(start).Seconds())
+	return report, err
+}
+
+// collectAll collects the files of one kind, a page at a time
+func (c *Collector) collectAll(ctx context.Context, coll collection) (Tally, error) {
+	var tally Tally
+	opts := storagex.ListOptions{Prefix: coll.prefix}
+	for {
+		page, err := coll.storage.List(ctx, opts)
+		if err != nil {
+			return tally, fmt.Errorf("failed to list %ss: %w", coll.kind, err)
+		}
+
+		if err := c.collect(ctx, coll, page.Keys, &tally); err != nil {
+			return tally, err
+		}
+
+		if !page.IsTruncated {
+			return tally, nil
+		}
+		opts.ContinuationToken = page.NextToken
+	}
+}
+
+// collect deletes those of a page of files that may be collected, and counts
+// them in tally
+func (c *Collector) collect(ctx context.Context, coll collection, stats []storagex.Stat, tally *Tally) error {
+	files := map[string][]storagex.Stat{}
+	var owners []string
+	for _, stat := range stats {
+		owner, ok := coll.owner(stat.Key)
+		if !ok || !c.expired(stat) {
+			continue
+		}
+		if _, seen := files[owner]; !seen {
+			owners = append(owners, owner)
+		}
+		files[owner] = append(files[owner], stat)
+	}
+	if len(owners) == 0 {
+		return nil
+	}
+
+	existing, err := coll.existing(ctx, owners)
+	if err != nil {
+		return fmt.Errorf("failed to find the %ss in use: %w", coll.kind, err)
+	}
+	for _, owner := range existing {
+		delete(files, owner)
+	}
+
+	for _, owner := range owners {
+		for _, stat := range files[owner] {
+			tally.Orphans++
+			tally.Bytes += stat.Size
+			if c.cfg.DryRun {
+				c.log.Info("would delete orphaned upload",
+					logx.String("kind", coll.kind),
+					logx.String("key", stat.Key),
+					logx.Int("size", int(stat.Size)),
+					logx.String("last_modified", stat.LastModified.Format(time.RFC3339)),
+				)
+				continue
+			}
+			if c.delete(ctx, coll, stat.Key) {
+				tally.Deleted++
+				c.metrics.deleted.Inc(coll.kind)
+			}
+		}
+	}
+	return nil
+}
+
+// delete deletes the file at key and its dependents, unless it was stored
+// again since it was listed. It reports whether the file was deleted.
+func (c *Collector) delete(ctx context.Context, coll collection, key string) bool {
+	stat, err := coll.storage.Head(ctx, key)
+	if err != nil || !c.expired(stat) {
+		return false
+	}
+
+	// The dependents go first, so none is left behind without its file
+	for _, dependent := range coll.dependents(key) {
+		if err := coll.storage.Delete(ctx, dependent); err != nil && !errors.Is(err, storagex.ErrNotFound) {
+			c.log.Warn("failed to delete upload", logx.String("kind", coll.kind), logx.String("key", dependent), logx.Err(err))
+			return false
+		}
+	}
+	if err := coll.storage.Delete(ctx, key); err != nil {
+		if !errors.Is(err, storagex.ErrNotFound) {
+			c.log.Warn("failed to delete upload", logx.String("kind", coll.kind), logx.String("key", key), logx.Err(err))
+		}
+		return false
+	}
+	return true
+}
+
+// expired reports whether the file was stored before the grace period
+func (c *Collector) expired(stat storagex.Stat) bool {
+	return stat.LastModified.Before(c.now().Add(-c.cfg.GracePeriod))
+}
+
+// avatarThumbnails returns the keys of the thumbnails of the avatar at key
+func avatarThumbnails(key string) []string {
+	if !domain.HasAvatarThumbnails(key) {
+		return nil
+	}
+	thumbnails := make([]string, 0, len(domain.AvatarVariants))
+	for _, variant := range domain.AvatarVariants {
+		thumbnails = append(thumbnails, domain.AvatarVariantKey(key, variant))
+	}
+	return thumbnails
+}
+
+// attachmentOrder returns the id of the order an attachment at key, under
+// prefix, is stored for. It reports false for keys not laid out as the
+// upload handler lays them out, <prefix><order id>/<file>.
+func attachmentOrder(prefix, key string) (string, bool) {
+	orderID, name, ok := strings.Cut(strings.TrimPrefix(key, prefix), "/")
+	if !strings.HasPrefix(key, prefix) || !ok || orderID == "" || name == "" || strings.Contains(name, "/") {
+		return "", false
+	}
+	return orderID, true
+}
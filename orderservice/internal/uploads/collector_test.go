@@ -0,0 +1,165 @@
+package uploads_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/orderservice/internal/buckets"
+	"github.com/gostratum/examples/orderservice/internal/testutil"
+	"github.com/gostratum/examples/orderservice/internal/uploads"
+)
+
+func TestContentKey(t *testing.T) {
+	key, hash := uploads.ContentKey("avatars/", []byte("hello"), "image/jpeg")
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", hash)
+	assert.Equal(t, "avatars/"+hash+".jpg", key)
+
+	got, ok := uploads.ContentHash("avatars/", key)
+	assert.True(t, ok)
+	assert.Equal(t, hash, got)
+
+	// Hashed a read at a time, a file gets the same key
+	streamed, err := uploads.HashContent(strings.NewReader("hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, key, uploads.HashKey("avatars/", streamed, "image/jpeg"))
+
+	for _, key := range []string{
+		"avatars/u1_1700000000.png",
+		"avatars/" + strings.ToUpper(hash) + ".jpg",
+		"avatars/nested/" + hash + ".jpg",
+		"thumbnails/small/" + hash + ".jpg",
+	} {
+		_, ok := uploads.ContentHash("avatars/", key)
+		assert.False(t, ok, key)
+	}
+}
+
+// references are the avatar hashes users have, or the ids orders have
+type references struct {
+	known []string
+	err   error
+}
+
+func (r *references) AvatarHashesInUse(ctx context.Context, hashes []string) ([]string, error) {
+	return r.find(hashes)
+}
+
+func (r *references) ExistingOrderIDs(ctx context.Context, ids []string) ([]string, error) {
+	return r.find(ids)
+}
+
+func (r *references) find(refs []string) ([]string, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	var found []string
+	for _, ref := range refs {
+		if slices.Contains(r.known, ref) {
+			found = append(found, ref)
+		}
+	}
+	return found, nil
+}
+
+// orphanedFiles stores avatars and attachments in use and orphaned, and
+// returns the references to those in use
+func orphanedFiles(t *testing.T, storage *testutil.MemoryStorage) (avatars, orders *references, used string) {
+	t.Helper()
+	used, usedHash := uploads.ContentKey("avatars/", []byte("used"), "image/png")
+	unused, unusedHash := uploads.ContentKey("avatars/", []byte("unused"), "image/png")
+	webp, _ := uploads.ContentKey("avatars/", []byte("webp"), "image/webp")
+	for _, key := range []string{
+		used, unused, webp,
+		"thumbnails/small/" + unusedHash + ".png",
+		"thumbnails/medium/" + unusedHash + ".png",
+		// Stored before content keys, and not the collector's
+		"avatars/u1_1700000000.png",
+		"attachments/o1/a_invoice.pdf",
+		"attachments/o1/b_receipt.pdf",
+		"attachments/gone/c_invoice.pdf",
+		// Not laid out as attachments are
+		"attachments/stray.pdf",
+		"attachments/o2/nested/d.pdf",
+	} {
+		_, err := storage.Put(context.Background(), key, strings.NewReader(key), nil)
+		require.NoError(t, err)
+	}
+	return &references{known: []string{usedHash}}, &references{known: []string{"o1", "o2"}}, used
+}
+
+func TestCollector_Collect(t *testing.T) {
+	ctx := context.Background()
+	cfg := baseConfig()
+	storage := testutil.NewMemoryStorage()
+	avatars, orders, used := orphanedFiles(t, storage)
+	metrics, recorded := uploads.NewRecordingMetrics()
+	c := uploads.NewCollector(cfg, buckets.Clients{buckets.Avatars: storage, buckets.Invoices: storage}, avatars, orders, metrics, logx.NewNoopLogger())
+
+	// Within the grace period, nothing is collected
+	report, err := c.Collect(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uploads.Report{"avatar": {}, "attachment": {}}, report)
+	assert.Len(t, storage.Keys(), 11)
+
+	uploads.SetCollectorNow(c, func() time.Time { return time.Now().Add(cfg.GC.GracePeriod + time.Minute) })
+
+	// Nothing of a kind is deleted when its references cannot be looked up,
+	// and the other kind is collected all the same
+	avatars.err = errors.New("database is down")
+	_, err = c.Collect(ctx)
+	assert.ErrorContains(t, err, "database is down")
+	assert.Len(t, storage.Keys(), 10)
+	assert.NotContains(t, storage.Keys(), "attachments/gone/c_invoice.pdf")
+	assert.Equal(t, 1.0, recorded.Get("runs", "failed"))
+
+	avatars.err = nil
+	report, err = c.Collect(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uploads.Report{
+		"avatar":     {Orphans: 2, Bytes: int64(len(used)*2 + 1), Deleted: 2},
+		"attachment": {},
+	}, report)
+	assert.Equal(t, []string{
+		"attachments/o1/a_invoice.pdf",
+		"attachments/o1/b_receipt.pdf",
+		"attachments/o2/nested/d.pdf",
+		"attachments/stray.pdf",
+		used,
+		"avatars/u1_1700000000.png",
+	}, storage.Keys())
+
+	assert.Equal(t, 2.0, recorded.Get("deleted", "avatar"))
+	assert.Equal(t, 1.0, recorded.Get("deleted", "attachment"))
+	assert.Equal(t, 0.0, recorded.Get("orphans", "attachment"), "the gauges hold the last collection")
+	assert.Equal(t, 2.0, recorded.Get("runs", "succeeded"))
+}
+
+func TestCollector_DryRun(t *testing.T) {
+	ctx := context.Background()
+	cfg := baseConfig()
+	cfg.GC.DryRun = true
+	storage := testutil.NewMemoryStorage()
+	avatars, orders, _ := orphanedFiles(t, storage)
+	metrics, recorded := uploads.NewRecordingMetrics()
+	c := uploads.NewCollector(cfg, buckets.Clients{buckets.Avatars: storage, buckets.Invoices: storage}, avatars, orders, metrics, logx.NewNoopLogger())
+	uploads.SetCollectorNow(c, func() time.Time { return time.Now().Add(cfg.GC.GracePeriod + time.Minute) })
+
+	report, err := c.Collect(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report["avatar"].Orphans)
+	assert.Equal(t, uploads.Tally{Orphans: 1, Bytes: int64(len("attachments/gone/c_invoice.pdf"))}, report["attachment"])
+	assert.Zero(t, report["avatar"].Deleted)
+	assert.Len(t, storage.Keys(), 11, "nothing is deleted")
+
+	assert.Equal(t, 2.0, recorded.Get("orphans", "avatar"))
+	assert.Equal(t, float64(len("attachments/gone/c_invoice.pdf")), recorded.Get("bytes", "attachment"))
+	assert.Zero(t, recorded.Get("deleted", "avatar"))
+}
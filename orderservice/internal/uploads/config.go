@@ -0,0 +1,220 @@
+// Package uploads takes large files in parts, so a client on a poor connection
+// resends the part that failed instead of the whole file. A session is
+// created for the size of the file; each part is stored as an object of its
+// own as it arrives, and completing the session streams the parts, in order,
+// into the file's object. storagex writes an object of unknown length as a
+// multipart upload on S3, so the file is never held whole in memory. Sessions
+// nobody completes expire, and their parts are deleted.
+//
+// Config also bounds what may be uploaded, whole or in parts: the size, the
+// MIME types and where each kind of file is stored. Avatars are stored under
+// the hash of their content. The Collector deletes the avatars no user refers
+// to and the attachments of orders that no longer exist.
+package uploads
+
+import (
+	"fmt"
+	"io/fs"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/gostratum/core/configx"
+)
+
+// Config sizes the parts, bounds how long a session waits for them, and
+// bounds the files of each kind
+type Config struct {
+	// PartSize is the size of every part but the last, which holds the rest.
+	// S3 takes no multipart parts under 5 MiB but the last.
+	PartSize int64 `mapstructure:"part_size" default:"5242880"`
+	// SessionTTL is how long a session lasts after its last part arrived
+	SessionTTL time.Duration `mapstructure:"session_ttl" default:"24h"`
+	// CleanupInterval is how often expired sessions are looked for
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval" default:"10m"`
+	// PartPrefix holds the parts until their session completes or expires,
+	// and avatars sent whole until they are stored under their hash
+	PartPrefix string `mapstructure:"part_prefix" default:"parts/"`
+	// ProgressStep is how many bytes of a file are streamed between progress
+	// logs, so smaller files log none; zero turns them off
+	ProgressStep int64 `mapstructure:"progress_step" default:"8388608"`
+
+	Avatar     AvatarConfig     `mapstructure:"avatar"`
+	Attachment AttachmentConfig `mapstructure:"attachment"`
+	// GC collects the avatars and attachments nothing refers to
+	GC GCConfig `mapstructure:"gc"`
+}
+
+// AvatarConfig bounds user avatars
+type AvatarConfig struct {
+	// MaxSize is the largest avatar, in bytes. Thumbnails are made of avatars
+	// up to 10 MiB.
+	MaxSize int64 `mapstructure:"max_size" default:"10485760"`
+	// MaxFormSize is the largest avatar sent whole to POST /users/:id/avatar;
+	// larger ones are sent in parts
+	MaxFormSize int64 `mapstructure:"max_form_size" default:"5242880"`
+	// AllowedTypes are the image types avatars may have
+	AllowedTypes []string `mapstructure:"allowed_types"`
+	// KeyPrefix is where avatars are stored
+	KeyPrefix string `mapstructure:"key_prefix" default:"avatars/"`
+	// StripMetadata removes EXIF, such as the GPS position and the device,
+	// and other metadata from avatars before they are stored
+	StripMetadata bool `mapstructure:"strip_metadata" default:"true"`
+}
+
+// AttachmentConfig bounds order attachments
+type AttachmentConfig struct {
+	// MaxSize is the largest attachment, in bytes
+	MaxSize int64 `mapstructure:"max_size" default:"104857600"`
+	// MaxFormSize is the largest attachment sent whole to
+	// POST /orders/:id/attachments; larger ones are sent in parts
+	MaxFormSize int64 `mapstructure:"max_form_size" default:"10485760"`
+	// AllowedTypes are the MIME types attachments may have; empty allows any
+	AllowedTypes []string `mapstructure:"allowed_types"`
+	// KeyPrefix is where attachments are stored, under the order's id
+	KeyPrefix string `mapstructure:"key_prefix" default:"attachments/"`
+}
+
+// GCConfig sets how often the avatars and attachments nothing refers to are
+// deleted
+type GCConfig struct {
+	// Interval is how often the files are looked through
+	Interval time.Duration `mapstructure:"interval" default:"1h"`
+	// GracePeriod keeps a file stored this recently, as the upload that
+	// stored it may not have recorded it yet
+	GracePeriod time.Duration `mapstructure:"grace_period" default:"24h"`
+	// DryRun logs and counts the files that would be deleted, and deletes
+	// none
+	DryRun bool `mapstructure:"dry_run"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "uploads"
+}
+
+// NewConfig loads the uploads section, and fails when it is not valid, so a
+// mistake stops the service at startup rather than failing uploads
+func NewConfig(loader configx.Loader) (Config, error) {
+	var cfg Config
+	if err := loader.Bind(&cfg); err != nil {
+		return Config{}, fmt.Errorf("failed to load uploads config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid uploads config: %w", err)
+	}
+	return cfg, nil
+}
+
+// Validate checks sizes and durations are positive, prefixes are distinct
+// directories, and MIME types parse
+func (c Config) Validate() error {
+	for name, v := range map[string]int64{
+		"part_size":                c.PartSize,
+		"session_ttl":              int64(c.SessionTTL),
+		"cleanup_interval":         int64(c.CleanupInterval),
+		"avatar.max_size":          c.Avatar.MaxSize,
+		"avatar.max_form_size":     c.Avatar.MaxFormSize,
+		"attachment.max_size":      c.Attachment.MaxSize,
+		"attachment.max_form_size": c.Attachment.MaxFormSize,
+		"gc.interval":              int64(c.GC.Interval),
+		"gc.grace_period":          int64(c.GC.GracePeriod),
+	} {
+		if v <= 0 {
+			return fmt.Errorf("%s must be positive", name)
+		}
+	}
+	if c.ProgressStep < 0 {
+		return fmt.Errorf("progress_step must not be negative")
+	}
+	if c.Avatar.MaxFormSize > c.Avatar.MaxSize {
+		return fmt.Errorf("avatar.max_form_size (%d) must not exceed avatar.max_size (%d)", c.Avatar.MaxFormSize, c.Avatar.MaxSize)
+	}
+	if c.Attachment.MaxFormSize > c.Attachment.MaxSize {
+		return fmt.Errorf("attachment.max_form_size (%d) must not exceed attachment.max_size (%d)", c.Attachment.MaxFormSize, c.Attachment.MaxSize)
+	}
+
+	prefixes := map[string]string{
+		"part_prefix":           c.PartPrefix,
+		"avatar.key_prefix":     c.Avatar.KeyPrefix,
+		"attachment.key_prefix": c.Attachment.KeyPrefix,
+	}
+	for name, prefix := range prefixes {
+		if !strings.HasSuffix(prefix, "/") || !fs.ValidPath(strings.TrimSuffix(prefix, "/")) {
+			return fmt.Errorf("%s must be a relative directory ending in a slash, got %q", name, prefix)
+		}
+		for other, otherPrefix := range prefixes {
+			if name != other && strings.HasPrefix(prefix, otherPrefix) {
+				return fmt.Errorf("%s (%q) must not be inside %s (%q)", name, prefix, other, otherPrefix)
+			}
+		}
+	}
+
+	if len(c.Avatar.AllowedTypes) == 0 {
+		return fmt.Errorf("avatar.allowed_types must list at least one image type")
+	}
+	for _, t := range c.Avatar.AllowedTypes {
+		if !strings.HasPrefix(mediaType(t), "image/") {
+			return fmt.Errorf("avatar.allowed_types must be image types, got %q", t)
+		}
+	}
+	for _, t := range c.Attachment.AllowedTypes {
+		if !strings.Contains(mediaType(t), "/") {
+			return fmt.Errorf("attachment.allowed_types must be MIME types such as application/pdf, got %q", t)
+		}
+	}
+	return nil
+}
+
+// Allows reports whether an avatar may have contentType
+func (c AvatarConfig) Allows(contentType string) bool {
+	return allows(c.AllowedTypes, contentType)
+}
+
+// Allows reports whether an attachment may have contentType
+func (c AttachmentConfig) Allows(contentType string) bool {
+	return len(c.AllowedTypes) == 0 || allows(c.AllowedTypes, contentType)
+}
+
+func allows(types []string, contentType string) bool {
+	contentType = mediaType(contentType)
+	for _, t := range types {
+		if contentType != "" && contentType == mediaType(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// extensions are the extensions keys get for common types, where mime knows
+// several
+var extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// Extension returns the extension a file of contentType is stored with, so
+// the key says what the content is whatever the client named the file. It is
+// empty for types without one.
+func Extension(contentType string) string {
+	contentType = mediaType(contentType)
+	if ext, ok := extensions[contentType]; ok {
+		return ext
+	}
+	if exts, err := mime.ExtensionsByType(contentType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
+// mediaType returns the type of contentType in lower case, without
+// parameters, or "" when it does not parse
+func mediaType(contentType string) string {
+	t, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return t
+}
@@ -0,0 +1,90 @@
+package uploads_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gostratum/examples/orderservice/internal/uploads"
+)
+
+// baseConfig is the uploads section of configs/base.yaml
+func baseConfig() uploads.Config {
+	cfg := testConfig
+	cfg.PartSize = 5 << 20
+	cfg.ProgressStep = 8 << 20
+	cfg.Avatar = uploads.AvatarConfig{
+		MaxSize:       10 << 20,
+		MaxFormSize:   5 << 20,
+		AllowedTypes:  []string{"image/jpeg", "image/png", "image/gif", "image/webp"},
+		KeyPrefix:     "avatars/",
+		StripMetadata: true,
+	}
+	cfg.Attachment = uploads.AttachmentConfig{
+		MaxSize:     100 << 20,
+		MaxFormSize: 10 << 20,
+		KeyPrefix:   "attachments/",
+	}
+	cfg.GC = uploads.GCConfig{Interval: time.Hour, GracePeriod: 24 * time.Hour}
+	return cfg
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, baseConfig().Validate())
+
+	tests := []struct {
+		name   string
+		change func(c *uploads.Config)
+		want   string
+	}{
+		{"no part size", func(c *uploads.Config) { c.PartSize = 0 }, "part_size must be positive"},
+		{"negative progress step", func(c *uploads.Config) { c.ProgressStep = -1 }, "progress_step must not be negative"},
+		{"negative avatar size", func(c *uploads.Config) { c.Avatar.MaxSize = -1 }, "avatar.max_size must be positive"},
+		{"no grace period", func(c *uploads.Config) { c.GC.GracePeriod = 0 }, "gc.grace_period must be positive"},
+		{"form larger than avatars", func(c *uploads.Config) { c.Avatar.MaxFormSize = 20 << 20 }, "must not exceed avatar.max_size"},
+		{"form larger than attachments", func(c *uploads.Config) { c.Attachment.MaxFormSize = 200 << 20 }, "must not exceed attachment.max_size"},
+		{"no avatar types", func(c *uploads.Config) { c.Avatar.AllowedTypes = nil }, "at least one image type"},
+		{"avatar type not an image", func(c *uploads.Config) { c.Avatar.AllowedTypes = []string{"application/pdf"} }, "must be image types"},
+		{"attachment type that is not a MIME type", func(c *uploads.Config) { c.Attachment.AllowedTypes = []string{"pdf"} }, "must be MIME types"},
+		{"prefix without a slash", func(c *uploads.Config) { c.Avatar.KeyPrefix = "avatars" }, "ending in a slash"},
+		{"absolute prefix", func(c *uploads.Config) { c.Attachment.KeyPrefix = "/attachments/" }, "relative directory"},
+		{"prefix outside", func(c *uploads.Config) { c.Attachment.KeyPrefix = "../attachments/" }, "relative directory"},
+		{"parts among avatars", func(c *uploads.Config) { c.PartPrefix = "avatars/parts/" }, "must not be inside"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := baseConfig()
+			tt.change(&cfg)
+			assert.ErrorContains(t, cfg.Validate(), tt.want)
+		})
+	}
+}
+
+func TestConfig_Allows(t *testing.T) {
+	cfg := baseConfig()
+	assert.True(t, cfg.Avatar.Allows("image/png"))
+	assert.True(t, cfg.Avatar.Allows("IMAGE/JPEG; charset=binary"), "case and parameters do not matter")
+	assert.False(t, cfg.Avatar.Allows("image/svg+xml"))
+	assert.False(t, cfg.Avatar.Allows(""))
+
+	assert.True(t, cfg.Attachment.Allows("application/pdf"), "any type without allowed_types")
+	assert.True(t, cfg.Attachment.Allows(""))
+	cfg.Attachment.AllowedTypes = []string{"application/pdf"}
+	assert.True(t, cfg.Attachment.Allows("application/pdf"))
+	assert.False(t, cfg.Attachment.Allows("text/html"))
+}
+
+func TestExtension(t *testing.T) {
+	for contentType, want := range map[string]string{
+		"image/jpeg":                 ".jpg",
+		"image/PNG":                  ".png",
+		"image/gif":                  ".gif",
+		"image/webp":                 ".webp",
+		"application/pdf":            ".pdf",
+		"application/x-no-such-type": "",
+		"":                           "",
+	} {
+		assert.Equal(t, want, uploads.Extension(contentType), "extension of %q", contentType)
+	}
+}
@@ -0,0 +1,49 @@
+package uploads
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"path"
+	"strings"
+)
+
+// ContentKey returns the key data is stored at under prefix, named by the
+// hex SHA-256 of data with the extension of contentType, and the hash. The
+// same file uploaded twice, by one user or two, gets the same key, and what
+// is at a key never changes, so it may be cached for good.
+func ContentKey(prefix string, data []byte, contentType string) (key, hash string) {
+	sum := sha256.Sum256(data)
+	hash = hex.EncodeToString(sum[:])
+	return HashKey(prefix, hash, contentType), hash
+}
+
+// HashKey returns the key a file of contentType whose content hashes to hash
+// is stored at under prefix, as ContentKey names it
+func HashKey(prefix, hash, contentType string) string {
+	return prefix + hash + Extension(contentType)
+}
+
+// HashContent returns the hex SHA-256 of what r reads, a read at a time, for
+// files too large to hold whole
+func HashContent(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ContentHash returns the hash a key under prefix was named by, and false for
+// keys ContentKey does not return, such as those of avatars stored before it
+func ContentHash(prefix, key string) (string, bool) {
+	name, ok := strings.CutPrefix(key, prefix)
+	if !ok || strings.Contains(name, "/") {
+		return "", false
+	}
+	hash := strings.TrimSuffix(name, path.Ext(name))
+	if len(hash) != 2*sha256.Size || strings.Trim(hash, "0123456789abcdef") != "" {
+		return "", false
+	}
+	return hash, true
+}
@@ -0,0 +1,68 @@
+package uploads
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrTooLarge indicates a file longer than the limit it was read under
+var ErrTooLarge = errors.New("file too large")
+
+// CountingReader counts the bytes read from R, so a file streamed to storage
+// is measured and bounded as it goes rather than held whole first, and
+// reports how far it got
+type CountingReader struct {
+	R io.Reader
+	// Limit fails reads past it with ErrTooLarge; zero does not limit
+	Limit int64
+	// Step is how many bytes are read between calls to Progress; zero makes
+	// none
+	Step int64
+	// Progress is called with the bytes read so far, once Step more were
+	// read
+	Progress func(n int64)
+
+	n        int64
+	reported int64
+	err      error
+}
+
+// Read implements io.Reader. Once more than Limit bytes were read, it reads
+// no more and fails with ErrTooLarge.
+func (r *CountingReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if r.Limit > 0 && int64(len(p)) > r.Limit-r.n+1 {
+		p = p[:r.Limit-r.n+1]
+	}
+
+	n, err := r.R.Read(p)
+	r.n += int64(n)
+	if r.Limit > 0 && r.n > r.Limit {
+		n -= int(r.n - r.Limit)
+		r.n = r.Limit + 1
+		err = ErrTooLarge
+	}
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+
+	if r.Step > 0 && r.Progress != nil && r.n-r.reported >= r.Step {
+		r.reported = r.n - r.n%r.Step
+		r.Progress(r.n)
+	}
+	return n, err
+}
+
+// N returns how many bytes were read, or Limit+1 once reads went past it
+func (r *CountingReader) N() int64 {
+	return r.n
+}
+
+// Err returns the error reading failed with, other than io.EOF, such as
+// ErrTooLarge or the client going away, or nil. A write of the reader that
+// failed without it failed on the writing side.
+func (r *CountingReader) Err() error {
+	return r.err
+}
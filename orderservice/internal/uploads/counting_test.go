@@ -0,0 +1,60 @@
+package uploads_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gostratum/examples/orderservice/internal/uploads"
+)
+
+func TestCountingReader(t *testing.T) {
+	var reported []int64
+	r := &uploads.CountingReader{
+		// One byte a read, so progress is seen at every step
+		R:        iotest.OneByteReader(strings.NewReader("0123456789")),
+		Limit:    10,
+		Step:     4,
+		Progress: func(n int64) { reported = append(reported, n) },
+	}
+
+	data, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "0123456789", string(data))
+	assert.EqualValues(t, 10, r.N())
+	assert.NoError(t, r.Err())
+	assert.Equal(t, []int64{4, 8}, reported, "a report every 4 bytes, and none for the rest")
+}
+
+func TestCountingReader_Limit(t *testing.T) {
+	r := &uploads.CountingReader{R: strings.NewReader("0123456789"), Limit: 4}
+
+	data, err := io.ReadAll(r)
+	assert.ErrorIs(t, err, uploads.ErrTooLarge)
+	assert.Equal(t, "0123", string(data), "nothing past the limit is returned")
+	assert.EqualValues(t, 5, r.N())
+	assert.ErrorIs(t, r.Err(), uploads.ErrTooLarge)
+
+	_, err = r.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, uploads.ErrTooLarge, "reads stay failed")
+
+	// A file of exactly the limit is read whole
+	r = &uploads.CountingReader{R: strings.NewReader("0123"), Limit: 4}
+	data, err = io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "0123", string(data))
+}
+
+func TestCountingReader_ReadError(t *testing.T) {
+	broken := errors.New("connection reset")
+	r := &uploads.CountingReader{R: io.MultiReader(strings.NewReader("01"), iotest.ErrReader(broken))}
+
+	_, err := io.ReadAll(r)
+	assert.ErrorIs(t, err, broken)
+	assert.ErrorIs(t, r.Err(), broken, "the writer of the reader can tell a read failed")
+	assert.EqualValues(t, 2, r.N())
+}
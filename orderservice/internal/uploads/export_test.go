@@ -0,0 +1,69 @@
+package uploads
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// SetNow replaces the clock of s, so tests can expire sessions
+func SetNow(s *Service, now func() time.Time) {
+	s.now = now
+}
+
+// SetCollectorNow replaces the clock of c, so tests can end grace periods
+func SetCollectorNow(c *Collector, now func() time.Time) {
+	c.now = now
+}
+
+// MetricsRecorder holds what a Metrics recorded: the last value set, or the
+// number of increments and observations, by metric and labels
+type MetricsRecorder struct {
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// Get returns what was recorded for the metric with labels
+func (r *MetricsRecorder) Get(name string, labels ...string) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.values[metricKey(name, labels)]
+}
+
+// NewRecordingMetrics returns Metrics that record into the returned recorder
+func NewRecordingMetrics() (*Metrics, *MetricsRecorder) {
+	r := &MetricsRecorder{values: map[string]float64{}}
+	m := func(name string) recordedMetric { return recordedMetric{r: r, name: name} }
+	return &Metrics{
+		orphans:  m("orphans"),
+		bytes:    m("bytes"),
+		deleted:  m("deleted"),
+		runs:     m("runs"),
+		duration: m("duration"),
+	}, r
+}
+
+type recordedMetric struct {
+	r    *MetricsRecorder
+	name string
+}
+
+func (m recordedMetric) Inc(labels ...string) {
+	m.r.mu.Lock()
+	defer m.r.mu.Unlock()
+	m.r.values[metricKey(m.name, labels)]++
+}
+
+func (m recordedMetric) Observe(v float64, labels ...string) {
+	m.Inc(labels...)
+}
+
+func (m recordedMetric) Set(v float64, labels ...string) {
+	m.r.mu.Lock()
+	defer m.r.mu.Unlock()
+	m.r.values[metricKey(m.name, labels)] = v
+}
+
+func metricKey(name string, labels []string) string {
+	return name + "{" + strings.Join(labels, ",") + "}"
+}
@@ -0,0 +1,76 @@
+package uploads
+
+import (
+	"github.com/gostratum/metricsx"
+	"go.uber.org/fx"
+)
+
+// Metrics records the collections of files nothing refers to
+type Metrics struct {
+	orphans  gauge
+	bytes    gauge
+	deleted  counter
+	runs     counter
+	duration histogram
+}
+
+// MetricsParams are the dependencies of NewMetrics
+type MetricsParams struct {
+	fx.In
+
+	// Metrics is missing when the application runs without metricsx.Module,
+	// as the test apps do
+	Metrics metricsx.Metrics `optional:"true"`
+}
+
+// NewMetrics registers the collection metrics, or records none without
+// metricsx
+func NewMetrics(p MetricsParams) *Metrics {
+	if p.Metrics == nil {
+		return &Metrics{orphans: discard{}, bytes: discard{}, deleted: discard{}, runs: discard{}, duration: discard{}}
+	}
+	metrics := p.Metrics
+	return &Metrics{
+		orphans: metrics.Gauge("uploads_gc_orphans",
+			metricsx.WithHelp("Files nothing refers to past the grace period, by kind, at the last collection"),
+			metricsx.WithLabels("kind"),
+		),
+		bytes: metrics.Gauge("uploads_gc_orphan_bytes",
+			metricsx.WithHelp("Size of the files nothing refers to, by kind, at the last collection"),
+			metricsx.WithLabels("kind"),
+		),
+		deleted: metrics.Counter("uploads_gc_deleted_total",
+			metricsx.WithHelp("Files nothing referred to that were deleted, by kind"),
+			metricsx.WithLabels("kind"),
+		),
+		runs: metrics.Counter("uploads_gc_runs_total",
+			metricsx.WithHelp("Collections, by result (succeeded, failed)"),
+			metricsx.WithLabels("result"),
+		),
+		duration: metrics.Histogram("uploads_gc_duration_seconds",
+			metricsx.WithHelp("Time a collection took"),
+		),
+	}
+}
+
+// counter is the part of metricsx.Counter the collector uses
+type counter interface {
+	Inc(labels ...string)
+}
+
+// histogram is the part of metricsx.Histogram the collector uses
+type histogram interface {
+	Observe(v float64, labels ...string)
+}
+
+// gauge is the part of metricsx.Gauge the collector uses
+type gauge interface {
+	Set(v float64, labels ...string)
+}
+
+// discard is a counter, histogram and gauge that records nothing
+type discard struct{}
+
+func (discard) Inc(labels ...string)                {}
+func (discard) Observe(v float64, labels ...string) {}
+func (discard) Set(v float64, labels ...string)     {}
@@ -0,0 +1,146 @@
+package uploads
+
+import (
+	"context"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"go.uber.org/fx"
+)
+
+// expireTimeout bounds one pass of the janitor, deleting parts included
+const expireTimeout = time.Minute
+
+// collectTimeout bounds one collection of avatars and attachments
+const collectTimeout = 10 * time.Minute
+
+// Module provides the validated config, the service and the collector, and
+// expires abandoned sessions and collects unused files while the application
+// runs. The application provides the AvatarReferences and the
+// AttachmentReferences; without metricsx the collector records no metrics.
+func Module() fx.Option {
+	return fx.Module("uploads",
+		fx.Provide(
+			NewConfig,
+			New,
+			NewMetrics,
+			NewCollector,
+		),
+		fx.Invoke(Register, RegisterCollector),
+	)
+}
+
+// Register ties the janitor to the application lifecycle.
+// This function is designed to be used with fx.Invoke.
+func Register(lc fx.Lifecycle, s *Service) {
+	lc.Append(fx.Hook{OnStart: s.start, OnStop: s.stop})
+}
+
+func (s *Service) start(context.Context) error {
+	s.wg.Add(1)
+	go s.janitor()
+	return nil
+}
+
+func (s *Service) stop(ctx context.Context) error {
+	close(s.stopping)
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// janitor expires sessions every cleanup interval. Sessions still open at
+// stop are lost with the process, and their parts stay in storage.
+func (s *Service) janitor() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.cfg.CleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopping:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), expireTimeout)
+		if n := s.Expire(ctx); n > 0 {
+			s.log.Info("expired upload sessions", logx.Int("sessions", n))
+		}
+		cancel()
+	}
+}
+
+// RegisterCollector ties the collection of unused files to the application
+// lifecycle.
+// This function is designed to be used with fx.Invoke.
+func RegisterCollector(lc fx.Lifecycle, c *Collector) {
+	lc.Append(fx.Hook{OnStart: c.start, OnStop: c.stop})
+}
+
+func (c *Collector) start(context.Context) error {
+	c.wg.Add(1)
+	go c.loop()
+	return nil
+}
+
+func (c *Collector) stop(ctx context.Context) error {
+	close(c.stopping)
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loop collects files every GC interval. A collection cut short, by an error
+// or by stop, is taken up by the next one.
+func (c *Collector) loop() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stopping:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+		report, err := c.Collect(ctx)
+		cancel()
+		msg := "collected orphaned uploads"
+		if c.cfg.DryRun {
+			msg = "found orphaned uploads, not deleted in a dry run"
+		}
+		for kind, tally := range report {
+			if tally.Orphans > 0 {
+				c.log.Info(msg,
+					logx.String("kind", kind),
+					logx.Int("orphans", tally.Orphans),
+					logx.Int("deleted", tally.Deleted),
+				)
+			}
+		}
+		if err != nil {
+			c.log.Warn("failed to collect orphaned uploads", logx.Err(err))
+		}
+	}
+}
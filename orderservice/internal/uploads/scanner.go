@@ -0,0 +1,44 @@
+package uploads
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var (
+	// ErrInfected indicates a file the scanner flagged. The error wrapping it
+	// names what was found.
+	ErrInfected = errors.New("file is infected")
+
+	// ErrScanFailed indicates a file that could not be scanned, and was not
+	// stored
+	ErrScanFailed = errors.New("scan failed")
+)
+
+// Scanner checks files for malware before they are stored; implemented by
+// the ClamAV adapter
+type Scanner interface {
+	// Scan reads r, and fails with an error wrapping ErrInfected when the
+	// file is flagged. Any other error means it could not tell. It may stop
+	// reading once it has decided.
+	Scan(ctx context.Context, r io.Reader) error
+}
+
+// NopScanner is a Scanner that passes every file without reading it
+type NopScanner struct{}
+
+// Scan implements Scanner
+func (NopScanner) Scan(ctx context.Context, r io.Reader) error {
+	return nil
+}
+
+// Scan runs s over r, and wraps its failures other than ErrInfected in
+// ErrScanFailed, so a file is stored only when s passed it
+func Scan(ctx context.Context, s Scanner, r io.Reader) error {
+	err := s.Scan(ctx, r)
+	if err == nil || errors.Is(err, ErrInfected) {
+		return err
+	}
+	return errors.Join(ErrScanFailed, err)
+}
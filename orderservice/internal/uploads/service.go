@@ -0,0 +1,426 @@
+package uploads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/storagex"
+)
+
+var (
+	// ErrNotFound indicates the session does not exist, or has expired
+	ErrNotFound = errors.New("upload session not found")
+
+	// ErrInvalidPart indicates a part number outside the session, or a part
+	// whose size is not the one its number calls for
+	ErrInvalidPart = errors.New("invalid part")
+
+	// ErrIncomplete indicates Complete was called before every part arrived
+	ErrIncomplete = errors.New("upload incomplete")
+
+	// ErrInvalidSize indicates a session for an empty file
+	ErrInvalidSize = errors.New("invalid size")
+)
+
+// Target is what a session uploads to. The service stores the file at Key;
+// Kind and Owner are for the caller, which acts on the file once it is
+// complete.
+type Target struct {
+	// Kind of the file, e.g. avatar or attachment
+	Kind string
+	// Owner is the id of the user or order the file belongs to
+	Owner string
+	// Key the complete file is stored at
+	Key         string
+	ContentType string
+	// Storage the complete file is stored in; the service's, where the
+	// parts are, when nil
+	Storage storagex.Storage
+	// Filename is the name the client gave the file
+	Filename string
+}
+
+// Session describes an upload in progress
+type Session struct {
+	ID     string
+	Target Target
+	// Size of the whole file in bytes
+	Size     int64
+	PartSize int64
+	// Parts is how many parts the file is sent in, numbered from 1
+	Parts int
+	// Received lists the numbers of the parts stored so far, in order
+	Received  []int
+	ExpiresAt time.Time
+}
+
+// session is the state of one upload
+type session struct {
+	id        string
+	target    Target
+	size      int64
+	partSize  int64
+	parts     int
+	partsSeen map[int]bool
+	expiresAt time.Time
+
+	// io is held for reading while a part is written, so parts arrive in
+	// parallel, and for writing while the parts are joined or deleted
+	io sync.RWMutex
+	// mu guards partsSeen and expiresAt
+	mu sync.Mutex
+	// done is set once the session is completed or aborted
+	done bool
+}
+
+// Service keeps upload sessions. Sessions live in the memory of the instance
+// that created them, so behind a load balancer a session's requests must
+// reach one instance.
+type Service struct {
+	cfg     Config
+	storage storagex.Storage
+	scanner Scanner
+	log     logx.Logger
+	now     func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*session
+
+	// stopping ends the janitor
+	stopping chan struct{}
+	wg       sync.WaitGroup
+}
+
+// New creates the service from cfg, scanning each file with scanner before it
+// is joined. Only the settings of sessions are checked; NewConfig validates
+// the rest.
+func New(cfg Config, storage storagex.Storage, scanner Scanner, log logx.Logger) (*Service, error) {
+	if cfg.PartSize <= 0 || cfg.SessionTTL <= 0 || cfg.CleanupInterval <= 0 {
+		return nil, fmt.Errorf("uploads.part_size (%d), uploads.session_ttl (%s) and uploads.cleanup_interval (%s) must be positive",
+			cfg.PartSize, cfg.SessionTTL, cfg.CleanupInterval)
+	}
+	return &Service{
+		cfg:      cfg,
+		storage:  storage,
+		scanner:  scanner,
+		log:      log,
+		now:      time.Now,
+		sessions: make(map[string]*session),
+		stopping: make(chan struct{}),
+	}, nil
+}
+
+// Create starts a session for a file of size bytes
+func (s *Service) Create(target Target, size int64) (Session, error) {
+	if size <= 0 {
+		return Session{}, ErrInvalidSize
+	}
+	sess := &session{
+		id:        uuid.NewString(),
+		target:    target,
+		size:      size,
+		partSize:  s.cfg.PartSize,
+		parts:     int((size + s.cfg.PartSize - 1) / s.cfg.PartSize),
+		partsSeen: make(map[int]bool),
+		expiresAt: s.now().Add(s.cfg.SessionTTL),
+	}
+
+	s.mu.Lock()
+	s.sessions[sess.id] = sess
+	s.mu.Unlock()
+	return sess.snapshot(), nil
+}
+
+// Get returns the session, with the parts received so far, so a client that
+// lost track of them knows which to send
+func (s *Service) Get(id string) (Session, error) {
+	sess, err := s.open(id)
+	if err != nil {
+		return Session{}, err
+	}
+	return sess.snapshot(), nil
+}
+
+// PutPart stores part number n. Sending a part again replaces it, so a part
+// that failed halfway is simply sent again. Every part but the last must be
+// exactly PartSize long.
+func (s *Service) PutPart(ctx context.Context, id string, n int, r io.Reader) (Session, error) {
+	sess, err := s.open(id)
+	if err != nil {
+		return Session{}, err
+	}
+	if n < 1 || n > sess.parts {
+		return Session{}, fmt.Errorf("%w: part %d of %d", ErrInvalidPart, n, sess.parts)
+	}
+	want := sess.partLen(n)
+
+	sess.io.RLock()
+	defer sess.io.RUnlock()
+	if sess.isDone() {
+		return Session{}, ErrNotFound
+	}
+
+	key := s.partKey(sess.id, n)
+	counted := &CountingReader{R: io.LimitReader(r, want+1)}
+	if _, err := s.storage.Put(ctx, key, counted, &storagex.PutOptions{Overwrite: true}); err != nil {
+		return Session{}, fmt.Errorf("failed to store part %d: %w", n, err)
+	}
+	if counted.N() != want {
+		// Not recorded; the object is replaced when the part is sent again,
+		// or deleted with the session
+		return Session{}, fmt.Errorf("%w: part %d is %d bytes, want %d", ErrInvalidPart, n, counted.N(), want)
+	}
+
+	sess.mu.Lock()
+	sess.partsSeen[n] = true
+	sess.expiresAt = s.now().Add(s.cfg.SessionTTL)
+	sess.mu.Unlock()
+	return sess.snapshot(), nil
+}
+
+// Complete scans the file, joins the parts into the target object and ends
+// the session. It fails with ErrIncomplete, and keeps the session, while parts
+// are missing. A file the scanner flags fails with ErrInfected, and ends the
+// session without being stored; one it could not scan fails with
+// ErrScanFailed, and keeps the session, so Complete can be called again.
+func (s *Service) Complete(ctx context.Context, id string) (Session, storagex.Stat, error) {
+	sess, err := s.open(id)
+	if err != nil {
+		return Session{}, storagex.Stat{}, err
+	}
+
+	sess.io.Lock()
+	defer sess.io.Unlock()
+	if sess.isDone() {
+		return Session{}, storagex.Stat{}, ErrNotFound
+	}
+	snapshot := sess.snapshot()
+	if len(snapshot.Received) != sess.parts {
+		return snapshot, storagex.Stat{}, fmt.Errorf("%w: %d of %d parts received", ErrIncomplete, len(snapshot.Received), sess.parts)
+	}
+
+	keys := make([]string, sess.parts)
+	for i := range keys {
+		keys[i] = s.partKey(sess.id, i+1)
+	}
+
+	scanned := &partsReader{ctx: ctx, storage: s.storage, keys: keys}
+	err = Scan(ctx, s.scanner, scanned)
+	scanned.Close()
+	if errors.Is(err, ErrInfected) {
+		s.log.Warn("upload flagged by the malware scanner",
+			logx.String("upload_id", sess.id),
+			logx.String("key", sess.target.Key),
+			logx.Err(err),
+		)
+		s.end(ctx, sess)
+		return snapshot, storagex.Stat{}, err
+	}
+	if err != nil {
+		return snapshot, storagex.Stat{}, err
+	}
+
+	storage := sess.target.Storage
+	if storage == nil {
+		storage = s.storage
+	}
+	// Large files take a while to join, so how far the join got is logged
+	parts := &partsReader{ctx: ctx, storage: s.storage, keys: keys}
+	joined := &CountingReader{R: parts, Step: s.cfg.ProgressStep, Progress: func(n int64) {
+		s.log.Info("joining upload",
+			logx.String("upload_id", sess.id),
+			logx.String("key", sess.target.Key),
+			logx.Int("bytes", int(n)),
+			logx.Int("size", int(sess.size)),
+		)
+	}}
+	stat, err := storage.Put(ctx, sess.target.Key, joined, &storagex.PutOptions{
+		ContentType: sess.target.ContentType,
+		Overwrite:   true,
+	})
+	parts.Close()
+	if err != nil {
+		// The parts are kept, so Complete can be called again
+		return snapshot, storagex.Stat{}, fmt.Errorf("failed to join parts: %w", err)
+	}
+
+	s.end(ctx, sess)
+	return snapshot, stat, nil
+}
+
+// Abort ends the session and deletes its parts
+func (s *Service) Abort(ctx context.Context, id string) error {
+	sess, err := s.open(id)
+	if err != nil {
+		return err
+	}
+	sess.io.Lock()
+	defer sess.io.Unlock()
+	if sess.isDone() {
+		return ErrNotFound
+	}
+	s.end(ctx, sess)
+	return nil
+}
+
+// Expire aborts the sessions that received nothing for the session TTL, and
+// returns how many there were
+func (s *Service) Expire(ctx context.Context) int {
+	now := s.now()
+	var expired []*session
+	s.mu.Lock()
+	for _, sess := range s.sessions {
+		if sess.expired(now) {
+			expired = append(expired, sess)
+		}
+	}
+	s.mu.Unlock()
+
+	n := 0
+	for _, sess := range expired {
+		sess.io.Lock()
+		// A part may have arrived since
+		if !sess.isDone() && sess.expired(s.now()) {
+			s.end(ctx, sess)
+			n++
+		}
+		sess.io.Unlock()
+	}
+	return n
+}
+
+// open returns the session while it is neither ended nor expired
+func (s *Service) open(id string) (*session, error) {
+	s.mu.Lock()
+	sess, ok := s.sessions[id]
+	s.mu.Unlock()
+	if !ok || sess.isDone() || sess.expired(s.now()) {
+		return nil, ErrNotFound
+	}
+	return sess, nil
+}
+
+// end forgets the session and deletes its parts. The caller holds sess.io.
+func (s *Service) end(ctx context.Context, sess *session) {
+	sess.mu.Lock()
+	sess.done = true
+	received := sess.received()
+	sess.mu.Unlock()
+
+	s.mu.Lock()
+	delete(s.sessions, sess.id)
+	s.mu.Unlock()
+
+	// Parts that failed their size check were stored without being recorded
+	for n := 1; n <= sess.parts; n++ {
+		err := s.storage.Delete(ctx, s.partKey(sess.id, n))
+		if err != nil && !errors.Is(err, storagex.ErrNotFound) && slices.Contains(received, n) {
+			s.log.Warn("failed to delete upload part",
+				logx.String("upload_id", sess.id),
+				logx.Int("part", n),
+				logx.Err(err),
+			)
+		}
+	}
+}
+
+// partKey returns the key part n of a session is stored at
+func (s *Service) partKey(id string, n int) string {
+	return fmt.Sprintf("%s%s/%05d", s.cfg.PartPrefix, id, n)
+}
+
+// partLen returns the size part n must have
+func (sess *session) partLen(n int) int64 {
+	if n < sess.parts {
+		return sess.partSize
+	}
+	return sess.size - int64(sess.parts-1)*sess.partSize
+}
+
+func (sess *session) isDone() bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.done
+}
+
+func (sess *session) expired(now time.Time) bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return !now.Before(sess.expiresAt)
+}
+
+// received returns the numbers of the parts stored, in order. The caller
+// holds sess.mu.
+func (sess *session) received() []int {
+	parts := make([]int, 0, len(sess.partsSeen))
+	for n := range sess.partsSeen {
+		parts = append(parts, n)
+	}
+	slices.Sort(parts)
+	return parts
+}
+
+func (sess *session) snapshot() Session {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return Session{
+		ID:        sess.id,
+		Target:    sess.target,
+		Size:      sess.size,
+		PartSize:  sess.partSize,
+		Parts:     sess.parts,
+		Received:  sess.received(),
+		ExpiresAt: sess.expiresAt,
+	}
+}
+
+// partsReader reads the parts one after the other, opening each when the one
+// before it is used up, so one part at a time is open
+type partsReader struct {
+	ctx     context.Context
+	storage storagex.Storage
+	keys    []string
+	current io.ReadCloser
+}
+
+func (r *partsReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.keys) == 0 {
+				return 0, io.EOF
+			}
+			body, _, err := r.storage.Get(r.ctx, r.keys[0])
+			if err != nil {
+				return 0, fmt.Errorf("failed to read part %s: %w", r.keys[0], err)
+			}
+			r.current, r.keys = body, r.keys[1:]
+		}
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+// Close closes the part being read, if any
+func (r *partsReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	err := r.current.Close()
+	r.current = nil
+	return err
+}
@@ -0,0 +1,254 @@
+package uploads_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/orderservice/internal/testutil"
+	"github.com/gostratum/examples/orderservice/internal/uploads"
+)
+
+var testConfig = uploads.Config{
+	PartSize:        4,
+	SessionTTL:      time.Hour,
+	CleanupInterval: time.Minute,
+	PartPrefix:      "parts/",
+}
+
+var target = uploads.Target{
+	Kind:        "attachment",
+	Owner:       "o1",
+	Key:         "attachments/o1/invoice.txt",
+	ContentType: "text/plain",
+}
+
+func newTestService(t *testing.T) (*uploads.Service, *testutil.MemoryStorage, *time.Time) {
+	t.Helper()
+	return newScanningService(t, uploads.NopScanner{})
+}
+
+func newScanningService(t *testing.T, scanner uploads.Scanner) (*uploads.Service, *testutil.MemoryStorage, *time.Time) {
+	t.Helper()
+	now := time.Unix(1700000000, 0)
+	storage := testutil.NewMemoryStorage()
+	s, err := uploads.New(testConfig, storage, scanner, logx.NewNoopLogger())
+	require.NoError(t, err)
+	uploads.SetNow(s, func() time.Time { return now })
+	return s, storage, &now
+}
+
+func putPart(s *uploads.Service, id string, n int, data string) (uploads.Session, error) {
+	return s.PutPart(context.Background(), id, n, strings.NewReader(data))
+}
+
+func read(t *testing.T, storage *testutil.MemoryStorage, key string) string {
+	t.Helper()
+	body, _, err := storage.Get(context.Background(), key)
+	require.NoError(t, err)
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestService_PartsInAnyOrder(t *testing.T) {
+	s, storage, _ := newTestService(t)
+	ctx := context.Background()
+
+	sess, err := s.Create(target, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 3, sess.Parts)
+	assert.EqualValues(t, 4, sess.PartSize)
+
+	_, err = putPart(s, sess.ID, 3, "89")
+	require.NoError(t, err)
+	_, err = putPart(s, sess.ID, 1, "0123")
+	require.NoError(t, err)
+	// A part sent again replaces the first copy
+	_, err = putPart(s, sess.ID, 1, "0123")
+	require.NoError(t, err)
+
+	got, err := s.Get(sess.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 3}, got.Received)
+
+	_, _, err = s.Complete(ctx, sess.ID)
+	assert.ErrorIs(t, err, uploads.ErrIncomplete)
+
+	_, err = putPart(s, sess.ID, 2, "4567")
+	require.NoError(t, err)
+	done, stat, err := s.Complete(ctx, sess.ID)
+	require.NoError(t, err)
+	assert.Equal(t, target, done.Target)
+	assert.EqualValues(t, 10, stat.Size)
+	assert.Equal(t, "text/plain", stat.ContentType)
+
+	assert.Equal(t, "0123456789", read(t, storage, target.Key))
+	assert.Equal(t, []string{target.Key}, storage.Keys(), "parts are deleted")
+
+	_, err = s.Get(sess.ID)
+	assert.ErrorIs(t, err, uploads.ErrNotFound, "the session ends")
+}
+
+func TestService_TargetStorage(t *testing.T) {
+	s, storage, _ := newTestService(t)
+	ctx := context.Background()
+	invoices := testutil.NewMemoryStorage()
+	into := target
+	into.Storage = invoices
+
+	sess, err := s.Create(into, 6)
+	require.NoError(t, err)
+	_, err = putPart(s, sess.ID, 1, "0123")
+	require.NoError(t, err)
+	_, err = putPart(s, sess.ID, 2, "45")
+	require.NoError(t, err)
+	_, _, err = s.Complete(ctx, sess.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, "012345", read(t, invoices, target.Key))
+	assert.Empty(t, storage.Keys(), "the parts are deleted where they were stored")
+}
+
+// signatureScanner flags the files that contain signature, and fails with err
+// when it is set
+type signatureScanner struct {
+	signature string
+	err       error
+}
+
+func (s *signatureScanner) Scan(ctx context.Context, r io.Reader) error {
+	if s.err != nil {
+		return s.err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if strings.Contains(string(data), s.signature) {
+		return fmt.Errorf("%w: Test-Signature", uploads.ErrInfected)
+	}
+	return nil
+}
+
+func TestService_Scan(t *testing.T) {
+	scanner := &signatureScanner{signature: "VIRUS"}
+	s, storage, _ := newScanningService(t, scanner)
+	ctx := context.Background()
+
+	upload := func(data string) uploads.Session {
+		sess, err := s.Create(target, int64(len(data)))
+		require.NoError(t, err)
+		for n := 1; len(data) > 0; n++ {
+			part := data[:min(len(data), 4)]
+			data = data[len(part):]
+			_, err := putPart(s, sess.ID, n, part)
+			require.NoError(t, err)
+		}
+		return sess
+	}
+
+	// The signature spans two parts, so the file is scanned whole
+	infected := upload("01VIRUS9")
+	_, _, err := s.Complete(ctx, infected.ID)
+	assert.ErrorIs(t, err, uploads.ErrInfected)
+	assert.ErrorContains(t, err, "Test-Signature")
+	assert.Empty(t, storage.Keys(), "the parts are deleted and the file is not stored")
+	_, err = s.Get(infected.ID)
+	assert.ErrorIs(t, err, uploads.ErrNotFound, "the session ends")
+
+	clean := upload("0123456789")
+	scanner.err = errors.New("scanner unreachable")
+	_, _, err = s.Complete(ctx, clean.ID)
+	assert.ErrorIs(t, err, uploads.ErrScanFailed)
+	assert.ErrorContains(t, err, "scanner unreachable")
+	assert.NotContains(t, storage.Keys(), target.Key, "a file that was not scanned is not stored")
+
+	// The session is kept, so completing it is retried
+	scanner.err = nil
+	_, _, err = s.Complete(ctx, clean.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789", read(t, storage, target.Key))
+}
+
+func TestService_InvalidParts(t *testing.T) {
+	s, storage, _ := newTestService(t)
+
+	_, err := s.Create(target, 0)
+	assert.ErrorIs(t, err, uploads.ErrInvalidSize)
+
+	sess, err := s.Create(target, 10)
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		n    int
+		data string
+	}{
+		{n: 0, data: "0123"},
+		{n: 4, data: "0123"},
+		{n: 1, data: "012"},   // short
+		{n: 1, data: "01234"}, // long
+		{n: 3, data: "8"},     // the last part holds the rest
+	} {
+		_, err := putPart(s, sess.ID, tt.n, tt.data)
+		assert.ErrorIs(t, err, uploads.ErrInvalidPart, "part %d %q", tt.n, tt.data)
+	}
+	got, err := s.Get(sess.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.Received)
+
+	// Parts that were stored but refused go with the session
+	require.NoError(t, s.Abort(context.Background(), sess.ID))
+	assert.Empty(t, storage.Keys())
+
+	_, err = putPart(s, sess.ID, 1, "0123")
+	assert.ErrorIs(t, err, uploads.ErrNotFound)
+	assert.ErrorIs(t, s.Abort(context.Background(), sess.ID), uploads.ErrNotFound)
+}
+
+func TestService_Expire(t *testing.T) {
+	s, storage, now := newTestService(t)
+	ctx := context.Background()
+
+	idle, err := s.Create(target, 8)
+	require.NoError(t, err)
+	_, err = putPart(s, idle.ID, 1, "0123")
+	require.NoError(t, err)
+
+	*now = now.Add(50 * time.Minute)
+	active, err := s.Create(target, 8)
+	require.NoError(t, err)
+	_, err = putPart(s, active.ID, 1, "0123")
+	require.NoError(t, err)
+	// A part keeps the session alive for another TTL
+	_, err = putPart(s, idle.ID, 2, "4567")
+	require.NoError(t, err)
+
+	*now = now.Add(55 * time.Minute)
+	assert.Equal(t, 0, s.Expire(ctx))
+
+	*now = now.Add(10 * time.Minute)
+	_, err = s.Get(idle.ID)
+	assert.ErrorIs(t, err, uploads.ErrNotFound, "an expired session is gone before the janitor runs")
+	assert.Equal(t, 2, s.Expire(ctx))
+	assert.Empty(t, storage.Keys())
+
+	_, err = putPart(s, active.ID, 2, "4567")
+	assert.ErrorIs(t, err, uploads.ErrNotFound)
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	cfg := testConfig
+	cfg.PartSize = 0
+	_, err := uploads.New(cfg, testutil.NewMemoryStorage(), uploads.NopScanner{}, logx.NewNoopLogger())
+	assert.ErrorContains(t, err, "must be positive")
+}
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gostratum/examples/orderservice/internal/audit"
+	"github.com/gostratum/examples/orderservice/internal/domain"
+)
+
+// AttachmentService handles the documents attached to orders. The files are
+// stored by the caller; the service keeps the record of them.
+type AttachmentService struct {
+	orders      OrderRepository
+	attachments AttachmentRepository
+}
+
+// NewAttachmentService creates a new attachment service with repository injection
+func NewAttachmentService(orders OrderRepository, attachments AttachmentRepository) *AttachmentService {
+	return &AttachmentService{
+		orders:      orders,
+		attachments: attachments,
+	}
+}
+
+// AddAttachment records a file stored at key as attached to an order
+func (s *AttachmentService) AddAttachment(ctx context.Context, orderID, key, filename, contentType string, size int64) (*domain.Attachment, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	attachment := domain.NewAttachment(orderID, key, filename, contentType, size)
+	if err := attachment.Validate(); err != nil {
+		// A *domain.ValidationError, which is ErrInvalid
+		return nil, err
+	}
+
+	if err := s.attachments.Save(ctx, attachment); err != nil {
+		return nil, s.translateError(err)
+	}
+	audit.Record(ctx, "attachment", attachment.ID, nil, attachment)
+
+	return attachment, nil
+}
+
+// ListAttachments retrieves the attachments of an order, oldest first
+func (s *AttachmentService) ListAttachments(ctx context.Context, orderID string) ([]*domain.Attachment, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	// An order without attachments lists none, one that does not exist is
+	// not found
+	if _, err := s.orders.FindByID(ctx, orderID); err != nil {
+		return nil, s.translateError(err)
+	}
+
+	attachments, err := s.attachments.ListByOrder(ctx, orderID)
+	if err != nil {
+		return nil, s.translateError(err)
+	}
+
+	return attachments, nil
+}
+
+// GetAttachment retrieves an attachment of an order by ID. An attachment of
+// another order is not found.
+func (s *AttachmentService) GetAttachment(ctx context.Context, orderID, id string) (*domain.Attachment, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	attachment, err := s.attachments.FindByID(ctx, id)
+	if err != nil {
+		return nil, s.translateError(err)
+	}
+	if attachment.OrderID != orderID {
+		return nil, ErrNotFound
+	}
+
+	return attachment, nil
+}
+
+// translateError converts repository/domain errors to usecase errors
+func (s *AttachmentService) translateError(err error) error {
+	// Domain errors pass through
+	if errors.Is(err, domain.ErrNotFound) {
+		return ErrNotFound
+	}
+	if errors.Is(err, domain.ErrConflict) {
+		return ErrConflict
+	}
+	if errors.Is(err, domain.ErrInvalidInput) {
+		return ErrInvalid
+	}
+
+	// All other errors are infrastructure/availability issues
+	return ErrUnavailable
+}
@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/gostratum/examples/orderservice/internal/domain"
+	"github.com/gostratum/examples/orderservice/internal/mocks"
+	"github.com/gostratum/examples/orderservice/internal/testutil/factories"
+)
+
+func TestAddAttachment(t *testing.T) {
+	tests := []struct {
+		name      string
+		filename  string
+		saveError error
+		wantErr   error
+	}{
+		{
+			name:     "valid attachment should be recorded",
+			filename: "invoice.pdf",
+			wantErr:  nil,
+		},
+		{
+			name:     "empty filename should return invalid error",
+			filename: " ",
+			wantErr:  ErrInvalid,
+		},
+		{
+			name:      "deleted order should return not found error",
+			filename:  "invoice.pdf",
+			saveError: domain.ErrNotFound,
+			wantErr:   ErrNotFound,
+		},
+		{
+			name:      "repository error should return unavailable error",
+			filename:  "invoice.pdf",
+			saveError: errors.New("database connection failed"),
+			wantErr:   ErrUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			attachments := mocks.NewMockAttachmentRepository(ctrl)
+			// Invalid attachments are refused before they reach the repository
+			if !errors.Is(tt.wantErr, ErrInvalid) {
+				attachments.EXPECT().Save(gomock.Any(), gomock.Any()).Return(tt.saveError)
+			}
+
+			ctx := context.Background()
+			service := NewAttachmentService(mocks.NewMockOrderRepository(ctrl), attachments)
+			attachment, err := service.AddAttachment(ctx, "order-1", "attachments/order-1/a_invoice.pdf", tt.filename, "application/pdf", 1024)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("AddAttachment() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if attachment != nil {
+					t.Errorf("AddAttachment() should return nil attachment on error, got %v", attachment)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("AddAttachment() unexpected error = %v", err)
+				}
+				if attachment == nil || attachment.OrderID != "order-1" || attachment.ID == "" {
+					t.Errorf("AddAttachment() attachment = %v, want one of order-1 with an ID", attachment)
+				}
+			}
+		})
+	}
+}
+
+func TestListAttachments(t *testing.T) {
+	stored := []*domain.Attachment{{ID: "a1", OrderID: "order-1"}, {ID: "a2", OrderID: "order-1"}}
+	tests := []struct {
+		name      string
+		findError error
+		listError error
+		want      int
+		wantErr   error
+	}{
+		{
+			name: "attachments of an existing order should be returned",
+			want: 2,
+		},
+		{
+			name:      "non-existing order should return not found error",
+			findError: domain.ErrNotFound,
+			wantErr:   ErrNotFound,
+		},
+		{
+			name:      "repository error should return unavailable error",
+			listError: errors.New("database connection failed"),
+			wantErr:   ErrUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			orders := mocks.NewMockOrderRepository(ctrl)
+			attachments := mocks.NewMockAttachmentRepository(ctrl)
+			orders.EXPECT().FindByID(gomock.Any(), "order-1").
+				Return(factories.Order(func(o *domain.Order) { o.ID = "order-1" }), tt.findError)
+			if tt.findError == nil {
+				attachments.EXPECT().ListByOrder(gomock.Any(), "order-1").Return(stored, tt.listError)
+			}
+
+			service := NewAttachmentService(orders, attachments)
+			got, err := service.ListAttachments(context.Background(), "order-1")
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ListAttachments() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && len(got) != tt.want {
+				t.Errorf("ListAttachments() returned %d attachments, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestGetAttachment(t *testing.T) {
+	tests := []struct {
+		name      string
+		orderID   string
+		stored    *domain.Attachment
+		findError error
+		wantErr   error
+	}{
+		{
+			name:    "attachment of the order should be returned",
+			orderID: "order-1",
+			stored:  &domain.Attachment{ID: "a1", OrderID: "order-1"},
+		},
+		{
+			name:    "attachment of another order should return not found error",
+			orderID: "order-2",
+			stored:  &domain.Attachment{ID: "a1", OrderID: "order-1"},
+			wantErr: ErrNotFound,
+		},
+		{
+			name:      "non-existing attachment should return not found error",
+			orderID:   "order-1",
+			findError: domain.ErrNotFound,
+			wantErr:   ErrNotFound,
+		},
+		{
+			name:      "repository error should return unavailable error",
+			orderID:   "order-1",
+			findError: errors.New("database connection failed"),
+			wantErr:   ErrUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			attachments := mocks.NewMockAttachmentRepository(ctrl)
+			attachments.EXPECT().FindByID(gomock.Any(), "a1").Return(tt.stored, tt.findError)
+
+			service := NewAttachmentService(mocks.NewMockOrderRepository(ctrl), attachments)
+			attachment, err := service.GetAttachment(context.Background(), tt.orderID, "a1")
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetAttachment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && attachment != nil {
+				t.Errorf("GetAttachment() should return nil attachment on error, got %v", attachment)
+			}
+			if tt.wantErr == nil && (attachment == nil || attachment.ID != "a1") {
+				t.Errorf("GetAttachment() attachment = %v, want a1", attachment)
+			}
+		})
+	}
+}
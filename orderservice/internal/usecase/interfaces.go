@@ -20,4 +20,10 @@ var (
 
 	// ErrConflict wraps domain.ErrConflict for application layer
 	ErrConflict = domain.ErrConflict
+
+	// ErrOutOfStock indicates inventory could not reserve every item of an order
+	ErrOutOfStock = errors.New("out of stock")
+
+	// ErrPaymentDeclined indicates the payment for an order was declined
+	ErrPaymentDeclined = errors.New("payment declined")
 )
@@ -0,0 +1,19 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/gostratum/examples/orderservice/internal/domain"
+)
+
+// InventoryClient reserves stock for orders in inventoryservice
+// This interface is owned by the use case layer (dependency inversion principle)
+type InventoryClient interface {
+	// Reserve holds stock for every item of the order, all or nothing, and returns
+	// the reservation ID. Reserving again for the same order returns the same
+	// reservation. It fails with ErrOutOfStock, ErrInvalid or ErrUnavailable.
+	Reserve(ctx context.Context, orderID string, items []domain.Item) (string, error)
+
+	// Release returns the stock held by a reservation
+	Release(ctx context.Context, reservationID string) error
+}
@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/gostratum/examples/orderservice/internal/mocks"
+	"github.com/gostratum/examples/orderservice/internal/testutil/factories"
+)
+
+// BenchmarkCreateOrder measures the service alone: validation, totals and
+// the reserve, charge and save calls, over mocks. The mocks' bookkeeping is
+// part of what is measured.
+func BenchmarkCreateOrder(b *testing.B) {
+	for _, n := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("items=%d", n), func(b *testing.B) {
+			ctrl := gomock.NewController(b)
+			repo := mocks.NewMockOrderRepository(ctrl)
+			repo.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			inventory := mocks.NewMockInventoryClient(ctrl)
+			inventory.EXPECT().Reserve(gomock.Any(), gomock.Any(), gomock.Any()).Return("res-1", nil).AnyTimes()
+			payments := mocks.NewMockPaymentGateway(ctrl)
+			payments.EXPECT().Charge(gomock.Any(), gomock.Any(), gomock.Any()).Return("ch-1", nil).AnyTimes()
+			service := NewOrderService(repo, inventory, payments)
+			items := factories.Items(n)
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := service.CreateOrder(ctx, "user-1", items); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
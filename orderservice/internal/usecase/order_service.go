@@ -5,49 +5,143 @@ import (
 	"errors"
 	"time"
 
+	"github.com/gostratum/examples/orderservice/internal/audit"
 	"github.com/gostratum/examples/orderservice/internal/domain"
 )
 
 // OrderService handles order business logic
 type OrderService struct {
-	repo OrderRepository
+	repo      OrderRepository
+	inventory InventoryClient
+	payments  PaymentGateway
 }
 
-// NewOrderService creates a new order service with repository injection
-func NewOrderService(repo OrderRepository) *OrderService {
+// NewOrderService creates a new order service with repository, inventory and payment injection
+func NewOrderService(repo OrderRepository, inventory InventoryClient, payments PaymentGateway) *OrderService {
 	return &OrderService{
-		repo: repo,
+		repo:      repo,
+		inventory: inventory,
+		payments:  payments,
 	}
 }
 
-// CreateOrder creates a new order
-func (s *OrderService) CreateOrder(ctx context.Context, userID string, items []domain.Item) (*domain.Order, error) {
-	// Apply context deadline
-	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
-	defer cancel()
+// Budgets of CreateOrder. The order deadline bounds storing the order; each call
+// to inventoryservice and paymentservice gets its own, longer budget, so a slow
+// reservation does not leave the charge too little time to be answered.
+const (
+	orderTimeout        = 800 * time.Millisecond
+	externalCallTimeout = 2 * time.Second
+)
 
+// CreateOrder reserves stock for the order's items, charges the order total and then
+// stores the order. Each step is undone when a later one fails: a failed charge
+// releases the reservation, and a failed save refunds the charge and releases it.
+// A charge that timed out or was not answered may still have been booked, so it is
+// looked up by order ID and refunded.
+func (s *OrderService) CreateOrder(ctx context.Context, userID string, items []domain.Item) (*domain.Order, error) {
 	order := domain.NewOrder(userID)
 	for _, item := range items {
 		if err := order.AddItem(item); err != nil {
-			return nil, ErrInvalid
+			// A *domain.ValidationError, which is ErrInvalid
+			return nil, err
 		}
 	}
 
 	if err := order.Validate(); err != nil {
-		return nil, ErrInvalid
+		return nil, err
+	}
+
+	reservationID, err := s.reserve(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	chargeID, err := s.charge(ctx, order)
+	if err != nil {
+		if chargeMayBeBooked(err) {
+			s.refundOrder(ctx, order.ID)
+		}
+		s.releaseReservation(ctx, reservationID)
+		return nil, err
 	}
 
-	if err := s.repo.Save(ctx, order); err != nil {
+	saveCtx, cancel := context.WithTimeout(ctx, orderTimeout)
+	defer cancel()
+	if err := s.repo.Save(saveCtx, order); err != nil {
+		s.refundCharge(ctx, chargeID)
+		s.releaseReservation(ctx, reservationID)
 		return nil, s.translateError(err)
 	}
+	audit.Record(ctx, "order", order.ID, nil, order)
 
 	return order, nil
 }
 
+func (s *OrderService) reserve(ctx context.Context, order *domain.Order) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, externalCallTimeout)
+	defer cancel()
+	return s.inventory.Reserve(ctx, order.ID, order.Items)
+}
+
+func (s *OrderService) charge(ctx context.Context, order *domain.Order) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, externalCallTimeout)
+	defer cancel()
+	return s.payments.Charge(ctx, order.ID, order.Total)
+}
+
+// chargeMayBeBooked reports whether paymentservice may have charged the order
+// although Charge failed: its answer timed out or never arrived. A decline or an
+// invalid request is an answer, and nothing was charged.
+func chargeMayBeBooked(err error) bool {
+	return errors.Is(err, ErrUnavailable) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, context.Canceled)
+}
+
+// releaseReservation gives back stock reserved for an order that was not stored.
+// It runs even when ctx already expired; a failed release leaves the stock held
+// until it is released on the inventoryservice side.
+func (s *OrderService) releaseReservation(ctx context.Context, reservationID string) {
+	if reservationID == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Second)
+	defer cancel()
+	_ = s.inventory.Release(ctx, reservationID)
+}
+
+// refundOrder refunds the charge of an order whose Charge call failed without an
+// answer, if paymentservice booked one. paymentservice keeps one charge per order
+// ID, so it is found by the order's ID.
+func (s *OrderService) refundOrder(ctx context.Context, orderID string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Second)
+	defer cancel()
+
+	chargeID, err := s.payments.FindCharge(ctx, orderID)
+	if err != nil || chargeID == "" {
+		return
+	}
+	_ = s.payments.Refund(ctx, chargeID)
+}
+
+// refundCharge refunds the charge of an order that was not stored. Like
+// releaseReservation it outlives ctx; a failed refund has to be settled in
+// paymentservice.
+func (s *OrderService) refundCharge(ctx context.Context, chargeID string) {
+	if chargeID == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Second)
+	defer cancel()
+	_ = s.payments.Refund(ctx, chargeID)
+}
+
 // GetOrder retrieves an order by ID
 func (s *OrderService) GetOrder(ctx context.Context, id string) (*domain.Order, error) {
 	// Apply context deadline
-	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	ctx, cancel := context.WithTimeout(ctx, orderTimeout)
 	defer cancel()
 
 	order, err := s.repo.FindByID(ctx, id)
@@ -3,64 +3,40 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
-	"time"
+
+	"go.uber.org/mock/gomock"
 
 	"github.com/gostratum/examples/orderservice/internal/domain"
+	"github.com/gostratum/examples/orderservice/internal/mocks"
+	"github.com/gostratum/examples/orderservice/internal/testutil/factories"
 )
 
-// MockOrderRepository implements ports.OrderRepository for testing
-type MockOrderRepository struct {
-	orders    map[string]*domain.Order
-	saveError error
-	findError error
-}
-
-func NewMockOrderRepository() *MockOrderRepository {
-	return &MockOrderRepository{
-		orders: make(map[string]*domain.Order),
-	}
-}
-
-func (m *MockOrderRepository) Save(ctx context.Context, o *domain.Order) error {
-	if m.saveError != nil {
-		return m.saveError
+// itemsTotal is what an order of items charges
+func itemsTotal(items []domain.Item) float64 {
+	var total float64
+	for _, item := range items {
+		total += item.Price * float64(item.Qty)
 	}
-	m.orders[o.ID] = o
-	return nil
-}
-
-func (m *MockOrderRepository) FindByID(ctx context.Context, id string) (*domain.Order, error) {
-	if m.findError != nil {
-		return nil, m.findError
-	}
-	order, exists := m.orders[id]
-	if !exists {
-		return nil, errors.New("not found")
-	}
-	return order, nil
-}
-
-func (m *MockOrderRepository) SetSaveError(err error) {
-	m.saveError = err
-}
-
-func (m *MockOrderRepository) SetFindError(err error) {
-	m.findError = err
+	return total
 }
 
 func TestCreateOrder(t *testing.T) {
-	validItems := []domain.Item{
-		{SKU: "SKU1", Qty: 2, Price: 10.0},
-		{SKU: "SKU2", Qty: 1, Price: 15.0},
-	}
+	validItems := factories.Items(2)
 
 	tests := []struct {
-		name      string
-		userID    string
-		items     []domain.Item
-		saveError error
-		wantErr   error
+		name         string
+		userID       string
+		items        []domain.Item
+		saveError    error
+		reserveError error
+		chargeError  error
+		foundCharge  string // what FindCharge returns after a charge without an answer
+		wantErr      error
+		wantLookup   bool
+		wantReleased bool
+		wantRefunded bool
 	}{
 		{
 			name:    "valid order creation",
@@ -81,47 +57,122 @@ func TestCreateOrder(t *testing.T) {
 			wantErr: ErrInvalid,
 		},
 		{
-			name:   "items with negative price should return invalid error",
-			userID: "user123",
-			items: []domain.Item{
-				{SKU: "SKU1", Qty: 1, Price: -10.0},
-			},
+			name:    "items with negative price should return invalid error",
+			userID:  "user123",
+			items:   []domain.Item{factories.Item(func(i *domain.Item) { i.Price = -10.0 })},
 			wantErr: ErrInvalid,
 		},
 		{
-			name:   "items with zero quantity should return invalid error",
-			userID: "user123",
-			items: []domain.Item{
-				{SKU: "SKU1", Qty: 0, Price: 10.0},
-			},
+			name:    "items with zero quantity should return invalid error",
+			userID:  "user123",
+			items:   []domain.Item{factories.Item(func(i *domain.Item) { i.Qty = 0 })},
 			wantErr: ErrInvalid,
 		},
 		{
-			name:   "items with empty SKU should return invalid error",
-			userID: "user123",
-			items: []domain.Item{
-				{SKU: "", Qty: 1, Price: 10.0},
-			},
+			name:    "items with empty SKU should return invalid error",
+			userID:  "user123",
+			items:   []domain.Item{factories.Item(func(i *domain.Item) { i.SKU = "" })},
 			wantErr: ErrInvalid,
 		},
 		{
-			name:      "repository error should return unavailable error",
-			userID:    "user123",
-			items:     validItems,
-			saveError: errors.New("database connection failed"),
-			wantErr:   ErrUnavailable,
+			name:         "repository error should return unavailable error, refund and release stock",
+			userID:       "user123",
+			items:        validItems,
+			saveError:    errors.New("database connection failed"),
+			wantErr:      ErrUnavailable,
+			wantReleased: true,
+			wantRefunded: true,
+		},
+		{
+			name:         "declined payment should return declined error and release stock",
+			userID:       "user123",
+			items:        validItems,
+			chargeError:  fmt.Errorf("%w: amount exceeds the card limit", ErrPaymentDeclined),
+			wantErr:      ErrPaymentDeclined,
+			wantReleased: true,
+		},
+		{
+			name:         "payment unavailable should return unavailable error, look up the charge and release stock",
+			userID:       "user123",
+			items:        validItems,
+			chargeError:  ErrUnavailable,
+			wantErr:      ErrUnavailable,
+			wantLookup:   true,
+			wantReleased: true,
+		},
+		{
+			name:         "payment timeout should refund the charge booked anyway and release stock",
+			userID:       "user123",
+			items:        validItems,
+			chargeError:  context.DeadlineExceeded,
+			foundCharge:  "ch-1",
+			wantErr:      context.DeadlineExceeded,
+			wantLookup:   true,
+			wantReleased: true,
+			wantRefunded: true,
+		},
+		{
+			name:         "out of stock should return out of stock error",
+			userID:       "user123",
+			items:        validItems,
+			reserveError: fmt.Errorf("%w: insufficient stock: sku %s", ErrOutOfStock, validItems[1].SKU),
+			wantErr:      ErrOutOfStock,
+		},
+		{
+			name:         "inventory unavailable should return unavailable error",
+			userID:       "user123",
+			items:        validItems,
+			reserveError: ErrUnavailable,
+			wantErr:      ErrUnavailable,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			repo := NewMockOrderRepository()
-			if tt.saveError != nil {
-				repo.SetSaveError(tt.saveError)
+			ctrl := gomock.NewController(t)
+			repo := mocks.NewMockOrderRepository(ctrl)
+			inventory := mocks.NewMockInventoryClient(ctrl)
+			payments := mocks.NewMockPaymentGateway(ctrl)
+
+			// Each step runs only when the ones before it succeeded; the
+			// controller fails the test on a call that is not expected here
+			var chargedOrderID string
+			switch {
+			case errors.Is(tt.wantErr, ErrInvalid):
+				// Refused before any port is called
+			case tt.reserveError != nil:
+				inventory.EXPECT().Reserve(gomock.Any(), gomock.Any(), tt.items).Return("", tt.reserveError)
+			default:
+				inventory.EXPECT().Reserve(gomock.Any(), gomock.Any(), tt.items).Return("res-1", nil)
+				if tt.chargeError != nil {
+					payments.EXPECT().Charge(gomock.Any(), gomock.Any(), itemsTotal(tt.items)).
+						DoAndReturn(func(_ context.Context, orderID string, _ float64) (string, error) {
+							chargedOrderID = orderID
+							return "", tt.chargeError
+						})
+				} else {
+					payments.EXPECT().Charge(gomock.Any(), gomock.Any(), itemsTotal(tt.items)).Return("ch-1", nil)
+					repo.EXPECT().Save(gomock.Any(), gomock.Any()).Return(tt.saveError)
+				}
+			}
+			if tt.wantReleased {
+				inventory.EXPECT().Release(gomock.Any(), "res-1").Return(nil)
+			}
+			if tt.wantLookup {
+				payments.EXPECT().FindCharge(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, orderID string) (string, error) {
+						if orderID != chargedOrderID {
+							t.Errorf("FindCharge() order ID = %q, want the charged order %q", orderID, chargedOrderID)
+						}
+						return tt.foundCharge, nil
+					})
+			}
+			if tt.wantRefunded {
+				payments.EXPECT().Refund(gomock.Any(), "ch-1").Return(nil)
 			}
 
 			ctx := context.Background()
-			service := NewOrderService(repo)
+			service := NewOrderService(repo, inventory, payments)
 			order, err := service.CreateOrder(ctx, tt.userID, tt.items)
 
 			if tt.wantErr != nil {
@@ -165,18 +216,10 @@ func TestGetOrder(t *testing.T) {
 		wantErr    error
 	}{
 		{
-			name:    "existing order should be returned",
-			orderID: "test-order-id",
-			setupOrder: &domain.Order{
-				ID:     "test-order-id",
-				UserID: "user123",
-				Items: []domain.Item{
-					{SKU: "SKU1", Qty: 2, Price: 10.0},
-				},
-				Status:    "pending",
-				CreatedAt: time.Now(),
-			},
-			wantErr: nil,
+			name:       "existing order should be returned",
+			orderID:    "test-order-id",
+			setupOrder: factories.Order(func(o *domain.Order) { o.ID = "test-order-id" }),
+			wantErr:    nil,
 		},
 		{
 			name:      "non-existing order should return not found error",
@@ -194,18 +237,12 @@ func TestGetOrder(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			repo := NewMockOrderRepository()
-
-			if tt.setupOrder != nil {
-				repo.orders[tt.setupOrder.ID] = tt.setupOrder
-			}
-
-			if tt.findError != nil {
-				repo.SetFindError(tt.findError)
-			}
+			ctrl := gomock.NewController(t)
+			repo := mocks.NewMockOrderRepository(ctrl)
+			repo.EXPECT().FindByID(gomock.Any(), tt.orderID).Return(tt.setupOrder, tt.findError)
 
 			ctx := context.Background()
-			service := NewOrderService(repo)
+			service := NewOrderService(repo, mocks.NewMockInventoryClient(ctrl), mocks.NewMockPaymentGateway(ctrl))
 			order, err := service.GetOrder(ctx, tt.orderID)
 
 			if tt.wantErr != nil {
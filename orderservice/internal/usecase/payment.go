@@ -0,0 +1,21 @@
+package usecase
+
+import (
+	"context"
+)
+
+// PaymentGateway charges orders through paymentservice
+// This interface is owned by the use case layer (dependency inversion principle)
+type PaymentGateway interface {
+	// Charge takes amount for the order and returns the charge ID. Charging the
+	// same order again returns the same charge. It fails with ErrPaymentDeclined
+	// or ErrUnavailable.
+	Charge(ctx context.Context, orderID string, amount float64) (string, error)
+
+	// FindCharge returns the ID of the order's charge, or "" when the order was
+	// not charged. It settles a Charge whose answer was lost to a timeout.
+	FindCharge(ctx context.Context, orderID string) (string, error)
+
+	// Refund returns the full amount of a charge
+	Refund(ctx context.Context, chargeID string) error
+}
@@ -12,6 +12,8 @@ type UserRepository interface {
 	Save(ctx context.Context, u *domain.User) error
 	FindByID(ctx context.Context, id string) (*domain.User, error)
 	Update(ctx context.Context, u *domain.User) error
+	// AvatarHashesInUse returns those of hashes some user's avatar has
+	AvatarHashesInUse(ctx context.Context, hashes []string) ([]string, error)
 }
 
 // OrderRepository defines the interface for order data operations
@@ -19,4 +21,15 @@ type UserRepository interface {
 type OrderRepository interface {
 	Save(ctx context.Context, o *domain.Order) error
 	FindByID(ctx context.Context, id string) (*domain.Order, error)
+	// ExistingOrderIDs returns those of ids an order has
+	ExistingOrderIDs(ctx context.Context, ids []string) ([]string, error)
+}
+
+// AttachmentRepository defines the interface for order attachment data operations
+// This interface is owned by the use case layer (dependency inversion principle)
+type AttachmentRepository interface {
+	Save(ctx context.Context, a *domain.Attachment) error
+	FindByID(ctx context.Context, id string) (*domain.Attachment, error)
+	// ListByOrder returns the attachments of an order, oldest first
+	ListByOrder(ctx context.Context, orderID string) ([]*domain.Attachment, error)
 }
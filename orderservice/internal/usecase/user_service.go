@@ -5,6 +5,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/gostratum/examples/orderservice/internal/audit"
 	"github.com/gostratum/examples/orderservice/internal/domain"
 )
 
@@ -29,13 +30,15 @@ func (s *UserService) CreateUser(ctx context.Context, name, email string) (*doma
 	user := domain.NewUser(name, email)
 
 	if err := user.Validate(); err != nil {
-		return nil, ErrInvalid
+		// A *domain.ValidationError, which is ErrInvalid
+		return nil, err
 	}
 
 	if err := s.repo.Save(ctx, user); err != nil {
 		// Translate errors from repository layer
 		return nil, s.translateError(err)
 	}
+	audit.Record(ctx, "user", user.ID, nil, user)
 
 	return user, nil
 }
@@ -54,8 +57,9 @@ func (s *UserService) GetUser(ctx context.Context, id string) (*domain.User, err
 	return user, nil
 }
 
-// UpdateAvatar updates a user's avatar URL
-func (s *UserService) UpdateAvatar(ctx context.Context, userID, avatarURL string) (*domain.User, error) {
+// UpdateAvatar updates a user's avatar URL, and the hash of the avatar's
+// content
+func (s *UserService) UpdateAvatar(ctx context.Context, userID, avatarURL, hash string) (*domain.User, error) {
 	// Apply context deadline
 	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
 	defer cancel()
@@ -67,12 +71,14 @@ func (s *UserService) UpdateAvatar(ctx context.Context, userID, avatarURL string
 	}
 
 	// Update the avatar URL
-	user.UpdateAvatar(avatarURL)
+	before := *user
+	user.UpdateAvatar(avatarURL, hash)
 
 	// Save the updated user
 	if err := s.repo.Update(ctx, user); err != nil {
 		return nil, s.translateError(err)
 	}
+	audit.Record(ctx, "user", user.ID, &before, user)
 
 	return user, nil
 }
@@ -3,68 +3,17 @@ package usecase
 import (
 	"context"
 	"errors"
+	"reflect"
 	"testing"
-	"time"
 
+	"go.uber.org/mock/gomock"
+
+	"github.com/gostratum/examples/orderservice/internal/audit"
 	"github.com/gostratum/examples/orderservice/internal/domain"
+	"github.com/gostratum/examples/orderservice/internal/mocks"
+	"github.com/gostratum/examples/orderservice/internal/testutil/factories"
 )
 
-// MockUserRepository implements ports.UserRepository for testing
-type MockUserRepository struct {
-	users       map[string]*domain.User
-	saveError   error
-	findError   error
-	updateError error
-}
-
-func NewMockUserRepository() *MockUserRepository {
-	return &MockUserRepository{
-		users: make(map[string]*domain.User),
-	}
-}
-
-func (m *MockUserRepository) Save(ctx context.Context, u *domain.User) error {
-	if m.saveError != nil {
-		return m.saveError
-	}
-	m.users[u.ID] = u
-	return nil
-}
-
-func (m *MockUserRepository) FindByID(ctx context.Context, id string) (*domain.User, error) {
-	if m.findError != nil {
-		return nil, m.findError
-	}
-	user, exists := m.users[id]
-	if !exists {
-		return nil, errors.New("not found")
-	}
-	return user, nil
-}
-
-func (m *MockUserRepository) Update(ctx context.Context, u *domain.User) error {
-	if m.updateError != nil {
-		return m.updateError
-	}
-	if _, exists := m.users[u.ID]; !exists {
-		return errors.New("not found")
-	}
-	m.users[u.ID] = u
-	return nil
-}
-
-func (m *MockUserRepository) SetSaveError(err error) {
-	m.saveError = err
-}
-
-func (m *MockUserRepository) SetFindError(err error) {
-	m.findError = err
-}
-
-func (m *MockUserRepository) SetUpdateError(err error) {
-	m.updateError = err
-}
-
 func TestCreateUser(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -108,9 +57,10 @@ func TestCreateUser(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			repo := NewMockUserRepository()
-			if tt.saveError != nil {
-				repo.SetSaveError(tt.saveError)
+			repo := mocks.NewMockUserRepository(gomock.NewController(t))
+			// Invalid users are refused before they reach the repository
+			if !errors.Is(tt.wantErr, ErrInvalid) {
+				repo.EXPECT().Save(gomock.Any(), gomock.Any()).Return(tt.saveError)
 			}
 
 			ctx := context.Background()
@@ -155,15 +105,10 @@ func TestGetUser(t *testing.T) {
 		wantErr   error
 	}{
 		{
-			name:   "existing user should be returned",
-			userID: "test-id",
-			setupUser: &domain.User{
-				ID:        "test-id",
-				Name:      "John Doe",
-				Email:     "john@example.com",
-				CreatedAt: time.Now(),
-			},
-			wantErr: nil,
+			name:      "existing user should be returned",
+			userID:    "test-id",
+			setupUser: factories.User(func(u *domain.User) { u.ID = "test-id" }),
+			wantErr:   nil,
 		},
 		{
 			name:      "non-existing user should return not found error",
@@ -181,15 +126,8 @@ func TestGetUser(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			repo := NewMockUserRepository()
-
-			if tt.setupUser != nil {
-				repo.users[tt.setupUser.ID] = tt.setupUser
-			}
-
-			if tt.findError != nil {
-				repo.SetFindError(tt.findError)
-			}
+			repo := mocks.NewMockUserRepository(gomock.NewController(t))
+			repo.EXPECT().FindByID(gomock.Any(), tt.userID).Return(tt.setupUser, tt.findError)
 
 			ctx := context.Background()
 			service := NewUserService(repo)
@@ -223,3 +161,27 @@ func TestGetUser(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateAvatar_RecordsChanges(t *testing.T) {
+	user := factories.User(func(u *domain.User) { u.ID = "test-id" })
+	repo := mocks.NewMockUserRepository(gomock.NewController(t))
+	repo.EXPECT().FindByID(gomock.Any(), "test-id").Return(user, nil)
+	repo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+	ctx, trail := audit.WithTrail(context.Background())
+	if _, err := NewUserService(repo).UpdateAvatar(ctx, "test-id", "avatars/ab.png", "ab"); err != nil {
+		t.Fatalf("UpdateAvatar() unexpected error = %v", err)
+	}
+
+	entries := trail.Entries(audit.Entry{})
+	if len(entries) != 1 {
+		t.Fatalf("UpdateAvatar() recorded %d entries, want 1", len(entries))
+	}
+	want := map[string]audit.Change{
+		"avatar_url":  {Before: "", After: "avatars/ab.png"},
+		"avatar_hash": {Before: "", After: "ab"},
+	}
+	if got := entries[0]; got.EntityType != "user" || got.EntityID != "test-id" || got.Action != audit.ActionUpdate || !reflect.DeepEqual(got.Changes, want) {
+		t.Errorf("UpdateAvatar() recorded %+v, want an update of user test-id with %v", got, want)
+	}
+}
@@ -0,0 +1,14 @@
+-- Restore the JSONB items column as the only copy of the items
+ALTER TABLE orders ALTER COLUMN id DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN id DROP DEFAULT;
+
+UPDATE orders SET items = COALESCE(
+    (SELECT jsonb_agg(jsonb_build_object('sku', sku, 'qty', qty, 'price', price) ORDER BY id) FROM items WHERE items.order_id = orders.id),
+    '[]'::jsonb
+)
+WHERE items IS NULL;
+
+ALTER TABLE orders ALTER COLUMN items SET NOT NULL;
+ALTER TABLE orders DROP COLUMN IF EXISTS total;
+
+DROP TABLE IF EXISTS items;
@@ -0,0 +1,29 @@
+-- Bring the schema in line with the repository: order items get a table of
+-- their own, orders a total, and ids a database default. The items JSONB
+-- column stays until 000006, for replicas still running the old code.
+
+CREATE TABLE IF NOT EXISTS items (
+    id BIGSERIAL PRIMARY KEY,
+    order_id VARCHAR(36) NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    sku VARCHAR(255) NOT NULL,
+    qty INTEGER NOT NULL,
+    price DOUBLE PRECISION NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_items_order_id ON items(order_id);
+
+-- Copy the items of existing orders out of the JSONB column
+INSERT INTO items (order_id, sku, qty, price)
+SELECT o.id, i.sku, i.qty, i.price
+FROM orders o, jsonb_to_recordset(o.items) AS i(sku TEXT, qty INTEGER, price DOUBLE PRECISION)
+WHERE jsonb_typeof(o.items) = 'array';
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS total DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+UPDATE orders SET total = (SELECT COALESCE(SUM(qty * price), 0) FROM items WHERE items.order_id = orders.id);
+
+-- New orders no longer write the JSONB column
+ALTER TABLE orders ALTER COLUMN items DROP NOT NULL;
+
+ALTER TABLE users ALTER COLUMN id SET DEFAULT gen_random_uuid();
+ALTER TABLE orders ALTER COLUMN id SET DEFAULT gen_random_uuid();
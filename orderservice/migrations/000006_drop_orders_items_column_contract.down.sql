@@ -0,0 +1,6 @@
+-- Recreate the JSONB items column from the items table
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS items JSONB;
+
+UPDATE orders SET items = (
+    SELECT jsonb_agg(jsonb_build_object('sku', sku, 'qty', qty, 'price', price) ORDER BY id) FROM items WHERE items.order_id = orders.id
+);
@@ -0,0 +1,3 @@
+-- Items live in the items table since 000005; run once no replica reads the
+-- JSONB column any more
+ALTER TABLE orders DROP COLUMN IF EXISTS items;
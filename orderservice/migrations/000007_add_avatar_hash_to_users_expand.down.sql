@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_users_avatar_hash;
+ALTER TABLE users DROP COLUMN IF EXISTS avatar_hash;
@@ -0,0 +1,8 @@
+-- Avatars are stored under the SHA-256 of their content; the hash records
+-- which stored avatar each user refers to, so the ones nobody refers to can be
+-- collected. Avatars uploaded before have none, and are never collected.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_hash VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_users_avatar_hash ON users(avatar_hash);
+
+COMMENT ON COLUMN users.avatar_hash IS 'Hex SHA-256 of the avatar, part of its storage key';
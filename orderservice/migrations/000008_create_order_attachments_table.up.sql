@@ -0,0 +1,14 @@
+-- Documents attached to orders, such as purchase orders and receipts. The
+-- file is in object storage at storage_key; the row lists and serves it.
+-- Attachments go with their order.
+CREATE TABLE IF NOT EXISTS order_attachments (
+    id VARCHAR(36) PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id VARCHAR(36) NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    storage_key TEXT NOT NULL UNIQUE,
+    filename TEXT NOT NULL,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    size BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_attachments_order_id ON order_attachments(order_id);
@@ -0,0 +1,20 @@
+-- Who did what through the API: a row for each entity a request changed, or
+-- for the request alone when it changed nothing. Rows are only ever added.
+-- changes holds the fields that changed, {"field": {"before": ..., "after": ...}}.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id VARCHAR(36) PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    actor TEXT NOT NULL,
+    request_id TEXT NOT NULL DEFAULT '',
+    method VARCHAR(10) NOT NULL,
+    route TEXT NOT NULL,
+    status INTEGER NOT NULL,
+    entity_type VARCHAR(64) NOT NULL DEFAULT '',
+    entity_id VARCHAR(64) NOT NULL DEFAULT '',
+    action VARCHAR(16) NOT NULL DEFAULT '',
+    changes JSONB
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id, created_at);
@@ -0,0 +1,234 @@
+package migrations_test
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gostratum/dbx/migrate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+
+	"github.com/gostratum/examples/orderservice/internal/adapter/repo"
+	"github.com/gostratum/examples/orderservice/migrations"
+	"github.com/gostratum/examples/testkit/containers"
+)
+
+// These tests apply the migrations with dbx/migrate, as cmd/migrations and
+// auto_migrate do, to an empty PostgreSQL database of their own.
+
+func TestMain(m *testing.M) {
+	os.Exit(containers.Run(m))
+}
+
+// migrator applies the migrations of this directory to one database
+type migrator struct {
+	t    *testing.T
+	dsn  string
+	opts []migrate.Option
+	db   *gorm.DB
+}
+
+func newMigrator(t *testing.T) *migrator {
+	t.Helper()
+	database := containers.Postgres(t)
+
+	dir, err := filepath.Abs(".")
+	require.NoError(t, err)
+	db, err := gorm.Open(postgres.Open(database.DSN), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	return &migrator{t: t, dsn: database.DSN, opts: []migrate.Option{migrate.WithDir(dir)}, db: db}
+}
+
+// steps applies n migrations, or rolls back -n
+func (m *migrator) steps(n int) {
+	m.t.Helper()
+	require.NoError(m.t, migrate.Steps(context.Background(), m.dsn, n, m.opts...), "steps %d", n)
+}
+
+// schema describes the tables, columns, indexes and constraints of the
+// database, one sorted line each, leaving out migrate's own table
+func (m *migrator) schema() []string {
+	m.t.Helper()
+	var lines []string
+	require.NoError(m.t, m.db.Raw(`
+		SELECT format('column %s.%s %s nullable=%s default=%s', table_name, column_name, data_type, is_nullable, column_default)
+		FROM information_schema.columns
+		WHERE table_schema = 'public' AND table_name <> 'schema_migrations'
+		UNION ALL
+		SELECT format('index %s', indexdef)
+		FROM pg_indexes
+		WHERE schemaname = 'public' AND tablename <> 'schema_migrations'
+		UNION ALL
+		SELECT format('constraint %s.%s %s', c.conrelid::regclass, c.conname, pg_get_constraintdef(c.oid))
+		FROM pg_constraint c JOIN pg_namespace n ON n.oid = c.connamespace
+		WHERE n.nspname = 'public' AND c.conrelid::regclass::text <> 'schema_migrations'
+		UNION ALL
+		SELECT format('comment %s.%s %s', c.relname, a.attname, d.description)
+		FROM pg_description d
+		JOIN pg_class c ON c.oid = d.objoid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_attribute a ON a.attrelid = c.oid AND a.attnum = d.objsubid
+		WHERE n.nspname = 'public'
+	`).Scan(&lines).Error)
+	slices.Sort(lines)
+	return lines
+}
+
+// upFiles returns the names of the up migrations, in the order they apply
+func upFiles(t *testing.T) []string {
+	t.Helper()
+	files, err := fs.Glob(migrations.FS, "*.up.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	slices.Sort(files)
+	return files
+}
+
+func TestMigrations_UpDownUp(t *testing.T) {
+	t.Parallel()
+	m := newMigrator(t)
+
+	// Each migration is applied, rolled back and applied again. The down
+	// migration must leave the schema exactly as it was before the up, and
+	// the up must apply again on top of it.
+	before := m.schema()
+	for _, file := range upFiles(t) {
+		m.steps(1)
+		after := m.schema()
+		assert.NotEqual(t, before, after, "%s changes nothing", file)
+
+		m.steps(-1)
+		assert.Equal(t, before, m.schema(), "%s is not reversed by its down migration", file)
+
+		m.steps(1)
+		assert.Equal(t, after, m.schema(), "%s applies differently after a rollback", file)
+		before = after
+	}
+
+	require.NoError(t, migrate.Down(context.Background(), m.dsn, m.opts...))
+	assert.Empty(t, m.schema(), "rolling back every migration leaves the database empty")
+}
+
+func TestMigrations_ItemsSurviveRoundTrip(t *testing.T) {
+	t.Parallel()
+	m := newMigrator(t)
+	files := upFiles(t)
+	expand := slices.IndexFunc(files, func(f string) bool { return strings.HasPrefix(f, "000005_") })
+	require.NotEqual(t, -1, expand)
+
+	// 000005 copies the JSONB items of orders into the items table, 000006
+	// drops the column, and their down migrations copy the items back
+	m.steps(expand)
+	items := `[{"sku": "LAPTOP", "qty": 1, "price": 1200}, {"sku": "MOUSE", "qty": 2, "price": 25}]`
+	require.NoError(t, m.db.Exec(`INSERT INTO users (id, name, email) VALUES ('u-1', 'Ada', 'ada@example.com')`).Error)
+	require.NoError(t, m.db.Exec(`INSERT INTO orders (id, user_id, items) VALUES ('o-1', 'u-1', ?::jsonb), ('o-2', 'u-1', '[]')`, items).Error)
+
+	m.steps(len(files) - expand)
+	var stored []repo.ItemEntity
+	require.NoError(t, m.db.Order("id").Find(&stored, "order_id = ?", "o-1").Error)
+	require.Len(t, stored, 2)
+	assert.Equal(t, "LAPTOP", stored[0].SKU)
+	assert.Equal(t, 2, stored[1].Qty)
+	var total float64
+	require.NoError(t, m.db.Raw(`SELECT total FROM orders WHERE id = 'o-1'`).Scan(&total).Error)
+	assert.InDelta(t, 1250, total, 1e-9)
+
+	m.steps(expand - len(files))
+	var restored, empty bool
+	require.NoError(t, m.db.Raw(`SELECT items = ?::jsonb FROM orders WHERE id = 'o-1'`, items).Scan(&restored).Error)
+	assert.True(t, restored, "the items of o-1 are restored")
+	require.NoError(t, m.db.Raw(`SELECT items = '[]'::jsonb FROM orders WHERE id = 'o-2'`).Scan(&empty).Error)
+	assert.True(t, empty, "an order without items gets an empty array back, not NULL")
+}
+
+// columnTypes are the PostgreSQL data types a column may have for a field of
+// each Go kind to read and write it
+var columnTypes = map[reflect.Kind][]string{
+	reflect.String:  {"character varying", "character", "text", "uuid"},
+	reflect.Int:     {"smallint", "integer", "bigint"},
+	reflect.Int64:   {"bigint"},
+	reflect.Uint:    {"smallint", "integer", "bigint"},
+	reflect.Float64: {"real", "double precision", "numeric"},
+	reflect.Bool:    {"boolean"},
+	reflect.Slice:   {"jsonb", "bytea"},
+}
+
+// timeTypes are the data types of time.Time fields
+var timeTypes = []string{"timestamp without time zone", "timestamp with time zone"}
+
+type column struct {
+	ColumnName    string
+	DataType      string
+	IsNullable    string
+	ColumnDefault *string
+}
+
+func TestMigrations_MatchEntities(t *testing.T) {
+	t.Parallel()
+	m := newMigrator(t)
+	require.NoError(t, migrate.Up(context.Background(), m.dsn, m.opts...))
+
+	for _, entity := range []any{&repo.UserEntity{}, &repo.OrderEntity{}, &repo.ItemEntity{}, &repo.AttachmentEntity{}, &repo.AuditEntryEntity{}} {
+		s, err := schema.Parse(entity, &sync.Map{}, m.db.NamingStrategy)
+		require.NoError(t, err)
+
+		t.Run(s.Table, func(t *testing.T) {
+			var columns []column
+			require.NoError(t, m.db.Raw(`
+				SELECT column_name, data_type, is_nullable, column_default
+				FROM information_schema.columns
+				WHERE table_schema = 'public' AND table_name = ?`, s.Table).Scan(&columns).Error)
+			require.NotEmpty(t, columns, "table %s does not exist", s.Table)
+			byName := map[string]column{}
+			for _, c := range columns {
+				byName[c.ColumnName] = c
+			}
+
+			// Every field the entity maps has a column it can be stored in
+			for _, name := range s.DBNames {
+				field := s.FieldsByDBName[name]
+				c, ok := byName[name]
+				if !assert.True(t, ok, "column %s.%s is missing", s.Table, name) {
+					continue
+				}
+				want := columnTypes[field.FieldType.Kind()]
+				if field.FieldType == reflect.TypeOf(time.Time{}) {
+					want = timeTypes
+				}
+				assert.Contains(t, want, c.DataType, "type of %s.%s, for a %s", s.Table, name, field.FieldType)
+				if field.NotNull || field.PrimaryKey {
+					assert.Equal(t, "NO", c.IsNullable, "%s.%s is NOT NULL in the entity", s.Table, name)
+				}
+				// The repositories leave these to the database, as gen_random_uuid()
+				if field.HasDefaultValue && field.DefaultValue != "" {
+					assert.NotNil(t, c.ColumnDefault, "%s.%s has a default in the entity", s.Table, name)
+				}
+			}
+
+			// and no column the entity does not write stops an insert
+			for _, c := range columns {
+				if _, mapped := s.FieldsByDBName[c.ColumnName]; mapped {
+					continue
+				}
+				assert.False(t, c.IsNullable == "NO" && c.ColumnDefault == nil,
+					"%s.%s is NOT NULL without a default, and the entity does not write it", s.Table, c.ColumnName)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+// Package migrations embeds the versioned SQL migration files so that binaries
+// such as cmd/migrations are self-contained and do not need this directory on disk.
+package migrations
+
+import "embed"
+
+// FS holds every *.sql migration in this directory
+//
+//go:embed *.sql
+var FS embed.FS
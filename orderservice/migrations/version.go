@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// LatestVersion returns the highest migration version embedded in FS, which is the
+// schema version this build of the service expects
+func LatestVersion() (uint, error) {
+	files, err := fs.Glob(FS, "*.up.sql")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint
+	for _, name := range files {
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			continue
+		}
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		if uint(version) > latest {
+			latest = uint(version)
+		}
+	}
+
+	if latest == 0 {
+		return 0, fmt.Errorf("no versioned migrations embedded")
+	}
+	return latest, nil
+}
@@ -0,0 +1,23 @@
+package migrations
+
+import (
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestVersion(t *testing.T) {
+	latest, err := LatestVersion()
+	require.NoError(t, err)
+
+	matches, err := fs.Glob(FS, fmt.Sprintf("%06d_*.up.sql", latest))
+	require.NoError(t, err)
+	assert.Len(t, matches, 1, "latest version should have exactly one up migration")
+
+	newer, err := fs.Glob(FS, fmt.Sprintf("%06d_*.up.sql", latest+1))
+	require.NoError(t, err)
+	assert.Empty(t, newer)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/examples/paymentservice/internal/adapter/gateway"
+	httpAdapter "github.com/gostratum/examples/paymentservice/internal/adapter/http"
+	"github.com/gostratum/examples/paymentservice/internal/adapter/memory"
+	"github.com/gostratum/examples/paymentservice/internal/usecase"
+	"github.com/gostratum/examples/redact"
+	"github.com/gostratum/httpx"
+)
+
+func main() {
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// Include httpx module; charges are kept in memory so there is no database
+		httpx.Module(),
+
+		// Provide dependencies
+		fx.Provide(
+			// In-memory repository and simulated card processor
+			memory.NewChargeRepo,
+			gateway.NewSimulator,
+
+			// Usecase services
+			usecase.NewPaymentService,
+
+			// HTTP handlers and request signature verification
+			httpAdapter.NewPaymentHandler,
+			httpAdapter.NewVerifier,
+		),
+
+		// Invoke setup functions
+		fx.Invoke(
+			httpAdapter.RegisterRoutes,
+		),
+	)
+
+	app.Run()
+}
@@ -0,0 +1,96 @@
+// Package gateway provides a simulated card processor so orderservice's payment flow
+// can be exercised against slow, flaky and declining payments without a real provider
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/gostratum/core/configx"
+
+	"github.com/gostratum/examples/paymentservice/internal/domain"
+	"github.com/gostratum/examples/paymentservice/internal/usecase"
+)
+
+// ErrGatewayFailure is returned for simulated processor outages
+var ErrGatewayFailure = errors.New("simulated gateway failure")
+
+// Config tunes the simulated processor
+type Config struct {
+	// Latency is the base delay of every gateway call
+	Latency time.Duration `mapstructure:"latency" default:"150ms"`
+	// Jitter adds up to this much random delay on top of Latency
+	Jitter time.Duration `mapstructure:"jitter" default:"100ms"`
+	// FailureRate is the fraction of calls (0-1) that fail as if the processor were down
+	FailureRate float64 `mapstructure:"failure_rate" default:"0.05"`
+	// DeclineOver declines charges above this amount in minor units; 0 never declines
+	DeclineOver int64 `mapstructure:"decline_over" default:"100000"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "gateway"
+}
+
+// Simulator implements usecase.Gateway with configurable latency and failures
+type Simulator struct {
+	cfg Config
+	// random returns a float in [0, 1); replaced in tests for deterministic failures
+	random func() float64
+}
+
+// NewSimulator creates the simulated gateway from the gateway config section
+func NewSimulator(loader configx.Loader) (usecase.Gateway, error) {
+	var cfg Config
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load gateway config: %w", err)
+	}
+	if cfg.FailureRate < 0 || cfg.FailureRate > 1 {
+		return nil, fmt.Errorf("gateway.failure_rate must be between 0 and 1, got %v", cfg.FailureRate)
+	}
+	return newSimulator(cfg, rand.Float64), nil
+}
+
+func newSimulator(cfg Config, random func() float64) *Simulator {
+	return &Simulator{cfg: cfg, random: random}
+}
+
+// Charge implements usecase.Gateway
+func (s *Simulator) Charge(ctx context.Context, c *domain.Charge) error {
+	if err := s.call(ctx); err != nil {
+		return err
+	}
+	if s.cfg.DeclineOver > 0 && c.Amount > s.cfg.DeclineOver {
+		return fmt.Errorf("%w: amount %d exceeds the card limit", domain.ErrDeclined, c.Amount)
+	}
+	return nil
+}
+
+// Refund implements usecase.Gateway
+func (s *Simulator) Refund(ctx context.Context, r *domain.Refund) error {
+	return s.call(ctx)
+}
+
+// call waits for the simulated latency and then fails at the configured rate
+func (s *Simulator) call(ctx context.Context) error {
+	delay := s.cfg.Latency
+	if s.cfg.Jitter > 0 {
+		delay += time.Duration(s.random() * float64(s.cfg.Jitter))
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+	}
+
+	if s.random() < s.cfg.FailureRate {
+		return ErrGatewayFailure
+	}
+	return nil
+}
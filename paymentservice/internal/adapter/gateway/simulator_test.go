@@ -0,0 +1,54 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gostratum/examples/paymentservice/internal/domain"
+)
+
+func fixed(v float64) func() float64 {
+	return func() float64 { return v }
+}
+
+func TestSimulatorCharge(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		random  float64
+		amount  int64
+		wantErr error
+	}{
+		{name: "succeeds", cfg: Config{FailureRate: 0.1, DeclineOver: 1000}, random: 0.5, amount: 500},
+		{name: "fails at failure rate", cfg: Config{FailureRate: 0.1}, random: 0.05, amount: 500, wantErr: ErrGatewayFailure},
+		{name: "declines over limit", cfg: Config{DeclineOver: 1000}, random: 0.5, amount: 1001, wantErr: domain.ErrDeclined},
+		{name: "zero limit never declines", cfg: Config{}, random: 0.5, amount: 1_000_000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := newSimulator(tt.cfg, fixed(tt.random))
+			err := sim.Charge(context.Background(), domain.NewCharge("order1", tt.amount, "USD"))
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSimulatorHonoursContext(t *testing.T) {
+	sim := newSimulator(Config{Latency: time.Second}, fixed(0.5))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := sim.Refund(ctx, &domain.Refund{Amount: 100})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gostratum/examples/paymentservice/internal/domain"
+)
+
+// ChargeResponse is the HTTP DTO for charge data
+// This struct handles JSON serialization concerns for the HTTP layer
+type ChargeResponse struct {
+	ID        string    `json:"id"`
+	OrderID   string    `json:"order_id"`
+	Amount    int64     `json:"amount"`
+	Currency  string    `json:"currency"`
+	Refunded  int64     `json:"refunded"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FromDomainCharge converts a domain.Charge to ChargeResponse DTO
+func FromDomainCharge(charge *domain.Charge) *ChargeResponse {
+	if charge == nil {
+		return nil
+	}
+	return &ChargeResponse{
+		ID:        charge.ID,
+		OrderID:   charge.OrderID,
+		Amount:    charge.Amount,
+		Currency:  charge.Currency,
+		Refunded:  charge.Refunded,
+		Status:    charge.Status,
+		CreatedAt: charge.CreatedAt,
+	}
+}
+
+// RefundResponse is the HTTP DTO for refund data
+type RefundResponse struct {
+	ID        string    `json:"id"`
+	ChargeID  string    `json:"charge_id"`
+	Amount    int64     `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FromDomainRefund converts a domain.Refund to RefundResponse DTO
+func FromDomainRefund(refund *domain.Refund) *RefundResponse {
+	if refund == nil {
+		return nil
+	}
+	return &RefundResponse{
+		ID:        refund.ID,
+		ChargeID:  refund.ChargeID,
+		Amount:    refund.Amount,
+		CreatedAt: refund.CreatedAt,
+	}
+}
@@ -0,0 +1,181 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/paymentservice/internal/adapter/memory"
+	"github.com/gostratum/examples/paymentservice/internal/domain"
+	"github.com/gostratum/examples/paymentservice/internal/usecase"
+)
+
+const testSecret = "test-secret"
+
+// declineOver declines charges above a fixed amount without any latency
+type declineOver int64
+
+func (d declineOver) Charge(ctx context.Context, c *domain.Charge) error {
+	if c.Amount > int64(d) {
+		return fmt.Errorf("%w: over limit", domain.ErrDeclined)
+	}
+	return nil
+}
+
+func (d declineOver) Refund(ctx context.Context, r *domain.Refund) error {
+	return nil
+}
+
+func setupRouter(now time.Time) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	service := usecase.NewPaymentService(memory.NewChargeRepo(), declineOver(10000))
+	handler := NewPaymentHandler(service, logx.NewNoopLogger())
+	verifier := newVerifier(testSecret, 5*time.Minute, func() time.Time { return now })
+
+	e := gin.New()
+	charges := e.Group("/charges", verifier.Middleware())
+	charges.POST("", handler.CreateCharge)
+	charges.GET("", handler.FindCharge)
+	charges.GET("/:id", handler.GetCharge)
+	charges.POST("/:id/refunds", handler.CreateRefund)
+	return e
+}
+
+func signedRequest(t *testing.T, method, path string, body any, ts time.Time) *http.Request {
+	t.Helper()
+
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		require.NoError(t, err)
+	}
+
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, fmt.Sprintf("t=%d,v1=%s", ts.Unix(), Sign([]byte(testSecret), ts.Unix(), payload)))
+	return req
+}
+
+func TestChargeAndRefund(t *testing.T) {
+	now := time.Now()
+	e := setupRouter(now)
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, signedRequest(t, http.MethodPost, "/charges", CreateChargeRequest{OrderID: "order1", Amount: 2500, Currency: "usd"}, now))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var charge responsex.Envelope[ChargeResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &charge))
+	assert.Equal(t, "USD", charge.Data.Currency)
+	assert.Equal(t, domain.ChargeSucceeded, charge.Data.Status)
+
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, signedRequest(t, http.MethodPost, "/charges/"+charge.Data.ID+"/refunds", CreateRefundRequest{Amount: 1000}, now))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, signedRequest(t, http.MethodPost, "/charges/"+charge.Data.ID+"/refunds", CreateRefundRequest{Amount: 5000}, now))
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, signedRequest(t, http.MethodGet, "/charges/"+charge.Data.ID, nil, now))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &charge))
+	assert.Equal(t, int64(1000), charge.Data.Refunded)
+	assert.Equal(t, domain.ChargePartiallyRefunded, charge.Data.Status)
+}
+
+func TestFindChargeByOrder(t *testing.T) {
+	now := time.Now()
+	e := setupRouter(now)
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, signedRequest(t, http.MethodGet, "/charges?order_id=order1", nil, now))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, signedRequest(t, http.MethodPost, "/charges", CreateChargeRequest{OrderID: "order1", Amount: 2500, Currency: "USD"}, now))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created responsex.Envelope[ChargeResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, signedRequest(t, http.MethodGet, "/charges?order_id=order1", nil, now))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var found responsex.Envelope[ChargeResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &found))
+	assert.Equal(t, created.Data.ID, found.Data.ID)
+
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, signedRequest(t, http.MethodGet, "/charges", nil, now))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestChargeDeclined(t *testing.T) {
+	now := time.Now()
+	e := setupRouter(now)
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, signedRequest(t, http.MethodPost, "/charges", CreateChargeRequest{OrderID: "order1", Amount: 20000, Currency: "USD"}, now))
+	assert.Equal(t, http.StatusPaymentRequired, w.Code)
+	assert.Contains(t, w.Body.String(), "PAYMENT_DECLINED")
+}
+
+func TestSignatureVerification(t *testing.T) {
+	now := time.Now()
+	e := setupRouter(now)
+	body := CreateChargeRequest{OrderID: "order1", Amount: 100, Currency: "USD"}
+
+	tests := []struct {
+		name   string
+		modify func(req *http.Request)
+	}{
+		{name: "missing header", modify: func(req *http.Request) { req.Header.Del(SignatureHeader) }},
+		{name: "malformed header", modify: func(req *http.Request) { req.Header.Set(SignatureHeader, "v1=abc") }},
+		{name: "wrong secret", modify: func(req *http.Request) {
+			req.Header.Set(SignatureHeader, fmt.Sprintf("t=%d,v1=%s", now.Unix(), Sign([]byte("other"), now.Unix(), nil)))
+		}},
+		{name: "tampered body", modify: func(req *http.Request) {
+			req.Body = signedRequest(t, http.MethodPost, "/charges", CreateChargeRequest{OrderID: "order1", Amount: 1, Currency: "USD"}, now).Body
+		}},
+		{name: "stale timestamp", modify: func(req *http.Request) {
+			*req = *signedRequest(t, http.MethodPost, "/charges", body, now.Add(-10*time.Minute))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := signedRequest(t, http.MethodPost, "/charges", body, now)
+			tt.modify(req)
+
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Contains(t, w.Body.String(), "INVALID_SIGNATURE")
+		})
+	}
+}
+
+func TestUnsignedRequestsAllowedWithoutSecret(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	e := gin.New()
+	e.Use(newVerifier("", time.Minute, time.Now).Middleware())
+	e.GET("/ping", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/paymentservice/internal/usecase"
+)
+
+// PaymentHandler handles charge and refund HTTP requests
+type PaymentHandler struct {
+	service *usecase.PaymentService
+	log     logx.Logger
+}
+
+// NewPaymentHandler creates a new payment handler
+func NewPaymentHandler(service *usecase.PaymentService, log logx.Logger) *PaymentHandler {
+	return &PaymentHandler{service: service, log: log}
+}
+
+// CreateChargeRequest represents the request payload for charging an order
+type CreateChargeRequest struct {
+	OrderID string `json:"order_id" binding:"required"`
+	// Amount in minor units (e.g. cents)
+	Amount   int64  `json:"amount" binding:"required"`
+	Currency string `json:"currency" binding:"required"`
+}
+
+// CreateRefundRequest represents the request payload for refunding a charge
+type CreateRefundRequest struct {
+	// Amount in minor units; omit to refund everything that is left
+	Amount int64 `json:"amount"`
+}
+
+// CreateCharge handles POST /charges
+func (h *PaymentHandler) CreateCharge(c *gin.Context) {
+	var req CreateChargeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid request payload", nil)
+		return
+	}
+
+	charge, err := h.service.Charge(c.Request.Context(), req.OrderID, req.Amount, req.Currency)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.Created(c, "", FromDomainCharge(charge))
+}
+
+// GetCharge handles GET /charges/:id
+func (h *PaymentHandler) GetCharge(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		responsex.Error(c, http.StatusBadRequest, "MISSING_PARAMETER", "charge id is required", nil)
+		return
+	}
+
+	charge, err := h.service.GetCharge(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.OK(c, FromDomainCharge(charge), nil)
+}
+
+// FindCharge handles GET /charges?order_id=
+func (h *PaymentHandler) FindCharge(c *gin.Context) {
+	orderID := c.Query("order_id")
+	if orderID == "" {
+		responsex.Error(c, http.StatusBadRequest, "MISSING_PARAMETER", "order_id is required", nil)
+		return
+	}
+
+	charge, err := h.service.GetChargeByOrder(c.Request.Context(), orderID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.OK(c, FromDomainCharge(charge), nil)
+}
+
+// CreateRefund handles POST /charges/:id/refunds
+func (h *PaymentHandler) CreateRefund(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		responsex.Error(c, http.StatusBadRequest, "MISSING_PARAMETER", "charge id is required", nil)
+		return
+	}
+
+	// An empty body is a full refund
+	var req CreateRefundRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid request payload", nil)
+			return
+		}
+	}
+
+	refund, err := h.service.Refund(c.Request.Context(), id, req.Amount)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.Created(c, "", FromDomainRefund(refund))
+}
+
+// handleError maps usecase errors to HTTP responses
+func (h *PaymentHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrDeclined):
+		// The message carries the decline reason
+		responsex.Error(c, http.StatusPaymentRequired, "PAYMENT_DECLINED", err.Error(), nil)
+	case errors.Is(err, usecase.ErrNotFound):
+		responsex.Error(c, http.StatusNotFound, "NOT_FOUND", "charge not found", nil)
+	case errors.Is(err, usecase.ErrConflict):
+		responsex.Error(c, http.StatusConflict, "REFUND_EXCEEDS_CHARGE", "refund exceeds the amount left on the charge", nil)
+	case errors.Is(err, usecase.ErrInvalid):
+		responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", "invalid input", nil)
+	case errors.Is(err, usecase.ErrUnavailable):
+		c.Header("Retry-After", "2")
+		responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "service temporarily unavailable", nil)
+	default:
+		h.log.Error("unexpected error", logx.Err(err))
+		responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+	}
+}
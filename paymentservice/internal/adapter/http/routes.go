@@ -0,0 +1,62 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+)
+
+// RegisterRoutes registers all HTTP routes using the provided Gin engine
+// This function is designed to be used with fx.Invoke to work with httpx.Module
+func RegisterRoutes(
+	e *gin.Engine,
+	paymentHandler *PaymentHandler,
+	verifier *Verifier,
+	reg core.Registry,
+	log logx.Logger,
+) {
+	// Add responsex middleware for request tracking and metadata
+	e.Use(responsex.MetaMiddleware("paymentservice/v1.0.0"))
+
+	// Payment endpoints only accept requests signed with the shared secret
+	charges := e.Group("/charges", verifier.Middleware())
+	charges.POST("", paymentHandler.CreateCharge)
+	charges.GET("", paymentHandler.FindCharge)
+	charges.GET("/:id", paymentHandler.GetCharge)
+	charges.POST("/:id/refunds", paymentHandler.CreateRefund)
+
+	// Health endpoints - readiness and liveness checks
+	e.GET("/healthz", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		res := reg.Aggregate(ctx, core.Readiness)
+		if res.OK {
+			c.JSON(http.StatusOK, gin.H{"ok": true, "details": res.Details})
+		} else {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"ok": false, "details": res.Details})
+		}
+	})
+
+	e.GET("/livez", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		res := reg.Aggregate(ctx, core.Liveness)
+		if res.OK {
+			c.JSON(http.StatusOK, gin.H{"ok": true, "details": res.Details})
+		} else {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"ok": false, "details": res.Details})
+		}
+	})
+
+	if len(verifier.secret) == 0 {
+		log.Warn("signing.secret is empty; payment requests are accepted unsigned")
+	}
+	log.Info("HTTP routes registered")
+}
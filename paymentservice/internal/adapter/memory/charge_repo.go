@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gostratum/examples/paymentservice/internal/domain"
+	"github.com/gostratum/examples/paymentservice/internal/usecase"
+)
+
+// ChargeRepo keeps charges and refunds in memory. A payment simulator has no data
+// worth persisting, so the example stays free of a database dependency.
+type ChargeRepo struct {
+	mu      sync.RWMutex
+	charges map[string]domain.Charge
+	orders  map[string]string
+	refunds map[string][]domain.Refund
+}
+
+// NewChargeRepo creates an empty in-memory charge repository
+func NewChargeRepo() usecase.ChargeRepository {
+	return &ChargeRepo{
+		charges: make(map[string]domain.Charge),
+		orders:  make(map[string]string),
+		refunds: make(map[string][]domain.Refund),
+	}
+}
+
+// Save stores a new charge
+func (r *ChargeRepo) Save(ctx context.Context, c *domain.Charge) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id, ok := r.orders[c.OrderID]; ok && id != c.ID {
+		return domain.ErrConflict
+	}
+
+	r.charges[c.ID] = *c
+	r.orders[c.OrderID] = c.ID
+	return nil
+}
+
+// FindByID returns a copy of the charge with the given ID
+func (r *ChargeRepo) FindByID(ctx context.Context, id string) (*domain.Charge, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.charges[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &c, nil
+}
+
+// FindByOrderID returns a copy of the charge made for an order
+func (r *ChargeRepo) FindByOrderID(ctx context.Context, orderID string) (*domain.Charge, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.orders[orderID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	c := r.charges[id]
+	return &c, nil
+}
+
+// AddRefund stores the refund and the charge's new refunded total. The refund is
+// rejected when a concurrent refund already changed the charge.
+func (r *ChargeRepo) AddRefund(ctx context.Context, c *domain.Charge, refund *domain.Refund) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.charges[c.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if stored.Refunded != c.Refunded-refund.Amount {
+		return domain.ErrConflict
+	}
+
+	r.charges[c.ID] = *c
+	r.refunds[c.ID] = append(r.refunds[c.ID], *refund)
+	return nil
+}
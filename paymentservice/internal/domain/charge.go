@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Charge statuses
+const (
+	ChargeSucceeded         = "succeeded"
+	ChargePartiallyRefunded = "partially_refunded"
+	ChargeRefunded          = "refunded"
+)
+
+// Charge is a payment taken for an order. Amounts are in minor units (cents) so they
+// never suffer from floating point rounding.
+// This is a pure domain model without infrastructure concerns
+type Charge struct {
+	ID        string
+	OrderID   string
+	Amount    int64
+	Currency  string
+	Refunded  int64
+	Status    string
+	CreatedAt time.Time
+}
+
+// NewCharge creates a charge for an order with a generated ID
+func NewCharge(orderID string, amount int64, currency string) *Charge {
+	return &Charge{
+		ID:        "ch_" + uuid.New().String(),
+		OrderID:   strings.TrimSpace(orderID),
+		Amount:    amount,
+		Currency:  strings.ToUpper(strings.TrimSpace(currency)),
+		Status:    ChargeSucceeded,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Validate performs basic validation on charge fields
+func (c *Charge) Validate() error {
+	if c.OrderID == "" {
+		return errors.New("order_id is required")
+	}
+	if c.Amount <= 0 {
+		return errors.New("amount must be positive")
+	}
+	if len(c.Currency) != 3 {
+		return errors.New("currency must be a 3-letter ISO code")
+	}
+	return nil
+}
+
+// Refundable returns the amount that has not been refunded yet
+func (c *Charge) Refundable() int64 {
+	return c.Amount - c.Refunded
+}
+
+// Refund creates a refund of amount against the charge; an amount of 0 refunds
+// everything that is left
+func (c *Charge) Refund(amount int64) (*Refund, error) {
+	if amount == 0 {
+		amount = c.Refundable()
+	}
+	if amount < 0 {
+		return nil, errors.New("refund amount cannot be negative")
+	}
+	if amount == 0 || amount > c.Refundable() {
+		return nil, ErrConflict
+	}
+
+	c.Refunded += amount
+	if c.Refunded == c.Amount {
+		c.Status = ChargeRefunded
+	} else {
+		c.Status = ChargePartiallyRefunded
+	}
+
+	return &Refund{
+		ID:        "re_" + uuid.New().String(),
+		ChargeID:  c.ID,
+		Amount:    amount,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// Refund is money returned against a charge
+type Refund struct {
+	ID        string
+	ChargeID  string
+	Amount    int64
+	CreatedAt time.Time
+}
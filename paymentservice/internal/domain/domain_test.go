@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestChargeValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		charge  *Charge
+		wantErr bool
+	}{
+		{name: "valid charge", charge: NewCharge("order1", 1999, "usd"), wantErr: false},
+		{name: "empty order id", charge: NewCharge("", 1999, "USD"), wantErr: true},
+		{name: "zero amount", charge: NewCharge("order1", 0, "USD"), wantErr: true},
+		{name: "invalid currency", charge: NewCharge("order1", 1999, "dollars"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.charge.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Charge.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChargeRefund(t *testing.T) {
+	charge := NewCharge("order1", 1000, "USD")
+
+	refund, err := charge.Refund(300)
+	if err != nil {
+		t.Fatalf("Refund(300) error = %v", err)
+	}
+	if refund.Amount != 300 || refund.ChargeID != charge.ID {
+		t.Errorf("Refund(300) = %+v", refund)
+	}
+	if charge.Status != ChargePartiallyRefunded {
+		t.Errorf("status = %s, want %s", charge.Status, ChargePartiallyRefunded)
+	}
+
+	if _, err := charge.Refund(800); !errors.Is(err, ErrConflict) {
+		t.Errorf("Refund(800) error = %v, want ErrConflict", err)
+	}
+	if _, err := charge.Refund(-1); err == nil {
+		t.Error("Refund(-1) expected an error")
+	}
+
+	refund, err = charge.Refund(0)
+	if err != nil {
+		t.Fatalf("Refund(0) error = %v", err)
+	}
+	if refund.Amount != 700 {
+		t.Errorf("Refund(0) amount = %d, want the remaining 700", refund.Amount)
+	}
+	if charge.Status != ChargeRefunded {
+		t.Errorf("status = %s, want %s", charge.Status, ChargeRefunded)
+	}
+
+	if _, err := charge.Refund(0); !errors.Is(err, ErrConflict) {
+		t.Errorf("Refund(0) on a refunded charge error = %v, want ErrConflict", err)
+	}
+}
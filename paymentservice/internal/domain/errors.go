@@ -0,0 +1,18 @@
+package domain
+
+import "errors"
+
+// Domain errors represent business rule violations
+var (
+	// ErrNotFound indicates a requested resource was not found
+	ErrNotFound = errors.New("resource not found")
+
+	// ErrInvalidInput indicates the provided input violates business rules
+	ErrInvalidInput = errors.New("invalid input")
+
+	// ErrConflict indicates a conflict with the current state (e.g., refunding more than was charged)
+	ErrConflict = errors.New("resource conflict")
+
+	// ErrDeclined indicates the payment gateway declined the charge
+	ErrDeclined = errors.New("payment declined")
+)
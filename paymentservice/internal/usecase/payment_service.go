@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gostratum/examples/paymentservice/internal/domain"
+)
+
+// operationTimeout is longer than in the other examples because the gateway call
+// alone may take seconds
+const operationTimeout = 5 * time.Second
+
+// PaymentService handles charges and refunds
+type PaymentService struct {
+	repo    ChargeRepository
+	gateway Gateway
+}
+
+// NewPaymentService creates a new payment service with repository and gateway injection
+func NewPaymentService(repo ChargeRepository, gateway Gateway) *PaymentService {
+	return &PaymentService{
+		repo:    repo,
+		gateway: gateway,
+	}
+}
+
+// Charge takes a payment for an order. Charging an order again returns the existing
+// charge, so the order ID doubles as idempotency key and callers can retry safely.
+func (s *PaymentService) Charge(ctx context.Context, orderID string, amount int64, currency string) (*domain.Charge, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, operationTimeout)
+	defer cancel()
+
+	charge := domain.NewCharge(orderID, amount, currency)
+	if err := charge.Validate(); err != nil {
+		return nil, ErrInvalid
+	}
+
+	existing, err := s.repo.FindByOrderID(ctx, charge.OrderID)
+	switch {
+	case err == nil:
+		return existing, nil
+	case !errors.Is(err, domain.ErrNotFound):
+		return nil, translateError(err)
+	}
+
+	if err := s.gateway.Charge(ctx, charge); err != nil {
+		return nil, translateError(err)
+	}
+
+	if err := s.repo.Save(ctx, charge); err != nil {
+		return nil, translateError(err)
+	}
+
+	return charge, nil
+}
+
+// GetCharge retrieves a charge by ID
+func (s *PaymentService) GetCharge(ctx context.Context, id string) (*domain.Charge, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, operationTimeout)
+	defer cancel()
+
+	charge, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return charge, nil
+}
+
+// GetChargeByOrder retrieves the charge of an order. Callers that lost the answer
+// to Charge use it to learn whether the order was charged after all.
+func (s *PaymentService) GetChargeByOrder(ctx context.Context, orderID string) (*domain.Charge, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, operationTimeout)
+	defer cancel()
+
+	charge, err := s.repo.FindByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return charge, nil
+}
+
+// Refund returns amount (or everything left when amount is 0) of a charge
+func (s *PaymentService) Refund(ctx context.Context, chargeID string, amount int64) (*domain.Refund, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, operationTimeout)
+	defer cancel()
+
+	charge, err := s.repo.FindByID(ctx, chargeID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	refund, err := charge.Refund(amount)
+	if err != nil {
+		if errors.Is(err, domain.ErrConflict) {
+			return nil, ErrConflict
+		}
+		return nil, ErrInvalid
+	}
+
+	if err := s.gateway.Refund(ctx, refund); err != nil {
+		return nil, translateError(err)
+	}
+
+	if err := s.repo.AddRefund(ctx, charge, refund); err != nil {
+		return nil, translateError(err)
+	}
+
+	return refund, nil
+}
+
+// translateError converts repository/gateway errors to usecase errors. Domain errors
+// pass through unchanged so messages like the decline reason survive.
+func translateError(err error) error {
+	for _, domainErr := range []error{domain.ErrNotFound, domain.ErrConflict, domain.ErrInvalidInput, domain.ErrDeclined} {
+		if errors.Is(err, domainErr) {
+			return err
+		}
+	}
+
+	// All other errors are infrastructure/availability issues
+	return fmt.Errorf("%w: %v", ErrUnavailable, err)
+}
@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gostratum/examples/paymentservice/internal/domain"
+)
+
+// MockChargeRepository implements ChargeRepository for testing
+type MockChargeRepository struct {
+	charges   map[string]*domain.Charge
+	refunds   []*domain.Refund
+	saveError error
+}
+
+func NewMockChargeRepository() *MockChargeRepository {
+	return &MockChargeRepository{
+		charges: make(map[string]*domain.Charge),
+	}
+}
+
+func (m *MockChargeRepository) Save(ctx context.Context, c *domain.Charge) error {
+	if m.saveError != nil {
+		return m.saveError
+	}
+	m.charges[c.ID] = c
+	return nil
+}
+
+func (m *MockChargeRepository) FindByID(ctx context.Context, id string) (*domain.Charge, error) {
+	charge, exists := m.charges[id]
+	if !exists {
+		return nil, domain.ErrNotFound
+	}
+	return charge, nil
+}
+
+func (m *MockChargeRepository) FindByOrderID(ctx context.Context, orderID string) (*domain.Charge, error) {
+	for _, charge := range m.charges {
+		if charge.OrderID == orderID {
+			return charge, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (m *MockChargeRepository) AddRefund(ctx context.Context, c *domain.Charge, r *domain.Refund) error {
+	if m.saveError != nil {
+		return m.saveError
+	}
+	m.charges[c.ID] = c
+	m.refunds = append(m.refunds, r)
+	return nil
+}
+
+// MockGateway implements Gateway for testing
+type MockGateway struct {
+	charges     int
+	refunds     int
+	chargeError error
+	refundError error
+}
+
+func (m *MockGateway) Charge(ctx context.Context, c *domain.Charge) error {
+	if m.chargeError != nil {
+		return m.chargeError
+	}
+	m.charges++
+	return nil
+}
+
+func (m *MockGateway) Refund(ctx context.Context, r *domain.Refund) error {
+	if m.refundError != nil {
+		return m.refundError
+	}
+	m.refunds++
+	return nil
+}
+
+func TestCharge(t *testing.T) {
+	tests := []struct {
+		name        string
+		orderID     string
+		amount      int64
+		chargeError error
+		saveError   error
+		wantErr     error
+	}{
+		{name: "valid charge", orderID: "order1", amount: 1999},
+		{name: "zero amount should return invalid error", orderID: "order1", amount: 0, wantErr: ErrInvalid},
+		{name: "declined charge should return declined error", orderID: "order1", amount: 1999, chargeError: domain.ErrDeclined, wantErr: ErrDeclined},
+		{
+			name:        "gateway failure should return unavailable error",
+			orderID:     "order1",
+			amount:      1999,
+			chargeError: errors.New("connection reset"),
+			wantErr:     ErrUnavailable,
+		},
+		{
+			name:      "repository error should return unavailable error",
+			orderID:   "order1",
+			amount:    1999,
+			saveError: errors.New("disk full"),
+			wantErr:   ErrUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockChargeRepository()
+			repo.saveError = tt.saveError
+			gateway := &MockGateway{chargeError: tt.chargeError}
+
+			service := NewPaymentService(repo, gateway)
+			charge, err := service.Charge(context.Background(), tt.orderID, tt.amount, "USD")
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Charge() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if charge != nil {
+					t.Error("Charge() expected nil charge on error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Charge() unexpected error = %v", err)
+			}
+			if charge.Amount != tt.amount || charge.Status != domain.ChargeSucceeded {
+				t.Errorf("Charge() = %+v", charge)
+			}
+		})
+	}
+}
+
+func TestChargeIsIdempotentPerOrder(t *testing.T) {
+	repo := NewMockChargeRepository()
+	gateway := &MockGateway{}
+	service := NewPaymentService(repo, gateway)
+
+	first, err := service.Charge(context.Background(), "order1", 1999, "USD")
+	if err != nil {
+		t.Fatalf("Charge() unexpected error = %v", err)
+	}
+	second, err := service.Charge(context.Background(), "order1", 1999, "USD")
+	if err != nil {
+		t.Fatalf("Charge() retry unexpected error = %v", err)
+	}
+
+	if first.ID != second.ID {
+		t.Errorf("retry created a new charge: %s != %s", first.ID, second.ID)
+	}
+	if gateway.charges != 1 {
+		t.Errorf("gateway charged %d times, want 1", gateway.charges)
+	}
+}
+
+func TestRefund(t *testing.T) {
+	tests := []struct {
+		name        string
+		chargeID    string
+		amount      int64
+		refundError error
+		wantAmount  int64
+		wantErr     error
+	}{
+		{name: "full refund", amount: 0, wantAmount: 1000},
+		{name: "partial refund", amount: 400, wantAmount: 400},
+		{name: "over refund should return conflict error", amount: 1500, wantErr: ErrConflict},
+		{name: "negative amount should return invalid error", amount: -1, wantErr: ErrInvalid},
+		{name: "unknown charge should return not found error", chargeID: "ch_missing", wantErr: ErrNotFound},
+		{name: "gateway failure should return unavailable error", refundError: errors.New("timeout"), wantErr: ErrUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockChargeRepository()
+			charge := domain.NewCharge("order1", 1000, "USD")
+			repo.charges[charge.ID] = charge
+
+			chargeID := tt.chargeID
+			if chargeID == "" {
+				chargeID = charge.ID
+			}
+
+			service := NewPaymentService(repo, &MockGateway{refundError: tt.refundError})
+			refund, err := service.Refund(context.Background(), chargeID, tt.amount)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Refund() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Refund() unexpected error = %v", err)
+			}
+			if refund.Amount != tt.wantAmount {
+				t.Errorf("Refund() amount = %d, want %d", refund.Amount, tt.wantAmount)
+			}
+			if len(repo.refunds) != 1 {
+				t.Errorf("stored %d refunds, want 1", len(repo.refunds))
+			}
+		})
+	}
+}
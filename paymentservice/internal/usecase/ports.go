@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/gostratum/examples/paymentservice/internal/domain"
+)
+
+// ChargeRepository defines the interface for charge data operations
+// This interface is owned by the use case layer (dependency inversion principle)
+type ChargeRepository interface {
+	Save(ctx context.Context, c *domain.Charge) error
+	FindByID(ctx context.Context, id string) (*domain.Charge, error)
+	FindByOrderID(ctx context.Context, orderID string) (*domain.Charge, error)
+	// AddRefund stores the refund together with the updated charge
+	AddRefund(ctx context.Context, c *domain.Charge, r *domain.Refund) error
+}
+
+// Gateway moves the money for charges and refunds
+// This interface is owned by the use case layer (dependency inversion principle)
+type Gateway interface {
+	// Charge captures the charge amount; it returns domain.ErrDeclined when the
+	// card is declined and any other error when the gateway could not be reached
+	Charge(ctx context.Context, c *domain.Charge) error
+	Refund(ctx context.Context, r *domain.Refund) error
+}
@@ -0,0 +1,89 @@
+// Command client requests a quote from PricingService with a deadline and prints
+// the result together with the trace ID to look up in Jaeger.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pricingv1 "github.com/gostratum/examples/pricingservice/api/pricing/v1"
+)
+
+// traceIDHeader matches the header the server interceptor sets
+const traceIDHeader = "x-trace-id"
+
+func main() {
+	addr := flag.String("addr", "localhost:50051", "PricingService address")
+	sku := flag.String("sku", "WIDGET-1", "product to quote")
+	quantity := flag.Int("quantity", 1, "number of units")
+	currency := flag.String("currency", "EUR", "quote currency (empty for the catalog currency)")
+	timeout := flag.Duration("timeout", time.Second, "deadline for the whole call, including the RatesService hop")
+	health := flag.Bool("health", false, "check grpc.health.v1 instead of requesting a quote")
+	flag.Parse()
+
+	conn, err := grpc.NewClient(*addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ failed to create client: %v\n", err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	if *health {
+		os.Exit(checkHealth(ctx, conn))
+	}
+
+	var header metadata.MD
+	start := time.Now()
+	resp, err := pricingv1.NewPricingServiceClient(conn).GetQuote(ctx, &pricingv1.GetQuoteRequest{
+		Sku:      *sku,
+		Quantity: int32(*quantity),
+		Currency: *currency,
+	}, grpc.Header(&header))
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	if ids := header.Get(traceIDHeader); len(ids) > 0 {
+		fmt.Printf("🔎 trace_id: %s\n", ids[0])
+	}
+	if err != nil {
+		st := status.Convert(err)
+		fmt.Fprintf(os.Stderr, "❌ %s after %s: %s\n", st.Code(), elapsed, st.Message())
+		os.Exit(1)
+	}
+
+	q := resp.GetQuote()
+	fmt.Printf("✅ quote in %s\n", elapsed)
+	fmt.Printf("  %d x %s at %d %s (rate %.4f)\n", q.GetQuantity(), q.GetSku(), q.GetUnitPrice(), q.GetCurrency(), q.GetExchangeRate())
+	fmt.Printf("  subtotal %d, discount %d, total %d %s\n", q.GetSubtotal(), q.GetDiscount(), q.GetTotal(), q.GetCurrency())
+	fmt.Printf("  expires at %s\n", q.GetExpiresAt().AsTime().Format(time.RFC3339))
+}
+
+// checkHealth prints the server's overall health status and returns the exit code
+func checkHealth(ctx context.Context, conn *grpc.ClientConn) int {
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ health check failed: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("health: %s\n", resp.GetStatus())
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return 1
+	}
+	return 0
+}
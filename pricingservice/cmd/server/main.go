@@ -0,0 +1,49 @@
+package main
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	grpcAdapter "github.com/gostratum/examples/pricingservice/internal/adapter/grpc"
+	"github.com/gostratum/examples/pricingservice/internal/adapter/memory"
+	"github.com/gostratum/examples/pricingservice/internal/usecase"
+	"github.com/gostratum/examples/redact"
+	"github.com/gostratum/tracingx"
+)
+
+func main() {
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// Tracing installs the tracer provider the gRPC stats handlers report to;
+		// there is no httpx module, the service speaks gRPC only
+		tracingx.Module(),
+
+		// Provide dependencies
+		fx.Provide(
+			// In-memory catalog and exchange rates
+			memory.NewCatalog,
+			memory.NewRateTable,
+
+			// RatesService client; PricingService calls it over the network
+			grpcAdapter.NewRatesClient,
+
+			// Usecase services
+			usecase.NewPricingService,
+			usecase.NewRateService,
+
+			// gRPC server and service handlers
+			grpcAdapter.NewServer,
+			grpcAdapter.NewPricingServer,
+			grpcAdapter.NewRatesServer,
+		),
+
+		// Invoke setup functions
+		fx.Invoke(
+			grpcAdapter.RegisterServices,
+		),
+	)
+
+	app.Run()
+}
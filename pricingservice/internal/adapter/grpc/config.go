@@ -0,0 +1,40 @@
+// Package grpc exposes PricingService and RatesService over gRPC and calls
+// RatesService back through a traced, deadline-aware client
+package grpc
+
+import "time"
+
+// ServerConfig holds the gRPC listener settings
+type ServerConfig struct {
+	Addr string `mapstructure:"addr" default:":50051"`
+	// Reflection lets grpcurl and similar tools discover the services
+	Reflection bool `mapstructure:"reflection" default:"true"`
+
+	// DefaultDeadline is applied to calls that arrive without a deadline
+	DefaultDeadline time.Duration `mapstructure:"default_deadline" default:"5s"`
+	// MinDeadline rejects calls with less time left than this up front,
+	// rather than starting work that cannot finish
+	MinDeadline time.Duration `mapstructure:"min_deadline" default:"10ms"`
+
+	// HealthInterval is how often readiness checks are mirrored into grpc.health.v1
+	HealthInterval  time.Duration `mapstructure:"health_interval" default:"5s"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" default:"10s"`
+}
+
+// Prefix implements configx.Configurable
+func (ServerConfig) Prefix() string {
+	return "grpc"
+}
+
+// RatesClientConfig holds the RatesService client settings
+type RatesClientConfig struct {
+	// Address of RatesService; this example serves it from the same process
+	Address string `mapstructure:"address" default:"localhost:50051"`
+	// Timeout caps each call; the caller's deadline still wins when it is shorter
+	Timeout time.Duration `mapstructure:"timeout" default:"1s"`
+}
+
+// Prefix implements configx.Configurable
+func (RatesClientConfig) Prefix() string {
+	return "rates"
+}
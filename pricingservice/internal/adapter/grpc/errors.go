@@ -0,0 +1,49 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gostratum/examples/pricingservice/internal/usecase"
+)
+
+// toStatus maps usecase errors to gRPC status errors
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrInvalid):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "deadline exceeded")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "call canceled")
+	case errors.Is(err, usecase.ErrUnavailable):
+		return status.Error(codes.Unavailable, "service temporarily unavailable")
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
+
+// fromStatus maps a status error returned by RatesService back to the errors
+// the usecase layer understands, so a deadline blown downstream is reported as
+// a deadline and not as an outage
+func fromStatus(err error) error {
+	st, _ := status.FromError(err)
+	switch st.Code() {
+	case codes.NotFound:
+		return usecase.ErrNotFound
+	case codes.InvalidArgument:
+		return fmt.Errorf("%w: %s", usecase.ErrInvalid, st.Message())
+	case codes.DeadlineExceeded:
+		return fmt.Errorf("rates service: %w", context.DeadlineExceeded)
+	case codes.Canceled:
+		return fmt.Errorf("rates service: %w", context.Canceled)
+	default:
+		return fmt.Errorf("rates service: %s", st.Message())
+	}
+}
@@ -0,0 +1,98 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TraceIDHeader is the response header carrying the trace ID of the call, so
+// clients can look the trace up without exporting spans themselves
+const TraceIDHeader = "x-trace-id"
+
+// isHealthCheck reports whether method belongs to grpc.health.v1
+func isHealthCheck(method string) bool {
+	return strings.HasPrefix(method, "/grpc.health.v1.Health/")
+}
+
+// deadlineInterceptor bounds calls without a deadline and fails calls whose
+// remaining budget is too small to be useful. The (possibly new) deadline stays
+// on ctx, and outgoing gRPC calls made with ctx send it on as grpc-timeout.
+func deadlineInterceptor(cfg ServerConfig) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			if cfg.DefaultDeadline <= 0 {
+				return handler(ctx, req)
+			}
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, cfg.DefaultDeadline)
+			defer cancel()
+			deadline, _ = ctx.Deadline()
+		}
+
+		budget := time.Until(deadline)
+		trace.SpanFromContext(ctx).SetAttributes(
+			attribute.Int64("rpc.deadline.budget_ms", budget.Milliseconds()),
+			attribute.Bool("rpc.deadline.defaulted", !ok),
+		)
+
+		if budget < cfg.MinDeadline {
+			return nil, status.Errorf(codes.DeadlineExceeded,
+				"%s left of the deadline, at least %s is needed", budget.Round(time.Millisecond), cfg.MinDeadline)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// traceHeaderInterceptor returns the call's trace ID in the response headers
+func traceHeaderInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() && !isHealthCheck(info.FullMethod) {
+			_ = grpc.SetHeader(ctx, metadata.Pairs(TraceIDHeader, sc.TraceID().String()))
+		}
+		return handler(ctx, req)
+	}
+}
+
+// loggingInterceptor logs every call except health checks with its status code
+// and trace ID
+func loggingInterceptor(log logx.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if isHealthCheck(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		code := status.Code(err)
+
+		fields := []logx.Field{
+			logx.String("method", info.FullMethod),
+			logx.String("code", code.String()),
+			logx.String("duration", time.Since(start).String()),
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+			fields = append(fields, logx.String("trace_id", sc.TraceID().String()))
+		}
+
+		switch code {
+		case codes.OK:
+			log.Info("gRPC call", fields...)
+		case codes.Internal, codes.Unknown, codes.Unavailable, codes.DataLoss:
+			log.Error("gRPC call failed", append(fields, logx.Err(err))...)
+		default:
+			log.Warn("gRPC call failed", append(fields, logx.Err(err))...)
+		}
+		return resp, err
+	}
+}
@@ -0,0 +1,51 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pricingv1 "github.com/gostratum/examples/pricingservice/api/pricing/v1"
+	"github.com/gostratum/examples/pricingservice/internal/domain"
+	"github.com/gostratum/examples/pricingservice/internal/usecase"
+)
+
+// PricingServer implements pricingv1.PricingServiceServer
+type PricingServer struct {
+	pricingv1.UnimplementedPricingServiceServer
+	service *usecase.PricingService
+}
+
+// NewPricingServer creates the PricingService gRPC handler
+func NewPricingServer(service *usecase.PricingService) *PricingServer {
+	return &PricingServer{service: service}
+}
+
+// GetQuote implements pricingv1.PricingServiceServer
+func (s *PricingServer) GetQuote(ctx context.Context, req *pricingv1.GetQuoteRequest) (*pricingv1.GetQuoteResponse, error) {
+	quote, err := s.service.Quote(ctx, domain.QuoteRequest{
+		SKU:      req.GetSku(),
+		Quantity: int(req.GetQuantity()),
+		Currency: req.GetCurrency(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pricingv1.GetQuoteResponse{Quote: toQuoteMessage(quote)}, nil
+}
+
+// toQuoteMessage converts a domain quote to its protobuf message
+func toQuoteMessage(q *domain.Quote) *pricingv1.Quote {
+	return &pricingv1.Quote{
+		Sku:          q.SKU,
+		Quantity:     int32(q.Quantity),
+		Currency:     q.Currency,
+		UnitPrice:    q.UnitPrice,
+		Subtotal:     q.Subtotal,
+		Discount:     q.Discount,
+		Total:        q.Total,
+		ExchangeRate: q.ExchangeRate,
+		ExpiresAt:    timestamppb.New(q.ExpiresAt),
+	}
+}
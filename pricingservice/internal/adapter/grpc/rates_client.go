@@ -0,0 +1,101 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/core/configx"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+
+	pricingv1 "github.com/gostratum/examples/pricingservice/api/pricing/v1"
+	"github.com/gostratum/examples/pricingservice/internal/domain"
+	"github.com/gostratum/examples/pricingservice/internal/usecase"
+)
+
+// RatesClient implements usecase.RateProvider by calling RatesService
+type RatesClient struct {
+	cfg    RatesClientConfig
+	client pricingv1.RatesServiceClient
+}
+
+// NewRatesClient creates the RatesService client from the rates config section.
+// The connection is established lazily and closed when the application stops.
+func NewRatesClient(lc fx.Lifecycle, loader configx.Loader, reg core.Registry) (usecase.RateProvider, error) {
+	var cfg RatesClientConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load rates config: %w", err)
+	}
+
+	conn, err := grpc.NewClient(cfg.Address,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(clientStatsHandler()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rates client for %s: %w", cfg.Address, err)
+	}
+
+	c := newRatesClient(cfg, conn)
+	reg.Register(&ratesCheck{conn: conn})
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return conn.Close()
+		},
+	})
+	return c, nil
+}
+
+func newRatesClient(cfg RatesClientConfig, conn *grpc.ClientConn) *RatesClient {
+	return &RatesClient{cfg: cfg, client: pricingv1.NewRatesServiceClient(conn)}
+}
+
+// Rate implements usecase.RateProvider. ctx carries the caller's deadline and
+// span; gRPC sends the remaining time as grpc-timeout, so RatesService gives up
+// at the same moment the original client does.
+func (c *RatesClient) Rate(ctx context.Context, base, quote string) (*domain.Rate, error) {
+	if c.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.Timeout)
+		defer cancel()
+	}
+
+	resp, err := c.client.GetRate(ctx, &pricingv1.GetRateRequest{Base: base, Quote: quote})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return &domain.Rate{
+		Base:  resp.GetBase(),
+		Quote: resp.GetQuote(),
+		Rate:  resp.GetRate(),
+		AsOf:  resp.GetAsOf().AsTime(),
+	}, nil
+}
+
+// ratesCheck reports the service not ready while the RatesService connection is failing
+type ratesCheck struct {
+	conn *grpc.ClientConn
+}
+
+func (c *ratesCheck) Name() string {
+	return "rates"
+}
+
+func (c *ratesCheck) Kind() core.Kind {
+	return core.Readiness
+}
+
+// Check fails only in TRANSIENT_FAILURE; an idle connection is kicked so the
+// next check sees the real state
+func (c *ratesCheck) Check(ctx context.Context) error {
+	switch state := c.conn.GetState(); state {
+	case connectivity.Idle:
+		c.conn.Connect()
+	case connectivity.TransientFailure, connectivity.Shutdown:
+		return fmt.Errorf("rates service connection is %s", state)
+	}
+	return nil
+}
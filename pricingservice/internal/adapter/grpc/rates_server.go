@@ -0,0 +1,36 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pricingv1 "github.com/gostratum/examples/pricingservice/api/pricing/v1"
+	"github.com/gostratum/examples/pricingservice/internal/usecase"
+)
+
+// RatesServer implements pricingv1.RatesServiceServer
+type RatesServer struct {
+	pricingv1.UnimplementedRatesServiceServer
+	service *usecase.RateService
+}
+
+// NewRatesServer creates the RatesService gRPC handler
+func NewRatesServer(service *usecase.RateService) *RatesServer {
+	return &RatesServer{service: service}
+}
+
+// GetRate implements pricingv1.RatesServiceServer
+func (s *RatesServer) GetRate(ctx context.Context, req *pricingv1.GetRateRequest) (*pricingv1.GetRateResponse, error) {
+	rate, err := s.service.Rate(ctx, req.GetBase(), req.GetQuote())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pricingv1.GetRateResponse{
+		Base:  rate.Base,
+		Quote: rate.Quote,
+		Rate:  rate.Rate,
+		AsOf:  timestamppb.New(rate.AsOf),
+	}, nil
+}
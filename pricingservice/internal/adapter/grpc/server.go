@@ -0,0 +1,183 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	pricingv1 "github.com/gostratum/examples/pricingservice/api/pricing/v1"
+)
+
+// Server is the gRPC server together with its health service
+type Server struct {
+	cfg    ServerConfig
+	grpc   *grpc.Server
+	health *health.Server
+}
+
+// NewServer creates the gRPC server from the grpc config section with tracing,
+// deadline, trace header and logging interceptors installed
+func NewServer(loader configx.Loader, log logx.Logger) (*Server, error) {
+	var cfg ServerConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load grpc config: %w", err)
+	}
+	if cfg.HealthInterval <= 0 {
+		return nil, fmt.Errorf("grpc.health_interval must be positive, got %s", cfg.HealthInterval)
+	}
+	return newServer(cfg, log), nil
+}
+
+func newServer(cfg ServerConfig, log logx.Logger) *Server {
+	srv := grpc.NewServer(
+		grpc.StatsHandler(serverStatsHandler()),
+		grpc.ChainUnaryInterceptor(
+			deadlineInterceptor(cfg),
+			traceHeaderInterceptor(),
+			loggingInterceptor(log),
+		),
+	)
+
+	return &Server{cfg: cfg, grpc: srv, health: health.NewServer()}
+}
+
+// RegisterServices registers the API, health and reflection services and ties
+// the server to the application lifecycle.
+// This function is designed to be used with fx.Invoke.
+func RegisterServices(
+	lc fx.Lifecycle,
+	srv *Server,
+	pricing *PricingServer,
+	rates *RatesServer,
+	reg core.Registry,
+	log logx.Logger,
+) {
+	srv.register(pricing, rates)
+
+	var stopHealth context.CancelFunc
+	healthDone := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			lis, err := net.Listen("tcp", srv.cfg.Addr)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s: %w", srv.cfg.Addr, err)
+			}
+
+			go func() {
+				if err := srv.grpc.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+					log.Error("gRPC server stopped", logx.Err(err))
+				}
+			}()
+
+			var healthCtx context.Context
+			healthCtx, stopHealth = context.WithCancel(context.Background())
+			go func() {
+				defer close(healthDone)
+				srv.watchReadiness(healthCtx, reg, log)
+			}()
+
+			log.Info("gRPC server listening",
+				logx.String("addr", lis.Addr().String()),
+				logx.String("reflection", fmt.Sprint(srv.cfg.Reflection)))
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if stopHealth != nil {
+				stopHealth()
+				<-healthDone
+			}
+			// Tell load balancers to go elsewhere before draining calls in flight
+			srv.health.Shutdown()
+			return srv.stop(ctx)
+		},
+	})
+}
+
+// register adds the API, health and reflection services. Health reports
+// NOT_SERVING until the first readiness check passes.
+func (s *Server) register(pricing *PricingServer, rates *RatesServer) {
+	pricingv1.RegisterPricingServiceServer(s.grpc, pricing)
+	pricingv1.RegisterRatesServiceServer(s.grpc, rates)
+	healthpb.RegisterHealthServer(s.grpc, s.health)
+	if s.cfg.Reflection {
+		reflection.Register(s.grpc)
+	}
+
+	for _, name := range servingNames() {
+		s.health.SetServingStatus(name, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+}
+
+// servingNames lists the names health status is published under: "" for the
+// server as a whole plus each API service
+func servingNames() []string {
+	return []string{
+		"",
+		pricingv1.PricingService_ServiceDesc.ServiceName,
+		pricingv1.RatesService_ServiceDesc.ServiceName,
+	}
+}
+
+// watchReadiness mirrors the core.Registry readiness checks into the gRPC health
+// service until ctx is cancelled
+func (s *Server) watchReadiness(ctx context.Context, reg core.Registry, log logx.Logger) {
+	ticker := time.NewTicker(s.cfg.HealthInterval)
+	defer ticker.Stop()
+
+	serving := false
+	for {
+		checkCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		res := reg.Aggregate(checkCtx, core.Readiness)
+		cancel()
+
+		if res.OK != serving && ctx.Err() == nil {
+			serving = res.OK
+			status := healthpb.HealthCheckResponse_NOT_SERVING
+			if serving {
+				status = healthpb.HealthCheckResponse_SERVING
+			}
+			for _, name := range servingNames() {
+				s.health.SetServingStatus(name, status)
+			}
+			log.Info("gRPC health status changed", logx.String("status", status.String()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// stop drains calls in flight, forcing the server down if ctx ends first
+func (s *Server) stop(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.ShutdownTimeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.grpc.Stop()
+		return fmt.Errorf("gRPC server did not drain in time: %w", ctx.Err())
+	}
+}
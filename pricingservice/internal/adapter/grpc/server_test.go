@@ -0,0 +1,178 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pricingv1 "github.com/gostratum/examples/pricingservice/api/pricing/v1"
+	"github.com/gostratum/examples/pricingservice/internal/domain"
+	"github.com/gostratum/examples/pricingservice/internal/usecase"
+)
+
+// slowRateTable serves a fixed USD/EUR rate after latency, honouring ctx
+type slowRateTable struct {
+	latency time.Duration
+}
+
+func (t slowRateTable) Lookup(ctx context.Context, base, quote string) (*domain.Rate, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(t.latency):
+	}
+	if base != "USD" || quote != "EUR" {
+		return nil, domain.ErrNotFound
+	}
+	return &domain.Rate{Base: base, Quote: quote, Rate: 0.5}, nil
+}
+
+type staticCatalog struct{}
+
+func (staticCatalog) FindBySKU(ctx context.Context, sku string) (*domain.Product, error) {
+	if sku != "WIDGET-1" {
+		return nil, domain.ErrNotFound
+	}
+	return &domain.Product{SKU: sku, UnitPrice: 1000, Currency: "USD"}, nil
+}
+
+// startTestServer runs PricingService and RatesService on an in-memory listener.
+// PricingService reaches RatesService through a real gRPC client, like in production.
+func startTestServer(t *testing.T, cfg ServerConfig, rateLatency time.Duration) *grpc.ClientConn {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	dial := func(handler grpc.DialOption) *grpc.ClientConn {
+		conn, err := grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			handler,
+		)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		return conn
+	}
+
+	rates := newRatesClient(RatesClientConfig{Timeout: time.Second}, dial(grpc.WithStatsHandler(clientStatsHandler())))
+	srv := newServer(cfg, logx.NewNoopLogger())
+	srv.register(
+		NewPricingServer(usecase.NewPricingService(staticCatalog{}, rates)),
+		NewRatesServer(usecase.NewRateService(slowRateTable{latency: rateLatency})),
+	)
+
+	go srv.grpc.Serve(lis)
+	t.Cleanup(srv.grpc.Stop)
+
+	return dial(grpc.WithStatsHandler(clientStatsHandler()))
+}
+
+func testServerConfig() ServerConfig {
+	return ServerConfig{DefaultDeadline: 5 * time.Second, MinDeadline: 10 * time.Millisecond}
+}
+
+func TestGetQuote(t *testing.T) {
+	client := pricingv1.NewPricingServiceClient(startTestServer(t, testServerConfig(), 0))
+
+	tests := []struct {
+		name     string
+		req      *pricingv1.GetQuoteRequest
+		wantCode codes.Code
+		wantRate float64
+	}{
+		{name: "catalog currency", req: &pricingv1.GetQuoteRequest{Sku: "WIDGET-1", Quantity: 2}, wantCode: codes.OK, wantRate: 1},
+		{name: "converted through RatesService", req: &pricingv1.GetQuoteRequest{Sku: "WIDGET-1", Quantity: 2, Currency: "EUR"}, wantCode: codes.OK, wantRate: 0.5},
+		{name: "unknown sku", req: &pricingv1.GetQuoteRequest{Sku: "NOPE", Quantity: 1}, wantCode: codes.NotFound},
+		{name: "invalid quantity", req: &pricingv1.GetQuoteRequest{Sku: "WIDGET-1"}, wantCode: codes.InvalidArgument},
+		{name: "unsupported currency", req: &pricingv1.GetQuoteRequest{Sku: "WIDGET-1", Quantity: 1, Currency: "GBP"}, wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.GetQuote(context.Background(), tt.req)
+			require.Equal(t, tt.wantCode, status.Code(err), "error: %v", err)
+			if tt.wantCode == codes.OK {
+				assert.Equal(t, tt.wantRate, resp.GetQuote().GetExchangeRate())
+				assert.Equal(t, resp.GetQuote().GetSubtotal()-resp.GetQuote().GetDiscount(), resp.GetQuote().GetTotal())
+			}
+		})
+	}
+}
+
+func TestGetQuoteDeadlinePropagation(t *testing.T) {
+	client := pricingv1.NewPricingServiceClient(startTestServer(t, testServerConfig(), 500*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetQuote(ctx, &pricingv1.GetQuoteRequest{Sku: "WIDGET-1", Quantity: 1, Currency: "EUR"})
+
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err), "error: %v", err)
+	// RatesService gave up with the caller instead of running for its full latency
+	assert.Less(t, time.Since(start), 400*time.Millisecond)
+}
+
+func TestMinDeadlineRejectsCall(t *testing.T) {
+	cfg := testServerConfig()
+	cfg.MinDeadline = time.Hour
+	client := pricingv1.NewPricingServiceClient(startTestServer(t, cfg, 0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := client.GetQuote(ctx, &pricingv1.GetQuoteRequest{Sku: "WIDGET-1", Quantity: 1})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestTracePropagation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	client := pricingv1.NewPricingServiceClient(startTestServer(t, testServerConfig(), 0))
+
+	var header metadata.MD
+	_, err := client.GetQuote(context.Background(),
+		&pricingv1.GetQuoteRequest{Sku: "WIDGET-1", Quantity: 1, Currency: "EUR"}, grpc.Header(&header))
+	require.NoError(t, err)
+	require.NoError(t, provider.ForceFlush(context.Background()))
+
+	spans := recorder.Ended()
+	names := make(map[string]bool)
+	for _, span := range spans {
+		names[span.Name()] = true
+		assert.Equal(t, spans[0].SpanContext().TraceID(), span.SpanContext().TraceID(), "span %s is in another trace", span.Name())
+	}
+	assert.True(t, names[pricingv1.PricingService_GetQuote_FullMethodName[1:]], "spans: %v", names)
+	assert.True(t, names[pricingv1.RatesService_GetRate_FullMethodName[1:]], "spans: %v", names)
+	// Client and server spans for both hops
+	assert.Len(t, spans, 4)
+
+	require.Len(t, header.Get(TraceIDHeader), 1)
+	assert.Equal(t, spans[0].SpanContext().TraceID().String(), header.Get(TraceIDHeader)[0])
+}
+
+func TestHealthStartsNotServing(t *testing.T) {
+	conn := startTestServer(t, testServerConfig(), 0)
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.GetStatus())
+}
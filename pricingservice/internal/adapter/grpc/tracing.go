@@ -0,0 +1,31 @@
+package grpc
+
+import (
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc/stats"
+)
+
+// propagator carries the W3C trace context and baggage in gRPC metadata. It is set
+// explicitly so propagation does not depend on what was installed globally.
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// serverStatsHandler starts a server span per call, continuing the caller's trace.
+// Spans go to the global tracer provider, which tracingx.Module installs.
+func serverStatsHandler() stats.Handler {
+	return otelgrpc.NewServerHandler(
+		otelgrpc.WithPropagators(propagator),
+		otelgrpc.WithFilter(notHealthCheck),
+	)
+}
+
+// clientStatsHandler starts a client span per call and injects its context into
+// the outgoing metadata
+func clientStatsHandler() stats.Handler {
+	return otelgrpc.NewClientHandler(otelgrpc.WithPropagators(propagator))
+}
+
+// notHealthCheck keeps health probes out of the traces
+func notHealthCheck(info *stats.RPCTagInfo) bool {
+	return !isHealthCheck(info.FullMethodName)
+}
@@ -0,0 +1,42 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/gostratum/examples/pricingservice/internal/domain"
+	"github.com/gostratum/examples/pricingservice/internal/usecase"
+)
+
+// catalogCurrency is the currency all catalog prices are kept in
+const catalogCurrency = "USD"
+
+// Catalog is a fixed, read-only product list. The example is about the gRPC
+// plumbing, so there is no database behind it.
+type Catalog struct {
+	products map[string]domain.Product
+}
+
+// NewCatalog creates the catalog seeded with the demo products
+func NewCatalog() usecase.Catalog {
+	products := []domain.Product{
+		{SKU: "WIDGET-1", Name: "Widget", UnitPrice: 1999},
+		{SKU: "GADGET-1", Name: "Gadget", UnitPrice: 4950},
+		{SKU: "GIZMO-1", Name: "Gizmo", UnitPrice: 12500},
+	}
+
+	c := &Catalog{products: make(map[string]domain.Product, len(products))}
+	for _, p := range products {
+		p.Currency = catalogCurrency
+		c.products[p.SKU] = p
+	}
+	return c
+}
+
+// FindBySKU implements usecase.Catalog
+func (c *Catalog) FindBySKU(ctx context.Context, sku string) (*domain.Product, error) {
+	p, ok := c.products[sku]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &p, nil
+}
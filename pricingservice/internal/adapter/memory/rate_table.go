@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gostratum/core/configx"
+
+	"github.com/gostratum/examples/pricingservice/internal/domain"
+	"github.com/gostratum/examples/pricingservice/internal/usecase"
+)
+
+// usdRates are the demo rates, in units of each currency per US dollar
+var usdRates = map[string]float64{
+	"USD": 1,
+	"EUR": 0.92,
+	"GBP": 0.79,
+	"JPY": 149.5,
+	"CHF": 0.88,
+}
+
+// RateTableConfig tunes the in-memory rate table
+type RateTableConfig struct {
+	// Latency delays every lookup as if the rates came from a remote feed.
+	// Raise it above the client deadline to watch DEADLINE_EXCEEDED travel back.
+	Latency time.Duration `mapstructure:"latency" default:"50ms"`
+}
+
+// Prefix implements configx.Configurable
+func (RateTableConfig) Prefix() string {
+	return "rate_table"
+}
+
+// RateTable serves cross rates derived from a fixed USD table
+type RateTable struct {
+	latency time.Duration
+	asOf    time.Time
+}
+
+// NewRateTable creates the rate table from the rate_table config section
+func NewRateTable(loader configx.Loader) (usecase.RateTable, error) {
+	var cfg RateTableConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load rate_table config: %w", err)
+	}
+	return &RateTable{latency: cfg.Latency, asOf: time.Now().UTC().Truncate(time.Second)}, nil
+}
+
+// Lookup implements usecase.RateTable
+func (t *RateTable) Lookup(ctx context.Context, base, quote string) (*domain.Rate, error) {
+	if t.latency > 0 {
+		timer := time.NewTimer(t.latency)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	from, ok := usdRates[base]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	to, ok := usdRates[quote]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	return &domain.Rate{Base: base, Quote: quote, Rate: to / from, AsOf: t.asOf}, nil
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQuoteRequestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     QuoteRequest
+		wantErr bool
+	}{
+		{name: "valid request", req: QuoteRequest{SKU: "sku-1", Quantity: 1, Currency: "EUR"}, wantErr: false},
+		{name: "catalog currency", req: QuoteRequest{SKU: "sku-1", Quantity: 1}, wantErr: false},
+		{name: "empty sku", req: QuoteRequest{Quantity: 1}, wantErr: true},
+		{name: "zero quantity", req: QuoteRequest{SKU: "sku-1"}, wantErr: true},
+		{name: "quantity over limit", req: QuoteRequest{SKU: "sku-1", Quantity: MaxQuantity + 1}, wantErr: true},
+		{name: "invalid currency", req: QuoteRequest{SKU: "sku-1", Quantity: 1, Currency: "euros"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Normalize().Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QuoteRequest.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("QuoteRequest.Validate() error = %v, want ErrInvalidInput", err)
+			}
+		})
+	}
+}
+
+func TestNewQuote(t *testing.T) {
+	product := &Product{SKU: "sku-1", UnitPrice: 1999, Currency: "USD"}
+	expires := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		quantity     int
+		rate         float64
+		wantUnit     int64
+		wantDiscount int64
+		wantTotal    int64
+	}{
+		{name: "no discount", quantity: 2, rate: 1, wantUnit: 1999, wantDiscount: 0, wantTotal: 3998},
+		{name: "5% tier", quantity: 10, rate: 1, wantUnit: 1999, wantDiscount: 999, wantTotal: 18991},
+		{name: "10% tier", quantity: 100, rate: 1, wantUnit: 1999, wantDiscount: 19990, wantTotal: 179910},
+		{name: "converted and rounded", quantity: 1, rate: 0.9234, wantUnit: 1846, wantDiscount: 0, wantTotal: 1846},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewQuote(product, tt.quantity, "USD", tt.rate, expires)
+			if q.UnitPrice != tt.wantUnit || q.Discount != tt.wantDiscount || q.Total != tt.wantTotal {
+				t.Errorf("NewQuote() = unit %d discount %d total %d, want %d %d %d",
+					q.UnitPrice, q.Discount, q.Total, tt.wantUnit, tt.wantDiscount, tt.wantTotal)
+			}
+			if q.Subtotal != q.Total+q.Discount {
+				t.Errorf("NewQuote() subtotal %d != total %d + discount %d", q.Subtotal, q.Total, q.Discount)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package domain
+
+// Product is a catalog entry priced in the catalog currency
+type Product struct {
+	SKU  string
+	Name string
+	// UnitPrice is in minor units of Currency
+	UnitPrice int64
+	Currency  string
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// MaxQuantity bounds a single quote; larger orders go through sales
+const MaxQuantity = 10000
+
+// discountTiers lists volume discounts in basis points, largest quantity first
+var discountTiers = []struct {
+	MinQuantity int
+	BasisPoints int64
+}{
+	{MinQuantity: 100, BasisPoints: 1000},
+	{MinQuantity: 10, BasisPoints: 500},
+}
+
+// Quote is a price offer for a quantity of one product. Amounts are in
+// minor units of Currency.
+type Quote struct {
+	SKU          string
+	Quantity     int
+	Currency     string
+	UnitPrice    int64
+	Subtotal     int64
+	Discount     int64
+	Total        int64
+	ExchangeRate float64
+	ExpiresAt    time.Time
+}
+
+// QuoteRequest describes what the caller wants priced
+type QuoteRequest struct {
+	SKU      string
+	Quantity int
+	Currency string
+}
+
+// Normalize trims the SKU and upper-cases the currency code
+func (r QuoteRequest) Normalize() QuoteRequest {
+	r.SKU = strings.TrimSpace(r.SKU)
+	r.Currency = strings.ToUpper(strings.TrimSpace(r.Currency))
+	return r
+}
+
+// Validate checks the request; an empty currency means the catalog currency
+func (r QuoteRequest) Validate() error {
+	if r.SKU == "" {
+		return fmt.Errorf("%w: sku is required", ErrInvalidInput)
+	}
+	if r.Quantity < 1 || r.Quantity > MaxQuantity {
+		return fmt.Errorf("%w: quantity must be between 1 and %d", ErrInvalidInput, MaxQuantity)
+	}
+	if r.Currency != "" && !ValidCurrency(r.Currency) {
+		return fmt.Errorf("%w: currency must be a three-letter ISO 4217 code", ErrInvalidInput)
+	}
+	return nil
+}
+
+// ValidCurrency reports whether code looks like an ISO 4217 currency code
+func ValidCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// NewQuote prices quantity units of p converted at rate into currency.
+// The converted unit price is rounded to the nearest minor unit before the
+// volume discount is applied, so totals always add up.
+func NewQuote(p *Product, quantity int, currency string, rate float64, expiresAt time.Time) *Quote {
+	unitPrice := int64(math.Round(float64(p.UnitPrice) * rate))
+	subtotal := unitPrice * int64(quantity)
+	discount := subtotal * discountBasisPoints(quantity) / 10000
+
+	return &Quote{
+		SKU:          p.SKU,
+		Quantity:     quantity,
+		Currency:     currency,
+		UnitPrice:    unitPrice,
+		Subtotal:     subtotal,
+		Discount:     discount,
+		Total:        subtotal - discount,
+		ExchangeRate: rate,
+		ExpiresAt:    expiresAt,
+	}
+}
+
+// discountBasisPoints returns the volume discount for quantity
+func discountBasisPoints(quantity int) int64 {
+	for _, tier := range discountTiers {
+		if quantity >= tier.MinQuantity {
+			return tier.BasisPoints
+		}
+	}
+	return 0
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// Rate is the price of one unit of Base in units of Quote
+type Rate struct {
+	Base  string
+	Quote string
+	Rate  float64
+	AsOf  time.Time
+}
@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/gostratum/examples/pricingservice/internal/domain"
+)
+
+// Catalog looks up products
+// This interface is owned by the use case layer (dependency inversion principle)
+type Catalog interface {
+	FindBySKU(ctx context.Context, sku string) (*domain.Product, error)
+}
+
+// RateProvider is how PricingService gets exchange rates. In this example it is
+// a gRPC client of RatesService, so it must honour ctx's deadline and trace.
+type RateProvider interface {
+	// Rate returns domain.ErrNotFound when the currency pair is not supported
+	Rate(ctx context.Context, base, quote string) (*domain.Rate, error)
+}
+
+// RateTable is the source of truth RatesService serves rates from
+// This interface is owned by the use case layer (dependency inversion principle)
+type RateTable interface {
+	Lookup(ctx context.Context, base, quote string) (*domain.Rate, error)
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gostratum/examples/pricingservice/internal/domain"
+)
+
+// operationTimeout caps a call when the client sent no deadline or a longer one.
+// A shorter client deadline always wins and is passed on to RatesService.
+const operationTimeout = 3 * time.Second
+
+// quoteTTL is how long a quote stays valid
+const quoteTTL = 15 * time.Minute
+
+// PricingService prices catalog products
+type PricingService struct {
+	catalog Catalog
+	rates   RateProvider
+	now     func() time.Time
+}
+
+// NewPricingService creates a new pricing service with catalog and rate provider injection
+func NewPricingService(catalog Catalog, rates RateProvider) *PricingService {
+	return &PricingService{
+		catalog: catalog,
+		rates:   rates,
+		now:     time.Now,
+	}
+}
+
+// Quote prices quantity units of sku in currency, converting from the catalog
+// currency through the rate provider when they differ
+func (s *PricingService) Quote(ctx context.Context, req domain.QuoteRequest) (*domain.Quote, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, operationTimeout)
+	defer cancel()
+
+	req = req.Normalize()
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	product, err := s.catalog.FindBySKU(ctx, req.SKU)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	currency, rate := product.Currency, 1.0
+	if req.Currency != "" && req.Currency != product.Currency {
+		r, err := s.rates.Rate(ctx, product.Currency, req.Currency)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return nil, fmt.Errorf("%w: currency %s is not supported", ErrInvalid, req.Currency)
+			}
+			return nil, translateError(err)
+		}
+		currency, rate = req.Currency, r.Rate
+	}
+
+	return domain.NewQuote(product, req.Quantity, currency, rate, s.now().Add(quoteTTL)), nil
+}
+
+// translateError converts repository/client errors to usecase errors. Domain errors
+// pass through unchanged, and so do context errors so the transport can report a
+// blown deadline as such instead of as an outage.
+func translateError(err error) error {
+	for _, passthrough := range []error{domain.ErrNotFound, domain.ErrInvalidInput, context.DeadlineExceeded, context.Canceled} {
+		if errors.Is(err, passthrough) {
+			return err
+		}
+	}
+
+	// All other errors are infrastructure/availability issues
+	return fmt.Errorf("%w: %v", ErrUnavailable, err)
+}
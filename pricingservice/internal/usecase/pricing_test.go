@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gostratum/examples/pricingservice/internal/domain"
+)
+
+// MockCatalog implements Catalog for testing
+type MockCatalog struct {
+	products map[string]*domain.Product
+}
+
+func (m *MockCatalog) FindBySKU(ctx context.Context, sku string) (*domain.Product, error) {
+	product, exists := m.products[sku]
+	if !exists {
+		return nil, domain.ErrNotFound
+	}
+	return product, nil
+}
+
+// MockRateProvider implements RateProvider for testing
+type MockRateProvider struct {
+	rates map[string]float64
+	err   error
+	calls int
+	// deadline records the deadline of the last call
+	deadline time.Time
+}
+
+func (m *MockRateProvider) Rate(ctx context.Context, base, quote string) (*domain.Rate, error) {
+	m.calls++
+	m.deadline, _ = ctx.Deadline()
+	if m.err != nil {
+		return nil, m.err
+	}
+	rate, exists := m.rates[base+"/"+quote]
+	if !exists {
+		return nil, domain.ErrNotFound
+	}
+	return &domain.Rate{Base: base, Quote: quote, Rate: rate}, nil
+}
+
+func newTestPricingService() (*PricingService, *MockRateProvider) {
+	catalog := &MockCatalog{products: map[string]*domain.Product{
+		"sku-1": {SKU: "sku-1", Name: "Widget", UnitPrice: 1000, Currency: "USD"},
+	}}
+	rates := &MockRateProvider{rates: map[string]float64{"USD/EUR": 0.5}}
+	return NewPricingService(catalog, rates), rates
+}
+
+func TestPricingService_Quote(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       domain.QuoteRequest
+		rateErr   error
+		wantErr   error
+		wantTotal int64
+		wantCalls int
+	}{
+		{name: "catalog currency", req: domain.QuoteRequest{SKU: "sku-1", Quantity: 2}, wantTotal: 2000},
+		{name: "same currency skips rates", req: domain.QuoteRequest{SKU: "sku-1", Quantity: 2, Currency: "usd"}, wantTotal: 2000},
+		{name: "converted", req: domain.QuoteRequest{SKU: "sku-1", Quantity: 2, Currency: "EUR"}, wantTotal: 1000, wantCalls: 1},
+		{name: "unknown sku", req: domain.QuoteRequest{SKU: "nope", Quantity: 1}, wantErr: ErrNotFound},
+		{name: "invalid quantity", req: domain.QuoteRequest{SKU: "sku-1"}, wantErr: ErrInvalid},
+		{name: "unsupported currency", req: domain.QuoteRequest{SKU: "sku-1", Quantity: 1, Currency: "XYZ"}, wantErr: ErrInvalid, wantCalls: 1},
+		{name: "rates unavailable", req: domain.QuoteRequest{SKU: "sku-1", Quantity: 1, Currency: "EUR"}, rateErr: errors.New("connection refused"), wantErr: ErrUnavailable, wantCalls: 1},
+		{name: "deadline passes through", req: domain.QuoteRequest{SKU: "sku-1", Quantity: 1, Currency: "EUR"}, rateErr: context.DeadlineExceeded, wantErr: context.DeadlineExceeded, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, rates := newTestPricingService()
+			rates.err = tt.rateErr
+
+			quote, err := service.Quote(context.Background(), tt.req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Quote() error = %v, want %v", err, tt.wantErr)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Quote() unexpected error = %v", err)
+				}
+				if quote.Total != tt.wantTotal {
+					t.Errorf("Quote() total = %d, want %d", quote.Total, tt.wantTotal)
+				}
+			}
+			if rates.calls != tt.wantCalls {
+				t.Errorf("rate provider calls = %d, want %d", rates.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestPricingService_QuoteKeepsShorterDeadline(t *testing.T) {
+	service, rates := newTestPricingService()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	want, _ := ctx.Deadline()
+
+	if _, err := service.Quote(ctx, domain.QuoteRequest{SKU: "sku-1", Quantity: 1, Currency: "EUR"}); err != nil {
+		t.Fatalf("Quote() unexpected error = %v", err)
+	}
+	if !rates.deadline.Equal(want) {
+		t.Errorf("rate provider deadline = %v, want caller deadline %v", rates.deadline, want)
+	}
+}
+
+func TestRateService_Rate(t *testing.T) {
+	service := NewRateService(rateTableFunc(func(ctx context.Context, base, quote string) (*domain.Rate, error) {
+		if base == "USD" && quote == "EUR" {
+			return &domain.Rate{Base: base, Quote: quote, Rate: 0.5}, nil
+		}
+		return nil, domain.ErrNotFound
+	}))
+
+	rate, err := service.Rate(context.Background(), "usd", "eur")
+	if err != nil || rate.Rate != 0.5 {
+		t.Fatalf("Rate(usd, eur) = %v, %v", rate, err)
+	}
+	if _, err := service.Rate(context.Background(), "USD", "GBP"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Rate(USD, GBP) error = %v, want ErrNotFound", err)
+	}
+	if _, err := service.Rate(context.Background(), "dollar", "EUR"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Rate(dollar, EUR) error = %v, want ErrInvalid", err)
+	}
+}
+
+// rateTableFunc adapts a function to RateTable
+type rateTableFunc func(ctx context.Context, base, quote string) (*domain.Rate, error)
+
+func (f rateTableFunc) Lookup(ctx context.Context, base, quote string) (*domain.Rate, error) {
+	return f(ctx, base, quote)
+}
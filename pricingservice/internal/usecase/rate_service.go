@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gostratum/examples/pricingservice/internal/domain"
+)
+
+// RateService serves exchange rates from the rate table
+type RateService struct {
+	table RateTable
+}
+
+// NewRateService creates a new rate service with rate table injection
+func NewRateService(table RateTable) *RateService {
+	return &RateService{table: table}
+}
+
+// Rate returns the exchange rate from base to quote. It runs under the caller's
+// deadline only; the caller is PricingService, which already applied one.
+func (s *RateService) Rate(ctx context.Context, base, quote string) (*domain.Rate, error) {
+	base, quote = strings.ToUpper(base), strings.ToUpper(quote)
+	if !domain.ValidCurrency(base) || !domain.ValidCurrency(quote) {
+		return nil, fmt.Errorf("%w: currencies must be three-letter ISO 4217 codes", ErrInvalid)
+	}
+
+	rate, err := s.table.Lookup(ctx, base, quote)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return rate, nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/dbx"
+	httpAdapter "github.com/gostratum/examples/quota-demo/internal/adapter/http"
+	repoAdapter "github.com/gostratum/examples/quota-demo/internal/adapter/repo"
+	"github.com/gostratum/examples/quota-demo/internal/reset"
+	"github.com/gostratum/examples/quota-demo/internal/usecase"
+	"github.com/gostratum/examples/redact"
+	"github.com/gostratum/httpx"
+)
+
+func main() {
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// Include dbx module; it migrates the database on startup
+		dbx.Module(),
+
+		// Include httpx module
+		httpx.Module(),
+
+		// Resets the daily quotas at UTC midnight
+		reset.Module(),
+
+		// Provide dependencies
+		fx.Provide(
+			// GORM repositories
+			repoAdapter.NewKeyRepo,
+
+			// Usecase services
+			usecase.NewKeyService,
+			usecase.NewQuotaService,
+
+			// HTTP handlers
+			httpAdapter.NewAdminAuth,
+			httpAdapter.NewQuotaHandler,
+			httpAdapter.NewQuoteHandler,
+			httpAdapter.NewAdminHandler,
+		),
+
+		// Invoke setup functions
+		fx.Invoke(
+			httpAdapter.RegisterRoutes,
+		),
+	)
+
+	app.Run()
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/httpx/responsex"
+)
+
+// AdminTokenHeader carries the admin token
+const AdminTokenHeader = "X-Admin-Token"
+
+// AdminAuth checks the admin token of every admin request
+type AdminAuth struct {
+	// hash is the SHA-256 hash of the token. Comparing hashes takes the same
+	// time whatever the length of the token sent.
+	hash [sha256.Size]byte
+}
+
+// NewAdminAuth creates the admin token check from the admin config section. It
+// fails without a token, since every admin request would be rejected.
+func NewAdminAuth(loader configx.Loader) (*AdminAuth, error) {
+	var cfg AdminConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load admin config: %w", err)
+	}
+	return newAdminAuth(cfg.Token)
+}
+
+func newAdminAuth(token string) (*AdminAuth, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, errors.New("admin.token is empty")
+	}
+	return &AdminAuth{hash: sha256.Sum256([]byte(token))}, nil
+}
+
+// Middleware rejects requests without the admin token
+func (a *AdminAuth) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sum := sha256.Sum256([]byte(c.GetHeader(AdminTokenHeader)))
+		if subtle.ConstantTimeCompare(sum[:], a.hash[:]) != 1 {
+			responsex.Error(c, http.StatusUnauthorized, "INVALID_ADMIN_TOKEN", "missing or invalid "+AdminTokenHeader+" header", nil)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,170 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/quota-demo/internal/domain"
+	"github.com/gostratum/examples/quota-demo/internal/usecase"
+)
+
+// Keys manages API keys; implemented by usecase.KeyService
+type Keys interface {
+	CreateKey(ctx context.Context, name, tier string) (*domain.APIKey, string, error)
+	ListKeys(ctx context.Context) ([]*domain.APIKey, error)
+	GetKey(ctx context.Context, id string) (*domain.APIKey, []domain.DailyUsage, error)
+	ChangeTier(ctx context.Context, id, tier string) (*domain.APIKey, error)
+	RevokeKey(ctx context.Context, id string) (*domain.APIKey, error)
+	Tiers(ctx context.Context) ([]domain.Tier, error)
+}
+
+// Resets runs the quota reset; implemented by usecase.QuotaService
+type Resets interface {
+	Reset(ctx context.Context) (int, error)
+}
+
+// AdminHandler handles the admin API: keys, tiers and the quota reset
+type AdminHandler struct {
+	keys   Keys
+	resets Resets
+	log    logx.Logger
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(keys *usecase.KeyService, quotas *usecase.QuotaService, log logx.Logger) *AdminHandler {
+	return newAdminHandler(keys, quotas, log)
+}
+
+func newAdminHandler(keys Keys, resets Resets, log logx.Logger) *AdminHandler {
+	return &AdminHandler{keys: keys, resets: resets, log: log}
+}
+
+// ListTiers handles GET /admin/tiers
+func (h *AdminHandler) ListTiers(c *gin.Context) {
+	tiers, err := h.keys.Tiers(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	resp := make([]TierResponse, len(tiers))
+	for i, t := range tiers {
+		resp[i] = TierResponse{Name: t.Name, DailyQuota: t.DailyQuota}
+	}
+	responsex.OK(c, resp, nil)
+}
+
+// CreateKey handles POST /admin/keys. The response holds the key itself;
+// it cannot be read again.
+func (h *AdminHandler) CreateKey(c *gin.Context) {
+	var req CreateKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid request payload", nil)
+		return
+	}
+
+	key, secret, err := h.keys.CreateKey(c.Request.Context(), req.Name, req.Tier)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.Created(c, "/admin/keys/"+key.ID, CreatedKeyResponse{
+		KeyResponse: FromDomainKey(key, time.Now()),
+		Key:         secret,
+	})
+}
+
+// ListKeys handles GET /admin/keys
+func (h *AdminHandler) ListKeys(c *gin.Context) {
+	keys, err := h.keys.ListKeys(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	now := time.Now()
+	resp := make([]KeyResponse, len(keys))
+	for i, key := range keys {
+		resp[i] = FromDomainKey(key, now)
+	}
+	responsex.OK(c, resp, nil)
+}
+
+// GetKey handles GET /admin/keys/:id
+func (h *AdminHandler) GetKey(c *gin.Context) {
+	key, usage, err := h.keys.GetKey(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	resp := KeyDetailResponse{KeyResponse: FromDomainKey(key, time.Now()), History: make([]UsageResponse, len(usage))}
+	for i, u := range usage {
+		resp.History[i] = UsageResponse{Day: u.Day.Format(time.DateOnly), Requests: u.Requests}
+	}
+	responsex.OK(c, resp, nil)
+}
+
+// ChangeTier handles PUT /admin/keys/:id/tier
+func (h *AdminHandler) ChangeTier(c *gin.Context) {
+	var req ChangeTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid request payload", nil)
+		return
+	}
+
+	key, err := h.keys.ChangeTier(c.Request.Context(), c.Param("id"), req.Tier)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.OK(c, FromDomainKey(key, time.Now()), nil)
+}
+
+// RevokeKey handles POST /admin/keys/:id/revoke
+func (h *AdminHandler) RevokeKey(c *gin.Context) {
+	key, err := h.keys.RevokeKey(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.OK(c, FromDomainKey(key, time.Now()), nil)
+}
+
+// Reset handles POST /admin/reset. It runs the nightly reset now; it only
+// resets keys whose day has ended, so it is safe to call at any time.
+func (h *AdminHandler) Reset(c *gin.Context) {
+	n, err := h.resets.Reset(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.OK(c, ResetResponse{Keys: n}, nil)
+}
+
+// handleError maps usecase errors to HTTP responses
+func (h *AdminHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrNotFound):
+		responsex.Error(c, http.StatusNotFound, "NOT_FOUND", "API key not found", nil)
+	case errors.Is(err, usecase.ErrInvalid):
+		responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
+	case errors.Is(err, usecase.ErrUnavailable):
+		h.log.Error("database unavailable", logx.Err(err))
+		c.Header("Retry-After", "2")
+		responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "service temporarily unavailable", nil)
+	default:
+		h.log.Error("unexpected error", logx.Err(err))
+		responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+	}
+}
@@ -0,0 +1,172 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gostratum/httpx/responsex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/quota-demo/internal/domain"
+	"github.com/gostratum/examples/quota-demo/internal/usecase"
+)
+
+// fakeKeys knows one key, key-1 on the free tier, or fails every call with err
+type fakeKeys struct {
+	err    error
+	resets int
+}
+
+func (f *fakeKeys) key() *domain.APIKey {
+	return &domain.APIKey{ID: "key-1", Name: "Acme", Prefix: "qk_abcdef", Tier: "free", DailyQuota: 20, Used: 3, Period: domain.Day(time.Now())}
+}
+
+func (f *fakeKeys) CreateKey(ctx context.Context, name, tier string) (*domain.APIKey, string, error) {
+	if f.err != nil {
+		return nil, "", f.err
+	}
+	if tier != "free" {
+		return nil, "", fmt.Errorf("%w: tier %q does not exist", usecase.ErrInvalid, tier)
+	}
+	key := f.key()
+	key.Used = 0
+	return key, "qk_abcdefsecret", nil
+}
+
+func (f *fakeKeys) ListKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	return []*domain.APIKey{f.key()}, f.err
+}
+
+func (f *fakeKeys) GetKey(ctx context.Context, id string) (*domain.APIKey, []domain.DailyUsage, error) {
+	if f.err != nil {
+		return nil, nil, f.err
+	}
+	if id != "key-1" {
+		return nil, nil, usecase.ErrNotFound
+	}
+	return f.key(), []domain.DailyUsage{{Day: time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC), Requests: 12}}, nil
+}
+
+func (f *fakeKeys) ChangeTier(ctx context.Context, id, tier string) (*domain.APIKey, error) {
+	key := f.key()
+	key.Tier = tier
+	return key, f.err
+}
+
+func (f *fakeKeys) RevokeKey(ctx context.Context, id string) (*domain.APIKey, error) {
+	key := f.key()
+	now := time.Now()
+	key.RevokedAt = &now
+	return key, f.err
+}
+
+func (f *fakeKeys) Tiers(ctx context.Context) ([]domain.Tier, error) {
+	return []domain.Tier{{Name: "free", DailyQuota: 20}}, f.err
+}
+
+func (f *fakeKeys) Reset(ctx context.Context) (int, error) {
+	f.resets++
+	return 4, f.err
+}
+
+func withAdmin() http.Header {
+	return http.Header{AdminTokenHeader: {testAdminToken}}
+}
+
+func TestAdminAuth(t *testing.T) {
+	e := setupRouter(t, &fakeQuotas{}, &fakeKeys{})
+
+	for _, token := range []string{"", "admin", "admin-token-2"} {
+		w := do(e, http.MethodGet, "/admin/keys", http.Header{AdminTokenHeader: {token}}, "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "token %q", token)
+		assert.Contains(t, w.Body.String(), "INVALID_ADMIN_TOKEN")
+	}
+
+	// An API key does not open the admin API
+	w := do(e, http.MethodGet, "/admin/keys", withKey(testSecret), "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = do(e, http.MethodGet, "/admin/keys", withAdmin(), "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	_, err := newAdminAuth(" ")
+	assert.ErrorContains(t, err, "admin.token is empty")
+}
+
+func TestCreateKey(t *testing.T) {
+	e := setupRouter(t, &fakeQuotas{}, &fakeKeys{})
+
+	w := do(e, http.MethodPost, "/admin/keys", withAdmin(), `{"name":"Acme","tier":"free"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "/admin/keys/key-1", w.Header().Get("Location"))
+
+	var resp responsex.Envelope[CreatedKeyResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "qk_abcdefsecret", resp.Data.Key)
+	assert.Equal(t, 20, resp.Data.RemainingToday)
+
+	w = do(e, http.MethodPost, "/admin/keys", withAdmin(), `{"name":"Acme","tier":"platinum"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `tier \"platinum\" does not exist`)
+
+	w = do(e, http.MethodPost, "/admin/keys", withAdmin(), `{"name":"Acme"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_REQUEST")
+}
+
+func TestGetKey(t *testing.T) {
+	e := setupRouter(t, &fakeQuotas{}, &fakeKeys{})
+
+	w := do(e, http.MethodGet, "/admin/keys/key-1", withAdmin(), "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "secret")
+
+	var resp responsex.Envelope[KeyDetailResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 3, resp.Data.UsedToday)
+	assert.Equal(t, 17, resp.Data.RemainingToday)
+	assert.Equal(t, []UsageResponse{{Day: "2025-03-09", Requests: 12}}, resp.Data.History)
+
+	w = do(e, http.MethodGet, "/admin/keys/key-2", withAdmin(), "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAdminActions(t *testing.T) {
+	keys := &fakeKeys{}
+	e := setupRouter(t, &fakeQuotas{}, keys)
+
+	w := do(e, http.MethodPut, "/admin/keys/key-1/tier", withAdmin(), `{"tier":"pro"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"tier":"pro"`)
+
+	w = do(e, http.MethodPost, "/admin/keys/key-1/revoke", withAdmin(), "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "revoked_at")
+
+	w = do(e, http.MethodPost, "/admin/reset", withAdmin(), "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"keys":4`)
+	assert.Equal(t, 1, keys.resets)
+
+	w = do(e, http.MethodGet, "/admin/tiers", withAdmin(), "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"daily_quota":20`)
+}
+
+func TestAdminErrors(t *testing.T) {
+	keys := &fakeKeys{err: fmt.Errorf("%w: connection refused", usecase.ErrUnavailable)}
+	e := setupRouter(t, &fakeQuotas{}, keys)
+
+	w := do(e, http.MethodGet, "/admin/keys", withAdmin(), "")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+
+	keys.err = fmt.Errorf("boom")
+	w = do(e, http.MethodPost, "/admin/reset", withAdmin(), "")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,13 @@
+package http
+
+// AdminConfig holds the token of the admin API
+type AdminConfig struct {
+	// Token authenticates admin requests. Set it through the environment in
+	// anything but development.
+	Token string `mapstructure:"token"`
+}
+
+// Prefix implements configx.Configurable
+func (AdminConfig) Prefix() string {
+	return "admin"
+}
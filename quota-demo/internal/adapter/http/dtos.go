@@ -0,0 +1,107 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gostratum/examples/quota-demo/internal/domain"
+)
+
+// CreateKeyRequest represents the request payload for creating an API key
+type CreateKeyRequest struct {
+	Name string `json:"name" binding:"required"`
+	Tier string `json:"tier" binding:"required"`
+}
+
+// ChangeTierRequest represents the request payload for moving a key to a tier
+type ChangeTierRequest struct {
+	Tier string `json:"tier" binding:"required"`
+}
+
+// KeyResponse is the HTTP DTO for API key data; it never holds the key itself
+type KeyResponse struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name"`
+	Prefix         string     `json:"prefix"`
+	Tier           string     `json:"tier"`
+	DailyQuota     int        `json:"daily_quota"`
+	UsedToday      int        `json:"used_today"`
+	RemainingToday int        `json:"remaining_today"`
+	CreatedAt      time.Time  `json:"created_at"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+}
+
+// FromDomainKey converts a domain.APIKey to KeyResponse DTO, with its quota
+// as of now
+func FromDomainKey(key *domain.APIKey, now time.Time) KeyResponse {
+	q := key.Quota(now)
+	return KeyResponse{
+		ID:             key.ID,
+		Name:           key.Name,
+		Prefix:         key.Prefix,
+		Tier:           key.Tier,
+		DailyQuota:     q.Limit,
+		UsedToday:      q.Used,
+		RemainingToday: q.Remaining(),
+		CreatedAt:      key.CreatedAt,
+		RevokedAt:      key.RevokedAt,
+	}
+}
+
+// CreatedKeyResponse is a new key, with the key itself
+type CreatedKeyResponse struct {
+	KeyResponse
+	// Key is shown only in this response
+	Key string `json:"key"`
+}
+
+// KeyDetailResponse is a key with its usage over the past days
+type KeyDetailResponse struct {
+	KeyResponse
+	History []UsageResponse `json:"history"`
+}
+
+// UsageResponse is a key's requests on a past day
+type UsageResponse struct {
+	Day      string `json:"day"`
+	Requests int    `json:"requests"`
+}
+
+// TierResponse is the HTTP DTO for tier data
+type TierResponse struct {
+	Name       string `json:"name"`
+	DailyQuota int    `json:"daily_quota"`
+}
+
+// QuotaResponse is the caller's own key and quota
+type QuotaResponse struct {
+	Name      string    `json:"name"`
+	Tier      string    `json:"tier"`
+	Limit     int       `json:"limit"`
+	Used      int       `json:"used"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+// FromDomainQuota converts a key and its quota to QuotaResponse DTO
+func FromDomainQuota(key *domain.APIKey, q domain.Quota) QuotaResponse {
+	return QuotaResponse{
+		Name:      key.Name,
+		Tier:      key.Tier,
+		Limit:     q.Limit,
+		Used:      q.Used,
+		Remaining: q.Remaining(),
+		ResetAt:   q.ResetAt,
+	}
+}
+
+// ResetResponse reports what a quota reset did
+type ResetResponse struct {
+	Keys int `json:"keys"`
+}
+
+// QuoteResponse is a quote of the public API
+type QuoteResponse struct {
+	ID     int    `json:"id"`
+	Text   string `json:"text"`
+	Author string `json:"author"`
+}
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/quota-demo/internal/domain"
+	"github.com/gostratum/examples/quota-demo/internal/usecase"
+)
+
+// APIKeyHeader carries the caller's API key
+const APIKeyHeader = "X-API-Key"
+
+// Quota headers, as GitHub and many other public APIs send them
+const (
+	// headerLimit is the key's requests per day
+	headerLimit = "X-RateLimit-Limit"
+	// headerRemaining is the requests left today
+	headerRemaining = "X-RateLimit-Remaining"
+	// headerReset is when the quota resets, in Unix seconds
+	headerReset = "X-RateLimit-Reset"
+)
+
+// Quotas counts requests against the daily quotas; implemented by usecase.QuotaService
+type Quotas interface {
+	Consume(ctx context.Context, secret string) (domain.Quota, error)
+	Status(ctx context.Context, secret string) (*domain.APIKey, domain.Quota, error)
+}
+
+// QuotaHandler authenticates public API requests and counts them against the
+// daily quota of their key
+type QuotaHandler struct {
+	quotas Quotas
+	log    logx.Logger
+}
+
+// NewQuotaHandler creates a new quota handler
+func NewQuotaHandler(service *usecase.QuotaService, log logx.Logger) *QuotaHandler {
+	return newQuotaHandler(service, log)
+}
+
+func newQuotaHandler(quotas Quotas, log logx.Logger) *QuotaHandler {
+	return &QuotaHandler{quotas: quotas, log: log}
+}
+
+// Middleware counts each request against its key's quota before passing it
+// on, and sets the quota headers. Requests it refuses are not counted.
+func (h *QuotaHandler) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		q, err := h.quotas.Consume(c.Request.Context(), c.GetHeader(APIKeyHeader))
+		if err != nil {
+			h.handleError(c, q, err)
+			c.Abort()
+			return
+		}
+
+		setQuotaHeaders(c, q)
+		c.Next()
+	}
+}
+
+// Usage handles GET /v1/usage. It shows the key's quota without using it up.
+func (h *QuotaHandler) Usage(c *gin.Context) {
+	key, q, err := h.quotas.Status(c.Request.Context(), c.GetHeader(APIKeyHeader))
+	if err != nil {
+		h.handleError(c, q, err)
+		return
+	}
+
+	setQuotaHeaders(c, q)
+	responsex.OK(c, FromDomainQuota(key, q), nil)
+}
+
+// setQuotaHeaders sets the quota headers from q
+func setQuotaHeaders(c *gin.Context, q domain.Quota) {
+	c.Header(headerLimit, strconv.Itoa(q.Limit))
+	c.Header(headerRemaining, strconv.Itoa(q.Remaining()))
+	c.Header(headerReset, strconv.FormatInt(q.ResetAt.Unix(), 10))
+}
+
+// handleError maps usecase errors to HTTP responses. The service fails closed:
+// without the database no request is let through.
+func (h *QuotaHandler) handleError(c *gin.Context, q domain.Quota, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrUnauthorized):
+		responsex.Error(c, http.StatusUnauthorized, "INVALID_API_KEY", "missing, invalid or revoked "+APIKeyHeader+" header", nil)
+	case errors.Is(err, usecase.ErrQuotaExceeded):
+		setQuotaHeaders(c, q)
+		retryAfter := int(math.Ceil(time.Until(q.ResetAt).Seconds()))
+		c.Header("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+		msg := fmt.Sprintf("daily quota of %d requests exceeded; it resets at %s", q.Limit, q.ResetAt.Format(time.RFC3339))
+		responsex.Error(c, http.StatusTooManyRequests, "QUOTA_EXCEEDED", msg, nil)
+	case errors.Is(err, usecase.ErrUnavailable):
+		h.log.Error("database unavailable", logx.Err(err))
+		c.Header("Retry-After", "2")
+		responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "service temporarily unavailable", nil)
+	default:
+		h.log.Error("unexpected error", logx.Err(err))
+		responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+	}
+}
@@ -0,0 +1,158 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/quota-demo/internal/domain"
+	"github.com/gostratum/examples/quota-demo/internal/usecase"
+)
+
+const (
+	testSecret     = "qk_valid"
+	testAdminToken = "admin-token"
+)
+
+// fakeQuotas gives testSecret limit requests, or fails every call with err
+type fakeQuotas struct {
+	limit int
+	used  int
+	err   error
+}
+
+func (f *fakeQuotas) quota() domain.Quota {
+	return domain.Quota{Limit: f.limit, Used: f.used, ResetAt: domain.Day(time.Now()).AddDate(0, 0, 1)}
+}
+
+func (f *fakeQuotas) Consume(ctx context.Context, secret string) (domain.Quota, error) {
+	if f.err != nil {
+		return domain.Quota{}, f.err
+	}
+	if secret != testSecret {
+		return domain.Quota{}, usecase.ErrUnauthorized
+	}
+	if f.used >= f.limit {
+		return f.quota(), usecase.ErrQuotaExceeded
+	}
+	f.used++
+	return f.quota(), nil
+}
+
+func (f *fakeQuotas) Status(ctx context.Context, secret string) (*domain.APIKey, domain.Quota, error) {
+	if secret != testSecret {
+		return nil, domain.Quota{}, usecase.ErrUnauthorized
+	}
+	return &domain.APIKey{Name: "Acme", Tier: "free"}, f.quota(), nil
+}
+
+func setupRouter(t *testing.T, quotas *fakeQuotas, keys *fakeKeys) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	adminAuth, err := newAdminAuth(testAdminToken)
+	require.NoError(t, err)
+
+	quotaHandler := newQuotaHandler(quotas, logx.NewNoopLogger())
+	adminHandler := newAdminHandler(keys, keys, logx.NewNoopLogger())
+
+	e := gin.New()
+	RegisterRoutes(e, quotaHandler, NewQuoteHandler(), adminHandler, adminAuth, nil, logx.NewNoopLogger())
+	return e
+}
+
+func do(e *gin.Engine, method, path string, header http.Header, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range header {
+		req.Header.Set(k, v[0])
+	}
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w
+}
+
+func withKey(secret string) http.Header {
+	return http.Header{APIKeyHeader: {secret}}
+}
+
+func TestQuotaMiddleware(t *testing.T) {
+	e := setupRouter(t, &fakeQuotas{limit: 2}, &fakeKeys{})
+	reset := strconv.FormatInt(domain.Day(time.Now()).AddDate(0, 0, 1).Unix(), 10)
+
+	for _, remaining := range []string{"1", "0"} {
+		w := do(e, http.MethodGet, "/v1/quotes/random", withKey(testSecret), "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, remaining, w.Header().Get("X-RateLimit-Remaining"))
+		assert.Equal(t, reset, w.Header().Get("X-RateLimit-Reset"))
+	}
+
+	w := do(e, http.MethodGet, "/v1/quotes", withKey(testSecret), "")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	assert.InDelta(t, time.Until(domain.Day(time.Now()).AddDate(0, 0, 1)).Seconds(), retryAfter, 2)
+
+	var env responsex.Envelope[any]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+	require.NotNil(t, env.Error)
+	assert.Equal(t, "QUOTA_EXCEEDED", env.Error.Code)
+}
+
+func TestQuotaMiddlewareErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		secret   string
+		err      error
+		wantCode int
+		wantErr  string
+	}{
+		{name: "missing key", secret: "", wantCode: http.StatusUnauthorized, wantErr: "INVALID_API_KEY"},
+		{name: "unknown key", secret: "qk_other", wantCode: http.StatusUnauthorized, wantErr: "INVALID_API_KEY"},
+		{name: "database down", secret: testSecret, err: fmt.Errorf("%w: connection refused", usecase.ErrUnavailable), wantCode: http.StatusServiceUnavailable, wantErr: "SERVICE_UNAVAILABLE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := setupRouter(t, &fakeQuotas{limit: 10, err: tt.err}, &fakeKeys{})
+
+			w := do(e, http.MethodGet, "/v1/quotes", withKey(tt.secret), "")
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantErr)
+			assert.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+		})
+	}
+}
+
+func TestUsage(t *testing.T) {
+	quotas := &fakeQuotas{limit: 20, used: 5}
+	e := setupRouter(t, quotas, &fakeKeys{})
+
+	w := do(e, http.MethodGet, "/v1/usage", withKey(testSecret), "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "15", w.Header().Get("X-RateLimit-Remaining"))
+
+	var resp responsex.Envelope[QuotaResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "free", resp.Data.Tier)
+	assert.Equal(t, 5, resp.Data.Used)
+	assert.Equal(t, 15, resp.Data.Remaining)
+	assert.Equal(t, 5, quotas.used, "reading the usage does not use the quota")
+
+	w = do(e, http.MethodGet, "/v1/usage", nil, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
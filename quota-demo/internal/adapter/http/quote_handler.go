@@ -0,0 +1,36 @@
+package http
+
+import (
+	"math/rand/v2"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/httpx/responsex"
+)
+
+// quotes are the public API's product. What the API serves does not matter to
+// the example; the quotas in front of it do.
+var quotes = []QuoteResponse{
+	{ID: 1, Text: "Simplicity is prerequisite for reliability.", Author: "Edsger W. Dijkstra"},
+	{ID: 2, Text: "Make it work, make it right, make it fast.", Author: "Kent Beck"},
+	{ID: 3, Text: "Premature optimization is the root of all evil.", Author: "Donald Knuth"},
+	{ID: 4, Text: "Clear is better than clever.", Author: "Rob Pike"},
+	{ID: 5, Text: "A little copying is better than a little dependency.", Author: "Rob Pike"},
+}
+
+// QuoteHandler serves the quotes of the public API
+type QuoteHandler struct{}
+
+// NewQuoteHandler creates a new quote handler
+func NewQuoteHandler() *QuoteHandler {
+	return &QuoteHandler{}
+}
+
+// ListQuotes handles GET /v1/quotes
+func (h *QuoteHandler) ListQuotes(c *gin.Context) {
+	responsex.OK(c, quotes, nil)
+}
+
+// RandomQuote handles GET /v1/quotes/random
+func (h *QuoteHandler) RandomQuote(c *gin.Context) {
+	responsex.OK(c, quotes[rand.IntN(len(quotes))], nil)
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+)
+
+// RegisterRoutes registers all HTTP routes using the provided Gin engine
+// This function is designed to be used with fx.Invoke to work with httpx.Module
+func RegisterRoutes(e *gin.Engine, quotaHandler *QuotaHandler, quoteHandler *QuoteHandler, adminHandler *AdminHandler, adminAuth *AdminAuth, reg core.Registry, log logx.Logger) {
+	// Add responsex middleware for request tracking and metadata
+	e.Use(responsex.MetaMiddleware("quota-demo/v1.0.0"))
+
+	// Public API; every request counts against the key's daily quota
+	v1 := e.Group("/v1", quotaHandler.Middleware())
+	v1.GET("/quotes", quoteHandler.ListQuotes)
+	v1.GET("/quotes/random", quoteHandler.RandomQuote)
+
+	// A key's own quota, which reading does not use up
+	e.GET("/v1/usage", quotaHandler.Usage)
+
+	// Admin API
+	admin := e.Group("/admin", adminAuth.Middleware())
+	admin.GET("/tiers", adminHandler.ListTiers)
+	admin.POST("/keys", adminHandler.CreateKey)
+	admin.GET("/keys", adminHandler.ListKeys)
+	admin.GET("/keys/:id", adminHandler.GetKey)
+	admin.PUT("/keys/:id/tier", adminHandler.ChangeTier)
+	admin.POST("/keys/:id/revoke", adminHandler.RevokeKey)
+	admin.POST("/reset", adminHandler.Reset)
+
+	// Health endpoints - readiness and liveness checks
+	e.GET("/healthz", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		res := reg.Aggregate(ctx, core.Readiness)
+		if res.OK {
+			c.JSON(http.StatusOK, gin.H{"ok": true, "details": res.Details})
+		} else {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"ok": false, "details": res.Details})
+		}
+	})
+
+	e.GET("/livez", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		res := reg.Aggregate(ctx, core.Liveness)
+		if res.OK {
+			c.JSON(http.StatusOK, gin.H{"ok": true, "details": res.Details})
+		} else {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"ok": false, "details": res.Details})
+		}
+	})
+
+	log.Info("HTTP routes registered")
+}
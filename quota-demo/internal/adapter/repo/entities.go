@@ -0,0 +1,83 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/gostratum/examples/quota-demo/internal/domain"
+)
+
+// KeyEntity represents the GORM model for api_keys table
+type KeyEntity struct {
+	ID        string    `gorm:"type:uuid;primaryKey"`
+	Name      string    `gorm:"not null"`
+	KeyHash   string    `gorm:"not null;uniqueIndex"`
+	Prefix    string    `gorm:"not null"`
+	Tier      string    `gorm:"not null"`
+	Used      int       `gorm:"not null"`
+	Period    time.Time `gorm:"type:date;not null"`
+	CreatedAt time.Time `gorm:"not null"`
+	RevokedAt *time.Time
+}
+
+// TableName specifies the table name for KeyEntity
+func (KeyEntity) TableName() string {
+	return "api_keys"
+}
+
+// FromDomain creates KeyEntity from domain.APIKey
+func (k *KeyEntity) FromDomain(key *domain.APIKey) {
+	k.ID = key.ID
+	k.Name = key.Name
+	k.KeyHash = key.Hash
+	k.Prefix = key.Prefix
+	k.Tier = key.Tier
+	k.Used = key.Used
+	k.Period = key.Period
+	k.CreatedAt = key.CreatedAt
+	k.RevokedAt = key.RevokedAt
+}
+
+// keyRow is a key joined with its tier's quota
+type keyRow struct {
+	KeyEntity  `gorm:"embedded"`
+	DailyQuota int
+}
+
+// ToDomain converts keyRow to domain.APIKey
+func (r *keyRow) ToDomain() *domain.APIKey {
+	return &domain.APIKey{
+		ID:         r.ID,
+		Name:       r.Name,
+		Hash:       r.KeyHash,
+		Prefix:     r.Prefix,
+		Tier:       r.Tier,
+		DailyQuota: r.DailyQuota,
+		Used:       r.Used,
+		Period:     r.Period.UTC(),
+		CreatedAt:  r.CreatedAt,
+		RevokedAt:  r.RevokedAt,
+	}
+}
+
+// TierEntity represents the GORM model for tiers table
+type TierEntity struct {
+	Name       string `gorm:"primaryKey"`
+	DailyQuota int    `gorm:"not null"`
+}
+
+// TableName specifies the table name for TierEntity
+func (TierEntity) TableName() string {
+	return "tiers"
+}
+
+// UsageEntity represents the GORM model for daily_usage table
+type UsageEntity struct {
+	KeyID    string    `gorm:"type:uuid;primaryKey"`
+	Day      time.Time `gorm:"type:date;primaryKey"`
+	Requests int       `gorm:"not null"`
+}
+
+// TableName specifies the table name for UsageEntity
+func (UsageEntity) TableName() string {
+	return "daily_usage"
+}
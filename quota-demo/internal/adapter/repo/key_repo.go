@@ -0,0 +1,228 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+
+	"github.com/gostratum/examples/quota-demo/internal/domain"
+	"github.com/gostratum/examples/quota-demo/internal/usecase"
+)
+
+// selectKey reads keys with their tier's quota
+const selectKey = `
+SELECT k.id, k.name, k.key_hash, k.prefix, k.tier, t.daily_quota, k.used, k.period, k.created_at, k.revoked_at
+FROM api_keys k
+JOIN tiers t ON t.name = k.tier`
+
+// consume counts a request in one statement: the row lock it takes makes
+// concurrent requests with the same key wait for each other, and each sees
+// the count the one before left, so none goes over the quota. A period after
+// day is counted in too; another replica's clock has reached midnight first.
+const consume = `
+UPDATE api_keys k
+SET used = k.used + 1
+FROM tiers t
+WHERE k.key_hash = ? AND k.revoked_at IS NULL AND k.period >= ?
+  AND t.name = k.tier AND k.used < t.daily_quota
+RETURNING k.id, k.name, k.key_hash, k.prefix, k.tier, t.daily_quota, k.used, k.period, k.created_at, k.revoked_at`
+
+// rollover moves keys whose period is before day on to day. The subquery
+// locks the keys and reads their latest count, so a request counted while
+// the rollover waited for the lock is archived too; a key another rollover
+// got to first no longer matches and is skipped. %s narrows it to one key.
+const rollover = `
+WITH rolled AS (
+    UPDATE api_keys k
+    SET used = 0, period = ?
+    FROM (SELECT id, used, period FROM api_keys WHERE period < ? %s FOR UPDATE) old
+    WHERE k.id = old.id
+    RETURNING old.id, old.period, old.used
+), archived AS (
+    INSERT INTO daily_usage (key_id, day, requests)
+    SELECT id, period, used FROM rolled WHERE used > 0
+    ON CONFLICT (key_id, day) DO UPDATE SET requests = daily_usage.requests + EXCLUDED.requests
+)
+SELECT COUNT(*) FROM rolled`
+
+// KeyRepo implements the KeyRepository interface using GORM
+type KeyRepo struct {
+	db *gorm.DB
+}
+
+// NewKeyRepo creates a new GORM-based key repository
+func NewKeyRepo(db *gorm.DB) usecase.KeyRepository {
+	return &KeyRepo{db: db}
+}
+
+// Create implements usecase.KeyRepository
+func (r *KeyRepo) Create(ctx context.Context, key *domain.APIKey) error {
+	var entity KeyEntity
+	entity.FromDomain(key)
+
+	if err := r.db.WithContext(ctx).Create(&entity).Error; err != nil {
+		if isForeignKeyViolation(err) {
+			return fmt.Errorf("%w: tier %q does not exist", domain.ErrInvalidInput, key.Tier)
+		}
+		return err
+	}
+	return nil
+}
+
+// FindByID implements usecase.KeyRepository
+func (r *KeyRepo) FindByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	if !validID(id) {
+		return nil, domain.ErrNotFound
+	}
+	return r.findOne(ctx, selectKey+" WHERE k.id = ?", id)
+}
+
+// FindByHash implements usecase.KeyRepository
+func (r *KeyRepo) FindByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	return r.findOne(ctx, selectKey+" WHERE k.key_hash = ?", hash)
+}
+
+func (r *KeyRepo) findOne(ctx context.Context, query string, args ...any) (*domain.APIKey, error) {
+	var rows []keyRow
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return rows[0].ToDomain(), nil
+}
+
+// List implements usecase.KeyRepository
+func (r *KeyRepo) List(ctx context.Context) ([]*domain.APIKey, error) {
+	var rows []keyRow
+	if err := r.db.WithContext(ctx).Raw(selectKey + " ORDER BY k.created_at DESC, k.id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	keys := make([]*domain.APIKey, len(rows))
+	for i := range rows {
+		keys[i] = rows[i].ToDomain()
+	}
+	return keys, nil
+}
+
+// SetTier implements usecase.KeyRepository
+func (r *KeyRepo) SetTier(ctx context.Context, id, tier string) error {
+	if !validID(id) {
+		return domain.ErrNotFound
+	}
+
+	result := r.db.WithContext(ctx).Model(&KeyEntity{}).Where("id = ?", id).Update("tier", tier)
+	if result.Error != nil {
+		if isForeignKeyViolation(result.Error) {
+			return fmt.Errorf("%w: tier %q does not exist", domain.ErrInvalidInput, tier)
+		}
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Revoke implements usecase.KeyRepository
+func (r *KeyRepo) Revoke(ctx context.Context, id string, at time.Time) error {
+	if !validID(id) {
+		return domain.ErrNotFound
+	}
+
+	result := r.db.WithContext(ctx).Model(&KeyEntity{}).
+		Where("id = ?", id).
+		Update("revoked_at", gorm.Expr("COALESCE(revoked_at, ?)", at))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Tiers implements usecase.KeyRepository
+func (r *KeyRepo) Tiers(ctx context.Context) ([]domain.Tier, error) {
+	var entities []TierEntity
+	if err := r.db.WithContext(ctx).Order("daily_quota, name").Find(&entities).Error; err != nil {
+		return nil, err
+	}
+
+	tiers := make([]domain.Tier, len(entities))
+	for i, e := range entities {
+		tiers[i] = domain.Tier{Name: e.Name, DailyQuota: e.DailyQuota}
+	}
+	return tiers, nil
+}
+
+// Consume implements usecase.KeyRepository
+func (r *KeyRepo) Consume(ctx context.Context, hash string, day time.Time) (*domain.APIKey, bool, error) {
+	var rows []keyRow
+	if err := r.db.WithContext(ctx).Raw(consume, hash, day).Scan(&rows).Error; err != nil {
+		return nil, false, err
+	}
+	if len(rows) == 0 {
+		return nil, false, nil
+	}
+	return rows[0].ToDomain(), true, nil
+}
+
+// Rollover implements usecase.KeyRepository
+func (r *KeyRepo) Rollover(ctx context.Context, day time.Time) (int, error) {
+	var n int
+	err := r.db.WithContext(ctx).Raw(fmt.Sprintf(rollover, ""), day, day).Scan(&n).Error
+	return n, err
+}
+
+// RolloverKey implements usecase.KeyRepository
+func (r *KeyRepo) RolloverKey(ctx context.Context, id string, day time.Time) error {
+	if !validID(id) {
+		return domain.ErrNotFound
+	}
+	var n int
+	return r.db.WithContext(ctx).Raw(fmt.Sprintf(rollover, "AND id = ?"), day, day, id).Scan(&n).Error
+}
+
+// Usage implements usecase.KeyRepository
+func (r *KeyRepo) Usage(ctx context.Context, id string, since time.Time) ([]domain.DailyUsage, error) {
+	if !validID(id) {
+		return nil, domain.ErrNotFound
+	}
+
+	var entities []UsageEntity
+	err := r.db.WithContext(ctx).
+		Where("key_id = ? AND day >= ?", id, since).
+		Order("day").
+		Find(&entities).Error
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make([]domain.DailyUsage, len(entities))
+	for i, e := range entities {
+		usage[i] = domain.DailyUsage{Day: e.Day.UTC(), Requests: e.Requests}
+	}
+	return usage, nil
+}
+
+// validID reports whether id could be a key ID. Postgres rejects anything
+// else as a UUID with an error, where it should be not found.
+func validID(id string) bool {
+	_, err := uuid.Parse(id)
+	return err == nil
+}
+
+// isForeignKeyViolation reports whether err is a foreign key violation,
+// whether or not GORM's TranslateError turned it into gorm.ErrForeignKeyViolated
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.Is(err, gorm.ErrForeignKeyViolated) || (errors.As(err, &pgErr) && pgErr.Code == "23503")
+}
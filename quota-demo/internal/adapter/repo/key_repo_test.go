@@ -0,0 +1,242 @@
+package repo
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/gostratum/examples/quota-demo/internal/domain"
+	"github.com/gostratum/examples/quota-demo/migrations"
+	"github.com/gostratum/examples/testkit/containers"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(containers.Run(m))
+}
+
+// setupTestDB creates a Postgres database with the service's migrations applied
+func setupTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	return containers.PostgresGorm(t, containers.WithMigrations(migrations.FS))
+}
+
+var (
+	yesterday = time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC)
+	today     = yesterday.AddDate(0, 0, 1)
+)
+
+// createKey stores a key on tier that has used used requests of period
+func createKey(t *testing.T, repo *KeyRepo, tier string, used int, period time.Time) (*domain.APIKey, string) {
+	t.Helper()
+	key, secret := domain.NewAPIKey("test", tier)
+	key.Used = used
+	key.Period = period
+	require.NoError(t, repo.Create(context.Background(), key))
+	return key, secret
+}
+
+func TestKeyRepoCreateAndFind(t *testing.T) {
+	t.Parallel()
+	repo := NewKeyRepo(setupTestDB(t)).(*KeyRepo)
+	ctx := context.Background()
+
+	key, _ := createKey(t, repo, "pro", 0, today)
+
+	got, err := repo.FindByID(ctx, key.ID)
+	require.NoError(t, err)
+	assert.Equal(t, key.Hash, got.Hash)
+	assert.Equal(t, "pro", got.Tier)
+	assert.Equal(t, 1000, got.DailyQuota, "the quota comes from the tier")
+	assert.Equal(t, today, got.Period)
+	assert.Nil(t, got.RevokedAt)
+
+	got, err = repo.FindByHash(ctx, key.Hash)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, got.ID)
+
+	_, err = repo.FindByID(ctx, uuid.NewString())
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	_, err = repo.FindByID(ctx, "not-a-uuid")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	_, err = repo.FindByHash(ctx, domain.HashSecret("qk_missing"))
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	unknown, _ := domain.NewAPIKey("test", "platinum")
+	assert.ErrorIs(t, repo.Create(ctx, unknown), domain.ErrInvalidInput)
+
+	keys, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, key.ID, keys[0].ID)
+}
+
+func TestKeyRepoSetTierAndRevoke(t *testing.T) {
+	t.Parallel()
+	repo := NewKeyRepo(setupTestDB(t)).(*KeyRepo)
+	ctx := context.Background()
+
+	key, _ := createKey(t, repo, "free", 0, today)
+
+	require.NoError(t, repo.SetTier(ctx, key.ID, "enterprise"))
+	got, err := repo.FindByID(ctx, key.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 100000, got.DailyQuota)
+
+	assert.ErrorIs(t, repo.SetTier(ctx, key.ID, "platinum"), domain.ErrInvalidInput)
+	assert.ErrorIs(t, repo.SetTier(ctx, uuid.NewString(), "pro"), domain.ErrNotFound)
+
+	first := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.Revoke(ctx, key.ID, first))
+	require.NoError(t, repo.Revoke(ctx, key.ID, first.Add(time.Hour)))
+	got, err = repo.FindByID(ctx, key.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.RevokedAt)
+	assert.True(t, first.Equal(*got.RevokedAt), "revoking again keeps the first time")
+
+	assert.ErrorIs(t, repo.Revoke(ctx, uuid.NewString(), first), domain.ErrNotFound)
+
+	tiers, err := repo.Tiers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Tier{{Name: "free", DailyQuota: 20}, {Name: "pro", DailyQuota: 1000}, {Name: "enterprise", DailyQuota: 100000}}, tiers)
+}
+
+func TestKeyRepoConsume(t *testing.T) {
+	t.Parallel()
+	repo := NewKeyRepo(setupTestDB(t)).(*KeyRepo)
+	ctx := context.Background()
+
+	key, _ := createKey(t, repo, "free", 18, today)
+
+	got, ok, err := repo.Consume(ctx, key.Hash, today)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, 19, got.Used)
+	assert.Equal(t, 20, got.DailyQuota)
+
+	_, ok, err = repo.Consume(ctx, key.Hash, today)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, ok, err = repo.Consume(ctx, key.Hash, today)
+	require.NoError(t, err)
+	assert.False(t, ok, "the quota is used up")
+
+	stale, _ := createKey(t, repo, "free", 0, yesterday)
+	_, ok, err = repo.Consume(ctx, stale.Hash, today)
+	require.NoError(t, err)
+	assert.False(t, ok, "a key has to be rolled over before its first request of the day")
+
+	revoked, _ := createKey(t, repo, "free", 0, today)
+	require.NoError(t, repo.Revoke(ctx, revoked.ID, time.Now()))
+	_, ok, err = repo.Consume(ctx, revoked.Hash, today)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, ok, err = repo.Consume(ctx, domain.HashSecret("qk_missing"), today)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestKeyRepoConsumeConcurrently(t *testing.T) {
+	t.Parallel()
+	repo := NewKeyRepo(setupTestDB(t)).(*KeyRepo)
+	ctx := context.Background()
+
+	key, _ := createKey(t, repo, "free", 0, today)
+
+	const requests = 50
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		accepted int
+	)
+	for range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, ok, err := repo.Consume(ctx, key.Hash, today)
+			assert.NoError(t, err)
+			if ok {
+				mu.Lock()
+				accepted++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 20, accepted, "exactly the quota is accepted")
+	got, err := repo.FindByID(ctx, key.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 20, got.Used)
+}
+
+func TestKeyRepoRollover(t *testing.T) {
+	t.Parallel()
+	repo := NewKeyRepo(setupTestDB(t)).(*KeyRepo)
+	ctx := context.Background()
+
+	busy, _ := createKey(t, repo, "pro", 7, yesterday)
+	idle, _ := createKey(t, repo, "pro", 0, yesterday)
+	current, _ := createKey(t, repo, "pro", 3, today)
+
+	n, err := repo.Rollover(ctx, today)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	got, err := repo.FindByID(ctx, busy.ID)
+	require.NoError(t, err)
+	assert.Zero(t, got.Used)
+	assert.Equal(t, today, got.Period)
+
+	usage, err := repo.Usage(ctx, busy.ID, yesterday.AddDate(0, 0, -30))
+	require.NoError(t, err)
+	assert.Equal(t, []domain.DailyUsage{{Day: yesterday, Requests: 7}}, usage)
+
+	usage, err = repo.Usage(ctx, idle.ID, yesterday.AddDate(0, 0, -30))
+	require.NoError(t, err)
+	assert.Empty(t, usage, "days without requests are not recorded")
+
+	got, err = repo.FindByID(ctx, current.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, got.Used, "keys already on the day are left alone")
+
+	n, err = repo.Rollover(ctx, today)
+	require.NoError(t, err)
+	assert.Zero(t, n, "a second rollover finds nothing to do")
+	usage, err = repo.Usage(ctx, busy.ID, yesterday)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.DailyUsage{{Day: yesterday, Requests: 7}}, usage)
+}
+
+func TestKeyRepoRolloverKey(t *testing.T) {
+	t.Parallel()
+	repo := NewKeyRepo(setupTestDB(t)).(*KeyRepo)
+	ctx := context.Background()
+
+	key, _ := createKey(t, repo, "free", 20, yesterday)
+	other, _ := createKey(t, repo, "free", 5, yesterday)
+
+	require.NoError(t, repo.RolloverKey(ctx, key.ID, today))
+	require.NoError(t, repo.RolloverKey(ctx, key.ID, today))
+
+	_, ok, err := repo.Consume(ctx, key.Hash, today)
+	require.NoError(t, err)
+	assert.True(t, ok, "the key has today's quota")
+
+	usage, err := repo.Usage(ctx, key.ID, yesterday)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.DailyUsage{{Day: yesterday, Requests: 20}}, usage)
+
+	got, err := repo.FindByID(ctx, other.ID)
+	require.NoError(t, err)
+	assert.Equal(t, yesterday, got.Period, "other keys are left for the nightly reset")
+	assert.Equal(t, 5, got.Used)
+}
@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// SecretPrefix starts every API key, so a leaked key is easy to recognise in
+// logs and by secret scanners
+const SecretPrefix = "qk_"
+
+// secretLength is the number of random characters after SecretPrefix;
+// 62^32 keys cannot be guessed
+const secretLength = 32
+
+// displayLength is how much of a key is kept in the clear to tell keys apart
+const displayLength = len(SecretPrefix) + 6
+
+// maxNameLength limits key names, in characters
+const maxNameLength = 100
+
+// secretAlphabet are the characters of a key
+const secretAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// Tier is a plan with a daily request quota
+type Tier struct {
+	Name       string
+	DailyQuota int
+}
+
+// APIKey is a client's key to the public API. Only the hash of the secret is
+// stored; the secret itself is shown once, when the key is created.
+//
+// Used counts the requests accepted during Period, a UTC day. A key whose
+// Period is before today has used nothing today; the nightly reset, or the
+// key's first request of the day, moves its count to the usage history.
+type APIKey struct {
+	ID         string
+	Name       string
+	Hash       string
+	Prefix     string
+	Tier       string
+	DailyQuota int
+	Used       int
+	Period     time.Time
+	CreatedAt  time.Time
+	RevokedAt  *time.Time
+}
+
+// NewAPIKey creates a key on tier with a new secret, and returns the key and
+// the secret
+func NewAPIKey(name, tier string) (*APIKey, string) {
+	secret := NewSecret()
+	now := time.Now().UTC()
+	return &APIKey{
+		ID:        uuid.NewString(),
+		Name:      strings.TrimSpace(name),
+		Hash:      HashSecret(secret),
+		Prefix:    secret[:displayLength],
+		Tier:      strings.TrimSpace(tier),
+		Period:    Day(now),
+		CreatedAt: now,
+	}, secret
+}
+
+// Validate checks the key against the business rules. Whether the tier exists
+// is for the database to say.
+func (k *APIKey) Validate() error {
+	if k.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(k.Name) > maxNameLength {
+		return fmt.Errorf("%w: name must be at most %d characters", ErrInvalidInput, maxNameLength)
+	}
+	if k.Tier == "" {
+		return fmt.Errorf("%w: tier is required", ErrInvalidInput)
+	}
+	return nil
+}
+
+// Revoked reports whether the key was revoked
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// Quota returns the key's quota as of now
+func (k *APIKey) Quota(now time.Time) Quota {
+	today := Day(now)
+	used := k.Used
+	if k.Period.Before(today) {
+		used = 0
+	}
+	return Quota{Limit: k.DailyQuota, Used: used, ResetAt: today.AddDate(0, 0, 1)}
+}
+
+// Quota is what an API key may still do today
+type Quota struct {
+	Limit int
+	Used  int
+	// ResetAt is the next UTC midnight
+	ResetAt time.Time
+}
+
+// Remaining returns the requests left today
+func (q Quota) Remaining() int {
+	return max(q.Limit-q.Used, 0)
+}
+
+// DailyUsage is the number of requests a key made on a past day
+type DailyUsage struct {
+	Day      time.Time
+	Requests int
+}
+
+// Day returns the UTC day of t, at midnight. Quotas reset at UTC midnight for
+// every key, whatever the client's time zone.
+func Day(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// NewSecret returns a random API key
+func NewSecret() string {
+	b := make([]byte, 0, len(SecretPrefix)+secretLength)
+	b = append(b, SecretPrefix...)
+	// 62 characters do not divide 256, so bytes of 248 and above are drawn
+	// again rather than making the first characters more likely
+	for len(b) < cap(b) {
+		var r [1]byte
+		_, _ = rand.Read(r[:]) // crypto/rand.Read never fails
+		if r[0] >= 248 {
+			continue
+		}
+		b = append(b, secretAlphabet[r[0]%62])
+	}
+	return string(b)
+}
+
+// HashSecret returns the hex SHA-256 of secret, under which its key is stored.
+// Keys are random and long, so a fast hash without a salt is enough: there is
+// no dictionary to try.
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSecret(t *testing.T) {
+	seen := map[string]bool{}
+	for range 1000 {
+		secret := NewSecret()
+		assert.True(t, strings.HasPrefix(secret, SecretPrefix), "secret %q", secret)
+		assert.Len(t, secret, len(SecretPrefix)+secretLength)
+		for _, r := range secret[len(SecretPrefix):] {
+			assert.True(t, strings.ContainsRune(secretAlphabet, r), "secret %q", secret)
+		}
+		seen[secret] = true
+	}
+	assert.Len(t, seen, 1000)
+}
+
+func TestNewAPIKey(t *testing.T) {
+	key, secret := NewAPIKey("  Acme  ", " pro ")
+
+	assert.Equal(t, "Acme", key.Name)
+	assert.Equal(t, "pro", key.Tier)
+	assert.Equal(t, HashSecret(secret), key.Hash)
+	assert.NotContains(t, key.Hash, secret)
+	assert.True(t, strings.HasPrefix(secret, key.Prefix))
+	assert.Len(t, key.Prefix, displayLength)
+	assert.Equal(t, Day(time.Now()), key.Period)
+	assert.False(t, key.Revoked())
+	assert.NoError(t, key.Validate())
+}
+
+func TestAPIKeyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyName string
+		tier    string
+		wantErr bool
+	}{
+		{name: "valid", keyName: "Acme", tier: "free"},
+		{name: "longest name", keyName: strings.Repeat("é", maxNameLength), tier: "free"},
+		{name: "name too long", keyName: strings.Repeat("é", maxNameLength+1), tier: "free", wantErr: true},
+		{name: "blank name", keyName: "   ", tier: "free", wantErr: true},
+		{name: "no tier", keyName: "Acme", tier: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, _ := NewAPIKey(tt.keyName, tt.tier)
+			err := key.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidInput)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAPIKeyQuota(t *testing.T) {
+	now := time.Date(2025, 3, 9, 23, 30, 0, 0, time.UTC)
+	key := &APIKey{DailyQuota: 100, Used: 40, Period: Day(now)}
+
+	q := key.Quota(now)
+	assert.Equal(t, Quota{Limit: 100, Used: 40, ResetAt: time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)}, q)
+	assert.Equal(t, 60, q.Remaining())
+
+	// The count of a day that has passed does not use today's quota
+	q = key.Quota(now.Add(time.Hour))
+	assert.Zero(t, q.Used)
+	assert.Equal(t, 100, q.Remaining())
+
+	// A key moved to a smaller tier can be over its quota
+	key.DailyQuota = 20
+	assert.Zero(t, key.Quota(now).Remaining())
+}
+
+func TestDay(t *testing.T) {
+	berlin := time.FixedZone("CET", 3600)
+	assert.Equal(t, time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC), Day(time.Date(2025, 3, 10, 0, 30, 0, 0, berlin)))
+	assert.Equal(t, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), Day(time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)))
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "errors"
+
+// Domain errors represent business rule violations
+var (
+	// ErrNotFound indicates the requested entity was not found
+	ErrNotFound = errors.New("not found")
+
+	// ErrInvalidInput indicates the provided input violates business rules
+	ErrInvalidInput = errors.New("invalid input")
+
+	// ErrUnauthorized indicates a request without a valid, unrevoked API key
+	ErrUnauthorized = errors.New("invalid API key")
+
+	// ErrQuotaExceeded indicates the API key has used its quota for the day
+	ErrQuotaExceeded = errors.New("daily quota exceeded")
+)
@@ -0,0 +1,20 @@
+// Package reset runs the nightly quota reset: at UTC midnight it moves every
+// key's count for the day that ended to the usage history and starts the new
+// day at zero
+package reset
+
+import "time"
+
+// Config sets when the reset runs
+type Config struct {
+	// Schedule is a cron expression, read in UTC, the time zone days are
+	// counted in
+	Schedule string `mapstructure:"schedule" default:"0 0 * * *"`
+	// Timeout bounds one reset
+	Timeout time.Duration `mapstructure:"timeout" default:"1m"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "reset"
+}
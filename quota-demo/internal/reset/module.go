@@ -0,0 +1,11 @@
+package reset
+
+import "go.uber.org/fx"
+
+// Module provides the Resetter and runs it from application start to stop
+func Module() fx.Option {
+	return fx.Module("reset",
+		fx.Provide(NewResetter),
+		fx.Invoke(Register),
+	)
+}
@@ -0,0 +1,124 @@
+package reset
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/fx"
+
+	"github.com/gostratum/examples/quota-demo/internal/usecase"
+)
+
+// Quotas resets the daily quotas; implemented by usecase.QuotaService
+type Quotas interface {
+	Reset(ctx context.Context) (int, error)
+}
+
+// Params holds the dependencies of the Resetter
+type Params struct {
+	fx.In
+
+	Loader  configx.Loader
+	Service *usecase.QuotaService
+	Log     logx.Logger
+}
+
+// Resetter calls Reset on its schedule, and once at start to catch up on a
+// midnight the service was down for
+type Resetter struct {
+	cfg      Config
+	schedule cron.Schedule
+	quotas   Quotas
+	log      logx.Logger
+
+	stopping chan struct{}
+	done     chan struct{}
+}
+
+// NewResetter creates a Resetter from the reset config section
+func NewResetter(p Params) (*Resetter, error) {
+	var cfg Config
+	if err := p.Loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load reset config: %w", err)
+	}
+	schedule, err := cron.ParseStandard(cfg.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reset.schedule %q: %w", cfg.Schedule, err)
+	}
+	if cfg.Timeout <= 0 {
+		return nil, fmt.Errorf("reset.timeout must be positive")
+	}
+	return newResetter(cfg, schedule, p.Service, p.Log), nil
+}
+
+func newResetter(cfg Config, schedule cron.Schedule, quotas Quotas, log logx.Logger) *Resetter {
+	return &Resetter{
+		cfg:      cfg,
+		schedule: schedule,
+		quotas:   quotas,
+		log:      log,
+		stopping: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Register ties the reset loop to the application lifecycle.
+// This function is designed to be used with fx.Invoke.
+func Register(lc fx.Lifecycle, r *Resetter) {
+	lc.Append(fx.Hook{
+		OnStart: r.start,
+		OnStop:  r.stop,
+	})
+}
+
+func (r *Resetter) start(context.Context) error {
+	go r.loop()
+	return nil
+}
+
+// stop ends the loop, waiting for a reset in progress
+func (r *Resetter) stop(ctx context.Context) error {
+	close(r.stopping)
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *Resetter) loop() {
+	defer close(r.done)
+
+	r.reset()
+	for {
+		next := r.schedule.Next(time.Now().UTC())
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-r.stopping:
+			timer.Stop()
+			return
+		case <-timer.C:
+			r.reset()
+		}
+	}
+}
+
+// reset runs one reset. Keys a failed reset missed start their day on their
+// first request instead, so failures are only logged.
+func (r *Resetter) reset() {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	n, err := r.quotas.Reset(ctx)
+	if err != nil {
+		r.log.Error("quota reset failed", logx.Err(err))
+		return
+	}
+	r.log.Info("quotas reset", logx.Int("keys", n), logx.String("duration", time.Since(start).String()))
+}
@@ -0,0 +1,66 @@
+package reset
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeQuotas counts the resets and fails the first failures of them
+type fakeQuotas struct {
+	mu       sync.Mutex
+	resets   int
+	failures int
+}
+
+func (f *fakeQuotas) Reset(ctx context.Context) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.resets++
+	if f.resets <= f.failures {
+		return 0, errors.New("connection refused")
+	}
+	return 2, nil
+}
+
+func (f *fakeQuotas) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.resets
+}
+
+// every is a schedule with runs d apart; cron.Every rounds to whole seconds
+type every time.Duration
+
+func (d every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(d))
+}
+
+func TestResetterRunsOnSchedule(t *testing.T) {
+	quotas := &fakeQuotas{failures: 1}
+	r := newResetter(Config{Timeout: time.Second}, every(10*time.Millisecond), quotas, logx.NewNoopLogger())
+
+	require.NoError(t, r.start(context.Background()))
+	// A failed reset does not end the loop
+	assert.Eventually(t, func() bool { return quotas.count() >= 3 }, 5*time.Second, 5*time.Millisecond)
+	require.NoError(t, r.stop(context.Background()))
+}
+
+func TestResetterResetsAtStart(t *testing.T) {
+	quotas := &fakeQuotas{}
+	schedule, err := cron.ParseStandard("0 0 * * *")
+	require.NoError(t, err)
+	r := newResetter(Config{Timeout: time.Second}, schedule, quotas, logx.NewNoopLogger())
+
+	require.NoError(t, r.start(context.Background()))
+	assert.Eventually(t, func() bool { return quotas.count() == 1 }, 5*time.Second, 5*time.Millisecond)
+	require.NoError(t, r.stop(context.Background()))
+	assert.Equal(t, 1, quotas.count())
+}
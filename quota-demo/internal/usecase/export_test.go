@@ -0,0 +1,8 @@
+package usecase
+
+import "time"
+
+// SetNow replaces the clock of s, so tests can move to the next day
+func SetNow(s *QuotaService, now func() time.Time) {
+	s.now = now
+}
@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/gostratum/examples/quota-demo/internal/domain"
+)
+
+// Application-level errors for use case layer
+// These are used to communicate failures to the presentation layer
+var (
+	// ErrUnavailable indicates the database cannot be reached
+	ErrUnavailable = errors.New("service unavailable")
+
+	// ErrNotFound wraps domain.ErrNotFound for application layer
+	ErrNotFound = domain.ErrNotFound
+
+	// ErrInvalid wraps domain.ErrInvalidInput for application layer
+	ErrInvalid = domain.ErrInvalidInput
+
+	// ErrUnauthorized wraps domain.ErrUnauthorized for application layer
+	ErrUnauthorized = domain.ErrUnauthorized
+
+	// ErrQuotaExceeded wraps domain.ErrQuotaExceeded for application layer
+	ErrQuotaExceeded = domain.ErrQuotaExceeded
+)
@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gostratum/core/logx"
+
+	"github.com/gostratum/examples/quota-demo/internal/domain"
+)
+
+// historyDays is how far back GetKey returns a key's usage
+const historyDays = 30
+
+// KeyService manages API keys for the admin API
+type KeyService struct {
+	repo KeyRepository
+	log  logx.Logger
+}
+
+// NewKeyService creates a new key service with repository injection
+func NewKeyService(repo KeyRepository, log logx.Logger) *KeyService {
+	return &KeyService{repo: repo, log: log}
+}
+
+// CreateKey creates a key on tier and returns it with its secret. The secret
+// is not stored, so this is the only time it can be read.
+func (s *KeyService) CreateKey(ctx context.Context, name, tier string) (*domain.APIKey, string, error) {
+	key, secret := domain.NewAPIKey(name, tier)
+	if err := key.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, "", translateError(err)
+	}
+	// Read back for the tier's quota
+	created, err := s.repo.FindByID(ctx, key.ID)
+	if err != nil {
+		return nil, "", translateError(err)
+	}
+
+	s.log.Info("API key created", logx.String("key_id", key.ID), logx.String("tier", key.Tier))
+	return created, secret, nil
+}
+
+// ListKeys returns every key, newest first
+func (s *KeyService) ListKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	keys, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return keys, nil
+}
+
+// GetKey returns a key with its usage over the past days
+func (s *KeyService) GetKey(ctx context.Context, id string) (*domain.APIKey, []domain.DailyUsage, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	key, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, nil, translateError(err)
+	}
+	since := domain.Day(time.Now()).AddDate(0, 0, -historyDays)
+	usage, err := s.repo.Usage(ctx, id, since)
+	if err != nil {
+		return nil, nil, translateError(err)
+	}
+	return key, usage, nil
+}
+
+// ChangeTier moves a key to another tier. The new quota applies at once, to
+// the requests already made today too.
+func (s *KeyService) ChangeTier(ctx context.Context, id, tier string) (*domain.APIKey, error) {
+	if tier == "" {
+		return nil, fmt.Errorf("%w: tier is required", ErrInvalid)
+	}
+
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	if err := s.repo.SetTier(ctx, id, tier); err != nil {
+		return nil, translateError(err)
+	}
+	key, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	s.log.Info("API key tier changed", logx.String("key_id", id), logx.String("tier", tier))
+	return key, nil
+}
+
+// RevokeKey revokes a key; requests with it are refused from then on.
+// Revoking a key again keeps the time it was first revoked.
+func (s *KeyService) RevokeKey(ctx context.Context, id string) (*domain.APIKey, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	if err := s.repo.Revoke(ctx, id, time.Now().UTC()); err != nil {
+		return nil, translateError(err)
+	}
+	key, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	s.log.Info("API key revoked", logx.String("key_id", id))
+	return key, nil
+}
+
+// Tiers returns the tiers keys can be on
+func (s *KeyService) Tiers(ctx context.Context) ([]domain.Tier, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	tiers, err := s.repo.Tiers(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return tiers, nil
+}
+
+// translateError converts repository/domain errors to usecase errors
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return ErrNotFound
+	case errors.Is(err, domain.ErrInvalidInput):
+		return err
+	default:
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+}
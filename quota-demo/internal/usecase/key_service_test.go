@@ -0,0 +1,82 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/quota-demo/internal/domain"
+	"github.com/gostratum/examples/quota-demo/internal/usecase"
+)
+
+func TestCreateKey(t *testing.T) {
+	t.Parallel()
+	f := setup(t)
+	ctx := context.Background()
+
+	key, secret, err := f.keys.CreateKey(ctx, "Acme", "pro")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, domain.SecretPrefix))
+	assert.True(t, strings.HasPrefix(secret, key.Prefix))
+	assert.Equal(t, 1000, key.DailyQuota)
+
+	got, usage, err := f.keys.GetKey(ctx, key.ID)
+	require.NoError(t, err)
+	assert.Equal(t, key.Hash, got.Hash)
+	assert.Empty(t, usage)
+
+	_, _, err = f.keys.CreateKey(ctx, "Acme", "platinum")
+	assert.ErrorIs(t, err, usecase.ErrInvalid)
+	_, _, err = f.keys.CreateKey(ctx, " ", "pro")
+	assert.ErrorIs(t, err, usecase.ErrInvalid)
+
+	keys, err := f.keys.ListKeys(ctx)
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+}
+
+func TestChangeTier(t *testing.T) {
+	t.Parallel()
+	f := setup(t)
+	ctx := context.Background()
+
+	key, secret, err := f.keys.CreateKey(ctx, "Acme", "pro")
+	require.NoError(t, err)
+	for range 25 {
+		_, err := f.quotas.Consume(ctx, secret)
+		require.NoError(t, err)
+	}
+
+	// Moving down to a quota below today's count refuses the next request
+	key, err = f.keys.ChangeTier(ctx, key.ID, "free")
+	require.NoError(t, err)
+	assert.Equal(t, 20, key.DailyQuota)
+	_, err = f.quotas.Consume(ctx, secret)
+	assert.ErrorIs(t, err, usecase.ErrQuotaExceeded)
+
+	_, err = f.keys.ChangeTier(ctx, key.ID, "pro")
+	require.NoError(t, err)
+	_, err = f.quotas.Consume(ctx, secret)
+	assert.NoError(t, err)
+
+	_, err = f.keys.ChangeTier(ctx, key.ID, "platinum")
+	assert.ErrorIs(t, err, usecase.ErrInvalid)
+	_, err = f.keys.ChangeTier(ctx, key.ID, "")
+	assert.ErrorIs(t, err, usecase.ErrInvalid)
+	_, err = f.keys.ChangeTier(ctx, uuid.NewString(), "pro")
+	assert.ErrorIs(t, err, usecase.ErrNotFound)
+}
+
+func TestTiers(t *testing.T) {
+	t.Parallel()
+	f := setup(t)
+
+	tiers, err := f.keys.Tiers(context.Background())
+	require.NoError(t, err)
+	require.Len(t, tiers, 3)
+	assert.Equal(t, domain.Tier{Name: "free", DailyQuota: 20}, tiers[0])
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gostratum/core/logx"
+
+	"github.com/gostratum/examples/quota-demo/internal/domain"
+)
+
+// QuotaService checks API keys and counts their requests against the daily
+// quota of their tier
+type QuotaService struct {
+	repo KeyRepository
+	log  logx.Logger
+
+	// now is replaced in tests
+	now func() time.Time
+}
+
+// NewQuotaService creates a new quota service with repository injection
+func NewQuotaService(repo KeyRepository, log logx.Logger) *QuotaService {
+	return &QuotaService{repo: repo, log: log, now: time.Now}
+}
+
+// Consume counts a request made with secret and returns the key's quota after
+// it. It fails with ErrUnauthorized for unknown and revoked keys, and with
+// ErrQuotaExceeded, along with the quota, when the key has used up today's.
+// Requests that fail are not counted.
+func (s *QuotaService) Consume(ctx context.Context, secret string) (domain.Quota, error) {
+	// Anything else cannot be a key; it is refused without a query
+	if !strings.HasPrefix(secret, domain.SecretPrefix) {
+		return domain.Quota{}, ErrUnauthorized
+	}
+
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	hash := domain.HashSecret(secret)
+	now := s.now()
+	today := domain.Day(now)
+
+	// One statement counts the request in the common case; the rest is only
+	// read when it did not
+	for rolledOver := false; ; rolledOver = true {
+		key, ok, err := s.repo.Consume(ctx, hash, today)
+		if err != nil {
+			return domain.Quota{}, translateError(err)
+		}
+		if ok {
+			return key.Quota(now), nil
+		}
+
+		key, err = s.repo.FindByHash(ctx, hash)
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Quota{}, ErrUnauthorized
+		}
+		if err != nil {
+			return domain.Quota{}, translateError(err)
+		}
+		if key.Revoked() {
+			return domain.Quota{}, fmt.Errorf("%w: the key was revoked", ErrUnauthorized)
+		}
+
+		// The key's first request today, and the nightly reset has not got
+		// to it yet; a reset that is late or did not run never locks a
+		// client out
+		if key.Period.Before(today) && !rolledOver {
+			if err := s.repo.RolloverKey(ctx, key.ID, today); err != nil {
+				return domain.Quota{}, translateError(err)
+			}
+			continue
+		}
+		return key.Quota(now), ErrQuotaExceeded
+	}
+}
+
+// Status returns the key of secret and its quota, without counting a request
+func (s *QuotaService) Status(ctx context.Context, secret string) (*domain.APIKey, domain.Quota, error) {
+	if !strings.HasPrefix(secret, domain.SecretPrefix) {
+		return nil, domain.Quota{}, ErrUnauthorized
+	}
+
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	key, err := s.repo.FindByHash(ctx, domain.HashSecret(secret))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.Quota{}, ErrUnauthorized
+	}
+	if err != nil {
+		return nil, domain.Quota{}, translateError(err)
+	}
+	if key.Revoked() {
+		return nil, domain.Quota{}, fmt.Errorf("%w: the key was revoked", ErrUnauthorized)
+	}
+	return key, key.Quota(s.now()), nil
+}
+
+// Reset starts the day for every key that has not started it yet: yesterday's
+// counts go to the usage history and today's start at zero. It is the nightly
+// reset job's work, and returns how many keys it reset. Running it again, or
+// on several replicas at once, resets no key twice.
+func (s *QuotaService) Reset(ctx context.Context) (int, error) {
+	n, err := s.repo.Rollover(ctx, domain.Day(s.now()))
+	if err != nil {
+		return 0, translateError(err)
+	}
+	return n, nil
+}
@@ -0,0 +1,143 @@
+// The use case tests run against the real repository, on Postgres in Docker;
+// they are an external test package because the repository imports this one
+package usecase_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	repoAdapter "github.com/gostratum/examples/quota-demo/internal/adapter/repo"
+	"github.com/gostratum/examples/quota-demo/internal/domain"
+	"github.com/gostratum/examples/quota-demo/internal/usecase"
+	"github.com/gostratum/examples/quota-demo/migrations"
+	"github.com/gostratum/examples/testkit/containers"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(containers.Run(m))
+}
+
+// fixture is a KeyService and a QuotaService on a database of the test's own
+type fixture struct {
+	keys   *usecase.KeyService
+	quotas *usecase.QuotaService
+	repo   usecase.KeyRepository
+	// now is the quota service's clock
+	now time.Time
+}
+
+func setup(t *testing.T) *fixture {
+	t.Helper()
+	db := containers.PostgresGorm(t, containers.WithMigrations(migrations.FS))
+
+	f := &fixture{repo: repoAdapter.NewKeyRepo(db), now: time.Now()}
+	f.keys = usecase.NewKeyService(f.repo, logx.NewNoopLogger())
+	f.quotas = usecase.NewQuotaService(f.repo, logx.NewNoopLogger())
+	usecase.SetNow(f.quotas, func() time.Time { return f.now })
+	return f
+}
+
+func TestConsumeCountsUpToTheQuota(t *testing.T) {
+	t.Parallel()
+	f := setup(t)
+	ctx := context.Background()
+
+	_, secret, err := f.keys.CreateKey(ctx, "Acme", "free")
+	require.NoError(t, err)
+
+	for i := 1; i <= 20; i++ {
+		q, err := f.quotas.Consume(ctx, secret)
+		require.NoError(t, err)
+		assert.Equal(t, 20-i, q.Remaining())
+	}
+
+	q, err := f.quotas.Consume(ctx, secret)
+	assert.ErrorIs(t, err, usecase.ErrQuotaExceeded)
+	assert.Equal(t, 20, q.Limit, "the quota comes with the error, for the response headers")
+	assert.Zero(t, q.Remaining())
+	assert.Equal(t, domain.Day(f.now).AddDate(0, 0, 1), q.ResetAt)
+
+	_, q, err = f.quotas.Status(ctx, secret)
+	require.NoError(t, err)
+	assert.Equal(t, 20, q.Used, "refused requests are not counted")
+}
+
+func TestConsumeRejectsUnknownAndRevokedKeys(t *testing.T) {
+	t.Parallel()
+	f := setup(t)
+	ctx := context.Background()
+
+	_, err := f.quotas.Consume(ctx, "")
+	assert.ErrorIs(t, err, usecase.ErrUnauthorized)
+	_, err = f.quotas.Consume(ctx, "not a key")
+	assert.ErrorIs(t, err, usecase.ErrUnauthorized)
+	_, err = f.quotas.Consume(ctx, domain.NewSecret())
+	assert.ErrorIs(t, err, usecase.ErrUnauthorized)
+
+	key, secret, err := f.keys.CreateKey(ctx, "Acme", "free")
+	require.NoError(t, err)
+	_, err = f.keys.RevokeKey(ctx, key.ID)
+	require.NoError(t, err)
+
+	_, err = f.quotas.Consume(ctx, secret)
+	assert.ErrorIs(t, err, usecase.ErrUnauthorized)
+	_, _, err = f.quotas.Status(ctx, secret)
+	assert.ErrorIs(t, err, usecase.ErrUnauthorized)
+}
+
+func TestConsumeStartsANewDayWithoutTheReset(t *testing.T) {
+	t.Parallel()
+	f := setup(t)
+	ctx := context.Background()
+
+	key, secret, err := f.keys.CreateKey(ctx, "Acme", "free")
+	require.NoError(t, err)
+	for range 20 {
+		_, err := f.quotas.Consume(ctx, secret)
+		require.NoError(t, err)
+	}
+	_, err = f.quotas.Consume(ctx, secret)
+	require.ErrorIs(t, err, usecase.ErrQuotaExceeded)
+
+	// Midnight passes, and the nightly reset has not run
+	f.now = f.now.AddDate(0, 0, 1)
+
+	q, err := f.quotas.Consume(ctx, secret)
+	require.NoError(t, err)
+	assert.Equal(t, 1, q.Used)
+
+	usage, err := f.repo.Usage(ctx, key.ID, domain.Day(f.now).AddDate(0, 0, -2))
+	require.NoError(t, err)
+	assert.Equal(t, []domain.DailyUsage{{Day: domain.Day(f.now).AddDate(0, 0, -1), Requests: 20}}, usage)
+}
+
+func TestReset(t *testing.T) {
+	t.Parallel()
+	f := setup(t)
+	ctx := context.Background()
+
+	_, secret, err := f.keys.CreateKey(ctx, "Acme", "pro")
+	require.NoError(t, err)
+	_, err = f.quotas.Consume(ctx, secret)
+	require.NoError(t, err)
+
+	n, err := f.quotas.Reset(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "the key started today already")
+
+	f.now = f.now.AddDate(0, 0, 1)
+	n, err = f.quotas.Reset(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, q, err := f.quotas.Status(ctx, secret)
+	require.NoError(t, err)
+	assert.Zero(t, q.Used)
+	assert.Equal(t, 1000, q.Remaining())
+}
@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/gostratum/examples/quota-demo/internal/domain"
+)
+
+// KeyRepository stores API keys, their tiers and their usage
+// This interface is owned by the use case layer (dependency inversion principle)
+type KeyRepository interface {
+	// Create returns domain.ErrInvalidInput when the key's tier does not exist
+	Create(ctx context.Context, key *domain.APIKey) error
+	FindByID(ctx context.Context, id string) (*domain.APIKey, error)
+	FindByHash(ctx context.Context, hash string) (*domain.APIKey, error)
+	List(ctx context.Context) ([]*domain.APIKey, error)
+	// SetTier returns domain.ErrInvalidInput when tier does not exist
+	SetTier(ctx context.Context, id, tier string) error
+	Revoke(ctx context.Context, id string, at time.Time) error
+	Tiers(ctx context.Context) ([]domain.Tier, error)
+
+	// Consume counts a request against the unrevoked key with hash if its
+	// period is day and it has quota left, and returns the key as counted.
+	// ok is false, and the key nil, when it did not count the request.
+	Consume(ctx context.Context, hash string, day time.Time) (key *domain.APIKey, ok bool, err error)
+	// Rollover adds the count of every key whose period is before day to its
+	// usage history, and starts day with nothing used. It returns how many
+	// keys it moved on.
+	Rollover(ctx context.Context, day time.Time) (int, error)
+	// RolloverKey does what Rollover does, for the key with id only
+	RolloverKey(ctx context.Context, id string, day time.Time) error
+	// Usage returns the key's past days with requests, from since on, oldest first
+	Usage(ctx context.Context, id string, since time.Time) ([]domain.DailyUsage, error)
+}
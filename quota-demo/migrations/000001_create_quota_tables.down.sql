@@ -0,0 +1,4 @@
+-- Drop quota tables
+DROP TABLE IF EXISTS daily_usage CASCADE;
+DROP TABLE IF EXISTS api_keys CASCADE;
+DROP TABLE IF EXISTS tiers CASCADE;
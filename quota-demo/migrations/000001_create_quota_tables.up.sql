@@ -0,0 +1,35 @@
+-- Create tiers table; the quotas are data, so they change without a deploy
+CREATE TABLE IF NOT EXISTS tiers (
+    name VARCHAR(32) PRIMARY KEY,
+    daily_quota INTEGER NOT NULL CHECK (daily_quota > 0)
+);
+
+INSERT INTO tiers (name, daily_quota) VALUES
+    ('free', 20),
+    ('pro', 1000),
+    ('enterprise', 100000)
+ON CONFLICT (name) DO NOTHING;
+
+-- Create api_keys table; used counts the requests of the day in period
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    prefix VARCHAR(16) NOT NULL,
+    tier VARCHAR(32) NOT NULL REFERENCES tiers (name),
+    used INTEGER NOT NULL DEFAULT 0 CHECK (used >= 0),
+    period DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMPTZ
+);
+
+-- The nightly reset looks for keys whose period has passed
+CREATE INDEX IF NOT EXISTS idx_api_keys_period ON api_keys (period);
+
+-- Create daily_usage table; one row per key and past day with requests
+CREATE TABLE IF NOT EXISTS daily_usage (
+    key_id UUID NOT NULL REFERENCES api_keys (id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    requests INTEGER NOT NULL CHECK (requests > 0),
+    PRIMARY KEY (key_id, day)
+);
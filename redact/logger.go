@@ -0,0 +1,72 @@
+package redact
+
+import (
+	"fmt"
+
+	"github.com/gostratum/core/logx"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Logger is a logx.Logger that masks the secrets of every line before the
+// logger it wraps writes it
+type Logger struct {
+	next logx.Logger
+}
+
+// NewLogger wraps next, so the secrets of its lines are masked
+func NewLogger(next logx.Logger) logx.Logger {
+	if l, ok := next.(*Logger); ok {
+		return l
+	}
+	return &Logger{next: next}
+}
+
+// Module decorates the application's logx.Logger with NewLogger, so every
+// component logs through it
+func Module() fx.Option {
+	return fx.Decorate(NewLogger)
+}
+
+func (l *Logger) Debug(msg string, fields ...logx.Field) {
+	l.next.Debug(String(msg), Fields(fields)...)
+}
+func (l *Logger) Info(msg string, fields ...logx.Field) { l.next.Info(String(msg), Fields(fields)...) }
+func (l *Logger) Warn(msg string, fields ...logx.Field) { l.next.Warn(String(msg), Fields(fields)...) }
+func (l *Logger) Error(msg string, fields ...logx.Field) {
+	l.next.Error(String(msg), Fields(fields)...)
+}
+
+// Fields masks the secrets of the string, error and fmt.Stringer values of
+// fields, with Value. logx fields are zap fields; a value with a secret
+// becomes a string field, and the others, numbers and the like, are kept as
+// they are. fields is not modified.
+func Fields(fields []logx.Field) []logx.Field {
+	var masked []logx.Field
+	for i, f := range fields {
+		var value string
+		switch f.Type {
+		case zapcore.StringType:
+			value = f.String
+		case zapcore.ErrorType:
+			value = f.Interface.(error).Error()
+		case zapcore.StringerType:
+			value = f.Interface.(fmt.Stringer).String()
+		default:
+			continue
+		}
+		redacted := Value(f.Key, value)
+		if redacted == value {
+			continue
+		}
+		if masked == nil {
+			masked = append([]logx.Field(nil), fields...)
+		}
+		masked[i] = zap.String(f.Key, redacted)
+	}
+	if masked == nil {
+		return fields
+	}
+	return masked
+}
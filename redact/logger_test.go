@@ -0,0 +1,83 @@
+package redact
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+// line is a line a captureLogger was given
+type line struct {
+	level  string
+	msg    string
+	fields map[string]any
+}
+
+// captureLogger keeps the lines it is given, with their fields encoded
+type captureLogger struct {
+	lines []line
+}
+
+func (l *captureLogger) Debug(msg string, fields ...logx.Field) { l.add("debug", msg, fields) }
+func (l *captureLogger) Info(msg string, fields ...logx.Field)  { l.add("info", msg, fields) }
+func (l *captureLogger) Warn(msg string, fields ...logx.Field)  { l.add("warn", msg, fields) }
+func (l *captureLogger) Error(msg string, fields ...logx.Field) { l.add("error", msg, fields) }
+
+func (l *captureLogger) add(level, msg string, fields []logx.Field) {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	l.lines = append(l.lines, line{level: level, msg: msg, fields: enc.Fields})
+}
+
+// stringer is a fmt.Stringer
+type stringer string
+
+func (s stringer) String() string { return string(s) }
+
+func TestLogger(t *testing.T) {
+	next := &captureLogger{}
+	log := NewLogger(next)
+
+	log.Info("connecting to postgres://app:hunter2@db/orders",
+		logx.String("dsn", "postgres://app:hunter2@db/orders"),
+		logx.String("email", "jane@example.com"),
+		logx.String("api_key", "sk_live_123"),
+		logx.Any("target", stringer("https://api.example.com?token=abc")),
+		logx.Int("attempt", 2),
+		logx.String("sku", "LAPTOP"))
+	log.Error("charge failed", logx.Err(errors.New("401 for Bearer abc123")))
+	log.Warn("nothing secret", logx.Err(errors.New("timeout")))
+	log.Debug("debug")
+
+	assert.Equal(t, []line{
+		{level: "info", msg: "connecting to postgres://app:***@db/orders", fields: map[string]any{
+			"dsn":     "postgres://app:***@db/orders",
+			"email":   "j***@example.com",
+			"api_key": Mask,
+			"target":  "https://api.example.com?token=***",
+			"attempt": int64(2),
+			"sku":     "LAPTOP",
+		}},
+		{level: "error", msg: "charge failed", fields: map[string]any{"error": "401 for Bearer ***"}},
+		{level: "warn", msg: "nothing secret", fields: map[string]any{"error": "timeout"}},
+		{level: "debug", msg: "debug", fields: map[string]any{}},
+	}, next.lines)
+
+	assert.Same(t, log, NewLogger(log), "a masking logger is not wrapped again")
+}
+
+func TestFields_KeepsInput(t *testing.T) {
+	fields := []logx.Field{logx.String("password", "hunter2")}
+
+	masked := Fields(fields)
+
+	assert.Equal(t, Mask, masked[0].String)
+	assert.Equal(t, "hunter2", fields[0].String, "the caller's fields are not modified")
+	clean := []logx.Field{logx.String("sku", "LAPTOP")}
+	assert.Equal(t, &clean[0], &Fields(clean)[0], "fields without secrets are not copied")
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/examples/redact"
+	httpAdapter "github.com/gostratum/examples/saga-demo/internal/adapter/http"
+	"github.com/gostratum/examples/saga-demo/internal/coordinator"
+	"github.com/gostratum/examples/saga-demo/internal/messaging"
+	"github.com/gostratum/httpx"
+)
+
+func main() {
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// HTTP API that starts orders and shows their sagas
+		httpx.Module(),
+
+		// NATS connection and stream; publishes payment commands and consumes
+		// the payment events through the reply handler below
+		messaging.Module(),
+
+		// Provide dependencies
+		fx.Provide(
+			coordinator.NewMemoryStore,
+			coordinator.NewStockClient,
+			coordinator.NewPaymentCommands,
+			coordinator.NewOrchestrator,
+			messaging.AsHandler(coordinator.NewReplyHandler),
+			httpAdapter.NewSagaHandler,
+		),
+
+		// Invoke setup functions
+		fx.Invoke(
+			coordinator.RegisterRecovery,
+			httpAdapter.RegisterCoordinatorRoutes,
+		),
+	)
+
+	app.Run()
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/examples/redact"
+	httpAdapter "github.com/gostratum/examples/saga-demo/internal/adapter/http"
+	"github.com/gostratum/examples/saga-demo/internal/messaging"
+	"github.com/gostratum/examples/saga-demo/internal/payments"
+	"github.com/gostratum/httpx"
+)
+
+func main() {
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// HTTP server for health probes only
+		httpx.Module(),
+
+		// NATS connection and stream; consumes payment commands and publishes
+		// their outcomes
+		messaging.Module(),
+
+		// Provide dependencies
+		fx.Provide(
+			payments.NewProcessor,
+			messaging.AsHandler(payments.NewCommandHandler),
+		),
+
+		// Invoke setup functions
+		fx.Invoke(
+			httpAdapter.RegisterHealthRoutes,
+		),
+	)
+
+	app.Run()
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/examples/redact"
+	httpAdapter "github.com/gostratum/examples/saga-demo/internal/adapter/http"
+	"github.com/gostratum/examples/saga-demo/internal/stock"
+	"github.com/gostratum/httpx"
+)
+
+func main() {
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// HTTP API for reservations; the coordinator calls it directly
+		httpx.Module(),
+
+		// Provide dependencies
+		fx.Provide(
+			stock.NewWarehouse,
+			httpAdapter.NewStockHandler,
+		),
+
+		// Invoke setup functions
+		fx.Invoke(
+			httpAdapter.RegisterStockRoutes,
+		),
+	)
+
+	app.Run()
+}
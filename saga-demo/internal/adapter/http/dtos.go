@@ -0,0 +1,128 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gostratum/examples/saga-demo/internal/saga"
+	"github.com/gostratum/examples/saga-demo/internal/stock"
+)
+
+// StartOrderRequest represents the request payload for placing an order
+type StartOrderRequest struct {
+	SKU      string `json:"sku" binding:"required"`
+	Quantity int    `json:"quantity" binding:"required"`
+	// Amount in minor units (cents)
+	Amount int64 `json:"amount" binding:"required"`
+}
+
+// SagaResponse represents a saga with its log in API responses
+type SagaResponse struct {
+	ID       string `json:"id"`
+	SKU      string `json:"sku"`
+	Quantity int    `json:"quantity"`
+	Amount   int64  `json:"amount"`
+	Status   string `json:"status"`
+	// Step is the step running or awaited; omitted once the saga has finished
+	Step     string `json:"step,omitempty"`
+	Attempts int    `json:"attempts,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	// Pending are the compensations left to run after Step
+	Pending   []string        `json:"pending,omitempty"`
+	Log       []EntryResponse `json:"log"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// EntryResponse represents a line of a saga's log
+type EntryResponse struct {
+	Step    string    `json:"step"`
+	Outcome string    `json:"outcome"`
+	Detail  string    `json:"detail,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+// FromSaga converts a saga to a response DTO
+func FromSaga(s *saga.Saga) SagaResponse {
+	resp := SagaResponse{
+		ID:        s.ID,
+		SKU:       s.SKU,
+		Quantity:  s.Quantity,
+		Amount:    s.Amount,
+		Status:    string(s.Status),
+		Step:      string(s.Step),
+		Attempts:  s.Attempts,
+		Reason:    s.Reason,
+		Log:       make([]EntryResponse, len(s.Log)),
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+	for _, step := range s.Pending {
+		resp.Pending = append(resp.Pending, string(step))
+	}
+	for i, e := range s.Log {
+		resp.Log[i] = EntryResponse{Step: string(e.Step), Outcome: string(e.Outcome), Detail: e.Detail, At: e.At}
+	}
+	return resp
+}
+
+// SagaSummaryResponse represents a saga without its log in lists
+type SagaSummaryResponse struct {
+	ID        string    `json:"id"`
+	SKU       string    `json:"sku"`
+	Status    string    `json:"status"`
+	Step      string    `json:"step,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FromSagaSummary converts a saga to a summary DTO
+func FromSagaSummary(s *saga.Saga) SagaSummaryResponse {
+	return SagaSummaryResponse{
+		ID:        s.ID,
+		SKU:       s.SKU,
+		Status:    string(s.Status),
+		Step:      string(s.Step),
+		Reason:    s.Reason,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+// ReserveRequest represents the request payload for reserving stock
+type ReserveRequest struct {
+	SagaID   string `json:"saga_id" binding:"required"`
+	SKU      string `json:"sku" binding:"required"`
+	Quantity int    `json:"quantity" binding:"required"`
+}
+
+// ReservationResponse represents a reservation in API responses
+type ReservationResponse struct {
+	SagaID    string     `json:"saga_id"`
+	SKU       string     `json:"sku,omitempty"`
+	Quantity  int        `json:"quantity,omitempty"`
+	Status    string     `json:"status"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// FromReservation converts a reservation to a response DTO
+func FromReservation(r stock.Reservation) ReservationResponse {
+	resp := ReservationResponse{SagaID: r.SagaID, SKU: r.SKU, Quantity: r.Quantity, Status: r.Status}
+	// Released reservations that were never made have no expiry
+	if !r.ExpiresAt.IsZero() {
+		resp.ExpiresAt = &r.ExpiresAt
+	}
+	return resp
+}
+
+// ItemResponse represents a SKU with its stock in API responses
+type ItemResponse struct {
+	SKU       string `json:"sku"`
+	OnHand    int    `json:"on_hand"`
+	Available int    `json:"available"`
+}
+
+// FromItem converts an item to a response DTO
+func FromItem(i stock.Item) ItemResponse {
+	return ItemResponse{SKU: i.SKU, OnHand: i.OnHand, Available: i.Available}
+}
@@ -0,0 +1,209 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/saga-demo/internal/coordinator"
+	"github.com/gostratum/examples/saga-demo/internal/saga"
+	"github.com/gostratum/examples/saga-demo/internal/stock"
+)
+
+var now = time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+
+// fakeSagas starts sagas that stop at the charge, or fails with err
+type fakeSagas struct {
+	err error
+}
+
+func (f *fakeSagas) Start(ctx context.Context, sku string, quantity int, amount int64) (*saga.Saga, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	s, err := saga.New("saga-1", sku, quantity, amount, now)
+	if err != nil {
+		return nil, err
+	}
+	s.Begin(now)
+	if err := s.Succeed(saga.StepReserveStock, "", now); err != nil {
+		return nil, err
+	}
+	s.Begin(now)
+	return s, nil
+}
+
+func (f *fakeSagas) Get(ctx context.Context, id string) (*saga.Saga, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.Start(ctx, "widget", 1, 100)
+}
+
+func (f *fakeSagas) List(ctx context.Context) ([]*saga.Saga, error) {
+	s, err := f.Get(ctx, "saga-1")
+	if err != nil {
+		return nil, err
+	}
+	return []*saga.Saga{s}, nil
+}
+
+// fakeWarehouse answers every reservation call with r, or fails with err
+type fakeWarehouse struct {
+	r   stock.Reservation
+	err error
+}
+
+func (f *fakeWarehouse) Reserve(ctx context.Context, sagaID, sku string, quantity int) (stock.Reservation, error) {
+	return f.r, f.err
+}
+
+func (f *fakeWarehouse) Confirm(ctx context.Context, sagaID string) (stock.Reservation, error) {
+	return f.r, f.err
+}
+
+func (f *fakeWarehouse) Release(ctx context.Context, sagaID string) (stock.Reservation, error) {
+	return f.r, f.err
+}
+
+func (f *fakeWarehouse) Items(ctx context.Context) []stock.Item {
+	return []stock.Item{{SKU: "widget", OnHand: 5, Available: 3}}
+}
+
+func setupRouter(sagas *fakeSagas, warehouse *fakeWarehouse) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	sagaHandler := newSagaHandler(sagas, logx.NewNoopLogger())
+	stockHandler := newStockHandler(warehouse, logx.NewNoopLogger())
+
+	e := gin.New()
+	e.POST("/orders", sagaHandler.StartOrder)
+	e.GET("/sagas", sagaHandler.ListSagas)
+	e.GET("/sagas/:id", sagaHandler.GetSaga)
+	e.GET("/items", stockHandler.ListItems)
+	e.POST("/reservations", stockHandler.Reserve)
+	e.POST("/reservations/:saga_id/confirm", stockHandler.Confirm)
+	e.POST("/reservations/:saga_id/release", stockHandler.Release)
+	return e
+}
+
+func do(e *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w
+}
+
+func TestStartOrder(t *testing.T) {
+	e := setupRouter(&fakeSagas{}, &fakeWarehouse{})
+
+	w := do(e, http.MethodPost, "/orders", `{"sku":"widget","quantity":2,"amount":2500}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, "/sagas/saga-1", w.Header().Get("Location"))
+
+	var resp responsex.Envelope[SagaResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "running", resp.Data.Status)
+	assert.Equal(t, "charge_payment", resp.Data.Step)
+	require.Len(t, resp.Data.Log, 3)
+	assert.Equal(t, EntryResponse{Step: "reserve_stock", Outcome: "succeeded", At: now}, resp.Data.Log[1])
+
+	w = do(e, http.MethodPost, "/orders", `{"sku":"widget","quantity":0,"amount":2500}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_REQUEST")
+
+	w = do(e, http.MethodPost, "/orders", `{"sku":"widget","quantity":-1,"amount":2500}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "quantity must be 1 to 1000")
+}
+
+func TestSagaErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantErr  string
+	}{
+		{name: "not found", err: coordinator.ErrNotFound, wantCode: http.StatusNotFound, wantErr: "NOT_FOUND"},
+		{name: "unavailable", err: fmt.Errorf("%w: store down", coordinator.ErrUnavailable), wantCode: http.StatusServiceUnavailable, wantErr: "SERVICE_UNAVAILABLE"},
+		{name: "unexpected", err: fmt.Errorf("boom"), wantCode: http.StatusInternalServerError, wantErr: "INTERNAL_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := setupRouter(&fakeSagas{err: tt.err}, &fakeWarehouse{})
+
+			w := do(e, http.MethodGet, "/sagas/saga-1", "")
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantErr)
+		})
+	}
+}
+
+func TestListSagas(t *testing.T) {
+	e := setupRouter(&fakeSagas{}, &fakeWarehouse{})
+
+	w := do(e, http.MethodGet, "/sagas", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp responsex.Envelope[[]map[string]any]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data, 1)
+	assert.Equal(t, "saga-1", resp.Data[0]["id"])
+	assert.NotContains(t, resp.Data[0], "log", "lists leave out the log")
+}
+
+func TestStockErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantErr  string
+	}{
+		{name: "ok", wantCode: http.StatusOK},
+		{name: "out of stock", err: fmt.Errorf("%w: 2 left", stock.ErrOutOfStock), wantCode: http.StatusConflict, wantErr: "OUT_OF_STOCK"},
+		{name: "expired", err: stock.ErrExpired, wantCode: http.StatusConflict, wantErr: "RESERVATION_EXPIRED"},
+		{name: "released", err: stock.ErrReleased, wantCode: http.StatusConflict, wantErr: "RESERVATION_RELEASED"},
+		{name: "confirmed", err: stock.ErrConfirmed, wantCode: http.StatusConflict, wantErr: "RESERVATION_CONFIRMED"},
+		{name: "not found", err: stock.ErrNotFound, wantCode: http.StatusNotFound, wantErr: "NOT_FOUND"},
+		{name: "invalid", err: stock.ErrInvalidInput, wantCode: http.StatusBadRequest, wantErr: "INVALID_INPUT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := stock.Reservation{SagaID: "saga-1", SKU: "widget", Quantity: 2, Status: stock.ReservationConfirmed, ExpiresAt: now}
+			e := setupRouter(&fakeSagas{}, &fakeWarehouse{r: r, err: tt.err})
+
+			w := do(e, http.MethodPost, "/reservations/saga-1/confirm", "")
+			assert.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			if tt.wantErr != "" {
+				assert.Contains(t, w.Body.String(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReserve(t *testing.T) {
+	e := setupRouter(&fakeSagas{}, &fakeWarehouse{r: stock.Reservation{SagaID: "saga-1", Status: stock.ReservationReleased}})
+
+	w := do(e, http.MethodPost, "/reservations", `{"saga_id":"saga-1","sku":"widget","quantity":2}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.NotContains(t, w.Body.String(), "expires_at")
+
+	w = do(e, http.MethodPost, "/reservations", `{"saga_id":"saga-1"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = do(e, http.MethodGet, "/items", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"available":3`)
+}
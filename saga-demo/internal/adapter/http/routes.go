@@ -0,0 +1,75 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+)
+
+// RegisterCoordinatorRoutes registers the order and saga API and the health endpoints
+// This function is designed to be used with fx.Invoke to work with httpx.Module
+func RegisterCoordinatorRoutes(e *gin.Engine, sagaHandler *SagaHandler, reg core.Registry, log logx.Logger) {
+	// Add responsex middleware for request tracking and metadata
+	e.Use(responsex.MetaMiddleware("saga-demo/v1.0.0"))
+
+	e.POST("/orders", sagaHandler.StartOrder)
+	e.GET("/sagas", sagaHandler.ListSagas)
+	e.GET("/sagas/:id", sagaHandler.GetSaga)
+
+	registerHealth(e, reg)
+	log.Info("HTTP routes registered")
+}
+
+// RegisterStockRoutes registers the stock service's API and the health endpoints
+// This function is designed to be used with fx.Invoke to work with httpx.Module
+func RegisterStockRoutes(e *gin.Engine, stockHandler *StockHandler, reg core.Registry, log logx.Logger) {
+	e.Use(responsex.MetaMiddleware("saga-demo/v1.0.0"))
+
+	e.GET("/items", stockHandler.ListItems)
+	e.POST("/reservations", stockHandler.Reserve)
+	e.POST("/reservations/:saga_id/confirm", stockHandler.Confirm)
+	e.POST("/reservations/:saga_id/release", stockHandler.Release)
+
+	registerHealth(e, reg)
+	log.Info("HTTP routes registered")
+}
+
+// RegisterHealthRoutes registers only the health endpoints, for the payments service
+// This function is designed to be used with fx.Invoke to work with httpx.Module
+func RegisterHealthRoutes(e *gin.Engine, reg core.Registry, log logx.Logger) {
+	registerHealth(e, reg)
+	log.Info("HTTP routes registered")
+}
+
+// registerHealth adds readiness and liveness checks; readiness includes the NATS
+// connection in the services that have one
+func registerHealth(e *gin.Engine, reg core.Registry) {
+	e.GET("/healthz", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		res := reg.Aggregate(ctx, core.Readiness)
+		if res.OK {
+			c.JSON(http.StatusOK, gin.H{"ok": true, "details": res.Details})
+		} else {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"ok": false, "details": res.Details})
+		}
+	})
+
+	e.GET("/livez", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		res := reg.Aggregate(ctx, core.Liveness)
+		if res.OK {
+			c.JSON(http.StatusOK, gin.H{"ok": true, "details": res.Details})
+		} else {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"ok": false, "details": res.Details})
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/saga-demo/internal/coordinator"
+	"github.com/gostratum/examples/saga-demo/internal/saga"
+)
+
+// Sagas starts and reads sagas; implemented by coordinator.Orchestrator
+type Sagas interface {
+	Start(ctx context.Context, sku string, quantity int, amount int64) (*saga.Saga, error)
+	Get(ctx context.Context, id string) (*saga.Saga, error)
+	List(ctx context.Context) ([]*saga.Saga, error)
+}
+
+// SagaHandler handles order and saga HTTP requests on the coordinator
+type SagaHandler struct {
+	sagas Sagas
+	log   logx.Logger
+}
+
+// NewSagaHandler creates a new saga handler
+func NewSagaHandler(orchestrator *coordinator.Orchestrator, log logx.Logger) *SagaHandler {
+	return newSagaHandler(orchestrator, log)
+}
+
+func newSagaHandler(sagas Sagas, log logx.Logger) *SagaHandler {
+	return &SagaHandler{sagas: sagas, log: log}
+}
+
+// StartOrder handles POST /orders. It answers once the saga waits for the
+// payment, or has already failed; GET /sagas/:id follows it from there.
+func (h *SagaHandler) StartOrder(c *gin.Context) {
+	var req StartOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid request payload", nil)
+		return
+	}
+
+	s, err := h.sagas.Start(c.Request.Context(), req.SKU, req.Quantity, req.Amount)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.Created(c, "/sagas/"+s.ID, FromSaga(s))
+}
+
+// GetSaga handles GET /sagas/:id
+func (h *SagaHandler) GetSaga(c *gin.Context) {
+	s, err := h.sagas.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.OK(c, FromSaga(s), nil)
+}
+
+// ListSagas handles GET /sagas
+func (h *SagaHandler) ListSagas(c *gin.Context) {
+	sagas, err := h.sagas.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	resp := make([]SagaSummaryResponse, len(sagas))
+	for i, s := range sagas {
+		resp[i] = FromSagaSummary(s)
+	}
+	responsex.OK(c, resp, nil)
+}
+
+// handleError maps coordinator errors to HTTP responses
+func (h *SagaHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, coordinator.ErrNotFound):
+		responsex.Error(c, http.StatusNotFound, "NOT_FOUND", "saga not found", nil)
+	case errors.Is(err, coordinator.ErrInvalid):
+		responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
+	case errors.Is(err, coordinator.ErrUnavailable):
+		c.Header("Retry-After", "2")
+		responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "service temporarily unavailable", nil)
+	default:
+		h.log.Error("unexpected error", logx.Err(err))
+		responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+	}
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/saga-demo/internal/stock"
+)
+
+// Warehouse reserves stock; implemented by stock.Warehouse
+type Warehouse interface {
+	Reserve(ctx context.Context, sagaID, sku string, quantity int) (stock.Reservation, error)
+	Confirm(ctx context.Context, sagaID string) (stock.Reservation, error)
+	Release(ctx context.Context, sagaID string) (stock.Reservation, error)
+	Items(ctx context.Context) []stock.Item
+}
+
+// StockHandler handles the stock service's HTTP requests
+type StockHandler struct {
+	warehouse Warehouse
+	log       logx.Logger
+}
+
+// NewStockHandler creates a new stock handler
+func NewStockHandler(warehouse *stock.Warehouse, log logx.Logger) *StockHandler {
+	return newStockHandler(warehouse, log)
+}
+
+func newStockHandler(warehouse Warehouse, log logx.Logger) *StockHandler {
+	return &StockHandler{warehouse: warehouse, log: log}
+}
+
+// ListItems handles GET /items
+func (h *StockHandler) ListItems(c *gin.Context) {
+	items := h.warehouse.Items(c.Request.Context())
+	resp := make([]ItemResponse, len(items))
+	for i, item := range items {
+		resp[i] = FromItem(item)
+	}
+	responsex.OK(c, resp, nil)
+}
+
+// Reserve handles POST /reservations. Reserving for a saga again answers with
+// its reservation.
+func (h *StockHandler) Reserve(c *gin.Context) {
+	var req ReserveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid request payload", nil)
+		return
+	}
+
+	r, err := h.warehouse.Reserve(c.Request.Context(), req.SagaID, req.SKU, req.Quantity)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.Created(c, "", FromReservation(r))
+}
+
+// Confirm handles POST /reservations/:saga_id/confirm
+func (h *StockHandler) Confirm(c *gin.Context) {
+	r, err := h.warehouse.Confirm(c.Request.Context(), c.Param("saga_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.OK(c, FromReservation(r), nil)
+}
+
+// Release handles POST /reservations/:saga_id/release
+func (h *StockHandler) Release(c *gin.Context) {
+	r, err := h.warehouse.Release(c.Request.Context(), c.Param("saga_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.OK(c, FromReservation(r), nil)
+}
+
+// handleError maps stock errors to HTTP responses. The messages say what went
+// wrong; the coordinator logs them with the saga.
+func (h *StockHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, stock.ErrOutOfStock):
+		responsex.Error(c, http.StatusConflict, "OUT_OF_STOCK", err.Error(), nil)
+	case errors.Is(err, stock.ErrExpired):
+		responsex.Error(c, http.StatusConflict, "RESERVATION_EXPIRED", err.Error(), nil)
+	case errors.Is(err, stock.ErrReleased):
+		responsex.Error(c, http.StatusConflict, "RESERVATION_RELEASED", err.Error(), nil)
+	case errors.Is(err, stock.ErrConfirmed):
+		responsex.Error(c, http.StatusConflict, "RESERVATION_CONFIRMED", err.Error(), nil)
+	case errors.Is(err, stock.ErrNotFound):
+		responsex.Error(c, http.StatusNotFound, "NOT_FOUND", err.Error(), nil)
+	case errors.Is(err, stock.ErrInvalidInput):
+		responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
+	default:
+		h.log.Error("unexpected error", logx.Err(err))
+		responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+	}
+}
@@ -0,0 +1,68 @@
+// Package contract defines the messages the coordinator and the payments service
+// exchange over NATS. Both sides import it, so they cannot drift apart.
+package contract
+
+import "strconv"
+
+// Commands are sent by the coordinator; the payments service answers each with
+// an event on the same stream
+const (
+	// SubjectCharge carries ChargePayment
+	SubjectCharge = "payments.commands.charge"
+	// SubjectRefund carries RefundPayment
+	SubjectRefund = "payments.commands.refund"
+
+	// SubjectCharged answers a charge that was taken, with a PaymentResult
+	SubjectCharged = "payments.events.charged"
+	// SubjectDeclined answers a charge that was not taken; Reason says why
+	SubjectDeclined = "payments.events.declined"
+	// SubjectRefunded answers a refund. It is also the answer when there was
+	// nothing to refund.
+	SubjectRefunded = "payments.events.refunded"
+
+	// Commands matches every command subject
+	Commands = "payments.commands.>"
+	// Events matches every event subject
+	Events = "payments.events.>"
+)
+
+// ChargePayment asks for the amount of a saga to be charged. The saga ID is the
+// idempotency key: charging a saga again answers with the first outcome.
+type ChargePayment struct {
+	SagaID string `json:"saga_id"`
+	// Amount in minor units (cents)
+	Amount int64 `json:"amount"`
+}
+
+// RefundPayment asks for the charge of a saga to be refunded. A refund that
+// arrives before its charge cancels the saga's payment, so the charge is
+// declined when it arrives later.
+type RefundPayment struct {
+	SagaID string `json:"saga_id"`
+}
+
+// PaymentResult answers a command
+type PaymentResult struct {
+	SagaID    string `json:"saga_id"`
+	PaymentID string `json:"payment_id,omitempty"`
+	// Reason a charge was declined
+	Reason string `json:"reason,omitempty"`
+}
+
+// ChargeID is the message ID of a saga's charge command; JetStream drops a
+// second publish with the same ID, so the coordinator can retry the publish
+func ChargeID(sagaID string) string {
+	return sagaID + ".charge"
+}
+
+// RefundID is the message ID of a saga's refund command. Each attempt gets its
+// own ID, so a refund sent again after a timeout is not dropped as a duplicate.
+func RefundID(sagaID string, attempt int) string {
+	return sagaID + ".refund." + strconv.Itoa(attempt)
+}
+
+// EventID is the message ID of the event answering a saga's command on subject.
+// A command delivered twice is answered twice; the second event is dropped.
+func EventID(sagaID, subject string) string {
+	return sagaID + "." + subject
+}
@@ -0,0 +1,304 @@
+package coordinator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gostratum/core/logx"
+
+	"github.com/gostratum/examples/saga-demo/internal/saga"
+)
+
+// stepTimeout bounds a step's call to a service; HTTP steps wait for their
+// answer, messaging steps only for the broker to store the command
+const stepTimeout = 5 * time.Second
+
+// maxListedSagas caps List
+const maxListedSagas = 100
+
+// awaited are the steps answered by a message rather than in the call
+var awaited = map[saga.Step]bool{
+	saga.StepChargePayment: true,
+	saga.StepRefundPayment: true,
+}
+
+// RecoveryPolicy decides what happens to sagas stuck on a step
+type RecoveryPolicy struct {
+	// StepTimeout: a step that started this long ago without an outcome is stuck
+	StepTimeout time.Duration
+	// MaxAttempts of a compensation before the saga is failed
+	MaxAttempts int
+}
+
+// Orchestrator moves sagas through their steps. Everything that moves a saga,
+// a new order, a payment reply or recovery, does so while holding the saga's
+// lock, so a saga takes one step at a time.
+type Orchestrator struct {
+	store    Store
+	stock    Stock
+	payments Payments
+	log      logx.Logger
+
+	locks [64]sync.Mutex
+	now   func() time.Time
+	newID func() string
+}
+
+// NewOrchestrator creates the orchestrator
+func NewOrchestrator(store Store, stock Stock, payments Payments, log logx.Logger) *Orchestrator {
+	return &Orchestrator{
+		store:    store,
+		stock:    stock,
+		payments: payments,
+		log:      log,
+		now:      time.Now,
+		newID:    uuid.NewString,
+	}
+}
+
+// Start creates a saga for an order and runs it up to its first message: the
+// charge. The saga is returned as it stands then; the rest happens as replies
+// arrive. A saga that failed and was compensated is returned too, not an error.
+func (o *Orchestrator) Start(ctx context.Context, sku string, quantity int, amount int64) (*saga.Saga, error) {
+	s, err := saga.New(o.newID(), sku, quantity, amount, o.now())
+	if err != nil {
+		return nil, err
+	}
+
+	// The saga runs on when the client disconnects, so it is not left half done
+	ctx = context.WithoutCancel(ctx)
+
+	unlock := o.lock(s.ID)
+	defer unlock()
+
+	if err := o.store.Create(ctx, s); err != nil {
+		return nil, translateError(err)
+	}
+	o.log.Info("saga started", logx.String("saga_id", s.ID), logx.String("sku", s.SKU))
+
+	if err := o.run(ctx, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Get returns a saga
+func (o *Orchestrator) Get(ctx context.Context, id string) (*saga.Saga, error) {
+	s, err := o.store.Get(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return s, nil
+}
+
+// List returns the most recent sagas
+func (o *Orchestrator) List(ctx context.Context) ([]*saga.Saga, error) {
+	sagas, err := o.store.List(ctx, maxListedSagas)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return sagas, nil
+}
+
+// Charged handles the payments service taking a saga's charge
+func (o *Orchestrator) Charged(ctx context.Context, sagaID, paymentID string) error {
+	return o.reply(ctx, sagaID, func(s *saga.Saga) error {
+		return s.Succeed(saga.StepChargePayment, "payment "+paymentID, o.now())
+	})
+}
+
+// Declined handles the payments service declining a saga's charge
+func (o *Orchestrator) Declined(ctx context.Context, sagaID, reason string) error {
+	return o.reply(ctx, sagaID, func(s *saga.Saga) error {
+		return s.Fail(saga.StepChargePayment, reason, o.now())
+	})
+}
+
+// Refunded handles the payments service refunding a saga's charge
+func (o *Orchestrator) Refunded(ctx context.Context, sagaID string) error {
+	return o.reply(ctx, sagaID, func(s *saga.Saga) error {
+		return s.Succeed(saga.StepRefundPayment, "", o.now())
+	})
+}
+
+// Recover moves on sagas stuck on a step for longer than p.StepTimeout. A stuck
+// forward step, a charge without reply, fails the saga, which compensates. A
+// stuck compensation, one that failed or whose reply is missing, is attempted
+// again, up to p.MaxAttempts, after which the saga is failed for an operator.
+// It returns how many sagas it moved.
+func (o *Orchestrator) Recover(ctx context.Context, p RecoveryPolicy) (int, error) {
+	sagas, err := o.store.Unfinished(ctx)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	deadline := o.now().Add(-p.StepTimeout)
+	moved := 0
+	for _, s := range sagas {
+		if s.StepStartedAt.After(deadline) {
+			continue
+		}
+		ok, err := o.recover(ctx, s.ID, deadline, p.MaxAttempts)
+		if err != nil {
+			return moved, err
+		}
+		if ok {
+			moved++
+		}
+	}
+	return moved, nil
+}
+
+// recover moves one stuck saga, if it is still stuck once locked
+func (o *Orchestrator) recover(ctx context.Context, id string, deadline time.Time, maxAttempts int) (bool, error) {
+	unlock := o.lock(id)
+	defer unlock()
+
+	s, err := o.store.Get(ctx, id)
+	if err != nil {
+		return false, translateError(err)
+	}
+	if s.Status.Finished() || s.StepStartedAt.After(deadline) {
+		return false, nil
+	}
+
+	fields := []logx.Field{logx.String("saga_id", s.ID), logx.String("step", string(s.Step)), logx.Int("attempts", s.Attempts)}
+	switch {
+	case s.Status == saga.StatusRunning:
+		o.log.Warn("saga step timed out", fields...)
+		if err := s.Fail(s.Step, fmt.Sprintf("%s timed out", s.Step), o.now()); err != nil {
+			return false, err
+		}
+	case s.Attempts >= maxAttempts:
+		o.log.Error("saga compensation keeps failing; an operator has to finish it", fields...)
+		s.GiveUp(fmt.Sprintf("%s failed %d times", s.Step, s.Attempts), o.now())
+		return true, o.save(ctx, s)
+	default:
+		o.log.Warn("attempting saga compensation again", fields...)
+	}
+
+	if err := o.save(ctx, s); err != nil {
+		return false, err
+	}
+	return true, o.run(ctx, s)
+}
+
+// reply applies a payment outcome to a saga and runs it on. Outcomes for steps
+// the saga is no longer at, or for sagas this coordinator does not know, are
+// dropped; the saga has moved on and compensates for them.
+func (o *Orchestrator) reply(ctx context.Context, id string, apply func(*saga.Saga) error) error {
+	unlock := o.lock(id)
+	defer unlock()
+
+	s, err := o.store.Get(ctx, id)
+	if errors.Is(err, saga.ErrNotFound) {
+		o.log.Warn("dropping reply for unknown saga", logx.String("saga_id", id))
+		return nil
+	}
+	if err != nil {
+		return translateError(err)
+	}
+
+	if err := apply(s); err != nil {
+		if errors.Is(err, saga.ErrStaleStep) {
+			o.log.Info("dropping late reply", logx.String("saga_id", id), logx.Err(err))
+			return nil
+		}
+		return err
+	}
+
+	if err := o.save(ctx, s); err != nil {
+		return err
+	}
+	return o.run(ctx, s)
+}
+
+// run executes steps until the saga finishes, waits for a reply, or a
+// compensation fails and waits for Recover. The saga's lock must be held.
+func (o *Orchestrator) run(ctx context.Context, s *saga.Saga) error {
+	for !s.Status.Finished() {
+		step := s.Begin(o.now())
+		if err := o.save(ctx, s); err != nil {
+			return err
+		}
+
+		err := o.execute(ctx, s, step)
+		switch {
+		case err != nil:
+			o.log.Warn("saga step failed", logx.String("saga_id", s.ID), logx.String("step", string(step)), logx.Err(err))
+			if err := s.Fail(step, err.Error(), o.now()); err != nil {
+				return err
+			}
+		case awaited[step]:
+			return nil
+		default:
+			if err := s.Succeed(step, "", o.now()); err != nil {
+				return err
+			}
+		}
+
+		if err := o.save(ctx, s); err != nil {
+			return err
+		}
+		if s.Step == step {
+			// A compensation failed; Recover attempts it again
+			return nil
+		}
+	}
+
+	o.log.Info("saga finished", logx.String("saga_id", s.ID), logx.String("status", string(s.Status)))
+	return nil
+}
+
+// execute calls the service of step
+func (o *Orchestrator) execute(ctx context.Context, s *saga.Saga, step saga.Step) error {
+	ctx, cancel := context.WithTimeout(ctx, stepTimeout)
+	defer cancel()
+
+	switch step {
+	case saga.StepReserveStock:
+		return o.stock.Reserve(ctx, s.ID, s.SKU, s.Quantity)
+	case saga.StepChargePayment:
+		return o.payments.RequestCharge(ctx, s.ID, s.Amount)
+	case saga.StepConfirmReservation:
+		return o.stock.Confirm(ctx, s.ID)
+	case saga.StepRefundPayment:
+		return o.payments.RequestRefund(ctx, s.ID, s.Attempts)
+	case saga.StepReleaseStock:
+		return o.stock.Release(ctx, s.ID)
+	default:
+		return fmt.Errorf("unknown step %q", step)
+	}
+}
+
+func (o *Orchestrator) save(ctx context.Context, s *saga.Saga) error {
+	if err := o.store.Update(ctx, s); err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// lock locks the saga with id and returns the unlock function. Sagas share a
+// fixed set of locks, so the set does not grow with the number of sagas.
+func (o *Orchestrator) lock(id string) func() {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	m := &o.locks[h.Sum32()%uint32(len(o.locks))]
+	m.Lock()
+	return m.Unlock
+}
+
+// translateError converts store errors to coordinator errors
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, saga.ErrNotFound), errors.Is(err, ErrUnavailable):
+		return err
+	default:
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+}
@@ -0,0 +1,268 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/saga-demo/internal/saga"
+)
+
+// fakeStock records the calls made to it and fails those in fail
+type fakeStock struct {
+	mu    sync.Mutex
+	calls []string
+	fail  map[string]error
+}
+
+func (f *fakeStock) call(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, name)
+	return f.fail[name]
+}
+
+func (f *fakeStock) Reserve(ctx context.Context, sagaID, sku string, quantity int) error {
+	return f.call("reserve")
+}
+
+func (f *fakeStock) Confirm(ctx context.Context, sagaID string) error {
+	return f.call("confirm")
+}
+
+func (f *fakeStock) Release(ctx context.Context, sagaID string) error {
+	return f.call("release")
+}
+
+// fakePayments records the commands sent; onCharge runs after a charge is sent
+type fakePayments struct {
+	mu       sync.Mutex
+	commands []string
+	onCharge func(sagaID string)
+}
+
+func (f *fakePayments) RequestCharge(ctx context.Context, sagaID string, amount int64) error {
+	f.mu.Lock()
+	f.commands = append(f.commands, fmt.Sprintf("charge %d", amount))
+	f.mu.Unlock()
+	if f.onCharge != nil {
+		f.onCharge(sagaID)
+	}
+	return nil
+}
+
+func (f *fakePayments) RequestRefund(ctx context.Context, sagaID string, attempt int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.commands = append(f.commands, fmt.Sprintf("refund #%d", attempt))
+	return nil
+}
+
+type fixture struct {
+	o        *Orchestrator
+	stock    *fakeStock
+	payments *fakePayments
+	now      time.Time
+}
+
+func setup() *fixture {
+	f := &fixture{
+		stock:    &fakeStock{fail: map[string]error{}},
+		payments: &fakePayments{},
+		now:      time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC),
+	}
+	f.o = NewOrchestrator(NewMemoryStore(), f.stock, f.payments, logx.NewNoopLogger())
+	f.o.now = func() time.Time { return f.now }
+	f.o.newID = func() string { return "saga-1" }
+	return f
+}
+
+func (f *fixture) start(t *testing.T) *saga.Saga {
+	t.Helper()
+	s, err := f.o.Start(context.Background(), "widget", 2, 2500)
+	require.NoError(t, err)
+	return s
+}
+
+func (f *fixture) saga(t *testing.T) *saga.Saga {
+	t.Helper()
+	s, err := f.o.Get(context.Background(), "saga-1")
+	require.NoError(t, err)
+	return s
+}
+
+var policy = RecoveryPolicy{StepTimeout: 30 * time.Second, MaxAttempts: 2}
+
+func TestSagaCompletes(t *testing.T) {
+	ctx := context.Background()
+	f := setup()
+
+	s := f.start(t)
+	assert.Equal(t, saga.StatusRunning, s.Status)
+	assert.Equal(t, saga.StepChargePayment, s.Step, "the saga waits for the payment reply")
+	assert.Equal(t, []string{"charge 2500"}, f.payments.commands)
+
+	require.NoError(t, f.o.Charged(ctx, "saga-1", "pay_1"))
+	s = f.saga(t)
+	assert.Equal(t, saga.StatusCompleted, s.Status)
+	assert.Equal(t, []string{"reserve", "confirm"}, f.stock.calls)
+	assert.Contains(t, s.Log, saga.Entry{Step: saga.StepChargePayment, Outcome: saga.OutcomeSucceeded, Detail: "payment pay_1", At: f.now})
+}
+
+func TestOutOfStockIsCompensated(t *testing.T) {
+	f := setup()
+	f.stock.fail["reserve"] = fmt.Errorf("%w: out of stock", ErrRejected)
+
+	s := f.start(t)
+	assert.Equal(t, saga.StatusCompensated, s.Status)
+	assert.Contains(t, s.Reason, "out of stock")
+	assert.Equal(t, []string{"reserve", "release"}, f.stock.calls)
+	assert.Empty(t, f.payments.commands)
+}
+
+func TestDeclinedPaymentIsCompensated(t *testing.T) {
+	ctx := context.Background()
+	f := setup()
+	f.start(t)
+
+	require.NoError(t, f.o.Declined(ctx, "saga-1", "amount exceeds the card limit"))
+	s := f.saga(t)
+	assert.Equal(t, saga.StatusCompensating, s.Status)
+	assert.Equal(t, saga.StepRefundPayment, s.Step)
+	assert.Equal(t, []string{"charge 2500", "refund #1"}, f.payments.commands)
+
+	require.NoError(t, f.o.Refunded(ctx, "saga-1"))
+	s = f.saga(t)
+	assert.Equal(t, saga.StatusCompensated, s.Status)
+	assert.Equal(t, "amount exceeds the card limit", s.Reason)
+	assert.Equal(t, []string{"reserve", "release"}, f.stock.calls)
+}
+
+func TestFailedConfirmIsCompensated(t *testing.T) {
+	ctx := context.Background()
+	f := setup()
+	f.stock.fail["confirm"] = fmt.Errorf("%w: reservation expired", ErrRejected)
+	f.start(t)
+
+	require.NoError(t, f.o.Charged(ctx, "saga-1", "pay_1"))
+	require.NoError(t, f.o.Refunded(ctx, "saga-1"))
+
+	s := f.saga(t)
+	assert.Equal(t, saga.StatusCompensated, s.Status)
+	assert.Contains(t, s.Reason, "reservation expired")
+	assert.Equal(t, []string{"reserve", "confirm", "release"}, f.stock.calls)
+	assert.Equal(t, []string{"charge 2500", "refund #1"}, f.payments.commands)
+}
+
+func TestMissingPaymentReplyTimesOut(t *testing.T) {
+	ctx := context.Background()
+	f := setup()
+	f.start(t)
+
+	moved, err := f.o.Recover(ctx, policy)
+	require.NoError(t, err)
+	assert.Zero(t, moved, "the charge has not timed out yet")
+
+	f.now = f.now.Add(31 * time.Second)
+	moved, err = f.o.Recover(ctx, policy)
+	require.NoError(t, err)
+	assert.Equal(t, 1, moved)
+	s := f.saga(t)
+	assert.Equal(t, saga.StepRefundPayment, s.Step)
+	assert.Equal(t, "charge_payment timed out", s.Reason)
+
+	// The charge went through after all; the refund takes care of it
+	require.NoError(t, f.o.Charged(ctx, "saga-1", "pay_1"))
+	assert.Equal(t, saga.StepRefundPayment, f.saga(t).Step, "a late reply is dropped")
+
+	require.NoError(t, f.o.Refunded(ctx, "saga-1"))
+	assert.Equal(t, saga.StatusCompensated, f.saga(t).Status)
+}
+
+func TestFailingCompensationIsRetriedThenGivenUp(t *testing.T) {
+	ctx := context.Background()
+	f := setup()
+	f.stock.fail["release"] = fmt.Errorf("%w: connection refused", ErrUnavailable)
+	f.start(t)
+	require.NoError(t, f.o.Declined(ctx, "saga-1", "declined"))
+
+	// The refund reply is missing too, so the refund is sent again
+	f.now = f.now.Add(31 * time.Second)
+	_, err := f.o.Recover(ctx, policy)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"charge 2500", "refund #1", "refund #2"}, f.payments.commands)
+
+	require.NoError(t, f.o.Refunded(ctx, "saga-1"))
+	s := f.saga(t)
+	assert.Equal(t, saga.StepReleaseStock, s.Step)
+	assert.Equal(t, 1, s.Attempts)
+
+	f.now = f.now.Add(31 * time.Second)
+	_, err = f.o.Recover(ctx, policy)
+	require.NoError(t, err)
+	assert.Equal(t, 2, f.saga(t).Attempts)
+
+	f.now = f.now.Add(31 * time.Second)
+	_, err = f.o.Recover(ctx, policy)
+	require.NoError(t, err)
+	s = f.saga(t)
+	assert.Equal(t, saga.StatusFailed, s.Status)
+	assert.Equal(t, "release_stock failed 2 times", s.Reason)
+	assert.Equal(t, []string{"reserve", "release", "release"}, f.stock.calls)
+}
+
+func TestReplyDuringStartWaitsForIt(t *testing.T) {
+	f := setup()
+
+	// The payments service answers before Start has finished with the saga
+	var wg sync.WaitGroup
+	f.payments.onCharge = func(sagaID string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, f.o.Charged(context.Background(), sagaID, "pay_1"))
+		}()
+	}
+
+	f.start(t)
+	wg.Wait()
+	assert.Equal(t, saga.StatusCompleted, f.saga(t).Status)
+}
+
+func TestRepliesForUnknownSagasAreDropped(t *testing.T) {
+	f := setup()
+	assert.NoError(t, f.o.Charged(context.Background(), "saga-2", "pay_1"))
+}
+
+func TestStartRejectsInvalidOrders(t *testing.T) {
+	f := setup()
+
+	_, err := f.o.Start(context.Background(), "widget", 0, 2500)
+	assert.ErrorIs(t, err, ErrInvalid)
+
+	_, err = f.o.Get(context.Background(), "saga-1")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestList(t *testing.T) {
+	f := setup()
+	ids := []string{"saga-1", "saga-2", "saga-3"}
+	for _, id := range ids {
+		f.o.newID = func() string { return id }
+		f.now = f.now.Add(time.Second)
+		_, err := f.o.Start(context.Background(), "widget", 1, 100)
+		require.NoError(t, err)
+	}
+
+	sagas, err := f.o.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, sagas, 3)
+	assert.Equal(t, "saga-3", sagas[0].ID)
+	assert.Equal(t, "saga-1", sagas[2].ID)
+}
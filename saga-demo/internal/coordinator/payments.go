@@ -0,0 +1,99 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gostratum/core/logx"
+
+	"github.com/gostratum/examples/saga-demo/internal/contract"
+	"github.com/gostratum/examples/saga-demo/internal/messaging"
+)
+
+// Publisher publishes commands; implemented by messaging.Publisher
+type Publisher interface {
+	Publish(ctx context.Context, subject, id string, v any) (*messaging.Receipt, error)
+}
+
+// PaymentCommands sends payment commands over NATS
+type PaymentCommands struct {
+	publisher Publisher
+}
+
+// NewPaymentCommands creates the payment commands on the shared publisher
+func NewPaymentCommands(publisher *messaging.Publisher) Payments {
+	return &PaymentCommands{publisher: publisher}
+}
+
+// RequestCharge implements Payments
+func (p *PaymentCommands) RequestCharge(ctx context.Context, sagaID string, amount int64) error {
+	return p.send(ctx, contract.SubjectCharge, contract.ChargeID(sagaID), contract.ChargePayment{SagaID: sagaID, Amount: amount})
+}
+
+// RequestRefund implements Payments
+func (p *PaymentCommands) RequestRefund(ctx context.Context, sagaID string, attempt int) error {
+	return p.send(ctx, contract.SubjectRefund, contract.RefundID(sagaID, attempt), contract.RefundPayment{SagaID: sagaID})
+}
+
+func (p *PaymentCommands) send(ctx context.Context, subject, id string, cmd any) error {
+	if _, err := p.publisher.Publish(ctx, subject, id, cmd); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return nil
+}
+
+// Replies takes the outcomes of payment commands; implemented by Orchestrator
+type Replies interface {
+	Charged(ctx context.Context, sagaID, paymentID string) error
+	Declined(ctx context.Context, sagaID, reason string) error
+	Refunded(ctx context.Context, sagaID string) error
+}
+
+// ReplyHandler feeds the events of the payments service to the sagas
+type ReplyHandler struct {
+	replies Replies
+	log     logx.Logger
+}
+
+// NewReplyHandler creates the handler of the payment events
+func NewReplyHandler(orchestrator *Orchestrator, log logx.Logger) *ReplyHandler {
+	return newReplyHandler(orchestrator, log)
+}
+
+func newReplyHandler(replies Replies, log logx.Logger) *ReplyHandler {
+	return &ReplyHandler{replies: replies, log: log}
+}
+
+// Name implements messaging.Handler
+func (h *ReplyHandler) Name() string {
+	return "coordinator"
+}
+
+// Subject implements messaging.Handler
+func (h *ReplyHandler) Subject() string {
+	return contract.Events
+}
+
+// Handle implements messaging.Handler. A reply the saga store cannot take is
+// redelivered; replies the saga no longer waits for are acked and dropped.
+func (h *ReplyHandler) Handle(ctx context.Context, msg messaging.Message) error {
+	var result contract.PaymentResult
+	if err := msg.Decode(&result); err != nil {
+		return err
+	}
+	if result.SagaID == "" {
+		return messaging.Permanent(fmt.Errorf("%s without saga_id", msg.Subject))
+	}
+
+	switch msg.Subject {
+	case contract.SubjectCharged:
+		return h.replies.Charged(ctx, result.SagaID, result.PaymentID)
+	case contract.SubjectDeclined:
+		return h.replies.Declined(ctx, result.SagaID, result.Reason)
+	case contract.SubjectRefunded:
+		return h.replies.Refunded(ctx, result.SagaID)
+	default:
+		h.log.Warn("ignoring unknown payment event", logx.String("subject", msg.Subject))
+		return nil
+	}
+}
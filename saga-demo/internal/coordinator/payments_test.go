@@ -0,0 +1,90 @@
+package coordinator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gostratum/examples/saga-demo/internal/contract"
+	"github.com/gostratum/examples/saga-demo/internal/messaging"
+)
+
+// fakePublisher records message IDs by subject, failing while err is set
+type fakePublisher struct {
+	ids map[string][]string
+	err error
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, subject, id string, v any) (*messaging.Receipt, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.ids[subject] = append(p.ids[subject], id)
+	return &messaging.Receipt{}, nil
+}
+
+func TestPaymentCommands(t *testing.T) {
+	ctx := context.Background()
+	publisher := &fakePublisher{ids: map[string][]string{}}
+	p := &PaymentCommands{publisher: publisher}
+
+	assert.NoError(t, p.RequestCharge(ctx, "saga-1", 2500))
+	assert.NoError(t, p.RequestRefund(ctx, "saga-1", 1))
+	assert.NoError(t, p.RequestRefund(ctx, "saga-1", 2))
+	assert.Equal(t, map[string][]string{
+		contract.SubjectCharge: {"saga-1.charge"},
+		contract.SubjectRefund: {"saga-1.refund.1", "saga-1.refund.2"},
+	}, publisher.ids)
+
+	publisher.err = errors.New("nats: timeout")
+	assert.ErrorIs(t, p.RequestCharge(ctx, "saga-1", 2500), ErrUnavailable)
+}
+
+// fakeReplies records the replies it gets
+type fakeReplies struct {
+	got []string
+	err error
+}
+
+func (f *fakeReplies) Charged(ctx context.Context, sagaID, paymentID string) error {
+	f.got = append(f.got, "charged "+sagaID+" "+paymentID)
+	return f.err
+}
+
+func (f *fakeReplies) Declined(ctx context.Context, sagaID, reason string) error {
+	f.got = append(f.got, "declined "+sagaID+" "+reason)
+	return f.err
+}
+
+func (f *fakeReplies) Refunded(ctx context.Context, sagaID string) error {
+	f.got = append(f.got, "refunded "+sagaID)
+	return f.err
+}
+
+func TestReplyHandler(t *testing.T) {
+	ctx := context.Background()
+	replies := &fakeReplies{}
+	h := newReplyHandler(replies, logx.NewNoopLogger())
+
+	msg := func(subject, data string) messaging.Message {
+		return messaging.Message{Subject: subject, Data: []byte(data), Attempt: 1}
+	}
+
+	assert.NoError(t, h.Handle(ctx, msg(contract.SubjectCharged, `{"saga_id":"saga-1","payment_id":"pay_1"}`)))
+	assert.NoError(t, h.Handle(ctx, msg(contract.SubjectDeclined, `{"saga_id":"saga-2","reason":"card limit"}`)))
+	assert.NoError(t, h.Handle(ctx, msg(contract.SubjectRefunded, `{"saga_id":"saga-2"}`)))
+	assert.NoError(t, h.Handle(ctx, msg("payments.events.captured", `{"saga_id":"saga-2"}`)))
+	assert.Equal(t, []string{"charged saga-1 pay_1", "declined saga-2 card limit", "refunded saga-2"}, replies.got)
+
+	assert.ErrorIs(t, h.Handle(ctx, msg(contract.SubjectCharged, `{}`)), messaging.ErrPermanent)
+	assert.ErrorIs(t, h.Handle(ctx, msg(contract.SubjectCharged, `{not json`)), messaging.ErrPermanent)
+
+	// The saga store being down is worth a redelivery
+	replies.err = ErrUnavailable
+	err := h.Handle(ctx, msg(contract.SubjectRefunded, `{"saga_id":"saga-2"}`))
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.NotErrorIs(t, err, messaging.ErrPermanent)
+}
@@ -0,0 +1,53 @@
+// Package coordinator runs order sagas: it reserves stock in the stock service
+// over HTTP, charges the payment through the payments service over NATS, and
+// confirms the reservation, undoing what was done when a step fails.
+package coordinator
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gostratum/examples/saga-demo/internal/saga"
+)
+
+var (
+	// ErrNotFound wraps saga.ErrNotFound
+	ErrNotFound = saga.ErrNotFound
+
+	// ErrInvalid wraps saga.ErrInvalidInput
+	ErrInvalid = saga.ErrInvalidInput
+
+	// ErrUnavailable indicates a service, the broker or the saga store could not be reached
+	ErrUnavailable = errors.New("service unavailable")
+
+	// ErrRejected indicates a service refused a step, such as the stock service
+	// when it has not enough stock
+	ErrRejected = errors.New("step rejected")
+)
+
+// Stock calls the stock service. Every call is keyed by saga, so it can be retried.
+type Stock interface {
+	Reserve(ctx context.Context, sagaID, sku string, quantity int) error
+	Confirm(ctx context.Context, sagaID string) error
+	// Release succeeds for reservations that are released already, or were never made
+	Release(ctx context.Context, sagaID string) error
+}
+
+// Payments sends commands to the payments service. They return once the command
+// is stored by the broker; the outcome arrives later, through ReplyHandler.
+type Payments interface {
+	RequestCharge(ctx context.Context, sagaID string, amount int64) error
+	// RequestRefund sends attempt of a refund; each attempt is a new message
+	RequestRefund(ctx context.Context, sagaID string, attempt int) error
+}
+
+// Store keeps sagas. It hands out copies, so a saga changes only when it is updated.
+type Store interface {
+	Create(ctx context.Context, s *saga.Saga) error
+	Get(ctx context.Context, id string) (*saga.Saga, error)
+	Update(ctx context.Context, s *saga.Saga) error
+	// List returns up to limit sagas, the most recently created first
+	List(ctx context.Context, limit int) ([]*saga.Saga, error)
+	// Unfinished returns the sagas that are running or compensating
+	Unfinished(ctx context.Context) ([]*saga.Saga, error)
+}
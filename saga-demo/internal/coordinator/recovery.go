@@ -0,0 +1,82 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"go.uber.org/fx"
+)
+
+// RecoveryConfig tunes how sagas stuck on a step are moved on
+type RecoveryConfig struct {
+	// Interval between looks for stuck sagas
+	Interval time.Duration `mapstructure:"interval" default:"5s"`
+	// StepTimeout is how long a step may go without an outcome. It must be
+	// longer than the payments service takes to answer.
+	StepTimeout time.Duration `mapstructure:"step_timeout" default:"30s"`
+	// MaxAttempts of a compensation before the saga is failed for an operator
+	MaxAttempts int `mapstructure:"max_attempts" default:"5"`
+}
+
+// Prefix implements configx.Configurable
+func (RecoveryConfig) Prefix() string {
+	return "recovery"
+}
+
+// RegisterRecovery runs Orchestrator.Recover every interval while the
+// application runs.
+// This function is designed to be used with fx.Invoke.
+func RegisterRecovery(lc fx.Lifecycle, loader configx.Loader, orchestrator *Orchestrator, log logx.Logger) error {
+	var cfg RecoveryConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return fmt.Errorf("failed to load recovery config: %w", err)
+	}
+	if cfg.Interval <= 0 || cfg.StepTimeout <= 0 {
+		return fmt.Errorf("recovery.interval and recovery.step_timeout must be positive, got %s and %s", cfg.Interval, cfg.StepTimeout)
+	}
+	if cfg.MaxAttempts < 1 {
+		return fmt.Errorf("recovery.max_attempts must be at least 1, got %d", cfg.MaxAttempts)
+	}
+	policy := RecoveryPolicy{StepTimeout: cfg.StepTimeout, MaxAttempts: cfg.MaxAttempts}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(cfg.Interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+					}
+					// A pass that has begun finishes its steps; stopping the
+					// application waits for it
+					moved, err := orchestrator.Recover(context.Background(), policy)
+					if err != nil {
+						log.Error("failed to recover sagas", logx.Err(err))
+					} else if moved > 0 {
+						log.Info("recovered sagas", logx.Int("sagas", moved))
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-stopCtx.Done():
+				return stopCtx.Err()
+			}
+		},
+	})
+	return nil
+}
@@ -0,0 +1,102 @@
+package coordinator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/httpx/responsex"
+)
+
+// StockConfig locates the stock service
+type StockConfig struct {
+	BaseURL string        `mapstructure:"base_url" default:"http://localhost:8102"`
+	Timeout time.Duration `mapstructure:"timeout" default:"2s"`
+}
+
+// Prefix implements configx.Configurable
+func (StockConfig) Prefix() string {
+	return "stock"
+}
+
+// StockClient calls the stock service's reservation endpoints
+type StockClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+// NewStockClient creates the stock client from the stock config section
+func NewStockClient(loader configx.Loader) (Stock, error) {
+	var cfg StockConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load stock config: %w", err)
+	}
+	return newStockClient(cfg.BaseURL, &http.Client{Timeout: cfg.Timeout}), nil
+}
+
+func newStockClient(baseURL string, client *http.Client) *StockClient {
+	return &StockClient{baseURL: strings.TrimSuffix(baseURL, "/"), http: client}
+}
+
+type reserveRequest struct {
+	SagaID   string `json:"saga_id"`
+	SKU      string `json:"sku"`
+	Quantity int    `json:"quantity"`
+}
+
+// Reserve implements Stock via POST /reservations
+func (c *StockClient) Reserve(ctx context.Context, sagaID, sku string, quantity int) error {
+	return c.call(ctx, "/reservations", reserveRequest{SagaID: sagaID, SKU: sku, Quantity: quantity})
+}
+
+// Confirm implements Stock via POST /reservations/:saga_id/confirm
+func (c *StockClient) Confirm(ctx context.Context, sagaID string) error {
+	return c.call(ctx, "/reservations/"+url.PathEscape(sagaID)+"/confirm", nil)
+}
+
+// Release implements Stock via POST /reservations/:saga_id/release
+func (c *StockClient) Release(ctx context.Context, sagaID string) error {
+	return c.call(ctx, "/reservations/"+url.PathEscape(sagaID)+"/release", nil)
+}
+
+// call posts body to path. 4xx answers are the stock service refusing the
+// step; anything else that is not a success may pass when tried again.
+func (c *StockClient) call(ctx context.Context, path string, body any) error {
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			return fmt.Errorf("failed to encode stock request: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, &payload)
+	if err != nil {
+		return fmt.Errorf("failed to create stock request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		var envelope responsex.Envelope[any]
+		if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil || envelope.Error == nil {
+			return fmt.Errorf("%w: stock returned status %d", ErrRejected, resp.StatusCode)
+		}
+		return fmt.Errorf("%w: %s", ErrRejected, envelope.Error.Message)
+	default:
+		return fmt.Errorf("%w: stock returned status %d", ErrUnavailable, resp.StatusCode)
+	}
+}
@@ -0,0 +1,59 @@
+package coordinator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStockClient(t *testing.T) {
+	var got []string
+	var reserve reserveRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/reservations":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&reserve))
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"data":{"status":"held"}}`))
+		case "/reservations/saga-1/confirm":
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error":{"code":"RESERVATION_EXPIRED","message":"reservation expired"}}`))
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+	c := newStockClient(server.URL+"/", server.Client())
+	ctx := context.Background()
+
+	require.NoError(t, c.Reserve(ctx, "saga-1", "widget", 2))
+	assert.Equal(t, reserveRequest{SagaID: "saga-1", SKU: "widget", Quantity: 2}, reserve)
+
+	err := c.Confirm(ctx, "saga-1")
+	assert.ErrorIs(t, err, ErrRejected)
+	assert.ErrorContains(t, err, "reservation expired")
+
+	err = c.Release(ctx, "saga-1")
+	assert.ErrorIs(t, err, ErrUnavailable, "a 5xx may pass when tried again")
+
+	assert.Equal(t, []string{
+		"POST /reservations",
+		"POST /reservations/saga-1/confirm",
+		"POST /reservations/saga-1/release",
+	}, got)
+}
+
+func TestStockClientUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	err := newStockClient(server.URL, http.DefaultClient).Release(context.Background(), "saga-1")
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
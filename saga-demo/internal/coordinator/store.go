@@ -0,0 +1,84 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/gostratum/examples/saga-demo/internal/saga"
+)
+
+// MemoryStore keeps sagas in memory. Sagas in flight are lost when the
+// coordinator stops; a store in a database would let Recover pick them up
+// after a restart.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	sagas map[string]*saga.Saga
+}
+
+// NewMemoryStore creates an empty store
+func NewMemoryStore() Store {
+	return &MemoryStore{sagas: make(map[string]*saga.Saga)}
+}
+
+// Create implements Store
+func (m *MemoryStore) Create(ctx context.Context, s *saga.Saga) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.sagas[s.ID]; ok {
+		return fmt.Errorf("saga %s exists already", s.ID)
+	}
+	m.sagas[s.ID] = s.Clone()
+	return nil
+}
+
+// Get implements Store
+func (m *MemoryStore) Get(ctx context.Context, id string) (*saga.Saga, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, ok := m.sagas[id]
+	if !ok {
+		return nil, saga.ErrNotFound
+	}
+	return s.Clone(), nil
+}
+
+// Update implements Store
+func (m *MemoryStore) Update(ctx context.Context, s *saga.Saga) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.sagas[s.ID]; !ok {
+		return saga.ErrNotFound
+	}
+	m.sagas[s.ID] = s.Clone()
+	return nil
+}
+
+// List implements Store
+func (m *MemoryStore) List(ctx context.Context, limit int) ([]*saga.Saga, error) {
+	sagas := m.filter(func(*saga.Saga) bool { return true })
+	slices.SortFunc(sagas, func(a, b *saga.Saga) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	return sagas[:min(limit, len(sagas))], nil
+}
+
+// Unfinished implements Store
+func (m *MemoryStore) Unfinished(ctx context.Context) ([]*saga.Saga, error) {
+	return m.filter(func(s *saga.Saga) bool { return !s.Status.Finished() }), nil
+}
+
+func (m *MemoryStore) filter(keep func(*saga.Saga) bool) []*saga.Saga {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var sagas []*saga.Saga
+	for _, s := range m.sagas {
+		if keep(s) {
+			sagas = append(sagas, s.Clone())
+		}
+	}
+	return sagas
+}
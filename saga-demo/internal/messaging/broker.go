@@ -0,0 +1,127 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.uber.org/fx"
+)
+
+// Broker owns the NATS connection and the JetStream context shared by the
+// publisher and all consumers
+type Broker struct {
+	cfg  Config
+	log  logx.Logger
+	conn atomic.Pointer[nats.Conn]
+	js   jetstream.JetStream
+}
+
+// NewBroker creates the broker from the messaging config section. It connects and
+// declares the stream on start, and drains the connection on stop, after the
+// consumers registered later have stopped.
+func NewBroker(lc fx.Lifecycle, loader configx.Loader, reg core.Registry, log logx.Logger) (*Broker, error) {
+	var cfg Config
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load messaging config: %w", err)
+	}
+	if cfg.Consumer.AckWait <= 0 {
+		return nil, fmt.Errorf("messaging.consumer.ack_wait must be positive, got %s", cfg.Consumer.AckWait)
+	}
+	if cfg.Consumer.MaxDeliver < 1 {
+		return nil, fmt.Errorf("messaging.consumer.max_deliver must be at least 1, got %d", cfg.Consumer.MaxDeliver)
+	}
+
+	b := &Broker{cfg: cfg, log: log}
+	reg.Register(&connectionCheck{broker: b})
+	lc.Append(fx.Hook{
+		OnStart: b.start,
+		OnStop:  b.stop,
+	})
+	return b, nil
+}
+
+// JetStream returns the JetStream context; it is set once the application has started
+func (b *Broker) JetStream() jetstream.JetStream {
+	return b.js
+}
+
+func (b *Broker) start(ctx context.Context) error {
+	nc, err := nats.Connect(b.cfg.URL,
+		nats.Name(b.cfg.Name),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			b.log.Warn("disconnected from NATS", logx.Err(err))
+		}),
+		nats.ReconnectHandler(func(nc *nats.Conn) {
+			b.log.Info("reconnected to NATS", logx.String("url", nc.ConnectedUrl()))
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NATS at %s: %w", b.cfg.URL, err)
+	}
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		nc.Close()
+		return fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:       b.cfg.Stream,
+		Subjects:   []string{b.cfg.Subjects},
+		MaxAge:     b.cfg.MaxAge,
+		Duplicates: b.cfg.DuplicateWindow,
+	})
+	if err != nil {
+		nc.Close()
+		return fmt.Errorf("failed to create stream %s: %w", b.cfg.Stream, err)
+	}
+
+	b.js = js
+	b.conn.Store(nc)
+	b.log.Info("connected to NATS",
+		logx.String("url", nc.ConnectedUrl()),
+		logx.String("stream", b.cfg.Stream),
+	)
+	return nil
+}
+
+// stop drains the connection so publishes in flight complete
+func (b *Broker) stop(ctx context.Context) error {
+	nc := b.conn.Load()
+	if nc == nil {
+		return nil
+	}
+	return nc.Drain()
+}
+
+// connectionCheck reports not ready while the NATS connection is down
+type connectionCheck struct {
+	broker *Broker
+}
+
+func (c *connectionCheck) Name() string {
+	return "nats"
+}
+
+func (c *connectionCheck) Kind() core.Kind {
+	return core.Readiness
+}
+
+func (c *connectionCheck) Check(ctx context.Context) error {
+	nc := c.broker.conn.Load()
+	if nc == nil {
+		return errors.New("not connected yet")
+	}
+	if status := nc.Status(); status != nats.CONNECTED {
+		return fmt.Errorf("connection is %s", status)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.uber.org/fx"
+)
+
+// HeaderContentType is set on every published message
+const HeaderContentType = "Content-Type"
+
+// handlersGroup is the fx value group consumers are created for
+const handlersGroup = `group:"messaging.handlers"`
+
+// Handler processes the messages of one durable consumer
+type Handler interface {
+	// Name is the durable consumer name. Every instance running a handler with
+	// the same name shares one consumer, so each message is handled once per name.
+	Name() string
+	// Subject filters the stream down to the messages this handler receives
+	Subject() string
+	// Handle processes a message. Returning nil acks it; errors wrapped with
+	// Permanent terminate it; any other error has it redelivered with backoff.
+	// A message can be delivered more than once, so Handle must be idempotent.
+	Handle(ctx context.Context, msg Message) error
+}
+
+// AsHandler annotates a constructor so its result joins the handler group that
+// RegisterConsumers creates consumers for:
+//
+//	fx.Provide(messaging.AsHandler(payments.NewCommandHandler))
+func AsHandler(constructor any) any {
+	return fx.Annotate(constructor, fx.As(new(Handler)), fx.ResultTags(handlersGroup))
+}
+
+// Message is a delivered message
+type Message struct {
+	Subject string
+	Data    []byte
+	// Attempt is 1 on first delivery and counts up with every redelivery
+	Attempt int
+}
+
+// Decode unmarshals the JSON payload into v. A payload that cannot be decoded
+// will never succeed, so the error is permanent.
+func (m Message) Decode(v any) error {
+	if err := json.Unmarshal(m.Data, v); err != nil {
+		return Permanent(fmt.Errorf("malformed message on %s: %w", m.Subject, err))
+	}
+	return nil
+}
+
+// ErrPermanent marks failures that retrying cannot fix
+var ErrPermanent = errors.New("permanent failure")
+
+// Permanent marks err as not worth retrying; the message is terminated
+func Permanent(err error) error {
+	return fmt.Errorf("%w: %w", ErrPermanent, err)
+}
@@ -0,0 +1,15 @@
+package messaging
+
+import "go.uber.org/fx"
+
+// Module provides the broker and the publisher, and creates a durable consumer
+// for every handler provided with AsHandler
+func Module() fx.Option {
+	return fx.Module("messaging",
+		fx.Provide(
+			NewBroker,
+			NewPublisher,
+		),
+		fx.Invoke(RegisterConsumers),
+	)
+}
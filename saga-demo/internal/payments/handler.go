@@ -0,0 +1,97 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gostratum/core/logx"
+
+	"github.com/gostratum/examples/saga-demo/internal/contract"
+	"github.com/gostratum/examples/saga-demo/internal/messaging"
+)
+
+// Publisher publishes the events answering commands; implemented by messaging.Publisher
+type Publisher interface {
+	Publish(ctx context.Context, subject, id string, v any) (*messaging.Receipt, error)
+}
+
+// CommandHandler runs payment commands and answers each with an event
+type CommandHandler struct {
+	processor *Processor
+	publisher Publisher
+	log       logx.Logger
+}
+
+// NewCommandHandler creates the handler of the payment commands
+func NewCommandHandler(processor *Processor, publisher *messaging.Publisher, log logx.Logger) *CommandHandler {
+	return newCommandHandler(processor, publisher, log)
+}
+
+func newCommandHandler(processor *Processor, publisher Publisher, log logx.Logger) *CommandHandler {
+	return &CommandHandler{processor: processor, publisher: publisher, log: log}
+}
+
+// Name implements messaging.Handler
+func (h *CommandHandler) Name() string {
+	return "payments"
+}
+
+// Subject implements messaging.Handler
+func (h *CommandHandler) Subject() string {
+	return contract.Commands
+}
+
+// Handle implements messaging.Handler. If the answer cannot be published, the
+// command is redelivered and run again, which the processor answers the same way.
+func (h *CommandHandler) Handle(ctx context.Context, msg messaging.Message) error {
+	switch msg.Subject {
+	case contract.SubjectCharge:
+		var cmd contract.ChargePayment
+		if err := msg.Decode(&cmd); err != nil {
+			return err
+		}
+		return h.charge(ctx, cmd)
+	case contract.SubjectRefund:
+		var cmd contract.RefundPayment
+		if err := msg.Decode(&cmd); err != nil {
+			return err
+		}
+		return h.refund(ctx, cmd)
+	default:
+		return messaging.Permanent(fmt.Errorf("unknown command %s", msg.Subject))
+	}
+}
+
+func (h *CommandHandler) charge(ctx context.Context, cmd contract.ChargePayment) error {
+	payment, err := h.processor.Charge(ctx, cmd.SagaID, cmd.Amount)
+	switch {
+	case errors.Is(err, ErrDeclined):
+		h.log.Info("charge declined", logx.String("saga_id", cmd.SagaID), logx.Err(err))
+		return h.answer(ctx, contract.SubjectDeclined, contract.PaymentResult{SagaID: cmd.SagaID, Reason: err.Error()})
+	case errors.Is(err, ErrInvalidInput):
+		return messaging.Permanent(err)
+	case err != nil:
+		return err
+	}
+
+	h.log.Info("charged", logx.String("saga_id", cmd.SagaID), logx.String("payment_id", payment.ID))
+	return h.answer(ctx, contract.SubjectCharged, contract.PaymentResult{SagaID: cmd.SagaID, PaymentID: payment.ID})
+}
+
+func (h *CommandHandler) refund(ctx context.Context, cmd contract.RefundPayment) error {
+	payment, err := h.processor.Refund(ctx, cmd.SagaID)
+	if err != nil {
+		return messaging.Permanent(err)
+	}
+
+	h.log.Info("refunded", logx.String("saga_id", cmd.SagaID), logx.String("status", payment.Status))
+	return h.answer(ctx, contract.SubjectRefunded, contract.PaymentResult{SagaID: cmd.SagaID, PaymentID: payment.ID})
+}
+
+func (h *CommandHandler) answer(ctx context.Context, subject string, result contract.PaymentResult) error {
+	if _, err := h.publisher.Publish(ctx, subject, contract.EventID(result.SagaID, subject), result); err != nil {
+		return fmt.Errorf("failed to answer saga %s: %w", result.SagaID, err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/saga-demo/internal/contract"
+	"github.com/gostratum/examples/saga-demo/internal/messaging"
+)
+
+// published is a message the handler published
+type published struct {
+	Subject string
+	ID      string
+	Result  contract.PaymentResult
+}
+
+// fakePublisher records what is published, failing while err is set
+type fakePublisher struct {
+	messages []published
+	err      error
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, subject, id string, v any) (*messaging.Receipt, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.messages = append(p.messages, published{Subject: subject, ID: id, Result: v.(contract.PaymentResult)})
+	return &messaging.Receipt{Stream: "PAYMENTS", Sequence: uint64(len(p.messages))}, nil
+}
+
+func setup() (*CommandHandler, *fakePublisher) {
+	publisher := &fakePublisher{}
+	processor := newProcessor(Config{DeclineOver: 100000})
+	processor.newID = func() string { return "pay_1" }
+	return newCommandHandler(processor, publisher, logx.NewNoopLogger()), publisher
+}
+
+func command(t *testing.T, subject string, v any) messaging.Message {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return messaging.Message{Subject: subject, Data: data, Attempt: 1}
+}
+
+func TestChargeAndRefund(t *testing.T) {
+	ctx := context.Background()
+	h, publisher := setup()
+
+	charge := command(t, contract.SubjectCharge, contract.ChargePayment{SagaID: "saga-1", Amount: 2500})
+	require.NoError(t, h.Handle(ctx, charge))
+	require.NoError(t, h.Handle(ctx, charge), "a redelivered charge is answered again")
+	require.NoError(t, h.Handle(ctx, command(t, contract.SubjectRefund, contract.RefundPayment{SagaID: "saga-1"})))
+
+	assert.Equal(t, []published{
+		{Subject: contract.SubjectCharged, ID: "saga-1.payments.events.charged", Result: contract.PaymentResult{SagaID: "saga-1", PaymentID: "pay_1"}},
+		{Subject: contract.SubjectCharged, ID: "saga-1.payments.events.charged", Result: contract.PaymentResult{SagaID: "saga-1", PaymentID: "pay_1"}},
+		{Subject: contract.SubjectRefunded, ID: "saga-1.payments.events.refunded", Result: contract.PaymentResult{SagaID: "saga-1", PaymentID: "pay_1"}},
+	}, publisher.messages)
+
+	// The refunded payment is not charged again
+	require.NoError(t, h.Handle(ctx, charge))
+	assert.Equal(t, contract.SubjectDeclined, publisher.messages[3].Subject)
+}
+
+func TestChargeOverTheLimitIsDeclined(t *testing.T) {
+	h, publisher := setup()
+
+	err := h.Handle(context.Background(), command(t, contract.SubjectCharge, contract.ChargePayment{SagaID: "saga-1", Amount: 100001}))
+	require.NoError(t, err)
+	require.Len(t, publisher.messages, 1)
+	assert.Equal(t, contract.SubjectDeclined, publisher.messages[0].Subject)
+	assert.Contains(t, publisher.messages[0].Result.Reason, "exceeds the card limit")
+}
+
+func TestRefundOvertakesCharge(t *testing.T) {
+	ctx := context.Background()
+	h, publisher := setup()
+
+	// The coordinator gave up waiting for the charge and refunded it before
+	// the charge command was processed
+	require.NoError(t, h.Handle(ctx, command(t, contract.SubjectRefund, contract.RefundPayment{SagaID: "saga-1"})))
+	require.NoError(t, h.Handle(ctx, command(t, contract.SubjectCharge, contract.ChargePayment{SagaID: "saga-1", Amount: 2500})))
+
+	require.Len(t, publisher.messages, 2)
+	assert.Equal(t, contract.SubjectRefunded, publisher.messages[0].Subject)
+	assert.Equal(t, contract.SubjectDeclined, publisher.messages[1].Subject)
+	assert.Contains(t, publisher.messages[1].Result.Reason, "was cancelled")
+}
+
+func TestHandleErrors(t *testing.T) {
+	ctx := context.Background()
+	h, publisher := setup()
+
+	err := h.Handle(ctx, messaging.Message{Subject: contract.SubjectCharge, Data: []byte(`{not json`)})
+	assert.ErrorIs(t, err, messaging.ErrPermanent)
+
+	err = h.Handle(ctx, command(t, contract.SubjectCharge, contract.ChargePayment{SagaID: "saga-1"}))
+	assert.ErrorIs(t, err, messaging.ErrPermanent, "a charge without an amount is never valid")
+
+	err = h.Handle(ctx, command(t, "payments.commands.capture", contract.RefundPayment{SagaID: "saga-1"}))
+	assert.ErrorIs(t, err, messaging.ErrPermanent)
+
+	// An answer that cannot be published is retried
+	publisher.err = errors.New("nats: timeout")
+	err = h.Handle(ctx, command(t, contract.SubjectCharge, contract.ChargePayment{SagaID: "saga-1", Amount: 2500}))
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, messaging.ErrPermanent)
+}
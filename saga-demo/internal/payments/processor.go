@@ -0,0 +1,148 @@
+// Package payments is the payments service: it takes charge and refund commands
+// from NATS, runs them against a simulated card processor and answers with an
+// event. Payments are kept in memory.
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gostratum/core/configx"
+)
+
+var (
+	// ErrInvalidInput indicates a malformed command
+	ErrInvalidInput = errors.New("invalid input")
+
+	// ErrDeclined indicates the charge was not taken
+	ErrDeclined = errors.New("payment declined")
+)
+
+// Config tunes the simulated processor
+type Config struct {
+	// DeclineOver declines charges above this amount in minor units; 0 never declines
+	DeclineOver int64 `mapstructure:"decline_over" default:"100000"`
+	// Latency delays every charge, as a real processor would. Raise it above
+	// the stock service's reservation_ttl to watch reservations expire.
+	Latency time.Duration `mapstructure:"latency" default:"200ms"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "payments"
+}
+
+// Payment statuses
+const (
+	PaymentCharged  = "charged"
+	PaymentRefunded = "refunded"
+	// PaymentCancelled is a refund that arrived before its charge
+	PaymentCancelled = "cancelled"
+)
+
+// Payment is the money taken, or not, for a saga
+type Payment struct {
+	ID     string
+	SagaID string
+	Amount int64
+	Status string
+}
+
+// Processor charges and refunds sagas. Payments are keyed by saga, so a command
+// delivered twice is answered the same way twice.
+type Processor struct {
+	cfg   Config
+	newID func() string
+
+	mu       sync.Mutex
+	payments map[string]*Payment
+}
+
+// NewProcessor creates the processor from the payments config section
+func NewProcessor(loader configx.Loader) (*Processor, error) {
+	var cfg Config
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load payments config: %w", err)
+	}
+	return newProcessor(cfg), nil
+}
+
+func newProcessor(cfg Config) *Processor {
+	return &Processor{
+		cfg:      cfg,
+		newID:    func() string { return "pay_" + uuid.New().String() },
+		payments: make(map[string]*Payment),
+	}
+}
+
+// Charge takes amount for a saga, or declines it
+func (p *Processor) Charge(ctx context.Context, sagaID string, amount int64) (Payment, error) {
+	sagaID = strings.TrimSpace(sagaID)
+	if sagaID == "" || amount < 1 {
+		return Payment{}, fmt.Errorf("%w: saga_id and a positive amount are required", ErrInvalidInput)
+	}
+
+	if err := p.wait(ctx); err != nil {
+		return Payment{}, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if existing, ok := p.payments[sagaID]; ok {
+		if existing.Status != PaymentCharged {
+			return Payment{}, fmt.Errorf("%w: the payment of saga %s was %s", ErrDeclined, sagaID, existing.Status)
+		}
+		return *existing, nil
+	}
+	if p.cfg.DeclineOver > 0 && amount > p.cfg.DeclineOver {
+		return Payment{}, fmt.Errorf("%w: amount %d exceeds the card limit", ErrDeclined, amount)
+	}
+
+	payment := &Payment{ID: p.newID(), SagaID: sagaID, Amount: amount, Status: PaymentCharged}
+	p.payments[sagaID] = payment
+	return *payment, nil
+}
+
+// Refund returns the charge of a saga. Without a charge there is nothing to
+// return; the saga's payment is cancelled, so a charge still on its way is
+// declined when it arrives.
+func (p *Processor) Refund(ctx context.Context, sagaID string) (Payment, error) {
+	sagaID = strings.TrimSpace(sagaID)
+	if sagaID == "" {
+		return Payment{}, fmt.Errorf("%w: saga_id is required", ErrInvalidInput)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[sagaID]
+	switch {
+	case !ok:
+		payment = &Payment{SagaID: sagaID, Status: PaymentCancelled}
+		p.payments[sagaID] = payment
+	case payment.Status == PaymentCharged:
+		payment.Status = PaymentRefunded
+	}
+	return *payment, nil
+}
+
+// wait sleeps for the simulated latency
+func (p *Processor) wait(ctx context.Context) error {
+	if p.cfg.Latency <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(p.cfg.Latency)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,253 @@
+// Package saga is the state machine of an order saga: which step runs next,
+// and which compensations undo the steps taken when one fails. It knows nothing
+// about the services that run the steps.
+package saga
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound indicates a saga does not exist
+	ErrNotFound = errors.New("saga not found")
+
+	// ErrInvalidInput indicates an order the saga cannot start with
+	ErrInvalidInput = errors.New("invalid input")
+
+	// ErrStaleStep indicates an outcome for a step the saga is no longer at,
+	// such as a payment reply that arrived after the saga gave up waiting
+	ErrStaleStep = errors.New("saga is not at this step")
+)
+
+// Limits of an order
+const (
+	MaxQuantity = 1000
+	// MaxAmount is 1,000,000.00 in minor units
+	MaxAmount    = 1_000_000_00
+	maxSKULength = 64
+)
+
+// Status of a saga
+type Status string
+
+const (
+	// StatusRunning: the forward steps are running
+	StatusRunning Status = "running"
+	// StatusCompensating: a step failed; the steps taken are being undone
+	StatusCompensating Status = "compensating"
+	// StatusCompleted: every step succeeded
+	StatusCompleted Status = "completed"
+	// StatusCompensated: a step failed and every step taken was undone
+	StatusCompensated Status = "compensated"
+	// StatusFailed: a compensation kept failing; an operator has to finish it
+	StatusFailed Status = "failed"
+)
+
+// Finished reports whether the saga has stopped moving
+func (s Status) Finished() bool {
+	return s == StatusCompleted || s == StatusCompensated || s == StatusFailed
+}
+
+// Step of a saga
+type Step string
+
+// Forward steps, in order
+const (
+	StepReserveStock       Step = "reserve_stock"
+	StepChargePayment      Step = "charge_payment"
+	StepConfirmReservation Step = "confirm_reservation"
+)
+
+// Compensations
+const (
+	StepRefundPayment Step = "refund_payment"
+	StepReleaseStock  Step = "release_stock"
+)
+
+// forward lists the forward steps in order
+var forward = []Step{StepReserveStock, StepChargePayment, StepConfirmReservation}
+
+// compensations maps forward steps to the step that undoes them. Confirming the
+// reservation has none: once it succeeds the saga has succeeded, so it is the
+// last step, and it is also the one most likely to fail, as the reservation may
+// have expired while the payment was taken.
+var compensations = map[Step]Step{
+	StepReserveStock:  StepReleaseStock,
+	StepChargePayment: StepRefundPayment,
+}
+
+// Outcome of a step, as logged
+type Outcome string
+
+const (
+	OutcomeStarted   Outcome = "started"
+	OutcomeSucceeded Outcome = "succeeded"
+	OutcomeFailed    Outcome = "failed"
+)
+
+// Entry is one line of a saga's log
+type Entry struct {
+	Step    Step
+	Outcome Outcome
+	Detail  string
+	At      time.Time
+}
+
+// Saga is an order going through the steps of reserving its stock, charging its
+// payment and confirming its reservation.
+// This is a pure domain model without infrastructure concerns
+type Saga struct {
+	ID       string
+	SKU      string
+	Quantity int
+	// Amount in minor units (cents)
+	Amount int64
+
+	Status Status
+	// Step is the step running or awaited; empty once the saga has finished
+	Step Step
+	// Attempts of Step so far
+	Attempts int
+	// StepStartedAt is when the latest attempt of Step started
+	StepStartedAt time.Time
+	// Reason the saga is compensating or failed
+	Reason string
+	// Pending are the compensations left to run after Step
+	Pending []Step
+	Log     []Entry
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// New creates a saga for an order, at its first step
+func New(id, sku string, quantity int, amount int64, now time.Time) (*Saga, error) {
+	sku = strings.TrimSpace(sku)
+	switch {
+	case sku == "" || len(sku) > maxSKULength:
+		return nil, fmt.Errorf("%w: sku must be 1 to %d characters", ErrInvalidInput, maxSKULength)
+	case quantity < 1 || quantity > MaxQuantity:
+		return nil, fmt.Errorf("%w: quantity must be 1 to %d", ErrInvalidInput, MaxQuantity)
+	case amount < 1 || amount > MaxAmount:
+		return nil, fmt.Errorf("%w: amount must be 1 to %d", ErrInvalidInput, MaxAmount)
+	}
+
+	return &Saga{
+		ID:        id,
+		SKU:       sku,
+		Quantity:  quantity,
+		Amount:    amount,
+		Status:    StatusRunning,
+		Step:      forward[0],
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// Begin starts an attempt of the current step and returns the step
+func (s *Saga) Begin(now time.Time) Step {
+	s.Attempts++
+	s.StepStartedAt = now
+	s.log(s.Step, OutcomeStarted, "", now)
+	return s.Step
+}
+
+// Succeed records that step succeeded and moves on to the next step, or
+// finishes the saga
+func (s *Saga) Succeed(step Step, detail string, now time.Time) error {
+	if err := s.at(step); err != nil {
+		return err
+	}
+	s.log(step, OutcomeSucceeded, detail, now)
+
+	if s.Status == StatusCompensating {
+		s.next(StatusCompensated)
+		return nil
+	}
+
+	i := slices.Index(forward, step)
+	if i == len(forward)-1 {
+		s.moveTo("")
+		s.Status = StatusCompleted
+		return nil
+	}
+	s.moveTo(forward[i+1])
+	return nil
+}
+
+// Fail records that step failed. A failed forward step starts compensating:
+// the steps taken so far are undone in reverse order, the failed step included,
+// as a step that timed out may have succeeded without the saga hearing of it.
+// A failed compensation stays the current step, to be attempted again.
+func (s *Saga) Fail(step Step, reason string, now time.Time) error {
+	if err := s.at(step); err != nil {
+		return err
+	}
+	s.log(step, OutcomeFailed, reason, now)
+
+	if s.Status == StatusCompensating {
+		return nil
+	}
+
+	s.Status = StatusCompensating
+	s.Reason = reason
+	s.Pending = nil
+	for _, taken := range slices.Backward(forward[:slices.Index(forward, step)+1]) {
+		if c, ok := compensations[taken]; ok {
+			s.Pending = append(s.Pending, c)
+		}
+	}
+	s.next(StatusCompensated)
+	return nil
+}
+
+// GiveUp stops a saga whose compensation keeps failing, leaving it to an operator
+func (s *Saga) GiveUp(reason string, now time.Time) {
+	s.log(s.Step, OutcomeFailed, "gave up: "+reason, now)
+	s.Status = StatusFailed
+	s.Reason = reason
+	s.Pending = nil
+	s.moveTo("")
+}
+
+// Clone returns a deep copy, so stored sagas are not changed through pointers
+// handed out
+func (s *Saga) Clone() *Saga {
+	c := *s
+	c.Pending = slices.Clone(s.Pending)
+	c.Log = slices.Clone(s.Log)
+	return &c
+}
+
+// at checks that the saga is running step
+func (s *Saga) at(step Step) error {
+	if s.Status.Finished() || s.Step != step {
+		return fmt.Errorf("%w: saga %s is at %q, not %q", ErrStaleStep, s.ID, s.Step, step)
+	}
+	return nil
+}
+
+// next moves to the first pending compensation, or finishes with status
+func (s *Saga) next(status Status) {
+	if len(s.Pending) == 0 {
+		s.moveTo("")
+		s.Status = status
+		return
+	}
+	s.moveTo(s.Pending[0])
+	s.Pending = s.Pending[1:]
+}
+
+func (s *Saga) moveTo(step Step) {
+	s.Step = step
+	s.Attempts = 0
+}
+
+func (s *Saga) log(step Step, outcome Outcome, detail string, now time.Time) {
+	s.Log = append(s.Log, Entry{Step: step, Outcome: outcome, Detail: detail, At: now})
+	s.UpdatedAt = now
+}
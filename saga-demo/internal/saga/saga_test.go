@@ -0,0 +1,162 @@
+package saga
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+
+func newSaga(t *testing.T) *Saga {
+	t.Helper()
+	s, err := New("saga-1", " widget ", 2, 2500, now)
+	require.NoError(t, err)
+	return s
+}
+
+// run begins the current step and settles it: ok succeeds it, anything else
+// fails it with that reason
+func run(t *testing.T, s *Saga, outcome string) Step {
+	t.Helper()
+	step := s.Begin(now)
+	if outcome == "ok" {
+		require.NoError(t, s.Succeed(step, "", now))
+	} else {
+		require.NoError(t, s.Fail(step, outcome, now))
+	}
+	return step
+}
+
+func TestNew(t *testing.T) {
+	s := newSaga(t)
+	assert.Equal(t, "widget", s.SKU)
+	assert.Equal(t, StatusRunning, s.Status)
+	assert.Equal(t, StepReserveStock, s.Step)
+
+	tests := []struct {
+		name     string
+		sku      string
+		quantity int
+		amount   int64
+	}{
+		{name: "no sku", sku: " ", quantity: 1, amount: 1},
+		{name: "long sku", sku: strings.Repeat("x", 65), quantity: 1, amount: 1},
+		{name: "no quantity", sku: "widget", quantity: 0, amount: 1},
+		{name: "too many", sku: "widget", quantity: MaxQuantity + 1, amount: 1},
+		{name: "free", sku: "widget", quantity: 1, amount: 0},
+		{name: "too expensive", sku: "widget", quantity: 1, amount: MaxAmount + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New("saga-1", tt.sku, tt.quantity, tt.amount, now)
+			assert.ErrorIs(t, err, ErrInvalidInput)
+		})
+	}
+}
+
+func TestSagaCompletes(t *testing.T) {
+	s := newSaga(t)
+
+	assert.Equal(t, StepReserveStock, run(t, s, "ok"))
+	assert.Equal(t, StepChargePayment, run(t, s, "ok"))
+	assert.Equal(t, StepConfirmReservation, run(t, s, "ok"))
+
+	assert.Equal(t, StatusCompleted, s.Status)
+	assert.Empty(t, s.Step)
+	assert.True(t, s.Status.Finished())
+	assert.Len(t, s.Log, 6)
+}
+
+func TestFailedStepIsCompensated(t *testing.T) {
+	tests := []struct {
+		name     string
+		outcomes []string
+		want     []Step
+	}{
+		{
+			name:     "reserve fails",
+			outcomes: []string{"out of stock"},
+			want:     []Step{StepReleaseStock},
+		},
+		{
+			name:     "charge fails",
+			outcomes: []string{"ok", "declined"},
+			want:     []Step{StepRefundPayment, StepReleaseStock},
+		},
+		{
+			name:     "confirm fails",
+			outcomes: []string{"ok", "ok", "reservation expired"},
+			want:     []Step{StepRefundPayment, StepReleaseStock},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSaga(t)
+			for _, outcome := range tt.outcomes {
+				run(t, s, outcome)
+			}
+			assert.Equal(t, StatusCompensating, s.Status)
+			assert.Equal(t, tt.outcomes[len(tt.outcomes)-1], s.Reason)
+
+			var compensated []Step
+			for !s.Status.Finished() {
+				compensated = append(compensated, run(t, s, "ok"))
+			}
+			assert.Equal(t, tt.want, compensated)
+			assert.Equal(t, StatusCompensated, s.Status)
+		})
+	}
+}
+
+func TestFailedCompensationIsAttemptedAgain(t *testing.T) {
+	s := newSaga(t)
+	run(t, s, "ok")
+	run(t, s, "declined")
+
+	run(t, s, "no reply")
+	assert.Equal(t, StepRefundPayment, s.Step, "a failed compensation stays the current step")
+	assert.Equal(t, 1, s.Attempts)
+	assert.Equal(t, "declined", s.Reason)
+
+	run(t, s, "ok")
+	assert.Equal(t, StepReleaseStock, s.Step)
+	assert.Zero(t, s.Attempts)
+
+	run(t, s, "connection refused")
+	s.GiveUp("release_stock failed 1 times", now)
+	assert.Equal(t, StatusFailed, s.Status)
+	assert.Empty(t, s.Step)
+	assert.Equal(t, "release_stock failed 1 times", s.Reason)
+}
+
+func TestStaleOutcomesAreRejected(t *testing.T) {
+	s := newSaga(t)
+	run(t, s, "ok")
+	s.Begin(now)
+
+	// The charge timed out and the saga moved on before the reply arrived
+	require.NoError(t, s.Fail(StepChargePayment, "charge_payment timed out", now))
+	assert.ErrorIs(t, s.Succeed(StepChargePayment, "payment p1", now), ErrStaleStep)
+	assert.ErrorIs(t, s.Fail(StepChargePayment, "declined", now), ErrStaleStep)
+
+	for !s.Status.Finished() {
+		run(t, s, "ok")
+	}
+	assert.ErrorIs(t, s.Succeed(StepReleaseStock, "", now), ErrStaleStep, "a finished saga takes no outcomes")
+}
+
+func TestClone(t *testing.T) {
+	s := newSaga(t)
+	run(t, s, "out of stock")
+
+	c := s.Clone()
+	run(t, c, "ok")
+	assert.Equal(t, StatusCompensating, s.Status)
+	assert.Len(t, s.Log, 2)
+	assert.Len(t, c.Log, 4)
+}
@@ -0,0 +1,241 @@
+// Package stock is the stock service: items on hand, and reservations holding
+// them for a saga until the saga confirms or releases them. Stock is kept in
+// memory; the saga only cares about the service's answers.
+package stock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gostratum/core/configx"
+)
+
+var (
+	// ErrNotFound indicates an unknown SKU or reservation
+	ErrNotFound = errors.New("not found")
+
+	// ErrInvalidInput indicates a malformed reservation
+	ErrInvalidInput = errors.New("invalid input")
+
+	// ErrOutOfStock indicates there is not enough stock available to reserve
+	ErrOutOfStock = errors.New("out of stock")
+
+	// ErrExpired indicates a reservation was not confirmed in time
+	ErrExpired = errors.New("reservation expired")
+
+	// ErrReleased indicates a reservation was released, possibly before it was made
+	ErrReleased = errors.New("reservation released")
+
+	// ErrConfirmed indicates a reservation was confirmed and can no longer be released
+	ErrConfirmed = errors.New("reservation confirmed")
+)
+
+// Config holds the stock the service starts with and how long it holds reservations
+type Config struct {
+	// ReservationTTL is how long a reservation holds its stock. An unconfirmed
+	// reservation expires after it, and its stock is available again.
+	ReservationTTL time.Duration `mapstructure:"reservation_ttl" default:"30s"`
+	// Items are the units on hand at startup, by SKU
+	Items map[string]int `mapstructure:"items"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "stock"
+}
+
+// Reservation statuses
+const (
+	ReservationHeld      = "held"
+	ReservationConfirmed = "confirmed"
+	ReservationReleased  = "released"
+	// ReservationExpired is reported for held reservations past ExpiresAt
+	ReservationExpired = "expired"
+)
+
+// Reservation holds units of a SKU for a saga
+type Reservation struct {
+	SagaID    string
+	SKU       string
+	Quantity  int
+	Status    string
+	ExpiresAt time.Time
+}
+
+// Item is a SKU with its stock
+type Item struct {
+	SKU    string
+	OnHand int
+	// Available is OnHand minus the units held by reservations that have not expired
+	Available int
+}
+
+// Warehouse keeps the stock and the reservations. Reservations are keyed by saga,
+// which makes every call idempotent: a saga retrying a call gets the answer of
+// the first.
+type Warehouse struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu           sync.Mutex
+	onHand       map[string]int
+	reservations map[string]*Reservation
+}
+
+// NewWarehouse creates the warehouse from the stock config section
+func NewWarehouse(loader configx.Loader) (*Warehouse, error) {
+	var cfg Config
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load stock config: %w", err)
+	}
+	if cfg.ReservationTTL <= 0 {
+		return nil, fmt.Errorf("stock.reservation_ttl must be positive, got %s", cfg.ReservationTTL)
+	}
+	for sku, units := range cfg.Items {
+		if units < 0 {
+			return nil, fmt.Errorf("stock.items.%s must not be negative, got %d", sku, units)
+		}
+	}
+	return newWarehouse(cfg, time.Now), nil
+}
+
+func newWarehouse(cfg Config, now func() time.Time) *Warehouse {
+	onHand := make(map[string]int, len(cfg.Items))
+	for sku, units := range cfg.Items {
+		onHand[sku] = units
+	}
+	return &Warehouse{
+		ttl:          cfg.ReservationTTL,
+		now:          now,
+		onHand:       onHand,
+		reservations: make(map[string]*Reservation),
+	}
+}
+
+// Reserve holds quantity units of sku for a saga until the reservation expires
+func (w *Warehouse) Reserve(ctx context.Context, sagaID, sku string, quantity int) (Reservation, error) {
+	sagaID, sku = strings.TrimSpace(sagaID), strings.TrimSpace(sku)
+	if sagaID == "" || sku == "" || quantity < 1 {
+		return Reservation{}, fmt.Errorf("%w: saga_id, sku and a positive quantity are required", ErrInvalidInput)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := w.now()
+
+	if r, ok := w.reservations[sagaID]; ok {
+		if r.Status == ReservationReleased {
+			// The saga gave up on the reservation before this request arrived
+			return Reservation{}, fmt.Errorf("%w: saga %s released it already", ErrReleased, sagaID)
+		}
+		return r.view(now), nil
+	}
+
+	if _, ok := w.onHand[sku]; !ok {
+		return Reservation{}, fmt.Errorf("%w: sku %q", ErrNotFound, sku)
+	}
+	if available := w.available(sku, now); available < quantity {
+		return Reservation{}, fmt.Errorf("%w: %d of %q left", ErrOutOfStock, available, sku)
+	}
+
+	r := &Reservation{
+		SagaID:    sagaID,
+		SKU:       sku,
+		Quantity:  quantity,
+		Status:    ReservationHeld,
+		ExpiresAt: now.Add(w.ttl),
+	}
+	w.reservations[sagaID] = r
+	return r.view(now), nil
+}
+
+// Confirm takes the units of a saga's reservation off the stock for good
+func (w *Warehouse) Confirm(ctx context.Context, sagaID string) (Reservation, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := w.now()
+
+	r, ok := w.reservations[sagaID]
+	if !ok {
+		return Reservation{}, fmt.Errorf("%w: no reservation for saga %s", ErrNotFound, sagaID)
+	}
+
+	switch r.view(now).Status {
+	case ReservationConfirmed:
+		return r.view(now), nil
+	case ReservationReleased:
+		return Reservation{}, fmt.Errorf("%w: saga %s released it", ErrReleased, sagaID)
+	case ReservationExpired:
+		return Reservation{}, fmt.Errorf("%w: it expired at %s", ErrExpired, r.ExpiresAt.Format(time.RFC3339))
+	}
+
+	r.Status = ReservationConfirmed
+	w.onHand[r.SKU] -= r.Quantity
+	return r.view(now), nil
+}
+
+// Release gives the units of a saga's reservation back. Releasing a reservation
+// that does not exist yet records it as released, so a reserve request that is
+// still on its way is refused when it arrives.
+func (w *Warehouse) Release(ctx context.Context, sagaID string) (Reservation, error) {
+	sagaID = strings.TrimSpace(sagaID)
+	if sagaID == "" {
+		return Reservation{}, fmt.Errorf("%w: saga_id is required", ErrInvalidInput)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := w.now()
+
+	r, ok := w.reservations[sagaID]
+	if !ok {
+		r = &Reservation{SagaID: sagaID, Status: ReservationReleased}
+		w.reservations[sagaID] = r
+		return r.view(now), nil
+	}
+
+	if r.Status == ReservationConfirmed {
+		return Reservation{}, fmt.Errorf("%w: saga %s confirmed it", ErrConfirmed, sagaID)
+	}
+	r.Status = ReservationReleased
+	return r.view(now), nil
+}
+
+// Items returns every SKU with its stock, ordered by SKU
+func (w *Warehouse) Items(ctx context.Context) []Item {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := w.now()
+
+	items := make([]Item, 0, len(w.onHand))
+	for sku, units := range w.onHand {
+		items = append(items, Item{SKU: sku, OnHand: units, Available: w.available(sku, now)})
+	}
+	slices.SortFunc(items, func(a, b Item) int { return strings.Compare(a.SKU, b.SKU) })
+	return items
+}
+
+// available returns the units of sku not held by reservations; w.mu must be held
+func (w *Warehouse) available(sku string, now time.Time) int {
+	held := 0
+	for _, r := range w.reservations {
+		if r.SKU == sku && r.view(now).Status == ReservationHeld {
+			held += r.Quantity
+		}
+	}
+	return w.onHand[sku] - held
+}
+
+// view returns a copy of the reservation with its status as of now
+func (r *Reservation) view(now time.Time) Reservation {
+	v := *r
+	if v.Status == ReservationHeld && !now.Before(v.ExpiresAt) {
+		v.Status = ReservationExpired
+	}
+	return v
+}
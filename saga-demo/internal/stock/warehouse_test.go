@@ -0,0 +1,119 @@
+package stock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clock is a time that tests move forward
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time { return c.now }
+
+func setup() (*Warehouse, *clock) {
+	c := &clock{now: time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)}
+	w := newWarehouse(Config{ReservationTTL: 30 * time.Second, Items: map[string]int{"widget": 5, "gadget": 1}}, c.Now)
+	return w, c
+}
+
+func TestReserveHoldsStock(t *testing.T) {
+	ctx := context.Background()
+	w, _ := setup()
+
+	r, err := w.Reserve(ctx, "saga-1", "widget", 3)
+	require.NoError(t, err)
+	assert.Equal(t, ReservationHeld, r.Status)
+
+	_, err = w.Reserve(ctx, "saga-2", "widget", 3)
+	assert.ErrorIs(t, err, ErrOutOfStock)
+	assert.ErrorContains(t, err, `2 of "widget" left`)
+
+	again, err := w.Reserve(ctx, "saga-1", "widget", 3)
+	require.NoError(t, err)
+	assert.Equal(t, r, again, "reserving for a saga again returns its reservation")
+
+	_, err = w.Reserve(ctx, "saga-3", "gizmo", 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = w.Reserve(ctx, "saga-3", "widget", 0)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	assert.Equal(t, []Item{
+		{SKU: "gadget", OnHand: 1, Available: 1},
+		{SKU: "widget", OnHand: 5, Available: 2},
+	}, w.Items(ctx))
+}
+
+func TestConfirm(t *testing.T) {
+	ctx := context.Background()
+	w, _ := setup()
+
+	_, err := w.Reserve(ctx, "saga-1", "widget", 3)
+	require.NoError(t, err)
+	r, err := w.Confirm(ctx, "saga-1")
+	require.NoError(t, err)
+	assert.Equal(t, ReservationConfirmed, r.Status)
+
+	_, err = w.Confirm(ctx, "saga-1")
+	assert.NoError(t, err, "confirming again is not an error")
+	assert.Equal(t, Item{SKU: "widget", OnHand: 2, Available: 2}, w.Items(ctx)[1])
+
+	_, err = w.Release(ctx, "saga-1")
+	assert.ErrorIs(t, err, ErrConfirmed)
+	_, err = w.Confirm(ctx, "saga-2")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestExpiredReservationCannotBeConfirmed(t *testing.T) {
+	ctx := context.Background()
+	w, c := setup()
+
+	_, err := w.Reserve(ctx, "saga-1", "gadget", 1)
+	require.NoError(t, err)
+	_, err = w.Reserve(ctx, "saga-2", "gadget", 1)
+	require.ErrorIs(t, err, ErrOutOfStock)
+
+	c.now = c.now.Add(30 * time.Second)
+	_, err = w.Confirm(ctx, "saga-1")
+	assert.ErrorIs(t, err, ErrExpired)
+
+	// The expired reservation holds nothing any more
+	_, err = w.Reserve(ctx, "saga-2", "gadget", 1)
+	assert.NoError(t, err)
+}
+
+func TestRelease(t *testing.T) {
+	ctx := context.Background()
+	w, _ := setup()
+
+	_, err := w.Reserve(ctx, "saga-1", "widget", 5)
+	require.NoError(t, err)
+	r, err := w.Release(ctx, "saga-1")
+	require.NoError(t, err)
+	assert.Equal(t, ReservationReleased, r.Status)
+	assert.Equal(t, 5, w.Items(ctx)[1].Available)
+
+	_, err = w.Release(ctx, "saga-1")
+	assert.NoError(t, err, "releasing again is not an error")
+	_, err = w.Confirm(ctx, "saga-1")
+	assert.ErrorIs(t, err, ErrReleased)
+}
+
+func TestReleaseOvertakesReserve(t *testing.T) {
+	ctx := context.Background()
+	w, _ := setup()
+
+	// The saga timed out on its reserve request and released; the request
+	// arrives afterwards
+	_, err := w.Release(ctx, "saga-1")
+	require.NoError(t, err)
+
+	_, err = w.Reserve(ctx, "saga-1", "widget", 1)
+	assert.ErrorIs(t, err, ErrReleased)
+	assert.Equal(t, 5, w.Items(ctx)[1].Available)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/examples/redact"
+	httpAdapter "github.com/gostratum/examples/scheduler-demo/internal/adapter/http"
+	memoryAdapter "github.com/gostratum/examples/scheduler-demo/internal/adapter/memory"
+	"github.com/gostratum/examples/scheduler-demo/internal/jobs"
+	"github.com/gostratum/examples/scheduler-demo/internal/leader"
+	"github.com/gostratum/examples/scheduler-demo/internal/scheduler"
+	"github.com/gostratum/examples/scheduler-demo/internal/usecase"
+	"github.com/gostratum/httpx"
+	"github.com/gostratum/metricsx"
+)
+
+func main() {
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// Prometheus metrics for job runs
+		metricsx.Module(),
+
+		// HTTP server for job status, manual runs and health probes
+		httpx.Module(),
+
+		// Elects one replica to run the jobs; before the scheduler, so it stops
+		// after the running jobs have finished
+		leader.Module(),
+
+		// Runs every job below on its schedule from the scheduler config section
+		scheduler.Module(),
+
+		// Provide dependencies
+		fx.Provide(
+			// In-memory stores
+			memoryAdapter.NewOrderStore,
+			memoryAdapter.NewChargeLedger,
+
+			// Usecase services
+			usecase.NewExpiryService,
+			usecase.NewReconciliationService,
+
+			// Only the elected replica runs the jobs
+			func(e *leader.Elector) scheduler.Leader { return e },
+
+			// Jobs join the scheduler.jobs group
+			scheduler.AsJob(jobs.NewOrderExpiryJob),
+			scheduler.AsJob(jobs.NewReconciliationJob),
+			scheduler.AsJob(jobs.NewTrafficJob),
+
+			// HTTP handlers
+			httpAdapter.NewJobHandler,
+			httpAdapter.NewLeaderHandler,
+		),
+
+		// Invoke setup functions
+		fx.Invoke(
+			httpAdapter.RegisterRoutes,
+		),
+	)
+
+	app.Run()
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gostratum/examples/scheduler-demo/internal/leader"
+	"github.com/gostratum/examples/scheduler-demo/internal/scheduler"
+)
+
+// JobResponse is the HTTP DTO for a job's status
+// This struct handles JSON serialization concerns for the HTTP layer
+type JobResponse struct {
+	Name     string     `json:"name"`
+	Schedule string     `json:"schedule"`
+	Timeout  string     `json:"timeout"`
+	Disabled bool       `json:"disabled"`
+	Running  bool       `json:"running"`
+	NextRun  *time.Time `json:"next_run,omitempty"`
+	LastRun  *LastRun   `json:"last_run,omitempty"`
+	// LastSuccess is when the last successful run started
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+}
+
+// LastRun describes the last finished run of a job
+type LastRun struct {
+	StartedAt  time.Time `json:"started_at"`
+	DurationMS int64     `json:"duration_ms"`
+	Result     string    `json:"result"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// FromJobStatus converts a scheduler.Status to JobResponse DTO
+func FromJobStatus(s scheduler.Status) *JobResponse {
+	resp := &JobResponse{
+		Name:        s.Name,
+		Schedule:    s.Schedule,
+		Timeout:     s.Timeout.String(),
+		Disabled:    s.Disabled,
+		Running:     s.Running,
+		NextRun:     optionalTime(s.NextRun),
+		LastSuccess: optionalTime(s.LastSuccess),
+	}
+	if !s.LastRun.IsZero() {
+		resp.LastRun = &LastRun{
+			StartedAt:  s.LastRun,
+			DurationMS: s.LastDuration.Milliseconds(),
+			Result:     string(s.LastResult),
+			Error:      s.LastError,
+		}
+	}
+	return resp
+}
+
+// optionalTime omits zero times from responses
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// LeaderResponse is the HTTP DTO for the leader election as a replica sees it
+type LeaderResponse struct {
+	Enabled bool   `json:"enabled"`
+	ID      string `json:"id"`
+	Leader  bool   `json:"leader"`
+	Holder  string `json:"holder,omitempty"`
+}
+
+// FromLeaderStatus converts a leader.Status to LeaderResponse DTO
+func FromLeaderStatus(s leader.Status) *LeaderResponse {
+	return &LeaderResponse{
+		Enabled: s.Enabled,
+		ID:      s.ID,
+		Leader:  s.Leader,
+		Holder:  s.Holder,
+	}
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/scheduler-demo/internal/scheduler"
+)
+
+// JobRunner lists and triggers jobs; implemented by scheduler.Scheduler
+type JobRunner interface {
+	Jobs() []scheduler.Status
+	Job(name string) (scheduler.Status, error)
+	Trigger(name string) (scheduler.Status, error)
+}
+
+// JobHandler exposes the scheduler over HTTP
+type JobHandler struct {
+	runner JobRunner
+	log    logx.Logger
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(s *scheduler.Scheduler, log logx.Logger) *JobHandler {
+	return newJobHandler(s, log)
+}
+
+func newJobHandler(runner JobRunner, log logx.Logger) *JobHandler {
+	return &JobHandler{runner: runner, log: log}
+}
+
+// ListJobs handles GET /jobs
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	statuses := h.runner.Jobs()
+	jobs := make([]*JobResponse, len(statuses))
+	for i, s := range statuses {
+		jobs[i] = FromJobStatus(s)
+	}
+	responsex.OK(c, jobs, nil)
+}
+
+// GetJob handles GET /jobs/:name
+func (h *JobHandler) GetJob(c *gin.Context) {
+	status, err := h.runner.Job(c.Param("name"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	responsex.OK(c, FromJobStatus(status), nil)
+}
+
+// RunJob handles POST /jobs/:name/run. The job runs with its configured timeout
+// and the response is sent once it has finished.
+func (h *JobHandler) RunJob(c *gin.Context) {
+	name := c.Param("name")
+	h.log.Info("job triggered manually", logx.String("job", name))
+
+	status, err := h.runner.Trigger(name)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	responsex.OK(c, FromJobStatus(status), nil)
+}
+
+// handleError maps scheduler errors to HTTP responses
+func (h *JobHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, scheduler.ErrUnknownJob):
+		responsex.Error(c, http.StatusNotFound, "JOB_NOT_FOUND", "job not found", nil)
+	case errors.Is(err, scheduler.ErrJobRunning):
+		responsex.Error(c, http.StatusConflict, "JOB_RUNNING", "job is already running", nil)
+	case errors.Is(err, scheduler.ErrNotLeader):
+		responsex.Error(c, http.StatusConflict, "NOT_LEADER", "another replica runs the jobs; see GET /leader", nil)
+	case errors.Is(err, scheduler.ErrStopped):
+		responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "scheduler is shutting down", nil)
+	default:
+		h.log.Error("job request failed", logx.Err(err))
+		responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+	}
+}
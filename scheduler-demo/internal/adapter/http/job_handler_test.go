@@ -0,0 +1,152 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/scheduler-demo/internal/leader"
+	"github.com/gostratum/examples/scheduler-demo/internal/scheduler"
+)
+
+// fakeRunner serves fixed statuses and fails triggers of running jobs, and of
+// every job when it does not lead
+type fakeRunner struct {
+	jobs      map[string]scheduler.Status
+	notLeader bool
+}
+
+func (r *fakeRunner) Jobs() []scheduler.Status {
+	return []scheduler.Status{r.jobs["order-expiry"], r.jobs["reconciliation"]}
+}
+
+func (r *fakeRunner) Job(name string) (scheduler.Status, error) {
+	s, ok := r.jobs[name]
+	if !ok {
+		return scheduler.Status{}, scheduler.ErrUnknownJob
+	}
+	return s, nil
+}
+
+func (r *fakeRunner) Trigger(name string) (scheduler.Status, error) {
+	s, err := r.Job(name)
+	if err != nil {
+		return s, err
+	}
+	if r.notLeader {
+		return scheduler.Status{}, scheduler.ErrNotLeader
+	}
+	if s.Running {
+		return scheduler.Status{}, scheduler.ErrJobRunning
+	}
+	s.LastRun = time.Now()
+	s.LastDuration = 1500 * time.Millisecond
+	s.LastResult = scheduler.ResultFailure
+	s.LastError = "ledger unavailable"
+	return s, nil
+}
+
+// fakeElection reports a fixed leader status
+type fakeElection leader.Status
+
+func (f fakeElection) Status() leader.Status {
+	return leader.Status(f)
+}
+
+func setupRouter() *gin.Engine {
+	return setupRouterAs(true)
+}
+
+// setupRouterAs sets up the routes of a replica that leads or not
+func setupRouterAs(leading bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	runner := &fakeRunner{notLeader: !leading, jobs: map[string]scheduler.Status{
+		"order-expiry":   {Name: "order-expiry", Schedule: "* * * * *", Timeout: 30 * time.Second, NextRun: time.Now().Add(time.Minute)},
+		"reconciliation": {Name: "reconciliation", Schedule: "*/5 * * * *", Timeout: time.Minute, Running: true},
+	}}
+	handler := newJobHandler(runner, logx.NewNoopLogger())
+	election := fakeElection{Enabled: true, ID: "scheduler-b", Leader: leading, Holder: "scheduler-a"}
+	if leading {
+		election.Holder = election.ID
+	}
+	leaderHandler := newLeaderHandler(election)
+
+	e := gin.New()
+	e.GET("/jobs", handler.ListJobs)
+	e.GET("/jobs/:name", handler.GetJob)
+	e.POST("/jobs/:name/run", handler.RunJob)
+	e.GET("/leader", leaderHandler.GetLeader)
+	return e
+}
+
+func TestListJobs(t *testing.T) {
+	e := setupRouter()
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/jobs", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp responsex.Envelope[[]JobResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data, 2)
+	assert.Equal(t, "order-expiry", resp.Data[0].Name)
+	assert.Equal(t, "30s", resp.Data[0].Timeout)
+	assert.NotNil(t, resp.Data[0].NextRun)
+	assert.Nil(t, resp.Data[0].LastRun)
+	assert.True(t, resp.Data[1].Running)
+}
+
+func TestGetJobNotFound(t *testing.T) {
+	e := setupRouter()
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/jobs/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "JOB_NOT_FOUND")
+}
+
+func TestRunJob(t *testing.T) {
+	e := setupRouter()
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/jobs/order-expiry/run", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp responsex.Envelope[JobResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotNil(t, resp.Data.LastRun)
+	assert.Equal(t, "failure", resp.Data.LastRun.Result)
+	assert.Equal(t, int64(1500), resp.Data.LastRun.DurationMS)
+	assert.Equal(t, "ledger unavailable", resp.Data.LastRun.Error)
+
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/jobs/reconciliation/run", nil))
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "JOB_RUNNING")
+}
+
+func TestRunJobOnFollower(t *testing.T) {
+	e := setupRouterAs(false)
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/jobs/order-expiry/run", nil))
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "NOT_LEADER")
+
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/leader", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp responsex.Envelope[LeaderResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, LeaderResponse{Enabled: true, ID: "scheduler-b", Leader: false, Holder: "scheduler-a"}, resp.Data)
+}
@@ -0,0 +1,32 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/scheduler-demo/internal/leader"
+)
+
+// Election reports the leader election; implemented by leader.Elector
+type Election interface {
+	Status() leader.Status
+}
+
+// LeaderHandler shows which replica runs the jobs
+type LeaderHandler struct {
+	election Election
+}
+
+// NewLeaderHandler creates a new leader handler
+func NewLeaderHandler(e *leader.Elector) *LeaderHandler {
+	return newLeaderHandler(e)
+}
+
+func newLeaderHandler(e Election) *LeaderHandler {
+	return &LeaderHandler{election: e}
+}
+
+// GetLeader handles GET /leader
+func (h *LeaderHandler) GetLeader(c *gin.Context) {
+	responsex.OK(c, FromLeaderStatus(h.election.Status()), nil)
+}
@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gostratum/core/configx"
+
+	"github.com/gostratum/examples/scheduler-demo/internal/domain"
+	"github.com/gostratum/examples/scheduler-demo/internal/usecase"
+)
+
+// ChargeLedgerConfig tunes the in-memory charge ledger
+type ChargeLedgerConfig struct {
+	// Latency delays every lookup as if the ledger were a remote payment provider.
+	// Raise it above the reconciliation timeout to watch the job time out.
+	Latency time.Duration `mapstructure:"latency" default:"200ms"`
+}
+
+// Prefix implements configx.Configurable
+func (ChargeLedgerConfig) Prefix() string {
+	return "ledger"
+}
+
+// ChargeLedger keeps charges in memory, indexed by order
+type ChargeLedger struct {
+	latency time.Duration
+
+	mu      sync.RWMutex
+	byOrder map[string][]domain.Charge
+}
+
+// NewChargeLedger creates the ledger from the ledger config section
+func NewChargeLedger(loader configx.Loader) (usecase.ChargeLedger, error) {
+	var cfg ChargeLedgerConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load ledger config: %w", err)
+	}
+	return &ChargeLedger{latency: cfg.Latency, byOrder: make(map[string][]domain.Charge)}, nil
+}
+
+// Record implements usecase.ChargeLedger
+func (l *ChargeLedger) Record(ctx context.Context, c *domain.Charge) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.byOrder[c.OrderID] = append(l.byOrder[c.OrderID], *c)
+	return nil
+}
+
+// ListByOrders implements usecase.ChargeLedger
+func (l *ChargeLedger) ListByOrders(ctx context.Context, orderIDs []string) ([]*domain.Charge, error) {
+	if l.latency > 0 {
+		timer := time.NewTimer(l.latency)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var charges []*domain.Charge
+	for _, id := range orderIDs {
+		for _, c := range l.byOrder[id] {
+			charges = append(charges, &c)
+		}
+	}
+	return charges, nil
+}
@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gostratum/examples/scheduler-demo/internal/domain"
+	"github.com/gostratum/examples/scheduler-demo/internal/usecase"
+)
+
+// OrderStore keeps orders in memory
+type OrderStore struct {
+	mu     sync.RWMutex
+	orders map[string]domain.Order
+}
+
+// NewOrderStore creates an empty order store
+func NewOrderStore() usecase.OrderRepository {
+	return &OrderStore{orders: make(map[string]domain.Order)}
+}
+
+// Save implements usecase.OrderRepository
+func (s *OrderStore) Save(ctx context.Context, o *domain.Order) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.orders[o.ID]; ok {
+		return domain.ErrConflict
+	}
+	s.orders[o.ID] = *o
+	return nil
+}
+
+// Update implements usecase.OrderRepository
+func (s *OrderStore) Update(ctx context.Context, o *domain.Order) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.orders[o.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if stored.Status != domain.OrderPending {
+		return domain.ErrConflict
+	}
+	s.orders[o.ID] = *o
+	return nil
+}
+
+// ListPending implements usecase.OrderRepository
+func (s *OrderStore) ListPending(ctx context.Context, createdBefore time.Time, limit int) ([]*domain.Order, error) {
+	orders := s.list(func(o domain.Order) bool {
+		return o.Status == domain.OrderPending && o.CreatedAt.Before(createdBefore)
+	})
+	if len(orders) > limit {
+		orders = orders[:limit]
+	}
+	return orders, nil
+}
+
+// ListCreated implements usecase.OrderRepository
+func (s *OrderStore) ListCreated(ctx context.Context, from, to time.Time) ([]*domain.Order, error) {
+	return s.list(func(o domain.Order) bool {
+		return !o.CreatedAt.Before(from) && o.CreatedAt.Before(to)
+	}), nil
+}
+
+// list returns copies of the matching orders, oldest first
+func (s *OrderStore) list(match func(domain.Order) bool) []*domain.Order {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var orders []*domain.Order
+	for _, o := range s.orders {
+		if match(o) {
+			orders = append(orders, &o)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CreatedAt.Before(orders[j].CreatedAt)
+	})
+	return orders
+}
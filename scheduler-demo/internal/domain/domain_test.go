@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOrderTransitions(t *testing.T) {
+	now := time.Now()
+
+	order := NewOrder("user1", 1000, "USD", now)
+	if err := order.Pay(now); err != nil {
+		t.Fatalf("Pay() on pending order: %v", err)
+	}
+	if err := order.Expire(now); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expire() on paid order error = %v, want ErrConflict", err)
+	}
+
+	order = NewOrder("user1", 1000, "USD", now)
+	if err := order.Expire(now); err != nil {
+		t.Fatalf("Expire() on pending order: %v", err)
+	}
+	if order.Status != OrderExpired {
+		t.Errorf("Status = %s, want %s", order.Status, OrderExpired)
+	}
+	if err := order.Pay(now); !errors.Is(err, ErrConflict) {
+		t.Errorf("Pay() on expired order error = %v, want ErrConflict", err)
+	}
+}
+
+func TestOrderIsStale(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name  string
+		order *Order
+		want  bool
+	}{
+		{name: "new pending order", order: NewOrder("user1", 1000, "USD", now.Add(-time.Minute)), want: false},
+		{name: "old pending order", order: NewOrder("user1", 1000, "USD", now.Add(-time.Hour)), want: true},
+		{name: "old paid order", order: func() *Order {
+			o := NewOrder("user1", 1000, "USD", now.Add(-time.Hour))
+			_ = o.Pay(now)
+			return o
+		}(), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.order.IsStale(15*time.Minute, now); got != tt.want {
+				t.Errorf("IsStale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	now := time.Now()
+	paid := func(id string, total int64) *Order {
+		o := NewOrder("user1", total, "USD", now)
+		o.ID = id
+		_ = o.Pay(now)
+		return o
+	}
+	pending := NewOrder("user1", 700, "USD", now)
+	pending.ID = "pending"
+
+	orders := []*Order{paid("ok", 1000), paid("split", 1000), paid("short", 1000), paid("unpaid", 500), pending}
+	charges := []*Charge{
+		NewCharge("ok", 1000, "USD", now),
+		NewCharge("split", 600, "USD", now),
+		NewCharge("split", 400, "USD", now),
+		NewCharge("short", 900, "USD", now),
+		NewCharge("pending", 700, "USD", now),
+		NewCharge("other", 100, "USD", now),
+	}
+
+	got := Reconcile(orders, charges)
+	want := []Mismatch{
+		{Kind: MismatchAmount, OrderID: "short", Expected: 1000, Charged: 900},
+		{Kind: MismatchMissingCharge, OrderID: "unpaid", Expected: 500},
+		{Kind: MismatchUnexpectedCharge, OrderID: "pending", Charged: 700},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Reconcile() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("mismatch %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	report := Report{Mismatches: got}
+	if n := report.Count(MismatchAmount); n != 1 {
+		t.Errorf("Count(%s) = %d, want 1", MismatchAmount, n)
+	}
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "errors"
+
+// Domain errors represent business rule violations
+var (
+	// ErrNotFound indicates a requested resource was not found
+	ErrNotFound = errors.New("resource not found")
+
+	// ErrInvalidInput indicates the provided input violates business rules
+	ErrInvalidInput = errors.New("invalid input")
+
+	// ErrConflict indicates a conflict with the current state (e.g., expiring a paid order)
+	ErrConflict = errors.New("resource conflict")
+)
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Order statuses
+const (
+	OrderPending = "pending"
+	OrderPaid    = "paid"
+	OrderExpired = "expired"
+)
+
+// Order is a customer order. Amounts are in minor units (cents).
+// This is a pure domain model without infrastructure concerns
+type Order struct {
+	ID        string
+	UserID    string
+	Status    string
+	Total     int64
+	Currency  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewOrder creates a pending order with a generated ID
+func NewOrder(userID string, total int64, currency string, now time.Time) *Order {
+	return &Order{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Status:    OrderPending,
+		Total:     total,
+		Currency:  currency,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Pay marks a pending order as paid
+func (o *Order) Pay(now time.Time) error {
+	if o.Status != OrderPending {
+		return ErrConflict
+	}
+	o.Status = OrderPaid
+	o.UpdatedAt = now
+	return nil
+}
+
+// Expire marks a pending order as expired. Paid and already expired orders
+// cannot expire.
+func (o *Order) Expire(now time.Time) error {
+	if o.Status != OrderPending {
+		return ErrConflict
+	}
+	o.Status = OrderExpired
+	o.UpdatedAt = now
+	return nil
+}
+
+// IsStale reports whether the order has been pending for maxAge or longer
+func (o *Order) IsStale(maxAge time.Duration, now time.Time) bool {
+	return o.Status == OrderPending && now.Sub(o.CreatedAt) >= maxAge
+}
+
+// Charge is a payment captured for an order
+// This is a pure domain model without infrastructure concerns
+type Charge struct {
+	ID        string
+	OrderID   string
+	Amount    int64
+	Currency  string
+	CreatedAt time.Time
+}
+
+// NewCharge creates a charge for an order with a generated ID
+func NewCharge(orderID string, amount int64, currency string, now time.Time) *Charge {
+	return &Charge{
+		ID:        "ch_" + uuid.New().String(),
+		OrderID:   orderID,
+		Amount:    amount,
+		Currency:  currency,
+		CreatedAt: now,
+	}
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// Mismatch kinds found by reconciliation
+const (
+	// MismatchMissingCharge: a paid order has no charge
+	MismatchMissingCharge = "missing_charge"
+	// MismatchAmount: the charges of a paid order do not add up to its total
+	MismatchAmount = "amount_mismatch"
+	// MismatchUnexpectedCharge: an order that is not paid has been charged
+	MismatchUnexpectedCharge = "unexpected_charge"
+)
+
+// MismatchKinds lists every mismatch kind, for reporting zero counts
+var MismatchKinds = []string{MismatchMissingCharge, MismatchAmount, MismatchUnexpectedCharge}
+
+// Mismatch is an order whose charges disagree with its status or total
+type Mismatch struct {
+	Kind    string
+	OrderID string
+	// Expected is what the order should have been charged, Charged what it was
+	Expected int64
+	Charged  int64
+}
+
+// Report is the outcome of reconciling the orders created in a time window
+type Report struct {
+	From       time.Time
+	To         time.Time
+	Orders     int
+	Charges    int
+	Mismatches []Mismatch
+}
+
+// Count returns the number of mismatches of a kind
+func (r *Report) Count(kind string) int {
+	n := 0
+	for _, m := range r.Mismatches {
+		if m.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// Reconcile compares orders with the charges made for them. Paid orders must be
+// charged exactly their total; other orders must not be charged at all.
+// Charges for orders that are not in the list are ignored.
+func Reconcile(orders []*Order, charges []*Charge) []Mismatch {
+	charged := make(map[string]int64, len(charges))
+	for _, c := range charges {
+		charged[c.OrderID] += c.Amount
+	}
+
+	var mismatches []Mismatch
+	for _, o := range orders {
+		amount, ok := charged[o.ID]
+		switch {
+		case o.Status == OrderPaid && !ok:
+			mismatches = append(mismatches, Mismatch{Kind: MismatchMissingCharge, OrderID: o.ID, Expected: o.Total})
+		case o.Status == OrderPaid && amount != o.Total:
+			mismatches = append(mismatches, Mismatch{Kind: MismatchAmount, OrderID: o.ID, Expected: o.Total, Charged: amount})
+		case o.Status != OrderPaid && ok:
+			mismatches = append(mismatches, Mismatch{Kind: MismatchUnexpectedCharge, OrderID: o.ID, Charged: amount})
+		}
+	}
+
+	sort.Slice(mismatches, func(i, j int) bool {
+		if mismatches[i].Kind != mismatches[j].Kind {
+			return mismatches[i].Kind < mismatches[j].Kind
+		}
+		return mismatches[i].OrderID < mismatches[j].OrderID
+	})
+	return mismatches
+}
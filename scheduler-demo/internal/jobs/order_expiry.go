@@ -0,0 +1,58 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+
+	"github.com/gostratum/examples/scheduler-demo/internal/usecase"
+)
+
+// OrderExpiryConfig tunes the order expiry job
+type OrderExpiryConfig struct {
+	// MaxAge is how long an order may stay pending before it expires
+	MaxAge time.Duration `mapstructure:"max_age" default:"15m"`
+	// BatchSize is how many orders are loaded at a time
+	BatchSize int `mapstructure:"batch_size" default:"100"`
+}
+
+// Prefix implements configx.Configurable
+func (OrderExpiryConfig) Prefix() string {
+	return "order_expiry"
+}
+
+// OrderExpiryJob expires orders that were never paid
+type OrderExpiryJob struct {
+	service *usecase.ExpiryService
+	cfg     OrderExpiryConfig
+	log     logx.Logger
+}
+
+// NewOrderExpiryJob creates the job from the order_expiry config section
+func NewOrderExpiryJob(loader configx.Loader, service *usecase.ExpiryService, log logx.Logger) (*OrderExpiryJob, error) {
+	var cfg OrderExpiryConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load order_expiry config: %w", err)
+	}
+	return &OrderExpiryJob{service: service, cfg: cfg, log: log}, nil
+}
+
+// Name implements scheduler.Job
+func (j *OrderExpiryJob) Name() string {
+	return "order-expiry"
+}
+
+// Run implements scheduler.Job
+func (j *OrderExpiryJob) Run(ctx context.Context) error {
+	expired, err := j.service.ExpireStale(ctx, j.cfg.MaxAge, j.cfg.BatchSize)
+	if expired > 0 {
+		j.log.Info("expired stale orders",
+			logx.Int("expired", expired),
+			logx.String("max_age", j.cfg.MaxAge.String()),
+		)
+	}
+	return err
+}
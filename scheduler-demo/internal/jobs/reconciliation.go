@@ -0,0 +1,87 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/metricsx"
+
+	"github.com/gostratum/examples/scheduler-demo/internal/domain"
+	"github.com/gostratum/examples/scheduler-demo/internal/usecase"
+)
+
+// ReconciliationConfig tunes the reconciliation job
+type ReconciliationConfig struct {
+	// Lookback is how far back each run checks orders
+	Lookback time.Duration `mapstructure:"lookback" default:"1h"`
+	// SettleDelay leaves out the most recent orders, whose charges may still be in flight
+	SettleDelay time.Duration `mapstructure:"settle_delay" default:"1m"`
+}
+
+// Prefix implements configx.Configurable
+func (ReconciliationConfig) Prefix() string {
+	return "reconciliation"
+}
+
+// ReconciliationJob compares recent orders with their charges and reports the
+// mismatches. Mismatches are findings, not failures: the run still succeeds.
+type ReconciliationJob struct {
+	service    *usecase.ReconciliationService
+	cfg        ReconciliationConfig
+	mismatches gauge
+	log        logx.Logger
+	now        func() time.Time
+}
+
+// NewReconciliationJob creates the job from the reconciliation config section
+func NewReconciliationJob(loader configx.Loader, service *usecase.ReconciliationService, metrics metricsx.Metrics, log logx.Logger) (*ReconciliationJob, error) {
+	var cfg ReconciliationConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load reconciliation config: %w", err)
+	}
+	mismatches := metrics.Gauge("reconciliation_mismatches",
+		metricsx.WithHelp("Mismatches found by the last reconciliation run, by kind"),
+		metricsx.WithLabels("kind"),
+	)
+	return &ReconciliationJob{service: service, cfg: cfg, mismatches: mismatches, log: log, now: time.Now}, nil
+}
+
+// Name implements scheduler.Job
+func (j *ReconciliationJob) Name() string {
+	return "reconciliation"
+}
+
+// Run implements scheduler.Job
+func (j *ReconciliationJob) Run(ctx context.Context) error {
+	to := j.now().Add(-j.cfg.SettleDelay)
+	report, err := j.service.Reconcile(ctx, to.Add(-j.cfg.Lookback), to)
+	if err != nil {
+		return err
+	}
+
+	for _, kind := range domain.MismatchKinds {
+		j.mismatches.Set(float64(report.Count(kind)), kind)
+	}
+	for _, m := range report.Mismatches {
+		j.log.Warn("reconciliation mismatch",
+			logx.String("kind", m.Kind),
+			logx.String("order_id", m.OrderID),
+			logx.Int("expected", int(m.Expected)),
+			logx.Int("charged", int(m.Charged)),
+		)
+	}
+	j.log.Info("reconciliation finished",
+		logx.Int("orders", report.Orders),
+		logx.Int("charges", report.Charges),
+		logx.Int("mismatches", len(report.Mismatches)),
+	)
+	return nil
+}
+
+// gauge is the part of metricsx.Gauge the jobs use
+type gauge interface {
+	Set(v float64, labels ...string)
+}
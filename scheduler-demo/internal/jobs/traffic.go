@@ -0,0 +1,98 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+
+	"github.com/gostratum/examples/scheduler-demo/internal/domain"
+	"github.com/gostratum/examples/scheduler-demo/internal/usecase"
+)
+
+// TrafficConfig tunes the demo traffic job
+type TrafficConfig struct {
+	// OrdersPerRun is how many orders each run creates
+	OrdersPerRun int `mapstructure:"orders_per_run" default:"5"`
+	// PaidRate is the fraction of orders (0-1) that get paid; the rest stay
+	// pending until order-expiry expires them
+	PaidRate float64 `mapstructure:"paid_rate" default:"0.6"`
+	// MismatchRate is the fraction of paid orders (0-1) whose charge goes wrong,
+	// for reconciliation to find
+	MismatchRate float64 `mapstructure:"mismatch_rate" default:"0.1"`
+}
+
+// Prefix implements configx.Configurable
+func (TrafficConfig) Prefix() string {
+	return "traffic"
+}
+
+// TrafficJob stands in for the order API: it creates orders and charges, so
+// the other jobs have something to work on
+type TrafficJob struct {
+	orders  usecase.OrderRepository
+	charges usecase.ChargeLedger
+	cfg     TrafficConfig
+	log     logx.Logger
+	// random returns a float in [0, 1)
+	random func() float64
+}
+
+// NewTrafficJob creates the job from the traffic config section
+func NewTrafficJob(loader configx.Loader, orders usecase.OrderRepository, charges usecase.ChargeLedger, log logx.Logger) (*TrafficJob, error) {
+	var cfg TrafficConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load traffic config: %w", err)
+	}
+	if cfg.PaidRate < 0 || cfg.PaidRate > 1 || cfg.MismatchRate < 0 || cfg.MismatchRate > 1 {
+		return nil, fmt.Errorf("traffic.paid_rate and traffic.mismatch_rate must be between 0 and 1, got %v and %v", cfg.PaidRate, cfg.MismatchRate)
+	}
+	return &TrafficJob{orders: orders, charges: charges, cfg: cfg, log: log, random: rand.Float64}, nil
+}
+
+// Name implements scheduler.Job
+func (j *TrafficJob) Name() string {
+	return "demo-traffic"
+}
+
+// Run implements scheduler.Job
+func (j *TrafficJob) Run(ctx context.Context) error {
+	paid := 0
+	for range j.cfg.OrdersPerRun {
+		now := time.Now()
+		order := domain.NewOrder(fmt.Sprintf("user-%d", rand.IntN(100)), 500+rand.Int64N(20000), "USD", now)
+
+		var charge *domain.Charge
+		if j.random() < j.cfg.PaidRate {
+			_ = order.Pay(now)
+			paid++
+
+			switch r := j.random(); {
+			case r < j.cfg.MismatchRate/2:
+				// Paid, but the charge was never recorded
+			case r < j.cfg.MismatchRate:
+				charge = domain.NewCharge(order.ID, order.Total-100, order.Currency, now)
+			default:
+				charge = domain.NewCharge(order.ID, order.Total, order.Currency, now)
+			}
+		}
+
+		if err := j.orders.Save(ctx, order); err != nil {
+			return err
+		}
+		if charge != nil {
+			if err := j.charges.Record(ctx, charge); err != nil {
+				return err
+			}
+		}
+	}
+
+	j.log.Info("created demo orders",
+		logx.Int("orders", j.cfg.OrdersPerRun),
+		logx.Int("paid", paid),
+	)
+	return nil
+}
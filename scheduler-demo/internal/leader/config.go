@@ -0,0 +1,32 @@
+// Package leader is a small fx module for leader election between replicas:
+// the replica holding a lease in Redis leads, renews the lease while it runs
+// and hands it over when it stops or can no longer renew it
+package leader
+
+import "time"
+
+// Config says where the lease is kept and how long it lasts
+type Config struct {
+	// Enabled turns on leader election. When it is off, the replica leads on its
+	// own, as a single replica would.
+	Enabled  bool   `mapstructure:"enabled"`
+	Addr     string `mapstructure:"addr" default:"localhost:6379"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db"`
+	// Key is the Redis key of the lease; replicas that share it elect one leader
+	Key string `mapstructure:"key" default:"scheduler-demo:leader"`
+	// TTL is how long the lease outlives its last renewal. A leader that dies
+	// without releasing it is replaced after at most this long.
+	TTL time.Duration `mapstructure:"ttl" default:"15s"`
+	// RenewInterval is how often the leader renews the lease and the other
+	// replicas try to take it. Keep it well below TTL.
+	RenewInterval time.Duration `mapstructure:"renew_interval" default:"5s"`
+	// ID identifies this replica in the lease and the logs; the host name and a
+	// random suffix when empty
+	ID string `mapstructure:"id"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "leader"
+}
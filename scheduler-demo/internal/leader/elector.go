@@ -0,0 +1,279 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/metricsx"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+)
+
+// acquire takes the lease when it is free and renews it when this replica
+// holds it, in one step. It returns the holder of the lease.
+var acquire = redis.NewScript(`
+local holder = redis.call("GET", KEYS[1])
+if holder == ARGV[1] then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return holder
+end
+if not holder then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return ARGV[1]
+end
+return holder
+`)
+
+// release deletes the lease if this replica still holds it
+var release = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Status is a snapshot of the election as this replica sees it
+type Status struct {
+	Enabled bool
+	ID      string
+	Leader  bool
+	// Holder is the ID of the replica holding the lease; empty when it is
+	// unknown, e.g. while Redis is unreachable
+	Holder string
+}
+
+// Params holds the dependencies of the Elector
+type Params struct {
+	fx.In
+
+	Loader  configx.Loader
+	Metrics metricsx.Metrics
+	Log     logx.Logger
+}
+
+// Elector takes part in the election for one lease
+type Elector struct {
+	cfg    Config
+	client *redis.Client // nil when election is disabled
+	gauge  gauge
+	log    logx.Logger
+
+	mu      sync.Mutex
+	leading bool
+	holder  string
+	// term is cancelled when this replica stops leading
+	term    context.Context
+	endTerm context.CancelFunc
+
+	stopping chan struct{}
+	done     chan struct{}
+}
+
+// gauge is the part of metricsx.Gauge the elector uses
+type gauge interface {
+	Set(v float64, labels ...string)
+}
+
+// NewElector creates an Elector from the leader config section
+func NewElector(p Params) (*Elector, error) {
+	var cfg Config
+	if err := p.Loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load leader config: %w", err)
+	}
+	if cfg.Enabled && cfg.RenewInterval >= cfg.TTL {
+		return nil, fmt.Errorf("leader.renew_interval (%s) must be shorter than leader.ttl (%s)", cfg.RenewInterval, cfg.TTL)
+	}
+	if cfg.ID == "" {
+		cfg.ID = defaultID()
+	}
+
+	var client *redis.Client
+	if cfg.Enabled {
+		client = redis.NewClient(&redis.Options{
+			Addr:     cfg.Addr,
+			Password: cfg.Password,
+			DB:       cfg.DB,
+		})
+	}
+	g := p.Metrics.Gauge("leader_is_leader",
+		metricsx.WithHelp("1 while this replica holds the leader lease"),
+		metricsx.WithLabels("lease"),
+	)
+	return newElector(cfg, client, g, p.Log), nil
+}
+
+func newElector(cfg Config, client *redis.Client, g gauge, log logx.Logger) *Elector {
+	term, endTerm := context.WithCancel(context.Background())
+	endTerm()
+	return &Elector{
+		cfg:      cfg,
+		client:   client,
+		gauge:    g,
+		log:      log,
+		term:     term,
+		endTerm:  endTerm,
+		stopping: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Register ties the election to the application lifecycle.
+// This function is designed to be used with fx.Invoke.
+func Register(lc fx.Lifecycle, e *Elector) {
+	lc.Append(fx.Hook{
+		OnStart: e.start,
+		OnStop:  e.stop,
+	})
+}
+
+// start tries to take the lease once, so the replica knows whether it leads
+// before anything asks, then keeps trying in the background
+func (e *Elector) start(ctx context.Context) error {
+	e.gauge.Set(0, e.cfg.Key)
+	if e.client == nil {
+		e.log.Info("leader election disabled; this replica leads", logx.String("id", e.cfg.ID))
+		e.lead()
+		close(e.done)
+		return nil
+	}
+
+	e.tryLead(ctx)
+	go e.loop()
+	return nil
+}
+
+// stop stops taking part in the election and, when this replica leads,
+// releases the lease so another replica takes over without waiting for it to
+// expire
+func (e *Elector) stop(ctx context.Context) error {
+	if e.client == nil {
+		e.follow("", nil)
+		return nil
+	}
+
+	close(e.stopping)
+	<-e.done
+
+	wasLeading := e.Status().Leader
+	e.follow("", nil)
+	if wasLeading {
+		if err := release.Run(ctx, e.client, []string{e.cfg.Key}, e.cfg.ID).Err(); err != nil {
+			e.log.Warn("failed to release leadership; it passes on when the lease expires",
+				logx.String("ttl", e.cfg.TTL.String()), logx.Err(err))
+		} else {
+			e.log.Info("released leadership", logx.String("key", e.cfg.Key), logx.String("id", e.cfg.ID))
+		}
+	}
+	return e.client.Close()
+}
+
+// Leading reports whether this replica leads. When it does, the context is
+// cancelled as soon as it stops leading, so work started as the leader can
+// stop before another replica takes over.
+func (e *Elector) Leading() (context.Context, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.term, e.leading
+}
+
+// Status returns the election as this replica sees it
+func (e *Elector) Status() Status {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return Status{
+		Enabled: e.client != nil,
+		ID:      e.cfg.ID,
+		Leader:  e.leading,
+		Holder:  e.holder,
+	}
+}
+
+func (e *Elector) loop() {
+	defer close(e.done)
+	ticker := time.NewTicker(e.cfg.RenewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.stopping:
+			return
+		case <-ticker.C:
+			e.tryLead(context.Background())
+		}
+	}
+}
+
+// tryLead takes or renews the lease and updates the state of this replica
+func (e *Elector) tryLead(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, e.cfg.RenewInterval)
+	defer cancel()
+
+	holder, err := acquire.Run(ctx, e.client, []string{e.cfg.Key}, e.cfg.ID, e.cfg.TTL.Milliseconds()).Text()
+	switch {
+	case err != nil:
+		// Whether the lease was renewed is unknown, and another replica takes
+		// it once it expires, so stop leading now rather than risk two leaders
+		e.follow("", err)
+	case holder == e.cfg.ID:
+		e.lead()
+	default:
+		e.follow(holder, nil)
+	}
+}
+
+// lead starts a term if this replica does not lead yet
+func (e *Elector) lead() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.leading {
+		return
+	}
+
+	e.leading = true
+	e.holder = e.cfg.ID
+	e.term, e.endTerm = context.WithCancel(context.Background())
+	e.gauge.Set(1, e.cfg.Key)
+	e.log.Info("became leader", logx.String("key", e.cfg.Key), logx.String("id", e.cfg.ID))
+}
+
+// follow ends the term of this replica if it leads, and records who does
+func (e *Elector) follow(holder string, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	wasLeading := e.leading
+	changed := e.holder != holder
+	e.leading = false
+	e.holder = holder
+	e.endTerm()
+	e.gauge.Set(0, e.cfg.Key)
+
+	switch {
+	case wasLeading && err != nil:
+		e.log.Warn("lost leadership: lease could not be renewed",
+			logx.String("key", e.cfg.Key), logx.String("id", e.cfg.ID), logx.Err(err))
+	case wasLeading && holder != "":
+		e.log.Warn("lost leadership", logx.String("key", e.cfg.Key), logx.String("id", e.cfg.ID), logx.String("leader", holder))
+	case err != nil:
+		// Every replica hits this while Redis is down; the leader's warning says it
+		e.log.Debug("leader election failed", logx.String("key", e.cfg.Key), logx.Err(err))
+	case changed && holder != "":
+		e.log.Info("following leader", logx.String("key", e.cfg.Key), logx.String("leader", holder))
+	}
+}
+
+// defaultID is the host name, which is the pod name on Kubernetes, and a
+// random suffix that tells apart replicas on one host
+func defaultID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "replica"
+	}
+	return host + "-" + uuid.NewString()[:8]
+}
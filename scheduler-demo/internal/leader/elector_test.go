@@ -0,0 +1,153 @@
+package leader
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gostratum/core/logx"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGauge stores the last value set
+type fakeGauge struct {
+	mu    sync.Mutex
+	value float64
+}
+
+func (g *fakeGauge) Set(v float64, labels ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.value = v
+}
+
+func (g *fakeGauge) get() float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.value
+}
+
+func newTestElector(t *testing.T, mr *miniredis.Miniredis, id string) (*Elector, *fakeGauge) {
+	t.Helper()
+	// No retries, so a Redis that is down fails fast
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	cfg := Config{Enabled: true, Key: "test:leader", TTL: 15 * time.Second, RenewInterval: 5 * time.Second, ID: id}
+	g := &fakeGauge{}
+	return newElector(cfg, client, g, logx.NewNoopLogger()), g
+}
+
+func TestOneReplicaLeads(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a, gaugeA := newTestElector(t, mr, "a")
+	b, gaugeB := newTestElector(t, mr, "b")
+
+	a.tryLead(context.Background())
+	b.tryLead(context.Background())
+
+	term, ok := a.Leading()
+	require.True(t, ok)
+	assert.NoError(t, term.Err())
+	assert.Equal(t, float64(1), gaugeA.get())
+
+	_, ok = b.Leading()
+	assert.False(t, ok)
+	assert.Equal(t, Status{Enabled: true, ID: "b", Holder: "a"}, b.Status())
+	assert.Equal(t, float64(0), gaugeB.get())
+
+	// Renewing keeps the lease and the term
+	mr.FastForward(10 * time.Second)
+	a.tryLead(context.Background())
+	assert.Equal(t, 15*time.Second, mr.TTL("test:leader"))
+	renewed, ok := a.Leading()
+	assert.True(t, ok)
+	assert.Equal(t, term, renewed)
+}
+
+func TestLeaseExpiresWithoutRenewal(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a, gaugeA := newTestElector(t, mr, "a")
+	b, _ := newTestElector(t, mr, "b")
+
+	a.tryLead(context.Background())
+	term, _ := a.Leading()
+
+	// a stops renewing, e.g. because it hangs
+	mr.FastForward(15 * time.Second)
+	b.tryLead(context.Background())
+	_, ok := b.Leading()
+	assert.True(t, ok)
+
+	a.tryLead(context.Background())
+	_, ok = a.Leading()
+	assert.False(t, ok)
+	assert.ErrorIs(t, term.Err(), context.Canceled, "work of the old term is cancelled")
+	assert.Equal(t, float64(0), gaugeA.get())
+	assert.Equal(t, "b", a.Status().Holder)
+}
+
+func TestStepsDownWhenRedisIsDown(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a, _ := newTestElector(t, mr, "a")
+
+	a.tryLead(context.Background())
+	term, ok := a.Leading()
+	require.True(t, ok)
+
+	mr.Close()
+	a.tryLead(context.Background())
+	_, ok = a.Leading()
+	assert.False(t, ok)
+	assert.Error(t, term.Err())
+	assert.Empty(t, a.Status().Holder)
+}
+
+func TestStopHandsOver(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a, _ := newTestElector(t, mr, "a")
+	b, _ := newTestElector(t, mr, "b")
+
+	require.NoError(t, a.start(context.Background()))
+	require.NoError(t, b.start(context.Background()))
+	term, ok := a.Leading()
+	require.True(t, ok)
+
+	require.NoError(t, a.stop(context.Background()))
+	assert.False(t, mr.Exists("test:leader"), "the lease is released")
+	assert.Error(t, term.Err())
+
+	// b takes over on its next attempt, without waiting for the TTL
+	b.tryLead(context.Background())
+	_, ok = b.Leading()
+	assert.True(t, ok)
+	require.NoError(t, b.stop(context.Background()))
+}
+
+func TestStopDoesNotReleaseAnotherLease(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a, _ := newTestElector(t, mr, "a")
+	require.NoError(t, mr.Set("test:leader", "b"))
+
+	require.NoError(t, a.start(context.Background()))
+	require.NoError(t, a.stop(context.Background()))
+	got, err := mr.Get("test:leader")
+	require.NoError(t, err)
+	assert.Equal(t, "b", got)
+}
+
+func TestDisabledElectorLeads(t *testing.T) {
+	g := &fakeGauge{}
+	e := newElector(Config{ID: "solo"}, nil, g, logx.NewNoopLogger())
+
+	require.NoError(t, e.start(context.Background()))
+	term, ok := e.Leading()
+	assert.True(t, ok)
+	assert.Equal(t, Status{ID: "solo", Leader: true, Holder: "solo"}, e.Status())
+	assert.Equal(t, float64(1), g.get())
+
+	require.NoError(t, e.stop(context.Background()))
+	assert.Error(t, term.Err())
+}
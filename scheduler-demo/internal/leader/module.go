@@ -0,0 +1,12 @@
+package leader
+
+import "go.uber.org/fx"
+
+// Module provides the Elector and runs the election from application start
+// until stop. It needs metricsx.Module for the leader gauge.
+func Module() fx.Option {
+	return fx.Module("leader",
+		fx.Provide(NewElector),
+		fx.Invoke(Register),
+	)
+}
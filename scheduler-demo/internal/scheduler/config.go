@@ -0,0 +1,33 @@
+// Package scheduler is a small fx module that runs the Jobs in the
+// "scheduler.jobs" group on cron schedules, with a timeout per job, no
+// overlapping runs, last-run metrics and a graceful stop
+package scheduler
+
+import "time"
+
+// Config holds the scheduler settings and the schedule of every job
+type Config struct {
+	// Timezone the schedules are evaluated in, as an IANA name
+	Timezone string `mapstructure:"timezone" default:"UTC"`
+	// DefaultTimeout bounds a run of any job that sets no timeout of its own
+	DefaultTimeout time.Duration `mapstructure:"default_timeout" default:"5m"`
+
+	// Jobs configures each registered job by name. Every job needs an entry.
+	Jobs map[string]JobConfig `mapstructure:"jobs"`
+}
+
+// JobConfig schedules one job
+type JobConfig struct {
+	// Schedule is a standard five-field cron expression ("*/5 * * * *") or a
+	// descriptor such as "@hourly" or "@every 30s"
+	Schedule string `mapstructure:"schedule"`
+	// Timeout bounds one run; the job's context is cancelled when it passes
+	Timeout time.Duration `mapstructure:"timeout"`
+	// Disabled keeps the job registered but never runs it on schedule
+	Disabled bool `mapstructure:"disabled"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "scheduler"
+}
@@ -0,0 +1,27 @@
+package scheduler
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+)
+
+// jobsGroup is the fx value group the scheduler runs
+const jobsGroup = `group:"scheduler.jobs"`
+
+// Job is a unit of scheduled work
+type Job interface {
+	// Name identifies the job in config, logs and metrics
+	Name() string
+	// Run does one pass of the work. ctx is cancelled when the job's timeout
+	// passes or the application stops; Run should return soon after.
+	Run(ctx context.Context) error
+}
+
+// AsJob annotates a constructor so its result joins the job group the
+// scheduler runs:
+//
+//	fx.Provide(scheduler.AsJob(jobs.NewOrderExpiryJob))
+func AsJob(constructor any) any {
+	return fx.Annotate(constructor, fx.As(new(Job)), fx.ResultTags(jobsGroup))
+}
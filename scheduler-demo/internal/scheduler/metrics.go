@@ -0,0 +1,54 @@
+package scheduler
+
+import "github.com/gostratum/metricsx"
+
+// Metrics records job runs
+type Metrics struct {
+	runs        counter
+	duration    histogram
+	lastRun     gauge
+	lastSuccess gauge
+	running     gauge
+}
+
+// NewMetrics registers the scheduler metrics
+func NewMetrics(metrics metricsx.Metrics) *Metrics {
+	return &Metrics{
+		runs: metrics.Counter("scheduler_job_runs_total",
+			metricsx.WithHelp("Job runs, by job and result (success, failure, timeout, cancelled, skipped)"),
+			metricsx.WithLabels("job", "result"),
+		),
+		duration: metrics.Histogram("scheduler_job_duration_seconds",
+			metricsx.WithHelp("Time a job run took"),
+			metricsx.WithLabels("job"),
+			metricsx.WithBuckets(0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300),
+		),
+		lastRun: metrics.Gauge("scheduler_job_last_run_timestamp_seconds",
+			metricsx.WithHelp("Unix time the last run of a job finished"),
+			metricsx.WithLabels("job"),
+		),
+		lastSuccess: metrics.Gauge("scheduler_job_last_success_timestamp_seconds",
+			metricsx.WithHelp("Unix time the last successful run of a job finished"),
+			metricsx.WithLabels("job"),
+		),
+		running: metrics.Gauge("scheduler_job_running",
+			metricsx.WithHelp("1 while a job is running"),
+			metricsx.WithLabels("job"),
+		),
+	}
+}
+
+// counter is the part of metricsx.Counter the scheduler uses
+type counter interface {
+	Inc(labels ...string)
+}
+
+// histogram is the part of metricsx.Histogram the scheduler uses
+type histogram interface {
+	Observe(v float64, labels ...string)
+}
+
+// gauge is the part of metricsx.Gauge the scheduler uses
+type gauge interface {
+	Set(v float64, labels ...string)
+}
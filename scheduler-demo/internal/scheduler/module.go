@@ -0,0 +1,15 @@
+package scheduler
+
+import "go.uber.org/fx"
+
+// Module provides the scheduler and its metrics, and runs every job provided
+// with AsJob from application start until stop
+func Module() fx.Option {
+	return fx.Module("scheduler",
+		fx.Provide(
+			NewMetrics,
+			NewScheduler,
+		),
+		fx.Invoke(Register),
+	)
+}
@@ -0,0 +1,415 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/fx"
+)
+
+// Result is how a job run ended
+type Result string
+
+const (
+	// ResultSuccess: Run returned nil
+	ResultSuccess Result = "success"
+	// ResultFailure: Run returned an error or panicked
+	ResultFailure Result = "failure"
+	// ResultTimeout: Run failed after the job's timeout passed
+	ResultTimeout Result = "timeout"
+	// ResultCancelled: Run failed after the scheduler cancelled it on shutdown or
+	// when this replica stopped leading
+	ResultCancelled Result = "cancelled"
+	// ResultSkipped: the previous run was still in progress, so this one did not start
+	ResultSkipped Result = "skipped"
+)
+
+var (
+	// ErrUnknownJob indicates no job with the given name is registered
+	ErrUnknownJob = errors.New("unknown job")
+
+	// ErrJobRunning indicates a run was skipped because the job is still running
+	ErrJobRunning = errors.New("job is already running")
+
+	// ErrStopped indicates the scheduler is shutting down and starts no more runs
+	ErrStopped = errors.New("scheduler is stopped")
+	// ErrNotLeader indicates a run did not start because another replica leads
+	ErrNotLeader = errors.New("another replica runs the jobs")
+)
+
+// Leader decides which replica runs the jobs. Without one, every replica runs
+// every job.
+type Leader interface {
+	// Leading reports whether this replica leads. When it does, the context is
+	// cancelled as soon as it stops leading.
+	Leading() (context.Context, bool)
+}
+
+// Status is a snapshot of one job
+type Status struct {
+	Name     string
+	Schedule string
+	Timeout  time.Duration
+	Disabled bool
+	Running  bool
+	// NextRun is zero for disabled jobs and before the scheduler has started
+	NextRun time.Time
+
+	// The last finished run; zero until the job has run once
+	LastRun      time.Time
+	LastDuration time.Duration
+	LastResult   Result
+	LastError    string
+	LastSuccess  time.Time
+}
+
+// Params are the dependencies of NewScheduler
+type Params struct {
+	fx.In
+
+	Loader  configx.Loader
+	Metrics *Metrics
+	Log     logx.Logger
+	Jobs    []Job  `group:"scheduler.jobs"`
+	Leader  Leader `optional:"true"`
+}
+
+// Scheduler runs jobs on their cron schedules
+type Scheduler struct {
+	cron    *cron.Cron
+	jobs    map[string]*entry
+	names   []string
+	metrics *Metrics
+	log     logx.Logger
+	// leader is nil when every replica runs every job
+	leader Leader
+
+	// runCtx is the parent context of every run; it is cancelled when
+	// shutdown runs out of time waiting for jobs
+	runCtx     context.Context
+	cancelRuns context.CancelFunc
+
+	mu      sync.Mutex
+	stopped bool
+	runs    sync.WaitGroup
+}
+
+// entry is a registered job and the record of its last run
+type entry struct {
+	job     Job
+	cfg     JobConfig
+	id      cron.EntryID
+	running atomic.Bool
+
+	mu   sync.Mutex
+	last Status
+}
+
+// NewScheduler creates the scheduler from the scheduler config section and every
+// job in the "scheduler.jobs" group. It fails if a job has no valid schedule.
+func NewScheduler(p Params) (*Scheduler, error) {
+	var cfg Config
+	if err := p.Loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load scheduler config: %w", err)
+	}
+	s, err := newScheduler(cfg, p.Jobs, p.Metrics, p.Log)
+	if err != nil {
+		return nil, err
+	}
+	s.leader = p.Leader
+	return s, nil
+}
+
+func newScheduler(cfg Config, jobs []Job, metrics *Metrics, log logx.Logger) (*Scheduler, error) {
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scheduler.timezone %q: %w", cfg.Timezone, err)
+	}
+
+	s := &Scheduler{
+		cron:    cron.New(cron.WithLocation(loc)),
+		jobs:    make(map[string]*entry, len(jobs)),
+		metrics: metrics,
+		log:     log,
+	}
+
+	for _, job := range jobs {
+		name := job.Name()
+		if _, ok := s.jobs[name]; ok {
+			return nil, fmt.Errorf("two jobs share the name %q", name)
+		}
+
+		jc, ok := cfg.Jobs[name]
+		if !ok || jc.Schedule == "" {
+			return nil, fmt.Errorf("scheduler.jobs.%s.schedule is not set", name)
+		}
+		schedule, err := cron.ParseStandard(jc.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scheduler.jobs.%s.schedule %q: %w", name, jc.Schedule, err)
+		}
+		if jc.Timeout <= 0 {
+			jc.Timeout = cfg.DefaultTimeout
+		}
+
+		e := &entry{job: job, cfg: jc}
+		if !jc.Disabled {
+			e.id = s.cron.Schedule(schedule, cron.FuncJob(func() {
+				_, _ = s.run(e)
+			}))
+		}
+		s.jobs[name] = e
+		s.names = append(s.names, name)
+	}
+
+	// Config keys are matched by name, so a typo would leave a job unscheduled
+	for name := range cfg.Jobs {
+		if _, ok := s.jobs[name]; !ok {
+			return nil, fmt.Errorf("scheduler.jobs.%s does not match any registered job", name)
+		}
+	}
+	sort.Strings(s.names)
+
+	s.runCtx, s.cancelRuns = context.WithCancel(context.Background())
+	return s, nil
+}
+
+// Register ties the scheduler to the application lifecycle.
+// This function is designed to be used with fx.Invoke.
+func Register(lc fx.Lifecycle, s *Scheduler) {
+	lc.Append(fx.Hook{
+		OnStart: s.start,
+		OnStop:  s.stop,
+	})
+}
+
+// start begins running jobs on their schedules
+func (s *Scheduler) start(ctx context.Context) error {
+	s.cron.Start()
+
+	for _, status := range s.Jobs() {
+		if status.Disabled {
+			s.log.Info("job disabled", logx.String("job", status.Name))
+			continue
+		}
+		s.log.Info("job scheduled",
+			logx.String("job", status.Name),
+			logx.String("schedule", status.Schedule),
+			logx.String("timeout", status.Timeout.String()),
+			logx.String("next_run", status.NextRun.Format(time.RFC3339)),
+		)
+	}
+	return nil
+}
+
+// stop starts no more runs and waits for the running ones to finish. Jobs still
+// running when ctx expires are cancelled.
+func (s *Scheduler) stop(ctx context.Context) error {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+	s.cron.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		s.runs.Wait()
+		close(done)
+	}()
+
+	defer s.cancelRuns()
+	select {
+	case <-done:
+		s.log.Info("scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		running := s.running()
+		s.log.Warn("jobs still running at shutdown, cancelling them", logx.String("jobs", strings.Join(running, ",")))
+		return fmt.Errorf("jobs still running at shutdown: %s", strings.Join(running, ", "))
+	}
+}
+
+// Trigger runs a job now, outside its schedule, and returns its status once the
+// run has finished. It fails with ErrJobRunning if the job is already running,
+// and with ErrNotLeader on a replica that does not lead.
+func (s *Scheduler) Trigger(name string) (Status, error) {
+	e, ok := s.jobs[name]
+	if !ok {
+		return Status{}, ErrUnknownJob
+	}
+	if _, err := s.run(e); err != nil {
+		return Status{}, err
+	}
+	return s.status(e), nil
+}
+
+// Jobs returns the status of every job, sorted by name
+func (s *Scheduler) Jobs() []Status {
+	statuses := make([]Status, 0, len(s.names))
+	for _, name := range s.names {
+		statuses = append(statuses, s.status(s.jobs[name]))
+	}
+	return statuses
+}
+
+// Job returns the status of one job
+func (s *Scheduler) Job(name string) (Status, error) {
+	e, ok := s.jobs[name]
+	if !ok {
+		return Status{}, ErrUnknownJob
+	}
+	return s.status(e), nil
+}
+
+func (s *Scheduler) status(e *entry) Status {
+	e.mu.Lock()
+	status := e.last
+	e.mu.Unlock()
+
+	status.Name = e.job.Name()
+	status.Schedule = e.cfg.Schedule
+	status.Timeout = e.cfg.Timeout
+	status.Disabled = e.cfg.Disabled
+	status.Running = e.running.Load()
+	if e.id != 0 {
+		status.NextRun = s.cron.Entry(e.id).Next
+	}
+	return status
+}
+
+// running returns the names of the jobs with a run in progress
+func (s *Scheduler) running() []string {
+	var names []string
+	for _, name := range s.names {
+		if s.jobs[name].running.Load() {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// run runs a job once, unless it is still running from the last time, the
+// scheduler is stopping or another replica leads. The error only reports why a
+// run did not start; how the run itself went is in the result.
+func (s *Scheduler) run(e *entry) (Result, error) {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return "", ErrStopped
+	}
+	s.runs.Add(1)
+	s.mu.Unlock()
+	defer s.runs.Done()
+
+	name := e.job.Name()
+	parent, endTerm, err := s.term()
+	if err != nil {
+		// Not a skipped run: the leader runs it
+		s.log.Debug("not the leader, leaving the run to it", logx.String("job", name))
+		return "", err
+	}
+	defer endTerm()
+	if !e.running.CompareAndSwap(false, true) {
+		s.log.Warn("previous run still in progress, skipping", logx.String("job", name))
+		s.metrics.runs.Inc(name, string(ResultSkipped))
+		return ResultSkipped, ErrJobRunning
+	}
+	defer e.running.Store(false)
+
+	s.metrics.running.Set(1, name)
+	defer s.metrics.running.Set(0, name)
+
+	ctx, cancel := context.WithTimeout(parent, e.cfg.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err = call(ctx, e.job)
+	elapsed := time.Since(start)
+	result := classify(ctx, err)
+
+	e.mu.Lock()
+	e.last.LastRun = start
+	e.last.LastDuration = elapsed
+	e.last.LastResult = result
+	e.last.LastError = ""
+	if err != nil {
+		e.last.LastError = err.Error()
+	}
+	if result == ResultSuccess {
+		e.last.LastSuccess = start
+	}
+	e.mu.Unlock()
+
+	finished := float64(time.Now().Unix())
+	s.metrics.runs.Inc(name, string(result))
+	s.metrics.duration.Observe(elapsed.Seconds(), name)
+	s.metrics.lastRun.Set(finished, name)
+
+	fields := []logx.Field{
+		logx.String("job", name),
+		logx.String("result", string(result)),
+		logx.String("duration", elapsed.String()),
+	}
+	switch result {
+	case ResultSuccess:
+		s.metrics.lastSuccess.Set(finished, name)
+		s.log.Info("job finished", fields...)
+	case ResultTimeout:
+		s.log.Error("job timed out", append(fields, logx.String("timeout", e.cfg.Timeout.String()), logx.Err(err))...)
+	default:
+		s.log.Error("job failed", append(fields, logx.Err(err))...)
+	}
+
+	return result, nil
+}
+
+// term returns the parent context of a run: cancelled on shutdown and, with a
+// Leader, as soon as this replica stops leading
+func (s *Scheduler) term() (context.Context, context.CancelFunc, error) {
+	if s.leader == nil {
+		return s.runCtx, func() {}, nil
+	}
+	leading, ok := s.leader.Leading()
+	if !ok {
+		return nil, nil, ErrNotLeader
+	}
+
+	ctx, cancel := context.WithCancel(s.runCtx)
+	stop := context.AfterFunc(leading, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}, nil
+}
+
+// call runs the job, turning a panic into an error so one bad run does not
+// take down the scheduler
+func call(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// classify decides the result of a run from its error and its context
+func classify(ctx context.Context, err error) Result {
+	switch {
+	case err == nil:
+		return ResultSuccess
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return ResultTimeout
+	case ctx.Err() != nil:
+		return ResultCancelled
+	default:
+		return ResultFailure
+	}
+}
@@ -0,0 +1,344 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// funcJob adapts a function to Job
+type funcJob struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+func (j funcJob) Name() string                  { return j.name }
+func (j funcJob) Run(ctx context.Context) error { return j.run(ctx) }
+
+// recorder stores the last value set and the number of increments per metric and labels
+type recorder struct {
+	name string
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func (r *recorder) key(labels []string) string {
+	return r.name + "{" + strings.Join(labels, ",") + "}"
+}
+
+func (r *recorder) Inc(labels ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[r.key(labels)]++
+}
+
+func (r *recorder) Observe(v float64, labels ...string) {
+	r.Inc(labels...)
+}
+
+func (r *recorder) Set(v float64, labels ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[r.key(labels)] = v
+}
+
+func (r *recorder) get(labels ...string) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.values[r.key(labels)]
+}
+
+func newTestMetrics() *Metrics {
+	m := func(name string) *recorder { return &recorder{name: name, values: map[string]float64{}} }
+	return &Metrics{
+		runs:        m("runs"),
+		duration:    m("duration"),
+		lastRun:     m("last_run"),
+		lastSuccess: m("last_success"),
+		running:     m("running"),
+	}
+}
+
+func newTestScheduler(t *testing.T, jobs ...Job) (*Scheduler, *Metrics) {
+	t.Helper()
+
+	cfg := Config{Timezone: "UTC", DefaultTimeout: time.Second, Jobs: map[string]JobConfig{}}
+	for _, j := range jobs {
+		cfg.Jobs[j.Name()] = JobConfig{Schedule: "@every 1h"}
+	}
+	metrics := newTestMetrics()
+	s, err := newScheduler(cfg, jobs, metrics, logx.NewNoopLogger())
+	require.NoError(t, err)
+	return s, metrics
+}
+
+func TestTriggerRecordsResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		run     func(ctx context.Context) error
+		timeout time.Duration
+		want    Result
+	}{
+		{name: "success", run: func(ctx context.Context) error { return nil }, want: ResultSuccess},
+		{name: "failure", run: func(ctx context.Context) error { return errors.New("boom") }, want: ResultFailure},
+		{name: "panic", run: func(ctx context.Context) error { panic("boom") }, want: ResultFailure},
+		{name: "timeout", timeout: 20 * time.Millisecond, want: ResultTimeout, run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, metrics := newTestScheduler(t, funcJob{name: "job", run: tt.run})
+			if tt.timeout > 0 {
+				s.jobs["job"].cfg.Timeout = tt.timeout
+			}
+
+			status, err := s.Trigger("job")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, status.LastResult)
+			assert.False(t, status.LastRun.IsZero())
+			assert.False(t, status.Running)
+			assert.Equal(t, float64(1), metrics.runs.(*recorder).get("job", string(tt.want)))
+			assert.NotZero(t, metrics.lastRun.(*recorder).get("job"))
+
+			if tt.want == ResultSuccess {
+				assert.Empty(t, status.LastError)
+				assert.Equal(t, status.LastRun, status.LastSuccess)
+				assert.NotZero(t, metrics.lastSuccess.(*recorder).get("job"))
+			} else {
+				assert.NotEmpty(t, status.LastError)
+				assert.True(t, status.LastSuccess.IsZero())
+				assert.Zero(t, metrics.lastSuccess.(*recorder).get("job"))
+			}
+		})
+	}
+}
+
+func TestOverlappingRunIsSkipped(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	s, metrics := newTestScheduler(t, funcJob{name: "slow", run: func(ctx context.Context) error {
+		close(started)
+		<-release
+		return nil
+	}})
+
+	done := make(chan Status)
+	go func() {
+		status, _ := s.Trigger("slow")
+		done <- status
+	}()
+	<-started
+
+	status, err := s.Job("slow")
+	require.NoError(t, err)
+	assert.True(t, status.Running)
+	assert.Equal(t, float64(1), metrics.running.(*recorder).get("slow"))
+
+	_, err = s.Trigger("slow")
+	assert.ErrorIs(t, err, ErrJobRunning)
+	assert.Equal(t, float64(1), metrics.runs.(*recorder).get("slow", string(ResultSkipped)))
+
+	close(release)
+	assert.Equal(t, ResultSuccess, (<-done).LastResult)
+	assert.Equal(t, float64(0), metrics.running.(*recorder).get("slow"))
+}
+
+func TestStopWaitsForRunningJobs(t *testing.T) {
+	started := make(chan struct{})
+	s, _ := newTestScheduler(t, funcJob{name: "job", run: func(ctx context.Context) error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}})
+	require.NoError(t, s.start(context.Background()))
+
+	done := make(chan Status)
+	go func() {
+		status, _ := s.Trigger("job")
+		done <- status
+	}()
+	<-started
+
+	require.NoError(t, s.stop(context.Background()))
+	assert.Equal(t, ResultSuccess, (<-done).LastResult)
+
+	_, err := s.Trigger("job")
+	assert.ErrorIs(t, err, ErrStopped)
+}
+
+func TestStopCancelsJobsAfterTimeout(t *testing.T) {
+	started := make(chan struct{})
+	s, _ := newTestScheduler(t, funcJob{name: "stuck", run: func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	s.jobs["stuck"].cfg.Timeout = time.Minute
+	require.NoError(t, s.start(context.Background()))
+
+	done := make(chan Status)
+	go func() {
+		status, _ := s.Trigger("stuck")
+		done <- status
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := s.stop(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stuck")
+	assert.Equal(t, ResultCancelled, (<-done).LastResult)
+}
+
+// fakeLeader leads until lose is called, or not at all
+type fakeLeader struct {
+	term context.Context
+	lose context.CancelFunc
+	ok   bool
+}
+
+func (l *fakeLeader) Leading() (context.Context, bool) {
+	return l.term, l.ok
+}
+
+func TestFollowerRunsNothing(t *testing.T) {
+	ran := false
+	s, metrics := newTestScheduler(t, funcJob{name: "job", run: func(ctx context.Context) error {
+		ran = true
+		return nil
+	}})
+	s.leader = &fakeLeader{term: context.Background()}
+
+	_, err := s.Trigger("job")
+	assert.ErrorIs(t, err, ErrNotLeader)
+	assert.False(t, ran)
+	assert.Zero(t, metrics.runs.(*recorder).get("job", string(ResultSkipped)))
+
+	status, err := s.Job("job")
+	require.NoError(t, err)
+	assert.True(t, status.LastRun.IsZero())
+}
+
+func TestLosingLeadershipCancelsRuns(t *testing.T) {
+	started := make(chan struct{})
+	s, _ := newTestScheduler(t, funcJob{name: "job", run: func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	s.jobs["job"].cfg.Timeout = time.Minute
+	leader := &fakeLeader{ok: true}
+	leader.term, leader.lose = context.WithCancel(context.Background())
+	s.leader = leader
+
+	done := make(chan Status)
+	go func() {
+		status, _ := s.Trigger("job")
+		done <- status
+	}()
+	<-started
+
+	leader.lose()
+	assert.Equal(t, ResultCancelled, (<-done).LastResult)
+}
+
+func TestNewScheduler(t *testing.T) {
+	job := funcJob{name: "job", run: func(ctx context.Context) error { return nil }}
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		jobs    []Job
+		wantErr string
+	}{
+		{
+			name: "valid",
+			cfg:  Config{Timezone: "Europe/Berlin", Jobs: map[string]JobConfig{"job": {Schedule: "*/5 * * * *"}}},
+			jobs: []Job{job},
+		},
+		{
+			name:    "missing schedule",
+			cfg:     Config{Timezone: "UTC"},
+			jobs:    []Job{job},
+			wantErr: "scheduler.jobs.job.schedule is not set",
+		},
+		{
+			name:    "invalid schedule",
+			cfg:     Config{Timezone: "UTC", Jobs: map[string]JobConfig{"job": {Schedule: "every minute"}}},
+			jobs:    []Job{job},
+			wantErr: "invalid scheduler.jobs.job.schedule",
+		},
+		{
+			name:    "config for unknown job",
+			cfg:     Config{Timezone: "UTC", Jobs: map[string]JobConfig{"job": {Schedule: "@hourly"}, "jbo": {Schedule: "@hourly"}}},
+			jobs:    []Job{job},
+			wantErr: "scheduler.jobs.jbo does not match any registered job",
+		},
+		{
+			name:    "duplicate name",
+			cfg:     Config{Timezone: "UTC", Jobs: map[string]JobConfig{"job": {Schedule: "@hourly"}}},
+			jobs:    []Job{job, job},
+			wantErr: `two jobs share the name "job"`,
+		},
+		{
+			name:    "invalid timezone",
+			cfg:     Config{Timezone: "Mars/Olympus"},
+			wantErr: "invalid scheduler.timezone",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newScheduler(tt.cfg, tt.jobs, newTestMetrics(), logx.NewNoopLogger())
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestJobsStatus(t *testing.T) {
+	cfg := Config{
+		Timezone:       "UTC",
+		DefaultTimeout: time.Minute,
+		Jobs: map[string]JobConfig{
+			"b": {Schedule: "@hourly", Timeout: 10 * time.Second},
+			"a": {Schedule: "@daily", Disabled: true},
+		},
+	}
+	noop := func(ctx context.Context) error { return nil }
+	s, err := newScheduler(cfg, []Job{funcJob{name: "b", run: noop}, funcJob{name: "a", run: noop}}, newTestMetrics(), logx.NewNoopLogger())
+	require.NoError(t, err)
+	require.NoError(t, s.start(context.Background()))
+	defer s.stop(context.Background())
+
+	jobs := s.Jobs()
+	require.Len(t, jobs, 2)
+
+	assert.Equal(t, "a", jobs[0].Name)
+	assert.True(t, jobs[0].Disabled)
+	assert.Equal(t, time.Minute, jobs[0].Timeout)
+	assert.True(t, jobs[0].NextRun.IsZero())
+
+	assert.Equal(t, "b", jobs[1].Name)
+	assert.Equal(t, 10*time.Second, jobs[1].Timeout)
+	assert.True(t, jobs[1].NextRun.After(time.Now()))
+
+	_, err = s.Job("c")
+	assert.ErrorIs(t, err, ErrUnknownJob)
+}
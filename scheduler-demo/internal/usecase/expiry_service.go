@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ExpiryService expires orders that were never paid
+type ExpiryService struct {
+	repo OrderRepository
+	now  func() time.Time
+}
+
+// NewExpiryService creates a new expiry service with repository injection
+func NewExpiryService(repo OrderRepository) *ExpiryService {
+	return &ExpiryService{repo: repo, now: time.Now}
+}
+
+// ExpireStale expires every order that has been pending for maxAge or longer,
+// batchSize orders at a time. It stops early when ctx is done and returns the
+// number of orders expired so far together with the error.
+func (s *ExpiryService) ExpireStale(ctx context.Context, maxAge time.Duration, batchSize int) (int, error) {
+	if maxAge <= 0 || batchSize <= 0 {
+		return 0, ErrInvalid
+	}
+
+	cutoff := s.now().Add(-maxAge)
+	expired := 0
+	for {
+		orders, err := s.repo.ListPending(ctx, cutoff, batchSize)
+		if err != nil {
+			return expired, s.translateError(err)
+		}
+		before := expired
+
+		for _, o := range orders {
+			if err := ctx.Err(); err != nil {
+				return expired, err
+			}
+			now := s.now()
+			if !o.IsStale(maxAge, now) {
+				continue
+			}
+			if err := o.Expire(now); err != nil {
+				continue
+			}
+			if err := s.repo.Update(ctx, o); err != nil {
+				// Paid after it was listed; the payment wins
+				if errors.Is(err, ErrConflict) {
+					continue
+				}
+				return expired, s.translateError(err)
+			}
+			expired++
+		}
+
+		// A batch in which nothing could be expired would be listed again unchanged
+		if len(orders) < batchSize || expired == before {
+			return expired, nil
+		}
+	}
+}
+
+// translateError converts repository/domain errors to usecase errors
+func (s *ExpiryService) translateError(err error) error {
+	// Domain and context errors pass through
+	if errors.Is(err, ErrConflict) || errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalid) {
+		return err
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	// All other errors are infrastructure/availability issues
+	return ErrUnavailable
+}
@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/gostratum/examples/scheduler-demo/internal/domain"
+)
+
+// OrderRepository defines the interface for order data operations
+// This interface is owned by the use case layer (dependency inversion principle)
+type OrderRepository interface {
+	Save(ctx context.Context, o *domain.Order) error
+	// Update stores a status change. It returns domain.ErrConflict if the stored
+	// order is no longer pending, so a concurrent payment is never overwritten.
+	Update(ctx context.Context, o *domain.Order) error
+	// ListPending returns up to limit pending orders created before the cutoff, oldest first
+	ListPending(ctx context.Context, createdBefore time.Time, limit int) ([]*domain.Order, error)
+	// ListCreated returns the orders created in [from, to)
+	ListCreated(ctx context.Context, from, to time.Time) ([]*domain.Order, error)
+}
+
+// ChargeLedger records the charges made for orders
+// This interface is owned by the use case layer (dependency inversion principle)
+type ChargeLedger interface {
+	Record(ctx context.Context, c *domain.Charge) error
+	// ListByOrders returns every charge made for the given orders
+	ListByOrders(ctx context.Context, orderIDs []string) ([]*domain.Charge, error)
+}
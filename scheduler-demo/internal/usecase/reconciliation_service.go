@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gostratum/examples/scheduler-demo/internal/domain"
+)
+
+// ReconciliationService checks that orders and charges agree
+type ReconciliationService struct {
+	orders  OrderRepository
+	charges ChargeLedger
+}
+
+// NewReconciliationService creates a new reconciliation service with repository injection
+func NewReconciliationService(orders OrderRepository, charges ChargeLedger) *ReconciliationService {
+	return &ReconciliationService{orders: orders, charges: charges}
+}
+
+// Reconcile compares the orders created in [from, to) with their charges
+func (s *ReconciliationService) Reconcile(ctx context.Context, from, to time.Time) (*domain.Report, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalid
+	}
+
+	orders, err := s.orders.ListCreated(ctx, from, to)
+	if err != nil {
+		return nil, s.translateError(err)
+	}
+
+	ids := make([]string, len(orders))
+	for i, o := range orders {
+		ids[i] = o.ID
+	}
+	charges, err := s.charges.ListByOrders(ctx, ids)
+	if err != nil {
+		return nil, s.translateError(err)
+	}
+
+	return &domain.Report{
+		From:       from,
+		To:         to,
+		Orders:     len(orders),
+		Charges:    len(charges),
+		Mismatches: domain.Reconcile(orders, charges),
+	}, nil
+}
+
+// translateError converts repository/domain errors to usecase errors
+func (s *ReconciliationService) translateError(err error) error {
+	// Context errors pass through, so a timed out run is reported as one
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	// All other errors are infrastructure/availability issues
+	return ErrUnavailable
+}
@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gostratum/examples/scheduler-demo/internal/domain"
+)
+
+// MockOrderRepository implements OrderRepository for testing
+type MockOrderRepository struct {
+	orders      map[string]*domain.Order
+	listCalls   int
+	updateError error
+	// paidOnUpdate simulates orders paid between listing and updating
+	paidOnUpdate map[string]bool
+}
+
+func NewMockOrderRepository(orders ...*domain.Order) *MockOrderRepository {
+	m := &MockOrderRepository{orders: make(map[string]*domain.Order), paidOnUpdate: make(map[string]bool)}
+	for _, o := range orders {
+		m.orders[o.ID] = o
+	}
+	return m
+}
+
+func (m *MockOrderRepository) Save(ctx context.Context, o *domain.Order) error {
+	m.orders[o.ID] = o
+	return nil
+}
+
+func (m *MockOrderRepository) Update(ctx context.Context, o *domain.Order) error {
+	if m.updateError != nil {
+		return m.updateError
+	}
+	if m.paidOnUpdate[o.ID] {
+		return domain.ErrConflict
+	}
+	m.orders[o.ID] = o
+	return nil
+}
+
+func (m *MockOrderRepository) ListPending(ctx context.Context, createdBefore time.Time, limit int) ([]*domain.Order, error) {
+	m.listCalls++
+	var orders []*domain.Order
+	for _, o := range m.sorted() {
+		if o.Status == domain.OrderPending && o.CreatedAt.Before(createdBefore) {
+			copied := *o
+			orders = append(orders, &copied)
+		}
+	}
+	if len(orders) > limit {
+		orders = orders[:limit]
+	}
+	return orders, nil
+}
+
+func (m *MockOrderRepository) ListCreated(ctx context.Context, from, to time.Time) ([]*domain.Order, error) {
+	var orders []*domain.Order
+	for _, o := range m.sorted() {
+		if !o.CreatedAt.Before(from) && o.CreatedAt.Before(to) {
+			orders = append(orders, o)
+		}
+	}
+	return orders, nil
+}
+
+func (m *MockOrderRepository) sorted() []*domain.Order {
+	orders := make([]*domain.Order, 0, len(m.orders))
+	for _, o := range m.orders {
+		orders = append(orders, o)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID < orders[j].ID })
+	return orders
+}
+
+// MockChargeLedger implements ChargeLedger for testing
+type MockChargeLedger struct {
+	charges   []*domain.Charge
+	listError error
+}
+
+func (m *MockChargeLedger) Record(ctx context.Context, c *domain.Charge) error {
+	m.charges = append(m.charges, c)
+	return nil
+}
+
+func (m *MockChargeLedger) ListByOrders(ctx context.Context, orderIDs []string) ([]*domain.Charge, error) {
+	if m.listError != nil {
+		return nil, m.listError
+	}
+	wanted := make(map[string]bool, len(orderIDs))
+	for _, id := range orderIDs {
+		wanted[id] = true
+	}
+	var charges []*domain.Charge
+	for _, c := range m.charges {
+		if wanted[c.OrderID] {
+			charges = append(charges, c)
+		}
+	}
+	return charges, nil
+}
+
+func newOrder(id string, age time.Duration, now time.Time) *domain.Order {
+	o := domain.NewOrder("user1", 1000, "USD", now.Add(-age))
+	o.ID = id
+	return o
+}
+
+func TestExpireStale(t *testing.T) {
+	now := time.Now()
+	paid := newOrder("paid", time.Hour, now)
+	_ = paid.Pay(now)
+
+	repo := NewMockOrderRepository(
+		newOrder("old1", time.Hour, now),
+		newOrder("old2", time.Hour, now),
+		newOrder("old3", time.Hour, now),
+		newOrder("racing", time.Hour, now),
+		newOrder("new", time.Minute, now),
+		paid,
+	)
+	repo.paidOnUpdate["racing"] = true
+	service := NewExpiryService(repo)
+
+	expired, err := service.ExpireStale(context.Background(), 15*time.Minute, 2)
+	if err != nil {
+		t.Fatalf("ExpireStale() error = %v", err)
+	}
+	if expired != 3 {
+		t.Errorf("expired = %d, want 3", expired)
+	}
+	// The last batch only holds the order that keeps conflicting, so it ends the run
+	if repo.listCalls != 3 {
+		t.Errorf("ListPending called %d times, want 3 batches", repo.listCalls)
+	}
+	for id, want := range map[string]string{
+		"old1": domain.OrderExpired,
+		"old3": domain.OrderExpired,
+		"new":  domain.OrderPending,
+		"paid": domain.OrderPaid,
+	} {
+		if got := repo.orders[id].Status; got != want {
+			t.Errorf("order %s status = %s, want %s", id, got, want)
+		}
+	}
+}
+
+func TestExpireStaleErrors(t *testing.T) {
+	now := time.Now()
+
+	repo := NewMockOrderRepository(newOrder("old", time.Hour, now))
+	repo.updateError = errors.New("connection refused")
+	_, err := NewExpiryService(repo).ExpireStale(context.Background(), time.Minute, 10)
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("error = %v, want ErrUnavailable", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, err := NewExpiryService(NewMockOrderRepository(newOrder("old", time.Hour, now))).ExpireStale(ctx, time.Minute, 10)
+	if !errors.Is(err, context.Canceled) || expired != 0 {
+		t.Errorf("ExpireStale() = %d, %v, want 0, context.Canceled", expired, err)
+	}
+
+	if _, err := NewExpiryService(repo).ExpireStale(context.Background(), 0, 10); !errors.Is(err, ErrInvalid) {
+		t.Errorf("error = %v, want ErrInvalid for zero max age", err)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	now := time.Now()
+	inWindow := newOrder("in-window", 30*time.Minute, now)
+	_ = inWindow.Pay(now)
+	tooOld := newOrder("too-old", 3*time.Hour, now)
+	_ = tooOld.Pay(now)
+
+	ledger := &MockChargeLedger{}
+	service := NewReconciliationService(NewMockOrderRepository(inWindow, tooOld), ledger)
+
+	report, err := service.Reconcile(context.Background(), now.Add(-time.Hour), now)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if report.Orders != 1 || len(report.Mismatches) != 1 || report.Mismatches[0].OrderID != "in-window" {
+		t.Errorf("report = %+v, want one missing charge for in-window", report)
+	}
+
+	ledger.listError = errors.New("connection refused")
+	if _, err := service.Reconcile(context.Background(), now.Add(-time.Hour), now); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("error = %v, want ErrUnavailable", err)
+	}
+
+	ledger.listError = context.DeadlineExceeded
+	if _, err := service.Reconcile(context.Background(), now.Add(-time.Hour), now); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want context.DeadlineExceeded", err)
+	}
+
+	if _, err := service.Reconcile(context.Background(), now, now); !errors.Is(err, ErrInvalid) {
+		t.Errorf("error = %v, want ErrInvalid for an empty window", err)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/dbx"
+	"github.com/gostratum/examples/redact"
+	httpAdapter "github.com/gostratum/examples/shortener-demo/internal/adapter/http"
+	redisAdapter "github.com/gostratum/examples/shortener-demo/internal/adapter/redis"
+	repoAdapter "github.com/gostratum/examples/shortener-demo/internal/adapter/repo"
+	"github.com/gostratum/examples/shortener-demo/internal/clicks"
+	"github.com/gostratum/examples/shortener-demo/internal/usecase"
+	"github.com/gostratum/httpx"
+)
+
+func main() {
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// Include dbx module; it migrates the database on startup
+		dbx.Module(),
+
+		// Include httpx module
+		httpx.Module(),
+
+		// Adds the clicks counted in Redis to the database every interval
+		clicks.Module(),
+
+		// Provide dependencies
+		fx.Provide(
+			// GORM repositories
+			repoAdapter.NewLinkRepo,
+
+			// Redis link cache and click counter
+			redisAdapter.NewClient,
+			redisAdapter.NewLinkCache,
+			redisAdapter.NewClickCounter,
+
+			// Usecase services
+			usecase.NewLinkService,
+
+			// HTTP handlers
+			httpAdapter.NewLinkHandler,
+		),
+
+		// Invoke setup functions
+		fx.Invoke(
+			redisAdapter.Register,
+			httpAdapter.RegisterRoutes,
+		),
+	)
+
+	app.Run()
+}
@@ -0,0 +1,19 @@
+package http
+
+import "strings"
+
+// Config configures the links the API hands out
+type Config struct {
+	// BaseURL is where this service is reached; short URLs are BaseURL/<code>
+	BaseURL string `mapstructure:"base_url" default:"http://localhost:8099"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "shortener"
+}
+
+// shortURL returns the short URL of code
+func (c Config) shortURL(code string) string {
+	return strings.TrimSuffix(c.BaseURL, "/") + "/" + code
+}
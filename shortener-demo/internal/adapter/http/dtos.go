@@ -0,0 +1,35 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gostratum/examples/shortener-demo/internal/domain"
+)
+
+// ShortenRequest represents the request payload for shortening a URL
+type ShortenRequest struct {
+	URL string `json:"url" binding:"required"`
+}
+
+// LinkResponse is the HTTP DTO for link data
+type LinkResponse struct {
+	Code      string    `json:"code"`
+	URL       string    `json:"url"`
+	ShortURL  string    `json:"short_url"`
+	Clicks    int64     `json:"clicks"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FromDomainLink converts a domain.Link to LinkResponse DTO
+func FromDomainLink(link *domain.Link, shortURL string) *LinkResponse {
+	if link == nil {
+		return nil
+	}
+	return &LinkResponse{
+		Code:      link.Code,
+		URL:       link.URL,
+		ShortURL:  shortURL,
+		Clicks:    link.Clicks,
+		CreatedAt: link.CreatedAt,
+	}
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/shortener-demo/internal/domain"
+	"github.com/gostratum/examples/shortener-demo/internal/usecase"
+)
+
+// Links shortens and resolves links; implemented by usecase.LinkService
+type Links interface {
+	Shorten(ctx context.Context, url string) (*domain.Link, error)
+	Resolve(ctx context.Context, code string) (string, error)
+	Stats(ctx context.Context, code string) (*domain.Link, error)
+}
+
+// LinkHandler handles link-related HTTP requests
+type LinkHandler struct {
+	cfg     Config
+	service Links
+	log     logx.Logger
+}
+
+// NewLinkHandler creates a new link handler from the shortener config section
+func NewLinkHandler(service *usecase.LinkService, loader configx.Loader, log logx.Logger) (*LinkHandler, error) {
+	var cfg Config
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load shortener config: %w", err)
+	}
+	return newLinkHandler(cfg, service, log), nil
+}
+
+func newLinkHandler(cfg Config, service Links, log logx.Logger) *LinkHandler {
+	return &LinkHandler{cfg: cfg, service: service, log: log}
+}
+
+// Shorten handles POST /links
+func (h *LinkHandler) Shorten(c *gin.Context) {
+	var req ShortenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid request payload", nil)
+		return
+	}
+
+	link, err := h.service.Shorten(c.Request.Context(), req.URL)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.Created(c, "/links/"+link.Code, FromDomainLink(link, h.cfg.shortURL(link.Code)))
+}
+
+// Stats handles GET /links/:code
+func (h *LinkHandler) Stats(c *gin.Context) {
+	link, err := h.service.Stats(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.OK(c, FromDomainLink(link, h.cfg.shortURL(link.Code)), nil)
+}
+
+// Redirect handles GET /:code. The redirect is a 302 rather than a 301,
+// which browsers would cache and follow without counting the click.
+func (h *LinkHandler) Redirect(c *gin.Context) {
+	url, err := h.service.Resolve(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Redirect(http.StatusFound, url)
+}
+
+// handleError maps usecase errors to HTTP responses
+func (h *LinkHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrNotFound):
+		responsex.Error(c, http.StatusNotFound, "NOT_FOUND", "link not found", nil)
+	case errors.Is(err, usecase.ErrInvalid):
+		responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
+	case errors.Is(err, usecase.ErrUnavailable):
+		h.log.Error("database unavailable", logx.Err(err))
+		c.Header("Retry-After", "2")
+		responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "service temporarily unavailable", nil)
+	default:
+		h.log.Error("unexpected error", logx.Err(err))
+		responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+	}
+}
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	redisAdapter "github.com/gostratum/examples/shortener-demo/internal/adapter/redis"
+	repoAdapter "github.com/gostratum/examples/shortener-demo/internal/adapter/repo"
+	"github.com/gostratum/examples/shortener-demo/internal/usecase"
+	"github.com/gostratum/examples/shortener-demo/migrations"
+	"github.com/gostratum/examples/testkit/containers"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(containers.Run(m))
+}
+
+// setupRouter serves the link routes from a LinkService on a database and a
+// Redis database of the test's own. The returned sql.DB is the connection
+// pool of the repository.
+func setupRouter(t *testing.T) (*gin.Engine, *sql.DB) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db := containers.PostgresGorm(t, containers.WithMigrations(migrations.FS))
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+
+	rdb := containers.Redis(t)
+	client := redisAdapter.NewClientFromConfig(redisAdapter.Config{
+		Addr:      rdb.Addr,
+		DB:        rdb.DB,
+		KeyPrefix: "test:",
+		CacheTTL:  time.Minute,
+		Timeout:   time.Second,
+	})
+	t.Cleanup(func() { client.Close() })
+
+	svc := usecase.NewLinkService(
+		repoAdapter.NewLinkRepo(db),
+		redisAdapter.NewLinkCache(client),
+		redisAdapter.NewClickCounter(client),
+		logx.NewNoopLogger(),
+	)
+	handler := newLinkHandler(Config{BaseURL: "https://sho.rt/"}, svc, logx.NewNoopLogger())
+
+	e := gin.New()
+	e.POST("/links", handler.Shorten)
+	e.GET("/links/:code", handler.Stats)
+	e.GET("/:code", handler.Redirect)
+	return e, sqlDB
+}
+
+func do(e *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w
+}
+
+func TestShortenRedirectAndStats(t *testing.T) {
+	t.Parallel()
+	e, _ := setupRouter(t)
+
+	w := do(e, http.MethodPost, "/links", `{"url":"https://example.com/docs?page=2"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var created responsex.Envelope[LinkResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	code := created.Data.Code
+	assert.Len(t, code, 7)
+	assert.Equal(t, "/links/"+code, w.Header().Get("Location"))
+	assert.Equal(t, "https://sho.rt/"+code, created.Data.ShortURL)
+
+	for range 2 {
+		w = do(e, http.MethodGet, "/"+code, "")
+		require.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, "https://example.com/docs?page=2", w.Header().Get("Location"))
+	}
+
+	w = do(e, http.MethodGet, "/links/"+code, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var stats responsex.Envelope[LinkResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, int64(2), stats.Data.Clicks)
+	assert.Equal(t, "https://example.com/docs?page=2", stats.Data.URL)
+}
+
+func TestUnknownLink(t *testing.T) {
+	t.Parallel()
+	e, _ := setupRouter(t)
+
+	for _, path := range []string{"/abcdefg", "/links/abcdefg", "/not-a-code"} {
+		w := do(e, http.MethodGet, path, "")
+		assert.Equal(t, http.StatusNotFound, w.Code, path)
+		assert.Contains(t, w.Body.String(), "NOT_FOUND", path)
+	}
+}
+
+func TestShortenInvalidInput(t *testing.T) {
+	t.Parallel()
+	e, _ := setupRouter(t)
+
+	w := do(e, http.MethodPost, "/links", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_REQUEST")
+
+	w = do(e, http.MethodPost, "/links", `{"url":"ftp://example.com/file"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_INPUT")
+}
+
+func TestDatabaseUnavailable(t *testing.T) {
+	t.Parallel()
+	e, sqlDB := setupRouter(t)
+	require.NoError(t, sqlDB.Close())
+
+	w := do(e, http.MethodPost, "/links", `{"url":"https://example.com"}`)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "SERVICE_UNAVAILABLE")
+}
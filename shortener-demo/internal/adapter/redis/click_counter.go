@@ -0,0 +1,108 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/gostratum/examples/shortener-demo/internal/usecase"
+)
+
+// takeScript moves the counted clicks (KEYS[1]) to the batch key (KEYS[2])
+// unless a batch is still there, and returns the batch. It runs as one
+// command, so no click is counted into a hash between the two steps.
+var takeScript = goredis.NewScript(`
+if redis.call('EXISTS', KEYS[2]) == 0 then
+  if redis.call('EXISTS', KEYS[1]) == 0 then
+    return {}
+  end
+  redis.call('RENAME', KEYS[1], KEYS[2])
+end
+return redis.call('HGETALL', KEYS[2])
+`)
+
+// ClickCounter implements usecase.ClickCounter with two hashes of counts by
+// code: the clicks counted since the last batch was taken, and the batch.
+// Only one flusher may take batches at a time.
+type ClickCounter struct {
+	c *Client
+}
+
+// NewClickCounter creates a click counter on the shared client
+func NewClickCounter(c *Client) usecase.ClickCounter {
+	return &ClickCounter{c: c}
+}
+
+// Incr implements usecase.ClickCounter
+func (cc *ClickCounter) Incr(ctx context.Context, code string) error {
+	ctx, cancel := cc.c.withTimeout(ctx)
+	defer cancel()
+
+	return cc.c.client.HIncrBy(ctx, cc.countedKey(), code, 1).Err()
+}
+
+// Pending implements usecase.ClickCounter
+func (cc *ClickCounter) Pending(ctx context.Context, code string) (int64, error) {
+	ctx, cancel := cc.c.withTimeout(ctx)
+	defer cancel()
+
+	pipe := cc.c.client.Pipeline()
+	counted := pipe.HGet(ctx, cc.countedKey(), code)
+	batched := pipe.HGet(ctx, cc.batchKey(), code)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, goredis.Nil) {
+		return 0, err
+	}
+
+	var total int64
+	for _, cmd := range []*goredis.StringCmd{counted, batched} {
+		n, err := cmd.Int64()
+		if errors.Is(err, goredis.Nil) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// Take implements usecase.ClickCounter
+func (cc *ClickCounter) Take(ctx context.Context) (map[string]int64, error) {
+	ctx, cancel := cc.c.withTimeout(ctx)
+	defer cancel()
+
+	fields, err := takeScript.Run(ctx, cc.c.client, []string{cc.countedKey(), cc.batchKey()}).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	batch := make(map[string]int64, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		n, err := strconv.ParseInt(fields[i+1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("click count of %s is not a number: %w", fields[i], err)
+		}
+		batch[fields[i]] = n
+	}
+	return batch, nil
+}
+
+// Ack implements usecase.ClickCounter
+func (cc *ClickCounter) Ack(ctx context.Context) error {
+	ctx, cancel := cc.c.withTimeout(ctx)
+	defer cancel()
+
+	return cc.c.client.Del(ctx, cc.batchKey()).Err()
+}
+
+func (cc *ClickCounter) countedKey() string {
+	return cc.c.key("clicks")
+}
+
+func (cc *ClickCounter) batchKey() string {
+	return cc.c.key("clicks:batch")
+}
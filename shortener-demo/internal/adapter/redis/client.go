@@ -0,0 +1,73 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	goredis "github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+)
+
+// Client is the Redis connection the link cache and the click counter share
+type Client struct {
+	cfg    Config
+	client *goredis.Client
+}
+
+// NewClient creates a Client from the redis config section
+func NewClient(loader configx.Loader) (*Client, error) {
+	var cfg Config
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load redis config: %w", err)
+	}
+	return NewClientFromConfig(cfg), nil
+}
+
+// NewClientFromConfig creates a Client from cfg. Connections are made when
+// the first command is sent.
+func NewClientFromConfig(cfg Config) *Client {
+	return &Client{
+		cfg: cfg,
+		client: goredis.NewClient(&goredis.Options{
+			Addr:     cfg.Addr,
+			Password: cfg.Password,
+			DB:       cfg.DB,
+		}),
+	}
+}
+
+// Register ties the Redis client to the application lifecycle.
+// This function is designed to be used with fx.Invoke.
+func Register(lc fx.Lifecycle, c *Client, log logx.Logger) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			// Redirects work without Redis, only slower and uncounted, so a
+			// Redis that is down is no reason not to start
+			if err := c.client.Ping(ctx).Err(); err != nil {
+				log.Warn("redis is unreachable; links are read from the database until it is back",
+					logx.String("addr", c.cfg.Addr), logx.Err(err))
+			}
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			return c.Close()
+		},
+	})
+}
+
+// Close closes the connections to Redis
+func (c *Client) Close() error {
+	return c.client.Close()
+}
+
+// key returns the Redis key of name
+func (c *Client) key(name string) string {
+	return c.cfg.KeyPrefix + name
+}
+
+// withTimeout bounds a Redis command
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, c.cfg.Timeout)
+}
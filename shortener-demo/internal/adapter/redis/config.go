@@ -0,0 +1,23 @@
+// Package redis keeps the link cache and the click counts in Redis
+package redis
+
+import "time"
+
+// Config says where Redis is and how long links stay cached
+type Config struct {
+	Addr     string `mapstructure:"addr" default:"localhost:6379"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db"`
+	// KeyPrefix is put in front of every key, so services can share a Redis
+	KeyPrefix string `mapstructure:"key_prefix" default:"shortener:"`
+	// CacheTTL is how long a resolved link stays cached. Links never change,
+	// so this only bounds the memory spent on links nobody follows anymore.
+	CacheTTL time.Duration `mapstructure:"cache_ttl" default:"1h"`
+	// Timeout bounds every Redis command
+	Timeout time.Duration `mapstructure:"timeout" default:"100ms"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "redis"
+}
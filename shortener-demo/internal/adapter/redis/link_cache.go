@@ -0,0 +1,47 @@
+package redis
+
+import (
+	"context"
+	"errors"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/gostratum/examples/shortener-demo/internal/usecase"
+)
+
+// LinkCache implements usecase.LinkCache with a string key per link
+type LinkCache struct {
+	c *Client
+}
+
+// NewLinkCache creates a link cache on the shared client
+func NewLinkCache(c *Client) usecase.LinkCache {
+	return &LinkCache{c: c}
+}
+
+// Get implements usecase.LinkCache
+func (l *LinkCache) Get(ctx context.Context, code string) (string, bool, error) {
+	ctx, cancel := l.c.withTimeout(ctx)
+	defer cancel()
+
+	url, err := l.c.client.Get(ctx, l.key(code)).Result()
+	if errors.Is(err, goredis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return url, true, nil
+}
+
+// Set implements usecase.LinkCache
+func (l *LinkCache) Set(ctx context.Context, code, url string) error {
+	ctx, cancel := l.c.withTimeout(ctx)
+	defer cancel()
+
+	return l.c.client.Set(ctx, l.key(code), url, l.c.cfg.CacheTTL).Err()
+}
+
+func (l *LinkCache) key(code string) string {
+	return l.c.key("link:" + code)
+}
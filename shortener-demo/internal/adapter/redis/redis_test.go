@@ -0,0 +1,135 @@
+package redis
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/testkit/containers"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(containers.Run(m))
+}
+
+// setupClient connects to a Redis database of the test's own
+func setupClient(t *testing.T) *Client {
+	t.Helper()
+	db := containers.Redis(t)
+
+	c := NewClientFromConfig(Config{Addr: db.Addr, DB: db.DB, KeyPrefix: "test:", CacheTTL: time.Minute, Timeout: time.Second})
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestLinkCache(t *testing.T) {
+	t.Parallel()
+	c := setupClient(t)
+	cache := NewLinkCache(c)
+	ctx := context.Background()
+
+	_, ok, err := cache.Get(ctx, "abc1234")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, cache.Set(ctx, "abc1234", "https://example.com"))
+
+	url, ok, err := cache.Get(ctx, "abc1234")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "https://example.com", url)
+
+	ttl, err := c.client.TTL(ctx, "test:link:abc1234").Result()
+	require.NoError(t, err)
+	assert.Greater(t, ttl, time.Duration(0))
+	assert.LessOrEqual(t, ttl, time.Minute)
+}
+
+func TestClickCounterBatches(t *testing.T) {
+	t.Parallel()
+	counter := NewClickCounter(setupClient(t))
+	ctx := context.Background()
+
+	batch, err := counter.Take(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, batch)
+
+	for _, code := range []string{"aaaaaaa", "aaaaaaa", "bbbbbbb"} {
+		require.NoError(t, counter.Incr(ctx, code))
+	}
+
+	batch, err = counter.Take(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"aaaaaaa": 2, "bbbbbbb": 1}, batch)
+
+	// Clicks after the take are counted apart from the batch
+	require.NoError(t, counter.Incr(ctx, "aaaaaaa"))
+	pending, err := counter.Pending(ctx, "aaaaaaa")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), pending)
+
+	// Until it is acknowledged, the same batch is handed out again
+	again, err := counter.Take(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, batch, again)
+
+	require.NoError(t, counter.Ack(ctx))
+	pending, err = counter.Pending(ctx, "aaaaaaa")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), pending)
+
+	batch, err = counter.Take(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"aaaaaaa": 1}, batch)
+	require.NoError(t, counter.Ack(ctx))
+
+	pending, err = counter.Pending(ctx, "bbbbbbb")
+	require.NoError(t, err)
+	assert.Zero(t, pending)
+}
+
+func TestClickCounterLosesNoClicksWhileTaking(t *testing.T) {
+	t.Parallel()
+	counter := NewClickCounter(setupClient(t))
+	ctx := context.Background()
+
+	const clickers, clicks = 4, 250
+	var wg sync.WaitGroup
+	for range clickers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range clicks {
+				assert.NoError(t, counter.Incr(ctx, "aaaaaaa"))
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	var total int64
+	take := func() {
+		batch, err := counter.Take(ctx)
+		require.NoError(t, err)
+		total += batch["aaaaaaa"]
+		require.NoError(t, counter.Ack(ctx))
+	}
+	for {
+		select {
+		case <-done:
+			take()
+			assert.Equal(t, int64(clickers*clicks), total)
+			return
+		default:
+			take()
+		}
+	}
+}
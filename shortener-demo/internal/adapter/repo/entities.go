@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/gostratum/examples/shortener-demo/internal/domain"
+)
+
+// LinkEntity represents the GORM model for links table
+type LinkEntity struct {
+	Code      string    `gorm:"primaryKey"`
+	URL       string    `gorm:"not null"`
+	Clicks    int64     `gorm:"not null"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for LinkEntity
+func (LinkEntity) TableName() string {
+	return "links"
+}
+
+// ToDomain converts LinkEntity to domain.Link
+func (l *LinkEntity) ToDomain() *domain.Link {
+	return &domain.Link{
+		Code:      l.Code,
+		URL:       l.URL,
+		Clicks:    l.Clicks,
+		CreatedAt: l.CreatedAt,
+	}
+}
+
+// FromDomain creates LinkEntity from domain.Link
+func (l *LinkEntity) FromDomain(link *domain.Link) {
+	l.Code = link.Code
+	l.URL = link.URL
+	l.Clicks = link.Clicks
+	l.CreatedAt = link.CreatedAt
+}
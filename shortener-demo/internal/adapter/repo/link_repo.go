@@ -0,0 +1,75 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/gostratum/examples/shortener-demo/internal/domain"
+	"github.com/gostratum/examples/shortener-demo/internal/usecase"
+)
+
+// LinkRepo implements the LinkRepository interface using GORM
+type LinkRepo struct {
+	db *gorm.DB
+}
+
+// NewLinkRepo creates a new GORM-based link repository
+func NewLinkRepo(db *gorm.DB) usecase.LinkRepository {
+	return &LinkRepo{db: db}
+}
+
+// Create implements usecase.LinkRepository
+func (r *LinkRepo) Create(ctx context.Context, link *domain.Link) error {
+	var entity LinkEntity
+	entity.FromDomain(link)
+
+	// Insert unless the code is taken; the primary key decides, so two
+	// concurrent requests cannot both create the same code
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&entity)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrConflict
+	}
+	return nil
+}
+
+// FindByCode implements usecase.LinkRepository
+func (r *LinkRepo) FindByCode(ctx context.Context, code string) (*domain.Link, error) {
+	var entity LinkEntity
+	if err := r.db.WithContext(ctx).Where("code = ?", code).First(&entity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return entity.ToDomain(), nil
+}
+
+// AddClicks implements usecase.LinkRepository
+func (r *LinkRepo) AddClicks(ctx context.Context, clicks map[string]int64) error {
+	// Updated in code order, so two flushes running at once lock the rows in
+	// the same order and cannot deadlock
+	codes := make([]string, 0, len(clicks))
+	for code := range clicks {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, code := range codes {
+			err := tx.Model(&LinkEntity{}).
+				Where("code = ?", code).
+				Update("clicks", gorm.Expr("clicks + ?", clicks[code])).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
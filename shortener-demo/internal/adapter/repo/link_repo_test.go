@@ -0,0 +1,120 @@
+package repo
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/gostratum/examples/shortener-demo/internal/domain"
+	"github.com/gostratum/examples/shortener-demo/migrations"
+	"github.com/gostratum/examples/testkit/containers"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(containers.Run(m))
+}
+
+// setupTestDB creates a Postgres database with the service's migrations applied
+func setupTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	return containers.PostgresGorm(t, containers.WithMigrations(migrations.FS))
+}
+
+func TestLinkRepoCreateAndFind(t *testing.T) {
+	t.Parallel()
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+
+	link := domain.NewLink("abc1234", "https://example.com/a")
+	require.NoError(t, repo.Create(ctx, link))
+
+	got, err := repo.FindByCode(ctx, "abc1234")
+	require.NoError(t, err)
+	assert.Equal(t, link.URL, got.URL)
+	assert.Zero(t, got.Clicks)
+	assert.WithinDuration(t, link.CreatedAt, got.CreatedAt, time.Millisecond)
+
+	_, err = repo.FindByCode(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	err = repo.Create(ctx, domain.NewLink("abc1234", "https://example.com/b"))
+	assert.ErrorIs(t, err, domain.ErrConflict)
+
+	got, err = repo.FindByCode(ctx, "abc1234")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/a", got.URL, "a conflicting create must not overwrite the link")
+}
+
+func TestLinkRepoCreateSameCodeConcurrently(t *testing.T) {
+	t.Parallel()
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+
+	const writers = 8
+	errs := make([]error, writers)
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = repo.Create(ctx, domain.NewLink("same123", "https://example.com"))
+		}()
+	}
+	wg.Wait()
+
+	var created, conflicts int
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case assert.ErrorIs(t, err, domain.ErrConflict):
+			conflicts++
+		}
+	}
+	assert.Equal(t, 1, created)
+	assert.Equal(t, writers-1, conflicts)
+}
+
+func TestLinkRepoAddClicks(t *testing.T) {
+	t.Parallel()
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+
+	require.NoError(t, repo.Create(ctx, domain.NewLink("aaaaaaa", "https://example.com/a")))
+	require.NoError(t, repo.Create(ctx, domain.NewLink("bbbbbbb", "https://example.com/b")))
+
+	require.NoError(t, repo.AddClicks(ctx, map[string]int64{"aaaaaaa": 3, "bbbbbbb": 1, "gone123": 5}))
+	require.NoError(t, repo.AddClicks(ctx, map[string]int64{"aaaaaaa": 2}))
+	require.NoError(t, repo.AddClicks(ctx, map[string]int64{}))
+
+	a, err := repo.FindByCode(ctx, "aaaaaaa")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), a.Clicks)
+
+	b, err := repo.FindByCode(ctx, "bbbbbbb")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), b.Clicks)
+}
+
+func TestLinkRepoAddClicksIsAllOrNothing(t *testing.T) {
+	t.Parallel()
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+
+	require.NoError(t, repo.Create(ctx, domain.NewLink("aaaaaaa", "https://example.com/a")))
+	require.NoError(t, repo.Create(ctx, domain.NewLink("bbbbbbb", "https://example.com/b")))
+
+	// aaaaaaa is updated first; the check constraint then rejects bbbbbbb
+	err := repo.AddClicks(ctx, map[string]int64{"aaaaaaa": 3, "bbbbbbb": -1})
+	require.Error(t, err)
+
+	a, err := repo.FindByCode(ctx, "aaaaaaa")
+	require.NoError(t, err)
+	assert.Zero(t, a.Clicks, "the update of aaaaaaa must be rolled back")
+}
@@ -0,0 +1,18 @@
+// Package clicks adds the clicks counted in Redis to the links in the
+// database, every interval and once more at shutdown
+package clicks
+
+import "time"
+
+// Config sets how often clicks are flushed
+type Config struct {
+	// Interval between flushes; click counts in the database lag this much
+	Interval time.Duration `mapstructure:"flush_interval" default:"10s"`
+	// Timeout bounds one flush
+	Timeout time.Duration `mapstructure:"flush_timeout" default:"5s"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "clicks"
+}
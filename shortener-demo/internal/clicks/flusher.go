@@ -0,0 +1,117 @@
+package clicks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"go.uber.org/fx"
+
+	"github.com/gostratum/examples/shortener-demo/internal/usecase"
+)
+
+// Clicks flushes counted clicks; implemented by usecase.LinkService
+type Clicks interface {
+	FlushClicks(ctx context.Context) (int, error)
+}
+
+// Params holds the dependencies of the Flusher
+type Params struct {
+	fx.In
+
+	Loader  configx.Loader
+	Service *usecase.LinkService
+	Log     logx.Logger
+}
+
+// Flusher calls FlushClicks every interval
+type Flusher struct {
+	cfg    Config
+	clicks Clicks
+	log    logx.Logger
+
+	stopping chan struct{}
+	done     chan struct{}
+}
+
+// NewFlusher creates a Flusher from the clicks config section
+func NewFlusher(p Params) (*Flusher, error) {
+	var cfg Config
+	if err := p.Loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load clicks config: %w", err)
+	}
+	if cfg.Interval <= 0 || cfg.Timeout <= 0 {
+		return nil, fmt.Errorf("clicks.flush_interval and clicks.flush_timeout must be positive")
+	}
+	return newFlusher(cfg, p.Service, p.Log), nil
+}
+
+func newFlusher(cfg Config, clicks Clicks, log logx.Logger) *Flusher {
+	return &Flusher{
+		cfg:      cfg,
+		clicks:   clicks,
+		log:      log,
+		stopping: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Register ties the flushing loop to the application lifecycle.
+// This function is designed to be used with fx.Invoke.
+func Register(lc fx.Lifecycle, f *Flusher) {
+	lc.Append(fx.Hook{
+		OnStart: f.start,
+		OnStop:  f.stop,
+	})
+}
+
+func (f *Flusher) start(context.Context) error {
+	go f.loop()
+	return nil
+}
+
+// stop ends the loop and flushes once more, so the database has every click
+// counted before shutdown
+func (f *Flusher) stop(ctx context.Context) error {
+	close(f.stopping)
+	select {
+	case <-f.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	f.flush(ctx)
+	return nil
+}
+
+func (f *Flusher) loop() {
+	defer close(f.done)
+
+	ticker := time.NewTicker(f.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.stopping:
+			return
+		case <-ticker.C:
+			f.flush(context.Background())
+		}
+	}
+}
+
+// flush runs one flush. A flush that fails leaves the clicks in Redis for
+// the next one, so failures are only logged.
+func (f *Flusher) flush(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, f.cfg.Timeout)
+	defer cancel()
+
+	updated, err := f.clicks.FlushClicks(ctx)
+	if err != nil {
+		f.log.Warn("failed to flush clicks; retrying next interval", logx.Err(err))
+		return
+	}
+	if updated > 0 {
+		f.log.Debug("flushed clicks", logx.Int("links", updated))
+	}
+}
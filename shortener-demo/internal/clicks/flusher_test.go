@@ -0,0 +1,55 @@
+package clicks
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClicks counts the flushes and fails the first failures of them
+type fakeClicks struct {
+	mu       sync.Mutex
+	flushes  int
+	failures int
+}
+
+func (f *fakeClicks) FlushClicks(ctx context.Context) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flushes++
+	if f.flushes <= f.failures {
+		return 0, errors.New("connection refused")
+	}
+	return 1, nil
+}
+
+func (f *fakeClicks) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.flushes
+}
+
+func TestFlusherFlushesEveryInterval(t *testing.T) {
+	clicks := &fakeClicks{failures: 1}
+	f := newFlusher(Config{Interval: 10 * time.Millisecond, Timeout: time.Second}, clicks, logx.NewNoopLogger())
+
+	require.NoError(t, f.start(context.Background()))
+	// A failed flush does not end the loop
+	assert.Eventually(t, func() bool { return clicks.count() >= 3 }, 5*time.Second, 5*time.Millisecond)
+	require.NoError(t, f.stop(context.Background()))
+}
+
+func TestFlusherFlushesOnStop(t *testing.T) {
+	clicks := &fakeClicks{}
+	f := newFlusher(Config{Interval: time.Hour, Timeout: time.Second}, clicks, logx.NewNoopLogger())
+
+	require.NoError(t, f.start(context.Background()))
+	require.NoError(t, f.stop(context.Background()))
+	assert.Equal(t, 1, clicks.count())
+}
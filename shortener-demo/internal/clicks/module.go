@@ -0,0 +1,11 @@
+package clicks
+
+import "go.uber.org/fx"
+
+// Module provides the Flusher and runs it from application start to stop
+func Module() fx.Option {
+	return fx.Module("clicks",
+		fx.Provide(NewFlusher),
+		fx.Invoke(Register),
+	)
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CodeLength is the length of a link code. 62^7 codes leave room for
+// billions of links before random codes start to collide.
+const CodeLength = 7
+
+// maxURLLength limits the URLs a link may point to, in bytes
+const maxURLLength = 2048
+
+// codeAlphabet are the characters of a code; all of them are safe in a URL path
+const codeAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// Link is a short code that redirects to a URL. Clicks counts the redirects
+// recorded in the database so far.
+type Link struct {
+	Code      string
+	URL       string
+	Clicks    int64
+	CreatedAt time.Time
+}
+
+// NewLink creates a link from code to rawURL
+func NewLink(code, rawURL string) *Link {
+	return &Link{
+		Code:      code,
+		URL:       strings.TrimSpace(rawURL),
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+// Validate checks the link against the business rules
+func (l *Link) Validate() error {
+	if !ValidCode(l.Code) {
+		return fmt.Errorf("%w: code must be %d letters or digits", ErrInvalidInput, CodeLength)
+	}
+	if len(l.URL) > maxURLLength {
+		return fmt.Errorf("%w: url must be at most %d bytes", ErrInvalidInput, maxURLLength)
+	}
+	u, err := url.Parse(l.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidInput)
+	}
+	return nil
+}
+
+// NewCode returns a random code
+func NewCode() string {
+	var b [CodeLength]byte
+	// 62 characters do not divide 256, so bytes of 248 and above are drawn
+	// again rather than making the first characters more likely
+	for i := 0; i < CodeLength; {
+		var r [1]byte
+		_, _ = rand.Read(r[:]) // crypto/rand.Read never fails
+		if r[0] >= 248 {
+			continue
+		}
+		b[i] = codeAlphabet[r[0]%62]
+		i++
+	}
+	return string(b[:])
+}
+
+// ValidCode reports whether code could be a link code. Lookups of anything
+// else fail without a query.
+func ValidCode(code string) bool {
+	if len(code) != CodeLength {
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		if !strings.ContainsRune(codeAlphabet, rune(code[i])) {
+			return false
+		}
+	}
+	return true
+}
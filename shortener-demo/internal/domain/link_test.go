@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCode(t *testing.T) {
+	seen := map[string]bool{}
+	for range 1000 {
+		code := NewCode()
+		assert.True(t, ValidCode(code), "code %q", code)
+		seen[code] = true
+	}
+	assert.Len(t, seen, 1000)
+}
+
+func TestValidCode(t *testing.T) {
+	assert.True(t, ValidCode("aZ09xYz"))
+	assert.False(t, ValidCode(""))
+	assert.False(t, ValidCode("aZ09xY"))
+	assert.False(t, ValidCode("aZ09xYzz"))
+	assert.False(t, ValidCode("aZ0-xYz"))
+	assert.False(t, ValidCode("aZ09xé"))
+}
+
+func TestLinkValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		url     string
+		wantErr bool
+	}{
+		{name: "valid", code: "abc1234", url: "https://example.com/a?b=c#d"},
+		{name: "http", code: "abc1234", url: "http://localhost:8080"},
+		{name: "surrounding spaces", code: "abc1234", url: "  https://example.com  "},
+		{name: "longest url", code: "abc1234", url: "https://example.com/" + strings.Repeat("a", maxURLLength-20)},
+		{name: "url too long", code: "abc1234", url: "https://example.com/" + strings.Repeat("a", maxURLLength-19), wantErr: true},
+		{name: "empty url", code: "abc1234", url: "", wantErr: true},
+		{name: "relative url", code: "abc1234", url: "/path", wantErr: true},
+		{name: "no host", code: "abc1234", url: "https:///path", wantErr: true},
+		{name: "javascript", code: "abc1234", url: "javascript:alert(1)", wantErr: true},
+		{name: "ftp", code: "abc1234", url: "ftp://example.com/file", wantErr: true},
+		{name: "invalid code", code: "abc", url: "https://example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewLink(tt.code, tt.url).Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidInput)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package usecase
+
+// SetNewCode replaces the code generator of s, so tests can draw codes that
+// are already taken
+func SetNewCode(s *LinkService, newCode func() string) {
+	s.newCode = newCode
+}
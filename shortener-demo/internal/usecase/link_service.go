@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gostratum/core/logx"
+
+	"github.com/gostratum/examples/shortener-demo/internal/domain"
+)
+
+// codeAttempts is how many random codes Shorten tries before giving up
+const codeAttempts = 3
+
+// LinkService handles link business logic. Links are stored in the database;
+// Redis caches their URLs and counts their clicks, which are added to the
+// database in batches.
+type LinkService struct {
+	repo   LinkRepository
+	cache  LinkCache
+	clicks ClickCounter
+	log    logx.Logger
+
+	// newCode is replaced in tests
+	newCode func() string
+}
+
+// NewLinkService creates a new link service with repository injection
+func NewLinkService(repo LinkRepository, cache LinkCache, clicks ClickCounter, log logx.Logger) *LinkService {
+	return &LinkService{
+		repo:    repo,
+		cache:   cache,
+		clicks:  clicks,
+		log:     log,
+		newCode: domain.NewCode,
+	}
+}
+
+// Shorten creates a link to url under a new random code
+func (s *LinkService) Shorten(ctx context.Context, url string) (*domain.Link, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	for range codeAttempts {
+		link := domain.NewLink(s.newCode(), url)
+		if err := link.Validate(); err != nil {
+			return nil, err
+		}
+
+		// The primary key decides whether a code is free, so two requests
+		// drawing the same code cannot both get it
+		err := s.repo.Create(ctx, link)
+		if errors.Is(err, domain.ErrConflict) {
+			continue
+		}
+		if err != nil {
+			return nil, translateError(err)
+		}
+		return link, nil
+	}
+	return nil, fmt.Errorf("%w: no free code after %d attempts", ErrUnavailable, codeAttempts)
+}
+
+// Resolve returns the URL of code and counts the click. Redis failures are
+// logged and passed over: the link is then read from the database and the
+// click goes uncounted, but the redirect works.
+func (s *LinkService) Resolve(ctx context.Context, code string) (string, error) {
+	if !domain.ValidCode(code) {
+		return "", ErrNotFound
+	}
+
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	url, ok, err := s.cache.Get(ctx, code)
+	if err != nil {
+		s.log.Warn("link cache lookup failed", logx.String("code", code), logx.Err(err))
+	}
+	if !ok {
+		link, err := s.repo.FindByCode(ctx, code)
+		if err != nil {
+			return "", translateError(err)
+		}
+		url = link.URL
+		if err := s.cache.Set(ctx, code, url); err != nil {
+			s.log.Warn("failed to cache link", logx.String("code", code), logx.Err(err))
+		}
+	}
+
+	if err := s.clicks.Incr(ctx, code); err != nil {
+		s.log.Warn("click not counted", logx.String("code", code), logx.Err(err))
+	}
+	return url, nil
+}
+
+// Stats returns the link of code with every click counted so far, including
+// those not yet added to the database
+func (s *LinkService) Stats(ctx context.Context, code string) (*domain.Link, error) {
+	if !domain.ValidCode(code) {
+		return nil, ErrNotFound
+	}
+
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	link, err := s.repo.FindByCode(ctx, code)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	pending, err := s.clicks.Pending(ctx, code)
+	if err != nil {
+		// The count lags behind until the next flush
+		s.log.Warn("failed to read pending clicks", logx.String("code", code), logx.Err(err))
+	}
+	link.Clicks += pending
+	return link, nil
+}
+
+// FlushClicks adds the clicks counted in Redis to the links in the database
+// and returns how many links it updated. When the database update fails the
+// batch stays in Redis and the next flush tries it again.
+func (s *LinkService) FlushClicks(ctx context.Context) (int, error) {
+	batch, err := s.clicks.Take(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%w: failed to take clicks: %v", ErrUnavailable, err)
+	}
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	if err := s.repo.AddClicks(ctx, batch); err != nil {
+		return 0, translateError(err)
+	}
+	// Should this fail, the next flush adds the batch a second time; counting
+	// some clicks twice is the price of never dropping any
+	if err := s.clicks.Ack(ctx); err != nil {
+		return 0, fmt.Errorf("%w: failed to acknowledge clicks: %v", ErrUnavailable, err)
+	}
+	return len(batch), nil
+}
+
+// translateError converts repository/domain errors to usecase errors
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return ErrNotFound
+	case errors.Is(err, domain.ErrConflict):
+		return ErrConflict
+	default:
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+}
@@ -0,0 +1,226 @@
+// The use case tests run against the real adapters, on Postgres and Redis in
+// Docker; they are an external test package because the adapters import
+// this one
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	redisAdapter "github.com/gostratum/examples/shortener-demo/internal/adapter/redis"
+	repoAdapter "github.com/gostratum/examples/shortener-demo/internal/adapter/repo"
+	"github.com/gostratum/examples/shortener-demo/internal/usecase"
+	"github.com/gostratum/examples/shortener-demo/migrations"
+	"github.com/gostratum/examples/testkit/containers"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(containers.Run(m))
+}
+
+// fixture is a LinkService on a database and a Redis database of the test's own
+type fixture struct {
+	svc    *usecase.LinkService
+	db     *gorm.DB
+	repo   usecase.LinkRepository
+	cache  usecase.LinkCache
+	clicks usecase.ClickCounter
+}
+
+func setup(t *testing.T) *fixture {
+	t.Helper()
+	db := containers.PostgresGorm(t, containers.WithMigrations(migrations.FS))
+
+	rdb := containers.Redis(t)
+	client := redisAdapter.NewClientFromConfig(redisAdapter.Config{
+		Addr:      rdb.Addr,
+		DB:        rdb.DB,
+		KeyPrefix: "test:",
+		CacheTTL:  time.Minute,
+		Timeout:   time.Second,
+	})
+	t.Cleanup(func() { client.Close() })
+
+	f := &fixture{
+		db:     db,
+		repo:   repoAdapter.NewLinkRepo(db),
+		cache:  redisAdapter.NewLinkCache(client),
+		clicks: redisAdapter.NewClickCounter(client),
+	}
+	f.svc = usecase.NewLinkService(f.repo, f.cache, f.clicks, logx.NewNoopLogger())
+	return f
+}
+
+// codes returns a code generator that draws codes in order
+func codes(c ...string) func() string {
+	return func() string {
+		code := c[0]
+		c = c[1:]
+		return code
+	}
+}
+
+func TestShortenAndResolve(t *testing.T) {
+	t.Parallel()
+	f := setup(t)
+	ctx := context.Background()
+
+	link, err := f.svc.Shorten(ctx, " https://example.com/a ")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/a", link.URL)
+
+	url, err := f.svc.Resolve(ctx, link.Code)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/a", url)
+
+	cached, ok, err := f.cache.Get(ctx, link.Code)
+	require.NoError(t, err)
+	assert.True(t, ok, "a resolved link must be cached")
+	assert.Equal(t, url, cached)
+
+	_, err = f.svc.Resolve(ctx, "missing")
+	assert.ErrorIs(t, err, usecase.ErrNotFound)
+	_, err = f.svc.Resolve(ctx, "abcdefg")
+	assert.ErrorIs(t, err, usecase.ErrNotFound)
+}
+
+func TestShortenRejectsInvalidURL(t *testing.T) {
+	t.Parallel()
+	f := setup(t)
+	usecase.SetNewCode(f.svc, codes("aaaaaaa"))
+
+	_, err := f.svc.Shorten(context.Background(), "javascript:alert(1)")
+	assert.ErrorIs(t, err, usecase.ErrInvalid)
+
+	_, err = f.repo.FindByCode(context.Background(), "aaaaaaa")
+	assert.ErrorIs(t, err, usecase.ErrNotFound)
+}
+
+func TestShortenDrawsAgainWhenCodeIsTaken(t *testing.T) {
+	t.Parallel()
+	f := setup(t)
+	ctx := context.Background()
+	usecase.SetNewCode(f.svc, codes("aaaaaaa", "aaaaaaa", "bbbbbbb", "aaaaaaa", "bbbbbbb", "aaaaaaa"))
+
+	first, err := f.svc.Shorten(ctx, "https://example.com/1")
+	require.NoError(t, err)
+	assert.Equal(t, "aaaaaaa", first.Code)
+
+	second, err := f.svc.Shorten(ctx, "https://example.com/2")
+	require.NoError(t, err)
+	assert.Equal(t, "bbbbbbb", second.Code)
+
+	_, err = f.svc.Shorten(ctx, "https://example.com/3")
+	assert.ErrorIs(t, err, usecase.ErrUnavailable)
+	assert.ErrorContains(t, err, "no free code after 3 attempts")
+
+	url, err := f.svc.Resolve(ctx, "aaaaaaa")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/1", url, "a taken code must keep its link")
+}
+
+func TestClicksAreCountedAndFlushed(t *testing.T) {
+	t.Parallel()
+	f := setup(t)
+	ctx := context.Background()
+
+	link, err := f.svc.Shorten(ctx, "https://example.com")
+	require.NoError(t, err)
+	for range 3 {
+		_, err := f.svc.Resolve(ctx, link.Code)
+		require.NoError(t, err)
+	}
+
+	stats, err := f.svc.Stats(ctx, link.Code)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.Clicks, "pending clicks count before they are flushed")
+
+	updated, err := f.svc.FlushClicks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated)
+
+	stored, err := f.repo.FindByCode(ctx, link.Code)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stored.Clicks)
+
+	stats, err = f.svc.Stats(ctx, link.Code)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.Clicks, "flushed clicks must not be counted twice")
+
+	updated, err = f.svc.FlushClicks(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, updated)
+}
+
+func TestFlushKeepsClicksWhenDatabaseFails(t *testing.T) {
+	t.Parallel()
+	f := setup(t)
+	ctx := context.Background()
+
+	link, err := f.svc.Shorten(ctx, "https://example.com")
+	require.NoError(t, err)
+	_, err = f.svc.Resolve(ctx, link.Code)
+	require.NoError(t, err)
+
+	// A constraint the batch breaks makes the database update fail
+	require.NoError(t, f.db.Exec("ALTER TABLE links ADD CONSTRAINT one_click_at_most CHECK (clicks < 1)").Error)
+
+	_, err = f.svc.FlushClicks(ctx)
+	assert.ErrorIs(t, err, usecase.ErrUnavailable)
+
+	pending, err := f.clicks.Pending(ctx, link.Code)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), pending, "the batch must stay in Redis")
+
+	require.NoError(t, f.db.Exec("ALTER TABLE links DROP CONSTRAINT one_click_at_most").Error)
+	updated, err := f.svc.FlushClicks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated)
+
+	stored, err := f.repo.FindByCode(ctx, link.Code)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stored.Clicks)
+}
+
+// brokenRedis fails every cache and counter operation, like a Redis that is down
+type brokenRedis struct{}
+
+var errRedisDown = errors.New("dial tcp 127.0.0.1:6379: connect: connection refused")
+
+func (brokenRedis) Get(context.Context, string) (string, bool, error) { return "", false, errRedisDown }
+func (brokenRedis) Set(context.Context, string, string) error         { return errRedisDown }
+func (brokenRedis) Incr(context.Context, string) error                { return errRedisDown }
+func (brokenRedis) Pending(context.Context, string) (int64, error)    { return 0, errRedisDown }
+func (brokenRedis) Take(context.Context) (map[string]int64, error)    { return nil, errRedisDown }
+func (brokenRedis) Ack(context.Context) error                         { return errRedisDown }
+
+func TestResolveWithoutRedis(t *testing.T) {
+	t.Parallel()
+	f := setup(t)
+	ctx := context.Background()
+
+	link, err := f.svc.Shorten(ctx, "https://example.com")
+	require.NoError(t, err)
+	require.NoError(t, f.repo.AddClicks(ctx, map[string]int64{link.Code: 2}))
+
+	svc := usecase.NewLinkService(f.repo, brokenRedis{}, brokenRedis{}, logx.NewNoopLogger())
+
+	url, err := svc.Resolve(ctx, link.Code)
+	require.NoError(t, err, "links must resolve from the database while Redis is down")
+	assert.Equal(t, "https://example.com", url)
+
+	stats, err := svc.Stats(ctx, link.Code)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stats.Clicks)
+
+	_, err = svc.FlushClicks(ctx)
+	assert.ErrorIs(t, err, usecase.ErrUnavailable)
+}
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/gostratum/examples/shortener-demo/internal/domain"
+)
+
+// LinkRepository stores links
+// This interface is owned by the use case layer (dependency inversion principle)
+type LinkRepository interface {
+	// Create returns domain.ErrConflict when a link has the same code
+	Create(ctx context.Context, link *domain.Link) error
+	FindByCode(ctx context.Context, code string) (*domain.Link, error)
+	// AddClicks adds clicks[code] to the click count of each link, all or
+	// none of them. Codes without a link are ignored.
+	AddClicks(ctx context.Context, clicks map[string]int64) error
+}
+
+// LinkCache keeps the URLs of links that were resolved recently
+// This interface is owned by the use case layer (dependency inversion principle)
+type LinkCache interface {
+	// Get returns the URL cached for code; ok is false on a miss
+	Get(ctx context.Context, code string) (url string, ok bool, err error)
+	Set(ctx context.Context, code, url string) error
+}
+
+// ClickCounter counts redirects until they are added to the links' counts.
+// The counts are taken in batches: a batch that was taken is handed out
+// again until it is acknowledged, so a failed flush loses no clicks.
+// This interface is owned by the use case layer (dependency inversion principle)
+type ClickCounter interface {
+	Incr(ctx context.Context, code string) error
+	// Pending returns the clicks of code not acknowledged yet
+	Pending(ctx context.Context, code string) (int64, error)
+	// Take returns the batch to flush: the one taken before when it was not
+	// acknowledged, or else every click counted since
+	Take(ctx context.Context) (map[string]int64, error)
+	// Ack drops the batch Take returned
+	Ack(ctx context.Context) error
+}
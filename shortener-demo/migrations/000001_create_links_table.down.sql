@@ -0,0 +1,2 @@
+-- Drop links table
+DROP TABLE IF EXISTS links CASCADE;
@@ -0,0 +1,7 @@
+-- Create links table
+CREATE TABLE IF NOT EXISTS links (
+    code VARCHAR(16) PRIMARY KEY,
+    url TEXT NOT NULL,
+    clicks BIGINT NOT NULL DEFAULT 0 CHECK (clicks >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/examples/redact"
+	"github.com/gostratum/examples/stock-import/internal/adapter/inventory"
+	"github.com/gostratum/examples/stock-import/internal/cli"
+	"github.com/gostratum/examples/stock-import/internal/usecase"
+)
+
+func main() {
+	var args cli.Args
+	flag.StringVar(&args.File, "file", "", "CSV file with sku, name and available columns (required)")
+	flag.BoolVar(&args.DryRun, "dry-run", false, "validate the file without writing to inventoryservice")
+	flag.Parse()
+
+	if args.File == "" || flag.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "usage: import -file stock.csv [-dry-run]")
+		flag.PrintDefaults()
+		os.Exit(cli.ExitUsage)
+	}
+
+	// The dry run swaps the writer; everything else is the same graph
+	writer := fx.Provide(inventory.NewClient)
+	if args.DryRun {
+		writer = fx.Provide(inventory.NewDryRun)
+	}
+
+	var cmd *cli.Command
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// No httpx: nothing listens, the process ends when the import does
+		fx.Supply(args),
+		writer,
+
+		// Provide dependencies
+		fx.Provide(
+			usecase.NewImportService,
+			cli.NewCommand,
+		),
+
+		// Run the import once the application has started
+		fx.Invoke(cli.Register),
+		fx.Populate(&cmd),
+	)
+
+	// Run returns once the command has shut the application down, or after
+	// SIGINT/SIGTERM; it exits with status 1 by itself if startup fails
+	app.Run()
+	os.Exit(cmd.ExitCode())
+}
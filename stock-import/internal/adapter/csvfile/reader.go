@@ -0,0 +1,106 @@
+// Package csvfile reads stock rows from CSV files
+package csvfile
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gostratum/examples/stock-import/internal/domain"
+)
+
+// columns the file must have, in any order; other columns are ignored
+var columns = []string{"sku", "name", "available"}
+
+// Reader reads rows from a CSV file with a header line. It implements
+// usecase.RowReader.
+type Reader struct {
+	file  io.Closer
+	csv   *csv.Reader
+	index map[string]int
+}
+
+// Open opens a CSV file and checks its header
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	r, err := newReader(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	r.file = f
+	return r, nil
+}
+
+func newReader(src io.Reader) (*Reader, error) {
+	c := csv.NewReader(src)
+	c.TrimLeadingSpace = true
+	// Rows with missing or extra fields are reported per row, not as a fatal error
+	c.FieldsPerRecord = -1
+
+	header, err := c.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range columns {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("header has no %q column", name)
+		}
+	}
+	return &Reader{csv: c, index: index}, nil
+}
+
+// Next implements usecase.RowReader
+func (r *Reader) Next() (domain.Row, error) {
+	record, err := r.csv.Read()
+	if errors.Is(err, io.EOF) {
+		return domain.Row{}, io.EOF
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		// A malformed line, such as a stray quote; the reader skips past it
+		return domain.Row{}, &domain.RowError{Line: parseErr.Line, Err: fmt.Errorf("%w: %v", domain.ErrInvalidRow, parseErr.Err)}
+	}
+	if err != nil {
+		return domain.Row{}, err
+	}
+	line, _ := r.csv.FieldPos(0)
+
+	field := func(name string) string {
+		if i := r.index[name]; i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	sku := strings.TrimSpace(field("sku"))
+	available, err := strconv.Atoi(strings.TrimSpace(field("available")))
+	if err != nil {
+		return domain.Row{}, &domain.RowError{Line: line, SKU: sku, Err: fmt.Errorf("%w: available %q is not a whole number", domain.ErrInvalidRow, field("available"))}
+	}
+	return domain.NewRow(line, sku, field("name"), available), nil
+}
+
+// Close closes the file
+func (r *Reader) Close() error {
+	if r.file == nil {
+		return nil
+	}
+	return r.file.Close()
+}
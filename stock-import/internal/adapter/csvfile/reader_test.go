@@ -0,0 +1,82 @@
+package csvfile
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/stock-import/internal/domain"
+)
+
+func TestReader(t *testing.T) {
+	src := `Available, SKU ,name,warehouse
+10,SKU1,Widget,north
+lots,SKU2,Gadget,south
+
+ 0, SKU3 , Gizmo ,east
+5,"SKU4
+`
+	r, err := newReader(strings.NewReader(src))
+	require.NoError(t, err)
+
+	row, err := r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, domain.Row{Line: 2, SKU: "SKU1", Name: "Widget", Available: 10}, row)
+
+	_, err = r.Next()
+	var rowErr *domain.RowError
+	require.ErrorAs(t, err, &rowErr)
+	assert.Equal(t, 3, rowErr.Line)
+	assert.Equal(t, "SKU2", rowErr.SKU)
+	assert.ErrorIs(t, err, domain.ErrInvalidRow)
+
+	row, err = r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, domain.Row{Line: 5, SKU: "SKU3", Name: "Gizmo", Available: 0}, row)
+
+	_, err = r.Next()
+	require.ErrorAs(t, err, &rowErr)
+	assert.Equal(t, 6, rowErr.Line)
+
+	_, err = r.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestReaderShortRow(t *testing.T) {
+	r, err := newReader(strings.NewReader("sku,name,available\nSKU1,Widget\n"))
+	require.NoError(t, err)
+
+	_, err = r.Next()
+	var rowErr *domain.RowError
+	require.ErrorAs(t, err, &rowErr)
+	assert.Equal(t, 2, rowErr.Line)
+}
+
+func TestNewReaderChecksHeader(t *testing.T) {
+	_, err := newReader(strings.NewReader("sku,title,available\nSKU1,Widget,1\n"))
+	assert.ErrorContains(t, err, `header has no "name" column`)
+
+	_, err = newReader(strings.NewReader(""))
+	assert.ErrorContains(t, err, "file is empty")
+}
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stock.csv")
+	require.NoError(t, os.WriteFile(path, []byte("sku,name,available\nSKU1,Widget,3\n"), 0o644))
+
+	r, err := Open(path)
+	require.NoError(t, err)
+	defer r.Close()
+	row, err := r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "SKU1", row.SKU)
+
+	_, err = Open(filepath.Join(t.TempDir(), "missing.csv"))
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
@@ -0,0 +1,124 @@
+// Package inventory implements usecase.StockWriter against inventoryservice's HTTP API
+package inventory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+
+	"github.com/gostratum/examples/stock-import/internal/domain"
+	"github.com/gostratum/examples/stock-import/internal/usecase"
+)
+
+// Config locates inventoryservice
+type Config struct {
+	BaseURL string        `mapstructure:"base_url"`
+	Timeout time.Duration `mapstructure:"timeout" default:"5s"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "inventory"
+}
+
+// NewClient creates the inventory client from the inventory config section
+func NewClient(loader configx.Loader) (usecase.StockWriter, error) {
+	var cfg Config
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load inventory config: %w", err)
+	}
+	if cfg.BaseURL == "" {
+		return nil, errors.New("inventory.base_url is not set")
+	}
+	return newHTTPClient(cfg.BaseURL, &http.Client{Timeout: cfg.Timeout}), nil
+}
+
+// HTTPClient calls inventoryservice's stock endpoint
+type HTTPClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+func newHTTPClient(baseURL string, client *http.Client) *HTTPClient {
+	return &HTTPClient{baseURL: strings.TrimSuffix(baseURL, "/"), http: client}
+}
+
+type stockRequest struct {
+	Name      string `json:"name"`
+	Available int    `json:"available"`
+}
+
+// envelope is the part of inventoryservice's response envelope the client reads
+type envelope struct {
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// SetStock implements usecase.StockWriter via PUT /stock/:sku, which creates
+// the SKU or replaces its available units
+func (c *HTTPClient) SetStock(ctx context.Context, row domain.Row) error {
+	var payload bytes.Buffer
+	if err := json.NewEncoder(&payload).Encode(stockRequest{Name: row.Name, Available: row.Available}); err != nil {
+		return fmt.Errorf("failed to encode inventory request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.baseURL+"/stock/"+url.PathEscape(row.SKU), &payload)
+	if err != nil {
+		return fmt.Errorf("failed to create inventory request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// Cancellation is not the service's fault; keep it recognizable
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%w: %v", usecase.ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return nil
+	case resp.StatusCode == http.StatusBadRequest:
+		var body envelope
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == nil {
+			return fmt.Errorf("%w: inventory returned status %d", usecase.ErrRejected, resp.StatusCode)
+		}
+		return fmt.Errorf("%w: %s: %s", usecase.ErrRejected, body.Error.Code, body.Error.Message)
+	default:
+		return fmt.Errorf("%w: inventory returned status %d", usecase.ErrUnavailable, resp.StatusCode)
+	}
+}
+
+// DryRun is a StockWriter that writes nothing, for checking a file before importing it
+type DryRun struct {
+	log logx.Logger
+}
+
+// NewDryRun creates a writer that only logs the rows it is given
+func NewDryRun(log logx.Logger) usecase.StockWriter {
+	return &DryRun{log: log}
+}
+
+// SetStock implements usecase.StockWriter
+func (d *DryRun) SetStock(ctx context.Context, row domain.Row) error {
+	d.log.Debug("would set stock",
+		logx.Int("line", row.Line),
+		logx.String("sku", row.SKU),
+		logx.Int("available", row.Available),
+	)
+	return nil
+}
@@ -0,0 +1,88 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/stock-import/internal/domain"
+	"github.com/gostratum/examples/stock-import/internal/usecase"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *HTTPClient {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return newHTTPClient(srv.URL+"/", srv.Client())
+}
+
+func writeEnvelope(w http.ResponseWriter, status int, envelope map[string]any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(envelope)
+}
+
+func TestHTTPClient_SetStock(t *testing.T) {
+	row := domain.NewRow(2, "SKU 1", "Widget", 10)
+
+	t.Run("puts the stock level", func(t *testing.T) {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPut, r.Method)
+			assert.Equal(t, "/stock/SKU%201", r.URL.EscapedPath())
+
+			var req stockRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, stockRequest{Name: "Widget", Available: 10}, req)
+
+			writeEnvelope(w, http.StatusOK, map[string]any{"ok": true, "data": map[string]any{"sku": "SKU 1"}})
+		})
+
+		require.NoError(t, client.SetStock(context.Background(), row))
+	})
+
+	t.Run("bad request rejects the row", func(t *testing.T) {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			writeEnvelope(w, http.StatusBadRequest, map[string]any{
+				"ok":    false,
+				"error": map[string]any{"code": "INVALID_INPUT", "message": "invalid input"},
+			})
+		})
+
+		err := client.SetStock(context.Background(), row)
+		assert.ErrorIs(t, err, usecase.ErrRejected)
+		assert.ErrorContains(t, err, "INVALID_INPUT")
+	})
+
+	t.Run("server error is unavailable", func(t *testing.T) {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		})
+
+		assert.ErrorIs(t, client.SetStock(context.Background(), row), usecase.ErrUnavailable)
+	})
+
+	t.Run("unreachable is unavailable", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		srv.Close()
+		client := newHTTPClient(srv.URL, http.DefaultClient)
+
+		assert.ErrorIs(t, client.SetStock(context.Background(), row), usecase.ErrUnavailable)
+	})
+
+	t.Run("cancelled is not unavailable", func(t *testing.T) {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := client.SetStock(ctx, row)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.NotErrorIs(t, err, usecase.ErrUnavailable)
+	})
+}
@@ -0,0 +1,187 @@
+// Package cli runs the import as a one-shot command inside the fx application:
+// it starts when the application has started, and stops the application with
+// an exit code when it is done
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"go.uber.org/fx"
+
+	"github.com/gostratum/examples/stock-import/internal/adapter/csvfile"
+	"github.com/gostratum/examples/stock-import/internal/usecase"
+)
+
+// Exit codes of the command
+const (
+	// ExitOK: every row was imported
+	ExitOK = 0
+	// ExitFailure: the import could not run or stopped early
+	ExitFailure = 1
+	// ExitUsage: invalid command-line arguments
+	ExitUsage = 2
+	// ExitRejected: the import finished, but some rows were rejected
+	ExitRejected = 3
+	// ExitInterrupted: stopped by SIGINT or SIGTERM (128 + SIGINT, as shells report it)
+	ExitInterrupted = 130
+)
+
+// Args are the command-line arguments
+type Args struct {
+	File   string
+	DryRun bool
+}
+
+// Config tunes the import
+type Config struct {
+	// Concurrency is how many rows are written at the same time
+	Concurrency int `mapstructure:"concurrency" default:"4"`
+	// MaxRejected stops the import once more rows were rejected; -1 for no limit
+	MaxRejected int `mapstructure:"max_rejected" default:"100"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "import"
+}
+
+// Command imports one file
+type Command struct {
+	args       Args
+	cfg        Config
+	service    *usecase.ImportService
+	shutdowner fx.Shutdowner
+	log        logx.Logger
+
+	// cancel stops the import when the application is stopped from outside
+	cancel context.CancelFunc
+	// done is closed once the import has finished and code is set
+	done chan struct{}
+	code int
+}
+
+// NewCommand creates the command from the import config section
+func NewCommand(args Args, loader configx.Loader, service *usecase.ImportService, shutdowner fx.Shutdowner, log logx.Logger) (*Command, error) {
+	var cfg Config
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load import config: %w", err)
+	}
+	return newCommand(args, cfg, service, shutdowner, log), nil
+}
+
+func newCommand(args Args, cfg Config, service *usecase.ImportService, shutdowner fx.Shutdowner, log logx.Logger) *Command {
+	return &Command{
+		args:       args,
+		cfg:        cfg,
+		service:    service,
+		shutdowner: shutdowner,
+		log:        log,
+		done:       make(chan struct{}),
+	}
+}
+
+// Register ties the command to the application lifecycle.
+// This function is designed to be used with fx.Invoke.
+func Register(lc fx.Lifecycle, cmd *Command) {
+	lc.Append(fx.Hook{
+		OnStart: cmd.start,
+		OnStop:  cmd.stop,
+	})
+}
+
+// start runs the import in the background; start hooks must return quickly
+func (c *Command) start(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	go func() {
+		c.code = c.run(ctx)
+		close(c.done)
+
+		// Stop the application, which makes app.Run return. When stop
+		// cancelled the import, the application is already stopping.
+		if ctx.Err() != nil {
+			return
+		}
+		if err := c.shutdowner.Shutdown(); err != nil {
+			c.log.Error("failed to shut down", logx.Err(err))
+		}
+	}()
+	return nil
+}
+
+// stop interrupts an import that is still running, on a signal, and waits
+// for it to finish the rows in flight
+func (c *Command) stop(ctx context.Context) error {
+	c.cancel()
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return errors.New("import did not stop in time")
+	}
+}
+
+// ExitCode is the code the process should exit with once the application has
+// stopped. It is ExitInterrupted if the import never finished.
+func (c *Command) ExitCode() int {
+	select {
+	case <-c.done:
+		return c.code
+	default:
+		return ExitInterrupted
+	}
+}
+
+// run imports the file and decides the exit code
+func (c *Command) run(ctx context.Context) int {
+	reader, err := csvfile.Open(c.args.File)
+	if err != nil {
+		c.log.Error("cannot read import file", logx.Err(err))
+		return ExitFailure
+	}
+	defer reader.Close()
+
+	c.log.Info("import started",
+		logx.String("file", c.args.File),
+		logx.String("dry_run", fmt.Sprint(c.args.DryRun)),
+		logx.Int("concurrency", c.cfg.Concurrency),
+	)
+
+	start := time.Now()
+	report, err := c.service.Import(ctx, reader, c.cfg.Concurrency, c.cfg.MaxRejected)
+
+	for _, r := range report.Rejected {
+		c.log.Warn("row rejected",
+			logx.Int("line", r.Line),
+			logx.String("sku", r.SKU),
+			logx.String("reason", r.Reason),
+		)
+	}
+
+	fields := []logx.Field{
+		logx.Int("read", report.Read),
+		logx.Int("imported", report.Imported),
+		logx.Int("rejected", len(report.Rejected)),
+		logx.String("duration", time.Since(start).String()),
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		c.log.Warn("import interrupted; it is safe to run again", fields...)
+		return ExitInterrupted
+	case err != nil:
+		c.log.Error("import failed", append(fields, logx.Err(err))...)
+		return ExitFailure
+	case len(report.Rejected) > 0:
+		c.log.Warn("import finished with rejected rows", fields...)
+		return ExitRejected
+	default:
+		c.log.Info("import finished", fields...)
+		return ExitOK
+	}
+}
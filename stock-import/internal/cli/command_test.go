@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+
+	"github.com/gostratum/examples/stock-import/internal/domain"
+	"github.com/gostratum/examples/stock-import/internal/usecase"
+)
+
+// fakeShutdowner counts shutdown requests
+type fakeShutdowner struct {
+	calls chan struct{}
+}
+
+func (s *fakeShutdowner) Shutdown(...fx.ShutdownOption) error {
+	s.calls <- struct{}{}
+	return nil
+}
+
+// writerFunc adapts a function to usecase.StockWriter
+type writerFunc func(ctx context.Context, row domain.Row) error
+
+func (f writerFunc) SetStock(ctx context.Context, row domain.Row) error { return f(ctx, row) }
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stock.csv")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestCommandExitCodes(t *testing.T) {
+	ok := writerFunc(func(ctx context.Context, row domain.Row) error { return nil })
+	down := writerFunc(func(ctx context.Context, row domain.Row) error {
+		return fmt.Errorf("%w: connection refused", usecase.ErrUnavailable)
+	})
+
+	tests := []struct {
+		name   string
+		file   string
+		writer usecase.StockWriter
+		want   int
+	}{
+		{name: "all imported", file: writeFile(t, "sku,name,available\nSKU1,Widget,1\n"), writer: ok, want: ExitOK},
+		{name: "rows rejected", file: writeFile(t, "sku,name,available\nSKU1,Widget,-1\nSKU2,Gadget,2\n"), writer: ok, want: ExitRejected},
+		{name: "writer unavailable", file: writeFile(t, "sku,name,available\nSKU1,Widget,1\n"), writer: down, want: ExitFailure},
+		{name: "missing file", file: filepath.Join(t.TempDir(), "missing.csv"), writer: ok, want: ExitFailure},
+		{name: "bad header", file: writeFile(t, "id,qty\n1,2\n"), writer: ok, want: ExitFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shutdowner := &fakeShutdowner{calls: make(chan struct{}, 1)}
+			cmd := newCommand(Args{File: tt.file}, Config{Concurrency: 2, MaxRejected: -1},
+				usecase.NewImportService(tt.writer), shutdowner, logx.NewNoopLogger())
+
+			require.NoError(t, cmd.start(context.Background()))
+			select {
+			case <-shutdowner.calls:
+			case <-time.After(time.Second):
+				t.Fatal("command did not shut the application down")
+			}
+			require.NoError(t, cmd.stop(context.Background()))
+			assert.Equal(t, tt.want, cmd.ExitCode())
+		})
+	}
+}
+
+func TestCommandInterrupted(t *testing.T) {
+	started := make(chan struct{})
+	blocking := writerFunc(func(ctx context.Context, row domain.Row) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	shutdowner := &fakeShutdowner{calls: make(chan struct{}, 1)}
+	cmd := newCommand(Args{File: writeFile(t, "sku,name,available\nSKU1,Widget,1\n")}, Config{Concurrency: 1, MaxRejected: -1},
+		usecase.NewImportService(blocking), shutdowner, logx.NewNoopLogger())
+
+	require.NoError(t, cmd.start(context.Background()))
+	<-started
+	assert.Equal(t, ExitInterrupted, cmd.ExitCode())
+
+	// What a signal does: the application stops while the import is running
+	require.NoError(t, cmd.stop(context.Background()))
+	assert.Equal(t, ExitInterrupted, cmd.ExitCode())
+	assert.Empty(t, shutdowner.calls)
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRowValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		row     Row
+		wantErr bool
+	}{
+		{name: "valid row", row: NewRow(2, "SKU1", "Widget", 10), wantErr: false},
+		{name: "zero available", row: NewRow(2, "SKU1", "Widget", 0), wantErr: false},
+		{name: "empty sku", row: NewRow(2, "  ", "Widget", 10), wantErr: true},
+		{name: "empty name", row: NewRow(2, "SKU1", "", 10), wantErr: true},
+		{name: "negative available", row: NewRow(2, "SKU1", "Widget", -1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.row.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Row.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidRow) {
+				t.Errorf("Row.Validate() error = %v, want ErrInvalidRow", err)
+			}
+		})
+	}
+}
+
+func TestRowError(t *testing.T) {
+	err := &RowError{Line: 7, SKU: "SKU1", Err: errors.New("bad quantity")}
+
+	if got := err.Error(); got != "line 7: bad quantity" {
+		t.Errorf("RowError.Error() = %q", got)
+	}
+	want := Rejection{Line: 7, SKU: "SKU1", Reason: "bad quantity"}
+	if got := err.Rejection(); got != want {
+		t.Errorf("RowError.Rejection() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,9 @@
+package domain
+
+import "errors"
+
+// Domain errors represent business rule violations
+var (
+	// ErrInvalidRow indicates a row violates the stock rules or cannot be parsed
+	ErrInvalidRow = errors.New("invalid row")
+)
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Row is one stock level to import, read from line Line of the source file
+// This is a pure domain model without infrastructure concerns
+type Row struct {
+	Line      int
+	SKU       string
+	Name      string
+	Available int
+}
+
+// NewRow creates a row with its fields trimmed
+func NewRow(line int, sku, name string, available int) Row {
+	return Row{
+		Line:      line,
+		SKU:       strings.TrimSpace(sku),
+		Name:      strings.TrimSpace(name),
+		Available: available,
+	}
+}
+
+// Validate applies the rules inventoryservice enforces, so bad rows are
+// rejected before any request is sent
+func (r Row) Validate() error {
+	if r.SKU == "" {
+		return fmt.Errorf("%w: sku is required", ErrInvalidRow)
+	}
+	if r.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidRow)
+	}
+	if r.Available < 0 {
+		return fmt.Errorf("%w: available units cannot be negative", ErrInvalidRow)
+	}
+	return nil
+}
+
+// Rejection records a row that was not imported and why
+type Rejection struct {
+	Line   int
+	SKU    string
+	Reason string
+}
+
+// RowError is a row that could not be read or validated. The import skips it
+// and carries on with the next row.
+type RowError struct {
+	Line int
+	SKU  string
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Rejection converts the error into the record kept in the report
+func (e *RowError) Rejection() Rejection {
+	return Rejection{Line: e.Line, SKU: e.SKU, Reason: e.Err.Error()}
+}
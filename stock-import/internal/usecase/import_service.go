@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/gostratum/examples/stock-import/internal/domain"
+)
+
+// Report summarizes an import
+type Report struct {
+	// Read counts every data row, including rejected ones
+	Read     int
+	Imported int
+	// Rejected rows, sorted by line
+	Rejected []domain.Rejection
+}
+
+// ImportService writes rows to the target system
+type ImportService struct {
+	writer StockWriter
+}
+
+// NewImportService creates a new import service with writer injection
+func NewImportService(writer StockWriter) *ImportService {
+	return &ImportService{writer: writer}
+}
+
+// Import writes every valid row, concurrency rows at a time. Invalid rows and
+// rows the writer rejects are skipped and listed in the report.
+//
+// The import stops early when ctx is done, when the writer is unavailable, or
+// once more than maxRejected rows were rejected (no limit when negative). The
+// report then covers the rows handled so far and the error says why it stopped.
+func (s *ImportService) Import(ctx context.Context, rows RowReader, concurrency, maxRejected int) (Report, error) {
+	if concurrency <= 0 {
+		return Report{}, fmt.Errorf("concurrency must be positive, got %d", concurrency)
+	}
+
+	ctx, stop := context.WithCancelCause(ctx)
+	defer stop(nil)
+
+	var (
+		mu       sync.Mutex
+		imported int
+		rejected []domain.Rejection
+	)
+	reject := func(r domain.Rejection) {
+		mu.Lock()
+		defer mu.Unlock()
+		rejected = append(rejected, r)
+		if maxRejected >= 0 && len(rejected) > maxRejected {
+			stop(fmt.Errorf("%w: more than %d", ErrTooManyRejected, maxRejected))
+		}
+	}
+
+	work := make(chan domain.Row)
+	var wg sync.WaitGroup
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for row := range work {
+				err := s.writer.SetStock(ctx, row)
+				switch {
+				case err == nil:
+					mu.Lock()
+					imported++
+					mu.Unlock()
+				case errors.Is(err, ErrRejected):
+					reject(domain.Rejection{Line: row.Line, SKU: row.SKU, Reason: err.Error()})
+				default:
+					// Unavailable or cancelled: the rows after this one would fail too
+					stop(err)
+				}
+			}
+		}()
+	}
+
+	read, readErr := s.feed(ctx, rows, work, reject)
+	close(work)
+	wg.Wait()
+
+	sort.Slice(rejected, func(i, j int) bool { return rejected[i].Line < rejected[j].Line })
+	report := Report{Read: read, Imported: imported, Rejected: rejected}
+
+	if readErr != nil {
+		return report, readErr
+	}
+	return report, context.Cause(ctx)
+}
+
+// feed reads rows and hands the valid ones to the workers until the reader is
+// exhausted or ctx is done. It returns the number of rows read.
+func (s *ImportService) feed(ctx context.Context, rows RowReader, work chan<- domain.Row, reject func(domain.Rejection)) (int, error) {
+	// Rows for the same SKU could be written in any order by concurrent
+	// workers, so only the first one is imported
+	seen := make(map[string]int)
+	read := 0
+
+	for ctx.Err() == nil {
+		row, err := rows.Next()
+		if errors.Is(err, io.EOF) {
+			return read, nil
+		}
+		var rowErr *domain.RowError
+		if errors.As(err, &rowErr) {
+			read++
+			reject(rowErr.Rejection())
+			continue
+		}
+		if err != nil {
+			return read, fmt.Errorf("failed to read rows: %w", err)
+		}
+		read++
+
+		if err := row.Validate(); err != nil {
+			reject(domain.Rejection{Line: row.Line, SKU: row.SKU, Reason: err.Error()})
+			continue
+		}
+		if first, ok := seen[row.SKU]; ok {
+			reject(domain.Rejection{Line: row.Line, SKU: row.SKU, Reason: fmt.Sprintf("duplicate of line %d", first)})
+			continue
+		}
+		seen[row.SKU] = row.Line
+
+		select {
+		case work <- row:
+		case <-ctx.Done():
+		}
+	}
+	return read, nil
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/stock-import/internal/domain"
+)
+
+// sliceReader returns rows and row errors from a slice
+type sliceReader struct {
+	rows []any
+}
+
+func (r *sliceReader) Next() (domain.Row, error) {
+	if len(r.rows) == 0 {
+		return domain.Row{}, io.EOF
+	}
+	next := r.rows[0]
+	r.rows = r.rows[1:]
+	if err, ok := next.(error); ok {
+		return domain.Row{}, err
+	}
+	return next.(domain.Row), nil
+}
+
+// fakeWriter records written rows and fails with the error set for a SKU
+type fakeWriter struct {
+	mu      sync.Mutex
+	written map[string]int
+	errs    map[string]error
+}
+
+func newFakeWriter() *fakeWriter {
+	return &fakeWriter{written: map[string]int{}, errs: map[string]error{}}
+}
+
+func (w *fakeWriter) SetStock(ctx context.Context, row domain.Row) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.errs[row.SKU]; err != nil {
+		return err
+	}
+	w.written[row.SKU] = row.Available
+	return nil
+}
+
+func TestImport(t *testing.T) {
+	writer := newFakeWriter()
+	writer.errs["REFUSED"] = fmt.Errorf("%w: INVALID_INPUT", ErrRejected)
+	rows := &sliceReader{rows: []any{
+		domain.NewRow(2, "SKU1", "Widget", 10),
+		domain.NewRow(3, "SKU2", "Gadget", 0),
+		domain.NewRow(4, "SKU3", "", 5),
+		&domain.RowError{Line: 5, SKU: "SKU4", Err: errors.New("available is not a number")},
+		domain.NewRow(6, "REFUSED", "Refused", 1),
+		domain.NewRow(7, "SKU1", "Widget again", 20),
+	}}
+
+	report, err := NewImportService(writer).Import(context.Background(), rows, 3, -1)
+	require.NoError(t, err)
+
+	assert.Equal(t, 6, report.Read)
+	assert.Equal(t, 2, report.Imported)
+	assert.Equal(t, map[string]int{"SKU1": 10, "SKU2": 0}, writer.written)
+
+	require.Len(t, report.Rejected, 4)
+	lines := make([]int, len(report.Rejected))
+	for i, r := range report.Rejected {
+		lines[i] = r.Line
+	}
+	assert.Equal(t, []int{4, 5, 6, 7}, lines)
+	assert.Contains(t, report.Rejected[0].Reason, "name is required")
+	assert.Equal(t, "available is not a number", report.Rejected[1].Reason)
+	assert.Contains(t, report.Rejected[2].Reason, "INVALID_INPUT")
+	assert.Equal(t, "duplicate of line 2", report.Rejected[3].Reason)
+}
+
+func TestImportStopsWhenWriterIsUnavailable(t *testing.T) {
+	writer := newFakeWriter()
+	writer.errs["SKU1"] = fmt.Errorf("%w: connection refused", ErrUnavailable)
+	var rows []any
+	for i := range 100 {
+		rows = append(rows, domain.NewRow(i+2, fmt.Sprintf("SKU%d", i+1), "Widget", 1))
+	}
+
+	report, err := NewImportService(writer).Import(context.Background(), &sliceReader{rows: rows}, 1, -1)
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Zero(t, report.Imported)
+	assert.Less(t, report.Read, 100)
+}
+
+func TestImportStopsAfterTooManyRejections(t *testing.T) {
+	writer := newFakeWriter()
+	var rows []any
+	for i := range 10 {
+		rows = append(rows, domain.NewRow(i+2, "", "Widget", 1))
+	}
+	rows = append(rows, domain.NewRow(12, "SKU1", "Widget", 1))
+
+	report, err := NewImportService(writer).Import(context.Background(), &sliceReader{rows: rows}, 2, 3)
+	assert.ErrorIs(t, err, ErrTooManyRejected)
+	assert.Len(t, report.Rejected, 4)
+	assert.Empty(t, writer.written)
+}
+
+func TestImportStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report, err := NewImportService(newFakeWriter()).Import(ctx, &sliceReader{rows: []any{domain.NewRow(2, "SKU1", "Widget", 1)}}, 1, -1)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, report.Imported)
+}
+
+func TestImportReadError(t *testing.T) {
+	rows := &sliceReader{rows: []any{domain.NewRow(2, "SKU1", "Widget", 1), errors.New("disk error")}}
+
+	report, err := NewImportService(newFakeWriter()).Import(context.Background(), rows, 1, -1)
+	assert.ErrorContains(t, err, "failed to read rows: disk error")
+	assert.Equal(t, 1, report.Imported)
+}
@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/gostratum/examples/stock-import/internal/domain"
+)
+
+// Application-level errors for use case layer
+// These are used to communicate failures to the presentation layer
+var (
+	// ErrUnavailable indicates the target system cannot take writes; the import stops
+	ErrUnavailable = errors.New("service unavailable")
+
+	// ErrRejected indicates the target system refused one row; the import carries on
+	ErrRejected = errors.New("row rejected")
+
+	// ErrTooManyRejected indicates more rows were rejected than allowed, which
+	// usually means the wrong file or a broken export
+	ErrTooManyRejected = errors.New("too many rejected rows")
+
+	// ErrInvalid wraps domain.ErrInvalidRow for application layer
+	ErrInvalid = domain.ErrInvalidRow
+)
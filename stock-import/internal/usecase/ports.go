@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/gostratum/examples/stock-import/internal/domain"
+)
+
+// RowReader reads the rows to import one at a time
+type RowReader interface {
+	// Next returns the next row, or io.EOF after the last one. A row that
+	// cannot be parsed is returned as a *domain.RowError, and reading can go on.
+	Next() (domain.Row, error)
+}
+
+// StockWriter stores stock levels in the target system. Writes must be
+// idempotent, so an interrupted import can simply be run again.
+type StockWriter interface {
+	SetStock(ctx context.Context, row domain.Row) error
+}
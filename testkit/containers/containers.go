@@ -0,0 +1,70 @@
+// Package containers starts Postgres and Redis in Docker for tests, through
+// testcontainers-go. A test binary starts each container once, when a test
+// first asks for it, and every test then gets a database of its own: tests
+// can run in parallel and leave nothing behind for the next one.
+//
+// Tests are skipped when Docker is not available, so go test ./... still
+// passes on a machine without it. Set TESTKIT_REQUIRE_DOCKER=1, as CI should,
+// to make them fail instead.
+package containers
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// RequireDockerEnv is the environment variable that makes tests fail, rather
+// than skip, when a container cannot be started
+const RequireDockerEnv = "TESTKIT_REQUIRE_DOCKER"
+
+// startTimeout bounds pulling an image and starting a container
+const startTimeout = 2 * time.Minute
+
+var (
+	mu       sync.Mutex
+	shutdown []func(context.Context) error
+)
+
+// Run runs the tests of m and then stops the containers they started. Call it
+// from TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(containers.Run(m))
+//	}
+//
+// Without it the containers are removed by testcontainers' reaper once the
+// test binary exits, which does not happen where the reaper is disabled.
+func Run(m *testing.M) int {
+	code := m.Run()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i := len(shutdown) - 1; i >= 0; i-- {
+		_ = shutdown[i](ctx)
+	}
+	shutdown = nil
+	return code
+}
+
+// onShutdown registers fn to run when Run returns
+func onShutdown(fn func(context.Context) error) {
+	mu.Lock()
+	defer mu.Unlock()
+	shutdown = append(shutdown, fn)
+}
+
+// unavailable ends the test when a container could not be started: it fails
+// when RequireDockerEnv is set and is skipped otherwise
+func unavailable(t testing.TB, what string, err error) {
+	t.Helper()
+	if os.Getenv(RequireDockerEnv) != "" {
+		t.Fatalf("failed to start %s: %v", what, err)
+	}
+	t.Skipf("skipping: %s is not available (set %s=1 to fail instead): %v", what, RequireDockerEnv, err)
+}
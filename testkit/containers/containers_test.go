@@ -0,0 +1,149 @@
+package containers
+
+import (
+	"context"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(Run(m))
+}
+
+var testMigrations = fstest.MapFS{
+	"000001_create_items.up.sql":   {Data: []byte("CREATE TABLE items (id uuid PRIMARY KEY DEFAULT gen_random_uuid(), name text NOT NULL UNIQUE);")},
+	"000001_create_items.down.sql": {Data: []byte("DROP TABLE items;")},
+	"000002_add_price.up.sql":      {Data: []byte("ALTER TABLE items ADD COLUMN price integer NOT NULL DEFAULT 0;\nCREATE INDEX idx_items_price ON items (price);")},
+}
+
+func connect(t *testing.T, dsn string) *pgx.Conn {
+	t.Helper()
+	conn, err := pgx.Connect(context.Background(), dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close(context.Background()) })
+	return conn
+}
+
+func TestPostgres_AppliesMigrations(t *testing.T) {
+	ctx := context.Background()
+	db := Postgres(t, WithMigrations(testMigrations))
+	conn := connect(t, db.DSN)
+
+	_, err := conn.Exec(ctx, "INSERT INTO items (name, price) VALUES ('widget', 250)")
+	require.NoError(t, err)
+
+	var version int64
+	var dirty bool
+	require.NoError(t, conn.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations").Scan(&version, &dirty))
+	assert.Equal(t, int64(2), version)
+	assert.False(t, dirty)
+}
+
+func TestPostgres_DatabasesAreIsolated(t *testing.T) {
+	ctx := context.Background()
+	first := Postgres(t, WithMigrations(testMigrations))
+	second := Postgres(t, WithMigrations(testMigrations))
+	require.NotEqual(t, first.Name, second.Name)
+
+	_, err := connect(t, first.DSN).Exec(ctx, "INSERT INTO items (name) VALUES ('widget')")
+	require.NoError(t, err)
+
+	var count int
+	require.NoError(t, connect(t, second.DSN).QueryRow(ctx, "SELECT count(*) FROM items").Scan(&count))
+	assert.Zero(t, count, "a copy of the template must not see rows of another copy")
+}
+
+func TestPostgres_WithoutMigrationsIsEmpty(t *testing.T) {
+	db := Postgres(t)
+
+	var count int
+	err := connect(t, db.DSN).QueryRow(context.Background(),
+		"SELECT count(*) FROM information_schema.tables WHERE table_schema = 'public'").Scan(&count)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestPostgresGorm_AppliesMigrations(t *testing.T) {
+	db := PostgresGorm(t, WithMigrations(testMigrations))
+
+	require.NoError(t, db.Exec("INSERT INTO items (name, price) VALUES ('widget', 250)").Error)
+	var price int
+	require.NoError(t, db.Raw("SELECT price FROM items WHERE name = 'widget'").Scan(&price).Error)
+	assert.Equal(t, 250, price)
+}
+
+func TestPostgres_DropsDatabaseAfterTest(t *testing.T) {
+	var name string
+	t.Run("uses a database", func(t *testing.T) {
+		db := Postgres(t)
+		name = db.Name
+		// Left open on purpose: dropping must not wait for it
+		connect(t, db.DSN)
+	})
+	if name == "" {
+		t.Skip("the subtest was skipped")
+	}
+
+	srv, err := postgresServerFor(DefaultPostgresImage)
+	require.NoError(t, err)
+	var exists bool
+	require.NoError(t, srv.admin.QueryRow(context.Background(),
+		"SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = $1)", name).Scan(&exists))
+	assert.False(t, exists)
+}
+
+func TestSharedPostgres_OutlivesTheTest(t *testing.T) {
+	var name string
+	t.Run("uses the shared database", func(t *testing.T) {
+		db := SharedPostgres(t, WithMigrations(testMigrations))
+		name = db.Name
+		_, err := connect(t, db.DSN).Exec(context.Background(), "INSERT INTO items (name) VALUES ('shared-widget')")
+		require.NoError(t, err)
+	})
+	if name == "" {
+		t.Skip("the subtest was skipped")
+	}
+
+	db := SharedPostgres(t, WithMigrations(testMigrations))
+	assert.Equal(t, name, db.Name)
+	assert.NotEqual(t, name, Postgres(t, WithMigrations(testMigrations)).Name)
+	assert.NotEqual(t, name, SharedPostgres(t).Name, "other options share another database")
+
+	var count int
+	require.NoError(t, connect(t, db.DSN).QueryRow(context.Background(),
+		"SELECT count(*) FROM items WHERE name = 'shared-widget'").Scan(&count))
+	assert.Equal(t, 1, count)
+}
+
+func TestRedis_FlushesDatabaseAfterTest(t *testing.T) {
+	ctx := context.Background()
+	var lent *RedisDB
+	t.Run("uses a database", func(t *testing.T) {
+		lent = Redis(t)
+		client := redis.NewClient(&redis.Options{Addr: lent.Addr, DB: lent.DB})
+		defer client.Close()
+		require.NoError(t, client.Set(ctx, "key", "value", 0).Err())
+	})
+	if lent == nil {
+		t.Skip("the subtest was skipped")
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: lent.Addr, DB: lent.DB})
+	defer client.Close()
+	size, err := client.DBSize(ctx).Result()
+	require.NoError(t, err)
+	assert.Zero(t, size)
+}
+
+func TestRedis_LendsEachDatabaseOnce(t *testing.T) {
+	first := Redis(t)
+	second := Redis(t)
+	assert.Equal(t, first.Addr, second.Addr)
+	assert.NotEqual(t, first.DB, second.DB)
+}
@@ -0,0 +1,28 @@
+package containers
+
+import (
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// PostgresGorm returns a GORM connection to a new database for t, created by
+// Postgres with opts. Its connections are closed when t ends, before the
+// database is dropped. Queries are not logged.
+func PostgresGorm(t testing.TB, opts ...PostgresOption) *gorm.DB {
+	t.Helper()
+	database := Postgres(t, opts...)
+
+	db, err := gorm.Open(postgres.Open(database.DSN), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to open test database %s: %v", database.Name, err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to open test database %s: %v", database.Name, err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
@@ -0,0 +1,57 @@
+package containers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/gostratum/examples/testkit/internal/migrationfiles"
+)
+
+// migrationTable is the table golang-migrate, and so dbx/migrate, records
+// the schema version in by default. It is filled in, so a service that
+// migrates on startup finds the test database up to date.
+const migrationTable = "schema_migrations"
+
+// migration is one up migration
+type migration = migrationfiles.File
+
+// readMigrations reads the *.up.sql files at the root of fsys in version
+// order. The digest identifies their names and contents.
+func readMigrations(fsys fs.FS) ([]migration, string, error) {
+	migrations, err := migrationfiles.ReadUp(fsys)
+	if err != nil {
+		return nil, "", err
+	}
+
+	h := sha256.New()
+	for _, m := range migrations {
+		fmt.Fprintf(h, "%s\x00%s\x00", m.Name, m.SQL)
+	}
+	return migrations, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// applyMigrations runs migrations in order and records the last version.
+// Like golang-migrate, it sends each file as one statement batch, which
+// Postgres runs in a transaction of its own.
+func applyMigrations(ctx context.Context, conn *pgx.Conn, migrations []migration) error {
+	for _, m := range migrations {
+		if _, err := conn.Exec(ctx, m.SQL); err != nil {
+			return fmt.Errorf("migration %s failed: %w", m.Name, err)
+		}
+	}
+
+	table := pgx.Identifier{migrationTable}.Sanitize()
+	if _, err := conn.Exec(ctx, "CREATE TABLE "+table+" (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)"); err != nil {
+		return fmt.Errorf("failed to create %s: %w", migrationTable, err)
+	}
+	last := migrations[len(migrations)-1].Version
+	if _, err := conn.Exec(ctx, "INSERT INTO "+table+" (version, dirty) VALUES ($1, false)", int64(last)); err != nil {
+		return fmt.Errorf("failed to record schema version: %w", err)
+	}
+	return nil
+}
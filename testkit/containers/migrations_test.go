@@ -0,0 +1,82 @@
+package containers
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadMigrations_OrdersByVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000010_add_index.up.sql":      {Data: []byte("CREATE INDEX a ON t (a);")},
+		"000002_create_table.up.sql":   {Data: []byte("CREATE TABLE t (a int);")},
+		"000002_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+		"README.md":                    {Data: []byte("# Migrations")},
+		"embed.go":                     {Data: []byte("package migrations")},
+	}
+
+	migrations, digest, err := readMigrations(fsys)
+	require.NoError(t, err)
+
+	require.Len(t, migrations, 2)
+	assert.Equal(t, uint64(2), migrations[0].Version)
+	assert.Equal(t, "CREATE TABLE t (a int);", migrations[0].SQL)
+	assert.Equal(t, uint64(10), migrations[1].Version)
+	assert.Len(t, digest, 64)
+}
+
+func TestReadMigrations_DigestFollowsContent(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000001_create_table.up.sql": {Data: []byte("CREATE TABLE t (a int);")},
+	}
+	_, before, err := readMigrations(fsys)
+	require.NoError(t, err)
+
+	fsys["000001_create_table.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE t (a bigint);")}
+	_, after, err := readMigrations(fsys)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, before, after, "a changed migration must get a new template")
+}
+
+func TestReadMigrations_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+		want string
+	}{
+		{
+			name: "no migrations",
+			fsys: fstest.MapFS{"README.md": {Data: []byte("# Migrations")}},
+			want: "no *.up.sql migrations found",
+		},
+		{
+			name: "no version",
+			fsys: fstest.MapFS{"create_table.up.sql": {}},
+			want: "not named {version}_{title}.up.sql",
+		},
+		{
+			name: "version is not a number",
+			fsys: fstest.MapFS{"v1_create_table.up.sql": {}},
+			want: "not named {version}_{title}.up.sql",
+		},
+		{
+			name: "same version twice",
+			fsys: fstest.MapFS{
+				"000001_create_users.up.sql":  {},
+				"000001_create_orders.up.sql": {},
+			},
+			want: "have the same version",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := readMigrations(tt.fsys)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
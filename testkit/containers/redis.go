@@ -0,0 +1,107 @@
+package containers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	tcredis "github.com/testcontainers/testcontainers-go/modules/redis"
+)
+
+// DefaultRedisImage is the image Redis runs from
+const DefaultRedisImage = "redis:7-alpine"
+
+// redisDatabases is the number of logical databases Redis has by default
+const redisDatabases = 16
+
+// RedisDB is a logical Redis database lent to one test
+type RedisDB struct {
+	// Addr is the host:port of the server
+	Addr string
+	// DB is the database number, for redis.Options.DB
+	DB int
+}
+
+// Redis lends t one of the server's 16 logical databases, empty, and flushes
+// it when t ends. When every database is lent out, Redis waits for a test to
+// give one back, so at most 16 tests use Redis at a time.
+func Redis(t testing.TB) *RedisDB {
+	t.Helper()
+	srv, err := redisServerFor()
+	if err != nil {
+		unavailable(t, "redis", err)
+	}
+
+	var db int
+	select {
+	case db = <-srv.free:
+	case <-time.After(startTimeout):
+		t.Fatalf("no Redis database became free within %s", startTimeout)
+	}
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		client := redis.NewClient(&redis.Options{Addr: srv.addr, DB: db})
+		defer client.Close()
+		if err := client.FlushDB(ctx).Err(); err != nil {
+			// Not handed out again: the next test would find it dirty
+			t.Errorf("failed to flush Redis database %d: %v", db, err)
+			return
+		}
+		srv.free <- db
+	})
+	return &RedisDB{Addr: srv.addr, DB: db}
+}
+
+var redisSrv = &redisServer{}
+
+// redisServer is the Redis container shared by every test of the binary
+type redisServer struct {
+	once sync.Once
+	err  error
+	addr string
+	free chan int
+}
+
+// redisServerFor returns the server, starting its container on first use
+func redisServerFor() (*redisServer, error) {
+	redisSrv.once.Do(redisSrv.start)
+	return redisSrv, redisSrv.err
+}
+
+func (s *redisServer) start() {
+	ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
+	defer cancel()
+
+	ctr, err := tcredis.Run(ctx, DefaultRedisImage)
+	if ctr != nil {
+		onShutdown(func(ctx context.Context) error { return ctr.Terminate(ctx) })
+	}
+	if err != nil {
+		s.err = err
+		return
+	}
+
+	conn, err := ctr.ConnectionString(ctx)
+	if err != nil {
+		s.err = fmt.Errorf("failed to read connection string: %w", err)
+		return
+	}
+	u, err := url.Parse(conn)
+	if err != nil {
+		s.err = fmt.Errorf("failed to parse connection string: %w", err)
+		return
+	}
+	s.addr = u.Host
+
+	s.free = make(chan int, redisDatabases)
+	for db := range redisDatabases {
+		s.free <- db
+	}
+}
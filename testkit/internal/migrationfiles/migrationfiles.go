@@ -0,0 +1,56 @@
+// Package migrationfiles reads a service's up migrations, named the way
+// golang-migrate names them: {version}_{title}.up.sql
+package migrationfiles
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// File is one up migration
+type File struct {
+	Version uint64
+	Name    string
+	SQL     string
+}
+
+// ReadUp reads the *.up.sql files at the root of fsys in version order
+func ReadUp(fsys fs.FS) ([]File, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var files []File
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".up.sql") {
+			continue
+		}
+		prefix, _, ok := strings.Cut(entry.Name(), "_")
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("migration %s is not named {version}_{title}.up.sql", entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		files = append(files, File{Version: version, Name: entry.Name(), SQL: string(content)})
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no *.up.sql migrations found")
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Version < files[j].Version
+	})
+	for i := 1; i < len(files); i++ {
+		if files[i].Version == files[i-1].Version {
+			return nil, fmt.Errorf("migrations %s and %s have the same version", files[i-1].Name, files[i].Name)
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,92 @@
+// Package sqlitedb opens in-memory SQLite databases for tests, with the
+// schema of a service's PostgreSQL migrations. The tests read the same
+// *.up.sql files the service migrates from, so a migration that adds a column
+// reaches them without anyone copying it into a CREATE TABLE in a test.
+//
+// The migrations are translated to SQLite with a few rewrites, listed at
+// Translate. Anything they do not cover is left as it is, and SQLite's error
+// names the migration. A schema that needs more than this, or a test of
+// PostgreSQL's own behaviour, belongs on containers.Postgres instead.
+package sqlitedb
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/gostratum/examples/testkit/internal/migrationfiles"
+)
+
+// seq names the databases, which are shared by the connections of one pool
+// and must not be by two tests
+var seq atomic.Int64
+
+// Open returns a new in-memory SQLite database with the *.up.sql migrations
+// at the root of fsys applied, or an empty one when fsys is nil. Foreign keys
+// are enforced, as in PostgreSQL. The database is gone when t ends.
+func Open(t testing.TB, fsys fs.FS) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:sqlitedb%d?mode=memory&cache=shared&_foreign_keys=on", seq.Add(1))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open SQLite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to open SQLite: %v", err)
+	}
+	// The database lives as long as a connection to it is open
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if fsys == nil {
+		return db
+	}
+	migrations, err := migrationfiles.ReadUp(fsys)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	for _, m := range migrations {
+		if err := db.Exec(Translate(m.SQL)).Error; err != nil {
+			t.Fatalf("migration %s failed on SQLite: %v", m.Name, err)
+		}
+	}
+	return db
+}
+
+// rewrites turn PostgreSQL into SQLite, in order
+var rewrites = []struct {
+	pattern *regexp.Regexp
+	with    string
+}{
+	// Only INTEGER PRIMARY KEY is an alias of the rowid, which SQLite
+	// numbers itself
+	{regexp.MustCompile(`(?i)\b(?:BIG|SMALL)?SERIAL\s+PRIMARY\s+KEY\b`), "INTEGER PRIMARY KEY AUTOINCREMENT"},
+	// The driver scans DATETIME columns into time.Time, but not TIMESTAMPTZ
+	{regexp.MustCompile(`(?i)\bTIMESTAMPTZ\b|\bTIMESTAMP\s+WITH(?:OUT)?\s+TIME\s+ZONE\b|\bTIMESTAMP\b`), "DATETIME"},
+	{regexp.MustCompile(`(?i)\bNOW\(\)`), "CURRENT_TIMESTAMP"},
+	// UUID would have numeric affinity
+	{regexp.MustCompile(`(?i)\bUUID\b`), "TEXT"},
+	{regexp.MustCompile(`(?i)\bJSONB?\b`), "TEXT"},
+}
+
+// Translate rewrites the PostgreSQL of a migration to SQLite:
+//
+//   - SERIAL PRIMARY KEY, and its BIG and SMALL forms, to INTEGER PRIMARY KEY AUTOINCREMENT
+//   - TIMESTAMP and TIMESTAMPTZ to DATETIME
+//   - NOW() to CURRENT_TIMESTAMP
+//   - UUID, JSON and JSONB to TEXT
+//
+// The rest, such as VARCHAR(n), CHECK, REFERENCES and IF NOT EXISTS, SQLite
+// takes as it is.
+func Translate(sql string) string {
+	for _, r := range rewrites {
+		sql = r.pattern.ReplaceAllString(sql, r.with)
+	}
+	return strings.TrimSpace(sql)
+}
@@ -0,0 +1,76 @@
+package sqlitedb
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMigrations = fstest.MapFS{
+	"000001_create_teams.up.sql": {Data: []byte(`
+		CREATE TABLE IF NOT EXISTS teams (
+			id UUID PRIMARY KEY,
+			name VARCHAR(100) NOT NULL UNIQUE,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);`)},
+	"000001_create_teams.down.sql": {Data: []byte("DROP TABLE teams;")},
+	"000002_create_members.up.sql": {Data: []byte(`
+		CREATE TABLE IF NOT EXISTS members (
+			id SERIAL PRIMARY KEY,
+			team_id UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+			joined_at TIMESTAMP NOT NULL DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_members_team_id ON members (team_id);`)},
+}
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		postgres string
+		want     string
+	}{
+		{"id SERIAL PRIMARY KEY", "id INTEGER PRIMARY KEY AUTOINCREMENT"},
+		{"id bigserial primary key", "id INTEGER PRIMARY KEY AUTOINCREMENT"},
+		{"at TIMESTAMPTZ NOT NULL DEFAULT NOW()", "at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP"},
+		{"at timestamp with time zone", "at DATETIME"},
+		{"at TIMESTAMP DEFAULT CURRENT_TIMESTAMP", "at DATETIME DEFAULT CURRENT_TIMESTAMP"},
+		{"id UUID REFERENCES teams(id)", "id TEXT REFERENCES teams(id)"},
+		{"attrs JSONB NOT NULL DEFAULT '{}'", "attrs TEXT NOT NULL DEFAULT '{}'"},
+		{"name VARCHAR(100) NOT NULL CHECK (name <> '')", "name VARCHAR(100) NOT NULL CHECK (name <> '')"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Translate(tt.postgres), tt.postgres)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	db := Open(t, testMigrations)
+
+	require.NoError(t, db.Exec("INSERT INTO teams (id, name) VALUES ('t-1', 'core')").Error)
+	require.NoError(t, db.Exec("INSERT INTO members (team_id) VALUES ('t-1'), ('t-1')").Error)
+
+	t.Run("defaults and types work", func(t *testing.T) {
+		var member struct {
+			ID       int
+			JoinedAt time.Time
+		}
+		require.NoError(t, db.Raw("SELECT id, joined_at FROM members ORDER BY id DESC LIMIT 1").Scan(&member).Error)
+		assert.Equal(t, 2, member.ID)
+		assert.WithinDuration(t, time.Now(), member.JoinedAt, time.Minute)
+	})
+
+	t.Run("constraints are enforced", func(t *testing.T) {
+		assert.Error(t, db.Exec("INSERT INTO teams (id, name) VALUES ('t-2', 'core')").Error, "unique")
+		assert.Error(t, db.Exec("INSERT INTO members (team_id) VALUES ('t-9')").Error, "foreign key")
+	})
+}
+
+func TestOpen_DatabasesAreSeparate(t *testing.T) {
+	a, b := Open(t, testMigrations), Open(t, nil)
+	require.NoError(t, a.Exec("INSERT INTO teams (id, name) VALUES ('t-1', 'core')").Error)
+
+	assert.True(t, a.Migrator().HasTable("teams"))
+	assert.False(t, b.Migrator().HasTable("teams"), "nil migrations give an empty database")
+}
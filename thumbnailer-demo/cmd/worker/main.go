@@ -0,0 +1,62 @@
+package main
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/examples/redact"
+	httpAdapter "github.com/gostratum/examples/thumbnailer-demo/internal/adapter/http"
+	storageAdapter "github.com/gostratum/examples/thumbnailer-demo/internal/adapter/storage"
+	"github.com/gostratum/examples/thumbnailer-demo/internal/poller"
+	"github.com/gostratum/examples/thumbnailer-demo/internal/tasks"
+	"github.com/gostratum/examples/workkit/workqueue"
+	"github.com/gostratum/httpx"
+	"github.com/gostratum/metricsx"
+	"github.com/gostratum/storagex"
+	s3Adapter "github.com/gostratum/storagex/adapters/s3"
+)
+
+func main() {
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// Prometheus metrics for the queue and thumbnail outcomes
+		metricsx.Module(),
+
+		// HTTP server that receives bucket notifications and serves health probes
+		httpx.Module(),
+
+		// Include storagex module with S3 adapter; MinIO locally
+		storagex.Module(),
+		s3Adapter.Module(),
+
+		// Bounded queue and worker pool; drains the queue on shutdown
+		workqueue.Module(),
+
+		// Provide dependencies
+		fx.Provide(
+			// Object store over storagex
+			storageAdapter.NewStore,
+
+			// Usecase service, configured from the thumbnails section
+			tasks.NewThumbnailService,
+
+			// Task handlers join the workqueue.handlers group
+			workqueue.AsHandler(tasks.NewThumbnailHandler),
+
+			// HTTP handlers
+			httpAdapter.NewEventHandler,
+		),
+
+		// Lists the bucket for sources notifications missed
+		poller.Module(),
+
+		// Invoke setup functions
+		fx.Invoke(
+			httpAdapter.RegisterRoutes,
+		),
+	)
+
+	app.Run()
+}
@@ -0,0 +1,30 @@
+package http
+
+// EventNotification is the body of an S3 or MinIO bucket notification; only
+// the fields the worker uses are decoded
+type EventNotification struct {
+	Records []EventRecord `json:"Records"`
+}
+
+// EventRecord is one event of a notification
+type EventRecord struct {
+	EventName string `json:"eventName"`
+	S3        struct {
+		Bucket struct {
+			Name string `json:"name"`
+		} `json:"bucket"`
+		Object struct {
+			Key  string `json:"key"`
+			Size int64  `json:"size"`
+			ETag string `json:"eTag"`
+		} `json:"object"`
+	} `json:"s3"`
+}
+
+// EventsResponse is the HTTP DTO answering a notification
+type EventsResponse struct {
+	// Queued records became thumbnail tasks
+	Queued int `json:"queued"`
+	// Ignored records were not about created objects under the base prefix
+	Ignored int `json:"ignored"`
+}
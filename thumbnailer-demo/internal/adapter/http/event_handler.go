@@ -0,0 +1,136 @@
+package http
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/thumbnailer-demo/internal/tasks"
+	"github.com/gostratum/examples/workkit/workqueue"
+)
+
+// maxEventBytes limits the size of a notification body
+const maxEventBytes = 1 << 20
+
+// EventsConfig secures and scopes the notification endpoint
+type EventsConfig struct {
+	// Token, when set, must be sent as "Authorization: Bearer <token>"
+	Token string `mapstructure:"token"`
+	// BasePrefix must match storagex.base_prefix. Notifications carry keys
+	// relative to the bucket; it is stripped from them, and keys outside it
+	// are ignored.
+	BasePrefix string `mapstructure:"base_prefix"`
+}
+
+// Prefix implements configx.Configurable
+func (EventsConfig) Prefix() string {
+	return "events"
+}
+
+// TaskQueue accepts tasks; implemented by workqueue.Pool
+type TaskQueue interface {
+	Enqueue(kind string, payload any) (workqueue.Task, error)
+}
+
+// EventHandler turns bucket notifications into thumbnail tasks
+type EventHandler struct {
+	cfg   EventsConfig
+	queue TaskQueue
+	log   logx.Logger
+}
+
+// NewEventHandler creates the handler from the events config section
+func NewEventHandler(loader configx.Loader, pool *workqueue.Pool, log logx.Logger) (*EventHandler, error) {
+	var cfg EventsConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load events config: %w", err)
+	}
+	return newEventHandler(cfg, pool, log), nil
+}
+
+func newEventHandler(cfg EventsConfig, queue TaskQueue, log logx.Logger) *EventHandler {
+	return &EventHandler{cfg: cfg, queue: queue, log: log}
+}
+
+// Receive handles POST /events, the webhook S3 and MinIO send bucket
+// notifications to. Each created object becomes a thumbnail task; the task
+// decides whether the object is a source at all. When the queue is full the
+// whole notification is refused with 503, so the sender delivers it again;
+// tasks queued before that run twice, which is harmless.
+func (h *EventHandler) Receive(c *gin.Context) {
+	if !h.authorized(c.GetHeader("Authorization")) {
+		responsex.Error(c, http.StatusUnauthorized, "UNAUTHORIZED", "missing or invalid token", nil)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxEventBytes)
+	var event EventNotification
+	if err := c.ShouldBindJSON(&event); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid notification payload", nil)
+		return
+	}
+
+	res := EventsResponse{}
+	for _, r := range event.Records {
+		key, ok := h.key(r)
+		if !ok {
+			res.Ignored++
+			continue
+		}
+		if _, err := h.queue.Enqueue(tasks.KindThumbnail, tasks.ThumbnailTask{Key: key}); err != nil {
+			h.handleError(c, err)
+			return
+		}
+		res.Queued++
+	}
+	responsex.OK(c, res, nil)
+}
+
+// key returns the storage key of a record about a created object
+func (h *EventHandler) key(r EventRecord) (string, bool) {
+	// "s3:ObjectCreated:Put" from MinIO, "ObjectCreated:Put" from S3
+	if !strings.Contains(r.EventName, "ObjectCreated:") {
+		return "", false
+	}
+	// Keys are URL-encoded in notifications, with spaces as "+"
+	key, err := url.QueryUnescape(r.S3.Object.Key)
+	if err != nil {
+		h.log.Warn("ignoring notification with malformed key", logx.String("key", r.S3.Object.Key))
+		return "", false
+	}
+	if !strings.HasPrefix(key, h.cfg.BasePrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(key, h.cfg.BasePrefix), true
+}
+
+func (h *EventHandler) authorized(header string) bool {
+	if h.cfg.Token == "" {
+		return true
+	}
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(h.cfg.Token)) == 1
+}
+
+// handleError maps queue errors to HTTP responses
+func (h *EventHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, workqueue.ErrQueueFull):
+		// Back pressure: the sender retries the notification later
+		c.Header("Retry-After", "1")
+		responsex.Error(c, http.StatusServiceUnavailable, "QUEUE_FULL", "too many tasks are waiting, retry later", nil)
+	case errors.Is(err, workqueue.ErrClosed):
+		responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "shutting down", nil)
+	default:
+		h.log.Error("event request failed", logx.Err(err))
+		responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+	}
+}
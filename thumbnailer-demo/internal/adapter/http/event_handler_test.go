@@ -0,0 +1,134 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/thumbnailer-demo/internal/tasks"
+	"github.com/gostratum/examples/workkit/workqueue"
+)
+
+// fakeQueue records the queued keys and fails with err when set
+type fakeQueue struct {
+	err  error
+	keys []string
+}
+
+func (q *fakeQueue) Enqueue(kind string, payload any) (workqueue.Task, error) {
+	if q.err != nil {
+		return workqueue.Task{}, q.err
+	}
+	q.keys = append(q.keys, payload.(tasks.ThumbnailTask).Key)
+	return workqueue.Task{ID: "task-1", Kind: kind, Payload: payload}, nil
+}
+
+func setupRouter(cfg EventsConfig, queue TaskQueue) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	handler := newEventHandler(cfg, queue, logx.NewNoopLogger())
+
+	e := gin.New()
+	e.POST("/events", handler.Receive)
+	return e
+}
+
+// minioEvent is a notification as MinIO sends it for one PUT, trimmed
+const minioEvent = `{
+  "EventName": "s3:ObjectCreated:Put",
+  "Key": "orderservice-avatars/avatars/u1_1700000000.png",
+  "Records": [{
+    "eventName": "s3:ObjectCreated:Put",
+    "s3": {
+      "bucket": {"name": "orderservice-avatars"},
+      "object": {"key": "avatars%2Fu1_1700000000.png", "size": 1024, "eTag": "d41d8cd98f00b204e9800998ecf8427e"}
+    }
+  }]
+}`
+
+func post(e *gin.Engine, body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w
+}
+
+func TestReceive(t *testing.T) {
+	queue := &fakeQueue{}
+	e := setupRouter(EventsConfig{}, queue)
+
+	w := post(e, minioEvent, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"avatars/u1_1700000000.png"}, queue.keys)
+
+	var resp responsex.Envelope[EventsResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, EventsResponse{Queued: 1}, resp.Data)
+}
+
+func TestReceiveFiltersRecords(t *testing.T) {
+	queue := &fakeQueue{}
+	e := setupRouter(EventsConfig{BasePrefix: "prod/"}, queue)
+
+	body := `{"Records": [
+	  {"eventName": "ObjectCreated:CompleteMultipartUpload", "s3": {"object": {"key": "prod/avatars/my+photo.jpg"}}},
+	  {"eventName": "ObjectRemoved:Delete", "s3": {"object": {"key": "prod/avatars/old.jpg"}}},
+	  {"eventName": "ObjectCreated:Put", "s3": {"object": {"key": "staging/avatars/u2.png"}}},
+	  {"eventName": "ObjectCreated:Put", "s3": {"object": {"key": "prod/avatars/bad%zz.png"}}}
+	]}`
+	w := post(e, body, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"avatars/my photo.jpg"}, queue.keys)
+
+	var resp responsex.Envelope[EventsResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, EventsResponse{Queued: 1, Ignored: 3}, resp.Data)
+}
+
+func TestReceiveRequiresToken(t *testing.T) {
+	queue := &fakeQueue{}
+	e := setupRouter(EventsConfig{Token: "secret"}, queue)
+
+	assert.Equal(t, http.StatusUnauthorized, post(e, minioEvent, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, post(e, minioEvent, "wrong").Code)
+	assert.Empty(t, queue.keys)
+
+	assert.Equal(t, http.StatusOK, post(e, minioEvent, "secret").Code)
+	assert.Len(t, queue.keys, 1)
+}
+
+func TestReceiveErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "malformed body", body: `{"Records": 1}`, wantStatus: http.StatusBadRequest, wantCode: "INVALID_REQUEST"},
+		{name: "queue full", body: minioEvent, err: workqueue.ErrQueueFull, wantStatus: http.StatusServiceUnavailable, wantCode: "QUEUE_FULL"},
+		{name: "shutting down", body: minioEvent, err: workqueue.ErrClosed, wantStatus: http.StatusServiceUnavailable, wantCode: "SERVICE_UNAVAILABLE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := setupRouter(EventsConfig{}, &fakeQueue{err: tt.err})
+
+			w := post(e, tt.body, "")
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantCode)
+		})
+	}
+}
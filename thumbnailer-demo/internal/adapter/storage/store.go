@@ -0,0 +1,100 @@
+// Package storage implements usecase.ObjectStore on top of storagex
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gostratum/storagex"
+
+	"github.com/gostratum/examples/thumbnailer-demo/internal/domain"
+	"github.com/gostratum/examples/thumbnailer-demo/internal/usecase"
+)
+
+// listPageSize is how many keys one List request asks for
+const listPageSize = 1000
+
+// Store reads and writes objects through storagex, so the same code runs
+// against S3, MinIO or any other backend storagex has an adapter for
+type Store struct {
+	storage storagex.Storage
+}
+
+// NewStore creates the object store
+func NewStore(storage storagex.Storage) usecase.ObjectStore {
+	return &Store{storage: storage}
+}
+
+// Head implements usecase.ObjectStore
+func (s *Store) Head(ctx context.Context, key string) (domain.Object, error) {
+	stat, err := s.storage.Head(ctx, key)
+	if err != nil {
+		return domain.Object{}, translateError(key, err)
+	}
+	return toDomain(stat), nil
+}
+
+// Get implements usecase.ObjectStore
+func (s *Store) Get(ctx context.Context, key string) (io.ReadCloser, domain.Object, error) {
+	body, stat, err := s.storage.Get(ctx, key)
+	if err != nil {
+		return nil, domain.Object{}, translateError(key, err)
+	}
+	return body, toDomain(stat), nil
+}
+
+// Put implements usecase.ObjectStore
+func (s *Store) Put(ctx context.Context, key string, r io.Reader, contentType string, metadata map[string]string) error {
+	_, err := s.storage.Put(ctx, key, r, &storagex.PutOptions{
+		ContentType: contentType,
+		Metadata:    metadata,
+		Overwrite:   true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put %s: %w", key, err)
+	}
+	return nil
+}
+
+// List implements usecase.ObjectStore
+func (s *Store) List(ctx context.Context, prefix string, fn func([]domain.Object) error) error {
+	opts := storagex.ListOptions{Prefix: prefix, PageSize: listPageSize}
+	for {
+		page, err := s.storage.List(ctx, opts)
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", prefix, err)
+		}
+		objects := make([]domain.Object, len(page.Keys))
+		for i, stat := range page.Keys {
+			objects[i] = toDomain(stat)
+		}
+		if err := fn(objects); err != nil {
+			return err
+		}
+		if !page.IsTruncated || page.NextToken == "" {
+			return nil
+		}
+		opts.ContinuationToken = page.NextToken
+	}
+}
+
+func toDomain(stat storagex.Stat) domain.Object {
+	return domain.Object{
+		Key:          stat.Key,
+		Size:         stat.Size,
+		ETag:         stat.ETag,
+		ContentType:  stat.ContentType,
+		LastModified: stat.LastModified,
+		Metadata:     stat.Metadata,
+	}
+}
+
+// translateError converts storagex errors to domain errors
+func translateError(key string, err error) error {
+	if errors.Is(err, storagex.ErrNotFound) {
+		return domain.ErrNotFound
+	}
+	return fmt.Errorf("failed to read %s: %w", key, err)
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gostratum/storagex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/thumbnailer-demo/internal/domain"
+)
+
+// fakeStorage serves List from pages and knows no objects
+type fakeStorage struct {
+	storagex.Storage
+	pages map[string]storagex.ListPage
+	lists []storagex.ListOptions
+	put   *storagex.PutOptions
+}
+
+func (f *fakeStorage) Head(ctx context.Context, key string) (storagex.Stat, error) {
+	return storagex.Stat{}, storagex.ErrNotFound
+}
+
+func (f *fakeStorage) Put(ctx context.Context, key string, r io.Reader, opts *storagex.PutOptions) (storagex.Stat, error) {
+	f.put = opts
+	return storagex.Stat{Key: key}, nil
+}
+
+func (f *fakeStorage) List(ctx context.Context, opts storagex.ListOptions) (storagex.ListPage, error) {
+	f.lists = append(f.lists, opts)
+	return f.pages[opts.ContinuationToken], nil
+}
+
+func TestListFollowsPages(t *testing.T) {
+	fake := &fakeStorage{pages: map[string]storagex.ListPage{
+		"":       {Keys: []storagex.Stat{{Key: "avatars/a.png", ETag: `"a"`}}, NextToken: "page-2", IsTruncated: true},
+		"page-2": {Keys: []storagex.Stat{{Key: "avatars/b.png", ETag: `"b"`}}},
+	}}
+	store := NewStore(fake)
+
+	var keys []string
+	err := store.List(context.Background(), "avatars/", func(objects []domain.Object) error {
+		for _, o := range objects {
+			keys = append(keys, o.Key)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"avatars/a.png", "avatars/b.png"}, keys)
+	require.Len(t, fake.lists, 2)
+	assert.Equal(t, "avatars/", fake.lists[1].Prefix)
+}
+
+func TestHeadMapsNotFound(t *testing.T) {
+	_, err := NewStore(&fakeStorage{}).Head(context.Background(), "avatars/a.png")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestPutOverwritesWithMetadata(t *testing.T) {
+	fake := &fakeStorage{}
+	metadata := map[string]string{domain.MetaSourceETag: `"a"`}
+
+	err := NewStore(fake).Put(context.Background(), "thumbnails/64/a.png", strings.NewReader("png"), "image/png", metadata)
+	require.NoError(t, err)
+	assert.Equal(t, &storagex.PutOptions{ContentType: "image/png", Metadata: metadata, Overwrite: true}, fake.put)
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "errors"
+
+// Domain errors for thumbnail generation
+var (
+	// ErrNotFound indicates an object does not exist (or no longer does)
+	ErrNotFound = errors.New("object not found")
+
+	// ErrUnsupportedImage indicates a source is not a PNG, JPEG or GIF image
+	ErrUnsupportedImage = errors.New("unsupported image")
+
+	// ErrImageTooLarge indicates a source is over the byte or pixel limit
+	ErrImageTooLarge = errors.New("image too large")
+
+	// ErrInvalidSpec indicates the thumbnail settings cannot work
+	ErrInvalidSpec = errors.New("invalid thumbnail spec")
+)
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// Metadata keys of a thumbnail, linking it to the version of the source it
+// was made from
+const (
+	MetaSourceKey  = "source-key"
+	MetaSourceETag = "source-etag"
+)
+
+// Object is a stored object, as far as thumbnails are concerned
+type Object struct {
+	Key          string
+	Size         int64
+	ETag         string
+	ContentType  string
+	LastModified time.Time
+	Metadata     map[string]string
+}
+
+// MadeFrom reports whether o is a thumbnail of the given version of a source
+func (o Object) MadeFrom(source Object) bool {
+	return source.ETag != "" && o.Metadata[MetaSourceETag] == source.ETag
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ThumbnailSpec says which objects get thumbnails, in which sizes, and where
+// the thumbnails go
+type ThumbnailSpec struct {
+	// SourcePrefix holds the images to make thumbnails of
+	SourcePrefix string
+	// TargetPrefix holds the thumbnails, as <TargetPrefix><size>/<name>
+	TargetPrefix string
+	// Sizes are the longest edges of the thumbnails in pixels
+	Sizes []int
+	// MaxBytes and MaxPixels keep a worker from loading huge images, or small
+	// files that decode to huge images
+	MaxBytes  int64
+	MaxPixels int
+	// JPEGQuality is the quality of JPEG thumbnails, 1-100
+	JPEGQuality int
+}
+
+// Rendition is one thumbnail of a source
+type Rendition struct {
+	Size int
+	Key  string
+	// Format is "jpeg" or "png"
+	Format string
+}
+
+// sourceFormats maps the extensions of the images that get thumbnails to the
+// format of their thumbnails. GIFs get a PNG of their first frame.
+var sourceFormats = map[string]string{
+	".jpg":  "jpeg",
+	".jpeg": "jpeg",
+	".png":  "png",
+	".gif":  "png",
+}
+
+// Validate checks the spec can work
+func (s ThumbnailSpec) Validate() error {
+	switch {
+	case s.SourcePrefix == "" || s.TargetPrefix == "":
+		return fmt.Errorf("%w: source and target prefixes are required", ErrInvalidSpec)
+	case strings.HasPrefix(s.TargetPrefix, s.SourcePrefix) || strings.HasPrefix(s.SourcePrefix, s.TargetPrefix):
+		// Thumbnails would become sources of thumbnails of their own
+		return fmt.Errorf("%w: source prefix %q and target prefix %q overlap", ErrInvalidSpec, s.SourcePrefix, s.TargetPrefix)
+	case len(s.Sizes) == 0:
+		return fmt.Errorf("%w: at least one size is required", ErrInvalidSpec)
+	case s.MaxBytes <= 0 || s.MaxPixels <= 0:
+		return fmt.Errorf("%w: max bytes and max pixels must be positive", ErrInvalidSpec)
+	case s.JPEGQuality < 1 || s.JPEGQuality > 100:
+		return fmt.Errorf("%w: JPEG quality %d is not between 1 and 100", ErrInvalidSpec, s.JPEGQuality)
+	}
+	for _, size := range s.Sizes {
+		if size < 1 {
+			return fmt.Errorf("%w: size %d is not positive", ErrInvalidSpec, size)
+		}
+	}
+	return nil
+}
+
+// IsSource reports whether the object at key gets thumbnails: an image with a
+// known extension under the source prefix
+func (s ThumbnailSpec) IsSource(key string) bool {
+	if !strings.HasPrefix(key, s.SourcePrefix) {
+		return false
+	}
+	_, ok := sourceFormats[strings.ToLower(path.Ext(key))]
+	return ok
+}
+
+// Renditions returns the thumbnails of the source at key
+func (s ThumbnailSpec) Renditions(key string) []Rendition {
+	ext := path.Ext(key)
+	name := strings.TrimSuffix(strings.TrimPrefix(key, s.SourcePrefix), ext)
+	format := sourceFormats[strings.ToLower(ext)]
+	out := ".png"
+	if format == "jpeg" {
+		out = ".jpg"
+	}
+
+	renditions := make([]Rendition, len(s.Sizes))
+	for i, size := range s.Sizes {
+		renditions[i] = Rendition{
+			Size:   size,
+			Key:    fmt.Sprintf("%s%d/%s%s", s.TargetPrefix, size, name, out),
+			Format: format,
+		}
+	}
+	return renditions
+}
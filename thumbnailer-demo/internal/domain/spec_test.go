@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testSpec() ThumbnailSpec {
+	return ThumbnailSpec{
+		SourcePrefix: "avatars/",
+		TargetPrefix: "thumbnails/",
+		Sizes:        []int{64, 256},
+		MaxBytes:     1 << 20,
+		MaxPixels:    1_000_000,
+		JPEGQuality:  85,
+	}
+}
+
+func TestThumbnailSpecValidate(t *testing.T) {
+	assert.NoError(t, testSpec().Validate())
+
+	tests := map[string]func(*ThumbnailSpec){
+		"no source prefix":     func(s *ThumbnailSpec) { s.SourcePrefix = "" },
+		"target inside source": func(s *ThumbnailSpec) { s.TargetPrefix = "avatars/thumbs/" },
+		"source inside target": func(s *ThumbnailSpec) { s.SourcePrefix = "thumbnails/avatars/" },
+		"no sizes":             func(s *ThumbnailSpec) { s.Sizes = nil },
+		"zero size":            func(s *ThumbnailSpec) { s.Sizes = []int{64, 0} },
+		"no byte limit":        func(s *ThumbnailSpec) { s.MaxBytes = 0 },
+		"quality over 100":     func(s *ThumbnailSpec) { s.JPEGQuality = 101 },
+	}
+	for name, change := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := testSpec()
+			change(&s)
+			assert.ErrorIs(t, s.Validate(), ErrInvalidSpec)
+		})
+	}
+}
+
+func TestThumbnailSpecIsSource(t *testing.T) {
+	s := testSpec()
+	assert.True(t, s.IsSource("avatars/u1_1700000000.png"))
+	assert.True(t, s.IsSource("avatars/u1_1700000000.JPEG"))
+	assert.False(t, s.IsSource("avatars/u1_1700000000.webp"), "the standard library cannot decode WebP")
+	assert.False(t, s.IsSource("thumbnails/64/u1_1700000000.png"))
+	assert.False(t, s.IsSource("avatars/"))
+}
+
+func TestThumbnailSpecRenditions(t *testing.T) {
+	s := testSpec()
+	assert.Equal(t, []Rendition{
+		{Size: 64, Key: "thumbnails/64/team/u1.jpg", Format: "jpeg"},
+		{Size: 256, Key: "thumbnails/256/team/u1.jpg", Format: "jpeg"},
+	}, s.Renditions("avatars/team/u1.jpeg"))
+	assert.Equal(t, "thumbnails/64/u1.png", s.Renditions("avatars/u1.gif")[0].Key)
+	assert.Equal(t, "png", s.Renditions("avatars/u1.gif")[0].Format)
+}
+
+func TestObjectMadeFrom(t *testing.T) {
+	src := Object{Key: "avatars/u1.png", ETag: `"abc"`}
+	assert.True(t, Object{Metadata: map[string]string{MetaSourceETag: `"abc"`}}.MadeFrom(src))
+	assert.False(t, Object{Metadata: map[string]string{MetaSourceETag: `"old"`}}.MadeFrom(src))
+	assert.False(t, Object{}.MadeFrom(Object{}), "without ETags nothing is known to be up to date")
+}
@@ -0,0 +1,20 @@
+// Package poller finds sources without up-to-date thumbnails by listing the
+// bucket, for the uploads notifications never announced: those made while the
+// worker was down, lost notifications, and failed tasks
+package poller
+
+import "time"
+
+// Config sets how often the bucket is listed
+type Config struct {
+	// Interval between listings; 0 disables polling, leaving notifications only
+	Interval time.Duration `mapstructure:"interval" default:"30s"`
+	// RetryAfter is how long a source that was queued and is still behind
+	// waits before it is queued again
+	RetryAfter time.Duration `mapstructure:"retry_after" default:"5m"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "poller"
+}
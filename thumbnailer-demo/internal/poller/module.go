@@ -0,0 +1,11 @@
+package poller
+
+import "go.uber.org/fx"
+
+// Module provides the Poller and runs it from application start to stop
+func Module() fx.Option {
+	return fx.Module("poller",
+		fx.Provide(NewPoller),
+		fx.Invoke(Register),
+	)
+}
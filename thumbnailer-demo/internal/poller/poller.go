@@ -0,0 +1,177 @@
+package poller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"go.uber.org/fx"
+
+	"github.com/gostratum/examples/thumbnailer-demo/internal/domain"
+	"github.com/gostratum/examples/thumbnailer-demo/internal/tasks"
+	"github.com/gostratum/examples/thumbnailer-demo/internal/usecase"
+	"github.com/gostratum/examples/workkit/workqueue"
+)
+
+// Sources lists the sources that are behind; implemented by usecase.ThumbnailService
+type Sources interface {
+	Pending(ctx context.Context) ([]domain.Object, error)
+}
+
+// Queue accepts tasks; implemented by workqueue.Pool
+type Queue interface {
+	Enqueue(kind string, payload any) (workqueue.Task, error)
+}
+
+// Params holds the dependencies of the Poller
+type Params struct {
+	fx.In
+
+	Loader  configx.Loader
+	Service *usecase.ThumbnailService
+	Pool    *workqueue.Pool
+	Log     logx.Logger
+}
+
+// Poller lists the bucket every interval and queues the sources that are behind
+type Poller struct {
+	cfg     Config
+	sources Sources
+	queue   Queue
+	log     logx.Logger
+	now     func() time.Time
+
+	// queued maps source keys to when they were last queued; only poll touches it
+	queued map[string]time.Time
+
+	stopping chan struct{}
+	done     chan struct{}
+}
+
+// NewPoller creates a Poller from the poller config section
+func NewPoller(p Params) (*Poller, error) {
+	var cfg Config
+	if err := p.Loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load poller config: %w", err)
+	}
+	if cfg.Interval < 0 || cfg.RetryAfter < 0 {
+		return nil, fmt.Errorf("poller.interval and poller.retry_after must not be negative")
+	}
+	return newPoller(cfg, p.Service, p.Pool, p.Log), nil
+}
+
+func newPoller(cfg Config, sources Sources, queue Queue, log logx.Logger) *Poller {
+	return &Poller{
+		cfg:      cfg,
+		sources:  sources,
+		queue:    queue,
+		log:      log,
+		now:      time.Now,
+		queued:   make(map[string]time.Time),
+		stopping: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Register ties the polling loop to the application lifecycle.
+// This function is designed to be used with fx.Invoke.
+func Register(lc fx.Lifecycle, p *Poller) {
+	lc.Append(fx.Hook{
+		OnStart: p.start,
+		OnStop:  p.stop,
+	})
+}
+
+// start begins polling in the background. The first listing runs right away,
+// catching up with what was uploaded while the worker was down.
+func (p *Poller) start(context.Context) error {
+	if p.cfg.Interval == 0 {
+		p.log.Info("polling disabled; thumbnails follow notifications only")
+		close(p.done)
+		return nil
+	}
+	go p.loop()
+	return nil
+}
+
+func (p *Poller) stop(ctx context.Context) error {
+	if p.cfg.Interval == 0 {
+		return nil
+	}
+	close(p.stopping)
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Poller) loop() {
+	defer close(p.done)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-p.stopping:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if err := p.poll(ctx); err != nil && ctx.Err() == nil {
+			p.log.Warn("failed to list sources", logx.Err(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll queues the sources that are behind, except those queued less than
+// RetryAfter ago, which are likely still waiting in the queue
+func (p *Poller) poll(ctx context.Context) error {
+	pending, err := p.sources.Pending(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := p.now()
+	behind := make(map[string]bool, len(pending))
+	queued := 0
+	for _, o := range pending {
+		behind[o.Key] = true
+		if at, ok := p.queued[o.Key]; ok && now.Sub(at) < p.cfg.RetryAfter {
+			continue
+		}
+		if _, err := p.queue.Enqueue(tasks.KindThumbnail, tasks.ThumbnailTask{Key: o.Key}); err != nil {
+			if errors.Is(err, workqueue.ErrQueueFull) {
+				// The rest waits for the next listing
+				p.log.Debug("queue is full; polling again later", logx.Int("queued", queued))
+				break
+			}
+			return fmt.Errorf("failed to queue %s: %w", o.Key, err)
+		}
+		p.queued[o.Key] = now
+		queued++
+	}
+
+	// Forget sources that caught up
+	for key := range p.queued {
+		if !behind[key] {
+			delete(p.queued, key)
+		}
+	}
+	if queued > 0 {
+		p.log.Info("queued sources that are behind", logx.Int("queued", queued), logx.Int("pending", len(pending)))
+	}
+	return nil
+}
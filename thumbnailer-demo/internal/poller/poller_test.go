@@ -0,0 +1,114 @@
+package poller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/thumbnailer-demo/internal/domain"
+	"github.com/gostratum/examples/thumbnailer-demo/internal/tasks"
+	"github.com/gostratum/examples/workkit/workqueue"
+)
+
+// fakeSources returns the keys in pending as the sources that are behind
+type fakeSources struct {
+	pending []string
+}
+
+func (f *fakeSources) Pending(ctx context.Context) ([]domain.Object, error) {
+	objects := make([]domain.Object, len(f.pending))
+	for i, key := range f.pending {
+		objects[i] = domain.Object{Key: key}
+	}
+	return objects, nil
+}
+
+// fakeQueue records queued keys and holds at most capacity of them
+type fakeQueue struct {
+	capacity int
+	keys     []string
+}
+
+func (q *fakeQueue) Enqueue(kind string, payload any) (workqueue.Task, error) {
+	if len(q.keys) == q.capacity {
+		return workqueue.Task{}, workqueue.ErrQueueFull
+	}
+	q.keys = append(q.keys, payload.(tasks.ThumbnailTask).Key)
+	return workqueue.Task{Kind: kind, Payload: payload}, nil
+}
+
+func newTestPoller(sources *fakeSources, queue *fakeQueue) (*Poller, *time.Time) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	p := newPoller(Config{Interval: time.Minute, RetryAfter: 5 * time.Minute}, sources, queue, logx.NewNoopLogger())
+	p.now = func() time.Time { return now }
+	return p, &now
+}
+
+func TestPollQueuesPendingSources(t *testing.T) {
+	sources := &fakeSources{pending: []string{"avatars/a.png", "avatars/b.png"}}
+	queue := &fakeQueue{capacity: 100}
+	p, now := newTestPoller(sources, queue)
+	ctx := context.Background()
+
+	require.NoError(t, p.poll(ctx))
+	assert.Equal(t, []string{"avatars/a.png", "avatars/b.png"}, queue.keys)
+
+	// Still behind, but queued a moment ago
+	*now = now.Add(time.Minute)
+	require.NoError(t, p.poll(ctx))
+	assert.Len(t, queue.keys, 2)
+
+	// Still behind after retry_after: the task failed, try again
+	*now = now.Add(5 * time.Minute)
+	require.NoError(t, p.poll(ctx))
+	assert.Equal(t, []string{"avatars/a.png", "avatars/b.png", "avatars/a.png", "avatars/b.png"}, queue.keys)
+}
+
+func TestPollForgetsSourcesThatCaughtUp(t *testing.T) {
+	sources := &fakeSources{pending: []string{"avatars/a.png"}}
+	queue := &fakeQueue{capacity: 100}
+	p, _ := newTestPoller(sources, queue)
+	ctx := context.Background()
+
+	require.NoError(t, p.poll(ctx))
+	sources.pending = nil
+	require.NoError(t, p.poll(ctx))
+	assert.Empty(t, p.queued)
+
+	// Replaced, so behind again; queued without waiting for retry_after
+	sources.pending = []string{"avatars/a.png"}
+	require.NoError(t, p.poll(ctx))
+	assert.Equal(t, []string{"avatars/a.png", "avatars/a.png"}, queue.keys)
+}
+
+func TestPollStopsWhenQueueIsFull(t *testing.T) {
+	sources := &fakeSources{pending: []string{"avatars/a.png", "avatars/b.png", "avatars/c.png"}}
+	queue := &fakeQueue{capacity: 1}
+	p, _ := newTestPoller(sources, queue)
+	ctx := context.Background()
+
+	require.NoError(t, p.poll(ctx))
+	assert.Equal(t, []string{"avatars/a.png"}, queue.keys)
+
+	// The rest goes on the next listing
+	queue.capacity = 100
+	require.NoError(t, p.poll(ctx))
+	assert.Equal(t, []string{"avatars/a.png", "avatars/b.png", "avatars/c.png"}, queue.keys)
+}
+
+func TestStartAndStop(t *testing.T) {
+	sources := &fakeSources{pending: []string{"avatars/a.png"}}
+	p := newPoller(Config{Interval: time.Hour, RetryAfter: time.Hour}, sources, &fakeQueue{capacity: 100}, logx.NewNoopLogger())
+
+	require.NoError(t, p.start(context.Background()))
+	require.NoError(t, p.stop(context.Background()))
+
+	// Disabled polling starts and stops without a loop
+	p = newPoller(Config{}, sources, &fakeQueue{}, logx.NewNoopLogger())
+	require.NoError(t, p.start(context.Background()))
+	require.NoError(t, p.stop(context.Background()))
+}
@@ -0,0 +1,134 @@
+// Package tasks holds the workqueue handler that turns queued keys into thumbnails
+package tasks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/metricsx"
+
+	"github.com/gostratum/examples/thumbnailer-demo/internal/domain"
+	"github.com/gostratum/examples/thumbnailer-demo/internal/usecase"
+	"github.com/gostratum/examples/workkit/workqueue"
+)
+
+// KindThumbnail is the task kind of thumbnail generation
+const KindThumbnail = "thumbnail"
+
+// outcomeRejected counts sources that cannot get thumbnails
+const outcomeRejected = "rejected"
+
+// ThumbnailTask is the payload of a thumbnail task
+type ThumbnailTask struct {
+	// Key of the object that was created or changed
+	Key string
+}
+
+// ThumbnailConfig says which objects get thumbnails and how
+type ThumbnailConfig struct {
+	// SourcePrefix holds the images to make thumbnails of
+	SourcePrefix string `mapstructure:"source_prefix" default:"avatars/"`
+	// TargetPrefix holds the thumbnails, as <target_prefix><size>/<name>
+	TargetPrefix string `mapstructure:"target_prefix" default:"thumbnails/"`
+	// Sizes are the longest edges of the thumbnails in pixels
+	Sizes       []int `mapstructure:"sizes"`
+	MaxBytes    int64 `mapstructure:"max_bytes" default:"10485760"`
+	MaxPixels   int   `mapstructure:"max_pixels" default:"40000000"`
+	JPEGQuality int   `mapstructure:"jpeg_quality" default:"85"`
+}
+
+// Prefix implements configx.Configurable
+func (ThumbnailConfig) Prefix() string {
+	return "thumbnails"
+}
+
+// NewThumbnailService creates the thumbnail service from the thumbnails config section
+func NewThumbnailService(loader configx.Loader, store usecase.ObjectStore) (*usecase.ThumbnailService, error) {
+	var cfg ThumbnailConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load thumbnails config: %w", err)
+	}
+	svc, err := usecase.NewThumbnailService(store, domain.ThumbnailSpec{
+		SourcePrefix: cfg.SourcePrefix,
+		TargetPrefix: cfg.TargetPrefix,
+		Sizes:        cfg.Sizes,
+		MaxBytes:     cfg.MaxBytes,
+		MaxPixels:    cfg.MaxPixels,
+		JPEGQuality:  cfg.JPEGQuality,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid thumbnails config: %w", err)
+	}
+	return svc, nil
+}
+
+// Processor brings the thumbnails of one source up to date; implemented by
+// usecase.ThumbnailService
+type Processor interface {
+	Process(ctx context.Context, key string) (usecase.Outcome, error)
+}
+
+// ThumbnailHandler runs thumbnail tasks. Scaling is CPU-bound, so the pool
+// size caps how many cores thumbnails can use, however many uploads arrive.
+type ThumbnailHandler struct {
+	svc      Processor
+	outcomes counter
+	log      logx.Logger
+}
+
+// NewThumbnailHandler creates the handler and registers its metric
+func NewThumbnailHandler(svc *usecase.ThumbnailService, metrics metricsx.Metrics, log logx.Logger) *ThumbnailHandler {
+	outcomes := metrics.Counter("thumbnailer_objects_total",
+		metricsx.WithHelp("Objects processed, by outcome (created, up_to_date, skipped, rejected)"),
+		metricsx.WithLabels("outcome"),
+	)
+	return newThumbnailHandler(svc, outcomes, log)
+}
+
+func newThumbnailHandler(svc Processor, outcomes counter, log logx.Logger) *ThumbnailHandler {
+	return &ThumbnailHandler{svc: svc, outcomes: outcomes, log: log}
+}
+
+// Kind implements workqueue.Handler
+func (h *ThumbnailHandler) Kind() string {
+	return KindThumbnail
+}
+
+// Handle implements workqueue.Handler. Failures are not retried here; the
+// poller picks up sources that are still behind.
+func (h *ThumbnailHandler) Handle(ctx context.Context, task workqueue.Task) error {
+	payload, ok := task.Payload.(ThumbnailTask)
+	if !ok {
+		return fmt.Errorf("unexpected payload %T", task.Payload)
+	}
+
+	outcome, err := h.svc.Process(ctx, payload.Key)
+	if usecase.IsPermanent(err) {
+		h.outcomes.Inc(outcomeRejected)
+		h.log.Warn("source cannot get thumbnails",
+			logx.String("task_id", task.ID),
+			logx.String("key", payload.Key),
+			logx.Err(err),
+		)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	h.outcomes.Inc(string(outcome))
+	if outcome == usecase.OutcomeCreated {
+		h.log.Info("thumbnails created",
+			logx.String("task_id", task.ID),
+			logx.String("key", payload.Key),
+		)
+	}
+	return nil
+}
+
+// counter is the part of metricsx.Counter the handler uses
+type counter interface {
+	Inc(labels ...string)
+}
@@ -0,0 +1,84 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gostratum/examples/thumbnailer-demo/internal/usecase"
+	"github.com/gostratum/examples/workkit/workqueue"
+)
+
+// fakeCounter counts increments per label value
+type fakeCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (f *fakeCounter) Inc(labels ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.counts[labels[0]]++
+}
+
+// processFunc implements Processor
+type processFunc func(ctx context.Context, key string) (usecase.Outcome, error)
+
+func (f processFunc) Process(ctx context.Context, key string) (usecase.Outcome, error) {
+	return f(ctx, key)
+}
+
+func newTestHandler(process processFunc) (*ThumbnailHandler, *fakeCounter) {
+	outcomes := &fakeCounter{counts: map[string]int{}}
+	return newThumbnailHandler(process, outcomes, logx.NewNoopLogger()), outcomes
+}
+
+func task(payload any) workqueue.Task {
+	return workqueue.Task{ID: "task-1", Kind: KindThumbnail, Payload: payload}
+}
+
+func TestThumbnailHandlerCountsOutcomes(t *testing.T) {
+	var keys []string
+	h, outcomes := newTestHandler(func(ctx context.Context, key string) (usecase.Outcome, error) {
+		keys = append(keys, key)
+		if key == "avatars/a.png" {
+			return usecase.OutcomeCreated, nil
+		}
+		return usecase.OutcomeUpToDate, nil
+	})
+
+	assert.NoError(t, h.Handle(context.Background(), task(ThumbnailTask{Key: "avatars/a.png"})))
+	assert.NoError(t, h.Handle(context.Background(), task(ThumbnailTask{Key: "avatars/b.png"})))
+	assert.Equal(t, []string{"avatars/a.png", "avatars/b.png"}, keys)
+	assert.Equal(t, map[string]int{"created": 1, "up_to_date": 1}, outcomes.counts)
+}
+
+func TestThumbnailHandlerFailures(t *testing.T) {
+	errUnsupported := fmt.Errorf("%w: avatars/a.png", usecase.ErrUnsupportedImage)
+	h, outcomes := newTestHandler(func(ctx context.Context, key string) (usecase.Outcome, error) {
+		if key == "avatars/a.png" {
+			return "", errUnsupported
+		}
+		return "", usecase.ErrUnavailable
+	})
+
+	err := h.Handle(context.Background(), task(ThumbnailTask{Key: "avatars/a.png"}))
+	assert.ErrorIs(t, err, usecase.ErrUnsupportedImage)
+	err = h.Handle(context.Background(), task(ThumbnailTask{Key: "avatars/b.png"}))
+	assert.ErrorIs(t, err, usecase.ErrUnavailable)
+
+	// Store failures show in the workqueue metrics; only rejections are counted here
+	assert.Equal(t, map[string]int{"rejected": 1}, outcomes.counts)
+}
+
+func TestThumbnailHandlerRejectsUnknownPayload(t *testing.T) {
+	h, _ := newTestHandler(func(ctx context.Context, key string) (usecase.Outcome, error) {
+		return "", errors.New("not called")
+	})
+	assert.ErrorContains(t, h.Handle(context.Background(), task("avatars/a.png")), "unexpected payload string")
+}
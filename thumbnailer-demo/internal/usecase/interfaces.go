@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/gostratum/examples/thumbnailer-demo/internal/domain"
+)
+
+// Application-level errors for use case layer
+// These are used to communicate failures to the presentation layer
+var (
+	// ErrUnavailable indicates the object store is temporarily unavailable (infrastructure failure)
+	ErrUnavailable = errors.New("service unavailable")
+
+	// ErrNotFound wraps domain.ErrNotFound for application layer
+	ErrNotFound = domain.ErrNotFound
+
+	// ErrUnsupportedImage wraps domain.ErrUnsupportedImage for application layer
+	ErrUnsupportedImage = domain.ErrUnsupportedImage
+
+	// ErrImageTooLarge wraps domain.ErrImageTooLarge for application layer
+	ErrImageTooLarge = domain.ErrImageTooLarge
+)
+
+// IsPermanent reports whether processing a source failed in a way that trying
+// again cannot fix until the source itself changes
+func IsPermanent(err error) bool {
+	return errors.Is(err, ErrUnsupportedImage) || errors.Is(err, ErrImageTooLarge)
+}
@@ -0,0 +1,22 @@
+package usecase
+
+import (
+	"context"
+	"io"
+
+	"github.com/gostratum/examples/thumbnailer-demo/internal/domain"
+)
+
+// ObjectStore defines the interface for the bucket holding sources and thumbnails
+// This interface is owned by the use case layer (dependency inversion principle)
+type ObjectStore interface {
+	// Head returns an object without its content, or domain.ErrNotFound
+	Head(ctx context.Context, key string) (domain.Object, error)
+	// Get returns the content of an object, or domain.ErrNotFound. The caller
+	// closes the reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, domain.Object, error)
+	// Put stores an object, replacing any object at key
+	Put(ctx context.Context, key string, r io.Reader, contentType string, metadata map[string]string) error
+	// List calls fn with every object under prefix, a page at a time
+	List(ctx context.Context, prefix string, fn func([]domain.Object) error) error
+}
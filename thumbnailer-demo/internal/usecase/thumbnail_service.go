@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // Register GIF decoding
+	"image/jpeg"
+	"image/png"
+	"io"
+	"sync"
+
+	"github.com/gostratum/examples/thumbnailer-demo/internal/domain"
+	"github.com/gostratum/examples/workkit/imaging"
+)
+
+// Outcome is what processing a source did
+type Outcome string
+
+const (
+	// OutcomeCreated means thumbnails were written
+	OutcomeCreated Outcome = "created"
+	// OutcomeUpToDate means every thumbnail was already made from the current source
+	OutcomeUpToDate Outcome = "up_to_date"
+	// OutcomeSkipped means the key is not a source, or no longer exists
+	OutcomeSkipped Outcome = "skipped"
+)
+
+// ThumbnailService keeps the thumbnails of the sources in a bucket up to date
+type ThumbnailService struct {
+	store ObjectStore
+	spec  domain.ThumbnailSpec
+
+	// done maps source keys to the ETag they were last processed at, so
+	// Pending lists only new and changed sources
+	mu   sync.Mutex
+	done map[string]string
+}
+
+// NewThumbnailService creates a new thumbnail service with store injection
+func NewThumbnailService(store ObjectStore, spec domain.ThumbnailSpec) (*ThumbnailService, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return &ThumbnailService{store: store, spec: spec, done: make(map[string]string)}, nil
+}
+
+// Process brings the thumbnails of the source at key up to date. It is safe to
+// call for any key, any number of times: keys that are not sources are
+// skipped, and thumbnails already made from the current version of the source
+// are left alone, so duplicate and late notifications cost a few HEAD requests.
+func (s *ThumbnailService) Process(ctx context.Context, key string) (Outcome, error) {
+	if !s.spec.IsSource(key) {
+		return OutcomeSkipped, nil
+	}
+
+	src, err := s.store.Head(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		// Deleted since it was announced
+		return OutcomeSkipped, nil
+	}
+	if err != nil {
+		return "", s.translateError(err)
+	}
+
+	stale, err := s.stale(ctx, src)
+	if err != nil {
+		return "", err
+	}
+	if len(stale) == 0 {
+		s.markDone(src)
+		return OutcomeUpToDate, nil
+	}
+
+	img, src, err := s.load(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return OutcomeSkipped, nil
+	}
+	if IsPermanent(err) {
+		// Not worth downloading again until it changes
+		s.markDone(src)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	for _, r := range stale {
+		if err := s.write(ctx, src, img, r); err != nil {
+			return "", err
+		}
+	}
+	s.markDone(src)
+	return OutcomeCreated, nil
+}
+
+// Pending returns the sources that changed, or appeared, since they were last
+// processed by this instance
+func (s *ThumbnailService) Pending(ctx context.Context) ([]domain.Object, error) {
+	var pending []domain.Object
+	err := s.store.List(ctx, s.spec.SourcePrefix, func(objects []domain.Object) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, o := range objects {
+			if s.spec.IsSource(o.Key) && s.done[o.Key] != o.ETag {
+				pending = append(pending, o)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, s.translateError(err)
+	}
+	return pending, nil
+}
+
+// stale returns the renditions of src that are missing or were made from an
+// older version of it
+func (s *ThumbnailService) stale(ctx context.Context, src domain.Object) ([]domain.Rendition, error) {
+	var stale []domain.Rendition
+	for _, r := range s.spec.Renditions(src.Key) {
+		thumb, err := s.store.Head(ctx, r.Key)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			stale = append(stale, r)
+		case err != nil:
+			return nil, s.translateError(err)
+		case !thumb.MadeFrom(src):
+			stale = append(stale, r)
+		}
+	}
+	return stale, nil
+}
+
+// load downloads and decodes a source within the byte and pixel limits. It
+// returns the object as downloaded, which may be newer than the one checked
+// before; the thumbnails record the version they were made from.
+func (s *ThumbnailService) load(ctx context.Context, key string) (image.Image, domain.Object, error) {
+	body, src, err := s.store.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, src, err
+		}
+		return nil, src, s.translateError(err)
+	}
+	defer body.Close()
+
+	if src.Size > s.spec.MaxBytes {
+		return nil, src, fmt.Errorf("%w: %s is %d bytes, over the limit of %d", ErrImageTooLarge, key, src.Size, s.spec.MaxBytes)
+	}
+	data, err := io.ReadAll(io.LimitReader(body, s.spec.MaxBytes+1))
+	if err != nil {
+		return nil, src, s.translateError(err)
+	}
+	if int64(len(data)) > s.spec.MaxBytes {
+		return nil, src, fmt.Errorf("%w: %s is over the limit of %d bytes", ErrImageTooLarge, key, s.spec.MaxBytes)
+	}
+
+	// Check the dimensions before decoding; a small file can decode to an
+	// image that does not fit in memory
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, src, fmt.Errorf("%w: %s: %v", ErrUnsupportedImage, key, err)
+	}
+	if cfg.Width*cfg.Height > s.spec.MaxPixels {
+		return nil, src, fmt.Errorf("%w: %s is %dx%d, over the limit of %d pixels", ErrImageTooLarge, key, cfg.Width, cfg.Height, s.spec.MaxPixels)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, src, fmt.Errorf("%w: %s: %v", ErrUnsupportedImage, key, err)
+	}
+	return img, src, nil
+}
+
+// write scales img down for one rendition and stores it
+func (s *ThumbnailService) write(ctx context.Context, src domain.Object, img image.Image, r domain.Rendition) error {
+	// Scaling cannot be interrupted, so give up before it if time is already out
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	thumb := imaging.Thumbnail(img, r.Size)
+
+	var buf bytes.Buffer
+	contentType := "image/png"
+	var err error
+	if r.Format == "jpeg" {
+		contentType = "image/jpeg"
+		err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: s.spec.JPEGQuality})
+	} else {
+		err = png.Encode(&buf, thumb)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode thumbnail %s: %w", r.Key, err)
+	}
+
+	metadata := map[string]string{
+		domain.MetaSourceKey:  src.Key,
+		domain.MetaSourceETag: src.ETag,
+	}
+	if err := s.store.Put(ctx, r.Key, &buf, contentType, metadata); err != nil {
+		return s.translateError(err)
+	}
+	return nil
+}
+
+func (s *ThumbnailService) markDone(src domain.Object) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done[src.Key] = src.ETag
+}
+
+// translateError converts store errors to usecase errors
+func (s *ThumbnailService) translateError(err error) error {
+	// Domain and context errors pass through
+	if errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	// All other errors are infrastructure/availability issues
+	return fmt.Errorf("%w: %v", ErrUnavailable, err)
+}
@@ -0,0 +1,322 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/thumbnailer-demo/internal/domain"
+)
+
+// MockObjectStore implements ObjectStore in memory, with ETags computed like
+// S3 does for single-part uploads
+type MockObjectStore struct {
+	mu      sync.Mutex
+	objects map[string]storedObject
+	gets    int
+	puts    int
+	headErr error
+	// pageSize splits List into pages
+	pageSize int
+}
+
+type storedObject struct {
+	obj  domain.Object
+	data []byte
+}
+
+func NewMockObjectStore() *MockObjectStore {
+	return &MockObjectStore{objects: make(map[string]storedObject), pageSize: 2}
+}
+
+func (m *MockObjectStore) Head(ctx context.Context, key string) (domain.Object, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.headErr != nil {
+		return domain.Object{}, m.headErr
+	}
+	o, ok := m.objects[key]
+	if !ok {
+		return domain.Object{}, domain.ErrNotFound
+	}
+	return o.obj, nil
+}
+
+func (m *MockObjectStore) Get(ctx context.Context, key string) (io.ReadCloser, domain.Object, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gets++
+	o, ok := m.objects[key]
+	if !ok {
+		return nil, domain.Object{}, domain.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(o.data)), o.obj, nil
+}
+
+func (m *MockObjectStore) Put(ctx context.Context, key string, r io.Reader, contentType string, metadata map[string]string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.puts++
+	m.objects[key] = storedObject{
+		obj: domain.Object{
+			Key:         key,
+			Size:        int64(len(data)),
+			ETag:        fmt.Sprintf(`"%x"`, md5.Sum(data)),
+			ContentType: contentType,
+			Metadata:    metadata,
+		},
+		data: data,
+	}
+	return nil
+}
+
+func (m *MockObjectStore) List(ctx context.Context, prefix string, fn func([]domain.Object) error) error {
+	m.mu.Lock()
+	var objects []domain.Object
+	for key, o := range m.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, o.obj)
+		}
+	}
+	m.mu.Unlock()
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+
+	for len(objects) > 0 {
+		n := min(m.pageSize, len(objects))
+		if err := fn(objects[:n]); err != nil {
+			return err
+		}
+		objects = objects[n:]
+	}
+	return nil
+}
+
+func (m *MockObjectStore) upload(t *testing.T, key string, data []byte) {
+	t.Helper()
+	require.NoError(t, m.Put(context.Background(), key, bytes.NewReader(data), "", nil))
+}
+
+func (m *MockObjectStore) decode(t *testing.T, key string) (image.Config, string) {
+	t.Helper()
+	m.mu.Lock()
+	o, ok := m.objects[key]
+	m.mu.Unlock()
+	require.True(t, ok, "%s does not exist", key)
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(o.data))
+	require.NoError(t, err)
+	return cfg, format
+}
+
+func testSpec() domain.ThumbnailSpec {
+	return domain.ThumbnailSpec{
+		SourcePrefix: "avatars/",
+		TargetPrefix: "thumbnails/",
+		Sizes:        []int{16, 64},
+		MaxBytes:     1 << 20,
+		MaxPixels:    1_000_000,
+		JPEGQuality:  85,
+	}
+}
+
+func newTestService(t *testing.T) (*ThumbnailService, *MockObjectStore) {
+	t.Helper()
+	store := NewMockObjectStore()
+	svc, err := NewThumbnailService(store, testSpec())
+	require.NoError(t, err)
+	return svc, store
+}
+
+func testImage(w, h int, shade uint8) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.SetRGBA(x, y, color.RGBA{R: shade, G: uint8(x), B: uint8(y), A: 255})
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, w, h int, shade uint8) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, testImage(w, h, shade)))
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, testImage(w, h, 0), nil))
+	return buf.Bytes()
+}
+
+func TestProcessCreatesThumbnails(t *testing.T) {
+	svc, store := newTestService(t)
+	store.upload(t, "avatars/u1.png", encodePNG(t, 200, 100, 0))
+
+	outcome, err := svc.Process(context.Background(), "avatars/u1.png")
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeCreated, outcome)
+
+	cfg, format := store.decode(t, "thumbnails/64/u1.png")
+	assert.Equal(t, "png", format)
+	assert.Equal(t, 64, cfg.Width)
+	assert.Equal(t, 32, cfg.Height)
+	cfg, _ = store.decode(t, "thumbnails/16/u1.png")
+	assert.Equal(t, 16, cfg.Width)
+
+	src, _ := store.Head(context.Background(), "avatars/u1.png")
+	thumb, _ := store.Head(context.Background(), "thumbnails/64/u1.png")
+	assert.Equal(t, "image/png", thumb.ContentType)
+	assert.Equal(t, map[string]string{domain.MetaSourceKey: "avatars/u1.png", domain.MetaSourceETag: src.ETag}, thumb.Metadata)
+}
+
+func TestProcessKeepsJPEGs(t *testing.T) {
+	svc, store := newTestService(t)
+	store.upload(t, "avatars/u1.jpg", encodeJPEG(t, 100, 200))
+
+	_, err := svc.Process(context.Background(), "avatars/u1.jpg")
+	require.NoError(t, err)
+
+	cfg, format := store.decode(t, "thumbnails/64/u1.jpg")
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, 32, cfg.Width)
+	assert.Equal(t, 64, cfg.Height)
+}
+
+func TestProcessIsIdempotent(t *testing.T) {
+	svc, store := newTestService(t)
+	store.upload(t, "avatars/u1.png", encodePNG(t, 200, 100, 0))
+	ctx := context.Background()
+
+	_, err := svc.Process(ctx, "avatars/u1.png")
+	require.NoError(t, err)
+
+	// A duplicate notification downloads and writes nothing
+	outcome, err := svc.Process(ctx, "avatars/u1.png")
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeUpToDate, outcome)
+	assert.Equal(t, 1, store.gets)
+	assert.Equal(t, 3, store.puts, "the source and two thumbnails")
+
+	// A replaced source gets new thumbnails
+	store.upload(t, "avatars/u1.png", encodePNG(t, 200, 100, 255))
+	outcome, err = svc.Process(ctx, "avatars/u1.png")
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeCreated, outcome)
+	assert.Equal(t, 6, store.puts)
+}
+
+func TestProcessRedoesMissingThumbnailsOnly(t *testing.T) {
+	svc, store := newTestService(t)
+	store.upload(t, "avatars/u1.png", encodePNG(t, 200, 100, 0))
+	ctx := context.Background()
+	_, err := svc.Process(ctx, "avatars/u1.png")
+	require.NoError(t, err)
+
+	store.mu.Lock()
+	delete(store.objects, "thumbnails/16/u1.png")
+	store.mu.Unlock()
+
+	outcome, err := svc.Process(ctx, "avatars/u1.png")
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeCreated, outcome)
+	assert.Equal(t, 4, store.puts, "only the missing thumbnail is written again")
+	store.decode(t, "thumbnails/16/u1.png")
+}
+
+func TestProcessSkips(t *testing.T) {
+	svc, store := newTestService(t)
+	ctx := context.Background()
+
+	for _, key := range []string{"thumbnails/64/u1.png", "avatars/u1.webp", "avatars/deleted.png"} {
+		outcome, err := svc.Process(ctx, key)
+		require.NoError(t, err, key)
+		assert.Equal(t, OutcomeSkipped, outcome, key)
+	}
+	assert.Zero(t, store.gets)
+}
+
+func TestProcessRejectsBadSources(t *testing.T) {
+	svc, store := newTestService(t)
+	store.upload(t, "avatars/text.png", []byte("not an image"))
+	store.upload(t, "avatars/huge.png", encodePNG(t, 2000, 1000, 0))
+	ctx := context.Background()
+
+	_, err := svc.Process(ctx, "avatars/text.png")
+	assert.ErrorIs(t, err, ErrUnsupportedImage)
+	assert.True(t, IsPermanent(err))
+
+	_, err = svc.Process(ctx, "avatars/huge.png")
+	assert.ErrorIs(t, err, ErrImageTooLarge)
+
+	// Rejected sources are not pending until they change
+	pending, err := svc.Pending(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestProcessStoreFailure(t *testing.T) {
+	svc, store := newTestService(t)
+	store.headErr = errors.New("connection refused")
+
+	_, err := svc.Process(context.Background(), "avatars/u1.png")
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.False(t, IsPermanent(err))
+}
+
+func TestPending(t *testing.T) {
+	svc, store := newTestService(t)
+	ctx := context.Background()
+	for _, key := range []string{"avatars/a.png", "avatars/b.png", "avatars/c.png", "avatars/notes.txt"} {
+		store.upload(t, key, encodePNG(t, 20, 20, 0))
+	}
+
+	pending, err := svc.Pending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"avatars/a.png", "avatars/b.png", "avatars/c.png"}, keys(pending))
+
+	_, err = svc.Process(ctx, "avatars/b.png")
+	require.NoError(t, err)
+	pending, err = svc.Pending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"avatars/a.png", "avatars/c.png"}, keys(pending))
+
+	store.upload(t, "avatars/b.png", encodePNG(t, 30, 30, 0))
+	pending, err = svc.Pending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"avatars/a.png", "avatars/b.png", "avatars/c.png"}, keys(pending), "changed since processed")
+}
+
+func TestNewThumbnailServiceValidatesSpec(t *testing.T) {
+	spec := testSpec()
+	spec.TargetPrefix = "avatars/thumbnails/"
+	_, err := NewThumbnailService(NewMockObjectStore(), spec)
+	assert.ErrorIs(t, err, domain.ErrInvalidSpec)
+}
+
+func keys(objects []domain.Object) []string {
+	out := make([]string, len(objects))
+	for i, o := range objects {
+		out[i] = o.Key
+	}
+	return out
+}
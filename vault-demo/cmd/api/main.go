@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/dbx"
+	"github.com/gostratum/examples/redact"
+	httpAdapter "github.com/gostratum/examples/vault-demo/internal/adapter/http"
+	repoAdapter "github.com/gostratum/examples/vault-demo/internal/adapter/repo"
+	"github.com/gostratum/examples/vault-demo/internal/secrets"
+	"github.com/gostratum/examples/vault-demo/internal/usecase"
+	"github.com/gostratum/httpx"
+)
+
+func main() {
+	// Read the database credentials and API keys from Vault first: they are
+	// set as config overrides, which dbx and the API key check bind below
+	s, err := secrets.Load(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to read secrets from Vault: %v", err)
+	}
+
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// Renews the Vault leases and revokes them at shutdown; listed before
+		// dbx so the credentials are revoked after its connections are closed
+		secrets.Module(s),
+
+		// Include dbx module; its DSN carries the credentials from Vault
+		dbx.Module(),
+
+		// Include httpx module
+		httpx.Module(),
+
+		// Provide dependencies
+		fx.Provide(
+			// GORM repositories
+			repoAdapter.NewCustomerRepo,
+
+			// Usecase services
+			usecase.NewCustomerService,
+
+			// HTTP handlers and the API key check
+			httpAdapter.NewCustomerHandler,
+			httpAdapter.NewAPIKeyAuth,
+		),
+
+		// Invoke setup functions
+		fx.Invoke(
+			httpAdapter.RegisterRoutes,
+		),
+	)
+
+	app.Run()
+}
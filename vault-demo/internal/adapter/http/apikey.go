@@ -0,0 +1,90 @@
+package http
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/httpx/responsex"
+)
+
+// APIKeyHeader carries the caller's API key
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyConfig holds the API keys callers authenticate with
+type APIKeyConfig struct {
+	// APIKeys is a comma-separated list of accepted keys, so a new key can be
+	// added before the old one is removed. This service reads it from Vault at
+	// startup; the config files leave it empty.
+	APIKeys string `mapstructure:"api_keys"`
+}
+
+// Prefix implements configx.Configurable
+func (APIKeyConfig) Prefix() string {
+	return "auth"
+}
+
+// APIKeyAuth checks the API key of every request
+type APIKeyAuth struct {
+	// hashes are the SHA-256 hashes of the accepted keys. Comparing hashes takes
+	// the same time whatever the length of the key sent.
+	hashes [][sha256.Size]byte
+}
+
+// NewAPIKeyAuth creates the API key check from the auth config section. It
+// fails when there are no keys, since every request would be rejected.
+func NewAPIKeyAuth(loader configx.Loader) (*APIKeyAuth, error) {
+	var cfg APIKeyConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load auth config: %w", err)
+	}
+	return newAPIKeyAuth(cfg.APIKeys)
+}
+
+func newAPIKeyAuth(keys string) (*APIKeyAuth, error) {
+	a := &APIKeyAuth{}
+	for _, key := range strings.Split(keys, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			a.hashes = append(a.hashes, sha256.Sum256([]byte(key)))
+		}
+	}
+	if len(a.hashes) == 0 {
+		return nil, errors.New("auth.api_keys is empty")
+	}
+	return a, nil
+}
+
+// Middleware rejects requests without a valid X-API-Key
+func (a *APIKeyAuth) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(APIKeyHeader)
+		if key == "" {
+			responsex.Error(c, http.StatusUnauthorized, "INVALID_API_KEY", "missing "+APIKeyHeader+" header", nil)
+			c.Abort()
+			return
+		}
+		if !a.valid(key) {
+			responsex.Error(c, http.StatusUnauthorized, "INVALID_API_KEY", "invalid API key", nil)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// valid reports whether key is one of the accepted keys. It compares against
+// every key, so the time taken does not tell which one matched.
+func (a *APIKeyAuth) valid(key string) bool {
+	sum := sha256.Sum256([]byte(key))
+	match := 0
+	for _, h := range a.hashes {
+		match |= subtle.ConstantTimeCompare(sum[:], h[:])
+	}
+	return match == 1
+}
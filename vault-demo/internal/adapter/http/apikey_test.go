@@ -0,0 +1,47 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gostratum/httpx/responsex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		apiKey     string
+		wantStatus int
+	}{
+		{name: "first key", apiKey: testAPIKey, wantStatus: http.StatusOK},
+		{name: "second key", apiKey: "key-2", wantStatus: http.StatusOK},
+		{name: "missing", apiKey: "", wantStatus: http.StatusUnauthorized},
+		{name: "wrong", apiKey: "key-3", wantStatus: http.StatusUnauthorized},
+		{name: "prefix of a key", apiKey: "key", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(setupRouter(t, &fakeCustomers{}), http.MethodGet, "/customers", tt.apiKey, "")
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.wantStatus == http.StatusUnauthorized {
+				var env responsex.Envelope[any]
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+				require.NotNil(t, env.Error)
+				assert.Equal(t, "INVALID_API_KEY", env.Error.Code)
+			}
+		})
+	}
+}
+
+func TestNewAPIKeyAuthNeedsKeys(t *testing.T) {
+	_, err := newAPIKeyAuth("")
+	assert.ErrorContains(t, err, "auth.api_keys is empty")
+
+	_, err = newAPIKeyAuth(" , ")
+	assert.Error(t, err)
+}
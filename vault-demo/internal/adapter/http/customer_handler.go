@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/vault-demo/internal/domain"
+	"github.com/gostratum/examples/vault-demo/internal/usecase"
+)
+
+// Customers manages customers; implemented by usecase.CustomerService
+type Customers interface {
+	CreateCustomer(ctx context.Context, name, email string) (*domain.Customer, error)
+	GetCustomer(ctx context.Context, id string) (*domain.Customer, error)
+	ListCustomers(ctx context.Context) ([]*domain.Customer, error)
+}
+
+// CustomerHandler handles customer-related HTTP requests
+type CustomerHandler struct {
+	service Customers
+	log     logx.Logger
+}
+
+// NewCustomerHandler creates a new customer handler
+func NewCustomerHandler(service *usecase.CustomerService, log logx.Logger) *CustomerHandler {
+	return newCustomerHandler(service, log)
+}
+
+func newCustomerHandler(service Customers, log logx.Logger) *CustomerHandler {
+	return &CustomerHandler{service: service, log: log}
+}
+
+// CreateCustomer handles POST /customers
+func (h *CustomerHandler) CreateCustomer(c *gin.Context) {
+	var req CreateCustomerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid request payload", nil)
+		return
+	}
+
+	customer, err := h.service.CreateCustomer(c.Request.Context(), req.Name, req.Email)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.Created(c, "/customers/"+customer.ID, FromDomainCustomer(customer))
+}
+
+// GetCustomer handles GET /customers/:id
+func (h *CustomerHandler) GetCustomer(c *gin.Context) {
+	customer, err := h.service.GetCustomer(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responsex.OK(c, FromDomainCustomer(customer), nil)
+}
+
+// ListCustomers handles GET /customers
+func (h *CustomerHandler) ListCustomers(c *gin.Context) {
+	customers, err := h.service.ListCustomers(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	resp := make([]*CustomerResponse, len(customers))
+	for i, customer := range customers {
+		resp[i] = FromDomainCustomer(customer)
+	}
+	responsex.OK(c, resp, nil)
+}
+
+// handleError maps usecase errors to HTTP responses
+func (h *CustomerHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrNotFound):
+		responsex.Error(c, http.StatusNotFound, "NOT_FOUND", "customer not found", nil)
+	case errors.Is(err, usecase.ErrConflict):
+		responsex.Error(c, http.StatusConflict, "CUSTOMER_EXISTS", err.Error(), nil)
+	case errors.Is(err, usecase.ErrInvalid):
+		responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
+	case errors.Is(err, usecase.ErrUnavailable):
+		h.log.Error("database unavailable", logx.Err(err))
+		c.Header("Retry-After", "2")
+		responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "service temporarily unavailable", nil)
+	default:
+		h.log.Error("unexpected error", logx.Err(err))
+		responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+	}
+}
@@ -0,0 +1,143 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/vault-demo/internal/domain"
+	"github.com/gostratum/examples/vault-demo/internal/usecase"
+)
+
+const testAPIKey = "key-1"
+
+// fakeCustomers keeps customers in creation order
+type fakeCustomers struct {
+	customers []*domain.Customer
+	err       error
+}
+
+func (f *fakeCustomers) CreateCustomer(ctx context.Context, name, email string) (*domain.Customer, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	c := &domain.Customer{ID: fmt.Sprintf("c-%d", len(f.customers)+1), Name: name, Email: email, CreatedAt: time.Now()}
+	f.customers = append(f.customers, c)
+	return c, nil
+}
+
+func (f *fakeCustomers) GetCustomer(ctx context.Context, id string) (*domain.Customer, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	for _, c := range f.customers {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return nil, usecase.ErrNotFound
+}
+
+func (f *fakeCustomers) ListCustomers(ctx context.Context) ([]*domain.Customer, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.customers, nil
+}
+
+func setupRouter(t *testing.T, customers Customers) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	handler := newCustomerHandler(customers, logx.NewNoopLogger())
+	apiKeys, err := newAPIKeyAuth(testAPIKey + ", key-2")
+	require.NoError(t, err)
+
+	e := gin.New()
+	g := e.Group("/customers", apiKeys.Middleware())
+	g.POST("", handler.CreateCustomer)
+	g.GET("", handler.ListCustomers)
+	g.GET("/:id", handler.GetCustomer)
+	return e
+}
+
+func do(e *gin.Engine, method, path, apiKey, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set(APIKeyHeader, apiKey)
+	}
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w
+}
+
+func TestCreateAndGetCustomer(t *testing.T) {
+	e := setupRouter(t, &fakeCustomers{})
+
+	w := do(e, http.MethodPost, "/customers", testAPIKey, `{"name":"Jane Doe","email":"jane@example.com"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "/customers/c-1", w.Header().Get("Location"))
+
+	var created responsex.Envelope[CustomerResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "jane@example.com", created.Data.Email)
+
+	w = do(e, http.MethodGet, "/customers/c-1", testAPIKey, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = do(e, http.MethodGet, "/customers/c-2", testAPIKey, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = do(e, http.MethodGet, "/customers", "key-2", "")
+	require.Equal(t, http.StatusOK, w.Code, "every configured key is accepted")
+	var list responsex.Envelope[[]CustomerResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Len(t, list.Data, 1)
+}
+
+func TestCreateCustomerInvalidPayload(t *testing.T) {
+	e := setupRouter(t, &fakeCustomers{})
+
+	w := do(e, http.MethodPost, "/customers", testAPIKey, `{"name":"Jane Doe"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_REQUEST")
+}
+
+func TestCustomerHandlerErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "conflict", err: fmt.Errorf("%w: a customer with email \"jane@example.com\" already exists", usecase.ErrConflict), wantStatus: http.StatusConflict, wantCode: "CUSTOMER_EXISTS"},
+		{name: "invalid", err: usecase.ErrInvalid, wantStatus: http.StatusBadRequest, wantCode: "INVALID_INPUT"},
+		{name: "unavailable", err: usecase.ErrUnavailable, wantStatus: http.StatusServiceUnavailable, wantCode: "SERVICE_UNAVAILABLE"},
+		{name: "unexpected", err: errors.New("boom"), wantStatus: http.StatusInternalServerError, wantCode: "INTERNAL_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := setupRouter(t, &fakeCustomers{err: tt.err})
+			w := do(e, http.MethodPost, "/customers", testAPIKey, `{"name":"Jane Doe","email":"jane@example.com"}`)
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			var env responsex.Envelope[CustomerResponse]
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+			require.NotNil(t, env.Error)
+			assert.Equal(t, tt.wantCode, env.Error.Code)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gostratum/examples/vault-demo/internal/domain"
+)
+
+// CreateCustomerRequest represents the request payload for creating a customer
+type CreateCustomerRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Email string `json:"email" binding:"required"`
+}
+
+// CustomerResponse is the HTTP DTO for customer data
+type CustomerResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FromDomainCustomer converts a domain.Customer to CustomerResponse DTO
+func FromDomainCustomer(customer *domain.Customer) *CustomerResponse {
+	if customer == nil {
+		return nil
+	}
+	return &CustomerResponse{
+		ID:        customer.ID,
+		Name:      customer.Name,
+		Email:     customer.Email,
+		CreatedAt: customer.CreatedAt,
+	}
+}
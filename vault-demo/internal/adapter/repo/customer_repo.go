@@ -0,0 +1,66 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/gostratum/examples/vault-demo/internal/domain"
+	"github.com/gostratum/examples/vault-demo/internal/usecase"
+)
+
+// CustomerRepo implements the CustomerRepository interface using GORM. Its
+// connection logs in with the database credentials dbx got from Vault.
+type CustomerRepo struct {
+	db *gorm.DB
+}
+
+// NewCustomerRepo creates a new GORM-based customer repository
+func NewCustomerRepo(db *gorm.DB) usecase.CustomerRepository {
+	return &CustomerRepo{db: db}
+}
+
+// Create implements usecase.CustomerRepository
+func (r *CustomerRepo) Create(ctx context.Context, customer *domain.Customer) error {
+	var entity CustomerEntity
+	entity.FromDomain(customer)
+
+	// Insert unless the email is taken; the unique index decides, so two
+	// concurrent requests cannot both create the same email
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&entity)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrConflict
+	}
+	return nil
+}
+
+// FindByID implements usecase.CustomerRepository
+func (r *CustomerRepo) FindByID(ctx context.Context, id string) (*domain.Customer, error) {
+	var entity CustomerEntity
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&entity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return entity.ToDomain(), nil
+}
+
+// List implements usecase.CustomerRepository
+func (r *CustomerRepo) List(ctx context.Context) ([]*domain.Customer, error) {
+	var entities []CustomerEntity
+	if err := r.db.WithContext(ctx).Order("created_at DESC").Find(&entities).Error; err != nil {
+		return nil, err
+	}
+
+	customers := make([]*domain.Customer, len(entities))
+	for i := range entities {
+		customers[i] = entities[i].ToDomain()
+	}
+	return customers, nil
+}
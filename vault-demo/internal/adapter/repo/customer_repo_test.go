@@ -0,0 +1,56 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/gostratum/examples/testkit/sqlitedb"
+	"github.com/gostratum/examples/vault-demo/internal/domain"
+	"github.com/gostratum/examples/vault-demo/migrations"
+)
+
+// setupTestDB creates an in-memory SQLite database with the service's schema
+func setupTestDB(t *testing.T) *gorm.DB {
+	return sqlitedb.Open(t, migrations.FS)
+}
+
+func TestCustomerRepoCreateAndFind(t *testing.T) {
+	repo := NewCustomerRepo(setupTestDB(t))
+	ctx := context.Background()
+
+	customer := domain.NewCustomer("Jane Doe", "jane@example.com")
+	require.NoError(t, repo.Create(ctx, customer))
+
+	got, err := repo.FindByID(ctx, customer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, customer.Name, got.Name)
+	assert.Equal(t, customer.Email, got.Email)
+	assert.WithinDuration(t, customer.CreatedAt, got.CreatedAt, time.Second)
+
+	_, err = repo.FindByID(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	err = repo.Create(ctx, domain.NewCustomer("Jane", "jane@example.com"))
+	assert.ErrorIs(t, err, domain.ErrConflict)
+}
+
+func TestCustomerRepoList(t *testing.T) {
+	repo := NewCustomerRepo(setupTestDB(t))
+	ctx := context.Background()
+
+	older := domain.NewCustomer("Older", "older@example.com")
+	older.CreatedAt = time.Now().Add(-time.Hour).UTC()
+	require.NoError(t, repo.Create(ctx, older))
+	require.NoError(t, repo.Create(ctx, domain.NewCustomer("Newer", "newer@example.com")))
+
+	customers, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, customers, 2)
+	assert.Equal(t, "Newer", customers[0].Name)
+	assert.Equal(t, "Older", customers[1].Name)
+}
@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/gostratum/examples/vault-demo/internal/domain"
+)
+
+// CustomerEntity represents the GORM model for customers table
+type CustomerEntity struct {
+	ID        string    `gorm:"primaryKey"`
+	Name      string    `gorm:"not null"`
+	Email     string    `gorm:"not null;uniqueIndex"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for CustomerEntity
+func (CustomerEntity) TableName() string {
+	return "customers"
+}
+
+// ToDomain converts CustomerEntity to domain.Customer
+func (c *CustomerEntity) ToDomain() *domain.Customer {
+	return &domain.Customer{
+		ID:        c.ID,
+		Name:      c.Name,
+		Email:     c.Email,
+		CreatedAt: c.CreatedAt,
+	}
+}
+
+// FromDomain creates CustomerEntity from domain.Customer
+func (c *CustomerEntity) FromDomain(customer *domain.Customer) {
+	c.ID = customer.ID
+	c.Name = customer.Name
+	c.Email = customer.Email
+	c.CreatedAt = customer.CreatedAt
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// maxNameLength limits customer names, in characters
+const maxNameLength = 100
+
+// Customer is a customer account. Emails are unique.
+type Customer struct {
+	ID        string
+	Name      string
+	Email     string
+	CreatedAt time.Time
+}
+
+// NewCustomer creates a customer with a new ID. The email is lowercased, so
+// uniqueness does not depend on case.
+func NewCustomer(name, email string) *Customer {
+	return &Customer{
+		ID:        uuid.NewString(),
+		Name:      strings.TrimSpace(name),
+		Email:     strings.ToLower(strings.TrimSpace(email)),
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+// Validate checks the customer against the business rules
+func (c *Customer) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(c.Name) > maxNameLength {
+		return fmt.Errorf("%w: name must be at most %d characters", ErrInvalidInput, maxNameLength)
+	}
+	// A bare address only: "Jane <jane@example.com>" parses too, but is not an email
+	if addr, err := mail.ParseAddress(c.Email); err != nil || addr.Address != c.Email {
+		return fmt.Errorf("%w: email is not a valid address", ErrInvalidInput)
+	}
+	return nil
+}
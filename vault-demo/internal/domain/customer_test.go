@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCustomer(t *testing.T) {
+	c := NewCustomer("  Jane Doe  ", " Jane@Example.com ")
+	assert.NotEmpty(t, c.ID)
+	assert.Equal(t, "Jane Doe", c.Name)
+	assert.Equal(t, "jane@example.com", c.Email)
+	assert.False(t, c.CreatedAt.IsZero())
+
+	assert.NotEqual(t, c.ID, NewCustomer("Jane Doe", "jane@example.com").ID)
+}
+
+func TestCustomerValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		customer string
+		email    string
+		wantErr  bool
+	}{
+		{name: "valid", customer: "Jane Doe", email: "jane@example.com"},
+		{name: "empty name", customer: "", email: "jane@example.com", wantErr: true},
+		{name: "longest name", customer: strings.Repeat("é", maxNameLength), email: "jane@example.com"},
+		{name: "name too long", customer: strings.Repeat("a", maxNameLength+1), email: "jane@example.com", wantErr: true},
+		{name: "empty email", customer: "Jane Doe", email: "", wantErr: true},
+		{name: "no domain", customer: "Jane Doe", email: "jane", wantErr: true},
+		{name: "display name", customer: "Jane Doe", email: "Jane <jane@example.com>", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewCustomer(tt.customer, tt.email).Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidInput)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+// Package secrets is a small fx module that reads secrets from HashiCorp Vault
+// before the application starts, hands them to the other modules as config
+// overrides, and renews their leases while the application runs
+package secrets
+
+import "time"
+
+// Config says where Vault is, how to log in and which secrets to read
+type Config struct {
+	// Address of the Vault server
+	Address string `mapstructure:"address" default:"http://127.0.0.1:8200"`
+	// Timeout bounds every request to Vault
+	Timeout time.Duration `mapstructure:"timeout" default:"10s"`
+	Auth    AuthConfig    `mapstructure:"auth"`
+	// Database reads dynamic credentials for a dbx connection; skipped when
+	// its path is empty
+	Database DatabaseConfig `mapstructure:"database"`
+	// KV reads fields of KV version 2 secrets into config keys
+	KV []KVConfig `mapstructure:"kv"`
+	// RevokeOnStop revokes the database credentials and the Vault token at
+	// shutdown, so they do not outlive the process
+	RevokeOnStop bool `mapstructure:"revoke_on_stop" default:"true"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "vault"
+}
+
+// AuthConfig says how the service logs in to Vault
+type AuthConfig struct {
+	// Method is approle, or token to use VAULT_TOKEN as the vault CLI does
+	Method string `mapstructure:"method"`
+	// Mount is where the AppRole auth method is enabled; approle when empty
+	Mount string `mapstructure:"mount"`
+	// RoleID identifies the AppRole. It is not a secret; RoleIDFile reads it
+	// from a file instead.
+	RoleID     string `mapstructure:"role_id"`
+	RoleIDFile string `mapstructure:"role_id_file"`
+	// SecretIDFile holds the AppRole secret ID, placed there by the deployment
+	SecretIDFile string `mapstructure:"secret_id_file"`
+	// WrappedSecretID means the file holds a response-wrapping token that
+	// unwraps to the secret ID
+	WrappedSecretID bool `mapstructure:"wrapped_secret_id"`
+}
+
+// DatabaseConfig maps dynamic database credentials onto a dbx connection
+type DatabaseConfig struct {
+	// Path of the credentials endpoint, such as database/creds/app
+	Path string `mapstructure:"path"`
+	// Connection is the name of the connection in db.databases
+	Connection string `mapstructure:"connection"`
+	// DSN is the connection URL without credentials. The username and
+	// password from Vault are added to it.
+	DSN string `mapstructure:"dsn"`
+}
+
+// KVConfig maps a field of a KV version 2 secret onto a config key
+type KVConfig struct {
+	// Mount is where the KV engine is enabled; secret when empty
+	Mount string `mapstructure:"mount"`
+	Path  string `mapstructure:"path"`
+	Field string `mapstructure:"field"`
+	// Key is the config key the value is set as, such as auth.api_keys
+	Key string `mapstructure:"key"`
+}
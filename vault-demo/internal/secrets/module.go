@@ -0,0 +1,15 @@
+package secrets
+
+import "go.uber.org/fx"
+
+// Module renews the leases of the secrets read by Load while the application
+// runs, and revokes them at stop. List it before dbx.Module: modules stop in
+// reverse order, so the database credentials are revoked after dbx has closed
+// its connections.
+func Module(s *Secrets) fx.Option {
+	return fx.Module("secrets",
+		fx.Supply(s),
+		fx.Provide(NewRenewer),
+		fx.Invoke(Register),
+	)
+}
@@ -0,0 +1,181 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gostratum/core/logx"
+	vault "github.com/hashicorp/vault/api"
+	"go.uber.org/fx"
+)
+
+// Params are the dependencies of NewRenewer
+type Params struct {
+	fx.In
+
+	Secrets    *Secrets
+	Shutdowner fx.Shutdowner
+	Log        logx.Logger
+}
+
+// Renewer keeps the Vault token and the database lease alive while the
+// application runs. Once a lease cannot be renewed any more, its credentials
+// are about to stop working, so the renewer shuts the application down to be
+// started again with new ones.
+type Renewer struct {
+	secrets  *Secrets
+	shutdown func() error
+	log      logx.Logger
+
+	watchers []*vault.LifetimeWatcher
+	// stopping is closed at stop; a watcher ending after that is not a lost lease
+	stopping     chan struct{}
+	shutdownOnce sync.Once
+	wg           sync.WaitGroup
+}
+
+// lease is a secret whose lifetime the renewer watches
+type lease struct {
+	name   string
+	secret *vault.Secret
+}
+
+// NewRenewer creates the renewer for the secrets read by Load
+func NewRenewer(p Params) *Renewer {
+	return newRenewer(p.Secrets, func() error {
+		return p.Shutdowner.Shutdown(fx.ExitCode(1))
+	}, p.Log)
+}
+
+func newRenewer(s *Secrets, shutdown func() error, log logx.Logger) *Renewer {
+	return &Renewer{
+		secrets:  s,
+		shutdown: shutdown,
+		log:      log,
+		stopping: make(chan struct{}),
+	}
+}
+
+// Register ties lease renewal to the application lifecycle.
+// This function is designed to be used with fx.Invoke.
+func Register(lc fx.Lifecycle, r *Renewer) {
+	lc.Append(fx.Hook{
+		OnStart: r.start,
+		OnStop:  r.stop,
+	})
+}
+
+// start watches every lease in the background
+func (r *Renewer) start(ctx context.Context) error {
+	r.log.Info("secrets read from vault", logx.String("keys", strings.Join(r.secrets.keys, ",")))
+
+	for _, l := range r.secrets.leases() {
+		w, err := r.secrets.client.NewLifetimeWatcher(&vault.LifetimeWatcherInput{Secret: l.secret})
+		if err != nil {
+			return fmt.Errorf("failed to watch the %s lease: %w", l.name, err)
+		}
+		r.watchers = append(r.watchers, w)
+
+		r.wg.Add(2)
+		go func() {
+			defer r.wg.Done()
+			w.Start()
+		}()
+		go func() {
+			defer r.wg.Done()
+			r.watch(l.name, w)
+		}()
+	}
+	return nil
+}
+
+// watch logs the renewals of one lease until it ends
+func (r *Renewer) watch(name string, w *vault.LifetimeWatcher) {
+	for {
+		select {
+		case out := <-w.RenewCh():
+			r.log.Debug("vault lease renewed",
+				logx.String("lease", name),
+				logx.Int("ttl_seconds", ttl(out.Secret)),
+			)
+		case err := <-w.DoneCh():
+			select {
+			case <-r.stopping:
+				return
+			default:
+			}
+
+			// The watcher returns nil once the lease has reached its maximum TTL,
+			// and an error once renewals kept failing until the lease expired
+			fields := []logx.Field{logx.String("lease", name)}
+			if err != nil {
+				fields = append(fields, logx.Err(err))
+			}
+			r.log.Error("vault lease cannot be renewed; shutting down to start again with new credentials", fields...)
+			r.shutdownOnce.Do(func() {
+				if err := r.shutdown(); err != nil {
+					r.log.Error("failed to shut down", logx.Err(err))
+				}
+			})
+			return
+		}
+	}
+}
+
+// stop ends the watchers and revokes the leases
+func (r *Renewer) stop(ctx context.Context) error {
+	close(r.stopping)
+	for _, w := range r.watchers {
+		w.Stop()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return errors.New("vault lease watchers still running at shutdown")
+	}
+
+	if !r.secrets.cfg.RevokeOnStop {
+		return nil
+	}
+	if err := r.secrets.revoke(ctx); err != nil {
+		// The leases still expire at the end of their TTL, so this does not
+		// fail the shutdown
+		r.log.Warn("failed to revoke vault leases", logx.Err(err))
+		return nil
+	}
+	r.log.Info("vault leases revoked")
+	return nil
+}
+
+// leases returns the leases to watch: the token of an AppRole login and the
+// database credentials
+func (s *Secrets) leases() []lease {
+	var leases []lease
+	if s.token != nil && s.token.Auth != nil {
+		leases = append(leases, lease{name: "token", secret: s.token})
+	}
+	if s.database != nil && s.database.LeaseID != "" {
+		leases = append(leases, lease{name: "database", secret: s.database})
+	}
+	return leases
+}
+
+// ttl returns the lease duration of a renewal, in seconds
+func ttl(s *vault.Secret) int {
+	if s == nil {
+		return 0
+	}
+	if s.Auth != nil {
+		return s.Auth.LeaseDuration
+	}
+	return s.LeaseDuration
+}
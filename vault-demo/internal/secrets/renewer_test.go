@@ -0,0 +1,86 @@
+package secrets
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startRenewer loads the test secrets from vault and starts a renewer for
+// them; the returned counter counts shutdown requests
+func startRenewer(t *testing.T, vault *fakeVault, address string) (*Renewer, *atomic.Int32) {
+	t.Helper()
+	s, _, err := loadWith(testConfig(t, address))
+	require.NoError(t, err)
+
+	var shutdowns atomic.Int32
+	r := newRenewer(s, func() error {
+		shutdowns.Add(1)
+		return nil
+	}, logx.NewNoopLogger())
+	require.NoError(t, r.start(context.Background()))
+	return r, &shutdowns
+}
+
+func TestRenewerRenewsAndRevokes(t *testing.T) {
+	vault, srv := newFakeVault(t)
+	r, shutdowns := startRenewer(t, vault, srv.URL)
+
+	assert.Eventually(t, func() bool {
+		return vault.called("PUT /v1/sys/leases/renew") && vault.called("PUT /v1/auth/token/renew-self")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, r.stop(context.Background()))
+	assert.Zero(t, shutdowns.Load(), "stopping is not a lost lease")
+	assert.True(t, vault.called("PUT /v1/auth/token/revoke-self"))
+	assert.False(t, vault.called("PUT /v1/sys/leases/revoke"), "revoking the token revokes its leases")
+}
+
+func TestRenewerRevokesLeaseWithTokenAuth(t *testing.T) {
+	vault, srv := newFakeVault(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.Auth = AuthConfig{Method: "token"}
+	t.Setenv("VAULT_TOKEN", testToken)
+	s, _, err := loadWith(cfg)
+	require.NoError(t, err)
+
+	r := newRenewer(s, func() error { return nil }, logx.NewNoopLogger())
+	require.NoError(t, r.start(context.Background()))
+	require.NoError(t, r.stop(context.Background()))
+
+	assert.True(t, vault.called("PUT /v1/sys/leases/revoke"))
+	assert.False(t, vault.called("PUT /v1/auth/token/revoke-self"), "the token is not the service's to revoke")
+}
+
+func TestRenewerShutsDownWhenLeaseEnds(t *testing.T) {
+	vault, srv := newFakeVault(t)
+	// Renewals no longer extend the leases: both have reached their maximum TTL
+	vault.renewTTL = 0
+	r, shutdowns := startRenewer(t, vault, srv.URL)
+
+	assert.Eventually(t, func() bool {
+		return shutdowns.Load() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, r.stop(context.Background()))
+	assert.Equal(t, int32(1), shutdowns.Load(), "two ended leases shut down once")
+}
+
+func TestRenewerKeepsLeases(t *testing.T) {
+	vault, srv := newFakeVault(t)
+	s, _, err := loadWith(testConfig(t, srv.URL))
+	require.NoError(t, err)
+	s.cfg.RevokeOnStop = false
+
+	r := newRenewer(s, func() error { return nil }, logx.NewNoopLogger())
+	require.NoError(t, r.start(context.Background()))
+	require.NoError(t, r.stop(context.Background()))
+
+	assert.False(t, vault.called("PUT /v1/sys/leases/revoke"))
+	assert.False(t, vault.called("PUT /v1/auth/token/revoke-self"))
+}
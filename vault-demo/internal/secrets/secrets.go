@@ -0,0 +1,236 @@
+package secrets
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/gostratum/core/configx"
+	vault "github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/api/auth/approle"
+)
+
+// envPrefix is the prefix of the environment variables configx reads
+// overrides from
+const envPrefix = "STRATUM_"
+
+// binder is the part of configx.Loader that Load needs
+type binder interface {
+	Bind(v any) error
+}
+
+// Secrets holds what was read from Vault and the client that renews its
+// leases. Load creates it before the application; Module takes it from there.
+type Secrets struct {
+	cfg    Config
+	client *vault.Client
+
+	// token is the login of AppRole auth. It is nil with token auth, whose
+	// token belongs to whoever issued it.
+	token *vault.Secret
+	// database holds the lease of the dynamic database credentials
+	database *vault.Secret
+	// keys are the config keys set from Vault, in the order they were read
+	keys []string
+}
+
+// Load logs in to Vault, reads the configured secrets and sets each as the
+// environment override of its config key: STRATUM_ and the key in upper case,
+// with dots as underscores. Call it before core.New, so every module binds its
+// config with the secrets in place.
+func Load(ctx context.Context) (*Secrets, error) {
+	loader := configx.New(configx.WithConfigPaths(configPaths()...))
+	return load(ctx, loader, os.Setenv)
+}
+
+func load(ctx context.Context, loader binder, setenv func(key, value string) error) (_ *Secrets, err error) {
+	var cfg Config
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load vault config: %w", err)
+	}
+
+	vcfg := vault.DefaultConfig()
+	if vcfg.Error != nil {
+		return nil, fmt.Errorf("failed to configure vault client: %w", vcfg.Error)
+	}
+	vcfg.Address = cfg.Address
+	vcfg.Timeout = cfg.Timeout
+	client, err := vault.NewClient(vcfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vault client: %w", err)
+	}
+
+	s := &Secrets{cfg: cfg, client: client}
+	if err := s.login(ctx); err != nil {
+		return nil, err
+	}
+	// Do not leave a token or credentials behind when a later read fails
+	defer func() {
+		if err != nil {
+			_ = s.revoke(context.Background())
+		}
+	}()
+
+	values := map[string]string{}
+	if cfg.Database.Path != "" {
+		key, dsn, err := s.readDatabase(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.keys = append(s.keys, key)
+		values[key] = dsn
+	}
+	for _, kv := range cfg.KV {
+		value, err := s.readKV(ctx, kv)
+		if err != nil {
+			return nil, err
+		}
+		s.keys = append(s.keys, kv.Key)
+		values[kv.Key] = value
+	}
+
+	for _, key := range s.keys {
+		if err := setenv(envName(key), values[key]); err != nil {
+			return nil, fmt.Errorf("failed to set %s: %w", key, err)
+		}
+	}
+	return s, nil
+}
+
+// login authenticates the client with the configured method
+func (s *Secrets) login(ctx context.Context) error {
+	auth := s.cfg.Auth
+	switch auth.Method {
+	case "token":
+		// vault.NewClient has taken the token from VAULT_TOKEN
+		if s.client.Token() == "" {
+			return errors.New("vault.auth.method is token, but VAULT_TOKEN is not set")
+		}
+		return nil
+	case "approle":
+	default:
+		return fmt.Errorf("vault.auth.method must be approle or token, got %q", auth.Method)
+	}
+
+	roleID := auth.RoleID
+	if auth.RoleIDFile != "" {
+		b, err := os.ReadFile(auth.RoleIDFile)
+		if err != nil {
+			return fmt.Errorf("failed to read vault.auth.role_id_file: %w", err)
+		}
+		roleID = strings.TrimSpace(string(b))
+	}
+
+	opts := []approle.LoginOption{approle.WithMountPath(cmp.Or(auth.Mount, "approle"))}
+	if auth.WrappedSecretID {
+		opts = append(opts, approle.WithWrappingToken())
+	}
+	method, err := approle.NewAppRoleAuth(roleID, &approle.SecretID{FromFile: auth.SecretIDFile}, opts...)
+	if err != nil {
+		return fmt.Errorf("invalid vault.auth config: %w", err)
+	}
+
+	token, err := s.client.Auth().Login(ctx, method)
+	if err != nil {
+		return fmt.Errorf("failed to log in to vault at %s: %w", s.cfg.Address, err)
+	}
+	s.token = token
+	return nil
+}
+
+// readDatabase reads dynamic credentials and returns the config key of the
+// connection's DSN and the DSN with the credentials in it
+func (s *Secrets) readDatabase(ctx context.Context) (string, string, error) {
+	db := s.cfg.Database
+	if db.Connection == "" {
+		return "", "", errors.New("vault.database.connection is not set")
+	}
+	u, err := url.Parse(db.DSN)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", "", fmt.Errorf("vault.database.dsn must be a URL such as postgres://host:5432/db, got %q", db.DSN)
+	}
+
+	secret, err := s.client.Logical().ReadWithContext(ctx, db.Path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read %s: %w", db.Path, err)
+	}
+	if secret == nil {
+		return "", "", fmt.Errorf("failed to read %s: no secret at this path", db.Path)
+	}
+	s.database = secret
+
+	username, _ := secret.Data["username"].(string)
+	password, _ := secret.Data["password"].(string)
+	if username == "" || password == "" {
+		return "", "", fmt.Errorf("%s returned no username and password", db.Path)
+	}
+	u.User = url.UserPassword(username, password)
+
+	return "db.databases." + db.Connection + ".dsn", u.String(), nil
+}
+
+// readKV reads one field of a KV version 2 secret
+func (s *Secrets) readKV(ctx context.Context, kv KVConfig) (string, error) {
+	if kv.Path == "" || kv.Field == "" || kv.Key == "" {
+		return "", errors.New("every vault.kv entry needs a path, field and key")
+	}
+	mount := cmp.Or(kv.Mount, "secret")
+
+	secret, err := s.client.KVv2(mount).Get(ctx, kv.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s/%s: %w", mount, kv.Path, err)
+	}
+	value, ok := secret.Data[kv.Field].(string)
+	if !ok {
+		return "", fmt.Errorf("%s/%s has no string field %q", mount, kv.Path, kv.Field)
+	}
+	return value, nil
+}
+
+// revoke revokes the database credentials, so they cannot be used once the
+// process is gone. After an AppRole login, revoking the token is enough: Vault
+// revokes every lease created with it, and the policy needs no access to
+// sys/leases/revoke.
+func (s *Secrets) revoke(ctx context.Context) error {
+	if s.token != nil {
+		if err := s.client.Auth().Token().RevokeSelfWithContext(ctx, ""); err != nil {
+			return fmt.Errorf("failed to revoke vault token: %w", err)
+		}
+		return nil
+	}
+	if s.database != nil && s.database.LeaseID != "" {
+		if err := s.client.Sys().RevokeWithContext(ctx, s.database.LeaseID); err != nil {
+			return fmt.Errorf("failed to revoke database credentials: %w", err)
+		}
+	}
+	return nil
+}
+
+// Keys returns the config keys set from Vault
+func (s *Secrets) Keys() []string {
+	return s.keys
+}
+
+// envName returns the environment variable that overrides a config key
+func envName(key string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// configPaths returns the comma-separated directories in CONFIG_PATHS, or
+// ./configs when it is not set
+func configPaths() []string {
+	var paths []string
+	for _, p := range strings.Split(os.Getenv("CONFIG_PATHS"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	if len(paths) == 0 {
+		paths = []string{"./configs"}
+	}
+	return paths
+}
@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gostratum/examples/vault-demo/internal/domain"
+)
+
+// CustomerService handles customer business logic
+type CustomerService struct {
+	repo CustomerRepository
+}
+
+// NewCustomerService creates a new customer service with repository injection
+func NewCustomerService(repo CustomerRepository) *CustomerService {
+	return &CustomerService{
+		repo: repo,
+	}
+}
+
+// CreateCustomer creates a customer
+func (s *CustomerService) CreateCustomer(ctx context.Context, name, email string) (*domain.Customer, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	customer := domain.NewCustomer(name, email)
+	if err := customer.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, customer); err != nil {
+		if errors.Is(err, domain.ErrConflict) {
+			return nil, fmt.Errorf("%w: a customer with email %q already exists", ErrConflict, customer.Email)
+		}
+		return nil, translateError(err)
+	}
+	return customer, nil
+}
+
+// GetCustomer retrieves a customer by ID
+func (s *CustomerService) GetCustomer(ctx context.Context, id string) (*domain.Customer, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	customer, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return customer, nil
+}
+
+// ListCustomers returns every customer, newest first
+func (s *CustomerService) ListCustomers(ctx context.Context) ([]*domain.Customer, error) {
+	// Apply context deadline
+	ctx, cancel := context.WithTimeout(ctx, 800*time.Millisecond)
+	defer cancel()
+
+	customers, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return customers, nil
+}
+
+// translateError converts repository/domain errors to usecase errors
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return ErrNotFound
+	case errors.Is(err, domain.ErrConflict):
+		return ErrConflict
+	default:
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/vault-demo/internal/domain"
+)
+
+// fakeRepo keeps customers in memory, keyed by ID
+type fakeRepo struct {
+	customers map[string]*domain.Customer
+	err       error
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{customers: map[string]*domain.Customer{}}
+}
+
+func (r *fakeRepo) Create(ctx context.Context, customer *domain.Customer) error {
+	if r.err != nil {
+		return r.err
+	}
+	for _, c := range r.customers {
+		if c.Email == customer.Email {
+			return domain.ErrConflict
+		}
+	}
+	r.customers[customer.ID] = customer
+	return nil
+}
+
+func (r *fakeRepo) FindByID(ctx context.Context, id string) (*domain.Customer, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	c, ok := r.customers[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return c, nil
+}
+
+func (r *fakeRepo) List(ctx context.Context) ([]*domain.Customer, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	customers := make([]*domain.Customer, 0, len(r.customers))
+	for _, c := range r.customers {
+		customers = append(customers, c)
+	}
+	return customers, nil
+}
+
+func TestCreateCustomer(t *testing.T) {
+	repo := newFakeRepo()
+	svc := NewCustomerService(repo)
+	ctx := context.Background()
+
+	customer, err := svc.CreateCustomer(ctx, "Jane Doe", "jane@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Doe", customer.Name)
+	assert.Contains(t, repo.customers, customer.ID)
+
+	got, err := svc.GetCustomer(ctx, customer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, customer, got)
+
+	_, err = svc.CreateCustomer(ctx, "Jane", "JANE@example.com")
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.ErrorContains(t, err, `a customer with email "jane@example.com" already exists`)
+
+	_, err = svc.CreateCustomer(ctx, "John Doe", "john")
+	assert.ErrorIs(t, err, ErrInvalid)
+	assert.Len(t, repo.customers, 1)
+}
+
+func TestCustomerServiceTranslatesErrors(t *testing.T) {
+	repo := newFakeRepo()
+	svc := NewCustomerService(repo)
+	ctx := context.Background()
+
+	_, err := svc.GetCustomer(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	repo.err = errors.New(`password authentication failed for user "v-approle-app-1"`)
+	_, err = svc.ListCustomers(ctx)
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.ErrorContains(t, err, "password authentication failed")
+
+	_, err = svc.CreateCustomer(ctx, "Jane Doe", "jane@example.com")
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
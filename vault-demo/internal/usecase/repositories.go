@@ -0,0 +1,16 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/gostratum/examples/vault-demo/internal/domain"
+)
+
+// CustomerRepository stores customers
+// This interface is owned by the use case layer (dependency inversion principle)
+type CustomerRepository interface {
+	// Create returns domain.ErrConflict when a customer has the same email
+	Create(ctx context.Context, customer *domain.Customer) error
+	FindByID(ctx context.Context, id string) (*domain.Customer, error)
+	List(ctx context.Context) ([]*domain.Customer, error)
+}
@@ -0,0 +1,2 @@
+-- Drop customers table
+DROP TABLE IF EXISTS customers CASCADE;
@@ -0,0 +1,10 @@
+// Package migrations embeds the versioned SQL migration files, so that tests
+// can build their schema from the same files the service migrates with.
+package migrations
+
+import "embed"
+
+// FS holds every *.sql migration in this directory
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,14 @@
+-- Roles for Vault's dynamic credentials; runs once, when the database is created,
+-- after the customers migration
+
+-- app holds the service's privileges. It cannot log in; the users Vault creates
+-- are members of it and get its privileges for as long as their lease lasts.
+CREATE ROLE app NOLOGIN;
+GRANT USAGE ON SCHEMA public TO app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO app;
+
+-- vault is the user Vault creates and drops those users with. make vault-setup
+-- rotates its password, so afterwards only Vault knows it.
+CREATE ROLE vault WITH LOGIN CREATEROLE PASSWORD 'vault-bootstrap';
+GRANT app TO vault WITH ADMIN OPTION;
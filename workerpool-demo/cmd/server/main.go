@@ -0,0 +1,46 @@
+package main
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gostratum/core"
+	"github.com/gostratum/examples/redact"
+	httpAdapter "github.com/gostratum/examples/workerpool-demo/internal/adapter/http"
+	"github.com/gostratum/examples/workerpool-demo/internal/tasks"
+	"github.com/gostratum/examples/workkit/workqueue"
+	"github.com/gostratum/httpx"
+	"github.com/gostratum/metricsx"
+)
+
+func main() {
+	app := core.New(
+		// Mask DSNs, tokens and emails in every log line
+		redact.Module(),
+
+		// Prometheus metrics for queue depth, workers and task results
+		metricsx.Module(),
+
+		// HTTP server that accepts tasks and serves health probes
+		httpx.Module(),
+
+		// Bounded queue and worker pool; drains the queue on shutdown
+		workqueue.Module(),
+
+		// Provide dependencies
+		fx.Provide(
+			// Task handlers join the workqueue.handlers group
+			workqueue.AsHandler(tasks.NewThumbnailHandler),
+			workqueue.AsHandler(tasks.NewEmailHandler),
+
+			// HTTP handlers
+			httpAdapter.NewTaskHandler,
+		),
+
+		// Invoke setup functions
+		fx.Invoke(
+			httpAdapter.RegisterRoutes,
+		),
+	)
+
+	app.Run()
+}
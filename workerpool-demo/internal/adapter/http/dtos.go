@@ -0,0 +1,47 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gostratum/examples/workkit/workqueue"
+)
+
+// EmailRequest represents the request payload for sending an email
+type EmailRequest struct {
+	To      string `json:"to" binding:"required,email"`
+	Subject string `json:"subject" binding:"required"`
+	Body    string `json:"body"`
+}
+
+// TaskResponse is the HTTP DTO for a task's status
+// This struct handles JSON serialization concerns for the HTTP layer
+type TaskResponse struct {
+	ID         string     `json:"id"`
+	Kind       string     `json:"kind"`
+	State      string     `json:"state"`
+	EnqueuedAt time.Time  `json:"enqueued_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// FromTaskStatus converts a workqueue.TaskStatus to TaskResponse DTO
+func FromTaskStatus(s workqueue.TaskStatus) *TaskResponse {
+	return &TaskResponse{
+		ID:         s.ID,
+		Kind:       s.Kind,
+		State:      s.State,
+		EnqueuedAt: s.EnqueuedAt,
+		StartedAt:  optionalTime(s.StartedAt),
+		FinishedAt: optionalTime(s.FinishedAt),
+		Error:      s.Error,
+	}
+}
+
+// optionalTime omits zero times from responses
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
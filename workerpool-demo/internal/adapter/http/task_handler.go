@@ -0,0 +1,136 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+
+	"github.com/gostratum/examples/workerpool-demo/internal/tasks"
+	"github.com/gostratum/examples/workkit/workqueue"
+)
+
+const (
+	// maxUploadBytes limits the size of uploaded images
+	maxUploadBytes = 10 << 20
+	// maxPixels rejects images that are small on the wire but huge once decoded
+	maxPixels = 40_000_000
+
+	defaultThumbnailSize = 128
+	minThumbnailSize     = 16
+	maxThumbnailSize     = 1024
+)
+
+// TaskQueue accepts tasks and reports their status; implemented by workqueue.Pool
+type TaskQueue interface {
+	Enqueue(kind string, payload any) (workqueue.Task, error)
+	Status(id string) (workqueue.TaskStatus, error)
+}
+
+// TaskHandler turns HTTP requests into queued tasks
+type TaskHandler struct {
+	queue TaskQueue
+	log   logx.Logger
+}
+
+// NewTaskHandler creates a new task handler
+func NewTaskHandler(pool *workqueue.Pool, log logx.Logger) *TaskHandler {
+	return newTaskHandler(pool, log)
+}
+
+func newTaskHandler(queue TaskQueue, log logx.Logger) *TaskHandler {
+	return &TaskHandler{queue: queue, log: log}
+}
+
+// CreateThumbnail handles POST /thumbnails. The body is the image itself; the
+// optional size query parameter sets the longest edge of the thumbnail.
+func (h *TaskHandler) CreateThumbnail(c *gin.Context) {
+	size := defaultThumbnailSize
+	if s := c.Query("size"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < minThumbnailSize || n > maxThumbnailSize {
+			responsex.Error(c, http.StatusBadRequest, "INVALID_INPUT", "size must be between 16 and 1024", nil)
+			return
+		}
+		size = n
+	}
+
+	source, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadBytes))
+	if err != nil {
+		responsex.Error(c, http.StatusRequestEntityTooLarge, "IMAGE_TOO_LARGE", "image must be at most 10 MiB", nil)
+		return
+	}
+
+	// Only the header is decoded here; the full decode happens on a worker
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(source))
+	if err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_IMAGE", "body must be a PNG, JPEG or GIF image", nil)
+		return
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_IMAGE", "image has too many pixels", nil)
+		return
+	}
+
+	h.enqueue(c, tasks.KindThumbnail, tasks.ThumbnailTask{Source: source, Size: size})
+}
+
+// CreateEmail handles POST /emails
+func (h *TaskHandler) CreateEmail(c *gin.Context) {
+	var req EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responsex.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "invalid request payload", nil)
+		return
+	}
+
+	h.enqueue(c, tasks.KindEmail, tasks.EmailTask{To: req.To, Subject: req.Subject, Body: req.Body})
+}
+
+// GetTask handles GET /tasks/:id
+func (h *TaskHandler) GetTask(c *gin.Context) {
+	status, err := h.queue.Status(c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	responsex.OK(c, FromTaskStatus(status), nil)
+}
+
+// enqueue queues a task and answers with its status and location
+func (h *TaskHandler) enqueue(c *gin.Context, kind string, payload any) {
+	task, err := h.queue.Enqueue(kind, payload)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	status, err := h.queue.Status(task.ID)
+	if err != nil {
+		// Already finished and forgotten; report it as just queued
+		status = workqueue.TaskStatus{ID: task.ID, Kind: task.Kind, State: workqueue.StateQueued, EnqueuedAt: task.EnqueuedAt}
+	}
+	responsex.Created(c, "/tasks/"+task.ID, FromTaskStatus(status))
+}
+
+// handleError maps queue errors to HTTP responses
+func (h *TaskHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, workqueue.ErrQueueFull):
+		// Back pressure: the client should slow down and retry
+		c.Header("Retry-After", "1")
+		responsex.Error(c, http.StatusServiceUnavailable, "QUEUE_FULL", "too many tasks are waiting, retry later", nil)
+	case errors.Is(err, workqueue.ErrClosed):
+		responsex.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "shutting down", nil)
+	case errors.Is(err, workqueue.ErrTaskNotFound):
+		responsex.Error(c, http.StatusNotFound, "TASK_NOT_FOUND", "task not found", nil)
+	default:
+		h.log.Error("task request failed", logx.Err(err))
+		responsex.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error", nil)
+	}
+}
@@ -0,0 +1,179 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostratum/core/logx"
+	"github.com/gostratum/httpx/responsex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/workerpool-demo/internal/tasks"
+	"github.com/gostratum/examples/workkit/workqueue"
+)
+
+// fakeQueue records enqueued tasks and fails with err when set
+type fakeQueue struct {
+	err   error
+	tasks []workqueue.Task
+}
+
+func (q *fakeQueue) Enqueue(kind string, payload any) (workqueue.Task, error) {
+	if q.err != nil {
+		return workqueue.Task{}, q.err
+	}
+	task := workqueue.Task{ID: "task-1", Kind: kind, Payload: payload, EnqueuedAt: time.Now()}
+	q.tasks = append(q.tasks, task)
+	return task, nil
+}
+
+func (q *fakeQueue) Status(id string) (workqueue.TaskStatus, error) {
+	for _, task := range q.tasks {
+		if task.ID == id {
+			return workqueue.TaskStatus{ID: id, Kind: task.Kind, State: workqueue.StateQueued, EnqueuedAt: task.EnqueuedAt}, nil
+		}
+	}
+	return workqueue.TaskStatus{}, workqueue.ErrTaskNotFound
+}
+
+func setupRouter(queue TaskQueue) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	handler := newTaskHandler(queue, logx.NewNoopLogger())
+
+	e := gin.New()
+	e.POST("/thumbnails", handler.CreateThumbnail)
+	e.POST("/emails", handler.CreateEmail)
+	e.GET("/tasks/:id", handler.GetTask)
+	return e
+}
+
+func pngBody(t *testing.T) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))))
+	return buf.Bytes()
+}
+
+func TestCreateThumbnail(t *testing.T) {
+	queue := &fakeQueue{}
+	e := setupRouter(queue)
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/thumbnails?size=64", bytes.NewReader(pngBody(t))))
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "/tasks/task-1", w.Header().Get("Location"))
+
+	var resp responsex.Envelope[TaskResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "task-1", resp.Data.ID)
+	assert.Equal(t, tasks.KindThumbnail, resp.Data.Kind)
+	assert.Equal(t, workqueue.StateQueued, resp.Data.State)
+	assert.Nil(t, resp.Data.StartedAt)
+
+	require.Len(t, queue.tasks, 1)
+	payload := queue.tasks[0].Payload.(tasks.ThumbnailTask)
+	assert.Equal(t, 64, payload.Size)
+}
+
+func TestCreateThumbnailValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		body     []byte
+		wantCode string
+	}{
+		{name: "size too small", url: "/thumbnails?size=8", wantCode: "INVALID_INPUT"},
+		{name: "size not a number", url: "/thumbnails?size=big", wantCode: "INVALID_INPUT"},
+		{name: "not an image", url: "/thumbnails", body: []byte("hello"), wantCode: "INVALID_IMAGE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := &fakeQueue{}
+			e := setupRouter(queue)
+
+			body := tt.body
+			if body == nil {
+				body = pngBody(t)
+			}
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.url, bytes.NewReader(body)))
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantCode)
+			assert.Empty(t, queue.tasks)
+		})
+	}
+}
+
+func TestCreateEmail(t *testing.T) {
+	queue := &fakeQueue{}
+	e := setupRouter(queue)
+
+	w := httptest.NewRecorder()
+	body := `{"to": "jane@example.com", "subject": "Welcome", "body": "Hi Jane"}`
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/emails", strings.NewReader(body)))
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	require.Len(t, queue.tasks, 1)
+	assert.Equal(t, tasks.EmailTask{To: "jane@example.com", Subject: "Welcome", Body: "Hi Jane"}, queue.tasks[0].Payload)
+
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/emails", strings.NewReader(`{"to": "not-an-address"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestQueueErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   string
+		retryAfter string
+	}{
+		{name: "full", err: workqueue.ErrQueueFull, wantCode: "QUEUE_FULL", retryAfter: "1"},
+		{name: "closed", err: workqueue.ErrClosed, wantCode: "SERVICE_UNAVAILABLE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := setupRouter(&fakeQueue{err: tt.err})
+
+			w := httptest.NewRecorder()
+			body := `{"to": "jane@example.com", "subject": "Welcome"}`
+			e.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/emails", strings.NewReader(body)))
+			assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantCode)
+			assert.Equal(t, tt.retryAfter, w.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func TestGetTask(t *testing.T) {
+	queue := &fakeQueue{}
+	e := setupRouter(queue)
+	_, err := queue.Enqueue(tasks.KindEmail, tasks.EmailTask{})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tasks/task-1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp responsex.Envelope[TaskResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, tasks.KindEmail, resp.Data.Kind)
+
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tasks/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "TASK_NOT_FOUND")
+}
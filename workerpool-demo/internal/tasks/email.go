@@ -0,0 +1,96 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+
+	"github.com/gostratum/examples/workkit/workqueue"
+)
+
+// KindEmail is the task kind of email sending
+const KindEmail = "email"
+
+// ErrSimulatedFailure is returned for the simulated delivery failures
+var ErrSimulatedFailure = errors.New("simulated delivery failure")
+
+// EmailTask is the payload of an email task
+type EmailTask struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// EmailConfig tunes the simulated email sender
+type EmailConfig struct {
+	From string `mapstructure:"from" default:"noreply@example.com"`
+	// Latency is how long one send takes, as if talking to an SMTP server
+	Latency time.Duration `mapstructure:"latency" default:"300ms"`
+	// FailureRate is the fraction of sends (0-1) that fail on purpose
+	FailureRate float64 `mapstructure:"failure_rate" default:"0.05"`
+}
+
+// Prefix implements configx.Configurable
+func (EmailConfig) Prefix() string {
+	return "email"
+}
+
+// EmailHandler sends emails. The sending is simulated: it waits for the
+// configured latency and logs the message. The work is I/O-bound, so the pool
+// size caps how many connections a mail server sees.
+type EmailHandler struct {
+	cfg EmailConfig
+	log logx.Logger
+	// random returns a float in [0, 1); replaced in tests for deterministic failures
+	random func() float64
+}
+
+// NewEmailHandler creates the handler from the email config section
+func NewEmailHandler(loader configx.Loader, log logx.Logger) (*EmailHandler, error) {
+	var cfg EmailConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load email config: %w", err)
+	}
+	if cfg.FailureRate < 0 || cfg.FailureRate > 1 {
+		return nil, fmt.Errorf("email.failure_rate must be between 0 and 1, got %v", cfg.FailureRate)
+	}
+	return &EmailHandler{cfg: cfg, log: log, random: rand.Float64}, nil
+}
+
+// Kind implements workqueue.Handler
+func (h *EmailHandler) Kind() string {
+	return KindEmail
+}
+
+// Handle implements workqueue.Handler
+func (h *EmailHandler) Handle(ctx context.Context, task workqueue.Task) error {
+	payload, ok := task.Payload.(EmailTask)
+	if !ok {
+		return fmt.Errorf("unexpected payload %T", task.Payload)
+	}
+
+	timer := time.NewTimer(h.cfg.Latency)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+	}
+
+	if h.random() < h.cfg.FailureRate {
+		return ErrSimulatedFailure
+	}
+
+	h.log.Info("email sent",
+		logx.String("task_id", task.ID),
+		logx.String("from", h.cfg.From),
+		logx.String("to", payload.To),
+		logx.String("subject", payload.Subject),
+	)
+	return nil
+}
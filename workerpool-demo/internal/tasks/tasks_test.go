@@ -0,0 +1,108 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gostratum/examples/workkit/workqueue"
+)
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.SetRGBA(x, y, color.RGBA{R: uint8(x), G: uint8(y), A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestThumbnailHandlerWritesPNG(t *testing.T) {
+	h, err := newThumbnailHandler(t.TempDir(), logx.NewNoopLogger())
+	require.NoError(t, err)
+
+	task := workqueue.Task{ID: "task-1", Kind: KindThumbnail, Payload: ThumbnailTask{Source: encodePNG(t, 200, 100), Size: 50}}
+	require.NoError(t, h.Handle(context.Background(), task))
+
+	f, err := os.Open(h.Path("task-1"))
+	require.NoError(t, err)
+	defer f.Close()
+	cfg, format, err := image.DecodeConfig(f)
+	require.NoError(t, err)
+	assert.Equal(t, "png", format)
+	assert.Equal(t, 50, cfg.Width)
+	assert.Equal(t, 25, cfg.Height)
+
+	_, err = os.Stat(h.Path("task-1") + ".tmp")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestThumbnailHandlerRejectsBadInput(t *testing.T) {
+	h, err := newThumbnailHandler(t.TempDir(), logx.NewNoopLogger())
+	require.NoError(t, err)
+
+	err = h.Handle(context.Background(), workqueue.Task{ID: "a", Payload: ThumbnailTask{Source: []byte("not an image"), Size: 50}})
+	assert.ErrorContains(t, err, "failed to decode image")
+
+	err = h.Handle(context.Background(), workqueue.Task{ID: "b", Payload: EmailTask{}})
+	assert.ErrorContains(t, err, "unexpected payload")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = h.Handle(ctx, workqueue.Task{ID: "c", Payload: ThumbnailTask{Source: encodePNG(t, 10, 10), Size: 5}})
+	assert.ErrorIs(t, err, context.Canceled)
+	_, statErr := os.Stat(h.Path("c"))
+	assert.True(t, os.IsNotExist(statErr))
+}
+
+func TestEmailHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		random  float64
+		wantErr error
+	}{
+		{name: "sent", random: 0.5},
+		{name: "simulated failure", random: 0.01, wantErr: ErrSimulatedFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &EmailHandler{
+				cfg:    EmailConfig{From: "noreply@example.com", FailureRate: 0.05},
+				log:    logx.NewNoopLogger(),
+				random: func() float64 { return tt.random },
+			}
+			task := workqueue.Task{ID: "task-1", Kind: KindEmail, Payload: EmailTask{To: "a@example.com", Subject: "Hi"}}
+
+			err := h.Handle(context.Background(), task)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEmailHandlerStopsOnCancel(t *testing.T) {
+	h := &EmailHandler{cfg: EmailConfig{Latency: time.Minute}, log: logx.NewNoopLogger(), random: func() float64 { return 1 }}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := h.Handle(ctx, workqueue.Task{ID: "task-1", Payload: EmailTask{To: "a@example.com"}})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,118 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif"  // Register GIF decoding
+	_ "image/jpeg" // Register JPEG decoding
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+
+	"github.com/gostratum/examples/workkit/imaging"
+	"github.com/gostratum/examples/workkit/workqueue"
+)
+
+// KindThumbnail is the task kind of thumbnail generation
+const KindThumbnail = "thumbnail"
+
+// ThumbnailTask is the payload of a thumbnail task
+type ThumbnailTask struct {
+	// Source is the encoded image (PNG, JPEG or GIF)
+	Source []byte
+	// Size is the longest edge of the thumbnail in pixels
+	Size int
+}
+
+// ThumbnailConfig tunes the thumbnail handler
+type ThumbnailConfig struct {
+	// Dir is where thumbnails are written, as <task id>.png
+	Dir string `mapstructure:"dir" default:"./thumbnails"`
+}
+
+// Prefix implements configx.Configurable
+func (ThumbnailConfig) Prefix() string {
+	return "thumbnails"
+}
+
+// ThumbnailHandler scales images down and stores them as PNG. The work is
+// CPU-bound, so the pool size caps how many cores thumbnails can use.
+type ThumbnailHandler struct {
+	dir string
+	log logx.Logger
+}
+
+// NewThumbnailHandler creates the handler from the thumbnails config section
+// and makes sure the output directory exists
+func NewThumbnailHandler(loader configx.Loader, log logx.Logger) (*ThumbnailHandler, error) {
+	var cfg ThumbnailConfig
+	if err := loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load thumbnails config: %w", err)
+	}
+	return newThumbnailHandler(cfg.Dir, log)
+}
+
+func newThumbnailHandler(dir string, log logx.Logger) (*ThumbnailHandler, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create thumbnails directory %s: %w", dir, err)
+	}
+	return &ThumbnailHandler{dir: dir, log: log}, nil
+}
+
+// Kind implements workqueue.Handler
+func (h *ThumbnailHandler) Kind() string {
+	return KindThumbnail
+}
+
+// Path returns where the thumbnail of a task is written
+func (h *ThumbnailHandler) Path(taskID string) string {
+	return filepath.Join(h.dir, taskID+".png")
+}
+
+// Handle implements workqueue.Handler
+func (h *ThumbnailHandler) Handle(ctx context.Context, task workqueue.Task) error {
+	payload, ok := task.Payload.(ThumbnailTask)
+	if !ok {
+		return fmt.Errorf("unexpected payload %T", task.Payload)
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(payload.Source))
+	if err != nil {
+		return fmt.Errorf("failed to decode image: %w", err)
+	}
+	// Scaling cannot be interrupted, so give up before it if time is already out
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	thumb := imaging.Thumbnail(src, payload.Size)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, thumb); err != nil {
+		return fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	// Write to a temporary file first so readers never see a partial thumbnail
+	path := h.Path(task.ID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write thumbnail: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write thumbnail: %w", err)
+	}
+
+	b := thumb.Bounds()
+	h.log.Info("thumbnail created",
+		logx.String("task_id", task.ID),
+		logx.String("format", format),
+		logx.Int("width", b.Dx()),
+		logx.Int("height", b.Dy()),
+		logx.String("path", path),
+	)
+	return nil
+}
@@ -0,0 +1,265 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// ErrMalformed indicates a file whose structure could not be followed to its
+// end, so its metadata could not be removed
+var ErrMalformed = errors.New("malformed image")
+
+var (
+	jpegSignature = []byte{0xFF, 0xD8}
+	pngSignature  = []byte("\x89PNG\r\n\x1a\n")
+	exifHeader    = []byte("Exif\x00\x00")
+)
+
+// StripMetadata removes what can tell who took a photo, where and with what
+// from a JPEG, PNG or WebP file: EXIF (GPS position, device, capture time),
+// XMP, IPTC, comments and text chunks, and anything after the end of the
+// image. The image data itself is copied byte for byte, without decoding, as
+// are colour profiles. The EXIF orientation of a JPEG is kept in an EXIF
+// block of its own, so photos still display upright. Other formats come back
+// unchanged.
+//
+// It reports whether anything was removed, and fails with ErrMalformed when
+// a segment or chunk runs past the end of data.
+func StripMetadata(data []byte) ([]byte, bool, error) {
+	switch {
+	case bytes.HasPrefix(data, jpegSignature):
+		return stripJPEG(data)
+	case bytes.HasPrefix(data, pngSignature):
+		return stripPNG(data)
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return stripWebP(data)
+	}
+	return data, false, nil
+}
+
+// keepJPEGSegment reports whether a JPEG marker segment is kept. APP0 (JFIF),
+// APP2 (ICC profile) and APP14 (Adobe colour transform) affect how the image
+// looks; the other application segments and comments are metadata.
+func keepJPEGSegment(marker byte) bool {
+	switch {
+	case marker == 0xE0, marker == 0xE2, marker == 0xEE:
+		return true
+	case marker >= 0xE1 && marker <= 0xEF, marker == 0xFE:
+		return false
+	}
+	return true
+}
+
+func stripJPEG(data []byte) ([]byte, bool, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, jpegSignature...)
+	stripped, orientationKept := false, false
+
+	i := len(jpegSignature)
+	for i < len(data) {
+		if i+2 > len(data) || data[i] != 0xFF {
+			return nil, false, ErrMalformed
+		}
+		marker := data[i+1]
+		switch {
+		case marker == 0xFF:
+			// Fill byte before a marker
+			i++
+			continue
+		case marker == 0xD9:
+			// End of image; anything after it, such as a second image with
+			// EXIF of its own, is dropped
+			out = append(out, data[i:i+2]...)
+			return out, stripped || i+2 < len(data), nil
+		case marker == 0x01, marker >= 0xD0 && marker <= 0xD7:
+			// Markers without a length
+			out = append(out, data[i:i+2]...)
+			i += 2
+			continue
+		}
+
+		if i+4 > len(data) {
+			return nil, false, ErrMalformed
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end < i+4 || end > len(data) {
+			return nil, false, ErrMalformed
+		}
+		segment := data[i:end]
+
+		if marker == 0xDA {
+			// Start of scan: the entropy-coded data runs up to the next marker
+			// that is neither a stuffed 0xFF00 nor a restart
+			j := end
+			for j+1 < len(data) && (data[j] != 0xFF || data[j+1] == 0x00 || data[j+1] >= 0xD0 && data[j+1] <= 0xD7) {
+				j++
+			}
+			if j+1 >= len(data) {
+				return nil, false, ErrMalformed
+			}
+			out = append(out, data[i:j]...)
+			i = j
+			continue
+		}
+
+		switch {
+		case keepJPEGSegment(marker):
+			out = append(out, segment...)
+		case marker == 0xE1 && !orientationKept:
+			// EXIF is replaced by an orientation-only block, which a file
+			// stripped before already has
+			if o := exifOrientation(segment[4:]); o > 1 {
+				kept := orientationSegment(o)
+				out = append(out, kept...)
+				orientationKept = true
+				stripped = stripped || !bytes.Equal(segment, kept)
+			} else {
+				stripped = true
+			}
+		default:
+			stripped = true
+		}
+		i = end
+	}
+	// The file ends between segments: truncated, but nothing is hidden in it
+	return out, stripped, nil
+}
+
+// exifOrientation returns the orientation tag of an APP1 payload, or 0 when
+// it is not EXIF or has no valid orientation
+func exifOrientation(payload []byte) int {
+	if !bytes.HasPrefix(payload, exifHeader) {
+		return 0
+	}
+	tiff := payload[len(exifHeader):]
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for n := range entries {
+		entry := ifd + 2 + 12*n
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		// Orientation is tag 0x0112, one SHORT, held in the value field
+		if order.Uint16(tiff[entry:]) == 0x0112 && order.Uint16(tiff[entry+2:]) == 3 {
+			if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+// orientationSegment returns an APP1 segment whose EXIF holds the
+// orientation o and nothing else
+func orientationSegment(o int) []byte {
+	tiff := []byte{
+		'M', 'M', 0, 42, // big-endian TIFF
+		0, 0, 0, 8, // IFD0 follows the header
+		0, 1, // one entry
+		0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, byte(o), 0, 0, // orientation, one SHORT
+		0, 0, 0, 0, // no further IFD
+	}
+	payload := append(append([]byte{}, exifHeader...), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(2+len(payload)))
+	return append(segment, payload...)
+}
+
+// strippedPNGChunks are the metadata chunks of a PNG
+var strippedPNGChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"iTXt": true,
+	"zTXt": true,
+	"tIME": true,
+}
+
+func stripPNG(data []byte) ([]byte, bool, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+	stripped := false
+
+	i := len(pngSignature)
+	for i < len(data) {
+		// Length, type, data and CRC
+		if i+12 > len(data) {
+			return nil, false, ErrMalformed
+		}
+		end := i + 12 + int(binary.BigEndian.Uint32(data[i:]))
+		if end < i+12 || end > len(data) {
+			return nil, false, ErrMalformed
+		}
+		chunk := string(data[i+4 : i+8])
+		if strippedPNGChunks[chunk] {
+			stripped = true
+		} else {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+		if chunk == "IEND" {
+			return out, stripped || i < len(data), nil
+		}
+	}
+	return out, stripped, nil
+}
+
+// VP8X flags of the metadata chunks
+const (
+	webpFlagEXIF = 0x08
+	webpFlagXMP  = 0x04
+)
+
+func stripWebP(data []byte) ([]byte, bool, error) {
+	riffEnd := 8 + int(binary.LittleEndian.Uint32(data[4:]))
+	if riffEnd > len(data) || riffEnd < 12 {
+		return nil, false, ErrMalformed
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+	stripped := riffEnd < len(data)
+
+	i := 12
+	for i < riffEnd {
+		if i+8 > riffEnd {
+			return nil, false, ErrMalformed
+		}
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size + size%2
+		if end < i+8 || end > riffEnd {
+			return nil, false, ErrMalformed
+		}
+		switch string(data[i : i+4]) {
+		case "EXIF", "XMP ":
+			stripped = true
+		case "VP8X":
+			chunk := append([]byte{}, data[i:end]...)
+			if size > 0 {
+				chunk[8] &^= webpFlagEXIF | webpFlagXMP
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, stripped, nil
+}
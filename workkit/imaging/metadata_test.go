@@ -0,0 +1,246 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// gpsLatitude is in the sample EXIF, and must not survive stripping
+const gpsLatitude = "N\x00\x00\x00"
+
+// sampleEXIF returns a little-endian TIFF, as a phone writes it: IFD0 with
+// the camera make and the orientation, and a GPS IFD with the latitude
+// reference
+func sampleEXIF(orientation int) []byte {
+	le := binary.LittleEndian
+	tiff := []byte{'I', 'I', 42, 0, 8, 0, 0, 0}
+	entry := func(tag, typ uint16, count, value uint32) []byte {
+		e := make([]byte, 12)
+		le.PutUint16(e, tag)
+		le.PutUint16(e[2:], typ)
+		le.PutUint32(e[4:], count)
+		le.PutUint32(e[8:], value)
+		return e
+	}
+	// IFD0 at 8: three entries, then the GPS IFD at 8+2+36+4 = 50
+	tiff = append(tiff, 3, 0)
+	tiff = append(tiff, entry(0x010F, 2, 4, binary.LittleEndian.Uint32([]byte("ACM\x00")))...) // Make
+	tiff = append(tiff, entry(0x0112, 3, 1, uint32(orientation))...)
+	tiff = append(tiff, entry(0x8825, 4, 1, 50)...) // GPS IFD
+	tiff = append(tiff, 0, 0, 0, 0)
+	tiff = append(tiff, 1, 0)
+	tiff = append(tiff, entry(0x0001, 2, 2, le.Uint32([]byte(gpsLatitude)))...) // GPSLatitudeRef
+	return append(tiff, 0, 0, 0, 0)
+}
+
+func sampleImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 16, 8))
+	for y := range 8 {
+		for x := range 16 {
+			img.Set(x, y, color.RGBA{R: uint8(x * 16), G: uint8(y * 32), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+// jpegSegment returns a marker segment with payload
+func jpegSegment(marker byte, payload []byte) []byte {
+	s := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(s[2:], uint16(2+len(payload)))
+	return append(s, payload...)
+}
+
+// sampleJPEG returns a JPEG with EXIF, XMP, a comment and an ICC profile
+// before the image, and a second image with EXIF of its own after it
+func sampleJPEG(t *testing.T, orientation int) []byte {
+	t.Helper()
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, sampleImage(), nil); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	out.Write(jpegSignature)
+	out.Write(jpegSegment(0xE1, append([]byte("Exif\x00\x00"), sampleEXIF(orientation)...)))
+	out.Write(jpegSegment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta>Jane's phone</x:xmpmeta>")))
+	out.Write(jpegSegment(0xE2, []byte("ICC_PROFILE\x00\x01\x01profile")))
+	out.Write(jpegSegment(0xFE, []byte("Jane's phone")))
+	out.Write(encoded.Bytes()[2:])
+	// A trailing preview, as some cameras append
+	out.Write(jpegSignature)
+	out.Write(jpegSegment(0xE1, append([]byte("Exif\x00\x00"), sampleEXIF(1)...)))
+	out.Write([]byte{0xFF, 0xD9})
+	return out.Bytes()
+}
+
+// pngChunk returns a chunk of typ holding data, with its CRC
+func pngChunk(typ string, data []byte) []byte {
+	c := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	c = append(c, typ...)
+	c = append(c, data...)
+	return binary.BigEndian.AppendUint32(c, crc32.ChecksumIEEE(c[4:]))
+}
+
+// samplePNG returns a PNG with eXIf and text chunks after its header
+func samplePNG(t *testing.T) []byte {
+	t.Helper()
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, sampleImage()); err != nil {
+		t.Fatal(err)
+	}
+	data := encoded.Bytes()
+	ihdrEnd := len(pngSignature) + 12 + 13
+	var out bytes.Buffer
+	out.Write(data[:ihdrEnd])
+	out.Write(pngChunk("eXIf", sampleEXIF(6)))
+	out.Write(pngChunk("tEXt", []byte("Author\x00Jane")))
+	out.Write(pngChunk("tIME", []byte{0x07, 0xE8, 1, 2, 3, 4, 5}))
+	out.Write(data[ihdrEnd:])
+	return out.Bytes()
+}
+
+func decode(t *testing.T, data []byte) image.Image {
+	t.Helper()
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("stripped image does not decode: %v", err)
+	}
+	return img
+}
+
+func TestStripMetadata_JPEG(t *testing.T) {
+	in := sampleJPEG(t, 6)
+	out, stripped, err := StripMetadata(in)
+	if err != nil || !stripped {
+		t.Fatalf("StripMetadata() stripped = %v, err = %v", stripped, err)
+	}
+
+	for _, leak := range []string{gpsLatitude, "ACM", "Jane", "xmpmeta"} {
+		if bytes.Contains(out, []byte(leak)) {
+			t.Errorf("%q is left in the stripped JPEG", leak)
+		}
+	}
+	if !bytes.Contains(out, []byte("ICC_PROFILE")) {
+		t.Error("the colour profile is removed")
+	}
+	if got := exifOrientation(findSegment(out, 0xE1)); got != 6 {
+		t.Errorf("orientation = %d, want 6", got)
+	}
+	if !bytes.HasSuffix(out, []byte{0xFF, 0xD9}) || bytes.Count(out, jpegSignature) != 1 {
+		t.Error("the trailing image is kept")
+	}
+	if b := decode(t, out).Bounds(); b.Dx() != 16 || b.Dy() != 8 {
+		t.Errorf("stripped image is %v, want 16×8", b)
+	}
+
+	// Stripping again finds nothing more
+	again, stripped, err := StripMetadata(out)
+	if err != nil || stripped || !bytes.Equal(again, out) {
+		t.Errorf("StripMetadata() of a stripped JPEG: stripped = %v, err = %v", stripped, err)
+	}
+}
+
+func TestStripMetadata_JPEGUpright(t *testing.T) {
+	out, _, err := StripMetadata(sampleJPEG(t, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Orientation 1 is the default, so no EXIF is written for it
+	if s := findSegment(out, 0xE1); s != nil {
+		t.Errorf("APP1 segment %q is left", s)
+	}
+}
+
+// findSegment returns the payload of the first segment with marker before
+// the image data, or nil
+func findSegment(data []byte, marker byte) []byte {
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF && data[i+1] != 0xDA; {
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if data[i+1] == marker {
+			return data[i+4 : end]
+		}
+		i = end
+	}
+	return nil
+}
+
+func TestStripMetadata_PNG(t *testing.T) {
+	in := samplePNG(t)
+	out, stripped, err := StripMetadata(in)
+	if err != nil || !stripped {
+		t.Fatalf("StripMetadata() stripped = %v, err = %v", stripped, err)
+	}
+	for _, leak := range []string{gpsLatitude, "eXIf", "tEXt", "tIME", "Jane"} {
+		if bytes.Contains(out, []byte(leak)) {
+			t.Errorf("%q is left in the stripped PNG", leak)
+		}
+	}
+	if !bytes.Equal(decode(t, out).(*image.RGBA).Pix, decode(t, in).(*image.RGBA).Pix) {
+		t.Error("the pixels changed")
+	}
+}
+
+func TestStripMetadata_WebP(t *testing.T) {
+	chunk := func(fourcc string, data []byte) []byte {
+		c := append([]byte(fourcc), binary.LittleEndian.AppendUint32(nil, uint32(len(data)))...)
+		c = append(c, data...)
+		if len(data)%2 == 1 {
+			c = append(c, 0)
+		}
+		return c
+	}
+	vp8x := []byte{webpFlagEXIF | webpFlagXMP, 0, 0, 0, 15, 0, 0, 7, 0, 0}
+	body := append([]byte("WEBP"), chunk("VP8X", vp8x)...)
+	body = append(body, chunk("VP8L", []byte("pixels"))...)
+	body = append(body, chunk("EXIF", sampleEXIF(6))...)
+	body = append(body, chunk("XMP ", []byte("<x:xmpmeta/>"))...)
+	in := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	in = append(in, body...)
+
+	out, stripped, err := StripMetadata(in)
+	if err != nil || !stripped {
+		t.Fatalf("StripMetadata() stripped = %v, err = %v", stripped, err)
+	}
+	want := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, 4+18+14)...)
+	want = append(want, "WEBP"...)
+	want = append(want, chunk("VP8X", append([]byte{0}, vp8x[1:]...))...)
+	want = append(want, chunk("VP8L", []byte("pixels"))...)
+	if !bytes.Equal(out, want) {
+		t.Errorf("StripMetadata() = %q, want %q", out, want)
+	}
+}
+
+func TestStripMetadata_Unchanged(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"GIF":        []byte("GIF89a\x01\x00\x01\x00"),
+		"unknown":    []byte("not an image"),
+		"bare PNG":   pngSignature,
+		"empty JPEG": jpegSignature,
+	} {
+		out, stripped, err := StripMetadata(data)
+		if err != nil || stripped || !bytes.Equal(out, data) {
+			t.Errorf("%s: StripMetadata() stripped = %v, err = %v", name, stripped, err)
+		}
+	}
+}
+
+func TestStripMetadata_Malformed(t *testing.T) {
+	jpg := sampleJPEG(t, 6)
+	for name, data := range map[string][]byte{
+		"JPEG cut in a segment":     jpg[:20],
+		"JPEG cut in the image":     jpg[:len(jpg)/2],
+		"JPEG without markers":      append(append([]byte{}, jpegSignature...), "garbage"...),
+		"PNG cut in a chunk":        samplePNG(t)[:40],
+		"WebP longer than its data": []byte("RIFF\xff\x00\x00\x00WEBP"),
+	} {
+		if _, _, err := StripMetadata(data); !errors.Is(err, ErrMalformed) {
+			t.Errorf("%s: err = %v, want ErrMalformed", name, err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Package imaging scales images down and strips their metadata, with the
+// standard library only
+package imaging
+
+import (
+	"image"
+	"image/color"
+)
+
+// Fit returns the size of a w×h image scaled down to fit in a size×size box,
+// keeping its aspect ratio. Images that already fit keep their size.
+func Fit(w, h, size int) (int, int) {
+	if w <= size && h <= size {
+		return w, h
+	}
+	if w >= h {
+		return size, max(1, h*size/w)
+	}
+	return max(1, w*size/h), size
+}
+
+// Thumbnail scales src down to fit in a size×size box. Each target pixel is the
+// average of the source pixels it covers (a box filter), which keeps detail
+// better than sampling one pixel when shrinking a lot.
+func Thumbnail(src image.Image, size int) *image.RGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dw, dh := Fit(sw, sh, size)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := range dh {
+		y0, y1 := b.Min.Y+y*sh/dh, b.Min.Y+max((y+1)*sh/dh, y*sh/dh+1)
+		for x := range dw {
+			x0, x1 := b.Min.X+x*sw/dw, b.Min.X+max((x+1)*sw/dw, x*sw/dw+1)
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(pr), g+uint64(pg), bl+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+			dst.SetRGBA64(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(bl / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,60 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		name         string
+		w, h, size   int
+		wantW, wantH int
+	}{
+		{name: "landscape", w: 1024, h: 768, size: 128, wantW: 128, wantH: 96},
+		{name: "portrait", w: 600, h: 1200, size: 100, wantW: 50, wantH: 100},
+		{name: "already fits", w: 64, h: 32, size: 128, wantW: 64, wantH: 32},
+		{name: "very wide", w: 4000, h: 10, size: 100, wantW: 100, wantH: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, h := Fit(tt.w, tt.h, tt.size)
+			if w != tt.wantW || h != tt.wantH {
+				t.Errorf("Fit(%d, %d, %d) = %d×%d, want %d×%d", tt.w, tt.h, tt.size, w, h, tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestThumbnailAveragesPixels(t *testing.T) {
+	// Left half black, right half white
+	src := image.NewRGBA(image.Rect(10, 10, 30, 20))
+	for y := 10; y < 20; y++ {
+		for x := 10; x < 30; x++ {
+			c := color.RGBA{A: 255}
+			if x >= 20 {
+				c = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			}
+			src.SetRGBA(x, y, c)
+		}
+	}
+
+	thumb := Thumbnail(src, 2)
+	if got := thumb.Bounds().Size(); got != image.Pt(2, 1) {
+		t.Fatalf("size = %v, want 2×1", got)
+	}
+	if got := thumb.RGBAAt(0, 0); got != (color.RGBA{A: 255}) {
+		t.Errorf("left pixel = %v, want black", got)
+	}
+	if got := thumb.RGBAAt(1, 0); got != (color.RGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Errorf("right pixel = %v, want white", got)
+	}
+
+	// A single target pixel averages the whole image to grey
+	grey := Thumbnail(src, 1).RGBAAt(0, 0)
+	if grey.R < 126 || grey.R > 128 {
+		t.Errorf("average = %v, want mid grey", grey)
+	}
+}
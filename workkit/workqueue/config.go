@@ -0,0 +1,26 @@
+// Package workqueue is a small fx module around an in-process job queue: a
+// bounded queue drained by a fixed pool of workers, which runs the Handler
+// registered for each task's kind
+package workqueue
+
+import "time"
+
+// Config sizes the queue and the worker pool
+type Config struct {
+	// Workers is how many tasks run at the same time
+	Workers int `mapstructure:"workers" default:"4"`
+	// Capacity is how many tasks can wait in the queue; Enqueue fails once it is full
+	Capacity int `mapstructure:"capacity" default:"100"`
+	// TaskTimeout bounds one call of a handler
+	TaskTimeout time.Duration `mapstructure:"task_timeout" default:"30s"`
+	// Retain is how many finished tasks keep their status for Status lookups
+	Retain int `mapstructure:"retain" default:"1000"`
+	// ReadyThreshold is the fill ratio (0-1) above which the readiness check
+	// fails, so load balancers send new work elsewhere before the queue is full
+	ReadyThreshold float64 `mapstructure:"ready_threshold" default:"0.9"`
+}
+
+// Prefix implements configx.Configurable
+func (Config) Prefix() string {
+	return "workqueue"
+}
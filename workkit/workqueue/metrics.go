@@ -0,0 +1,90 @@
+package workqueue
+
+import (
+	"github.com/gostratum/metricsx"
+	"go.uber.org/fx"
+)
+
+// Metrics records the queue and the tasks
+type Metrics struct {
+	depth    gauge
+	capacity gauge
+	workers  gauge
+	busy     gauge
+	tasks    counter
+	rejected counter
+	wait     histogram
+	duration histogram
+}
+
+// MetricsParams are the dependencies of NewMetrics
+type MetricsParams struct {
+	fx.In
+
+	// Metrics is missing when the application runs without metricsx.Module,
+	// as the test apps do
+	Metrics metricsx.Metrics `optional:"true"`
+}
+
+// NewMetrics registers the work queue metrics, or records none without metricsx
+func NewMetrics(p MetricsParams) *Metrics {
+	if p.Metrics == nil {
+		return &Metrics{
+			depth: discard{}, capacity: discard{}, workers: discard{}, busy: discard{},
+			tasks: discard{}, rejected: discard{}, wait: discard{}, duration: discard{},
+		}
+	}
+	metrics := p.Metrics
+	return &Metrics{
+		depth: metrics.Gauge("workqueue_depth",
+			metricsx.WithHelp("Tasks waiting in the queue"),
+		),
+		capacity: metrics.Gauge("workqueue_capacity",
+			metricsx.WithHelp("Tasks the queue can hold"),
+		),
+		workers: metrics.Gauge("workqueue_workers",
+			metricsx.WithHelp("Workers in the pool"),
+		),
+		busy: metrics.Gauge("workqueue_workers_busy",
+			metricsx.WithHelp("Workers running a task"),
+		),
+		tasks: metrics.Counter("workqueue_tasks_total",
+			metricsx.WithHelp("Tasks taken from the queue, by kind and result (succeeded, failed, dropped)"),
+			metricsx.WithLabels("kind", "result"),
+		),
+		rejected: metrics.Counter("workqueue_rejected_total",
+			metricsx.WithHelp("Tasks not accepted because the queue was full or closed"),
+			metricsx.WithLabels("kind"),
+		),
+		wait: metrics.Histogram("workqueue_wait_seconds",
+			metricsx.WithHelp("Time a task waited in the queue"),
+			metricsx.WithLabels("kind"),
+		),
+		duration: metrics.Histogram("workqueue_task_duration_seconds",
+			metricsx.WithHelp("Time a handler took for one task"),
+			metricsx.WithLabels("kind"),
+		),
+	}
+}
+
+// counter is the part of metricsx.Counter the queue uses
+type counter interface {
+	Inc(labels ...string)
+}
+
+// histogram is the part of metricsx.Histogram the queue uses
+type histogram interface {
+	Observe(v float64, labels ...string)
+}
+
+// gauge is the part of metricsx.Gauge the queue uses
+type gauge interface {
+	Set(v float64, labels ...string)
+}
+
+// discard is a counter, histogram and gauge that records nothing
+type discard struct{}
+
+func (discard) Inc(labels ...string)                {}
+func (discard) Observe(v float64, labels ...string) {}
+func (discard) Set(v float64, labels ...string)     {}
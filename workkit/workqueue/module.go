@@ -0,0 +1,15 @@
+package workqueue
+
+import "go.uber.org/fx"
+
+// Module provides the pool and its metrics, and runs the workers from
+// application start until the queue has drained at stop
+func Module() fx.Option {
+	return fx.Module("workqueue",
+		fx.Provide(
+			NewMetrics,
+			NewPool,
+		),
+		fx.Invoke(Register),
+	)
+}
@@ -0,0 +1,300 @@
+package workqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gostratum/core"
+	"github.com/gostratum/core/configx"
+	"github.com/gostratum/core/logx"
+	"go.uber.org/fx"
+)
+
+// Task results recorded in metrics
+const (
+	resultSucceeded = "succeeded"
+	resultFailed    = "failed"
+	// resultDropped: still queued when shutdown gave up waiting; never run
+	resultDropped = "dropped"
+)
+
+// Params are the dependencies of NewPool
+type Params struct {
+	fx.In
+
+	Loader   configx.Loader
+	Registry core.Registry
+	Metrics  *Metrics
+	Log      logx.Logger
+	Handlers []Handler `group:"workqueue.handlers"`
+}
+
+// Pool is a bounded queue of tasks and the workers that run them
+type Pool struct {
+	cfg      Config
+	handlers map[string]Handler
+	queue    chan Task
+	tracker  *tracker
+	metrics  *Metrics
+	log      logx.Logger
+
+	// mu keeps Enqueue from sending on the queue after stop has closed it
+	mu     sync.RWMutex
+	closed bool
+
+	busy    atomic.Int64
+	workers sync.WaitGroup
+
+	// taskCtx is the parent context of every task; it is cancelled when
+	// shutdown runs out of time draining the queue
+	taskCtx     context.Context
+	cancelTasks context.CancelFunc
+}
+
+// NewPool creates the pool from the workqueue config section and every handler
+// in the "workqueue.handlers" group, and registers its readiness check
+func NewPool(p Params) (*Pool, error) {
+	var cfg Config
+	if err := p.Loader.Bind(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load workqueue config: %w", err)
+	}
+
+	pool, err := newPool(cfg, p.Handlers, p.Metrics, p.Log)
+	if err != nil {
+		return nil, err
+	}
+	p.Registry.Register(&capacityCheck{pool: pool})
+	return pool, nil
+}
+
+func newPool(cfg Config, handlers []Handler, metrics *Metrics, log logx.Logger) (*Pool, error) {
+	if cfg.Workers <= 0 || cfg.Capacity <= 0 || cfg.Retain <= 0 {
+		return nil, fmt.Errorf("workqueue.workers (%d), workqueue.capacity (%d) and workqueue.retain (%d) must be positive",
+			cfg.Workers, cfg.Capacity, cfg.Retain)
+	}
+
+	byKind := make(map[string]Handler, len(handlers))
+	for _, h := range handlers {
+		if _, ok := byKind[h.Kind()]; ok {
+			return nil, fmt.Errorf("two handlers share the task kind %q", h.Kind())
+		}
+		byKind[h.Kind()] = h
+	}
+
+	taskCtx, cancel := context.WithCancel(context.Background())
+	return &Pool{
+		cfg:         cfg,
+		handlers:    byKind,
+		queue:       make(chan Task, cfg.Capacity),
+		tracker:     newTracker(cfg.Retain),
+		metrics:     metrics,
+		log:         log,
+		taskCtx:     taskCtx,
+		cancelTasks: cancel,
+	}, nil
+}
+
+// Register ties the workers to the application lifecycle.
+// This function is designed to be used with fx.Invoke.
+func Register(lc fx.Lifecycle, pool *Pool) {
+	lc.Append(fx.Hook{
+		OnStart: pool.start,
+		OnStop:  pool.stop,
+	})
+}
+
+// start launches the workers
+func (p *Pool) start(ctx context.Context) error {
+	p.metrics.capacity.Set(float64(p.cfg.Capacity))
+	p.metrics.workers.Set(float64(p.cfg.Workers))
+	p.metrics.depth.Set(float64(len(p.queue)))
+	p.metrics.busy.Set(0)
+
+	for range p.cfg.Workers {
+		p.workers.Add(1)
+		go p.work()
+	}
+
+	p.log.Info("worker pool started",
+		logx.Int("workers", p.cfg.Workers),
+		logx.Int("capacity", p.cfg.Capacity),
+	)
+	return nil
+}
+
+// stop closes the queue and waits for the workers to run every task left in
+// it. If ctx expires first, running tasks are cancelled and queued ones dropped.
+func (p *Pool) stop(ctx context.Context) error {
+	p.mu.Lock()
+	p.closed = true
+	close(p.queue)
+	p.mu.Unlock()
+
+	p.log.Info("draining work queue", logx.Int("queued", len(p.queue)))
+
+	done := make(chan struct{})
+	go func() {
+		p.workers.Wait()
+		close(done)
+	}()
+
+	defer p.cancelTasks()
+	select {
+	case <-done:
+		p.log.Info("work queue drained")
+		return nil
+	case <-ctx.Done():
+		left := len(p.queue)
+		p.log.Warn("shutdown timed out before the queue drained; cancelling running tasks",
+			logx.Int("running", int(p.busy.Load())),
+			logx.Int("dropped", left),
+		)
+		return fmt.Errorf("work queue not drained: %d tasks dropped", left)
+	}
+}
+
+// Enqueue adds a task to the queue without waiting. It fails with ErrQueueFull
+// when the queue is at capacity and with ErrClosed once shutdown has begun.
+func (p *Pool) Enqueue(kind string, payload any) (Task, error) {
+	if _, ok := p.handlers[kind]; !ok {
+		return Task{}, ErrUnknownKind
+	}
+	task := Task{
+		ID:         uuid.NewString(),
+		Kind:       kind,
+		Payload:    payload,
+		EnqueuedAt: time.Now(),
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		p.metrics.rejected.Inc(kind)
+		return Task{}, ErrClosed
+	}
+
+	// Tracked before it is queued, so a fast worker never finds it missing
+	p.tracker.queued(task)
+	select {
+	case p.queue <- task:
+	default:
+		p.tracker.forget(task.ID)
+		p.metrics.rejected.Inc(kind)
+		return Task{}, ErrQueueFull
+	}
+	p.metrics.depth.Set(float64(len(p.queue)))
+	return task, nil
+}
+
+// Status returns the status of a task. Finished tasks are remembered up to
+// the retain limit.
+func (p *Pool) Status(id string) (TaskStatus, error) {
+	return p.tracker.get(id)
+}
+
+// Depth returns the number of tasks waiting in the queue
+func (p *Pool) Depth() int {
+	return len(p.queue)
+}
+
+// Capacity returns the number of tasks the queue can hold
+func (p *Pool) Capacity() int {
+	return p.cfg.Capacity
+}
+
+// work runs tasks until the queue is closed and empty
+func (p *Pool) work() {
+	defer p.workers.Done()
+
+	for task := range p.queue {
+		p.metrics.depth.Set(float64(len(p.queue)))
+		p.process(task)
+	}
+}
+
+// process runs one task with its handler
+func (p *Pool) process(task Task) {
+	fields := []logx.Field{
+		logx.String("task_id", task.ID),
+		logx.String("kind", task.Kind),
+	}
+	p.metrics.wait.Observe(time.Since(task.EnqueuedAt).Seconds(), task.Kind)
+
+	if p.taskCtx.Err() != nil {
+		p.log.Warn("task dropped at shutdown", fields...)
+		p.tracker.done(task.ID, StateDropped, time.Now(), p.taskCtx.Err())
+		p.metrics.tasks.Inc(task.Kind, resultDropped)
+		return
+	}
+
+	p.metrics.busy.Set(float64(p.busy.Add(1)))
+	defer func() {
+		p.metrics.busy.Set(float64(p.busy.Add(-1)))
+	}()
+
+	ctx, cancel := context.WithTimeout(p.taskCtx, p.cfg.TaskTimeout)
+	defer cancel()
+
+	start := time.Now()
+	p.tracker.running(task.ID, start)
+	err := call(ctx, p.handlers[task.Kind], task)
+	elapsed := time.Since(start)
+	p.metrics.duration.Observe(elapsed.Seconds(), task.Kind)
+
+	fields = append(fields, logx.String("duration", elapsed.String()))
+	if err != nil {
+		p.log.Error("task failed", append(fields, logx.Err(err))...)
+		p.tracker.done(task.ID, StateFailed, time.Now(), err)
+		p.metrics.tasks.Inc(task.Kind, resultFailed)
+		return
+	}
+	p.log.Info("task done", fields...)
+	p.tracker.done(task.ID, StateSucceeded, time.Now(), nil)
+	p.metrics.tasks.Inc(task.Kind, resultSucceeded)
+}
+
+// call runs the handler, turning a panic into an error so one bad task does
+// not take down its worker
+func call(ctx context.Context, h Handler, task Task) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("task panicked: %v", r)
+		}
+	}()
+	return h.Handle(ctx, task)
+}
+
+// capacityCheck reports not ready while the queue is nearly full or draining
+type capacityCheck struct {
+	pool *Pool
+}
+
+func (c *capacityCheck) Name() string {
+	return "workqueue"
+}
+
+func (c *capacityCheck) Kind() core.Kind {
+	return core.Readiness
+}
+
+func (c *capacityCheck) Check(ctx context.Context) error {
+	p := c.pool
+
+	p.mu.RLock()
+	closed := p.closed
+	p.mu.RUnlock()
+	if closed {
+		return errors.New("draining for shutdown")
+	}
+
+	depth, capacity := p.Depth(), p.Capacity()
+	if float64(depth) >= p.cfg.ReadyThreshold*float64(capacity) {
+		return fmt.Errorf("queue is nearly full (%d/%d)", depth, capacity)
+	}
+	return nil
+}
@@ -0,0 +1,285 @@
+package workqueue
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gostratum/core/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// funcHandler adapts a function to Handler
+type funcHandler struct {
+	kind   string
+	handle func(ctx context.Context, task Task) error
+}
+
+func (h funcHandler) Kind() string                                { return h.kind }
+func (h funcHandler) Handle(ctx context.Context, task Task) error { return h.handle(ctx, task) }
+
+// recorder stores the last value set and the number of increments per metric and labels
+type recorder struct {
+	name string
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func (r *recorder) key(labels []string) string {
+	return r.name + "{" + strings.Join(labels, ",") + "}"
+}
+
+func (r *recorder) Inc(labels ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[r.key(labels)]++
+}
+
+func (r *recorder) Observe(v float64, labels ...string) {
+	r.Inc(labels...)
+}
+
+func (r *recorder) Set(v float64, labels ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[r.key(labels)] = v
+}
+
+func (r *recorder) get(labels ...string) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.values[r.key(labels)]
+}
+
+func newTestMetrics() *Metrics {
+	m := func(name string) *recorder { return &recorder{name: name, values: map[string]float64{}} }
+	return &Metrics{
+		depth:    m("depth"),
+		capacity: m("capacity"),
+		workers:  m("workers"),
+		busy:     m("busy"),
+		tasks:    m("tasks"),
+		rejected: m("rejected"),
+		wait:     m("wait"),
+		duration: m("duration"),
+	}
+}
+
+func testConfig() Config {
+	return Config{Workers: 2, Capacity: 4, TaskTimeout: time.Second, Retain: 10, ReadyThreshold: 0.5}
+}
+
+func newTestPool(t *testing.T, cfg Config, handlers ...Handler) (*Pool, *Metrics) {
+	t.Helper()
+
+	metrics := newTestMetrics()
+	p, err := newPool(cfg, handlers, metrics, logx.NewNoopLogger())
+	require.NoError(t, err)
+	return p, metrics
+}
+
+// waitForState polls until the task reaches a final state
+func waitForState(t *testing.T, p *Pool, id string) TaskStatus {
+	t.Helper()
+
+	var status TaskStatus
+	require.Eventually(t, func() bool {
+		var err error
+		status, err = p.Status(id)
+		require.NoError(t, err)
+		return status.State != StateQueued && status.State != StateRunning
+	}, time.Second, 5*time.Millisecond)
+	return status
+}
+
+func TestTaskResults(t *testing.T) {
+	tests := []struct {
+		name   string
+		handle func(ctx context.Context, task Task) error
+		want   string
+	}{
+		{name: "success", handle: func(ctx context.Context, task Task) error { return nil }, want: StateSucceeded},
+		{name: "failure", handle: func(ctx context.Context, task Task) error { return errors.New("boom") }, want: StateFailed},
+		{name: "panic", handle: func(ctx context.Context, task Task) error { panic("boom") }, want: StateFailed},
+		{name: "timeout", want: StateFailed, handle: func(ctx context.Context, task Task) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.TaskTimeout = 20 * time.Millisecond
+			p, metrics := newTestPool(t, cfg, funcHandler{kind: "test", handle: tt.handle})
+			require.NoError(t, p.start(context.Background()))
+			defer p.stop(context.Background())
+
+			task, err := p.Enqueue("test", nil)
+			require.NoError(t, err)
+
+			status := waitForState(t, p, task.ID)
+			assert.Equal(t, tt.want, status.State)
+			assert.False(t, status.StartedAt.IsZero())
+			assert.False(t, status.FinishedAt.IsZero())
+			if tt.want == StateSucceeded {
+				assert.Empty(t, status.Error)
+			} else {
+				assert.NotEmpty(t, status.Error)
+			}
+			assert.Equal(t, float64(1), metrics.tasks.(*recorder).get("test", tt.want))
+			assert.Equal(t, float64(1), metrics.duration.(*recorder).get("test"))
+		})
+	}
+}
+
+func TestEnqueueFailsWhenQueueIsFull(t *testing.T) {
+	// Not started: nothing takes tasks off the queue
+	p, metrics := newTestPool(t, testConfig(), funcHandler{kind: "test", handle: func(ctx context.Context, task Task) error { return nil }})
+
+	var ids []string
+	for range p.Capacity() {
+		task, err := p.Enqueue("test", nil)
+		require.NoError(t, err)
+		ids = append(ids, task.ID)
+	}
+	assert.Equal(t, p.Capacity(), p.Depth())
+	assert.Equal(t, float64(p.Capacity()), metrics.depth.(*recorder).get())
+
+	_, err := p.Enqueue("test", nil)
+	assert.ErrorIs(t, err, ErrQueueFull)
+	assert.Equal(t, float64(1), metrics.rejected.(*recorder).get("test"))
+
+	// Only the accepted tasks are tracked
+	for _, id := range ids {
+		status, err := p.Status(id)
+		require.NoError(t, err)
+		assert.Equal(t, StateQueued, status.State)
+	}
+}
+
+func TestEnqueueUnknownKind(t *testing.T) {
+	p, _ := newTestPool(t, testConfig())
+
+	_, err := p.Enqueue("nope", nil)
+	assert.ErrorIs(t, err, ErrUnknownKind)
+}
+
+func TestStopDrainsQueue(t *testing.T) {
+	var mu sync.Mutex
+	var ran []string
+	p, _ := newTestPool(t, testConfig(), funcHandler{kind: "test", handle: func(ctx context.Context, task Task) error {
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		ran = append(ran, task.ID)
+		mu.Unlock()
+		return nil
+	}})
+
+	var ids []string
+	for range p.Capacity() {
+		task, err := p.Enqueue("test", nil)
+		require.NoError(t, err)
+		ids = append(ids, task.ID)
+	}
+
+	require.NoError(t, p.start(context.Background()))
+	require.NoError(t, p.stop(context.Background()))
+
+	assert.ElementsMatch(t, ids, ran)
+	for _, id := range ids {
+		status, err := p.Status(id)
+		require.NoError(t, err)
+		assert.Equal(t, StateSucceeded, status.State)
+	}
+
+	_, err := p.Enqueue("test", nil)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestStopCancelsTasksAfterTimeout(t *testing.T) {
+	cfg := testConfig()
+	cfg.Workers = 1
+	cfg.TaskTimeout = time.Minute
+	started := make(chan struct{}, 1)
+	p, metrics := newTestPool(t, cfg, funcHandler{kind: "test", handle: func(ctx context.Context, task Task) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	require.NoError(t, p.start(context.Background()))
+
+	running, err := p.Enqueue("test", nil)
+	require.NoError(t, err)
+	<-started
+	queued, err := p.Enqueue("test", nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = p.stop(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 tasks dropped")
+
+	assert.Equal(t, StateFailed, waitForState(t, p, running.ID).State)
+	assert.Equal(t, StateDropped, waitForState(t, p, queued.ID).State)
+	assert.Equal(t, float64(1), metrics.tasks.(*recorder).get("test", resultDropped))
+}
+
+func TestCapacityCheck(t *testing.T) {
+	p, _ := newTestPool(t, testConfig(), funcHandler{kind: "test", handle: func(ctx context.Context, task Task) error { return nil }})
+	check := &capacityCheck{pool: p}
+
+	assert.NoError(t, check.Check(context.Background()))
+
+	// ReadyThreshold is 0.5 of a capacity of 4
+	for range 2 {
+		_, err := p.Enqueue("test", nil)
+		require.NoError(t, err)
+	}
+	err := check.Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nearly full (2/4)")
+
+	require.NoError(t, p.start(context.Background()))
+	require.NoError(t, p.stop(context.Background()))
+	err = check.Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "draining")
+}
+
+func TestNewPool(t *testing.T) {
+	noop := funcHandler{kind: "test", handle: func(ctx context.Context, task Task) error { return nil }}
+
+	_, err := newPool(Config{Workers: 0, Capacity: 1, Retain: 1}, nil, newTestMetrics(), logx.NewNoopLogger())
+	assert.ErrorContains(t, err, "must be positive")
+
+	_, err = newPool(testConfig(), []Handler{noop, noop}, newTestMetrics(), logx.NewNoopLogger())
+	assert.ErrorContains(t, err, `two handlers share the task kind "test"`)
+}
+
+func TestTrackerForgetsOldestFinishedTasks(t *testing.T) {
+	tr := newTracker(2)
+	now := time.Now()
+	for _, id := range []string{"a", "b", "c"} {
+		tr.queued(Task{ID: id, Kind: "test", EnqueuedAt: now})
+	}
+	tr.queued(Task{ID: "waiting", Kind: "test", EnqueuedAt: now})
+
+	for _, id := range []string{"a", "b", "c"} {
+		tr.running(id, now)
+		tr.done(id, StateSucceeded, now, nil)
+	}
+
+	_, err := tr.get("a")
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+	for _, id := range []string{"b", "c", "waiting"} {
+		_, err := tr.get(id)
+		assert.NoError(t, err, id)
+	}
+}
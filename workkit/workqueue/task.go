@@ -0,0 +1,50 @@
+package workqueue
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/fx"
+)
+
+// handlersGroup is the fx value group the pool runs tasks with
+const handlersGroup = `group:"workqueue.handlers"`
+
+var (
+	// ErrQueueFull indicates the queue is at capacity; the caller should retry later
+	ErrQueueFull = errors.New("queue is full")
+
+	// ErrClosed indicates the queue is draining for shutdown and accepts no more tasks
+	ErrClosed = errors.New("queue is closed")
+
+	// ErrUnknownKind indicates no handler is registered for a task kind
+	ErrUnknownKind = errors.New("unknown task kind")
+)
+
+// Task is a unit of work waiting in or taken from the queue
+type Task struct {
+	ID   string
+	Kind string
+	// Payload is handed to the handler as is; the queue is in-process, so it
+	// is never serialized
+	Payload    any
+	EnqueuedAt time.Time
+}
+
+// Handler runs the tasks of one kind
+type Handler interface {
+	// Kind is the task kind the handler runs
+	Kind() string
+	// Handle runs one task. ctx is cancelled after the task timeout, or when
+	// shutdown gives up waiting. A failed task is not retried.
+	Handle(ctx context.Context, task Task) error
+}
+
+// AsHandler annotates a constructor so its result joins the handler group the
+// pool runs tasks with:
+//
+//	fx.Provide(workqueue.AsHandler(tasks.NewThumbnailHandler))
+func AsHandler(constructor any) any {
+	return fx.Annotate(constructor, fx.As(new(Handler)), fx.ResultTags(handlersGroup))
+}
@@ -0,0 +1,100 @@
+package workqueue
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrTaskNotFound indicates a task is unknown or was forgotten to make room
+var ErrTaskNotFound = errors.New("task not found")
+
+// Task states
+const (
+	StateQueued    = "queued"
+	StateRunning   = "running"
+	StateSucceeded = "succeeded"
+	StateFailed    = "failed"
+	StateDropped   = "dropped"
+)
+
+// TaskStatus is where a task is in its life
+type TaskStatus struct {
+	ID         string
+	Kind       string
+	State      string
+	EnqueuedAt time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Error      string
+}
+
+// tracker remembers the status of queued and running tasks, and of the most
+// recent finished ones
+type tracker struct {
+	retain int
+
+	mu       sync.Mutex
+	tasks    map[string]*TaskStatus
+	finished []string
+}
+
+func newTracker(retain int) *tracker {
+	return &tracker{retain: retain, tasks: make(map[string]*TaskStatus)}
+}
+
+func (t *tracker) queued(task Task) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tasks[task.ID] = &TaskStatus{ID: task.ID, Kind: task.Kind, State: StateQueued, EnqueuedAt: task.EnqueuedAt}
+}
+
+// forget removes a task that never made it into the queue
+func (t *tracker) forget(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.tasks, id)
+}
+
+func (t *tracker) running(id string, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if s, ok := t.tasks[id]; ok {
+		s.State = StateRunning
+		s.StartedAt = at
+	}
+}
+
+// done records the final state and forgets the oldest finished task once more
+// than retain are kept
+func (t *tracker) done(id, state string, at time.Time, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.tasks[id]
+	if !ok {
+		return
+	}
+	s.State = state
+	s.FinishedAt = at
+	if err != nil {
+		s.Error = err.Error()
+	}
+
+	t.finished = append(t.finished, id)
+	if len(t.finished) > t.retain {
+		delete(t.tasks, t.finished[0])
+		t.finished = t.finished[1:]
+	}
+}
+
+func (t *tracker) get(id string) (TaskStatus, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.tasks[id]
+	if !ok {
+		return TaskStatus{}, ErrTaskNotFound
+	}
+	return *s, nil
+}